@@ -0,0 +1,439 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gpu-orchestrator/config"
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/monitoring"
+	"gpu-orchestrator/core/optimizer"
+	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/providers"
+
+	"github.com/gorilla/mux"
+)
+
+// AdminHandler exposes the running configuration for debugging deployments
+type AdminHandler struct {
+	cfg   *config.Config
+	admin *AdminAuth
+	meter *providers.UsageMeter // Optional; provider API usage
+	usage *repository.ProviderUsageRepository
+
+	instanceTypes     *optimizer.InstanceTypeRules // Optional; org instance type lists
+	instanceTypeRules *repository.InstanceTypeRuleRepository
+
+	priceQuarantine *repository.PriceQuarantineRepository // Optional; quarantined prices
+	pricing         *optimizer.PricingFetcher             // Optional; pricing refresh status
+
+	consistency *repository.ConsistencyRepository // Optional; consistency check reports
+	orphans     *monitoring.OrphanCleaner         // Optional; orphaned record cleanup
+
+	benchmarks *optimizer.PerformanceMetricsStore // Optional; throughput benchmarks
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(cfg *config.Config, admin *AdminAuth) *AdminHandler {
+	return &AdminHandler{cfg: cfg, admin: admin}
+}
+
+// SetProviderUsage enables GET /v1/admin/providers/usage
+func (h *AdminHandler) SetProviderUsage(meter *providers.UsageMeter, usage *repository.ProviderUsageRepository) {
+	h.meter = meter
+	h.usage = usage
+}
+
+// SetInstanceTypeRules enables GET/PUT /v1/admin/instance-types
+func (h *AdminHandler) SetInstanceTypeRules(rules *optimizer.InstanceTypeRules, repo *repository.InstanceTypeRuleRepository) {
+	h.instanceTypes = rules
+	h.instanceTypeRules = repo
+}
+
+// SetPricingFetcher enables GET /v1/admin/pricing/status
+func (h *AdminHandler) SetPricingFetcher(pricing *optimizer.PricingFetcher) {
+	h.pricing = pricing
+}
+
+// SetPriceQuarantine enables the /v1/admin/pricing/quarantine endpoints
+func (h *AdminHandler) SetPriceQuarantine(repo *repository.PriceQuarantineRepository) {
+	h.priceQuarantine = repo
+}
+
+// SetBenchmarks enables GET /v1/admin/benchmarks
+func (h *AdminHandler) SetBenchmarks(benchmarks *optimizer.PerformanceMetricsStore) {
+	h.benchmarks = benchmarks
+}
+
+// SetConsistencyReports enables GET /v1/admin/consistency
+func (h *AdminHandler) SetConsistencyReports(repo *repository.ConsistencyRepository) {
+	h.consistency = repo
+}
+
+// SetOrphanCleaner enables POST /v1/admin/orphans/cleanup
+func (h *AdminHandler) SetOrphanCleaner(orphans *monitoring.OrphanCleaner) {
+	h.orphans = orphans
+}
+
+// loopIntervalView is a background loop interval as reported by the API
+type loopIntervalView struct {
+	Name     string  `json:"name"`
+	Env      string  `json:"env"`
+	Value    string  `json:"value"` // Go duration, e.g. "30s"; "0s" = disabled
+	Seconds  float64 `json:"seconds"`
+	Min      string  `json:"min"`
+	Max      string  `json:"max,omitempty"`
+	Optional bool    `json:"optional,omitempty"` // 0 disables the loop
+}
+
+// GetConfig handles GET /v1/admin/config (admin).
+// Secrets are never included.
+func (h *AdminHandler) GetConfig(w http.ResponseWriter, r *http.Request) {
+	if !h.admin.Allow(w, r) {
+		return
+	}
+
+	var intervals []loopIntervalView
+	for _, interval := range h.cfg.Intervals() {
+		view := loopIntervalView{
+			Name:     interval.Name,
+			Env:      interval.Env,
+			Value:    interval.Value.String(),
+			Seconds:  interval.Value.Seconds(),
+			Min:      interval.Min.String(),
+			Optional: interval.Optional,
+		}
+		if interval.Max > 0 {
+			view.Max = interval.Max.String()
+		}
+		intervals = append(intervals, view)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"instance_id":     h.cfg.InstanceID,
+		"leader_election": h.cfg.LeaderElection,
+		"intervals":       intervals,
+	})
+}
+
+// GetProviderUsage handles GET /v1/admin/providers/usage (admin). It returns
+// the daily rollups of provider API calls (?days=7, ?provider=), this
+// replica's counts since start, and each provider's hourly call budget.
+func (h *AdminHandler) GetProviderUsage(w http.ResponseWriter, r *http.Request) {
+	if !h.admin.Allow(w, r) {
+		return
+	}
+	if h.meter == nil {
+		http.Error(w, "Provider API usage is not metered", http.StatusNotFound)
+		return
+	}
+
+	days := 7
+	if v := r.URL.Query().Get("days"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1 || parsed > 366 {
+			http.Error(w, "days must be between 1 and 366", http.StatusBadRequest)
+			return
+		}
+		days = parsed
+	}
+	since := time.Now().UTC().AddDate(0, 0, 1-days).Format("2006-01-02")
+
+	daily, err := h.usage.ListDaily(since, r.URL.Query().Get("provider"))
+	if err != nil {
+		http.Error(w, "Failed to fetch provider usage: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if daily == nil {
+		daily = []models.ProviderAPIUsage{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"since":       since,
+		"daily":       daily,
+		"instance_id": h.cfg.InstanceID,
+		"instance":    h.meter.Usage(), // Not yet flushed counts are included here only
+		"budgets":     h.meter.Budgets(),
+	})
+}
+
+// GetInstanceTypes handles GET /v1/admin/instance-types (admin). It returns
+// the org-wide allow and deny lists and their recent changes (?limit=20).
+func (h *AdminHandler) GetInstanceTypes(w http.ResponseWriter, r *http.Request) {
+	if !h.admin.Allow(w, r) {
+		return
+	}
+	if h.instanceTypes == nil {
+		http.Error(w, "Instance type lists are not configured", http.StatusNotFound)
+		return
+	}
+
+	limit := 20
+	if v := r.URL.Query().Get("limit"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1 || parsed > 500 {
+			http.Error(w, "limit must be between 1 and 500", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+	changes, err := h.instanceTypeRules.ListChanges(limit)
+	if err != nil {
+		http.Error(w, "Failed to fetch instance type rule changes: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if changes == nil {
+		changes = []models.InstanceTypeRuleChange{}
+	}
+
+	allow, deny := h.instanceTypes.Lists()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"allow":   allow,
+		"deny":    deny,
+		"changes": changes,
+	})
+}
+
+// UpdateInstanceTypesRequest is the body of PUT /v1/admin/instance-types
+type UpdateInstanceTypesRequest struct {
+	Allow  []string `json:"allow"`
+	Deny   []string `json:"deny"`
+	Reason string   `json:"reason,omitempty"`
+}
+
+// UpdateInstanceTypes handles PUT /v1/admin/instance-types (admin). Both
+// lists are replaced and the change is recorded. It applies to the next
+// optimization on this replica and is picked up by the others on their
+// next sync; jobs already scheduled keep their allocations.
+func (h *AdminHandler) UpdateInstanceTypes(w http.ResponseWriter, r *http.Request) {
+	if !h.admin.Allow(w, r) {
+		return
+	}
+	if h.instanceTypes == nil {
+		http.Error(w, "Instance type lists are not configured", http.StatusNotFound)
+		return
+	}
+
+	var req UpdateInstanceTypesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := optimizer.ValidateInstanceTypePatterns(req.Allow); err != nil {
+		http.Error(w, "Invalid allow list: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := optimizer.ValidateInstanceTypePatterns(req.Deny); err != nil {
+		http.Error(w, "Invalid deny list: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	previousAllow, previousDeny := h.instanceTypes.Lists()
+	change := &models.InstanceTypeRuleChange{
+		Allow:         req.Allow,
+		Deny:          req.Deny,
+		PreviousAllow: previousAllow,
+		PreviousDeny:  previousDeny,
+		Reason:        req.Reason,
+		RemoteAddr:    r.RemoteAddr,
+	}
+	if err := h.instanceTypeRules.RecordChange(change); err != nil {
+		http.Error(w, "Failed to record instance type rule change: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := h.instanceTypes.Sync(h.instanceTypeRules); err != nil {
+		http.Error(w, "Failed to apply instance type rules: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Instance type rules changed by %s: allow %v -> %v, deny %v -> %v (%s)",
+		r.RemoteAddr, previousAllow, req.Allow, previousDeny, req.Deny, req.Reason)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(change)
+}
+
+// GetPricingStatus handles GET /v1/admin/pricing/status (admin). It reports
+// the refresh of every provider and price kind on this replica.
+func (h *AdminHandler) GetPricingStatus(w http.ResponseWriter, r *http.Request) {
+	if !h.admin.Allow(w, r) {
+		return
+	}
+	if h.pricing == nil {
+		http.Error(w, "Pricing refresh is disabled", http.StatusNotFound)
+		return
+	}
+
+	statuses := h.pricing.RefreshStatus()
+	healthy := true
+	for _, status := range statuses {
+		if status.ConsecutiveFailures > 0 {
+			healthy = false
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"refreshes": statuses,
+		"healthy":   healthy,
+	})
+}
+
+// ListQuarantinedPrices handles GET /v1/admin/pricing/quarantine (admin). It
+// lists quarantined prices; ?status=accepted|cleared|all selects others.
+func (h *AdminHandler) ListQuarantinedPrices(w http.ResponseWriter, r *http.Request) {
+	if !h.admin.Allow(w, r) {
+		return
+	}
+	if h.priceQuarantine == nil {
+		http.Error(w, "Price sanity checks are disabled", http.StatusNotFound)
+		return
+	}
+
+	status := models.AnomalyQuarantined
+	switch v := r.URL.Query().Get("status"); v {
+	case "":
+	case "all":
+		status = ""
+	case string(models.AnomalyQuarantined), string(models.AnomalyAccepted), string(models.AnomalyCleared):
+		status = models.PriceAnomalyStatus(v)
+	default:
+		http.Error(w, "status must be quarantined, accepted, cleared or all", http.StatusBadRequest)
+		return
+	}
+
+	anomalies, err := h.priceQuarantine.ListAnomalies(status)
+	if err != nil {
+		http.Error(w, "Failed to fetch quarantined prices: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if anomalies == nil {
+		anomalies = []models.PriceAnomaly{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"items": anomalies,
+	})
+}
+
+// AcceptQuarantinedPrice handles POST /v1/admin/pricing/quarantine/{id}/accept
+// (admin). The quarantined price is stored in the pricing cache and later
+// refreshes returning it are no longer quarantined.
+func (h *AdminHandler) AcceptQuarantinedPrice(w http.ResponseWriter, r *http.Request) {
+	if !h.admin.Allow(w, r) {
+		return
+	}
+	if h.priceQuarantine == nil {
+		http.Error(w, "Price sanity checks are disabled", http.StatusNotFound)
+		return
+	}
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid quarantine ID", http.StatusBadRequest)
+		return
+	}
+
+	anomaly, err := h.priceQuarantine.Accept(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "Quarantined price not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, repository.ErrAnomalyNotQuarantined) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to accept price: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Accepted quarantined %s price of %s %s/%s: $%.4f/hr (by %s)",
+		anomaly.Kind, anomaly.Provider, anomaly.Region, anomaly.InstanceType, anomaly.Price, r.RemoteAddr)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(anomaly)
+}
+
+// GetConsistencyReport handles GET /v1/admin/consistency (admin). It returns
+// the latest report of the consistency checker.
+func (h *AdminHandler) GetConsistencyReport(w http.ResponseWriter, r *http.Request) {
+	if !h.admin.Allow(w, r) {
+		return
+	}
+	if h.consistency == nil {
+		http.Error(w, "Consistency checks are disabled", http.StatusNotFound)
+		return
+	}
+
+	report, err := h.consistency.LatestReport()
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "No consistency check has run yet", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to fetch consistency report: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if report.Discrepancies == nil {
+		report.Discrepancies = []models.Discrepancy{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// CleanOrphans handles POST /v1/admin/orphans/cleanup (admin). It runs one
+// orphan cleaner pass synchronously and returns its report. The pass only
+// counts unless ?apply=true, whatever mode scheduled passes run in.
+func (h *AdminHandler) CleanOrphans(w http.ResponseWriter, r *http.Request) {
+	if !h.admin.Allow(w, r) {
+		return
+	}
+	if h.orphans == nil {
+		http.Error(w, "Orphan cleanup is not available", http.StatusNotFound)
+		return
+	}
+	apply := false
+	if v := r.URL.Query().Get("apply"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			http.Error(w, "apply must be true or false", http.StatusBadRequest)
+			return
+		}
+		apply = parsed
+	}
+
+	report := h.orphans.Run(r.Context(), apply, requestActor(r))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// GetBenchmarks handles GET /v1/admin/benchmarks (admin). It lists the
+// throughput each framework, GPU type and model class is estimated with,
+// whether it is static or learned, and the samples behind learned figures.
+func (h *AdminHandler) GetBenchmarks(w http.ResponseWriter, r *http.Request) {
+	if !h.admin.Allow(w, r) {
+		return
+	}
+	if h.benchmarks == nil {
+		http.Error(w, "Benchmarks are not available", http.StatusNotFound)
+		return
+	}
+
+	benchmarks, version := h.benchmarks.Benchmarks()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"static_version": version,
+		"min_samples":    optimizer.BenchmarkMinSamples,
+		"items":          benchmarks,
+	})
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/monitoring"
+	"gpu-orchestrator/core/repository"
+)
+
+// AlertHandler handles alert rule and firing HTTP requests
+type AlertHandler struct {
+	alertRepo *repository.AlertRepository
+}
+
+// NewAlertHandler creates a new alert handler
+func NewAlertHandler(alertRepo *repository.AlertRepository) *AlertHandler {
+	return &AlertHandler{alertRepo: alertRepo}
+}
+
+// ListAlerts handles GET /v1/alerts
+func (h *AlertHandler) ListAlerts(w http.ResponseWriter, r *http.Request) {
+	limit := 100 // Default limit
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		fmt.Sscanf(limitParam, "%d", &limit)
+	}
+
+	var ruleID int64
+	if ruleParam := r.URL.Query().Get("rule_id"); ruleParam != "" {
+		var err error
+		ruleID, err = strconv.ParseInt(ruleParam, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid rule_id", http.StatusBadRequest)
+			return
+		}
+	}
+
+	firings, err := h.alertRepo.ListFirings(ruleID, limit)
+	if err != nil {
+		http.Error(w, "Failed to list alerts: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Build response items
+	items := make([]map[string]interface{}, len(firings))
+	for i, firing := range firings {
+		items[i] = map[string]interface{}{
+			"id":        firing.ID,
+			"rule_id":   firing.RuleID,
+			"rule_name": firing.RuleName,
+			"key":       firing.DedupKey,
+			"fired_at":  firing.FiredAt,
+			"subject":   firing.Subject,
+			"message":   firing.Message,
+			"meta":      firing.MetaJSON,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"items": items,
+	})
+}
+
+// ListRules handles GET /v1/alerts/rules
+func (h *AlertHandler) ListRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.alertRepo.ListRules(false)
+	if err != nil {
+		http.Error(w, "Failed to list alert rules: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	items := make([]map[string]interface{}, len(rules))
+	for i, rule := range rules {
+		items[i] = alertRuleResponse(rule)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"items": items,
+	})
+}
+
+// UpsertRule handles POST /v1/alerts/rules (rules are keyed by name)
+func (h *AlertHandler) UpsertRule(w http.ResponseWriter, r *http.Request) {
+	var spec monitoring.AlertRuleSpec
+	if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := spec.ToRule()
+	if err != nil {
+		http.Error(w, "Invalid alert rule: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.alertRepo.UpsertRule(rule); err != nil {
+		http.Error(w, "Failed to save alert rule: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(alertRuleResponse(rule))
+}
+
+// alertRuleResponse builds the API representation of an alert rule
+func alertRuleResponse(rule *models.AlertRule) map[string]interface{} {
+	item := map[string]interface{}{
+		"id":               rule.ID,
+		"name":             rule.Name,
+		"condition":        rule.Condition,
+		"threshold":        rule.Threshold,
+		"cooldown":         rule.Cooldown.String(),
+		"email_recipients": rule.EmailRecipients,
+		"enabled":          rule.Enabled,
+		"created_at":       rule.CreatedAt,
+	}
+	if rule.Status != "" {
+		item["status"] = rule.Status
+	}
+	if rule.Window > 0 {
+		item["window"] = rule.Window.String()
+	}
+	if rule.TeamID != "" {
+		item["team_id"] = rule.TeamID
+	}
+	if rule.WebhookURL != "" {
+		item["webhook_url"] = rule.WebhookURL
+	}
+	if rule.LastEvaluatedAt != nil {
+		item["last_evaluated_at"] = *rule.LastEvaluatedAt
+	}
+	return item
+}
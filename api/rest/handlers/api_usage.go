@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/monitoring"
+	"gpu-orchestrator/core/repository"
+
+	"github.com/gorilla/mux"
+)
+
+// APIUsageHandler meters API requests and reports the usage to admins
+type APIUsageHandler struct {
+	meter *monitoring.APIUsageMeter
+	repo  *repository.APIUsageRepository
+	admin *AdminAuth
+}
+
+// NewAPIUsageHandler creates the API usage middleware and report
+func NewAPIUsageHandler(meter *monitoring.APIUsageMeter, repo *repository.APIUsageRepository, admin *AdminAuth) *APIUsageHandler {
+	return &APIUsageHandler{meter: meter, repo: repo, admin: admin}
+}
+
+// Middleware counts every request by endpoint (method and route template),
+// client and, on job-scoped routes, job. It runs after the audit middleware,
+// which identifies the client.
+func (h *APIUsageHandler) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		template := "unmatched" // Raw paths would make an endpoint per job ID
+		if route := mux.CurrentRoute(r); route != nil {
+			if t, err := route.GetPathTemplate(); err == nil {
+				template = t
+			}
+		}
+		jobID := ""
+		if strings.Contains(template, "/jobs/{id}") {
+			jobID = mux.Vars(r)["id"]
+		}
+		h.meter.Record(r.Method+" "+template, requestActor(r), jobID, recorder.status, time.Since(started))
+	})
+}
+
+// GetUsage handles GET /v1/admin/api-usage (admin). It returns the requests
+// of the last ?days=7 UTC days per endpoint, and the ?limit=20 busiest
+// clients and jobs. Jobs polled far more than others are candidates for the
+// event endpoints.
+func (h *APIUsageHandler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	if !h.admin.Allow(w, r) {
+		return
+	}
+
+	query := r.URL.Query()
+	days, limit := 7, 20
+	for param, dest := range map[string]*int{"days": &days, "limit": &limit} {
+		if v := query.Get(param); v != "" {
+			parsed, err := strconv.Atoi(v)
+			if err != nil || parsed < 1 || parsed > 1000 {
+				http.Error(w, fmt.Sprintf("%s must be between 1 and 1000", param), http.StatusBadRequest)
+				return
+			}
+			*dest = parsed
+		}
+	}
+	since := time.Now().UTC().AddDate(0, 0, 1-days).Format("2006-01-02")
+
+	report := map[string]interface{}{
+		"since":        since,
+		"tracked_jobs": h.meter.TrackedJobs(), // Distinct jobs of this replica's unflushed window
+	}
+	for name, group := range map[string]struct {
+		dimension string
+		limit     int
+	}{
+		"endpoints": {repository.APIUsageByEndpoint, 0},
+		"clients":   {repository.APIUsageByClient, limit},
+		"jobs":      {repository.APIUsageByJob, limit},
+	} {
+		totals, err := h.repo.Totals(since, group.dimension, group.limit)
+		if err != nil {
+			http.Error(w, "Failed to fetch API usage: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if totals == nil {
+			totals = []models.APIUsageTotal{}
+		}
+		report[name] = totals
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// statusRecorder captures the status of a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Hijack hands the connection to WebSocket handlers
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response does not support hijacking")
+	}
+	r.status = http.StatusSwitchingProtocols
+	return hijacker.Hijack()
+}
+
+// Flush passes flushes through so streamed responses reach the client as
+// they are written
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/storage"
+
+	"github.com/gorilla/mux"
+)
+
+// ArtifactGCHandler triggers checkpoint retention GC and serves its reports
+type ArtifactGCHandler struct {
+	gc           *storage.ArtifactGC
+	artifactRepo *repository.ArtifactRepository
+}
+
+// NewArtifactGCHandler creates a new artifact GC handler
+func NewArtifactGCHandler(gc *storage.ArtifactGC, artifactRepo *repository.ArtifactRepository) *ArtifactGCHandler {
+	return &ArtifactGCHandler{gc: gc, artifactRepo: artifactRepo}
+}
+
+// TriggerGCRunRequest is the body of POST /v1/artifacts/gc/runs
+type TriggerGCRunRequest struct {
+	DryRun *bool `json:"dry_run,omitempty"` // Default: the configured mode
+}
+
+// TriggerGCRun handles POST /v1/artifacts/gc/runs (admin).
+// Runs one pass synchronously and returns its report.
+func (h *ArtifactGCHandler) TriggerGCRun(w http.ResponseWriter, r *http.Request) {
+	// TODO: Restrict to admins once auth is in place
+	var req TriggerGCRunRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+	dryRun := h.gc.DryRunDefault()
+	if req.DryRun != nil {
+		dryRun = *req.DryRun
+	}
+
+	run, err := h.gc.Run(r.Context(), dryRun)
+	if err != nil {
+		http.Error(w, "Artifact GC failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(run)
+}
+
+// ListGCRuns handles GET /v1/artifacts/gc/runs
+func (h *ArtifactGCHandler) ListGCRuns(w http.ResponseWriter, r *http.Request) {
+	limit := 20
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		if n, err := strconv.Atoi(limitParam); err == nil && n > 0 {
+			limit = n
+		}
+	}
+
+	runs, err := h.artifactRepo.ListGCRuns(limit)
+	if err != nil {
+		http.Error(w, "Failed to list gc runs: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"items": runs,
+	})
+}
+
+// GetGCRun handles GET /v1/artifacts/gc/runs/{id}
+func (h *ArtifactGCHandler) GetGCRun(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid run ID", http.StatusBadRequest)
+		return
+	}
+
+	run, err := h.artifactRepo.GetGCRun(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "GC run not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to fetch gc run: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(run)
+}
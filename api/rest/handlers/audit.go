@@ -0,0 +1,372 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gpu-orchestrator/core/clock"
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/repository"
+
+	"github.com/gorilla/mux"
+)
+
+// Bounds of what the audit middleware captures per call
+const (
+	maxAuditBody     = 64 << 10 // Request bytes summarized; the rest is marked truncated
+	maxAuditResponse = 4 << 10  // Response bytes searched for the ID of a created resource
+)
+
+// redacted replaces secret values in audit summaries
+const redacted = "[REDACTED]"
+
+var (
+	// secretKey matches field names whose values are never logged
+	secretKey = regexp.MustCompile(`(?i)secret|token|password|passwd|credential|api_?key|access_?key|private_?key|authorization`)
+	// secretAssignment matches "key: value" and "key=value" lines of text
+	// payloads such as job spec YAML
+	secretAssignment = regexp.MustCompile(`(?im)^(\s*-?\s*["']?[\w.-]*(?:secret|token|password|passwd|credential|api_?key|access_?key|private_?key)[\w.-]*["']?\s*[:=]\s*)\S.*$`)
+)
+
+// actorKey is the request context key of the caller's audit identity
+type actorKey struct{}
+
+// requestActor returns the caller identified by the audit middleware
+func requestActor(r *http.Request) string {
+	if actor, ok := r.Context().Value(actorKey{}).(string); ok {
+		return actor
+	}
+	return models.ActorAnonymous
+}
+
+// AuditLog records every mutating API call in the append-only audit log and
+// serves the log to admins
+type AuditLog struct {
+	repo        *repository.AuditRepository
+	admin       *AdminAuth
+	actorHeader string // Set by an authenticating proxy to the caller's identity; empty = not trusted
+}
+
+// NewAuditLog creates the audit middleware. actorHeader names the header an
+// authenticating proxy sets to the caller's identity, e.g. X-Forwarded-User.
+func NewAuditLog(repo *repository.AuditRepository, admin *AdminAuth, actorHeader string) *AuditLog {
+	return &AuditLog{repo: repo, admin: admin, actorHeader: actorHeader}
+}
+
+// actor identifies the caller: the proxy's identity header, else admin for
+// the admin token, else anonymous
+func (a *AuditLog) actor(r *http.Request) string {
+	if a.actorHeader != "" {
+		if actor := strings.TrimSpace(r.Header.Get(a.actorHeader)); actor != "" {
+			return actor
+		}
+	}
+	if a.admin.IsAdmin(r) {
+		return models.ActorAdmin
+	}
+	return models.ActorAnonymous
+}
+
+// Middleware identifies the caller for handlers (see requestActor) and
+// records POST, PUT, PATCH and DELETE calls once they are served, whatever
+// their outcome
+func (a *AuditLog) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor := a.actor(r)
+		r = r.WithContext(context.WithValue(r.Context(), actorKey{}, actor))
+
+		switch r.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Keep the head of the body for the summary and hand the handler all of it
+		var body []byte
+		if r.Body != nil {
+			body, _ = io.ReadAll(io.LimitReader(r.Body, maxAuditBody+1))
+			r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+		}
+
+		recorder := &auditRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		template := r.URL.Path
+		if route := mux.CurrentRoute(r); route != nil {
+			if t, err := route.GetPathTemplate(); err == nil {
+				template = t
+			}
+		}
+		entry := &models.AuditEntry{
+			Actor:        actor,
+			Action:       r.Method + " " + template,
+			ResourceType: auditResourceType(template),
+			ResourceID:   auditResourceID(mux.Vars(r)),
+			Request:      summarizeRequest(r, body),
+			StatusCode:   recorder.status,
+			RemoteAddr:   r.RemoteAddr,
+		}
+		if entry.ResourceID == "" && recorder.status < 300 {
+			entry.ResourceID = createdResourceID(recorder.body.Bytes())
+		}
+		if err := a.repo.Record(entry); err != nil {
+			log.Printf("Failed to audit %s by %s: %v", entry.Action, actor, err)
+		}
+	})
+}
+
+// ListEntries handles GET /v1/audit (admin). Filters: actor, resource_type,
+// resource_id, since and until (RFC 3339) and limit; format=csv exports.
+func (a *AuditLog) ListEntries(w http.ResponseWriter, r *http.Request) {
+	if !a.admin.Allow(w, r) {
+		return
+	}
+
+	query := r.URL.Query()
+	format := query.Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "csv" {
+		http.Error(w, fmt.Sprintf("Unsupported format %q (supported: json, csv)", format), http.StatusBadRequest)
+		return
+	}
+
+	// Exports may page through more of the log at once
+	limit, maxLimit := 100, 1000
+	if format == "csv" {
+		limit, maxLimit = 10000, 10000
+	}
+	if v := query.Get("limit"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1 || parsed > maxLimit {
+			http.Error(w, fmt.Sprintf("limit must be between 1 and %d", maxLimit), http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	filter := models.AuditFilter{
+		Actor:        query.Get("actor"),
+		ResourceType: query.Get("resource_type"),
+		ResourceID:   query.Get("resource_id"),
+		Limit:        limit,
+	}
+	for param, dest := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if v := query.Get(param); v != "" {
+			parsed, err := clock.ParseRFC3339(v)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Invalid %s (want RFC 3339): %v", param, err), http.StatusBadRequest)
+				return
+			}
+			*dest = parsed
+		}
+	}
+
+	entries, err := a.repo.ListEntries(filter)
+	if err != nil {
+		http.Error(w, "Failed to fetch audit log: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="audit.csv"`)
+		writeAuditCSV(w, entries)
+		return
+	}
+
+	if entries == nil {
+		entries = []models.AuditEntry{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"entries": entries,
+		"count":   len(entries),
+	})
+}
+
+// writeAuditCSV writes entries as CSV, the request summary as JSON
+func writeAuditCSV(w io.Writer, entries []models.AuditEntry) {
+	out := csv.NewWriter(w)
+	out.Write([]string{"id", "created_at", "actor", "action", "resource_type", "resource_id", "status_code", "remote_addr", "request"})
+	for _, e := range entries {
+		request := ""
+		if e.Request != nil {
+			encoded, _ := json.Marshal(e.Request)
+			request = string(encoded)
+		}
+		status := ""
+		if e.StatusCode != 0 {
+			status = strconv.Itoa(e.StatusCode)
+		}
+		out.Write([]string{
+			strconv.FormatInt(e.ID, 10),
+			e.CreatedAt.UTC().Format(time.RFC3339),
+			e.Actor,
+			e.Action,
+			e.ResourceType,
+			e.ResourceID,
+			status,
+			e.RemoteAddr,
+			request,
+		})
+	}
+	out.Flush()
+	if err := out.Error(); err != nil {
+		log.Printf("Audit export aborted: %v", err)
+	}
+}
+
+// auditRecorder captures the status and the head of the body of a response
+type auditRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *auditRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Hijack hands the connection to WebSocket handlers; the call is audited as
+// switching protocols
+func (r *auditRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response does not support hijacking")
+	}
+	r.status = http.StatusSwitchingProtocols
+	return hijacker.Hijack()
+}
+
+// Flush passes flushes through so streamed responses (e.g. bulk submission
+// results) reach the client as they are written
+func (r *auditRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (r *auditRecorder) Write(p []byte) (int, error) {
+	if room := maxAuditResponse - r.body.Len(); room > 0 {
+		r.body.Write(p[:min(room, len(p))])
+	}
+	return r.ResponseWriter.Write(p)
+}
+
+// auditResourceType is the first path segment after /v1 (and /admin),
+// e.g. jobs for /v1/jobs/{id}/cancel
+func auditResourceType(template string) string {
+	segments := strings.Split(strings.TrimPrefix(template, "/v1/"), "/")
+	if len(segments) > 1 && segments[0] == "admin" {
+		segments = segments[1:]
+	}
+	return segments[0]
+}
+
+// auditResourceID is the route's resource ID variable, if any
+func auditResourceID(vars map[string]string) string {
+	for _, name := range []string{"id", "name"} {
+		if id := vars[name]; id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// createdResourceID returns the id or job_id of a JSON response, so calls
+// creating a resource are attributed to it
+func createdResourceID(response []byte) string {
+	var fields map[string]interface{}
+	if json.Unmarshal(response, &fields) != nil {
+		return ""
+	}
+	for _, name := range []string{"id", "job_id"} {
+		switch id := fields[name].(type) {
+		case string:
+			return id
+		case float64:
+			return strconv.FormatInt(int64(id), 10)
+		}
+	}
+	return ""
+}
+
+// summarizeRequest returns the query and body of a call with secret values
+// redacted. Bodies larger than maxAuditBody are marked truncated.
+func summarizeRequest(r *http.Request, body []byte) map[string]interface{} {
+	summary := make(map[string]interface{})
+	if len(r.URL.Query()) > 0 {
+		query := make(map[string]interface{})
+		for key, values := range r.URL.Query() {
+			query[key] = strings.Join(values, ",")
+		}
+		summary["query"] = redact(query)
+	}
+
+	if len(body) > maxAuditBody {
+		body = body[:maxAuditBody]
+		summary["truncated"] = true
+	}
+	var decoded interface{}
+	switch {
+	case len(body) == 0:
+	case json.Unmarshal(body, &decoded) == nil:
+		summary["body"] = redact(decoded)
+	case utf8.Valid(body):
+		summary["body"] = redactText(string(body))
+	default:
+		summary["body_bytes"] = len(body)
+	}
+
+	if len(summary) == 0 {
+		return nil
+	}
+	return summary
+}
+
+// redact replaces the values of secret-named fields, recursively, and
+// secret assignments inside string values (e.g. spec YAML)
+func redact(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, inner := range v {
+			if secretKey.MatchString(key) {
+				v[key] = redacted
+			} else {
+				v[key] = redact(inner)
+			}
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = redact(v[i])
+		}
+		return v
+	case string:
+		return redactText(v)
+	default:
+		return value
+	}
+}
+
+// redactText replaces the values of secret assignments in text
+func redactText(text string) string {
+	return secretAssignment.ReplaceAllString(text, "${1}"+redacted)
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// AdminAuth restricts operator endpoints to callers presenting the admin
+// token as "Authorization: Bearer <token>"
+type AdminAuth struct {
+	token string
+}
+
+// NewAdminAuth creates admin auth for the given token. An empty token
+// disables admin endpoints.
+func NewAdminAuth(token string) *AdminAuth {
+	return &AdminAuth{token: token}
+}
+
+// Allow reports whether the request is from an admin. Otherwise it writes
+// a 403 response.
+func (a *AdminAuth) Allow(w http.ResponseWriter, r *http.Request) bool {
+	if a == nil || a.token == "" {
+		http.Error(w, "Admin endpoints are disabled (ADMIN_API_TOKEN not set)", http.StatusForbidden)
+		return false
+	}
+
+	if !a.IsAdmin(r) {
+		http.Error(w, "Admin role required", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// IsAdmin reports whether the request presents the admin token
+func (a *AdminAuth) IsAdmin(r *http.Request) bool {
+	if a == nil || a.token == "" {
+		return false
+	}
+	presented, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(presented), []byte(a.token)) == 1
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/storage"
+
+	"github.com/gorilla/mux"
+)
+
+// CheckpointHandler serves a job's checkpoints and their replication state
+type CheckpointHandler struct {
+	jobRepo      *repository.JobRepository
+	artifactRepo *repository.ArtifactRepository
+	replicator   *storage.CheckpointReplicator
+}
+
+// NewCheckpointHandler creates a new checkpoint handler
+func NewCheckpointHandler(jobRepo *repository.JobRepository, artifactRepo *repository.ArtifactRepository, replicator *storage.CheckpointReplicator) *CheckpointHandler {
+	return &CheckpointHandler{jobRepo: jobRepo, artifactRepo: artifactRepo, replicator: replicator}
+}
+
+// checkpointView is a checkpoint with its replica, when the job replicates
+type checkpointView struct {
+	ID        int64                     `json:"id"`
+	URI       string                    `json:"uri"`
+	CreatedAt time.Time                 `json:"created_at"`
+	Meta      map[string]interface{}    `json:"meta,omitempty"`
+	Replica   *models.CheckpointReplica `json:"replica,omitempty"`
+}
+
+// GetJobCheckpoints handles GET /v1/jobs/{id}/checkpoints. Checkpoints are
+// listed newest first; jobs declaring checkpointing.replicate_to also get
+// each checkpoint's replica and a replication summary with the lag of the
+// oldest checkpoint not replicated yet.
+func (h *CheckpointHandler) GetJobCheckpoints(w http.ResponseWriter, r *http.Request) {
+	jobID := mux.Vars(r)["id"]
+
+	job, err := h.jobRepo.GetJob(jobID)
+	if err != nil {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+	checkpointType := models.ArtifactTypeCheckpoint
+	checkpoints, err := h.artifactRepo.GetJobArtifacts(jobID, &checkpointType)
+	if err != nil {
+		http.Error(w, "Failed to fetch checkpoints: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	replicas, replication := h.replicator.Replication(job, checkpoints)
+	views := make([]checkpointView, len(checkpoints))
+	for i, checkpoint := range checkpoints {
+		views[i] = checkpointView{
+			ID:        checkpoint.ID,
+			URI:       checkpoint.URI,
+			CreatedAt: checkpoint.CreatedAt,
+			Meta:      checkpoint.MetaJSON,
+		}
+		if replicas != nil {
+			views[i].Replica = &replicas[i]
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"job_id":      job.ID,
+		"checkpoints": views,
+		"replication": replication,
+	})
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/spec"
+
+	"github.com/gorilla/mux"
+)
+
+// compareFailureEvents bounds the events searched for a job's failure reason
+const compareFailureEvents = 200
+
+// CompareJobs handles GET /v1/jobs/{id}/compare?with={otherID}. It returns
+// what changed from job {id} to the other job: spec fields, placement, and
+// duration, cost and throughput deltas.
+func (h *JobHandler) CompareJobs(w http.ResponseWriter, r *http.Request) {
+	jobID := mux.Vars(r)["id"]
+	otherID := r.URL.Query().Get("with")
+	if otherID == "" {
+		http.Error(w, "Missing ?with=<job id> to compare against", http.StatusBadRequest)
+		return
+	}
+
+	base, err := h.jobRepo.GetJob(jobID)
+	if err != nil {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+	other, err := h.jobRepo.GetJob(otherID)
+	if err != nil {
+		http.Error(w, "Job "+otherID+" not found", http.StatusNotFound)
+		return
+	}
+	if !h.canReadJob(r, base) || !h.canReadJob(r, other) {
+		http.Error(w, "Admin role or ownership of both jobs required", http.StatusForbidden)
+		return
+	}
+
+	specChanges, err := spec.DiffSpecs(base.SpecYAML, other.SpecYAML)
+	if err != nil {
+		http.Error(w, "Failed to compare specs: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	now := time.Now()
+	baseSide, err := h.comparedJob(base, now)
+	if err != nil {
+		http.Error(w, "Failed to load job "+base.ID+": "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	otherSide, err := h.comparedJob(other, now)
+	if err != nil {
+		http.Error(w, "Failed to load job "+other.ID+": "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	comparison := models.JobComparison{
+		Base:             baseSide,
+		Other:            otherSide,
+		SameSpec:         len(specChanges) == 0,
+		SpecChanges:      specChanges,
+		PlacementChanges: models.DiffPlacements(baseSide.Placements, otherSide.Placements),
+		Outcome:          models.DiffOutcomes(baseSide.Outcome, otherSide.Outcome),
+	}
+	if comparison.SpecChanges == nil {
+		comparison.SpecChanges = []models.SpecChange{}
+	}
+	if comparison.PlacementChanges == nil {
+		comparison.PlacementChanges = []string{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(comparison)
+}
+
+// comparedJob collects one side of a comparison: the job's placement and
+// its outcome
+func (h *JobHandler) comparedJob(job *models.Job, now time.Time) (models.ComparedJob, error) {
+	allocations, err := h.allocationRepo.GetAllocationsByJobID(job.ID)
+	if err != nil {
+		return models.ComparedJob{}, err
+	}
+
+	failureReason := ""
+	if job.Status == models.JobStatusFailed {
+		events, err := h.eventRepo.GetJobEvents(job.ID, compareFailureEvents)
+		if err != nil {
+			return models.ComparedJob{}, err
+		}
+		// Newest first
+		for _, event := range events {
+			if event.ToStatus == models.JobStatusFailed {
+				failureReason = event.Reason
+				break
+			}
+		}
+	}
+
+	placements := models.SummarizePlacements(allocations)
+	if placements == nil {
+		placements = []models.PlacementSummary{}
+	}
+	return models.ComparedJob{
+		ID:         job.ID,
+		Name:       job.Name,
+		CreatedAt:  job.CreatedAt,
+		SpecHash:   job.SpecHash,
+		Placements: placements,
+		Outcome:    models.OutcomeOf(job, failureReason, now),
+	}, nil
+}
+
+// canReadJob reports whether the caller may read a job. Admins read every
+// job and callers identified by the authenticating proxy their own.
+// Anonymous callers read every job, as on the other read endpoints.
+func (h *JobHandler) canReadJob(r *http.Request, job *models.Job) bool {
+	actor := requestActor(r)
+	return actor == models.ActorAnonymous || actor == job.UserID || h.admin.IsAdmin(r)
+}
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"gpu-orchestrator/core/executor"
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/storage"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// consoleJobPoll is how often an attached console checks that its job is
+// still running
+const consoleJobPoll = 5 * time.Second
+
+// ShellOpener opens interactive shells on job nodes with the credentials of
+// their cluster
+type ShellOpener interface {
+	OpenShell(ctx context.Context, clusterID, host string) (executor.ShellSession, error)
+}
+
+// ConsoleConfig controls console attach
+type ConsoleConfig struct {
+	Enabled       bool
+	IdleTimeout   time.Duration // 0 = sessions never time out
+	TranscriptURI string        // Object storage prefix for transcripts; "" stores them inline
+}
+
+// ConsoleHandler attaches interactive consoles to running jobs
+type ConsoleHandler struct {
+	jobRepo      *repository.JobRepository
+	artifactRepo *repository.ArtifactRepository
+	admin        *AdminAuth
+	shells       ShellOpener
+	stores       *storage.Registry
+	cfg          ConsoleConfig
+}
+
+// NewConsoleHandler creates a new console handler
+func NewConsoleHandler(jobRepo *repository.JobRepository, artifactRepo *repository.ArtifactRepository, admin *AdminAuth, shells ShellOpener, stores *storage.Registry, cfg ConsoleConfig) *ConsoleHandler {
+	return &ConsoleHandler{
+		jobRepo:      jobRepo,
+		artifactRepo: artifactRepo,
+		admin:        admin,
+		shells:       shells,
+		stores:       stores,
+		cfg:          cfg,
+	}
+}
+
+// Attach handles POST /v1/jobs/{id}/attach (admin or job owner). It upgrades
+// to a WebSocket bridged to a shell on the job's primary (rank 0) node.
+// Sessions are read-only unless ?mode=readwrite. Every session is recorded in
+// the job's events and its keystrokes and output in a transcript artifact.
+func (h *ConsoleHandler) Attach(w http.ResponseWriter, r *http.Request) {
+	if !h.cfg.Enabled {
+		http.Error(w, "Console attach is disabled (CONSOLE_ATTACH_ENABLED not set)", http.StatusForbidden)
+		return
+	}
+	jobID := mux.Vars(r)["id"]
+
+	job, err := h.jobRepo.GetJob(jobID)
+	if err != nil {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+	actor := requestActor(r)
+	owner := actor != models.ActorAnonymous && actor == job.UserID
+	if !owner && !h.admin.IsAdmin(r) {
+		http.Error(w, "Admin role or job ownership required", http.StatusForbidden)
+		return
+	}
+
+	mode := r.URL.Query().Get("mode")
+	if mode == "" {
+		mode = executor.ConsoleReadOnly
+	}
+	if mode != executor.ConsoleReadOnly && mode != executor.ConsoleReadWrite {
+		http.Error(w, fmt.Sprintf("Invalid mode %q (supported: readonly, readwrite)", mode), http.StatusBadRequest)
+		return
+	}
+	if job.Status != models.JobStatusRunning {
+		http.Error(w, fmt.Sprintf("Job is %s; consoles attach to running jobs", job.Status), http.StatusConflict)
+		return
+	}
+
+	clusterID, host, err := h.primaryHost(jobID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	shell, err := h.shells.OpenShell(r.Context(), clusterID, host)
+	if err != nil {
+		http.Error(w, "Failed to open shell on the primary node: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	conn, err := upgradeWebSocket(w, r)
+	if err != nil {
+		shell.Close()
+		return
+	}
+	defer conn.Close()
+
+	sessionID := uuid.New().String()
+	h.recordEvent(job, "console_attached", map[string]interface{}{
+		"session_id": sessionID,
+		"actor":      actor,
+		"mode":       mode,
+		"host":       host,
+	})
+	log.Printf("Console %s attached to job %s (%s) by %s", sessionID, jobID, mode, actor)
+
+	// End the session when the job stops running
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go h.watchJob(ctx, jobID, cancel)
+
+	transcript := executor.NewConsoleTranscript()
+	started := time.Now()
+	result := executor.RunConsole(ctx, conn, shell, transcript, executor.ConsoleOptions{
+		Mode:        mode,
+		IdleTimeout: h.cfg.IdleTimeout,
+	})
+
+	meta := map[string]interface{}{
+		"session_id":       sessionID,
+		"actor":            actor,
+		"mode":             mode,
+		"host":             host,
+		"reason":           result.Reason,
+		"duration_seconds": int(time.Since(started).Seconds()),
+		"input_bytes":      result.InputBytes,
+		"rejected_bytes":   result.RejectedBytes,
+		"output_bytes":     result.OutputBytes,
+	}
+	h.storeTranscript(jobID, sessionID, transcript, meta)
+	h.recordEvent(job, "console_detached", meta)
+	log.Printf("Console %s of job %s closed: %s", sessionID, jobID, result.Reason)
+}
+
+// primaryHost returns the cluster ID and address of the rank 0 node of the
+// job's first cluster
+func (h *ConsoleHandler) primaryHost(jobID string) (string, string, error) {
+	manifest, err := h.jobRepo.GetTopology(jobID)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get topology: %w", err)
+	}
+	if manifest == nil || len(manifest.Clusters) == 0 || manifest.Clusters[0].MasterAddr == "" {
+		return "", "", fmt.Errorf("no primary node recorded for this job")
+	}
+	return manifest.Clusters[0].ClusterID, manifest.Clusters[0].MasterAddr, nil
+}
+
+// watchJob calls end once the job is no longer running
+func (h *ConsoleHandler) watchJob(ctx context.Context, jobID string, end context.CancelFunc) {
+	ticker := time.NewTicker(consoleJobPoll)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			job, err := h.jobRepo.GetJob(jobID)
+			if err != nil {
+				log.Printf("Console: failed to check job %s: %v", jobID, err)
+				continue
+			}
+			if job.Status != models.JobStatusRunning && job.Status != models.JobStatusCheckpointing {
+				end()
+				return
+			}
+		}
+	}
+}
+
+// storeTranscript uploads a session's transcript and records it as an
+// artifact; without a transcript prefix (or when the upload fails) it is
+// stored inline in the artifact's meta
+func (h *ConsoleHandler) storeTranscript(jobID, sessionID string, transcript *executor.ConsoleTranscript, meta map[string]interface{}) {
+	data, truncated := transcript.Bytes()
+	artifactMeta := make(map[string]interface{}, len(meta)+2)
+	for key, value := range meta {
+		artifactMeta[key] = value
+	}
+	if truncated {
+		artifactMeta["truncated"] = true
+	}
+
+	uri := ""
+	if h.cfg.TranscriptURI != "" && h.stores != nil {
+		target := fmt.Sprintf("%s/%s/%s.jsonl", h.cfg.TranscriptURI, jobID, sessionID)
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		err := h.stores.Put(ctx, target, bytes.NewReader(data), int64(len(data)), "application/x-ndjson")
+		cancel()
+		if err != nil {
+			log.Printf("Failed to upload console transcript %s of job %s, storing it inline: %v", sessionID, jobID, err)
+		} else {
+			uri = target
+		}
+	}
+	if uri == "" {
+		uri = "inline:console/" + sessionID
+		artifactMeta["transcript"] = string(data)
+	}
+
+	if err := h.artifactRepo.CreateArtifact(jobID, models.ArtifactTypeConsole, uri, artifactMeta); err != nil {
+		log.Printf("Failed to record console transcript %s of job %s: %v", sessionID, jobID, err)
+	}
+}
+
+// recordEvent records a console event in the job's history
+func (h *ConsoleHandler) recordEvent(job *models.Job, reason string, meta map[string]interface{}) {
+	status := job.Status
+	if err := h.jobRepo.CreateJobEvent(job.ID, &status, status, reason, meta); err != nil {
+		log.Printf("Failed to record %s for job %s: %v", reason, job.ID, err)
+	}
+}
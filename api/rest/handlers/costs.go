@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"gpu-orchestrator/core/monitoring"
+	"gpu-orchestrator/core/repository"
+
+	"github.com/gorilla/mux"
+)
+
+// CostHandler serves billing exports for finance ingestion and per-job costs
+type CostHandler struct {
+	exporter           *monitoring.BillingExporter
+	jobRepo            *repository.JobRepository
+	defaultDestination string // Object storage prefix for async exports without a destination
+}
+
+// NewCostHandler creates a new cost handler
+func NewCostHandler(exporter *monitoring.BillingExporter, jobRepo *repository.JobRepository, defaultDestination string) *CostHandler {
+	return &CostHandler{
+		exporter:           exporter,
+		jobRepo:            jobRepo,
+		defaultDestination: defaultDestination,
+	}
+}
+
+// GetJobCost handles GET /v1/jobs/{id}/cost.
+// Splits the job's cost into compute over its running window and
+// infrastructure from instance launch to termination, and reconciles the
+// infrastructure cost with the estimate and the transfer cost with the
+// allocation decision.
+func (h *CostHandler) GetJobCost(w http.ResponseWriter, r *http.Request) {
+	job, err := h.jobRepo.GetJob(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	cost, err := h.exporter.JobCost(job)
+	if err != nil {
+		http.Error(w, "Failed to compute job cost: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	decision, err := h.jobRepo.GetAllocationDecision(job.ID)
+	if err != nil {
+		http.Error(w, "Failed to get allocation decision: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	monitoring.ReconcileTransfer(cost, decision)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cost)
+}
+
+// ExportCosts handles GET /v1/costs/export?period=2024-05&format=csv.
+// Streams one row per job-allocation-day.
+func (h *CostHandler) ExportCosts(w http.ResponseWriter, r *http.Request) {
+	period := r.URL.Query().Get("period")
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = monitoring.BillingFormatCSV
+	}
+
+	if _, _, err := monitoring.ParseBillingPeriod(period); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if format != monitoring.BillingFormatCSV {
+		writeUnsupportedFormat(w, format)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="billing-%s.%s"`, period, format))
+
+	// Headers are sent with the first row, so later failures can only be logged
+	rows, err := h.exporter.Write(r.Context(), w, period, format)
+	if err != nil {
+		log.Printf("Billing export for %s aborted after %d rows: %v", period, rows, err)
+	}
+}
+
+// StartCostExportRequest is the body of POST /v1/costs/exports
+type StartCostExportRequest struct {
+	Period      string `json:"period"`                // YYYY-MM
+	Format      string `json:"format,omitempty"`      // Default: csv
+	Destination string `json:"destination,omitempty"` // Object storage prefix; default COST_EXPORT_URI
+}
+
+// StartCostExport handles POST /v1/costs/exports.
+// Writes the export to object storage in the background for very large periods.
+func (h *CostHandler) StartCostExport(w http.ResponseWriter, r *http.Request) {
+	var req StartCostExportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Format == "" {
+		req.Format = monitoring.BillingFormatCSV
+	}
+	if req.Destination == "" {
+		req.Destination = h.defaultDestination
+	}
+	if req.Destination == "" {
+		http.Error(w, "destination is required (no COST_EXPORT_URI configured)", http.StatusBadRequest)
+		return
+	}
+
+	export, err := h.exporter.StartExport(req.Period, req.Format, req.Destination)
+	if errors.Is(err, monitoring.ErrUnsupportedBillingFormat) {
+		writeUnsupportedFormat(w, req.Format)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to start export: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(export)
+}
+
+// GetCostExport handles GET /v1/costs/exports/{id}
+func (h *CostHandler) GetCostExport(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	export, ok := h.exporter.GetExport(vars["id"])
+	if !ok {
+		http.Error(w, "Export not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(export)
+}
+
+// writeUnsupportedFormat rejects export formats this build cannot encode
+func writeUnsupportedFormat(w http.ResponseWriter, format string) {
+	status := http.StatusBadRequest
+	if format == monitoring.BillingFormatParquet {
+		status = http.StatusNotImplemented
+	}
+	http.Error(w, fmt.Sprintf("Unsupported export format %q (supported: %s)", format, monitoring.BillingFormatCSV), status)
+}
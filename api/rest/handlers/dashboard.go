@@ -1,11 +1,14 @@
 package handlers
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
+	"gpu-orchestrator/core/clock"
 	"gpu-orchestrator/core/models"
 	"gpu-orchestrator/core/monitoring"
 	"gpu-orchestrator/core/repository"
@@ -16,6 +19,8 @@ type DashboardHandler struct {
 	jobRepo     *repository.JobRepository
 	costTracker *monitoring.CostTracker
 	clusterPool interface{} // TODO: Add cluster pool interface
+
+	experimentRepo *repository.ExperimentRepository // Optional; enables experiment rollups
 }
 
 // NewDashboardHandler creates a new dashboard handler
@@ -29,6 +34,11 @@ func NewDashboardHandler(
 	}
 }
 
+// SetExperimentRepository enables experiment cost rollups and filters
+func (h *DashboardHandler) SetExperimentRepository(experimentRepo *repository.ExperimentRepository) {
+	h.experimentRepo = experimentRepo
+}
+
 // GetCostMetrics returns cost metrics for dashboard
 func (h *DashboardHandler) GetCostMetrics(w http.ResponseWriter, r *http.Request) {
 	// Get query parameters
@@ -40,24 +50,24 @@ func (h *DashboardHandler) GetCostMetrics(w http.ResponseWriter, r *http.Request
 	var start, end time.Time
 	if startDate != "" {
 		var err error
-		start, err = time.Parse(time.RFC3339, startDate)
+		start, err = clock.ParseRFC3339(startDate)
 		if err != nil {
 			http.Error(w, "Invalid start_date format", http.StatusBadRequest)
 			return
 		}
 	} else {
-		start = time.Now().AddDate(0, 0, -30)
+		start = clock.System.Now().AddDate(0, 0, -30)
 	}
 
 	if endDate != "" {
 		var err error
-		end, err = time.Parse(time.RFC3339, endDate)
+		end, err = clock.ParseRFC3339(endDate)
 		if err != nil {
 			http.Error(w, "Invalid end_date format", http.StatusBadRequest)
 			return
 		}
 	} else {
-		end = time.Now()
+		end = clock.System.Now()
 	}
 
 	// Get jobs in date range
@@ -80,7 +90,7 @@ func (h *DashboardHandler) GetCostMetrics(w http.ResponseWriter, r *http.Request
 			continue
 		}
 
-		if job.Status == models.JobStatusRunning {
+		if job.Status.IsRunning() {
 			runningJobs++
 			runningCost += h.costTracker.GetRunningCost(job.ID)
 		}
@@ -111,11 +121,36 @@ func (h *DashboardHandler) GetCostMetrics(w http.ResponseWriter, r *http.Request
 		},
 	}
 
+	// Cost and emissions per team over the period
+	teams, err := h.jobRepo.TeamCostRollup(start, end)
+	if err != nil {
+		http.Error(w, "Failed to fetch team costs: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if teams == nil {
+		teams = []models.TeamCost{}
+	}
+	response["teams"] = teams
+
+	// All-time cost per experiment
+	if h.experimentRepo != nil {
+		experiments, err := h.experimentRepo.CostRollup()
+		if err != nil {
+			http.Error(w, "Failed to fetch experiment costs: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if experiments == nil {
+			experiments = []models.ExperimentCost{}
+		}
+		response["experiments"] = experiments
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
-// GetJobCosts returns cost breakdown by job
+// GetJobCosts returns cost breakdown by job. The experiment parameter (name
+// or ID) limits it to the experiment's jobs.
 func (h *DashboardHandler) GetJobCosts(w http.ResponseWriter, r *http.Request) {
 	userID := r.URL.Query().Get("user_id")
 	limit := 50
@@ -123,6 +158,11 @@ func (h *DashboardHandler) GetJobCosts(w http.ResponseWriter, r *http.Request) {
 		fmt.Sscanf(limitParam, "%d", &limit)
 	}
 
+	if experiment := r.URL.Query().Get("experiment"); experiment != "" {
+		h.getExperimentJobCosts(w, experiment)
+		return
+	}
+
 	jobs, _, err := h.jobRepo.ListJobs(userID, nil, limit, "")
 	if err != nil {
 		http.Error(w, "Failed to fetch jobs: "+err.Error(), http.StatusInternalServerError)
@@ -137,11 +177,50 @@ func (h *DashboardHandler) GetJobCosts(w http.ResponseWriter, r *http.Request) {
 		}
 
 		jobCosts = append(jobCosts, map[string]interface{}{
-			"job_id":     job.ID,
-			"name":       job.Name,
-			"status":     job.Status,
-			"cost_usd":   cost,
-			"created_at": job.CreatedAt,
+			"job_id":          job.ID,
+			"name":            job.Name,
+			"status":          job.Status,
+			"cost_usd":        cost,
+			"emissions_gco2e": job.EmissionsGCO2e,
+			"created_at":      job.CreatedAt,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"items": jobCosts,
+	})
+}
+
+// getExperimentJobCosts writes the cost breakdown of an experiment's jobs
+func (h *DashboardHandler) getExperimentJobCosts(w http.ResponseWriter, nameOrID string) {
+	if h.experimentRepo == nil {
+		http.Error(w, "Experiments are not enabled", http.StatusBadRequest)
+		return
+	}
+	experiment, err := h.experimentRepo.Resolve(nameOrID)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "Experiment not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to fetch experiment: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	members, err := h.experimentRepo.ListMembers(experiment.ID)
+	if err != nil {
+		http.Error(w, "Failed to fetch experiment jobs: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	jobCosts := make([]map[string]interface{}, 0, len(members))
+	for _, member := range members {
+		jobCosts = append(jobCosts, map[string]interface{}{
+			"job_id":        member.JobID,
+			"name":          member.Name,
+			"status":        member.Status,
+			"cost_usd":      member.CostUSD,
+			"experiment_id": experiment.ID,
 		})
 	}
 
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gpu-orchestrator/core/optimizer"
+
+	"github.com/gorilla/mux"
+)
+
+// DataGravityHandler reports where each team's data lives
+type DataGravityHandler struct {
+	gravity *optimizer.DataGravity // nil when data gravity is disabled
+}
+
+// NewDataGravityHandler creates a new data gravity handler
+func NewDataGravityHandler(gravity *optimizer.DataGravity) *DataGravityHandler {
+	return &DataGravityHandler{gravity: gravity}
+}
+
+// GetTeamDataGravity handles GET /v1/teams/{id}/data-gravity.
+// Returns the team's regions scored by their share of its recency-weighted
+// datasets, checkpoints and completed jobs, and the bonus the optimizer gives
+// a strategy placed entirely in a region with score 1.
+func (h *DataGravityHandler) GetTeamDataGravity(w http.ResponseWriter, r *http.Request) {
+	teamID := mux.Vars(r)["id"]
+
+	if h.gravity == nil {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"team_id": teamID,
+			"enabled": false,
+		})
+		return
+	}
+
+	profile, err := h.gravity.Profile(teamID)
+	if err != nil {
+		http.Error(w, "Failed to compute data gravity: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"team_id":        teamID,
+		"enabled":        true,
+		"max_bonus":      optimizer.DataGravityBonus,
+		"half_life_days": profile.HalfLifeDays,
+		"regions":        profile.Regions,
+		"computed_at":    profile.ComputedAt,
+	})
+}
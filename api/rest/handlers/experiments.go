@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/repository"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// ExperimentHandler handles experiment requests
+type ExperimentHandler struct {
+	jobRepo        *repository.JobRepository
+	experimentRepo *repository.ExperimentRepository
+}
+
+// NewExperimentHandler creates a new experiment handler
+func NewExperimentHandler(jobRepo *repository.JobRepository, experimentRepo *repository.ExperimentRepository) *ExperimentHandler {
+	return &ExperimentHandler{jobRepo: jobRepo, experimentRepo: experimentRepo}
+}
+
+// CreateExperimentRequest creates an experiment
+type CreateExperimentRequest struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+}
+
+// CreateExperiment handles POST /v1/experiments
+func (h *ExperimentHandler) CreateExperiment(w http.ResponseWriter, r *http.Request) {
+	var req CreateExperimentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	}
+	if _, err := uuid.Parse(req.Name); err == nil {
+		// Jobs reference experiments by name or ID
+		http.Error(w, "name must not be a UUID", http.StatusBadRequest)
+		return
+	}
+
+	experiment := &models.Experiment{Name: req.Name, Description: req.Description, Labels: req.Labels}
+	err := h.experimentRepo.Create(experiment)
+	if errors.Is(err, repository.ErrExperimentExists) {
+		http.Error(w, "Experiment "+req.Name+" already exists", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to create experiment: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(experiment)
+}
+
+// ListExperiments handles GET /v1/experiments
+func (h *ExperimentHandler) ListExperiments(w http.ResponseWriter, r *http.Request) {
+	experiments, err := h.experimentRepo.List()
+	if err != nil {
+		http.Error(w, "Failed to fetch experiments: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if experiments == nil {
+		experiments = []models.Experiment{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"items": experiments,
+	})
+}
+
+// GetExperiment handles GET /v1/experiments/{id} (ID or name). It aggregates
+// the member jobs' statuses, cost, GPU-hours and completed run durations.
+func (h *ExperimentHandler) GetExperiment(w http.ResponseWriter, r *http.Request) {
+	experiment, ok := h.resolve(w, r)
+	if !ok {
+		return
+	}
+
+	members, err := h.experimentRepo.ListMembers(experiment.ID)
+	if err != nil {
+		http.Error(w, "Failed to fetch experiment jobs: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.SummarizeExperiment(*experiment, members, time.Now()))
+}
+
+// DeleteExperiment handles DELETE /v1/experiments/{id}. Member jobs are
+// detached, not deleted.
+func (h *ExperimentHandler) DeleteExperiment(w http.ResponseWriter, r *http.Request) {
+	experiment, ok := h.resolve(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.experimentRepo.Delete(experiment.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "Experiment not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to delete experiment: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CancelExperiment handles POST /v1/experiments/{id}/cancel. Every
+// non-terminal member job is cancelled; jobs that finish meanwhile are skipped.
+func (h *ExperimentHandler) CancelExperiment(w http.ResponseWriter, r *http.Request) {
+	experiment, ok := h.resolve(w, r)
+	if !ok {
+		return
+	}
+
+	members, err := h.experimentRepo.ListMembers(experiment.ID)
+	if err != nil {
+		http.Error(w, "Failed to fetch experiment jobs: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	cancelled := []string{}
+	failed := map[string]string{}
+	for _, member := range members {
+		if member.Status.Terminal() {
+			continue
+		}
+		_, err := cancelJob(h.jobRepo, member.JobID, "experiment_cancelled", requestActor(r), 0)
+		if errors.Is(err, errJobTerminal) {
+			continue
+		}
+		if err != nil {
+			failed[member.JobID] = err.Error()
+			continue
+		}
+		cancelled = append(cancelled, member.JobID)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":        experiment.ID,
+		"cancelled": cancelled,
+		"failed":    failed,
+	})
+}
+
+// resolve loads the experiment named by the {id} route variable, writing a
+// 404 if there is none
+func (h *ExperimentHandler) resolve(w http.ResponseWriter, r *http.Request) (*models.Experiment, bool) {
+	experiment, err := h.experimentRepo.Resolve(mux.Vars(r)["id"])
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "Experiment not found", http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		http.Error(w, "Failed to fetch experiment: "+err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	return experiment, true
+}
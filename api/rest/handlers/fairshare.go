@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gpu-orchestrator/core/scheduler"
+)
+
+// FairShareHandler reports per-team fair shares and usage
+type FairShareHandler struct {
+	fairShare *scheduler.FairShare // nil when fair share is disabled
+}
+
+// NewFairShareHandler creates a new fair-share handler
+func NewFairShareHandler(fairShare *scheduler.FairShare) *FairShareHandler {
+	return &FairShareHandler{fairShare: fairShare}
+}
+
+// GetFairShare handles GET /v1/fairshare.
+// Usage is re-read first, since only the leader refreshes it in the background.
+func (h *FairShareHandler) GetFairShare(w http.ResponseWriter, r *http.Request) {
+	if h.fairShare == nil {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"enabled": false,
+		})
+		return
+	}
+
+	if err := h.fairShare.Refresh(); err != nil {
+		http.Error(w, "Failed to compute fair share: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"enabled":   true,
+		"fairshare": h.fairShare.Report(),
+	})
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/repository"
+)
+
+// FleetHandler exposes stored allocations across jobs
+type FleetHandler struct {
+	allocationRepo  *repository.AllocationRepository
+	maintenanceRepo *repository.MaintenanceRepository
+}
+
+// NewFleetHandler creates a new fleet handler
+func NewFleetHandler(allocationRepo *repository.AllocationRepository, maintenanceRepo *repository.MaintenanceRepository) *FleetHandler {
+	return &FleetHandler{allocationRepo: allocationRepo, maintenanceRepo: maintenanceRepo}
+}
+
+// ListAllocations handles GET /v1/fleet/allocations. ?status= filters by
+// allocation status and ?orphaned=true keeps active allocations of jobs that
+// are no longer provisioning or running. ?maintenance=true keeps allocations
+// with nodes under pending scheduled maintenance.
+func (h *FleetHandler) ListAllocations(w http.ResponseWriter, r *http.Request) {
+	status := models.AllocationStatus(r.URL.Query().Get("status"))
+	switch status {
+	case "", models.AllocationPlanned, models.AllocationProvisioning, models.AllocationActive, models.AllocationFailed, models.AllocationTerminated:
+	default:
+		http.Error(w, "Invalid status: "+string(status), http.StatusBadRequest)
+		return
+	}
+	orphaned := r.URL.Query().Get("orphaned") == "true"
+	maintenanceOnly := r.URL.Query().Get("maintenance") == "true"
+
+	limit := 200
+	if v := r.URL.Query().Get("limit"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1 || parsed > 1000 {
+			http.Error(w, "limit must be between 1 and 1000", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	fleet, err := h.allocationRepo.ListFleetAllocations(status, orphaned, limit)
+	if err != nil {
+		http.Error(w, "Failed to fetch allocations: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	maintenance, err := h.maintenanceRepo.ListPendingMaintenance(time.Now())
+	if err != nil {
+		http.Error(w, "Failed to fetch maintenance events: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	fleet = withMaintenance(fleet, maintenance, maintenanceOnly)
+	if fleet == nil {
+		fleet = []models.FleetAllocation{}
+	}
+
+	counts := make(map[models.AllocationStatus]int)
+	requested, provisioned, orphans, underMaintenance := 0, 0, 0, 0
+	for _, entry := range fleet {
+		counts[entry.Allocation.Status]++
+		requested += entry.Allocation.Count
+		provisioned += entry.Allocation.ProvisionedCount
+		if entry.Orphaned {
+			orphans++
+		}
+		if len(entry.Maintenance) > 0 {
+			underMaintenance++
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"items":                 fleet,
+		"status_counts":         counts,
+		"requested_instances":   requested,
+		"provisioned_instances": provisioned,
+		"orphaned":              orphans,
+		"pending_maintenance":   underMaintenance,
+	})
+}
+
+// withMaintenance attaches pending maintenance events to the active
+// allocations whose nodes they affect; only keeps those when onlyAffected
+func withMaintenance(fleet []models.FleetAllocation, events []models.MaintenanceEvent, onlyAffected bool) []models.FleetAllocation {
+	var kept []models.FleetAllocation
+	for _, entry := range fleet {
+		alloc := entry.Allocation
+		if alloc.Status == models.AllocationActive {
+			for _, event := range events {
+				if event.JobID == entry.JobID && event.Provider == alloc.Provider && event.Region == alloc.Region && event.InstanceType == alloc.InstanceType {
+					entry.Maintenance = append(entry.Maintenance, event)
+				}
+			}
+		}
+		if !onlyAffected || len(entry.Maintenance) > 0 {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
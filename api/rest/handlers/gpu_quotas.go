@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/core/scheduler"
+
+	"github.com/gorilla/mux"
+)
+
+// GPUQuotaHandler reports and manages monthly GPU-hour quotas
+type GPUQuotaHandler struct {
+	repo   *repository.GPUQuotaRepository
+	quotas *scheduler.GPUQuotas
+	admin  *AdminAuth
+}
+
+// NewGPUQuotaHandler creates a new GPU-hour quota handler
+func NewGPUQuotaHandler(repo *repository.GPUQuotaRepository, quotas *scheduler.GPUQuotas, admin *AdminAuth) *GPUQuotaHandler {
+	return &GPUQuotaHandler{repo: repo, quotas: quotas, admin: admin}
+}
+
+// ListQuotas handles GET /v1/gpu-quotas. Every quota's current month is
+// returned with its GPU-hours next to the dollars the same jobs cost.
+func (h *GPUQuotaHandler) ListQuotas(w http.ResponseWriter, r *http.Request) {
+	report, err := h.quotas.Report()
+	if err != nil {
+		http.Error(w, "Failed to compute GPU-hour quotas: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"items": report,
+	})
+}
+
+// PutQuotaRequest is the body of PUT /v1/admin/gpu-quotas/{scope}/{id}
+type PutQuotaRequest struct {
+	MonthlyHours      float64 `json:"monthly_hours"`
+	CarryOver         string  `json:"carry_over,omitempty"`           // none (default) or unused
+	CarryOverMaxHours float64 `json:"carry_over_max_hours,omitempty"` // 0 = no cap
+}
+
+// PutQuota handles PUT /v1/admin/gpu-quotas/{scope}/{id} (admin). It creates
+// or replaces the quota of a team or project.
+func (h *GPUQuotaHandler) PutQuota(w http.ResponseWriter, r *http.Request) {
+	if !h.admin.Allow(w, r) {
+		return
+	}
+	scope, scopeID, ok := quotaScope(w, r)
+	if !ok {
+		return
+	}
+	var req PutQuotaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	carryOver := models.GPUQuotaCarryOver(req.CarryOver)
+	if carryOver == "" {
+		carryOver = models.CarryOverNone
+	}
+	if carryOver != models.CarryOverNone && carryOver != models.CarryOverUnused {
+		http.Error(w, "carry_over must be none or unused", http.StatusBadRequest)
+		return
+	}
+	if req.MonthlyHours < 0 || req.CarryOverMaxHours < 0 {
+		http.Error(w, "monthly_hours and carry_over_max_hours must not be negative", http.StatusBadRequest)
+		return
+	}
+
+	quota := &models.GPUQuota{
+		Scope:             scope,
+		ScopeID:           scopeID,
+		MonthlyHours:      req.MonthlyHours,
+		CarryOver:         carryOver,
+		CarryOverMaxHours: req.CarryOverMaxHours,
+	}
+	if err := h.repo.UpsertQuota(quota); err != nil {
+		http.Error(w, "Failed to save GPU-hour quota: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.quotas.Invalidate()
+	log.Printf("Set %s %s GPU-hour quota to %.1f hours a month (by %s)", scope, scopeID, quota.MonthlyHours, requestActor(r))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(quota)
+}
+
+// DeleteQuota handles DELETE /v1/admin/gpu-quotas/{scope}/{id} (admin)
+func (h *GPUQuotaHandler) DeleteQuota(w http.ResponseWriter, r *http.Request) {
+	if !h.admin.Allow(w, r) {
+		return
+	}
+	scope, scopeID, ok := quotaScope(w, r)
+	if !ok {
+		return
+	}
+	if err := h.repo.DeleteQuota(scope, scopeID); errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "GPU-hour quota not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, "Failed to delete GPU-hour quota: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.quotas.Invalidate()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AdjustQuotaRequest is the body of POST /v1/admin/gpu-quotas/{scope}/{id}/adjustments
+type AdjustQuotaRequest struct {
+	Hours  float64 `json:"hours"` // Negative takes hours away
+	Reason string  `json:"reason"`
+}
+
+// AdjustQuota handles POST /v1/admin/gpu-quotas/{scope}/{id}/adjustments
+// (admin). The hours are added to the quota's current month only; the caller
+// and reason are kept with the adjustment.
+func (h *GPUQuotaHandler) AdjustQuota(w http.ResponseWriter, r *http.Request) {
+	if !h.admin.Allow(w, r) {
+		return
+	}
+	scope, scopeID, ok := quotaScope(w, r)
+	if !ok {
+		return
+	}
+	var req AdjustQuotaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Hours == 0 || req.Reason == "" {
+		http.Error(w, "hours and reason are required", http.StatusBadRequest)
+		return
+	}
+
+	quota, err := h.repo.GetQuota(scope, scopeID)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "GPU-hour quota not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, "Failed to get GPU-hour quota: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	adjustment, err := h.quotas.Adjust(quota, req.Hours, req.Reason, requestActor(r))
+	if err != nil {
+		http.Error(w, "Failed to adjust GPU-hour quota: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Adjusted %s %s GPU-hour quota by %.1f hours: %s (by %s)", scope, scopeID, req.Hours, req.Reason, adjustment.Actor)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(adjustment)
+}
+
+// quotaScope reads the scope and scope ID of a quota route
+func quotaScope(w http.ResponseWriter, r *http.Request) (models.GPUQuotaScope, string, bool) {
+	vars := mux.Vars(r)
+	scope := models.GPUQuotaScope(vars["scope"])
+	if scope != models.GPUQuotaTeam && scope != models.GPUQuotaProject {
+		http.Error(w, "scope must be team or project", http.StatusBadRequest)
+		return "", "", false
+	}
+	return scope, vars["id"], true
+}
@@ -0,0 +1,384 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/optimizer"
+	"gpu-orchestrator/core/spec"
+
+	"github.com/google/uuid"
+)
+
+// Default bulk submission limits; see SetBatchLimits
+const (
+	defaultBatchMaxItems    = 500
+	defaultBatchConcurrency = 8
+)
+
+// Batch item outcomes
+const (
+	BatchItemCreated = "created" // Job created and enqueued
+	BatchItemInvalid = "invalid" // Rejected by validation; see error
+	BatchItemFailed  = "failed"  // Validated but could not be stored
+	BatchItemSkipped = "skipped" // Not created because the atomic batch stopped
+)
+
+// BatchSubmitRequest is the body of POST /v1/jobs/batch. Items either carry
+// a full spec or fill the shared template's {{NAME}} placeholders.
+type BatchSubmitRequest struct {
+	Template       string      `json:"template,omitempty"`        // Spec with {{NAME}} placeholders
+	Experiment     string      `json:"experiment,omitempty"`      // For every item; default an implicit batch experiment
+	Atomic         bool        `json:"atomic,omitempty"`          // Create nothing unless every item validates
+	AllowDuplicate bool        `json:"allow_duplicate,omitempty"` // Submit even if an identical spec is still active
+	Items          []BatchItem `json:"items"`
+}
+
+// BatchItem is one submission of a batch
+type BatchItem struct {
+	Name       string            `json:"name"`
+	SpecYAML   string            `json:"spec_yaml,omitempty"`  // Full spec; the template is not used
+	Params     map[string]string `json:"params,omitempty"`     // Template parameters
+	Experiment string            `json:"experiment,omitempty"` // Overrides the batch's and the spec's
+}
+
+// BatchItemResult is the outcome of one item, streamed as it completes
+type BatchItemResult struct {
+	Index        int                          `json:"index"`
+	Name         string                       `json:"name,omitempty"`
+	Status       string                       `json:"status"`
+	ID           string                       `json:"id,omitempty"`
+	Error        string                       `json:"error,omitempty"`
+	Detail       map[string]interface{}       `json:"detail,omitempty"` // e.g. the policy denial or infeasibility
+	Warnings     []optimizer.AdmissionProblem `json:"warnings,omitempty"`
+	SpecWarnings []string                     `json:"spec_warnings,omitempty"`
+}
+
+// SetBatchLimits sets how many items a bulk submission may hold and how
+// many of them are validated and stored at once
+func (h *JobHandler) SetBatchLimits(maxItems, concurrency int) {
+	h.batchMaxItems = maxItems
+	h.batchConcurrency = concurrency
+}
+
+// SubmitBatch handles POST /v1/jobs/batch. Every item is validated before any
+// is created; ?atomic=true (or "atomic" in the body) creates nothing unless
+// all of them validate, answering 422 with every item's result. Otherwise the
+// response is NDJSON: a header line with the batch ID, one line per item as
+// it completes and a closing summary line.
+func (h *JobHandler) SubmitBatch(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeBatch(r.Body)
+	if err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if r.URL.Query().Get("atomic") == "true" {
+		req.Atomic = true
+	}
+	maxItems := h.batchMaxItems
+	if maxItems <= 0 {
+		maxItems = defaultBatchMaxItems
+	}
+	if len(req.Items) == 0 || len(req.Items) > maxItems {
+		http.Error(w, fmt.Sprintf("A batch needs between 1 and %d items", maxItems), http.StatusBadRequest)
+		return
+	}
+
+	batchID := uuid.New().String()
+	results := make([]BatchItemResult, len(req.Items))
+	admitted := make([]*admission, len(req.Items))
+	h.forEachBatchItem(len(req.Items), func(i int) {
+		results[i] = BatchItemResult{Index: i, Name: req.Items[i].Name}
+		job, rejection := h.validateBatchItem(r, req, req.Items[i], batchID)
+		if rejection != nil {
+			results[i].Status, results[i].Error, results[i].Detail = BatchItemInvalid, rejection.Message, rejection.Body
+			return
+		}
+		admitted[i] = job
+	})
+	if !req.AllowDuplicate {
+		rejectBatchDuplicates(req.Items, admitted, results)
+	}
+
+	invalid := 0
+	for i := range results {
+		if admitted[i] == nil {
+			invalid++
+		}
+	}
+	if req.Atomic && invalid > 0 {
+		for i := range results {
+			if admitted[i] != nil {
+				results[i].Status = BatchItemSkipped
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "batch_invalid",
+			"message": fmt.Sprintf("%d of %d items failed validation; nothing was created", invalid, len(results)),
+			"items":   results,
+		})
+		return
+	}
+
+	experimentID := h.batchExperiment(req, admitted, batchID)
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	stream := newBatchStream(w)
+	header := map[string]interface{}{"batch_id": batchID, "items": len(results), "atomic": req.Atomic}
+	if experimentID != "" {
+		header["experiment_id"] = experimentID
+	}
+	stream.write(header)
+	for i := range results {
+		if admitted[i] == nil {
+			stream.write(results[i])
+		}
+	}
+
+	// Stored in parallel; an atomic batch stops creating at the first failure
+	var mu sync.Mutex
+	stopped := false
+	counts := map[string]int{BatchItemInvalid: invalid}
+	h.forEachBatchItem(len(results), func(i int) {
+		if admitted[i] == nil {
+			return
+		}
+		result := results[i]
+		mu.Lock()
+		skip := stopped
+		mu.Unlock()
+		if skip {
+			result.Status = BatchItemSkipped
+		} else if err := h.create(admitted[i]); err != nil {
+			result.Status, result.Error = BatchItemFailed, "Failed to create job: "+err.Error()
+		} else {
+			job := admitted[i].job
+			result.Status, result.ID = BatchItemCreated, job.ID
+			result.Warnings, result.SpecWarnings = admitted[i].warnings, job.SpecWarnings
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if result.Status == BatchItemFailed && req.Atomic {
+			stopped = true
+		}
+		counts[result.Status]++
+		stream.write(result)
+	})
+	log.Printf("Batch %s: created %d of %d jobs", batchID, counts[BatchItemCreated], len(results))
+
+	stream.write(map[string]interface{}{
+		"batch_id": batchID,
+		"done":     true,
+		"created":  counts[BatchItemCreated],
+		"invalid":  counts[BatchItemInvalid],
+		"failed":   counts[BatchItemFailed],
+		"skipped":  counts[BatchItemSkipped],
+	})
+}
+
+// validateBatchItem parses an item's spec and admits it like a single
+// submission, without storing it
+func (h *JobHandler) validateBatchItem(r *http.Request, req *BatchSubmitRequest, item BatchItem, batchID string) (*admission, *submitError) {
+	specYAML := item.SpecYAML
+	if specYAML == "" {
+		if req.Template == "" {
+			return nil, &submitError{Status: http.StatusBadRequest, Message: "Item has neither spec_yaml nor a batch template"}
+		}
+		rendered, err := spec.RenderSpec(req.Template, item.Params)
+		if err != nil {
+			return nil, &submitError{Status: http.StatusBadRequest, Message: "Invalid template parameters: " + err.Error()}
+		}
+		specYAML = rendered
+	} else if len(item.Params) > 0 {
+		return nil, &submitError{Status: http.StatusBadRequest, Message: "Item sets both spec_yaml and params"}
+	}
+
+	job, err := spec.ParseJobSpecWith(specYAML, h.specOptions)
+	if err != nil {
+		return nil, &submitError{Status: http.StatusBadRequest, Message: "Invalid job spec: " + err.Error()}
+	}
+	job.UserID = "default-user" // TODO: Extract from auth token
+	job.Name = item.Name
+	job.BatchID = batchID
+	switch {
+	case item.Experiment != "":
+		job.Experiment = item.Experiment
+	case req.Experiment != "":
+		job.Experiment = req.Experiment
+	}
+
+	if !req.AllowDuplicate {
+		if rejection := h.checkDuplicate(job); rejection != nil {
+			return nil, rejection
+		}
+	}
+	return h.admit(r.Context(), job)
+}
+
+// rejectBatchDuplicates rejects items whose spec is identical to an earlier
+// valid item of the batch
+func rejectBatchDuplicates(items []BatchItem, admitted []*admission, results []BatchItemResult) {
+	first := make(map[string]int)
+	for i, item := range admitted {
+		if item == nil {
+			continue
+		}
+		if earlier, ok := first[item.job.SpecHash]; ok {
+			admitted[i] = nil
+			results[i].Status = BatchItemInvalid
+			results[i].Error = fmt.Sprintf("Spec is identical to item %d (%s); set allow_duplicate to submit both", earlier, items[earlier].Name)
+			continue
+		}
+		first[item.job.SpecHash] = i
+	}
+}
+
+// batchExperiment groups the batch's jobs that joined no experiment under an
+// implicit one named after the batch. Returns its ID, or "" when there is
+// none (experiments disabled or every job chose its own).
+func (h *JobHandler) batchExperiment(req *BatchSubmitRequest, admitted []*admission, batchID string) string {
+	if h.experimentRepo == nil || req.Experiment != "" {
+		return ""
+	}
+	var ungrouped []*models.Job
+	for _, item := range admitted {
+		if item != nil && item.job.ExperimentID == "" {
+			ungrouped = append(ungrouped, item.job)
+		}
+	}
+	if len(ungrouped) == 0 {
+		return ""
+	}
+
+	experiment := &models.Experiment{
+		Name:        "batch-" + batchID,
+		Description: fmt.Sprintf("Jobs of bulk submission %s", batchID),
+		Labels:      map[string]string{"batch_id": batchID},
+	}
+	if err := h.experimentRepo.Create(experiment); err != nil {
+		log.Printf("Failed to create the experiment of batch %s: %v", batchID, err)
+		return ""
+	}
+	for _, job := range ungrouped {
+		job.ExperimentID = experiment.ID
+	}
+	return experiment.ID
+}
+
+// forEachBatchItem calls fn for every item index, at most the batch
+// concurrency at a time, and returns when all calls have
+func (h *JobHandler) forEachBatchItem(n int, fn func(i int)) {
+	concurrency := h.batchConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// decodeBatch reads a bulk submission: a BatchSubmitRequest object, a JSON
+// array of items, or NDJSON with one item per line whose first line may be
+// {"batch": {...}} holding the request's other fields
+func decodeBatch(body io.Reader) (*BatchSubmitRequest, error) {
+	var values []json.RawMessage
+	dec := json.NewDecoder(body)
+	for {
+		var value json.RawMessage
+		err := dec.Decode(&value)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("no items")
+	}
+
+	req := &BatchSubmitRequest{}
+	if len(values) == 1 {
+		value := bytes.TrimSpace(values[0])
+		if bytes.HasPrefix(value, []byte("[")) {
+			return req, json.Unmarshal(value, &req.Items)
+		}
+		var probe map[string]json.RawMessage
+		if err := json.Unmarshal(value, &probe); err != nil {
+			return nil, err
+		}
+		if _, ok := probe["items"]; ok {
+			return req, json.Unmarshal(value, req)
+		}
+	}
+
+	for i, value := range values {
+		if i == 0 {
+			var header struct {
+				Batch *BatchSubmitRequest `json:"batch"`
+			}
+			if err := json.Unmarshal(value, &header); err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			if header.Batch != nil {
+				if len(header.Batch.Items) > 0 {
+					return nil, fmt.Errorf("line 1: items go on their own lines")
+				}
+				req = header.Batch
+				continue
+			}
+		}
+		var item BatchItem
+		if err := json.Unmarshal(value, &item); err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		req.Items = append(req.Items, item)
+	}
+	return req, nil
+}
+
+// batchStream writes NDJSON lines, flushing each so clients see results as
+// items complete. Callers serialize writes.
+type batchStream struct {
+	w       http.ResponseWriter
+	enc     *json.Encoder
+	flusher http.Flusher
+}
+
+// newBatchStream creates a stream over w
+func newBatchStream(w http.ResponseWriter) *batchStream {
+	flusher, _ := w.(http.Flusher)
+	return &batchStream{w: w, enc: json.NewEncoder(w), flusher: flusher}
+}
+
+// write writes one line and flushes it
+func (s *batchStream) write(line interface{}) {
+	if err := s.enc.Encode(line); err != nil {
+		log.Printf("Failed to stream batch result: %v", err)
+		return
+	}
+	if s.flusher != nil {
+		s.flusher.Flush()
+	}
+}
@@ -1,15 +1,26 @@
 package handlers
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"gpu-orchestrator/core/clock"
 	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/monitoring"
+	"gpu-orchestrator/core/optimizer"
+	"gpu-orchestrator/core/policy"
 	"gpu-orchestrator/core/repository"
 	"gpu-orchestrator/core/scheduler"
 	"gpu-orchestrator/core/spec"
+	"gpu-orchestrator/providers"
 
 	"github.com/gorilla/mux"
 )
@@ -20,7 +31,33 @@ type JobHandler struct {
 	allocationRepo *repository.AllocationRepository
 	eventRepo      *repository.EventRepository
 	artifactRepo   *repository.ArtifactRepository
+	taskRepo       *repository.TaskRepository
 	scheduler      *scheduler.Scheduler
+	admission      AdmissionConfig
+	policies       *policy.Engine                   // Optional org policies checked before admission
+	experimentRepo *repository.ExperimentRepository // Optional; resolves job experiments
+	admin          *AdminAuth                       // Guards operator-only endpoints such as boosts
+	specOptions    spec.ParseOptions
+	providerUsage  *providers.UsageMeter              // Optional; reports provider API budgets to why-pending
+	cancelGrace    time.Duration                      // Running jobs stay cancelling this long before teardown; 0 = immediate
+	benchmarks     *optimizer.PerformanceMetricsStore // Optional; learns throughput from progress reports
+	imageRepo      *repository.MachineImageRepository // Optional; the allow list of pinned images
+
+	batchMaxItems    int // Items per bulk submission; see SetBatchLimits
+	batchConcurrency int // Items of a bulk submission validated and stored at once
+}
+
+// Admission modes for SubmitJob
+const (
+	AdmissionOff    = "off"    // No feasibility check
+	AdmissionWarn   = "warn"   // Create the job and attach warnings
+	AdmissionReject = "reject" // Reject infeasible jobs with 422
+)
+
+// AdmissionConfig controls the submission-time feasibility check
+type AdmissionConfig struct {
+	Mode    string
+	Timeout time.Duration
 }
 
 // NewJobHandler creates a new job handler
@@ -29,28 +66,79 @@ func NewJobHandler(
 	allocationRepo *repository.AllocationRepository,
 	eventRepo *repository.EventRepository,
 	artifactRepo *repository.ArtifactRepository,
+	taskRepo *repository.TaskRepository,
 	sched *scheduler.Scheduler,
+	admission AdmissionConfig,
 ) *JobHandler {
 	return &JobHandler{
 		jobRepo:        jobRepo,
 		allocationRepo: allocationRepo,
 		eventRepo:      eventRepo,
 		artifactRepo:   artifactRepo,
+		taskRepo:       taskRepo,
 		scheduler:      sched,
+		admission:      admission,
 	}
 }
 
+// SetPolicyEngine enables org policy checks on submitted and cloned jobs
+func (h *JobHandler) SetPolicyEngine(engine *policy.Engine) {
+	h.policies = engine
+}
+
+// SetSpecOptions sets how strictly submitted and cloned specs are parsed
+func (h *JobHandler) SetSpecOptions(opts spec.ParseOptions) {
+	h.specOptions = opts
+}
+
+// SetExperimentRepository enables grouping submitted jobs under experiments
+func (h *JobHandler) SetExperimentRepository(experimentRepo *repository.ExperimentRepository) {
+	h.experimentRepo = experimentRepo
+}
+
+// SetMachineImageRepository enables pinning registered machine images
+// (resources.image). Without it jobs that pin an image are rejected.
+func (h *JobHandler) SetMachineImageRepository(imageRepo *repository.MachineImageRepository) {
+	h.imageRepo = imageRepo
+}
+
+// SetAdminAuth sets the auth for operator-only job endpoints
+func (h *JobHandler) SetAdminAuth(admin *AdminAuth) {
+	h.admin = admin
+}
+
+// SetCancelGrace sets how long cancelled running jobs can be restored with
+// POST /v1/jobs/{id}/uncancel before their cluster is torn down
+func (h *JobHandler) SetCancelGrace(window time.Duration) {
+	h.cancelGrace = window
+}
+
+// SetBenchmarks enables learning per-GPU throughput from progress reports
+func (h *JobHandler) SetBenchmarks(benchmarks *optimizer.PerformanceMetricsStore) {
+	h.benchmarks = benchmarks
+}
+
+// SetProviderUsage sets the meter whose exhausted API budgets are reported
+// as wait reasons
+func (h *JobHandler) SetProviderUsage(meter *providers.UsageMeter) {
+	h.providerUsage = meter
+}
+
 // SubmitJobRequest represents the request to submit a job
 type SubmitJobRequest struct {
-	Name     string `json:"name"`
-	SpecYAML string `json:"spec_yaml"`
+	Name           string `json:"name"`
+	SpecYAML       string `json:"spec_yaml"`
+	AllowDuplicate bool   `json:"allow_duplicate,omitempty"` // Submit even if an identical spec is still active
+	Experiment     string `json:"experiment,omitempty"`      // Experiment name or ID; overrides the spec's
 }
 
 // SubmitJobResponse represents the response after submitting a job
 type SubmitJobResponse struct {
-	ID        string    `json:"id"`
-	Status    string    `json:"status"`
-	CreatedAt time.Time `json:"created_at"`
+	ID           string                       `json:"id"`
+	Status       string                       `json:"status"`
+	CreatedAt    time.Time                    `json:"created_at"`
+	Warnings     []optimizer.AdmissionProblem `json:"warnings,omitempty"`
+	SpecWarnings []string                     `json:"spec_warnings,omitempty"`
 }
 
 // SubmitJob handles POST /v1/jobs
@@ -60,154 +148,1287 @@ func (h *JobHandler) SubmitJob(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
-
-	// Parse YAML spec
-	job, err := spec.ParseJobSpec(req.SpecYAML)
+
+	// Parse YAML spec
+	job, err := spec.ParseJobSpecWith(req.SpecYAML, h.specOptions)
+	if err != nil {
+		http.Error(w, "Invalid job spec: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Set user ID and name (TODO: Get from auth context)
+	job.UserID = "default-user" // TODO: Extract from auth token
+	job.Name = req.Name
+	if req.Experiment != "" {
+		job.Experiment = req.Experiment
+	}
+
+	// Reject accidental double submissions of the same spec
+	if !req.AllowDuplicate {
+		if rejection := h.checkDuplicate(job); rejection != nil {
+			rejection.write(w)
+			return
+		}
+	}
+
+	job, warnings, ok := h.admitAndCreate(w, r, job)
+	if !ok {
+		return
+	}
+
+	resp := SubmitJobResponse{
+		ID:           job.ID,
+		Status:       string(job.Status),
+		CreatedAt:    job.CreatedAt,
+		Warnings:     warnings,
+		SpecWarnings: job.SpecWarnings,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// SpecProblem is one reason POST /v1/jobs/validate rejects a spec
+type SpecProblem struct {
+	Reason  string `json:"reason"`          // invalid_spec | unknown_field | rejected | policy_denied
+	Field   string `json:"field,omitempty"` // Spec path, when the problem names one
+	Message string `json:"message"`
+}
+
+// ValidateJobResponse is how a valid spec would be submitted: the resolved
+// requirements and constraints, after org policy mutations
+type ValidateJobResponse struct {
+	Valid           bool                   `json:"valid"`
+	JobType         models.JobType         `json:"job_type"`
+	Framework       string                 `json:"framework"`
+	ExecutionMode   models.ExecutionMode   `json:"execution_mode"`
+	Backend         models.BackendType     `json:"backend"`
+	Requirements    models.JobRequirements `json:"requirements"`
+	Constraints     models.JobConstraints  `json:"constraints"`
+	SpecHash        string                 `json:"spec_hash"`
+	Warnings        []models.SpecNote      `json:"warnings,omitempty"`      // Defaults applied, detected values, deprecated fields
+	SpecWarnings    []string               `json:"spec_warnings,omitempty"` // Ignored unknown fields
+	PolicyMutations []policy.Mutation      `json:"policy_mutations,omitempty"`
+	PolicyWarning   string                 `json:"policy_warning,omitempty"` // Webhook failure let through in fail-open mode
+}
+
+// ValidateJob handles POST /v1/jobs/validate
+// Parses the spec and checks its experiment, pinned image and org policies
+// like a submission, without creating a job or running the optimizer
+func (h *JobHandler) ValidateJob(w http.ResponseWriter, r *http.Request) {
+	var req SubmitJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	job, decision, ok := h.lintSpec(w, r, req)
+	if !ok {
+		return
+	}
+
+	resp := ValidateJobResponse{
+		Valid:         true,
+		JobType:       job.JobType,
+		Framework:     job.Framework,
+		ExecutionMode: job.Requirements.ExecutionMode,
+		Backend:       job.SelectedBackend,
+		Requirements:  job.Requirements,
+		Constraints:   job.Constraints,
+		SpecHash:      job.SpecHash,
+		Warnings:      job.SpecNotes,
+		SpecWarnings:  job.SpecWarnings,
+	}
+	if decision != nil {
+		resp.PolicyMutations = decision.Mutations
+		resp.PolicyWarning = decision.WebhookError
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// lintSpec parses a submission's spec and checks it like a submission would,
+// writing the problems found as a 422. Nothing is stored.
+func (h *JobHandler) lintSpec(w http.ResponseWriter, r *http.Request, req SubmitJobRequest) (*models.Job, *policy.Decision, bool) {
+	job, err := spec.ParseJobSpecWith(req.SpecYAML, h.specOptions)
+	if err != nil {
+		writeSpecProblems(w, specProblems(err))
+		return nil, nil, false
+	}
+	job.UserID = "default-user" // TODO: Extract from auth token
+	job.Name = req.Name
+	if req.Experiment != "" {
+		job.Experiment = req.Experiment
+	}
+
+	decision, rejection := h.checkSpec(r.Context(), job)
+	if rejection != nil {
+		if rejection.Status >= http.StatusInternalServerError {
+			rejection.write(w)
+			return nil, nil, false
+		}
+		problem := SpecProblem{Reason: "rejected", Field: rejection.Field, Message: rejection.Message}
+		if rejection.Body["error"] == "policy_denied" {
+			problem.Reason = "policy_denied"
+			problem.Message = fmt.Sprintf("%v: %s", rejection.Body["policy"], rejection.Message)
+		}
+		writeSpecProblems(w, []SpecProblem{problem})
+		return nil, nil, false
+	}
+	return job, decision, true
+}
+
+// defaultEstimateStrategies is how many strategies POST /v1/jobs/estimate
+// returns without ?top
+const defaultEstimateStrategies = 3
+
+// EstimateJobResponse is what the optimizer would do with a spec right now
+type EstimateJobResponse struct {
+	Chosen            string                      `json:"chosen,omitempty"` // Strategy a submission would be placed with; empty when none fits
+	Strategies        []models.StrategyEvaluation `json:"strategies"`       // Best first, infeasible ones with their rejections
+	TotalStrategies   int                         `json:"total_strategies"`
+	EstimatedHours    float64                     `json:"estimated_hours"`
+	ExcludedInstances int                         `json:"excluded_instances,omitempty"`
+	Explanation       string                      `json:"explanation,omitempty"`
+	Warnings          []models.SpecNote           `json:"warnings,omitempty"`
+	SpecWarnings      []string                    `json:"spec_warnings,omitempty"`
+	PolicyMutations   []policy.Mutation           `json:"policy_mutations,omitempty"`
+}
+
+// EstimateJob handles POST /v1/jobs/estimate. The spec is checked like a
+// submission (422 with the same problems as POST /v1/jobs/validate), then
+// scored by the optimizer at cached prices. Nothing is stored; ?top=N
+// limits the strategies returned (default 3).
+func (h *JobHandler) EstimateJob(w http.ResponseWriter, r *http.Request) {
+	top := defaultEstimateStrategies
+	if topParam := r.URL.Query().Get("top"); topParam != "" {
+		n, err := strconv.Atoi(topParam)
+		if err != nil || n < 1 {
+			http.Error(w, "top must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		top = n
+	}
+
+	var req SubmitJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	job, policyDecision, ok := h.lintSpec(w, r, req)
+	if !ok {
+		return
+	}
+
+	strategies, decision, err := h.scheduler.EstimateStrategies(r.Context(), job)
+	if errors.Is(err, optimizer.ErrNoPricing) {
+		http.Error(w, "No pricing data available yet", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil && decision == nil {
+		http.Error(w, "Failed to estimate job: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := EstimateJobResponse{
+		Strategies:      []models.StrategyEvaluation{},
+		TotalStrategies: len(strategies),
+		EstimatedHours:  job.Requirements.EstimatedHours,
+		Warnings:        job.SpecNotes,
+		SpecWarnings:    job.SpecWarnings,
+	}
+	if decision != nil {
+		resp.Chosen = decision.Chosen
+		resp.ExcludedInstances = decision.ExcludedInstances
+		resp.Explanation = decision.Explanation
+	}
+	if policyDecision != nil {
+		resp.PolicyMutations = policyDecision.Mutations
+	}
+	for i, strategy := range strategies {
+		if i == top {
+			break
+		}
+		resp.Strategies = append(resp.Strategies, strategy.Evaluation())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// specProblems breaks a parse error into problems, one per unknown field
+func specProblems(err error) []SpecProblem {
+	var unknown *spec.UnknownFieldsError
+	if errors.As(err, &unknown) {
+		problems := make([]SpecProblem, 0, len(unknown.Fields))
+		for _, entry := range unknown.Fields {
+			field, _, _ := strings.Cut(entry, " (line") // Entries carry their line number
+			problems = append(problems, SpecProblem{Reason: "unknown_field", Field: field, Message: "unknown field " + entry})
+		}
+		return problems
+	}
+	return []SpecProblem{{Reason: "invalid_spec", Field: spec.ErrorField(err), Message: err.Error()}}
+}
+
+// writeSpecProblems writes the 422 rejection of an invalid spec
+func writeSpecProblems(w http.ResponseWriter, problems []SpecProblem) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"valid":   false,
+		"error":   "invalid_spec",
+		"message": problems[0].Message,
+		"errors":  problems,
+	})
+}
+
+// submitError rejects a submission before its job is created. Body, when
+// set, is written as the JSON response; otherwise Message is.
+type submitError struct {
+	Status  int
+	Message string
+	Body    map[string]interface{}
+	Field   string // Spec field the rejection is about; "" = none
+}
+
+// write writes the rejection as the response
+func (e *submitError) write(w http.ResponseWriter) {
+	if e.Body == nil {
+		http.Error(w, e.Message, e.Status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(e.Status)
+	json.NewEncoder(w).Encode(e.Body)
+}
+
+// admission is a submission that passed experiment resolution, org policies
+// and admission control, ready to be stored
+type admission struct {
+	job      *models.Job
+	decision *policy.Decision
+	warnings []optimizer.AdmissionProblem
+}
+
+// admitAndCreate enforces org policies, runs admission control, stores the
+// job and enqueues it. On failure the error response has been written and ok
+// is false.
+func (h *JobHandler) admitAndCreate(w http.ResponseWriter, r *http.Request, job *models.Job) (*models.Job, []optimizer.AdmissionProblem, bool) {
+	admitted, rejection := h.admit(r.Context(), job)
+	if rejection != nil {
+		rejection.write(w)
+		return nil, nil, false
+	}
+	if err := h.create(admitted); err != nil {
+		http.Error(w, "Failed to create job: "+err.Error(), http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	return admitted.job, admitted.warnings, true
+}
+
+// admit resolves the job's experiment, checks its pinned image, enforces org
+// policies and runs admission control. Nothing is stored.
+func (h *JobHandler) admit(ctx context.Context, job *models.Job) (*admission, *submitError) {
+	decision, rejection := h.checkSpec(ctx, job)
+	if rejection != nil {
+		return nil, rejection
+	}
+
+	// Admission control: fast feasibility check against cached pricing
+	var warnings []optimizer.AdmissionProblem
+	if h.admission.Mode != AdmissionOff {
+		result := h.scheduler.CheckAdmission(ctx, job, h.admission.Timeout)
+		if !result.Checked {
+			log.Printf("Admission check skipped for job %q: %s", job.Name, result.SkipReason)
+		} else if !result.Feasible() {
+			if h.admission.Mode == AdmissionReject {
+				return nil, infeasible(result.Problems)
+			}
+			warnings = result.Problems
+		}
+	}
+
+	return &admission{job: job, decision: decision, warnings: warnings}, nil
+}
+
+// checkSpec resolves the job's experiment, checks its pinned image and
+// enforces org policies, whose webhook may mutate the job. Nothing is stored.
+func (h *JobHandler) checkSpec(ctx context.Context, job *models.Job) (*policy.Decision, *submitError) {
+	if job.Experiment != "" {
+		if h.experimentRepo == nil {
+			return nil, &submitError{Status: http.StatusBadRequest, Message: "Experiments are not enabled", Field: "experiment"}
+		}
+		experiment, err := h.experimentRepo.Resolve(job.Experiment)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &submitError{Status: http.StatusBadRequest, Message: fmt.Sprintf("Experiment %q not found", job.Experiment), Field: "experiment"}
+		}
+		if err != nil {
+			return nil, &submitError{Status: http.StatusInternalServerError, Message: "Failed to resolve experiment: " + err.Error()}
+		}
+		job.ExperimentID = experiment.ID
+	}
+
+	if job.Requirements.Image != "" {
+		if rejection := h.checkPinnedImage(job.Requirements.Image); rejection != nil {
+			return nil, rejection
+		}
+	}
+
+	// Org policies; the webhook may mutate constraints and labels
+	var decision *policy.Decision
+	if h.policies != nil {
+		var err error
+		decision, err = h.policies.Evaluate(ctx, job)
+		if err != nil {
+			return nil, &submitError{Status: http.StatusInternalServerError, Message: "Failed to evaluate policies: " + err.Error()}
+		}
+		if !decision.Allowed {
+			return nil, policyDenied(decision.Violation)
+		}
+	}
+	return decision, nil
+}
+
+// create stores an admitted job, records why it was mutated or warned about
+// and enqueues it
+func (h *JobHandler) create(admitted *admission) error {
+	job, decision, warnings := admitted.job, admitted.decision, admitted.warnings
+
+	// Create job in database
+	if err := h.jobRepo.CreateJob(job); err != nil {
+		return err
+	}
+
+	pending := models.JobStatusPending
+	if decision != nil && len(decision.Mutations) > 0 {
+		if err := h.jobRepo.CreateJobEvent(job.ID, &pending, pending, "policy_mutated", map[string]interface{}{
+			"mutations": decision.Mutations,
+		}); err != nil {
+			log.Printf("Failed to record policy mutations for job %s: %v", job.ID, err)
+		}
+	}
+	if decision != nil && decision.WebhookError != "" {
+		if err := h.jobRepo.CreateJobEvent(job.ID, &pending, pending, "policy_webhook_failed_open", map[string]interface{}{
+			"error": decision.WebhookError,
+		}); err != nil {
+			log.Printf("Failed to record policy webhook failure for job %s: %v", job.ID, err)
+		}
+	}
+
+	if len(job.SpecWarnings) > 0 {
+		if err := h.jobRepo.CreateJobEvent(job.ID, &pending, pending, "spec_warning", map[string]interface{}{
+			"warnings": job.SpecWarnings,
+		}); err != nil {
+			log.Printf("Failed to record spec warnings for job %s: %v", job.ID, err)
+		}
+	}
+
+	if len(warnings) > 0 {
+		if err := h.jobRepo.CreateJobEvent(job.ID, &pending, pending, "admission_warning", map[string]interface{}{
+			"warnings": warnings,
+		}); err != nil {
+			log.Printf("Failed to record admission warnings for job %s: %v", job.ID, err)
+		}
+	}
+
+	// Enqueue job for scheduling
+	h.scheduler.Enqueue(job)
+
+	return nil
+}
+
+// CloneJobRequest represents the request to clone a job
+type CloneJobRequest struct {
+	Name      string                 `json:"name,omitempty"` // Default: "<source name>-clone"
+	Overrides map[string]interface{} `json:"overrides"`      // Sparse job-section overrides; null removes a field
+}
+
+// CloneJob handles POST /v1/jobs/{id}/clone
+func (h *JobHandler) CloneJob(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	sourceID := vars["id"]
+
+	var req CloneJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	source, err := h.jobRepo.GetJob(sourceID)
+	if err != nil {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	// Merge and re-validate before touching the database
+	mergedYAML, changes, err := spec.MergeOverrides(source.SpecYAML, req.Overrides)
+	if err != nil {
+		http.Error(w, "Invalid overrides: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	job, err := spec.ParseJobSpecWith(mergedYAML, h.specOptions)
+	if err != nil {
+		http.Error(w, "Invalid job spec: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	job.UserID = source.UserID
+	job.TeamID = source.TeamID
+	job.ProjectID = source.ProjectID
+	job.Name = req.Name
+	if job.Name == "" {
+		job.Name = source.Name + "-clone"
+	}
+	job.ClonedFrom = &source.ID
+	job.SpecBases = source.SpecBases // The source spec is already resolved
+	if job.Experiment == "" {
+		job.ExperimentID = source.ExperimentID // Clones stay in the source's experiment
+	}
+
+	job, warnings, ok := h.admitAndCreate(w, r, job)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":            job.ID,
+		"status":        job.Status,
+		"created_at":    job.CreatedAt,
+		"cloned_from":   source.ID,
+		"changes":       changes,
+		"warnings":      warnings,
+		"spec_warnings": job.SpecWarnings,
+	})
+}
+
+// checkPinnedImage checks that a job's pinned image is validated for at
+// least one provider, region and instance family
+func (h *JobHandler) checkPinnedImage(imageID string) *submitError {
+	if h.imageRepo == nil {
+		return &submitError{Status: http.StatusBadRequest, Message: "Machine images are not enabled", Field: "resources.image"}
+	}
+	images, err := h.imageRepo.ImagesByImageID(imageID)
+	if err != nil {
+		return &submitError{Status: http.StatusInternalServerError, Message: "Failed to look up machine image: " + err.Error()}
+	}
+	for _, image := range images {
+		if image.Usable(true) {
+			return nil
+		}
+	}
+	return &submitError{Status: http.StatusBadRequest, Message: fmt.Sprintf("Machine image %q is not a validated registered image", imageID), Field: "resources.image"}
+}
+
+// checkDuplicate rejects a job whose spec is identical to a pending or
+// running job of the same user
+func (h *JobHandler) checkDuplicate(job *models.Job) *submitError {
+	existingID, err := h.jobRepo.FindActiveDuplicate(job.UserID, job.SpecHash)
+	if err != nil {
+		return &submitError{Status: http.StatusInternalServerError, Message: "Failed to check for duplicate jobs: " + err.Error()}
+	}
+	if existingID == "" {
+		return nil
+	}
+	const message = "an identical spec is already pending or running; set allow_duplicate to submit anyway"
+	return &submitError{Status: http.StatusConflict, Message: message, Body: map[string]interface{}{
+		"error":           "duplicate_job",
+		"message":         message,
+		"existing_job_id": existingID,
+	}}
+}
+
+// infeasible is the 422 admission control rejection
+func infeasible(problems []optimizer.AdmissionProblem) *submitError {
+	return &submitError{Status: http.StatusUnprocessableEntity, Message: problems[0].Message, Body: map[string]interface{}{
+		"error":    "infeasible_job",
+		"message":  problems[0].Message,
+		"details":  map[string]interface{}{"field": problems[0].Field, "reason": problems[0].Reason},
+		"problems": problems,
+	}}
+}
+
+// policyDenied is the 422 rejection of a job denied by an org policy
+func policyDenied(violation *policy.Violation) *submitError {
+	return &submitError{Status: http.StatusUnprocessableEntity, Message: violation.Message, Body: map[string]interface{}{
+		"error":   "policy_denied",
+		"policy":  violation.Policy,
+		"message": violation.Message,
+	}}
+}
+
+// GetJob handles GET /v1/jobs/{id}
+func (h *JobHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	jobID := vars["id"]
+
+	job, err := h.jobRepo.GetJob(jobID)
+	if err != nil {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	// Get allocations; instances compares launched with requested so partial
+	// provisioning is visible
+	allocations, _ := h.allocationRepo.GetAllocationsByJobID(jobID)
+	requested, provisioned := 0, 0
+	for _, alloc := range allocations {
+		requested += alloc.Count
+		provisioned += alloc.ProvisionedCount
+	}
+
+	// Build response
+	response := map[string]interface{}{
+		"id":             job.ID,
+		"name":           job.Name,
+		"status":         job.Status,
+		"job_type":       job.JobType,
+		"framework":      job.Framework,
+		"execution_mode": job.Requirements.ExecutionMode,
+		"labels":         job.Labels,
+		"priority_boost": job.PriorityBoost,
+		"allocations":    allocations,
+		"instances": map[string]interface{}{
+			"requested":   requested,
+			"provisioned": provisioned,
+		},
+		"progress": monitoring.ComputeProgress(job, time.Now()),
+		"cost": map[string]interface{}{
+			"running_usd":     job.CostRunningUSD,
+			"estimated_usd":   job.CostEstimatedUSD,
+			"transfer_usd":    job.CostTransferUSD, // Checkpoint replication
+			"egress_usd":      job.CostEgressUSD,
+			"emissions_gco2e": job.EmissionsGCO2e, // null until accounted after the job finishes
+		},
+		"timestamps": map[string]interface{}{
+			"created_at":  job.CreatedAt,
+			"started_at":  job.StartedAt,
+			"finished_at": job.CompletedAt,
+		},
+	}
+
+	// Tasks of multi_task jobs; the job's running cost is their sum
+	if job.Requirements.ExecutionMode == models.ModeMultiTask {
+		policy := models.DefaultTaskPolicy
+		if job.TaskPolicy != nil {
+			policy = *job.TaskPolicy
+		}
+		response["task_policy"] = policy
+		if tasks, err := h.taskRepo.ListTasks(job.ID); err == nil {
+			response["tasks"] = taskViews(tasks)
+			if len(job.Requirements.TaskGroups) > 0 {
+				response["task_groups"] = taskGroupViews(job.Requirements.TaskGroups, tasks)
+			}
+		}
+	}
+
+	// Instance identities, for audit
+	if job.DataAccess != nil {
+		response["data_access"] = job.DataAccess
+	}
+	if identities, err := h.jobRepo.ListJobIdentities(job.ID); err == nil && len(identities) > 0 {
+		response["identities"] = identities
+	}
+
+	// Base specs the spec was merged onto
+	if len(job.SpecBases) > 0 {
+		response["spec_bases"] = job.SpecBases
+	}
+
+	// Clone lineage
+	if job.ClonedFrom != nil {
+		response["cloned_from"] = *job.ClonedFrom
+	}
+	if clones, err := h.jobRepo.ListClones(job.ID); err == nil && len(clones) > 0 {
+		response["clones"] = clones
+	}
+	if job.BatchID != "" {
+		response["batch_id"] = job.BatchID
+	}
+
+	// Latest deadline risk assessment of unfinished deadline jobs
+	if job.Constraints.Deadline != nil && !job.Status.Terminal() {
+		if risk, err := h.jobRepo.GetDeadlineRisk(job.ID); err == nil && risk != nil {
+			response["deadline_risk"] = risk
+		}
+	}
+
+	// Interactive session
+	if job.Session != nil {
+		response["session"] = map[string]interface{}{
+			"max_runtime": job.Session.MaxRuntime.String(),
+			"jupyter":     job.Session.Jupyter,
+			"idle_stop":   job.Session.IdleStop,
+			"endpoint":    job.SessionEndpoint,
+			"expires_at":  job.SessionExpiresAt,
+		}
+	}
+
+	if job.SelectedProvider != nil {
+		response["selected"] = map[string]interface{}{
+			"provider":      *job.SelectedProvider,
+			"region":        job.SelectedRegion,
+			"backend":       job.SelectedBackend,
+			"instance_type": allocations[0].InstanceType, // TODO: Handle multiple allocations
+			"spot":          allocations[0].Spot,
+			"count":         allocations[0].Count,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// taskViews formats tasks for the job detail response
+func taskViews(tasks []models.JobTask) []map[string]interface{} {
+	views := make([]map[string]interface{}, 0, len(tasks))
+	for _, task := range tasks {
+		view := map[string]interface{}{
+			"index":         task.Index,
+			"status":        task.Status,
+			"attempts":      task.Attempts,
+			"provider":      task.Allocation.Provider,
+			"region":        task.Allocation.Region,
+			"instance_type": task.Allocation.InstanceType,
+			"count":         task.Allocation.Count,
+			"spot":          task.Allocation.Spot,
+			"cost_usd":      task.CostUSD,
+			"cluster_id":    task.ClusterID,
+			"started_at":    task.StartedAt,
+			"finished_at":   task.FinishedAt,
+		}
+		if task.Allocation.Zone != "" {
+			view["zone"] = task.Allocation.Zone
+		}
+		if task.Allocation.TaskGroup != "" {
+			view["task_group"] = task.Allocation.TaskGroup
+		}
+		if task.Error != "" {
+			view["error"] = task.Error
+		}
+		views = append(views, view)
+	}
+	return views
+}
+
+// taskGroupViews rolls a multi_task job's tasks up per task group: the
+// group's shape, its tasks by status and their cost
+func taskGroupViews(groups []models.TaskGroup, tasks []models.JobTask) []map[string]interface{} {
+	statuses := make(map[string]map[models.JobStatus]int, len(groups))
+	costs := make(map[string]float64, len(groups))
+	for _, task := range tasks {
+		group := task.Allocation.TaskGroup
+		if statuses[group] == nil {
+			statuses[group] = make(map[models.JobStatus]int)
+		}
+		statuses[group][task.Status]++
+		costs[group] += task.CostUSD
+	}
+
+	views := make([]map[string]interface{}, 0, len(groups))
+	for _, group := range groups {
+		counts := statuses[group.Name]
+		if counts == nil {
+			counts = map[models.JobStatus]int{}
+		}
+		views = append(views, map[string]interface{}{
+			"name":          group.Name,
+			"tasks":         group.Tasks,
+			"gpus_per_task": group.GPUsPerTask,
+			"statuses":      counts,
+			"cost_usd":      costs[group.Name],
+		})
+	}
+	return views
+}
+
+// GetJobDecision handles GET /v1/jobs/{id}/decision
+// Returns the strategies the optimizer evaluated and why it chose the allocation
+func (h *JobHandler) GetJobDecision(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	jobID := vars["id"]
+
+	decision, err := h.jobRepo.GetAllocationDecision(jobID)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to get decision: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if decision == nil {
+		http.Error(w, "Job has no allocation decision yet", http.StatusNotFound)
+		return
+	}
+	if decision.Explanation == "" {
+		decision.Explanation = optimizer.ExplainDecision(decision)
+	}
+	if decision.Metric == models.MetricTokens {
+		job, err := h.jobRepo.GetJob(jobID)
+		if err != nil {
+			http.Error(w, "Failed to get job: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		decision.TokenCost = optimizer.ReconcileTokenCost(decision, job)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(decision)
+}
+
+// GetWhyPending handles GET /v1/jobs/{id}/why-pending.
+// Lists what keeps a pending or scheduled job from starting.
+func (h *JobHandler) GetWhyPending(w http.ResponseWriter, r *http.Request) {
+	jobID := mux.Vars(r)["id"]
+
+	job, err := h.jobRepo.GetJob(jobID)
+	if err != nil {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+	if job.Status != models.JobStatusPending && job.Status != models.JobStatusScheduled {
+		http.Error(w, fmt.Sprintf("Job is %s, not waiting to start", job.Status), http.StatusConflict)
+		return
+	}
+
+	reasons, err := h.scheduler.WhyPending(job)
+	if err != nil {
+		http.Error(w, "Failed to get wait reasons: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if job.Status == models.JobStatusPending && h.providerUsage != nil {
+		for _, budget := range h.providerUsage.Budgets() {
+			if !budget.Throttled {
+				continue
+			}
+			reasons = append(reasons, models.WaitReason{
+				Code:       models.WaitProviderBudget,
+				Message:    fmt.Sprintf("%s is over its API call budget; pricing refreshes and other deferrable calls wait for the next hour", budget.Provider),
+				Value:      fmt.Sprintf("%d/%d calls this hour", budget.HourCalls, budget.PerHour),
+				RecordedAt: time.Now(),
+			})
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"job_id":  job.ID,
+		"status":  job.Status,
+		"reasons": reasons,
+	})
+}
+
+// GetTopology handles GET /v1/jobs/{id}/topology
+func (h *JobHandler) GetTopology(w http.ResponseWriter, r *http.Request) {
+	jobID := mux.Vars(r)["id"]
+
+	if _, err := h.jobRepo.GetJob(jobID); err != nil {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+	manifest, err := h.jobRepo.GetTopology(jobID)
+	if err != nil {
+		http.Error(w, "Failed to get topology: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if manifest == nil {
+		http.Error(w, "No topology recorded for this job", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(manifest)
+}
+
+// ListJobs handles GET /v1/jobs
+func (h *JobHandler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	// Parse query parameters
+	statusParam := r.URL.Query().Get("status")
+	limit := 50 // Default limit
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		fmt.Sscanf(limitParam, "%d", &limit)
+	}
+	if limit < 1 {
+		http.Error(w, "limit must be positive", http.StatusBadRequest)
+		return
+	}
+	cursor := r.URL.Query().Get("cursor")
+
+	var status *models.JobStatus
+	if statusParam != "" {
+		s := models.JobStatus(statusParam)
+		status = &s
+	}
+
+	// Fetch jobs from database
+	jobs, nextCursor, err := h.jobRepo.ListJobs("", status, limit, cursor)
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		http.Error(w, "Invalid cursor", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to list jobs: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Build response items
+	items := make([]map[string]interface{}, len(jobs))
+	for i, job := range jobs {
+		items[i] = map[string]interface{}{
+			"id":         job.ID,
+			"name":       job.Name,
+			"status":     job.Status,
+			"job_type":   job.JobType,
+			"framework":  job.Framework,
+			"created_at": job.CreatedAt,
+		}
+	}
+
+	// null on the last page
+	var next interface{}
+	if nextCursor != "" {
+		next = nextCursor
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"items":       items,
+		"next_cursor": next,
+	})
+}
+
+// UpdateJobRequest changes the constraints of a pending or scheduled job.
+// Omitted fields keep their value.
+type UpdateJobRequest struct {
+	Budget            *float64 `json:"budget,omitempty"`   // USD
+	Deadline          *string  `json:"deadline,omitempty"` // RFC 3339; "" clears the deadline
+	AllowSpot         *bool    `json:"allow_spot,omitempty"`
+	MinReliability    *float64 `json:"min_reliability,omitempty"`    // 0-1
+	PerformanceWeight *float64 `json:"performance_weight,omitempty"` // 0-1
+}
+
+// UpdateJob handles PATCH /v1/jobs/{id}. Pending and scheduled jobs take the
+// new constraints when the scheduler next processes them; a scheduled job
+// goes back to pending and is placed again. Jobs that started provisioning
+// are rejected with 409.
+func (h *JobHandler) UpdateJob(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	jobID := vars["id"]
+
+	var req UpdateJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	job, err := h.jobRepo.GetJob(jobID)
+	if err != nil {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+	if job.Status != models.JobStatusPending && job.Status != models.JobStatusScheduled {
+		http.Error(w, fmt.Sprintf("Only pending or scheduled jobs can be updated (job is %s)", job.Status), http.StatusConflict)
+		return
+	}
+
+	constraints, err := h.updatedConstraints(job.Constraints, req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// The new constraints must pass the org policies a submission would
+	if h.policies != nil {
+		candidate := *job
+		candidate.Constraints = constraints
+		decision, err := h.policies.Evaluate(r.Context(), &candidate)
+		if err != nil {
+			http.Error(w, "Failed to evaluate policies: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !decision.Allowed {
+			policyDenied(decision.Violation).write(w)
+			return
+		}
+		constraints = candidate.Constraints
+	}
+
+	changes := constraintChanges(job.Constraints, constraints)
+	if len(changes) > 0 {
+		err = h.scheduler.UpdateConstraints(job, constraints, map[string]interface{}{
+			"actor":   requestActor(r),
+			"changes": changes,
+		})
+		if errors.Is(err, repository.ErrStatusConflict) {
+			http.Error(w, "The job started provisioning meanwhile", http.StatusConflict)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Failed to update job: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":      job.ID,
+		"status":  job.Status,
+		"changes": changes,
+		"constraints": map[string]interface{}{
+			"budget":             job.Constraints.MaxBudget,
+			"deadline":           job.Constraints.Deadline,
+			"allow_spot":         job.Constraints.AllowSpot,
+			"min_reliability":    job.Constraints.MinReliability,
+			"performance_weight": job.Constraints.PerformanceWeight,
+		},
+	})
+}
+
+// updatedConstraints applies an update request to a job's constraints
+func (h *JobHandler) updatedConstraints(constraints models.JobConstraints, req UpdateJobRequest) (models.JobConstraints, error) {
+	if req.Budget != nil {
+		if *req.Budget < 0 {
+			return constraints, fmt.Errorf("budget must not be negative")
+		}
+		constraints.MaxBudget = *req.Budget
+	}
+	if req.Deadline != nil {
+		constraints.Deadline = nil
+		if *req.Deadline != "" {
+			deadline, err := clock.ParseRFC3339(*req.Deadline)
+			if err != nil {
+				return constraints, fmt.Errorf("invalid deadline format: %w", err)
+			}
+			if h.specOptions.Clock != nil {
+				if err := clock.CheckNotPast(deadline, h.specOptions.Clock.Now(), h.specOptions.ClockSkew); err != nil {
+					return constraints, fmt.Errorf("invalid deadline: %w", err)
+				}
+			}
+			constraints.Deadline = &deadline
+		}
+	}
+	if req.AllowSpot != nil {
+		constraints.AllowSpot = *req.AllowSpot
+	}
+	if req.MinReliability != nil {
+		if *req.MinReliability < 0 || *req.MinReliability > 1 {
+			return constraints, fmt.Errorf("min_reliability must be between 0 and 1")
+		}
+		constraints.MinReliability = *req.MinReliability
+	}
+	if req.PerformanceWeight != nil {
+		if *req.PerformanceWeight < 0 || *req.PerformanceWeight > 1 {
+			return constraints, fmt.Errorf("performance_weight must be between 0 and 1")
+		}
+		constraints.PerformanceWeight = *req.PerformanceWeight
+	}
+	return constraints, nil
+}
+
+// constraintChanges returns the old and new value of every editable
+// constraint that differs
+func constraintChanges(from, to models.JobConstraints) map[string]interface{} {
+	changes := map[string]interface{}{}
+	change := func(field string, old, new interface{}) {
+		changes[field] = map[string]interface{}{"from": old, "to": new}
+	}
+	if from.MaxBudget != to.MaxBudget {
+		change("budget", from.MaxBudget, to.MaxBudget)
+	}
+	if !sameTime(from.Deadline, to.Deadline) {
+		change("deadline", from.Deadline, to.Deadline)
+	}
+	if from.AllowSpot != to.AllowSpot {
+		change("allow_spot", from.AllowSpot, to.AllowSpot)
+	}
+	if from.MinReliability != to.MinReliability {
+		change("min_reliability", from.MinReliability, to.MinReliability)
+	}
+	if from.PerformanceWeight != to.PerformanceWeight {
+		change("performance_weight", from.PerformanceWeight, to.PerformanceWeight)
+	}
+	return changes
+}
+
+// sameTime reports whether two optional times are equal
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// CancelJob handles POST /v1/jobs/{id}/cancel. Running jobs move to
+// cancelling for the grace window and can be restored until it ends;
+// ?force=true cancels them right away.
+func (h *JobHandler) CancelJob(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	jobID := vars["id"]
+
+	grace := h.cancelGrace
+	if r.URL.Query().Get("force") == "true" {
+		grace = 0
+	}
+
+	job, err := cancelJob(h.jobRepo, jobID, "user_cancelled", requestActor(r), grace)
+	switch {
+	case errors.Is(err, errJobNotFound):
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	case errors.Is(err, errJobTerminal):
+		http.Error(w, fmt.Sprintf("Job is already %s", job.Status), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, "Failed to cancel job: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// TODO: Trigger cleanup (terminate instances, etc.)
+
+	response := map[string]interface{}{
+		"id":     job.ID,
+		"status": job.Status,
+	}
+	if job.Status == models.JobStatusCancelling {
+		response["teardown_after_seconds"] = int(grace.Seconds())
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// UncancelJob handles POST /v1/jobs/{id}/uncancel: restores a cancelling job
+// to running before its grace window ends
+func (h *JobHandler) UncancelJob(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	jobID := vars["id"]
+
+	job, err := h.jobRepo.GetJob(jobID)
+	if err != nil {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+	if job.Status != models.JobStatusCancelling {
+		http.Error(w, fmt.Sprintf("Only cancelling jobs can be restored (job is %s)", job.Status), http.StatusConflict)
+		return
+	}
+
+	err = h.jobRepo.UpdateJobStatus(job.ID, models.JobStatusCancelling, models.JobStatusRunning, "cancel_undone", map[string]interface{}{
+		"actor": requestActor(r),
+	})
+	if errors.Is(err, repository.ErrStatusConflict) {
+		http.Error(w, "The grace window ended or the job finished meanwhile", http.StatusConflict)
+		return
+	}
 	if err != nil {
-		http.Error(w, "Invalid job spec: "+err.Error(), http.StatusBadRequest)
+		http.Error(w, "Failed to restore job: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// Set user ID and name (TODO: Get from auth context)
-	job.UserID = "default-user" // TODO: Extract from auth token
-	job.Name = req.Name
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":     job.ID,
+		"status": models.JobStatusRunning,
+	})
+}
 
-	// Create job in database
-	if err := h.jobRepo.CreateJob(job); err != nil {
-		http.Error(w, "Failed to create job: "+err.Error(), http.StatusInternalServerError)
+// SuspendJob handles POST /v1/jobs/{id}/suspend. The job checkpoints, then
+// its instances are released until it is resumed; ?release_reservation=true
+// also hands its GPU-hour reservations back to its quotas.
+func (h *JobHandler) SuspendJob(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	jobID := vars["id"]
+
+	suspender := h.scheduler.Suspender()
+	if suspender == nil {
+		http.Error(w, "Job suspension is not enabled", http.StatusNotFound)
+		return
+	}
+	job, err := h.jobRepo.GetJob(jobID)
+	if err != nil {
+		http.Error(w, "Job not found", http.StatusNotFound)
 		return
 	}
 
-	// Enqueue job for scheduling
-	h.scheduler.Enqueue(job)
-
-	resp := SubmitJobResponse{
-		ID:        job.ID,
-		Status:    string(job.Status),
-		CreatedAt: job.CreatedAt,
+	release := r.URL.Query().Get("release_reservation") == "true"
+	suspension, err := suspender.Suspend(job, release, requestActor(r))
+	switch {
+	case errors.Is(err, repository.ErrStatusConflict), errors.Is(err, scheduler.ErrNotSuspendable):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, "Failed to suspend job: "+err.Error(), http.StatusInternalServerError)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(resp)
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(suspension)
 }
 
-// GetJob handles GET /v1/jobs/{id}
-func (h *JobHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+// ResumeJob handles POST /v1/jobs/{id}/resume: queues a suspended job to be
+// placed again at current prices
+func (h *JobHandler) ResumeJob(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	jobID := vars["id"]
 
+	suspender := h.scheduler.Suspender()
+	if suspender == nil {
+		http.Error(w, "Job suspension is not enabled", http.StatusNotFound)
+		return
+	}
 	job, err := h.jobRepo.GetJob(jobID)
 	if err != nil {
 		http.Error(w, "Job not found", http.StatusNotFound)
 		return
 	}
 
-	// Get allocations
-	allocations, _ := h.allocationRepo.GetAllocationsByJobID(jobID)
-
-	// Build response
-	response := map[string]interface{}{
-		"id":             job.ID,
-		"name":           job.Name,
-		"status":         job.Status,
-		"job_type":       job.JobType,
-		"framework":      job.Framework,
-		"execution_mode": job.Requirements.ExecutionMode,
-		"allocations":    allocations,
-		"cost": map[string]interface{}{
-			"running_usd":   job.CostRunningUSD,
-			"estimated_usd": job.CostEstimatedUSD,
-		},
-		"timestamps": map[string]interface{}{
-			"created_at":  job.CreatedAt,
-			"started_at":  job.StartedAt,
-			"finished_at": job.CompletedAt,
-		},
+	resumption, err := suspender.Resume(job, requestActor(r))
+	switch {
+	case errors.Is(err, repository.ErrStatusConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, "Failed to resume job: "+err.Error(), http.StatusInternalServerError)
+		return
 	}
 
-	if job.SelectedProvider != nil {
-		response["selected"] = map[string]interface{}{
-			"provider":      *job.SelectedProvider,
-			"region":        job.SelectedRegion,
-			"backend":       job.SelectedBackend,
-			"instance_type": allocations[0].InstanceType, // TODO: Handle multiple allocations
-			"spot":          allocations[0].Spot,
-			"count":         allocations[0].Count,
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resumption)
+}
+
+// Errors of cancelJob
+var (
+	errJobNotFound = errors.New("job not found")
+	errJobTerminal = errors.New("job is already terminal")
+)
+
+// cancelJob cancels a job from its current status, re-reading it if the
+// scheduler moved it meanwhile. With a grace window, running jobs move to
+// cancelling instead (recorded as cancel_requested with the reason) and jobs
+// already cancelling are left as they are; without one, both are cancelled
+// at once. Returns the job in its new status, or with errJobTerminal if it
+// already finished. actor is recorded as who cancelled it.
+func cancelJob(jobRepo *repository.JobRepository, jobID, reason, actor string, grace time.Duration) (*models.Job, error) {
+	for attempt := 0; ; attempt++ {
+		job, err := jobRepo.GetJob(jobID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errJobNotFound, err)
+		}
+		if job.Status.Terminal() {
+			return job, errJobTerminal
+		}
+		if grace > 0 && job.Status == models.JobStatusCancelling {
+			return job, nil
+		}
+
+		to, event := models.JobStatusCancelled, reason
+		meta := map[string]interface{}{
+			"actor": actor,
+		}
+		if grace > 0 && job.Status == models.JobStatusRunning {
+			to, event = models.JobStatusCancelling, "cancel_requested"
+			meta["reason"] = reason
+			meta["grace_seconds"] = int(grace.Seconds())
+		}
+		err = jobRepo.UpdateJobStatus(job.ID, job.Status, to, event, meta)
+		if err == nil {
+			job.Status = to
+			return job, nil
+		}
+		if !errors.Is(err, repository.ErrStatusConflict) || attempt >= 2 {
+			return nil, err
 		}
 	}
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+// maxPriorityBoost bounds operator boosts
+const maxPriorityBoost = 100
+
+// BoostJobRequest sets a pending job's priority boost
+type BoostJobRequest struct {
+	Boost  int    `json:"boost"` // 0 clears; higher boosts are scheduled first
+	Reason string `json:"reason,omitempty"`
 }
 
-// ListJobs handles GET /v1/jobs
-func (h *JobHandler) ListJobs(w http.ResponseWriter, r *http.Request) {
-	// Parse query parameters
-	statusParam := r.URL.Query().Get("status")
-	limit := 50 // Default limit
-	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
-		fmt.Sscanf(limitParam, "%d", &limit)
+// BoostJob handles POST /v1/jobs/{id}/boost (admin)
+func (h *JobHandler) BoostJob(w http.ResponseWriter, r *http.Request) {
+	if !h.admin.Allow(w, r) {
+		return
 	}
-	cursor := r.URL.Query().Get("cursor")
 
-	var status *models.JobStatus
-	if statusParam != "" {
-		s := models.JobStatus(statusParam)
-		status = &s
+	vars := mux.Vars(r)
+	jobID := vars["id"]
+
+	var req BoostJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Boost < 0 || req.Boost > maxPriorityBoost {
+		http.Error(w, fmt.Sprintf("boost must be between 0 and %d", maxPriorityBoost), http.StatusBadRequest)
+		return
 	}
 
-	// Fetch jobs from database
-	jobs, nextCursor, err := h.jobRepo.ListJobs("", status, limit, cursor)
+	job, err := h.jobRepo.GetJob(jobID)
 	if err != nil {
-		http.Error(w, "Failed to list jobs: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+	if job.Status != models.JobStatusPending {
+		http.Error(w, fmt.Sprintf("Only pending jobs can be boosted (job is %s)", job.Status), http.StatusConflict)
 		return
 	}
 
-	// Build response items
-	items := make([]map[string]interface{}, len(jobs))
-	for i, job := range jobs {
-		items[i] = map[string]interface{}{
-			"id":         job.ID,
-			"name":       job.Name,
-			"status":     job.Status,
-			"job_type":   job.JobType,
-			"framework":  job.Framework,
-			"created_at": job.CreatedAt,
-		}
+	meta := map[string]interface{}{
+		"from": job.PriorityBoost,
+		"to":   req.Boost,
+	}
+	if req.Reason != "" {
+		meta["reason"] = req.Reason
+	}
+	if err := h.jobRepo.SetPriorityBoost(job.ID, req.Boost, "priority_boosted", meta); err != nil {
+		http.Error(w, "Failed to boost job: "+err.Error(), http.StatusInternalServerError)
+		return
 	}
+	job.PriorityBoost = req.Boost
+	h.scheduler.Reprioritize(job)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"items":       items,
-		"next_cursor": nextCursor,
+		"id":             job.ID,
+		"priority_boost": job.PriorityBoost,
 	})
 }
 
-// CancelJob handles POST /v1/jobs/{id}/cancel
-func (h *JobHandler) CancelJob(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	jobID := vars["id"]
-
-	// Get current job status
-	job, err := h.jobRepo.GetJob(jobID)
+// GetQueue handles GET /v1/queue: pending jobs in projected scheduling order
+func (h *JobHandler) GetQueue(w http.ResponseWriter, r *http.Request) {
+	queued, err := h.scheduler.ProjectedQueue()
 	if err != nil {
-		http.Error(w, "Job not found", http.StatusNotFound)
+		http.Error(w, "Failed to project queue: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// Update job status to cancelled
-	if err := h.jobRepo.UpdateJobStatus(job.ID, job.Status, models.JobStatusCancelled, "user_cancelled", nil); err != nil {
-		http.Error(w, "Failed to cancel job: "+err.Error(), http.StatusInternalServerError)
-		return
+	items := make([]map[string]interface{}, len(queued))
+	for i, item := range queued {
+		items[i] = map[string]interface{}{
+			"position":       i + 1,
+			"id":             item.Job.ID,
+			"name":           item.Job.Name,
+			"priority":       item.Priority,
+			"priority_boost": item.Job.PriorityBoost,
+			"deadline":       item.Job.Constraints.Deadline,
+			"budget_usd":     item.Job.Constraints.MaxBudget,
+			"created_at":     item.Job.CreatedAt,
+		}
 	}
 
-	// TODO: Trigger cleanup (terminate instances, etc.)
-
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"id":     job.ID,
-		"status": "cancelled",
+		"items": items,
 	})
 }
 
@@ -241,6 +1462,12 @@ func (h *JobHandler) GetJobEvents(w http.ResponseWriter, r *http.Request) {
 		if event.FromStatus != nil {
 			item["from_status"] = *event.FromStatus
 		}
+		if event.ArtifactID != nil {
+			item["artifact_id"] = *event.ArtifactID
+		}
+		if len(event.MetaJSON) > 0 {
+			item["meta"] = event.MetaJSON
+		}
 		items[i] = item
 	}
 
@@ -279,11 +1506,17 @@ func (h *JobHandler) GetJobArtifacts(w http.ResponseWriter, r *http.Request) {
 	// Build response items
 	items := make([]map[string]interface{}, len(artifacts))
 	for i, artifact := range artifacts {
-		items[i] = map[string]interface{}{
+		item := map[string]interface{}{
+			"id":         artifact.ID,
 			"type":       artifact.Type,
 			"uri":        artifact.URI,
 			"created_at": artifact.CreatedAt,
+			"pinned":     artifact.Pinned,
+		}
+		if len(artifact.MetaJSON) > 0 {
+			item["meta"] = artifact.MetaJSON
 		}
+		items[i] = item
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -291,3 +1524,225 @@ func (h *JobHandler) GetJobArtifacts(w http.ResponseWriter, r *http.Request) {
 		"items": items,
 	})
 }
+
+// UpdateArtifactRequest is the body of PATCH /v1/jobs/{id}/artifacts/{artifactId}
+type UpdateArtifactRequest struct {
+	Pinned *bool `json:"pinned"`
+}
+
+// UpdateArtifact handles PATCH /v1/jobs/{id}/artifacts/{artifactId}.
+// Pinned artifacts are never deleted by retention GC.
+func (h *JobHandler) UpdateArtifact(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	jobID := vars["id"]
+	artifactID, err := strconv.ParseInt(vars["artifactId"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid artifact ID", http.StatusBadRequest)
+		return
+	}
+
+	var req UpdateArtifactRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Pinned == nil {
+		http.Error(w, "Invalid request body: pinned is required", http.StatusBadRequest)
+		return
+	}
+
+	artifact, err := h.artifactRepo.SetPinned(jobID, artifactID, *req.Pinned)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "Artifact not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to update artifact: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":         artifact.ID,
+		"type":       artifact.Type,
+		"uri":        artifact.URI,
+		"created_at": artifact.CreatedAt,
+		"pinned":     artifact.Pinned,
+		"deleted_at": artifact.DeletedAt,
+	})
+}
+
+// GetElasticHosts handles GET /v1/jobs/{id}/elastic/hosts
+// Returns the live host list ("address:slots" per line) polled by the Horovod Elastic discovery script
+func (h *JobHandler) GetElasticHosts(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	jobID := vars["id"]
+
+	manager := h.scheduler.ElasticManager()
+	if manager == nil {
+		http.Error(w, "Elastic scaling is not enabled", http.StatusNotFound)
+		return
+	}
+
+	hosts, ok := manager.DiscoveryHosts(jobID)
+	if !ok {
+		http.Error(w, "Job has no elastic cluster", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	for _, host := range hosts {
+		fmt.Fprintln(w, host)
+	}
+}
+
+// ExtendSessionRequest represents the request to extend an interactive session
+type ExtendSessionRequest struct {
+	Duration string `json:"duration"` // Go duration, e.g. "2h"
+}
+
+// ExtendSession handles POST /v1/jobs/{id}/extend
+func (h *JobHandler) ExtendSession(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	jobID := vars["id"]
+
+	var req ExtendSessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	extension, err := time.ParseDuration(req.Duration)
+	if err != nil || extension <= 0 {
+		http.Error(w, "duration must be a positive Go duration such as \"2h\"", http.StatusBadRequest)
+		return
+	}
+
+	sessions := h.scheduler.SessionManager()
+	if sessions == nil {
+		http.Error(w, "Interactive sessions are not enabled", http.StatusNotFound)
+		return
+	}
+
+	expiresAt, err := sessions.Extend(jobID, extension)
+	switch {
+	case errors.Is(err, scheduler.ErrSessionNotFound):
+		http.Error(w, "Job has no running session", http.StatusNotFound)
+		return
+	case errors.Is(err, scheduler.ErrSessionOverBudget):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	case err != nil:
+		http.Error(w, "Failed to extend session: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":         jobID,
+		"expires_at": expiresAt,
+	})
+}
+
+// SessionActivityRequest is a GPU utilization report from a session node
+type SessionActivityRequest struct {
+	GPUUtilization float64 `json:"gpu_utilization"` // Average percent across GPUs
+}
+
+// RecordSessionActivity handles POST /v1/jobs/{id}/session/activity
+func (h *JobHandler) RecordSessionActivity(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	jobID := vars["id"]
+
+	var req SessionActivityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	sessions := h.scheduler.SessionManager()
+	if sessions == nil {
+		http.Error(w, "Interactive sessions are not enabled", http.StatusNotFound)
+		return
+	}
+	if err := sessions.RecordUtilization(jobID, req.GPUUtilization); err != nil {
+		http.Error(w, "Job has no running session", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ProgressReportRequest is a step count reported by a training job
+type ProgressReportRequest struct {
+	StepsCompleted  int64 `json:"steps_completed"`
+	TokensProcessed int64 `json:"tokens_processed,omitempty"` // Cumulative; reconciles $/1M tokens
+}
+
+// ReportProgress handles POST /v1/jobs/{id}/progress
+func (h *JobHandler) ReportProgress(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	jobID := vars["id"]
+
+	var req ProgressReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.StepsCompleted < 0 || req.TokensProcessed < 0 {
+		http.Error(w, "steps_completed and tokens_processed must be non-negative", http.StatusBadRequest)
+		return
+	}
+
+	progress, err := h.jobRepo.RecordProgress(jobID, req.StepsCompleted, req.TokensProcessed, time.Now())
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	case errors.Is(err, repository.ErrStatusConflict):
+		http.Error(w, "Job is not running", http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, "Failed to record progress: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// One sample per run, once its rate is past warmup
+	if h.benchmarks != nil && progress.Intervals == models.ProgressWarmupIntervals {
+		h.observeBenchmark(jobID, progress)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// observeBenchmark records the per-GPU throughput of a single-cluster job
+// that declares its model class and runs on one GPU type
+func (h *JobHandler) observeBenchmark(jobID string, progress *models.TrainingProgress) {
+	job, err := h.jobRepo.GetJob(jobID)
+	if err != nil {
+		log.Printf("Failed to load job %s for benchmarks: %v", jobID, err)
+		return
+	}
+	if job.Requirements.ExecutionMode != models.ModeSingleCluster || job.Requirements.ModelClass == "" || job.Requirements.GPUs <= 0 {
+		return
+	}
+	allocations, err := h.allocationRepo.GetAllocationsByJobID(jobID)
+	if err != nil || len(allocations) == 0 {
+		return
+	}
+	gpuType := optimizer.GPUTypeForInstanceType(allocations[0].InstanceType)
+	for _, alloc := range allocations[1:] {
+		if optimizer.GPUTypeForInstanceType(alloc.InstanceType) != gpuType {
+			return
+		}
+	}
+
+	gpus := float64(job.Requirements.GPUs)
+	sample := models.BenchmarkSample{
+		Framework:    job.Framework,
+		GPUType:      gpuType,
+		ModelClass:   job.Requirements.ModelClass,
+		StepsPerHour: progress.StepsPerHour / gpus,
+	}
+	if progress.Tokens > 0 && progress.StepsCompleted > 0 {
+		tokensPerStep := float64(progress.Tokens) / float64(progress.StepsCompleted)
+		sample.TokensPerHour = progress.StepsPerHour * tokensPerStep / gpus
+	}
+	if err := h.benchmarks.Observe(sample); err != nil {
+		log.Printf("Failed to record benchmark for job %s: %v", jobID, err)
+	}
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/optimizer"
+)
+
+// LimitsHandler exposes and updates the optimizer's per-cluster node limits
+type LimitsHandler struct {
+	nodeLimits *optimizer.NodeLimits
+}
+
+// NewLimitsHandler creates a new limits handler
+func NewLimitsHandler(nodeLimits *optimizer.NodeLimits) *LimitsHandler {
+	return &LimitsHandler{nodeLimits: nodeLimits}
+}
+
+// ListLimits handles GET /v1/limits
+func (h *LimitsHandler) ListLimits(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"items": h.nodeLimits.List(),
+	})
+}
+
+// UpdateLimitRequest is the body of PATCH /v1/limits
+type UpdateLimitRequest struct {
+	Provider       string `json:"provider"`
+	Region         string `json:"region,omitempty"`
+	InstanceFamily string `json:"instance_family,omitempty"`
+	MaxNodes       int    `json:"max_nodes"`
+}
+
+// UpdateLimit handles PATCH /v1/limits (admin).
+// The new limit applies to the next optimization without a restart.
+func (h *LimitsHandler) UpdateLimit(w http.ResponseWriter, r *http.Request) {
+	// TODO: Restrict to admins once auth is in place
+	var req UpdateLimitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	limit := optimizer.NodeLimit{
+		Provider:       models.Provider(req.Provider),
+		Region:         req.Region,
+		InstanceFamily: req.InstanceFamily,
+		MaxNodes:       req.MaxNodes,
+		Source:         optimizer.LimitSourceAdmin,
+	}
+	if err := h.nodeLimits.Set(limit); err != nil {
+		http.Error(w, "Invalid limit: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(limit)
+}
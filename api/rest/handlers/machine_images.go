@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/repository"
+
+	"github.com/gorilla/mux"
+)
+
+// machineImageBootsShown is how many recent boots GetImage returns
+const machineImageBootsShown = 20
+
+// MachineImageHandler manages the registry of pre-baked machine images
+// (admin). Validated images are preferred for their provider, region and
+// instance family and are the allow list of resources.image.
+type MachineImageHandler struct {
+	repo          *repository.MachineImageRepository
+	admin         *AdminAuth
+	defaultBudget time.Duration // Boot budget of images registered without one
+}
+
+// NewMachineImageHandler creates a new machine image handler
+func NewMachineImageHandler(repo *repository.MachineImageRepository, admin *AdminAuth, defaultBudget time.Duration) *MachineImageHandler {
+	return &MachineImageHandler{repo: repo, admin: admin, defaultBudget: defaultBudget}
+}
+
+// MachineImageRequest is the body of POST /v1/admin/images. PUT only reads
+// build_date, validation and boot_budget_seconds.
+type MachineImageRequest struct {
+	Provider          models.Provider        `json:"provider"`
+	Region            string                 `json:"region"`
+	InstanceFamily    string                 `json:"instance_family"`
+	ImageID           string                 `json:"image_id"`
+	BuildDate         string                 `json:"build_date,omitempty"` // YYYY-MM-DD
+	Validation        models.ImageValidation `json:"validation,omitempty"` // Default pending
+	BootBudgetSeconds int                    `json:"boot_budget_seconds,omitempty"`
+}
+
+// CreateImage handles POST /v1/admin/images (admin)
+func (h *MachineImageHandler) CreateImage(w http.ResponseWriter, r *http.Request) {
+	if !h.admin.Allow(w, r) {
+		return
+	}
+	var req MachineImageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	image, err := h.imageFromRequest(req)
+	if err == nil {
+		err = validateImageScope(image)
+	}
+	if err != nil {
+		http.Error(w, "Invalid machine image: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.repo.CreateImage(image)
+	if errors.Is(err, repository.ErrImageExists) {
+		http.Error(w, fmt.Sprintf("Image %s is already registered for %s %s %s", image.ImageID, image.Provider, image.Region, image.InstanceFamily), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to register machine image: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Registered machine image %s for %s %s %s as %s (by %s)",
+		image.ImageID, image.Provider, image.Region, image.InstanceFamily, image.Validation, r.RemoteAddr)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(machineImageResponse(image))
+}
+
+// ListImages handles GET /v1/admin/images (admin); ?provider= filters
+func (h *MachineImageHandler) ListImages(w http.ResponseWriter, r *http.Request) {
+	if !h.admin.Allow(w, r) {
+		return
+	}
+	images, err := h.repo.ListImages(models.Provider(r.URL.Query().Get("provider")))
+	if err != nil {
+		http.Error(w, "Failed to list machine images: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	items := make([]map[string]interface{}, len(images))
+	for i := range images {
+		items[i] = machineImageResponse(&images[i])
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"items": items,
+	})
+}
+
+// GetImage handles GET /v1/admin/images/{id} (admin). It includes the boots
+// observed since the image was last validated, newest first.
+func (h *MachineImageHandler) GetImage(w http.ResponseWriter, r *http.Request) {
+	if !h.admin.Allow(w, r) {
+		return
+	}
+	image, ok := h.resolve(w, r)
+	if !ok {
+		return
+	}
+	boots, err := h.repo.RecentBoots(image.ID, machineImageBootsShown)
+	if err != nil {
+		http.Error(w, "Failed to fetch image boots: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if boots == nil {
+		boots = []models.ImageBoot{}
+	}
+
+	item := machineImageResponse(image)
+	item["boots"] = boots
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(item)
+}
+
+// UpdateImage handles PUT /v1/admin/images/{id} (admin). Setting validation
+// to validated clears a degradation, and only later boots count toward the
+// next one.
+func (h *MachineImageHandler) UpdateImage(w http.ResponseWriter, r *http.Request) {
+	if !h.admin.Allow(w, r) {
+		return
+	}
+	existing, ok := h.resolve(w, r)
+	if !ok {
+		return
+	}
+	var req MachineImageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.Provider, req.Region, req.InstanceFamily, req.ImageID = existing.Provider, existing.Region, existing.InstanceFamily, existing.ImageID
+	if req.BootBudgetSeconds == 0 {
+		req.BootBudgetSeconds = int(existing.BootBudget.Seconds())
+	}
+	image, err := h.imageFromRequest(req)
+	if err != nil {
+		http.Error(w, "Invalid machine image: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	updated, err := h.repo.UpdateImage(existing.ID, image.BuildDate, image.Validation, image.BootBudget)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "Machine image not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to update machine image: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Updated machine image %s for %s %s %s: %s (by %s)",
+		updated.ImageID, updated.Provider, updated.Region, updated.InstanceFamily, updated.Validation, r.RemoteAddr)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(machineImageResponse(updated))
+}
+
+// DeleteImage handles DELETE /v1/admin/images/{id} (admin). Jobs that pinned
+// the image and have not launched yet fail to provision.
+func (h *MachineImageHandler) DeleteImage(w http.ResponseWriter, r *http.Request) {
+	if !h.admin.Allow(w, r) {
+		return
+	}
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid image ID", http.StatusBadRequest)
+		return
+	}
+
+	err = h.repo.DeleteImage(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "Machine image not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to delete machine image: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Deleted machine image %d (by %s)", id, r.RemoteAddr)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// resolve loads the image named by the {id} route variable, writing a 404 if
+// there is none
+func (h *MachineImageHandler) resolve(w http.ResponseWriter, r *http.Request) (*models.MachineImage, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid image ID", http.StatusBadRequest)
+		return nil, false
+	}
+	image, err := h.repo.GetImage(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "Machine image not found", http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		http.Error(w, "Failed to fetch machine image: "+err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	return image, true
+}
+
+// imageFromRequest validates a request's build date, validation and budget
+func (h *MachineImageHandler) imageFromRequest(req MachineImageRequest) (*models.MachineImage, error) {
+	image := &models.MachineImage{
+		Provider:       req.Provider,
+		Region:         strings.TrimSpace(req.Region),
+		InstanceFamily: strings.TrimSpace(req.InstanceFamily),
+		ImageID:        strings.TrimSpace(req.ImageID),
+		Validation:     req.Validation,
+		BootBudget:     time.Duration(req.BootBudgetSeconds) * time.Second,
+	}
+	if req.BuildDate != "" {
+		buildDate, err := time.Parse("2006-01-02", req.BuildDate)
+		if err != nil {
+			return nil, fmt.Errorf("build_date must be YYYY-MM-DD")
+		}
+		image.BuildDate = &buildDate
+	}
+	switch image.Validation {
+	case "":
+		image.Validation = models.ImageValidationPending
+	case models.ImageValidationPending, models.ImageValidationValidated, models.ImageValidationFailed:
+	default:
+		return nil, fmt.Errorf("validation must be pending, validated or failed")
+	}
+	if req.BootBudgetSeconds < 0 {
+		return nil, fmt.Errorf("boot_budget_seconds must be positive")
+	}
+	if image.BootBudget == 0 {
+		image.BootBudget = h.defaultBudget
+	}
+	return image, nil
+}
+
+// validateImageScope checks the provider, region, family and image of a new
+// registration
+func validateImageScope(image *models.MachineImage) error {
+	switch image.Provider {
+	case models.ProviderAWS, models.ProviderGCP, models.ProviderAzure:
+	default:
+		return fmt.Errorf("provider must be aws, gcp or azure")
+	}
+	if image.Region == "" || image.InstanceFamily == "" || image.ImageID == "" {
+		return fmt.Errorf("region, instance_family and image_id are required")
+	}
+	if family := models.InstanceFamily(image.InstanceFamily); family != image.InstanceFamily {
+		return fmt.Errorf("instance_family must be a family such as %s, not an instance type", family)
+	}
+	return nil
+}
+
+// machineImageResponse builds the API representation of a machine image
+func machineImageResponse(image *models.MachineImage) map[string]interface{} {
+	item := map[string]interface{}{
+		"id":                  image.ID,
+		"provider":            image.Provider,
+		"region":              image.Region,
+		"instance_family":     image.InstanceFamily,
+		"image_id":            image.ImageID,
+		"validation":          image.Validation,
+		"boot_budget_seconds": int(image.BootBudget.Seconds()),
+		"degraded":            image.DegradedAt != nil,
+		"created_at":          image.CreatedAt,
+		"updated_at":          image.UpdatedAt,
+	}
+	if image.BuildDate != nil {
+		item["build_date"] = image.BuildDate.Format("2006-01-02")
+	}
+	if image.ValidatedAt != nil {
+		item["validated_at"] = image.ValidatedAt
+	}
+	if image.DegradedAt != nil {
+		item["degraded_at"] = image.DegradedAt
+		item["degraded_reason"] = image.DegradedReason
+	}
+	return item
+}
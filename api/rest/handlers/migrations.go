@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/repository"
+
+	"github.com/gorilla/mux"
+)
+
+// MigrationHandler serves the migration advisor's recorded recommendations
+type MigrationHandler struct {
+	jobRepo       *repository.JobRepository
+	migrationRepo *repository.MigrationRepository
+}
+
+// NewMigrationHandler creates a new migration advice handler
+func NewMigrationHandler(jobRepo *repository.JobRepository, migrationRepo *repository.MigrationRepository) *MigrationHandler {
+	return &MigrationHandler{jobRepo: jobRepo, migrationRepo: migrationRepo}
+}
+
+// GetJobMigrations handles GET /v1/jobs/{id}/migrations.
+// Every recommendation includes the inputs its savings were computed from.
+func (h *MigrationHandler) GetJobMigrations(w http.ResponseWriter, r *http.Request) {
+	jobID := mux.Vars(r)["id"]
+	if _, err := h.jobRepo.GetJob(jobID); err != nil {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	advice, err := h.migrationRepo.ListAdvice(jobID)
+	if err != nil {
+		http.Error(w, "Failed to fetch migration advice: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if advice == nil {
+		advice = []models.MigrationAdvice{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"items": advice,
+	})
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/policy"
+	"gpu-orchestrator/core/repository"
+
+	"github.com/gorilla/mux"
+)
+
+// PolicyHandler handles org policy rule HTTP requests
+type PolicyHandler struct {
+	policyRepo *repository.PolicyRepository
+}
+
+// NewPolicyHandler creates a new policy handler
+func NewPolicyHandler(policyRepo *repository.PolicyRepository) *PolicyHandler {
+	return &PolicyHandler{policyRepo: policyRepo}
+}
+
+// ListPolicies handles GET /v1/policies
+func (h *PolicyHandler) ListPolicies(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.policyRepo.ListRules(false)
+	if err != nil {
+		http.Error(w, "Failed to list policies: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	items := make([]map[string]interface{}, len(rules))
+	for i, rule := range rules {
+		items[i] = policyRuleResponse(rule)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"items": items,
+	})
+}
+
+// UpsertPolicy handles POST /v1/policies (rules are keyed by name)
+func (h *PolicyHandler) UpsertPolicy(w http.ResponseWriter, r *http.Request) {
+	var spec policy.RuleSpec
+	if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := spec.ToRule()
+	if err != nil {
+		http.Error(w, "Invalid policy: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.policyRepo.UpsertRule(rule); err != nil {
+		http.Error(w, "Failed to save policy: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(policyRuleResponse(rule))
+}
+
+// DeletePolicy handles DELETE /v1/policies/{name}
+func (h *PolicyHandler) DeletePolicy(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	deleted, err := h.policyRepo.DeleteRule(name)
+	if err != nil {
+		http.Error(w, "Failed to delete policy: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !deleted {
+		http.Error(w, "Policy not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// policyRuleResponse builds the API representation of a policy rule
+func policyRuleResponse(rule *models.PolicyRule) map[string]interface{} {
+	item := map[string]interface{}{
+		"id":         rule.ID,
+		"name":       rule.Name,
+		"type":       rule.Type,
+		"enabled":    rule.Enabled,
+		"created_at": rule.CreatedAt,
+	}
+	if rule.Type == models.PolicyConditional {
+		item["when"] = rule.When
+		item["then"] = rule.Then
+	} else {
+		item["check"] = rule.Check
+	}
+	if rule.Message != "" {
+		item["message"] = rule.Message
+	}
+	return item
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/storage"
+
+	"github.com/gorilla/mux"
+)
+
+// PostmortemHandler serves the post-mortem bundles of failed jobs
+type PostmortemHandler struct {
+	jobRepo      *repository.JobRepository
+	artifactRepo *repository.ArtifactRepository
+	stores       *storage.Registry
+	urlTTL       time.Duration
+}
+
+// NewPostmortemHandler creates a handler issuing download URLs valid for urlTTL
+func NewPostmortemHandler(jobRepo *repository.JobRepository, artifactRepo *repository.ArtifactRepository, stores *storage.Registry, urlTTL time.Duration) *PostmortemHandler {
+	return &PostmortemHandler{jobRepo: jobRepo, artifactRepo: artifactRepo, stores: stores, urlTTL: urlTTL}
+}
+
+// GetPostmortem handles GET /v1/jobs/{id}/postmortem. It returns the newest
+// post-mortem bundle of the job with a temporary download URL.
+func (h *PostmortemHandler) GetPostmortem(w http.ResponseWriter, r *http.Request) {
+	jobID := mux.Vars(r)["id"]
+
+	if _, err := h.jobRepo.GetJob(jobID); err != nil {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+	artifactType := models.ArtifactTypePostmortem
+	artifacts, err := h.artifactRepo.GetJobArtifacts(jobID, &artifactType)
+	if err != nil {
+		http.Error(w, "Failed to fetch artifacts: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(artifacts) == 0 {
+		http.Error(w, "No post-mortem bundle for this job", http.StatusNotFound)
+		return
+	}
+	bundle := artifacts[0]
+
+	response := map[string]interface{}{
+		"artifact_id": bundle.ID,
+		"uri":         bundle.URI,
+		"created_at":  bundle.CreatedAt,
+		"meta":        bundle.MetaJSON,
+	}
+	downloadURL, err := h.stores.PresignGet(r.Context(), bundle.URI, h.urlTTL)
+	switch {
+	case errors.Is(err, storage.ErrPresignUnsupported):
+		response["download_error"] = err.Error()
+	case err != nil:
+		http.Error(w, "Failed to sign download URL: "+err.Error(), http.StatusBadGateway)
+		return
+	default:
+		response["download_url"] = downloadURL
+		response["expires_at"] = time.Now().Add(h.urlTTL).UTC()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/optimizer"
+)
+
+// PricingHandler exposes the inputs the optimizer prices allocations with
+type PricingHandler struct {
+	interruptions *optimizer.InterruptionModel
+}
+
+// NewPricingHandler creates a new pricing handler. Without an interruption
+// model only static interruption rates are reported.
+func NewPricingHandler(interruptions *optimizer.InterruptionModel) *PricingHandler {
+	return &PricingHandler{interruptions: interruptions}
+}
+
+// ListInterruptionRates handles GET /v1/pricing/interruptions.
+// Lists the observed spot interruption rates per instance type and region,
+// blended with the static estimates; ?provider= filters by provider.
+func (h *PricingHandler) ListInterruptionRates(w http.ResponseWriter, r *http.Request) {
+	provider := models.Provider(r.URL.Query().Get("provider"))
+
+	rates := []models.InterruptionRate{}
+	if h.interruptions != nil {
+		for _, rate := range h.interruptions.Rates() {
+			if provider == "" || rate.Provider == provider {
+				rates = append(rates, rate)
+			}
+		}
+	}
+
+	static := make(map[models.Provider]float64)
+	for _, p := range []models.Provider{models.ProviderAWS, models.ProviderGCP, models.ProviderAzure, models.ProviderCoreWeave} {
+		if provider == "" || p == provider {
+			static[p] = optimizer.StaticInterruptionRate(p)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"items":        rates,
+		"static_rates": static,
+		"window_days":  int(optimizer.InterruptionWindow.Hours() / 24),
+		"prior_hours":  optimizer.InterruptionPriorHours,
+	})
+}
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/monitoring"
+	"gpu-orchestrator/core/repository"
+
+	"github.com/gorilla/mux"
+)
+
+// PricingSubscriptionHandler handles pricing subscription requests. Every
+// caller manages their own subscriptions; others' subscriptions are not found.
+type PricingSubscriptionHandler struct {
+	repo  *repository.PricingSubscriptionRepository
+	limit int // Subscriptions per user
+}
+
+// NewPricingSubscriptionHandler creates a new pricing subscription handler
+func NewPricingSubscriptionHandler(repo *repository.PricingSubscriptionRepository, limit int) *PricingSubscriptionHandler {
+	return &PricingSubscriptionHandler{repo: repo, limit: limit}
+}
+
+// CreateSubscription handles POST /v1/pricing/subscriptions
+func (h *PricingSubscriptionHandler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
+	var spec monitoring.PricingSubscriptionSpec
+	if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	sub, err := spec.ToSubscription(requestActor(r))
+	if err != nil {
+		http.Error(w, "Invalid pricing subscription: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.repo.CreateSubscription(sub, h.limit)
+	if errors.Is(err, repository.ErrSubscriptionLimit) {
+		http.Error(w, fmt.Sprintf("At most %d pricing subscriptions per user", h.limit), http.StatusConflict)
+		return
+	}
+	if errors.Is(err, repository.ErrSubscriptionExists) {
+		http.Error(w, "Pricing subscription "+sub.Name+" already exists", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to save pricing subscription: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(pricingSubscriptionResponse(sub))
+}
+
+// ListSubscriptions handles GET /v1/pricing/subscriptions
+func (h *PricingSubscriptionHandler) ListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	subs, err := h.repo.ListSubscriptions(requestActor(r))
+	if err != nil {
+		http.Error(w, "Failed to list pricing subscriptions: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	items := make([]map[string]interface{}, len(subs))
+	for i := range subs {
+		items[i] = pricingSubscriptionResponse(&subs[i])
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"items": items,
+		"limit": h.limit,
+	})
+}
+
+// GetSubscription handles GET /v1/pricing/subscriptions/{id}
+func (h *PricingSubscriptionHandler) GetSubscription(w http.ResponseWriter, r *http.Request) {
+	sub, ok := h.resolve(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pricingSubscriptionResponse(sub))
+}
+
+// UpdateSubscription handles PUT /v1/pricing/subscriptions/{id}. The body
+// replaces the subscription; a condition that still holds notifies again.
+func (h *PricingSubscriptionHandler) UpdateSubscription(w http.ResponseWriter, r *http.Request) {
+	existing, ok := h.resolve(w, r)
+	if !ok {
+		return
+	}
+	var spec monitoring.PricingSubscriptionSpec
+	if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	sub, err := spec.ToSubscription(existing.UserID)
+	if err != nil {
+		http.Error(w, "Invalid pricing subscription: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	sub.ID = existing.ID
+
+	err = h.repo.UpdateSubscription(sub)
+	if errors.Is(err, repository.ErrSubscriptionExists) {
+		http.Error(w, "Pricing subscription "+sub.Name+" already exists", http.StatusConflict)
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "Pricing subscription not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to save pricing subscription: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pricingSubscriptionResponse(sub))
+}
+
+// DeleteSubscription handles DELETE /v1/pricing/subscriptions/{id}
+func (h *PricingSubscriptionHandler) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid subscription ID", http.StatusBadRequest)
+		return
+	}
+
+	err = h.repo.DeleteSubscription(requestActor(r), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "Pricing subscription not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to delete pricing subscription: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListNotifications handles GET /v1/pricing/subscriptions/{id}/notifications.
+// Open notifications (no cleared_at) are conditions that still hold.
+func (h *PricingSubscriptionHandler) ListNotifications(w http.ResponseWriter, r *http.Request) {
+	sub, ok := h.resolve(w, r)
+	if !ok {
+		return
+	}
+	limit := 100 // Default limit
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		fmt.Sscanf(limitParam, "%d", &limit)
+	}
+
+	notifications, err := h.repo.ListNotifications(sub.ID, limit)
+	if err != nil {
+		http.Error(w, "Failed to list pricing notifications: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if notifications == nil {
+		notifications = []models.PriceNotification{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"items": notifications,
+	})
+}
+
+// resolve loads the caller's subscription named by the {id} route variable,
+// writing a 404 if there is none
+func (h *PricingSubscriptionHandler) resolve(w http.ResponseWriter, r *http.Request) (*models.PricingSubscription, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid subscription ID", http.StatusBadRequest)
+		return nil, false
+	}
+	sub, err := h.repo.GetSubscription(requestActor(r), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "Pricing subscription not found", http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		http.Error(w, "Failed to fetch pricing subscription: "+err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	return sub, true
+}
+
+// pricingSubscriptionResponse builds the API representation of a pricing subscription
+func pricingSubscriptionResponse(sub *models.PricingSubscription) map[string]interface{} {
+	item := map[string]interface{}{
+		"id":             sub.ID,
+		"name":           sub.Name,
+		"instance_types": nonNilList(sub.InstanceTypes),
+		"gpu_types":      nonNilList(sub.GPUTypes),
+		"regions":        nonNilList(sub.Regions),
+		"kind":           sub.Kind,
+		"condition":      sub.Condition,
+		"threshold":      sub.Threshold,
+		"enabled":        sub.Enabled,
+		"created_at":     sub.CreatedAt,
+		"updated_at":     sub.UpdatedAt,
+	}
+	if sub.Window > 0 {
+		item["window"] = sub.Window.String()
+	}
+	if sub.WebhookURL != "" {
+		item["webhook_url"] = sub.WebhookURL
+	}
+	return item
+}
+
+// nonNilList returns list, or an empty list for nil so it encodes as []
+func nonNilList(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
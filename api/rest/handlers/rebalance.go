@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/repository"
+
+	"github.com/gorilla/mux"
+)
+
+// RebalanceHandler serves spot rebalance recommendations and the checkpoint
+// requests they raise
+type RebalanceHandler struct {
+	jobRepo       *repository.JobRepository
+	rebalanceRepo *repository.RebalanceRepository
+}
+
+// NewRebalanceHandler creates a new rebalance handler
+func NewRebalanceHandler(jobRepo *repository.JobRepository, rebalanceRepo *repository.RebalanceRepository) *RebalanceHandler {
+	return &RebalanceHandler{jobRepo: jobRepo, rebalanceRepo: rebalanceRepo}
+}
+
+// GetJobRebalance handles GET /v1/jobs/{id}/rebalance: the job's rebalance
+// recommendations with the checkpoint and replacement node of each
+func (h *RebalanceHandler) GetJobRebalance(w http.ResponseWriter, r *http.Request) {
+	jobID := mux.Vars(r)["id"]
+	if _, err := h.jobRepo.GetJob(jobID); err != nil {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	signals, err := h.rebalanceRepo.ListSignals(jobID)
+	if err != nil {
+		http.Error(w, "Failed to fetch rebalance signals: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if signals == nil {
+		signals = []models.RebalanceSignal{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"items": signals,
+	})
+}
+
+// GetCheckpointRequest handles GET /v1/jobs/{id}/checkpoint-request. The
+// training wrapper polls it and writes a checkpoint as soon as requested is
+// true; the request is answered once the checkpoint is recorded as an artifact.
+// Requests come from rebalance recommendations and from suspending the job.
+func (h *RebalanceHandler) GetCheckpointRequest(w http.ResponseWriter, r *http.Request) {
+	jobID := mux.Vars(r)["id"]
+	job, err := h.jobRepo.GetJob(jobID)
+	if err != nil {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	signal, err := h.rebalanceRepo.PendingCheckpointRequest(jobID)
+	if err != nil {
+		http.Error(w, "Failed to fetch checkpoint request: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	request := models.CheckpointRequest{}
+	switch {
+	case signal != nil:
+		request = models.CheckpointRequest{
+			Requested:   true,
+			Reason:      models.CheckpointRequestRebalance,
+			InstanceID:  signal.InstanceID,
+			RequestedAt: signal.CheckpointRequestedAt,
+		}
+	case job.Status == models.JobStatusCheckpointing && job.SuspendRequestedAt != nil:
+		request = models.CheckpointRequest{
+			Requested:   true,
+			Reason:      models.CheckpointRequestSuspend,
+			RequestedAt: job.SuspendRequestedAt,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(request)
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"gpu-orchestrator/core/executor"
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/core/scheduler"
+
+	"github.com/gorilla/mux"
+)
+
+// SSHKeyHandler lists and rotates the per-cluster SSH keys of jobs (admin)
+type SSHKeyHandler struct {
+	jobRepo *repository.JobRepository
+	keys    *executor.ClusterKeys
+	sched   *scheduler.Scheduler
+	admin   *AdminAuth
+}
+
+// NewSSHKeyHandler creates a new SSH key handler
+func NewSSHKeyHandler(jobRepo *repository.JobRepository, keys *executor.ClusterKeys, sched *scheduler.Scheduler, admin *AdminAuth) *SSHKeyHandler {
+	return &SSHKeyHandler{jobRepo: jobRepo, keys: keys, sched: sched, admin: admin}
+}
+
+// ListKeys handles GET /v1/admin/jobs/{id}/ssh-keys (admin). It returns the
+// keys every cluster of the job has had, newest first; private keys are never
+// returned.
+func (h *SSHKeyHandler) ListKeys(w http.ResponseWriter, r *http.Request) {
+	if !h.admin.Allow(w, r) {
+		return
+	}
+	jobID := mux.Vars(r)["id"]
+	if _, err := h.jobRepo.GetJob(jobID); err != nil {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+	manifest, err := h.jobRepo.GetTopology(jobID)
+	if err != nil {
+		http.Error(w, "Failed to get topology: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	clusters := []map[string]interface{}{}
+	if manifest != nil {
+		for _, cluster := range manifest.Clusters {
+			keys, err := h.keys.Keys(cluster.ClusterID)
+			if err != nil {
+				http.Error(w, "Failed to list SSH keys: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if keys == nil {
+				keys = []models.ClusterSSHKey{}
+			}
+			clusters = append(clusters, map[string]interface{}{
+				"cluster_id": cluster.ClusterID,
+				"keys":       keys,
+			})
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"job_id":   jobID,
+		"clusters": clusters,
+	})
+}
+
+// RotateKey handles POST /v1/admin/jobs/{id}/ssh-keys/rotate (admin). The
+// running job's cluster gets a new key; the old one is retired once every
+// node accepts the new one.
+func (h *SSHKeyHandler) RotateKey(w http.ResponseWriter, r *http.Request) {
+	if !h.admin.Allow(w, r) {
+		return
+	}
+	jobID := mux.Vars(r)["id"]
+	cluster, ok := h.sched.RunningClusters()[jobID]
+	if !ok {
+		http.Error(w, fmt.Sprintf("Job %s has no running cluster", jobID), http.StatusConflict)
+		return
+	}
+
+	key, err := h.keys.Rotate(r.Context(), cluster, jobID, requestActor(r))
+	if err != nil {
+		http.Error(w, "Failed to rotate SSH key: "+err.Error(), http.StatusConflict)
+		return
+	}
+	log.Printf("Rotated SSH key of cluster %s to %s (by %s)", cluster.ID, key.Fingerprint, requestActor(r))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(key)
+}
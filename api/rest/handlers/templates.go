@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gpu-orchestrator/core/spec"
+)
+
+// TemplateHandler serves the built-in starter specs
+type TemplateHandler struct{}
+
+// NewTemplateHandler creates a new template handler
+func NewTemplateHandler() *TemplateHandler {
+	return &TemplateHandler{}
+}
+
+// ListBuiltinTemplates handles GET /v1/templates/builtin. Each spec holds
+// {{NAME}} placeholders for its parameters; submit it rendered, or extend it
+// by name and set every parameter.
+func (h *TemplateHandler) ListBuiltinTemplates(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"items": spec.BuiltinTemplates(),
+	})
+}
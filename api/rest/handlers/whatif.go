@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/core/scheduler"
+
+	"github.com/gorilla/mux"
+)
+
+// WhatIfHandler serves capacity-planning replays of past jobs
+type WhatIfHandler struct {
+	jobRepo    *repository.JobRepository
+	whatIfRepo *repository.WhatIfRepository
+	scheduler  *scheduler.Scheduler
+	syncJobs   int // Larger windows run in the background
+	maxJobs    int // Larger windows are rejected
+}
+
+// NewWhatIfHandler creates a new what-if handler
+func NewWhatIfHandler(jobRepo *repository.JobRepository, whatIfRepo *repository.WhatIfRepository, sched *scheduler.Scheduler, syncJobs, maxJobs int) *WhatIfHandler {
+	return &WhatIfHandler{
+		jobRepo:    jobRepo,
+		whatIfRepo: whatIfRepo,
+		scheduler:  sched,
+		syncJobs:   syncJobs,
+		maxJobs:    maxJobs,
+	}
+}
+
+// RunWhatIf handles POST /v1/whatif.
+// Replays the jobs created in [from, to) against today's supply and the
+// scenario. Small windows answer inline (200); larger ones return a run
+// record (202) to poll at GET /v1/whatif/{id}.
+func (h *WhatIfHandler) RunWhatIf(w http.ResponseWriter, r *http.Request) {
+	var req models.WhatIfRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.From.IsZero() || req.To.IsZero() || !req.From.Before(req.To) {
+		http.Error(w, "from and to are required and from must be before to", http.StatusBadRequest)
+		return
+	}
+	if err := req.Scenario.Validate(); err != nil {
+		http.Error(w, "Invalid scenario: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	count, err := h.jobRepo.CountJobsCreatedBetween(req.From, req.To)
+	if err != nil {
+		http.Error(w, "Failed to count jobs: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if count > h.maxJobs {
+		http.Error(w, fmt.Sprintf("Window has %d jobs, more than the %d a replay may cover; narrow it", count, h.maxJobs), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if count <= h.syncJobs {
+		result, err := h.replay(r.Context(), req)
+		if err != nil {
+			http.Error(w, "What-if replay failed: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"jobs":   count,
+			"result": result,
+		})
+		return
+	}
+
+	run := &models.WhatIfRun{Request: req, Jobs: count}
+	if err := h.whatIfRepo.CreateRun(run); err != nil {
+		http.Error(w, "Failed to record what-if run: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	go h.runAsync(run.ID, req)
+
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(run)
+}
+
+// GetWhatIfRun handles GET /v1/whatif/{id}
+func (h *WhatIfHandler) GetWhatIfRun(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid run ID", http.StatusBadRequest)
+		return
+	}
+
+	run, err := h.whatIfRepo.GetRun(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "What-if run not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to fetch what-if run: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(run)
+}
+
+// replay loads the window's jobs and runs both replays
+func (h *WhatIfHandler) replay(ctx context.Context, req models.WhatIfRequest) (*models.WhatIfResult, error) {
+	jobs, err := h.jobRepo.ListJobsCreatedBetween(req.From, req.To, h.maxJobs)
+	if err != nil {
+		return nil, err
+	}
+	return h.scheduler.WhatIf(ctx, jobs, req.Scenario)
+}
+
+// runAsync runs a replay in the background and records its outcome
+func (h *WhatIfHandler) runAsync(id int64, req models.WhatIfRequest) {
+	result, err := h.replay(context.Background(), req)
+	if err != nil {
+		log.Printf("What-if run %d failed: %v", id, err)
+		if err := h.whatIfRepo.FailRun(id, err); err != nil {
+			log.Printf("Failed to record failure of what-if run %d: %v", id, err)
+		}
+		return
+	}
+	if err := h.whatIfRepo.CompleteRun(id, result); err != nil {
+		log.Printf("Failed to record result of what-if run %d: %v", id, err)
+	}
+}
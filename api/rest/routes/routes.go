@@ -2,27 +2,213 @@ package routes
 
 import (
 	"gpu-orchestrator/api/rest/handlers"
+	"gpu-orchestrator/config"
+	"gpu-orchestrator/core/clock"
+	"gpu-orchestrator/core/executor"
+	"gpu-orchestrator/core/monitoring"
+	"gpu-orchestrator/core/optimizer"
+	"gpu-orchestrator/core/policy"
 	"gpu-orchestrator/core/repository"
 	"gpu-orchestrator/core/scheduler"
+	"gpu-orchestrator/core/spec"
+	"gpu-orchestrator/providers"
+	"gpu-orchestrator/storage"
 
 	"github.com/gorilla/mux"
 )
 
 // SetupRoutes configures all API routes
-func SetupRoutes(r *mux.Router, db *repository.DB, sched *scheduler.Scheduler) {
+func SetupRoutes(r *mux.Router, db *repository.DB, sched *scheduler.Scheduler, pricingFetcher *optimizer.PricingFetcher, artifactGC *storage.ArtifactGC, checkpointReplicator *storage.CheckpointReplicator, billingExporter *monitoring.BillingExporter, providerUsage *providers.UsageMeter, apiUsage *monitoring.APIUsageMeter, orphanCleaner *monitoring.OrphanCleaner, objectStores *storage.Registry, clusterKeys *executor.ClusterKeys, cfg *config.Config) {
 	jobRepo := repository.NewJobRepository(db)
 	allocationRepo := repository.NewAllocationRepository(db)
 	eventRepo := repository.NewEventRepository(db)
 	artifactRepo := repository.NewArtifactRepository(db)
-	jobHandler := handlers.NewJobHandler(jobRepo, allocationRepo, eventRepo, artifactRepo, sched)
+	taskRepo := repository.NewTaskRepository(db)
+	alertRepo := repository.NewAlertRepository(db)
+	jobHandler := handlers.NewJobHandler(jobRepo, allocationRepo, eventRepo, artifactRepo, taskRepo, sched, handlers.AdmissionConfig{
+		Mode:    cfg.AdmissionMode,
+		Timeout: cfg.AdmissionTimeout,
+	})
+	alertHandler := handlers.NewAlertHandler(alertRepo)
+	policyRepo := repository.NewPolicyRepository(db)
+	policyEngine := policy.NewEngine(policyRepo)
+	if cfg.PolicyWebhookURL != "" {
+		policyEngine.SetWebhook(policy.NewWebhook(cfg.PolicyWebhookURL, cfg.PolicyWebhookTimeout, cfg.PolicyWebhookFailOpen))
+	}
+	jobHandler.SetPolicyEngine(policyEngine)
+	adminAuth := handlers.NewAdminAuth(cfg.AdminAPIToken)
+	jobHandler.SetAdminAuth(adminAuth)
+	jobHandler.SetSpecOptions(spec.ParseOptions{
+		AllowUnknownFields: cfg.SpecUnknownFields == "warn",
+		Bases:              spec.NewBuiltinBases(spec.NewObjectStoreBases(objectStores)),
+		Clock:              clock.System,
+		ClockSkew:          cfg.ClockSkewAllowance,
+	})
+	experimentRepo := repository.NewExperimentRepository(db)
+	jobHandler.SetExperimentRepository(experimentRepo)
+	jobHandler.SetProviderUsage(providerUsage)
+	jobHandler.SetCancelGrace(cfg.CancelGrace)
+	jobHandler.SetBatchLimits(cfg.JobBatchMaxItems, cfg.JobBatchConcurrency)
+	jobHandler.SetBenchmarks(sched.PerformanceMetrics())
+	machineImageRepo := repository.NewMachineImageRepository(db)
+	jobHandler.SetMachineImageRepository(machineImageRepo)
+	experimentHandler := handlers.NewExperimentHandler(jobRepo, experimentRepo)
+	policyHandler := handlers.NewPolicyHandler(policyRepo)
+	limitsHandler := handlers.NewLimitsHandler(sched.NodeLimits())
+	artifactGCHandler := handlers.NewArtifactGCHandler(artifactGC, artifactRepo)
+	checkpointHandler := handlers.NewCheckpointHandler(jobRepo, artifactRepo, checkpointReplicator)
+	costHandler := handlers.NewCostHandler(billingExporter, jobRepo, cfg.CostExportURI)
+	adminHandler := handlers.NewAdminHandler(cfg, adminAuth)
+	adminHandler.SetProviderUsage(providerUsage, repository.NewProviderUsageRepository(db))
+	adminHandler.SetInstanceTypeRules(sched.InstanceTypeRules(), repository.NewInstanceTypeRuleRepository(db))
+	adminHandler.SetPricingFetcher(pricingFetcher)
+	adminHandler.SetConsistencyReports(repository.NewConsistencyRepository(db))
+	adminHandler.SetOrphanCleaner(orphanCleaner)
+	adminHandler.SetBenchmarks(sched.PerformanceMetrics())
+	if cfg.PriceAnomalyFactor > 0 {
+		adminHandler.SetPriceQuarantine(repository.NewPriceQuarantineRepository(db))
+	}
+	machineImageHandler := handlers.NewMachineImageHandler(machineImageRepo, adminAuth, cfg.ImageBootBudget)
+	migrationHandler := handlers.NewMigrationHandler(jobRepo, repository.NewMigrationRepository(db))
+	fairShareHandler := handlers.NewFairShareHandler(sched.FairShare())
+	gpuQuotaHandler := handlers.NewGPUQuotaHandler(repository.NewGPUQuotaRepository(db), sched.GPUQuotas(), adminAuth)
+	dataGravityHandler := handlers.NewDataGravityHandler(sched.DataGravity())
+	rebalanceHandler := handlers.NewRebalanceHandler(jobRepo, repository.NewRebalanceRepository(db))
+	fleetHandler := handlers.NewFleetHandler(allocationRepo, repository.NewMaintenanceRepository(db))
+	pricingHandler := handlers.NewPricingHandler(sched.InterruptionModel())
+	pricingSubscriptionHandler := handlers.NewPricingSubscriptionHandler(repository.NewPricingSubscriptionRepository(db), cfg.PricingSubscriptionLimit)
+	consoleHandler := handlers.NewConsoleHandler(jobRepo, artifactRepo, adminAuth, clusterKeys, objectStores, handlers.ConsoleConfig{
+		Enabled:       cfg.ConsoleAttachEnabled,
+		IdleTimeout:   cfg.ConsoleIdleTimeout,
+		TranscriptURI: cfg.ConsoleTranscriptURI,
+	})
+	sshKeyHandler := handlers.NewSSHKeyHandler(jobRepo, clusterKeys, sched, adminAuth)
+	postmortemHandler := handlers.NewPostmortemHandler(jobRepo, artifactRepo, objectStores, cfg.PostmortemURLTTL)
+	templateHandler := handlers.NewTemplateHandler()
+	whatIfHandler := handlers.NewWhatIfHandler(jobRepo, repository.NewWhatIfRepository(db), sched, cfg.WhatIfSyncJobs, cfg.WhatIfMaxJobs)
 
 	api := r.PathPrefix("/v1").Subrouter()
+	auditLog := handlers.NewAuditLog(repository.NewAuditRepository(db), adminAuth, cfg.AuditActorHeader)
+	api.Use(auditLog.Middleware)
+	apiUsageHandler := handlers.NewAPIUsageHandler(apiUsage, repository.NewAPIUsageRepository(db), adminAuth)
+	api.Use(apiUsageHandler.Middleware)
 
 	// Job endpoints
 	api.HandleFunc("/jobs", jobHandler.SubmitJob).Methods("POST")
+	api.HandleFunc("/jobs/batch", jobHandler.SubmitBatch).Methods("POST")
+	api.HandleFunc("/jobs/validate", jobHandler.ValidateJob).Methods("POST")
+	api.HandleFunc("/jobs/estimate", jobHandler.EstimateJob).Methods("POST")
 	api.HandleFunc("/jobs/{id}", jobHandler.GetJob).Methods("GET")
+	api.HandleFunc("/jobs/{id}", jobHandler.UpdateJob).Methods("PATCH")
 	api.HandleFunc("/jobs", jobHandler.ListJobs).Methods("GET")
 	api.HandleFunc("/jobs/{id}/cancel", jobHandler.CancelJob).Methods("POST")
+	api.HandleFunc("/jobs/{id}/uncancel", jobHandler.UncancelJob).Methods("POST")
+	api.HandleFunc("/jobs/{id}/suspend", jobHandler.SuspendJob).Methods("POST")
+	api.HandleFunc("/jobs/{id}/resume", jobHandler.ResumeJob).Methods("POST")
+	api.HandleFunc("/jobs/{id}/clone", jobHandler.CloneJob).Methods("POST")
+	api.HandleFunc("/jobs/{id}/boost", jobHandler.BoostJob).Methods("POST")
+	api.HandleFunc("/jobs/{id}/decision", jobHandler.GetJobDecision).Methods("GET")
+	api.HandleFunc("/jobs/{id}/compare", jobHandler.CompareJobs).Methods("GET")
+	api.HandleFunc("/jobs/{id}/cost", costHandler.GetJobCost).Methods("GET")
+	api.HandleFunc("/jobs/{id}/why-pending", jobHandler.GetWhyPending).Methods("GET")
+	api.HandleFunc("/jobs/{id}/topology", jobHandler.GetTopology).Methods("GET")
 	api.HandleFunc("/jobs/{id}/events", jobHandler.GetJobEvents).Methods("GET")
 	api.HandleFunc("/jobs/{id}/artifacts", jobHandler.GetJobArtifacts).Methods("GET")
+	api.HandleFunc("/jobs/{id}/artifacts/{artifactId}", jobHandler.UpdateArtifact).Methods("PATCH")
+	api.HandleFunc("/jobs/{id}/checkpoints", checkpointHandler.GetJobCheckpoints).Methods("GET")
+	api.HandleFunc("/jobs/{id}/migrations", migrationHandler.GetJobMigrations).Methods("GET")
+	api.HandleFunc("/jobs/{id}/rebalance", rebalanceHandler.GetJobRebalance).Methods("GET")
+	api.HandleFunc("/jobs/{id}/checkpoint-request", rebalanceHandler.GetCheckpointRequest).Methods("GET")
+	api.HandleFunc("/jobs/{id}/elastic/hosts", jobHandler.GetElasticHosts).Methods("GET")
+	api.HandleFunc("/jobs/{id}/extend", jobHandler.ExtendSession).Methods("POST")
+	api.HandleFunc("/jobs/{id}/session/activity", jobHandler.RecordSessionActivity).Methods("POST")
+	api.HandleFunc("/jobs/{id}/progress", jobHandler.ReportProgress).Methods("POST")
+	api.HandleFunc("/jobs/{id}/attach", consoleHandler.Attach).Methods("POST")
+	api.HandleFunc("/jobs/{id}/postmortem", postmortemHandler.GetPostmortem).Methods("GET")
+	api.HandleFunc("/queue", jobHandler.GetQueue).Methods("GET")
+
+	// Experiment endpoints
+	api.HandleFunc("/experiments", experimentHandler.CreateExperiment).Methods("POST")
+	api.HandleFunc("/experiments", experimentHandler.ListExperiments).Methods("GET")
+	api.HandleFunc("/experiments/{id}", experimentHandler.GetExperiment).Methods("GET")
+	api.HandleFunc("/experiments/{id}", experimentHandler.DeleteExperiment).Methods("DELETE")
+	api.HandleFunc("/experiments/{id}/cancel", experimentHandler.CancelExperiment).Methods("POST")
+
+	// Alert endpoints
+	api.HandleFunc("/alerts", alertHandler.ListAlerts).Methods("GET")
+	api.HandleFunc("/alerts/rules", alertHandler.ListRules).Methods("GET")
+	api.HandleFunc("/alerts/rules", alertHandler.UpsertRule).Methods("POST")
+
+	// Org policy endpoints
+	api.HandleFunc("/policies", policyHandler.ListPolicies).Methods("GET")
+	api.HandleFunc("/policies", policyHandler.UpsertPolicy).Methods("POST")
+	api.HandleFunc("/policies/{name}", policyHandler.DeletePolicy).Methods("DELETE")
+
+	// Node limit endpoints
+	api.HandleFunc("/limits", limitsHandler.ListLimits).Methods("GET")
+	api.HandleFunc("/limits", limitsHandler.UpdateLimit).Methods("PATCH")
+
+	// Artifact retention endpoints
+	api.HandleFunc("/artifacts/gc/runs", artifactGCHandler.ListGCRuns).Methods("GET")
+	api.HandleFunc("/artifacts/gc/runs", artifactGCHandler.TriggerGCRun).Methods("POST")
+	api.HandleFunc("/artifacts/gc/runs/{id}", artifactGCHandler.GetGCRun).Methods("GET")
+
+	// Billing export endpoints
+	api.HandleFunc("/costs/export", costHandler.ExportCosts).Methods("GET")
+	api.HandleFunc("/costs/exports", costHandler.StartCostExport).Methods("POST")
+	api.HandleFunc("/costs/exports/{id}", costHandler.GetCostExport).Methods("GET")
+
+	// Admin endpoints
+	api.HandleFunc("/admin/config", adminHandler.GetConfig).Methods("GET")
+	api.HandleFunc("/admin/providers/usage", adminHandler.GetProviderUsage).Methods("GET")
+	api.HandleFunc("/admin/api-usage", apiUsageHandler.GetUsage).Methods("GET")
+	api.HandleFunc("/admin/instance-types", adminHandler.GetInstanceTypes).Methods("GET")
+	api.HandleFunc("/admin/instance-types", adminHandler.UpdateInstanceTypes).Methods("PUT")
+	api.HandleFunc("/admin/pricing/status", adminHandler.GetPricingStatus).Methods("GET")
+	api.HandleFunc("/admin/pricing/quarantine", adminHandler.ListQuarantinedPrices).Methods("GET")
+	api.HandleFunc("/admin/pricing/quarantine/{id}/accept", adminHandler.AcceptQuarantinedPrice).Methods("POST")
+	api.HandleFunc("/admin/consistency", adminHandler.GetConsistencyReport).Methods("GET")
+	api.HandleFunc("/admin/orphans/cleanup", adminHandler.CleanOrphans).Methods("POST")
+	api.HandleFunc("/admin/benchmarks", adminHandler.GetBenchmarks).Methods("GET")
+	api.HandleFunc("/admin/jobs/{id}/ssh-keys", sshKeyHandler.ListKeys).Methods("GET")
+	api.HandleFunc("/admin/jobs/{id}/ssh-keys/rotate", sshKeyHandler.RotateKey).Methods("POST")
+	api.HandleFunc("/admin/gpu-quotas/{scope}/{id}", gpuQuotaHandler.PutQuota).Methods("PUT")
+	api.HandleFunc("/admin/gpu-quotas/{scope}/{id}", gpuQuotaHandler.DeleteQuota).Methods("DELETE")
+	api.HandleFunc("/admin/gpu-quotas/{scope}/{id}/adjustments", gpuQuotaHandler.AdjustQuota).Methods("POST")
+	api.HandleFunc("/admin/images", machineImageHandler.ListImages).Methods("GET")
+	api.HandleFunc("/admin/images", machineImageHandler.CreateImage).Methods("POST")
+	api.HandleFunc("/admin/images/{id}", machineImageHandler.GetImage).Methods("GET")
+	api.HandleFunc("/admin/images/{id}", machineImageHandler.UpdateImage).Methods("PUT")
+	api.HandleFunc("/admin/images/{id}", machineImageHandler.DeleteImage).Methods("DELETE")
+
+	// Audit log endpoints
+	api.HandleFunc("/audit", auditLog.ListEntries).Methods("GET")
+
+	// Template endpoints
+	api.HandleFunc("/templates/builtin", templateHandler.ListBuiltinTemplates).Methods("GET")
+
+	// Pricing endpoints
+	api.HandleFunc("/pricing/interruptions", pricingHandler.ListInterruptionRates).Methods("GET")
+	api.HandleFunc("/pricing/subscriptions", pricingSubscriptionHandler.CreateSubscription).Methods("POST")
+	api.HandleFunc("/pricing/subscriptions", pricingSubscriptionHandler.ListSubscriptions).Methods("GET")
+	api.HandleFunc("/pricing/subscriptions/{id}", pricingSubscriptionHandler.GetSubscription).Methods("GET")
+	api.HandleFunc("/pricing/subscriptions/{id}", pricingSubscriptionHandler.UpdateSubscription).Methods("PUT")
+	api.HandleFunc("/pricing/subscriptions/{id}", pricingSubscriptionHandler.DeleteSubscription).Methods("DELETE")
+	api.HandleFunc("/pricing/subscriptions/{id}/notifications", pricingSubscriptionHandler.ListNotifications).Methods("GET")
+
+	// Capacity planning endpoints
+	api.HandleFunc("/whatif", whatIfHandler.RunWhatIf).Methods("POST")
+	api.HandleFunc("/whatif/{id}", whatIfHandler.GetWhatIfRun).Methods("GET")
+
+	// Fair share endpoints
+	api.HandleFunc("/fairshare", fairShareHandler.GetFairShare).Methods("GET")
+
+	// GPU-hour quota endpoints
+	api.HandleFunc("/gpu-quotas", gpuQuotaHandler.ListQuotas).Methods("GET")
+
+	// Team endpoints
+	api.HandleFunc("/teams/{id}/data-gravity", dataGravityHandler.GetTeamDataGravity).Methods("GET")
+
+	// Fleet endpoints
+	api.HandleFunc("/fleet/allocations", fleetHandler.ListAllocations).Methods("GET")
 }
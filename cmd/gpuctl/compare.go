@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"gpu-orchestrator/core/models"
+)
+
+// defaultAPIURL is the orchestrator gpuctl talks to without -api or GPUCTL_API_URL
+const defaultAPIURL = "http://localhost:8080"
+
+// runCompare prints what changed from one job to another: spec fields,
+// placement and outcome deltas
+func runCompare(args []string) error {
+	fs := flag.NewFlagSet("compare", flag.ContinueOnError)
+	apiURL := fs.String("api", envOr("GPUCTL_API_URL", defaultAPIURL), "Orchestrator URL")
+	token := fs.String("token", os.Getenv("GPUCTL_TOKEN"), "Bearer token (admins compare any jobs)")
+	raw := fs.Bool("json", false, "Print the comparison as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return fmt.Errorf("usage: gpuctl compare [-api URL] [-json] <job id> <other job id>")
+	}
+
+	endpoint := fmt.Sprintf("%s/v1/jobs/%s/compare?with=%s", strings.TrimRight(*apiURL, "/"),
+		url.PathEscape(fs.Arg(0)), url.QueryEscape(fs.Arg(1)))
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	if *token != "" {
+		req.Header.Set("Authorization", "Bearer "+*token)
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	if *raw {
+		_, err := os.Stdout.Write(body)
+		return err
+	}
+	var comparison models.JobComparison
+	if err := json.Unmarshal(body, &comparison); err != nil {
+		return fmt.Errorf("unexpected response: %w", err)
+	}
+	printComparison(os.Stdout, comparison)
+	return nil
+}
+
+// printComparison renders a comparison for the terminal
+func printComparison(w io.Writer, c models.JobComparison) {
+	fmt.Fprintf(w, "%s (%s) → %s (%s)\n", c.Base.ID, c.Base.Name, c.Other.ID, c.Other.Name)
+
+	fmt.Fprintln(w, "\nSpec:")
+	if c.SameSpec {
+		fmt.Fprintln(w, "  (identical)")
+	}
+	for _, change := range c.SpecChanges {
+		fmt.Fprintf(w, "  %s\n", change)
+	}
+
+	fmt.Fprintln(w, "\nPlacement:")
+	if len(c.PlacementChanges) == 0 {
+		fmt.Fprintln(w, "  (unchanged)")
+	}
+	for _, change := range c.PlacementChanges {
+		fmt.Fprintf(w, "  %s\n", change)
+	}
+
+	fmt.Fprintln(w, "\nOutcome:")
+	fmt.Fprintf(w, "  status:         %s → %s\n", outcomeStatus(c.Base.Outcome), outcomeStatus(c.Other.Outcome))
+	fmt.Fprintf(w, "  duration_hours: %s → %s%s\n", optional(c.Base.Outcome.DurationHours), optional(c.Other.Outcome.DurationHours), delta(c.Outcome.DurationHours))
+	fmt.Fprintf(w, "  cost_usd:       %.2f → %.2f (%+.2f)\n", c.Base.Outcome.CostUSD, c.Other.Outcome.CostUSD, c.Outcome.CostUSD)
+	fmt.Fprintf(w, "  steps_per_hour: %s → %s%s\n", optional(c.Base.Outcome.StepsPerHour), optional(c.Other.Outcome.StepsPerHour), delta(c.Outcome.StepsPerHour))
+}
+
+// outcomeStatus renders a status with the failure class of failed jobs
+func outcomeStatus(outcome models.JobOutcome) string {
+	if outcome.FailureClass != "" {
+		return fmt.Sprintf("%s (%s)", outcome.Status, outcome.FailureClass)
+	}
+	return string(outcome.Status)
+}
+
+// optional renders a figure that may be missing
+func optional(value *float64) string {
+	if value == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", *value)
+}
+
+// delta renders a difference after the figures it compares
+func delta(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprintf(" (%+.2f)", *value)
+}
+
+// envOr returns an environment variable, or fallback when it is unset
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,135 @@
+// Command gpuctl is the command line client for the GPU orchestrator.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"gpu-orchestrator/core/spec"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "init":
+		if err := runInit(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "gpuctl init: %v\n", err)
+			os.Exit(1)
+		}
+	case "submit":
+		if err := runSubmit(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "gpuctl submit: %v\n", err)
+			os.Exit(1)
+		}
+	case "compare":
+		if err := runCompare(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "gpuctl compare: %v\n", err)
+			os.Exit(1)
+		}
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: gpuctl init <template> [-o file] [-set NAME=value ...]")
+	fmt.Fprintln(os.Stderr, "       gpuctl submit [-api URL] [-name NAME] [-experiment E] [-allow-duplicate] [-skip-validate] <spec.yaml>")
+	fmt.Fprintln(os.Stderr, "       gpuctl compare [-api URL] [-json] <job id> <other job id>")
+	listTemplates()
+}
+
+// listTemplates prints the built-in templates to stderr
+func listTemplates() {
+	fmt.Fprintln(os.Stderr, "\nTemplates:")
+	for _, template := range spec.BuiltinTemplates() {
+		fmt.Fprintf(os.Stderr, "  %-18s %s\n", template.Name, template.Description)
+	}
+}
+
+// paramFlags collects repeated -set NAME=value flags
+type paramFlags map[string]string
+
+func (p paramFlags) String() string { return "" }
+
+func (p paramFlags) Set(value string) error {
+	name, val, ok := strings.Cut(value, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("expected NAME=value, got %q", value)
+	}
+	p[name] = val
+	return nil
+}
+
+// runInit scaffolds a spec from a built-in template. Parameters not set with
+// -set keep the template's example value, to be edited before submitting.
+func runInit(args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		usage()
+		return fmt.Errorf("missing template name")
+	}
+	name := args[0]
+
+	params := paramFlags{}
+	fs := flag.NewFlagSet("init", flag.ContinueOnError)
+	output := fs.String("o", "", "File to write (default: <template>.yaml; - for stdout)")
+	force := fs.Bool("f", false, "Overwrite an existing file")
+	fs.Var(params, "set", "Template parameter as NAME=value (repeatable)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	template, ok := spec.GetTemplate(name)
+	if !ok {
+		listTemplates()
+		return fmt.Errorf("unknown template %q", name)
+	}
+	rendered, err := template.Render(params)
+	if err != nil {
+		return err
+	}
+	if _, err := spec.ParseJobSpec(rendered); err != nil {
+		return fmt.Errorf("rendered spec is invalid: %w", err)
+	}
+
+	var header strings.Builder
+	fmt.Fprintf(&header, "# %s: %s\n", template.Name, template.Description)
+	for _, param := range template.Parameters {
+		if _, set := params[param.Name]; !set {
+			fmt.Fprintf(&header, "# TODO: replace the example %s (%s)\n", param.Name, param.Description)
+		}
+	}
+	content := header.String() + rendered
+
+	path := *output
+	if path == "" {
+		path = name + ".yaml"
+	}
+	if path == "-" {
+		_, err := os.Stdout.WriteString(content)
+		return err
+	}
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if *force {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	file, err := os.OpenFile(path, flags, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.WriteString(content); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %s\n", path)
+	return nil
+}
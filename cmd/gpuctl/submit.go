@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"gpu-orchestrator/core/models"
+)
+
+// runSubmit submits a spec file. The spec is validated first so problems
+// are listed field by field before anything is created.
+func runSubmit(args []string) error {
+	fs := flag.NewFlagSet("submit", flag.ContinueOnError)
+	apiURL := fs.String("api", envOr("GPUCTL_API_URL", defaultAPIURL), "Orchestrator URL")
+	token := fs.String("token", os.Getenv("GPUCTL_TOKEN"), "Bearer token")
+	name := fs.String("name", "", "Job name")
+	experiment := fs.String("experiment", "", "Experiment name or ID; overrides the spec's")
+	allowDuplicate := fs.Bool("allow-duplicate", false, "Submit even if an identical spec is still active")
+	skipValidate := fs.Bool("skip-validate", false, "Submit without validating the spec first")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: gpuctl submit [-api URL] [-name NAME] [-skip-validate] <spec.yaml>")
+	}
+	specYAML, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	client := apiClient{url: strings.TrimRight(*apiURL, "/"), token: *token}
+	request := map[string]interface{}{
+		"name":            *name,
+		"spec_yaml":       string(specYAML),
+		"experiment":      *experiment,
+		"allow_duplicate": *allowDuplicate,
+	}
+
+	if !*skipValidate {
+		if err := validateSpec(client, request); err != nil {
+			return err
+		}
+	}
+
+	status, body, err := client.post("/v1/jobs", request)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusCreated {
+		return fmt.Errorf("%d %s: %s", status, http.StatusText(status), strings.TrimSpace(string(body)))
+	}
+	var submitted struct {
+		ID       string `json:"id"`
+		Status   string `json:"status"`
+		Warnings []struct {
+			Message string `json:"message"`
+		} `json:"warnings"`
+	}
+	if err := json.Unmarshal(body, &submitted); err != nil {
+		return fmt.Errorf("unexpected response: %w", err)
+	}
+	for _, warning := range submitted.Warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", warning.Message)
+	}
+	fmt.Printf("Submitted %s (%s)\n", submitted.ID, submitted.Status)
+	return nil
+}
+
+// validateSpec validates a submission with POST /v1/jobs/validate, printing
+// how the spec was resolved to stderr and its problems when it is invalid
+func validateSpec(client apiClient, request map[string]interface{}) error {
+	status, body, err := client.post("/v1/jobs/validate", request)
+	if err != nil {
+		return err
+	}
+	switch status {
+	case http.StatusOK:
+		var resolved struct {
+			ExecutionMode string            `json:"execution_mode"`
+			Backend       string            `json:"backend"`
+			Warnings      []models.SpecNote `json:"warnings"`
+			SpecWarnings  []string          `json:"spec_warnings"`
+			PolicyWarning string            `json:"policy_warning"`
+		}
+		if err := json.Unmarshal(body, &resolved); err != nil {
+			return fmt.Errorf("unexpected validation response: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Spec is valid: %s on %s\n", resolved.ExecutionMode, resolved.Backend)
+		for _, note := range resolved.Warnings {
+			fmt.Fprintf(os.Stderr, "  %-10s %s = %s: %s\n", note.Kind, note.Field, note.Value, note.Message)
+		}
+		for _, warning := range resolved.SpecWarnings {
+			fmt.Fprintf(os.Stderr, "  warning    %s\n", warning)
+		}
+		if resolved.PolicyWarning != "" {
+			fmt.Fprintf(os.Stderr, "  warning    policy check skipped: %s\n", resolved.PolicyWarning)
+		}
+		return nil
+	case http.StatusUnprocessableEntity:
+		var rejected struct {
+			Errors []struct {
+				Reason  string `json:"reason"`
+				Field   string `json:"field"`
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+		if err := json.Unmarshal(body, &rejected); err != nil {
+			return fmt.Errorf("unexpected validation response: %w", err)
+		}
+		fmt.Fprintln(os.Stderr, "Spec is invalid:")
+		for _, problem := range rejected.Errors {
+			field := problem.Field
+			if field == "" {
+				field = "-"
+			}
+			fmt.Fprintf(os.Stderr, "  %-14s %s: %s\n", problem.Reason, field, problem.Message)
+		}
+		return fmt.Errorf("validation failed; nothing was submitted")
+	default:
+		return fmt.Errorf("validation: %d %s: %s", status, http.StatusText(status), strings.TrimSpace(string(body)))
+	}
+}
+
+// apiClient calls the orchestrator API
+type apiClient struct {
+	url   string
+	token string
+}
+
+// post sends a JSON request and returns the response status and body
+func (c apiClient) post(path string, request interface{}) (int, []byte, error) {
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return 0, nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.url+path, bytes.NewReader(payload))
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	return resp.StatusCode, body, err
+}
@@ -2,23 +2,36 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"gpu-orchestrator/api/rest/routes"
 	"gpu-orchestrator/config"
 	"gpu-orchestrator/core/executor"
+	"gpu-orchestrator/core/models"
 	"gpu-orchestrator/core/monitoring"
 	"gpu-orchestrator/core/optimizer"
+	"gpu-orchestrator/core/policy"
 	"gpu-orchestrator/core/repository"
 	"gpu-orchestrator/core/resource_manager"
 	"gpu-orchestrator/core/scheduler"
+	"gpu-orchestrator/core/secrets"
+	"gpu-orchestrator/core/spec"
+	"gpu-orchestrator/core/supervisor"
+	"gpu-orchestrator/providers"
 	"gpu-orchestrator/providers/aws"
 	"gpu-orchestrator/providers/azure"
+	"gpu-orchestrator/providers/coreweave"
 	"gpu-orchestrator/providers/gcp"
+	"gpu-orchestrator/storage"
+	"gpu-orchestrator/training/bootstrap"
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 
 	"github.com/gorilla/mux"
 )
@@ -26,6 +39,13 @@ import (
 func main() {
 	cfg := config.Load()
 
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	if err := spec.ValidateBuiltinTemplates(); err != nil {
+		log.Fatalf("Invalid built-in spec template: %v", err)
+	}
+
 	// Initialize database
 	db, err := repository.NewDB(cfg.DatabaseURL)
 	if err != nil {
@@ -36,47 +56,582 @@ func main() {
 	log.Println("Database connected successfully")
 
 	// Initialize providers
+	// Adding a provider only requires registering its client here
 	ctx := context.Background()
-	awsClient, _ := aws.NewClient(ctx, []string{"us-east-1", "us-west-2"})
-	gcpClient, _ := gcp.NewClient(ctx, "project-id", []string{"us-central1"})
-	azureClient, _ := azure.NewClient(ctx, "subscription-id", []string{"eastus"})
+	providerRegistry := providers.NewRegistry()
+	if awsClient, err := aws.NewClient(ctx, cfg.AWSRegions); err == nil {
+		awsClient.SetInstanceProfile(cfg.AWSInstanceProfile)
+		awsClient.SetSubnets(cfg.AWSSubnets)
+		awsClient.SetSageMaker(cfg.SageMakerRoleARN, cfg.SageMakerImage, cfg.SageMakerOutputURI)
+		providerRegistry.Register(awsClient)
+	} else {
+		log.Printf("AWS provider disabled: %v", err)
+	}
+	if gcpClient, err := gcp.NewClient(ctx, cfg.GCPProjectID, cfg.GCPRegions); err == nil {
+		gcpClient.SetAccessToken(cfg.GCSAccessToken)
+		gcpClient.SetServiceAccount(cfg.GCPServiceAccount)
+		gcpClient.SetVertexTraining(cfg.VertexTrainingImage, cfg.VertexTrainingOutput)
+		providerRegistry.Register(gcpClient)
+	} else {
+		log.Printf("GCP provider disabled: %v", err)
+	}
+	if azureClient, err := azure.NewClient(ctx, cfg.AzureSubscriptionID, cfg.AzureRegions); err == nil {
+		azureClient.SetAccessToken(cfg.AzureAccessToken)
+		azureClient.SetNetwork(cfg.AzureResourceGroup, cfg.AzureSubnetID)
+		azureClient.SetAdminUser(cfg.AzureAdminUser, cfg.AzureSSHPublicKey)
+		providerRegistry.Register(azureClient)
+	} else {
+		log.Printf("Azure provider disabled: %v", err)
+	}
+	if cfg.CoreWeaveEndpoint != "" || cfg.CoreWeavePriceSheet != "" {
+		cwClient, err := coreweave.NewClient(ctx, cfg.CoreWeaveEndpoint, cfg.CoreWeaveAPIToken, cfg.CoreWeaveRegions, cfg.CoreWeavePriceSheet)
+		if err == nil {
+			providerRegistry.Register(cwClient)
+		} else {
+			log.Printf("CoreWeave provider disabled: %v", err)
+		}
+	}
+
+	// Meter provider API calls; over a provider's hourly budget, non-critical
+	// callers (pricing refresh, identity cleanup) are throttled
+	providerUsage := providers.NewUsageMeter(cfg.ProviderCallBudgets)
+	providerRegistry.Instrument(providerUsage)
+
+	// Initialize object storage
+	objectStores := storage.NewRegistry()
+	if awsCfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(cfg.AWSRegion)); err == nil {
+		objectStores.RegisterScheme("s3", storage.NewS3Store(cfg.AWSRegion, awsCfg.Credentials))
+	} else {
+		log.Printf("S3 object storage disabled: %v", err)
+	}
+	objectStores.RegisterScheme("gs", storage.NewGCSStore(cfg.GCSAccessToken))
+	if cfg.AzureStorageAccount != "" {
+		objectStores.RegisterScheme("az", storage.NewAzureBlobStore(cfg.AzureStorageAccount, cfg.AzureStorageSAS))
+	}
+	for alias, minio := range cfg.MinIOAliases {
+		objectStores.RegisterMinIOAlias(alias, storage.NewS3CompatibleStore(alias, minio.Endpoint, minio.Region, minio.AccessKey, minio.SecretKey))
+	}
+
+	// Supervise background workers: restart after panics, watch heartbeats
+	workers := supervisor.NewSupervisor(cfg.WorkerMaxRestarts, cfg.WorkerRestartBackoff, 5*time.Minute)
+	if cfg.AlertWebhookURL != "" {
+		workers.SetStallHandler(monitoring.NotifyWorkerStall(monitoring.NewWebhookNotifier(cfg.AlertWebhookURL)))
+	}
+	go workers.Watch(ctx, cfg.WorkerWatchInterval)
 
 	// Initialize pricing fetcher
-	pricingFetcher := optimizer.NewPricingFetcher(awsClient, gcpClient, azureClient, db.DB)
-	go pricingFetcher.StartRefreshWorker(ctx)
+	pricingFetcher := optimizer.NewPricingFetcher(providerRegistry, db)
+	pricingFetcher.SetRefreshInterval(cfg.PricingRefreshInterval)
+	pricingFetcher.SetSpotRefreshInterval(cfg.PricingSpotRefresh)
+	pricingFetcher.SetRefreshOverrides(cfg.PricingRefreshOverrides)
+	pricingFetcher.SetRefreshTimeout(cfg.PricingRefreshTimeout)
+	var priceGuard *optimizer.PriceGuard
+	if cfg.PriceAnomalyFactor > 0 {
+		priceGuard = optimizer.NewPriceGuard(cfg.PriceAnomalyFactor, repository.NewPriceQuarantineRepository(db))
+		pricingFetcher.SetPriceGuard(priceGuard)
+	}
+	pricingFetcher.SetPriceObserver(monitoring.NewPriceWatcher(repository.NewPricingSubscriptionRepository(db)))
+	workers.Go(ctx, "pricing_refresher", pricingFetcher.MinRefreshInterval(), pricingFetcher.StartRefreshWorker)
+
+	// Every replica adds the calls it made to the daily provider usage rollups
+	providerUsageFlusher := monitoring.NewProviderUsageFlusher(providerUsage, repository.NewProviderUsageRepository(db))
+	workers.Go(ctx, "provider_usage_flush", cfg.ProviderUsageFlushInterval, func(ctx context.Context) {
+		providerUsageFlusher.Start(ctx, cfg.ProviderUsageFlushInterval)
+	})
+
+	// Every replica adds the requests it served to the daily API usage rollups
+	apiUsage := monitoring.NewAPIUsageMeter(repository.NewAPIUsageRepository(db), monitoring.APIUsageLimits{
+		MaxJobs:    cfg.APIUsageMaxJobs,
+		MaxClients: cfg.APIUsageMaxClients,
+	})
+	workers.Go(ctx, "api_usage_flush", cfg.APIUsageFlushInterval, func(ctx context.Context) {
+		apiUsage.Start(ctx, cfg.APIUsageFlushInterval)
+	})
 
 	// Initialize optimizer
 	costCalculator := optimizer.NewCostCalculator(pricingFetcher)
-	allocationOptimizer := optimizer.NewAllocationOptimizer(costCalculator, pricingFetcher)
+	if cfg.TransferPricingFile != "" {
+		if err := costCalculator.LoadTransferPricing(cfg.TransferPricingFile); err != nil {
+			log.Fatalf("Failed to load transfer pricing: %v", err)
+		}
+	}
+	if cfg.CarbonIntensityFile != "" {
+		if err := costCalculator.LoadCarbonIntensity(cfg.CarbonIntensityFile); err != nil {
+			log.Fatalf("Failed to load carbon intensity: %v", err)
+		}
+	}
+	// Spot reliability is calibrated from recorded interruptions on every replica
+	interruptionRepo := repository.NewInterruptionRepository(db)
+	interruptionModel := optimizer.NewInterruptionModel()
+	costCalculator.SetInterruptionModel(interruptionModel)
+	workers.Go(ctx, "interruption_model", cfg.InterruptionRefreshInterval, func(ctx context.Context) {
+		interruptionModel.Start(ctx, interruptionRepo, cfg.InterruptionRefreshInterval)
+	})
+	nodeLimits := optimizer.NewNodeLimits()
+	nodeLimits.RefreshFromQuotas(ctx, providerRegistry)
+	if cfg.NodeLimitsFile != "" {
+		if err := nodeLimits.LoadNodeLimits(cfg.NodeLimitsFile); err != nil {
+			log.Fatalf("Failed to load node limits: %v", err)
+		}
+	}
+	allocationOptimizer := optimizer.NewAllocationOptimizer(costCalculator, pricingFetcher, nodeLimits)
+	// Learned throughput is shared by replicas through the database
+	benchmarks := allocationOptimizer.PerformanceMetrics()
+	if cfg.BenchmarksFile != "" {
+		if err := benchmarks.LoadStaticBenchmarks(cfg.BenchmarksFile); err != nil {
+			log.Fatalf("Failed to load performance benchmarks: %v", err)
+		}
+	}
+	if err := benchmarks.SetHistory(repository.NewBenchmarkRepository(db)); err != nil {
+		log.Printf("Failed to load learned benchmarks: %v", err)
+	}
+	// The last admin change to the instance type lists overrides the configured ones
+	instanceTypeRules, err := optimizer.NewInstanceTypeRules(cfg.InstanceTypeAllow, cfg.InstanceTypeDeny)
+	if err != nil {
+		log.Fatalf("Invalid INSTANCE_TYPE_ALLOW/INSTANCE_TYPE_DENY: %v", err)
+	}
+	instanceTypeRuleRepo := repository.NewInstanceTypeRuleRepository(db)
+	if err := instanceTypeRules.Sync(instanceTypeRuleRepo); err != nil {
+		log.Printf("Failed to load instance type rule changes: %v", err)
+	}
+	allocationOptimizer.SetInstanceTypeRules(instanceTypeRules)
+	machineImageRepo := repository.NewMachineImageRepository(db)
+	allocationOptimizer.SetImageCatalog(machineImageRepo)
+	workers.Go(ctx, "instance_type_rules", cfg.InstanceTypeSyncInterval, func(ctx context.Context) {
+		instanceTypeRules.Start(ctx, instanceTypeRuleRepo, cfg.InstanceTypeSyncInterval)
+	})
 
 	// Initialize repositories
 	jobRepo := repository.NewJobRepository(db)
 	allocationRepo := repository.NewAllocationRepository(db)
+	taskRepo := repository.NewTaskRepository(db)
 
 	// Initialize resource manager
-	provisioner := resource_manager.NewProvisioner(awsClient, gcpClient, azureClient)
+	provisioner := resource_manager.NewProvisioner(providerRegistry)
+	var bootstrapDefault *models.BootstrapConfig
+	if cfg.BootstrapDefaultFile != "" {
+		defaults, err := bootstrap.LoadDefault(cfg.BootstrapDefaultFile)
+		if err != nil {
+			log.Fatalf("Failed to load bootstrap defaults: %v", err)
+		}
+		bootstrapDefault = defaults
+	}
+	provisioner.SetBootstrap(bootstrapDefault, repository.NewArtifactRepository(db))
+	provisioner.SetIdentityStore(jobRepo)
+	provisioner.SetAllocationStore(allocationRepo)
+	provisioner.SetImageStore(machineImageRepo)
+	provisioner.Kubernetes().SetTimeSlicingReplicas(cfg.K8sTimeSlicingReplicas)
+
+	// Per-cluster SSH keys, sealed under SECRETS_MASTER_KEY
+	var keyring *secrets.Keyring
+	if cfg.SecretsMasterKey != "" {
+		masterKey, err := secrets.ParseMasterKey(cfg.SecretsMasterKey)
+		if err == nil {
+			keyring, err = secrets.NewKeyring(masterKey)
+		}
+		if err != nil {
+			log.Fatalf("Failed to load secrets master key: %v", err)
+		}
+	}
+	clusterKeys := executor.NewClusterKeys(repository.NewSSHKeyRepository(db), keyring, cfg.NodeSSHUser)
+	clusterKeys.SetAuditRecorder(repository.NewAuditRepository(db))
+	if keyring != nil {
+		provisioner.SetSSHKeys(clusterKeys, cfg.NodeSSHUser)
+	}
+
+	// Initialize cross-region checkpoint replication (checkpointing.replicate_to)
+	checkpointReplicator := storage.NewCheckpointReplicator(repository.NewArtifactRepository(db), jobRepo, objectStores,
+		cfg.CheckpointLocations, cfg.CheckpointReplicaAttempts)
+	checkpointReplicator.SetTransferPricer(costCalculator)
 
 	// Initialize training executor
-	trainingExecutor := executor.NewTrainingExecutor(jobRepo)
+	trainingExecutor := executor.NewTrainingExecutor(jobRepo, objectStores, cfg.PublicURL)
+	trainingExecutor.SetCheckpointReplicator(checkpointReplicator)
+	trainingExecutor.SetLaunchConfigStore(repository.NewArtifactRepository(db), cfg.LaunchConfigURI)
+	trainingExecutor.SetInstanceCatalog(pricingFetcher)
+	if keyring != nil {
+		trainingExecutor.SetNodeRunner(clusterKeys)
+	}
+	var portAllocator *resource_manager.PortAllocator
+	if cfg.FrameworkPortMin > 0 {
+		portAllocator = resource_manager.NewPortAllocator(allocationRepo, cfg.FrameworkPortMin, cfg.FrameworkPortMax)
+		trainingExecutor.SetPortAllocator(portAllocator)
+	}
 
 	// Initialize cost tracker
-	costTracker := monitoring.NewCostTracker(jobRepo)
-	go costTracker.Start(ctx)
+	costTracker := monitoring.NewCostTracker(jobRepo, taskRepo)
+	costTracker.SetInterval(cfg.CostTickInterval)
+	costTracker.SetMinFlushDelta(cfg.CostFlushMinDelta)
+
+	// Initialize alert engine
+	alertRepo := repository.NewAlertRepository(db)
+	if cfg.AlertRulesFile != "" {
+		rules, err := monitoring.LoadAlertRules(cfg.AlertRulesFile)
+		if err != nil {
+			log.Fatalf("Failed to load alert rules: %v", err)
+		}
+		for _, rule := range rules {
+			if err := alertRepo.UpsertRule(rule); err != nil {
+				log.Fatalf("Failed to save alert rule %s: %v", rule.Name, err)
+			}
+		}
+		log.Printf("Loaded %d alert rules from %s", len(rules), cfg.AlertRulesFile)
+	}
+	// Load org policy rules (also managed via /v1/policies)
+	if cfg.PolicyRulesFile != "" {
+		rules, err := policy.LoadRules(cfg.PolicyRulesFile)
+		if err != nil {
+			log.Fatalf("Failed to load policy rules: %v", err)
+		}
+		policyRepo := repository.NewPolicyRepository(db)
+		for _, rule := range rules {
+			if err := policyRepo.UpsertRule(rule); err != nil {
+				log.Fatalf("Failed to save policy rule %s: %v", rule.Name, err)
+			}
+		}
+		log.Printf("Loaded %d policy rules from %s", len(rules), cfg.PolicyRulesFile)
+	}
+	var emailSender monitoring.EmailSender = monitoring.LogEmailSender{}
+	if cfg.SMTPHost != "" {
+		emailSender = monitoring.NewSMTPEmailSender(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPFrom, cfg.SMTPUsername, cfg.SMTPPassword)
+	}
+	// Initialize cluster pool (hibernated clusters count as idle cost)
+	clusterPool := resource_manager.NewClusterPool(0, 10)
+
+	alertEngine := monitoring.NewAlertEngine(alertRepo, clusterPool, emailSender, cfg.AlertWebhookURL)
+	alertEngine.SetInterval(cfg.AlertEvalInterval)
+
+	// Initialize elastic cluster management (horovod_elastic jobs)
+	elasticManager := resource_manager.NewElasticManager(provisioner, costTracker)
+	elasticScaler := scheduler.NewElasticScaler(elasticManager, jobRepo, pricingFetcher)
+	elasticScaler.SetInterval(cfg.ElasticScaleInterval)
+	elasticScaler.SetInterruptionRepository(interruptionRepo)
+
+	// Initialize cluster hibernation between jobs with the same requirements
+	var hibernator *resource_manager.Hibernator
+	if cfg.HibernationWindow > 0 {
+		hibernator = resource_manager.NewHibernator(clusterPool, provisioner, costTracker, cfg.HibernationWindow, cfg.HibernationStorageGB)
+		hibernator.SetInterval(cfg.HibernationCheckInterval)
+	}
+
+	// Initialize interactive session management (TTL, idle stop, extend)
+	sessionManager := scheduler.NewSessionManager(jobRepo, costTracker, cfg.SessionIdleTimeout)
+	sessionManager.SetInterval(cfg.SessionCheckInterval)
+
+	// Initialize per-task execution of multi_task jobs
+	taskRunner := scheduler.NewTaskRunner(taskRepo, jobRepo, provisioner, trainingExecutor, costTracker)
+
+	// Initialize the stuck job sweeper
+	stuckAction, err := scheduler.ParseStuckAction(cfg.StuckAction)
+	if err != nil {
+		log.Fatalf("Invalid STUCK_ACTION: %v", err)
+	}
+	stuckSweeper := scheduler.NewStuckSweeper(jobRepo, scheduler.StuckPolicy{
+		Thresholds: map[models.JobStatus]time.Duration{
+			models.JobStatusScheduled:     cfg.StuckScheduled,
+			models.JobStatusProvisioning:  cfg.StuckProvisioning,
+			models.JobStatusCheckpointing: cfg.StuckCheckpointing,
+		},
+		Escalation: cfg.StuckEscalation,
+		Action:     stuckAction,
+	})
+
+	// Initialize the pending-time alarm (constraints.max_queue_time)
+	queueAlarm := scheduler.NewQueueAlarm(jobRepo)
+	if cfg.AlertWebhookURL != "" {
+		queueAlarm.SetNotifier(monitoring.NewWebhookNotifier(cfg.AlertWebhookURL))
+	}
+
+	// Initialize the managed training watcher (execution.backend managed)
+	managedTraining := scheduler.NewManagedTrainingWatcher(jobRepo, repository.NewManagedTrainingRepository(db), providerRegistry, pricingFetcher)
+
+	// Initialize the deadline risk evaluator (constraints.deadline)
+	deadlineRisk := scheduler.NewDeadlineRiskEvaluator(jobRepo, allocationRepo, scheduler.DeadlineRiskPolicy{
+		Watch:      cfg.DeadlineRiskWatch,
+		AtRisk:     cfg.DeadlineRiskAtRisk,
+		LikelyMiss: cfg.DeadlineRiskLikelyMiss,
+	})
+	if cfg.AlertWebhookURL != "" {
+		deadlineRisk.SetNotifier(monitoring.NewWebhookNotifier(cfg.AlertWebhookURL))
+	}
+
+	// Initialize the restart-elsewhere advisor
+	migrationAdvisor := scheduler.NewMigrationAdvisor(jobRepo, allocationRepo, repository.NewArtifactRepository(db), repository.NewMigrationRepository(db),
+		allocationOptimizer, costCalculator, scheduler.MigrationPolicy{
+			MinSavingsUSD:    cfg.MigrationMinSavingsUSD,
+			RestartOverhead:  cfg.MigrationRestartOverhead,
+			MaxCheckpointAge: cfg.MigrationMaxCheckpointAge,
+		})
+	migrationAdvisor.SetCheckpointReplicator(checkpointReplicator)
+	if cfg.AlertWebhookURL != "" {
+		migrationAdvisor.SetNotifier(monitoring.NewWebhookNotifier(cfg.AlertWebhookURL))
+	}
+
+	// Initialize the maintenance watcher (provider-scheduled host maintenance)
+	maintenanceRepo := repository.NewMaintenanceRepository(db)
+	maintenanceWatcher := scheduler.NewMaintenanceWatcher(jobRepo, repository.NewArtifactRepository(db), maintenanceRepo, providerRegistry, scheduler.MaintenancePolicy{
+		MigrateLead: cfg.MaintenanceMigrateLead,
+	})
+	if cfg.AlertWebhookURL != "" {
+		maintenanceWatcher.SetNotifier(monitoring.NewWebhookNotifier(cfg.AlertWebhookURL))
+	}
+
+	// Initialize the rebalance watcher (spot rebalance recommendations)
+	rebalanceWatcher := scheduler.NewRebalanceWatcher(jobRepo, repository.NewArtifactRepository(db), repository.NewRebalanceRepository(db), providerRegistry, pricingFetcher, scheduler.RebalancePolicy{
+		RequestCheckpoint:    cfg.RebalanceRequestCheckpoint,
+		ProvisionReplacement: cfg.RebalanceReplaceNodes,
+	})
+
+	// Initialize the checkpoint cadence check (checkpointing spec block)
+	jobMonitor := monitoring.NewJobMonitor(jobRepo, costTracker)
+	jobMonitor.SetCheckpointCadence(repository.NewArtifactRepository(db))
+
+	// Initialize the cancellation grace window sweeper
+	cancelGrace := scheduler.NewCancelGrace(jobRepo, cfg.CancelGrace)
+
+	// Initialize suspension of jobs on request (POST /v1/jobs/{id}/suspend)
+	suspender := scheduler.NewSuspender(jobRepo, repository.NewArtifactRepository(db), cfg.SuspendCheckpointTimeout)
+
+	// Initialize the consistency checker (read-only; DB vs provider state vs cost)
+	consistencyChecker := scheduler.NewConsistencyChecker(jobRepo, allocationRepo, repository.NewConsistencyRepository(db), providerRegistry, scheduler.ConsistencyPolicy{
+		ProviderPause: cfg.ConsistencyProviderPause,
+		Grace:         cfg.ConsistencyGrace,
+		CostGapRatio:  cfg.ConsistencyCostGapRatio,
+	})
+	consistencyChecker.SetClusterPool(clusterPool)
+
+	// Initialize the orphan cleaner (records left behind by missing or ended jobs)
+	orphanCleaner := monitoring.NewOrphanCleaner(repository.NewOrphanRepository(db), monitoring.OrphanPolicy{
+		Apply:     cfg.OrphanCleanupApply,
+		Retention: cfg.OrphanRetention,
+		BatchSize: cfg.OrphanBatchSize,
+	})
+	orphanCleaner.SetAuditRepository(repository.NewAuditRepository(db))
+
+	// Initialize carbon accounting (estimated emissions of finished jobs)
+	carbonAccountant := monitoring.NewCarbonAccountant(jobRepo, repository.NewBillingRepository(db), costCalculator.CarbonModel())
+
+	// Initialize the image boot monitor (degrades images over their boot budget)
+	imageBootMonitor := monitoring.NewImageBootMonitor(machineImageRepo)
+	if cfg.AlertWebhookURL != "" {
+		imageBootMonitor.SetNotifier(monitoring.NewWebhookNotifier(cfg.AlertWebhookURL))
+	}
+
+	// Initialize post-mortem bundles of failed jobs
+	postmortems := monitoring.NewPostmortemGenerator(jobRepo, repository.NewEventRepository(db), allocationRepo, repository.NewArtifactRepository(db), objectStores, cfg.PostmortemURI, cfg.PostmortemMaxBytes)
+	postmortems.SetNodeRunner(clusterKeys, cfg.PostmortemLogLines)
+
+	// Initialize scheduled rotation of per-cluster SSH keys
+	sshKeyRotator := scheduler.NewSSHKeyRotator(clusterKeys, cfg.SSHKeyRotationAge)
+
+	// Initialize egress tracking of running jobs' nodes
+	egressTracker := scheduler.NewEgressTracker(jobRepo, repository.NewEgressRepository(db), clusterKeys, costCalculator, scheduler.EgressPolicy{
+		MaxUSD:   cfg.EgressAlertUSD,
+		MaxRatio: cfg.EgressAlertRatio,
+	})
+	if cfg.AlertWebhookURL != "" {
+		egressTracker.SetNotifier(monitoring.NewWebhookNotifier(cfg.AlertWebhookURL))
+	}
+
+	// Initialize fair share (soft per-team GPU-hour shares)
+	fairShare := scheduler.NewFairShare(repository.NewBillingRepository(db), cfg.FairShareWeights, cfg.FairShareWindow, cfg.FairShareMaxAdjustment, cfg.FairShareMaxDelay)
+
+	// Initialize monthly GPU-hour quotas (no quotas until one is set through the API)
+	gpuQuotas := scheduler.NewGPUQuotas(repository.NewGPUQuotaRepository(db), repository.NewBillingRepository(db), cfg.GPUQuotaRefresh)
 
 	// Initialize scheduler
-	scheduler := scheduler.NewScheduler(jobRepo, allocationRepo, allocationOptimizer, provisioner, trainingExecutor)
-	go scheduler.Start(ctx)
+	scheduleRetry := scheduler.ScheduleRetryPolicy{
+		MaxAttempts: cfg.ScheduleRetryMaxAttempts,
+		BaseDelay:   cfg.ScheduleRetryBase,
+		MaxDelay:    cfg.ScheduleRetryMaxBackoff,
+	}
+	scheduler := scheduler.NewScheduler(jobRepo, allocationRepo, allocationOptimizer, provisioner, trainingExecutor, elasticManager, hibernator, sessionManager)
+	scheduler.SetPriceRecheck(optimizer.PriceRecheckPolicy{
+		MaxDrift: cfg.PriceRecheckMaxDrift,
+		FreshFor: cfg.PriceRecheckFreshFor,
+	})
+	scheduler.SetIntervals(cfg.SchedulerTick, cfg.PendingResyncInterval)
+	scheduler.SetScheduleRetry(scheduleRetry)
+	scheduler.SetGPUQuotas(gpuQuotas)
+	scheduler.SetSuspender(suspender)
+	if len(cfg.FairShareWeights) > 0 {
+		scheduler.SetFairShare(fairShare)
+	}
+	if cfg.DataGravityHalfLife > 0 {
+		scheduler.SetDataGravity(optimizer.NewDataGravity(repository.NewDataGravityRepository(db), cfg.DataGravityHalfLife))
+	}
+	scheduler.SetTaskRunner(taskRunner)
+	scheduler.SetPortAllocator(portAllocator)
+	scheduler.SetDatasetVerifier(storage.NewDatasetVerifier(objectStores, cfg.DatasetVerifyMaxObjects, cfg.DatasetVerifySampleSize))
+	scheduler.SetManagedTraining(managedTraining)
+	stuckSweeper.SetScheduler(scheduler)
+	migrationAdvisor.SetScheduler(scheduler)
+	maintenanceWatcher.SetScheduler(scheduler)
+	sshKeyRotator.SetScheduler(scheduler)
+	egressTracker.SetScheduler(scheduler)
+	rebalanceWatcher.SetScheduler(scheduler)
+	consistencyChecker.SetScheduler(scheduler)
+	suspender.SetScheduler(scheduler)
 	defer scheduler.Stop()
 
 	// Phase 4: Initialize autoscaler (if cluster pool is used)
-	// TODO: Initialize cluster pool and connect autoscaler
-	// clusterPool := resource_manager.NewClusterPool(...)
+	// TODO: Connect autoscaler to the cluster pool
 	// autoscaler := scheduler.NewAutoScaler(clusterPool, scheduler.GetQueue())
+	// autoscaler.SetInterval(cfg.AutoscalerInterval)
 	// go autoscaler.Start(ctx)
 
-	// Setup routes with database and scheduler
+	// Initialize checkpoint retention GC
+	artifactGC := storage.NewArtifactGC(repository.NewArtifactRepository(db), jobRepo, objectStores, models.RetentionPolicy{
+		KeepLast:   &cfg.ArtifactRetentionKeepLast,
+		MaxAgeDays: &cfg.ArtifactRetentionMaxAgeDays,
+	}, cfg.ArtifactGCDryRun)
+
+	// Singleton workers run only on the leader replica; every replica serves the API
+	startSingletons := func(ctx context.Context) {
+		workers.Go(ctx, "cost_tracker", cfg.CostTickInterval, costTracker.Start)
+		workers.Go(ctx, "alert_engine", cfg.AlertEvalInterval, alertEngine.Start)
+		workers.Go(ctx, "elastic_scaler", cfg.ElasticScaleInterval, elasticScaler.Start)
+		if hibernator != nil {
+			workers.Go(ctx, "hibernator", cfg.HibernationCheckInterval, hibernator.Start)
+		}
+		workers.Go(ctx, "session_manager", cfg.SessionCheckInterval, sessionManager.Start)
+		workers.Go(ctx, "scheduler", cfg.SchedulerTick, scheduler.Start)
+		if cfg.MigrationCheckInterval > 0 {
+			workers.Go(ctx, "migration_advisor", cfg.MigrationCheckInterval, func(ctx context.Context) {
+				migrationAdvisor.Start(ctx, cfg.MigrationCheckInterval)
+			})
+		}
+		if cfg.MaintenanceCheckInterval > 0 {
+			workers.Go(ctx, "maintenance_watcher", cfg.MaintenanceCheckInterval, func(ctx context.Context) {
+				maintenanceWatcher.Start(ctx, cfg.MaintenanceCheckInterval)
+			})
+		}
+		if cfg.RebalanceCheckInterval > 0 {
+			workers.Go(ctx, "rebalance_watcher", cfg.RebalanceCheckInterval, func(ctx context.Context) {
+				rebalanceWatcher.Start(ctx, cfg.RebalanceCheckInterval)
+			})
+		}
+		if cfg.CarbonAccountInterval > 0 {
+			workers.Go(ctx, "carbon_accountant", cfg.CarbonAccountInterval, func(ctx context.Context) {
+				carbonAccountant.Start(ctx, cfg.CarbonAccountInterval)
+			})
+		}
+		if cfg.ImageBootCheckInterval > 0 {
+			workers.Go(ctx, "image_boot_monitor", cfg.ImageBootCheckInterval, func(ctx context.Context) {
+				imageBootMonitor.Start(ctx, cfg.ImageBootCheckInterval)
+			})
+		}
+		if keyring != nil && cfg.SSHKeyRotationAge > 0 && cfg.SSHKeyRotationInterval > 0 {
+			workers.Go(ctx, "ssh_key_rotation", cfg.SSHKeyRotationInterval, func(ctx context.Context) {
+				sshKeyRotator.Start(ctx, cfg.SSHKeyRotationInterval)
+			})
+		}
+		// Nodes are sampled over SSH, with the cluster's key or the orchestrator's
+		if cfg.EgressSampleInterval > 0 && (keyring != nil || cfg.ConsoleSSHKeyFile != "") {
+			workers.Go(ctx, "egress_sample", cfg.EgressSampleInterval, func(ctx context.Context) {
+				egressTracker.Start(ctx, cfg.EgressSampleInterval)
+			})
+		}
+		if cfg.CheckpointCadenceInterval > 0 {
+			workers.Go(ctx, "checkpoint_cadence", cfg.CheckpointCadenceInterval, func(ctx context.Context) {
+				jobMonitor.StartCheckpointCadence(ctx, cfg.CheckpointCadenceInterval)
+			})
+		}
+		if cfg.ConsistencyCheckInterval > 0 {
+			workers.Go(ctx, "consistency_check", cfg.ConsistencyCheckInterval, func(ctx context.Context) {
+				consistencyChecker.Start(ctx, cfg.ConsistencyCheckInterval)
+			})
+		}
+		if cfg.OrphanCleanupInterval > 0 {
+			workers.Go(ctx, "orphan_cleanup", cfg.OrphanCleanupInterval, func(ctx context.Context) {
+				orphanCleaner.Start(ctx, cfg.OrphanCleanupInterval)
+			})
+		}
+		if cfg.PostmortemInterval > 0 && cfg.PostmortemURI != "" {
+			workers.Go(ctx, "postmortem", cfg.PostmortemInterval, func(ctx context.Context) {
+				postmortems.Start(ctx, cfg.PostmortemInterval)
+			})
+		}
+		if len(cfg.FairShareWeights) > 0 {
+			workers.Go(ctx, "fairshare", cfg.FairShareRefresh, func(ctx context.Context) {
+				fairShare.Start(ctx, cfg.FairShareRefresh)
+			})
+		}
+		workers.Go(ctx, "queue_alarm", cfg.QueueAlarmInterval, func(ctx context.Context) {
+			queueAlarm.Start(ctx, cfg.QueueAlarmInterval)
+		})
+		workers.Go(ctx, "managed_training", cfg.ManagedTrainingPoll, func(ctx context.Context) {
+			managedTraining.Start(ctx, cfg.ManagedTrainingPoll)
+		})
+		if cfg.DeadlineRiskInterval > 0 {
+			workers.Go(ctx, "deadline_risk", cfg.DeadlineRiskInterval, func(ctx context.Context) {
+				deadlineRisk.Start(ctx, cfg.DeadlineRiskInterval)
+			})
+		}
+		workers.Go(ctx, "stuck_sweeper", cfg.StuckSweepInterval, func(ctx context.Context) {
+			stuckSweeper.Start(ctx, cfg.StuckSweepInterval)
+		})
+		workers.Go(ctx, "cancel_grace", cfg.CancelSweepInterval, func(ctx context.Context) {
+			cancelGrace.Start(ctx, cfg.CancelSweepInterval)
+		})
+		workers.Go(ctx, "suspender", cfg.SuspendSweepInterval, func(ctx context.Context) {
+			suspender.Start(ctx, cfg.SuspendSweepInterval)
+		})
+		workers.Go(ctx, "identity_cleanup", cfg.IdentityCleanupInterval, func(ctx context.Context) {
+			provisioner.StartIdentityCleanup(ctx, cfg.IdentityCleanupInterval)
+		})
+		if portAllocator != nil {
+			workers.Go(ctx, "port_release", cfg.PortReleaseInterval, func(ctx context.Context) {
+				portAllocator.Start(ctx, cfg.PortReleaseInterval)
+			})
+		}
+		if cfg.ArtifactGCInterval > 0 {
+			workers.Go(ctx, "artifact_gc", cfg.ArtifactGCInterval, func(ctx context.Context) {
+				artifactGC.Start(ctx, cfg.ArtifactGCInterval)
+			})
+		}
+		if cfg.CheckpointReplicaInterval > 0 {
+			workers.Go(ctx, "checkpoint_replicator", cfg.CheckpointReplicaInterval, func(ctx context.Context) {
+				checkpointReplicator.Start(ctx, cfg.CheckpointReplicaInterval)
+			})
+		}
+		if cfg.AuditRetention > 0 {
+			auditRetention := monitoring.NewAuditRetention(repository.NewAuditRepository(db), cfg.AuditRetention)
+			workers.Go(ctx, "audit_retention", cfg.AuditPruneInterval, func(ctx context.Context) {
+				auditRetention.Start(ctx, cfg.AuditPruneInterval)
+			})
+		}
+	}
+	var elector *supervisor.Elector
+	if cfg.LeaderElection && db.Dialect() == repository.DialectSQLite {
+		log.Println("Leader election needs PostgreSQL; SQLite mode runs singleton workers on this single instance")
+	} else if cfg.LeaderElection {
+		elector = supervisor.NewElector(repository.NewLeaseRepository(db), "orchestrator", cfg.InstanceID, cfg.LeaderLeaseTTL)
+		elector.OnElected(startSingletons)
+		workers.Go(ctx, "leader_election", elector.RenewInterval(), elector.Run)
+	} else {
+		startSingletons(ctx)
+	}
+
+	// Setup routes with database, scheduler and config
 	r := mux.NewRouter()
-	routes.SetupRoutes(r, db, scheduler)
+	billingExporter := monitoring.NewBillingExporter(repository.NewBillingRepository(db), objectStores)
+	billingExporter.SetCarbonModel(costCalculator.CarbonModel())
+	billingExporter.SetEgressRepository(repository.NewEgressRepository(db))
+
+	// Clusters without a key of their own are reached with the orchestrator's SSH key
+	if cfg.ConsoleSSHKeyFile != "" {
+		key, err := os.ReadFile(cfg.ConsoleSSHKeyFile)
+		if err != nil {
+			log.Fatalf("Failed to read console SSH key: %v", err)
+		}
+		consoleShells, err := executor.NewSSHClient(key, cfg.ConsoleSSHUser)
+		if err != nil {
+			log.Fatalf("Failed to create console SSH client: %v", err)
+		}
+		clusterKeys.SetFallback(consoleShells)
+	}
+	routes.SetupRoutes(r, db, scheduler, pricingFetcher, artifactGC, checkpointReplicator, billingExporter, providerUsage, apiUsage, orphanCleaner, objectStores, clusterKeys, cfg)
 
 	// Health check endpoint
 	r.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -84,6 +639,40 @@ func main() {
 		w.Write([]byte("OK"))
 	}).Methods("GET")
 
+	// Prometheus metrics
+	metricsExporter := monitoring.NewMetricsExporter(jobRepo, costTracker)
+	metricsExporter.SetSupervisor(workers)
+	metricsExporter.AddSource(stuckSweeper)
+	metricsExporter.AddSource(providerUsage)
+	metricsExporter.AddSource(apiUsage)
+	metricsExporter.AddSource(consistencyChecker)
+	if priceGuard != nil {
+		metricsExporter.AddSource(priceGuard)
+	}
+	r.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		w.Write([]byte(metricsExporter.GetPrometheusMetrics()))
+	}).Methods("GET")
+
+	// Readiness: fails when a background worker crashed too often or stopped
+	// heartbeating; followers are ready (they serve the API) and report leader=false
+	r.HandleFunc("/health/ready", func(w http.ResponseWriter, r *http.Request) {
+		healthy := workers.Healthy()
+		w.Header().Set("Content-Type", "application/json")
+		if !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		leader := supervisor.LeaderStatus{Instance: cfg.InstanceID, Leader: true}
+		if elector != nil {
+			leader = elector.Status()
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"ready":   healthy,
+			"leader":  leader,
+			"workers": workers.Status(),
+		})
+	}).Methods("GET")
+
 	// Start server
 	server := &http.Server{
 		Addr:    ":" + cfg.ServerPort,
@@ -107,5 +696,11 @@ func main() {
 	if err := server.Shutdown(context.Background()); err != nil {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
+	// Write running costs accrued since the last flush
+	costTracker.Flush()
+	// Hand leadership over now instead of after the lease TTL
+	if elector != nil {
+		elector.Resign()
+	}
 	log.Println("Server exited")
 }
@@ -2,31 +2,504 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Config holds the application configuration
 type Config struct {
 	// Database
-	DatabaseURL string
+	DatabaseURL string // postgres://… or sqlite:path/to/file.db (single instance, -tags sqlite)
 
 	// Server
 	ServerPort string
+	PublicURL  string // Orchestrator URL reachable from training nodes (elastic discovery)
+
+	// Operator access
+	AdminAPIToken string // Bearer token for admin-only endpoints (e.g. job boosts); empty disables them
+
+	// Background worker supervision
+	WorkerMaxRestarts    int           // Crashes per worker before it is left stopped and /health/ready fails
+	WorkerRestartBackoff time.Duration // First restart delay; doubles per restart up to 5 minutes
+
+	// Background loop intervals (bounds in Intervals; checked by Validate)
+	SchedulerTick            time.Duration // Queue processing
+	PendingResyncInterval    time.Duration // Reload pending jobs submitted through other replicas
+	AutoscalerInterval       time.Duration
+	JobMonitorInterval       time.Duration
+	CostTickInterval         time.Duration // Running cost accrual
+	CostFlushMinDelta        float64       // USD a running cost must grow by before it is written; 0 writes every tick
+	AlertEvalInterval        time.Duration
+	ElasticScaleInterval     time.Duration
+	HibernationCheckInterval time.Duration
+	SessionCheckInterval     time.Duration
+	QueueAlarmInterval       time.Duration // Pending jobs past constraints.max_queue_time
+	PricingRefreshInterval   time.Duration // On-demand prices of each provider
+	PricingSpotRefresh       time.Duration // Spot prices of each provider
+	WorkerWatchInterval      time.Duration // Supervisor heartbeat checks
+
+	// Leader election between replicas (only the leader runs singleton workers)
+	LeaderElection bool          // Disable only for a single replica without lease table access
+	LeaderLeaseTTL time.Duration // Followers take over this long after the leader stops renewing
+	InstanceID     string        // This replica's lease holder ID
+
+	// Spec parsing
+	SpecUnknownFields string // error | warn (ignore unknown spec fields and attach warnings to the job)
+
+	// Admission control
+	AdmissionMode    string        // off | warn | reject
+	AdmissionTimeout time.Duration // Budget for the submission-time feasibility check
+
+	// Org policies
+	PolicyRulesFile       string        // YAML policy rules upserted at startup
+	PolicyWebhookURL      string        // External allow/deny/mutate webhook; empty disables
+	PolicyWebhookTimeout  time.Duration // Budget for one webhook call
+	PolicyWebhookFailOpen bool          // Allow jobs when the webhook errors or times out (default: deny)
+
+	// Optimizer
+	NodeLimitsFile       string        // YAML per-provider/region/instance-family node limit overrides
+	InstanceTypeAllow    []string      // Org-wide instance/GPU type globs jobs may use; empty = any
+	InstanceTypeDeny     []string      // Org-wide instance/GPU type globs no job may use; deny wins
+	TransferPricingFile  string        // YAML egress pricing rules consulted before the embedded table
+	CarbonIntensityFile  string        // YAML grid intensity and GPU power overrides of the embedded carbon dataset
+	BenchmarksFile       string        // YAML baseline throughput replacing embedded entries; version must not be older
+	PriceRecheckMaxDrift float64       // Hourly cost increase before provisioning that triggers re-optimization; 0 disables
+	PriceRecheckFreshFor time.Duration // Allocations scheduled more recently than this skip the re-check
+	PriceAnomalyFactor   float64       // Refreshed prices this many times above/below the stored price are quarantined; 0 disables price sanity checks
+
+	// Scheduling retries (pending jobs the optimizer places nowhere)
+	ScheduleRetryMaxAttempts int           // Failed attempts before the job fails; 0 retries forever
+	ScheduleRetryBase        time.Duration // Backoff after the first failed attempt; doubles per attempt
+	ScheduleRetryMaxBackoff  time.Duration // Longest backoff between attempts
+
+	// Pricing refresh (every provider and price kind refreshes on its own schedule)
+	PricingRefreshTimeout   time.Duration            // Bound on one provider's refresh of one price kind
+	PricingRefreshOverrides map[string]time.Duration // Refresh intervals by "provider.kind", e.g. azure.spot
+
+	// Pricing subscriptions, evaluated after every refresh
+	PricingSubscriptionLimit int // Subscriptions per user
+
+	// Node bootstrap
+	BootstrapDefaultFile string // YAML org-level bootstrap block applied before each job's
+
+	// Dataset verification (data.verify / data.manifest in the spec)
+	DatasetVerifyMaxObjects int // Listing cap; full mode fails above it, size mode reports a lower bound
+	DatasetVerifySampleSize int // Objects listed and manifest entries checked in sample mode
+
+	// Stuck job sweeper (per-job override: execution.stuck_after in the spec)
+	StuckSweepInterval time.Duration
+	StuckScheduled     time.Duration // Warn after this long in scheduled; 0 disables
+	StuckProvisioning  time.Duration
+	StuckCheckpointing time.Duration
+	StuckEscalation    int    // Escalate after this multiple of the threshold
+	StuckAction        string // alert | requeue | fail
+
+	// Cluster hibernation (stop instead of terminate between jobs)
+	HibernationWindow    time.Duration // How long a stopped cluster waits for a follow-up job; 0 disables
+	HibernationStorageGB int           // Disk per node billed while stopped
+
+	// Kubernetes GPU sharing
+	K8sTimeSlicingReplicas int // Replicas per GPU in the clusters' device plugin time-slicing config
+
+	// Framework rendezvous ports, allocated per node so co-located jobs never collide
+	FrameworkPortMin    int           // 0 disables allocation (frameworks use their default ports)
+	FrameworkPortMax    int           // Inclusive
+	PortReleaseInterval time.Duration // Ports still held by ended jobs are released this often
+
+	// Checkpoint retention (per-job override: artifacts.retention in the spec)
+	ArtifactRetentionKeepLast   int           // Newest checkpoints kept per completed job
+	ArtifactRetentionMaxAgeDays int           // Older checkpoints beyond keep_last are deleted
+	ArtifactGCInterval          time.Duration // 0 disables scheduled passes
+	ArtifactGCDryRun            bool          // Scheduled passes only report (default true)
+
+	// Checkpoint replication (per-job opt-in: checkpointing.replicate_to)
+	CheckpointLocations       map[string]string // "provider/region" -> checkpoint URI prefix stored there
+	CheckpointReplicaInterval time.Duration     // 0 disables the replicator
+	CheckpointReplicaAttempts int               // Copies are retried until marked failed
+
+	// Billing export
+	CostExportURI string // Default object storage prefix for async billing exports
+
+	// Fair share (soft per-team GPU-hour shares)
+	FairShareWeights       map[string]float64 // Team -> share weight; empty disables fair share
+	FairShareWindow        time.Duration      // Rolling usage window
+	FairShareMaxAdjustment float64            // Cap on a team's adjustment, in doublings of usage over share
+	FairShareMaxDelay      time.Duration      // Jobs pending longer are never moved back; 0 = no limit
+	FairShareRefresh       time.Duration
+
+	// GPU-hour quotas (monthly team and project limits, managed through the API)
+	GPUQuotaRefresh time.Duration // Consumption older than this is recomputed before a quota check
+
+	// Migration advisor (restart elsewhere from a checkpoint when cheaper)
+	MigrationCheckInterval    time.Duration // 0 disables the advisor
+	MigrationMinSavingsUSD    float64
+	MigrationRestartOverhead  time.Duration // Provisioning and restore time charged to a restart
+	MigrationMaxCheckpointAge time.Duration // Jobs whose latest checkpoint is older are not evaluated
+
+	// Maintenance watcher (provider-scheduled host maintenance of running nodes)
+	MaintenanceCheckInterval time.Duration // 0 disables the watcher
+	MaintenanceMigrateLead   time.Duration // Migrate opted-in jobs this long before the window; 0 only warns
+
+	// Rebalance watcher (spot rebalance recommendations of running nodes)
+	RebalanceCheckInterval     time.Duration // 0 disables the watcher
+	RebalanceRequestCheckpoint bool          // Ask jobs with checkpointing for an immediate checkpoint
+	RebalanceReplaceNodes      bool          // Add an on-demand node to elastic jobs ahead of the interruption
+
+	// Carbon accounting (estimated emissions of finished jobs)
+	CarbonAccountInterval time.Duration // 0 disables accounting
+
+	// Checkpoint cadence check of spot-backed jobs declaring checkpointing
+	CheckpointCadenceInterval time.Duration // 0 disables the check
+
+	// Machine image registry (pre-baked images preferred per instance family)
+	ImageBootBudget        time.Duration // Boot budget of images registered without one
+	ImageBootCheckInterval time.Duration // 0 disables degrading slow images
+
+	// Cancellation grace window (cancelled running jobs can be restored until it ends)
+	CancelGrace         time.Duration // 0 cancels immediately
+	CancelSweepInterval time.Duration
+
+	// Job suspension
+	SuspendCheckpointTimeout time.Duration // Longest wait for a suspension's checkpoint; 0 = none
+	SuspendSweepInterval     time.Duration
+
+	// Consistency checker (read-only comparison of jobs, provider instances and cost)
+	ConsistencyCheckInterval time.Duration // 0 disables the check
+	ConsistencyProviderPause time.Duration // Wait between provider API calls
+	ConsistencyGrace         time.Duration // Newer instances and jobs are not checked
+	ConsistencyCostGapRatio  float64       // Flag running jobs whose recorded cost is below this share of expected
+
+	// Deadline risk (unfinished jobs with constraints.deadline)
+	DeadlineRiskInterval   time.Duration // 0 disables the evaluator
+	DeadlineRiskWatch      float64       // Risk (0-1) of the watch level
+	DeadlineRiskAtRisk     float64       // Risk (0-1) of the at_risk level
+	DeadlineRiskLikelyMiss float64       // Risk (0-1) of the likely_miss level
+
+	// Managed training (execution.backend managed: SageMaker Training, Vertex AI Custom Training)
+	ManagedTrainingPoll  time.Duration // How often running managed training jobs are polled
+	SageMakerRoleARN     string        // Execution role of SageMaker training jobs; empty disables SageMaker
+	SageMakerImage       string        // Training container of SageMaker jobs
+	SageMakerOutputURI   string        // s3:// default output of jobs without data.access.output_uri
+	VertexTrainingImage  string        // Training container of Vertex AI jobs; empty disables Vertex AI
+	VertexTrainingOutput string        // gs:// default output of jobs without data.access.output_uri
+
+	// Orphan cleaner (records left behind by missing or ended jobs)
+	OrphanCleanupInterval time.Duration // 0 disables scheduled passes
+	OrphanCleanupApply    bool          // Scheduled passes delete what they find (default false: report only)
+	OrphanRetention       time.Duration // Records of jobs that ended more recently are kept
+	OrphanBatchSize       int           // Rows deleted per statement
+
+	// Data gravity (jobs lean toward the regions holding their team's data)
+	DataGravityHalfLife time.Duration // Data this old counts half; 0 disables data gravity
+
+	// Audit log of mutating API calls and system status changes
+	AuditActorHeader   string        // Header an authenticating proxy sets to the caller's identity; empty = not trusted
+	AuditRetention     time.Duration // Older entries are deleted; 0 keeps them forever
+	AuditPruneInterval time.Duration
+
+	// Launch config artifacts
+	LaunchConfigURI string // Object storage prefix for content-addressed launch scripts; "" stores them inline
+
+	// Post-mortem bundles of failed jobs
+	PostmortemURI      string        // Object storage prefix for bundles; "" disables generation
+	PostmortemMaxBytes int           // Cap on a bundle's uncompressed content
+	PostmortemLogLines int           // Lines tailed from each node log
+	PostmortemInterval time.Duration // How often new failures are bundled
+	PostmortemURLTTL   time.Duration // Lifetime of bundle download URLs
+
+	// Bulk job submission (POST /v1/jobs/batch)
+	JobBatchMaxItems    int // Items per batch
+	JobBatchConcurrency int // Items validated and stored at once
+
+	// Client-supplied times (e.g. constraints.deadline)
+	ClockSkewAllowance time.Duration // How far in the past a client time may be before it is rejected
+
+	// What-if replays (capacity planning)
+	WhatIfSyncJobs int // Windows with at most this many jobs are answered inline; larger ones run async
+	WhatIfMaxJobs  int // Windows with more jobs are rejected
+
+	// Interactive sessions
+	SessionIdleTimeout time.Duration // GPU-idle time before an idle_stop session is stopped; 0 disables
+
+	// Console attach (WebSocket shell on a running job's primary node)
+	ConsoleAttachEnabled bool
+	ConsoleSSHUser       string
+	ConsoleSSHKeyFile    string        // Orchestrator's private key for nodes without a cluster key
+	ConsoleIdleTimeout   time.Duration // Sessions without input or output are closed; 0 = never
+	ConsoleTranscriptURI string        // Object storage prefix for session transcripts; "" stores them inline
+
+	// Per-cluster SSH keys
+	SecretsMasterKey       string        // Base64 32-byte key sealing stored private keys; "" disables per-cluster keys
+	NodeSSHUser            string        // Node user the per-cluster keys log in as
+	SSHKeyRotationAge      time.Duration // Active keys this old are rotated; 0 disables scheduled rotation
+	SSHKeyRotationInterval time.Duration // How often key ages are checked
+
+	// Network egress of running jobs (counters sampled over SSH)
+	EgressSampleInterval time.Duration // 0 disables sampling
+	EgressAlertUSD       float64       // Egress cost that alerts; 0 = no absolute threshold
+	EgressAlertRatio     float64       // Egress cost over compute cost that alerts; 0 = no proportional threshold
 
 	// AWS
-	AWSRegion string
+	AWSRegion          string
+	AWSRegions         []string
+	AWSInstanceProfile string            // Attached to instances of jobs without data.access
+	AWSSubnets         map[string]string // Subnet instances use per availability zone; others use the zone's default subnet
+
+	// GCP
+	GCPProjectID      string
+	GCPRegions        []string
+	GCPServiceAccount string // Attached to instances of jobs without data.access; empty = compute default
+
+	// Job-scoped identities (data.access auto) left on ended jobs are deleted this often
+	IdentityCleanupInterval time.Duration
+
+	// Provider API usage
+	ProviderCallBudgets        map[string]int // Provider -> calls per hour before non-critical callers are throttled
+	ProviderUsageFlushInterval time.Duration  // How often metered calls are added to the daily rollups
+
+	// API usage
+	APIUsageFlushInterval time.Duration // How often metered requests are added to the daily rollups
+	APIUsageMaxJobs       int           // Distinct job IDs counted per flush window; the rest are counted as _other
+	APIUsageMaxClients    int           // Distinct clients counted since start; the rest are counted as _other
+
+	// Spot interruption rates are recomputed from recorded reclaims this often
+	InterruptionRefreshInterval time.Duration
+
+	// Admin changes to the instance type lists are picked up by other replicas this often
+	InstanceTypeSyncInterval time.Duration
+
+	// Azure
+	AzureSubscriptionID string
+	AzureRegions        []string
+	AzureAccessToken    string // ARM token; empty = the server VM's managed identity
+	AzureResourceGroup  string // VMs and their network interfaces are created here
+	AzureSubnetID       string // Subnet resource ID the VMs join
+	AzureAdminUser      string
+	AzureSSHPublicKey   string // Authorized for the admin user; required to create VMs
+
+	// CoreWeave (enabled when an endpoint or price sheet is configured)
+	CoreWeaveEndpoint   string
+	CoreWeaveAPIToken   string
+	CoreWeaveRegions    []string
+	CoreWeavePriceSheet string
 
 	// On-premise
 	OnPremEndpoint string
+
+	// Object storage
+	// MinIO aliases map minio://alias/bucket/path URIs to S3-compatible endpoints
+	MinIOAliases        map[string]MinIOAlias
+	GCSAccessToken      string // Empty = GCE metadata server
+	AzureStorageAccount string
+	AzureStorageSAS     string
+
+	// Alerting
+	AlertRulesFile  string // YAML rules upserted at startup
+	AlertWebhookURL string // Default webhook for rules without their own
+	SMTPHost        string // Email is logged instead of sent when empty
+	SMTPPort        string
+	SMTPFrom        string
+	SMTPUsername    string
+	SMTPPassword    string
+}
+
+// MinIOAlias is an S3-compatible endpoint and its credentials
+type MinIOAlias struct {
+	Endpoint  string
+	Region    string
+	AccessKey string
+	SecretKey string
 }
 
 // Load loads configuration from environment variables
 func Load() *Config {
 	return &Config{
-		DatabaseURL: getEnv("DATABASE_URL", "postgres://localhost/gpu_orchestrator?sslmode=disable"),
-		ServerPort:  getEnv("SERVER_PORT", "8080"),
-		AWSRegion:   getEnv("AWS_REGION", "us-east-1"),
-		OnPremEndpoint: getEnv("ONPREM_ENDPOINT", ""),
+		DatabaseURL:                 getEnv("DATABASE_URL", "postgres://localhost/gpu_orchestrator?sslmode=disable"),
+		ServerPort:                  getEnv("SERVER_PORT", "8080"),
+		PublicURL:                   strings.TrimRight(getEnv("ORCHESTRATOR_URL", ""), "/"),
+		AdminAPIToken:               getEnv("ADMIN_API_TOKEN", ""),
+		WorkerMaxRestarts:           getEnvInt("WORKER_MAX_RESTARTS", 5),
+		WorkerRestartBackoff:        time.Duration(getEnvInt("WORKER_RESTART_BACKOFF_SECONDS", 5)) * time.Second,
+		SchedulerTick:               time.Duration(getEnvInt("SCHEDULER_TICK_SECONDS", 5)) * time.Second,
+		PendingResyncInterval:       time.Duration(getEnvInt("PENDING_RESYNC_SECONDS", 30)) * time.Second,
+		AutoscalerInterval:          time.Duration(getEnvInt("AUTOSCALER_INTERVAL_SECONDS", 30)) * time.Second,
+		JobMonitorInterval:          time.Duration(getEnvInt("JOB_MONITOR_INTERVAL_SECONDS", 30)) * time.Second,
+		CostTickInterval:            time.Duration(getEnvInt("COST_TICK_SECONDS", 60)) * time.Second,
+		CostFlushMinDelta:           float64(getEnvInt("COST_FLUSH_MIN_DELTA_CENTS", 1)) / 100,
+		AlertEvalInterval:           time.Duration(getEnvInt("ALERT_EVAL_INTERVAL_SECONDS", 60)) * time.Second,
+		ElasticScaleInterval:        time.Duration(getEnvInt("ELASTIC_SCALE_INTERVAL_SECONDS", 60)) * time.Second,
+		HibernationCheckInterval:    time.Duration(getEnvInt("HIBERNATION_CHECK_INTERVAL_SECONDS", 60)) * time.Second,
+		SessionCheckInterval:        time.Duration(getEnvInt("SESSION_CHECK_INTERVAL_SECONDS", 30)) * time.Second,
+		QueueAlarmInterval:          time.Duration(getEnvInt("QUEUE_ALARM_INTERVAL_SECONDS", 60)) * time.Second,
+		PricingRefreshInterval:      time.Duration(getEnvInt("PRICING_REFRESH_MINUTES", 15)) * time.Minute,
+		PricingSpotRefresh:          time.Duration(getEnvInt("PRICING_SPOT_REFRESH_MINUTES", 5)) * time.Minute,
+		PricingRefreshTimeout:       time.Duration(getEnvInt("PRICING_REFRESH_TIMEOUT_SECONDS", 120)) * time.Second,
+		PricingRefreshOverrides:     getPricingRefreshOverrides(),
+		PricingSubscriptionLimit:    getEnvInt("PRICING_SUBSCRIPTIONS_PER_USER", 20),
+		WorkerWatchInterval:         time.Duration(getEnvInt("WORKER_WATCH_SECONDS", 30)) * time.Second,
+		LeaderElection:              getEnv("LEADER_ELECTION", "true") != "false",
+		LeaderLeaseTTL:              time.Duration(getEnvInt("LEADER_LEASE_TTL_SECONDS", 15)) * time.Second,
+		InstanceID:                  getEnv("INSTANCE_ID", defaultInstanceID()),
+		SpecUnknownFields:           getEnv("SPEC_UNKNOWN_FIELDS", "error"),
+		AdmissionMode:               getEnv("ADMISSION_MODE", "warn"),
+		AdmissionTimeout:            time.Duration(getEnvInt("ADMISSION_TIMEOUT_MS", 500)) * time.Millisecond,
+		PolicyRulesFile:             getEnv("POLICY_RULES_FILE", ""),
+		PolicyWebhookURL:            getEnv("POLICY_WEBHOOK_URL", ""),
+		PolicyWebhookTimeout:        time.Duration(getEnvInt("POLICY_WEBHOOK_TIMEOUT_MS", 2000)) * time.Millisecond,
+		PolicyWebhookFailOpen:       getEnv("POLICY_WEBHOOK_FAIL_OPEN", "false") == "true",
+		NodeLimitsFile:              getEnv("NODE_LIMITS_FILE", ""),
+		InstanceTypeAllow:           getEnvList("INSTANCE_TYPE_ALLOW", nil),
+		InstanceTypeDeny:            getEnvList("INSTANCE_TYPE_DENY", nil),
+		TransferPricingFile:         getEnv("TRANSFER_PRICING_FILE", ""),
+		CarbonIntensityFile:         getEnv("CARBON_INTENSITY_FILE", ""),
+		BenchmarksFile:              getEnv("PERFORMANCE_BENCHMARKS_FILE", ""),
+		PriceRecheckMaxDrift:        float64(getEnvInt("PRICE_RECHECK_MAX_DRIFT_PCT", 10)) / 100,
+		PriceAnomalyFactor:          float64(getEnvInt("PRICE_ANOMALY_FACTOR", 5)),
+		PriceRecheckFreshFor:        time.Duration(getEnvInt("PRICE_RECHECK_AFTER_SECONDS", 60)) * time.Second,
+		ScheduleRetryMaxAttempts:    getEnvInt("SCHEDULE_RETRY_MAX_ATTEMPTS", 10),
+		ScheduleRetryBase:           time.Duration(getEnvInt("SCHEDULE_RETRY_BASE_SECONDS", 30)) * time.Second,
+		ScheduleRetryMaxBackoff:     time.Duration(getEnvInt("SCHEDULE_RETRY_MAX_BACKOFF_SECONDS", 1800)) * time.Second,
+		BootstrapDefaultFile:        getEnv("BOOTSTRAP_DEFAULT_FILE", ""),
+		DatasetVerifyMaxObjects:     getEnvInt("DATASET_VERIFY_MAX_OBJECTS", 100000),
+		DatasetVerifySampleSize:     getEnvInt("DATASET_VERIFY_SAMPLE_SIZE", 100),
+		StuckSweepInterval:          time.Duration(getEnvInt("STUCK_SWEEP_INTERVAL_SECONDS", 60)) * time.Second,
+		StuckScheduled:              time.Duration(getEnvInt("STUCK_SCHEDULED_MINUTES", 10)) * time.Minute,
+		StuckProvisioning:           time.Duration(getEnvInt("STUCK_PROVISIONING_MINUTES", 45)) * time.Minute,
+		StuckCheckpointing:          time.Duration(getEnvInt("STUCK_CHECKPOINTING_MINUTES", 30)) * time.Minute,
+		StuckEscalation:             getEnvInt("STUCK_ESCALATION_MULTIPLIER", 2),
+		StuckAction:                 getEnv("STUCK_ACTION", "alert"),
+		HibernationWindow:           time.Duration(getEnvInt("HIBERNATION_WINDOW_MINUTES", 0)) * time.Minute,
+		HibernationStorageGB:        getEnvInt("HIBERNATION_STORAGE_GB", 500),
+		K8sTimeSlicingReplicas:      getEnvInt("K8S_TIME_SLICING_REPLICAS", 4),
+		FrameworkPortMin:            getEnvInt("FRAMEWORK_PORT_MIN", 29500),
+		FrameworkPortMax:            getEnvInt("FRAMEWORK_PORT_MAX", 29999),
+		PortReleaseInterval:         time.Duration(getEnvInt("PORT_RELEASE_INTERVAL_SECONDS", 60)) * time.Second,
+		CostExportURI:               getEnv("COST_EXPORT_URI", ""),
+		FairShareWeights:            getFairShareWeights(),
+		ProviderCallBudgets:         getProviderCallBudgets(),
+		ProviderUsageFlushInterval:  time.Duration(getEnvInt("PROVIDER_USAGE_FLUSH_SECONDS", 60)) * time.Second,
+		APIUsageFlushInterval:       time.Duration(getEnvInt("API_USAGE_FLUSH_SECONDS", 60)) * time.Second,
+		APIUsageMaxJobs:             getEnvInt("API_USAGE_MAX_JOBS", 1000),
+		APIUsageMaxClients:          getEnvInt("API_USAGE_MAX_CLIENTS", 200),
+		InterruptionRefreshInterval: time.Duration(getEnvInt("INTERRUPTION_REFRESH_SECONDS", 900)) * time.Second,
+		InstanceTypeSyncInterval:    time.Duration(getEnvInt("INSTANCE_TYPE_RULES_SYNC_SECONDS", 60)) * time.Second,
+		FairShareWindow:             time.Duration(getEnvInt("FAIRSHARE_WINDOW_HOURS", 168)) * time.Hour,
+		FairShareMaxAdjustment:      float64(getEnvInt("FAIRSHARE_MAX_ADJUSTMENT", 2)),
+		FairShareMaxDelay:           time.Duration(getEnvInt("FAIRSHARE_MAX_DELAY_MINUTES", 240)) * time.Minute,
+		FairShareRefresh:            time.Duration(getEnvInt("FAIRSHARE_REFRESH_SECONDS", 300)) * time.Second,
+		GPUQuotaRefresh:             time.Duration(getEnvInt("GPU_QUOTA_REFRESH_SECONDS", 60)) * time.Second,
+		MigrationCheckInterval:      time.Duration(getEnvInt("MIGRATION_CHECK_INTERVAL_MINUTES", 15)) * time.Minute,
+		MigrationMinSavingsUSD:      float64(getEnvInt("MIGRATION_MIN_SAVINGS_USD", 50)),
+		MigrationRestartOverhead:    time.Duration(getEnvInt("MIGRATION_RESTART_OVERHEAD_MINUTES", 15)) * time.Minute,
+		MigrationMaxCheckpointAge:   time.Duration(getEnvInt("MIGRATION_MAX_CHECKPOINT_AGE_MINUTES", 60)) * time.Minute,
+		MaintenanceCheckInterval:    time.Duration(getEnvInt("MAINTENANCE_CHECK_INTERVAL_SECONDS", 300)) * time.Second,
+		MaintenanceMigrateLead:      time.Duration(getEnvInt("MAINTENANCE_MIGRATE_LEAD_MINUTES", 30)) * time.Minute,
+		RebalanceCheckInterval:      time.Duration(getEnvInt("REBALANCE_CHECK_INTERVAL_SECONDS", 60)) * time.Second,
+		RebalanceRequestCheckpoint:  getEnv("REBALANCE_REQUEST_CHECKPOINT", "true") != "false",
+		RebalanceReplaceNodes:       getEnv("REBALANCE_PROVISION_REPLACEMENT", "false") == "true",
+		CarbonAccountInterval:       time.Duration(getEnvInt("CARBON_ACCOUNT_INTERVAL_SECONDS", 300)) * time.Second,
+		CheckpointCadenceInterval:   time.Duration(getEnvInt("CHECKPOINT_CADENCE_INTERVAL_SECONDS", 120)) * time.Second,
+		ImageBootBudget:             time.Duration(getEnvInt("IMAGE_BOOT_BUDGET_SECONDS", 300)) * time.Second,
+		ImageBootCheckInterval:      time.Duration(getEnvInt("IMAGE_BOOT_CHECK_INTERVAL_SECONDS", 300)) * time.Second,
+		CancelGrace:                 time.Duration(getEnvInt("CANCEL_GRACE_SECONDS", 120)) * time.Second,
+		CancelSweepInterval:         time.Duration(getEnvInt("CANCEL_SWEEP_INTERVAL_SECONDS", 10)) * time.Second,
+		SuspendCheckpointTimeout:    time.Duration(getEnvInt("SUSPEND_CHECKPOINT_TIMEOUT_SECONDS", 600)) * time.Second,
+		SuspendSweepInterval:        time.Duration(getEnvInt("SUSPEND_SWEEP_INTERVAL_SECONDS", 10)) * time.Second,
+		ConsistencyCheckInterval:    time.Duration(getEnvInt("CONSISTENCY_CHECK_INTERVAL_HOURS", 24)) * time.Hour,
+		ConsistencyProviderPause:    time.Duration(getEnvInt("CONSISTENCY_PROVIDER_PAUSE_MS", 500)) * time.Millisecond,
+		ConsistencyGrace:            time.Duration(getEnvInt("CONSISTENCY_GRACE_MINUTES", 30)) * time.Minute,
+		ConsistencyCostGapRatio:     float64(getEnvInt("CONSISTENCY_COST_GAP_PERCENT", 50)) / 100,
+		DeadlineRiskInterval:        time.Duration(getEnvInt("DEADLINE_RISK_INTERVAL_SECONDS", 300)) * time.Second,
+		DeadlineRiskWatch:           float64(getEnvInt("DEADLINE_RISK_WATCH_PCT", 30)) / 100,
+		DeadlineRiskAtRisk:          float64(getEnvInt("DEADLINE_RISK_AT_RISK_PCT", 60)) / 100,
+		DeadlineRiskLikelyMiss:      float64(getEnvInt("DEADLINE_RISK_LIKELY_MISS_PCT", 85)) / 100,
+		ManagedTrainingPoll:         time.Duration(getEnvInt("MANAGED_TRAINING_POLL_SECONDS", 60)) * time.Second,
+		SageMakerRoleARN:            getEnv("SAGEMAKER_ROLE_ARN", ""),
+		SageMakerImage:              getEnv("SAGEMAKER_TRAINING_IMAGE", ""),
+		SageMakerOutputURI:          getEnv("SAGEMAKER_OUTPUT_URI", ""),
+		VertexTrainingImage:         getEnv("VERTEX_TRAINING_IMAGE", ""),
+		VertexTrainingOutput:        getEnv("VERTEX_OUTPUT_URI", ""),
+		OrphanCleanupInterval:       time.Duration(getEnvInt("ORPHAN_CLEANUP_INTERVAL_HOURS", 24)) * time.Hour,
+		OrphanCleanupApply:          getEnv("ORPHAN_CLEANUP_APPLY", "false") == "true",
+		OrphanRetention:             time.Duration(getEnvInt("ORPHAN_RETENTION_DAYS", 30)) * 24 * time.Hour,
+		OrphanBatchSize:             getEnvInt("ORPHAN_CLEANUP_BATCH_SIZE", 500),
+		DataGravityHalfLife:         time.Duration(getEnvInt("DATA_GRAVITY_HALF_LIFE_DAYS", 30)) * 24 * time.Hour,
+		AuditActorHeader:            getEnv("AUDIT_ACTOR_HEADER", ""),
+		AuditRetention:              time.Duration(getEnvInt("AUDIT_RETENTION_DAYS", 365)) * 24 * time.Hour,
+		AuditPruneInterval:          time.Duration(getEnvInt("AUDIT_PRUNE_INTERVAL_MINUTES", 60)) * time.Minute,
+		LaunchConfigURI:             getEnv("LAUNCH_CONFIG_URI", ""),
+		PostmortemURI:               strings.TrimRight(getEnv("POSTMORTEM_URI", ""), "/"),
+		PostmortemMaxBytes:          getEnvInt("POSTMORTEM_MAX_MB", 20) << 20,
+		PostmortemLogLines:          getEnvInt("POSTMORTEM_LOG_LINES", 200),
+		PostmortemInterval:          time.Duration(getEnvInt("POSTMORTEM_INTERVAL_SECONDS", 60)) * time.Second,
+		PostmortemURLTTL:            time.Duration(getEnvInt("POSTMORTEM_URL_TTL_MINUTES", 15)) * time.Minute,
+		JobBatchMaxItems:            getEnvInt("JOB_BATCH_MAX_ITEMS", 500),
+		JobBatchConcurrency:         getEnvInt("JOB_BATCH_CONCURRENCY", 8),
+		ClockSkewAllowance:          time.Duration(getEnvInt("CLOCK_SKEW_ALLOWANCE_SECONDS", 30)) * time.Second,
+		WhatIfSyncJobs:              getEnvInt("WHATIF_SYNC_JOBS", 200),
+		WhatIfMaxJobs:               getEnvInt("WHATIF_MAX_JOBS", 20000),
+		SessionIdleTimeout:          time.Duration(getEnvInt("SESSION_IDLE_TIMEOUT_MINUTES", 60)) * time.Minute,
+		ConsoleAttachEnabled:        getEnv("CONSOLE_ATTACH_ENABLED", "false") == "true",
+		ConsoleSSHUser:              getEnv("CONSOLE_SSH_USER", "ubuntu"),
+		ConsoleSSHKeyFile:           getEnv("CONSOLE_SSH_KEY_FILE", ""),
+		ConsoleIdleTimeout:          time.Duration(getEnvInt("CONSOLE_IDLE_TIMEOUT_MINUTES", 15)) * time.Minute,
+		ConsoleTranscriptURI:        strings.TrimRight(getEnv("CONSOLE_TRANSCRIPT_URI", ""), "/"),
+		SecretsMasterKey:            getEnv("SECRETS_MASTER_KEY", ""),
+		NodeSSHUser:                 getEnv("NODE_SSH_USER", "ubuntu"),
+		SSHKeyRotationAge:           time.Duration(getEnvInt("SSH_KEY_ROTATION_HOURS", 168)) * time.Hour,
+		SSHKeyRotationInterval:      time.Duration(getEnvInt("SSH_KEY_ROTATION_CHECK_INTERVAL_MINUTES", 60)) * time.Minute,
+		EgressSampleInterval:        time.Duration(getEnvInt("EGRESS_SAMPLE_INTERVAL_SECONDS", 300)) * time.Second,
+		EgressAlertUSD:              float64(getEnvInt("EGRESS_ALERT_USD", 0)),
+		EgressAlertRatio:            float64(getEnvInt("EGRESS_ALERT_PCT", 25)) / 100,
+		ArtifactRetentionKeepLast:   getEnvInt("ARTIFACT_RETENTION_KEEP_LAST", 3),
+		ArtifactRetentionMaxAgeDays: getEnvInt("ARTIFACT_RETENTION_MAX_AGE_DAYS", 30),
+		ArtifactGCInterval:          time.Duration(getEnvInt("ARTIFACT_GC_INTERVAL_MINUTES", 0)) * time.Minute,
+		ArtifactGCDryRun:            getEnv("ARTIFACT_GC_DRY_RUN", "true") != "false",
+		CheckpointLocations:         getCheckpointLocations(),
+		CheckpointReplicaInterval:   time.Duration(getEnvInt("CHECKPOINT_REPLICATION_INTERVAL_SECONDS", 60)) * time.Second,
+		CheckpointReplicaAttempts:   getEnvInt("CHECKPOINT_REPLICATION_MAX_ATTEMPTS", 5),
+		AWSRegion:                   getEnv("AWS_REGION", "us-east-1"),
+		AWSRegions:                  getEnvList("AWS_REGIONS", []string{"us-east-1", "us-west-2"}),
+		GCPProjectID:                getEnv("GCP_PROJECT_ID", "project-id"),
+		GCPRegions:                  getEnvList("GCP_REGIONS", []string{"us-central1"}),
+		GCPServiceAccount:           getEnv("GCP_SERVICE_ACCOUNT", ""),
+		AWSInstanceProfile:          getEnv("AWS_INSTANCE_PROFILE", "gpu-instance-profile"),
+		AWSSubnets:                  getAWSSubnets(),
+		IdentityCleanupInterval:     time.Duration(getEnvInt("IDENTITY_CLEANUP_INTERVAL_MINUTES", 10)) * time.Minute,
+		AzureSubscriptionID:         getEnv("AZURE_SUBSCRIPTION_ID", "subscription-id"),
+		AzureRegions:                getEnvList("AZURE_REGIONS", []string{"eastus"}),
+		AzureAccessToken:            getEnv("AZURE_ACCESS_TOKEN", ""),
+		AzureResourceGroup:          getEnv("AZURE_RESOURCE_GROUP", ""),
+		AzureSubnetID:               getEnv("AZURE_SUBNET_ID", ""),
+		AzureAdminUser:              getEnv("AZURE_ADMIN_USER", "azureuser"),
+		AzureSSHPublicKey:           getEnv("AZURE_SSH_PUBLIC_KEY", ""),
+		CoreWeaveEndpoint:           getEnv("COREWEAVE_ENDPOINT", ""),
+		CoreWeaveAPIToken:           getEnv("COREWEAVE_API_TOKEN", ""),
+		CoreWeaveRegions:            getEnvList("COREWEAVE_REGIONS", []string{"ORD1", "LAS1"}),
+		CoreWeavePriceSheet:         getEnv("COREWEAVE_PRICE_SHEET", ""),
+		OnPremEndpoint:              getEnv("ONPREM_ENDPOINT", ""),
+		MinIOAliases:                getMinIOAliases(),
+		GCSAccessToken:              getEnv("GCS_ACCESS_TOKEN", ""),
+		AzureStorageAccount:         getEnv("AZURE_STORAGE_ACCOUNT", ""),
+		AzureStorageSAS:             getEnv("AZURE_STORAGE_SAS_TOKEN", ""),
+		AlertRulesFile:              getEnv("ALERT_RULES_FILE", ""),
+		AlertWebhookURL:             getEnv("ALERT_WEBHOOK_URL", ""),
+		SMTPHost:                    getEnv("SMTP_HOST", ""),
+		SMTPPort:                    getEnv("SMTP_PORT", "587"),
+		SMTPFrom:                    getEnv("SMTP_FROM", "gpu-orchestrator@localhost"),
+		SMTPUsername:                getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:                getEnv("SMTP_PASSWORD", ""),
+	}
+}
+
+// defaultInstanceID identifies this replica by hostname and process ID
+func defaultInstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "orchestrator"
 	}
+	return hostname + "-" + strconv.Itoa(os.Getpid())
 }
 
 func getEnv(key, defaultValue string) string {
@@ -35,3 +508,113 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvInt parses an integer environment variable, falling back on parse errors
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvList parses a comma-separated environment variable
+func getEnvList(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// getMinIOAliases parses MINIO_ALIASES ("alias=https://host:9000,...").
+// Credentials come from MINIO_<ALIAS>_ACCESS_KEY / MINIO_<ALIAS>_SECRET_KEY and
+// the optional region from MINIO_<ALIAS>_REGION.
+func getMinIOAliases() map[string]MinIOAlias {
+	aliases := make(map[string]MinIOAlias)
+	for _, entry := range getEnvList("MINIO_ALIASES", nil) {
+		name, endpoint, ok := strings.Cut(entry, "=")
+		if !ok || name == "" || endpoint == "" {
+			continue
+		}
+		envPrefix := "MINIO_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+		aliases[name] = MinIOAlias{
+			Endpoint:  endpoint,
+			Region:    getEnv(envPrefix+"_REGION", ""),
+			AccessKey: getEnv(envPrefix+"_ACCESS_KEY", ""),
+			SecretKey: getEnv(envPrefix+"_SECRET_KEY", ""),
+		}
+	}
+	return aliases
+}
+
+// getAWSSubnets parses AWS_SUBNETS ("us-east-1a=subnet-0abc,us-east-1b=subnet-0def")
+func getAWSSubnets() map[string]string {
+	subnets := make(map[string]string)
+	for _, entry := range getEnvList("AWS_SUBNETS", nil) {
+		zone, subnet, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(zone) == "" || strings.TrimSpace(subnet) == "" {
+			continue
+		}
+		subnets[strings.TrimSpace(zone)] = strings.TrimSpace(subnet)
+	}
+	return subnets
+}
+
+// getCheckpointLocations parses CHECKPOINT_LOCATIONS
+// ("aws/us-west-2=s3://ckpt-usw2,gcp/us-central1=gs://ckpt-usc1")
+func getCheckpointLocations() map[string]string {
+	locations := make(map[string]string)
+	for _, entry := range getEnvList("CHECKPOINT_LOCATIONS", nil) {
+		location, prefix, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(location) == "" || strings.TrimSpace(prefix) == "" {
+			continue
+		}
+		locations[strings.TrimSpace(location)] = strings.TrimRight(strings.TrimSpace(prefix), "/")
+	}
+	return locations
+}
+
+// getPricingRefreshOverrides parses PRICING_REFRESH_OVERRIDES
+// ("azure.spot=15,gcp.on_demand=30", in minutes). Unparseable intervals are
+// kept as 0 so Validate reports them.
+func getPricingRefreshOverrides() map[string]time.Duration {
+	overrides := make(map[string]time.Duration)
+	for _, entry := range getEnvList("PRICING_REFRESH_OVERRIDES", nil) {
+		key, value, _ := strings.Cut(entry, "=")
+		minutes, _ := strconv.Atoi(strings.TrimSpace(value))
+		overrides[strings.TrimSpace(key)] = time.Duration(minutes) * time.Minute
+	}
+	return overrides
+}
+
+// getProviderCallBudgets parses PROVIDER_CALL_BUDGETS ("aws=5000,gcp=3000").
+// Unparseable budgets are kept as 0 so Validate reports them.
+func getProviderCallBudgets() map[string]int {
+	budgets := make(map[string]int)
+	for _, entry := range getEnvList("PROVIDER_CALL_BUDGETS", nil) {
+		provider, value, _ := strings.Cut(entry, "=")
+		budget, _ := strconv.Atoi(strings.TrimSpace(value))
+		budgets[strings.TrimSpace(provider)] = budget
+	}
+	return budgets
+}
+
+// getFairShareWeights parses FAIRSHARE_WEIGHTS ("team=80,other-team=20").
+// Unparseable weights are kept as 0 so Validate reports them.
+func getFairShareWeights() map[string]float64 {
+	weights := make(map[string]float64)
+	for _, entry := range getEnvList("FAIRSHARE_WEIGHTS", nil) {
+		team, value, _ := strings.Cut(entry, "=")
+		weight, _ := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		weights[strings.TrimSpace(team)] = weight
+	}
+	return weights
+}
@@ -0,0 +1,184 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gpu-orchestrator/core/secrets"
+)
+
+// LoopInterval describes one configurable background loop interval
+type LoopInterval struct {
+	Name     string
+	Env      string
+	Value    time.Duration
+	Min      time.Duration // Shorter intervals would busy-loop against the database or providers
+	Max      time.Duration // 0 = unbounded
+	Optional bool          // 0 disables the loop
+}
+
+// Intervals lists every background loop interval with its bounds
+func (c *Config) Intervals() []LoopInterval {
+	return []LoopInterval{
+		{Name: "scheduler_tick", Env: "SCHEDULER_TICK_SECONDS", Value: c.SchedulerTick, Min: time.Second},
+		{Name: "pending_resync", Env: "PENDING_RESYNC_SECONDS", Value: c.PendingResyncInterval, Min: 5 * time.Second},
+		{Name: "autoscaler", Env: "AUTOSCALER_INTERVAL_SECONDS", Value: c.AutoscalerInterval, Min: 5 * time.Second},
+		{Name: "job_monitor", Env: "JOB_MONITOR_INTERVAL_SECONDS", Value: c.JobMonitorInterval, Min: 5 * time.Second},
+		{Name: "cost_tick", Env: "COST_TICK_SECONDS", Value: c.CostTickInterval, Min: 10 * time.Second},
+		{Name: "alert_eval", Env: "ALERT_EVAL_INTERVAL_SECONDS", Value: c.AlertEvalInterval, Min: 10 * time.Second},
+		{Name: "elastic_scale", Env: "ELASTIC_SCALE_INTERVAL_SECONDS", Value: c.ElasticScaleInterval, Min: 10 * time.Second},
+		{Name: "hibernation_check", Env: "HIBERNATION_CHECK_INTERVAL_SECONDS", Value: c.HibernationCheckInterval, Min: 10 * time.Second},
+		{Name: "session_check", Env: "SESSION_CHECK_INTERVAL_SECONDS", Value: c.SessionCheckInterval, Min: 5 * time.Second},
+		{Name: "queue_alarm", Env: "QUEUE_ALARM_INTERVAL_SECONDS", Value: c.QueueAlarmInterval, Min: 10 * time.Second},
+		{Name: "stuck_sweep", Env: "STUCK_SWEEP_INTERVAL_SECONDS", Value: c.StuckSweepInterval, Min: 10 * time.Second},
+		// The pricing cache only serves rows refreshed within the last hour
+		{Name: "pricing_refresh", Env: "PRICING_REFRESH_MINUTES", Value: c.PricingRefreshInterval, Min: time.Minute, Max: 45 * time.Minute},
+		{Name: "pricing_spot_refresh", Env: "PRICING_SPOT_REFRESH_MINUTES", Value: c.PricingSpotRefresh, Min: time.Minute, Max: 45 * time.Minute},
+		{Name: "provider_usage_flush", Env: "PROVIDER_USAGE_FLUSH_SECONDS", Value: c.ProviderUsageFlushInterval, Min: 10 * time.Second},
+		{Name: "api_usage_flush", Env: "API_USAGE_FLUSH_SECONDS", Value: c.APIUsageFlushInterval, Min: 10 * time.Second},
+		{Name: "interruption_refresh", Env: "INTERRUPTION_REFRESH_SECONDS", Value: c.InterruptionRefreshInterval, Min: time.Minute},
+		{Name: "instance_type_rules_sync", Env: "INSTANCE_TYPE_RULES_SYNC_SECONDS", Value: c.InstanceTypeSyncInterval, Min: 10 * time.Second},
+		{Name: "identity_cleanup", Env: "IDENTITY_CLEANUP_INTERVAL_MINUTES", Value: c.IdentityCleanupInterval, Min: time.Minute},
+		{Name: "port_release", Env: "PORT_RELEASE_INTERVAL_SECONDS", Value: c.PortReleaseInterval, Min: 10 * time.Second},
+		{Name: "artifact_gc", Env: "ARTIFACT_GC_INTERVAL_MINUTES", Value: c.ArtifactGCInterval, Min: time.Minute, Optional: true},
+		{Name: "fairshare_refresh", Env: "FAIRSHARE_REFRESH_SECONDS", Value: c.FairShareRefresh, Min: 30 * time.Second},
+		{Name: "migration_check", Env: "MIGRATION_CHECK_INTERVAL_MINUTES", Value: c.MigrationCheckInterval, Min: time.Minute, Optional: true},
+		{Name: "maintenance_check", Env: "MAINTENANCE_CHECK_INTERVAL_SECONDS", Value: c.MaintenanceCheckInterval, Min: 30 * time.Second, Optional: true},
+		{Name: "rebalance_check", Env: "REBALANCE_CHECK_INTERVAL_SECONDS", Value: c.RebalanceCheckInterval, Min: 10 * time.Second, Optional: true},
+		{Name: "carbon_account", Env: "CARBON_ACCOUNT_INTERVAL_SECONDS", Value: c.CarbonAccountInterval, Min: 10 * time.Second, Optional: true},
+		{Name: "checkpoint_cadence", Env: "CHECKPOINT_CADENCE_INTERVAL_SECONDS", Value: c.CheckpointCadenceInterval, Min: 10 * time.Second, Optional: true},
+		{Name: "image_boot_check", Env: "IMAGE_BOOT_CHECK_INTERVAL_SECONDS", Value: c.ImageBootCheckInterval, Min: 30 * time.Second, Optional: true},
+		{Name: "cancel_sweep", Env: "CANCEL_SWEEP_INTERVAL_SECONDS", Value: c.CancelSweepInterval, Min: time.Second},
+		{Name: "suspend_sweep", Env: "SUSPEND_SWEEP_INTERVAL_SECONDS", Value: c.SuspendSweepInterval, Min: time.Second},
+		{Name: "consistency_check", Env: "CONSISTENCY_CHECK_INTERVAL_HOURS", Value: c.ConsistencyCheckInterval, Min: time.Hour, Optional: true},
+		{Name: "orphan_cleanup", Env: "ORPHAN_CLEANUP_INTERVAL_HOURS", Value: c.OrphanCleanupInterval, Min: time.Hour, Optional: true},
+		{Name: "deadline_risk", Env: "DEADLINE_RISK_INTERVAL_SECONDS", Value: c.DeadlineRiskInterval, Min: 30 * time.Second, Optional: true},
+		{Name: "managed_training", Env: "MANAGED_TRAINING_POLL_SECONDS", Value: c.ManagedTrainingPoll, Min: 10 * time.Second},
+		{Name: "postmortem", Env: "POSTMORTEM_INTERVAL_SECONDS", Value: c.PostmortemInterval, Min: 10 * time.Second, Optional: true},
+		{Name: "egress_sample", Env: "EGRESS_SAMPLE_INTERVAL_SECONDS", Value: c.EgressSampleInterval, Min: 30 * time.Second, Optional: true},
+		{Name: "ssh_key_rotation", Env: "SSH_KEY_ROTATION_CHECK_INTERVAL_MINUTES", Value: c.SSHKeyRotationInterval, Min: time.Minute, Optional: true},
+		{Name: "audit_prune", Env: "AUDIT_PRUNE_INTERVAL_MINUTES", Value: c.AuditPruneInterval, Min: time.Minute},
+		{Name: "worker_watch", Env: "WORKER_WATCH_SECONDS", Value: c.WorkerWatchInterval, Min: 5 * time.Second},
+	}
+}
+
+// Validate checks the configuration and returns the first problem found
+func (c *Config) Validate() error {
+	if c.SpecUnknownFields != "error" && c.SpecUnknownFields != "warn" {
+		return fmt.Errorf("invalid SPEC_UNKNOWN_FIELDS %q (want error or warn)", c.SpecUnknownFields)
+	}
+	for team, weight := range c.FairShareWeights {
+		if team == "" || weight <= 0 {
+			return fmt.Errorf("invalid FAIRSHARE_WEIGHTS entry %q=%v (want team=positive weight)", team, weight)
+		}
+	}
+	if len(c.FairShareWeights) > 0 && (c.FairShareWindow <= 0 || c.FairShareMaxAdjustment <= 0) {
+		return fmt.Errorf("FAIRSHARE_WINDOW_HOURS and FAIRSHARE_MAX_ADJUSTMENT must be positive")
+	}
+	if c.APIUsageMaxJobs < 1 || c.APIUsageMaxClients < 1 {
+		return fmt.Errorf("API_USAGE_MAX_JOBS and API_USAGE_MAX_CLIENTS must be positive")
+	}
+	if c.EgressAlertUSD < 0 || c.EgressAlertRatio < 0 {
+		return fmt.Errorf("EGRESS_ALERT_USD and EGRESS_ALERT_PCT must not be negative")
+	}
+	// The stuck-state sweeper would flag a suspension still waiting for its checkpoint
+	if c.SuspendCheckpointTimeout < 0 || (c.StuckCheckpointing > 0 && c.SuspendCheckpointTimeout >= c.StuckCheckpointing) {
+		return fmt.Errorf("SUSPEND_CHECKPOINT_TIMEOUT_SECONDS must not be negative and must stay under STUCK_CHECKPOINTING_MINUTES")
+	}
+	if c.OrphanRetention <= 0 || c.OrphanBatchSize < 1 {
+		return fmt.Errorf("ORPHAN_RETENTION_DAYS and ORPHAN_CLEANUP_BATCH_SIZE must be positive")
+	}
+	if c.DeadlineRiskWatch <= 0 || c.DeadlineRiskAtRisk <= c.DeadlineRiskWatch || c.DeadlineRiskLikelyMiss <= c.DeadlineRiskAtRisk || c.DeadlineRiskLikelyMiss > 1 {
+		return fmt.Errorf("DEADLINE_RISK_WATCH_PCT, DEADLINE_RISK_AT_RISK_PCT and DEADLINE_RISK_LIKELY_MISS_PCT must rise within 1-100")
+	}
+	if c.ScheduleRetryMaxAttempts < 0 || c.ScheduleRetryBase <= 0 || c.ScheduleRetryMaxBackoff < c.ScheduleRetryBase {
+		return fmt.Errorf("SCHEDULE_RETRY_MAX_ATTEMPTS must not be negative, SCHEDULE_RETRY_BASE_SECONDS must be positive and SCHEDULE_RETRY_MAX_BACKOFF_SECONDS at least the base")
+	}
+	if c.GPUQuotaRefresh <= 0 {
+		return fmt.Errorf("GPU_QUOTA_REFRESH_SECONDS must be positive")
+	}
+	if c.CostFlushMinDelta < 0 {
+		return fmt.Errorf("COST_FLUSH_MIN_DELTA_CENTS must not be negative")
+	}
+	if c.DataGravityHalfLife < 0 {
+		return fmt.Errorf("DATA_GRAVITY_HALF_LIFE_DAYS must not be negative")
+	}
+	for provider, budget := range c.ProviderCallBudgets {
+		if provider == "" || budget <= 0 {
+			return fmt.Errorf("invalid PROVIDER_CALL_BUDGETS entry %q=%d (want provider=positive calls per hour)", provider, budget)
+		}
+	}
+	for key, interval := range c.PricingRefreshOverrides {
+		_, kind, _ := strings.Cut(key, ".")
+		if !strings.Contains(key, ".") || (kind != "on_demand" && kind != "spot") {
+			return fmt.Errorf("invalid PRICING_REFRESH_OVERRIDES entry %q (want provider.on_demand or provider.spot)", key)
+		}
+		// Same bounds as PRICING_REFRESH_MINUTES
+		if interval < time.Minute || interval > 45*time.Minute {
+			return fmt.Errorf("PRICING_REFRESH_OVERRIDES %s is %s, want 1 to 45 minutes", key, interval)
+		}
+	}
+	if c.PricingRefreshTimeout <= 0 {
+		return fmt.Errorf("PRICING_REFRESH_TIMEOUT_SECONDS must be positive")
+	}
+	if c.PricingSubscriptionLimit < 1 {
+		return fmt.Errorf("PRICING_SUBSCRIPTIONS_PER_USER must be at least 1")
+	}
+	if c.K8sTimeSlicingReplicas < 1 {
+		return fmt.Errorf("K8S_TIME_SLICING_REPLICAS must be at least 1")
+	}
+	if c.FrameworkPortMin != 0 && (c.FrameworkPortMin < 1024 || c.FrameworkPortMax > 65535 || c.FrameworkPortMax < c.FrameworkPortMin) {
+		return fmt.Errorf("FRAMEWORK_PORT_MIN and FRAMEWORK_PORT_MAX must be 0 (disabled) or a range within 1024-65535")
+	}
+	if c.PriceAnomalyFactor != 0 && c.PriceAnomalyFactor < 2 {
+		return fmt.Errorf("PRICE_ANOMALY_FACTOR must be 0 (disabled) or at least 2")
+	}
+	if c.AuditRetention < 0 {
+		return fmt.Errorf("AUDIT_RETENTION_DAYS must not be negative")
+	}
+	if c.MaintenanceMigrateLead < 0 {
+		return fmt.Errorf("MAINTENANCE_MIGRATE_LEAD_MINUTES must not be negative")
+	}
+	if c.JobBatchMaxItems < 1 || c.JobBatchConcurrency < 1 {
+		return fmt.Errorf("JOB_BATCH_MAX_ITEMS and JOB_BATCH_CONCURRENCY must be at least 1")
+	}
+	if c.ClockSkewAllowance < 0 {
+		return fmt.Errorf("CLOCK_SKEW_ALLOWANCE_SECONDS must not be negative")
+	}
+	if c.ImageBootBudget <= 0 {
+		return fmt.Errorf("IMAGE_BOOT_BUDGET_SECONDS must be positive")
+	}
+	if c.PostmortemMaxBytes < 1<<20 || c.PostmortemLogLines < 1 {
+		return fmt.Errorf("POSTMORTEM_MAX_MB and POSTMORTEM_LOG_LINES must be at least 1")
+	}
+	if c.PostmortemURLTTL <= 0 || c.PostmortemURLTTL > 7*24*time.Hour {
+		return fmt.Errorf("POSTMORTEM_URL_TTL_MINUTES must be between 1 minute and 7 days")
+	}
+	if c.ConsoleAttachEnabled && c.ConsoleSSHKeyFile == "" && c.SecretsMasterKey == "" {
+		return fmt.Errorf("CONSOLE_ATTACH_ENABLED needs CONSOLE_SSH_KEY_FILE or SECRETS_MASTER_KEY")
+	}
+	if c.ConsoleIdleTimeout < 0 {
+		return fmt.Errorf("CONSOLE_IDLE_TIMEOUT_MINUTES must not be negative")
+	}
+	if c.SecretsMasterKey != "" {
+		if _, err := secrets.ParseMasterKey(c.SecretsMasterKey); err != nil {
+			return fmt.Errorf("invalid SECRETS_MASTER_KEY: %w", err)
+		}
+	}
+	if c.SSHKeyRotationAge < 0 {
+		return fmt.Errorf("SSH_KEY_ROTATION_HOURS must not be negative")
+	}
+	for _, interval := range c.Intervals() {
+		if interval.Optional && interval.Value == 0 {
+			continue
+		}
+		if interval.Value < interval.Min {
+			return fmt.Errorf("%s is %s, below the minimum of %s", interval.Env, interval.Value, interval.Min)
+		}
+		if interval.Max > 0 && interval.Value > interval.Max {
+			return fmt.Errorf("%s is %s, above the maximum of %s", interval.Env, interval.Value, interval.Max)
+		}
+	}
+	return nil
+}
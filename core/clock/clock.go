@@ -0,0 +1,84 @@
+// Package clock is the source of the current time for timestamps that are
+// stored or compared across components. Every time it hands out is in UTC,
+// so values read back from the database, parsed from clients and taken from
+// the clock compare and serialize the same way.
+package clock
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Clock tells the current time in UTC
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now().UTC()
+}
+
+// System is the server's wall clock
+var System Clock = systemClock{}
+
+// Manual is a clock that only moves when told to. Inject it wherever a Clock
+// or a now func is taken to make time-based behaviour deterministic.
+type Manual struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewManual creates a manual clock stopped at now
+func NewManual(now time.Time) *Manual {
+	return &Manual{now: now.UTC()}
+}
+
+// Now returns the clock's current time
+func (m *Manual) Now() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.now
+}
+
+// Set moves the clock to now
+func (m *Manual) Set(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = now.UTC()
+}
+
+// Advance moves the clock forward by d
+func (m *Manual) Advance(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = m.now.Add(d)
+}
+
+// ErrInPast is returned by CheckNotPast for a time further in the past than
+// the allowed skew
+var ErrInPast = errors.New("time is in the past")
+
+// ParseRFC3339 parses a client-supplied RFC 3339 time, in any offset, and
+// returns it in UTC
+func ParseRFC3339(value string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.UTC(), nil
+}
+
+// CheckNotPast returns ErrInPast when t is before now by more than skew. A
+// client whose clock trails the server's by up to skew can still send a time
+// that is "now" or a moment ahead of it.
+func CheckNotPast(t, now time.Time, skew time.Duration) error {
+	if behind := now.Sub(t); behind > skew {
+		return fmt.Errorf("%w: %s is %s before the server's %s (allowed skew %s)",
+			ErrInPast, t.UTC().Format(time.RFC3339), behind.Round(time.Second), now.UTC().Format(time.RFC3339), skew)
+	}
+	return nil
+}
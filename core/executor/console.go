@@ -0,0 +1,208 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Console modes
+const (
+	ConsoleReadOnly  = "readonly"  // Terminal output only; keystrokes are recorded but not sent
+	ConsoleReadWrite = "readwrite" // Keystrokes are typed into the shell
+)
+
+// Reasons a console session ends
+const (
+	ConsoleClientClosed = "client_closed"
+	ConsoleShellClosed  = "shell_closed"
+	ConsoleIdleTimeout  = "idle_timeout"
+	ConsoleCancelled    = "cancelled" // The caller's context ended, e.g. because the job ended
+)
+
+// maxTranscriptBytes caps a console transcript; later records are dropped
+const maxTranscriptBytes = 16 << 20
+
+// readOnlyNotice is shown once when a read-only session receives input
+const readOnlyNotice = "\r\n[read-only session: input is not sent; attach with mode=readwrite to type]\r\n"
+
+// ConsoleConn is the client side of a console, one message per call.
+// WriteMessage must be safe to call from several goroutines.
+type ConsoleConn interface {
+	ReadMessage() ([]byte, error)
+	WriteMessage(data []byte) error
+}
+
+// ConsoleOptions controls a console session
+type ConsoleOptions struct {
+	Mode        string        // ConsoleReadOnly or ConsoleReadWrite
+	IdleTimeout time.Duration // Ends the session after this long without input or output; 0 = never
+}
+
+// ConsoleResult summarizes an ended console session
+type ConsoleResult struct {
+	Reason        string
+	InputBytes    int // Keystrokes typed into the shell
+	RejectedBytes int // Keystrokes a read-only session did not send
+	OutputBytes   int
+}
+
+// ConsoleTranscript records a console session as JSON lines of
+// {"at", "dir", "data"}, dir being in, out or rejected (input a read-only
+// session did not send)
+type ConsoleTranscript struct {
+	mu        sync.Mutex
+	buf       bytes.Buffer
+	truncated bool
+	now       func() time.Time
+}
+
+// NewConsoleTranscript creates an empty transcript
+func NewConsoleTranscript() *ConsoleTranscript {
+	return &ConsoleTranscript{now: time.Now}
+}
+
+// record appends one record unless the transcript is full
+func (t *ConsoleTranscript) record(dir string, data []byte) {
+	line, err := json.Marshal(map[string]interface{}{
+		"at":   t.now().UTC().Format(time.RFC3339Nano),
+		"dir":  dir,
+		"data": string(data),
+	})
+	if err != nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.buf.Len()+len(line)+1 > maxTranscriptBytes {
+		t.truncated = true
+		return
+	}
+	t.buf.Write(line)
+	t.buf.WriteByte('\n')
+}
+
+// Bytes returns the transcript so far and whether records were dropped
+func (t *ConsoleTranscript) Bytes() ([]byte, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]byte(nil), t.buf.Bytes()...), t.truncated
+}
+
+// consoleCounters are the byte counts of a running session
+type consoleCounters struct {
+	mu     sync.Mutex
+	result ConsoleResult
+}
+
+func (c *consoleCounters) add(field *int, n int) {
+	c.mu.Lock()
+	*field += n
+	c.mu.Unlock()
+}
+
+// RunConsole bridges conn and shell until either side closes, nothing
+// passes for IdleTimeout or ctx is done. In read-only mode input is recorded
+// and dropped. It closes the shell before returning; the caller closes conn,
+// which ends the goroutine reading it.
+func RunConsole(ctx context.Context, conn ConsoleConn, shell ShellSession, transcript *ConsoleTranscript, opts ConsoleOptions) ConsoleResult {
+	defer shell.Close()
+
+	counters := &consoleCounters{}
+	activity := make(chan struct{}, 1)
+	ended := make(chan string, 2)
+	touch := func() {
+		select {
+		case activity <- struct{}{}:
+		default:
+		}
+	}
+
+	// Shell output to the client
+	go func() {
+		buf := make([]byte, 32<<10)
+		for {
+			n, err := shell.Read(buf)
+			if n > 0 {
+				touch()
+				transcript.record("out", buf[:n])
+				counters.add(&counters.result.OutputBytes, n)
+				if werr := conn.WriteMessage(append([]byte(nil), buf[:n]...)); werr != nil {
+					ended <- ConsoleClientClosed
+					return
+				}
+			}
+			if err != nil {
+				ended <- ConsoleShellClosed
+				return
+			}
+		}
+	}()
+
+	// Client keystrokes to the shell
+	go func() {
+		notified := false
+		for {
+			data, err := conn.ReadMessage()
+			if err != nil {
+				ended <- ConsoleClientClosed
+				return
+			}
+			touch()
+			if opts.Mode != ConsoleReadWrite {
+				transcript.record("rejected", data)
+				counters.add(&counters.result.RejectedBytes, len(data))
+				if !notified {
+					conn.WriteMessage([]byte(readOnlyNotice))
+					notified = true
+				}
+				continue
+			}
+			transcript.record("in", data)
+			counters.add(&counters.result.InputBytes, len(data))
+			if _, err := shell.Write(data); err != nil {
+				ended <- ConsoleShellClosed
+				return
+			}
+		}
+	}()
+
+	var idle <-chan time.Time
+	var timer *time.Timer
+	if opts.IdleTimeout > 0 {
+		timer = time.NewTimer(opts.IdleTimeout)
+		defer timer.Stop()
+		idle = timer.C
+	}
+
+	reason := ""
+	for reason == "" {
+		select {
+		case <-ctx.Done():
+			reason = ConsoleCancelled
+		case reason = <-ended:
+		case <-idle:
+			reason = ConsoleIdleTimeout
+		case <-activity:
+			if timer != nil {
+				if !timer.Stop() {
+					<-timer.C
+				}
+				timer.Reset(opts.IdleTimeout)
+			}
+		}
+	}
+	if reason != ConsoleClientClosed {
+		conn.WriteMessage([]byte(fmt.Sprintf("\r\n[console closed: %s]\r\n", reason)))
+	}
+
+	counters.mu.Lock()
+	defer counters.mu.Unlock()
+	result := counters.result
+	result.Reason = reason
+	return result
+}
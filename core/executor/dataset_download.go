@@ -0,0 +1,287 @@
+package executor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gpu-orchestrator/core/models"
+)
+
+const (
+	// localDatasetRoot is where nodes keep their own dataset copies
+	localDatasetRoot = "/data/datasets"
+
+	// defaultDownloadConcurrency applies to instances without a known bandwidth
+	defaultDownloadConcurrency = 16
+
+	// datasetProgressSeconds is how often nodes report downloaded bytes
+	datasetProgressSeconds = 60
+
+	// Output lines of the download script
+	datasetProgressLine = "DATASET_PROGRESS"
+	datasetDoneLine     = "DATASET_DONE"
+)
+
+// ErrDatasetDownload is returned when a node fails to download the dataset
+var ErrDatasetDownload = errors.New("dataset download failed")
+
+// safeURIPattern matches dataset URIs safe to quote in node scripts
+var safeURIPattern = regexp.MustCompile(`^[A-Za-z0-9._~:/@%+=,-]+$`)
+
+// NodeRunner runs a shell script on a node, passing every line it prints to
+// output. It returns when the script exits; a non-zero exit is an error.
+type NodeRunner interface {
+	Run(ctx context.Context, node models.Node, script string, output func(line string)) error
+}
+
+// InstanceCatalog lists the instance types of every provider
+type InstanceCatalog interface {
+	GetAllInstances(ctx context.Context) (map[models.Provider][]models.GPUInstance, error)
+}
+
+// SetNodeRunner sets how scripts run on cluster nodes. Without a runner the
+// dataset download is only logged, like the training launch.
+func (e *TrainingExecutor) SetNodeRunner(runner NodeRunner) {
+	e.runner = runner
+}
+
+// SetInstanceCatalog sets where instance network bandwidth is looked up to
+// size download parallelism
+func (e *TrainingExecutor) SetInstanceCatalog(catalog InstanceCatalog) {
+	e.catalog = catalog
+}
+
+// nodeDownload is what a node reported while downloading
+type nodeDownload struct {
+	done  bool
+	files int64
+	bytes int64
+}
+
+// downloadDataset stages the job's dataset on the cluster before training and
+// returns the directory it was downloaded to ("" when the job does not stage
+// its dataset). With a shared mount only the first node downloads. Every node
+// must report exactly the objects and bytes under the dataset prefix, or an
+// error wrapping ErrDatasetDownload names the first node that failed.
+func (e *TrainingExecutor) downloadDataset(ctx context.Context, job *models.Job, cluster *models.Cluster) (string, error) {
+	if job.DatasetDownload == nil || job.DatasetURI == "" || len(cluster.Nodes) == 0 {
+		return "", nil
+	}
+	if !safeURIPattern.MatchString(job.DatasetURI) {
+		return "", fmt.Errorf("%w: dataset URI %q contains unsupported characters", ErrDatasetDownload, job.DatasetURI)
+	}
+
+	prefix := strings.TrimSuffix(job.DatasetURI, "/") + "/"
+	sum := sha256.Sum256([]byte(prefix))
+	root := localDatasetRoot
+	nodes := cluster.Nodes
+	if job.DatasetDownload.SharedMount != "" {
+		root = job.DatasetDownload.SharedMount + "/datasets"
+		nodes = nodes[:1]
+	}
+	dest := root + "/" + hex.EncodeToString(sum[:6])
+
+	// Expected totals; without object storage only completion is checked
+	expectedFiles, expectedBytes := int64(-1), int64(-1)
+	if e.stores != nil {
+		objects, err := e.stores.List(ctx, prefix)
+		if err != nil {
+			return "", fmt.Errorf("%w: failed to list %s: %v", ErrDatasetDownload, prefix, err)
+		}
+		expectedFiles, expectedBytes = 0, 0
+		for _, obj := range objects {
+			if strings.HasSuffix(obj.Key, "/") {
+				continue // Directory marker; not a file on disk
+			}
+			expectedFiles++
+			expectedBytes += obj.Size
+		}
+	}
+
+	e.recordDownloadEvent(job.ID, "dataset_download_started", map[string]interface{}{
+		"dataset":        job.DatasetURI,
+		"dest":           dest,
+		"nodes":          len(nodes),
+		"shared_mount":   job.DatasetDownload.SharedMount,
+		"expected_files": expectedFiles,
+		"expected_bytes": expectedBytes,
+	})
+
+	scripts := make([]string, len(nodes))
+	concurrency := make(map[string]int) // Instance type -> parallel transfers
+	for i, node := range nodes {
+		if _, ok := concurrency[node.InstanceType]; !ok {
+			concurrency[node.InstanceType] = e.downloadConcurrency(ctx, node)
+		}
+		syncCommand, err := e.syncCommand(prefix, dest, concurrency[node.InstanceType])
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrDatasetDownload, err)
+		}
+		scripts[i] = datasetDownloadScript(dest, syncCommand)
+	}
+
+	if e.runner == nil {
+		// TODO: Implement SSH execution
+		log.Printf("Dataset download script for job %s:\n%s", job.ID, scripts[0])
+		return dest, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]nodeDownload, len(nodes))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	failed, failure := -1, error(nil)
+	for i := range nodes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			node := nodes[i]
+			err := e.runner.Run(ctx, node, scripts[i], func(line string) {
+				e.parseDownloadOutput(job.ID, node.ID, line, expectedBytes, &results[i])
+			})
+			if err == nil {
+				err = verifyDownload(results[i], expectedFiles, expectedBytes)
+			}
+			if err == nil {
+				return
+			}
+			mu.Lock()
+			if failed < 0 {
+				failed, failure = i, err
+				cancel() // No point finishing the other nodes
+			}
+			mu.Unlock()
+		}(i)
+	}
+	wg.Wait()
+
+	if failed >= 0 {
+		return "", fmt.Errorf("%w on node %s: %v", ErrDatasetDownload, nodes[failed].ID, failure)
+	}
+
+	e.recordDownloadEvent(job.ID, "dataset_downloaded", map[string]interface{}{
+		"dest":  dest,
+		"nodes": len(nodes),
+		"files": results[0].files,
+		"bytes": results[0].bytes,
+	})
+	return dest, nil
+}
+
+// syncCommand returns the download snippet for the dataset prefix. Without
+// object storage configured the dataset is assumed to live on AWS S3.
+func (e *TrainingExecutor) syncCommand(prefix, dest string, concurrency int) (string, error) {
+	if e.stores == nil {
+		return fmt.Sprintf("aws configure set default.s3.max_concurrent_requests %d\naws s3 sync --only-show-errors --delete %s '%s'", concurrency, prefix, dest), nil
+	}
+	return e.stores.SyncCommand(prefix, dest, concurrency)
+}
+
+// downloadConcurrency sizes parallel transfers to the node's network
+// bandwidth from the instance catalog: about one per 250 Mbps
+func (e *TrainingExecutor) downloadConcurrency(ctx context.Context, node models.Node) int {
+	if e.catalog == nil {
+		return defaultDownloadConcurrency
+	}
+	instances, err := e.catalog.GetAllInstances(ctx)
+	if err != nil {
+		log.Printf("Failed to look up bandwidth of %s: %v", node.InstanceType, err)
+		return defaultDownloadConcurrency
+	}
+	for _, instance := range instances[node.Provider] {
+		if instance.InstanceType != node.InstanceType || instance.NetworkGbps <= 0 {
+			continue
+		}
+		concurrency := int(instance.NetworkGbps * 4)
+		if concurrency < 8 {
+			concurrency = 8
+		}
+		if concurrency > 256 {
+			concurrency = 256
+		}
+		return concurrency
+	}
+	return defaultDownloadConcurrency
+}
+
+// parseDownloadOutput records progress lines as events and keeps the
+// completion totals a node reports
+func (e *TrainingExecutor) parseDownloadOutput(jobID, nodeID, line string, expectedBytes int64, result *nodeDownload) {
+	fields := strings.Fields(line)
+	switch {
+	case len(fields) == 2 && fields[0] == datasetProgressLine:
+		downloaded, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return
+		}
+		meta := map[string]interface{}{"node": nodeID, "bytes_downloaded": downloaded}
+		if expectedBytes > 0 {
+			meta["bytes_total"] = expectedBytes
+			meta["percent"] = float64(downloaded) / float64(expectedBytes) * 100
+		}
+		e.recordDownloadEvent(jobID, "dataset_download_progress", meta)
+	case len(fields) == 3 && fields[0] == datasetDoneLine:
+		files, filesErr := strconv.ParseInt(fields[1], 10, 64)
+		bytes, bytesErr := strconv.ParseInt(fields[2], 10, 64)
+		if filesErr == nil && bytesErr == nil {
+			*result = nodeDownload{done: true, files: files, bytes: bytes}
+		}
+	}
+}
+
+// recordDownloadEvent notes a download step on the job timeline
+func (e *TrainingExecutor) recordDownloadEvent(jobID, reason string, meta map[string]interface{}) {
+	running := models.JobStatusRunning
+	if err := e.jobRepo.CreateJobEvent(jobID, &running, running, reason, meta); err != nil {
+		log.Printf("Failed to record %s event for job %s: %v", reason, jobID, err)
+	}
+}
+
+// verifyDownload compares what a node reported with the dataset listing.
+// Negative expectations only require the download to have completed.
+func verifyDownload(result nodeDownload, expectedFiles, expectedBytes int64) error {
+	if !result.done {
+		return fmt.Errorf("download did not report completion")
+	}
+	if expectedFiles >= 0 && (result.files != expectedFiles || result.bytes != expectedBytes) {
+		return fmt.Errorf("downloaded %d of %d objects (%d of %d bytes)", result.files, expectedFiles, result.bytes, expectedBytes)
+	}
+	return nil
+}
+
+// datasetDownloadScript wraps a sync snippet with progress reporting and the
+// completion totals verified by the executor
+func datasetDownloadScript(dest, syncCommand string) string {
+	return fmt.Sprintf(`#!/bin/bash
+set -euo pipefail
+DEST='%s'
+mkdir -p "$DEST"
+dataset_bytes() { find "$DEST" -type f -printf '%%s\n' | awk '{s+=$1} END {printf "%%.0f", s}'; }
+( while sleep %d; do echo "%s $(dataset_bytes)"; done ) &
+PROGRESS_PID=$!
+trap 'kill $PROGRESS_PID 2>/dev/null || true' EXIT
+
+%s
+
+echo "%s $(find "$DEST" -type f | wc -l) $(dataset_bytes)"
+`, dest, datasetProgressSeconds, datasetProgressLine, syncCommand, datasetDoneLine)
+}
+
+// exportDatasetDir makes the downloaded dataset's directory available to the
+// training script as DATASET_DIR
+func exportDatasetDir(script, dir string) string {
+	if dir == "" {
+		return script
+	}
+	return strings.Replace(script, "#!/bin/bash\n", fmt.Sprintf("#!/bin/bash\nexport DATASET_DIR='%s'\n", dir), 1)
+}
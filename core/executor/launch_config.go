@@ -0,0 +1,152 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/storage"
+	"gpu-orchestrator/training/bootstrap"
+	"gpu-orchestrator/training/frameworks"
+)
+
+// redactedValue replaces secret values in recorded environments
+const redactedValue = "<redacted>"
+
+// secretEnvName matches variable names whose values are treated as secrets
+var secretEnvName = regexp.MustCompile(`(?i)(secret|token|passw(or)?d|api_?key|credential|private_key)`)
+
+// ArtifactRecorder stores artifact records of a job
+type ArtifactRecorder interface {
+	CreateArtifact(jobID string, artifactType models.ArtifactType, uri string, meta map[string]interface{}) error
+}
+
+// SetLaunchConfigStore records the generated script and environment of every
+// launch as a launch_config artifact. Scripts are uploaded content-addressed
+// under uri (e.g. s3://bucket/launch-configs); with an empty uri they are
+// stored inline in the artifact meta.
+func (e *TrainingExecutor) SetLaunchConfigStore(records ArtifactRecorder, uri string) {
+	e.launchRecords = records
+	e.launchConfigURI = strings.TrimSuffix(uri, "/")
+}
+
+// recordLaunchConfig stores the script run on the cluster's nodes and their
+// resolved environments, and notes it on the job timeline. Every node runs
+// the same script, so it is stored once with the nodes that run it.
+func (e *TrainingExecutor) recordLaunchConfig(
+	ctx context.Context,
+	job *models.Job,
+	config *frameworks.DistributedConfig,
+	script string,
+	meta map[string]interface{},
+) {
+	if e.launchRecords == nil {
+		return
+	}
+
+	sum := sha256.Sum256([]byte(script))
+	digest := hex.EncodeToString(sum[:])
+
+	secretNames := make(map[string]bool)
+	if job.Bootstrap != nil {
+		for name := range job.Bootstrap.EnvFromSecrets {
+			secretNames[name] = true
+		}
+	}
+	nodes := make([]map[string]interface{}, len(config.Nodes))
+	for i, node := range config.Nodes {
+		nodes[i] = map[string]interface{}{
+			"rank":    node.Rank,
+			"address": node.Address,
+			"env":     redactEnv(node.Environment, secretNames),
+		}
+	}
+
+	if meta == nil {
+		meta = make(map[string]interface{})
+	}
+	meta["sha256"] = digest
+	meta["size_bytes"] = len(script)
+	meta["framework"] = job.Framework
+	meta["network_profile"] = config.NetworkProfile
+	meta["nodes"] = nodes
+	if len(secretNames) > 0 {
+		meta["secret_env"] = sortedKeys(secretNames)
+	}
+
+	uri, err := e.storeScript(ctx, digest, script)
+	if err != nil {
+		log.Printf("Failed to upload launch script for job %s, storing it inline: %v", job.ID, err)
+	}
+	if uri == "" {
+		uri = "inline:launch_config/sha256:" + digest
+		meta["script"] = script
+	}
+
+	if err := e.launchRecords.CreateArtifact(job.ID, models.ArtifactTypeLaunchConfig, uri, meta); err != nil {
+		log.Printf("Failed to record launch config for job %s: %v", job.ID, err)
+		return
+	}
+
+	running := models.JobStatusRunning
+	if err := e.jobRepo.CreateJobEvent(job.ID, &running, running, "launch_config_recorded", map[string]interface{}{
+		"uri":    uri,
+		"sha256": digest,
+		"nodes":  len(config.Nodes),
+	}); err != nil {
+		log.Printf("Failed to record launch_config_recorded event for job %s: %v", job.ID, err)
+	}
+}
+
+// storeScript uploads script under its digest unless an identical script is
+// already stored, and returns its URI. Returns "" when no store is configured.
+func (e *TrainingExecutor) storeScript(ctx context.Context, digest, script string) (string, error) {
+	if e.launchConfigURI == "" || e.stores == nil {
+		return "", nil
+	}
+	uri := fmt.Sprintf("%s/%s.sh", e.launchConfigURI, digest)
+
+	_, err := e.stores.Stat(ctx, uri)
+	if err == nil {
+		return uri, nil
+	}
+	if !errors.Is(err, storage.ErrObjectNotFound) {
+		return "", err
+	}
+	if err := e.stores.Put(ctx, uri, bytes.NewReader([]byte(script)), int64(len(script)), "text/x-shellscript"); err != nil {
+		return "", err
+	}
+	return uri, nil
+}
+
+// redactEnv returns env with secret values replaced, keeping every name.
+// Values are secret when the name comes from bootstrap.env_from_secrets,
+// the name looks like a credential or the value contains one.
+func redactEnv(env map[string]string, secretNames map[string]bool) map[string]string {
+	redacted := make(map[string]string, len(env))
+	for name, value := range env {
+		if secretNames[name] || secretEnvName.MatchString(name) || bootstrap.ContainsSecret(value) {
+			value = redactedValue
+		}
+		redacted[name] = value
+	}
+	return redacted
+}
+
+// sortedKeys returns the keys of set in order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
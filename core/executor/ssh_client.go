@@ -75,3 +75,18 @@ func (sc *SSHClient) TestConnection(ctx context.Context, host string) error {
 	// TODO: Implement when golang.org/x/crypto/ssh is added
 	return fmt.Errorf("SSH test requires golang.org/x/crypto/ssh package")
 }
+
+// ShellSession is an interactive shell on a remote node: reads return the
+// terminal's output, writes are typed into it
+type ShellSession interface {
+	io.ReadWriteCloser
+}
+
+// OpenShell starts an interactive login shell on a remote node
+func (sc *SSHClient) OpenShell(ctx context.Context, host string) (ShellSession, error) {
+	// Phase 4: Request a PTY and start a shell
+	// TODO: Implement when golang.org/x/crypto/ssh is added:
+	// session.RequestPty("xterm", 40, 120, ssh.TerminalModes{}), then
+	// StdinPipe/StdoutPipe (stderr merged) and session.Shell()
+	return nil, fmt.Errorf("SSH shells require golang.org/x/crypto/ssh package")
+}
@@ -0,0 +1,349 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/secrets"
+)
+
+// SSHKeyStore stores per-cluster SSH keys; see repository.SSHKeyRepository
+type SSHKeyStore interface {
+	CreateKey(key *models.ClusterSSHKey) error
+	BindKey(id int64, clusterID string) error
+	PromoteKey(id, previousID int64, clusterID string) error
+	DiscardKey(id int64) error
+	DestroyClusterKeys(clusterID string) ([]models.ClusterSSHKey, error)
+	ActiveKey(clusterID string) (*models.ClusterSSHKey, error)
+	ClusterKeys(clusterID string) ([]models.ClusterSSHKey, error)
+}
+
+// AuditRecorder records audit entries; see repository.AuditRepository
+type AuditRecorder interface {
+	Record(entry *models.AuditEntry) error
+}
+
+// ClusterKeys issues an SSH keypair per cluster, connects to nodes with it
+// and rotates it. Private keys are stored sealed under the secrets keyring
+// and only opened for the connection that uses them. Every issuance, use,
+// rotation and destruction is audited.
+type ClusterKeys struct {
+	store     SSHKeyStore
+	keyring   *secrets.Keyring // nil issues no keys; every connection uses the fallback
+	user      string           // Node user the keys log in as
+	audit     AuditRecorder    // Optional; nil logs nothing to the audit log
+	fallback  *SSHClient       // Connects to clusters without a key of their own; nil refuses them
+	newClient func(privateKey []byte, user string) (*SSHClient, error)
+	rotating  sync.Mutex // One rotation at a time
+}
+
+// NewClusterKeys creates the per-cluster key manager
+func NewClusterKeys(store SSHKeyStore, keyring *secrets.Keyring, user string) *ClusterKeys {
+	return &ClusterKeys{
+		store:     store,
+		keyring:   keyring,
+		user:      user,
+		newClient: NewSSHClient,
+	}
+}
+
+// SetAuditRecorder sets where key issuance, use and rotation are audited
+func (k *ClusterKeys) SetAuditRecorder(audit AuditRecorder) {
+	k.audit = audit
+}
+
+// SetFallback sets the client used for clusters launched without a key of
+// their own, e.g. before per-cluster keys were enabled
+func (k *ClusterKeys) SetFallback(client *SSHClient) {
+	k.fallback = client
+}
+
+// Issue generates the key of a cluster about to launch and stores it sealed
+// and pending
+func (k *ClusterKeys) Issue(jobID, clusterID string) (*models.ClusterSSHKey, error) {
+	if k.keyring == nil {
+		return nil, fmt.Errorf("per-cluster SSH keys need a secrets master key")
+	}
+	pair, err := secrets.GenerateSSHKey(clusterID)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := k.keyring.Seal(pair.PrivateKeyPEM, []byte(pair.Fingerprint))
+	if err != nil {
+		return nil, fmt.Errorf("failed to seal SSH key: %w", err)
+	}
+
+	key := &models.ClusterSSHKey{
+		JobID:       jobID,
+		ClusterID:   clusterID,
+		Fingerprint: pair.Fingerprint,
+		PublicKey:   pair.AuthorizedKey,
+		SealedKey:   sealed,
+	}
+	if err := k.store.CreateKey(key); err != nil {
+		return nil, fmt.Errorf("failed to store SSH key: %w", err)
+	}
+	k.record(models.ActorSystem, models.AuditSSHKeyIssued, key, nil)
+	return key, nil
+}
+
+// Activate makes an issued key its cluster's active key
+func (k *ClusterKeys) Activate(key *models.ClusterSSHKey) error {
+	return k.store.BindKey(key.ID, key.ClusterID)
+}
+
+// Active returns the cluster's active key, nil when it has none
+func (k *ClusterKeys) Active(clusterID string) (*models.ClusterSSHKey, error) {
+	return k.store.ActiveKey(clusterID)
+}
+
+// Keys returns every key a cluster has had, newest first
+func (k *ClusterKeys) Keys(clusterID string) ([]models.ClusterSSHKey, error) {
+	return k.store.ClusterKeys(clusterID)
+}
+
+// Discard destroys a key whose cluster never came up
+func (k *ClusterKeys) Discard(key *models.ClusterSSHKey) error {
+	if err := k.store.DiscardKey(key.ID); err != nil {
+		return err
+	}
+	k.record(models.ActorSystem, models.AuditSSHKeyDestroyed, key, map[string]interface{}{"reason": "launch_failed"})
+	return nil
+}
+
+// DestroyCluster destroys every key of a terminated cluster
+func (k *ClusterKeys) DestroyCluster(clusterID string) error {
+	keys, err := k.store.DestroyClusterKeys(clusterID)
+	if err != nil {
+		return err
+	}
+	for i := range keys {
+		k.record(models.ActorSystem, models.AuditSSHKeyDestroyed, &keys[i], map[string]interface{}{"reason": "cluster_terminated"})
+	}
+	return nil
+}
+
+// Run runs a script on a node with its cluster's key, passing every line it
+// prints to output. Implements NodeRunner.
+func (k *ClusterKeys) Run(ctx context.Context, node models.Node, script string, output func(line string)) error {
+	client, err := k.connect(node.ClusterID, node.PrivateIP, "run")
+	if err != nil {
+		return err
+	}
+	lines := &lineWriter{output: output}
+	err = client.ExecuteCommandStream(ctx, node.PrivateIP, script, lines)
+	lines.flush()
+	return err
+}
+
+// OpenShell opens an interactive shell on a node of a cluster with the
+// cluster's key
+func (k *ClusterKeys) OpenShell(ctx context.Context, clusterID, host string) (ShellSession, error) {
+	client, err := k.connect(clusterID, host, "shell")
+	if err != nil {
+		return nil, err
+	}
+	return client.OpenShell(ctx, host)
+}
+
+// connect returns a client holding the cluster's opened private key and
+// audits its use for purpose on host
+func (k *ClusterKeys) connect(clusterID, host, purpose string) (*SSHClient, error) {
+	key, err := k.usableKey(clusterID)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		if k.fallback == nil {
+			return nil, fmt.Errorf("cluster %q has no SSH key", clusterID)
+		}
+		return k.fallback, nil
+	}
+
+	client, err := k.client(key)
+	if err != nil {
+		return nil, err
+	}
+	k.record(models.ActorSystem, models.AuditSSHKeyUsed, key, map[string]interface{}{
+		"host":    host,
+		"purpose": purpose,
+	})
+	return client, nil
+}
+
+// usableKey returns the cluster's active key, nil when there is none or keys
+// are disabled
+func (k *ClusterKeys) usableKey(clusterID string) (*models.ClusterSSHKey, error) {
+	if k.keyring == nil || clusterID == "" {
+		return nil, nil
+	}
+	key, err := k.store.ActiveKey(clusterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SSH key of cluster %s: %w", clusterID, err)
+	}
+	return key, nil
+}
+
+// client opens a key's sealed private half into an SSH client
+func (k *ClusterKeys) client(key *models.ClusterSSHKey) (*SSHClient, error) {
+	privateKey, err := k.keyring.Open(key.SealedKey, []byte(key.Fingerprint))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SSH key %s: %w", key.Fingerprint, err)
+	}
+	client, err := k.newClient(privateKey, k.user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SSH client: %w", err)
+	}
+	return client, nil
+}
+
+// Rotate replaces a running cluster's key without interrupting it: the new
+// key is authorized next to the old one on every node and tested before it
+// becomes active, and only then is the old key removed from the nodes and
+// retired. A rotation that fails before the switch leaves the old key active.
+func (k *ClusterKeys) Rotate(ctx context.Context, cluster *models.Cluster, jobID, actor string) (*models.ClusterSSHKey, error) {
+	k.rotating.Lock()
+	defer k.rotating.Unlock()
+
+	old, err := k.usableKey(cluster.ID)
+	if err != nil {
+		return nil, err
+	}
+	if old == nil {
+		return nil, fmt.Errorf("cluster %s has no SSH key of its own to rotate", cluster.ID)
+	}
+	oldClient, err := k.client(old)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := k.Issue(jobID, cluster.ID)
+	if err != nil {
+		return nil, err
+	}
+	newClient, err := k.client(key)
+	if err != nil {
+		k.discardRotation(ctx, key, oldClient, nil)
+		return nil, err
+	}
+
+	// Authorize the new key with the old one, then prove it works
+	var authorized []string
+	for _, node := range cluster.Nodes {
+		if _, err := oldClient.ExecuteCommand(ctx, node.PrivateIP, addAuthorizedKeyCommand(key.PublicKey)); err != nil {
+			k.discardRotation(ctx, key, oldClient, authorized)
+			return nil, fmt.Errorf("failed to authorize new SSH key on node %s: %w", node.ID, err)
+		}
+		authorized = append(authorized, node.PrivateIP)
+		k.record(actor, models.AuditSSHKeyUsed, old, map[string]interface{}{"host": node.PrivateIP, "purpose": "rotate"})
+	}
+	for _, node := range cluster.Nodes {
+		if err := newClient.TestConnection(ctx, node.PrivateIP); err != nil {
+			k.discardRotation(ctx, key, oldClient, authorized)
+			return nil, fmt.Errorf("new SSH key rejected by node %s: %w", node.ID, err)
+		}
+	}
+
+	if err := k.store.PromoteKey(key.ID, old.ID, cluster.ID); err != nil {
+		k.discardRotation(ctx, key, oldClient, authorized)
+		return nil, fmt.Errorf("failed to activate new SSH key: %w", err)
+	}
+	k.record(actor, models.AuditSSHKeyRotated, key, map[string]interface{}{"previous_fingerprint": old.Fingerprint})
+	if active, err := k.store.ActiveKey(cluster.ID); err == nil && active != nil {
+		key = active
+	}
+
+	// The old key is retired; nodes that miss the cleanup still reject it
+	// once the cluster terminates
+	for _, node := range cluster.Nodes {
+		if _, err := newClient.ExecuteCommand(ctx, node.PrivateIP, removeAuthorizedKeyCommand(old.PublicKey)); err != nil {
+			log.Printf("Failed to remove retired SSH key %s from node %s: %v", old.Fingerprint, node.ID, err)
+		}
+	}
+	return key, nil
+}
+
+// discardRotation destroys a key that failed to become active and removes it
+// from the hosts it was authorized on
+func (k *ClusterKeys) discardRotation(ctx context.Context, key *models.ClusterSSHKey, oldClient *SSHClient, hosts []string) {
+	for _, host := range hosts {
+		if _, err := oldClient.ExecuteCommand(ctx, host, removeAuthorizedKeyCommand(key.PublicKey)); err != nil {
+			log.Printf("Failed to remove discarded SSH key %s from %s: %v", key.Fingerprint, host, err)
+		}
+	}
+	if err := k.store.DiscardKey(key.ID); err != nil {
+		log.Printf("Failed to discard SSH key %s: %v", key.Fingerprint, err)
+		return
+	}
+	k.record(models.ActorSystem, models.AuditSSHKeyDestroyed, key, map[string]interface{}{"reason": "rotation_failed"})
+}
+
+// record writes an audit entry about a key
+func (k *ClusterKeys) record(actor, action string, key *models.ClusterSSHKey, details map[string]interface{}) {
+	if k.audit == nil {
+		return
+	}
+	request := map[string]interface{}{
+		"fingerprint": key.Fingerprint,
+		"key_id":      key.ID,
+	}
+	if key.JobID != "" {
+		request["job_id"] = key.JobID
+	}
+	for name, value := range details {
+		request[name] = value
+	}
+	err := k.audit.Record(&models.AuditEntry{
+		Actor:        actor,
+		Action:       action,
+		ResourceType: "cluster",
+		ResourceID:   key.ClusterID,
+		Request:      request,
+	})
+	if err != nil {
+		log.Printf("Failed to audit %s of SSH key %s: %v", action, key.Fingerprint, err)
+	}
+}
+
+// addAuthorizedKeyCommand appends an authorized_keys line unless present
+func addAuthorizedKeyCommand(line string) string {
+	return fmt.Sprintf(`f="$HOME/.ssh/authorized_keys"; grep -qxF '%[1]s' "$f" || echo '%[1]s' >> "$f"`, line)
+}
+
+// removeAuthorizedKeyCommand drops every authorized_keys line of a key,
+// whatever its comment
+func removeAuthorizedKeyCommand(line string) string {
+	return fmt.Sprintf(`f="$HOME/.ssh/authorized_keys"; { grep -vF '%s' "$f" || true; } > "$f.tmp" && chmod 600 "$f.tmp" && mv "$f.tmp" "$f"`,
+		secrets.AuthorizedKeyBody(line))
+}
+
+// lineWriter passes every complete line written to it to output
+type lineWriter struct {
+	output  func(line string)
+	pending bytes.Buffer
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.pending.Write(p)
+	for {
+		i := bytes.IndexByte(w.pending.Bytes(), '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		line := string(w.pending.Next(i + 1))
+		w.output(strings.TrimRight(line, "\r\n"))
+	}
+}
+
+// flush passes a last line not ended by a newline
+func (w *lineWriter) flush() {
+	if w.pending.Len() > 0 {
+		w.output(w.pending.String())
+		w.pending.Reset()
+	}
+}
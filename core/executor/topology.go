@@ -0,0 +1,115 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/training/frameworks"
+)
+
+// Where nodes of jobs with execution.export_topology find their topology manifest
+const (
+	topologyDir  = "/var/lib/gpu-orchestrator"
+	topologyFile = topologyDir + "/topology.json"
+)
+
+// recordTopology stores the topology of a job's cluster, with the ranks and
+// master endpoint of its framework setup, in the job's manifest. task is set
+// for multi_task jobs. Returns the updated manifest, or nil if it could not
+// be stored.
+func (e *TrainingExecutor) recordTopology(
+	job *models.Job,
+	cluster *models.Cluster,
+	config *frameworks.DistributedConfig,
+	task *int,
+) *models.TopologyManifest {
+	tc := models.NewTopologyCluster(cluster)
+	tc.Task = task
+	if config != nil {
+		ranks := make(map[string]int, len(config.Nodes))
+		ports := make(map[string]int, len(config.Nodes))
+		for _, node := range config.Nodes {
+			ranks[node.Address] = node.Rank
+			ports[node.Address] = node.Port
+		}
+		tc.SetRanks(ranks)
+		for i := range tc.Nodes {
+			tc.Nodes[i].Port = ports[tc.Nodes[i].PrivateIP]
+		}
+		tc.MasterAddr = config.MasterAddr
+		tc.MasterPort = config.MasterPort
+		tc.WorldSize = config.WorldSize
+		tc.NetworkProfile = config.NetworkProfile
+	}
+
+	// Tasks of one job launch concurrently
+	e.topologyMu.Lock()
+	defer e.topologyMu.Unlock()
+
+	manifest, err := e.jobRepo.GetTopology(job.ID)
+	if err != nil {
+		log.Printf("Failed to read topology of job %s: %v", job.ID, err)
+		return nil
+	}
+	if manifest == nil || task == nil {
+		// A single cluster launch (or relaunch) replaces the whole manifest
+		revision := 0
+		if manifest != nil {
+			revision = manifest.Revision
+		}
+		manifest = &models.TopologyManifest{JobID: job.ID, Framework: job.Framework, Revision: revision}
+	}
+	manifest.Upsert(tc)
+
+	if err := e.jobRepo.SetTopology(job.ID, manifest); err != nil {
+		log.Printf("Failed to store topology of job %s: %v", job.ID, err)
+		return nil
+	}
+	return manifest
+}
+
+// exportTopology records the manifest as a topology artifact and makes the
+// training script write it to topologyFile. GPU_TOPOLOGY_URL points at the
+// live manifest, which follows elastic resizes, when the orchestrator URL is known.
+func (e *TrainingExecutor) exportTopology(job *models.Job, manifest *models.TopologyManifest, script string) string {
+	if !job.ExportTopology || manifest == nil {
+		return script
+	}
+
+	encoded, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		log.Printf("Failed to encode topology of job %s: %v", job.ID, err)
+		return script
+	}
+
+	url := ""
+	if e.apiBaseURL != "" {
+		url = fmt.Sprintf("%s/v1/jobs/%s/topology", e.apiBaseURL, job.ID)
+	}
+	if e.launchRecords != nil {
+		uri := url
+		if uri == "" {
+			uri = fmt.Sprintf("inline:topology/revision:%d", manifest.Revision)
+		}
+		if err := e.launchRecords.CreateArtifact(job.ID, models.ArtifactTypeTopology, uri, map[string]interface{}{
+			"version":  manifest.Version,
+			"revision": manifest.Revision,
+			"manifest": manifest,
+		}); err != nil {
+			log.Printf("Failed to record topology artifact for job %s: %v", job.ID, err)
+		}
+	}
+
+	var header strings.Builder
+	header.WriteString("#!/bin/bash\n")
+	fmt.Fprintf(&header, "mkdir -p %s\n", topologyDir)
+	fmt.Fprintf(&header, "cat > %s <<'GPU_TOPOLOGY_EOF'\n%s\nGPU_TOPOLOGY_EOF\n", topologyFile, encoded)
+	fmt.Fprintf(&header, "export GPU_TOPOLOGY_FILE='%s'\n", topologyFile)
+	if url != "" {
+		fmt.Fprintf(&header, "export GPU_TOPOLOGY_URL='%s'\n", url)
+	}
+	return strings.Replace(script, "#!/bin/bash\n", header.String(), 1)
+}
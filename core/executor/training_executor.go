@@ -2,68 +2,108 @@ package executor
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
+	"sync"
 	"time"
 
 	"gpu-orchestrator/core/models"
 	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/storage"
 	"gpu-orchestrator/training/frameworks"
 )
 
 // TrainingExecutor executes training jobs on provisioned instances
 type TrainingExecutor struct {
-	jobRepo      *repository.JobRepository
-	pyTorchSetup *frameworks.PyTorchSetup
+	jobRepo         *repository.JobRepository
+	stores          *storage.Registry
+	fetcher         frameworks.ObjectFetcher
+	apiBaseURL      string // Orchestrator URL reachable from nodes (elastic host discovery)
+	onComplete      CompletionHandler
+	onTaskDone      TaskHandler
+	launchRecords   ArtifactRecorder              // Optional; nil skips launch config recording
+	launchConfigURI string                        // Content-addressed script prefix; "" stores scripts inline
+	runner          NodeRunner                    // Optional; nil logs node scripts instead of running them
+	catalog         InstanceCatalog               // Optional; sizes dataset download parallelism
+	replicator      *storage.CheckpointReplicator // Optional; picks the checkpoint copy restarts resume from
+	ports           frameworks.PortAllocator      // Optional; nil uses framework default ports
+	topologyMu      sync.Mutex                    // Serializes topology manifest updates
 }
 
-// NewTrainingExecutor creates a new training executor
-func NewTrainingExecutor(jobRepo *repository.JobRepository) *TrainingExecutor {
+// CompletionHandler is called after a job finishes successfully on its cluster
+type CompletionHandler func(ctx context.Context, job *models.Job, cluster *models.Cluster)
+
+// TaskHandler is called when an attempt of a multi_task job's task ends on
+// its cluster; err is nil on success
+type TaskHandler func(ctx context.Context, job *models.Job, task models.JobTask, cluster *models.Cluster, err error)
+
+// NewTrainingExecutor creates a new training executor.
+// stores resolves entrypoint URIs (s3://, gs://, az://, minio://); nil assumes AWS S3.
+// apiBaseURL is the orchestrator URL nodes poll for elastic host discovery.
+func NewTrainingExecutor(jobRepo *repository.JobRepository, stores *storage.Registry, apiBaseURL string) *TrainingExecutor {
+	var fetcher frameworks.ObjectFetcher
+	if stores != nil {
+		fetcher = stores
+	}
 	return &TrainingExecutor{
-		jobRepo:      jobRepo,
-		pyTorchSetup: &frameworks.PyTorchSetup{},
+		jobRepo:    jobRepo,
+		stores:     stores,
+		fetcher:    fetcher,
+		apiBaseURL: apiBaseURL,
 	}
 }
 
+// SetCompletionHandler registers the callback that releases a finished job's cluster
+func (e *TrainingExecutor) SetCompletionHandler(handler CompletionHandler) {
+	e.onComplete = handler
+}
+
+// SetTaskHandler registers the callback that receives the outcome of task attempts
+func (e *TrainingExecutor) SetTaskHandler(handler TaskHandler) {
+	e.onTaskDone = handler
+}
+
+// SetPortAllocator sets where framework setups get master and worker ports
+func (e *TrainingExecutor) SetPortAllocator(ports frameworks.PortAllocator) {
+	e.ports = ports
+}
+
+// SetCheckpointReplicator sets the replicator that picks which copy of the
+// newest checkpoint a restarted job resumes from
+func (e *TrainingExecutor) SetCheckpointReplicator(replicator *storage.CheckpointReplicator) {
+	e.replicator = replicator
+}
+
 // ExecuteJob executes a training job on a cluster
 func (e *TrainingExecutor) ExecuteJob(
 	ctx context.Context,
 	job *models.Job,
 	cluster *models.Cluster,
 ) error {
+	if job.JobType == models.JobTypeInteractive {
+		return e.startSession(ctx, job, cluster)
+	}
+
 	log.Printf("Executing training job %s on cluster %s", job.ID, cluster.ID)
 
-	// Setup distributed training based on framework
-	var config *frameworks.DistributedConfig
-	var trainingScript string
-	var err error
-
-	switch job.Framework {
-	case "pytorch_ddp":
-		config, err = e.pyTorchSetup.SetupDistributedTraining(cluster, job)
-		if err != nil {
-			return fmt.Errorf("failed to setup PyTorch DDP: %w", err)
-		}
-		trainingScript = e.pyTorchSetup.GenerateTrainingScript(config, job)
-	case "horovod", "horovod_elastic":
-		// Phase 4: Horovod support
-		horovodSetup := &frameworks.HorovodSetup{}
-		config, err = horovodSetup.SetupDistributedTraining(cluster, job)
-		if err != nil {
-			return fmt.Errorf("failed to setup Horovod: %w", err)
-		}
-		trainingScript = horovodSetup.GenerateTrainingScript(config, job)
-	case "tensorflow_multiworker":
-		// Phase 4: TensorFlow MultiWorker support
-		tfSetup := &frameworks.TensorFlowSetup{}
-		config, err = tfSetup.SetupDistributedTraining(cluster, job)
-		if err != nil {
-			return fmt.Errorf("failed to setup TensorFlow: %w", err)
-		}
-		trainingScript = tfSetup.GenerateTrainingScript(config, job)
-	default:
-		return fmt.Errorf("unsupported framework: %s", job.Framework)
+	datasetDir, err := e.downloadDataset(ctx, job, cluster)
+	if err != nil {
+		return err
+	}
+
+	config, trainingScript, err := e.trainingScript(job, cluster)
+	if err != nil {
+		return err
 	}
+	trainingScript = exportDatasetDir(trainingScript, datasetDir)
+	trainingScript = e.exportResumeCheckpoint(job, cluster, trainingScript)
+	manifest := e.recordTopology(job, cluster, config, nil)
+	trainingScript = e.exportTopology(job, manifest, trainingScript)
+	e.recordLaunchConfig(ctx, job, config, trainingScript, nil)
 
 	// Execute on each node
 	// TODO: Implement SSH execution
@@ -76,6 +116,147 @@ func (e *TrainingExecutor) ExecuteJob(
 	return nil
 }
 
+// exportResumeCheckpoint exports CHECKPOINT_RESUME_URI, the copy of the job's
+// newest checkpoint closest to the cluster: its replica when the job is
+// restarted in or near the secondary region. Only jobs that ran before, e.g.
+// migrated or resumed after suspension, have a checkpoint to resume from.
+func (e *TrainingExecutor) exportResumeCheckpoint(job *models.Job, cluster *models.Cluster, script string) string {
+	if e.replicator == nil {
+		return script
+	}
+	resume, ok, err := e.replicator.ResumeCheckpoint(job, cluster.Provider, cluster.Region)
+	if err != nil {
+		log.Printf("Failed to find checkpoint for job %s to resume from: %v", job.ID, err)
+		return script
+	}
+	if !ok {
+		return script
+	}
+	if resume.Replica {
+		log.Printf("Job %s resumes from checkpoint replica %s", job.ID, resume.URI)
+	}
+	return strings.Replace(script, "#!/bin/bash\n", fmt.Sprintf("#!/bin/bash\nexport CHECKPOINT_RESUME_URI='%s'\n", resume.URI), 1)
+}
+
+// ExecuteTask executes one attempt of a multi_task job's task on the task's
+// own cluster. The outcome is reported to the task handler instead of
+// changing the job status, which is aggregated over all tasks.
+func (e *TrainingExecutor) ExecuteTask(
+	ctx context.Context,
+	job *models.Job,
+	task models.JobTask,
+	cluster *models.Cluster,
+) error {
+	log.Printf("Executing task %d (attempt %d) of job %s on cluster %s", task.Index, task.Attempts, job.ID, cluster.ID)
+
+	datasetDir, err := e.downloadDataset(ctx, job, cluster)
+	if err != nil {
+		return err
+	}
+
+	config, trainingScript, err := e.trainingScript(job, cluster)
+	if err != nil {
+		return err
+	}
+	trainingScript = exportDatasetDir(trainingScript, datasetDir)
+	trainingScript = e.exportResumeCheckpoint(job, cluster, trainingScript)
+	if group := task.Allocation.TaskGroup; group != "" {
+		trainingScript = strings.Replace(trainingScript, "#!/bin/bash\n", fmt.Sprintf("#!/bin/bash\nexport TASK_GROUP='%s'\n", group), 1)
+	}
+	taskIndex := task.Index
+	manifest := e.recordTopology(job, cluster, config, &taskIndex)
+	trainingScript = e.exportTopology(job, manifest, trainingScript)
+	launch := map[string]interface{}{
+		"task":    task.Index,
+		"attempt": task.Attempts,
+	}
+	if task.Allocation.TaskGroup != "" {
+		launch["task_group"] = task.Allocation.TaskGroup
+	}
+	e.recordLaunchConfig(ctx, job, config, trainingScript, launch)
+
+	// TODO: Implement SSH execution
+	log.Printf("Training script for task %d of job %s:\n%s", task.Index, job.ID, trainingScript)
+
+	go e.simulateTask(ctx, job, task, cluster)
+
+	return nil
+}
+
+// trainingScript sets up distributed training for the job's framework on
+// cluster and returns its configuration and the script to run on its nodes
+func (e *TrainingExecutor) trainingScript(job *models.Job, cluster *models.Cluster) (*frameworks.DistributedConfig, string, error) {
+	setup, err := frameworks.Lookup(job.Framework, frameworks.Options{Fetcher: e.fetcher, APIBaseURL: e.apiBaseURL, Ports: e.ports})
+	if err != nil {
+		return nil, "", err
+	}
+	config, err := setup.SetupDistributedTraining(cluster, job)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to setup %s: %w", setup.Name(), err)
+	}
+	for i := range config.Nodes {
+		config.Nodes[i].Environment = setup.Environment(config, i)
+	}
+	return config, setup.GenerateNodeScripts(config, job), nil
+}
+
+// startSession bootstraps an interactive session instead of running a training
+// script and records its endpoint and expiry on the job. The session stays up
+// until the scheduler's session manager tears it down.
+func (e *TrainingExecutor) startSession(_ context.Context, job *models.Job, cluster *models.Cluster) error {
+	if job.Session == nil {
+		return fmt.Errorf("interactive job %s has no session config", job.ID)
+	}
+	log.Printf("Starting interactive session for job %s on cluster %s", job.ID, cluster.ID)
+
+	token, err := sessionToken()
+	if err != nil {
+		return err
+	}
+
+	sessionSetup := &frameworks.SessionSetup{}
+	if e.apiBaseURL != "" && job.Session.IdleStop {
+		sessionSetup.ActivityURL = fmt.Sprintf("%s/v1/jobs/%s/session/activity", e.apiBaseURL, job.ID)
+	}
+	endpoint, err := sessionSetup.Endpoint(cluster, job, token)
+	if err != nil {
+		return fmt.Errorf("failed to resolve session endpoint: %w", err)
+	}
+
+	// Execute on the node
+	// TODO: Implement SSH execution
+	// For now, log the script
+	log.Printf("Session script for job %s:\n%s", job.ID, sessionSetup.GenerateSessionScript(job, endpoint))
+
+	expiresAt := time.Now().Add(job.Session.MaxRuntime)
+	if err := e.jobRepo.SetSessionEndpoint(job.ID, endpoint, expiresAt); err != nil {
+		return fmt.Errorf("failed to register session endpoint: %w", err)
+	}
+	job.SessionEndpoint = &endpoint
+	job.SessionExpiresAt = &expiresAt
+
+	running := models.JobStatusRunning
+	if err := e.jobRepo.CreateJobEvent(job.ID, &running, running, "session_ready", map[string]interface{}{
+		"host":       endpoint.Host,
+		"port":       endpoint.Port,
+		"protocol":   endpoint.Protocol,
+		"expires_at": expiresAt,
+	}); err != nil {
+		log.Printf("Failed to record session_ready event for job %s: %v", job.ID, err)
+	}
+
+	return nil
+}
+
+// sessionToken returns a random Jupyter access token
+func sessionToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate session token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
 // simulateExecution simulates training execution (for MVP testing)
 func (e *TrainingExecutor) simulateExecution(ctx context.Context, job *models.Job, cluster *models.Cluster) {
 	// Simulate training time
@@ -92,17 +273,50 @@ func (e *TrainingExecutor) simulateExecution(ctx context.Context, job *models.Jo
 	time.Sleep(testDuration)
 
 	// Update job status to completed
-	if err := e.jobRepo.UpdateJobStatus(
+	err := e.jobRepo.UpdateJobStatus(
 		job.ID,
 		models.JobStatusRunning,
 		models.JobStatusCompleted,
 		"training_completed",
 		nil,
-	); err != nil {
+	)
+	if errors.Is(err, repository.ErrStatusConflict) {
+		// Training that finishes within a cancellation's grace window completes
+		err = e.jobRepo.UpdateJobStatus(job.ID, models.JobStatusCancelling, models.JobStatusCompleted, "training_completed", nil)
+	}
+	if errors.Is(err, repository.ErrStatusConflict) {
+		// So does training that finishes while a suspension waits for its checkpoint
+		err = e.jobRepo.UpdateJobStatus(job.ID, models.JobStatusCheckpointing, models.JobStatusCompleted, "training_completed", nil)
+	}
+	if errors.Is(err, repository.ErrStatusConflict) {
+		// Cancelled or failed meanwhile; the cluster still has to be released
+		log.Printf("Job %s finished training but is no longer running: %v", job.ID, err)
+	} else if err != nil {
 		log.Printf("Failed to update job status: %v", err)
+		return
+	} else {
+		log.Printf("Job %s completed", job.ID)
 	}
 
-	log.Printf("Job %s completed", job.ID)
+	if e.onComplete != nil {
+		e.onComplete(ctx, job, cluster)
+	}
+}
+
+// simulateTask simulates a task attempt (for MVP testing)
+func (e *TrainingExecutor) simulateTask(ctx context.Context, job *models.Job, task models.JobTask, cluster *models.Cluster) {
+	duration := time.Duration(job.Requirements.EstimatedHours * float64(time.Hour))
+	if testDuration := 30 * time.Second; duration > testDuration {
+		duration = testDuration
+	}
+
+	time.Sleep(duration)
+
+	log.Printf("Task %d of job %s completed", task.Index, job.ID)
+
+	if e.onTaskDone != nil {
+		e.onTaskDone(ctx, job, task, cluster, nil)
+	}
 }
 
 // ExecuteOnNode executes a command on a specific node via SSH
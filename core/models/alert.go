@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// AlertConditionType represents what an alert rule evaluates
+type AlertConditionType string
+
+const (
+	// AlertStatusDuration fires when a job stays in a status longer than Threshold minutes
+	AlertStatusDuration AlertConditionType = "status_duration"
+	// AlertFailureCount fires when a team has at least Threshold failed jobs within Window
+	AlertFailureCount AlertConditionType = "failure_count"
+	// AlertCostThreshold fires when a job's running cost exceeds Threshold USD
+	AlertCostThreshold AlertConditionType = "cost_threshold"
+	// AlertIdleCost fires when fleet idle cost exceeds Threshold USD/hour
+	AlertIdleCost AlertConditionType = "idle_cost"
+)
+
+// AlertRule defines a condition evaluated periodically against jobs/fleet state
+type AlertRule struct {
+	ID              int64
+	Name            string
+	Condition       AlertConditionType
+	Threshold       float64       // Minutes, count, USD or USD/hour depending on Condition
+	Status          JobStatus     // For status_duration
+	Window          time.Duration // For failure_count
+	TeamID          string        // Optional team filter
+	Cooldown        time.Duration // Minimum time between firings for the same key
+	WebhookURL      string        // e.g. Slack incoming webhook for #ml-infra
+	EmailRecipients []string
+	Enabled         bool
+	CreatedAt       time.Time
+	LastEvaluatedAt *time.Time
+}
+
+// AlertFiring records a delivered alert
+type AlertFiring struct {
+	ID       int64
+	RuleID   int64
+	RuleName string
+	DedupKey string // Job ID, team ID or "fleet"
+	FiredAt  time.Time
+	Subject  string
+	Message  string
+	MetaJSON map[string]interface{}
+}
@@ -0,0 +1,27 @@
+package models
+
+// APIUsageOther replaces the job IDs and clients first seen after the
+// tracking caps are reached, so their requests are counted in one row
+const APIUsageOther = "_other"
+
+// APIUsage counts the API requests one client made to one endpoint, on one
+// job for job-scoped endpoints. Day is the UTC date ("2006-01-02") of the
+// daily rollup.
+type APIUsage struct {
+	Day       string  `json:"day"`
+	Endpoint  string  `json:"endpoint"` // Method and route, e.g. "GET /v1/jobs/{id}"
+	Client    string  `json:"client"`   // Caller identity, as recorded in the audit log
+	JobID     string  `json:"job_id,omitempty"`
+	Requests  int64   `json:"requests"`
+	Errors    int64   `json:"errors"` // 4xx and 5xx responses
+	LatencyMs float64 `json:"latency_ms_total"`
+}
+
+// APIUsageTotal is the API usage of one endpoint, client or job over a
+// period
+type APIUsageTotal struct {
+	Key          string  `json:"key"`
+	Requests     int64   `json:"requests"`
+	Errors       int64   `json:"errors"`
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Audit actors that are not API callers
+const (
+	ActorSystem    = "system"    // Background workers, e.g. scheduler status changes
+	ActorAdmin     = "admin"     // Caller presenting the admin token without an identity header
+	ActorAnonymous = "anonymous" // Caller without credentials
+)
+
+// AuditJobStatusChanged is the action of job status changes
+const AuditJobStatusChanged = "job.status_changed"
+
+// AuditEntry records one mutating action: an API call or a change made by
+// the system. Entries are append-only.
+type AuditEntry struct {
+	ID           int64                  `json:"id"`
+	Actor        string                 `json:"actor"`
+	Action       string                 `json:"action"` // e.g. "POST /v1/jobs/{id}/cancel" or job.status_changed
+	ResourceType string                 `json:"resource_type,omitempty"`
+	ResourceID   string                 `json:"resource_id,omitempty"`
+	Request      map[string]interface{} `json:"request,omitempty"`     // Summary with secrets redacted
+	StatusCode   int                    `json:"status_code,omitempty"` // HTTP status of API calls
+	RemoteAddr   string                 `json:"remote_addr,omitempty"`
+	CreatedAt    time.Time              `json:"created_at"`
+}
+
+// AuditFilter selects audit entries; zero fields match everything
+type AuditFilter struct {
+	Actor        string
+	ResourceType string
+	ResourceID   string
+	Since        time.Time
+	Until        time.Time
+	Limit        int
+}
+
+// Audit actions of per-cluster SSH keys
+const (
+	AuditSSHKeyIssued    = "ssh_key.issued"
+	AuditSSHKeyUsed      = "ssh_key.used"
+	AuditSSHKeyRotated   = "ssh_key.rotated"
+	AuditSSHKeyDestroyed = "ssh_key.destroyed"
+)
+
+// AuditOrphansDeleted is the action of orphan cleaner passes that deleted
+// records
+const AuditOrphansDeleted = "orphans.deleted"
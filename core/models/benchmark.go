@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// Benchmark sources
+const (
+	BenchmarkStatic  = "static"  // Shipped baseline
+	BenchmarkLearned = "learned" // Observed on jobs that ran here
+)
+
+// BenchmarkSample is the throughput one job was observed at, per GPU
+type BenchmarkSample struct {
+	Framework     string
+	GPUType       string
+	ModelClass    string
+	StepsPerHour  float64
+	TokensPerHour float64 // 0 when the job reports no tokens
+}
+
+// LearnedBenchmark is the mean throughput observed for a framework, GPU type
+// and model class. Means weigh at most the last window samples equally, so
+// they follow changes in drivers and images.
+type LearnedBenchmark struct {
+	Framework     string    `json:"framework"`
+	GPUType       string    `json:"gpu_type"`
+	ModelClass    string    `json:"model_class"`
+	StepsPerHour  float64   `json:"steps_per_hour"`
+	Samples       int       `json:"samples"`
+	TokensPerHour float64   `json:"tokens_per_hour,omitempty"`
+	TokenSamples  int       `json:"token_samples,omitempty"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Add folds a sample into the means
+func (b *LearnedBenchmark) Add(sample BenchmarkSample, window int, at time.Time) {
+	b.Samples++
+	b.StepsPerHour += (sample.StepsPerHour - b.StepsPerHour) / float64(min(b.Samples, window))
+	if sample.TokensPerHour > 0 {
+		b.TokenSamples++
+		b.TokensPerHour += (sample.TokensPerHour - b.TokensPerHour) / float64(min(b.TokenSamples, window))
+	}
+	b.UpdatedAt = at
+}
+
+// EffectiveBenchmark is the throughput the optimizer estimates with for a
+// framework, GPU type and model class, next to the figures it came from
+type EffectiveBenchmark struct {
+	Framework     string  `json:"framework"`
+	GPUType       string  `json:"gpu_type"`
+	ModelClass    string  `json:"model_class"`
+	Source        string  `json:"source"` // static | learned
+	StepsPerHour  float64 `json:"steps_per_hour"`
+	TokensPerHour float64 `json:"tokens_per_hour,omitempty"`
+
+	StaticStepsPerHour  float64           `json:"static_steps_per_hour,omitempty"` // 0 = no shipped baseline
+	StaticTokensPerHour float64           `json:"static_tokens_per_hour,omitempty"`
+	Learned             *LearnedBenchmark `json:"learned,omitempty"`
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CheckpointBehindFactor is how many declared intervals may pass without a
+// new checkpoint before a job counts as behind its cadence
+const CheckpointBehindFactor = 2
+
+// CheckpointSchedule is the checkpoint cadence a job declares (checkpointing).
+// The training process reads it from CHECKPOINT_INTERVAL,
+// CHECKPOINT_INTERVAL_UNIT and CHECKPOINT_URI; the orchestrator only checks
+// that checkpoints appear at roughly that cadence.
+type CheckpointSchedule struct {
+	IntervalSteps   int64  `json:"interval_steps,omitempty"`
+	IntervalMinutes int    `json:"interval_minutes,omitempty"`
+	URI             string `json:"uri"` // Prefix; the job's checkpoints go under <uri>/<job id>
+
+	// Secondary location every checkpoint is copied to: "provider/region"
+	// (resolved through CHECKPOINT_LOCATIONS) or an object store URI prefix.
+	// Empty = no replication.
+	ReplicateTo string `json:"replicate_to,omitempty"`
+}
+
+// JobURI returns where the job writes its checkpoints
+func (s *CheckpointSchedule) JobURI(jobID string) string {
+	return strings.TrimRight(s.URI, "/") + "/" + jobID
+}
+
+// Environment returns the variables that expose the schedule to the
+// training process
+func (s *CheckpointSchedule) Environment(jobID string) map[string]string {
+	env := map[string]string{"CHECKPOINT_URI": s.JobURI(jobID)}
+	if s.IntervalSteps > 0 {
+		env["CHECKPOINT_INTERVAL"] = strconv.FormatInt(s.IntervalSteps, 10)
+		env["CHECKPOINT_INTERVAL_UNIT"] = "steps"
+	} else {
+		env["CHECKPOINT_INTERVAL"] = strconv.Itoa(s.IntervalMinutes)
+		env["CHECKPOINT_INTERVAL_UNIT"] = "minutes"
+	}
+	return env
+}
+
+// Replication status of a checkpoint artifact (replica_status)
+const (
+	ReplicaPending    = "pending"    // Not copied yet, or retrying after a failure
+	ReplicaReplicated = "replicated" // Copied; the replica URI is in the artifact metadata
+	ReplicaFailed     = "failed"     // Gave up after the maximum attempts
+	ReplicaNone       = "none"       // The job does not replicate checkpoints
+)
+
+// CheckpointReplica is the replication state of one checkpoint
+type CheckpointReplica struct {
+	Status       string     `json:"status"`
+	URI          string     `json:"uri,omitempty"`
+	Attempts     int        `json:"attempts"`
+	ReplicatedAt *time.Time `json:"replicated_at,omitempty"`
+	LagSeconds   float64    `json:"lag_seconds"` // Until replicated, or so far while pending
+	SizeGB       float64    `json:"size_gb,omitempty"`
+	CostUSD      float64    `json:"cost_usd,omitempty"`
+	Error        string     `json:"error,omitempty"` // Last failure
+}
+
+// CheckpointReplication summarizes a job's checkpoint replication
+type CheckpointReplication struct {
+	ReplicateTo      string     `json:"replicate_to"`
+	Location         string     `json:"location,omitempty"` // Resolved URI prefix; empty when unresolvable
+	Replicated       int        `json:"replicated"`
+	Pending          int        `json:"pending"`
+	Failed           int        `json:"failed"`
+	LagSeconds       float64    `json:"lag_seconds"` // Age of the oldest checkpoint not replicated yet; 0 in sync
+	LastReplicatedAt *time.Time `json:"last_replicated_at,omitempty"`
+	CostUSD          float64    `json:"cost_usd"`
+}
+
+// CheckpointCadence is how far a job is past its latest checkpoint
+type CheckpointCadence struct {
+	Behind       bool      `json:"behind"`
+	Since        time.Time `json:"since"`                   // Latest checkpoint, or the job's start without one
+	StepsSince   int64     `json:"steps_since,omitempty"`   // interval_steps schedules
+	MinutesSince float64   `json:"minutes_since,omitempty"` // interval_minutes schedules
+	Limit        float64   `json:"limit"`                   // Steps or minutes after which the job is behind
+}
+
+// CheckpointingBehind compares a job's declared checkpoint schedule with its
+// latest checkpoint (nil when it has none). Step schedules need reported
+// progress and are never behind without it. Returns nil when the job declares
+// no schedule or has not started.
+func CheckpointingBehind(job *Job, latest *JobArtifact, now time.Time) *CheckpointCadence {
+	schedule := job.Checkpointing
+	if schedule == nil || job.StartedAt == nil {
+		return nil
+	}
+
+	cadence := &CheckpointCadence{Since: *job.StartedAt}
+	sinceStep := int64(0)
+	if latest != nil && latest.CreatedAt.After(cadence.Since) {
+		cadence.Since = latest.CreatedAt
+		if step, ok := latest.MetaJSON["step"].(float64); ok {
+			sinceStep = int64(step)
+		}
+	}
+
+	if schedule.IntervalSteps > 0 {
+		cadence.Limit = float64(CheckpointBehindFactor * schedule.IntervalSteps)
+		if job.Progress == nil || job.Progress.StepsCompleted < sinceStep {
+			return cadence // No progress yet, or resumed from an earlier checkpoint
+		}
+		cadence.StepsSince = job.Progress.StepsCompleted - sinceStep
+		cadence.Behind = float64(cadence.StepsSince) > cadence.Limit
+		return cadence
+	}
+
+	cadence.Limit = float64(CheckpointBehindFactor * schedule.IntervalMinutes)
+	cadence.MinutesSince = now.Sub(cadence.Since).Minutes()
+	cadence.Behind = cadence.MinutesSince > cadence.Limit
+	return cadence
+}
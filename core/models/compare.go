@@ -0,0 +1,218 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Spec change kinds
+const (
+	SpecChangeAdded   = "added"   // Unset (or zero) in the base job
+	SpecChangeRemoved = "removed" // Unset (or zero) in the other job
+	SpecChangeChanged = "changed"
+)
+
+// SpecChange is one field that differs between two parsed job specs. Path
+// uses the spec's YAML keys, e.g. resources.gpus or labels.team.
+type SpecChange struct {
+	Path   string      `json:"path"`
+	Change string      `json:"change"` // added | removed | changed
+	From   interface{} `json:"from"`
+	To     interface{} `json:"to"`
+}
+
+// String renders the change as "resources.gpus: 4 → 8"
+func (c SpecChange) String() string {
+	return fmt.Sprintf("%s: %s → %s", c.Path, formatSpecValue(c.From), formatSpecValue(c.To))
+}
+
+// formatSpecValue renders a spec value; unset values read as "(unset)"
+func formatSpecValue(value interface{}) string {
+	if value == nil {
+		return "(unset)"
+	}
+	if s, ok := value.(string); ok && s == "" {
+		return `""`
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// PlacementSummary is the capacity a job was placed on in one provider,
+// region, instance type and pricing model
+type PlacementSummary struct {
+	Provider     Provider `json:"provider"`
+	Region       string   `json:"region"`
+	InstanceType string   `json:"instance_type"`
+	Spot         bool     `json:"spot"`
+	Count        int      `json:"count"`
+	PricePerHour float64  `json:"price_per_hour"` // Per instance
+}
+
+// key identifies a placement regardless of its size and price, e.g.
+// "aws/us-east-1 p4d.24xlarge spot"
+func (p PlacementSummary) key() string {
+	pricing := "on-demand"
+	if p.Spot {
+		pricing = "spot"
+	}
+	return fmt.Sprintf("%s/%s %s %s", p.Provider, p.Region, p.InstanceType, pricing)
+}
+
+// String renders the placement as "aws/us-east-1 p4d.24xlarge spot x2 @ $12.30/h"
+func (p PlacementSummary) String() string {
+	return fmt.Sprintf("%s x%d @ $%.2f/h", p.key(), p.Count, p.PricePerHour)
+}
+
+// SummarizePlacements merges a job's allocations by placement, in a stable
+// order. Counts add up; the price is the one last allocated at.
+func SummarizePlacements(allocations []Allocation) []PlacementSummary {
+	byKey := make(map[string]*PlacementSummary)
+	var summaries []*PlacementSummary
+	for _, alloc := range allocations {
+		summary := PlacementSummary{
+			Provider:     alloc.Provider,
+			Region:       alloc.Region,
+			InstanceType: alloc.InstanceType,
+			Spot:         alloc.Spot,
+		}
+		existing, ok := byKey[summary.key()]
+		if !ok {
+			existing = &summary
+			byKey[summary.key()] = existing
+			summaries = append(summaries, existing)
+		}
+		existing.Count += alloc.Count
+		existing.PricePerHour = alloc.PricePerHour
+	}
+
+	result := make([]PlacementSummary, len(summaries))
+	for i, summary := range summaries {
+		result[i] = *summary
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].key() < result[j].key() })
+	return result
+}
+
+// DiffPlacements describes how the other job's placement differs from the
+// base job's: placements added, removed, or resized and repriced
+func DiffPlacements(base, other []PlacementSummary) []string {
+	otherByKey := make(map[string]PlacementSummary, len(other))
+	for _, placement := range other {
+		otherByKey[placement.key()] = placement
+	}
+
+	var changes []string
+	for _, before := range base {
+		after, ok := otherByKey[before.key()]
+		if !ok {
+			changes = append(changes, "removed "+before.String())
+			continue
+		}
+		delete(otherByKey, before.key())
+		if before.Count != after.Count {
+			changes = append(changes, fmt.Sprintf("%s: count %d → %d", before.key(), before.Count, after.Count))
+		}
+		if before.PricePerHour != after.PricePerHour {
+			changes = append(changes, fmt.Sprintf("%s: price $%.4f/h → $%.4f/h", before.key(), before.PricePerHour, after.PricePerHour))
+		}
+	}
+	for _, after := range other {
+		if _, added := otherByKey[after.key()]; added {
+			changes = append(changes, "added "+after.String())
+		}
+	}
+	return changes
+}
+
+// JobOutcome is how a job ran: what it took and what it reached
+type JobOutcome struct {
+	Status        JobStatus `json:"status"`
+	DurationHours *float64  `json:"duration_hours"` // Start to finish, or to now while running; null until started
+	CostUSD       float64   `json:"cost_usd"`       // Running cost plus checkpoint transfers
+	StepsPerHour  *float64  `json:"steps_per_hour"` // From progress reports; null without telemetry
+	Steps         *int64    `json:"steps"`
+	FailureClass  string    `json:"failure_class,omitempty"` // Reason of the failure event
+}
+
+// OutcomeOf returns a job's outcome. failureReason is the reason of the
+// job's failure event, if it failed.
+func OutcomeOf(job *Job, failureReason string, now time.Time) JobOutcome {
+	outcome := JobOutcome{
+		Status:  job.Status,
+		CostUSD: job.CostRunningUSD + job.CostTransferUSD + job.CostEgressUSD,
+	}
+	if job.StartedAt != nil {
+		end := now
+		if job.CompletedAt != nil {
+			end = *job.CompletedAt
+		}
+		hours := end.Sub(*job.StartedAt).Hours()
+		outcome.DurationHours = &hours
+	}
+	if job.Progress != nil {
+		steps := job.Progress.StepsCompleted
+		outcome.Steps = &steps
+		if rate := job.Progress.Rate(); rate > 0 {
+			outcome.StepsPerHour = &rate
+		}
+	}
+	if job.Status == JobStatusFailed {
+		outcome.FailureClass = failureReason
+	}
+	return outcome
+}
+
+// OutcomeDelta is the other job's outcome minus the base job's. Deltas are
+// null when either side has no figure.
+type OutcomeDelta struct {
+	DurationHours *float64 `json:"duration_hours"`
+	CostUSD       float64  `json:"cost_usd"`
+	StepsPerHour  *float64 `json:"steps_per_hour"`
+	// Relative change of steps per hour, e.g. 0.25 for 25% faster
+	StepsPerHourRatio *float64 `json:"steps_per_hour_ratio,omitempty"`
+}
+
+// DiffOutcomes returns the other outcome minus the base outcome
+func DiffOutcomes(base, other JobOutcome) OutcomeDelta {
+	delta := OutcomeDelta{
+		DurationHours: subtractOptional(other.DurationHours, base.DurationHours),
+		CostUSD:       other.CostUSD - base.CostUSD,
+		StepsPerHour:  subtractOptional(other.StepsPerHour, base.StepsPerHour),
+	}
+	if delta.StepsPerHour != nil && *base.StepsPerHour > 0 {
+		ratio := *delta.StepsPerHour / *base.StepsPerHour
+		delta.StepsPerHourRatio = &ratio
+	}
+	return delta
+}
+
+// subtractOptional returns a - b, or nil when either is missing
+func subtractOptional(a, b *float64) *float64 {
+	if a == nil || b == nil {
+		return nil
+	}
+	difference := *a - *b
+	return &difference
+}
+
+// ComparedJob is one side of a job comparison
+type ComparedJob struct {
+	ID         string             `json:"id"`
+	Name       string             `json:"name"`
+	CreatedAt  time.Time          `json:"created_at"`
+	SpecHash   string             `json:"spec_hash"`
+	Placements []PlacementSummary `json:"placements"`
+	Outcome    JobOutcome         `json:"outcome"`
+}
+
+// JobComparison is what differs between a base job and another job, as
+// the other job relative to the base
+type JobComparison struct {
+	Base             ComparedJob  `json:"base"`
+	Other            ComparedJob  `json:"other"`
+	SameSpec         bool         `json:"same_spec"`
+	SpecChanges      []SpecChange `json:"spec_changes"`
+	PlacementChanges []string     `json:"placement_changes"`
+	Outcome          OutcomeDelta `json:"outcome"`
+}
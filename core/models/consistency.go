@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// Discrepancy categories found by the consistency checker
+const (
+	DiscrepancyMissingInstance    = "missing_instance"    // A tracked node is gone or terminated at the provider
+	DiscrepancyUnexpectedInstance = "unexpected_instance" // A managed instance at the provider no cluster tracks
+	DiscrepancyCostGap            = "cost_gap"            // A running job's recorded cost is far below its allocations' price
+	DiscrepancyStatusMismatch     = "status_mismatch"     // Job, allocation and cluster states disagree
+)
+
+// DiscrepancyCategories lists every category, in report order
+var DiscrepancyCategories = []string{
+	DiscrepancyMissingInstance,
+	DiscrepancyUnexpectedInstance,
+	DiscrepancyCostGap,
+	DiscrepancyStatusMismatch,
+}
+
+// Discrepancy is one disagreement between the database, provider state and
+// cost records
+type Discrepancy struct {
+	Category     string   `json:"category"`
+	JobID        string   `json:"job_id,omitempty"`
+	AllocationID int64    `json:"allocation_id,omitempty"`
+	ClusterID    string   `json:"cluster_id,omitempty"`
+	Provider     Provider `json:"provider,omitempty"`
+	Region       string   `json:"region,omitempty"`
+	InstanceID   string   `json:"instance_id,omitempty"`
+	Expected     string   `json:"expected,omitempty"`
+	Actual       string   `json:"actual,omitempty"`
+	Detail       string   `json:"detail"`
+}
+
+// ConsistencyReport is the result of one consistency check. The check only
+// reads; discrepancies are left for an operator to resolve.
+type ConsistencyReport struct {
+	ID            int64          `json:"id"`
+	StartedAt     time.Time      `json:"started_at"`
+	FinishedAt    time.Time      `json:"finished_at"`
+	Counts        map[string]int `json:"counts"` // Discrepancies per category
+	Discrepancies []Discrepancy  `json:"discrepancies"`
+	Skipped       []string       `json:"skipped,omitempty"` // Provider regions that could not be checked, and why
+}
+
+// Add records a discrepancy
+func (r *ConsistencyReport) Add(d Discrepancy) {
+	if r.Counts == nil {
+		r.Counts = make(map[string]int)
+	}
+	r.Counts[d.Category]++
+	r.Discrepancies = append(r.Discrepancies, d)
+}
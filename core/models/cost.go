@@ -0,0 +1,75 @@
+package models
+
+// JobCost splits what a job cost into compute, billed for its allocations
+// over the job's running window, and infrastructure, billed for its
+// instances from launch to termination. The difference is overhead: boot,
+// setup, waiting on held instances and teardown.
+type JobCost struct {
+	JobID             string  `json:"job_id"`
+	ComputeUSD        float64 `json:"compute_usd"`
+	InfrastructureUSD float64 `json:"infrastructure_usd"`
+	OverheadUSD       float64 `json:"overhead_usd"`
+	RunningUSD        float64 `json:"running_usd"`  // Accrued by the cost tracker while running
+	TransferUSD       float64 `json:"transfer_usd"` // Checkpoint replication to the secondary location
+	EgressUSD         float64 `json:"egress_usd"`   // Data the nodes sent out of the provider's network
+	EgressGB          float64 `json:"egress_gb"`
+
+	Instances     int `json:"instances"`
+	OpenInstances int `json:"open_instances"` // Not terminated yet; billed up to now
+	// Allocations without instance records (Kubernetes backend, or launched
+	// before instances were recorded); their infrastructure is their compute
+	UntrackedAllocations int `json:"untracked_allocations,omitempty"`
+
+	Allocations    []AllocationCost   `json:"allocations"`
+	Reconciliation CostReconciliation `json:"reconciliation"`
+
+	// Cost of each task group of a multi_task job, in spec order; empty
+	// without groups
+	TaskGroups []TaskGroupCost `json:"task_groups,omitempty"`
+}
+
+// TaskGroupCost sums the allocation costs of one task group. Instances of
+// replaced allocations are not attributed to a group.
+type TaskGroupCost struct {
+	Name              string  `json:"name"`
+	Allocations       int     `json:"allocations"`
+	ComputeUSD        float64 `json:"compute_usd"`
+	InfrastructureUSD float64 `json:"infrastructure_usd"`
+	OverheadUSD       float64 `json:"overhead_usd"`
+}
+
+// AllocationCost is the cost of one allocation of a job. Instances whose
+// allocation was replaced (requeues) or never stored (elastic growth) are
+// reported with allocation ID 0 and no compute.
+type AllocationCost struct {
+	AllocationID      int64    `json:"allocation_id"`
+	TaskGroup         string   `json:"task_group,omitempty"` // Task group the allocation runs
+	Provider          Provider `json:"provider"`
+	Region            string   `json:"region"`
+	InstanceType      string   `json:"instance_type"`
+	Spot              bool     `json:"spot"`
+	PricePerHour      float64  `json:"price_per_hour"`
+	ComputeHours      float64  `json:"compute_hours"` // Instance-hours over the running window
+	InstanceHours     float64  `json:"instance_hours"`
+	ComputeUSD        float64  `json:"compute_usd"`
+	InfrastructureUSD float64  `json:"infrastructure_usd"`
+	OverheadUSD       float64  `json:"overhead_usd"`
+}
+
+// CostReconciliation compares a job's infrastructure cost with its estimate
+type CostReconciliation struct {
+	EstimatedUSD      *float64 `json:"estimated_usd"` // null when the job has no estimate
+	InfrastructureUSD float64  `json:"infrastructure_usd"`
+	VarianceUSD       *float64 `json:"variance_usd,omitempty"`   // Infrastructure minus estimate
+	VarianceRatio     *float64 `json:"variance_ratio,omitempty"` // Variance over the estimate; omitted for a $0 estimate
+
+	Transfer TransferReconciliation `json:"transfer"`
+}
+
+// TransferReconciliation compares the data transfer cost the optimizer
+// planned for with the transfer the job was charged
+type TransferReconciliation struct {
+	EstimatedUSD *float64 `json:"estimated_usd"` // Chosen strategy's data transfer; null without a decision
+	ObservedUSD  float64  `json:"observed_usd"`  // Egress plus checkpoint replication
+	VarianceUSD  *float64 `json:"variance_usd,omitempty"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// Deadline risk levels, in escalating order
+const (
+	DeadlineRiskLow        = "low"
+	DeadlineRiskWatch      = "watch"
+	DeadlineRiskAtRisk     = "at_risk"
+	DeadlineRiskLikelyMiss = "likely_miss"
+)
+
+// DeadlineRiskLevels lists the levels by rank: a level's index is its rank
+var DeadlineRiskLevels = []string{DeadlineRiskLow, DeadlineRiskWatch, DeadlineRiskAtRisk, DeadlineRiskLikelyMiss}
+
+// DeadlineRiskRank returns the rank of a level, 0 for unknown levels
+func DeadlineRiskRank(level string) int {
+	for rank, l := range DeadlineRiskLevels {
+		if l == level {
+			return rank
+		}
+	}
+	return 0
+}
+
+// Deadline projection bases
+const (
+	DeadlineBasisSteps = "steps" // Reported step rate vs training.total_steps
+	DeadlineBasisTime  = "time"  // Estimated runtime
+)
+
+// Deadline remedy actions
+const (
+	RemedyRaiseBudget   = "raise_budget"   // Afford on-demand capacity instead of waiting for or losing spot
+	RemedyRelaxLocality = "relax_locality" // Let the optimizer place the job outside the data's region
+	RemedyIncreaseGPUs  = "increase_gpus"  // Train faster on more GPUs
+)
+
+// DeadlineRemedy is a change to a job's spec that would lower its risk of
+// missing the deadline
+type DeadlineRemedy struct {
+	Action  string `json:"action"`
+	Field   string `json:"field"`           // Spec field to change, e.g. "constraints.budget"
+	Value   string `json:"value,omitempty"` // Suggested value, when one can be derived
+	Message string `json:"message"`
+}
+
+// DeadlineRisk is the assessed chance that an unfinished job misses its
+// deadline, from its progress, remaining work and, while queued, the queue
+// wait forecast
+type DeadlineRisk struct {
+	Risk            float64          `json:"risk"` // 0-1; probability-like, not calibrated
+	Level           string           `json:"level"`
+	Deadline        time.Time        `json:"deadline"`
+	ProjectedFinish time.Time        `json:"projected_finish"`
+	SlackHours      float64          `json:"slack_hours"` // Deadline minus projected finish; negative = late
+	Basis           string           `json:"basis"`
+	RunHours        float64          `json:"run_hours"`             // Training time left
+	QueueHours      float64          `json:"queue_hours,omitempty"` // Forecast wait and startup before training resumes
+	Remedies        []DeadlineRemedy `json:"remedies,omitempty"`    // Only at watch and above
+	EvaluatedAt     time.Time        `json:"evaluated_at"`
+}
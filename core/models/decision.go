@@ -0,0 +1,108 @@
+package models
+
+import "time"
+
+// Strategy rejection reasons recorded by the optimizer
+const (
+	RejectionOverBudget     = "over_budget"           // Total cost incl. transfer exceeds the budget
+	RejectionLowReliability = "below_min_reliability" // Reliability below constraints.min_reliability
+	RejectionNoCapacity     = "no_capacity"           // Not all requested GPUs could be placed
+	RejectionBidBelowSpot   = "bid_below_spot"        // Spot bid (spot_bid_cap) below the current spot price
+)
+
+// AllocationDecision records how the optimizer chose a job's allocation:
+// every strategy it evaluated and a human-readable explanation
+type AllocationDecision struct {
+	Chosen          string               `json:"chosen,omitempty"` // Strategy name; empty when nothing was feasible
+	Strategies      []StrategyEvaluation `json:"strategies"`
+	DatasetLocation string               `json:"dataset_location,omitempty"`
+	Weights         *ScoringWeights      `json:"weights,omitempty"` // Normalized score weights used
+	Metric          TrainingMetric       `json:"metric,omitempty"`  // Set when the cost term was not per hour
+	Explanation     string               `json:"explanation"`
+	DecidedAt       time.Time            `json:"decided_at"`
+
+	// Otherwise suitable instances the instance type allow/deny lists excluded;
+	// set only when that left no candidates
+	ExcludedInstances int `json:"excluded_instances,omitempty"`
+
+	// Team's data gravity scores by "provider:region" that strategies got a
+	// bonus for; empty when the job had none
+	DataGravity map[string]float64 `json:"data_gravity,omitempty"`
+
+	// Estimate vs telemetry for tokens-mode jobs; filled in when read, not stored
+	TokenCost *TokenCostReconciliation `json:"token_cost,omitempty"`
+
+	// Decisions of a multi_task job's task groups, each planned on its own;
+	// Strategies then holds only their combination
+	TaskGroups []TaskGroupDecision `json:"task_groups,omitempty"`
+}
+
+// TaskGroupDecision is the allocation decision of one task group
+type TaskGroupDecision struct {
+	Name     string              `json:"name"`
+	Decision *AllocationDecision `json:"decision"`
+}
+
+// TokenCostReconciliation compares a tokens-mode job's estimated $/1M tokens
+// with the actual value from its running cost and reported tokens
+type TokenCostReconciliation struct {
+	EstimatedUSDPerMillionTokens float64 `json:"estimated_usd_per_million_tokens"`
+	ActualUSDPerMillionTokens    float64 `json:"actual_usd_per_million_tokens,omitempty"` // 0 until tokens are reported
+	Tokens                       int64   `json:"tokens"`
+	CostUSD                      float64 `json:"cost_usd"`
+}
+
+// ChosenStrategy returns the evaluation of the chosen strategy
+func (d *AllocationDecision) ChosenStrategy() (StrategyEvaluation, bool) {
+	for _, s := range d.Strategies {
+		if d.Chosen != "" && s.Strategy == d.Chosen {
+			return s, true
+		}
+	}
+	return StrategyEvaluation{}, false
+}
+
+// StrategyEvaluation is one scored strategy of a decision
+type StrategyEvaluation struct {
+	Strategy            string               `json:"strategy"` // e.g. cheapest_single_region, data_locality
+	Allocations         []DecisionAllocation `json:"allocations,omitempty"`
+	HourlyCost          float64              `json:"hourly_cost"`
+	TotalCost           float64              `json:"total_cost"`
+	DataTransferCost    float64              `json:"data_transfer_cost"`
+	Reliability         float64              `json:"reliability"`
+	USDPerMillionTokens float64              `json:"usd_per_million_tokens,omitempty"` // Tokens mode only
+	EmissionsGCO2e      float64              `json:"emissions_gco2e"`                  // Estimated footprint of the run
+	GravityBonus        float64              `json:"gravity_bonus,omitempty"`          // Subtracted from the score for the team's data regions
+	Score               float64              `json:"score"`                            // Lower is better
+	Terms               *ScoreTerms          `json:"terms,omitempty"`
+	Rejections          []StrategyRejection  `json:"rejections,omitempty"`
+	TaskSpread          []RegionTasks        `json:"task_spread,omitempty"` // Tasks per region; geo_distributed only
+}
+
+// ScoreTerms are a strategy's unweighted score terms (lower is better); the
+// score is their sum weighted by the decision's weights
+type ScoreTerms struct {
+	Cost        float64 `json:"cost"`
+	Reliability float64 `json:"reliability"`
+	Time        float64 `json:"time"`
+	Locality    float64 `json:"locality"`
+	Carbon      float64 `json:"carbon"`
+}
+
+// DecisionAllocation is an allocation as recorded in a decision
+type DecisionAllocation struct {
+	Provider     Provider `json:"provider"`
+	Region       string   `json:"region"`
+	InstanceType string   `json:"instance_type"`
+	Count        int      `json:"count"`
+	Spot         bool     `json:"spot"`
+	PricePerHour float64  `json:"price_per_hour"`
+	TaskGroup    string   `json:"task_group,omitempty"`
+}
+
+// StrategyRejection is a constraint a strategy failed
+type StrategyRejection struct {
+	Reason string  `json:"reason"`
+	Value  float64 `json:"value,omitempty"` // Observed value, e.g. cost or reliability
+	Limit  float64 `json:"limit,omitempty"` // Constraint it was compared with
+}
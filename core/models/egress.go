@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Egress counter sources
+const (
+	EgressSourceIPTables = "iptables" // Bytes sent to addresses outside private networks
+	EgressSourceNetdev   = "netdev"   // Bytes sent on the node's interfaces, from /proc/net/dev
+)
+
+// EgressUsage is the data a job's nodes in one region sent out on one UTC
+// day, and what it cost
+type EgressUsage struct {
+	JobID    string    `json:"job_id"`
+	Day      time.Time `json:"day"`
+	Provider Provider  `json:"provider"`
+	Region   string    `json:"region"`
+	Bytes    int64     `json:"bytes"`
+	CostUSD  float64   `json:"cost_usd"`
+}
@@ -11,24 +11,79 @@ type JobEvent struct {
 	ToStatus   JobStatus
 	Reason     string
 	MetaJSON   map[string]interface{} // Additional metadata
+	ArtifactID *int64                 // Artifact the event links to, e.g. a failure's post-mortem bundle
 }
 
 // ArtifactType represents the type of job artifact
 type ArtifactType string
 
 const (
-	ArtifactTypeCheckpoint ArtifactType = "checkpoint"
-	ArtifactTypeLog        ArtifactType = "log"
-	ArtifactTypeOutput     ArtifactType = "output"
-	ArtifactTypeMetrics    ArtifactType = "metrics"
+	ArtifactTypeCheckpoint   ArtifactType = "checkpoint"
+	ArtifactTypeLog          ArtifactType = "log"
+	ArtifactTypeOutput       ArtifactType = "output"
+	ArtifactTypeMetrics      ArtifactType = "metrics"
+	ArtifactTypeBootstrap    ArtifactType = "bootstrap"     // Rendered node boot script, stored inline in meta
+	ArtifactTypeLaunchConfig ArtifactType = "launch_config" // Generated training script and redacted node environments
+	ArtifactTypeTopology     ArtifactType = "topology"      // Topology manifest at launch, in meta
+	ArtifactTypeConsole      ArtifactType = "console"       // Console session transcript (JSON lines)
+	ArtifactTypePostmortem   ArtifactType = "postmortem"    // Failure diagnostics bundle (tar.gz)
 )
 
 // JobArtifact represents a job artifact (checkpoint, log, output, etc.)
 type JobArtifact struct {
-	ID        int64
-	JobID     string
-	Type      ArtifactType
-	URI       string
-	CreatedAt time.Time
-	MetaJSON  map[string]interface{}
+	ID              int64
+	JobID           string
+	Type            ArtifactType
+	URI             string
+	CreatedAt       time.Time
+	MetaJSON        map[string]interface{}
+	Pinned          bool       // Never garbage collected
+	DeletedAt       *time.Time // Set once the underlying objects were deleted by retention GC
+	ReclaimedBytes  int64
+	ReplicaStatus   string // Checkpoints of jobs with checkpointing; empty until the replicator saw it
+	ReplicaAttempts int
+}
+
+// RetentionPolicy controls garbage collection of a completed job's checkpoints:
+// the newest KeepLast and pinned checkpoints are kept, the rest are deleted
+// once they are older than MaxAgeDays. Nil fields fall back to the global default.
+type RetentionPolicy struct {
+	KeepLast   *int `json:"keep_last,omitempty"`
+	MaxAgeDays *int `json:"max_age_days,omitempty"`
+}
+
+// Resolve returns the policy with unset fields taken from defaults
+func (p *RetentionPolicy) Resolve(defaults RetentionPolicy) RetentionPolicy {
+	resolved := defaults
+	if p == nil {
+		return resolved
+	}
+	if p.KeepLast != nil {
+		resolved.KeepLast = p.KeepLast
+	}
+	if p.MaxAgeDays != nil {
+		resolved.MaxAgeDays = p.MaxAgeDays
+	}
+	return resolved
+}
+
+// ArtifactGCRun is the report of one retention GC pass
+type ArtifactGCRun struct {
+	ID             int64              `json:"id"`
+	StartedAt      time.Time          `json:"started_at"`
+	FinishedAt     *time.Time         `json:"finished_at"`
+	DryRun         bool               `json:"dry_run"`
+	JobsScanned    int                `json:"jobs_scanned"`
+	BytesReclaimed int64              `json:"bytes_reclaimed"`
+	Deletions      []ArtifactDeletion `json:"deletions"`
+}
+
+// ArtifactDeletion is one artifact selected by retention GC
+type ArtifactDeletion struct {
+	JobID      string    `json:"job_id"`
+	ArtifactID int64     `json:"artifact_id"`
+	URI        string    `json:"uri"`
+	CreatedAt  time.Time `json:"created_at"`
+	Bytes      int64     `json:"bytes"`           // Reclaimed (or reclaimable in a dry run)
+	Error      string    `json:"error,omitempty"` // Deletion failed; the artifact is kept
 }
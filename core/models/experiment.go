@@ -0,0 +1,89 @@
+package models
+
+import "time"
+
+// Experiment groups related jobs, such as the runs of a hyperparameter sweep
+type Experiment struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+// ExperimentMember is a job of an experiment with its run time and cost
+type ExperimentMember struct {
+	JobID      string     `json:"job_id"`
+	Name       string     `json:"name"`
+	Status     JobStatus  `json:"status"`
+	GPUs       int        `json:"gpus"`
+	CostUSD    float64    `json:"cost_usd"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`  // First transition to running
+	FinishedAt *time.Time `json:"finished_at,omitempty"` // First terminal transition
+	Link       string     `json:"link"`
+}
+
+// ExperimentJobRef names the job behind a best or worst duration
+type ExperimentJobRef struct {
+	JobID           string  `json:"job_id"`
+	DurationSeconds float64 `json:"duration_seconds"`
+}
+
+// ExperimentSummary aggregates an experiment's member jobs
+type ExperimentSummary struct {
+	Experiment
+	Jobs          int                `json:"jobs"`
+	StatusCounts  map[JobStatus]int  `json:"status_counts"`
+	TotalCostUSD  float64            `json:"total_cost_usd"`
+	GPUHours      float64            `json:"gpu_hours"`
+	BestDuration  *ExperimentJobRef  `json:"best_duration,omitempty"`  // Shortest completed run
+	WorstDuration *ExperimentJobRef  `json:"worst_duration,omitempty"` // Longest completed run
+	Members       []ExperimentMember `json:"members"`
+}
+
+// SummarizeExperiment aggregates member jobs. Running jobs count GPU-hours
+// up to now; durations only compare completed jobs.
+func SummarizeExperiment(experiment Experiment, members []ExperimentMember, now time.Time) ExperimentSummary {
+	summary := ExperimentSummary{
+		Experiment:   experiment,
+		Jobs:         len(members),
+		StatusCounts: make(map[JobStatus]int),
+		Members:      members,
+	}
+	if summary.Members == nil {
+		summary.Members = []ExperimentMember{}
+	}
+
+	for _, member := range members {
+		summary.StatusCounts[member.Status]++
+		summary.TotalCostUSD += member.CostUSD
+		if member.StartedAt == nil {
+			continue
+		}
+		end := now
+		if member.FinishedAt != nil {
+			end = *member.FinishedAt
+		}
+		summary.GPUHours += float64(member.GPUs) * end.Sub(*member.StartedAt).Hours()
+
+		if member.Status != JobStatusCompleted || member.FinishedAt == nil {
+			continue
+		}
+		ref := &ExperimentJobRef{JobID: member.JobID, DurationSeconds: end.Sub(*member.StartedAt).Seconds()}
+		if summary.BestDuration == nil || ref.DurationSeconds < summary.BestDuration.DurationSeconds {
+			summary.BestDuration = ref
+		}
+		if summary.WorstDuration == nil || ref.DurationSeconds > summary.WorstDuration.DurationSeconds {
+			summary.WorstDuration = ref
+		}
+	}
+	return summary
+}
+
+// ExperimentCost is an experiment's cost rollup for the dashboard
+type ExperimentCost struct {
+	ID      string  `json:"id"`
+	Name    string  `json:"name"`
+	Jobs    int     `json:"jobs"`
+	CostUSD float64 `json:"cost_usd"`
+}
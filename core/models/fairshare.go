@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// TeamShare is a team's configured fair share and its recent GPU-hour usage
+type TeamShare struct {
+	TeamID     string  `json:"team_id"`
+	Weight     float64 `json:"weight"`     // Configured share weight
+	Share      float64 `json:"share"`      // Weight as a fraction of all weights
+	GPUHours   float64 `json:"gpu_hours"`  // Consumed within the window
+	Usage      float64 `json:"usage"`      // GPU-hours as a fraction of all teams with a share
+	Ratio      float64 `json:"ratio"`      // Usage / share; below 1 is under-served
+	Adjustment float64 `json:"adjustment"` // Capped log2(ratio); negative moves the team's jobs ahead
+}
+
+// FairShareReport is the fair-share state the scheduler orders jobs by
+type FairShareReport struct {
+	WindowHours     float64     `json:"window_hours"`
+	MaxAdjustment   float64     `json:"max_adjustment"`
+	MaxDelayMinutes float64     `json:"max_delay_minutes"` // Jobs pending longer are never held back
+	RefreshedAt     time.Time   `json:"refreshed_at"`
+	Teams           []TeamShare `json:"teams"`
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MIGSlices is the number of compute slices a MIG-capable GPU is divided into
+const MIGSlices = 7
+
+// GPUModel describes a physical GPU model for sharing decisions
+type GPUModel struct {
+	Name        string // Catalog name, e.g. "A100-80"
+	Family      string // GPUType as reported by providers, e.g. "A100"
+	MemoryGB    int
+	MIGProfiles []MIGProfile // Empty when the model does not support MIG
+}
+
+// MIGProfile is a MIG partition profile, e.g. "3g.40gb"
+type MIGProfile struct {
+	Name         string
+	Slices       int // Compute slices out of MIGSlices
+	MemoryGB     int
+	MaxInstances int // Instances of this profile that fit on one GPU
+}
+
+// Fraction returns the share of the GPU's compute the profile provides
+func (p MIGProfile) Fraction() float64 {
+	return float64(p.Slices) / MIGSlices
+}
+
+// SupportsMIG reports whether the GPU model can be MIG-partitioned
+func (m GPUModel) SupportsMIG() bool {
+	return len(m.MIGProfiles) > 0
+}
+
+// MIGProfile returns the named MIG profile of the model
+func (m GPUModel) MIGProfile(name string) (MIGProfile, bool) {
+	for _, profile := range m.MIGProfiles {
+		if profile.Name == name {
+			return profile, true
+		}
+	}
+	return MIGProfile{}, false
+}
+
+// MIGProfileNames returns the names of the model's MIG profiles
+func (m GPUModel) MIGProfileNames() []string {
+	names := make([]string, len(m.MIGProfiles))
+	for i, profile := range m.MIGProfiles {
+		names[i] = profile.Name
+	}
+	return names
+}
+
+// gpuModels is the GPU catalog. Families with several memory sizes list one
+// entry per size.
+var gpuModels = []GPUModel{
+	{Name: "T4", Family: "T4", MemoryGB: 16},
+	{Name: "K80", Family: "K80", MemoryGB: 12},
+	{Name: "V100-16", Family: "V100", MemoryGB: 16},
+	{Name: "V100-32", Family: "V100", MemoryGB: 32},
+	{Name: "A10", Family: "A10", MemoryGB: 24},
+	{Name: "A10G", Family: "A10G", MemoryGB: 24},
+	{Name: "L4", Family: "L4", MemoryGB: 24},
+	{Name: "L40S", Family: "L40S", MemoryGB: 48},
+	{Name: "A30", Family: "A30", MemoryGB: 24, MIGProfiles: []MIGProfile{
+		{Name: "1g.6gb", Slices: 1, MemoryGB: 6, MaxInstances: 4},
+		{Name: "2g.12gb", Slices: 2, MemoryGB: 12, MaxInstances: 2},
+		{Name: "4g.24gb", Slices: 4, MemoryGB: 24, MaxInstances: 1},
+	}},
+	{Name: "A100-40", Family: "A100", MemoryGB: 40, MIGProfiles: []MIGProfile{
+		{Name: "1g.5gb", Slices: 1, MemoryGB: 5, MaxInstances: 7},
+		{Name: "2g.10gb", Slices: 2, MemoryGB: 10, MaxInstances: 3},
+		{Name: "3g.20gb", Slices: 3, MemoryGB: 20, MaxInstances: 2},
+		{Name: "4g.20gb", Slices: 4, MemoryGB: 20, MaxInstances: 1},
+		{Name: "7g.40gb", Slices: 7, MemoryGB: 40, MaxInstances: 1},
+	}},
+	{Name: "A100-80", Family: "A100", MemoryGB: 80, MIGProfiles: []MIGProfile{
+		{Name: "1g.10gb", Slices: 1, MemoryGB: 10, MaxInstances: 7},
+		{Name: "2g.20gb", Slices: 2, MemoryGB: 20, MaxInstances: 3},
+		{Name: "3g.40gb", Slices: 3, MemoryGB: 40, MaxInstances: 2},
+		{Name: "4g.40gb", Slices: 4, MemoryGB: 40, MaxInstances: 1},
+		{Name: "7g.80gb", Slices: 7, MemoryGB: 80, MaxInstances: 1},
+	}},
+	{Name: "H100", Family: "H100", MemoryGB: 80, MIGProfiles: []MIGProfile{
+		{Name: "1g.10gb", Slices: 1, MemoryGB: 10, MaxInstances: 7},
+		{Name: "1g.20gb", Slices: 1, MemoryGB: 20, MaxInstances: 4},
+		{Name: "2g.20gb", Slices: 2, MemoryGB: 20, MaxInstances: 3},
+		{Name: "3g.40gb", Slices: 3, MemoryGB: 40, MaxInstances: 2},
+		{Name: "4g.40gb", Slices: 4, MemoryGB: 40, MaxInstances: 1},
+		{Name: "7g.80gb", Slices: 7, MemoryGB: 80, MaxInstances: 1},
+	}},
+}
+
+// ResolveGPUModel finds the catalog entry for a GPU type (a family such as
+// "A100" or a catalog name such as "A100-40") with memoryGB per GPU. Within a
+// family the entry with exactly that memory wins; otherwise the largest entry
+// not above it, or the largest entry when memoryGB is unknown (0).
+func ResolveGPUModel(gpuType string, memoryGB int) (GPUModel, error) {
+	var best *GPUModel
+	for i := range gpuModels {
+		model := &gpuModels[i]
+		if strings.EqualFold(model.Name, gpuType) {
+			return *model, nil
+		}
+		if !strings.EqualFold(model.Family, gpuType) {
+			continue
+		}
+		if model.MemoryGB == memoryGB {
+			return *model, nil
+		}
+		if memoryGB > 0 && model.MemoryGB > memoryGB {
+			continue
+		}
+		if best == nil || model.MemoryGB > best.MemoryGB {
+			best = model
+		}
+	}
+	if best == nil {
+		return GPUModel{}, fmt.Errorf("unknown GPU model %q with %dGB", gpuType, memoryGB)
+	}
+	return *best, nil
+}
@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+// GPUQuotaScope is what a GPU-hour quota limits
+type GPUQuotaScope string
+
+const (
+	GPUQuotaTeam    GPUQuotaScope = "team"    // Jobs with the quota's team_id
+	GPUQuotaProject GPUQuotaScope = "project" // Jobs with the quota's project_id
+)
+
+// GPUQuotaCarryOver is what happens to hours left at the end of a month
+type GPUQuotaCarryOver string
+
+const (
+	CarryOverNone   GPUQuotaCarryOver = "none"   // Every month starts at the monthly limit
+	CarryOverUnused GPUQuotaCarryOver = "unused" // Unused hours are added to the next month
+)
+
+// GPUQuota is a monthly GPU-hour limit of a team or project
+type GPUQuota struct {
+	ID                int64             `json:"id"`
+	Scope             GPUQuotaScope     `json:"scope"`
+	ScopeID           string            `json:"scope_id"`
+	MonthlyHours      float64           `json:"monthly_hours"`
+	CarryOver         GPUQuotaCarryOver `json:"carry_over"`
+	CarryOverMaxHours float64           `json:"carry_over_max_hours,omitempty"` // 0 = no cap
+	CreatedAt         time.Time         `json:"created_at"`
+	UpdatedAt         time.Time         `json:"updated_at"`
+}
+
+// GPUQuotaPeriod is one month of a quota
+type GPUQuotaPeriod struct {
+	QuotaID       int64      `json:"quota_id"`
+	Start         time.Time  `json:"period_start"`
+	CarriedHours  float64    `json:"carried_hours"`            // Carried over from the previous month
+	ConsumedHours *float64   `json:"consumed_hours,omitempty"` // Recorded when the month is closed
+	ClosedAt      *time.Time `json:"closed_at,omitempty"`
+}
+
+// GPUQuotaAdjustment is an admin change of a month's limit
+type GPUQuotaAdjustment struct {
+	ID          int64     `json:"id"`
+	QuotaID     int64     `json:"quota_id"`
+	PeriodStart time.Time `json:"period_start"`
+	Hours       float64   `json:"hours"` // Negative takes hours away
+	Reason      string    `json:"reason"`
+	Actor       string    `json:"actor"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// GPUHourReservation is the estimated GPU-hours a scheduled job holds
+// against a quota until it consumes them or ends
+type GPUHourReservation struct {
+	JobID       string    `json:"job_id"`
+	QuotaID     int64     `json:"quota_id"`
+	PeriodStart time.Time `json:"period_start"`
+	Hours       float64   `json:"hours"`
+}
+
+// GPUQuotaUsage is a quota's current month: GPU-hours against the limit,
+// next to the dollars the same jobs cost
+type GPUQuotaUsage struct {
+	Quota           GPUQuota  `json:"quota"`
+	PeriodStart     time.Time `json:"period_start"`
+	PeriodEnd       time.Time `json:"period_end"`
+	LimitHours      float64   `json:"limit_hours"` // Monthly hours + carried + adjustments
+	CarriedHours    float64   `json:"carried_hours"`
+	AdjustmentHours float64   `json:"adjustment_hours"`
+	ConsumedHours   float64   `json:"consumed_hours"`
+	ReservedHours   float64   `json:"reserved_hours"` // Held by scheduled and running jobs beyond what they consumed
+	RemainingHours  float64   `json:"remaining_hours"`
+	CostUSD         float64   `json:"cost_usd"` // Compute cost of the consumed hours
+	Exhausted       bool      `json:"exhausted"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Kinds of data a team's data gravity is computed from
+const (
+	FootprintDataset    = "dataset"    // A dataset the team's jobs read
+	FootprintCheckpoint = "checkpoint" // A live checkpoint, in the region its job ran in
+	FootprintJob        = "job"        // A completed job, weighted by the data it staged
+)
+
+// DataFootprint is one piece of a team's data and where it lives
+type DataFootprint struct {
+	Kind     string
+	Provider Provider // Empty for datasets; resolved from URI
+	Region   string
+	URI      string  // Dataset URI
+	SizeGB   float64 // 0 = unknown
+	At       time.Time
+}
+
+// DataGravityProfile is where a team's data lives, weighted by size and
+// recency
+type DataGravityProfile struct {
+	TeamID       string          `json:"team_id"`
+	HalfLifeDays float64         `json:"half_life_days"` // Data this old counts half
+	Regions      []RegionGravity `json:"regions"`        // Highest score first
+	ComputedAt   time.Time       `json:"computed_at"`
+}
+
+// RegionGravity is a team's data in one region
+type RegionGravity struct {
+	Provider     Provider `json:"provider"`
+	Region       string   `json:"region"`
+	Score        float64  `json:"score"`       // Share of the team's recency-weighted data, 0-1
+	WeightedGB   float64  `json:"weighted_gb"` // Size after recency decay
+	DatasetGB    float64  `json:"dataset_gb"`
+	CheckpointGB float64  `json:"checkpoint_gb"`
+	JobGB        float64  `json:"job_gb"`
+	Datasets     int      `json:"datasets"`
+	Checkpoints  int      `json:"checkpoints"`
+	Jobs         int      `json:"jobs"`
+}
+
+// Scores returns the gravity score of every region, keyed by
+// "provider:region"
+func (p *DataGravityProfile) Scores() map[string]float64 {
+	if p == nil || len(p.Regions) == 0 {
+		return nil
+	}
+	scores := make(map[string]float64, len(p.Regions))
+	for _, region := range p.Regions {
+		scores[string(region.Provider)+":"+region.Region] = region.Score
+	}
+	return scores
+}
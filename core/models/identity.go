@@ -0,0 +1,84 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// DataAccessMode selects the cloud identity a job's instances run as
+type DataAccessMode string
+
+const (
+	// DataAccessDefault attaches the provider's default identity (e.g. the
+	// configured AWS instance profile)
+	DataAccessDefault DataAccessMode = ""
+	// DataAccessAuto creates an identity scoped to the job's dataset, entrypoint
+	// and output prefixes, deleted when the job ends
+	DataAccessAuto DataAccessMode = "auto"
+	// DataAccessRoleARN attaches an existing AWS instance profile
+	DataAccessRoleARN DataAccessMode = "role-arn"
+	// DataAccessServiceAccount attaches an existing GCP service account
+	DataAccessServiceAccount DataAccessMode = "service-account"
+)
+
+// DataAccess configures how a job's instances get cloud credentials
+type DataAccess struct {
+	Mode      DataAccessMode `json:"mode"`
+	Identity  string         `json:"identity,omitempty"`   // Instance profile ARN or service account email
+	OutputURI string         `json:"output_uri,omitempty"` // Prefix the job writes checkpoints and outputs to
+}
+
+// UsesDefaultIdentity reports whether instances run as the provider default,
+// so they can be shared with other jobs (nil-safe)
+func (a *DataAccess) UsesDefaultIdentity() bool {
+	return a == nil || a.Mode == DataAccessDefault
+}
+
+// ParseDataAccessMode validates a data.access value
+func ParseDataAccessMode(mode string) (DataAccessMode, error) {
+	switch DataAccessMode(mode) {
+	case DataAccessDefault, DataAccessAuto, DataAccessRoleARN, DataAccessServiceAccount:
+		return DataAccessMode(mode), nil
+	}
+	return "", fmt.Errorf("invalid data access %q (want auto, role-arn or service-account)", mode)
+}
+
+// Grants returns the storage access a job-scoped identity needs: read on the
+// entrypoint and dataset, write on the output prefix
+func (j *Job) Grants() []StorageGrant {
+	var grants []StorageGrant
+	for _, uri := range []string{j.EntrypointURI, j.DatasetURI} {
+		if uri != "" {
+			grants = append(grants, StorageGrant{URI: uri})
+		}
+	}
+	if j.DataAccess != nil && j.DataAccess.OutputURI != "" {
+		grants = append(grants, StorageGrant{URI: j.DataAccess.OutputURI, Write: true})
+	}
+	return grants
+}
+
+// StorageGrant is access to one object or prefix
+type StorageGrant struct {
+	URI   string `json:"uri"`
+	Write bool   `json:"write,omitempty"` // Read and write; false = read only
+}
+
+// IdentityKind is the kind of cloud identity attached to instances
+type IdentityKind string
+
+const (
+	IdentityInstanceProfile IdentityKind = "instance_profile" // AWS
+	IdentityServiceAccount  IdentityKind = "service_account"  // GCP
+)
+
+// JobIdentity is a cloud identity attached to a job's instances, kept for audit
+type JobIdentity struct {
+	Provider   Provider       `json:"provider"`
+	Kind       IdentityKind   `json:"kind"`
+	Name       string         `json:"name"`             // Instance profile name/ARN or service account email
+	Managed    bool           `json:"managed"`          // Created for the job and deleted when it ends
+	Grants     []StorageGrant `json:"grants,omitempty"` // Access granted to a managed identity
+	AttachedAt time.Time      `json:"attached_at"`
+	DeletedAt  *time.Time     `json:"deleted_at,omitempty"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// InstanceTypeRuleChange is an admin change to the org-wide instance type
+// allow and deny lists, kept for auditing
+type InstanceTypeRuleChange struct {
+	ID            int64     `json:"id"`
+	Allow         []string  `json:"allow"`
+	Deny          []string  `json:"deny"`
+	PreviousAllow []string  `json:"previous_allow"`
+	PreviousDeny  []string  `json:"previous_deny"`
+	Reason        string    `json:"reason,omitempty"`
+	RemoteAddr    string    `json:"remote_addr,omitempty"`
+	ChangedAt     time.Time `json:"changed_at"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// SpotInterruption is a spot instance the provider reclaimed while a job ran
+type SpotInterruption struct {
+	ID             int64     `json:"id"`
+	JobID          string    `json:"job_id,omitempty"`
+	Provider       Provider  `json:"provider"`
+	InstanceType   string    `json:"instance_type"`
+	Region         string    `json:"region"`
+	Zone           string    `json:"zone,omitempty"`
+	InstanceID     string    `json:"instance_id,omitempty"`
+	InterruptedAt  time.Time `json:"interrupted_at"`
+	RuntimeSeconds int64     `json:"runtime_seconds"` // Job runtime when the instance was reclaimed
+}
+
+// SpotExposure is a run of spot instances, the denominator of interruption rates
+type SpotExposure struct {
+	Provider     Provider
+	InstanceType string
+	Region       string
+	Count        int
+	Start        time.Time
+	End          time.Time // Now for jobs still running
+}
+
+// InterruptionRate is the spot interruption rate of an instance type in a
+// region: observed interruptions per instance-hour, blended with the static
+// estimate by how much history there is
+type InterruptionRate struct {
+	Provider      Provider `json:"provider"`
+	InstanceType  string   `json:"instance_type"`
+	Region        string   `json:"region"`
+	TimeOfDay     string   `json:"time_of_day,omitempty"` // UTC bucket, e.g. "06-12"; empty = all day
+	Interruptions int      `json:"interruptions"`
+	InstanceHours float64  `json:"instance_hours"`
+	ObservedRate  float64  `json:"observed_rate"` // Per instance-hour; 0 without exposure
+	StaticRate    float64  `json:"static_rate"`   // Estimate the blend falls back to
+	Confidence    float64  `json:"confidence"`    // Weight of the observed rate, 0-1
+	Rate          float64  `json:"rate"`          // Blended rate used for scoring
+}
@@ -1,33 +1,120 @@
 package models
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 // Job represents a training job submitted to the platform
 type Job struct {
-	ID               string
-	UserID           string
-	Name             string
-	TeamID           string // For cost attribution (like Run:AI/Cast AI)
-	ProjectID        string // For cost attribution (like Run:AI/Cast AI)
-	JobType          JobType
-	Framework        string // "pytorch_ddp", "horovod", "tensorflow_multiworker"
-	EntrypointURI    string // S3/MinIO path or git repo (s3:// or minio:// for MVP)
-	DatasetURI       string // Dataset location
-	Requirements     JobRequirements
-	Constraints      JobConstraints
-	Status           JobStatus
-	SelectedProvider *Provider
-	SelectedRegion   string
-	SelectedBackend  BackendType
-	ClusterVPC       string
-	ClusterID        *string
-	CreatedAt        time.Time
-	StartedAt        *time.Time
-	CompletedAt      *time.Time
-	UpdatedAt        time.Time
-	CostRunningUSD   float64
-	CostEstimatedUSD *float64
-	SpecYAML         string // Original spec for replay/debug
+	ID                 string
+	UserID             string
+	Name               string
+	TeamID             string // For cost attribution (like Run:AI/Cast AI)
+	ProjectID          string // For cost attribution (like Run:AI/Cast AI)
+	JobType            JobType
+	Framework          string // "pytorch_ddp", "horovod", "tensorflow_multiworker"
+	EntrypointURI      string // S3/MinIO path or git repo (s3:// or minio:// for MVP)
+	DatasetURI         string // Dataset location
+	Requirements       JobRequirements
+	Constraints        JobConstraints
+	Network            NetworkOverrides
+	Status             JobStatus
+	SelectedProvider   *Provider
+	SelectedRegion     string
+	SelectedBackend    BackendType
+	ClusterVPC         string
+	ClusterID          *string
+	CreatedAt          time.Time
+	StartedAt          *time.Time
+	CompletedAt        *time.Time
+	UpdatedAt          time.Time
+	CostRunningUSD     float64
+	CostEstimatedUSD   *float64
+	CostTransferUSD    float64 // Data transfer charged to the job, e.g. checkpoint replication
+	CostEgressUSD      float64 // Network egress of the job's nodes, see EgressBytes
+	EgressBytes        int64   // Bytes the job's nodes sent out of the provider's network
+	EmissionsGCO2e     *float64
+	SpecYAML           string  // Original spec for replay/debug; resolved when it extends a base (see SpecBases, nearest first)
+	SpecHash           string  // Canonical hash of the parsed spec, for duplicate detection
+	ClonedFrom         *string // Source job ID when created via clone
+	SpecBases          []SpecBase
+	Labels             map[string]string
+	Experiment         string     // Experiment name or ID from the spec or request; resolved on submit
+	ExperimentID       string     // Experiment the job belongs to; "" = none
+	BatchID            string     // Bulk submission that created the job; "" = submitted on its own
+	PriorityBoost      int        // Operator boost; higher is scheduled first, cleared once scheduled
+	SuspendRequestedAt *time.Time // Last suspension; kept while suspended, cleared when resumed
+	ScheduleAttempts   int        // Failed scheduling attempts since last placed or since its constraints changed
+	NextScheduleAt     *time.Time // Backoff: the scheduler leaves the pending job alone until then
+	SpecWarnings       []string   // Parse warnings such as ignored unknown fields; recorded as an event, not stored
+	SpecNotes          []SpecNote // How parsing resolved the spec: defaults, detected values, deprecated fields; not stored
+
+	// Interactive sessions (JobTypeInteractive only)
+	Session          *SessionConfig
+	SessionEndpoint  *SessionEndpoint // Set once the session is reachable
+	SessionExpiresAt *time.Time       // Teardown time; moves forward on extend
+
+	ArtifactRetention *RetentionPolicy // Overrides the global checkpoint retention; nil = default
+	Bootstrap         *BootstrapConfig // Extra node boot steps, merged after the org default
+	TaskPolicy        *TaskPolicy      // Retries and aggregation of multi_task jobs
+	DatasetVerify     *DatasetVerify   // Pre-flight dataset check; nil = not verified
+	DataAccess        *DataAccess      // Instance identity and output prefix; nil = provider default
+	DatasetDownload   *DatasetDownload // Stage the dataset on the nodes before training; nil = not staged
+	ExportTopology    bool             // Write the topology manifest to the nodes and record it as an artifact
+	PlacementSpread   PlacementSpread  // How multi_task instances spread across zones; empty = cheapest zone per task
+	FrameworkConfig   *FrameworkConfig // Framework launch settings; nil = framework defaults
+
+	TotalSteps int64             // Planned training steps (training.total_steps); 0 = unknown
+	Progress   *TrainingProgress // Latest step telemetry; nil until the job reports
+
+	// Declared checkpoint cadence (checkpointing); nil = left to the training code
+	Checkpointing *CheckpointSchedule
+
+	// Per-status stuck thresholds overriding the sweeper defaults (scheduled,
+	// provisioning, checkpointing)
+	StuckAfter map[JobStatus]time.Duration
+}
+
+// Spec note kinds
+const (
+	SpecNoteDefault    = "default"    // Field not set; the default applies
+	SpecNoteDetected   = "detected"   // Field not set; derived from other fields
+	SpecNoteDeprecated = "deprecated" // Field set but superseded
+	SpecNoteParsed     = "parsed"     // Field set but read differently than it may look
+)
+
+// SpecNote explains how one spec field was resolved when it was parsed
+type SpecNote struct {
+	Kind    string `json:"kind"`
+	Field   string `json:"field"`           // Spec path, e.g. "execution.mode"
+	Value   string `json:"value,omitempty"` // Resolved value
+	Message string `json:"message"`
+}
+
+// DatasetVerifyMode controls how much of a dataset is checked before provisioning
+type DatasetVerifyMode string
+
+const (
+	// DatasetVerifyFull lists the whole prefix and checks every manifest entry
+	DatasetVerifyFull DatasetVerifyMode = "full"
+	// DatasetVerifySample lists a bounded sample and stats a sample of manifest entries
+	DatasetVerifySample DatasetVerifyMode = "sample"
+	// DatasetVerifySize only checks the prefix is non-empty and records its size
+	DatasetVerifySize DatasetVerifyMode = "size"
+)
+
+// DatasetVerify configures the pre-flight dataset check
+type DatasetVerify struct {
+	Mode        DatasetVerifyMode `json:"mode"`
+	ManifestURI string            `json:"manifest_uri,omitempty"` // JSON file list with sizes/checksums
+}
+
+// DatasetDownload stages the dataset onto the cluster before the training
+// launch. Without a shared mount every node downloads its own copy.
+type DatasetDownload struct {
+	SharedMount string `json:"shared_mount,omitempty"` // Shared filesystem (EFS, Filestore) mounted on every node; downloaded once
 }
 
 // JobType represents the type of job
@@ -38,23 +125,93 @@ const (
 	JobTypeHPO       JobType = "hpo"
 	JobTypeInference JobType = "inference"
 	JobTypeEval      JobType = "eval"
+
+	// JobTypeInteractive provisions a dev box with SSH/Jupyter access instead of running a script
+	JobTypeInteractive JobType = "interactive"
 )
 
+// BootstrapConfig adds validated steps to the node boot script: files written
+// to allow-listed paths and commands run as an unprivileged user
+type BootstrapConfig struct {
+	WriteFiles     []BootstrapFile   `json:"write_files,omitempty"`
+	Run            []string          `json:"run,omitempty"`
+	EnvFromSecrets map[string]string `json:"env_from_secrets,omitempty"` // Env var -> secret name, resolved on the node for Run
+}
+
+// BootstrapFile is a file written at boot
+type BootstrapFile struct {
+	Path        string `json:"path"`
+	Content     string `json:"content"`
+	Permissions string `json:"permissions,omitempty"` // Octal, default 0644
+}
+
+// SessionConfig configures an interactive session
+type SessionConfig struct {
+	MaxRuntime time.Duration `json:"max_runtime"` // Session TTL, enforced strictly
+	Jupyter    bool          `json:"jupyter"`     // Start a Jupyter server (false = SSH only)
+	IdleStop   bool          `json:"idle_stop"`   // Stop the session when GPUs stay idle
+}
+
+// SessionEndpoint is how a user connects to a running interactive session
+type SessionEndpoint struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol"`        // jupyter | ssh
+	Token    string `json:"token,omitempty"` // Jupyter access token
+	URL      string `json:"url,omitempty"`
+}
+
 // JobRequirements specifies the resource requirements for a job
 type JobRequirements struct {
-	GPUs              int
-	GPUFraction       float64 // 0.0 - 1.0 (for fractional GPUs, like Run:AI) - MVP: always 1.0
-	UseMIG            bool    // Enable MIG partitioning (like Run:AI/Cast AI) - MVP: false
-	MIGProfile        string  // e.g., "1g.10gb" (for MIG-capable GPUs like A100)
-	MaxGPUsPerNode    int     // Max GPUs per instance (for multi-node training)
-	RequiresMultiNode bool    // Whether job requires multiple nodes
-	GPUMemory         int     // GB per GPU
-	CPUMemory         int     // GB per instance
-	Storage           int     // GB
-	EstimatedHours    float64
-	Framework         string
-	ExecutionMode     ExecutionMode // ModeSingleCluster or ModeMultiTask
-	DatasetLocation   string        // URI (s3://, gs://, az://, minio://)
+	GPUs                 int
+	GPUFraction          float64  // 0.0 - 1.0 (for fractional GPUs, like Run:AI) - MVP: always 1.0
+	UseMIG               bool     // Enable MIG partitioning (like Run:AI/Cast AI) - MVP: false
+	MIGProfile           string   // e.g., "1g.10gb" (for MIG-capable GPUs like A100)
+	MaxGPUsPerNode       int      // Max GPUs per instance (for multi-node training)
+	RequiresMultiNode    bool     // Whether job requires multiple nodes
+	MinInterconnectGbps  float64  // Least node-to-node bandwidth of multi-node placements (resources.min_interconnect_gbps); 0 = default
+	GPUMemory            int      // GB per GPU
+	CPUMemory            int      // GB per instance
+	Storage              int      // Data volume GB per instance (resources.storage); 0 = none
+	StorageIOPS          int      // Provisioned data volume IOPS; 0 = volume baseline
+	StorageThroughput    int      // Provisioned data volume throughput in MB/s; 0 = volume baseline
+	InstanceTypes        []string // Allowed instance/GPU type globs (resources.instance_types); empty = any
+	ExcludeInstanceTypes []string // Denied instance/GPU type globs (resources.exclude_instance_types)
+	EstimatedHours       float64
+	Framework            string
+	ExecutionMode        ExecutionMode  // ModeSingleCluster or ModeMultiTask
+	DatasetLocation      string         // URI (s3://, gs://, az://, minio://)
+	DatasetSizeGB        float64        // Measured by dataset verification; 0 = unknown
+	Elastic              *ElasticConfig // Node bounds for horovod_elastic jobs (nil = fixed size)
+	Metric               TrainingMetric // What the cost term optimizes (training.metric); "" = hours
+	ModelClass           string         // Benchmark model class (training.model_class), e.g. llama
+	RegionSpread         *RegionSpread  // How a multi_task job's tasks spread over regions (execution.spread); nil = defaults
+	TaskGroups           []TaskGroup    // Task shapes of a multi_task job planned independently (execution.task_groups); empty = one shape
+	GPUsPerTask          int            // GPUs each multi_task task needs; 0 = 1. Set per task group, not parsed from resources
+	Image                string         // Registered machine image the job pins (resources.image); "" = the family's preferred image
+	Managed              bool           // execution.backend managed: managed training entries compete with raw VMs
+
+	// Team's data gravity score by "provider:region"; set by the scheduler
+	// when the job may lean toward its team's data, not parsed from the spec
+	DataGravity map[string]float64
+
+	// Job declares a checkpoint schedule (checkpointing), so an interruption
+	// only loses the work since the last checkpoint
+	Checkpointed bool
+}
+
+// TrainingMetric selects the unit the optimizer's cost term is measured in
+type TrainingMetric string
+
+const (
+	MetricHours  TrainingMetric = "hours"  // Cost of estimated_hours on the allocation (default)
+	MetricTokens TrainingMetric = "tokens" // Cost per token from the GPU type's tokens-per-hour benchmark
+)
+
+// ElasticConfig bounds the cluster size of an elastic training job
+type ElasticConfig struct {
+	MinNodes int
+	MaxNodes int
 }
 
 // JobConstraints specifies constraints for job execution
@@ -63,10 +220,75 @@ type JobConstraints struct {
 	Deadline          *time.Time
 	PreferredRegions  []string
 	AllowSpot         bool
+	MaxSpotFraction   float64           // 0.0 - 1.0 share of nodes allowed on spot (1.0 = no limit)
+	OnDemandRanks     []int             // Ranks pinned to on-demand nodes (e.g. [0] keeps the master off spot)
 	MinReliability    float64           // 0.0 - 1.0
 	DataLocality      DataLocality      // prefer | required | ignore
-	PerformanceWeight float64           // 0.0 (cost only) to 1.0 (performance only)
+	PerformanceWeight float64           // 0.0 (cost only) to 1.0 (performance only); superseded by Weights
+	Weights           *ScoringWeights   // Explicit score term weights; nil derives them from PerformanceWeight
 	ReplicationPolicy ReplicationPolicy // none | pre-stage | on-demand-cache
+	MaxQueueTime      time.Duration     // Warn when pending longer than this; 0 = never
+	QueueCancelAfter  time.Duration     // Cancel when pending longer than this; 0 = never
+	AllowMigration    bool              // Let the migration advisor checkpoint and reschedule the job when cheaper
+	CarbonWeight      float64           // 0.0 - 1.0 weight of the emissions term, added to the other weights
+	IgnoreDataGravity bool              // constraints.data_gravity: false; no bonus toward the team's data regions
+	SpotBidStrategy   SpotBidStrategy   // Max price of spot requests; "" = market
+	SpotBidCap        float64           // Explicit low bid, USD per instance-hour; 0 = 1.1x current spot
+}
+
+// ScoringWeights weights the terms of the optimizer's strategy score. Each
+// weight is 0-1; they are normalized to sum to 1 before scoring.
+type ScoringWeights struct {
+	Cost        float64 `json:"cost"`        // Compute cost relative to the budget
+	Reliability float64 `json:"reliability"` // Expected interruption risk
+	Time        float64 `json:"time"`        // Run time relative to the deadline
+	Locality    float64 `json:"locality"`    // Data transfer cost relative to the budget
+	Carbon      float64 `json:"carbon"`      // Estimated emissions relative to the highest of the strategies
+}
+
+// LegacyScoringWeights maps the single performance_weight knob onto explicit
+// weights. Transfer cost used to count as cost and the reliability penalty
+// was fixed at 0.2, so legacy jobs keep their strategy ranking.
+func LegacyScoringWeights(performanceWeight float64) ScoringWeights {
+	return ScoringWeights{
+		Cost:        1.0 - performanceWeight,
+		Reliability: 0.2,
+		Time:        performanceWeight,
+		Locality:    1.0 - performanceWeight,
+	}
+}
+
+// Normalized returns the weights scaled to sum to 1 (unchanged if all zero)
+func (w ScoringWeights) Normalized() ScoringWeights {
+	sum := w.Cost + w.Reliability + w.Time + w.Locality + w.Carbon
+	if sum <= 0 {
+		return w
+	}
+	return ScoringWeights{
+		Cost:        w.Cost / sum,
+		Reliability: w.Reliability / sum,
+		Time:        w.Time / sum,
+		Locality:    w.Locality / sum,
+		Carbon:      w.Carbon / sum,
+	}
+}
+
+// ScoringWeights returns the normalized score weights of the constraints:
+// the explicit weights when set, otherwise the legacy mapping, with the
+// carbon weight added before normalizing
+func (c JobConstraints) ScoringWeights() ScoringWeights {
+	weights := LegacyScoringWeights(c.PerformanceWeight)
+	if c.Weights != nil {
+		weights = *c.Weights
+	}
+	weights.Carbon = c.CarbonWeight
+	return weights.Normalized()
+}
+
+// NetworkOverrides lets power users override the NCCL/fabric network profile
+type NetworkOverrides struct {
+	Profile string            `json:"profile,omitempty"` // Force a named profile instead of auto-selection
+	Env     map[string]string `json:"env,omitempty"`     // Merged on top of the profile environment
 }
 
 // JobStatus represents the current status of a job
@@ -78,11 +300,63 @@ const (
 	JobStatusProvisioning  JobStatus = "provisioning"
 	JobStatusRunning       JobStatus = "running"
 	JobStatusCheckpointing JobStatus = "checkpointing"
+	JobStatusCancelling    JobStatus = "cancelling" // Cancel requested; torn down after the grace window unless uncancelled
+	JobStatusSuspended     JobStatus = "suspended"  // Checkpointed and torn down on request; queued again when resumed
 	JobStatusCompleted     JobStatus = "completed"
 	JobStatusFailed        JobStatus = "failed"
 	JobStatusCancelled     JobStatus = "cancelled"
 )
 
+// ErrIllegalTransition is returned for job status changes the lifecycle does not allow
+var ErrIllegalTransition = errors.New("illegal job status transition")
+
+// jobTransitions is the job lifecycle: the statuses each status may move to.
+// Terminal statuses have none. Scheduled and provisioning jobs move back to
+// pending when the stuck-state sweeper re-enqueues them; checkpointing jobs
+// when the migration advisor reschedules them, or to suspended once a
+// suspension's checkpoint is written. Suspended jobs move to pending when
+// resumed. Cancelling jobs move back to running when the cancellation is
+// undone within its grace window.
+var jobTransitions = map[JobStatus][]JobStatus{
+	JobStatusPending:       {JobStatusScheduled, JobStatusFailed, JobStatusCancelled},
+	JobStatusScheduled:     {JobStatusProvisioning, JobStatusPending, JobStatusFailed, JobStatusCancelled},
+	JobStatusProvisioning:  {JobStatusRunning, JobStatusPending, JobStatusFailed, JobStatusCancelled},
+	JobStatusRunning:       {JobStatusCheckpointing, JobStatusCancelling, JobStatusCompleted, JobStatusFailed, JobStatusCancelled},
+	JobStatusCheckpointing: {JobStatusRunning, JobStatusPending, JobStatusSuspended, JobStatusCompleted, JobStatusFailed, JobStatusCancelled},
+	JobStatusSuspended:     {JobStatusPending, JobStatusCancelled},
+	JobStatusCancelling:    {JobStatusRunning, JobStatusCompleted, JobStatusFailed, JobStatusCancelled},
+}
+
+// Terminal reports whether the job will not change status again
+func (s JobStatus) Terminal() bool {
+	return s == JobStatusCompleted || s == JobStatusFailed || s == JobStatusCancelled
+}
+
+// IsRunning reports whether the job's workload is up: running, or cancelling
+// and still within its grace window
+func (s JobStatus) IsRunning() bool {
+	return s == JobStatusRunning || s == JobStatusCancelling
+}
+
+// CanTransitionTo reports whether the lifecycle allows moving from s to next
+func (s JobStatus) CanTransitionTo(next JobStatus) bool {
+	for _, allowed := range jobTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTransition returns an error wrapping ErrIllegalTransition if the
+// lifecycle does not allow moving from one status to the other
+func ValidateTransition(from, to JobStatus) error {
+	if !from.CanTransitionTo(to) {
+		return fmt.Errorf("%w: %s -> %s", ErrIllegalTransition, from, to)
+	}
+	return nil
+}
+
 // ExecutionMode determines how the job is executed
 type ExecutionMode string
 
@@ -91,6 +365,53 @@ const (
 	ModeMultiTask     ExecutionMode = "multi_task"
 )
 
+// FrameworkConfig holds framework specific launch settings (spec
+// framework_config block). DeepSpeed and JAX jobs take one.
+type FrameworkConfig struct {
+	ZeroStage   *int                   `json:"zero_stage,omitempty"`  // ZeRO stage 0-3 written into the ds_config; nil = the config's own
+	Accelerator string                 `json:"accelerator,omitempty"` // DS_ACCELERATOR; "" = cuda
+	ConfigURI   string                 `json:"config_uri,omitempty"`  // ds_config.json downloaded onto the master node
+	Config      map[string]interface{} `json:"config,omitempty"`      // Inline ds_config rendered to JSON
+	XLAFlags    []string               `json:"xla_flags,omitempty"`   // JAX: XLA_FLAGS of every process
+}
+
+// RegionSpread bounds how many regions a multi_task job's tasks spread over
+type RegionSpread struct {
+	MaxRegions        int `json:"max_regions,omitempty"`          // 0 = every candidate region
+	MinTasksPerRegion int `json:"min_tasks_per_region,omitempty"` // 0 = 1
+}
+
+// TaskGroup is one shape of a multi_task job's tasks. Each group is planned
+// on its own, so e.g. trainer and evaluation tasks can land on different
+// instance families and regions.
+type TaskGroup struct {
+	Name          string   `json:"name"`
+	Tasks         int      `json:"tasks"`
+	GPUsPerTask   int      `json:"gpus_per_task"`
+	GPUMemory     int      `json:"gpu_memory,omitempty"`     // GB per GPU; 0 = the job's resources.gpu_memory
+	InstanceTypes []string `json:"instance_types,omitempty"` // Allowed instance/GPU type globs; empty = the job's resources.instance_types
+}
+
+// GPUs returns the GPUs the group's tasks need in total
+func (g TaskGroup) GPUs() int {
+	return g.Tasks * g.GPUsPerTask
+}
+
+// RegionTasks is how many of a multi_task job's tasks a region runs
+type RegionTasks struct {
+	Provider Provider `json:"provider"`
+	Region   string   `json:"region"`
+	Tasks    int      `json:"tasks"`
+}
+
+// PlacementSpread specifies how a multi_task job's tasks spread out
+type PlacementSpread string
+
+const (
+	SpreadNone PlacementSpread = ""   // Every task picks its own cheapest zone
+	SpreadAZ   PlacementSpread = "az" // Tasks of a region land in distinct availability zones
+)
+
 // DataLocality specifies data locality requirements
 type DataLocality string
 
@@ -108,3 +429,17 @@ const (
 	ReplicationPreStage      ReplicationPolicy = "pre-stage"
 	ReplicationOnDemandCache ReplicationPolicy = "on-demand-cache"
 )
+
+// SpecBase is a base spec a job's spec was merged onto (extends)
+type SpecBase struct {
+	Ref     string `json:"ref"`     // Template name or URI
+	Version string `json:"version"` // Revision that was fetched, e.g. an ETag
+}
+
+// TeamCost is a team's cost and emissions rollup for the dashboard
+type TeamCost struct {
+	TeamID         string  `json:"team_id"` // Empty for jobs without a team
+	Jobs           int     `json:"jobs"`
+	CostUSD        float64 `json:"cost_usd"`
+	EmissionsGCO2e float64 `json:"emissions_gco2e"` // Accounted jobs only
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// ImageValidation is an operator's verdict on a registered machine image
+type ImageValidation string
+
+const (
+	ImageValidationPending   ImageValidation = "pending"   // Registered, not used yet
+	ImageValidationValidated ImageValidation = "validated" // Preferred for its family; jobs may pin it
+	ImageValidationFailed    ImageValidation = "failed"    // Never used
+)
+
+// MachineImage is a pre-baked image registered for one provider, region and
+// instance family. Instances booted from it skip the generic image's driver
+// and package installs, so their bootstrap is expected to stay within
+// BootBudget; an image whose observed boots exceed it is degraded.
+type MachineImage struct {
+	ID             int64           `json:"id"`
+	Provider       Provider        `json:"provider"`
+	Region         string          `json:"region"`
+	InstanceFamily string          `json:"instance_family"` // e.g. p4d, a2
+	ImageID        string          `json:"image_id"`        // Provider image, e.g. ami-0abc...
+	BuildDate      *time.Time      `json:"build_date,omitempty"`
+	Validation     ImageValidation `json:"validation"`
+	ValidatedAt    *time.Time      `json:"validated_at,omitempty"`
+	BootBudget     time.Duration   `json:"-"`
+	DegradedAt     *time.Time      `json:"degraded_at,omitempty"`
+	DegradedReason string          `json:"degraded_reason,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// Usable reports whether the image may boot instances: validated and, unless
+// a job pins it, not degraded
+func (m MachineImage) Usable(pinned bool) bool {
+	return m.Validation == ImageValidationValidated && (pinned || m.DegradedAt == nil)
+}
+
+// ImageBoot is one observed bootstrap of instances from a registered image
+type ImageBoot struct {
+	JobID        string    `json:"job_id,omitempty"`
+	InstanceType string    `json:"instance_type"`
+	BootSeconds  float64   `json:"boot_seconds"`
+	ObservedAt   time.Time `json:"observed_at"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// MaintenanceAction is what was done about scheduled maintenance
+type MaintenanceAction string
+
+const (
+	MaintenanceWarned   MaintenanceAction = "warned"   // Event and webhook only
+	MaintenanceMigrated MaintenanceAction = "migrated" // Checkpointed and requeued before the window
+)
+
+// MaintenanceEvent is provider-scheduled maintenance of a node of a running
+// job, e.g. an AWS system-reboot or an Azure Redeploy
+type MaintenanceEvent struct {
+	ID           int64             `json:"id"`
+	JobID        string            `json:"job_id"`
+	Provider     Provider          `json:"provider"`
+	Region       string            `json:"region"`
+	InstanceType string            `json:"instance_type"`
+	InstanceID   string            `json:"instance_id"`
+	EventType    string            `json:"event_type"`
+	Description  string            `json:"description,omitempty"`
+	NotBefore    time.Time         `json:"not_before"`
+	NotAfter     *time.Time        `json:"not_after,omitempty"`
+	DetectedAt   time.Time         `json:"detected_at"`
+	Action       MaintenanceAction `json:"action"`
+}
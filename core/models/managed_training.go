@@ -0,0 +1,74 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// managedTypePrefixes mark the pricing catalog entries of managed training
+// services, by provider: SageMaker's own ml.* instance types, and Vertex AI
+// machine types under vertex.*
+var managedTypePrefixes = map[Provider]string{
+	ProviderAWS: "ml.",
+	ProviderGCP: "vertex.",
+}
+
+// ManagedInstanceType returns the catalog entry of a provider's managed
+// training service for a raw instance type; "" when the provider has none
+func ManagedInstanceType(provider Provider, instanceType string) string {
+	prefix, ok := managedTypePrefixes[provider]
+	if !ok {
+		return ""
+	}
+	return prefix + instanceType
+}
+
+// IsManagedInstanceType reports whether a catalog entry is priced for the
+// provider's managed training service rather than raw VMs
+func IsManagedInstanceType(provider Provider, instanceType string) bool {
+	prefix, ok := managedTypePrefixes[provider]
+	return ok && strings.HasPrefix(instanceType, prefix)
+}
+
+// RawInstanceType returns the raw instance type behind a managed catalog entry
+func RawInstanceType(provider Provider, instanceType string) string {
+	return strings.TrimPrefix(instanceType, managedTypePrefixes[provider])
+}
+
+// Managed training run states, as mapped from the service
+const (
+	ManagedRunPending   = "pending"  // Queued, or the service is provisioning instances
+	ManagedRunRunning   = "running"  // Training
+	ManagedRunStopping  = "stopping" // Stop requested
+	ManagedRunCompleted = "completed"
+	ManagedRunFailed    = "failed"
+	ManagedRunStopped   = "stopped"
+)
+
+// ManagedRunEnded reports whether a run state is final
+func ManagedRunEnded(state string) bool {
+	return state == ManagedRunCompleted || state == ManagedRunFailed || state == ManagedRunStopped
+}
+
+// ManagedTrainingRun is a job handed to a provider's managed training
+// service, with the status and billable time the service last reported
+type ManagedTrainingRun struct {
+	ID              int64      `json:"id"`
+	JobID           string     `json:"job_id"`
+	Provider        Provider   `json:"provider"`
+	Region          string     `json:"region"`
+	ServiceJobID    string     `json:"service_job_id"` // SageMaker job name or Vertex CustomJob resource name
+	InstanceType    string     `json:"instance_type"`  // Managed catalog entry, e.g. ml.p4d.24xlarge
+	Count           int        `json:"count"`
+	Spot            bool       `json:"spot"`
+	OnDemandPrice   float64    `json:"on_demand_price"` // Per instance-hour at submission
+	SpotPrice       float64    `json:"spot_price,omitempty"`
+	Status          string     `json:"status"`
+	StatusDetail    string     `json:"status_detail,omitempty"`
+	BillableSeconds int64      `json:"billable_seconds"` // Per instance, as reported by the service
+	CostUSD         float64    `json:"cost_usd"`
+	SubmittedAt     time.Time  `json:"submitted_at"`
+	StartedAt       *time.Time `json:"started_at,omitempty"`
+	EndedAt         *time.Time `json:"ended_at,omitempty"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
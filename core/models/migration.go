@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// MigrationAction is what the migration advisor did about a job
+type MigrationAction string
+
+const (
+	MigrationRecommended MigrationAction = "recommended" // Event and webhook only
+	MigrationStarted     MigrationAction = "migrated"    // Checkpointed and rescheduled (constraints.allow_migration)
+)
+
+// MigrationTarget is one allocation of a placement compared by the advisor
+type MigrationTarget struct {
+	Provider     Provider `json:"provider"`
+	Region       string   `json:"region"`
+	InstanceType string   `json:"instance_type"`
+	Count        int      `json:"count"`
+	Spot         bool     `json:"spot"`
+	PricePerHour float64  `json:"price_per_hour"` // Per instance
+}
+
+// MigrationInputs are the inputs and result of one restart-elsewhere
+// calculation, recorded for audit
+type MigrationInputs struct {
+	CheckpointURI        string            `json:"checkpoint_uri"`
+	CheckpointAt         time.Time         `json:"checkpoint_at"`
+	CheckpointSizeGB     float64           `json:"checkpoint_size_gb"`
+	RemainingHours       float64           `json:"remaining_hours"`        // From the job's progress ETA
+	LostHours            float64           `json:"lost_hours"`             // Work since the checkpoint, redone after restart
+	RestartOverheadHours float64           `json:"restart_overhead_hours"` // Provisioning and restore
+	Current              []MigrationTarget `json:"current"`
+	CurrentHourlyCost    float64           `json:"current_hourly_cost"`
+	CurrentRemainingCost float64           `json:"current_remaining_cost"`
+	Alternative          []MigrationTarget `json:"alternative"`
+	AlternativeHourly    float64           `json:"alternative_hourly_cost"`
+	RestartCost          float64           `json:"restart_cost"` // (lost + overhead hours) at the alternative's rate
+	TransferCost         float64           `json:"transfer_cost"`
+	AlternativeCost      float64           `json:"alternative_remaining_cost"`
+	SavingsUSD           float64           `json:"savings_usd"` // Current remaining - (restart + transfer + alternative remaining)
+	MinSavingsUSD        float64           `json:"min_savings_usd"`
+}
+
+// MigrationAdvice is a recorded restart-elsewhere recommendation
+type MigrationAdvice struct {
+	ID        int64           `json:"id"`
+	JobID     string          `json:"job_id"`
+	Action    MigrationAction `json:"action"`
+	Inputs    MigrationInputs `json:"inputs"`
+	CreatedAt time.Time       `json:"created_at"`
+}
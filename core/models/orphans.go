@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Orphaned record categories found by the orphan cleaner
+const (
+	OrphanAllocationWithoutJob  = "allocation_without_job"  // Allocation whose job no longer exists
+	OrphanUnscheduledAllocation = "unscheduled_allocation"  // Planned allocation of a job that ended without starting
+	OrphanEventWithoutJob       = "event_without_job"       // Event whose job no longer exists
+	OrphanReservationWithoutJob = "reservation_without_job" // GPU-hour reservation whose job no longer exists
+	OrphanStaleEgressSample     = "stale_egress_sample"     // Egress counter sample of a job that ended before retention
+	OrphanCollectedArtifact     = "collected_artifact"      // Live artifact row whose URI retention GC already deleted
+)
+
+// OrphanCategories lists every category, in report order
+var OrphanCategories = []string{
+	OrphanAllocationWithoutJob,
+	OrphanUnscheduledAllocation,
+	OrphanEventWithoutJob,
+	OrphanReservationWithoutJob,
+	OrphanStaleEgressSample,
+	OrphanCollectedArtifact,
+}
+
+// OrphanCleanup is the report of one orphan cleaner pass. Without Apply the
+// pass only counts; Deleted stays empty.
+type OrphanCleanup struct {
+	StartedAt  time.Time           `json:"started_at"`
+	FinishedAt time.Time           `json:"finished_at"`
+	Apply      bool                `json:"apply"`
+	Cutoff     time.Time           `json:"cutoff"`            // Jobs that ended after this keep their records
+	Found      map[string]int64    `json:"found"`             // Orphans per category
+	Deleted    map[string]int64    `json:"deleted"`           // Orphans deleted per category
+	Samples    map[string][]string `json:"samples,omitempty"` // A few keys per category, e.g. allocation IDs
+	Errors     []string            `json:"errors,omitempty"`  // Categories that could not be counted or cleaned, and why
+}
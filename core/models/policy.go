@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// PolicyRuleType represents what a policy rule checks
+type PolicyRuleType string
+
+const (
+	// PolicyRequired requires Field to be set (non-empty, non-zero)
+	PolicyRequired PolicyRuleType = "required"
+	// PolicyBounds requires Field to be a number within Min/Max
+	PolicyBounds PolicyRuleType = "bounds"
+	// PolicyAllowedValues requires Field to be one of Values
+	PolicyAllowedValues PolicyRuleType = "allowed_values"
+	// PolicyConditional requires Then to hold whenever When holds
+	PolicyConditional PolicyRuleType = "conditional"
+)
+
+// PolicyCondition matches a job field. A condition with no bounds or values
+// only requires the field to be set.
+type PolicyCondition struct {
+	Field  string   `json:"field"`
+	Min    *float64 `json:"min,omitempty"`
+	Max    *float64 `json:"max,omitempty"`
+	Values []string `json:"values,omitempty"`
+}
+
+// PolicyRule is an org policy evaluated against every submitted job
+type PolicyRule struct {
+	ID        int64            `json:"-"`
+	Name      string           `json:"-"`
+	Type      PolicyRuleType   `json:"type"`
+	Check     PolicyCondition  `json:"check"`          // required, bounds, allowed_values
+	When      *PolicyCondition `json:"when,omitempty"` // conditional only
+	Then      *PolicyCondition `json:"then,omitempty"` // conditional only
+	Message   string           `json:"message,omitempty"`
+	Enabled   bool             `json:"-"`
+	CreatedAt time.Time        `json:"-"`
+}
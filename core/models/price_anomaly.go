@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Price kinds checked for anomalies
+const (
+	PriceKindOnDemand = "on_demand"
+	PriceKindSpot     = "spot"
+)
+
+// Price anomaly reasons
+const (
+	AnomalyDeviation = "deviation"   // Too far from the previously stored price
+	AnomalyOutOfBand = "out_of_band" // Outside the $/GPU-hour band of the GPU type
+)
+
+// PriceAnomalyStatus is the state of a quarantined price
+type PriceAnomalyStatus string
+
+const (
+	AnomalyQuarantined PriceAnomalyStatus = "quarantined" // Not stored; the last known good price is served
+	AnomalyAccepted    PriceAnomalyStatus = "accepted"    // Accepted by an admin and stored
+	AnomalyCleared     PriceAnomalyStatus = "cleared"     // A later refresh passed the checks
+)
+
+// PriceAnomaly is a refreshed price the sanity checks quarantined. One row is
+// kept per instance type, region and price kind with its latest observation.
+type PriceAnomaly struct {
+	ID            int64              `json:"id"`
+	Provider      Provider           `json:"provider"`
+	Region        string             `json:"region"`
+	InstanceType  string             `json:"instance_type"`
+	GPUType       string             `json:"gpu_type"`
+	Kind          string             `json:"kind"` // on_demand | spot
+	Price         float64            `json:"price"`
+	PreviousPrice float64            `json:"previous_price,omitempty"` // 0 when nothing was stored yet
+	Reason        string             `json:"reason"`
+	Status        PriceAnomalyStatus `json:"status"`
+	Occurrences   int                `json:"occurrences"` // Refreshes that returned an anomalous price
+	DetectedAt    time.Time          `json:"detected_at"`
+	LastSeenAt    time.Time          `json:"last_seen_at"`
+	ResolvedAt    *time.Time         `json:"resolved_at,omitempty"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// PricingRefreshStatus is the state of one provider's refresh of one price
+// kind. Every provider and kind refreshes on its own schedule.
+type PricingRefreshStatus struct {
+	Provider            Provider   `json:"provider"`
+	Kind                string     `json:"kind"` // on_demand | spot
+	IntervalSeconds     float64    `json:"interval_seconds"`
+	Running             bool       `json:"running"` // A refresh is in progress
+	LastStartedAt       *time.Time `json:"last_started_at,omitempty"`
+	LastSuccessAt       *time.Time `json:"last_success_at,omitempty"`
+	LastDurationMs      int64      `json:"last_duration_ms"`
+	LastError           string     `json:"last_error,omitempty"` // Error of the last refresh; cleared by a success
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	Prices              int        `json:"prices"` // Prices stored by the last successful refresh
+}
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// Pricing subscription conditions
+const (
+	// PriceConditionBelow holds while the price is under Threshold USD per GPU-hour
+	PriceConditionBelow = "below"
+	// PriceConditionDrop holds while the price is at least Threshold percent
+	// under its peak within Window
+	PriceConditionDrop = "drop"
+)
+
+// PricingSubscription asks to be notified when prices of instance or GPU
+// types meet a condition. It matches an instance type listed in
+// InstanceTypes or of a GPU type listed in GPUTypes, in any of Regions.
+type PricingSubscription struct {
+	ID            int64         `json:"id"`
+	UserID        string        `json:"user_id"`
+	Name          string        `json:"name"`
+	InstanceTypes []string      `json:"instance_types"`
+	GPUTypes      []string      `json:"gpu_types"`
+	Regions       []string      `json:"regions"` // Empty = every region
+	Kind          string        `json:"kind"`    // on_demand | spot
+	Condition     string        `json:"condition"`
+	Threshold     float64       `json:"threshold"` // USD per GPU-hour for below, percent for drop
+	Window        time.Duration `json:"-"`         // For drop
+	WebhookURL    string        `json:"webhook_url,omitempty"`
+	Enabled       bool          `json:"enabled"`
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+}
+
+// PriceNotification is a subscription's condition met by one instance type.
+// It stays open while the condition holds, so the subscription notifies once
+// until the condition clears.
+type PriceNotification struct {
+	ID             int64      `json:"id"`
+	SubscriptionID int64      `json:"subscription_id"`
+	Provider       Provider   `json:"provider"`
+	Region         string     `json:"region"`
+	InstanceType   string     `json:"instance_type"`
+	GPUType        string     `json:"gpu_type"`
+	OldPrice       float64    `json:"old_price,omitempty"` // USD per GPU-hour: the previous price (below) or the window's peak (drop); 0 when unknown
+	NewPrice       float64    `json:"new_price"`           // USD per GPU-hour
+	FiredAt        time.Time  `json:"fired_at"`
+	ClearedAt      *time.Time `json:"cleared_at,omitempty"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// ProgressWarmupIntervals is how many report intervals pass before the step
+// rate is trusted; the first intervals include startup and data loading
+const ProgressWarmupIntervals = 3
+
+// progressSmoothing is the weight of the newest interval in the step rate
+const progressSmoothing = 0.3
+
+// TrainingProgress is the step telemetry reported by a running job
+type TrainingProgress struct {
+	StepsCompleted int64     `json:"steps_completed"`
+	StepsPerHour   float64   `json:"steps_per_hour"` // Exponentially smoothed over report intervals
+	Intervals      int       `json:"intervals"`      // Report intervals since the last baseline
+	ReportedAt     time.Time `json:"reported_at"`
+	Tokens         int64     `json:"tokens,omitempty"` // Tokens processed so far, if the job reports them
+}
+
+// Observe records a step count reported at the given time. A count below the
+// previous one (training resumed from an earlier checkpoint) starts a new
+// baseline; reports that are not newer than the last one are ignored.
+func (p *TrainingProgress) Observe(steps int64, at time.Time) {
+	if p.ReportedAt.IsZero() || steps < p.StepsCompleted {
+		*p = TrainingProgress{StepsCompleted: steps, ReportedAt: at}
+		return
+	}
+	elapsed := at.Sub(p.ReportedAt)
+	if elapsed <= 0 {
+		return
+	}
+
+	rate := float64(steps-p.StepsCompleted) / elapsed.Hours()
+	if p.Intervals == 0 {
+		p.StepsPerHour = rate
+	} else {
+		p.StepsPerHour = progressSmoothing*rate + (1-progressSmoothing)*p.StepsPerHour
+	}
+	p.Intervals++
+	p.StepsCompleted = steps
+	p.ReportedAt = at
+}
+
+// Rate returns the smoothed steps per hour, or 0 while warming up
+func (p *TrainingProgress) Rate() float64 {
+	if p.Intervals < ProgressWarmupIntervals {
+		return 0
+	}
+	return p.StepsPerHour
+}
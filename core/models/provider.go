@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"math"
+	"strings"
+	"time"
+)
 
 // Provider represents a cloud provider
 type Provider string
@@ -10,6 +14,9 @@ const (
 	ProviderGCP    Provider = "gcp"
 	ProviderAzure  Provider = "azure"
 	ProviderOnPrem Provider = "onprem"
+
+	// Specialty GPU clouds
+	ProviderCoreWeave Provider = "coreweave"
 )
 
 // GPUInstance represents a GPU instance type available from a provider
@@ -24,6 +31,8 @@ type GPUInstance struct {
 	SpotPrice        float64          // If available
 	Availability     float64          // 0.0 - 1.0
 	InterconnectTier InterconnectTier // "standard" | "high" (for multi-node training)
+	InterconnectGbps float64          // Node-to-node bandwidth for collectives (EFA, InfiniBand); 0 = unknown
+	NetworkGbps      float64          // Instance network bandwidth; 0 = unknown
 	LastUpdated      time.Time        // When pricing was fetched
 }
 
@@ -35,37 +44,64 @@ const (
 	InterconnectHigh     InterconnectTier = "high"
 )
 
+// HighTierInterconnectGbps is the node-to-node bandwidth assumed for
+// high-tier instances whose bandwidth is unknown: the slowest of the high
+// tier (a2-highgpu-8g)
+const HighTierInterconnectGbps = 100.0
+
+// EffectiveInterconnectGbps returns the instance's node-to-node bandwidth,
+// or the least its tier guarantees when it is unknown (0 for standard)
+func (g GPUInstance) EffectiveInterconnectGbps() float64 {
+	if g.InterconnectGbps > 0 {
+		return g.InterconnectGbps
+	}
+	if g.InterconnectTier == InterconnectHigh {
+		return HighTierInterconnectGbps
+	}
+	return 0
+}
+
 // Cluster represents a logical grouping of nodes that share provider/region/network domain
 // For BackendVM, cluster = "a managed group of instances in same VPC/subnet/AZ group"
 // All nodes in a cluster can communicate with low latency (required for DDP/Horovod)
 type Cluster struct {
-	ID       string
-	Provider Provider
-	Region   string
-	VPC      string // Network domain
-	Backend  BackendType
-	Nodes    []Node // All nodes in this cluster
+	ID            string
+	Provider      Provider
+	Region        string
+	Zone          string // Availability zone of every node; empty = unknown
+	VPC           string // Network domain
+	Backend       BackendType
+	Nodes         []Node  // All nodes in this cluster
+	AllocationIDs []int64 // Stored allocations the nodes were launched for; terminated with the cluster
+	ServiceJobID  string  // Managed backend: the training service's job; the cluster has no nodes
 }
 
 // Node represents a compute node in a cluster
 type Node struct {
-	ID         string
-	InstanceID string // Provider-specific instance ID
-	Provider   Provider
-	Region     string
-	VPC        string
-	PrivateIP  string // For DDP communication
-	GPUs       int
+	ID           string
+	ClusterID    string // Cluster the node belongs to; selects its SSH key
+	InstanceID   string // Provider-specific instance ID
+	InstanceType string
+	Provider     Provider
+	Region       string
+	Zone         string // Availability zone; empty = unknown
+	VPC          string
+	PrivateIP    string // For DDP communication
+	GPUs         int
+	GPUType      string // GPU family from the instance catalog, e.g. "A100"
+	GPUMemoryGB  int    // Per GPU; selects the model within a family (A100 40GB vs 80GB)
+	Spot         bool   // Whether the node runs on spot/preemptible capacity
 }
 
 // BackendType represents the compute backend
 type BackendType string
 
 const (
-	BackendKubernetes BackendType = "k8s"   // Kubernetes cluster
-	BackendSlurm      BackendType = "slurm" // Slurm cluster
-	BackendRay        BackendType = "ray"   // Ray cluster
-	BackendVM         BackendType = "vm"    // Raw VMs (MVP only)
+	BackendKubernetes BackendType = "k8s"     // Kubernetes cluster
+	BackendSlurm      BackendType = "slurm"   // Slurm cluster
+	BackendRay        BackendType = "ray"     // Ray cluster
+	BackendVM         BackendType = "vm"      // Raw VMs (MVP only)
+	BackendManaged    BackendType = "managed" // Provider training service (SageMaker, Vertex AI)
 )
 
 // Target represents a compute target (provider + region + backend)
@@ -78,20 +114,106 @@ type Target struct {
 // PerformanceMetrics tracks performance metrics for $/step optimization
 type PerformanceMetrics struct {
 	StepsPerHour         float64 // Training steps per hour
-	TokensPerHour        float64 // For LLM training
+	TokensPerHour        float64 // For LLM training; per GPU
 	StorageThroughput    float64 // MB/s
 	NetworkBandwidth     float64 // Gbps (for multi-node)
 	EffectiveCostPerStep float64 // PricePerHour / StepsPerHour
 }
 
+// AllocationStatus is the lifecycle state of a stored allocation
+type AllocationStatus string
+
+const (
+	AllocationPlanned      AllocationStatus = "planned"      // Chosen by the optimizer, nothing launched yet
+	AllocationProvisioning AllocationStatus = "provisioning" // Instances are being launched
+	AllocationActive       AllocationStatus = "active"       // All instances launched
+	AllocationFailed       AllocationStatus = "failed"       // Launch failed or never started; ProvisionedCount may be partial
+	AllocationTerminated   AllocationStatus = "terminated"   // Instances released by the job
+)
+
+// Terminal reports whether the allocation holds no instances for its job anymore
+func (s AllocationStatus) Terminal() bool {
+	return s == AllocationFailed || s == AllocationTerminated
+}
+
 // Allocation represents a compute allocation decision
 type Allocation struct {
-	Provider      Provider
-	InstanceType  string
-	Region        string
-	Count         int
-	Spot          bool
-	PricePerHour  float64 // Price per hour per instance (explicit for cost tracking)
-	EstimatedCost float64 // Total estimated cost (PricePerHour * Count * Hours)
-	EstimatedTime time.Duration
+	ID                  int64 // Stored allocation row; 0 until stored
+	Provider            Provider
+	InstanceType        string
+	Region              string
+	Zone                string // Availability zone chosen at provisioning; empty = not chosen yet
+	Count               int
+	Spot                bool
+	PricePerHour        float64 // Price per hour per instance (explicit for cost tracking)
+	EstimatedCost       float64 // Total estimated cost (HourlyPrice * Count * Hours)
+	EstimatedTime       time.Duration
+	GPUType             string           // From the instance catalog; not persisted
+	GPUMemoryGB         int              // Per GPU, from the instance catalog; not persisted
+	GPUsPerNode         int              // From the instance catalog; not persisted
+	Status              AllocationStatus // Set once stored
+	ProvisionedCount    int              // Instances launched of Count
+	StatusDetail        string           // Why the allocation failed or ended
+	VolumeType          string           // Data volume attached per instance, e.g. gp3; empty = none
+	VolumeGB            int              // Data volume size per instance; 0 = none or instance store
+	StoragePricePerHour float64          // Data volume price per instance, prorated from per-GB-month
+	GPUSharing          GPUSharingMode   // How the job shares the instances' GPUs (Kubernetes backend)
+	GPUShare            float64          // Share of the instances billed to the job; 0 = whole instances
+	BidStrategy         SpotBidStrategy  // Spot allocations: how SpotBid was set
+	SpotBid             float64          // Spot allocations: max price per instance-hour requested; 0 = no max
+	TaskGroup           string           // Task group of a multi_task job the allocation runs; empty = no groups
+}
+
+// GPUSharingMode is how a job shares physical GPUs with other jobs
+type GPUSharingMode string
+
+const (
+	GPUSharingNone        GPUSharingMode = ""             // Whole GPUs
+	GPUSharingTimeSlicing GPUSharingMode = "time-slicing" // Time-sliced replicas of a GPU
+	GPUSharingMIG         GPUSharingMode = "mig"          // MIG partitions of a GPU
+)
+
+// FleetAllocation is a stored allocation with its job, for the fleet view
+type FleetAllocation struct {
+	JobID      string     `json:"job_id"`
+	JobStatus  JobStatus  `json:"job_status"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	Orphaned   bool       `json:"orphaned"` // Active, but the job is not provisioning or running
+	Allocation Allocation `json:"allocation"`
+	// Pending scheduled maintenance of the allocation's nodes
+	Maintenance []MaintenanceEvent `json:"maintenance,omitempty"`
+}
+
+// HourlyPrice returns the price per instance per hour billed to the job: its
+// GPUShare of the instance price plus its data volume
+func (a Allocation) HourlyPrice() float64 {
+	price := a.PricePerHour
+	if a.GPUShare > 0 && a.GPUShare < 1 {
+		price *= a.GPUShare
+	}
+	return price + a.StoragePricePerHour
+}
+
+// ExpectedCost returns HourlyPrice * Count * EstimatedTime in hours
+func (a Allocation) ExpectedCost() float64 {
+	return a.HourlyPrice() * float64(a.Count) * a.EstimatedTime.Hours()
+}
+
+// CostConsistent reports whether EstimatedCost matches ExpectedCost within a
+// relative tolerance (0.01 = 1%). Differences under one cent always pass so
+// that numeric rounding in storage does not trip the check.
+func (a Allocation) CostConsistent(tolerance float64) bool {
+	expected := a.ExpectedCost()
+	diff := math.Abs(a.EstimatedCost - expected)
+	return diff < 0.01 || diff <= tolerance*math.Max(math.Abs(expected), math.Abs(a.EstimatedCost))
+}
+
+// InstanceFamily returns the family of an instance type: the part before the
+// first "." or "-" (p4d.24xlarge -> p4d, a2-highgpu-8g -> a2). Types without
+// a separator (Azure sizes) are their own family.
+func InstanceFamily(instanceType string) string {
+	if i := strings.IndexAny(instanceType, ".-"); i > 0 {
+		return instanceType[:i]
+	}
+	return instanceType
 }
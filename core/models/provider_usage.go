@@ -0,0 +1,21 @@
+package models
+
+// ProviderAPIUsage counts the calls to one provider API method. Day is the
+// UTC date ("2006-01-02") of daily rollups and empty in running totals.
+type ProviderAPIUsage struct {
+	Day       string   `json:"day,omitempty"`
+	Provider  Provider `json:"provider"`
+	Method    string   `json:"method"`
+	Calls     int64    `json:"calls"`
+	Errors    int64    `json:"errors"`
+	Throttled int64    `json:"throttled"` // Non-critical calls refused over budget; not counted in Calls
+	LatencyMs float64  `json:"latency_ms_total"`
+}
+
+// ProviderCallBudget is a provider's hourly call budget and its use this hour
+type ProviderCallBudget struct {
+	Provider  Provider `json:"provider"`
+	PerHour   int      `json:"per_hour"` // 0 = unlimited
+	HourCalls int      `json:"hour_calls"`
+	Throttled bool     `json:"throttled"` // Non-critical callers are being refused
+}
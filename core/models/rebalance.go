@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// CheckpointRequestRebalance is the reason of checkpoint requests raised by a
+// rebalance recommendation
+const CheckpointRequestRebalance = "rebalance_recommendation"
+
+// RebalanceSignal is a provider rebalance recommendation for a spot node of
+// a running job and what was done about it
+type RebalanceSignal struct {
+	ID           int64     `json:"id"`
+	JobID        string    `json:"job_id"`
+	Provider     Provider  `json:"provider"`
+	Region       string    `json:"region"`
+	InstanceType string    `json:"instance_type"`
+	InstanceID   string    `json:"instance_id"`
+	NoticedAt    time.Time `json:"noticed_at"` // Raised by the provider
+	DetectedAt   time.Time `json:"detected_at"`
+
+	// Immediate checkpoint asked of the training wrapper; saved once a
+	// checkpoint newer than the request is recorded
+	CheckpointRequestedAt *time.Time `json:"checkpoint_requested_at,omitempty"`
+	CheckpointSavedAt     *time.Time `json:"checkpoint_saved_at,omitempty"`
+	CheckpointURI         string     `json:"checkpoint_uri,omitempty"`
+	WorkSavedSeconds      int64      `json:"work_saved_seconds,omitempty"` // Training since the previous checkpoint that survives the interruption
+
+	// Node provisioned ahead of the interruption (elastic jobs)
+	ReplacementNodeID    string     `json:"replacement_node_id,omitempty"`
+	ReplacementStartedAt *time.Time `json:"replacement_started_at,omitempty"`
+
+	InterruptedAt *time.Time `json:"interrupted_at,omitempty"` // The node left the job's cluster
+}
+
+// CheckpointRequest is an immediate checkpoint the training wrapper is asked
+// to write, polled from GET /v1/jobs/{id}/checkpoint-request
+type CheckpointRequest struct {
+	Requested   bool       `json:"requested"`
+	Reason      string     `json:"reason,omitempty"`
+	InstanceID  string     `json:"instance_id,omitempty"` // Node at risk
+	RequestedAt *time.Time `json:"requested_at,omitempty"`
+}
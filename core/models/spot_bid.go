@@ -0,0 +1,57 @@
+package models
+
+// SpotBidStrategy sets the max price of a job's spot requests
+// (constraints.spot_bid_strategy)
+type SpotBidStrategy string
+
+const (
+	// SpotBidLow bids 1.1x the current spot price, or the explicit
+	// constraints.spot_bid_cap; cheap, but price spikes interrupt the job
+	SpotBidLow SpotBidStrategy = "low"
+	// SpotBidMarket sets no max price: the job pays the market price up to
+	// the provider's ceiling (default)
+	SpotBidMarket SpotBidStrategy = "market"
+	// SpotBidOnDemandCap bids up to the on-demand rate
+	SpotBidOnDemandCap SpotBidStrategy = "on_demand_cap"
+)
+
+// spotBidLowMarkup is how far above the current spot price a low bid goes
+const spotBidLowMarkup = 1.1
+
+// Valid reports whether s is a known strategy; empty means market
+func (s SpotBidStrategy) Valid() bool {
+	switch s {
+	case "", SpotBidLow, SpotBidMarket, SpotBidOnDemandCap:
+		return true
+	}
+	return false
+}
+
+// InterruptionFactor scales the observed spot interruption rate: low bids
+// are also reclaimed when the spot price rises above them
+func (s SpotBidStrategy) InterruptionFactor() float64 {
+	switch s {
+	case SpotBidLow:
+		return 1.5
+	case SpotBidOnDemandCap:
+		return 1.05 // Spot rarely exceeds the on-demand rate
+	default:
+		return 1
+	}
+}
+
+// SpotMaxPrice returns the max price per instance-hour of a spot request for
+// an instance under the job's bid strategy; 0 sets no max price
+func SpotMaxPrice(constraints JobConstraints, spotPrice, onDemandPrice float64) float64 {
+	switch constraints.SpotBidStrategy {
+	case SpotBidLow:
+		if constraints.SpotBidCap > 0 {
+			return constraints.SpotBidCap
+		}
+		return spotPrice * spotBidLowMarkup
+	case SpotBidOnDemandCap:
+		return onDemandPrice
+	default:
+		return 0
+	}
+}
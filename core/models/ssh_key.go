@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// SSHKeyStatus is where a per-cluster SSH key is in its lifecycle
+type SSHKeyStatus string
+
+const (
+	SSHKeyPending   SSHKeyStatus = "pending"   // Issued; its instances are launching
+	SSHKeyActive    SSHKeyStatus = "active"    // Trusted by the cluster's nodes and used for SSH
+	SSHKeyRetired   SSHKeyStatus = "retired"   // Replaced by rotation and removed from the nodes
+	SSHKeyDestroyed SSHKeyStatus = "destroyed" // Its cluster was terminated
+)
+
+// ClusterSSHKey is the ephemeral keypair the orchestrator uses to reach one
+// cluster's nodes. The private key is only stored sealed and is dropped when
+// the key is retired or destroyed.
+type ClusterSSHKey struct {
+	ID          int64        `json:"id"`
+	JobID       string       `json:"job_id,omitempty"`
+	ClusterID   string       `json:"cluster_id,omitempty"`
+	Fingerprint string       `json:"fingerprint"` // SHA256:... as printed by ssh-keygen -l
+	PublicKey   string       `json:"public_key"`  // authorized_keys line
+	SealedKey   string       `json:"-"`           // Envelope-encrypted PEM private key
+	Status      SSHKeyStatus `json:"status"`
+	CreatedAt   time.Time    `json:"created_at"`
+	ActivatedAt *time.Time   `json:"activated_at,omitempty"`
+	RetiredAt   *time.Time   `json:"retired_at,omitempty"` // Retired or destroyed
+}
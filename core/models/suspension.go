@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// CheckpointRequestSuspend is the reason of checkpoint requests raised by
+// suspending a job
+const CheckpointRequestSuspend = "suspend"
+
+// JobSuspension is the outcome of suspending or resuming a job
+type JobSuspension struct {
+	JobID               string     `json:"id"`
+	Status              JobStatus  `json:"status"`
+	SuspendRequestedAt  *time.Time `json:"suspend_requested_at,omitempty"`
+	CheckpointURI       string     `json:"checkpoint_uri,omitempty"` // Newest checkpoint; the job resumes from it
+	CheckpointAt        *time.Time `json:"checkpoint_at,omitempty"`
+	ReservationReleased bool       `json:"reservation_released,omitempty"` // GPU-hour reservations dropped on suspension
+	Warning             string     `json:"warning,omitempty"`              // Set when resuming will lose training progress
+}
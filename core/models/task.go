@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// TaskAggregation decides a multi_task job's final status from its tasks
+type TaskAggregation string
+
+const (
+	TaskAggregationAll TaskAggregation = "all" // Completed only if every task completed
+	TaskAggregationAny TaskAggregation = "any" // Completed if at least one task completed
+)
+
+// DefaultTaskPolicy applies to multi_task jobs whose spec sets no policy
+var DefaultTaskPolicy = TaskPolicy{MaxRetries: 1, Aggregation: TaskAggregationAll}
+
+// TaskPolicy configures retries and status aggregation of a multi_task job
+type TaskPolicy struct {
+	MaxRetries  int             `json:"max_retries"` // Extra attempts per task after a failure
+	Aggregation TaskAggregation `json:"aggregation"`
+}
+
+// JobTask is one independently provisioned part of a multi_task job. A task
+// failing is retried on a fresh cluster without touching its siblings.
+type JobTask struct {
+	JobID      string
+	Index      int
+	Status     JobStatus
+	Attempts   int
+	Allocation Allocation
+	ClusterID  *string
+	CostUSD    float64 // Accrued over all attempts
+	Error      string  // Failure of the last attempt
+	StartedAt  *time.Time
+	FinishedAt *time.Time
+	UpdatedAt  time.Time
+}
+
+// Terminal reports whether the task will not run again
+func (t JobTask) Terminal() bool {
+	return t.Status == JobStatusCompleted || t.Status == JobStatusFailed || t.Status == JobStatusCancelled
+}
+
+// Aggregate returns the parent job status for its tasks: running while any
+// task is not terminal, then completed or failed per the aggregation
+func (p TaskPolicy) Aggregate(tasks []JobTask) JobStatus {
+	completed := 0
+	for _, task := range tasks {
+		if !task.Terminal() {
+			return JobStatusRunning
+		}
+		if task.Status == JobStatusCompleted {
+			completed++
+		}
+	}
+
+	switch {
+	case len(tasks) == 0:
+		return JobStatusFailed
+	case p.Aggregation == TaskAggregationAny && completed > 0:
+		return JobStatusCompleted
+	case completed == len(tasks):
+		return JobStatusCompleted
+	default:
+		return JobStatusFailed
+	}
+}
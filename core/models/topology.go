@@ -0,0 +1,131 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// TopologyVersion is the schema version of topology manifests; bumped on
+// incompatible changes
+const TopologyVersion = 1
+
+// TopologyManifest is a machine-readable description of where a running job
+// runs, for external monitoring and for the training code itself. Single
+// cluster jobs have one cluster; multi_task jobs one per task.
+type TopologyManifest struct {
+	Version   int               `json:"version"`
+	JobID     string            `json:"job_id"`
+	Framework string            `json:"framework"`
+	Revision  int               `json:"revision"` // Incremented on every change, e.g. elastic resizes
+	UpdatedAt time.Time         `json:"updated_at"`
+	Clusters  []TopologyCluster `json:"clusters"`
+}
+
+// TopologyCluster is one cluster of a job with its distributed training setup
+type TopologyCluster struct {
+	ClusterID      string         `json:"cluster_id"`
+	Task           *int           `json:"task,omitempty"` // multi_task jobs only
+	Provider       Provider       `json:"provider"`
+	Region         string         `json:"region"`
+	Backend        BackendType    `json:"backend,omitempty"`
+	MasterAddr     string         `json:"master_addr"`
+	MasterPort     int            `json:"master_port,omitempty"`
+	WorldSize      int            `json:"world_size"` // Launcher processes (one per node), as set up by the framework
+	GPUs           int            `json:"gpus"`       // Across all nodes
+	NetworkProfile string         `json:"network_profile,omitempty"`
+	Nodes          []TopologyNode `json:"nodes"`
+}
+
+// TopologyNode is one node of a cluster
+type TopologyNode struct {
+	Rank         int      `json:"rank"`
+	NodeID       string   `json:"node_id"`
+	InstanceID   string   `json:"instance_id,omitempty"`
+	InstanceType string   `json:"instance_type"`
+	Provider     Provider `json:"provider"`
+	Region       string   `json:"region"`
+	Zone         string   `json:"zone,omitempty"`
+	PrivateIP    string   `json:"private_ip"`
+	GPUs         int      `json:"gpus"`
+	GPUType      string   `json:"gpu_type,omitempty"`
+	GPUMemoryGB  int      `json:"gpu_memory_gb,omitempty"`
+	Spot         bool     `json:"spot"`
+	Port         int      `json:"port,omitempty"` // Server port of the node's worker, allocated per node
+}
+
+// NewTopologyCluster describes a cluster with its nodes ranked in cluster
+// order and the first node as master; see SetRanks for framework ranks.
+func NewTopologyCluster(cluster *Cluster) TopologyCluster {
+	tc := TopologyCluster{
+		ClusterID: cluster.ID,
+		Provider:  cluster.Provider,
+		Region:    cluster.Region,
+		Backend:   cluster.Backend,
+		Nodes:     make([]TopologyNode, len(cluster.Nodes)),
+	}
+	for i, node := range cluster.Nodes {
+		tc.Nodes[i] = TopologyNode{
+			Rank:         i,
+			NodeID:       node.ID,
+			InstanceID:   node.InstanceID,
+			InstanceType: node.InstanceType,
+			Provider:     node.Provider,
+			Region:       node.Region,
+			Zone:         node.Zone,
+			PrivateIP:    node.PrivateIP,
+			GPUs:         node.GPUs,
+			GPUType:      node.GPUType,
+			GPUMemoryGB:  node.GPUMemoryGB,
+			Spot:         node.Spot,
+		}
+		tc.GPUs += node.GPUs
+	}
+	tc.WorldSize = len(cluster.Nodes)
+	if len(cluster.Nodes) > 0 {
+		tc.MasterAddr = cluster.Nodes[0].PrivateIP
+	}
+	return tc
+}
+
+// SetRanks applies the ranks the framework setup assigned, by private IP, and
+// orders the nodes by rank
+func (tc *TopologyCluster) SetRanks(ranks map[string]int) {
+	for i := range tc.Nodes {
+		if rank, ok := ranks[tc.Nodes[i].PrivateIP]; ok {
+			tc.Nodes[i].Rank = rank
+		}
+	}
+	sort.SliceStable(tc.Nodes, func(i, j int) bool {
+		return tc.Nodes[i].Rank < tc.Nodes[j].Rank
+	})
+}
+
+// Upsert adds a cluster or replaces the one with the same ID (or task) and
+// bumps the revision
+func (m *TopologyManifest) Upsert(cluster TopologyCluster) {
+	replaced := false
+	for i, existing := range m.Clusters {
+		sameTask := existing.Task != nil && cluster.Task != nil && *existing.Task == *cluster.Task
+		if existing.ClusterID == cluster.ClusterID || sameTask {
+			m.Clusters[i] = cluster
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		m.Clusters = append(m.Clusters, cluster)
+	}
+	m.Version = TopologyVersion
+	m.Revision++
+	m.UpdatedAt = time.Now()
+}
+
+// Cluster returns the manifest's entry for a cluster
+func (m *TopologyManifest) Cluster(clusterID string) (TopologyCluster, bool) {
+	for _, cluster := range m.Clusters {
+		if cluster.ClusterID == clusterID {
+			return cluster, true
+		}
+	}
+	return TopologyCluster{}, false
+}
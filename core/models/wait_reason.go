@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Wait reason codes
+const (
+	WaitQueuePosition  = "queue_position"           // Other jobs are ahead in the queue
+	WaitFairShare      = "fair_share"               // The team is over its fair share
+	WaitGPUHourQuota   = "gpu_hour_quota_exhausted" // A team or project quota cannot cover the job's GPU-hours
+	WaitStalePricing   = "stale_pricing"            // No GPU prices fresh enough to optimize with
+	WaitNoAllocation   = "no_allocation"            // The optimizer found nothing to place the job on
+	WaitInstanceTypes  = "instance_types_excluded"  // The instance type lists exclude every suitable instance
+	WaitProviderBudget = "provider_api_budget"      // A provider is over its API call budget
+	WaitProvisioning   = "awaiting_provisioning"    // Scheduled; provisioning has not started
+	WaitSchedulerPass  = "scheduler_pass"           // Nothing blocks; waiting for the next pass
+)
+
+// WaitReason is something keeping a pending or scheduled job from starting
+type WaitReason struct {
+	Code       string    `json:"code"`
+	Message    string    `json:"message"`
+	Value      string    `json:"value,omitempty"` // What is blocking, e.g. "position 4 of 9"
+	RecordedAt time.Time `json:"recorded_at"`
+}
@@ -0,0 +1,155 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// SupplyScenario is a hypothetical change to the GPU supply, replayed against
+// past jobs for capacity planning
+type SupplyScenario struct {
+	DisabledProviders []Provider      `json:"disabled_providers,omitempty"`
+	Prices            []PriceOverride `json:"prices,omitempty"`
+	Inventory         []InventoryPool `json:"inventory,omitempty"` // Extra fixed-size capacity, e.g. on-prem nodes
+}
+
+// PriceOverride replaces the prices of matching catalog entries.
+// Empty instance type and region match any.
+type PriceOverride struct {
+	Provider     Provider `json:"provider"`
+	InstanceType string   `json:"instance_type,omitempty"`
+	Region       string   `json:"region,omitempty"`
+	PricePerHour *float64 `json:"price_per_hour,omitempty"`
+	SpotPrice    *float64 `json:"spot_price,omitempty"` // 0 removes spot capacity
+}
+
+// Matches reports whether the override applies to instance
+func (o PriceOverride) Matches(instance GPUInstance) bool {
+	return o.Provider == instance.Provider &&
+		(o.InstanceType == "" || o.InstanceType == instance.InstanceType) &&
+		(o.Region == "" || o.Region == instance.Region)
+}
+
+// InventoryPool is a fixed number of identical nodes. Jobs placed on it hold
+// their nodes for their run time; others cannot use them meanwhile.
+type InventoryPool struct {
+	Provider         Provider         `json:"provider,omitempty"` // Default: onprem
+	InstanceType     string           `json:"instance_type"`
+	Region           string           `json:"region"`
+	GPUType          string           `json:"gpu_type"`
+	GPUsPerInstance  int              `json:"gpus_per_instance"`
+	MemoryPerGPU     int              `json:"memory_per_gpu_gb"`
+	Nodes            int              `json:"nodes"`
+	PricePerHour     float64          `json:"price_per_hour"` // Amortized cost per node-hour; 0 = free
+	InterconnectTier InterconnectTier `json:"interconnect,omitempty"`
+}
+
+// Instance returns the catalog entry for the pool's nodes
+func (p InventoryPool) Instance() GPUInstance {
+	return GPUInstance{
+		Provider:         p.Provider,
+		InstanceType:     p.InstanceType,
+		Region:           p.Region,
+		GPUType:          p.GPUType,
+		GPUsPerInstance:  p.GPUsPerInstance,
+		MemoryPerGPU:     p.MemoryPerGPU,
+		PricePerHour:     p.PricePerHour,
+		Availability:     1.0,
+		InterconnectTier: p.InterconnectTier,
+		LastUpdated:      time.Now(),
+	}
+}
+
+// Validate checks the scenario and defaults inventory pools to on-prem
+func (s *SupplyScenario) Validate() error {
+	for i := range s.Inventory {
+		pool := &s.Inventory[i]
+		if pool.Provider == "" {
+			pool.Provider = ProviderOnPrem
+		}
+		if pool.InstanceType == "" || pool.Region == "" {
+			return fmt.Errorf("inventory[%d]: instance_type and region are required", i)
+		}
+		if pool.Nodes <= 0 || pool.GPUsPerInstance <= 0 || pool.MemoryPerGPU <= 0 {
+			return fmt.Errorf("inventory[%d]: nodes, gpus_per_instance and memory_per_gpu_gb must be positive", i)
+		}
+		if pool.PricePerHour < 0 {
+			return fmt.Errorf("inventory[%d]: price_per_hour must not be negative", i)
+		}
+	}
+	for i, override := range s.Prices {
+		if override.Provider == "" {
+			return fmt.Errorf("prices[%d]: provider is required", i)
+		}
+		if (override.PricePerHour != nil && *override.PricePerHour < 0) || (override.SpotPrice != nil && *override.SpotPrice < 0) {
+			return fmt.Errorf("prices[%d]: prices must not be negative", i)
+		}
+	}
+	return nil
+}
+
+// ReplaySummary aggregates the allocations a replay chose for a set of jobs
+type ReplaySummary struct {
+	Jobs               int                  `json:"jobs"`
+	Placed             int                  `json:"placed"`
+	Unplaced           int                  `json:"unplaced"` // No feasible allocation
+	TotalCostUSD       float64              `json:"total_cost_usd"`
+	SpotFraction       float64              `json:"spot_fraction"`    // Share of instance-hours on spot
+	MissedDeadlines    int                  `json:"missed_deadlines"` // Includes unplaced jobs with a deadline
+	GPUHoursByProvider map[Provider]float64 `json:"gpu_hours_by_provider"`
+}
+
+// Minus returns s - base field by field
+func (s ReplaySummary) Minus(base ReplaySummary) ReplaySummary {
+	delta := ReplaySummary{
+		Jobs:               s.Jobs - base.Jobs,
+		Placed:             s.Placed - base.Placed,
+		Unplaced:           s.Unplaced - base.Unplaced,
+		TotalCostUSD:       s.TotalCostUSD - base.TotalCostUSD,
+		SpotFraction:       s.SpotFraction - base.SpotFraction,
+		MissedDeadlines:    s.MissedDeadlines - base.MissedDeadlines,
+		GPUHoursByProvider: make(map[Provider]float64),
+	}
+	for provider, hours := range s.GPUHoursByProvider {
+		delta.GPUHoursByProvider[provider] += hours
+	}
+	for provider, hours := range base.GPUHoursByProvider {
+		delta.GPUHoursByProvider[provider] -= hours
+	}
+	return delta
+}
+
+// WhatIfRequest replays the jobs created in [From, To) against a scenario
+type WhatIfRequest struct {
+	From     time.Time      `json:"from"`
+	To       time.Time      `json:"to"`
+	Scenario SupplyScenario `json:"scenario"`
+}
+
+// WhatIfResult compares a replay against today's supply with one against the scenario
+type WhatIfResult struct {
+	Baseline ReplaySummary `json:"baseline"`
+	Scenario ReplaySummary `json:"scenario"`
+	Delta    ReplaySummary `json:"delta"` // Scenario minus baseline
+}
+
+// WhatIfRunStatus is the state of an asynchronous what-if replay
+type WhatIfRunStatus string
+
+const (
+	WhatIfRunning   WhatIfRunStatus = "running"
+	WhatIfCompleted WhatIfRunStatus = "completed"
+	WhatIfFailed    WhatIfRunStatus = "failed"
+)
+
+// WhatIfRun is the record of a what-if replay
+type WhatIfRun struct {
+	ID         int64           `json:"id"`
+	Status     WhatIfRunStatus `json:"status"`
+	Request    WhatIfRequest   `json:"request"`
+	Jobs       int             `json:"jobs"`
+	Result     *WhatIfResult   `json:"result,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+}
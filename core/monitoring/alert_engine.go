@@ -0,0 +1,297 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"gpu-orchestrator/core/clock"
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/core/supervisor"
+)
+
+// IdleCostSource reports the hourly cost of provisioned capacity not running any job
+type IdleCostSource interface {
+	IdleCostPerHour() float64
+}
+
+// AlertEngine periodically evaluates alert rules and delivers firings.
+// Each rule yields zero or more matches keyed by job, team or fleet; a key fires
+// at most once per rule cooldown.
+type AlertEngine struct {
+	alertRepo      *repository.AlertRepository
+	idleCost       IdleCostSource // Optional; idle_cost rules match nothing without it
+	emailSender    EmailSender
+	defaultWebhook string // Used for rules without their own webhook URL
+	now            func() time.Time
+	interval       time.Duration // How often rules are evaluated
+}
+
+// alertMatch is a single key crossing a rule's condition
+type alertMatch struct {
+	key     string
+	subject string
+	message string
+	meta    map[string]interface{}
+}
+
+// NewAlertEngine creates a new alert engine
+func NewAlertEngine(alertRepo *repository.AlertRepository, idleCost IdleCostSource, emailSender EmailSender, defaultWebhook string) *AlertEngine {
+	if emailSender == nil {
+		emailSender = LogEmailSender{}
+	}
+	return &AlertEngine{
+		alertRepo:      alertRepo,
+		idleCost:       idleCost,
+		emailSender:    emailSender,
+		defaultWebhook: defaultWebhook,
+		now:            clock.System.Now,
+		interval:       time.Minute,
+	}
+}
+
+// SetInterval sets how often rules are evaluated (default 1 minute)
+func (e *AlertEngine) SetInterval(interval time.Duration) {
+	e.interval = interval
+}
+
+// Start starts the alert evaluation worker
+func (e *AlertEngine) Start(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			supervisor.Heartbeat(ctx)
+			e.EvaluateAll(ctx)
+		}
+	}
+}
+
+// EvaluateAll evaluates every enabled rule once
+func (e *AlertEngine) EvaluateAll(ctx context.Context) {
+	rules, err := e.alertRepo.ListRules(true)
+	if err != nil {
+		log.Printf("Failed to load alert rules: %v", err)
+		return
+	}
+
+	for _, rule := range rules {
+		matched, fired, err := e.evaluateRule(ctx, rule)
+		if err != nil {
+			log.Printf("Failed to evaluate alert rule %s: %v", rule.Name, err)
+		}
+		if recErr := e.alertRepo.RecordEvaluation(rule.ID, matched, fired, err); recErr != nil {
+			log.Printf("Failed to record evaluation for alert rule %s: %v", rule.Name, recErr)
+		}
+	}
+}
+
+// evaluateRule evaluates a rule and delivers matches that are out of cooldown
+func (e *AlertEngine) evaluateRule(ctx context.Context, rule *models.AlertRule) (int, int, error) {
+	matches, err := e.match(rule)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	fired := 0
+	for _, m := range matches {
+		inCooldown, err := e.inCooldown(rule, m.key)
+		if err != nil {
+			return len(matches), fired, err
+		}
+		if inCooldown {
+			continue
+		}
+
+		if err := e.deliver(ctx, rule, m); err != nil {
+			// Don't record the firing so delivery is retried on the next evaluation
+			log.Printf("Failed to deliver alert %s (%s): %v", rule.Name, m.key, err)
+			continue
+		}
+
+		firing := &models.AlertFiring{
+			RuleID:   rule.ID,
+			DedupKey: m.key,
+			Subject:  m.subject,
+			Message:  m.message,
+			MetaJSON: m.meta,
+		}
+		if err := e.alertRepo.CreateFiring(firing); err != nil {
+			return len(matches), fired, fmt.Errorf("failed to record firing: %w", err)
+		}
+		fired++
+	}
+
+	return len(matches), fired, nil
+}
+
+// match returns the keys currently crossing the rule's condition
+func (e *AlertEngine) match(rule *models.AlertRule) ([]alertMatch, error) {
+	now := e.now()
+
+	switch rule.Condition {
+	case models.AlertStatusDuration:
+		limit := time.Duration(rule.Threshold * float64(time.Minute))
+		rows, err := e.alertRepo.ListJobsInStatusSince(rule.Status, now.Add(-limit), rule.TeamID)
+		if err != nil {
+			return nil, err
+		}
+		matches := make([]alertMatch, 0, len(rows))
+		for _, row := range rows {
+			elapsed := now.Sub(row.Since).Round(time.Minute)
+			matches = append(matches, alertMatch{
+				key:     row.JobID,
+				subject: fmt.Sprintf("[%s] Job %s has been %s for %s", rule.Name, row.Name, rule.Status, elapsed),
+				message: fmt.Sprintf("Job %s (%s) entered %s at %s, exceeding the %.0f minute limit.",
+					row.Name, row.JobID, rule.Status, row.Since.Format(time.RFC3339), rule.Threshold),
+				meta: map[string]interface{}{
+					"job_id":  row.JobID,
+					"team_id": row.TeamID,
+					"status":  string(rule.Status),
+					"since":   row.Since,
+				},
+			})
+		}
+		return matches, nil
+
+	case models.AlertFailureCount:
+		window := rule.Window
+		if window <= 0 {
+			window = 24 * time.Hour
+		}
+		counts, err := e.alertRepo.CountFailedJobsByTeam(now.Add(-window), rule.TeamID)
+		if err != nil {
+			return nil, err
+		}
+		var matches []alertMatch
+		for team, count := range counts {
+			if float64(count) < rule.Threshold {
+				continue
+			}
+			teamName := team
+			if teamName == "" {
+				teamName = "unassigned"
+			}
+			matches = append(matches, alertMatch{
+				key:     "team:" + team,
+				subject: fmt.Sprintf("[%s] %d failed jobs for team %s", rule.Name, count, teamName),
+				message: fmt.Sprintf("Team %s had %d failed jobs in the last %s.", teamName, count, window),
+				meta: map[string]interface{}{
+					"team_id":      team,
+					"failed_jobs":  count,
+					"window_hours": window.Hours(),
+				},
+			})
+		}
+		return matches, nil
+
+	case models.AlertCostThreshold:
+		rows, err := e.alertRepo.ListRunningJobsOverCost(rule.Threshold, rule.TeamID)
+		if err != nil {
+			return nil, err
+		}
+		matches := make([]alertMatch, 0, len(rows))
+		for _, row := range rows {
+			matches = append(matches, alertMatch{
+				key:     row.JobID,
+				subject: fmt.Sprintf("[%s] Job %s has cost $%.2f", rule.Name, row.Name, row.RunningCost),
+				message: fmt.Sprintf("Job %s (%s) has accumulated $%.2f, exceeding the $%.2f threshold.",
+					row.Name, row.JobID, row.RunningCost, rule.Threshold),
+				meta: map[string]interface{}{
+					"job_id":       row.JobID,
+					"team_id":      row.TeamID,
+					"running_cost": row.RunningCost,
+				},
+			})
+		}
+		return matches, nil
+
+	case models.AlertIdleCost:
+		if e.idleCost == nil {
+			return nil, nil
+		}
+		idle := e.idleCost.IdleCostPerHour()
+		if idle <= rule.Threshold {
+			return nil, nil
+		}
+		return []alertMatch{{
+			key:     "fleet",
+			subject: fmt.Sprintf("[%s] Fleet idle cost is $%.2f/hour", rule.Name, idle),
+			message: fmt.Sprintf("Idle provisioned capacity costs $%.2f/hour, exceeding the $%.2f/hour threshold.",
+				idle, rule.Threshold),
+			meta: map[string]interface{}{
+				"idle_cost_per_hour": idle,
+			},
+		}}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown alert condition: %s", rule.Condition)
+	}
+}
+
+// inCooldown reports whether the rule fired for key within its cooldown
+func (e *AlertEngine) inCooldown(rule *models.AlertRule, key string) (bool, error) {
+	lastFired, err := e.alertRepo.LastFiredAt(rule.ID, key)
+	if err != nil {
+		return false, err
+	}
+	if lastFired == nil {
+		return false, nil
+	}
+	return e.now().Sub(*lastFired) < rule.Cooldown, nil
+}
+
+// deliver sends a match to every channel configured on the rule
+func (e *AlertEngine) deliver(ctx context.Context, rule *models.AlertRule, m alertMatch) error {
+	var notifiers []Notifier
+	webhookURL := rule.WebhookURL
+	if webhookURL == "" {
+		webhookURL = e.defaultWebhook
+	}
+	if webhookURL != "" {
+		notifiers = append(notifiers, NewWebhookNotifier(webhookURL))
+	}
+	if len(rule.EmailRecipients) > 0 {
+		notifiers = append(notifiers, NewEmailNotifier(e.emailSender, rule.EmailRecipients))
+	}
+	if len(notifiers) == 0 {
+		// Firing is still recorded and visible via GET /v1/alerts
+		log.Printf("Alert %s: %s", rule.Name, m.subject)
+		return nil
+	}
+
+	n := Notification{
+		Subject: m.subject,
+		Message: m.message,
+		Source:  rule.Name,
+		Meta:    m.meta,
+		SentAt:  e.now(),
+	}
+
+	var firstErr error
+	delivered := 0
+	for _, notifier := range notifiers {
+		if err := notifier.Notify(ctx, n); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		delivered++
+	}
+
+	// Partial delivery counts as fired to avoid duplicate messages on working channels
+	if delivered == 0 {
+		return firstErr
+	}
+	if firstErr != nil {
+		log.Printf("Alert %s partially delivered: %v", rule.Name, firstErr)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package monitoring
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gpu-orchestrator/core/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AlertRuleSpec is the config/API representation of an alert rule.
+// Durations use Go duration syntax (e.g. "15m", "24h").
+type AlertRuleSpec struct {
+	Name            string   `yaml:"name" json:"name"`
+	Condition       string   `yaml:"condition" json:"condition"`
+	Threshold       float64  `yaml:"threshold" json:"threshold"`
+	Status          string   `yaml:"status,omitempty" json:"status,omitempty"`
+	Window          string   `yaml:"window,omitempty" json:"window,omitempty"`
+	TeamID          string   `yaml:"team_id,omitempty" json:"team_id,omitempty"`
+	Cooldown        string   `yaml:"cooldown,omitempty" json:"cooldown,omitempty"`
+	WebhookURL      string   `yaml:"webhook_url,omitempty" json:"webhook_url,omitempty"`
+	EmailRecipients []string `yaml:"email_recipients,omitempty" json:"email_recipients,omitempty"`
+	Enabled         *bool    `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+}
+
+// LoadAlertRules loads alert rules from a YAML file with a top-level "rules" list
+func LoadAlertRules(path string) ([]*models.AlertRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alert rules: %w", err)
+	}
+
+	var file struct {
+		Rules []AlertRuleSpec `yaml:"rules"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse alert rules: %w", err)
+	}
+
+	rules := make([]*models.AlertRule, 0, len(file.Rules))
+	for _, spec := range file.Rules {
+		rule, err := spec.ToRule()
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// ToRule validates the spec and converts it to an alert rule
+func (s AlertRuleSpec) ToRule() (*models.AlertRule, error) {
+	if s.Name == "" {
+		return nil, fmt.Errorf("alert rule name is required")
+	}
+	if s.Threshold < 0 {
+		return nil, fmt.Errorf("alert rule %s: threshold must be non-negative", s.Name)
+	}
+
+	rule := &models.AlertRule{
+		Name:            s.Name,
+		Condition:       models.AlertConditionType(s.Condition),
+		Threshold:       s.Threshold,
+		Status:          models.JobStatus(s.Status),
+		TeamID:          s.TeamID,
+		Cooldown:        time.Hour,
+		WebhookURL:      s.WebhookURL,
+		EmailRecipients: s.EmailRecipients,
+		Enabled:         true,
+	}
+	if s.Enabled != nil {
+		rule.Enabled = *s.Enabled
+	}
+
+	switch rule.Condition {
+	case models.AlertStatusDuration:
+		if rule.Status == "" {
+			return nil, fmt.Errorf("alert rule %s: status is required for %s", s.Name, rule.Condition)
+		}
+	case models.AlertFailureCount:
+		rule.Window = 24 * time.Hour
+	case models.AlertCostThreshold, models.AlertIdleCost:
+	default:
+		return nil, fmt.Errorf("alert rule %s: unknown condition %q", s.Name, s.Condition)
+	}
+
+	if s.Window != "" {
+		window, err := time.ParseDuration(s.Window)
+		if err != nil {
+			return nil, fmt.Errorf("alert rule %s: invalid window: %w", s.Name, err)
+		}
+		rule.Window = window
+	}
+	if s.Cooldown != "" {
+		cooldown, err := time.ParseDuration(s.Cooldown)
+		if err != nil {
+			return nil, fmt.Errorf("alert rule %s: invalid cooldown: %w", s.Name, err)
+		}
+		rule.Cooldown = cooldown
+	}
+
+	return rule, nil
+}
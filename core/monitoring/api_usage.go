@@ -0,0 +1,267 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/core/supervisor"
+)
+
+// apiLatencyBuckets are the upper bounds, in seconds, of the request latency
+// histograms
+var apiLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// promLabel escapes a label value of the text exposition format
+var promLabel = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// apiUsageKey identifies the counters of one daily rollup row
+type apiUsageKey struct {
+	day      string
+	endpoint string
+	client   string
+	jobID    string
+}
+
+// apiLatency is the latency histogram of one endpoint
+type apiLatency struct {
+	buckets []int64 // Requests per bucket, not cumulative; the last is +Inf
+	count   int64
+	errors  int64
+	sum     float64 // Seconds
+}
+
+// APIUsageLimits caps what the meter tracks. Requests past a cap are counted
+// under models.APIUsageOther.
+type APIUsageLimits struct {
+	MaxJobs    int // Distinct job IDs per flush window
+	MaxClients int // Distinct clients since start
+}
+
+// APIUsageMeter counts API requests per endpoint, client and job. Counts are
+// added to the daily rollups every flush; latency histograms per endpoint
+// and request totals per client are kept since start for metrics.
+type APIUsageMeter struct {
+	mu        sync.Mutex
+	repo      *repository.APIUsageRepository
+	limits    APIUsageLimits
+	pending   map[apiUsageKey]*models.APIUsage // Since the last Drain
+	jobs      map[string]bool                  // Job IDs counted since the last Drain
+	clients   map[string]int64                 // Requests per client since start
+	endpoints map[string]*apiLatency           // Since start
+	overflow  map[string]int64                 // Requests counted under APIUsageOther, per capped dimension
+	now       func() time.Time
+}
+
+// NewAPIUsageMeter creates a meter that stores its counts with repo
+func NewAPIUsageMeter(repo *repository.APIUsageRepository, limits APIUsageLimits) *APIUsageMeter {
+	return &APIUsageMeter{
+		repo:      repo,
+		limits:    limits,
+		pending:   make(map[apiUsageKey]*models.APIUsage),
+		jobs:      make(map[string]bool),
+		clients:   make(map[string]int64),
+		endpoints: make(map[string]*apiLatency),
+		overflow:  make(map[string]int64),
+		now:       time.Now,
+	}
+}
+
+// Record counts a served request. jobID is empty for endpoints that are not
+// job-scoped.
+func (m *APIUsageMeter) Record(endpoint, client, jobID string, status int, latency time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	failed := status >= 400
+	seconds := latency.Seconds()
+
+	histogram, ok := m.endpoints[endpoint]
+	if !ok {
+		histogram = &apiLatency{buckets: make([]int64, len(apiLatencyBuckets)+1)}
+		m.endpoints[endpoint] = histogram
+	}
+	histogram.buckets[sort.SearchFloat64s(apiLatencyBuckets, seconds)]++
+	histogram.count++
+	histogram.sum += seconds
+	if failed {
+		histogram.errors++
+	}
+
+	if _, seen := m.clients[client]; !seen && len(m.clients) >= m.limits.MaxClients {
+		client = models.APIUsageOther
+		m.overflow["client"]++
+	}
+	m.clients[client]++
+
+	if jobID != "" && !m.jobs[jobID] {
+		if len(m.jobs) >= m.limits.MaxJobs {
+			jobID = models.APIUsageOther
+			m.overflow["job"]++
+		} else {
+			m.jobs[jobID] = true
+		}
+	}
+
+	key := apiUsageKey{day: m.now().UTC().Format("2006-01-02"), endpoint: endpoint, client: client, jobID: jobID}
+	usage := m.counters(key)
+	usage.Requests++
+	if failed {
+		usage.Errors++
+	}
+	usage.LatencyMs += float64(latency) / float64(time.Millisecond)
+}
+
+// counters returns the pending rollup row of key. Callers hold m.mu.
+func (m *APIUsageMeter) counters(key apiUsageKey) *models.APIUsage {
+	usage, ok := m.pending[key]
+	if !ok {
+		usage = &models.APIUsage{Day: key.day, Endpoint: key.endpoint, Client: key.client, JobID: key.jobID}
+		m.pending[key] = usage
+	}
+	return usage
+}
+
+// Drain returns the counts since the last Drain and resets them, starting a
+// new window for the job ID cap. Counts that could not be stored are handed
+// back with Restore.
+func (m *APIUsageMeter) Drain() []models.APIUsage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	usage := make([]models.APIUsage, 0, len(m.pending))
+	for _, u := range m.pending {
+		usage = append(usage, *u)
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		a, b := usage[i], usage[j]
+		if a.Day != b.Day {
+			return a.Day < b.Day
+		}
+		if a.Endpoint != b.Endpoint {
+			return a.Endpoint < b.Endpoint
+		}
+		if a.Client != b.Client {
+			return a.Client < b.Client
+		}
+		return a.JobID < b.JobID
+	})
+	m.pending = make(map[apiUsageKey]*models.APIUsage)
+	m.jobs = make(map[string]bool)
+	return usage
+}
+
+// Restore adds drained counts back so the next Drain includes them. Their
+// job IDs count against the new window's cap.
+func (m *APIUsageMeter) Restore(usage []models.APIUsage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, u := range usage {
+		pending := m.counters(apiUsageKey{day: u.Day, endpoint: u.Endpoint, client: u.Client, jobID: u.JobID})
+		pending.Requests += u.Requests
+		pending.Errors += u.Errors
+		pending.LatencyMs += u.LatencyMs
+		if u.JobID != "" && u.JobID != models.APIUsageOther {
+			m.jobs[u.JobID] = true
+		}
+	}
+}
+
+// Start flushes every interval, and once more when ctx is cancelled
+func (m *APIUsageMeter) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			m.Flush()
+			return
+		case <-ticker.C:
+			supervisor.Heartbeat(ctx)
+			m.Flush()
+		}
+	}
+}
+
+// Flush adds the counts since the last flush to the daily rollups. Counts
+// that fail to store are kept for the next flush.
+func (m *APIUsageMeter) Flush() {
+	usage := m.Drain()
+	if err := m.repo.AddUsage(usage); err != nil {
+		log.Printf("Failed to store API usage: %v", err)
+		m.Restore(usage)
+	}
+}
+
+// TrackedJobs returns how many distinct job IDs the current window counts
+func (m *APIUsageMeter) TrackedJobs() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.jobs)
+}
+
+// PrometheusMetrics exports request latency histograms per endpoint, request
+// totals per client and the requests aggregated past the caps
+func (m *APIUsageMeter) PrometheusMetrics() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+	endpoints := sortedKeys(m.endpoints)
+
+	b.WriteString("# HELP gpu_api_request_duration_seconds API request latency by endpoint\n")
+	b.WriteString("# TYPE gpu_api_request_duration_seconds histogram\n")
+	for _, endpoint := range endpoints {
+		histogram := m.endpoints[endpoint]
+		label := promLabel.Replace(endpoint)
+		var cumulative int64
+		for i, bound := range apiLatencyBuckets {
+			cumulative += histogram.buckets[i]
+			fmt.Fprintf(&b, "gpu_api_request_duration_seconds_bucket{endpoint=\"%s\",le=\"%g\"} %d\n", label, bound, cumulative)
+		}
+		fmt.Fprintf(&b, "gpu_api_request_duration_seconds_bucket{endpoint=\"%s\",le=\"+Inf\"} %d\n", label, histogram.count)
+		fmt.Fprintf(&b, "gpu_api_request_duration_seconds_sum{endpoint=\"%s\"} %.6f\n", label, histogram.sum)
+		fmt.Fprintf(&b, "gpu_api_request_duration_seconds_count{endpoint=\"%s\"} %d\n", label, histogram.count)
+	}
+
+	b.WriteString("# HELP gpu_api_request_errors_total API requests answered with a 4xx or 5xx status by endpoint\n")
+	b.WriteString("# TYPE gpu_api_request_errors_total counter\n")
+	for _, endpoint := range endpoints {
+		fmt.Fprintf(&b, "gpu_api_request_errors_total{endpoint=\"%s\"} %d\n", promLabel.Replace(endpoint), m.endpoints[endpoint].errors)
+	}
+
+	b.WriteString("# HELP gpu_api_client_requests_total API requests by client\n")
+	b.WriteString("# TYPE gpu_api_client_requests_total counter\n")
+	for _, client := range sortedKeys(m.clients) {
+		fmt.Fprintf(&b, "gpu_api_client_requests_total{client=\"%s\"} %d\n", promLabel.Replace(client), m.clients[client])
+	}
+
+	b.WriteString("# HELP gpu_api_usage_overflow_requests_total API requests counted under _other past the tracked jobs or clients cap\n")
+	b.WriteString("# TYPE gpu_api_usage_overflow_requests_total counter\n")
+	for _, dimension := range []string{"job", "client"} {
+		fmt.Fprintf(&b, "gpu_api_usage_overflow_requests_total{dimension=\"%s\"} %d\n", dimension, m.overflow[dimension])
+	}
+
+	b.WriteString("# HELP gpu_api_usage_tracked_jobs Distinct job IDs counted in the current flush window\n")
+	b.WriteString("# TYPE gpu_api_usage_tracked_jobs gauge\n")
+	fmt.Fprintf(&b, "gpu_api_usage_tracked_jobs %d\n", len(m.jobs))
+
+	return b.String()
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,50 @@
+package monitoring
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"gpu-orchestrator/core/clock"
+	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/core/supervisor"
+)
+
+// AuditRetention deletes audit log entries older than the retention period
+type AuditRetention struct {
+	repo      *repository.AuditRepository
+	retention time.Duration
+}
+
+// NewAuditRetention creates a new audit retention worker
+func NewAuditRetention(repo *repository.AuditRepository, retention time.Duration) *AuditRetention {
+	return &AuditRetention{repo: repo, retention: retention}
+}
+
+// Start prunes every interval
+func (a *AuditRetention) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			supervisor.Heartbeat(ctx)
+			a.Prune()
+		}
+	}
+}
+
+// Prune deletes the entries past retention
+func (a *AuditRetention) Prune() {
+	deleted, err := a.repo.Prune(clock.System.Now().Add(-a.retention))
+	if err != nil {
+		log.Printf("Failed to prune audit log: %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("Pruned %d audit log entries older than %s", deleted, a.retention)
+	}
+}
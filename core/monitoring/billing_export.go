@@ -0,0 +1,394 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gpu-orchestrator/core/clock"
+	"gpu-orchestrator/core/optimizer"
+	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/storage"
+
+	"github.com/google/uuid"
+)
+
+// Billing export formats
+const (
+	BillingFormatCSV     = "csv"
+	BillingFormatParquet = "parquet"
+)
+
+// ErrUnsupportedBillingFormat is returned for formats without an encoder in this build
+var ErrUnsupportedBillingFormat = errors.New("unsupported billing export format")
+
+// billingColumns is the CSV header; one compute row per job-allocation-day
+// (UTC) and one egress row per job-region-day. cost_usd is compute over the
+// job's running window, or the egress charged that day; infrastructure_cost_usd
+// the allocation's instances from launch to termination.
+var billingColumns = []string{
+	"date", "job_id", "job_name", "team_id", "project_id", "allocation_id",
+	"provider", "region", "instance_type", "spot", "instance_count",
+	"price_per_hour_usd", "hours", "gpu_hours", "cost_usd", "emissions_gco2e",
+	"instance_hours", "infrastructure_cost_usd", "overhead_cost_usd",
+	"line_item", "egress_gb",
+}
+
+// Billing line items
+const (
+	lineItemCompute = "compute"
+	lineItemEgress  = "egress"
+)
+
+// BillingLine is the usage of one allocation on one UTC day
+type BillingLine struct {
+	Date          time.Time
+	Alloc         repository.BillableAllocation
+	Hours         float64 // Wall-clock hours the allocation ran that day
+	InstanceHours float64 // Hours its instances were up that day, summed
+	Tracked       bool    // Whether the allocation has instance records
+}
+
+// GPUHours returns GPU-hours consumed by the line
+func (l BillingLine) GPUHours() float64 {
+	return l.Hours * float64(l.Alloc.Count*l.Alloc.GPUsPerInstance)
+}
+
+// CostUSD returns the compute cost of the line
+func (l BillingLine) CostUSD() float64 {
+	return l.Hours * float64(l.Alloc.Count) * l.Alloc.PricePerHour
+}
+
+// BilledInstanceHours returns the instance-hours infrastructure is billed
+// for. Allocations without instance records are billed at compute.
+func (l BillingLine) BilledInstanceHours() float64 {
+	if !l.Tracked {
+		return l.Hours * float64(l.Alloc.Count)
+	}
+	return l.InstanceHours
+}
+
+// InfrastructureCostUSD returns the cost of the line's instances from launch
+// to termination
+func (l BillingLine) InfrastructureCostUSD() float64 {
+	return l.BilledInstanceHours() * l.Alloc.PricePerHour
+}
+
+// OverheadCostUSD returns infrastructure cost beyond compute
+func (l BillingLine) OverheadCostUSD() float64 {
+	return l.InfrastructureCostUSD() - l.CostUSD()
+}
+
+// ParseBillingPeriod parses a month ("2024-05") into its UTC [start, end) bounds
+func ParseBillingPeriod(period string) (time.Time, time.Time, error) {
+	start, err := time.Parse("2006-01", period)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid period %q: expected YYYY-MM", period)
+	}
+	return start, start.AddDate(0, 1, 0), nil
+}
+
+// SplitByDay splits an allocation's running window and its instances'
+// lifetimes into UTC day lines
+func SplitByDay(usage repository.BillableUsage) []BillingLine {
+	var from, to time.Time
+	extend := func(start, end time.Time) {
+		if from.IsZero() || start.Before(from) {
+			from = start
+		}
+		if end.After(to) {
+			to = end
+		}
+	}
+	if usage.Compute {
+		extend(usage.From, usage.To)
+	}
+	for _, instance := range usage.Instances {
+		extend(instance.From, instance.To)
+	}
+
+	var lines []BillingLine
+	for day := from.UTC().Truncate(24 * time.Hour); day.Before(to); day = day.Add(24 * time.Hour) {
+		line := BillingLine{Date: day, Alloc: usage.BillableAllocation, Tracked: len(usage.Instances) > 0}
+		if usage.Compute {
+			line.Hours = overlapHours(usage.From, usage.To, day)
+		}
+		for _, instance := range usage.Instances {
+			line.InstanceHours += overlapHours(instance.From, instance.To, day)
+		}
+		if line.Hours > 0 || line.InstanceHours > 0 {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// overlapHours returns the hours of [from, to) that fall on a UTC day
+func overlapHours(from, to, day time.Time) float64 {
+	start, end := day, day.Add(24*time.Hour)
+	if from.After(start) {
+		start = from
+	}
+	if to.Before(end) {
+		end = to
+	}
+	return max(end.Sub(start).Hours(), 0)
+}
+
+// BillingExport tracks an asynchronous export to object storage
+type BillingExport struct {
+	ID          string     `json:"id"`
+	Period      string     `json:"period"`
+	Format      string     `json:"format"`
+	Status      string     `json:"status"` // running | completed | failed
+	URI         string     `json:"uri"`
+	Rows        int        `json:"rows"`
+	Bytes       int64      `json:"bytes"`
+	Error       string     `json:"error,omitempty"`
+	StartedAt   time.Time  `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// BillingExporter streams per-job, per-day billing line items for finance ingestion
+type BillingExporter struct {
+	billingRepo *repository.BillingRepository
+	stores      *storage.Registry
+	carbon      *optimizer.CarbonModel       // Optional; emissions are left blank without it
+	egressRepo  *repository.EgressRepository // Optional; egress rows are left out without it
+	exports     map[string]*BillingExport
+	mu          sync.RWMutex
+}
+
+// NewBillingExporter creates a new billing exporter.
+// stores may be nil, which disables asynchronous exports.
+func NewBillingExporter(billingRepo *repository.BillingRepository, stores *storage.Registry) *BillingExporter {
+	return &BillingExporter{
+		billingRepo: billingRepo,
+		stores:      stores,
+		exports:     make(map[string]*BillingExport),
+	}
+}
+
+// SetCarbonModel enables the emissions column
+func (be *BillingExporter) SetCarbonModel(carbon *optimizer.CarbonModel) {
+	be.carbon = carbon
+}
+
+// SetEgressRepository adds the egress charged to jobs as rows of its own
+func (be *BillingExporter) SetEgressRepository(egressRepo *repository.EgressRepository) {
+	be.egressRepo = egressRepo
+}
+
+// Write streams the export for a period to w and returns the number of rows.
+// Memory use is independent of the row count: rows are read from a database
+// cursor and encoded one at a time.
+func (be *BillingExporter) Write(ctx context.Context, w io.Writer, period, format string) (int, error) {
+	if format != BillingFormatCSV {
+		// Parquet needs an encoder dependency that is not vendored yet
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedBillingFormat, format)
+	}
+	start, end, err := ParseBillingPeriod(period)
+	if err != nil {
+		return 0, err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(billingColumns); err != nil {
+		return 0, err
+	}
+
+	rows := 0
+	err = be.billingRepo.StreamBillableUsage(start, end, func(usage repository.BillableUsage) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		for _, line := range SplitByDay(usage) {
+			if err := writer.Write(be.billingRecord(line)); err != nil {
+				return err
+			}
+			rows++
+		}
+		// Push completed rows to the client instead of holding them in the buffer
+		writer.Flush()
+		return writer.Error()
+	})
+	if err != nil {
+		return rows, fmt.Errorf("billing export failed after %d rows: %w", rows, err)
+	}
+
+	if be.egressRepo != nil {
+		err = be.egressRepo.StreamBillableEgress(start, end, func(egress repository.BillableEgress) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := writer.Write(egressRecord(egress)); err != nil {
+				return err
+			}
+			rows++
+			return nil
+		})
+		if err != nil {
+			return rows, fmt.Errorf("billing export failed after %d rows: %w", rows, err)
+		}
+	}
+
+	writer.Flush()
+	return rows, writer.Error()
+}
+
+// StartExport writes the export to destinationURI in the background.
+// The file is spooled to local disk first so the upload has a known size.
+func (be *BillingExporter) StartExport(period, format, destinationURI string) (*BillingExport, error) {
+	if be.stores == nil {
+		return nil, fmt.Errorf("object storage is not configured")
+	}
+	if format != BillingFormatCSV {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedBillingFormat, format)
+	}
+	if _, _, err := ParseBillingPeriod(period); err != nil {
+		return nil, err
+	}
+	if _, _, err := be.stores.Resolve(destinationURI); err != nil {
+		return nil, err
+	}
+
+	export := &BillingExport{
+		ID:        uuid.New().String(),
+		Period:    period,
+		Format:    format,
+		Status:    "running",
+		URI:       fmt.Sprintf("%s/billing-%s-%s.%s", strings.TrimSuffix(destinationURI, "/"), period, time.Now().UTC().Format("20060102T150405Z"), format),
+		StartedAt: clock.System.Now(),
+	}
+
+	be.mu.Lock()
+	be.exports[export.ID] = export
+	be.mu.Unlock()
+
+	go be.runExport(context.Background(), export)
+
+	snapshot := *export
+	return &snapshot, nil
+}
+
+// GetExport returns the state of an asynchronous export
+func (be *BillingExporter) GetExport(id string) (*BillingExport, bool) {
+	be.mu.RLock()
+	defer be.mu.RUnlock()
+
+	export, ok := be.exports[id]
+	if !ok {
+		return nil, false
+	}
+	snapshot := *export
+	return &snapshot, true
+}
+
+// runExport spools the export to a temporary file and uploads it
+func (be *BillingExporter) runExport(ctx context.Context, export *BillingExport) {
+	rows, size, err := be.spoolAndUpload(ctx, export)
+
+	be.mu.Lock()
+	defer be.mu.Unlock()
+
+	now := clock.System.Now()
+	export.CompletedAt = &now
+	export.Rows = rows
+	export.Bytes = size
+	if err != nil {
+		export.Status = "failed"
+		export.Error = err.Error()
+		log.Printf("Billing export %s failed: %v", export.ID, err)
+		return
+	}
+	export.Status = "completed"
+	log.Printf("Billing export %s wrote %d rows (%d bytes) to %s", export.ID, rows, size, export.URI)
+}
+
+// spoolAndUpload writes the export to a temporary file, then uploads it
+func (be *BillingExporter) spoolAndUpload(ctx context.Context, export *BillingExport) (int, int64, error) {
+	file, err := os.CreateTemp("", "billing-export-*."+export.Format)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create spool file: %w", err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	rows, err := be.Write(ctx, file, export.Period, export.Format)
+	if err != nil {
+		return rows, 0, err
+	}
+
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return rows, 0, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return rows, 0, err
+	}
+
+	if err := be.stores.Put(ctx, export.URI, file, size, "text/csv"); err != nil {
+		return rows, size, fmt.Errorf("failed to upload export: %w", err)
+	}
+	return rows, size, nil
+}
+
+// billingRecord formats a line as a CSV record matching billingColumns
+func (be *BillingExporter) billingRecord(line BillingLine) []string {
+	alloc := line.Alloc
+	emissions := ""
+	if be.carbon != nil {
+		emissions = strconv.FormatFloat(billableEmissions(be.carbon, alloc, line.Hours), 'f', 2, 64)
+	}
+	return []string{
+		line.Date.Format("2006-01-02"),
+		alloc.JobID,
+		alloc.JobName,
+		alloc.TeamID,
+		alloc.ProjectID,
+		strconv.FormatInt(alloc.AllocationID, 10),
+		string(alloc.Provider),
+		alloc.Region,
+		alloc.InstanceType,
+		strconv.FormatBool(alloc.Spot),
+		strconv.Itoa(alloc.Count),
+		strconv.FormatFloat(alloc.PricePerHour, 'f', 6, 64),
+		strconv.FormatFloat(line.Hours, 'f', 4, 64),
+		strconv.FormatFloat(line.GPUHours(), 'f', 4, 64),
+		strconv.FormatFloat(line.CostUSD(), 'f', 4, 64),
+		emissions,
+		strconv.FormatFloat(line.BilledInstanceHours(), 'f', 4, 64),
+		strconv.FormatFloat(line.InfrastructureCostUSD(), 'f', 4, 64),
+		strconv.FormatFloat(line.OverheadCostUSD(), 'f', 4, 64),
+		lineItemCompute,
+		"",
+	}
+}
+
+// egressRecord formats a job's egress on one day as a CSV record matching
+// billingColumns; columns about instances are left blank
+func egressRecord(egress repository.BillableEgress) []string {
+	return []string{
+		egress.Day.Format("2006-01-02"),
+		egress.JobID,
+		egress.JobName,
+		egress.TeamID,
+		egress.ProjectID,
+		"",
+		string(egress.Provider),
+		egress.Region,
+		"", "", "", "", "", "",
+		strconv.FormatFloat(egress.CostUSD, 'f', 4, 64),
+		"", "", "", "",
+		lineItemEgress,
+		strconv.FormatFloat(float64(egress.Bytes)/(1<<30), 'f', 4, 64),
+	}
+}
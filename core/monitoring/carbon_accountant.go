@@ -0,0 +1,85 @@
+package monitoring
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"gpu-orchestrator/core/optimizer"
+	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/core/supervisor"
+)
+
+// carbonAccountBatch caps the jobs accounted per pass
+const carbonAccountBatch = 100
+
+// CarbonAccountant stores the estimated emissions of finished jobs, from the
+// GPU-hours of their allocations and the carbon model
+type CarbonAccountant struct {
+	jobRepo     *repository.JobRepository
+	billingRepo *repository.BillingRepository
+	carbon      *optimizer.CarbonModel
+}
+
+// NewCarbonAccountant creates a new carbon accountant
+func NewCarbonAccountant(jobRepo *repository.JobRepository, billingRepo *repository.BillingRepository, carbon *optimizer.CarbonModel) *CarbonAccountant {
+	return &CarbonAccountant{jobRepo: jobRepo, billingRepo: billingRepo, carbon: carbon}
+}
+
+// Start accounts finished jobs every interval
+func (ca *CarbonAccountant) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			supervisor.Heartbeat(ctx)
+			if err := ca.Account(); err != nil {
+				log.Printf("Carbon accounting failed: %v", err)
+			}
+		}
+	}
+}
+
+// Account stores the emissions of finished jobs not yet accounted. A job that
+// never ran is accounted with zero emissions.
+func (ca *CarbonAccountant) Account() error {
+	jobIDs, err := ca.jobRepo.ListUnaccountedJobIDs(carbonAccountBatch)
+	if err != nil {
+		return err
+	}
+
+	for _, jobID := range jobIDs {
+		gco2e, err := ca.JobEmissions(jobID)
+		if err != nil {
+			log.Printf("Failed to estimate emissions of job %s: %v", jobID, err)
+			continue
+		}
+		if err := ca.jobRepo.SetEmissions(jobID, gco2e); err != nil {
+			log.Printf("Failed to store emissions of job %s: %v", jobID, err)
+		}
+	}
+	return nil
+}
+
+// JobEmissions returns the estimated gCO2e of a job's GPU-hours so far
+func (ca *CarbonAccountant) JobEmissions(jobID string) (float64, error) {
+	allocations, err := ca.billingRepo.JobBillableAllocations(jobID)
+	if err != nil {
+		return 0, err
+	}
+	total := 0.0
+	for _, alloc := range allocations {
+		total += billableEmissions(ca.carbon, alloc, alloc.To.Sub(alloc.From).Hours())
+	}
+	return total, nil
+}
+
+// billableEmissions returns the gCO2e of hours of a billable allocation
+func billableEmissions(carbon *optimizer.CarbonModel, alloc repository.BillableAllocation, hours float64) float64 {
+	gpuHours := hours * float64(alloc.Count*alloc.GPUsPerInstance)
+	return carbon.Emissions(alloc.Provider, alloc.Region, alloc.GPUType, gpuHours)
+}
@@ -2,138 +2,382 @@ package monitoring
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"sync"
 	"time"
 
+	"gpu-orchestrator/core/clock"
 	"gpu-orchestrator/core/models"
 	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/core/supervisor"
 )
 
 // CostTracker tracks real-time costs for running jobs
 type CostTracker struct {
-	jobRepo      *repository.JobRepository
-	jobCosts     map[string]*JobCost
-	mu           sync.RWMutex
-	updateTicker *time.Ticker
+	jobRepo       *repository.JobRepository
+	taskRepo      *repository.TaskRepository
+	jobCosts      map[string]*JobCost
+	taskCosts     map[string]map[int]float64 // Job ID -> task index -> accrued cost, kept after a task stops
+	overhead      map[string]*OverheadCost
+	overheadTotal float64 // Settled overhead cost
+	mu            sync.RWMutex
+	interval      time.Duration // How often running costs are accrued
+	minFlushDelta float64       // Accrued cost written to the database only once it grew this much (USD)
+	now           func() time.Time
 }
 
+// defaultMinFlushDelta skips database writes of cost changes under a cent
+const defaultMinFlushDelta = 0.01
+
 // JobCost tracks cost for a single job
 type JobCost struct {
 	JobID       string
+	Task        *int // Set for a task of a multi_task job
 	StartTime   time.Time
 	RunningCost float64
 	Allocations []models.Allocation
 	LastUpdate  time.Time
+	FlushedCost float64 // RunningCost last written to the database
+}
+
+// OverheadCost tracks cost not attributable to a running job
+// (e.g. disks of a stopped cluster waiting for its next job)
+type OverheadCost struct {
+	Key         string
+	Reason      string
+	StartTime   time.Time
+	CostPerHour float64
 }
 
 // NewCostTracker creates a new cost tracker
-func NewCostTracker(jobRepo *repository.JobRepository) *CostTracker {
+func NewCostTracker(jobRepo *repository.JobRepository, taskRepo *repository.TaskRepository) *CostTracker {
 	return &CostTracker{
-		jobRepo:      jobRepo,
-		jobCosts:     make(map[string]*JobCost),
-		updateTicker: time.NewTicker(1 * time.Minute), // Update every minute
+		jobRepo:       jobRepo,
+		taskRepo:      taskRepo,
+		jobCosts:      make(map[string]*JobCost),
+		taskCosts:     make(map[string]map[int]float64),
+		overhead:      make(map[string]*OverheadCost),
+		interval:      time.Minute,
+		minFlushDelta: defaultMinFlushDelta,
+		now:           clock.System.Now,
 	}
 }
 
+// SetInterval sets how often running costs are accrued (default 1 minute)
+func (ct *CostTracker) SetInterval(interval time.Duration) {
+	ct.interval = interval
+}
+
+// SetMinFlushDelta sets how much a running cost must grow before it is
+// written to the database (default $0.01); 0 writes every accrual
+func (ct *CostTracker) SetMinFlushDelta(delta float64) {
+	ct.minFlushDelta = delta
+}
+
 // Start starts the cost tracking worker
 func (ct *CostTracker) Start(ctx context.Context) {
+	ticker := time.NewTicker(ct.interval)
+	defer ticker.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
+			ct.Flush()
 			return
-		case <-ct.updateTicker.C:
+		case <-ticker.C:
+			supervisor.Heartbeat(ctx)
 			ct.updateAllJobCosts(ctx)
 		}
 	}
 }
 
-// TrackJob starts tracking cost for a job
-func (ct *CostTracker) TrackJob(jobID string, allocations []models.Allocation) {
+// TrackJob starts tracking cost for a job. accrued is the cost recorded for
+// earlier runs, e.g. before a suspension, so the job total keeps growing.
+func (ct *CostTracker) TrackJob(jobID string, allocations []models.Allocation, accrued float64) {
 	ct.mu.Lock()
 	defer ct.mu.Unlock()
 
 	ct.jobCosts[jobID] = &JobCost{
 		JobID:       jobID,
-		StartTime:   time.Now(),
+		StartTime:   ct.now(),
+		RunningCost: accrued,
 		Allocations: allocations,
-		LastUpdate:  time.Now(),
+		LastUpdate:  ct.now(),
+		FlushedCost: accrued,
 	}
 }
 
-// StopTracking stops tracking a job
+// StopTracking stops tracking a job and forgets the cost of its tasks. Cost
+// accrued but not yet written is written first.
 func (ct *CostTracker) StopTracking(jobID string) {
 	ct.mu.Lock()
-	defer ct.mu.Unlock()
-
+	jobCost, exists := ct.jobCosts[jobID]
 	delete(ct.jobCosts, jobID)
-}
+	delete(ct.taskCosts, jobID)
+	ct.mu.Unlock()
 
-// updateAllJobCosts updates costs for all tracked jobs
-func (ct *CostTracker) updateAllJobCosts(ctx context.Context) {
-	ct.mu.RLock()
-	jobIDs := make([]string, 0, len(ct.jobCosts))
-	for jobID := range ct.jobCosts {
-		jobIDs = append(jobIDs, jobID)
+	if exists && jobCost.RunningCost != jobCost.FlushedCost {
+		if err := ct.jobRepo.UpdateJobCost(jobID, jobCost.RunningCost); err != nil {
+			log.Printf("Failed to update cost for job %s: %v", jobID, err)
+		}
 	}
-	ct.mu.RUnlock()
+}
+
+// taskCostKey is the jobCosts key of a task
+func taskCostKey(jobID string, index int) string {
+	return fmt.Sprintf("%s/task-%d", jobID, index)
+}
 
-	for _, jobID := range jobIDs {
-		ct.updateJobCost(ctx, jobID)
+// TrackTask starts tracking one attempt of a task on its own allocation.
+// accrued is the cost of earlier attempts so the task total keeps growing.
+func (ct *CostTracker) TrackTask(jobID string, index int, allocation models.Allocation, accrued float64) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	task := index
+	ct.jobCosts[taskCostKey(jobID, index)] = &JobCost{
+		JobID:       jobID,
+		Task:        &task,
+		StartTime:   ct.now(),
+		RunningCost: accrued,
+		Allocations: []models.Allocation{allocation},
+		LastUpdate:  ct.now(),
 	}
+	ct.setTaskCost(jobID, index, accrued)
 }
 
-// updateJobCost updates cost for a single job
-func (ct *CostTracker) updateJobCost(_ context.Context, jobID string) {
+// StopTrackingTask settles and stops tracking a task's current attempt,
+// returning the task's accrued cost over all attempts. Sibling tasks are
+// not affected.
+func (ct *CostTracker) StopTrackingTask(jobID string, index int) float64 {
 	ct.mu.Lock()
-	jobCost, exists := ct.jobCosts[jobID]
+	defer ct.mu.Unlock()
+
+	key := taskCostKey(jobID, index)
+	jobCost, exists := ct.jobCosts[key]
 	if !exists {
-		ct.mu.Unlock()
+		return ct.taskCosts[jobID][index]
+	}
+	ct.settle(jobCost, ct.now())
+	delete(ct.jobCosts, key)
+	return jobCost.RunningCost
+}
+
+// setTaskCost records a task's accrued cost. Caller must hold ct.mu.
+func (ct *CostTracker) setTaskCost(jobID string, index int, cost float64) {
+	if ct.taskCosts[jobID] == nil {
+		ct.taskCosts[jobID] = make(map[int]float64)
+	}
+	ct.taskCosts[jobID][index] = cost
+}
+
+// pendingCost is an accrued cost to write to the database
+type pendingCost struct {
+	cost  *JobCost
+	value float64 // Value to write; the JobCost may accrue further while it is written
+}
+
+// updateAllJobCosts accrues the cost of every tracked job that is running,
+// reading their statuses in one query, and writes the costs that grew by at
+// least minFlushDelta in one batch. Jobs that stopped running since the last
+// tick stop accruing, and their unwritten cost is written regardless of the
+// threshold.
+func (ct *CostTracker) updateAllJobCosts(_ context.Context) {
+	ct.mu.RLock()
+	ids := make([]string, 0, len(ct.jobCosts))
+	seen := make(map[string]bool, len(ct.jobCosts))
+	for _, jobCost := range ct.jobCosts {
+		if !seen[jobCost.JobID] {
+			seen[jobCost.JobID] = true
+			ids = append(ids, jobCost.JobID)
+		}
+	}
+	ct.mu.RUnlock()
+	if len(ids) == 0 {
 		return
 	}
-	ct.mu.Unlock()
 
-	// Get current job status
-	job, err := ct.jobRepo.GetJob(jobID)
+	statuses, err := ct.jobRepo.GetJobStatuses(ids)
 	if err != nil {
-		log.Printf("Failed to fetch job %s for cost update: %v", jobID, err)
+		log.Printf("Failed to fetch job statuses for cost update: %v", err)
 		return
 	}
 
-	// Only track costs for running jobs
-	if job.Status != models.JobStatusRunning {
+	now := ct.now()
+	var pending []pendingCost
+	ct.mu.Lock()
+	for _, jobCost := range ct.jobCosts {
+		status, ok := statuses[jobCost.JobID]
+		if !ok {
+			continue // Created after the status query or deleted
+		}
+		if status.IsRunning() {
+			ct.settle(jobCost, now)
+			if jobCost.RunningCost-jobCost.FlushedCost < ct.minFlushDelta {
+				continue
+			}
+		} else if jobCost.RunningCost == jobCost.FlushedCost {
+			continue
+		}
+		pending = append(pending, pendingCost{cost: jobCost, value: jobCost.RunningCost})
+	}
+	ct.mu.Unlock()
+
+	ct.write(pending)
+}
+
+// Flush writes every accrued cost not yet in the database, e.g. on shutdown.
+// Costs are not accrued further, since job statuses are not re-read.
+func (ct *CostTracker) Flush() {
+	var pending []pendingCost
+	ct.mu.RLock()
+	for _, jobCost := range ct.jobCosts {
+		if jobCost.RunningCost != jobCost.FlushedCost {
+			pending = append(pending, pendingCost{cost: jobCost, value: jobCost.RunningCost})
+		}
+	}
+	ct.mu.RUnlock()
+
+	ct.write(pending)
+}
+
+// write stores pending costs: jobs in one batch, tasks one by one since each
+// rolls up into its job's cost. Written values are remembered as flushed.
+func (ct *CostTracker) write(pending []pendingCost) {
+	if len(pending) == 0 {
 		return
 	}
 
-	// Calculate delta time since last update
-	now := time.Now()
+	jobs := make(map[string]float64)
+	var written []pendingCost
+	for _, p := range pending {
+		if p.cost.Task == nil {
+			jobs[p.cost.JobID] = p.value
+			continue
+		}
+		if ct.taskRepo == nil {
+			continue
+		}
+		if err := ct.taskRepo.UpdateTaskCost(p.cost.JobID, *p.cost.Task, p.value); err != nil {
+			log.Printf("Failed to update cost for task %d of job %s: %v", *p.cost.Task, p.cost.JobID, err)
+			continue
+		}
+		written = append(written, p)
+	}
+	if len(jobs) > 0 {
+		if err := ct.jobRepo.UpdateJobCosts(jobs); err != nil {
+			log.Printf("Failed to update cost for %d jobs: %v", len(jobs), err)
+		} else {
+			for _, p := range pending {
+				if p.cost.Task == nil {
+					written = append(written, p)
+				}
+			}
+		}
+	}
+
+	ct.mu.Lock()
+	for _, p := range written {
+		p.cost.FlushedCost = p.value
+	}
+	ct.mu.Unlock()
+}
+
+// settle accrues cost at the current allocations up to now. Caller must hold ct.mu.
+func (ct *CostTracker) settle(jobCost *JobCost, now time.Time) {
 	deltaHours := now.Sub(jobCost.LastUpdate).Hours()
 
-	// Calculate cost for delta time
 	deltaCost := 0.0
 	for _, alloc := range jobCost.Allocations {
-		deltaCost += alloc.PricePerHour * float64(alloc.Count) * deltaHours
+		deltaCost += alloc.HourlyPrice() * float64(alloc.Count) * deltaHours
 	}
 
-	// Update running cost
 	jobCost.RunningCost += deltaCost
 	jobCost.LastUpdate = now
+	if jobCost.Task != nil {
+		ct.setTaskCost(jobCost.JobID, *jobCost.Task, jobCost.RunningCost)
+	}
+}
 
-	// Update in database
-	if err := ct.jobRepo.UpdateJobCost(jobID, jobCost.RunningCost); err != nil {
-		log.Printf("Failed to update cost for job %s: %v", jobID, err)
+// UpdateAllocations replaces a job's allocations after an elastic resize.
+// Cost accrued at the old node count is settled first so the new count only
+// applies from now on. Jobs not yet tracked start tracking from their
+// recorded cost.
+func (ct *CostTracker) UpdateAllocations(jobID string, allocations []models.Allocation) {
+	ct.mu.Lock()
+	jobCost, exists := ct.jobCosts[jobID]
+	if exists {
+		ct.settle(jobCost, ct.now())
+		jobCost.Allocations = allocations
+	}
+	ct.mu.Unlock()
+
+	if !exists {
+		var accrued float64
+		if job, err := ct.jobRepo.GetJob(jobID); err != nil {
+			log.Printf("Failed to load recorded cost of job %s: %v", jobID, err)
+		} else {
+			accrued = job.CostRunningUSD
+		}
+		ct.TrackJob(jobID, allocations, accrued)
 	}
 }
 
-// GetRunningCost returns the current running cost for a job
-func (ct *CostTracker) GetRunningCost(jobID string) float64 {
-	ct.mu.RLock()
-	defer ct.mu.RUnlock()
+// StartOverhead starts accruing overhead cost under key
+func (ct *CostTracker) StartOverhead(key, reason string, costPerHour float64) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
 
-	jobCost, exists := ct.jobCosts[jobID]
+	ct.overhead[key] = &OverheadCost{
+		Key:         key,
+		Reason:      reason,
+		StartTime:   ct.now(),
+		CostPerHour: costPerHour,
+	}
+}
+
+// StopOverhead settles and stops an overhead entry, returning its accrued cost
+func (ct *CostTracker) StopOverhead(key string) float64 {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	entry, exists := ct.overhead[key]
 	if !exists {
 		return 0.0
 	}
+	delete(ct.overhead, key)
 
-	return jobCost.RunningCost
+	cost := entry.CostPerHour * ct.now().Sub(entry.StartTime).Hours()
+	ct.overheadTotal += cost
+	log.Printf("Overhead %s (%s) settled: $%.4f", key, entry.Reason, cost)
+	return cost
+}
+
+// GetOverheadCost returns settled plus currently accruing overhead cost
+func (ct *CostTracker) GetOverheadCost() float64 {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+
+	total := ct.overheadTotal
+	for _, entry := range ct.overhead {
+		total += entry.CostPerHour * ct.now().Sub(entry.StartTime).Hours()
+	}
+	return total
+}
+
+// GetRunningCost returns the current running cost for a job, including
+// the cost of its tasks
+func (ct *CostTracker) GetRunningCost(jobID string) float64 {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+
+	total := 0.0
+	if jobCost, exists := ct.jobCosts[jobID]; exists {
+		total = jobCost.RunningCost
+	}
+	for _, cost := range ct.taskCosts[jobID] {
+		total += cost
+	}
+	return total
 }
@@ -0,0 +1,138 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"gpu-orchestrator/core/clock"
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/core/supervisor"
+)
+
+// imageBootWindow is how many of an image's latest boots are compared to its
+// budget. The median is used so one slow launch does not degrade an image.
+const imageBootWindow = 3
+
+// ImageBootMonitor flags registered machine images whose instances boot
+// slower than the image's budget. A degraded image is no longer preferred for
+// its family, so new instances fall back to the generic image until an
+// operator validates it again.
+type ImageBootMonitor struct {
+	repo     *repository.MachineImageRepository
+	notifier Notifier // Optional; nil only logs
+	now      func() time.Time
+}
+
+// NewImageBootMonitor creates a new image boot monitor
+func NewImageBootMonitor(repo *repository.MachineImageRepository) *ImageBootMonitor {
+	return &ImageBootMonitor{repo: repo, now: clock.System.Now}
+}
+
+// SetNotifier sets where degraded images are announced
+func (m *ImageBootMonitor) SetNotifier(notifier Notifier) {
+	m.notifier = notifier
+}
+
+// Start checks the registry every interval until ctx is done
+func (m *ImageBootMonitor) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			supervisor.Heartbeat(ctx)
+			if err := m.Check(ctx); err != nil {
+				log.Printf("Image boot check failed: %v", err)
+			}
+		}
+	}
+}
+
+// Check degrades every validated image whose median boot over its latest
+// imageBootWindow boots exceeds its budget
+func (m *ImageBootMonitor) Check(ctx context.Context) error {
+	images, err := m.repo.ListImages("")
+	if err != nil {
+		return fmt.Errorf("failed to list machine images: %w", err)
+	}
+	for i := range images {
+		image := &images[i]
+		if !image.Usable(false) || image.BootBudget <= 0 {
+			continue
+		}
+		boots, err := m.repo.RecentBoots(image.ID, imageBootWindow)
+		if err != nil {
+			log.Printf("Failed to load boots of image %s: %v", image.ImageID, err)
+			continue
+		}
+		if len(boots) < imageBootWindow {
+			continue
+		}
+		observed := time.Duration(medianBootSeconds(boots) * float64(time.Second))
+		if observed <= image.BootBudget {
+			continue
+		}
+		m.degrade(ctx, image, observed)
+	}
+	return nil
+}
+
+// degrade flags an image and announces it
+func (m *ImageBootMonitor) degrade(ctx context.Context, image *models.MachineImage, observed time.Duration) {
+	now := m.now()
+	reason := fmt.Sprintf("median boot %s over the last %d boots exceeds the %s budget",
+		observed.Round(time.Second), imageBootWindow, image.BootBudget)
+	marked, err := m.repo.MarkDegraded(image.ID, reason, now)
+	if err != nil {
+		log.Printf("Failed to degrade image %s: %v", image.ImageID, err)
+		return
+	}
+	if !marked {
+		return
+	}
+	log.Printf("Degraded image %s (%s %s %s): %s", image.ImageID, image.Provider, image.Region, image.InstanceFamily, reason)
+	if m.notifier == nil {
+		return
+	}
+
+	err = m.notifier.Notify(ctx, Notification{
+		Subject: fmt.Sprintf("Machine image %s degraded in %s %s", image.ImageID, image.Provider, image.Region),
+		Message: fmt.Sprintf("Image %s for %s instances in %s %s is degraded: %s. New %s instances boot the generic image until it is validated again.",
+			image.ImageID, image.InstanceFamily, image.Provider, image.Region, reason, image.InstanceFamily),
+		Source: "image_boot_monitor",
+		Meta: map[string]interface{}{
+			"machine_image_id":    image.ID,
+			"image_id":            image.ImageID,
+			"provider":            image.Provider,
+			"region":              image.Region,
+			"instance_family":     image.InstanceFamily,
+			"boot_seconds":        observed.Seconds(),
+			"boot_budget_seconds": image.BootBudget.Seconds(),
+		},
+		SentAt: now,
+	})
+	if err != nil {
+		log.Printf("Failed to notify about degraded image %s: %v", image.ImageID, err)
+	}
+}
+
+// medianBootSeconds returns the median boot time of boots
+func medianBootSeconds(boots []models.ImageBoot) float64 {
+	seconds := make([]float64, len(boots))
+	for i, boot := range boots {
+		seconds[i] = boot.BootSeconds
+	}
+	sort.Float64s(seconds)
+	mid := len(seconds) / 2
+	if len(seconds)%2 == 0 {
+		return (seconds[mid-1] + seconds[mid]) / 2
+	}
+	return seconds[mid]
+}
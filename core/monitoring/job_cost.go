@@ -0,0 +1,130 @@
+package monitoring
+
+import (
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/repository"
+)
+
+// JobCost returns a job's compute and infrastructure cost so far and
+// reconciles the infrastructure cost with the job's estimate
+func (be *BillingExporter) JobCost(job *models.Job) (*models.JobCost, error) {
+	usage, err := be.billingRepo.JobBillableUsage(job.ID)
+	if err != nil {
+		return nil, err
+	}
+	cost := BuildJobCost(job, usage)
+	if len(job.Requirements.TaskGroups) > 0 {
+		groups, err := be.billingRepo.JobAllocationTaskGroups(job.ID)
+		if err != nil {
+			return nil, err
+		}
+		AttributeTaskGroups(cost, job.Requirements.TaskGroups, groups)
+	}
+	return cost, nil
+}
+
+// AttributeTaskGroups tags a job cost's allocations with their task group,
+// given by allocation ID, and sums the cost of each group
+func AttributeTaskGroups(cost *models.JobCost, taskGroups []models.TaskGroup, groupOf map[int64]string) {
+	index := make(map[string]int, len(taskGroups))
+	cost.TaskGroups = make([]models.TaskGroupCost, len(taskGroups))
+	for i, group := range taskGroups {
+		index[group.Name] = i
+		cost.TaskGroups[i].Name = group.Name
+	}
+	for i := range cost.Allocations {
+		allocation := &cost.Allocations[i]
+		allocation.TaskGroup = groupOf[allocation.AllocationID]
+		g, ok := index[allocation.TaskGroup]
+		if !ok {
+			continue
+		}
+		group := &cost.TaskGroups[g]
+		group.Allocations++
+		group.ComputeUSD += allocation.ComputeUSD
+		group.InfrastructureUSD += allocation.InfrastructureUSD
+		group.OverheadUSD += allocation.OverheadUSD
+	}
+}
+
+// BuildJobCost sums a job's billable usage into its cost breakdown
+func BuildJobCost(job *models.Job, usage []repository.BillableUsage) *models.JobCost {
+	cost := &models.JobCost{
+		JobID:       job.ID,
+		RunningUSD:  job.CostRunningUSD,
+		TransferUSD: job.CostTransferUSD,
+		EgressUSD:   job.CostEgressUSD,
+		EgressGB:    float64(job.EgressBytes) / (1 << 30),
+		Allocations: make([]models.AllocationCost, 0, len(usage)),
+	}
+
+	for _, group := range usage {
+		// One line over the whole life; hours are summed, not split by day
+		line := BillingLine{Alloc: group.BillableAllocation, Tracked: len(group.Instances) > 0}
+		if group.Compute {
+			line.Hours = group.To.Sub(group.From).Hours()
+		}
+		for _, instance := range group.Instances {
+			line.InstanceHours += instance.To.Sub(instance.From).Hours()
+			if instance.Open {
+				cost.OpenInstances++
+			}
+		}
+		cost.Instances += len(group.Instances)
+		if !line.Tracked {
+			cost.UntrackedAllocations++
+		}
+
+		allocation := models.AllocationCost{
+			AllocationID:      group.AllocationID,
+			Provider:          group.Provider,
+			Region:            group.Region,
+			InstanceType:      group.InstanceType,
+			Spot:              group.Spot,
+			PricePerHour:      group.PricePerHour,
+			ComputeHours:      line.Hours * float64(group.Count),
+			InstanceHours:     line.BilledInstanceHours(),
+			ComputeUSD:        line.CostUSD(),
+			InfrastructureUSD: line.InfrastructureCostUSD(),
+			OverheadUSD:       line.OverheadCostUSD(),
+		}
+		cost.Allocations = append(cost.Allocations, allocation)
+		cost.ComputeUSD += allocation.ComputeUSD
+		cost.InfrastructureUSD += allocation.InfrastructureUSD
+	}
+	cost.OverheadUSD = cost.InfrastructureUSD - cost.ComputeUSD
+
+	cost.Reconciliation = models.CostReconciliation{
+		EstimatedUSD:      job.CostEstimatedUSD,
+		InfrastructureUSD: cost.InfrastructureUSD,
+		Transfer: models.TransferReconciliation{
+			ObservedUSD: job.CostTransferUSD + job.CostEgressUSD,
+		},
+	}
+	if job.CostEstimatedUSD != nil {
+		variance := cost.InfrastructureUSD - *job.CostEstimatedUSD
+		cost.Reconciliation.VarianceUSD = &variance
+		if *job.CostEstimatedUSD > 0 {
+			ratio := variance / *job.CostEstimatedUSD
+			cost.Reconciliation.VarianceRatio = &ratio
+		}
+	}
+	return cost
+}
+
+// ReconcileTransfer compares a job cost's observed transfer with the data
+// transfer cost of the strategy the optimizer chose
+func ReconcileTransfer(cost *models.JobCost, decision *models.AllocationDecision) {
+	if decision == nil {
+		return
+	}
+	chosen, ok := decision.ChosenStrategy()
+	if !ok {
+		return
+	}
+	transfer := &cost.Reconciliation.Transfer
+	estimated := chosen.DataTransferCost
+	variance := transfer.ObservedUSD - estimated
+	transfer.EstimatedUSD = &estimated
+	transfer.VarianceUSD = &variance
+}
@@ -2,11 +2,14 @@ package monitoring
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"time"
 
+	"gpu-orchestrator/core/clock"
 	"gpu-orchestrator/core/models"
 	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/core/supervisor"
 )
 
 // JobMonitor monitors job execution and health
@@ -14,6 +17,12 @@ import (
 type JobMonitor struct {
 	jobRepo     *repository.JobRepository
 	costTracker *CostTracker
+	interval    time.Duration
+
+	// Checkpoint cadence of spot-backed jobs
+	artifactRepo *repository.ArtifactRepository // nil = not checked
+	behindSince  map[string]time.Time           // Job ID -> baseline last warned about
+	now          func() time.Time
 }
 
 // NewJobMonitor creates a new job monitor
@@ -24,12 +33,127 @@ func NewJobMonitor(
 	return &JobMonitor{
 		jobRepo:     jobRepo,
 		costTracker: costTracker,
+		interval:    30 * time.Second,
+		behindSince: make(map[string]time.Time),
+		now:         clock.System.Now,
+	}
+}
+
+// SetInterval sets how often running jobs are checked (default 30 seconds)
+func (jm *JobMonitor) SetInterval(interval time.Duration) {
+	jm.interval = interval
+}
+
+// SetCheckpointCadence enables the checkpoint cadence check of spot-backed
+// jobs that declare a checkpoint schedule
+func (jm *JobMonitor) SetCheckpointCadence(artifactRepo *repository.ArtifactRepository) {
+	jm.artifactRepo = artifactRepo
+}
+
+// StartCheckpointCadence checks checkpoint cadence every interval until ctx
+// is done
+func (jm *JobMonitor) StartCheckpointCadence(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			supervisor.Heartbeat(ctx)
+			if err := jm.CheckCheckpointCadence(ctx); err != nil {
+				log.Printf("Checkpoint cadence check failed: %v", err)
+			}
+		}
+	}
+}
+
+// CheckCheckpointCadence records a checkpointing_behind event for every
+// running spot-backed job that registered no checkpoint within
+// models.CheckpointBehindFactor of its declared interval. Each job is warned
+// once per latest checkpoint.
+func (jm *JobMonitor) CheckCheckpointCadence(ctx context.Context) error {
+	if jm.artifactRepo == nil {
+		return nil
+	}
+	ids, err := jm.jobRepo.ListSpotCheckpointedJobIDs()
+	if err != nil {
+		return fmt.Errorf("failed to list checkpointed spot jobs: %w", err)
+	}
+
+	checked := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		checked[id] = true
+		job, err := jm.jobRepo.GetJob(id)
+		if err != nil {
+			log.Printf("Failed to load job %s for checkpoint cadence: %v", id, err)
+			continue
+		}
+		latest, err := jm.latestCheckpoint(id)
+		if err != nil {
+			log.Printf("Checkpoint cadence of job %s skipped: %v", id, err)
+			continue
+		}
+		cadence := models.CheckpointingBehind(job, latest, jm.now())
+		if cadence == nil || !cadence.Behind {
+			delete(jm.behindSince, id)
+			continue
+		}
+		if since, ok := jm.behindSince[id]; ok && since.Equal(cadence.Since) {
+			continue
+		}
+
+		meta := map[string]interface{}{
+			"since":          cadence.Since,
+			"limit":          cadence.Limit,
+			"checkpoint_uri": job.Checkpointing.JobURI(job.ID),
+		}
+		if job.Checkpointing.IntervalSteps > 0 {
+			meta["interval_steps"] = job.Checkpointing.IntervalSteps
+			meta["steps_since"] = cadence.StepsSince
+		} else {
+			meta["interval_minutes"] = job.Checkpointing.IntervalMinutes
+			meta["minutes_since"] = cadence.MinutesSince
+		}
+		running := models.JobStatusRunning
+		if err := jm.jobRepo.CreateJobEvent(id, &running, running, "checkpointing_behind", meta); err != nil {
+			log.Printf("Failed to record checkpointing_behind for job %s: %v", id, err)
+			continue
+		}
+		jm.behindSince[id] = cadence.Since
+		log.Printf("WARNING: Job %s is behind its checkpoint schedule, no checkpoint since %s", id, cadence.Since.Format(time.RFC3339))
+	}
+
+	// Forget jobs that finished or left spot capacity
+	for id := range jm.behindSince {
+		if !checked[id] {
+			delete(jm.behindSince, id)
+		}
+	}
+	return nil
+}
+
+// latestCheckpoint returns the job's newest checkpoint artifact, or nil.
+// Artifacts are listed newest first.
+func (jm *JobMonitor) latestCheckpoint(jobID string) (*models.JobArtifact, error) {
+	checkpointType := models.ArtifactTypeCheckpoint
+	artifacts, err := jm.artifactRepo.GetJobArtifacts(jobID, &checkpointType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load checkpoints: %w", err)
+	}
+	if len(artifacts) == 0 {
+		return nil, nil
 	}
+	return &artifacts[0], nil
 }
 
 // Start starts the job monitoring loop
 func (jm *JobMonitor) Start(ctx context.Context) {
-	ticker := time.NewTicker(30 * time.Second) // Check every 30 seconds
+	ticker := time.NewTicker(jm.interval)
 	defer ticker.Stop()
 
 	for {
@@ -37,6 +161,7 @@ func (jm *JobMonitor) Start(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
+			supervisor.Heartbeat(ctx)
 			jm.monitorRunningJobs(ctx)
 		}
 	}
@@ -3,9 +3,11 @@ package monitoring
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"gpu-orchestrator/core/models"
 	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/core/supervisor"
 )
 
 // MetricsExporter exports metrics for Prometheus/Grafana
@@ -13,6 +15,13 @@ import (
 type MetricsExporter struct {
 	jobRepo     *repository.JobRepository
 	costTracker *CostTracker
+	workers     *supervisor.Supervisor // Optional; adds background worker health
+	sources     []MetricsSource
+}
+
+// MetricsSource is a component that exports its own Prometheus metrics
+type MetricsSource interface {
+	PrometheusMetrics() string
 }
 
 // NewMetricsExporter creates a new metrics exporter
@@ -23,6 +32,16 @@ func NewMetricsExporter(jobRepo *repository.JobRepository, costTracker *CostTrac
 	}
 }
 
+// SetSupervisor adds the health of supervised background workers to the metrics
+func (me *MetricsExporter) SetSupervisor(workers *supervisor.Supervisor) {
+	me.workers = workers
+}
+
+// AddSource appends a component's metrics to the export
+func (me *MetricsExporter) AddSource(source MetricsSource) {
+	me.sources = append(me.sources, source)
+}
+
 // GetPrometheusMetrics returns metrics in Prometheus format
 func (me *MetricsExporter) GetPrometheusMetrics() string {
 	// Get all running jobs
@@ -83,6 +102,42 @@ func (me *MetricsExporter) GetPrometheusMetrics() string {
 		metrics += fmt.Sprintf("gpu_project_cost_usd{project_id=\"%s\"} %.4f\n", projectID, cost)
 	}
 
+	if me.workers != nil {
+		metrics += workerMetrics(me.workers.Status(), time.Now())
+	}
+	for _, source := range me.sources {
+		metrics += source.PrometheusMetrics()
+	}
+
+	return metrics
+}
+
+// workerMetrics formats background worker health
+func workerMetrics(statuses []supervisor.WorkerStatus, now time.Time) string {
+	var metrics string
+
+	metrics += "# HELP gpu_worker_healthy Whether a background worker is running and heartbeating\n"
+	metrics += "# TYPE gpu_worker_healthy gauge\n"
+	for _, status := range statuses {
+		healthy := 0
+		if status.Healthy() {
+			healthy = 1
+		}
+		metrics += fmt.Sprintf("gpu_worker_healthy{worker=\"%s\",state=\"%s\"} %d\n", status.Name, status.State, healthy)
+	}
+
+	metrics += "# HELP gpu_worker_restarts_total Restarts of a background worker after crashes\n"
+	metrics += "# TYPE gpu_worker_restarts_total counter\n"
+	for _, status := range statuses {
+		metrics += fmt.Sprintf("gpu_worker_restarts_total{worker=\"%s\"} %d\n", status.Name, status.Restarts)
+	}
+
+	metrics += "# HELP gpu_worker_heartbeat_age_seconds Time since a background worker last heartbeated\n"
+	metrics += "# TYPE gpu_worker_heartbeat_age_seconds gauge\n"
+	for _, status := range statuses {
+		metrics += fmt.Sprintf("gpu_worker_heartbeat_age_seconds{worker=\"%s\"} %.0f\n", status.Name, now.Sub(status.LastHeartbeat).Seconds())
+	}
+
 	return metrics
 }
 
@@ -102,7 +157,7 @@ func (me *MetricsExporter) GetCostByTeam(ctx context.Context) (map[string]float6
 		}
 
 		var cost float64
-		if job.Status == models.JobStatusRunning {
+		if job.Status.IsRunning() {
 			cost = me.costTracker.GetRunningCost(job.ID)
 		} else if job.CostRunningUSD > 0 {
 			cost = job.CostRunningUSD
@@ -131,7 +186,7 @@ func (me *MetricsExporter) GetCostByProject(ctx context.Context) (map[string]flo
 		}
 
 		var cost float64
-		if job.Status == models.JobStatusRunning {
+		if job.Status.IsRunning() {
 			cost = me.costTracker.GetRunningCost(job.ID)
 		} else if job.CostRunningUSD > 0 {
 			cost = job.CostRunningUSD
@@ -0,0 +1,172 @@
+package monitoring
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"gpu-orchestrator/core/supervisor"
+)
+
+// Notification is a message delivered to operators
+type Notification struct {
+	Subject string                 `json:"subject"`
+	Message string                 `json:"message"`
+	Source  string                 `json:"source"` // e.g. alert rule name
+	Meta    map[string]interface{} `json:"meta,omitempty"`
+	SentAt  time.Time              `json:"sent_at"`
+}
+
+// Notifier delivers notifications to a single destination
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// NotifyWorkerStall returns a supervisor stall handler that notifies operators
+// when a background worker stops heartbeating or is given up on
+func NotifyWorkerStall(notifier Notifier) supervisor.StallHandler {
+	return func(ctx context.Context, status supervisor.WorkerStatus) {
+		condition := "has not heartbeated for " + time.Since(status.LastHeartbeat).Round(time.Second).String()
+		if status.State == supervisor.StateFailed {
+			condition = fmt.Sprintf("failed after %d restarts", status.Restarts)
+		}
+		err := notifier.Notify(ctx, Notification{
+			Subject: fmt.Sprintf("Background worker %s %s", status.Name, condition),
+			Message: workerStallMessage(status, condition),
+			Source:  "supervisor",
+			Meta: map[string]interface{}{
+				"worker":   status.Name,
+				"state":    status.State,
+				"restarts": status.Restarts,
+			},
+			SentAt: time.Now(),
+		})
+		if err != nil {
+			log.Printf("Failed to notify about worker %s: %v", status.Name, err)
+		}
+	}
+}
+
+// workerStallMessage describes a stalled or failed worker
+func workerStallMessage(status supervisor.WorkerStatus, condition string) string {
+	message := fmt.Sprintf("Worker %s %s (expected heartbeat every %s).", status.Name, condition, status.Interval)
+	if status.LastError != "" {
+		message += " Last error: " + strings.SplitN(status.LastError, "\n", 2)[0]
+	}
+	return message
+}
+
+// WebhookNotifier posts notifications as JSON to a webhook URL.
+// The payload includes a "text" field so Slack/Mattermost incoming webhooks render it directly.
+type WebhookNotifier struct {
+	url        string
+	httpClient *http.Client
+}
+
+// NewWebhookNotifier creates a new webhook notifier
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:        url,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Notify posts the notification to the webhook
+func (w *WebhookNotifier) Notify(ctx context.Context, n Notification) error {
+	payload, err := json.Marshal(struct {
+		Notification
+		Text string `json:"text"`
+	}{
+		Notification: n,
+		Text:         fmt.Sprintf("*%s*\n%s", n.Subject, n.Message),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook delivery failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, string(msg))
+	}
+
+	return nil
+}
+
+// EmailSender sends plain-text email
+type EmailSender interface {
+	Send(ctx context.Context, to []string, subject, body string) error
+}
+
+// EmailNotifier delivers notifications to a fixed set of recipients through an EmailSender
+type EmailNotifier struct {
+	sender     EmailSender
+	recipients []string
+}
+
+// NewEmailNotifier creates a new email notifier
+func NewEmailNotifier(sender EmailSender, recipients []string) *EmailNotifier {
+	return &EmailNotifier{sender: sender, recipients: recipients}
+}
+
+// Notify emails the notification to all recipients
+func (e *EmailNotifier) Notify(ctx context.Context, n Notification) error {
+	return e.sender.Send(ctx, e.recipients, n.Subject, n.Message)
+}
+
+// LogEmailSender logs emails instead of sending them (used when SMTP is not configured)
+type LogEmailSender struct{}
+
+// Send logs the email
+func (LogEmailSender) Send(_ context.Context, to []string, subject, body string) error {
+	log.Printf("Email to %s: %s\n%s", strings.Join(to, ", "), subject, body)
+	return nil
+}
+
+// SMTPEmailSender sends email through an SMTP relay
+type SMTPEmailSender struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// NewSMTPEmailSender creates a new SMTP sender; auth is skipped when username is empty
+func NewSMTPEmailSender(host, port, from, username, password string) *SMTPEmailSender {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &SMTPEmailSender{
+		addr: host + ":" + port,
+		from: from,
+		auth: auth,
+	}
+}
+
+// Send sends the email
+func (s *SMTPEmailSender) Send(_ context.Context, to []string, subject, body string) error {
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s\r\n",
+		s.from, strings.Join(to, ", "), subject, body)
+	if err := smtp.SendMail(s.addr, s.auth, s.from, to, []byte(msg)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gpu-orchestrator/core/clock"
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/core/supervisor"
+)
+
+// orphanSampleSize is how many keys a report lists per category
+const orphanSampleSize = 20
+
+// OrphanPolicy configures the orphan cleaner
+type OrphanPolicy struct {
+	Apply     bool          // Scheduled passes delete what they find
+	Retention time.Duration // Records of jobs that ended more recently are kept
+	BatchSize int           // Rows deleted per statement
+}
+
+// OrphanCleaner finds records left behind by missing or ended jobs:
+// allocations and events whose job is gone (rows written before foreign keys
+// or with them disabled), planned allocations of jobs that never started,
+// egress samples and reservations no job needs anymore, and artifact rows
+// whose objects retention GC already deleted. It reports them, and deletes
+// them in batches when applied.
+type OrphanCleaner struct {
+	repo   *repository.OrphanRepository
+	audit  *repository.AuditRepository // Optional; applied passes are recorded
+	policy OrphanPolicy
+	now    func() time.Time
+	mu     sync.Mutex // One pass at a time
+}
+
+// NewOrphanCleaner creates a new orphan cleaner
+func NewOrphanCleaner(repo *repository.OrphanRepository, policy OrphanPolicy) *OrphanCleaner {
+	return &OrphanCleaner{repo: repo, policy: policy, now: clock.System.Now}
+}
+
+// SetAuditRepository records applied passes in the audit log
+func (c *OrphanCleaner) SetAuditRepository(audit *repository.AuditRepository) {
+	c.audit = audit
+}
+
+// ApplyDefault reports whether scheduled passes delete what they find
+func (c *OrphanCleaner) ApplyDefault() bool {
+	return c.policy.Apply
+}
+
+// Start runs a pass every interval in the configured mode
+func (c *OrphanCleaner) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			supervisor.Heartbeat(ctx)
+			c.Run(ctx, c.policy.Apply, models.ActorSystem)
+		}
+	}
+}
+
+// Run performs one pass on behalf of actor. Without apply nothing is
+// deleted and the report only counts. A category that fails is reported in
+// Errors and does not stop the others.
+func (c *OrphanCleaner) Run(ctx context.Context, apply bool, actor string) *models.OrphanCleanup {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	started := c.now()
+	report := &models.OrphanCleanup{
+		StartedAt: started,
+		Apply:     apply,
+		Cutoff:    started.Add(-c.policy.Retention),
+		Found:     make(map[string]int64),
+		Deleted:   make(map[string]int64),
+		Samples:   make(map[string][]string),
+	}
+
+	for _, category := range models.OrphanCategories {
+		if ctx.Err() != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", category, ctx.Err()))
+			continue
+		}
+		found, sample, err := c.repo.Find(category, report.Cutoff, orphanSampleSize)
+		if err != nil {
+			report.Errors = append(report.Errors, err.Error())
+			continue
+		}
+		report.Found[category] = found
+		if len(sample) > 0 {
+			report.Samples[category] = sample
+		}
+		if apply && found > 0 {
+			deleted, err := c.deleteCategory(ctx, category, report.Cutoff)
+			report.Deleted[category] = deleted
+			if err != nil {
+				report.Errors = append(report.Errors, err.Error())
+			}
+		}
+	}
+	report.FinishedAt = c.now()
+
+	total := int64(0)
+	for _, deleted := range report.Deleted {
+		total += deleted
+	}
+	log.Printf("Orphan cleanup (apply=%v): found %v, deleted %v", apply, report.Found, report.Deleted)
+	for _, problem := range report.Errors {
+		log.Printf("Orphan cleanup: %s", problem)
+	}
+	if total > 0 {
+		c.record(actor, report)
+	}
+	return report
+}
+
+// deleteCategory deletes the orphans of category one batch at a time until
+// none are left, returning how many were deleted
+func (c *OrphanCleaner) deleteCategory(ctx context.Context, category string, cutoff time.Time) (int64, error) {
+	var total int64
+	for ctx.Err() == nil {
+		deleted, err := c.repo.DeleteBatch(category, cutoff, c.policy.BatchSize)
+		total += deleted
+		if err != nil {
+			return total, err
+		}
+		if deleted > 0 {
+			log.Printf("Orphan cleanup deleted %d %s records", deleted, category)
+		}
+		if deleted < int64(c.policy.BatchSize) {
+			return total, nil
+		}
+	}
+	return total, fmt.Errorf("%s: %w", category, ctx.Err())
+}
+
+// record writes an audit entry about an applied pass
+func (c *OrphanCleaner) record(actor string, report *models.OrphanCleanup) {
+	if c.audit == nil {
+		return
+	}
+	deleted := make(map[string]interface{}, len(report.Deleted))
+	for category, count := range report.Deleted {
+		deleted[category] = count
+	}
+	err := c.audit.Record(&models.AuditEntry{
+		Actor:        actor,
+		Action:       models.AuditOrphansDeleted,
+		ResourceType: "orphans",
+		Request: map[string]interface{}{
+			"cutoff":  report.Cutoff,
+			"deleted": deleted,
+		},
+	})
+	if err != nil {
+		log.Printf("Failed to audit orphan cleanup: %v", err)
+	}
+}
@@ -0,0 +1,503 @@
+package monitoring
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"gpu-orchestrator/core/clock"
+	"gpu-orchestrator/core/executor"
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/core/supervisor"
+	"gpu-orchestrator/storage"
+)
+
+// Post-mortem generation bounds
+const (
+	postmortemBatch    = 20               // Failures bundled per pass
+	postmortemLookback = 24 * time.Hour   // Older failures without a bundle are left alone
+	postmortemTimeout  = 2 * time.Minute  // Budget for collecting and uploading one bundle
+	postmortemEvents   = 500              // Newest events included in the timeline
+	manifestReserve    = 64 << 10         // Bundle bytes kept free for MANIFEST.json
+	nodeLogTimeout     = 30 * time.Second // Budget for tailing one node's logs
+)
+
+// postmortemLogPaths are the node logs tailed into a bundle
+var postmortemLogPaths = []string{"/var/log/user-data.log", "/var/log/cloud-init-output.log", "/var/log/jupyter.log"}
+
+// PostmortemManifest describes the contents of a post-mortem bundle
+type PostmortemManifest struct {
+	JobID       string            `json:"job_id"`
+	Reason      string            `json:"reason"` // Reason of the failure event
+	FailedAt    time.Time         `json:"failed_at"`
+	GeneratedAt time.Time         `json:"generated_at"`
+	Files       []PostmortemFile  `json:"files"`
+	Missing     map[string]string `json:"missing,omitempty"` // Piece -> why it is not included
+	Truncated   bool              `json:"truncated"`         // Some content was cut or dropped by the size cap
+}
+
+// PostmortemFile is one file of a post-mortem bundle
+type PostmortemFile struct {
+	Name      string `json:"name"`
+	Bytes     int    `json:"bytes"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+// PostmortemGenerator collects the diagnostics of failed jobs into a bundle
+// artifact linked from the failure event. Generation is best-effort: pieces
+// that cannot be collected are listed as missing, and a failure is never
+// held back by its bundle.
+type PostmortemGenerator struct {
+	jobRepo        *repository.JobRepository
+	eventRepo      *repository.EventRepository
+	allocationRepo *repository.AllocationRepository
+	artifactRepo   *repository.ArtifactRepository
+	stores         *storage.Registry
+	prefix         string // Object storage prefix bundles are written under
+	maxBytes       int    // Cap on a bundle's uncompressed content
+	runner         executor.NodeRunner
+	logLines       int
+	now            func() time.Time
+}
+
+// NewPostmortemGenerator creates a generator writing bundles of at most
+// maxBytes of uncompressed content under prefix
+func NewPostmortemGenerator(
+	jobRepo *repository.JobRepository,
+	eventRepo *repository.EventRepository,
+	allocationRepo *repository.AllocationRepository,
+	artifactRepo *repository.ArtifactRepository,
+	stores *storage.Registry,
+	prefix string,
+	maxBytes int,
+) *PostmortemGenerator {
+	return &PostmortemGenerator{
+		jobRepo:        jobRepo,
+		eventRepo:      eventRepo,
+		allocationRepo: allocationRepo,
+		artifactRepo:   artifactRepo,
+		stores:         stores,
+		prefix:         strings.TrimRight(prefix, "/"),
+		maxBytes:       maxBytes,
+		logLines:       200,
+		now:            clock.System.Now,
+	}
+}
+
+// SetNodeRunner enables tailing the last lines of each node's logs. Without
+// a runner node logs are listed as missing.
+func (pg *PostmortemGenerator) SetNodeRunner(runner executor.NodeRunner, lines int) {
+	pg.runner = runner
+	if lines > 0 {
+		pg.logLines = lines
+	}
+}
+
+// Start bundles new failures every interval
+func (pg *PostmortemGenerator) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			supervisor.Heartbeat(ctx)
+			if err := pg.Generate(ctx); err != nil {
+				log.Printf("Post-mortem generation failed: %v", err)
+			}
+		}
+	}
+}
+
+// Generate bundles failures of the lookback window that have no bundle yet.
+// A failure whose bundle cannot be uploaded is retried on later passes.
+func (pg *PostmortemGenerator) Generate(ctx context.Context) error {
+	events, err := pg.eventRepo.ListPendingPostmortems(pg.now().Add(-postmortemLookback), postmortemBatch)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		if ctx.Err() != nil {
+			return nil
+		}
+		bundleCtx, cancel := context.WithTimeout(ctx, postmortemTimeout)
+		artifactID, err := pg.GenerateFor(bundleCtx, event)
+		cancel()
+		if err != nil {
+			log.Printf("Failed to generate post-mortem of job %s: %v", event.JobID, err)
+			continue
+		}
+		log.Printf("Post-mortem bundle %d recorded for failed job %s", artifactID, event.JobID)
+	}
+	return nil
+}
+
+// GenerateFor writes the bundle of a failure event, records it as a
+// postmortem artifact and links it from the event
+func (pg *PostmortemGenerator) GenerateFor(ctx context.Context, failure models.JobEvent) (int64, error) {
+	job, err := pg.jobRepo.GetJob(failure.JobID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get job: %w", err)
+	}
+	bundle, manifest, err := pg.Build(ctx, job, failure)
+	if err != nil {
+		return 0, err
+	}
+
+	uri := fmt.Sprintf("%s/%s/postmortem-%d.tar.gz", pg.prefix, job.ID, failure.ID)
+	if err := pg.stores.Put(ctx, uri, bytes.NewReader(bundle), int64(len(bundle)), "application/gzip"); err != nil {
+		return 0, fmt.Errorf("failed to upload bundle: %w", err)
+	}
+
+	files := make([]string, len(manifest.Files))
+	for i, file := range manifest.Files {
+		files[i] = file.Name
+	}
+	artifactID, err := pg.artifactRepo.InsertArtifact(job.ID, models.ArtifactTypePostmortem, uri, map[string]interface{}{
+		"failure_event_id": failure.ID,
+		"reason":           failure.Reason,
+		"size_bytes":       len(bundle),
+		"files":            files,
+		"missing":          manifest.Missing,
+		"truncated":        manifest.Truncated,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to record bundle: %w", err)
+	}
+	if err := pg.eventRepo.LinkArtifact(failure.ID, artifactID); err != nil {
+		return 0, fmt.Errorf("failed to link bundle to the failure event: %w", err)
+	}
+	return artifactID, nil
+}
+
+// Build collects a failure's diagnostics into a tar.gz bundle. Smaller
+// pieces are added first so the size cap cuts node logs before the spec or
+// the timeline.
+func (pg *PostmortemGenerator) Build(ctx context.Context, job *models.Job, failure models.JobEvent) ([]byte, *PostmortemManifest, error) {
+	manifest := &PostmortemManifest{
+		JobID:       job.ID,
+		Reason:      failure.Reason,
+		FailedAt:    failure.At,
+		GeneratedAt: pg.now().UTC(),
+		Missing:     make(map[string]string),
+	}
+	bundle := newBundleWriter(pg.maxBytes-manifestReserve, manifest)
+
+	if job.SpecYAML != "" {
+		bundle.add("spec.yaml", []byte(job.SpecYAML), false)
+	} else {
+		manifest.Missing["spec"] = "job has no stored spec"
+	}
+
+	pg.addEvents(bundle, job.ID)
+	pg.addAllocations(bundle, job)
+
+	topology, err := pg.jobRepo.GetTopology(job.ID)
+	switch {
+	case err != nil:
+		manifest.Missing["topology"] = err.Error()
+	case topology == nil:
+		manifest.Missing["topology"] = "no nodes were launched"
+	default:
+		bundle.addJSON("topology.json", topology)
+	}
+
+	pg.addLaunchConfig(ctx, bundle, job.ID)
+	pg.addBootstrap(bundle, job.ID)
+	pg.addNodeLogs(ctx, bundle, topology)
+
+	if len(manifest.Missing) == 0 {
+		manifest.Missing = nil
+	}
+	data, err := bundle.close()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return data, manifest, nil
+}
+
+// addEvents adds the event timeline, oldest first
+func (pg *PostmortemGenerator) addEvents(bundle *bundleWriter, jobID string) {
+	events, err := pg.eventRepo.GetJobEvents(jobID, postmortemEvents)
+	if err != nil {
+		bundle.manifest.Missing["events"] = err.Error()
+		return
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].At.Before(events[j].At) })
+	bundle.addJSON("events.json", events)
+}
+
+// addAllocations adds the allocations, the provider errors recorded on them
+// and the cost summary
+func (pg *PostmortemGenerator) addAllocations(bundle *bundleWriter, job *models.Job) {
+	bundle.addJSON("cost.json", map[string]interface{}{
+		"running_usd":      job.CostRunningUSD,
+		"estimated_usd":    job.CostEstimatedUSD,
+		"budget_usd":       job.Constraints.MaxBudget,
+		"provider":         job.SelectedProvider,
+		"region":           job.SelectedRegion,
+		"started_at":       job.StartedAt,
+		"completed_at":     job.CompletedAt,
+		"emissions_gco2e":  job.EmissionsGCO2e,
+		"selected_backend": job.SelectedBackend,
+	})
+
+	allocations, err := pg.allocationRepo.GetAllocationsByJobID(job.ID)
+	if err != nil {
+		bundle.manifest.Missing["allocations"] = err.Error()
+		return
+	}
+	if len(allocations) == 0 {
+		bundle.manifest.Missing["allocations"] = "job was never allocated"
+		return
+	}
+	bundle.addJSON("allocations.json", allocations)
+
+	var providerErrors []map[string]interface{}
+	for _, alloc := range allocations {
+		if alloc.StatusDetail == "" {
+			continue
+		}
+		providerErrors = append(providerErrors, map[string]interface{}{
+			"allocation_id":     alloc.ID,
+			"provider":          alloc.Provider,
+			"region":            alloc.Region,
+			"zone":              alloc.Zone,
+			"instance_type":     alloc.InstanceType,
+			"status":            alloc.Status,
+			"provisioned_count": alloc.ProvisionedCount,
+			"count":             alloc.Count,
+			"detail":            alloc.StatusDetail,
+		})
+	}
+	if len(providerErrors) == 0 {
+		bundle.manifest.Missing["provider_errors"] = "no provider errors recorded on the allocations"
+		return
+	}
+	bundle.addJSON("provider_errors.json", providerErrors)
+}
+
+// addLaunchConfig adds the newest launch config: its redacted node
+// environments and the training script
+func (pg *PostmortemGenerator) addLaunchConfig(ctx context.Context, bundle *bundleWriter, jobID string) {
+	artifactType := models.ArtifactTypeLaunchConfig
+	artifacts, err := pg.artifactRepo.GetJobArtifacts(jobID, &artifactType)
+	if err != nil {
+		bundle.manifest.Missing["launch_config"] = err.Error()
+		return
+	}
+	if len(artifacts) == 0 {
+		bundle.manifest.Missing["launch_config"] = "job was never launched"
+		return
+	}
+
+	launch := artifacts[0]
+	meta := make(map[string]interface{}, len(launch.MetaJSON))
+	for key, value := range launch.MetaJSON {
+		meta[key] = value
+	}
+	script, inline := meta["script"].(string)
+	delete(meta, "script")
+	meta["uri"] = launch.URI
+	bundle.addJSON("launch_config.json", meta)
+
+	if !inline {
+		fetched, err := pg.fetch(ctx, launch.URI)
+		if err != nil {
+			bundle.manifest.Missing["launch_script"] = err.Error()
+			return
+		}
+		script = fetched
+	}
+	bundle.add("launch_script.sh", []byte(script), false)
+}
+
+// addBootstrap adds the rendered boot script of every allocation. The
+// platform records no boot verification results yet; they are listed as
+// missing.
+func (pg *PostmortemGenerator) addBootstrap(bundle *bundleWriter, jobID string) {
+	bundle.manifest.Missing["bootstrap_verification"] = "not recorded by the platform; bootstrap/ holds the rendered boot scripts"
+
+	artifactType := models.ArtifactTypeBootstrap
+	artifacts, err := pg.artifactRepo.GetJobArtifacts(jobID, &artifactType)
+	if err != nil {
+		bundle.manifest.Missing["bootstrap"] = err.Error()
+		return
+	}
+	if len(artifacts) == 0 {
+		bundle.manifest.Missing["bootstrap"] = "no boot scripts recorded"
+		return
+	}
+	for _, artifact := range artifacts {
+		script, _ := artifact.MetaJSON["script"].(string)
+		name := fmt.Sprintf("bootstrap/%d-%v-%v-%v.sh", artifact.ID,
+			artifact.MetaJSON["provider"], artifact.MetaJSON["region"], artifact.MetaJSON["instance_type"])
+		bundle.add(name, []byte(script), false)
+	}
+}
+
+// addNodeLogs adds the last lines of each node's logs, truncated from the
+// start when the size cap is reached
+func (pg *PostmortemGenerator) addNodeLogs(ctx context.Context, bundle *bundleWriter, topology *models.TopologyManifest) {
+	if topology == nil {
+		bundle.manifest.Missing["node_logs"] = "no nodes were launched"
+		return
+	}
+	if pg.runner == nil {
+		bundle.manifest.Missing["node_logs"] = "no node runner configured"
+		return
+	}
+
+	command := fmt.Sprintf("tail -n %d %s 2>/dev/null; true", pg.logLines, strings.Join(postmortemLogPaths, " "))
+	for _, cluster := range topology.Clusters {
+		for _, node := range cluster.Nodes {
+			var out bytes.Buffer
+			nodeCtx, cancel := context.WithTimeout(ctx, nodeLogTimeout)
+			err := pg.runner.Run(nodeCtx, models.Node{
+				ID:           node.NodeID,
+				ClusterID:    cluster.ClusterID,
+				InstanceID:   node.InstanceID,
+				InstanceType: node.InstanceType,
+				Provider:     node.Provider,
+				Region:       node.Region,
+				Zone:         node.Zone,
+				PrivateIP:    node.PrivateIP,
+				GPUs:         node.GPUs,
+			}, command, func(line string) {
+				out.WriteString(line)
+				out.WriteByte('\n')
+			})
+			cancel()
+
+			name := fmt.Sprintf("logs/%s-rank%d-%s.log", cluster.ClusterID, node.Rank, node.NodeID)
+			if err != nil {
+				bundle.manifest.Missing[name] = err.Error()
+				if out.Len() == 0 {
+					continue
+				}
+			}
+			bundle.add(name, out.Bytes(), true)
+		}
+	}
+}
+
+// fetch reads a small object such as a launch script
+func (pg *PostmortemGenerator) fetch(ctx context.Context, uri string) (string, error) {
+	if pg.stores == nil {
+		return "", fmt.Errorf("no object storage configured")
+	}
+	body, err := pg.stores.Get(ctx, uri)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+	data, err := io.ReadAll(io.LimitReader(body, int64(pg.maxBytes)))
+	return string(data), err
+}
+
+// bundleWriter writes files into a tar.gz, keeping their combined size
+// under a cap. A file that does not fit is truncated (keeping its head, or
+// its tail for logs); once the cap is reached further files are dropped.
+// Both are noted in the manifest, which is written last.
+type bundleWriter struct {
+	buf       bytes.Buffer
+	gz        *gzip.Writer
+	tw        *tar.Writer
+	remaining int
+	manifest  *PostmortemManifest
+	err       error
+}
+
+func newBundleWriter(capBytes int, manifest *PostmortemManifest) *bundleWriter {
+	b := &bundleWriter{remaining: capBytes, manifest: manifest}
+	b.gz = gzip.NewWriter(&b.buf)
+	b.tw = tar.NewWriter(b.gz)
+	return b
+}
+
+// addJSON adds a value as indented JSON
+func (b *bundleWriter) addJSON(name string, value interface{}) {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		b.manifest.Missing[strings.TrimSuffix(name, ".json")] = err.Error()
+		return
+	}
+	b.add(name, data, false)
+}
+
+// add adds a file, truncating it to the space left under the cap
+func (b *bundleWriter) add(name string, data []byte, keepTail bool) {
+	if b.remaining <= 0 {
+		b.manifest.Missing[name] = "dropped by the bundle size cap"
+		b.manifest.Truncated = true
+		return
+	}
+
+	truncated := false
+	if len(data) > b.remaining {
+		if keepTail {
+			data = data[len(data)-b.remaining:]
+		} else {
+			data = data[:b.remaining]
+		}
+		truncated = true
+		b.manifest.Truncated = true
+	}
+	if b.write(name, data) {
+		b.remaining -= len(data)
+		b.manifest.Files = append(b.manifest.Files, PostmortemFile{Name: name, Bytes: len(data), Truncated: truncated})
+	}
+}
+
+// write writes one tar entry, keeping the first error
+func (b *bundleWriter) write(name string, data []byte) bool {
+	if b.err != nil {
+		return false
+	}
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: b.manifest.GeneratedAt,
+	}
+	if err := b.tw.WriteHeader(header); err != nil {
+		b.err = err
+		return false
+	}
+	if _, err := b.tw.Write(data); err != nil {
+		b.err = err
+		return false
+	}
+	return true
+}
+
+// close writes MANIFEST.json and returns the compressed bundle
+func (b *bundleWriter) close() ([]byte, error) {
+	manifest, err := json.MarshalIndent(b.manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	b.write("MANIFEST.json", manifest)
+	if b.err != nil {
+		return nil, b.err
+	}
+	if err := b.tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := b.gz.Close(); err != nil {
+		return nil, err
+	}
+	return b.buf.Bytes(), nil
+}
@@ -0,0 +1,220 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gpu-orchestrator/core/clock"
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/repository"
+)
+
+// priceHistoryPruneInterval is how often superseded history is pruned
+const priceHistoryPruneInterval = time.Hour
+
+// PriceWatcher evaluates pricing subscriptions against the prices each
+// pricing refresh stores. A condition met by an instance type notifies once
+// and re-arms when it no longer holds. It also keeps the price history drop
+// conditions are measured against.
+type PriceWatcher struct {
+	repo *repository.PricingSubscriptionRepository
+	now  func() time.Time
+
+	mu         sync.Mutex
+	lastPruned time.Time
+}
+
+// NewPriceWatcher creates a new price watcher
+func NewPriceWatcher(repo *repository.PricingSubscriptionRepository) *PriceWatcher {
+	return &PriceWatcher{repo: repo, now: clock.System.Now}
+}
+
+// PricesStored records a refresh's stored prices of kind in the history and
+// evaluates the subscriptions to them
+func (w *PriceWatcher) PricesStored(ctx context.Context, provider models.Provider, kind string, instances []models.GPUInstance) {
+	now := w.now()
+	previous, err := w.repo.LatestPrices(provider, kind)
+	if err != nil {
+		log.Printf("Failed to read %s %s price history: %v", provider, kind, err)
+		return
+	}
+
+	var changes []repository.PricePoint
+	for _, instance := range instances {
+		point := pricePoint(instance, kind)
+		if old, ok := previous[point.Key()]; point.Price > 0 && (!ok || old != point.Price) {
+			changes = append(changes, point)
+		}
+	}
+	if err := w.repo.RecordPriceChanges(provider, kind, changes, now); err != nil {
+		log.Printf("Failed to record %s %s price history: %v", provider, kind, err)
+	}
+	w.pruneHistory(now)
+
+	subs, err := w.repo.ListEnabledSubscriptions(kind)
+	if err != nil {
+		log.Printf("Failed to load pricing subscriptions: %v", err)
+		return
+	}
+	if len(subs) == 0 {
+		return
+	}
+	notifications, err := w.repo.ListOpenNotifications(provider, kind)
+	if err != nil {
+		log.Printf("Failed to load open pricing notifications: %v", err)
+		return
+	}
+	open := make(map[int64]map[string]bool)
+	for _, n := range notifications {
+		if open[n.SubscriptionID] == nil {
+			open[n.SubscriptionID] = make(map[string]bool)
+		}
+		open[n.SubscriptionID][n.Region+"/"+n.InstanceType] = true
+	}
+
+	for i := range subs {
+		sub := &subs[i]
+		for _, instance := range instances {
+			point := pricePoint(instance, kind)
+			if point.Price <= 0 || instance.GPUsPerInstance <= 0 || !subscriptionMatches(sub, instance) {
+				continue
+			}
+			oldPrice, holds, err := w.check(sub, provider, instance, point, previous[point.Key()], now)
+			if err != nil {
+				log.Printf("Failed to evaluate pricing subscription %d for %s %s: %v", sub.ID, provider, point.Key(), err)
+				continue
+			}
+			gpus := float64(instance.GPUsPerInstance)
+			switch {
+			case holds && !open[sub.ID][point.Key()]:
+				w.notify(ctx, sub, &models.PriceNotification{
+					SubscriptionID: sub.ID,
+					Provider:       provider,
+					Region:         instance.Region,
+					InstanceType:   instance.InstanceType,
+					GPUType:        instance.GPUType,
+					OldPrice:       oldPrice / gpus,
+					NewPrice:       point.Price / gpus,
+					FiredAt:        now,
+				})
+			case !holds && open[sub.ID][point.Key()]:
+				if err := w.repo.ClearNotification(sub.ID, provider, instance.Region, instance.InstanceType, now); err != nil {
+					log.Printf("Failed to re-arm pricing subscription %d for %s %s: %v", sub.ID, provider, point.Key(), err)
+				}
+			}
+		}
+	}
+}
+
+// check reports whether a price meets a subscription's condition, with the
+// price it is compared to: the previously stored price (below) or the
+// window's peak (drop). Prices are per instance-hour.
+func (w *PriceWatcher) check(sub *models.PricingSubscription, provider models.Provider, instance models.GPUInstance, point repository.PricePoint, previous float64, now time.Time) (float64, bool, error) {
+	switch sub.Condition {
+	case models.PriceConditionBelow:
+		return previous, point.Price/float64(instance.GPUsPerInstance) < sub.Threshold, nil
+	case models.PriceConditionDrop:
+		peak, err := w.repo.PeakPrice(provider, point.Region, point.InstanceType, sub.Kind, now.Add(-sub.Window))
+		if err != nil {
+			return 0, false, err
+		}
+		return peak, peak > 0 && point.Price <= peak*(1-sub.Threshold/100), nil
+	default:
+		return 0, false, fmt.Errorf("unknown condition %q", sub.Condition)
+	}
+}
+
+// notify opens a notification and delivers it to the subscription's
+// webhook. Opening first keeps concurrent evaluations from notifying twice;
+// an undelivered notification is deleted so the next refresh retries it.
+// Without a webhook the notification is only listed by the API.
+func (w *PriceWatcher) notify(ctx context.Context, sub *models.PricingSubscription, n *models.PriceNotification) {
+	opened, err := w.repo.OpenNotification(n)
+	if err != nil {
+		log.Printf("Failed to record pricing notification of subscription %d: %v", sub.ID, err)
+		return
+	}
+	if !opened || sub.WebhookURL == "" {
+		return
+	}
+
+	subject := fmt.Sprintf("[%s] %s %s %s in %s is $%.4f/GPU-hour", sub.Name, n.Provider, n.InstanceType, sub.Kind, n.Region, n.NewPrice)
+	message := fmt.Sprintf("%s %s price of %s (%s) in %s is $%.4f per GPU-hour, below your $%.4f threshold.",
+		n.Provider, sub.Kind, n.InstanceType, n.GPUType, n.Region, n.NewPrice, sub.Threshold)
+	if sub.Condition == models.PriceConditionDrop {
+		message = fmt.Sprintf("%s %s price of %s (%s) in %s fell from $%.4f to $%.4f per GPU-hour within %s, at least %.0f%% down.",
+			n.Provider, sub.Kind, n.InstanceType, n.GPUType, n.Region, n.OldPrice, n.NewPrice, sub.Window, sub.Threshold)
+	}
+	err = NewWebhookNotifier(sub.WebhookURL).Notify(ctx, Notification{
+		Subject: subject,
+		Message: message,
+		Source:  "pricing_subscription",
+		Meta: map[string]interface{}{
+			"subscription_id": sub.ID,
+			"user_id":         sub.UserID,
+			"provider":        n.Provider,
+			"region":          n.Region,
+			"instance_type":   n.InstanceType,
+			"gpu_type":        n.GPUType,
+			"kind":            sub.Kind,
+			"condition":       sub.Condition,
+			"threshold":       sub.Threshold,
+			"old_price":       n.OldPrice,
+			"new_price":       n.NewPrice,
+		},
+		SentAt: n.FiredAt,
+	})
+	if err == nil {
+		return
+	}
+	log.Printf("Failed to deliver pricing notification of subscription %d: %v", sub.ID, err)
+	if err := w.repo.DeleteNotification(n.ID); err != nil {
+		log.Printf("Failed to withdraw undelivered pricing notification %d: %v", n.ID, err)
+	}
+}
+
+// pruneHistory deletes history superseded before the longest drop window,
+// at most once per priceHistoryPruneInterval
+func (w *PriceWatcher) pruneHistory(now time.Time) {
+	w.mu.Lock()
+	if now.Sub(w.lastPruned) < priceHistoryPruneInterval {
+		w.mu.Unlock()
+		return
+	}
+	w.lastPruned = now
+	w.mu.Unlock()
+
+	pruned, err := w.repo.PruneHistory(now.Add(-MaxPriceDropWindow))
+	if err != nil {
+		log.Printf("Failed to prune price history: %v", err)
+	} else if pruned > 0 {
+		log.Printf("Pruned %d superseded prices from the price history", pruned)
+	}
+}
+
+// pricePoint returns an instance's price of kind
+func pricePoint(instance models.GPUInstance, kind string) repository.PricePoint {
+	price := instance.PricePerHour
+	if kind == models.PriceKindSpot {
+		price = instance.SpotPrice
+	}
+	return repository.PricePoint{Region: instance.Region, InstanceType: instance.InstanceType, Price: price}
+}
+
+// subscriptionMatches reports whether a subscription covers an instance type
+func subscriptionMatches(sub *models.PricingSubscription, instance models.GPUInstance) bool {
+	if len(sub.Regions) > 0 && !slices.Contains(sub.Regions, instance.Region) {
+		return false
+	}
+	if slices.Contains(sub.InstanceTypes, instance.InstanceType) {
+		return true
+	}
+	return slices.ContainsFunc(sub.GPUTypes, func(gpuType string) bool {
+		return strings.EqualFold(gpuType, instance.GPUType)
+	})
+}
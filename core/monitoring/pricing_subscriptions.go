@@ -0,0 +1,100 @@
+package monitoring
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"gpu-orchestrator/core/models"
+)
+
+// Bounds of drop conditions' windows; the price history is kept for the
+// longest window
+const (
+	DefaultPriceDropWindow = 24 * time.Hour
+	MaxPriceDropWindow     = 7 * 24 * time.Hour
+)
+
+// PricingSubscriptionSpec is the API representation of a pricing
+// subscription. Window uses Go duration syntax (e.g. "24h").
+type PricingSubscriptionSpec struct {
+	Name          string   `json:"name"`
+	InstanceTypes []string `json:"instance_types,omitempty"`
+	GPUTypes      []string `json:"gpu_types,omitempty"`
+	Regions       []string `json:"regions,omitempty"`
+	Kind          string   `json:"kind,omitempty"` // Default spot
+	Condition     string   `json:"condition"`
+	Threshold     float64  `json:"threshold"`
+	Window        string   `json:"window,omitempty"`
+	WebhookURL    string   `json:"webhook_url,omitempty"`
+	Enabled       *bool    `json:"enabled,omitempty"`
+}
+
+// ToSubscription validates the spec and converts it to a subscription of userID
+func (s PricingSubscriptionSpec) ToSubscription(userID string) (*models.PricingSubscription, error) {
+	name := strings.TrimSpace(s.Name)
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if len(s.InstanceTypes) == 0 && len(s.GPUTypes) == 0 {
+		return nil, fmt.Errorf("instance_types or gpu_types is required")
+	}
+	if s.Threshold <= 0 {
+		return nil, fmt.Errorf("threshold must be positive")
+	}
+
+	sub := &models.PricingSubscription{
+		UserID:        userID,
+		Name:          name,
+		InstanceTypes: s.InstanceTypes,
+		GPUTypes:      s.GPUTypes,
+		Regions:       s.Regions,
+		Kind:          s.Kind,
+		Condition:     s.Condition,
+		Threshold:     s.Threshold,
+		WebhookURL:    s.WebhookURL,
+		Enabled:       true,
+	}
+	if s.Enabled != nil {
+		sub.Enabled = *s.Enabled
+	}
+	if sub.Kind == "" {
+		sub.Kind = models.PriceKindSpot
+	}
+	if sub.Kind != models.PriceKindSpot && sub.Kind != models.PriceKindOnDemand {
+		return nil, fmt.Errorf("unknown kind %q (want spot or on_demand)", s.Kind)
+	}
+
+	switch sub.Condition {
+	case models.PriceConditionBelow:
+		if s.Window != "" {
+			return nil, fmt.Errorf("window only applies to %s conditions", models.PriceConditionDrop)
+		}
+	case models.PriceConditionDrop:
+		if s.Threshold >= 100 {
+			return nil, fmt.Errorf("threshold of a drop is a percentage below 100")
+		}
+		sub.Window = DefaultPriceDropWindow
+		if s.Window != "" {
+			window, err := time.ParseDuration(s.Window)
+			if err != nil {
+				return nil, fmt.Errorf("invalid window: %w", err)
+			}
+			sub.Window = window
+		}
+		if sub.Window < time.Hour || sub.Window > MaxPriceDropWindow {
+			return nil, fmt.Errorf("window must be between 1h and %s", MaxPriceDropWindow)
+		}
+	default:
+		return nil, fmt.Errorf("unknown condition %q (want %s or %s)", s.Condition, models.PriceConditionBelow, models.PriceConditionDrop)
+	}
+
+	if sub.WebhookURL != "" {
+		u, err := url.Parse(sub.WebhookURL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return nil, fmt.Errorf("webhook_url must be an http(s) URL")
+		}
+	}
+	return sub, nil
+}
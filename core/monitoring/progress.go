@@ -0,0 +1,83 @@
+package monitoring
+
+import (
+	"math"
+	"time"
+
+	"gpu-orchestrator/core/models"
+)
+
+// maxRunningPercent caps the progress of unfinished jobs, so a job that
+// overruns its step count or time estimate never reads as done
+const maxRunningPercent = 99.0
+
+// Progress bases
+const (
+	ProgressBySteps = "steps" // Reported steps vs training.total_steps
+	ProgressByTime  = "time"  // Elapsed vs estimated runtime
+)
+
+// JobProgress is the client-facing progress of a job. Fields are nil when
+// there is no data to derive them from.
+type JobProgress struct {
+	ProgressPercent *float64   `json:"progress_percent"`
+	ETA             *time.Time `json:"eta"`
+	StepsCompleted  *int64     `json:"steps_completed"`
+	StepsPerHour    *float64   `json:"steps_per_hour"`
+	Basis           string     `json:"basis,omitempty"`
+}
+
+// ComputeProgress derives a job's progress and ETA. Running jobs use reported
+// steps against training.total_steps when both are known, otherwise elapsed
+// time against the estimated runtime. The ETA comes from the smoothed step
+// rate, or the estimate; it is nil once the estimate has been overrun.
+func ComputeProgress(job *models.Job, now time.Time) JobProgress {
+	var progress JobProgress
+	rate := 0.0
+	if job.Progress != nil {
+		steps := job.Progress.StepsCompleted
+		progress.StepsCompleted = &steps
+		if rate = job.Progress.Rate(); rate > 0 {
+			progress.StepsPerHour = &rate
+		}
+	}
+
+	switch job.Status {
+	case models.JobStatusCompleted:
+		progress.ProgressPercent = percent(100, 100)
+		return progress
+	case models.JobStatusRunning, models.JobStatusCheckpointing, models.JobStatusCancelling:
+	default:
+		return progress
+	}
+
+	if job.TotalSteps > 0 && job.Progress != nil {
+		progress.Basis = ProgressBySteps
+		steps := job.Progress.StepsCompleted
+		progress.ProgressPercent = percent(float64(steps)/float64(job.TotalSteps)*100, maxRunningPercent)
+		if rate > 0 && steps < job.TotalSteps {
+			remaining := time.Duration(float64(job.TotalSteps-steps) / rate * float64(time.Hour))
+			eta := job.Progress.ReportedAt.Add(remaining)
+			progress.ETA = &eta
+		}
+		return progress
+	}
+
+	if job.StartedAt != nil && job.Requirements.EstimatedHours > 0 {
+		progress.Basis = ProgressByTime
+		estimated := time.Duration(job.Requirements.EstimatedHours * float64(time.Hour))
+		elapsed := now.Sub(*job.StartedAt)
+		progress.ProgressPercent = percent(float64(elapsed)/float64(estimated)*100, maxRunningPercent)
+		if elapsed < estimated {
+			eta := job.StartedAt.Add(estimated)
+			progress.ETA = &eta
+		}
+	}
+	return progress
+}
+
+// percent clamps value to [0, max] and rounds it to one decimal
+func percent(value, max float64) *float64 {
+	rounded := math.Round(math.Min(math.Max(value, 0), max)*10) / 10
+	return &rounded
+}
@@ -0,0 +1,50 @@
+package monitoring
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/core/supervisor"
+	"gpu-orchestrator/providers"
+)
+
+// ProviderUsageFlusher persists the provider API calls metered by this
+// replica into the daily rollups
+type ProviderUsageFlusher struct {
+	meter *providers.UsageMeter
+	repo  *repository.ProviderUsageRepository
+}
+
+// NewProviderUsageFlusher creates a new provider usage flusher
+func NewProviderUsageFlusher(meter *providers.UsageMeter, repo *repository.ProviderUsageRepository) *ProviderUsageFlusher {
+	return &ProviderUsageFlusher{meter: meter, repo: repo}
+}
+
+// Start flushes every interval, and once more when ctx is cancelled
+func (f *ProviderUsageFlusher) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			f.Flush()
+			return
+		case <-ticker.C:
+			supervisor.Heartbeat(ctx)
+			f.Flush()
+		}
+	}
+}
+
+// Flush adds the counts since the last flush to the daily rollups. Counts
+// that fail to store are kept for the next flush.
+func (f *ProviderUsageFlusher) Flush() {
+	usage := f.meter.Drain()
+	if err := f.repo.AddUsage(usage); err != nil {
+		log.Printf("Failed to store provider API usage: %v", err)
+		f.meter.Restore(usage)
+	}
+}
@@ -0,0 +1,172 @@
+package optimizer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"gpu-orchestrator/core/models"
+)
+
+// AdmissionProblem describes one reason a job cannot be scheduled as specified
+type AdmissionProblem struct {
+	Reason  string `json:"reason"` // no_matching_instance | exceeds_node_limits | interconnect_too_slow | budget_too_low
+	Field   string `json:"field"`  // Spec field to change, e.g. "constraints.budget"
+	Message string `json:"message"`
+}
+
+// AdmissionResult is the outcome of a submission-time feasibility check
+type AdmissionResult struct {
+	Checked    bool   // False when the check was skipped (cold cache, timeout)
+	SkipReason string // Why the check was skipped
+	MinCostUSD float64
+	Problems   []AdmissionProblem
+}
+
+// Feasible reports whether no problems were found (skipped checks are feasible)
+func (r *AdmissionResult) Feasible() bool {
+	return len(r.Problems) == 0
+}
+
+// CheckAdmission runs a fast feasibility pass over cached pricing only.
+// It never calls provider APIs: if the cache is cold or the lookup exceeds
+// timeout the check is skipped rather than blocking submission.
+func (ao *AllocationOptimizer) CheckAdmission(
+	ctx context.Context,
+	requirements models.JobRequirements,
+	constraints models.JobConstraints,
+	timeout time.Duration,
+) *AdmissionResult {
+	if ao.instances == nil {
+		return &AdmissionResult{SkipReason: "pricing fetcher not configured"}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	allInstances, err := ao.instances.GetAllInstances(ctx)
+	if err != nil {
+		return &AdmissionResult{SkipReason: fmt.Sprintf("pricing cache unavailable: %v", err)}
+	}
+	if ctx.Err() != nil {
+		// Rows read before the deadline may be partial
+		return &AdmissionResult{SkipReason: "pricing lookup timed out"}
+	}
+	if len(allInstances) == 0 {
+		return &AdmissionResult{SkipReason: "pricing cache is cold"}
+	}
+
+	result := &AdmissionResult{Checked: true}
+
+	// GPU memory / per-node constraints
+	var candidates []models.GPUInstance
+	suitable, excluded := ao.filterCandidates(allInstances, requirements)
+	for _, instance := range suitable {
+		if requirements.MaxGPUsPerNode > 0 && instance.GPUsPerInstance > requirements.MaxGPUsPerNode {
+			continue
+		}
+		candidates = append(candidates, instance)
+	}
+	if len(suitable) == 0 && excluded > 0 {
+		result.Problems = append(result.Problems, AdmissionProblem{
+			Reason:  "instance_types_excluded",
+			Field:   "resources.instance_types",
+			Message: fmt.Sprintf("all %d instance types offering %d GB per GPU are excluded by the org or job instance type lists", excluded, requirements.GPUMemory),
+		})
+		return result
+	}
+	if len(candidates) == 0 {
+		result.Problems = append(result.Problems, AdmissionProblem{
+			Reason:  "no_matching_instance",
+			Field:   "resources.gpu_memory",
+			Message: fmt.Sprintf("no instance type offers %d GB per GPU with at most %d GPUs per node", requirements.GPUMemory, requirements.MaxGPUsPerNode),
+		})
+		return result
+	}
+
+	// Node limits: single-cluster jobs must fit in one provider+region,
+	// multi-task jobs may spread across all of them
+	var fitting []models.GPUInstance
+	capacityByRegion := make(map[string]int)
+	for _, instance := range candidates {
+		maxNodes := ao.getMaxNodesForProvider(instance.Provider, instance.Region, instance.InstanceType)
+		nodesNeeded := (requirements.GPUs + instance.GPUsPerInstance - 1) / instance.GPUsPerInstance
+		if nodesNeeded <= maxNodes {
+			fitting = append(fitting, instance)
+		}
+
+		key := fmt.Sprintf("%s:%s", instance.Provider, instance.Region)
+		if capacity := maxNodes * instance.GPUsPerInstance; capacity > capacityByRegion[key] {
+			capacityByRegion[key] = capacity
+		}
+	}
+
+	if requirements.ExecutionMode == models.ModeMultiTask {
+		total := 0
+		for _, capacity := range capacityByRegion {
+			total += capacity
+		}
+		if requirements.GPUs > total {
+			result.Problems = append(result.Problems, AdmissionProblem{
+				Reason:  "exceeds_node_limits",
+				Field:   "resources.gpus",
+				Message: fmt.Sprintf("%d GPUs requested but node limits allow at most %d across all providers", requirements.GPUs, total),
+			})
+			return result
+		}
+		fitting = candidates
+	} else if len(fitting) == 0 {
+		largest := 0
+		for _, capacity := range capacityByRegion {
+			if capacity > largest {
+				largest = capacity
+			}
+		}
+		result.Problems = append(result.Problems, AdmissionProblem{
+			Reason:  "exceeds_node_limits",
+			Field:   "resources.gpus",
+			Message: fmt.Sprintf("%d GPUs requested but a single provider+region allows at most %d", requirements.GPUs, largest),
+		})
+		return result
+	}
+
+	// Multi-node clusters need a fast enough interconnect
+	if requirements.ExecutionMode == models.ModeSingleCluster && requirements.RequiresMultiNode {
+		fitting = ao.filterMultiNodeCompatible(fitting, requirements)
+		if len(fitting) == 0 {
+			result.Problems = append(result.Problems, AdmissionProblem{
+				Reason:  "interconnect_too_slow",
+				Field:   "resources.min_interconnect_gbps",
+				Message: fmt.Sprintf("no instance type that fits %d GPUs in one provider+region offers %.0f Gbps between nodes", requirements.GPUs, minInterconnectGbps(requirements)),
+			})
+			return result
+		}
+	}
+
+	// Minimum achievable cost vs budget
+	result.MinCostUSD = math.Inf(1)
+	for _, instance := range fitting {
+		nodesNeeded := (requirements.GPUs + instance.GPUsPerInstance - 1) / instance.GPUsPerInstance
+		price := instance.PricePerHour
+		if constraints.AllowSpot && instance.SpotPrice > 0 {
+			// Lower bound: assume the cheapest spot/on-demand mix allowed
+			spotCount, onDemandCount := splitSpotCount(nodesNeeded, instance, constraints)
+			price = (instance.SpotPrice*float64(spotCount) + instance.PricePerHour*float64(onDemandCount)) / float64(nodesNeeded)
+		}
+		cost := price * float64(nodesNeeded) * requirements.EstimatedHours
+		if cost < result.MinCostUSD {
+			result.MinCostUSD = cost
+		}
+	}
+
+	if constraints.MaxBudget > 0 && result.MinCostUSD > constraints.MaxBudget {
+		result.Problems = append(result.Problems, AdmissionProblem{
+			Reason:  "budget_too_low",
+			Field:   "constraints.budget",
+			Message: fmt.Sprintf("cheapest allocation for %d GPUs over %.1fh costs $%.2f, budget is $%.2f", requirements.GPUs, requirements.EstimatedHours, result.MinCostUSD, constraints.MaxBudget),
+		})
+	}
+
+	return result
+}
@@ -2,7 +2,9 @@ package optimizer
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"sort"
 	"strings"
 	"time"
@@ -10,29 +12,98 @@ import (
 	"gpu-orchestrator/core/models"
 )
 
+// ErrNoPricing is returned when the instance catalog is empty, e.g. because
+// no price was refreshed within the last hour
+var ErrNoPricing = errors.New("no fresh GPU pricing")
+
+// ErrInstanceTypesExcluded is returned when instances met the job's GPU
+// requirements but the instance type allow/deny lists excluded all of them
+var ErrInstanceTypesExcluded = errors.New("all suitable instance types are excluded")
+
+// checkpointedInterruptionCost scales the reliability term of jobs that
+// declare a checkpoint schedule: an interruption loses at most a couple of
+// intervals of work rather than the whole run
+const checkpointedInterruptionCost = 0.5
+
 // AllocationOptimizer optimizes compute allocation for jobs
 type AllocationOptimizer struct {
 	costCalculator     *CostCalculator
-	pricingFetcher     *PricingFetcher
+	instances          InstanceSource
 	performanceMetrics *PerformanceMetricsStore
+	nodeLimits         *NodeLimits
+	instanceTypes      *InstanceTypeRules // Org allow/deny lists; nil = none
+	images             ImageCatalog       // Machine image registry for pinned images; see SetImageCatalog
 }
 
-// NewAllocationOptimizer creates a new allocation optimizer
-func NewAllocationOptimizer(cc *CostCalculator, pf *PricingFetcher) *AllocationOptimizer {
-	return &AllocationOptimizer{
+// NewAllocationOptimizer creates a new allocation optimizer.
+// limits may be nil to use the built-in provider defaults.
+func NewAllocationOptimizer(cc *CostCalculator, pf *PricingFetcher, limits *NodeLimits) *AllocationOptimizer {
+	if limits == nil {
+		limits = NewNodeLimits()
+	}
+	ao := &AllocationOptimizer{
 		costCalculator:     cc,
-		pricingFetcher:     pf,
 		performanceMetrics: NewPerformanceMetricsStore(),
+		nodeLimits:         limits,
+	}
+	if pf != nil {
+		ao.instances = pf
 	}
+	return ao
+}
+
+// NodeLimits returns the node limits consulted by the optimizer
+func (ao *AllocationOptimizer) NodeLimits() *NodeLimits {
+	return ao.nodeLimits
+}
+
+// SetInstanceTypeRules sets the org-wide instance type allow/deny lists
+func (ao *AllocationOptimizer) SetInstanceTypeRules(rules *InstanceTypeRules) {
+	ao.instanceTypes = rules
+}
+
+// InstanceTypeRules returns the org-wide instance type lists, or nil
+func (ao *AllocationOptimizer) InstanceTypeRules() *InstanceTypeRules {
+	return ao.instanceTypes
+}
+
+// InterruptionModel returns the spot interruption rates reliability is scored
+// with, or nil when only static estimates are used
+func (ao *AllocationOptimizer) InterruptionModel() *InterruptionModel {
+	return ao.costCalculator.InterruptionModel()
+}
+
+// PerformanceMetrics returns the throughput benchmarks estimates are made with
+func (ao *AllocationOptimizer) PerformanceMetrics() *PerformanceMetricsStore {
+	return ao.performanceMetrics
 }
 
+// Strategy names recorded in allocation decisions
+const (
+	StrategyCheapestSingleRegion  = "cheapest_single_region"
+	StrategyReliableSingleRegion  = "reliable_single_region"
+	StrategyDataLocality          = "data_locality"
+	StrategyCheapestMultiProvider = "cheapest_multi_provider"
+	StrategyGeoDistributed        = "geo_distributed"
+	StrategyHybrid                = "hybrid"
+	StrategyLowCarbonSingleRegion = "low_carbon_single_region"
+)
+
 // Strategy represents an allocation strategy with scoring
 type Strategy struct {
-	Allocation    []models.Allocation
-	TotalCost     float64
-	Reliability   float64
-	EstimatedTime time.Duration
-	Score         float64
+	Name                string
+	Allocation          []models.Allocation
+	TotalCost           float64
+	DataTransferCost    float64
+	Reliability         float64
+	USDPerMillionTokens float64 // Benchmark cost per 1M tokens; tokens mode only
+	EmissionsGCO2e      float64 // Estimated footprint of the run
+	GravityBonus        float64 // Subtracted from the score for the team's data regions
+	EstimatedTime       time.Duration
+	Score               float64
+	Terms               models.ScoreTerms          // Unweighted score terms
+	Rejections          []models.StrategyRejection // Constraints the strategy failed
+	TaskSpread          []models.RegionTasks       // Tasks per region of a geo-distributed strategy
 }
 
 // Optimize optimizes allocation based on job requirements and constraints
@@ -41,14 +112,49 @@ func (ao *AllocationOptimizer) Optimize(
 	requirements models.JobRequirements,
 	constraints models.JobConstraints,
 ) ([]models.Allocation, error) {
+	allocations, _, err := ao.OptimizeWithDecision(ctx, requirements, constraints)
+	return allocations, err
+}
+
+// OptimizeWithDecision optimizes allocation and returns the decision record
+// with its explanation. The decision is also returned when no strategy was
+// feasible, so callers can record why.
+func (ao *AllocationOptimizer) OptimizeWithDecision(
+	ctx context.Context,
+	requirements models.JobRequirements,
+	constraints models.JobConstraints,
+) ([]models.Allocation, *models.AllocationDecision, error) {
 	// Step 1: Get all available GPU instances
-	allInstances, err := ao.pricingFetcher.FetchAllPricing(ctx)
+	if ao.instances == nil {
+		return nil, nil, fmt.Errorf("pricing fetcher not configured")
+	}
+	allInstances, err := ao.instances.GetAllInstances(ctx)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	if countInstances(allInstances) == 0 {
+		return nil, nil, ErrNoPricing
+	}
+	if requirements.ExecutionMode == models.ModeMultiTask && len(requirements.TaskGroups) > 0 {
+		return ao.optimizeTaskGroups(allInstances, requirements, constraints)
 	}
+	return ao.decide(allInstances, requirements, constraints)
+}
 
+// decide plans an allocation for requirements from a catalog
+func (ao *AllocationOptimizer) decide(
+	allInstances map[models.Provider][]models.GPUInstance,
+	requirements models.JobRequirements,
+	constraints models.JobConstraints,
+) ([]models.Allocation, *models.AllocationDecision, error) {
 	// Step 2: Filter instances that meet requirements
-	candidates := ao.filterCandidates(allInstances, requirements)
+	candidates, excluded := ao.filterCandidates(allInstances, requirements)
+	if len(candidates) == 0 && excluded > 0 {
+		decision := newDecision(nil, requirements, constraints.ScoringWeights())
+		decision.ExcludedInstances = excluded
+		decision.Explanation = ExplainDecision(decision)
+		return nil, decision, fmt.Errorf("%w: %d excluded by the instance type lists", ErrInstanceTypesExcluded, excluded)
+	}
 
 	// Step 3: Generate allocation strategies
 	strategies := ao.generateStrategies(candidates, requirements, constraints)
@@ -57,30 +163,98 @@ func (ao *AllocationOptimizer) Optimize(
 	scoredStrategies := ao.scoreStrategies(strategies, requirements, constraints)
 
 	// Step 5: Return best strategy
+	decision := newDecision(scoredStrategies, requirements, constraints.ScoringWeights())
 	if len(scoredStrategies) == 0 {
-		return nil, fmt.Errorf("no suitable allocation found")
+		return nil, decision, fmt.Errorf("no suitable allocation found")
+	}
+
+	return scoredStrategies[0].Allocation, decision, nil
+}
+
+// ScoreStrategies runs the optimizer without placing anything and returns
+// every strategy it generated, scored and best first, with the decision.
+// Infeasible strategies are included with their rejections. Jobs with task
+// groups return the combination of each group's choice, if every group fits.
+func (ao *AllocationOptimizer) ScoreStrategies(
+	ctx context.Context,
+	requirements models.JobRequirements,
+	constraints models.JobConstraints,
+) ([]Strategy, *models.AllocationDecision, error) {
+	if ao.instances == nil {
+		return nil, nil, fmt.Errorf("pricing fetcher not configured")
+	}
+	allInstances, err := ao.instances.GetAllInstances(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if countInstances(allInstances) == 0 {
+		return nil, nil, ErrNoPricing
 	}
 
-	return scoredStrategies[0].Allocation, nil
+	if requirements.ExecutionMode == models.ModeMultiTask && len(requirements.TaskGroups) > 0 {
+		allocations, decision, err := ao.optimizeTaskGroups(allInstances, requirements, constraints)
+		if allocations == nil {
+			return nil, decision, err
+		}
+		return []Strategy{combinedTaskGroupStrategy(decision.TaskGroups, allocations, constraints)}, decision, err
+	}
+
+	candidates, excluded := ao.filterCandidates(allInstances, requirements)
+	if len(candidates) == 0 && excluded > 0 {
+		decision := newDecision(nil, requirements, constraints.ScoringWeights())
+		decision.ExcludedInstances = excluded
+		decision.Explanation = ExplainDecision(decision)
+		return nil, decision, fmt.Errorf("%w: %d excluded by the instance type lists", ErrInstanceTypesExcluded, excluded)
+	}
+	strategies := ao.scoreStrategies(ao.generateStrategies(candidates, requirements, constraints), requirements, constraints)
+	return strategies, newDecision(strategies, requirements, constraints.ScoringWeights()), nil
+}
+
+// Evaluation returns the decision record of a scored strategy
+func (s Strategy) Evaluation() models.StrategyEvaluation {
+	return evaluationOf(s)
 }
 
+// countInstances returns the number of instances in a catalog
+func countInstances(instances map[models.Provider][]models.GPUInstance) int {
+	count := 0
+	for _, list := range instances {
+		count += len(list)
+	}
+	return count
+}
+
+// filterCandidates returns the instances that meet the job's requirements and
+// the number of otherwise suitable instances the instance type lists excluded
 func (ao *AllocationOptimizer) filterCandidates(
 	allInstances map[models.Provider][]models.GPUInstance,
 	requirements models.JobRequirements,
-) []models.GPUInstance {
+) ([]models.GPUInstance, int) {
 	var candidates []models.GPUInstance
+	excluded := 0
+	imageScopes := ao.imageScopes(requirements)
 
 	for _, instances := range allInstances {
 		for _, instance := range instances {
+			// Managed training entries are only for jobs on the managed backend
+			if models.IsManagedInstanceType(instance.Provider, instance.InstanceType) && !requirements.Managed {
+				continue
+			}
 			// Check if instance meets requirements
 			if instance.GPUsPerInstance > 0 &&
-				instance.MemoryPerGPU >= requirements.GPUMemory {
+				instance.GPUsPerInstance >= requirements.GPUsPerTask &&
+				instance.MemoryPerGPU >= requirements.GPUMemory &&
+				imagePermits(imageScopes, instance) {
+				if !ao.instanceTypes.Permits(instance, requirements) {
+					excluded++
+					continue
+				}
 				candidates = append(candidates, instance)
 			}
 		}
 	}
 
-	return candidates
+	return candidates, excluded
 }
 
 func (ao *AllocationOptimizer) generateStrategies(
@@ -95,31 +269,42 @@ func (ao *AllocationOptimizer) generateStrategies(
 	case models.ModeSingleCluster:
 		// Single-cluster strategies: ALL nodes must be same provider+region
 		// Strategy 1: Cheapest single region (prefer spot)
-		strategies = append(strategies, ao.cheapestSingleRegionStrategy(candidates, requirements, constraints))
+		strategies = append(strategies, named(StrategyCheapestSingleRegion, ao.cheapestSingleRegionStrategy(candidates, requirements, constraints)))
 
 		// Strategy 2: Most reliable single region (avoid spot, prefer on-prem)
-		strategies = append(strategies, ao.reliableSingleRegionStrategy(candidates, requirements, constraints))
+		strategies = append(strategies, named(StrategyReliableSingleRegion, ao.reliableSingleRegionStrategy(candidates, requirements, constraints)))
 
 		// Strategy 3: Data locality (prefer region where dataset exists)
 		if constraints.DataLocality == models.DataLocalityRequired || constraints.DataLocality == models.DataLocalityPrefer {
-			strategies = append(strategies, ao.dataLocalityStrategy(candidates, requirements, constraints))
+			strategies = append(strategies, named(StrategyDataLocality, ao.dataLocalityStrategy(candidates, requirements, constraints)))
+		}
+
+		// Strategy 4: Lowest emissions single region, when carbon is weighed
+		if constraints.CarbonWeight > 0 {
+			strategies = append(strategies, named(StrategyLowCarbonSingleRegion, ao.lowCarbonSingleRegionStrategy(candidates, requirements, constraints)))
 		}
 
 	case models.ModeMultiTask:
 		// Multi-task strategies: Can distribute across providers/regions
 		// Strategy 1: Cheapest overall (distribute tasks)
-		strategies = append(strategies, ao.cheapestMultiProviderStrategy(candidates, requirements, constraints))
+		strategies = append(strategies, named(StrategyCheapestMultiProvider, ao.cheapestMultiProviderStrategy(candidates, requirements, constraints)))
 
 		// Strategy 2: Geographic distribution (for parallel tasks)
-		strategies = append(strategies, ao.geoDistributedTaskStrategy(candidates, requirements, constraints))
+		strategies = append(strategies, named(StrategyGeoDistributed, ao.geoDistributedTaskStrategy(candidates, requirements, constraints)))
 
 		// Strategy 3: On-prem first, cloud backup
-		strategies = append(strategies, ao.hybridTaskStrategy(candidates, requirements, constraints))
+		strategies = append(strategies, named(StrategyHybrid, ao.hybridTaskStrategy(candidates, requirements, constraints)))
 	}
 
 	return strategies
 }
 
+// named sets the name a strategy is recorded under
+func named(name string, strategy Strategy) Strategy {
+	strategy.Name = name
+	return strategy
+}
+
 // cheapestSingleRegionStrategy finds cheapest strategy within ONE provider+region
 func (ao *AllocationOptimizer) cheapestSingleRegionStrategy(
 	candidates []models.GPUInstance,
@@ -158,6 +343,44 @@ func (ao *AllocationOptimizer) cheapestSingleRegionStrategy(
 	return bestStrategy
 }
 
+// lowCarbonSingleRegionStrategy finds the provider+region whose cheapest
+// allocation has the lowest estimated emissions
+func (ao *AllocationOptimizer) lowCarbonSingleRegionStrategy(
+	candidates []models.GPUInstance,
+	requirements models.JobRequirements,
+	constraints models.JobConstraints,
+) Strategy {
+	regionGroups := make(map[string][]models.GPUInstance)
+	for _, instance := range candidates {
+		key := fmt.Sprintf("%s:%s", instance.Provider, instance.Region)
+		regionGroups[key] = append(regionGroups[key], instance)
+	}
+
+	// Iterate regions in order so ties resolve the same way every time
+	keys := make([]string, 0, len(regionGroups))
+	for key := range regionGroups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	carbon := ao.costCalculator.CarbonModel()
+	var bestStrategy Strategy
+	bestEmissions := math.Inf(1)
+	for _, key := range keys {
+		regionStrategy := ao.cheapestStrategy(regionGroups[key], requirements, constraints)
+		if len(regionStrategy.Allocation) == 0 {
+			continue
+		}
+		emissions := carbon.AllocationEmissions(regionStrategy.Allocation, requirements.EstimatedHours)
+		if emissions < bestEmissions {
+			bestEmissions = emissions
+			bestStrategy = regionStrategy
+		}
+	}
+
+	return bestStrategy
+}
+
 func (ao *AllocationOptimizer) cheapestStrategy(
 	candidates []models.GPUInstance,
 	requirements models.JobRequirements,
@@ -169,24 +392,22 @@ func (ao *AllocationOptimizer) cheapestStrategy(
 		candidates = ao.filterMultiNodeCompatible(candidates, requirements)
 	}
 
-	// Sort by price per GPU (prefer spot instances)
+	// Sort by price per GPU (prefer spot instances), or per token in tokens mode
 	sorted := make([]models.GPUInstance, len(candidates))
 	copy(sorted, candidates)
 
 	sort.Slice(sorted, func(i, j int) bool {
-		iPricePerGPU := sorted[i].PricePerHour / float64(sorted[i].GPUsPerInstance)
-		if sorted[i].SpotPrice > 0 && constraints.AllowSpot {
-			iPricePerGPU = sorted[i].SpotPrice / float64(sorted[i].GPUsPerInstance)
-		}
-		jPricePerGPU := sorted[j].PricePerHour / float64(sorted[j].GPUsPerInstance)
-		if sorted[j].SpotPrice > 0 && constraints.AllowSpot {
-			jPricePerGPU = sorted[j].SpotPrice / float64(sorted[j].GPUsPerInstance)
+		iPrice, iRanked := ao.rankPrice(sorted[i], requirements, constraints)
+		jPrice, jRanked := ao.rankPrice(sorted[j], requirements, constraints)
+		if iRanked != jRanked {
+			return iRanked
 		}
-		return iPricePerGPU < jPricePerGPU
+		return iPrice < jPrice
 	})
 
 	// Allocate greedily
 	var allocation []models.Allocation
+	var used []models.GPUInstance
 	remaining := requirements.GPUs
 
 	for _, instance := range sorted {
@@ -203,28 +424,15 @@ func (ao *AllocationOptimizer) cheapestStrategy(
 		if instancesNeeded > 0 {
 			// For multi-node training, check max nodes per cluster/AZ constraints
 			if requirements.RequiresMultiNode {
-				maxNodes := ao.getMaxNodesForProvider(instance.Provider, instance.Region)
+				maxNodes := ao.getMaxNodesForProvider(instance.Provider, instance.Region, instance.InstanceType)
 				if instancesNeeded > maxNodes {
 					// Can't allocate all in one region - skip this instance type
 					continue
 				}
 			}
 
-			useSpot := constraints.AllowSpot && instance.SpotPrice > 0
-			price := instance.PricePerHour
-			if useSpot {
-				price = instance.SpotPrice
-			}
-
-			allocation = append(allocation, models.Allocation{
-				Provider:      instance.Provider,
-				InstanceType:  instance.InstanceType,
-				Region:        instance.Region,
-				Count:         instancesNeeded,
-				Spot:          useSpot,
-				PricePerHour:  price, // Store explicitly per instance
-				EstimatedCost: price * float64(instancesNeeded) * requirements.EstimatedHours,
-			})
+			allocation = append(allocation, buildAllocations(instance, instancesNeeded, requirements, constraints)...)
+			used = append(used, instance)
 
 			remaining -= instancesNeeded * instance.GPUsPerInstance
 		}
@@ -236,25 +444,137 @@ func (ao *AllocationOptimizer) cheapestStrategy(
 		return Strategy{Allocation: []models.Allocation{}}
 	}
 
+	if requirements.RequiresMultiNode {
+		applyInterconnectScaling(allocation, used)
+	}
+
 	return Strategy{Allocation: allocation}
 }
 
-// filterMultiNodeCompatible filters instances compatible with multi-node training
+// buildAllocations creates allocation rows for count instances of one type.
+// When spot is allowed, nodes are split between spot and on-demand rows so that
+// at most MaxSpotFraction of them run on spot and pinned ranks have on-demand nodes.
+func buildAllocations(
+	instance models.GPUInstance,
+	count int,
+	requirements models.JobRequirements,
+	constraints models.JobConstraints,
+) []models.Allocation {
+	spotCount, onDemandCount := splitSpotCount(count, instance, constraints)
+	duration := EstimatedDuration(requirements)
+
+	var allocations []models.Allocation
+	if spotCount > 0 {
+		allocations = append(allocations, models.Allocation{
+			Provider:      instance.Provider,
+			InstanceType:  instance.InstanceType,
+			Region:        instance.Region,
+			Count:         spotCount,
+			Spot:          true,
+			PricePerHour:  instance.SpotPrice, // Store explicitly per instance
+			EstimatedTime: duration,
+			GPUType:       instance.GPUType,
+			GPUMemoryGB:   instance.MemoryPerGPU,
+			GPUsPerNode:   instance.GPUsPerInstance,
+			BidStrategy:   spotBidStrategy(constraints),
+			SpotBid:       models.SpotMaxPrice(constraints, instance.SpotPrice, instance.PricePerHour),
+		})
+	}
+	if onDemandCount > 0 {
+		allocations = append(allocations, models.Allocation{
+			Provider:      instance.Provider,
+			InstanceType:  instance.InstanceType,
+			Region:        instance.Region,
+			Count:         onDemandCount,
+			Spot:          false,
+			PricePerHour:  instance.PricePerHour,
+			EstimatedTime: duration,
+			GPUType:       instance.GPUType,
+			GPUMemoryGB:   instance.MemoryPerGPU,
+			GPUsPerNode:   instance.GPUsPerInstance,
+		})
+	}
+
+	// Data volumes are priced per instance, so they are part of the estimate
+	for i := range allocations {
+		ApplyDataVolume(&allocations[i], requirements)
+		allocations[i].EstimatedCost = allocations[i].ExpectedCost()
+	}
+
+	return allocations
+}
+
+// spotBidStrategy returns the job's bid strategy, market when unset
+func spotBidStrategy(constraints models.JobConstraints) models.SpotBidStrategy {
+	if constraints.SpotBidStrategy == "" {
+		return models.SpotBidMarket
+	}
+	return constraints.SpotBidStrategy
+}
+
+// EstimatedDuration returns the expected run time of a job's allocation.
+// This is the only place allocation durations are derived (multi-node
+// allocations stretch it by their interconnect, see applyInterconnectScaling);
+// estimated costs must be computed from it so the two stay consistent.
+func EstimatedDuration(requirements models.JobRequirements) time.Duration {
+	return time.Duration(requirements.EstimatedHours * float64(time.Hour))
+}
+
+// splitSpotCount splits a node count into spot and on-demand nodes
+func splitSpotCount(count int, instance models.GPUInstance, constraints models.JobConstraints) (int, int) {
+	if !constraints.AllowSpot || instance.SpotPrice <= 0 {
+		return 0, count
+	}
+
+	spotCount := int(math.Floor(float64(count)*constraints.MaxSpotFraction + 1e-9))
+
+	// Pinned ranks always need an on-demand node
+	pinned := make(map[int]bool)
+	for _, rank := range constraints.OnDemandRanks {
+		pinned[rank] = true
+	}
+	if count-spotCount < len(pinned) {
+		spotCount = count - len(pinned)
+	}
+	if spotCount < 0 {
+		spotCount = 0
+	}
+
+	return spotCount, count - spotCount
+}
+
+// defaultMinInterconnectGbps is the node-to-node bandwidth multi-node jobs
+// need without resources.min_interconnect_gbps: what the high tier guarantees
+const defaultMinInterconnectGbps = models.HighTierInterconnectGbps
+
+// minInterconnectGbps returns the node-to-node bandwidth a multi-node job needs
+func minInterconnectGbps(requirements models.JobRequirements) float64 {
+	if requirements.MinInterconnectGbps > 0 {
+		return requirements.MinInterconnectGbps
+	}
+	return defaultMinInterconnectGbps
+}
+
+// filterMultiNodeCompatible filters instances compatible with multi-node
+// training: fast enough between nodes and within the node limits. Instances
+// of unknown bandwidth count as the least their tier guarantees, so a
+// standard one never qualifies and a high one only up to
+// models.HighTierInterconnectGbps.
 func (ao *AllocationOptimizer) filterMultiNodeCompatible(
 	candidates []models.GPUInstance,
 	requirements models.JobRequirements,
 ) []models.GPUInstance {
 	var filtered []models.GPUInstance
+	minGbps := minInterconnectGbps(requirements)
 
 	for _, instance := range candidates {
-		// MVP-safe rule: Multi-node training only supported for high-tier interconnect
-		hasFastInterconnect := instance.InterconnectTier == models.InterconnectHigh
+		fastEnough := instance.EffectiveInterconnectGbps() >= minGbps
 
 		// Check max nodes per AZ/cluster for this instance type
-		maxNodes := ao.getMaxNodesForProvider(instance.Provider, instance.Region)
+		maxNodes := ao.getMaxNodesForProvider(instance.Provider, instance.Region, instance.InstanceType)
 		minNodesNeeded := (requirements.GPUs + instance.GPUsPerInstance - 1) / instance.GPUsPerInstance
 
-		if hasFastInterconnect && minNodesNeeded <= maxNodes {
+		if fastEnough && minNodesNeeded <= maxNodes {
 			filtered = append(filtered, instance)
 		}
 	}
@@ -262,6 +582,33 @@ func (ao *AllocationOptimizer) filterMultiNodeCompatible(
 	return filtered
 }
 
+// applyInterconnectScaling stretches the estimated time of a multi-node
+// allocation by the communication overhead of its slowest interconnect, and
+// its estimated cost with it. Allocations using an instance type of unknown
+// bandwidth keep the job's estimate.
+func applyInterconnectScaling(allocations []models.Allocation, instances []models.GPUInstance) {
+	gbps := math.Inf(1)
+	for _, instance := range instances {
+		if instance.InterconnectGbps <= 0 {
+			return
+		}
+		gbps = math.Min(gbps, instance.InterconnectGbps)
+	}
+	nodes := 0
+	for _, alloc := range allocations {
+		nodes += alloc.Count
+	}
+
+	efficiency := interconnectEfficiency(nodes, gbps)
+	if efficiency >= 1 {
+		return
+	}
+	for i := range allocations {
+		allocations[i].EstimatedTime = time.Duration(float64(allocations[i].EstimatedTime) / efficiency)
+		allocations[i].EstimatedCost = allocations[i].ExpectedCost()
+	}
+}
+
 // parseRegionKey parses a region key (format: "provider:region") into provider and region
 func parseRegionKey(key string) (models.Provider, string) {
 	parts := strings.Split(key, ":")
@@ -271,22 +618,9 @@ func parseRegionKey(key string) (models.Provider, string) {
 	return models.Provider(parts[0]), parts[1]
 }
 
-// getMaxNodesForProvider returns max nodes per cluster/AZ for provider+region
-func (ao *AllocationOptimizer) getMaxNodesForProvider(provider models.Provider, region string) int {
-	// Provider-specific limits (region can be used for region-specific quotas in future)
-	_ = region // Reserved for future region-specific quota checks
-	switch provider {
-	case models.ProviderAWS:
-		return 16 // Conservative limit per AZ
-	case models.ProviderGCP:
-		return 32 // Per region
-	case models.ProviderAzure:
-		return 16 // Per availability set
-	case models.ProviderOnPrem:
-		return 100 // K8s cluster can be large
-	default:
-		return 8 // Conservative default
-	}
+// getMaxNodesForProvider returns max nodes per cluster/AZ for an instance type in provider+region
+func (ao *AllocationOptimizer) getMaxNodesForProvider(provider models.Provider, region, instanceType string) int {
+	return ao.nodeLimits.MaxNodes(provider, region, instanceType)
 }
 
 func (ao *AllocationOptimizer) reliableSingleRegionStrategy(
@@ -413,7 +747,8 @@ func (ao *AllocationOptimizer) dataLocalityStrategy(
 }
 
 // parseDatasetLocation extracts provider and region from dataset URI
-// Supports: s3://bucket/path, gs://bucket/path, az://container/path, minio://endpoint/bucket/path
+// Supports: s3://bucket/path, gs://bucket/path, az://container/path, minio://endpoint/bucket/path,
+// cw://bucket/path (CoreWeave object storage)
 func parseDatasetLocation(uri string) (models.Provider, string) {
 	// Phase 2: Parse URI to extract provider and region
 	// For now, use simple parsing
@@ -422,7 +757,7 @@ func parseDatasetLocation(uri string) (models.Provider, string) {
 		return models.ProviderAWS, "us-east-1" // Default
 	}
 
-	scheme := uri[:5] // s3://, gs://, az://, minio://
+	scheme := uri[:5] // s3://, gs://, az://, cw://, minio://
 
 	switch {
 	case scheme == "s3://":
@@ -434,6 +769,9 @@ func parseDatasetLocation(uri string) (models.Provider, string) {
 	case scheme == "az://":
 		// Azure Blob - default to eastus
 		return models.ProviderAzure, "eastus"
+	case scheme == "cw://":
+		// CoreWeave object storage - region not encoded in the URI
+		return models.ProviderCoreWeave, ""
 	case scheme == "minio":
 		// MinIO (on-premise) - no specific region
 		return models.ProviderOnPrem, ""
@@ -448,80 +786,187 @@ func (ao *AllocationOptimizer) scoreStrategies(
 	requirements models.JobRequirements,
 	constraints models.JobConstraints,
 ) []Strategy {
+	weights := constraints.ScoringWeights()
+	now := time.Now() // Time of day interruption rates are read at
+
+	// The carbon term is emissions relative to the highest of the strategies
+	maxEmissions := 0.0
+	for i := range strategies {
+		strategies[i].EmissionsGCO2e = ao.costCalculator.CarbonModel().AllocationEmissions(strategies[i].Allocation, requirements.EstimatedHours)
+		maxEmissions = math.Max(maxEmissions, strategies[i].EmissionsGCO2e)
+	}
+
 	for i := range strategies {
 		strategy := &strategies[i]
 
-		// Calculate cost metrics
+		// Calculate cost metrics over the strategy's own run time
 		totalCost, _ := ao.costCalculator.CalculateCost(
 			strategy.Allocation,
-			requirements.EstimatedHours,
+			strategyDuration(strategy.Allocation, requirements).Hours(),
 		)
 		strategy.TotalCost = totalCost
 
-		// Calculate data transfer cost
+		// Calculate data transfer cost: the dataset is copied once to every
+		// region the strategy uses
 		dataTransferCost := 0.0
 		if requirements.DatasetLocation != "" {
-			// Estimate transfer cost if dataset not in same region
+			datasetProvider, datasetRegion := parseDatasetLocation(requirements.DatasetLocation)
+			regions := make(map[string]bool)
 			for _, alloc := range strategy.Allocation {
-				transferCost := ao.costCalculator.CalculateDataTransferCost(
-					100.0, // Estimate dataset size (should be from job config)
-					parseProviderFromLocation(requirements.DatasetLocation),
-					parseRegionFromLocation(requirements.DatasetLocation),
+				key := fmt.Sprintf("%s:%s", alloc.Provider, alloc.Region)
+				if regions[key] {
+					continue
+				}
+				regions[key] = true
+				dataTransferCost += ao.costCalculator.CalculateDataTransferCost(
+					datasetSizeGB(requirements),
+					datasetProvider,
+					datasetRegion,
 					alloc.Provider,
 					alloc.Region,
 				)
-				dataTransferCost += transferCost
 			}
 		}
+		strategy.DataTransferCost = dataTransferCost
 
-		// Calculate reliability
-		spotCount := 0
+		// Calculate reliability from the spot/on-demand node mixture, at the
+		// observed interruption rate of each spot instance type
+		spotRisk := 0.0
+		totalCount := 0
 		for _, alloc := range strategy.Allocation {
-			if alloc.Spot {
-				spotCount += alloc.Count
-			}
+			totalCount += alloc.Count
+			spotRisk += ao.costCalculator.InterruptionRate(alloc, now) * float64(alloc.Count)
+		}
+		strategy.Reliability = 1.0
+		if totalCount > 0 {
+			strategy.Reliability = math.Max(0, 1.0-spotRisk/float64(totalCount))
 		}
-		// Simplified 10% interruption rate for spot instances
-		strategy.Reliability = 1.0 - (float64(spotCount) / float64(len(strategy.Allocation)) * 0.1)
 
-		// Calculate score (lower is better)
-		costWeight := 1.0 - constraints.PerformanceWeight
-		normalizedCost := (totalCost + dataTransferCost) / constraints.MaxBudget
+		// Calculate score (lower is better) as the weighted sum of its terms
+		strategy.Terms = models.ScoreTerms{
+			Cost:        totalCost / constraints.MaxBudget,
+			Reliability: 1.0 - strategy.Reliability,
+			Time:        timeTerm(strategy.Allocation, requirements, constraints),
+			Locality:    dataTransferCost / constraints.MaxBudget,
+		}
+		if maxEmissions > 0 {
+			strategy.Terms.Carbon = strategy.EmissionsGCO2e / maxEmissions
+		}
+		if requirements.Checkpointed {
+			strategy.Terms.Reliability *= checkpointedInterruptionCost
+		}
 
-		reliabilityPenalty := (1.0 - strategy.Reliability) * 0.2
+		// Tokens mode: cost of the job's token volume at this strategy's $/token
+		if requirements.Metric == models.MetricTokens {
+			if tokensPerHour := ao.tokensPerHour(strategy.Allocation, requirements); tokensPerHour > 0 {
+				hourly, _ := ao.costCalculator.CalculateCost(strategy.Allocation, 1)
+				strategy.USDPerMillionTokens = hourly / tokensPerHour * 1e6
+				strategy.Terms.Cost = strategy.USDPerMillionTokens * ao.referenceMillionTokens(requirements, tokensPerHour) / constraints.MaxBudget
+			}
+		}
+		strategy.Score = weightedScore(strategy.Terms, weights)
 
-		strategy.Score = costWeight*normalizedCost + reliabilityPenalty
+		// Lean toward the regions holding the team's data
+		strategy.GravityBonus = DataGravityBonus * gravityShare(strategy.Allocation, requirements.DataGravity)
+		strategy.Score -= strategy.GravityBonus
 
 		// Filter out strategies that don't meet constraints
-		if (totalCost + dataTransferCost) > constraints.MaxBudget {
-			strategy.Score = 999999 // Very bad score
+		strategy.Rejections = nil
+		if len(strategy.Allocation) == 0 {
+			strategy.Rejections = append(strategy.Rejections, models.StrategyRejection{
+				Reason: models.RejectionNoCapacity,
+			})
+		} else {
+			if (totalCost + dataTransferCost) > constraints.MaxBudget {
+				strategy.Rejections = append(strategy.Rejections, models.StrategyRejection{
+					Reason: models.RejectionOverBudget,
+					Value:  totalCost + dataTransferCost,
+					Limit:  constraints.MaxBudget,
+				})
+			}
+			if strategy.Reliability < constraints.MinReliability {
+				strategy.Rejections = append(strategy.Rejections, models.StrategyRejection{
+					Reason: models.RejectionLowReliability,
+					Value:  strategy.Reliability,
+					Limit:  constraints.MinReliability,
+				})
+			}
+			// A bid below the current spot price would never be filled
+			for _, alloc := range strategy.Allocation {
+				if alloc.Spot && alloc.SpotBid > 0 && alloc.SpotBid < alloc.PricePerHour {
+					strategy.Rejections = append(strategy.Rejections, models.StrategyRejection{
+						Reason: models.RejectionBidBelowSpot,
+						Value:  alloc.PricePerHour,
+						Limit:  alloc.SpotBid,
+					})
+					break
+				}
+			}
 		}
-		if strategy.Reliability < constraints.MinReliability {
-			strategy.Score = 999999
+		if len(strategy.Rejections) > 0 {
+			strategy.Score = 999999 // Very bad score
 		}
 	}
 
 	// Sort by score (best first)
-	sort.Slice(strategies, func(i, j int) bool {
+	sort.SliceStable(strategies, func(i, j int) bool {
 		return strategies[i].Score < strategies[j].Score
 	})
 
 	return strategies
 }
 
+// weightedScore combines score terms with normalized weights
+func weightedScore(terms models.ScoreTerms, weights models.ScoringWeights) float64 {
+	return weights.Cost*terms.Cost +
+		weights.Reliability*terms.Reliability +
+		weights.Time*terms.Time +
+		weights.Locality*terms.Locality +
+		weights.Carbon*terms.Carbon
+}
+
+// timeTerm is the strategy's run time relative to the time left until the
+// deadline, or to the job's estimated hours when there is no deadline
+func timeTerm(allocations []models.Allocation, requirements models.JobRequirements, constraints models.JobConstraints) float64 {
+	duration := strategyDuration(allocations, requirements)
+
+	reference := requirements.EstimatedHours
+	if constraints.Deadline != nil {
+		if left := time.Until(*constraints.Deadline).Hours(); left > 0 {
+			reference = left
+		}
+	}
+	if reference <= 0 {
+		reference = 1
+	}
+	return duration.Hours() / reference
+}
+
+// strategyDuration is the run time of a strategy: its longest allocation,
+// or the job's estimate without allocations
+func strategyDuration(allocations []models.Allocation, requirements models.JobRequirements) time.Duration {
+	duration := time.Duration(0)
+	for _, alloc := range allocations {
+		if alloc.EstimatedTime > duration {
+			duration = alloc.EstimatedTime
+		}
+	}
+	if duration == 0 {
+		duration = EstimatedDuration(requirements)
+	}
+	return duration
+}
+
 // Helper functions
 // parseRegionKey is defined above (line 266)
 
-func parseProviderFromLocation(location string) models.Provider {
-	// Parse URI scheme: s3:// -> aws, gs:// -> gcp, az:// -> azure, minio:// -> onprem
-	// TODO: Implement
-	_ = location // Reserved for future parsing implementation
-	return models.ProviderAWS
-}
+// defaultDatasetSizeGB is the assumed dataset size when it was not measured
+const defaultDatasetSizeGB = 100.0
 
-func parseRegionFromLocation(location string) string {
-	// Parse region from URI
-	// TODO: Implement
-	_ = location // Reserved for future parsing implementation
-	return "us-east-1"
+// datasetSizeGB returns the verified dataset size, or the default estimate
+func datasetSizeGB(requirements models.JobRequirements) float64 {
+	if requirements.DatasetSizeGB > 0 {
+		return requirements.DatasetSizeGB
+	}
+	return defaultDatasetSizeGB
 }
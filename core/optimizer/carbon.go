@@ -0,0 +1,170 @@
+package optimizer
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"strings"
+
+	"gpu-orchestrator/core/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed carbon_intensity.yaml
+var carbonIntensityYAML []byte
+
+// defaultGPUPowerKey is the gpu_power_watts entry of unlisted GPU types
+const defaultGPUPowerKey = "default"
+
+// CarbonRule sets the grid intensity of a provider's regions. Regions match
+// by prefix; without them the rule is the provider's default.
+type CarbonRule struct {
+	Provider  models.Provider `yaml:"provider"`
+	Regions   []string        `yaml:"regions,omitempty"`
+	Intensity float64         `yaml:"intensity"` // gCO2e per kWh
+}
+
+// carbonTable is the file format of the embedded dataset and of overrides
+type carbonTable struct {
+	PUE              float64            `yaml:"pue,omitempty"`
+	DefaultIntensity float64            `yaml:"default_intensity,omitempty"`
+	GPUPowerWatts    map[string]float64 `yaml:"gpu_power_watts,omitempty"`
+	Rules            []CarbonRule       `yaml:"rules,omitempty"`
+}
+
+// CarbonModel estimates the emissions of GPU usage from the grid intensity of
+// the region it runs in and the power draw of its GPU type
+type CarbonModel struct {
+	pue              float64
+	defaultIntensity float64
+	gpuPower         map[string]float64
+	overrides        []CarbonRule // CARBON_INTENSITY_FILE; consulted before the embedded rules
+	rules            []CarbonRule
+}
+
+// NewCarbonModel creates a carbon model from the embedded dataset
+func NewCarbonModel() *CarbonModel {
+	table, err := parseCarbonTable(carbonIntensityYAML)
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded carbon intensity: %v", err))
+	}
+	return &CarbonModel{
+		pue:              table.PUE,
+		defaultIntensity: table.DefaultIntensity,
+		gpuPower:         table.GPUPowerWatts,
+		rules:            table.Rules,
+	}
+}
+
+// LoadOverrides reads carbon overrides from a YAML file in the format of the
+// embedded dataset. Its rules are consulted first; pue, default_intensity and
+// gpu_power_watts entries replace the embedded values.
+func (cm *CarbonModel) LoadOverrides(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read carbon intensity: %w", err)
+	}
+	table, err := parseCarbonTable(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse carbon intensity: %w", err)
+	}
+	if table.PUE > 0 {
+		cm.pue = table.PUE
+	}
+	if table.DefaultIntensity > 0 {
+		cm.defaultIntensity = table.DefaultIntensity
+	}
+	for gpuType, watts := range table.GPUPowerWatts {
+		cm.gpuPower[gpuType] = watts
+	}
+	cm.overrides = table.Rules
+	return nil
+}
+
+// parseCarbonTable parses and validates a carbon table
+func parseCarbonTable(data []byte) (*carbonTable, error) {
+	table := &carbonTable{GPUPowerWatts: make(map[string]float64)}
+	if err := yaml.Unmarshal(data, table); err != nil {
+		return nil, err
+	}
+	if table.PUE != 0 && table.PUE < 1 {
+		return nil, fmt.Errorf("pue must be at least 1, got %g", table.PUE)
+	}
+	if table.DefaultIntensity < 0 {
+		return nil, fmt.Errorf("default_intensity must not be negative")
+	}
+	for gpuType, watts := range table.GPUPowerWatts {
+		if watts <= 0 {
+			return nil, fmt.Errorf("gpu_power_watts of %s must be positive", gpuType)
+		}
+	}
+	for i, rule := range table.Rules {
+		if rule.Provider == "" {
+			return nil, fmt.Errorf("rule %d: provider is required", i+1)
+		}
+		if rule.Intensity < 0 {
+			return nil, fmt.Errorf("rule %d: intensity must not be negative", i+1)
+		}
+	}
+	return table, nil
+}
+
+// Intensity returns the grid intensity of a region in gCO2e per kWh
+func (cm *CarbonModel) Intensity(provider models.Provider, region string) float64 {
+	for _, rules := range [][]CarbonRule{cm.overrides, cm.rules} {
+		best, bestLength := -1.0, -1
+		for _, rule := range rules {
+			if rule.Provider != provider {
+				continue
+			}
+			if length := matchedPrefix(rule.Regions, region); length > bestLength {
+				best, bestLength = rule.Intensity, length
+			}
+		}
+		if bestLength >= 0 {
+			return best
+		}
+	}
+	return cm.defaultIntensity
+}
+
+// matchedPrefix returns the length of the longest prefix region starts with,
+// 0 for an empty list (matches every region) and -1 when none matches
+func matchedPrefix(prefixes []string, region string) int {
+	if len(prefixes) == 0 {
+		return 0
+	}
+	longest := -1
+	for _, prefix := range prefixes {
+		if region != "" && strings.HasPrefix(region, prefix) && len(prefix) > longest {
+			longest = len(prefix)
+		}
+	}
+	return longest
+}
+
+// GPUPowerWatts returns the power draw of one GPU of a type under load
+func (cm *CarbonModel) GPUPowerWatts(gpuType string) float64 {
+	if watts, ok := cm.gpuPower[gpuType]; ok {
+		return watts
+	}
+	return cm.gpuPower[defaultGPUPowerKey]
+}
+
+// Emissions returns the gCO2e of GPU-hours of a GPU type in a region,
+// including data center overhead (PUE)
+func (cm *CarbonModel) Emissions(provider models.Provider, region, gpuType string, gpuHours float64) float64 {
+	kWh := gpuHours * cm.GPUPowerWatts(gpuType) / 1000 * cm.pue
+	return kWh * cm.Intensity(provider, region)
+}
+
+// AllocationEmissions returns the gCO2e of running allocations for hours
+func (cm *CarbonModel) AllocationEmissions(allocations []models.Allocation, hours float64) float64 {
+	total := 0.0
+	for _, alloc := range allocations {
+		gpus := alloc.Count * alloc.GPUsPerNode
+		total += cm.Emissions(alloc.Provider, alloc.Region, alloc.GPUType, float64(gpus)*hours)
+	}
+	return total
+}
@@ -1,18 +1,24 @@
 package optimizer
 
 import (
+	"time"
+
 	"gpu-orchestrator/core/models"
 )
 
 // CostCalculator calculates costs for allocations
 type CostCalculator struct {
-	pricingFetcher *PricingFetcher
+	pricingFetcher    *PricingFetcher
+	transferOverrides []TransferRule // TRANSFER_PRICING_FILE; consulted before the embedded table
+	interruptions     *InterruptionModel
+	carbon            *CarbonModel
 }
 
 // NewCostCalculator creates a new cost calculator
 func NewCostCalculator(pf *PricingFetcher) *CostCalculator {
 	return &CostCalculator{
 		pricingFetcher: pf,
+		carbon:         NewCarbonModel(),
 	}
 }
 
@@ -21,32 +27,68 @@ func (cc *CostCalculator) CalculateCost(allocation []models.Allocation, estimate
 	totalCost := 0.0
 
 	for _, alloc := range allocation {
-		// PricePerHour is explicitly stored per instance
-		cost := alloc.PricePerHour * float64(alloc.Count) * estimatedHours
+		// HourlyPrice is the instance price plus its data volume
+		cost := alloc.HourlyPrice() * float64(alloc.Count) * estimatedHours
 		totalCost += cost
 	}
 
 	return totalCost, nil
 }
 
-// CalculateCostWithReliability calculates cost with spot instance interruption probability
+// SetInterruptionModel sets the observed spot interruption rates used in
+// place of the static estimates
+func (cc *CostCalculator) SetInterruptionModel(model *InterruptionModel) {
+	cc.interruptions = model
+}
+
+// InterruptionModel returns the observed spot interruption rates, or nil
+func (cc *CostCalculator) InterruptionModel() *InterruptionModel {
+	return cc.interruptions
+}
+
+// CarbonModel returns the grid intensity and GPU power model emissions are
+// estimated with
+func (cc *CostCalculator) CarbonModel() *CarbonModel {
+	return cc.carbon
+}
+
+// LoadCarbonIntensity reads carbon overrides from a YAML file. Its rules are
+// consulted before the embedded dataset.
+func (cc *CostCalculator) LoadCarbonIntensity(path string) error {
+	return cc.carbon.LoadOverrides(path)
+}
+
+// InterruptionRate returns the interruption rate per instance-hour of an
+// allocation's instances at a time of day, scaled by its spot bid strategy;
+// 0 for on-demand
+func (cc *CostCalculator) InterruptionRate(alloc models.Allocation, at time.Time) float64 {
+	if !alloc.Spot {
+		return 0
+	}
+	rate := StaticInterruptionRate(alloc.Provider)
+	if cc.interruptions != nil {
+		rate = cc.interruptions.Rate(alloc.Provider, alloc.InstanceType, alloc.Region, at)
+	}
+	return rate * alloc.BidStrategy.InterruptionFactor()
+}
+
+// CalculateCostWithReliability calculates cost with the expected spot
+// interruptions of the allocation's spot instances
 func (cc *CostCalculator) CalculateCostWithReliability(
 	allocation []models.Allocation,
 	estimatedHours float64,
-	spotInterruptionRate float64, // e.g., 0.1 = 10% chance of interruption
 ) (float64, float64) {
 	baseCost, _ := cc.CalculateCost(allocation, estimatedHours)
 
-	// Calculate expected cost including restarts
-	spotInstances := 0
+	// Any interrupted instance interrupts the job
+	interruptionsPerHour := 0.0
+	now := time.Now()
 	for _, alloc := range allocation {
-		if alloc.Spot {
-			spotInstances += alloc.Count
-		}
+		interruptionsPerHour += cc.InterruptionRate(alloc, now) * float64(alloc.Count)
 	}
 
 	// Expected interruptions = hours × interruption_rate
-	expectedInterruptions := estimatedHours * spotInterruptionRate
+	expectedInterruptions := estimatedHours * interruptionsPerHour
 
 	// Each interruption adds ~10 minutes overhead (restart time)
 	overheadHours := expectedInterruptions * (10.0 / 60.0)
@@ -73,14 +115,15 @@ func (cc *CostCalculator) CalculateCostPerStep(
 	// Calculate hourly cost
 	hourlyCost := 0.0
 	for _, alloc := range allocation {
-		hourlyCost += alloc.PricePerHour * float64(alloc.Count)
+		hourlyCost += alloc.HourlyPrice() * float64(alloc.Count)
 	}
 
 	// Cost per step = hourly cost / steps per hour
 	return hourlyCost / metrics.StepsPerHour, nil
 }
 
-// CalculateDataTransferCost calculates data transfer cost between regions
+// CalculateDataTransferCost calculates the cost of moving data between
+// regions from the transfer pricing table. Transfers within a region are free.
 func (cc *CostCalculator) CalculateDataTransferCost(
 	dataSizeGB float64,
 	sourceProvider models.Provider,
@@ -88,17 +131,19 @@ func (cc *CostCalculator) CalculateDataTransferCost(
 	targetProvider models.Provider,
 	targetRegion string,
 ) float64 {
-	// If same provider and region, no transfer cost
-	if sourceProvider == targetProvider && sourceRegion == targetRegion {
+	if dataSizeGB <= 0 || (sourceProvider == targetProvider && sourceRegion == targetRegion) {
 		return 0.0
 	}
 
-	// TODO: Implement provider-specific egress pricing
-	// AWS: ~$0.09/GB for first 10TB
-	// GCP: ~$0.12/GB for first 10TB
-	// Azure: ~$0.087/GB for first 10TB
+	rule := cc.transferRule(sourceProvider, sourceRegion, targetProvider, targetRegion)
+	if rule == nil {
+		return dataSizeGB * defaultEgressPerGB
+	}
+	return rule.cost(dataSizeGB)
+}
 
-	// Simplified: Use average egress cost
-	egressCostPerGB := 0.10 // $0.10 per GB
-	return dataSizeGB * egressCostPerGB
+// CalculateEgressCost calculates the cost of data sent from a region to
+// addresses outside the provider's network, priced as internet transfer
+func (cc *CostCalculator) CalculateEgressCost(dataSizeGB float64, provider models.Provider, region string) float64 {
+	return cc.CalculateDataTransferCost(dataSizeGB, provider, region, TransferInternet, "")
 }
@@ -0,0 +1,180 @@
+package optimizer
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"gpu-orchestrator/core/models"
+)
+
+// Data gravity parameters
+const (
+	// DataGravityBonus is the score bonus of a strategy entirely in regions
+	// holding all of the team's data; scores are mostly 0-1, so it only
+	// breaks near ties
+	DataGravityBonus = 0.05
+	// dataGravityHalfLives is how many half-lives of history are read; older
+	// data would count less than 1/16
+	dataGravityHalfLives = 4
+	// dataGravityCacheTTL is how long a team's profile is reused
+	dataGravityCacheTTL = 5 * time.Minute
+)
+
+// DataGravityHistory lists the data a team's gravity is computed from
+type DataGravityHistory interface {
+	ListTeamDataFootprints(teamID string, since time.Time) ([]models.DataFootprint, error)
+}
+
+// cachedGravity is a team's profile and when it was computed
+type cachedGravity struct {
+	profile *models.DataGravityProfile
+	at      time.Time
+}
+
+// DataGravity computes where each team's datasets, checkpoints and completed
+// jobs live, so jobs without a region constraint lean toward that data. Safe
+// for concurrent use.
+type DataGravity struct {
+	history  DataGravityHistory
+	halfLife time.Duration
+	mu       sync.Mutex
+	cache    map[string]cachedGravity
+}
+
+// NewDataGravity creates a data gravity model; data halfLife old counts half
+func NewDataGravity(history DataGravityHistory, halfLife time.Duration) *DataGravity {
+	return &DataGravity{
+		history:  history,
+		halfLife: halfLife,
+		cache:    make(map[string]cachedGravity),
+	}
+}
+
+// Profile returns the team's data gravity, computing it when the cached
+// profile is older than dataGravityCacheTTL
+func (g *DataGravity) Profile(teamID string) (*models.DataGravityProfile, error) {
+	now := time.Now()
+	g.mu.Lock()
+	cached, ok := g.cache[teamID]
+	g.mu.Unlock()
+	if ok && now.Sub(cached.at) < dataGravityCacheTTL {
+		return cached.profile, nil
+	}
+
+	footprints, err := g.history.ListTeamDataFootprints(teamID, now.Add(-dataGravityHalfLives*g.halfLife))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list data of team %s: %w", teamID, err)
+	}
+	profile := ComputeDataGravity(teamID, footprints, g.halfLife, now)
+
+	g.mu.Lock()
+	g.cache[teamID] = cachedGravity{profile: profile, at: now}
+	g.mu.Unlock()
+	return profile, nil
+}
+
+// ComputeDataGravity weights every footprint by its size and by
+// 0.5^(age/halfLife), and scores each region by its share of the total.
+// Datasets count once, at their latest use; datasets whose region cannot be
+// told from their URI are left out.
+func ComputeDataGravity(teamID string, footprints []models.DataFootprint, halfLife time.Duration, now time.Time) *models.DataGravityProfile {
+	profile := &models.DataGravityProfile{
+		TeamID:       teamID,
+		HalfLifeDays: halfLife.Hours() / 24,
+		Regions:      []models.RegionGravity{},
+		ComputedAt:   now,
+	}
+
+	// Latest use of each dataset
+	datasets := make(map[string]models.DataFootprint)
+	var located []models.DataFootprint
+	for _, footprint := range footprints {
+		if footprint.Kind != models.FootprintDataset {
+			located = append(located, footprint)
+			continue
+		}
+		if latest, ok := datasets[footprint.URI]; ok && !footprint.At.After(latest.At) {
+			continue
+		}
+		datasets[footprint.URI] = footprint
+	}
+	for uri, footprint := range datasets {
+		footprint.Provider, footprint.Region = parseDatasetLocation(uri)
+		located = append(located, footprint)
+	}
+
+	regions := make(map[string]*models.RegionGravity)
+	total := 0.0
+	for _, footprint := range located {
+		if footprint.Provider == "" || footprint.Region == "" {
+			continue
+		}
+		size := footprint.SizeGB
+		if size <= 0 && footprint.Kind != models.FootprintCheckpoint {
+			size = defaultDatasetSizeGB
+		}
+		if size <= 0 {
+			continue
+		}
+
+		key := fmt.Sprintf("%s:%s", footprint.Provider, footprint.Region)
+		region, ok := regions[key]
+		if !ok {
+			region = &models.RegionGravity{Provider: footprint.Provider, Region: footprint.Region}
+			regions[key] = region
+		}
+		switch footprint.Kind {
+		case models.FootprintDataset:
+			region.DatasetGB += size
+			region.Datasets++
+		case models.FootprintCheckpoint:
+			region.CheckpointGB += size
+			region.Checkpoints++
+		default:
+			region.JobGB += size
+			region.Jobs++
+		}
+		weighted := size * recencyWeight(now.Sub(footprint.At), halfLife)
+		region.WeightedGB += weighted
+		total += weighted
+	}
+
+	for _, region := range regions {
+		if total > 0 {
+			region.Score = region.WeightedGB / total
+		}
+		profile.Regions = append(profile.Regions, *region)
+	}
+	sort.Slice(profile.Regions, func(i, j int) bool {
+		a, b := profile.Regions[i], profile.Regions[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return string(a.Provider)+a.Region < string(b.Provider)+b.Region
+	})
+	return profile
+}
+
+// recencyWeight halves every halfLife; future timestamps count fully
+func recencyWeight(age, halfLife time.Duration) float64 {
+	if age <= 0 || halfLife <= 0 {
+		return 1
+	}
+	return math.Pow(0.5, age.Hours()/halfLife.Hours())
+}
+
+// gravityShare is the node-weighted gravity score of an allocation's regions
+func gravityShare(allocations []models.Allocation, gravity map[string]float64) float64 {
+	nodes, share := 0, 0.0
+	for _, alloc := range allocations {
+		nodes += alloc.Count
+		share += gravity[fmt.Sprintf("%s:%s", alloc.Provider, alloc.Region)] * float64(alloc.Count)
+	}
+	if nodes == 0 {
+		return 0
+	}
+	return share / float64(nodes)
+}
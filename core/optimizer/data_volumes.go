@@ -0,0 +1,101 @@
+package optimizer
+
+import (
+	"gpu-orchestrator/core/models"
+)
+
+// hoursPerMonth prorates per-GB-month volume prices to hours
+const hoursPerMonth = 730
+
+// volumePricing is the data volume a provider attaches and its monthly prices.
+// IOPS and throughput above the baseline are billed separately (gp3 only).
+type volumePricing struct {
+	Type               string
+	PerGBMonth         float64
+	BaselineIOPS       int
+	PerIOPSMonth       float64
+	BaselineThroughput int // MB/s
+	PerMBpsMonth       float64
+}
+
+// dataVolumePricing lists the data volume of each provider. Providers without
+// an entry get no volume; /data is then a directory on the boot disk.
+var dataVolumePricing = map[models.Provider]volumePricing{
+	models.ProviderAWS: {
+		Type:               "gp3",
+		PerGBMonth:         0.08,
+		BaselineIOPS:       3000,
+		PerIOPSMonth:       0.005,
+		BaselineThroughput: 125,
+		PerMBpsMonth:       0.04,
+	},
+	models.ProviderGCP:       {Type: "pd-ssd", PerGBMonth: 0.17},
+	models.ProviderAzure:     {Type: "premium-ssd", PerGBMonth: 0.132},
+	models.ProviderCoreWeave: {Type: "block-nvme", PerGBMonth: 0.07},
+}
+
+// instanceStoreGB is the local NVMe storage that comes with an instance type.
+// Jobs whose resources.storage fits use it instead of a data volume.
+var instanceStoreGB = map[string]int{
+	"g4dn.xlarge":              125,
+	"g5.xlarge":                250,
+	"g6.xlarge":                250,
+	"g6.12xlarge":              3760,
+	"g6e.xlarge":               250,
+	"g6e.12xlarge":             3800,
+	"p4d.24xlarge":             8000,
+	"p4de.24xlarge":            8000,
+	"p5.48xlarge":              30400,
+	"a3-highgpu-8g":            6000,
+	"Standard_NC96ads_A100_v4": 3576,
+	"Standard_ND96isr_H100_v5": 28000,
+}
+
+// InstanceStoreGB returns the local NVMe storage of an instance type, 0 when
+// it has none or is unknown
+func InstanceStoreGB(instanceType string) int {
+	return instanceStoreGB[instanceType]
+}
+
+// DataVolumeType returns the data volume type attached on a provider, or ""
+// when the provider has none
+func DataVolumeType(provider models.Provider) string {
+	return dataVolumePricing[provider].Type
+}
+
+// DataVolumePricePerHour returns the prorated hourly price of one data volume
+// of sizeGB on a provider, including provisioned IOPS and throughput above the
+// baseline where the volume bills them
+func DataVolumePricePerHour(provider models.Provider, sizeGB, iops, throughputMBps int) float64 {
+	pricing, ok := dataVolumePricing[provider]
+	if !ok || sizeGB <= 0 {
+		return 0
+	}
+
+	monthly := pricing.PerGBMonth * float64(sizeGB)
+	if pricing.PerIOPSMonth > 0 && iops > pricing.BaselineIOPS {
+		monthly += pricing.PerIOPSMonth * float64(iops-pricing.BaselineIOPS)
+	}
+	if pricing.PerMBpsMonth > 0 && throughputMBps > pricing.BaselineThroughput {
+		monthly += pricing.PerMBpsMonth * float64(throughputMBps-pricing.BaselineThroughput)
+	}
+	return monthly / hoursPerMonth
+}
+
+// ApplyDataVolume sizes the data volume of an allocation from the job's
+// resources.storage. Instances whose instance store holds the requested
+// storage get no volume; the bootstrap mounts the instance store instead.
+func ApplyDataVolume(alloc *models.Allocation, requirements models.JobRequirements) {
+	alloc.VolumeType, alloc.VolumeGB, alloc.StoragePricePerHour = "", 0, 0
+	if requirements.Storage <= 0 || InstanceStoreGB(alloc.InstanceType) >= requirements.Storage {
+		return
+	}
+
+	volumeType := DataVolumeType(alloc.Provider)
+	if volumeType == "" {
+		return
+	}
+	alloc.VolumeType = volumeType
+	alloc.VolumeGB = requirements.Storage
+	alloc.StoragePricePerHour = DataVolumePricePerHour(alloc.Provider, requirements.Storage, requirements.StorageIOPS, requirements.StorageThroughput)
+}
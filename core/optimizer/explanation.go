@@ -0,0 +1,307 @@
+package optimizer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gpu-orchestrator/core/models"
+)
+
+// newDecision records scored strategies (best first) as a decision
+func newDecision(strategies []Strategy, requirements models.JobRequirements, weights models.ScoringWeights) *models.AllocationDecision {
+	decision := &models.AllocationDecision{
+		Strategies:      make([]models.StrategyEvaluation, 0, len(strategies)),
+		DatasetLocation: requirements.DatasetLocation,
+		Weights:         &weights,
+		DecidedAt:       time.Now(),
+	}
+	if requirements.Metric == models.MetricTokens {
+		decision.Metric = models.MetricTokens
+	}
+	if len(requirements.DataGravity) > 0 {
+		decision.DataGravity = requirements.DataGravity
+	}
+	for _, strategy := range strategies {
+		decision.Strategies = append(decision.Strategies, evaluationOf(strategy))
+	}
+	if len(strategies) > 0 && len(strategies[0].Allocation) > 0 {
+		decision.Chosen = strategies[0].Name
+	}
+	decision.Explanation = ExplainDecision(decision)
+	return decision
+}
+
+// evaluationOf converts a scored strategy to its decision record
+func evaluationOf(strategy Strategy) models.StrategyEvaluation {
+	terms := strategy.Terms
+	evaluation := models.StrategyEvaluation{
+		Strategy:            strategy.Name,
+		TotalCost:           strategy.TotalCost,
+		DataTransferCost:    strategy.DataTransferCost,
+		Reliability:         strategy.Reliability,
+		USDPerMillionTokens: strategy.USDPerMillionTokens,
+		EmissionsGCO2e:      strategy.EmissionsGCO2e,
+		GravityBonus:        strategy.GravityBonus,
+		Score:               strategy.Score,
+		Terms:               &terms,
+		Rejections:          strategy.Rejections,
+		TaskSpread:          strategy.TaskSpread,
+	}
+	for _, alloc := range strategy.Allocation {
+		evaluation.Allocations = append(evaluation.Allocations, models.DecisionAllocation{
+			Provider:     alloc.Provider,
+			Region:       alloc.Region,
+			InstanceType: alloc.InstanceType,
+			Count:        alloc.Count,
+			Spot:         alloc.Spot,
+			PricePerHour: alloc.PricePerHour,
+			TaskGroup:    alloc.TaskGroup,
+		})
+		evaluation.HourlyCost += alloc.HourlyPrice() * float64(alloc.Count)
+	}
+	return evaluation
+}
+
+// ExplainDecision turns a decision into a short narrative: what was chosen
+// and why each distinct alternative lost. Missing prices, allocations or
+// strategy names are left out of the text rather than rendered as zeros.
+func ExplainDecision(decision *models.AllocationDecision) string {
+	if decision == nil {
+		return "No allocation decision was recorded."
+	}
+	if len(decision.TaskGroups) > 0 {
+		return explainTaskGroups(decision)
+	}
+	if len(decision.Strategies) == 0 && decision.ExcludedInstances > 0 {
+		return fmt.Sprintf("No allocation chosen: the instance type allow/deny lists excluded all %d instances that met the job's GPU requirements.", decision.ExcludedInstances)
+	}
+	if len(decision.Strategies) == 0 {
+		return "No allocation chosen: no instance type met the job's GPU requirements."
+	}
+
+	chosen, ok := decision.ChosenStrategy()
+	if !ok {
+		var clauses []string
+		for _, strategy := range distinctStrategies(decision.Strategies, nil) {
+			reason := rejectionText(strategy.Rejections)
+			if reason == "" {
+				reason = "not feasible"
+			}
+			clauses = append(clauses, strategyLabel(strategy)+" was rejected: "+reason)
+		}
+		return "No allocation chosen: " + strings.Join(clauses, "; ") + "."
+	}
+
+	var b strings.Builder
+	b.WriteString("Chose ")
+	b.WriteString(describeAllocations(chosen.Allocations))
+	switch {
+	case chosen.HourlyCost > 0 && chosen.USDPerMillionTokens > 0:
+		fmt.Fprintf(&b, " ($%.2f/hr, $%.2f/1M tokens)", chosen.HourlyCost, chosen.USDPerMillionTokens)
+	case chosen.HourlyCost > 0:
+		fmt.Fprintf(&b, " ($%.2f/hr)", chosen.HourlyCost)
+	}
+	if len(chosen.TaskSpread) > 0 {
+		b.WriteString(" running ")
+		b.WriteString(describeTaskSpread(chosen.TaskSpread))
+	}
+	if len(chosen.Rejections) > 0 {
+		// Best of the infeasible strategies; the scheduler still received it
+		b.WriteString(" although its ")
+		b.WriteString(rejectionText(chosen.Rejections))
+	}
+
+	carbonWeight := 0.0
+	if decision.Weights != nil {
+		carbonWeight = decision.Weights.Carbon
+	}
+	var clauses []string
+	for _, alternative := range distinctStrategies(decision.Strategies, &chosen) {
+		clauses = append(clauses, compareStrategy(chosen, alternative, decision.DatasetLocation, carbonWeight))
+	}
+	if len(clauses) > 0 {
+		b.WriteString(": ")
+		b.WriteString(strings.Join(clauses, "; "))
+	}
+	b.WriteString(".")
+	return b.String()
+}
+
+// distinctStrategies drops strategies that produced the same allocation as
+// exclude or as an earlier strategy, since they explain nothing new
+func distinctStrategies(strategies []models.StrategyEvaluation, exclude *models.StrategyEvaluation) []models.StrategyEvaluation {
+	seen := make(map[string]bool)
+	if exclude != nil {
+		seen[allocationKey(*exclude)] = true
+	}
+
+	var distinct []models.StrategyEvaluation
+	for _, strategy := range strategies {
+		key := allocationKey(strategy)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		distinct = append(distinct, strategy)
+	}
+	return distinct
+}
+
+// allocationKey identifies a strategy by its allocation; strategies without
+// one are identified by name
+func allocationKey(strategy models.StrategyEvaluation) string {
+	if len(strategy.Allocations) == 0 {
+		return "strategy:" + strategy.Strategy
+	}
+	return fmt.Sprintf("%v", strategy.Allocations)
+}
+
+// compareStrategy explains why an alternative lost to the chosen strategy
+func compareStrategy(chosen, alternative models.StrategyEvaluation, datasetLocation string, carbonWeight float64) string {
+	label := strategyLabel(alternative)
+
+	if len(alternative.Rejections) > 0 {
+		if pct, ok := percentCheaper(alternative, chosen); ok {
+			return fmt.Sprintf("%s was %.0f%% cheaper but %s", label, pct, rejectionText(alternative.Rejections))
+		}
+		return label + " was rejected: " + rejectionText(alternative.Rejections)
+	}
+
+	if alternative.HourlyCost > 0 && alternative.HourlyCost < chosen.HourlyCost {
+		saving := fmt.Sprintf("%s was $%.2f/hr cheaper", label, chosen.HourlyCost-alternative.HourlyCost)
+		switch {
+		case alternative.USDPerMillionTokens > chosen.USDPerMillionTokens && chosen.USDPerMillionTokens > 0:
+			return fmt.Sprintf("%s but costs more per token ($%.2f vs $%.2f/1M tokens)", saving, alternative.USDPerMillionTokens, chosen.USDPerMillionTokens)
+		case alternative.DataTransferCost > chosen.DataTransferCost:
+			if datasetLocation != "" {
+				return fmt.Sprintf("%s but dataset locality (%s) adds an estimated $%.2f transfer cost",
+					saving, datasetLocation, alternative.DataTransferCost-chosen.DataTransferCost)
+			}
+			return fmt.Sprintf("%s but data transfer adds an estimated $%.2f", saving, alternative.DataTransferCost-chosen.DataTransferCost)
+		case alternative.Reliability < chosen.Reliability:
+			return fmt.Sprintf("%s but less reliable (%.2f vs %.2f)", saving, alternative.Reliability, chosen.Reliability)
+		case carbonWeight > 0 && alternative.EmissionsGCO2e > chosen.EmissionsGCO2e:
+			return fmt.Sprintf("%s but emits more (%.1f vs %.1f kgCO2e)", saving, alternative.EmissionsGCO2e/1000, chosen.EmissionsGCO2e/1000)
+		case alternative.GravityBonus < chosen.GravityBonus:
+			return fmt.Sprintf("%s but is farther from the team's data (gravity bonus %.3f vs %.3f)", saving, alternative.GravityBonus, chosen.GravityBonus)
+		default:
+			return fmt.Sprintf("%s but scored worse overall (%.3f vs %.3f)", saving, alternative.Score, chosen.Score)
+		}
+	}
+
+	extra := (alternative.TotalCost + alternative.DataTransferCost) - (chosen.TotalCost + chosen.DataTransferCost)
+	if extra >= 0.01 {
+		return fmt.Sprintf("%s would cost $%.2f more in total", label, extra)
+	}
+	return fmt.Sprintf("%s scored worse (%.3f vs %.3f)", label, alternative.Score, chosen.Score)
+}
+
+// percentCheaper returns how much cheaper a is than b, by hourly cost when
+// both have one and by total cost otherwise
+func percentCheaper(a, b models.StrategyEvaluation) (float64, bool) {
+	aCost, bCost := a.HourlyCost, b.HourlyCost
+	if aCost <= 0 || bCost <= 0 {
+		aCost, bCost = a.TotalCost, b.TotalCost
+	}
+	if aCost <= 0 || bCost <= 0 || aCost >= bCost {
+		return 0, false
+	}
+	return (1 - aCost/bCost) * 100, true
+}
+
+// strategyLabel names an alternative by its allocation, or by strategy name
+// when it has none
+func strategyLabel(strategy models.StrategyEvaluation) string {
+	if len(strategy.Allocations) > 0 {
+		return describeAllocations(strategy.Allocations)
+	}
+	if strategy.Strategy == "" {
+		return "a strategy"
+	}
+	return "the " + strings.ReplaceAll(strategy.Strategy, "_", " ") + " strategy"
+}
+
+// describeTaskSpread renders tasks per region, e.g. "3 tasks in aws us-east-1
+// and 2 in gcp us-central1"
+func describeTaskSpread(spread []models.RegionTasks) string {
+	var parts []string
+	for i, region := range spread {
+		unit := ""
+		if i == 0 {
+			unit = " tasks"
+			if region.Tasks == 1 {
+				unit = " task"
+			}
+		}
+		parts = append(parts, fmt.Sprintf("%d%s in %s %s", region.Tasks, unit, region.Provider, region.Region))
+	}
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	return strings.Join(parts[:len(parts)-1], ", ") + " and " + parts[len(parts)-1]
+}
+
+// describeAllocations renders allocations as e.g.
+// "1× p4d.24xlarge spot + 1× p4d.24xlarge on-demand in aws us-east-1"
+func describeAllocations(allocations []models.DecisionAllocation) string {
+	if len(allocations) == 0 {
+		return "no instances"
+	}
+
+	var groups, parts []string
+	location := ""
+	flush := func() {
+		group := strings.Join(parts, " + ")
+		if location != "" {
+			group += " in " + location
+		}
+		groups = append(groups, group)
+		parts = nil
+	}
+
+	for i, alloc := range allocations {
+		allocLocation := strings.TrimSpace(fmt.Sprintf("%s %s", alloc.Provider, alloc.Region))
+		if i > 0 && allocLocation != location {
+			flush()
+		}
+		location = allocLocation
+
+		instanceType := alloc.InstanceType
+		if instanceType == "" {
+			instanceType = "instance"
+		}
+		market := "on-demand"
+		if alloc.Spot {
+			market = "spot"
+		}
+		parts = append(parts, fmt.Sprintf("%d× %s %s", alloc.Count, instanceType, market))
+	}
+	flush()
+
+	return strings.Join(groups, ", ")
+}
+
+// rejectionText renders the constraints a strategy failed
+func rejectionText(rejections []models.StrategyRejection) string {
+	var parts []string
+	for _, rejection := range rejections {
+		switch rejection.Reason {
+		case models.RejectionOverBudget:
+			if rejection.Value > 0 {
+				parts = append(parts, fmt.Sprintf("cost $%.2f exceeds budget $%.2f", rejection.Value, rejection.Limit))
+			} else {
+				parts = append(parts, "cost exceeds budget")
+			}
+		case models.RejectionLowReliability:
+			parts = append(parts, fmt.Sprintf("reliability %.2f < required %.2f", rejection.Value, rejection.Limit))
+		case models.RejectionNoCapacity:
+			parts = append(parts, "could not place all requested GPUs")
+		case models.RejectionBidBelowSpot:
+			parts = append(parts, fmt.Sprintf("spot bid $%.4f/h is below the current spot price $%.4f/h", rejection.Limit, rejection.Value))
+		default:
+			parts = append(parts, strings.ReplaceAll(rejection.Reason, "_", " "))
+		}
+	}
+	return strings.Join(parts, " and ")
+}
@@ -0,0 +1,169 @@
+package optimizer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/supervisor"
+)
+
+// InstanceTypeRuleHistory is the store of admin changes to the lists
+type InstanceTypeRuleHistory interface {
+	LatestChange() (*models.InstanceTypeRuleChange, error)
+}
+
+// InstanceTypeRules are the org-wide instance type allow and deny lists.
+// Patterns are shell globs matched case-insensitively against the instance
+// type or its GPU type, e.g. "p2.*", "*-k80", "K80". Safe for concurrent
+// use; updates apply to subsequent optimizations.
+type InstanceTypeRules struct {
+	allow    []string
+	deny     []string
+	changeID int64 // Admin change the lists come from; 0 = configuration
+	mu       sync.RWMutex
+}
+
+// NewInstanceTypeRules creates rules from the configured lists
+func NewInstanceTypeRules(allow, deny []string) (*InstanceTypeRules, error) {
+	rules := &InstanceTypeRules{}
+	if err := rules.Set(allow, deny); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// Set replaces both lists
+func (r *InstanceTypeRules) Set(allow, deny []string) error {
+	if err := ValidateInstanceTypePatterns(allow); err != nil {
+		return err
+	}
+	if err := ValidateInstanceTypePatterns(deny); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.allow = append([]string(nil), allow...)
+	r.deny = append([]string(nil), deny...)
+	return nil
+}
+
+// Sync applies the latest admin change if it is newer than the current lists.
+// Admin changes replace the configured lists until the next change.
+func (r *InstanceTypeRules) Sync(history InstanceTypeRuleHistory) error {
+	change, err := history.LatestChange()
+	if err != nil || change == nil {
+		return err
+	}
+
+	r.mu.RLock()
+	current := r.changeID
+	r.mu.RUnlock()
+	if change.ID == current {
+		return nil
+	}
+
+	if err := r.Set(change.Allow, change.Deny); err != nil {
+		return fmt.Errorf("instance type rule change %d: %w", change.ID, err)
+	}
+	r.mu.Lock()
+	r.changeID = change.ID
+	r.mu.Unlock()
+	log.Printf("Applied instance type rule change %d (allow %v, deny %v)", change.ID, change.Allow, change.Deny)
+	return nil
+}
+
+// Start syncs admin changes made on any replica every interval
+func (r *InstanceTypeRules) Start(ctx context.Context, history InstanceTypeRuleHistory, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			supervisor.Heartbeat(ctx)
+			if err := r.Sync(history); err != nil {
+				log.Printf("Failed to sync instance type rules: %v", err)
+			}
+		}
+	}
+}
+
+// Lists returns copies of the allow and deny lists
+func (r *InstanceTypeRules) Lists() (allow, deny []string) {
+	if r == nil {
+		return nil, nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]string{}, r.allow...), append([]string{}, r.deny...)
+}
+
+// Permits reports whether a job may run on an instance. A deny match on
+// either the org or the job list always wins; otherwise the instance must
+// match every allow list that is set.
+func (r *InstanceTypeRules) Permits(instance models.GPUInstance, requirements models.JobRequirements) bool {
+	allow, deny := r.Lists()
+	if matchesAny(deny, instance) || matchesAny(requirements.ExcludeInstanceTypes, instance) {
+		return false
+	}
+	if len(allow) > 0 && !matchesAny(allow, instance) {
+		return false
+	}
+	if len(requirements.InstanceTypes) > 0 && !matchesAny(requirements.InstanceTypes, instance) {
+		return false
+	}
+	return true
+}
+
+// PermitsAllocation reports whether the org and job instance type lists allow
+// an existing allocation, e.g. of a hibernated cluster being reused
+func (ao *AllocationOptimizer) PermitsAllocation(alloc models.Allocation, requirements models.JobRequirements) bool {
+	return ao.instanceTypes.Permits(models.GPUInstance{
+		Provider:     alloc.Provider,
+		Region:       alloc.Region,
+		InstanceType: alloc.InstanceType,
+		GPUType:      alloc.GPUType,
+	}, requirements)
+}
+
+// ValidateInstanceTypePatterns rejects empty or malformed patterns
+func ValidateInstanceTypePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if strings.TrimSpace(pattern) == "" {
+			return fmt.Errorf("instance type pattern must not be empty")
+		}
+		if _, err := path.Match(strings.ToLower(pattern), ""); err != nil {
+			return fmt.Errorf("invalid instance type pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// matchesAny reports whether any pattern matches the instance type or GPU type
+func matchesAny(patterns []string, instance models.GPUInstance) bool {
+	for _, pattern := range patterns {
+		if MatchInstanceType(pattern, instance.InstanceType) || MatchInstanceType(pattern, instance.GPUType) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchInstanceType reports whether a glob pattern matches a name, ignoring case
+func MatchInstanceType(pattern, name string) bool {
+	if name == "" {
+		return false
+	}
+	ok, err := path.Match(strings.ToLower(pattern), strings.ToLower(name))
+	return err == nil && ok
+}
@@ -0,0 +1,241 @@
+package optimizer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/supervisor"
+)
+
+// Interruption model parameters
+const (
+	// defaultSpotInterruptionRate is the static estimate, per instance-hour,
+	// for providers without a published one
+	defaultSpotInterruptionRate = 0.1
+	// InterruptionPriorHours is how many instance-hours of history the static
+	// estimate is worth in the blend
+	InterruptionPriorHours = 200.0
+	// interruptionMinHours is the history below which the static estimate is used as is
+	interruptionMinHours = 10.0
+	// InterruptionWindow is how far back interruptions and exposure count
+	InterruptionWindow = 90 * 24 * time.Hour
+	// timeOfDayBuckets splits the UTC day into equal buckets
+	timeOfDayBuckets = 4
+)
+
+// staticInterruptionRates are per-provider estimates before there is history
+var staticInterruptionRates = map[models.Provider]float64{
+	models.ProviderAWS:       0.05, // Spot advisor: most GPU types under 10% a month, reclaimed with 2 min notice
+	models.ProviderGCP:       0.1,  // Spot VMs
+	models.ProviderAzure:     0.1,  // Spot VMs
+	models.ProviderCoreWeave: 0.1,  // Preemptible instances
+}
+
+// StaticInterruptionRate returns the static interruption estimate of a provider
+func StaticInterruptionRate(provider models.Provider) float64 {
+	if rate, ok := staticInterruptionRates[provider]; ok {
+		return rate
+	}
+	return defaultSpotInterruptionRate
+}
+
+// BlendInterruptionRate blends an observed rate with a prior by the history
+// behind it: (interruptions + prior*P) / (hours + P), with P prior hours. The
+// confidence is the observed rate's weight, hours / (hours + P). Below
+// interruptionMinHours the prior is returned with no confidence.
+func BlendInterruptionRate(prior float64, interruptions int, hours float64) (rate, confidence float64) {
+	if hours < interruptionMinHours {
+		return prior, 0
+	}
+	rate = (float64(interruptions) + prior*InterruptionPriorHours) / (hours + InterruptionPriorHours)
+	return rate, hours / (hours + InterruptionPriorHours)
+}
+
+// InterruptionHistory lists what interruption rates are computed from
+type InterruptionHistory interface {
+	ListInterruptions(since time.Time) ([]models.SpotInterruption, error)
+	ListSpotExposure(since time.Time) ([]models.SpotExposure, error)
+}
+
+// interruptionStats counts interruptions and instance-hours of one instance
+// type in one region, per time-of-day bucket and in total
+type interruptionStats struct {
+	provider      models.Provider
+	instanceType  string
+	region        string
+	interruptions [timeOfDayBuckets + 1]int // Last entry is the whole day
+	hours         [timeOfDayBuckets + 1]float64
+}
+
+// InterruptionModel holds spot interruption rates observed over
+// InterruptionWindow. Safe for concurrent use.
+type InterruptionModel struct {
+	mu    sync.RWMutex
+	stats map[string]*interruptionStats
+}
+
+// NewInterruptionModel creates a model with no history; every rate is static
+func NewInterruptionModel() *InterruptionModel {
+	return &InterruptionModel{stats: make(map[string]*interruptionStats)}
+}
+
+// interruptionKey identifies an instance type in a region
+func interruptionKey(provider models.Provider, instanceType, region string) string {
+	return string(provider) + "/" + instanceType + "/" + region
+}
+
+// timeOfDayBucket returns the UTC bucket of an hour
+func timeOfDayBucket(t time.Time) int {
+	return t.UTC().Hour() * timeOfDayBuckets / 24
+}
+
+// bucketLabel names a bucket by its UTC hours, e.g. "06-12"
+func bucketLabel(bucket int) string {
+	width := 24 / timeOfDayBuckets
+	return fmt.Sprintf("%02d-%02d", bucket*width, (bucket+1)*width)
+}
+
+// Refresh recomputes the observed rates from the history of the last
+// InterruptionWindow
+func (m *InterruptionModel) Refresh(history InterruptionHistory) error {
+	since := time.Now().Add(-InterruptionWindow)
+	interruptions, err := history.ListInterruptions(since)
+	if err != nil {
+		return fmt.Errorf("failed to list spot interruptions: %w", err)
+	}
+	exposure, err := history.ListSpotExposure(since)
+	if err != nil {
+		return fmt.Errorf("failed to list spot exposure: %w", err)
+	}
+
+	stats := make(map[string]*interruptionStats)
+	get := func(provider models.Provider, instanceType, region string) *interruptionStats {
+		key := interruptionKey(provider, instanceType, region)
+		s, ok := stats[key]
+		if !ok {
+			s = &interruptionStats{provider: provider, instanceType: instanceType, region: region}
+			stats[key] = s
+		}
+		return s
+	}
+
+	for _, e := range exposure {
+		s := get(e.Provider, e.InstanceType, e.Region)
+		addExposure(s, e)
+	}
+	for _, i := range interruptions {
+		s := get(i.Provider, i.InstanceType, i.Region)
+		s.interruptions[timeOfDayBucket(i.InterruptedAt)]++
+		s.interruptions[timeOfDayBuckets]++
+	}
+
+	m.mu.Lock()
+	m.stats = stats
+	m.mu.Unlock()
+	return nil
+}
+
+// addExposure adds the instance-hours of a spot run to its time-of-day buckets
+func addExposure(s *interruptionStats, e models.SpotExposure) {
+	width := time.Duration(24/timeOfDayBuckets) * time.Hour
+	for start := e.Start.UTC(); start.Before(e.End); {
+		end := start.Truncate(width).Add(width)
+		if end.After(e.End) {
+			end = e.End
+		}
+		hours := end.Sub(start).Hours() * float64(e.Count)
+		s.hours[timeOfDayBucket(start)] += hours
+		s.hours[timeOfDayBuckets] += hours
+		start = end
+	}
+}
+
+// Start refreshes the model every interval
+func (m *InterruptionModel) Start(ctx context.Context, history InterruptionHistory, interval time.Duration) {
+	if err := m.Refresh(history); err != nil {
+		log.Printf("Failed to refresh interruption rates: %v", err)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			supervisor.Heartbeat(ctx)
+			if err := m.Refresh(history); err != nil {
+				log.Printf("Failed to refresh interruption rates: %v", err)
+			}
+		}
+	}
+}
+
+// Rate returns the interruption rate per instance-hour of an instance type in
+// a region at a time of day. The all-day rate is blended with the static
+// estimate; the time-of-day rate is blended with the all-day rate.
+func (m *InterruptionModel) Rate(provider models.Provider, instanceType, region string, at time.Time) float64 {
+	m.mu.RLock()
+	s, ok := m.stats[interruptionKey(provider, instanceType, region)]
+	m.mu.RUnlock()
+	if !ok {
+		return StaticInterruptionRate(provider)
+	}
+	allDay, _ := BlendInterruptionRate(StaticInterruptionRate(provider), s.interruptions[timeOfDayBuckets], s.hours[timeOfDayBuckets])
+	bucket := timeOfDayBucket(at)
+	rate, _ := BlendInterruptionRate(allDay, s.interruptions[bucket], s.hours[bucket])
+	return rate
+}
+
+// Rates returns the observed rates of every instance type with history: the
+// all-day rate, followed by its time-of-day buckets that have exposure
+func (m *InterruptionModel) Rates() []models.InterruptionRate {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys := make([]string, 0, len(m.stats))
+	for key := range m.stats {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var rates []models.InterruptionRate
+	for _, key := range keys {
+		s := m.stats[key]
+		static := StaticInterruptionRate(s.provider)
+		allDay := interruptionRate(s, timeOfDayBuckets, static)
+		rates = append(rates, allDay)
+		for bucket := 0; bucket < timeOfDayBuckets; bucket++ {
+			if s.hours[bucket] > 0 {
+				rates = append(rates, interruptionRate(s, bucket, allDay.Rate))
+			}
+		}
+	}
+	return rates
+}
+
+// interruptionRate describes one bucket of stats blended with a prior
+func interruptionRate(s *interruptionStats, bucket int, prior float64) models.InterruptionRate {
+	rate := models.InterruptionRate{
+		Provider:      s.provider,
+		InstanceType:  s.instanceType,
+		Region:        s.region,
+		Interruptions: s.interruptions[bucket],
+		InstanceHours: math.Round(s.hours[bucket]*100) / 100,
+		StaticRate:    prior,
+	}
+	if bucket < timeOfDayBuckets {
+		rate.TimeOfDay = bucketLabel(bucket)
+	}
+	if s.hours[bucket] > 0 {
+		rate.ObservedRate = float64(s.interruptions[bucket]) / s.hours[bucket]
+	}
+	rate.Rate, rate.Confidence = BlendInterruptionRate(prior, s.interruptions[bucket], s.hours[bucket])
+	return rate
+}
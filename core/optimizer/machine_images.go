@@ -0,0 +1,48 @@
+package optimizer
+
+import (
+	"log"
+
+	"gpu-orchestrator/core/models"
+)
+
+// ImageCatalog looks up the registrations of a machine image
+type ImageCatalog interface {
+	ImagesByImageID(imageID string) ([]models.MachineImage, error)
+}
+
+// SetImageCatalog sets the machine image registry jobs that pin an image
+// (resources.image) are placed by. Without one pinned jobs cannot be placed.
+func (ao *AllocationOptimizer) SetImageCatalog(catalog ImageCatalog) {
+	ao.images = catalog
+}
+
+// imageScopes returns the "provider/region/family" scopes a job's pinned
+// image is validated for, or nil when the job pins none. A pinned image
+// without usable registrations yields an empty set, so nothing is placed.
+func (ao *AllocationOptimizer) imageScopes(requirements models.JobRequirements) map[string]bool {
+	if requirements.Image == "" {
+		return nil
+	}
+	scopes := make(map[string]bool)
+	if ao.images == nil {
+		return scopes
+	}
+	images, err := ao.images.ImagesByImageID(requirements.Image)
+	if err != nil {
+		log.Printf("Failed to look up pinned image %s: %v", requirements.Image, err)
+		return scopes
+	}
+	for _, image := range images {
+		if image.Usable(true) {
+			scopes[limitKey(image.Provider, image.Region, image.InstanceFamily)] = true
+		}
+	}
+	return scopes
+}
+
+// imagePermits reports whether an instance can boot the pinned image of the
+// scopes imageScopes returned
+func imagePermits(scopes map[string]bool, instance models.GPUInstance) bool {
+	return scopes == nil || scopes[limitKey(instance.Provider, instance.Region, models.InstanceFamily(instance.InstanceType))]
+}
@@ -3,7 +3,6 @@ package optimizer
 import (
 	"fmt"
 	"sort"
-	"strings"
 
 	"gpu-orchestrator/core/models"
 )
@@ -20,109 +19,157 @@ func (ao *AllocationOptimizer) cheapestMultiProviderStrategy(
 	return ao.cheapestStrategy(candidates, requirements, constraints)
 }
 
-// geoDistributedTaskStrategy distributes tasks geographically for parallel execution
-// Phase 2: Full implementation
+// geoDistributedTaskStrategy distributes tasks geographically for parallel
+// execution, e.g. HPO sweeps and batch inference. Regions are ranked by the
+// per-GPU price of their cheapest instance, then availability; tasks go to
+// as many of the best regions as execution.spread allows, at least
+// min_tasks_per_region each, using fewer regions when there are too few
+// tasks or regions to fill them.
 func (ao *AllocationOptimizer) geoDistributedTaskStrategy(
 	candidates []models.GPUInstance,
 	requirements models.JobRequirements,
 	constraints models.JobConstraints,
 ) Strategy {
-	// Phase 2: Distribute tasks across regions for parallel execution
-	// This is useful for HPO sweeps, batch inference, etc.
-
-	// Group candidates by region
-	regionGroups := make(map[string][]models.GPUInstance)
-	for _, instance := range candidates {
-		key := fmt.Sprintf("%s:%s", instance.Provider, instance.Region)
-		regionGroups[key] = append(regionGroups[key], instance)
-	}
-
-	// For multi-task, we can distribute across regions
-	// Calculate how many tasks we can run in parallel
-	// For now, distribute evenly across available regions
-
-	var allocations []models.Allocation
-	gpusPerTask := 1 // Assume each task needs 1 GPU (can be configured)
+	gpusPerTask := max(requirements.GPUsPerTask, 1) // Set by task groups; one GPU otherwise
 	totalTasks := requirements.GPUs / gpusPerTask
-
 	if totalTasks == 0 {
 		totalTasks = 1
 	}
 
-	// Distribute tasks across regions (round-robin)
-	regions := make([]string, 0, len(regionGroups))
-	for key := range regionGroups {
-		regions = append(regions, key)
+	// Cheapest instance of each region
+	best := make(map[string]models.GPUInstance)
+	for _, instance := range candidates {
+		key := fmt.Sprintf("%s:%s", instance.Provider, instance.Region)
+		if current, ok := best[key]; !ok || effectivePrice(instance, constraints) < effectivePrice(current, constraints) {
+			best[key] = instance
+		}
 	}
-
-	if len(regions) == 0 {
-		return Strategy{}
+	regions := make([]models.GPUInstance, 0, len(best))
+	for _, instance := range best {
+		regions = append(regions, instance)
+	}
+	sort.Slice(regions, func(i, j int) bool {
+		pi := effectivePrice(regions[i], constraints) / float64(max(regions[i].GPUsPerInstance, 1))
+		pj := effectivePrice(regions[j], constraints) / float64(max(regions[j].GPUsPerInstance, 1))
+		if pi != pj {
+			return pi < pj
+		}
+		if regions[i].Availability != regions[j].Availability {
+			return regions[i].Availability > regions[j].Availability
+		}
+		if regions[i].Provider != regions[j].Provider {
+			return regions[i].Provider < regions[j].Provider
+		}
+		return regions[i].Region < regions[j].Region
+	})
+
+	// Tasks each region fits within its node limit
+	capacities := make([]int, len(regions))
+	for i, instance := range regions {
+		nodes := ao.getMaxNodesForProvider(instance.Provider, instance.Region, instance.InstanceType)
+		capacities[i] = nodes * (instance.GPUsPerInstance / gpusPerTask) // A task never spans instances
 	}
 
-	tasksPerRegion := totalTasks / len(regions)
-	if tasksPerRegion == 0 {
-		tasksPerRegion = 1
+	var spread models.RegionSpread
+	if requirements.RegionSpread != nil {
+		spread = *requirements.RegionSpread
+	}
+	tasks := splitTasks(totalTasks, capacities, spread.MaxRegions, spread.MinTasksPerRegion)
+	if tasks == nil {
+		return Strategy{}
 	}
 
-	for i, regionKey := range regions {
-		instances := regionGroups[regionKey]
-		if len(instances) == 0 {
+	var strategy Strategy
+	for i, count := range tasks {
+		if count == 0 {
 			continue
 		}
-
-		// Get cheapest instance in this region
-		sort.Slice(instances, func(i, j int) bool {
-			priceI := instances[i].PricePerHour
-			if instances[i].SpotPrice > 0 && constraints.AllowSpot {
-				priceI = instances[i].SpotPrice
-			}
-			priceJ := instances[j].PricePerHour
-			if instances[j].SpotPrice > 0 && constraints.AllowSpot {
-				priceJ = instances[j].SpotPrice
-			}
-			return priceI < priceJ
+		instance := regions[i]
+		tasksPerInstance := instance.GPUsPerInstance / gpusPerTask
+		instancesNeeded := (count + tasksPerInstance - 1) / tasksPerInstance
+		strategy.Allocation = append(strategy.Allocation, buildAllocations(instance, instancesNeeded, requirements, constraints)...)
+		strategy.TaskSpread = append(strategy.TaskSpread, models.RegionTasks{
+			Provider: instance.Provider,
+			Region:   instance.Region,
+			Tasks:    count,
 		})
+	}
+	return strategy
+}
 
-		bestInstance := instances[0]
-		// Parse region key (format: "provider:region")
-		parts := strings.Split(regionKey, ":")
-		var provider models.Provider
-		var region string
-		if len(parts) == 2 {
-			provider = models.Provider(parts[0])
-			region = parts[1]
-		} else {
-			provider = models.ProviderAWS
-			region = "us-east-1"
-		}
+// effectivePrice is the hourly price of an instance, spot when allowed and offered
+func effectivePrice(instance models.GPUInstance, constraints models.JobConstraints) float64 {
+	if instance.SpotPrice > 0 && constraints.AllowSpot {
+		return instance.SpotPrice
+	}
+	return instance.PricePerHour
+}
 
-		// Calculate how many instances needed for tasks in this region
-		remainingTasks := tasksPerRegion
-		if i == len(regions)-1 {
-			// Last region gets remaining tasks
-			remainingTasks = totalTasks - (tasksPerRegion * (len(regions) - 1))
-		}
+// splitTasks divides total tasks over regions in preference order, given
+// each region's capacity in tasks. At most maxRegions regions (0 = any) are
+// used, each with at least minPerRegion tasks (0 = 1); regions that cannot
+// hold minPerRegion are skipped, and fewer regions are used when there are
+// too few tasks to give each minPerRegion. Tasks are spread as evenly as
+// capacities allow, extra tasks going to preferred regions first. The
+// counts, aligned with capacities, sum to total; nil means the tasks do not
+// fit.
+func splitTasks(total int, capacities []int, maxRegions, minPerRegion int) []int {
+	if total <= 0 {
+		return nil
+	}
+	if minPerRegion <= 0 {
+		minPerRegion = 1
+	}
 
-		instancesNeeded := (remainingTasks*gpusPerTask + bestInstance.GPUsPerInstance - 1) / bestInstance.GPUsPerInstance
+	// Usable regions in preference order
+	var usable []int
+	for i, capacity := range capacities {
+		if maxRegions > 0 && len(usable) == maxRegions {
+			break
+		}
+		if capacity >= minPerRegion {
+			usable = append(usable, i)
+		}
+	}
 
-		useSpot := constraints.AllowSpot && bestInstance.SpotPrice > 0
-		price := bestInstance.PricePerHour
-		if useSpot {
-			price = bestInstance.SpotPrice
+	// As many regions as minPerRegion allows
+	n := min(len(usable), total/minPerRegion)
+	if n == 0 {
+		return nil
+	}
+	chosen := usable[:n]
+	fill := func(level int) int {
+		sum := 0
+		for _, i := range chosen {
+			sum += min(capacities[i], level)
 		}
+		return sum
+	}
+	if fill(total) < total {
+		return nil
+	}
 
-		allocations = append(allocations, models.Allocation{
-			Provider:      provider,
-			InstanceType:  bestInstance.InstanceType,
-			Region:        region,
-			Count:         instancesNeeded,
-			Spot:          useSpot,
-			PricePerHour:  price,
-			EstimatedCost: price * float64(instancesNeeded) * requirements.EstimatedHours,
-		})
+	// Raise every chosen region to the highest common level its capacity
+	// allows without exceeding total, then hand out the rounding remainder,
+	// one task each, in preference order
+	level := 0
+	for fill(level+1) <= total && level < total {
+		level++
 	}
 
-	return Strategy{Allocation: allocations}
+	counts := make([]int, len(capacities))
+	remaining := total
+	for _, i := range chosen {
+		counts[i] = min(capacities[i], level)
+		remaining -= counts[i]
+	}
+	for _, i := range chosen {
+		if remaining > 0 && counts[i] < capacities[i] {
+			counts[i]++
+			remaining--
+		}
+	}
+	return counts
 }
 
 // hybridTaskStrategy uses on-prem first, cloud as backup
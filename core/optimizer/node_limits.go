@@ -0,0 +1,178 @@
+package optimizer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/providers"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Sources of a node limit
+const (
+	LimitSourceDefault = "default" // Built-in provider default
+	LimitSourceConfig  = "config"  // NODE_LIMITS_FILE
+	LimitSourceQuota   = "quota"   // Reported by the provider's quota API
+	LimitSourceAdmin   = "admin"   // PATCH /v1/limits
+)
+
+// defaultFallbackNodes applies to providers without a configured default
+const defaultFallbackNodes = 8
+
+// NodeLimit caps the number of nodes in one cluster. Region and InstanceFamily
+// are optional; the most specific matching limit wins.
+type NodeLimit struct {
+	Provider       models.Provider `yaml:"provider" json:"provider"`
+	Region         string          `yaml:"region,omitempty" json:"region,omitempty"`
+	InstanceFamily string          `yaml:"instance_family,omitempty" json:"instance_family,omitempty"` // e.g. "p4d", "a2"
+	MaxNodes       int             `yaml:"max_nodes" json:"max_nodes"`
+	Source         string          `yaml:"-" json:"source"`
+}
+
+// NodeLimits holds max nodes per cluster/AZ by provider, region and instance family.
+// Safe for concurrent use; updates apply to subsequent optimizations.
+type NodeLimits struct {
+	limits map[string]NodeLimit
+	mu     sync.RWMutex
+}
+
+// NewNodeLimits creates node limits seeded with conservative provider defaults
+func NewNodeLimits() *NodeLimits {
+	nl := &NodeLimits{limits: make(map[string]NodeLimit)}
+	for provider, maxNodes := range map[models.Provider]int{
+		models.ProviderAWS:       16,  // Conservative limit per AZ
+		models.ProviderGCP:       32,  // Per region
+		models.ProviderAzure:     16,  // Per availability set
+		models.ProviderOnPrem:    100, // K8s cluster can be large
+		models.ProviderCoreWeave: 32,  // Per region (Kubernetes-native, InfiniBand fabric)
+	} {
+		nl.limits[limitKey(provider, "", "")] = NodeLimit{Provider: provider, MaxNodes: maxNodes, Source: LimitSourceDefault}
+	}
+	return nl
+}
+
+// LoadNodeLimits reads limit overrides from a YAML file with a top-level "limits" list
+func (nl *NodeLimits) LoadNodeLimits(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read node limits: %w", err)
+	}
+
+	var file struct {
+		Limits []NodeLimit `yaml:"limits"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse node limits: %w", err)
+	}
+
+	for _, limit := range file.Limits {
+		limit.Source = LimitSourceConfig
+		if err := nl.Set(limit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RefreshFromQuotas applies limits reported by providers that expose a quota API.
+// Configured and admin limits take precedence over reported quotas.
+func (nl *NodeLimits) RefreshFromQuotas(ctx context.Context, registry providers.Registry) {
+	ctx = providers.NonCritical(ctx)
+	for _, name := range registry.Names() {
+		reporter, ok := registry[name].(providers.QuotaReporter)
+		if !ok {
+			continue
+		}
+		quotas, err := reporter.NodeQuotas(ctx)
+		if err != nil {
+			log.Printf("Failed to fetch node quotas for %s: %v", name, err)
+			continue
+		}
+		for _, quota := range quotas {
+			limit := NodeLimit{
+				Provider:       name,
+				Region:         quota.Region,
+				InstanceFamily: quota.InstanceFamily,
+				MaxNodes:       quota.MaxNodes,
+				Source:         LimitSourceQuota,
+			}
+			if existing, ok := nl.lookupExact(limit); ok && existing.Source != LimitSourceDefault && existing.Source != LimitSourceQuota {
+				continue
+			}
+			if err := nl.Set(limit); err != nil {
+				log.Printf("Ignoring node quota for %s: %v", name, err)
+			}
+		}
+	}
+}
+
+// Set adds or replaces a limit
+func (nl *NodeLimits) Set(limit NodeLimit) error {
+	if limit.Provider == "" {
+		return fmt.Errorf("node limit requires a provider")
+	}
+	if limit.MaxNodes < 1 {
+		return fmt.Errorf("node limit for %s must be at least 1, got %d", limit.Provider, limit.MaxNodes)
+	}
+
+	nl.mu.Lock()
+	defer nl.mu.Unlock()
+
+	nl.limits[limitKey(limit.Provider, limit.Region, limit.InstanceFamily)] = limit
+	return nil
+}
+
+// MaxNodes returns the node limit for an instance type in a provider+region
+func (nl *NodeLimits) MaxNodes(provider models.Provider, region, instanceType string) int {
+	nl.mu.RLock()
+	defer nl.mu.RUnlock()
+
+	family := models.InstanceFamily(instanceType)
+	for _, key := range []string{
+		limitKey(provider, region, family),
+		limitKey(provider, "", family),
+		limitKey(provider, region, ""),
+		limitKey(provider, "", ""),
+	} {
+		if limit, ok := nl.limits[key]; ok {
+			return limit.MaxNodes
+		}
+	}
+	return defaultFallbackNodes
+}
+
+// List returns all limits sorted by provider, region and family
+func (nl *NodeLimits) List() []NodeLimit {
+	nl.mu.RLock()
+	defer nl.mu.RUnlock()
+
+	result := make([]NodeLimit, 0, len(nl.limits))
+	for _, limit := range nl.limits {
+		result = append(result, limit)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return limitKey(result[i].Provider, result[i].Region, result[i].InstanceFamily) <
+			limitKey(result[j].Provider, result[j].Region, result[j].InstanceFamily)
+	})
+	return result
+}
+
+// lookupExact returns the limit stored for exactly the same scope
+func (nl *NodeLimits) lookupExact(limit NodeLimit) (NodeLimit, bool) {
+	nl.mu.RLock()
+	defer nl.mu.RUnlock()
+
+	existing, ok := nl.limits[limitKey(limit.Provider, limit.Region, limit.InstanceFamily)]
+	return existing, ok
+}
+
+// limitKey builds the map key for a limit scope
+func limitKey(provider models.Provider, region, family string) string {
+	return fmt.Sprintf("%s/%s/%s", provider, region, family)
+}
@@ -1,81 +1,283 @@
 package optimizer
 
 import (
+	_ "embed"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
 	"gpu-orchestrator/core/models"
+
+	"gopkg.in/yaml.v3"
 )
 
-// PerformanceMetricsStore provides performance benchmarks for different GPU/framework combinations
-// Phase 1: Static benchmarks (MVP)
-// Phase 2: Historical telemetry
-// Phase 3: Per-customer profiles
+//go:embed benchmarks.yaml
+var benchmarksYAML []byte
+
+// Learned benchmark parameters
+const (
+	// BenchmarkMinSamples is how many jobs must have been observed before a
+	// learned benchmark replaces the static one
+	BenchmarkMinSamples = 3
+	// BenchmarkWindow is the most samples a learned mean weighs equally
+	BenchmarkWindow = 50
+)
+
+// StaticBenchmark is a shipped baseline for one framework, GPU type and model class
+type StaticBenchmark struct {
+	Framework         string  `yaml:"framework"`
+	GPUType           string  `yaml:"gpu_type"`
+	ModelClass        string  `yaml:"model_class"`
+	StepsPerHour      float64 `yaml:"steps_per_hour"`
+	TokensPerHour     float64 `yaml:"tokens_per_hour,omitempty"`
+	StorageThroughput float64 `yaml:"storage_throughput,omitempty"`
+	NetworkBandwidth  float64 `yaml:"network_bandwidth,omitempty"`
+}
+
+// benchmarkTable is the file format of the embedded baselines and of
+// PERFORMANCE_BENCHMARKS_FILE
+type benchmarkTable struct {
+	Version    int               `yaml:"version"`
+	Benchmarks []StaticBenchmark `yaml:"benchmarks"`
+}
+
+// BenchmarkHistory persists learned benchmarks. RecordBenchmarkSample folds a
+// sample into the stored means atomically, so replicas do not overwrite each
+// other, and returns the result.
+type BenchmarkHistory interface {
+	ListBenchmarks() ([]models.LearnedBenchmark, error)
+	RecordBenchmarkSample(sample models.BenchmarkSample, window int) (*models.LearnedBenchmark, error)
+}
+
+// PerformanceMetricsStore provides performance benchmarks for different
+// GPU/framework combinations: versioned static baselines, replaced by
+// throughput learned from jobs once enough have run. Safe for concurrent use.
 type PerformanceMetricsStore struct {
-	benchmarks map[string]models.PerformanceMetrics
+	mu            sync.RWMutex
+	static        map[string]models.PerformanceMetrics
+	staticVersion int
+	learned       map[string]models.LearnedBenchmark
+	history       BenchmarkHistory // Optional; learned benchmarks are written through to it
 }
 
-// NewPerformanceMetricsStore creates a new performance metrics store
+// NewPerformanceMetricsStore creates a store with the embedded baselines and
+// nothing learned
 func NewPerformanceMetricsStore() *PerformanceMetricsStore {
+	table, err := parseBenchmarkTable(benchmarksYAML)
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded benchmarks: %v", err))
+	}
 	store := &PerformanceMetricsStore{
-		benchmarks: make(map[string]models.PerformanceMetrics),
+		static:        make(map[string]models.PerformanceMetrics),
+		staticVersion: table.Version,
+		learned:       make(map[string]models.LearnedBenchmark),
 	}
+	store.applyStatic(table)
+	return store
+}
 
-	// Initialize with static benchmarks (MVP)
-	store.initializeBenchmarks()
+// benchmarkKey identifies a framework, GPU type and model class,
+// e.g. "pytorch:A100:resnet50"
+func benchmarkKey(framework, gpuType, modelClass string) string {
+	return framework + ":" + gpuType + ":" + modelClass
+}
 
-	return store
+// applyStatic sets the baselines of a table, replacing entries with the same key
+func (pms *PerformanceMetricsStore) applyStatic(table *benchmarkTable) {
+	for _, b := range table.Benchmarks {
+		pms.static[benchmarkKey(b.Framework, b.GPUType, b.ModelClass)] = models.PerformanceMetrics{
+			StepsPerHour:      b.StepsPerHour,
+			TokensPerHour:     b.TokensPerHour,
+			StorageThroughput: b.StorageThroughput,
+			NetworkBandwidth:  b.NetworkBandwidth,
+		}
+	}
 }
 
-// initializeBenchmarks loads static benchmark data
-func (pms *PerformanceMetricsStore) initializeBenchmarks() {
-	// Key format: "framework:gpu_type:model_class"
-	// Example: "pytorch:A100:resnet50"
+// LoadStaticBenchmarks reads baselines from a YAML file in the format of the
+// embedded ones. Its entries replace the embedded entries with the same key;
+// its version must be at least the embedded version.
+func (pms *PerformanceMetricsStore) LoadStaticBenchmarks(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read benchmarks: %w", err)
+	}
+	table, err := parseBenchmarkTable(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse benchmarks: %w", err)
+	}
 
-	// PyTorch + A100 benchmarks
-	pms.benchmarks["pytorch:A100:resnet50"] = models.PerformanceMetrics{
-		StepsPerHour:      1200.0,
-		StorageThroughput: 500.0, // MB/s
-		NetworkBandwidth:  100.0, // Gbps
+	pms.mu.Lock()
+	defer pms.mu.Unlock()
+	if table.Version < pms.staticVersion {
+		return fmt.Errorf("benchmarks version %d is older than the built-in version %d", table.Version, pms.staticVersion)
 	}
+	pms.applyStatic(table)
+	pms.staticVersion = table.Version
+	return nil
+}
 
-	pms.benchmarks["pytorch:A100:bert"] = models.PerformanceMetrics{
-		StepsPerHour:      800.0,
-		StorageThroughput: 400.0,
-		NetworkBandwidth:  100.0,
+// parseBenchmarkTable parses and validates a benchmark table
+func parseBenchmarkTable(data []byte) (*benchmarkTable, error) {
+	table := &benchmarkTable{}
+	if err := yaml.Unmarshal(data, table); err != nil {
+		return nil, err
+	}
+	if table.Version <= 0 {
+		return nil, fmt.Errorf("version must be positive")
+	}
+	for i, b := range table.Benchmarks {
+		if b.Framework == "" || b.GPUType == "" || b.ModelClass == "" {
+			return nil, fmt.Errorf("benchmark %d: framework, gpu_type and model_class are required", i+1)
+		}
+		if b.StepsPerHour <= 0 || b.TokensPerHour < 0 {
+			return nil, fmt.Errorf("benchmark %d: steps_per_hour must be positive and tokens_per_hour not negative", i+1)
+		}
+	}
+	return table, nil
+}
+
+// SetHistory loads the learned benchmarks from history and writes new
+// samples through to it
+func (pms *PerformanceMetricsStore) SetHistory(history BenchmarkHistory) error {
+	learned, err := history.ListBenchmarks()
+	if err != nil {
+		return fmt.Errorf("failed to load learned benchmarks: %w", err)
 	}
 
-	pms.benchmarks["pytorch:A100:llama"] = models.PerformanceMetrics{
-		StepsPerHour:    200.0,
-		TokensPerHour:   50000.0,
-		StorageThroughput: 300.0,
-		NetworkBandwidth: 100.0,
+	pms.mu.Lock()
+	defer pms.mu.Unlock()
+	pms.history = history
+	for _, b := range learned {
+		pms.learned[benchmarkKey(b.Framework, b.GPUType, b.ModelClass)] = b
 	}
+	return nil
+}
 
-	// PyTorch + V100 benchmarks
-	pms.benchmarks["pytorch:V100:resnet50"] = models.PerformanceMetrics{
-		StepsPerHour:      600.0,
-		StorageThroughput: 300.0,
-		NetworkBandwidth:  25.0,
+// Observe records the per-GPU throughput of one job
+func (pms *PerformanceMetricsStore) Observe(sample models.BenchmarkSample) error {
+	if sample.StepsPerHour <= 0 {
+		return nil
 	}
+	key := benchmarkKey(sample.Framework, sample.GPUType, sample.ModelClass)
 
-	// Horovod + A100 benchmarks
-	pms.benchmarks["horovod:A100:resnet50"] = models.PerformanceMetrics{
-		StepsPerHour:      1100.0,
-		StorageThroughput: 450.0,
-		NetworkBandwidth:  100.0,
+	// Held across the write so samples land in the cache in the order stored
+	pms.mu.Lock()
+	defer pms.mu.Unlock()
+	if pms.history != nil {
+		learned, err := pms.history.RecordBenchmarkSample(sample, BenchmarkWindow)
+		if err != nil {
+			return fmt.Errorf("failed to record benchmark sample: %w", err)
+		}
+		pms.learned[key] = *learned
+		return nil
 	}
+
+	learned, ok := pms.learned[key]
+	if !ok {
+		learned = models.LearnedBenchmark{Framework: sample.Framework, GPUType: sample.GPUType, ModelClass: sample.ModelClass}
+	}
+	learned.Add(sample, BenchmarkWindow, time.Now())
+	pms.learned[key] = learned
+	return nil
+}
+
+// lookup returns the effective metrics of a key
+func (pms *PerformanceMetricsStore) lookup(key string) (models.PerformanceMetrics, bool) {
+	pms.mu.RLock()
+	defer pms.mu.RUnlock()
+	return pms.effective(key)
+}
+
+// effective returns the static baseline of a key with its throughput replaced
+// by learned figures that have enough samples. Callers hold mu.
+func (pms *PerformanceMetricsStore) effective(key string) (models.PerformanceMetrics, bool) {
+	metrics, ok := pms.static[key]
+	if learned, found := pms.learned[key]; found && learned.Samples >= BenchmarkMinSamples {
+		if !ok {
+			metrics = defaultPerformanceMetrics
+		}
+		metrics.StepsPerHour = learned.StepsPerHour
+		if learned.TokenSamples >= BenchmarkMinSamples {
+			metrics.TokensPerHour = learned.TokensPerHour
+		}
+		ok = true
+	}
+	return metrics, ok
+}
+
+// defaultPerformanceMetrics are used for combinations without a benchmark
+var defaultPerformanceMetrics = models.PerformanceMetrics{
+	StepsPerHour:      500.0, // Conservative default
+	StorageThroughput: 200.0,
+	NetworkBandwidth:  10.0,
 }
 
 // GetPerformanceMetrics returns performance metrics for a framework+GPU combination
 func (pms *PerformanceMetricsStore) GetPerformanceMetrics(framework, gpuType, modelClass string) models.PerformanceMetrics {
-	key := framework + ":" + gpuType + ":" + modelClass
-	if metrics, ok := pms.benchmarks[key]; ok {
+	if metrics, ok := pms.lookup(benchmarkKey(framework, gpuType, modelClass)); ok {
 		return metrics
 	}
-	// Return default/unknown metrics
-	return models.PerformanceMetrics{
-		StepsPerHour:      500.0, // Conservative default
-		StorageThroughput: 200.0,
-		NetworkBandwidth:  10.0,
+	return defaultPerformanceMetrics
+}
+
+// TokenBenchmark returns the benchmark with a tokens-per-hour figure for one
+// GPU of the type, falling back to the PyTorch benchmark for frameworks
+// without their own. ok is false when the GPU type has none.
+func (pms *PerformanceMetricsStore) TokenBenchmark(framework, gpuType, modelClass string) (models.PerformanceMetrics, bool) {
+	for _, fw := range []string{framework, "pytorch"} {
+		if metrics, ok := pms.lookup(benchmarkKey(fw, gpuType, modelClass)); ok && metrics.TokensPerHour > 0 {
+			return metrics, true
+		}
+	}
+	return models.PerformanceMetrics{}, false
+}
+
+// Benchmarks returns the effective benchmark of every combination with a
+// static baseline or learned samples, sorted by key, and the static version
+func (pms *PerformanceMetricsStore) Benchmarks() ([]models.EffectiveBenchmark, int) {
+	pms.mu.RLock()
+	defer pms.mu.RUnlock()
+
+	keys := make([]string, 0, len(pms.static)+len(pms.learned))
+	for key := range pms.static {
+		keys = append(keys, key)
+	}
+	for key := range pms.learned {
+		if _, ok := pms.static[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	benchmarks := make([]models.EffectiveBenchmark, 0, len(keys))
+	for _, key := range keys {
+		parts := strings.SplitN(key, ":", 3)
+		metrics, _ := pms.effective(key)
+		benchmark := models.EffectiveBenchmark{
+			Framework:     parts[0],
+			GPUType:       parts[1],
+			ModelClass:    parts[2],
+			Source:        models.BenchmarkStatic,
+			StepsPerHour:  metrics.StepsPerHour,
+			TokensPerHour: metrics.TokensPerHour,
+		}
+		if static, ok := pms.static[key]; ok {
+			benchmark.StaticStepsPerHour = static.StepsPerHour
+			benchmark.StaticTokensPerHour = static.TokensPerHour
+		}
+		if learned, ok := pms.learned[key]; ok {
+			benchmark.Learned = &learned
+			if learned.Samples >= BenchmarkMinSamples {
+				benchmark.Source = models.BenchmarkLearned
+			}
+		}
+		benchmarks = append(benchmarks, benchmark)
 	}
+	return benchmarks, pms.staticVersion
 }
 
 // GetPerformanceMetricsForAllocation returns performance metrics for an allocation
@@ -89,9 +291,8 @@ func (pms *PerformanceMetricsStore) GetPerformanceMetricsForAllocation(
 		return models.PerformanceMetrics{}
 	}
 
-	// Extract GPU type from instance type (simplified for MVP)
-	// TODO: Phase 2 - Map instance types to GPU types properly
-	gpuType := "A100" // Default assumption
+	// Extract GPU type from instance type family
+	gpuType := GPUTypeForInstanceType(allocation[0].InstanceType)
 	modelClass := "resnet50" // Default assumption
 
 	return pms.GetPerformanceMetrics(framework, gpuType, modelClass)
@@ -104,7 +305,11 @@ func (pms *PerformanceMetricsStore) GetBaselineCostPerStep(framework, gpuType st
 	baselines := map[string]float64{
 		"pytorch:A100": 0.001,
 		"pytorch:V100": 0.002,
+		"pytorch:H100": 0.0008,
+		"pytorch:L4":   0.0015,
+		"pytorch:L40S": 0.0012,
 		"horovod:A100": 0.001,
+		"horovod:H100": 0.0008,
 	}
 	key := framework + ":" + gpuType
 	if baseline, ok := baselines[key]; ok {
@@ -120,7 +325,11 @@ func (pms *PerformanceMetricsStore) GetBaselineStepsPerHour(framework, gpuType s
 	baselines := map[string]float64{
 		"pytorch:A100": 1000.0,
 		"pytorch:V100": 500.0,
+		"pytorch:H100": 2200.0,
+		"pytorch:L4":   400.0,
+		"pytorch:L40S": 850.0,
 		"horovod:A100": 900.0,
+		"horovod:H100": 2000.0,
 	}
 	key := framework + ":" + gpuType
 	if baseline, ok := baselines[key]; ok {
@@ -128,3 +337,50 @@ func (pms *PerformanceMetricsStore) GetBaselineStepsPerHour(framework, gpuType s
 	}
 	return 500.0 // Conservative default
 }
+
+// instanceFamilyGPUTypes maps instance type prefixes to GPU types.
+// Longer prefixes must come first (e.g. "g6e." before "g6.").
+var instanceFamilyGPUTypes = []struct {
+	prefix  string
+	gpuType string
+}{
+	// AWS
+	{"p5.", "H100"},
+	{"p4d.", "A100"},
+	{"p4de.", "A100"},
+	{"p3.", "V100"},
+	{"g6e.", "L40S"},
+	{"g6.", "L4"},
+	{"g5.", "A10G"},
+	{"g4dn.", "T4"},
+	// GCP
+	{"a3-", "H100"},
+	{"a2-", "A100"},
+	{"g2-", "L4"},
+	// CoreWeave
+	{"gd-8xh100", "H100"},
+	{"gd-8xa100", "A100"},
+	{"gd-1xa100", "A100"},
+}
+
+// GPUTypeForInstanceType derives the GPU type from a provider instance type name
+// Falls back to A100 when the family is unknown
+func GPUTypeForInstanceType(instanceType string) string {
+	for _, family := range instanceFamilyGPUTypes {
+		if strings.HasPrefix(instanceType, family.prefix) {
+			return family.gpuType
+		}
+	}
+
+	// Azure encodes the GPU in the size name (e.g. Standard_ND96isr_H100_v5)
+	for _, gpuType := range []string{"H100", "A100", "L40S", "L4", "T4", "V100"} {
+		if strings.Contains(instanceType, "_"+gpuType) {
+			return gpuType
+		}
+	}
+	if strings.HasPrefix(instanceType, "Standard_NC") && strings.HasSuffix(instanceType, "_v3") {
+		return "V100"
+	}
+
+	return "A100" // Default assumption
+}
@@ -0,0 +1,172 @@
+package optimizer
+
+import (
+	_ "embed"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"gpu-orchestrator/core/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed price_bands.yaml
+var priceBandsYAML []byte
+
+// acceptedPriceTolerance is how far a refreshed price may be from an
+// admin-accepted price and still count as the accepted price
+const acceptedPriceTolerance = 0.10
+
+// PriceBand is the plausible USD per GPU-hour range of a GPU type
+type PriceBand struct {
+	GPUType string  `yaml:"gpu_type"`
+	Min     float64 `yaml:"min"`
+	Max     float64 `yaml:"max"`
+}
+
+// defaultPriceBands holds the embedded bands by upper-case GPU type
+var defaultPriceBands map[string]PriceBand
+
+func init() {
+	var file struct {
+		Bands []PriceBand `yaml:"bands"`
+	}
+	if err := yaml.Unmarshal(priceBandsYAML, &file); err != nil {
+		panic(fmt.Sprintf("invalid embedded price bands: %v", err))
+	}
+	defaultPriceBands = make(map[string]PriceBand, len(file.Bands))
+	for _, band := range file.Bands {
+		if band.GPUType == "" || band.Min <= 0 || band.Max <= band.Min {
+			panic(fmt.Sprintf("invalid embedded price band %+v", band))
+		}
+		defaultPriceBands[strings.ToUpper(band.GPUType)] = band
+	}
+}
+
+// PriceQuarantine stores quarantined prices; repository.PriceQuarantineRepository
+// satisfies it
+type PriceQuarantine interface {
+	Lookup(provider models.Provider, region, instanceType, kind string) (*models.PriceAnomaly, error)
+	Quarantine(anomaly *models.PriceAnomaly) error
+	Clear(provider models.Provider, region, instanceType, kind string) error
+}
+
+// PriceGuard checks refreshed prices before they reach the pricing cache. A
+// price more than maxDeviation times above or below the stored price, or
+// outside its GPU type's band, is quarantined and the last known good price
+// keeps being served.
+type PriceGuard struct {
+	maxDeviation float64
+	bands        map[string]PriceBand
+	quarantine   PriceQuarantine
+
+	mu        sync.Mutex
+	anomalies map[anomalyCounterKey]int64
+}
+
+// anomalyCounterKey labels the anomaly counter
+type anomalyCounterKey struct {
+	provider models.Provider
+	kind     string
+	reason   string
+}
+
+// NewPriceGuard creates a price guard with the embedded bands
+func NewPriceGuard(maxDeviation float64, quarantine PriceQuarantine) *PriceGuard {
+	return &PriceGuard{
+		maxDeviation: maxDeviation,
+		bands:        defaultPriceBands,
+		quarantine:   quarantine,
+		anomalies:    make(map[anomalyCounterKey]int64),
+	}
+}
+
+// Admit reports whether a refreshed price may be stored. previous is the
+// stored price (0 if none). Rejected prices are quarantined.
+func (g *PriceGuard) Admit(instance models.GPUInstance, kind string, price, previous float64) bool {
+	state, err := g.quarantine.Lookup(instance.Provider, instance.Region, instance.InstanceType, kind)
+	if err != nil {
+		log.Printf("Failed to look up price quarantine for %s %s/%s: %v", instance.Provider, instance.Region, instance.InstanceType, err)
+	}
+	if state != nil && state.Status == models.AnomalyAccepted && withinTolerance(price, state.Price, acceptedPriceTolerance) {
+		return true
+	}
+
+	reason := g.Check(instance, price, previous)
+	if reason == "" {
+		if state != nil && state.Status == models.AnomalyQuarantined {
+			if err := g.quarantine.Clear(instance.Provider, instance.Region, instance.InstanceType, kind); err != nil {
+				log.Printf("Failed to clear price quarantine for %s %s/%s: %v", instance.Provider, instance.Region, instance.InstanceType, err)
+			}
+		}
+		return true
+	}
+
+	g.mu.Lock()
+	g.anomalies[anomalyCounterKey{instance.Provider, kind, reason}]++
+	g.mu.Unlock()
+
+	log.Printf("Quarantined %s %s price of %s %s/%s: $%.4f/hr (stored $%.4f/hr, %s)",
+		kind, instance.GPUType, instance.Provider, instance.Region, instance.InstanceType, price, previous, reason)
+	anomaly := &models.PriceAnomaly{
+		Provider:      instance.Provider,
+		Region:        instance.Region,
+		InstanceType:  instance.InstanceType,
+		GPUType:       instance.GPUType,
+		Kind:          kind,
+		Price:         price,
+		PreviousPrice: previous,
+		Reason:        reason,
+	}
+	if err := g.quarantine.Quarantine(anomaly); err != nil {
+		log.Printf("Failed to record quarantined price for %s %s/%s: %v", instance.Provider, instance.Region, instance.InstanceType, err)
+	}
+	return false
+}
+
+// Check returns why a price is anomalous, or "" if it looks sane
+func (g *PriceGuard) Check(instance models.GPUInstance, price, previous float64) string {
+	if previous > 0 && g.maxDeviation > 1 && (price > previous*g.maxDeviation || price < previous/g.maxDeviation) {
+		return models.AnomalyDeviation
+	}
+	band, ok := g.bands[strings.ToUpper(instance.GPUType)]
+	if ok && instance.GPUsPerInstance > 0 {
+		perGPU := price / float64(instance.GPUsPerInstance)
+		if perGPU < band.Min || perGPU > band.Max {
+			return models.AnomalyOutOfBand
+		}
+	}
+	return ""
+}
+
+// PrometheusMetrics exports the anomaly counters
+func (g *PriceGuard) PrometheusMetrics() string {
+	g.mu.Lock()
+	counts := make(map[anomalyCounterKey]int64, len(g.anomalies))
+	keys := make([]anomalyCounterKey, 0, len(g.anomalies))
+	for key, count := range g.anomalies {
+		counts[key] = count
+		keys = append(keys, key)
+	}
+	g.mu.Unlock()
+
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+	})
+
+	metrics := "# HELP gpu_price_anomalies_total Refreshed prices quarantined by the sanity checks\n"
+	metrics += "# TYPE gpu_price_anomalies_total counter\n"
+	for _, key := range keys {
+		metrics += fmt.Sprintf("gpu_price_anomalies_total{provider=\"%s\",kind=\"%s\",reason=\"%s\"} %d\n", key.provider, key.kind, key.reason, counts[key])
+	}
+	return metrics
+}
+
+// withinTolerance reports whether a is within a relative tolerance of b
+func withinTolerance(a, b, tolerance float64) bool {
+	return b > 0 && math.Abs(a-b) <= b*tolerance
+}
@@ -0,0 +1,143 @@
+package optimizer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gpu-orchestrator/core/models"
+)
+
+// PriceRecheckPolicy controls the pre-provisioning price re-check
+type PriceRecheckPolicy struct {
+	MaxDrift float64       // Hourly cost increase that triggers re-optimization (0.10 = 10%); 0 disables the re-check
+	FreshFor time.Duration // Allocations priced more recently than this are provisioned without a re-check
+}
+
+// PriceRecheck is the outcome of re-pricing an allocation before provisioning
+type PriceRecheck struct {
+	Checked        bool
+	SkipReason     string
+	OriginalHourly float64
+	CurrentHourly  float64
+	Drift          float64 // (current - original) / original hourly cost
+	ProjectedCost  float64 // Estimated cost of Allocations at current prices
+	Allocations    []models.Allocation
+	Swapped        bool                       // Allocations were replaced by a re-optimized allocation
+	Decision       *models.AllocationDecision // Set when re-optimization ran
+	OverBudget     bool                       // No allocation fits the budget at current prices
+}
+
+// RecheckPrices re-prices an allocation chosen at pricedAt against the
+// pricing cache (never provider APIs). If its hourly cost drifted beyond
+// policy.MaxDrift or it no longer fits the budget, optimization is re-run and
+// the cheaper of the two allocations is returned. Allocations are returned
+// unchanged when the check is skipped.
+func (ao *AllocationOptimizer) RecheckPrices(
+	ctx context.Context,
+	requirements models.JobRequirements,
+	constraints models.JobConstraints,
+	allocations []models.Allocation,
+	pricedAt time.Time,
+	policy PriceRecheckPolicy,
+) *PriceRecheck {
+	result := &PriceRecheck{Allocations: allocations}
+	switch {
+	case policy.MaxDrift <= 0:
+		result.SkipReason = "price re-check disabled"
+		return result
+	case !pricedAt.IsZero() && time.Since(pricedAt) < policy.FreshFor:
+		result.SkipReason = "prices are fresh"
+		return result
+	case ao.instances == nil:
+		result.SkipReason = "pricing fetcher not configured"
+		return result
+	}
+
+	allInstances, err := ao.instances.GetAllInstances(ctx)
+	if err != nil {
+		result.SkipReason = fmt.Sprintf("pricing cache unavailable: %v", err)
+		return result
+	}
+
+	repriced := repriceAllocations(allocations, allInstances, constraints)
+	result.Checked = true
+	result.Allocations = repriced
+	result.OriginalHourly = hourlyCost(allocations)
+	result.CurrentHourly = hourlyCost(repriced)
+	result.ProjectedCost = estimatedCost(repriced)
+	if result.OriginalHourly > 0 {
+		result.Drift = (result.CurrentHourly - result.OriginalHourly) / result.OriginalHourly
+	}
+
+	overBudget := constraints.MaxBudget > 0 && result.ProjectedCost > constraints.MaxBudget
+	if result.Drift <= policy.MaxDrift && !overBudget {
+		return result
+	}
+
+	// Prices moved: look for a better allocation at current prices
+	replacement, decision, err := ao.OptimizeWithDecision(ctx, requirements, constraints)
+	result.Decision = decision
+	result.OverBudget = overBudget
+	if err != nil || len(replacement) == 0 {
+		return result
+	}
+
+	replacementCost := estimatedCost(replacement)
+	if replacementCost >= result.ProjectedCost {
+		return result
+	}
+	if constraints.MaxBudget > 0 && replacementCost > constraints.MaxBudget {
+		// Cheaper, but still over budget
+		return result
+	}
+
+	result.Allocations = replacement
+	result.ProjectedCost = replacementCost
+	result.CurrentHourly = hourlyCost(replacement)
+	result.Swapped = true
+	result.OverBudget = false
+	return result
+}
+
+// repriceAllocations applies cached prices and the spot bids they imply to
+// allocations. Allocations whose instance is missing from the cache keep
+// their price.
+func repriceAllocations(allocations []models.Allocation, allInstances map[models.Provider][]models.GPUInstance, constraints models.JobConstraints) []models.Allocation {
+	repriced := make([]models.Allocation, len(allocations))
+	for i, alloc := range allocations {
+		for _, instance := range allInstances[alloc.Provider] {
+			if instance.Region != alloc.Region || instance.InstanceType != alloc.InstanceType {
+				continue
+			}
+			if alloc.Spot && instance.SpotPrice > 0 {
+				alloc.PricePerHour = instance.SpotPrice
+				alloc.SpotBid = models.SpotMaxPrice(constraints, instance.SpotPrice, instance.PricePerHour)
+			} else if !alloc.Spot && instance.PricePerHour > 0 {
+				alloc.PricePerHour = instance.PricePerHour
+			}
+			break
+		}
+		alloc.EstimatedCost = alloc.ExpectedCost()
+		repriced[i] = alloc
+	}
+	return repriced
+}
+
+// hourlyCost returns the combined hourly price of allocations
+func hourlyCost(allocations []models.Allocation) float64 {
+	total := 0.0
+	for _, alloc := range allocations {
+		total += alloc.HourlyPrice() * float64(alloc.Count)
+	}
+	return total
+}
+
+// estimatedCost returns the combined estimated cost of allocations
+func estimatedCost(allocations []models.Allocation) float64 {
+	total := 0.0
+	for _, alloc := range allocations {
+		total += alloc.EstimatedCost
+	}
+	return total
+}
@@ -3,120 +3,299 @@ package optimizer
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"gpu-orchestrator/core/models"
-	"gpu-orchestrator/providers/aws"
-	"gpu-orchestrator/providers/azure"
-	"gpu-orchestrator/providers/gcp"
+	"gpu-orchestrator/core/supervisor"
+	"gpu-orchestrator/providers"
 )
 
 // PricingFetcher fetches and caches GPU pricing from all providers
 type PricingFetcher struct {
-	awsClient   *aws.Client
-	gcpClient   *gcp.Client
-	azureClient *azure.Client
-	db          *sql.DB
-	cacheTTL    time.Duration
-	mu          sync.RWMutex
+	providers    providers.Registry
+	db           PricingDB
+	cacheTTL     time.Duration            // On-demand refresh interval
+	spotInterval time.Duration            // Spot refresh interval
+	overrides    map[string]time.Duration // Intervals by "provider.kind"
+	timeout      time.Duration            // Bound on one provider's refresh
+	guard        *PriceGuard              // Optional; quarantines anomalous prices
+	observer     PriceObserver            // Optional; told about stored prices
+	mu           sync.RWMutex
+	status       map[string]*models.PricingRefreshStatus // By "provider.kind"
+}
+
+// pricingBatchRows is how many prices one upsert statement writes; it keeps
+// statements under SQLite's 999 parameter limit
+const pricingBatchRows = 90
+
+// PricingDB is the database holding the pricing cache; repository.DB
+// satisfies it and rebinds queries for SQLite
+type PricingDB interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// PriceObserver is told about the prices each refresh stored, e.g. to
+// evaluate pricing subscriptions
+type PriceObserver interface {
+	PricesStored(ctx context.Context, provider models.Provider, kind string, instances []models.GPUInstance)
 }
 
 // NewPricingFetcher creates a new pricing fetcher
 func NewPricingFetcher(
-	awsClient *aws.Client,
-	gcpClient *gcp.Client,
-	azureClient *azure.Client,
-	db *sql.DB,
+	registry providers.Registry,
+	db PricingDB,
 ) *PricingFetcher {
 	if db == nil {
 		// Return nil if no database (for testing)
 		return nil
 	}
 	return &PricingFetcher{
-		awsClient:   awsClient,
-		gcpClient:   gcpClient,
-		azureClient: azureClient,
-		db:          db,
-		cacheTTL:    15 * time.Minute, // Refresh every 15 minutes
+		providers:    registry,
+		db:           db,
+		cacheTTL:     15 * time.Minute, // Refresh every 15 minutes
+		spotInterval: 5 * time.Minute,
+		timeout:      2 * time.Minute,
+		status:       make(map[string]*models.PricingRefreshStatus),
+	}
+}
+
+// SetRefreshInterval sets how often on-demand pricing is refreshed (default
+// 15 minutes). The cache serves rows refreshed within the last hour.
+func (pf *PricingFetcher) SetRefreshInterval(interval time.Duration) {
+	pf.cacheTTL = interval
+}
+
+// SetSpotRefreshInterval sets how often spot pricing is refreshed (default 5 minutes)
+func (pf *PricingFetcher) SetSpotRefreshInterval(interval time.Duration) {
+	pf.spotInterval = interval
+}
+
+// SetRefreshOverrides sets the intervals of individual providers and price
+// kinds, keyed "provider.kind" (e.g. "azure.spot")
+func (pf *PricingFetcher) SetRefreshOverrides(overrides map[string]time.Duration) {
+	pf.overrides = overrides
+}
+
+// SetRefreshTimeout bounds one provider's refresh of one price kind (default
+// 2 minutes), so a hung provider API only delays its own prices
+func (pf *PricingFetcher) SetRefreshTimeout(timeout time.Duration) {
+	pf.timeout = timeout
+}
+
+// SetPriceGuard enables sanity checks on refreshed prices
+func (pf *PricingFetcher) SetPriceGuard(guard *PriceGuard) {
+	pf.guard = guard
+}
+
+// SetPriceObserver sets the observer told about the prices each refresh stored
+func (pf *PricingFetcher) SetPriceObserver(observer PriceObserver) {
+	pf.observer = observer
+}
+
+// refreshKey names a provider's refresh of a price kind
+func refreshKey(provider models.Provider, kind string) string {
+	return string(provider) + "." + kind
+}
+
+// RefreshInterval returns how often a provider's prices of kind are refreshed
+func (pf *PricingFetcher) RefreshInterval(provider models.Provider, kind string) time.Duration {
+	if interval, ok := pf.overrides[refreshKey(provider, kind)]; ok {
+		return interval
+	}
+	if kind == models.PriceKindSpot {
+		return pf.spotInterval
+	}
+	return pf.cacheTTL
+}
+
+// MinRefreshInterval is the shortest refresh interval of any enabled
+// provider: how often the refresh worker heartbeats
+func (pf *PricingFetcher) MinRefreshInterval() time.Duration {
+	shortest := min(pf.cacheTTL, pf.spotInterval)
+	for _, name := range pf.providers.Names() {
+		for _, kind := range []string{models.PriceKindOnDemand, models.PriceKindSpot} {
+			shortest = min(shortest, pf.RefreshInterval(name, kind))
+		}
 	}
+	return shortest
 }
 
-// StartRefreshWorker starts a background worker to refresh pricing from provider APIs
+// StartRefreshWorker refreshes pricing from provider APIs: on-demand and spot
+// prices of every provider refresh independently, each on its own schedule,
+// so a slow or failing provider does not delay the others. Refreshes are
+// non-critical: over a provider's call budget the cached pricing is kept.
 func (pf *PricingFetcher) StartRefreshWorker(ctx context.Context) {
-	ticker := time.NewTicker(pf.cacheTTL)
+	ctx = providers.NonCritical(ctx)
+
+	var wg sync.WaitGroup
+	for _, name := range pf.providers.Names() {
+		for _, kind := range []string{models.PriceKindOnDemand, models.PriceKindSpot} {
+			wg.Add(1)
+			go func(name models.Provider, kind string) {
+				defer wg.Done()
+				pf.refreshLoop(ctx, name, kind)
+			}(name, kind)
+		}
+	}
+	wg.Wait()
+}
+
+// refreshLoop refreshes one provider's prices of kind until ctx is done
+func (pf *PricingFetcher) refreshLoop(ctx context.Context, name models.Provider, kind string) {
+	interval := pf.RefreshInterval(name, kind)
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	// Initial refresh
-	pf.refreshAllPricing(ctx)
+	pf.refresh(ctx, name, kind, interval)
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			pf.refreshAllPricing(ctx)
+			supervisor.Heartbeat(ctx)
+			pf.refresh(ctx, name, kind, interval)
 		}
 	}
 }
 
-func (pf *PricingFetcher) refreshAllPricing(ctx context.Context) {
-	// Fetch on-demand pricing from provider APIs (stable)
-	if pf.awsClient != nil {
-		awsPricing, err := pf.awsClient.FetchOnDemandPricing(ctx)
-		if err == nil {
-			pf.storePricing(awsPricing)
+// refresh fetches and stores one provider's prices of kind within the
+// refresh timeout, recording the outcome. Panics are recovered so the
+// provider refreshes again on its next tick.
+func (pf *PricingFetcher) refresh(ctx context.Context, name models.Provider, kind string, interval time.Duration) {
+	started := pf.refreshStarted(name, kind, interval)
+	stored := 0
+	var err error
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
 		}
-
-		// Fetch spot pricing (probabilistic - uses EC2 Spot Price History)
-		awsSpotPricing, err := pf.awsClient.FetchSpotPricing(ctx)
-		if err == nil {
-			pf.storeSpotPricing(awsSpotPricing)
+		if err != nil {
+			log.Printf("Failed to refresh %s %s pricing: %v", name, kind, err)
 		}
-	}
+		pf.refreshFinished(name, kind, started, stored, err)
+	}()
+
+	fetchCtx, cancel := context.WithTimeout(ctx, pf.timeout)
+	defer cancel()
 
-	// Fetch GCP on-demand + preemptible (similar approach)
-	if pf.gcpClient != nil {
-		gcpPricing, err := pf.gcpClient.FetchOnDemandPricing(ctx)
+	client := pf.providers[name]
+	var instances, admitted []models.GPUInstance
+	if kind == models.PriceKindSpot {
+		// Spot/preemptible pricing (probabilistic)
+		instances, err = client.FetchSpotPricing(fetchCtx)
 		if err == nil {
-			pf.storePricing(gcpPricing)
+			admitted, err = pf.storeSpotPricing(name, withManagedPricing(client, instances))
 		}
-
-		gcpPreemptiblePricing, err := pf.gcpClient.FetchPreemptiblePricing(ctx)
+	} else {
+		// On-demand pricing from provider APIs (stable)
+		instances, err = client.FetchOnDemandPricing(fetchCtx)
 		if err == nil {
-			pf.storePreemptiblePricing(gcpPreemptiblePricing)
+			admitted, err = pf.storePricing(name, withManagedPricing(client, instances))
 		}
 	}
+	if err != nil {
+		return
+	}
+	stored = len(admitted)
+	if pf.observer != nil {
+		// Outside the refresh timeout: notifications are delivered here
+		pf.observer.PricesStored(ctx, name, kind, admitted)
+	}
+}
 
-	// Fetch Azure on-demand + spot (similar approach)
-	if pf.azureClient != nil {
-		azurePricing, err := pf.azureClient.FetchOnDemandPricing(ctx)
-		if err == nil {
-			pf.storePricing(azurePricing)
-		}
+// refreshStarted marks a refresh as running and returns its start time
+func (pf *PricingFetcher) refreshStarted(name models.Provider, kind string, interval time.Duration) time.Time {
+	now := time.Now()
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+	status, ok := pf.status[refreshKey(name, kind)]
+	if !ok {
+		status = &models.PricingRefreshStatus{Provider: name, Kind: kind}
+		pf.status[refreshKey(name, kind)] = status
+	}
+	status.IntervalSeconds = interval.Seconds()
+	status.Running = true
+	status.LastStartedAt = &now
+	return now
+}
 
-		azureSpotPricing, err := pf.azureClient.FetchSpotPricing(ctx)
-		if err == nil {
-			pf.storeSpotPricing(azureSpotPricing)
+// refreshFinished records the outcome of a refresh
+func (pf *PricingFetcher) refreshFinished(name models.Provider, kind string, started time.Time, stored int, err error) {
+	now := time.Now()
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+	status := pf.status[refreshKey(name, kind)]
+	status.Running = false
+	status.LastDurationMs = now.Sub(started).Milliseconds()
+	if err != nil {
+		status.LastError = err.Error()
+		status.ConsecutiveFailures++
+		return
+	}
+	status.LastError = ""
+	status.ConsecutiveFailures = 0
+	status.LastSuccessAt = &now
+	status.Prices = stored
+}
+
+// RefreshStatus returns the state of every provider's refreshes, ordered by
+// provider and kind
+func (pf *PricingFetcher) RefreshStatus() []models.PricingRefreshStatus {
+	pf.mu.RLock()
+	defer pf.mu.RUnlock()
+	statuses := make([]models.PricingRefreshStatus, 0, len(pf.status))
+	for _, status := range pf.status {
+		statuses = append(statuses, *status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Provider != statuses[j].Provider {
+			return statuses[i].Provider < statuses[j].Provider
 		}
+		return statuses[i].Kind < statuses[j].Kind
+	})
+	return statuses
+}
+
+// storedPrice is the price of an instance type in the pricing cache
+type storedPrice struct {
+	onDemand float64
+	spot     float64
+}
+
+// pricingKey identifies an instance type's row in the pricing cache
+// withManagedPricing appends the entries of the provider's managed training
+// service, priced from the raw instances, so managed training competes with
+// raw VMs in the optimizer
+func withManagedPricing(client providers.Provider, instances []models.GPUInstance) []models.GPUInstance {
+	trainer, ok := client.(providers.ManagedTrainer)
+	if !ok {
+		return instances
 	}
+	return append(instances, trainer.ManagedPricing(instances)...)
 }
 
-// storePricing stores pricing data in the database
-func (pf *PricingFetcher) storePricing(instances []models.GPUInstance) {
-	for _, instance := range instances {
-		query := `
-			INSERT INTO gpu_pricing (
-				provider, region, instance_type, gpu_type, gpus_per_instance,
-				memory_per_gpu_gb, interconnect, on_demand_price_per_hour, last_updated
-			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
-			ON CONFLICT (provider, region, instance_type)
-			DO UPDATE SET
-				on_demand_price_per_hour = EXCLUDED.on_demand_price_per_hour,
-				last_updated = NOW()
-		`
-
-		_, err := pf.db.Exec(query,
+func pricingKey(instance models.GPUInstance) string {
+	return instance.Region + "/" + instance.InstanceType
+}
+
+// storePricing stores on-demand pricing in batched upserts and returns the
+// instances whose prices were stored
+func (pf *PricingFetcher) storePricing(provider models.Provider, instances []models.GPUInstance) ([]models.GPUInstance, error) {
+	admitted := pf.admitAll(provider, instances, models.PriceKindOnDemand)
+
+	rows := make([][]interface{}, len(admitted))
+	for i, instance := range admitted {
+		rows[i] = []interface{}{
 			instance.Provider,
 			instance.Region,
 			instance.InstanceType,
@@ -125,31 +304,32 @@ func (pf *PricingFetcher) storePricing(instances []models.GPUInstance) {
 			instance.MemoryPerGPU,
 			instance.InterconnectTier,
 			instance.PricePerHour,
-		)
-		if err != nil {
-			// Log error but continue
-			continue
+			instance.NetworkGbps,
+			instance.InterconnectGbps,
 		}
 	}
+	return admitted, pf.upsertBatches(`
+		INSERT INTO gpu_pricing (
+			provider, region, instance_type, gpu_type, gpus_per_instance,
+			memory_per_gpu_gb, interconnect, on_demand_price_per_hour, network_gbps, interconnect_gbps, last_updated
+		) VALUES `, `
+		ON CONFLICT (provider, region, instance_type)
+		DO UPDATE SET
+			on_demand_price_per_hour = EXCLUDED.on_demand_price_per_hour,
+			network_gbps = EXCLUDED.network_gbps,
+			interconnect_gbps = EXCLUDED.interconnect_gbps,
+			last_updated = NOW()
+	`, rows)
 }
 
-// storeSpotPricing stores spot pricing data in the database
-func (pf *PricingFetcher) storeSpotPricing(instances []models.GPUInstance) {
-	for _, instance := range instances {
-		query := `
-			INSERT INTO gpu_pricing (
-				provider, region, instance_type, gpu_type, gpus_per_instance,
-				memory_per_gpu_gb, interconnect, on_demand_price_per_hour,
-				spot_price_per_hour, spot_availability, last_updated
-			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW())
-			ON CONFLICT (provider, region, instance_type)
-			DO UPDATE SET
-				spot_price_per_hour = EXCLUDED.spot_price_per_hour,
-				spot_availability = EXCLUDED.spot_availability,
-				last_updated = NOW()
-		`
-
-		_, err := pf.db.Exec(query,
+// storeSpotPricing stores spot pricing in batched upserts and returns the
+// instances whose prices were stored
+func (pf *PricingFetcher) storeSpotPricing(provider models.Provider, instances []models.GPUInstance) ([]models.GPUInstance, error) {
+	admitted := pf.admitAll(provider, instances, models.PriceKindSpot)
+
+	rows := make([][]interface{}, len(admitted))
+	for i, instance := range admitted {
+		rows[i] = []interface{}{
 			instance.Provider,
 			instance.Region,
 			instance.InstanceType,
@@ -160,18 +340,122 @@ func (pf *PricingFetcher) storeSpotPricing(instances []models.GPUInstance) {
 			instance.PricePerHour, // Keep on-demand price
 			instance.SpotPrice,
 			instance.Availability,
-		)
-		if err != nil {
-			// Log error but continue
+		}
+	}
+	return admitted, pf.upsertBatches(`
+		INSERT INTO gpu_pricing (
+			provider, region, instance_type, gpu_type, gpus_per_instance,
+			memory_per_gpu_gb, interconnect, on_demand_price_per_hour,
+			spot_price_per_hour, spot_availability, last_updated
+		) VALUES `, `
+		ON CONFLICT (provider, region, instance_type)
+		DO UPDATE SET
+			spot_price_per_hour = EXCLUDED.spot_price_per_hour,
+			spot_availability = EXCLUDED.spot_availability,
+			last_updated = NOW()
+	`, rows)
+}
+
+// upsertBatches writes rows (the first three values are provider, region and
+// instance type) as multi-row upserts of pricingBatchRows rows each. An
+// instance type listed twice keeps its last row: one statement cannot
+// update the same row twice.
+func (pf *PricingFetcher) upsertBatches(insert, conflict string, rows [][]interface{}) error {
+	index := make(map[string]int, len(rows))
+	var unique [][]interface{}
+	for _, row := range rows {
+		key := fmt.Sprint(row[0], "/", row[1], "/", row[2])
+		if i, ok := index[key]; ok {
+			unique[i] = row
+			continue
+		}
+		index[key] = len(unique)
+		unique = append(unique, row)
+	}
+
+	for start := 0; start < len(unique); start += pricingBatchRows {
+		batch := unique[start:min(start+pricingBatchRows, len(unique))]
+		values := make([]string, len(batch))
+		var args []interface{}
+		for i, row := range batch {
+			placeholders := make([]string, len(row))
+			for j, value := range row {
+				args = append(args, value)
+				placeholders[j] = "$" + strconv.Itoa(len(args))
+			}
+			values[i] = "(" + strings.Join(placeholders, ", ") + ", NOW())"
+		}
+		if _, err := pf.db.Exec(insert+strings.Join(values, ",\n")+conflict, args...); err != nil {
+			return fmt.Errorf("failed to store %d prices: %w", len(batch), err)
+		}
+	}
+	return nil
+}
+
+// admitAll returns the instances whose price of kind passes the sanity
+// checks; quarantined instances keep serving their stored price. Without a
+// guard every instance is admitted. Stored prices are read once per refresh.
+func (pf *PricingFetcher) admitAll(provider models.Provider, instances []models.GPUInstance, kind string) []models.GPUInstance {
+	if pf.guard == nil {
+		return instances
+	}
+
+	stored, err := pf.storedPrices(provider)
+	if err != nil {
+		log.Printf("Failed to read stored %s prices: %v", provider, err)
+	}
+
+	var admitted []models.GPUInstance
+	for _, instance := range instances {
+		price, previous := instance.PricePerHour, stored[pricingKey(instance)].onDemand
+		if kind == models.PriceKindSpot {
+			price, previous = instance.SpotPrice, stored[pricingKey(instance)].spot
+		}
+		// Instances without a spot price only refresh the row
+		if (kind == models.PriceKindOnDemand || price > 0) && !pf.guard.Admit(instance, kind, price, previous) {
+			pf.keepLastKnownGood(instance)
 			continue
 		}
+		admitted = append(admitted, instance)
 	}
+	return admitted
 }
 
-// storePreemptiblePricing stores preemptible pricing data in the database (GCP)
-func (pf *PricingFetcher) storePreemptiblePricing(instances []models.GPUInstance) {
-	// GCP preemptible is similar to spot pricing
-	pf.storeSpotPricing(instances)
+// storedPrices returns the cached prices of a provider's instance types
+func (pf *PricingFetcher) storedPrices(provider models.Provider) (map[string]storedPrice, error) {
+	rows, err := pf.db.QueryContext(context.Background(), `
+		SELECT region, instance_type, on_demand_price_per_hour, spot_price_per_hour
+		FROM gpu_pricing
+		WHERE provider = $1
+	`, provider)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prices := make(map[string]storedPrice)
+	for rows.Next() {
+		var instance models.GPUInstance
+		var onDemand float64
+		var spot sql.NullFloat64
+		if err := rows.Scan(&instance.Region, &instance.InstanceType, &onDemand, &spot); err != nil {
+			return nil, err
+		}
+		prices[pricingKey(instance)] = storedPrice{onDemand: onDemand, spot: spot.Float64}
+	}
+	return prices, rows.Err()
+}
+
+// keepLastKnownGood keeps serving the stored price of a quarantined
+// instance: the cache only serves rows refreshed within the last hour
+func (pf *PricingFetcher) keepLastKnownGood(instance models.GPUInstance) {
+	_, err := pf.db.Exec(`
+		UPDATE gpu_pricing SET last_updated = NOW()
+		WHERE provider = $1 AND region = $2 AND instance_type = $3
+	`, instance.Provider, instance.Region, instance.InstanceType)
+	if err != nil {
+		log.Printf("Failed to keep last known price of %s %s/%s: %v", instance.Provider, instance.Region, instance.InstanceType, err)
+	}
 }
 
 // FetchAllPricing fetches real-time pricing from all providers
@@ -185,7 +469,7 @@ func (pf *PricingFetcher) GetAllInstances(ctx context.Context) (map[models.Provi
 	query := `
         SELECT provider, instance_type, region, gpu_type, gpus_per_instance,
                memory_per_gpu_gb, on_demand_price_per_hour, spot_price_per_hour, 
-               spot_availability, interconnect, last_updated
+               spot_availability, interconnect, network_gbps, interconnect_gbps, last_updated
         FROM gpu_pricing
         WHERE last_updated > NOW() - INTERVAL '1 hour'
     `
@@ -214,6 +498,8 @@ func (pf *PricingFetcher) GetAllInstances(ctx context.Context) (map[models.Provi
 			&spotPrice,
 			&spotAvailability,
 			&instance.InterconnectTier,
+			&instance.NetworkGbps,
+			&instance.InterconnectGbps,
 			&instance.LastUpdated,
 		)
 		if err != nil {
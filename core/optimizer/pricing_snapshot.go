@@ -0,0 +1,46 @@
+package optimizer
+
+import (
+	"context"
+
+	"gpu-orchestrator/core/models"
+)
+
+// InstanceSource supplies the GPU catalog the optimizer allocates from.
+// *PricingFetcher reads the live pricing cache; *PricingSnapshot is a fixed
+// catalog for simulations.
+type InstanceSource interface {
+	GetAllInstances(ctx context.Context) (map[models.Provider][]models.GPUInstance, error)
+}
+
+// PricingSnapshot is an in-memory GPU catalog. It is never refreshed.
+type PricingSnapshot struct {
+	instances map[models.Provider][]models.GPUInstance
+}
+
+// NewPricingSnapshot creates a snapshot holding a copy of instances
+func NewPricingSnapshot(instances map[models.Provider][]models.GPUInstance) *PricingSnapshot {
+	return &PricingSnapshot{instances: copyCatalog(instances)}
+}
+
+// GetAllInstances returns a copy of the snapshot, so callers may modify it
+func (s *PricingSnapshot) GetAllInstances(_ context.Context) (map[models.Provider][]models.GPUInstance, error) {
+	return copyCatalog(s.instances), nil
+}
+
+// WithInstances returns an optimizer that allocates from src instead of the
+// live pricing cache. It shares node limits and performance metrics with ao.
+func (ao *AllocationOptimizer) WithInstances(src InstanceSource) *AllocationOptimizer {
+	clone := *ao
+	clone.instances = src
+	return &clone
+}
+
+// copyCatalog copies a catalog's per-provider slices
+func copyCatalog(instances map[models.Provider][]models.GPUInstance) map[models.Provider][]models.GPUInstance {
+	copied := make(map[models.Provider][]models.GPUInstance, len(instances))
+	for provider, list := range instances {
+		copied[provider] = append([]models.GPUInstance(nil), list...)
+	}
+	return copied
+}
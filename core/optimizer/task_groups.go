@@ -0,0 +1,133 @@
+package optimizer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gpu-orchestrator/core/models"
+)
+
+// StrategyTaskGroups names the combined strategy of a job planned per task group
+const StrategyTaskGroups = "task_groups"
+
+// optimizeTaskGroups plans each task group of a multi_task job on its own
+// and concatenates the groups' allocations, tagged with their group, in
+// group order. The job's budget applies to the groups' total; every group
+// must be placed.
+func (ao *AllocationOptimizer) optimizeTaskGroups(
+	allInstances map[models.Provider][]models.GPUInstance,
+	requirements models.JobRequirements,
+	constraints models.JobConstraints,
+) ([]models.Allocation, *models.AllocationDecision, error) {
+	var allocations []models.Allocation
+	groups := make([]models.TaskGroupDecision, 0, len(requirements.TaskGroups))
+	for _, group := range requirements.TaskGroups {
+		groupAllocations, decision, err := ao.decide(allInstances, groupRequirements(requirements, group), constraints)
+		if decision != nil {
+			groups = append(groups, models.TaskGroupDecision{Name: group.Name, Decision: decision})
+		}
+		if err != nil {
+			return nil, newTaskGroupsDecision(groups, nil, requirements, constraints), fmt.Errorf("task group %s: %w", group.Name, err)
+		}
+		if len(groupAllocations) == 0 {
+			// Nothing is placed unless every group is
+			return nil, newTaskGroupsDecision(groups, nil, requirements, constraints), nil
+		}
+		for _, alloc := range groupAllocations {
+			alloc.TaskGroup = group.Name
+			allocations = append(allocations, alloc)
+		}
+	}
+	return allocations, newTaskGroupsDecision(groups, allocations, requirements, constraints), nil
+}
+
+// groupRequirements returns the requirements of one task group: its GPUs,
+// GPU memory and instance types in place of the job's
+func groupRequirements(requirements models.JobRequirements, group models.TaskGroup) models.JobRequirements {
+	groupReq := requirements
+	groupReq.TaskGroups = nil
+	groupReq.GPUs = group.GPUs()
+	groupReq.GPUsPerTask = group.GPUsPerTask
+	if group.GPUMemory > 0 {
+		groupReq.GPUMemory = group.GPUMemory
+	}
+	if len(group.InstanceTypes) > 0 {
+		groupReq.InstanceTypes = group.InstanceTypes
+	}
+	return groupReq
+}
+
+// newTaskGroupsDecision combines the decisions of a job's task groups. The
+// chosen strategy is the union of each group's chosen strategy; it is left
+// out when a group was not placed (allocations is nil).
+func newTaskGroupsDecision(groups []models.TaskGroupDecision, allocations []models.Allocation, requirements models.JobRequirements, constraints models.JobConstraints) *models.AllocationDecision {
+	weights := constraints.ScoringWeights()
+	decision := &models.AllocationDecision{
+		Strategies:      []models.StrategyEvaluation{},
+		DatasetLocation: requirements.DatasetLocation,
+		Weights:         &weights,
+		TaskGroups:      groups,
+		DecidedAt:       time.Now(),
+	}
+	if requirements.Metric == models.MetricTokens {
+		decision.Metric = models.MetricTokens
+	}
+	for _, group := range groups {
+		decision.ExcludedInstances += group.Decision.ExcludedInstances
+	}
+
+	if allocations != nil {
+		decision.Strategies = append(decision.Strategies, evaluationOf(combinedTaskGroupStrategy(groups, allocations, constraints)))
+		decision.Chosen = StrategyTaskGroups
+	}
+
+	decision.Explanation = ExplainDecision(decision)
+	return decision
+}
+
+// combinedTaskGroupStrategy combines the chosen strategies of a job's task
+// groups into one over all their allocations
+func combinedTaskGroupStrategy(groups []models.TaskGroupDecision, allocations []models.Allocation, constraints models.JobConstraints) Strategy {
+	combined := Strategy{Name: StrategyTaskGroups, Allocation: allocations}
+	for _, group := range groups {
+		chosen, _ := group.Decision.ChosenStrategy()
+		combined.TotalCost += chosen.TotalCost
+		combined.DataTransferCost += chosen.DataTransferCost
+		combined.EmissionsGCO2e += chosen.EmissionsGCO2e
+		combined.Rejections = append(combined.Rejections, chosen.Rejections...)
+		combined.TaskSpread = append(combined.TaskSpread, chosen.TaskSpread...)
+		// The least reliable group bounds the job
+		if combined.Reliability == 0 || chosen.Reliability < combined.Reliability {
+			combined.Reliability = chosen.Reliability
+		}
+	}
+	if total := combined.TotalCost + combined.DataTransferCost; total > constraints.MaxBudget && !hasRejection(combined.Rejections, models.RejectionOverBudget) {
+		combined.Rejections = append(combined.Rejections, models.StrategyRejection{
+			Reason: models.RejectionOverBudget,
+			Value:  total,
+			Limit:  constraints.MaxBudget,
+		})
+	}
+	return combined
+}
+
+// hasRejection reports whether rejections include reason
+func hasRejection(rejections []models.StrategyRejection, reason string) bool {
+	for _, rejection := range rejections {
+		if rejection.Reason == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// explainTaskGroups explains a decision planned per task group, one
+// sentence per group, e.g. "Task group trainer: Chose ..."
+func explainTaskGroups(decision *models.AllocationDecision) string {
+	parts := make([]string, 0, len(decision.TaskGroups))
+	for _, group := range decision.TaskGroups {
+		parts = append(parts, "Task group "+group.Name+": "+ExplainDecision(group.Decision))
+	}
+	return strings.Join(parts, " ")
+}
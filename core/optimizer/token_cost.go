@@ -0,0 +1,111 @@
+package optimizer
+
+import (
+	"math"
+
+	"gpu-orchestrator/core/models"
+)
+
+// referenceTokenGPU is the GPU type a tokens-mode job's estimated_hours are
+// read against to size its token volume
+const referenceTokenGPU = "A100"
+
+// interconnectEfficiency is the share of per-GPU throughput kept across
+// nodes. Each extra node loses a little, less on faster interconnects.
+func interconnectEfficiency(nodes int, networkGbps float64) float64 {
+	if nodes <= 1 {
+		return 1
+	}
+	loss := 0.10
+	switch {
+	case networkGbps >= 400:
+		loss = 0.02
+	case networkGbps >= 100:
+		loss = 0.05
+	}
+	return math.Max(0.5, 1-loss*float64(nodes-1))
+}
+
+// rankPrice is the price cheapestStrategy picks instances by: per GPU-hour,
+// spot when allowed, or in tokens mode per token of the GPU's benchmark.
+// ranked is false for instances without a token benchmark in tokens mode,
+// which are only used after every benchmarked one.
+func (ao *AllocationOptimizer) rankPrice(instance models.GPUInstance, requirements models.JobRequirements, constraints models.JobConstraints) (price float64, ranked bool) {
+	price = instance.PricePerHour / float64(instance.GPUsPerInstance)
+	if instance.SpotPrice > 0 && constraints.AllowSpot {
+		price = instance.SpotPrice / float64(instance.GPUsPerInstance)
+	}
+	if requirements.Metric != models.MetricTokens {
+		return price, true
+	}
+
+	gpuType := instance.GPUType
+	if gpuType == "" {
+		gpuType = GPUTypeForInstanceType(instance.InstanceType)
+	}
+	metrics, ok := ao.performanceMetrics.TokenBenchmark(requirements.Framework, gpuType, requirements.ModelClass)
+	if !ok {
+		return price, false
+	}
+	return price / metrics.TokensPerHour, true
+}
+
+// tokensPerHour returns the benchmark throughput of an allocation: per-GPU
+// tokens per hour times the GPUs the job uses, scaled by interconnect
+// efficiency. Returns 0 when a GPU type has no token benchmark.
+func (ao *AllocationOptimizer) tokensPerHour(allocations []models.Allocation, requirements models.JobRequirements) float64 {
+	nodes, capacity := 0, 0
+	tokens := 0.0
+	bandwidth := math.Inf(1)
+	for _, alloc := range allocations {
+		gpuType := alloc.GPUType
+		if gpuType == "" {
+			gpuType = GPUTypeForInstanceType(alloc.InstanceType)
+		}
+		metrics, ok := ao.performanceMetrics.TokenBenchmark(requirements.Framework, gpuType, requirements.ModelClass)
+		if !ok || alloc.GPUsPerNode <= 0 {
+			return 0
+		}
+		nodes += alloc.Count
+		capacity += alloc.Count * alloc.GPUsPerNode
+		tokens += metrics.TokensPerHour * float64(alloc.Count*alloc.GPUsPerNode)
+		bandwidth = math.Min(bandwidth, metrics.NetworkBandwidth)
+	}
+	if capacity == 0 {
+		return 0
+	}
+
+	// Nodes can have more GPUs than the job uses
+	used := math.Min(1, float64(requirements.GPUs)/float64(capacity))
+	return tokens * used * interconnectEfficiency(nodes, bandwidth)
+}
+
+// referenceMillionTokens is the job's token volume in millions: its estimated
+// hours on the reference GPU, or on the strategy itself without a reference
+// benchmark
+func (ao *AllocationOptimizer) referenceMillionTokens(requirements models.JobRequirements, strategyTokensPerHour float64) float64 {
+	tokensPerHour := strategyTokensPerHour
+	if metrics, ok := ao.performanceMetrics.TokenBenchmark(requirements.Framework, referenceTokenGPU, requirements.ModelClass); ok {
+		tokensPerHour = metrics.TokensPerHour * float64(requirements.GPUs)
+	}
+	return requirements.EstimatedHours * tokensPerHour / 1e6
+}
+
+// ReconcileTokenCost compares the chosen strategy's $/1M tokens estimate with
+// the job's running cost over the tokens it reported. Returns nil for jobs
+// not optimized for tokens; the actual value is 0 until tokens are reported.
+func ReconcileTokenCost(decision *models.AllocationDecision, job *models.Job) *models.TokenCostReconciliation {
+	if job.Requirements.Metric != models.MetricTokens {
+		return nil
+	}
+
+	reconciliation := &models.TokenCostReconciliation{CostUSD: job.CostRunningUSD}
+	if chosen, ok := decision.ChosenStrategy(); ok {
+		reconciliation.EstimatedUSDPerMillionTokens = chosen.USDPerMillionTokens
+	}
+	if job.Progress != nil && job.Progress.Tokens > 0 {
+		reconciliation.Tokens = job.Progress.Tokens
+		reconciliation.ActualUSDPerMillionTokens = job.CostRunningUSD / float64(job.Progress.Tokens) * 1e6
+	}
+	return reconciliation
+}
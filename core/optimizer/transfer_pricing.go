@@ -0,0 +1,183 @@
+package optimizer
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"strings"
+
+	"gpu-orchestrator/core/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed transfer_pricing.yaml
+var transferPricingYAML []byte
+
+// defaultEgressPerGB prices transfers no rule matches
+const defaultEgressPerGB = 0.10
+
+// Transfer destinations besides a provider name
+const (
+	TransferInterRegion = "inter_region" // Another region of the source provider
+	TransferInternet    = "internet"     // Any other provider
+)
+
+// TransferTier is the rate of one volume band of a transfer
+type TransferTier struct {
+	UpToGB float64 `yaml:"up_to_gb,omitempty"` // Cumulative GB the rate applies up to; 0 for the rest
+	Rate   float64 `yaml:"rate"`               // USD per GB
+}
+
+// TransferRule prices transfers from a provider's regions to a destination.
+// Region lists match by prefix and match every region when empty.
+type TransferRule struct {
+	Source             models.Provider `yaml:"source"`
+	SourceRegions      []string        `yaml:"source_regions,omitempty"`
+	Destination        string          `yaml:"destination"` // inter_region, internet or a provider
+	DestinationRegions []string        `yaml:"destination_regions,omitempty"`
+	Tiers              []TransferTier  `yaml:"tiers"`
+}
+
+// defaultTransferRules holds the embedded pricing table
+var defaultTransferRules []TransferRule
+
+func init() {
+	rules, err := parseTransferRules(transferPricingYAML)
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded transfer pricing: %v", err))
+	}
+	defaultTransferRules = rules
+}
+
+// LoadTransferPricing reads transfer pricing overrides from a YAML file with a
+// top-level "rules" list. They are consulted before the embedded table.
+func (cc *CostCalculator) LoadTransferPricing(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read transfer pricing: %w", err)
+	}
+	rules, err := parseTransferRules(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse transfer pricing: %w", err)
+	}
+	cc.transferOverrides = rules
+	return nil
+}
+
+// parseTransferRules parses and validates a pricing table
+func parseTransferRules(data []byte) ([]TransferRule, error) {
+	var file struct {
+		Rules []TransferRule `yaml:"rules"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	for i, rule := range file.Rules {
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+	}
+	return file.Rules, nil
+}
+
+// validate checks that the rule has a route and ascending tiers
+func (r TransferRule) validate() error {
+	if r.Source == "" || r.Destination == "" {
+		return fmt.Errorf("source and destination are required")
+	}
+	if len(r.Tiers) == 0 {
+		return fmt.Errorf("%s to %s has no tiers", r.Source, r.Destination)
+	}
+	previous := 0.0
+	for i, tier := range r.Tiers {
+		if tier.Rate < 0 {
+			return fmt.Errorf("%s to %s has a negative rate", r.Source, r.Destination)
+		}
+		if tier.UpToGB == 0 && i < len(r.Tiers)-1 {
+			return fmt.Errorf("%s to %s: only the last tier may omit up_to_gb", r.Source, r.Destination)
+		}
+		if tier.UpToGB != 0 && tier.UpToGB <= previous {
+			return fmt.Errorf("%s to %s: up_to_gb must increase", r.Source, r.Destination)
+		}
+		previous = tier.UpToGB
+	}
+	return nil
+}
+
+// matches reports whether the rule prices a transfer between two regions
+func (r TransferRule) matches(source models.Provider, sourceRegion string, target models.Provider, targetRegion string) bool {
+	if r.Source != source || !matchesRegion(r.SourceRegions, sourceRegion) || !matchesRegion(r.DestinationRegions, targetRegion) {
+		return false
+	}
+	switch r.Destination {
+	case TransferInterRegion:
+		return target == source
+	case TransferInternet:
+		return target != source
+	default:
+		return models.Provider(r.Destination) == target
+	}
+}
+
+// specificity ranks matching rules: source regions over destination regions
+// over a named destination provider
+func (r TransferRule) specificity() int {
+	specificity := 0
+	if len(r.SourceRegions) > 0 {
+		specificity += 4
+	}
+	if len(r.DestinationRegions) > 0 {
+		specificity += 2
+	}
+	if r.Destination != TransferInterRegion && r.Destination != TransferInternet {
+		specificity++
+	}
+	return specificity
+}
+
+// cost prices dataSizeGB through the tiers. Volume past a bounded last tier
+// is charged at its rate.
+func (r TransferRule) cost(dataSizeGB float64) float64 {
+	cost, from := 0.0, 0.0
+	for _, tier := range r.Tiers {
+		if tier.UpToGB == 0 || dataSizeGB <= tier.UpToGB {
+			return cost + (dataSizeGB-from)*tier.Rate
+		}
+		cost += (tier.UpToGB - from) * tier.Rate
+		from = tier.UpToGB
+	}
+	return cost + (dataSizeGB-from)*r.Tiers[len(r.Tiers)-1].Rate
+}
+
+// matchesRegion reports whether region starts with any prefix; an empty
+// list matches every region
+func matchesRegion(prefixes []string, region string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	for _, prefix := range prefixes {
+		if region != "" && strings.HasPrefix(region, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// transferRule returns the most specific rule for a transfer, preferring
+// overrides and then earlier rules. Returns nil when none matches.
+func (cc *CostCalculator) transferRule(source models.Provider, sourceRegion string, target models.Provider, targetRegion string) *TransferRule {
+	for _, rules := range [][]TransferRule{cc.transferOverrides, defaultTransferRules} {
+		var best *TransferRule
+		for i := range rules {
+			rule := &rules[i]
+			if rule.matches(source, sourceRegion, target, targetRegion) && (best == nil || rule.specificity() > best.specificity()) {
+				best = rule
+			}
+		}
+		if best != nil {
+			return best
+		}
+	}
+	return nil
+}
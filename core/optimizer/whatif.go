@@ -0,0 +1,285 @@
+package optimizer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"gpu-orchestrator/core/models"
+)
+
+// defaultReplayHours is the run time assumed for a replayed job that neither
+// ran nor had an estimate
+const defaultReplayHours = 1.0
+
+// WhatIf replays jobs against the optimizer's current catalog and against the
+// catalog modified by scenario, and returns both summaries with their delta.
+// Nothing is provisioned or written; the live pricing cache is read once.
+func (ao *AllocationOptimizer) WhatIf(ctx context.Context, jobs []*models.Job, scenario models.SupplyScenario) (*models.WhatIfResult, error) {
+	if ao.instances == nil {
+		return nil, fmt.Errorf("pricing fetcher not configured")
+	}
+	catalog, err := ao.instances.GetAllInstances(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pricing: %w", err)
+	}
+
+	baseline, err := ao.Replay(ctx, NewPricingSnapshot(catalog), jobs, nil)
+	if err != nil {
+		return nil, err
+	}
+	modified, err := ao.Replay(ctx, NewPricingSnapshot(ApplyScenario(catalog, scenario)), jobs, scenario.Inventory)
+	if err != nil {
+		return nil, err
+	}
+	return &models.WhatIfResult{
+		Baseline: baseline,
+		Scenario: modified,
+		Delta:    modified.Minus(baseline),
+	}, nil
+}
+
+// ApplyScenario returns catalog with the scenario's providers removed, its
+// prices overridden and its inventory pools added
+func ApplyScenario(catalog map[models.Provider][]models.GPUInstance, scenario models.SupplyScenario) map[models.Provider][]models.GPUInstance {
+	modified := copyCatalog(catalog)
+	for _, provider := range scenario.DisabledProviders {
+		delete(modified, provider)
+	}
+	for provider, instances := range modified {
+		for i := range instances {
+			for _, override := range scenario.Prices {
+				if !override.Matches(instances[i]) {
+					continue
+				}
+				if override.PricePerHour != nil {
+					instances[i].PricePerHour = *override.PricePerHour
+				}
+				if override.SpotPrice != nil {
+					instances[i].SpotPrice = *override.SpotPrice
+				}
+			}
+		}
+		modified[provider] = instances
+	}
+	for _, pool := range scenario.Inventory {
+		modified[pool.Provider] = append(modified[pool.Provider], pool.Instance())
+	}
+	return modified
+}
+
+// Replay feeds jobs through the optimizer in creation order against src.
+// Each job runs for its recorded run time if it ran, else its estimate, and
+// keeps its deadline relative to submission. Nodes of the inventory pools are
+// held for the run time of the jobs placed on them; a job that would need
+// more nodes than are free is placed elsewhere.
+func (ao *AllocationOptimizer) Replay(
+	ctx context.Context,
+	src InstanceSource,
+	jobs []*models.Job,
+	pools []models.InventoryPool,
+) (models.ReplaySummary, error) {
+	summary := models.ReplaySummary{GPUHoursByProvider: make(map[models.Provider]float64)}
+	catalog, err := src.GetAllInstances(ctx)
+	if err != nil {
+		return summary, fmt.Errorf("failed to load replay catalog: %w", err)
+	}
+
+	ordered := append([]*models.Job(nil), jobs...)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].CreatedAt.Before(ordered[j].CreatedAt) })
+
+	inventory := newInventoryTracker(pools)
+	var spotHours, instanceHours float64
+	for _, job := range ordered {
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+		summary.Jobs++
+
+		requirements, constraints := replayInputs(job)
+		allocations, err := ao.replayJob(ctx, catalog, inventory, job.CreatedAt, requirements, constraints)
+		if err != nil {
+			summary.Unplaced++
+			if job.Constraints.Deadline != nil {
+				summary.MissedDeadlines++
+			}
+			continue
+		}
+		summary.Placed++
+
+		var runTime time.Duration
+		for _, alloc := range allocations {
+			hours := float64(alloc.Count) * alloc.EstimatedTime.Hours()
+			summary.TotalCostUSD += alloc.EstimatedCost
+			summary.GPUHoursByProvider[alloc.Provider] += hours * float64(gpusPerInstance(catalog, alloc))
+			instanceHours += hours
+			if alloc.Spot {
+				spotHours += hours
+			}
+			if alloc.EstimatedTime > runTime {
+				runTime = alloc.EstimatedTime
+			}
+		}
+		if deadline := job.Constraints.Deadline; deadline != nil && job.CreatedAt.Add(runTime).After(*deadline) {
+			summary.MissedDeadlines++
+		}
+		inventory.hold(allocations, job.CreatedAt)
+	}
+	if instanceHours > 0 {
+		summary.SpotFraction = spotHours / instanceHours
+	}
+	return summary, nil
+}
+
+// replayJob optimizes one job against catalog minus the pools without enough
+// free nodes at submission time
+func (ao *AllocationOptimizer) replayJob(
+	ctx context.Context,
+	catalog map[models.Provider][]models.GPUInstance,
+	inventory *inventoryTracker,
+	at time.Time,
+	requirements models.JobRequirements,
+	constraints models.JobConstraints,
+) ([]models.Allocation, error) {
+	excluded := make(map[string]bool)
+	for key := range inventory.pools {
+		if inventory.free(key, at) == 0 {
+			excluded[key] = true
+		}
+	}
+
+	// Each retry excludes one more pool, so this ends
+	for {
+		snapshot := ao.WithInstances(NewPricingSnapshot(withoutPools(catalog, excluded)))
+		allocations, _, err := snapshot.OptimizeWithDecision(ctx, requirements, constraints)
+		if err != nil {
+			return nil, err
+		}
+		if len(allocations) == 0 {
+			return nil, fmt.Errorf("no capacity")
+		}
+		overbooked := inventory.overbooked(allocations, at)
+		if overbooked == "" {
+			return allocations, nil
+		}
+		excluded[overbooked] = true
+	}
+}
+
+// replayInputs returns the job's requirements and constraints as if it were
+// submitted now: the run time is its recorded one and the deadline is moved
+// by the same offset, since the optimizer scores time left until the deadline
+func replayInputs(job *models.Job) (models.JobRequirements, models.JobConstraints) {
+	requirements := job.Requirements
+	constraints := job.Constraints
+
+	if job.StartedAt != nil && job.CompletedAt != nil && job.CompletedAt.After(*job.StartedAt) {
+		requirements.EstimatedHours = job.CompletedAt.Sub(*job.StartedAt).Hours()
+	}
+	if requirements.EstimatedHours <= 0 {
+		requirements.EstimatedHours = defaultReplayHours
+	}
+	if constraints.Deadline != nil {
+		deadline := time.Now().Add(constraints.Deadline.Sub(job.CreatedAt))
+		constraints.Deadline = &deadline
+	}
+	return requirements, constraints
+}
+
+// gpusPerInstance looks up an allocation's GPUs per instance in catalog
+func gpusPerInstance(catalog map[models.Provider][]models.GPUInstance, alloc models.Allocation) int {
+	for _, instance := range catalog[alloc.Provider] {
+		if instance.InstanceType == alloc.InstanceType && instance.Region == alloc.Region {
+			return instance.GPUsPerInstance
+		}
+	}
+	return 0
+}
+
+// poolKey identifies the catalog entry of an inventory pool
+func poolKey(provider models.Provider, instanceType, region string) string {
+	return string(provider) + "/" + region + "/" + instanceType
+}
+
+// withoutPools returns catalog without the entries of the excluded pools
+func withoutPools(catalog map[models.Provider][]models.GPUInstance, excluded map[string]bool) map[models.Provider][]models.GPUInstance {
+	if len(excluded) == 0 {
+		return catalog
+	}
+	filtered := make(map[models.Provider][]models.GPUInstance, len(catalog))
+	for provider, instances := range catalog {
+		for _, instance := range instances {
+			if !excluded[poolKey(provider, instance.InstanceType, instance.Region)] {
+				filtered[provider] = append(filtered[provider], instance)
+			}
+		}
+	}
+	return filtered
+}
+
+// inventoryTracker tracks which inventory pool nodes are held over replay time
+type inventoryTracker struct {
+	pools map[string]int             // Key -> total nodes
+	holds map[string][]inventoryHold // Key -> nodes held by placed jobs
+}
+
+// inventoryHold is a number of nodes held until a time
+type inventoryHold struct {
+	nodes int
+	until time.Time
+}
+
+// newInventoryTracker creates a tracker with every pool free
+func newInventoryTracker(pools []models.InventoryPool) *inventoryTracker {
+	t := &inventoryTracker{
+		pools: make(map[string]int),
+		holds: make(map[string][]inventoryHold),
+	}
+	for _, pool := range pools {
+		t.pools[poolKey(pool.Provider, pool.InstanceType, pool.Region)] += pool.Nodes
+	}
+	return t
+}
+
+// free returns the pool's nodes not held at time at
+func (t *inventoryTracker) free(key string, at time.Time) int {
+	free := t.pools[key]
+	for _, h := range t.holds[key] {
+		if h.until.After(at) {
+			free -= h.nodes
+		}
+	}
+	if free < 0 {
+		return 0
+	}
+	return free
+}
+
+// overbooked returns the key of a pool allocations need more free nodes of
+// than it has at time at, or "" if they fit
+func (t *inventoryTracker) overbooked(allocations []models.Allocation, at time.Time) string {
+	needed := make(map[string]int)
+	for _, alloc := range allocations {
+		key := poolKey(alloc.Provider, alloc.InstanceType, alloc.Region)
+		if _, ok := t.pools[key]; ok {
+			needed[key] += alloc.Count
+		}
+	}
+	for key, nodes := range needed {
+		if nodes > t.free(key, at) {
+			return key
+		}
+	}
+	return ""
+}
+
+// hold marks the pool nodes used by allocations as held for their run time
+func (t *inventoryTracker) hold(allocations []models.Allocation, at time.Time) {
+	for _, alloc := range allocations {
+		key := poolKey(alloc.Provider, alloc.InstanceType, alloc.Region)
+		if _, ok := t.pools[key]; ok {
+			t.holds[key] = append(t.holds[key], inventoryHold{nodes: alloc.Count, until: at.Add(alloc.EstimatedTime)})
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/repository"
+)
+
+// WebhookPolicy names denials caused by an unreachable webhook in fail-closed mode
+const WebhookPolicy = "policy_webhook"
+
+// Decision is the outcome of evaluating a job against org policies
+type Decision struct {
+	Allowed      bool
+	Violation    *Violation // Set when denied
+	Mutations    []Mutation // Changes made by the webhook, already applied to the job
+	WebhookError string     // Webhook failure that was let through in fail-open mode
+}
+
+// Engine enforces org policies on submitted jobs: built-in rules stored in
+// the database, then the optional external webhook, whose mutations are
+// re-checked against the built-in rules
+type Engine struct {
+	policyRepo *repository.PolicyRepository
+	webhook    *Webhook
+}
+
+// NewEngine creates a new policy engine
+func NewEngine(policyRepo *repository.PolicyRepository) *Engine {
+	return &Engine{policyRepo: policyRepo}
+}
+
+// SetWebhook configures the external policy webhook
+func (e *Engine) SetWebhook(webhook *Webhook) {
+	e.webhook = webhook
+}
+
+// Evaluate checks the job against all enabled policies, applying any
+// webhook mutations to it. An error means the rules could not be loaded.
+func (e *Engine) Evaluate(ctx context.Context, job *models.Job) (*Decision, error) {
+	rules, err := e.policyRepo.ListRules(true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load policy rules: %w", err)
+	}
+
+	if violation := Check(job, rules); violation != nil {
+		return &Decision{Violation: violation}, nil
+	}
+	if e.webhook == nil {
+		return &Decision{Allowed: true}, nil
+	}
+
+	resp, err := e.webhook.Review(ctx, job)
+	if err == nil && resp.Allowed {
+		var mutations []Mutation
+		mutations, err = applyMutations(job, resp)
+		if err == nil {
+			if violation := Check(job, rules); violation != nil {
+				return &Decision{Violation: violation, Mutations: mutations}, nil
+			}
+			return &Decision{Allowed: true, Mutations: mutations}, nil
+		}
+	}
+	if err != nil {
+		if e.webhook.failOpen {
+			log.Printf("Policy webhook failed for job %q, allowing (fail-open): %v", job.Name, err)
+			return &Decision{Allowed: true, WebhookError: err.Error()}, nil
+		}
+		log.Printf("Policy webhook failed for job %q, denying (fail-closed): %v", job.Name, err)
+		return &Decision{Violation: &Violation{
+			Policy:  WebhookPolicy,
+			Message: "policy webhook unavailable: " + err.Error(),
+		}}, nil
+	}
+
+	policy := resp.Policy
+	if policy == "" {
+		policy = WebhookPolicy
+	}
+	message := resp.Message
+	if message == "" {
+		message = "denied by policy webhook"
+	}
+	return &Decision{Violation: &Violation{Policy: policy, Message: message}}, nil
+}
@@ -0,0 +1,292 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gpu-orchestrator/core/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConditionSpec is the config/API representation of a policy condition
+type ConditionSpec struct {
+	Field  string   `yaml:"field" json:"field"`
+	Min    *float64 `yaml:"min,omitempty" json:"min,omitempty"`
+	Max    *float64 `yaml:"max,omitempty" json:"max,omitempty"`
+	Values []string `yaml:"values,omitempty" json:"values,omitempty"`
+}
+
+// RuleSpec is the config/API representation of a policy rule. Field, Min,
+// Max and Values are the check of required, bounds and allowed_values rules;
+// conditional rules use When and Then instead.
+type RuleSpec struct {
+	Name    string         `yaml:"name" json:"name"`
+	Type    string         `yaml:"type" json:"type"`
+	Field   string         `yaml:"field,omitempty" json:"field,omitempty"`
+	Min     *float64       `yaml:"min,omitempty" json:"min,omitempty"`
+	Max     *float64       `yaml:"max,omitempty" json:"max,omitempty"`
+	Values  []string       `yaml:"values,omitempty" json:"values,omitempty"`
+	When    *ConditionSpec `yaml:"when,omitempty" json:"when,omitempty"`
+	Then    *ConditionSpec `yaml:"then,omitempty" json:"then,omitempty"`
+	Message string         `yaml:"message,omitempty" json:"message,omitempty"`
+	Enabled *bool          `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+}
+
+// LoadRules loads policy rules from a YAML file with a top-level "policies" list
+func LoadRules(path string) ([]*models.PolicyRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy rules: %w", err)
+	}
+
+	var file struct {
+		Policies []RuleSpec `yaml:"policies"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse policy rules: %w", err)
+	}
+
+	rules := make([]*models.PolicyRule, 0, len(file.Policies))
+	for _, spec := range file.Policies {
+		rule, err := spec.ToRule()
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// ToRule validates the spec and converts it to a policy rule
+func (s RuleSpec) ToRule() (*models.PolicyRule, error) {
+	if s.Name == "" {
+		return nil, fmt.Errorf("policy rule name is required")
+	}
+
+	rule := &models.PolicyRule{
+		Name:    s.Name,
+		Type:    models.PolicyRuleType(s.Type),
+		Message: s.Message,
+		Enabled: true,
+	}
+	if s.Enabled != nil {
+		rule.Enabled = *s.Enabled
+	}
+
+	check := models.PolicyCondition{Field: s.Field, Min: s.Min, Max: s.Max, Values: s.Values}
+	switch rule.Type {
+	case models.PolicyRequired:
+		check.Min, check.Max, check.Values = nil, nil, nil
+	case models.PolicyBounds:
+		if s.Min == nil && s.Max == nil {
+			return nil, fmt.Errorf("policy %s: bounds rules require min or max", s.Name)
+		}
+		check.Values = nil
+	case models.PolicyAllowedValues:
+		if len(s.Values) == 0 {
+			return nil, fmt.Errorf("policy %s: allowed_values rules require values", s.Name)
+		}
+		check.Min, check.Max = nil, nil
+	case models.PolicyConditional:
+		if s.When == nil || s.Then == nil {
+			return nil, fmt.Errorf("policy %s: conditional rules require when and then", s.Name)
+		}
+		when, then := s.When.condition(), s.Then.condition()
+		if err := validateCondition(s.Name, when); err != nil {
+			return nil, err
+		}
+		if err := validateCondition(s.Name, then); err != nil {
+			return nil, err
+		}
+		rule.When, rule.Then = &when, &then
+		return rule, nil
+	default:
+		return nil, fmt.Errorf("policy %s: unknown type %q (want required, bounds, allowed_values or conditional)", s.Name, s.Type)
+	}
+
+	if err := validateCondition(s.Name, check); err != nil {
+		return nil, err
+	}
+	rule.Check = check
+	return rule, nil
+}
+
+// condition converts the spec to a condition
+func (s ConditionSpec) condition() models.PolicyCondition {
+	return models.PolicyCondition{Field: s.Field, Min: s.Min, Max: s.Max, Values: s.Values}
+}
+
+// validateCondition rejects unknown fields and empty ranges
+func validateCondition(name string, cond models.PolicyCondition) error {
+	if !knownField(cond.Field) {
+		return fmt.Errorf("policy %s: unknown field %q", name, cond.Field)
+	}
+	if cond.Min != nil && cond.Max != nil && *cond.Min > *cond.Max {
+		return fmt.Errorf("policy %s: min (%v) must be <= max (%v)", name, *cond.Min, *cond.Max)
+	}
+	return nil
+}
+
+// jobFields are the job fields policies can reference, besides labels.<key>
+var jobFields = []string{
+	"name", "job_type", "framework", "entrypoint", "team_id", "project_id",
+	"resources.gpus", "resources.max_gpus_per_node", "resources.gpu_memory", "resources.cpu_memory",
+	"resources.storage", "data.dataset", "data.locality", "execution.mode", "execution.backend",
+	"constraints.budget", "constraints.deadline", "constraints.allow_spot",
+	"constraints.max_spot_fraction", "constraints.min_reliability", "constraints.performance_weight",
+}
+
+// knownField reports whether policies can reference the field
+func knownField(field string) bool {
+	if key, ok := strings.CutPrefix(field, "labels."); ok {
+		return key != ""
+	}
+	return slices.Contains(jobFields, field)
+}
+
+// fieldValue returns a job field as a string and whether it is set. Zero
+// numbers count as unset, matching how the spec treats omitted fields.
+func fieldValue(job *models.Job, field string) (string, bool) {
+	if key, ok := strings.CutPrefix(field, "labels."); ok {
+		value := job.Labels[key]
+		return value, value != ""
+	}
+
+	number := func(v float64) (string, bool) {
+		return strconv.FormatFloat(v, 'f', -1, 64), v != 0
+	}
+	text := func(v string) (string, bool) {
+		return v, v != ""
+	}
+
+	switch field {
+	case "name":
+		return text(job.Name)
+	case "job_type":
+		return text(string(job.JobType))
+	case "framework":
+		return text(job.Framework)
+	case "entrypoint":
+		return text(job.EntrypointURI)
+	case "team_id":
+		return text(job.TeamID)
+	case "project_id":
+		return text(job.ProjectID)
+	case "resources.gpus":
+		return number(float64(job.Requirements.GPUs))
+	case "resources.max_gpus_per_node":
+		return number(float64(job.Requirements.MaxGPUsPerNode))
+	case "resources.gpu_memory":
+		return number(float64(job.Requirements.GPUMemory))
+	case "resources.cpu_memory":
+		return number(float64(job.Requirements.CPUMemory))
+	case "resources.storage":
+		return number(float64(job.Requirements.Storage))
+	case "data.dataset":
+		return text(job.DatasetURI)
+	case "data.locality":
+		return text(string(job.Constraints.DataLocality))
+	case "execution.mode":
+		return text(string(job.Requirements.ExecutionMode))
+	case "execution.backend":
+		return text(string(job.SelectedBackend))
+	case "constraints.budget":
+		return number(job.Constraints.MaxBudget)
+	case "constraints.deadline":
+		if job.Constraints.Deadline == nil {
+			return "", false
+		}
+		return job.Constraints.Deadline.Format(time.RFC3339), true
+	case "constraints.allow_spot":
+		return strconv.FormatBool(job.Constraints.AllowSpot), true
+	case "constraints.max_spot_fraction":
+		return strconv.FormatFloat(job.Constraints.MaxSpotFraction, 'f', -1, 64), true
+	case "constraints.min_reliability":
+		return number(job.Constraints.MinReliability)
+	case "constraints.performance_weight":
+		return strconv.FormatFloat(job.Constraints.PerformanceWeight, 'f', -1, 64), true
+	}
+	return "", false
+}
+
+// matches reports whether the job field is set and satisfies the condition
+func matches(job *models.Job, cond models.PolicyCondition) bool {
+	value, ok := fieldValue(job, cond.Field)
+	if !ok {
+		return false
+	}
+	if cond.Min != nil || cond.Max != nil {
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return false
+		}
+		if (cond.Min != nil && number < *cond.Min) || (cond.Max != nil && number > *cond.Max) {
+			return false
+		}
+	}
+	if len(cond.Values) > 0 && !slices.Contains(cond.Values, value) {
+		return false
+	}
+	return true
+}
+
+// Violation is a policy rule a job does not satisfy
+type Violation struct {
+	Policy  string `json:"policy"`
+	Message string `json:"message"`
+}
+
+// Check returns the first rule the job violates, or nil
+func Check(job *models.Job, rules []*models.PolicyRule) *Violation {
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+
+		var failed *models.PolicyCondition
+		switch rule.Type {
+		case models.PolicyConditional:
+			if rule.When != nil && rule.Then != nil && matches(job, *rule.When) && !matches(job, *rule.Then) {
+				failed = rule.Then
+			}
+		default:
+			if !matches(job, rule.Check) {
+				failed = &rule.Check
+			}
+		}
+		if failed == nil {
+			continue
+		}
+
+		message := rule.Message
+		if message == "" {
+			message = describe(job, *failed)
+		}
+		return &Violation{Policy: rule.Name, Message: message}
+	}
+	return nil
+}
+
+// describe explains why a condition did not match
+func describe(job *models.Job, cond models.PolicyCondition) string {
+	value, ok := fieldValue(job, cond.Field)
+	switch {
+	case !ok:
+		return fmt.Sprintf("%s is required", cond.Field)
+	case len(cond.Values) > 0 && !slices.Contains(cond.Values, value):
+		return fmt.Sprintf("%s must be one of %s, got %q", cond.Field, strings.Join(cond.Values, ", "), value)
+	case cond.Min != nil && cond.Max != nil:
+		return fmt.Sprintf("%s must be between %v and %v, got %s", cond.Field, *cond.Min, *cond.Max, value)
+	case cond.Min != nil:
+		return fmt.Sprintf("%s must be at least %v, got %s", cond.Field, *cond.Min, value)
+	case cond.Max != nil:
+		return fmt.Sprintf("%s must be at most %v, got %s", cond.Field, *cond.Max, value)
+	}
+	return fmt.Sprintf("%s does not satisfy the policy", cond.Field)
+}
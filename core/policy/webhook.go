@@ -0,0 +1,217 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"gpu-orchestrator/core/clock"
+	"gpu-orchestrator/core/models"
+)
+
+// WebhookRequest is the body POSTed to the external policy webhook
+type WebhookRequest struct {
+	Job WebhookJob `json:"job"`
+}
+
+// WebhookJob is the view of a submitted job sent to the policy webhook
+type WebhookJob struct {
+	Name        string             `json:"name"`
+	UserID      string             `json:"user_id"`
+	TeamID      string             `json:"team_id,omitempty"`
+	ProjectID   string             `json:"project_id,omitempty"`
+	JobType     models.JobType     `json:"job_type"`
+	Framework   string             `json:"framework"`
+	GPUs        int                `json:"gpus"`
+	Constraints WebhookConstraints `json:"constraints"`
+	Labels      map[string]string  `json:"labels"`
+	SpecYAML    string             `json:"spec_yaml"`
+}
+
+// WebhookConstraints are the job constraints the webhook sees and may mutate.
+// In a mutation, nil fields are left unchanged.
+type WebhookConstraints struct {
+	Budget            *float64 `json:"budget,omitempty"`
+	Deadline          *string  `json:"deadline,omitempty"` // RFC 3339
+	AllowSpot         *bool    `json:"allow_spot,omitempty"`
+	MaxSpotFraction   *float64 `json:"max_spot_fraction,omitempty"`
+	MinReliability    *float64 `json:"min_reliability,omitempty"`
+	PerformanceWeight *float64 `json:"performance_weight,omitempty"`
+}
+
+// WebhookResponse is the webhook's decision. Mutations are only honored when
+// the job is allowed; a label set to "" is removed.
+type WebhookResponse struct {
+	Allowed   bool   `json:"allowed"`
+	Policy    string `json:"policy,omitempty"` // Name of the denying policy
+	Message   string `json:"message,omitempty"`
+	Mutations *struct {
+		Constraints *WebhookConstraints `json:"constraints,omitempty"`
+		Labels      map[string]string   `json:"labels,omitempty"`
+	} `json:"mutations,omitempty"`
+}
+
+// Mutation is one field changed by the policy webhook
+type Mutation struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// Webhook calls an external policy service
+type Webhook struct {
+	url        string
+	failOpen   bool // Allow jobs when the webhook errors or times out
+	httpClient *http.Client
+}
+
+// NewWebhook creates a new policy webhook client
+func NewWebhook(url string, timeout time.Duration, failOpen bool) *Webhook {
+	return &Webhook{
+		url:        url,
+		failOpen:   failOpen,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// Review posts the job to the webhook and returns its decision
+func (w *Webhook) Review(ctx context.Context, job *models.Job) (*WebhookResponse, error) {
+	body, err := json.Marshal(WebhookRequest{Job: webhookJob(job)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode policy request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("policy webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("policy webhook returned %d: %s", resp.StatusCode, respBody)
+	}
+
+	var decision WebhookResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decision); err != nil {
+		return nil, fmt.Errorf("failed to decode policy webhook response: %w", err)
+	}
+	return &decision, nil
+}
+
+// webhookJob builds the webhook's view of a job
+func webhookJob(job *models.Job) WebhookJob {
+	constraints := WebhookConstraints{
+		Budget:            &job.Constraints.MaxBudget,
+		AllowSpot:         &job.Constraints.AllowSpot,
+		MaxSpotFraction:   &job.Constraints.MaxSpotFraction,
+		MinReliability:    &job.Constraints.MinReliability,
+		PerformanceWeight: &job.Constraints.PerformanceWeight,
+	}
+	if job.Constraints.Deadline != nil {
+		deadline := job.Constraints.Deadline.Format(time.RFC3339)
+		constraints.Deadline = &deadline
+	}
+
+	labels := job.Labels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+
+	return WebhookJob{
+		Name:        job.Name,
+		UserID:      job.UserID,
+		TeamID:      job.TeamID,
+		ProjectID:   job.ProjectID,
+		JobType:     job.JobType,
+		Framework:   job.Framework,
+		GPUs:        job.Requirements.GPUs,
+		Constraints: constraints,
+		Labels:      labels,
+		SpecYAML:    job.SpecYAML,
+	}
+}
+
+// applyMutations validates and applies the webhook's mutations to the job.
+// Nothing is applied if any mutation is invalid.
+func applyMutations(job *models.Job, resp *WebhookResponse) ([]Mutation, error) {
+	if resp.Mutations == nil {
+		return nil, nil
+	}
+
+	updated := job.Constraints
+	var mutations []Mutation
+	if c := resp.Mutations.Constraints; c != nil {
+		if c.Budget != nil {
+			if *c.Budget < 0 {
+				return nil, fmt.Errorf("mutated budget must be non-negative, got %v", *c.Budget)
+			}
+			mutations = append(mutations, Mutation{Field: "constraints.budget", From: updated.MaxBudget, To: *c.Budget})
+			updated.MaxBudget = *c.Budget
+		}
+		if c.Deadline != nil {
+			deadline, err := clock.ParseRFC3339(*c.Deadline)
+			if err != nil {
+				return nil, fmt.Errorf("invalid mutated deadline: %w", err)
+			}
+			mutations = append(mutations, Mutation{Field: "constraints.deadline", From: updated.Deadline, To: deadline})
+			updated.Deadline = &deadline
+		}
+		if c.AllowSpot != nil {
+			mutations = append(mutations, Mutation{Field: "constraints.allow_spot", From: updated.AllowSpot, To: *c.AllowSpot})
+			updated.AllowSpot = *c.AllowSpot
+		}
+		for _, f := range []struct {
+			name  string
+			value *float64
+			dst   *float64
+		}{
+			{"constraints.max_spot_fraction", c.MaxSpotFraction, &updated.MaxSpotFraction},
+			{"constraints.min_reliability", c.MinReliability, &updated.MinReliability},
+			{"constraints.performance_weight", c.PerformanceWeight, &updated.PerformanceWeight},
+		} {
+			if f.value == nil {
+				continue
+			}
+			if *f.value < 0 || *f.value > 1 {
+				return nil, fmt.Errorf("mutated %s must be between 0.0 and 1.0, got %v", f.name, *f.value)
+			}
+			mutations = append(mutations, Mutation{Field: f.name, From: *f.dst, To: *f.value})
+			*f.dst = *f.value
+		}
+	}
+
+	labels := make(map[string]string, len(job.Labels))
+	for key, value := range job.Labels {
+		labels[key] = value
+	}
+	for key, value := range resp.Mutations.Labels {
+		if key == "" || len(key) > 63 || len(value) > 255 {
+			return nil, fmt.Errorf("invalid mutated label %q", key)
+		}
+		from, had := labels[key]
+		if value == "" {
+			if !had {
+				continue
+			}
+			delete(labels, key)
+		} else {
+			labels[key] = value
+		}
+		mutations = append(mutations, Mutation{Field: "labels." + key, From: from, To: value})
+	}
+
+	job.Constraints = updated
+	job.Labels = labels
+	return mutations, nil
+}
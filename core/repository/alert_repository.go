@@ -0,0 +1,351 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gpu-orchestrator/core/models"
+
+	"github.com/lib/pq"
+)
+
+// AlertRepository handles database operations for alert rules, evaluations and firings
+type AlertRepository struct {
+	db *DB
+}
+
+// NewAlertRepository creates a new alert repository
+func NewAlertRepository(db *DB) *AlertRepository {
+	return &AlertRepository{db: db}
+}
+
+// StatusDurationRow is a job that has been in its current status since At
+type StatusDurationRow struct {
+	JobID  string
+	Name   string
+	TeamID string
+	Since  time.Time
+}
+
+// JobCostRow is a running job and its accumulated cost
+type JobCostRow struct {
+	JobID       string
+	Name        string
+	TeamID      string
+	RunningCost float64
+}
+
+// UpsertRule creates a rule or updates the rule with the same name
+func (r *AlertRepository) UpsertRule(rule *models.AlertRule) error {
+	query := `
+		INSERT INTO alert_rules (
+			name, condition, threshold, status, window_seconds, team_id,
+			cooldown_seconds, webhook_url, email_recipients, enabled
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (name) DO UPDATE SET
+			condition = EXCLUDED.condition,
+			threshold = EXCLUDED.threshold,
+			status = EXCLUDED.status,
+			window_seconds = EXCLUDED.window_seconds,
+			team_id = EXCLUDED.team_id,
+			cooldown_seconds = EXCLUDED.cooldown_seconds,
+			webhook_url = EXCLUDED.webhook_url,
+			email_recipients = EXCLUDED.email_recipients,
+			enabled = EXCLUDED.enabled
+		RETURNING id, created_at
+	`
+
+	recipients := rule.EmailRecipients
+	if recipients == nil {
+		recipients = []string{}
+	}
+
+	return r.db.QueryRow(query,
+		rule.Name,
+		rule.Condition,
+		rule.Threshold,
+		nullString(string(rule.Status)),
+		int64(rule.Window.Seconds()),
+		nullString(rule.TeamID),
+		int64(rule.Cooldown.Seconds()),
+		nullString(rule.WebhookURL),
+		pq.Array(recipients),
+		rule.Enabled,
+	).Scan(&rule.ID, &rule.CreatedAt)
+}
+
+// ListRules lists alert rules, optionally only enabled ones
+func (r *AlertRepository) ListRules(enabledOnly bool) ([]*models.AlertRule, error) {
+	query := `
+		SELECT id, name, condition, threshold, status, window_seconds, team_id,
+			cooldown_seconds, webhook_url, email_recipients, enabled, created_at, last_evaluated_at
+		FROM alert_rules
+	`
+	if enabledOnly {
+		query += " WHERE enabled = true"
+	}
+	query += " ORDER BY id"
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []*models.AlertRule
+	for rows.Next() {
+		var rule models.AlertRule
+		var status, teamID, webhookURL sql.NullString
+		var windowSeconds, cooldownSeconds int64
+		var lastEvaluatedAt sql.NullTime
+
+		err := rows.Scan(
+			&rule.ID,
+			&rule.Name,
+			&rule.Condition,
+			&rule.Threshold,
+			&status,
+			&windowSeconds,
+			&teamID,
+			&cooldownSeconds,
+			&webhookURL,
+			pq.Array(&rule.EmailRecipients),
+			&rule.Enabled,
+			&rule.CreatedAt,
+			&lastEvaluatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan alert rule: %w", err)
+		}
+
+		rule.Status = models.JobStatus(status.String)
+		rule.TeamID = teamID.String
+		rule.WebhookURL = webhookURL.String
+		rule.Window = time.Duration(windowSeconds) * time.Second
+		rule.Cooldown = time.Duration(cooldownSeconds) * time.Second
+		if lastEvaluatedAt.Valid {
+			rule.LastEvaluatedAt = &lastEvaluatedAt.Time
+		}
+
+		rules = append(rules, &rule)
+	}
+
+	return rules, rows.Err()
+}
+
+// RecordEvaluation stores the outcome of a rule evaluation for audit
+func (r *AlertRepository) RecordEvaluation(ruleID int64, matched, fired int, evalErr error) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var errMsg sql.NullString
+	if evalErr != nil {
+		errMsg = sql.NullString{String: evalErr.Error(), Valid: true}
+	}
+
+	now := r.db.Now()
+	_, err = tx.Exec(
+		`INSERT INTO alert_evaluations (rule_id, matched, fired, error, at) VALUES ($1, $2, $3, $4, $5)`,
+		ruleID, matched, fired, errMsg, now,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`UPDATE alert_rules SET last_evaluated_at = $2 WHERE id = $1`, ruleID, now)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// LastFiredAt returns when a rule last fired for a dedup key (nil if never)
+func (r *AlertRepository) LastFiredAt(ruleID int64, dedupKey string) (*time.Time, error) {
+	var firedAt sql.NullTime
+	err := r.db.QueryRow(
+		`SELECT MAX(fired_at) FROM alert_firings WHERE rule_id = $1 AND dedup_key = $2`,
+		ruleID, dedupKey,
+	).Scan(&firedAt)
+	if err != nil {
+		return nil, err
+	}
+	if !firedAt.Valid {
+		return nil, nil
+	}
+	return &firedAt.Time, nil
+}
+
+// CreateFiring records a delivered alert
+func (r *AlertRepository) CreateFiring(firing *models.AlertFiring) error {
+	metaJSON := "{}"
+	if firing.MetaJSON != nil {
+		metaBytes, err := json.Marshal(firing.MetaJSON)
+		if err == nil {
+			metaJSON = string(metaBytes)
+		}
+	}
+
+	query := `
+		INSERT INTO alert_firings (rule_id, dedup_key, subject, message, meta_json)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, fired_at
+	`
+	return r.db.QueryRow(query,
+		firing.RuleID,
+		firing.DedupKey,
+		firing.Subject,
+		firing.Message,
+		metaJSON,
+	).Scan(&firing.ID, &firing.FiredAt)
+}
+
+// ListFirings lists recent firings, optionally for a single rule (ruleID 0 = all)
+func (r *AlertRepository) ListFirings(ruleID int64, limit int) ([]models.AlertFiring, error) {
+	query := `
+		SELECT f.id, f.rule_id, r.name, f.dedup_key, f.fired_at, f.subject, f.message, f.meta_json
+		FROM alert_firings f
+		JOIN alert_rules r ON r.id = f.rule_id
+	`
+	args := []interface{}{}
+	argIndex := 1
+
+	if ruleID != 0 {
+		query += fmt.Sprintf(" WHERE f.rule_id = $%d", argIndex)
+		args = append(args, ruleID)
+		argIndex++
+	}
+
+	query += fmt.Sprintf(" ORDER BY f.fired_at DESC LIMIT $%d", argIndex)
+	args = append(args, limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var firings []models.AlertFiring
+	for rows.Next() {
+		var firing models.AlertFiring
+		var metaJSON string
+
+		err := rows.Scan(
+			&firing.ID,
+			&firing.RuleID,
+			&firing.RuleName,
+			&firing.DedupKey,
+			&firing.FiredAt,
+			&firing.Subject,
+			&firing.Message,
+			&metaJSON,
+		)
+		if err != nil {
+			continue
+		}
+
+		// Parse meta JSON
+		if metaJSON != "" {
+			json.Unmarshal([]byte(metaJSON), &firing.MetaJSON)
+		}
+
+		firings = append(firings, firing)
+	}
+
+	return firings, nil
+}
+
+// ListJobsInStatusSince returns jobs that entered status before the given time.
+// The entry time is the latest job event into the current status, falling back to updated_at.
+func (r *AlertRepository) ListJobsInStatusSince(status models.JobStatus, before time.Time, teamID string) ([]StatusDurationRow, error) {
+	query := `
+		SELECT j.id, j.name, COALESCE(j.team_id, ''), COALESCE(MAX(e.at), j.updated_at) AS since
+		FROM jobs j
+		LEFT JOIN job_events e ON e.job_id = j.id AND e.to_status = j.status
+		WHERE j.status = $1 AND ($2 = '' OR j.team_id = $2)
+		GROUP BY j.id
+		HAVING COALESCE(MAX(e.at), j.updated_at) < $3
+	`
+
+	rows, err := r.db.Query(query, status, teamID, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []StatusDurationRow
+	for rows.Next() {
+		var row StatusDurationRow
+		if err := rows.Scan(&row.JobID, &row.Name, &row.TeamID, &row.Since); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+// CountFailedJobsByTeam counts jobs that failed since the given time, grouped by team
+func (r *AlertRepository) CountFailedJobsByTeam(since time.Time, teamID string) (map[string]int, error) {
+	query := `
+		SELECT COALESCE(team_id, ''), COUNT(*)
+		FROM jobs
+		WHERE status = 'failed' AND updated_at >= $1 AND ($2 = '' OR team_id = $2)
+		GROUP BY COALESCE(team_id, '')
+	`
+
+	rows, err := r.db.Query(query, since, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var team string
+		var count int
+		if err := rows.Scan(&team, &count); err != nil {
+			return nil, err
+		}
+		counts[team] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// ListRunningJobsOverCost returns running (or cancelling) jobs whose
+// accumulated cost exceeds threshold
+func (r *AlertRepository) ListRunningJobsOverCost(threshold float64, teamID string) ([]JobCostRow, error) {
+	query := `
+		SELECT id, name, COALESCE(team_id, ''), cost_running_usd
+		FROM jobs
+		WHERE status IN ('running', 'cancelling') AND cost_running_usd > $1 AND ($2 = '' OR team_id = $2)
+	`
+
+	rows, err := r.db.Query(query, threshold, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []JobCostRow
+	for rows.Next() {
+		var row JobCostRow
+		if err := rows.Scan(&row.JobID, &row.Name, &row.TeamID, &row.RunningCost); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+// nullString converts an empty string to a SQL NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -1,6 +1,9 @@
 package repository
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
 	"time"
 
 	"gpu-orchestrator/core/models"
@@ -16,18 +19,47 @@ func NewAllocationRepository(db *DB) *AllocationRepository {
 	return &AllocationRepository{db: db}
 }
 
-// CreateAllocation creates an allocation record
-func (r *AllocationRepository) CreateAllocation(jobID string, allocation models.Allocation) error {
-	query := `
+// CreateAllocation stores a planned allocation and sets its ID and status
+func (r *AllocationRepository) CreateAllocation(jobID string, allocation *models.Allocation) error {
+	return insertAllocation(r.db.QueryRow, jobID, allocation, r.db.Now())
+}
+
+// ReplaceAllocations atomically replaces all allocation records of a job.
+// The new allocations' IDs and status are set in place.
+func (r *AllocationRepository) ReplaceAllocations(jobID string, allocations []models.Allocation) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM allocations WHERE job_id = $1`, jobID); err != nil {
+		return err
+	}
+	now := r.db.Now()
+	for i := range allocations {
+		if err := insertAllocation(tx.QueryRow, jobID, &allocations[i], now); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// insertAllocation inserts a planned allocation row through queryRow. Billing
+// compares created_at with job events, so it is set from the same clock.
+func insertAllocation(queryRow func(query string, args ...interface{}) *sql.Row, jobID string, allocation *models.Allocation, now time.Time) error {
+	err := queryRow(`
 		INSERT INTO allocations (
 			job_id, provider, region, backend, instance_type, count, spot,
-			price_per_hour, estimated_hours, estimated_cost_usd
+			price_per_hour, estimated_hours, estimated_cost_usd, status,
+			volume_type, volume_gb, storage_price_per_hour, gpu_sharing, gpu_share, zone,
+			spot_bid_strategy, spot_bid_usd, task_group, created_at, updated_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $21
 		)
-	`
-
-	_, err := r.db.Exec(query,
+		RETURNING id
+	`,
 		jobID,
 		allocation.Provider,
 		allocation.Region,
@@ -38,19 +70,247 @@ func (r *AllocationRepository) CreateAllocation(jobID string, allocation models.
 		allocation.PricePerHour,
 		allocation.EstimatedTime.Hours(),
 		allocation.EstimatedCost,
-	)
+		models.AllocationPlanned,
+		nullString(allocation.VolumeType),
+		allocation.VolumeGB,
+		allocation.StoragePricePerHour,
+		nullString(string(allocation.GPUSharing)),
+		allocation.GPUShare,
+		nullString(allocation.Zone),
+		nullString(string(allocation.BidStrategy)),
+		sql.NullFloat64{Float64: allocation.SpotBid, Valid: allocation.SpotBid > 0},
+		nullString(allocation.TaskGroup),
+		now,
+	).Scan(&allocation.ID)
+	if err != nil {
+		return err
+	}
+	allocation.Status = models.AllocationPlanned
+	allocation.ProvisionedCount = 0
+	allocation.StatusDetail = ""
+	return nil
+}
+
+// UpdateAllocationStatus records an allocation's lifecycle state and how many
+// of its instances are launched. A negative provisioned keeps the stored count.
+func (r *AllocationRepository) UpdateAllocationStatus(id int64, status models.AllocationStatus, provisioned int, detail string) error {
+	_, err := r.db.Exec(`
+		UPDATE allocations SET status = $2,
+			provisioned_count = CASE WHEN $3 < 0 THEN provisioned_count ELSE $3 END,
+			status_detail = $4, updated_at = $5
+		WHERE id = $1
+	`, id, status, provisioned, nullString(detail), r.db.Now())
+	return err
+}
+
+// UpdateAllocationSharing records how an allocation shares its GPUs and the
+// estimated cost at its billed share
+func (r *AllocationRepository) UpdateAllocationSharing(id int64, sharing models.GPUSharingMode, share, estimatedCost float64) error {
+	_, err := r.db.Exec(`
+		UPDATE allocations SET gpu_sharing = $2, gpu_share = $3, estimated_cost_usd = $4, updated_at = $5
+		WHERE id = $1
+	`, id, nullString(string(sharing)), share, estimatedCost, r.db.Now())
+	return err
+}
+
+// UpdateAllocationZone records the availability zone an allocation's
+// instances are launched in
+func (r *AllocationRepository) UpdateAllocationZone(id int64, zone string) error {
+	_, err := r.db.Exec(`
+		UPDATE allocations SET zone = $2, updated_at = $3
+		WHERE id = $1
+	`, id, nullString(zone), r.db.Now())
+	return err
+}
+
+// RecordInstancesLaunched records instances launched for a job's
+// allocation at the price they were launched at. An allocation ID of 0
+// (never stored, e.g. elastic growth) is recorded as NULL. Instances already
+// recorded keep their launch time.
+func (r *AllocationRepository) RecordInstancesLaunched(jobID string, alloc models.Allocation, instanceIDs []string, at time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, instanceID := range instanceIDs {
+		_, err := tx.Exec(`
+			INSERT INTO job_instances (
+				job_id, allocation_id, provider, region, instance_type, instance_id,
+				spot, price_per_hour, launched_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			ON CONFLICT (provider, region, instance_id) DO NOTHING
+		`,
+			jobID,
+			sql.NullInt64{Int64: alloc.ID, Valid: alloc.ID != 0},
+			alloc.Provider,
+			alloc.Region,
+			alloc.InstanceType,
+			instanceID,
+			alloc.Spot,
+			alloc.PricePerHour,
+			at,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// RecordInstancesTerminated records the termination of instances still
+// recorded as running. Unknown instances are ignored.
+func (r *AllocationRepository) RecordInstancesTerminated(provider models.Provider, region string, instanceIDs []string, at time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, instanceID := range instanceIDs {
+		_, err := tx.Exec(`
+			UPDATE job_instances SET terminated_at = $4
+			WHERE provider = $1 AND region = $2 AND instance_id = $3 AND terminated_at IS NULL
+		`, provider, region, instanceID, at)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ErrNoFreePort is returned when every port of the range is held on a node
+var ErrNoFreePort = errors.New("no free port in range")
+
+// portAllocationRetries bounds retries when another launch takes the same
+// port concurrently
+const portAllocationRetries = 5
+
+// AllocateNodePort hands the lowest free port of [low, high] on a node to a
+// job's service. A service the job already holds on the node keeps its port,
+// so relaunches reuse it.
+func (r *AllocationRepository) AllocateNodePort(node, jobID, service string, low, high int) (int, error) {
+	for attempt := 0; attempt < portAllocationRetries; attempt++ {
+		port, err := r.allocateNodePort(node, jobID, service, low, high)
+		if err != nil || port != 0 {
+			return port, err
+		}
+	}
+	return 0, fmt.Errorf("port allocation on %s kept conflicting", node)
+}
+
+// allocateNodePort makes one allocation attempt; 0 without error means the
+// chosen port was taken concurrently
+func (r *AllocationRepository) allocateNodePort(node, jobID, service string, low, high int) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var held int
+	err = tx.QueryRow(`
+		SELECT port FROM node_ports WHERE node = $1 AND job_id = $2 AND service = $3
+	`, node, jobID, service).Scan(&held)
+	if err == nil {
+		return held, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	rows, err := tx.Query(`
+		SELECT port FROM node_ports WHERE node = $1 AND port BETWEEN $2 AND $3
+	`, node, low, high)
+	if err != nil {
+		return 0, err
+	}
+	used := make(map[int]bool)
+	for rows.Next() {
+		var port int
+		if err := rows.Scan(&port); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		used[port] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	port := low
+	for port <= high && used[port] {
+		port++
+	}
+	if port > high {
+		return 0, fmt.Errorf("%w %d-%d on %s", ErrNoFreePort, low, high, node)
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO node_ports (node, port, job_id, service, allocated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT DO NOTHING
+	`, node, port, jobID, service, r.db.Now())
+	if err != nil {
+		return 0, err
+	}
+	if inserted, err := result.RowsAffected(); err != nil || inserted == 0 {
+		return 0, err
+	}
+	return port, tx.Commit()
+}
 
+// ReleaseJobPorts releases every port a job holds, on all nodes
+func (r *AllocationRepository) ReleaseJobPorts(jobID string) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM node_ports WHERE job_id = $1`, jobID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// ReleaseEndedJobPorts releases the ports still held by jobs that have
+// ended, catching releases that were missed
+func (r *AllocationRepository) ReleaseEndedJobPorts() (int64, error) {
+	result, err := r.db.Exec(`
+		DELETE FROM node_ports
+		WHERE job_id IN (SELECT id FROM jobs WHERE status IN ('completed', 'failed', 'cancelled'))
+	`)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// FailAllocations marks a job's planned and provisioning allocations failed,
+// after provisioning gave up. Active ones keep running until torn down.
+func (r *AllocationRepository) FailAllocations(jobID, detail string) error {
+	_, err := r.db.Exec(`
+		UPDATE allocations SET status = 'failed', status_detail = $2, updated_at = $3
+		WHERE job_id = $1 AND status IN ('planned', 'provisioning')
+	`, jobID, nullString(detail), r.db.Now())
+	return err
+}
+
+// TerminateAllocations marks every non-terminal allocation of a job
+// terminated, once the job no longer holds its instances
+func (r *AllocationRepository) TerminateAllocations(jobID, detail string) error {
+	_, err := r.db.Exec(`
+		UPDATE allocations SET status = 'terminated', status_detail = $2, updated_at = $3
+		WHERE job_id = $1 AND status IN ('planned', 'provisioning', 'active')
+	`, jobID, nullString(detail), r.db.Now())
 	return err
 }
 
 // GetAllocationsByJobID retrieves all allocations for a job
 func (r *AllocationRepository) GetAllocationsByJobID(jobID string) ([]models.Allocation, error) {
 	query := `
-		SELECT provider, region, instance_type, count, spot,
-			price_per_hour, estimated_hours, estimated_cost_usd
-		FROM allocations
-		WHERE job_id = $1
-		ORDER BY created_at
+		SELECT ` + allocationColumns + `
+		FROM allocations a
+		WHERE a.job_id = $1
+		ORDER BY a.created_at, a.id
 	`
 
 	rows, err := r.db.Query(query, jobID)
@@ -61,26 +321,103 @@ func (r *AllocationRepository) GetAllocationsByJobID(jobID string) ([]models.All
 
 	var allocations []models.Allocation
 	for rows.Next() {
-		var alloc models.Allocation
-		var estimatedHours float64
-
-		err := rows.Scan(
-			&alloc.Provider,
-			&alloc.Region,
-			&alloc.InstanceType,
-			&alloc.Count,
-			&alloc.Spot,
-			&alloc.PricePerHour,
-			&estimatedHours,
-			&alloc.EstimatedCost,
-		)
+		alloc, err := scanAllocation(rows)
 		if err != nil {
-			continue
+			// A dropped row would silently undercount cost, so fail the read
+			return nil, fmt.Errorf("failed to scan allocation for job %s: %w", jobID, err)
 		}
+		allocations = append(allocations, *alloc)
+	}
 
-		alloc.EstimatedTime = time.Duration(estimatedHours * float64(time.Hour))
-		allocations = append(allocations, alloc)
+	return allocations, rows.Err()
+}
+
+// ListFleetAllocations returns stored allocations with their jobs, newest
+// first. status filters by allocation status; orphaned keeps only active
+// allocations of jobs that are not provisioning, running, checkpointing or
+// cancelling.
+func (r *AllocationRepository) ListFleetAllocations(status models.AllocationStatus, orphaned bool, limit int) ([]models.FleetAllocation, error) {
+	rows, err := r.db.Query(`
+		SELECT a.job_id, j.status, a.updated_at, `+allocationColumns+`
+		FROM allocations a
+		JOIN jobs j ON j.id = a.job_id
+		WHERE ($1 = '' OR a.status = $1)
+			AND ($2 = false OR (a.status = 'active' AND j.status NOT IN ('provisioning', 'running', 'checkpointing', 'cancelling')))
+		ORDER BY a.updated_at DESC, a.id DESC
+		LIMIT $3
+	`, string(status), orphaned, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var fleet []models.FleetAllocation
+	for rows.Next() {
+		var entry models.FleetAllocation
+		alloc, err := scanAllocation(rows, &entry.JobID, &entry.JobStatus, &entry.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		entry.Allocation = *alloc
+		switch entry.JobStatus {
+		case models.JobStatusProvisioning, models.JobStatusRunning, models.JobStatusCheckpointing, models.JobStatusCancelling:
+		default:
+			entry.Orphaned = alloc.Status == models.AllocationActive
+		}
+		fleet = append(fleet, entry)
+	}
+	return fleet, rows.Err()
+}
+
+// allocationColumns are the columns scanAllocation reads, in order
+const allocationColumns = `a.id, a.provider, a.region, a.instance_type, a.count, a.spot,
+			a.price_per_hour, a.estimated_hours, a.estimated_cost_usd,
+			a.status, a.provisioned_count, a.status_detail,
+			a.volume_type, a.volume_gb, a.storage_price_per_hour,
+			a.gpu_sharing, a.gpu_share, a.zone,
+			a.spot_bid_strategy, a.spot_bid_usd, a.task_group`
+
+// scanAllocation scans allocationColumns after any leading destinations
+func scanAllocation(row interface{ Scan(...interface{}) error }, leading ...interface{}) (*models.Allocation, error) {
+	var alloc models.Allocation
+	var estimatedHours float64
+	var detail, volumeType, sharing, zone, bidStrategy, taskGroup sql.NullString
+	var spotBid sql.NullFloat64
+
+	dest := append(leading,
+		&alloc.ID,
+		&alloc.Provider,
+		&alloc.Region,
+		&alloc.InstanceType,
+		&alloc.Count,
+		&alloc.Spot,
+		&alloc.PricePerHour,
+		&estimatedHours,
+		&alloc.EstimatedCost,
+		&alloc.Status,
+		&alloc.ProvisionedCount,
+		&detail,
+		&volumeType,
+		&alloc.VolumeGB,
+		&alloc.StoragePricePerHour,
+		&sharing,
+		&alloc.GPUShare,
+		&zone,
+		&bidStrategy,
+		&spotBid,
+		&taskGroup,
+	)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
 	}
 
-	return allocations, nil
+	alloc.EstimatedTime = time.Duration(estimatedHours * float64(time.Hour))
+	alloc.StatusDetail = detail.String
+	alloc.VolumeType = volumeType.String
+	alloc.GPUSharing = models.GPUSharingMode(sharing.String)
+	alloc.Zone = zone.String
+	alloc.BidStrategy = models.SpotBidStrategy(bidStrategy.String)
+	alloc.SpotBid = spotBid.Float64
+	alloc.TaskGroup = taskGroup.String
+	return &alloc, nil
 }
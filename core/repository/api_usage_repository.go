@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"fmt"
+
+	"gpu-orchestrator/core/models"
+)
+
+// APIUsageRepository handles database operations for daily API usage
+type APIUsageRepository struct {
+	db *DB
+}
+
+// NewAPIUsageRepository creates a new API usage repository
+func NewAPIUsageRepository(db *DB) *APIUsageRepository {
+	return &APIUsageRepository{db: db}
+}
+
+// AddUsage adds counts to their daily rollups. Replicas add their own counts,
+// so rows total the requests served by every replica.
+func (r *APIUsageRepository) AddUsage(usage []models.APIUsage) error {
+	if len(usage) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := r.db.Now()
+	for _, u := range usage {
+		_, err := tx.Exec(`
+			INSERT INTO api_usage (day, endpoint, client, job_id, requests, errors, latency_ms, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT (day, endpoint, client, job_id) DO UPDATE SET
+				requests = api_usage.requests + EXCLUDED.requests,
+				errors = api_usage.errors + EXCLUDED.errors,
+				latency_ms = api_usage.latency_ms + EXCLUDED.latency_ms,
+				updated_at = EXCLUDED.updated_at
+		`, u.Day, u.Endpoint, u.Client, u.JobID, u.Requests, u.Errors, u.LatencyMs, now)
+		if err != nil {
+			return fmt.Errorf("failed to add usage of %s by %s: %w", u.Endpoint, u.Client, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// API usage dimensions Totals groups by
+const (
+	APIUsageByEndpoint = "endpoint"
+	APIUsageByClient   = "client"
+	APIUsageByJob      = "job_id"
+)
+
+// Totals sums the daily rollups from since (YYYY-MM-DD) on by one dimension,
+// most requests first. Grouping by job leaves out requests to endpoints
+// that are not job-scoped. limit 0 returns every group.
+func (r *APIUsageRepository) Totals(since, dimension string, limit int) ([]models.APIUsageTotal, error) {
+	filter := ""
+	switch dimension {
+	case APIUsageByEndpoint, APIUsageByClient:
+	case APIUsageByJob:
+		filter = " AND job_id <> ''"
+	default:
+		return nil, fmt.Errorf("unknown API usage dimension %q", dimension)
+	}
+	query := `
+		SELECT ` + dimension + `, SUM(requests), SUM(errors), SUM(latency_ms)
+		FROM api_usage
+		WHERE day >= $1` + filter + `
+		GROUP BY ` + dimension + `
+		ORDER BY SUM(requests) DESC, ` + dimension
+	args := []interface{}{since}
+	if limit > 0 {
+		query += ` LIMIT $2`
+		args = append(args, limit)
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var totals []models.APIUsageTotal
+	for rows.Next() {
+		var total models.APIUsageTotal
+		var latencyMs float64
+		if err := rows.Scan(&total.Key, &total.Requests, &total.Errors, &latencyMs); err != nil {
+			return nil, err
+		}
+		if total.Requests > 0 {
+			total.AvgLatencyMs = latencyMs / float64(total.Requests)
+		}
+		totals = append(totals, total)
+	}
+	return totals, rows.Err()
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"database/sql"
 	"encoding/json"
 	"fmt"
 
@@ -17,12 +18,16 @@ func NewArtifactRepository(db *DB) *ArtifactRepository {
 	return &ArtifactRepository{db: db}
 }
 
-// GetJobArtifacts retrieves artifacts for a job
+// artifactColumns is the select list scanned by scanArtifact
+const artifactColumns = `id, job_id, type, uri, created_at, meta_json, pinned, deleted_at, reclaimed_bytes,
+	COALESCE(replica_status, ''), replica_attempts`
+
+// GetJobArtifacts retrieves live (not garbage collected) artifacts for a job
 func (r *ArtifactRepository) GetJobArtifacts(jobID string, artifactType *models.ArtifactType) ([]models.JobArtifact, error) {
 	query := `
-		SELECT id, job_id, type, uri, created_at, meta_json
+		SELECT ` + artifactColumns + `
 		FROM job_artifacts
-		WHERE job_id = $1
+		WHERE job_id = $1 AND deleted_at IS NULL
 	`
 	args := []interface{}{jobID}
 	argIndex := 2
@@ -43,34 +48,58 @@ func (r *ArtifactRepository) GetJobArtifacts(jobID string, artifactType *models.
 
 	var artifacts []models.JobArtifact
 	for rows.Next() {
-		var artifact models.JobArtifact
-		var metaJSON string
-
-		err := rows.Scan(
-			&artifact.ID,
-			&artifact.JobID,
-			&artifact.Type,
-			&artifact.URI,
-			&artifact.CreatedAt,
-			&metaJSON,
-		)
+		artifact, err := scanArtifact(rows)
 		if err != nil {
 			continue
 		}
+		artifacts = append(artifacts, *artifact)
+	}
 
-		// Parse meta JSON
-		if metaJSON != "" {
-			json.Unmarshal([]byte(metaJSON), &artifact.MetaJSON)
-		}
+	return artifacts, nil
+}
 
-		artifacts = append(artifacts, artifact)
+// scanArtifact scans one row selected with artifactColumns
+func scanArtifact(row interface{ Scan(...interface{}) error }) (*models.JobArtifact, error) {
+	var artifact models.JobArtifact
+	var metaJSON string
+	var deletedAt sql.NullTime
+
+	err := row.Scan(
+		&artifact.ID,
+		&artifact.JobID,
+		&artifact.Type,
+		&artifact.URI,
+		&artifact.CreatedAt,
+		&metaJSON,
+		&artifact.Pinned,
+		&deletedAt,
+		&artifact.ReclaimedBytes,
+		&artifact.ReplicaStatus,
+		&artifact.ReplicaAttempts,
+	)
+	if err != nil {
+		return nil, err
 	}
 
-	return artifacts, nil
+	// Parse meta JSON
+	if metaJSON != "" {
+		json.Unmarshal([]byte(metaJSON), &artifact.MetaJSON)
+	}
+	if deletedAt.Valid {
+		artifact.DeletedAt = &deletedAt.Time
+	}
+
+	return &artifact, nil
 }
 
 // CreateArtifact creates a new artifact record
 func (r *ArtifactRepository) CreateArtifact(jobID string, artifactType models.ArtifactType, uri string, meta map[string]interface{}) error {
+	_, err := r.InsertArtifact(jobID, artifactType, uri, meta)
+	return err
+}
+
+// InsertArtifact creates a new artifact record and returns its ID
+func (r *ArtifactRepository) InsertArtifact(jobID string, artifactType models.ArtifactType, uri string, meta map[string]interface{}) (int64, error) {
 	metaJSON := "{}"
 	if meta != nil {
 		metaBytes, err := json.Marshal(meta)
@@ -81,9 +110,182 @@ func (r *ArtifactRepository) CreateArtifact(jobID string, artifactType models.Ar
 
 	query := `
 		INSERT INTO job_artifacts (job_id, type, uri, meta_json, created_at)
-		VALUES ($1, $2, $3, $4, NOW())
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+
+	var id int64
+	err := r.db.QueryRow(query, jobID, artifactType, uri, metaJSON, r.db.Now()).Scan(&id)
+	return id, err
+}
+
+// GetArtifact retrieves a live artifact of a job. Returns sql.ErrNoRows if
+// it does not exist, belongs to another job or was garbage collected.
+func (r *ArtifactRepository) GetArtifact(jobID string, artifactID int64) (*models.JobArtifact, error) {
+	query := `
+		SELECT ` + artifactColumns + `
+		FROM job_artifacts
+		WHERE id = $1 AND job_id = $2 AND deleted_at IS NULL
 	`
+	return scanArtifact(r.db.QueryRow(query, artifactID, jobID))
+}
+
+// SetPinned pins or unpins an artifact of a job. Returns sql.ErrNoRows if the
+// artifact does not exist or belongs to another job.
+func (r *ArtifactRepository) SetPinned(jobID string, artifactID int64, pinned bool) (*models.JobArtifact, error) {
+	query := `
+		UPDATE job_artifacts SET pinned = $1
+		WHERE id = $2 AND job_id = $3
+		RETURNING ` + artifactColumns
+	return scanArtifact(r.db.QueryRow(query, pinned, artifactID, jobID))
+}
 
-	_, err := r.db.Exec(query, jobID, artifactType, uri, metaJSON)
+// MarkDeleted records that an artifact's objects were deleted by retention GC
+func (r *ArtifactRepository) MarkDeleted(artifactID int64, reclaimedBytes int64) error {
+	_, err := r.db.Exec(`
+		UPDATE job_artifacts SET deleted_at = $3, reclaimed_bytes = $1
+		WHERE id = $2 AND deleted_at IS NULL
+	`, reclaimedBytes, artifactID, r.db.Now())
 	return err
 }
+
+// ListReplicationCandidates returns live checkpoints of jobs declaring
+// checkpointing whose replication is not settled: not seen by the replicator
+// yet, or pending a retry. Newest first, at most limit.
+func (r *ArtifactRepository) ListReplicationCandidates(limit int) ([]models.JobArtifact, error) {
+	rows, err := r.db.Query(`
+		SELECT `+artifactColumns+`
+		FROM job_artifacts a
+		WHERE type = 'checkpoint' AND deleted_at IS NULL
+			AND (replica_status IS NULL OR replica_status = 'pending')
+			AND EXISTS (SELECT 1 FROM jobs j WHERE j.id = a.job_id AND j.checkpointing_json IS NOT NULL)
+		ORDER BY created_at DESC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var artifacts []models.JobArtifact
+	for rows.Next() {
+		artifact, err := scanArtifact(rows)
+		if err != nil {
+			return nil, err
+		}
+		artifacts = append(artifacts, *artifact)
+	}
+	return artifacts, rows.Err()
+}
+
+// SetReplication records a checkpoint's replication status and attempts and
+// replaces its metadata, which carries the replica URI, lag and cost
+func (r *ArtifactRepository) SetReplication(artifactID int64, status string, attempts int, meta map[string]interface{}) error {
+	metaBytes, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(`
+		UPDATE job_artifacts SET replica_status = $2, replica_attempts = $3, meta_json = $4
+		WHERE id = $1
+	`, artifactID, status, attempts, string(metaBytes))
+	return err
+}
+
+// ListJobsWithLiveCheckpoints returns completed jobs that still have live checkpoints
+func (r *ArtifactRepository) ListJobsWithLiveCheckpoints() ([]string, error) {
+	rows, err := r.db.Query(`
+		SELECT DISTINCT a.job_id
+		FROM job_artifacts a
+		JOIN jobs j ON j.id = a.job_id
+		WHERE j.status = 'completed' AND a.type = 'checkpoint' AND a.deleted_at IS NULL
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobIDs []string
+	for rows.Next() {
+		var jobID string
+		if err := rows.Scan(&jobID); err != nil {
+			return nil, err
+		}
+		jobIDs = append(jobIDs, jobID)
+	}
+	return jobIDs, rows.Err()
+}
+
+// CreateGCRun inserts a retention GC run and sets its ID and start time
+func (r *ArtifactRepository) CreateGCRun(run *models.ArtifactGCRun) error {
+	return r.db.QueryRow(`
+		INSERT INTO artifact_gc_runs (dry_run) VALUES ($1)
+		RETURNING id, started_at
+	`, run.DryRun).Scan(&run.ID, &run.StartedAt)
+}
+
+// FinishGCRun stores the outcome of a retention GC run
+func (r *ArtifactRepository) FinishGCRun(run *models.ArtifactGCRun) error {
+	deletionsJSON, err := json.Marshal(run.Deletions)
+	if err != nil {
+		return fmt.Errorf("failed to encode gc deletions: %w", err)
+	}
+	_, err = r.db.Exec(`
+		UPDATE artifact_gc_runs
+		SET finished_at = $1, jobs_scanned = $2, bytes_reclaimed = $3, deletions_json = $4
+		WHERE id = $5
+	`, run.FinishedAt, run.JobsScanned, run.BytesReclaimed, string(deletionsJSON), run.ID)
+	return err
+}
+
+// GetGCRun retrieves a retention GC run report
+func (r *ArtifactRepository) GetGCRun(id int64) (*models.ArtifactGCRun, error) {
+	run := &models.ArtifactGCRun{}
+	var finishedAt sql.NullTime
+	var deletionsJSON string
+
+	err := r.db.QueryRow(`
+		SELECT id, started_at, finished_at, dry_run, jobs_scanned, bytes_reclaimed, deletions_json
+		FROM artifact_gc_runs
+		WHERE id = $1
+	`, id).Scan(&run.ID, &run.StartedAt, &finishedAt, &run.DryRun, &run.JobsScanned, &run.BytesReclaimed, &deletionsJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	if finishedAt.Valid {
+		run.FinishedAt = &finishedAt.Time
+	}
+	if err := json.Unmarshal([]byte(deletionsJSON), &run.Deletions); err != nil {
+		return nil, fmt.Errorf("failed to decode gc deletions for run %d: %w", id, err)
+	}
+	return run, nil
+}
+
+// ListGCRuns returns the most recent retention GC runs without their deletion lists
+func (r *ArtifactRepository) ListGCRuns(limit int) ([]models.ArtifactGCRun, error) {
+	rows, err := r.db.Query(`
+		SELECT id, started_at, finished_at, dry_run, jobs_scanned, bytes_reclaimed
+		FROM artifact_gc_runs
+		ORDER BY started_at DESC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []models.ArtifactGCRun
+	for rows.Next() {
+		var run models.ArtifactGCRun
+		var finishedAt sql.NullTime
+		if err := rows.Scan(&run.ID, &run.StartedAt, &finishedAt, &run.DryRun, &run.JobsScanned, &run.BytesReclaimed); err != nil {
+			return nil, err
+		}
+		if finishedAt.Valid {
+			run.FinishedAt = &finishedAt.Time
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gpu-orchestrator/core/models"
+)
+
+// AuditRepository stores the append-only audit log
+type AuditRepository struct {
+	db *DB
+}
+
+// NewAuditRepository creates a new audit repository
+func NewAuditRepository(db *DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Record appends an entry, stamped now unless CreatedAt is set
+func (r *AuditRepository) Record(entry *models.AuditEntry) error {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = r.db.Now()
+	}
+	return insertAuditEntry(r.db.Exec, entry)
+}
+
+// insertAuditEntry appends an entry through exec, so status changes are
+// audited in the transaction that makes them. The caller sets CreatedAt.
+func insertAuditEntry(exec func(query string, args ...interface{}) (sql.Result, error), entry *models.AuditEntry) error {
+	var request interface{}
+	if entry.Request != nil {
+		encoded, err := json.Marshal(entry.Request)
+		if err != nil {
+			return fmt.Errorf("failed to encode audit request: %w", err)
+		}
+		request = string(encoded)
+	}
+	_, err := exec(`
+		INSERT INTO audit_log (
+			actor, action, resource_type, resource_id, request_json, status_code, remote_addr, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`,
+		entry.Actor,
+		entry.Action,
+		nullString(entry.ResourceType),
+		nullString(entry.ResourceID),
+		request,
+		sql.NullInt64{Int64: int64(entry.StatusCode), Valid: entry.StatusCode != 0},
+		nullString(entry.RemoteAddr),
+		entry.CreatedAt,
+	)
+	return err
+}
+
+// ListEntries returns entries matching the filter, newest first
+func (r *AuditRepository) ListEntries(filter models.AuditFilter) ([]models.AuditEntry, error) {
+	query := `
+		SELECT id, actor, action, resource_type, resource_id, request_json, status_code, remote_addr, created_at
+		FROM audit_log
+		WHERE true
+	`
+	var args []interface{}
+	where := func(clause string, arg interface{}) {
+		args = append(args, arg)
+		query += fmt.Sprintf(" AND "+clause, len(args))
+	}
+	if filter.Actor != "" {
+		where("actor = $%d", filter.Actor)
+	}
+	if filter.ResourceType != "" {
+		where("resource_type = $%d", filter.ResourceType)
+	}
+	if filter.ResourceID != "" {
+		where("resource_id = $%d", filter.ResourceID)
+	}
+	if !filter.Since.IsZero() {
+		where("created_at >= $%d", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		where("created_at < $%d", filter.Until)
+	}
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d", len(args))
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.AuditEntry
+	for rows.Next() {
+		var e models.AuditEntry
+		var resourceType, resourceID, request, remoteAddr sql.NullString
+		var statusCode sql.NullInt64
+		if err := rows.Scan(
+			&e.ID, &e.Actor, &e.Action, &resourceType, &resourceID, &request, &statusCode, &remoteAddr, &e.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		e.ResourceType, e.ResourceID, e.RemoteAddr = resourceType.String, resourceID.String, remoteAddr.String
+		e.StatusCode = int(statusCode.Int64)
+		if request.Valid {
+			if err := json.Unmarshal([]byte(request.String), &e.Request); err != nil {
+				return nil, fmt.Errorf("failed to decode audit entry %d: %w", e.ID, err)
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// Prune deletes entries older than the retention cutoff and returns how
+// many were deleted. It is the only deletion the log allows.
+func (r *AuditRepository) Prune(before time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM audit_log WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"gpu-orchestrator/core/models"
+)
+
+// BenchmarkRepository stores throughput learned from jobs
+type BenchmarkRepository struct {
+	db *DB
+}
+
+// NewBenchmarkRepository creates a new benchmark repository
+func NewBenchmarkRepository(db *DB) *BenchmarkRepository {
+	return &BenchmarkRepository{db: db}
+}
+
+// ListBenchmarks returns every learned benchmark
+func (r *BenchmarkRepository) ListBenchmarks() ([]models.LearnedBenchmark, error) {
+	rows, err := r.db.Query(`
+		SELECT framework, gpu_type, model_class, steps_per_hour, samples,
+			tokens_per_hour, token_samples, updated_at
+		FROM performance_benchmarks
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var benchmarks []models.LearnedBenchmark
+	for rows.Next() {
+		var b models.LearnedBenchmark
+		if err := rows.Scan(&b.Framework, &b.GPUType, &b.ModelClass, &b.StepsPerHour, &b.Samples,
+			&b.TokensPerHour, &b.TokenSamples, &b.UpdatedAt); err != nil {
+			return nil, err
+		}
+		benchmarks = append(benchmarks, b)
+	}
+	return benchmarks, rows.Err()
+}
+
+// RecordBenchmarkSample folds a sample into the stored means in one
+// statement, as models.LearnedBenchmark.Add does, and returns the result
+func (r *BenchmarkRepository) RecordBenchmarkSample(sample models.BenchmarkSample, window int) (*models.LearnedBenchmark, error) {
+	tokenSamples := 0
+	if sample.TokensPerHour > 0 {
+		tokenSamples = 1
+	}
+
+	b := &models.LearnedBenchmark{Framework: sample.Framework, GPUType: sample.GPUType, ModelClass: sample.ModelClass}
+	err := r.db.QueryRow(`
+		INSERT INTO performance_benchmarks (
+			framework, gpu_type, model_class, steps_per_hour, samples,
+			tokens_per_hour, token_samples, updated_at
+		) VALUES ($1, $2, $3, $4, 1, $5, $6, $8)
+		ON CONFLICT (framework, gpu_type, model_class) DO UPDATE SET
+			steps_per_hour = performance_benchmarks.steps_per_hour
+				+ (EXCLUDED.steps_per_hour - performance_benchmarks.steps_per_hour)
+				/ LEAST(performance_benchmarks.samples + 1, $7),
+			samples = performance_benchmarks.samples + 1,
+			tokens_per_hour = CASE WHEN EXCLUDED.token_samples = 0 THEN performance_benchmarks.tokens_per_hour
+				ELSE performance_benchmarks.tokens_per_hour
+					+ (EXCLUDED.tokens_per_hour - performance_benchmarks.tokens_per_hour)
+					/ LEAST(performance_benchmarks.token_samples + 1, $7) END,
+			token_samples = performance_benchmarks.token_samples + EXCLUDED.token_samples,
+			updated_at = EXCLUDED.updated_at
+		RETURNING steps_per_hour, samples, tokens_per_hour, token_samples, updated_at
+	`, sample.Framework, sample.GPUType, sample.ModelClass, sample.StepsPerHour,
+		sample.TokensPerHour, tokenSamples, window, r.db.Now(),
+	).Scan(&b.StepsPerHour, &b.Samples, &b.TokensPerHour, &b.TokenSamples, &b.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
@@ -0,0 +1,362 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"gpu-orchestrator/core/models"
+)
+
+// BillingRepository reads billable usage for cost exports
+type BillingRepository struct {
+	db *DB
+}
+
+// NewBillingRepository creates a new billing repository
+func NewBillingRepository(db *DB) *BillingRepository {
+	return &BillingRepository{db: db}
+}
+
+// BillableAllocation is one allocation of a job with its run interval
+// clipped to the requested period
+type BillableAllocation struct {
+	JobID           string
+	JobName         string
+	TeamID          string
+	ProjectID       string
+	AllocationID    int64
+	Provider        models.Provider
+	Region          string
+	InstanceType    string
+	GPUType         string // Empty when the instance type is no longer priced
+	Spot            bool
+	Count           int
+	PricePerHour    float64
+	GPUsPerInstance int
+	GPUShare        float64 // Share of the instances' GPUs the job used; 0 = whole instances
+	From            time.Time
+	To              time.Time
+}
+
+// billableGPUColumns selects an allocation's GPU type, spot flag, count,
+// price, GPUs per instance and GPU share. GPUs per instance fall back to the
+// job's GPUs spread over the allocation when the instance type is no longer
+// priced.
+const billableGPUColumns = `COALESCE(
+				(SELECT p.gpu_type FROM gpu_pricing p
+				 WHERE p.provider = a.provider AND p.instance_type = a.instance_type
+				 LIMIT 1),
+				''
+			) AS gpu_type,
+			a.spot, a.count, a.price_per_hour,
+			COALESCE(
+				(SELECT p.gpus_per_instance FROM gpu_pricing p
+				 WHERE p.provider = a.provider AND p.instance_type = a.instance_type
+				 LIMIT 1),
+				(r.gpus + a.count - 1) / a.count
+			) AS gpus_per_instance,
+			a.gpu_share`
+
+// allocationBilledFrom starts an allocation's billing when its job first ran,
+// or when the allocation was created if later: jobs resumed after suspension
+// and elastic jobs add allocations while they run
+const allocationBilledFrom = `GREATEST(r.run_start, a.created_at)`
+
+// allocationBilledTo ends an allocation's billing when its job finished or
+// the allocation was terminated, whichever came first. now is the parameter
+// holding the current time.
+func allocationBilledTo(now string) string {
+	return `LEAST(COALESCE(r.run_end, ` + now + `), COALESCE(CASE WHEN a.status = 'terminated' THEN a.updated_at END, ` + now + `))`
+}
+
+// StreamBillableAllocations calls fn for every allocation of a job that ran
+// during [start, end), ordered by job and allocation. Rows are read from the
+// cursor one at a time so memory does not grow with the period size.
+//
+// A job runs from its first transition to running until its first terminal
+// transition (now while it is still running), as recorded in job_events. Its
+// allocations are billed over the part of the run they existed for.
+func (r *BillingRepository) StreamBillableAllocations(start, end time.Time, fn func(BillableAllocation) error) error {
+	query := `
+		WITH runs AS (
+			SELECT j.id, j.name, j.team_id, j.project_id, j.gpus,
+				(SELECT MIN(e.at) FROM job_events e
+				 WHERE e.job_id = j.id AND e.to_status = 'running') AS run_start,
+				(SELECT MIN(e.at) FROM job_events e
+				 WHERE e.job_id = j.id AND e.to_status IN ('completed', 'failed', 'cancelled')) AS run_end
+			FROM jobs j
+		)
+		SELECT r.id, r.name, r.team_id, r.project_id,
+			a.id, a.provider, a.region, a.instance_type, ` + billableGPUColumns + `,
+			GREATEST(` + allocationBilledFrom + `, $1) AS billed_from,
+			LEAST(` + allocationBilledTo("$3") + `, $2) AS billed_to
+		FROM runs r
+		JOIN allocations a ON a.job_id = r.id
+		WHERE r.run_start IS NOT NULL
+			AND ` + allocationBilledFrom + ` < $2
+			AND ` + allocationBilledTo("$3") + ` > $1
+			AND ` + allocationBilledFrom + ` < ` + allocationBilledTo("$3") + `
+		ORDER BY r.id, a.id
+	`
+
+	rows, err := r.db.Query(query, start, end, r.db.Now())
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		alloc, err := scanBillableAllocation(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(alloc); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// JobBillableAllocations returns the allocations of one job over its whole
+// run, ordered by allocation; none when the job never ran
+func (r *BillingRepository) JobBillableAllocations(jobID string) ([]BillableAllocation, error) {
+	query := `
+		WITH runs AS (
+			SELECT j.id, j.name, j.team_id, j.project_id, j.gpus,
+				(SELECT MIN(e.at) FROM job_events e
+				 WHERE e.job_id = j.id AND e.to_status = 'running') AS run_start,
+				(SELECT MIN(e.at) FROM job_events e
+				 WHERE e.job_id = j.id AND e.to_status IN ('completed', 'failed', 'cancelled')) AS run_end
+			FROM jobs j
+			WHERE j.id = $1
+		)
+		SELECT r.id, r.name, r.team_id, r.project_id,
+			a.id, a.provider, a.region, a.instance_type, ` + billableGPUColumns + `,
+			` + allocationBilledFrom + ` AS billed_from,
+			` + allocationBilledTo("$2") + ` AS billed_to
+		FROM runs r
+		JOIN allocations a ON a.job_id = r.id
+		WHERE r.run_start IS NOT NULL
+			AND ` + allocationBilledFrom + ` < ` + allocationBilledTo("$2") + `
+		ORDER BY a.id
+	`
+
+	rows, err := r.db.Query(query, jobID, r.db.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var allocations []BillableAllocation
+	for rows.Next() {
+		alloc, err := scanBillableAllocation(rows)
+		if err != nil {
+			return nil, err
+		}
+		allocations = append(allocations, alloc)
+	}
+	return allocations, rows.Err()
+}
+
+// scanBillableAllocation scans a row selected by the billable allocation
+// queries; extra receives the columns that follow
+func scanBillableAllocation(row rowScanner, extra ...interface{}) (BillableAllocation, error) {
+	var alloc BillableAllocation
+	var teamID, projectID sql.NullString
+	dest := []interface{}{
+		&alloc.JobID,
+		&alloc.JobName,
+		&teamID,
+		&projectID,
+		&alloc.AllocationID,
+		&alloc.Provider,
+		&alloc.Region,
+		&alloc.InstanceType,
+		&alloc.GPUType,
+		&alloc.Spot,
+		&alloc.Count,
+		&alloc.PricePerHour,
+		&alloc.GPUsPerInstance,
+		&alloc.GPUShare,
+		&alloc.From,
+		&alloc.To,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	alloc.TeamID = teamID.String
+	alloc.ProjectID = projectID.String
+	return alloc, err
+}
+
+// InstanceInterval is when one instance ran, clipped to the requested period
+type InstanceInterval struct {
+	InstanceID string
+	From       time.Time
+	To         time.Time
+	Open       bool // Not recorded as terminated; To is now or the period end
+}
+
+// BillableUsage is one allocation of a job next to the instances launched
+// for it. The allocation is billed over the part of the job's run it existed
+// for (Compute), the instances from launch to termination. Instances whose allocation row
+// is gone (requeued jobs, elastic growth) form groups of their own, with
+// the allocation fields taken from the instances and Compute false.
+type BillableUsage struct {
+	BillableAllocation
+	Compute   bool
+	Instances []InstanceInterval
+}
+
+// billableUsageQuery selects the allocation rows of StreamBillableAllocations
+// and the instance rows of job_instances overlapping [$1, $2), one group per
+// job, allocation and instance type. Runs and instances still going end at
+// $3, the current time. jobFilter restricts both halves to the jobs it
+// matches by j.id.
+func billableUsageQuery(jobFilter string) string {
+	return `
+		WITH runs AS (
+			SELECT j.id, j.name, j.team_id, j.project_id, j.gpus,
+				(SELECT MIN(e.at) FROM job_events e
+				 WHERE e.job_id = j.id AND e.to_status = 'running') AS run_start,
+				(SELECT MIN(e.at) FROM job_events e
+				 WHERE e.job_id = j.id AND e.to_status IN ('completed', 'failed', 'cancelled')) AS run_end
+			FROM jobs j
+			WHERE TRUE` + jobFilter + `
+		)
+		SELECT * FROM (
+			SELECT r.id AS job_id, r.name AS job_name, r.team_id, r.project_id,
+				a.id AS allocation_id, a.provider, a.region, a.instance_type, ` + billableGPUColumns + `,
+				GREATEST(` + allocationBilledFrom + `, $1) AS billed_from,
+				LEAST(` + allocationBilledTo("$3") + `, $2) AS billed_to,
+				'' AS instance_id, FALSE AS open, 0 AS kind
+			FROM runs r
+			JOIN allocations a ON a.job_id = r.id
+			WHERE r.run_start IS NOT NULL
+				AND ` + allocationBilledFrom + ` < $2
+				AND ` + allocationBilledTo("$3") + ` > $1
+				AND ` + allocationBilledFrom + ` < ` + allocationBilledTo("$3") + `
+			UNION ALL
+			SELECT j.id, j.name, j.team_id, j.project_id,
+				COALESCE(i.allocation_id, 0), i.provider, i.region, i.instance_type,
+				COALESCE(
+					(SELECT p.gpu_type FROM gpu_pricing p
+					 WHERE p.provider = i.provider AND p.instance_type = i.instance_type
+					 LIMIT 1),
+					''
+				),
+				i.spot, 1, i.price_per_hour,
+				COALESCE(
+					(SELECT p.gpus_per_instance FROM gpu_pricing p
+					 WHERE p.provider = i.provider AND p.instance_type = i.instance_type
+					 LIMIT 1),
+					0
+				),
+				COALESCE((SELECT s.gpu_share FROM allocations s WHERE s.id = i.allocation_id), 0),
+				GREATEST(i.launched_at, $1),
+				LEAST(COALESCE(i.terminated_at, $3), $2),
+				i.instance_id, i.terminated_at IS NULL, 1
+			FROM job_instances i
+			JOIN jobs j ON j.id = i.job_id
+			WHERE i.launched_at < $2
+				AND COALESCE(i.terminated_at, $3) > $1` + jobFilter + `
+		) billable
+		ORDER BY job_id, allocation_id, provider, region, instance_type, kind, instance_id
+	`
+}
+
+// StreamBillableUsage calls fn for every allocation and instance group of a
+// job that ran during [start, end), ordered by job and allocation. Rows are
+// read from the cursor one at a time; only one group's instances are held
+// in memory.
+func (r *BillingRepository) StreamBillableUsage(start, end time.Time, fn func(BillableUsage) error) error {
+	return r.streamBillableUsage(billableUsageQuery(""), fn, start, end, r.db.Now())
+}
+
+// JobBillableUsage returns the allocation and instance groups of one job
+// over its whole life, ordered by allocation
+func (r *BillingRepository) JobBillableUsage(jobID string) ([]BillableUsage, error) {
+	var usage []BillableUsage
+	now := r.db.Now()
+	err := r.streamBillableUsage(billableUsageQuery(" AND j.id = $4"), func(group BillableUsage) error {
+		usage = append(usage, group)
+		return nil
+	}, time.Unix(0, 0), now, now, jobID)
+	return usage, err
+}
+
+// JobAllocationTaskGroups returns the task group of each of a job's
+// allocations that runs one, by allocation ID
+func (r *BillingRepository) JobAllocationTaskGroups(jobID string) (map[int64]string, error) {
+	rows, err := r.db.Query(`
+		SELECT id, task_group FROM allocations
+		WHERE job_id = $1 AND task_group IS NOT NULL
+	`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := make(map[int64]string)
+	for rows.Next() {
+		var id int64
+		var group string
+		if err := rows.Scan(&id, &group); err != nil {
+			return nil, err
+		}
+		groups[id] = group
+	}
+	return groups, rows.Err()
+}
+
+// streamBillableUsage groups consecutive rows of a billable usage query
+func (r *BillingRepository) streamBillableUsage(query string, fn func(BillableUsage) error, args ...interface{}) error {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var group *BillableUsage
+	for rows.Next() {
+		var instanceID string
+		var open bool
+		var kind int
+		alloc, err := scanBillableAllocation(rows, &instanceID, &open, &kind)
+		if err != nil {
+			return err
+		}
+
+		if group != nil && !sameUsageGroup(group.BillableAllocation, alloc) {
+			if err := fn(*group); err != nil {
+				return err
+			}
+			group = nil
+		}
+		if group == nil {
+			group = &BillableUsage{BillableAllocation: alloc}
+			if kind == 1 {
+				group.Count = 0 // Counted from the instances below
+			}
+		}
+		if kind == 0 {
+			group.Compute = true
+			continue
+		}
+		group.Instances = append(group.Instances, InstanceInterval{InstanceID: instanceID, From: alloc.From, To: alloc.To, Open: open})
+		if !group.Compute {
+			group.Count++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if group != nil {
+		return fn(*group)
+	}
+	return nil
+}
+
+// sameUsageGroup reports whether two usage rows belong to the same group
+func sameUsageGroup(a, b BillableAllocation) bool {
+	return a.JobID == b.JobID && a.AllocationID == b.AllocationID &&
+		a.Provider == b.Provider && a.Region == b.Region && a.InstanceType == b.InstanceType
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gpu-orchestrator/core/models"
+)
+
+// ConsistencyRepository records consistency check reports
+type ConsistencyRepository struct {
+	db *DB
+}
+
+// NewConsistencyRepository creates a new consistency repository
+func NewConsistencyRepository(db *DB) *ConsistencyRepository {
+	return &ConsistencyRepository{db: db}
+}
+
+// CreateReport inserts a finished report and sets its ID
+func (r *ConsistencyRepository) CreateReport(report *models.ConsistencyReport) error {
+	countsJSON, err := json.Marshal(report.Counts)
+	if err != nil {
+		return fmt.Errorf("failed to encode discrepancy counts: %w", err)
+	}
+	discrepanciesJSON, err := json.Marshal(report.Discrepancies)
+	if err != nil {
+		return fmt.Errorf("failed to encode discrepancies: %w", err)
+	}
+	skippedJSON, err := json.Marshal(report.Skipped)
+	if err != nil {
+		return fmt.Errorf("failed to encode skipped checks: %w", err)
+	}
+	return r.db.QueryRow(`
+		INSERT INTO consistency_reports (started_at, finished_at, counts_json, discrepancies_json, skipped_json)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, report.StartedAt, report.FinishedAt, string(countsJSON), string(discrepanciesJSON), string(skippedJSON)).Scan(&report.ID)
+}
+
+// LatestReport returns the most recent report; sql.ErrNoRows when no check
+// has run yet
+func (r *ConsistencyRepository) LatestReport() (*models.ConsistencyReport, error) {
+	report := &models.ConsistencyReport{}
+	var countsJSON, discrepanciesJSON, skippedJSON string
+
+	err := r.db.QueryRow(`
+		SELECT id, started_at, finished_at, counts_json, discrepancies_json, skipped_json
+		FROM consistency_reports
+		ORDER BY started_at DESC
+		LIMIT 1
+	`).Scan(&report.ID, &report.StartedAt, &report.FinishedAt, &countsJSON, &discrepanciesJSON, &skippedJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(countsJSON), &report.Counts); err != nil {
+		return nil, fmt.Errorf("failed to decode counts of consistency report %d: %w", report.ID, err)
+	}
+	if err := json.Unmarshal([]byte(discrepanciesJSON), &report.Discrepancies); err != nil {
+		return nil, fmt.Errorf("failed to decode discrepancies of consistency report %d: %w", report.ID, err)
+	}
+	if err := json.Unmarshal([]byte(skippedJSON), &report.Skipped); err != nil {
+		return nil, fmt.Errorf("failed to decode skipped checks of consistency report %d: %w", report.ID, err)
+	}
+	return report, nil
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"gpu-orchestrator/core/models"
+)
+
+// DataGravityRepository reads where a team's data lives
+type DataGravityRepository struct {
+	db *DB
+}
+
+// NewDataGravityRepository creates a new data gravity repository
+func NewDataGravityRepository(db *DB) *DataGravityRepository {
+	return &DataGravityRepository{db: db}
+}
+
+// ListTeamDataFootprints returns the datasets of the team's jobs created
+// since the given time, its live checkpoints saved since then (in the region
+// their job ran in) and its jobs completed since then
+func (r *DataGravityRepository) ListTeamDataFootprints(teamID string, since time.Time) ([]models.DataFootprint, error) {
+	var footprints []models.DataFootprint
+
+	// Datasets
+	rows, err := r.db.Query(`
+		SELECT dataset_uri, COALESCE(dataset_size_gb, 0), created_at
+		FROM jobs
+		WHERE team_id = $1 AND created_at >= $2 AND dataset_uri <> ''
+	`, teamID, since)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		footprint := models.DataFootprint{Kind: models.FootprintDataset}
+		if err := rows.Scan(&footprint.URI, &footprint.SizeGB, &footprint.At); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		footprints = append(footprints, footprint)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Live checkpoints; their size is recorded in the artifact meta
+	rows, err = r.db.Query(`
+		SELECT j.selected_provider, j.selected_region, a.meta_json, a.created_at
+		FROM job_artifacts a
+		JOIN jobs j ON j.id = a.job_id
+		WHERE j.team_id = $1 AND a.type = 'checkpoint' AND a.deleted_at IS NULL
+			AND a.created_at >= $2 AND j.selected_region IS NOT NULL
+	`, teamID, since)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		footprint := models.DataFootprint{Kind: models.FootprintCheckpoint}
+		var provider sql.NullString
+		var metaJSON string
+		if err := rows.Scan(&provider, &footprint.Region, &metaJSON, &footprint.At); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		footprint.Provider = models.Provider(provider.String)
+		var meta map[string]interface{}
+		if json.Unmarshal([]byte(metaJSON), &meta) == nil {
+			if size, ok := meta["size_bytes"].(float64); ok {
+				footprint.SizeGB = size / (1 << 30)
+			}
+		}
+		footprints = append(footprints, footprint)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Completed jobs, sized by the dataset they staged
+	rows, err = r.db.Query(`
+		SELECT selected_provider, selected_region, COALESCE(dataset_size_gb, 0), updated_at
+		FROM jobs
+		WHERE team_id = $1 AND status = 'completed' AND updated_at >= $2
+			AND selected_region IS NOT NULL
+	`, teamID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		footprint := models.DataFootprint{Kind: models.FootprintJob}
+		var provider sql.NullString
+		if err := rows.Scan(&provider, &footprint.Region, &footprint.SizeGB, &footprint.At); err != nil {
+			return nil, err
+		}
+		footprint.Provider = models.Provider(provider.String)
+		footprints = append(footprints, footprint)
+	}
+	return footprints, rows.Err()
+}
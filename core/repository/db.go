@@ -1,20 +1,39 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"slices"
+	"time"
+
+	"gpu-orchestrator/core/clock"
+	"gpu-orchestrator/migrations"
 
 	_ "github.com/lib/pq" // PostgreSQL driver
 )
 
-// DB wraps the database connection
+// DB wraps the database connection and rebinds queries for its dialect.
+// Repositories take the timestamps they write from its clock rather than the
+// database's NOW(), so rows written together carry the same instant. Leader
+// leases are the exception: they expire on the database clock so replicas
+// with skewed clocks agree.
 type DB struct {
 	*sql.DB
+	dialect Dialect
+	clock   clock.Clock
 }
 
-// NewDB creates a new database connection
+// NewDB creates a new database connection. URLs starting with sqlite: open
+// a SQLite file (single-node/dev mode) and create the schema if needed; the
+// driver is only linked into builds with the sqlite tag.
 func NewDB(databaseURL string) (*DB, error) {
-	db, err := sql.Open("postgres", databaseURL)
+	dialect, dsn := dialectForURL(databaseURL)
+	if dialect == DialectSQLite && !slices.Contains(sql.Drivers(), "sqlite") {
+		return nil, fmt.Errorf("SQLite support is not built in; rebuild with -tags sqlite")
+	}
+
+	db, err := sql.Open(string(dialect), dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -23,10 +42,83 @@ func NewDB(databaseURL string) (*DB, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return &DB{db}, nil
+	if dialect == DialectSQLite {
+		// One connection serializes transactions in place of row locks
+		db.SetMaxOpenConns(1)
+		if _, err := db.Exec(migrations.SQLiteSchema); err != nil {
+			return nil, fmt.Errorf("failed to create SQLite schema: %w", err)
+		}
+	}
+
+	return &DB{DB: db, dialect: dialect, clock: clock.System}, nil
+}
+
+// SetClock replaces the clock repositories stamp rows with
+func (db *DB) SetClock(c clock.Clock) {
+	db.clock = c
+}
+
+// Now returns the current time of the DB's clock, in UTC
+func (db *DB) Now() time.Time {
+	return db.clock.Now()
+}
+
+// Dialect returns the SQL dialect of the connection
+func (db *DB) Dialect() Dialect {
+	return db.dialect
+}
+
+// Exec executes a query without returning rows
+func (db *DB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return db.DB.Exec(db.dialect.Rebind(query), args...)
+}
+
+// Query executes a query that returns rows
+func (db *DB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return db.DB.Query(db.dialect.Rebind(query), args...)
+}
+
+// QueryContext executes a query that returns rows
+func (db *DB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return db.DB.QueryContext(ctx, db.dialect.Rebind(query), args...)
+}
+
+// QueryRow executes a query that returns at most one row
+func (db *DB) QueryRow(query string, args ...interface{}) *sql.Row {
+	return db.DB.QueryRow(db.dialect.Rebind(query), args...)
+}
+
+// Begin starts a transaction
+func (db *DB) Begin() (*Tx, error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx, dialect: db.dialect}, nil
 }
 
 // Close closes the database connection
 func (db *DB) Close() error {
 	return db.DB.Close()
 }
+
+// Tx wraps a transaction and rebinds queries for its dialect
+type Tx struct {
+	*sql.Tx
+	dialect Dialect
+}
+
+// Exec executes a query without returning rows
+func (tx *Tx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return tx.Tx.Exec(tx.dialect.Rebind(query), args...)
+}
+
+// Query executes a query that returns rows
+func (tx *Tx) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return tx.Tx.Query(tx.dialect.Rebind(query), args...)
+}
+
+// QueryRow executes a query that returns at most one row
+func (tx *Tx) QueryRow(query string, args ...interface{}) *sql.Row {
+	return tx.Tx.QueryRow(tx.dialect.Rebind(query), args...)
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Dialect is the SQL flavour of the connected database. Queries are written
+// for PostgreSQL ($n placeholders, NOW(), FOR UPDATE) and rebound for SQLite
+// by the DB and Tx wrappers. ON CONFLICT … DO UPDATE and RETURNING are shared
+// by both and need no rewriting.
+type Dialect string
+
+const (
+	DialectPostgres Dialect = "postgres"
+	DialectSQLite   Dialect = "sqlite"
+)
+
+var (
+	placeholderPattern = regexp.MustCompile(`\$(\d+)`)
+	nowPattern         = regexp.MustCompile(`(?i)\bnow\(\)`)
+	forUpdatePattern   = regexp.MustCompile(`(?i)\s+FOR\s+UPDATE\b`)
+	greatestPattern    = regexp.MustCompile(`(?i)\bGREATEST\(`)
+	leastPattern       = regexp.MustCompile(`(?i)\bLEAST\(`)
+)
+
+// Rebind rewrites a PostgreSQL query for the dialect. For SQLite, $n becomes
+// ?n, NOW() the current timestamp, GREATEST/LEAST the multi-argument MAX/MIN,
+// and FOR UPDATE is dropped: SQLite connections are limited to one, so
+// transactions are already serialized.
+func (d Dialect) Rebind(query string) string {
+	if d != DialectSQLite {
+		return query
+	}
+	query = placeholderPattern.ReplaceAllString(query, "?$1")
+	query = nowPattern.ReplaceAllString(query, d.Now())
+	query = forUpdatePattern.ReplaceAllString(query, "")
+	query = greatestPattern.ReplaceAllString(query, "MAX(")
+	return leastPattern.ReplaceAllString(query, "MIN(")
+}
+
+// Now returns the dialect's current-timestamp expression
+func (d Dialect) Now() string {
+	if d == DialectSQLite {
+		return "CURRENT_TIMESTAMP"
+	}
+	return "NOW()"
+}
+
+// dialectForURL picks the dialect from the database URL scheme and returns
+// the driver data source name
+func dialectForURL(databaseURL string) (Dialect, string) {
+	rest, ok := strings.CutPrefix(databaseURL, "sqlite:")
+	if !ok {
+		return DialectPostgres, databaseURL
+	}
+	// sqlite://gpu.db and sqlite:///var/lib/gpu.db name a file path
+	path := strings.TrimPrefix(rest, "//")
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	return DialectSQLite, path + separator + "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_time_format=sqlite"
+}
+
+// inPlaceholders returns "$start, …, $start+n-1" for an IN list
+func inPlaceholders(start, n int) string {
+	placeholders := make([]string, n)
+	for i := range placeholders {
+		placeholders[i] = "$" + strconv.Itoa(start+i)
+	}
+	return strings.Join(placeholders, ", ")
+}
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"gpu-orchestrator/core/models"
+)
+
+// EgressRepository stores the egress counters of job nodes and the egress
+// charged to jobs per day
+type EgressRepository struct {
+	db *DB
+}
+
+// NewEgressRepository creates a new egress repository
+func NewEgressRepository(db *DB) *EgressRepository {
+	return &EgressRepository{db: db}
+}
+
+// RecordCounter stores a node's latest counter value and returns the bytes
+// sent since the previous sample
+func (r *EgressRepository) RecordCounter(jobID, nodeID string, counter int64, source string) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var previous int64
+	var previousSource string
+	err = tx.QueryRow(`
+		SELECT counter_bytes, source FROM job_egress_counters
+		WHERE job_id = $1 AND node_id = $2
+	`, jobID, nodeID).Scan(&previous, &previousSource)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+	delta := counterDelta(previous, counter, err == nil && previousSource == source)
+
+	if _, err := tx.Exec(`
+		INSERT INTO job_egress_counters (job_id, node_id, counter_bytes, source, sampled_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (job_id, node_id) DO UPDATE SET
+			counter_bytes = EXCLUDED.counter_bytes,
+			source = EXCLUDED.source,
+			sampled_at = EXCLUDED.sampled_at
+	`, jobID, nodeID, counter, source, r.db.Now()); err != nil {
+		return 0, err
+	}
+	return delta, tx.Commit()
+}
+
+// counterDelta returns the bytes a counter advanced by. A counter seen for
+// the first time, or lower than before because the node rebooted or the
+// counter was reinstalled, counts from zero.
+func counterDelta(previous, current int64, seen bool) int64 {
+	if !seen || current < previous {
+		return current
+	}
+	return current - previous
+}
+
+// AddEgress charges egress to a job's day and adds it to the job's totals
+func (r *EgressRepository) AddEgress(usage models.EgressUsage) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		INSERT INTO job_egress_daily (job_id, day, provider, region, bytes, cost_usd)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (job_id, day, provider, region) DO UPDATE SET
+			bytes = job_egress_daily.bytes + EXCLUDED.bytes,
+			cost_usd = job_egress_daily.cost_usd + EXCLUDED.cost_usd
+	`, usage.JobID, usage.Day, usage.Provider, usage.Region, usage.Bytes, usage.CostUSD); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		UPDATE jobs SET
+			egress_bytes = egress_bytes + $2,
+			cost_egress_usd = cost_egress_usd + $3,
+			updated_at = $4
+		WHERE id = $1
+	`, usage.JobID, usage.Bytes, usage.CostUSD, r.db.Now()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// JobEgress returns a job's egress so far, one entry per provider and region
+// with Day unset
+func (r *EgressRepository) JobEgress(jobID string) ([]models.EgressUsage, error) {
+	rows, err := r.db.Query(`
+		SELECT provider, region, SUM(bytes), SUM(cost_usd)
+		FROM job_egress_daily
+		WHERE job_id = $1
+		GROUP BY provider, region
+		ORDER BY provider, region
+	`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var usage []models.EgressUsage
+	for rows.Next() {
+		entry := models.EgressUsage{JobID: jobID}
+		if err := rows.Scan(&entry.Provider, &entry.Region, &entry.Bytes, &entry.CostUSD); err != nil {
+			return nil, err
+		}
+		usage = append(usage, entry)
+	}
+	return usage, rows.Err()
+}
+
+// MarkEgressAlerted records that a job's egress alert fired. It returns false
+// when it had fired already.
+func (r *EgressRepository) MarkEgressAlerted(jobID string) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE jobs SET egress_alerted_at = $2
+		WHERE id = $1 AND egress_alerted_at IS NULL
+	`, jobID, r.db.Now())
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// BillableEgress is a job's egress on one day with the job's attribution
+type BillableEgress struct {
+	models.EgressUsage
+	JobName   string
+	TeamID    string
+	ProjectID string
+}
+
+// StreamBillableEgress calls fn for every job-region-day of egress in
+// [start, end), ordered by day and job
+func (r *EgressRepository) StreamBillableEgress(start, end time.Time, fn func(BillableEgress) error) error {
+	rows, err := r.db.Query(`
+		SELECT e.job_id, e.day, e.provider, e.region, e.bytes, e.cost_usd,
+			j.name, j.team_id, j.project_id
+		FROM job_egress_daily e
+		JOIN jobs j ON j.id = e.job_id
+		WHERE e.day >= $1 AND e.day < $2
+		ORDER BY e.day, e.job_id, e.provider, e.region
+	`, start, end)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var egress BillableEgress
+		var teamID, projectID sql.NullString
+		if err := rows.Scan(
+			&egress.JobID,
+			&egress.Day,
+			&egress.Provider,
+			&egress.Region,
+			&egress.Bytes,
+			&egress.CostUSD,
+			&egress.JobName,
+			&teamID,
+			&projectID,
+		); err != nil {
+			return err
+		}
+		egress.TeamID = teamID.String
+		egress.ProjectID = projectID.String
+		if err := fn(egress); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -3,6 +3,8 @@ package repository
 import (
 	"database/sql"
 	"encoding/json"
+	"strings"
+	"time"
 
 	"gpu-orchestrator/core/models"
 )
@@ -20,7 +22,7 @@ func NewEventRepository(db *DB) *EventRepository {
 // GetJobEvents retrieves events for a job
 func (r *EventRepository) GetJobEvents(jobID string, limit int) ([]models.JobEvent, error) {
 	query := `
-		SELECT id, job_id, at, from_status, to_status, reason, meta_json
+		SELECT ` + eventColumns + `
 		FROM job_events
 		WHERE job_id = $1
 		ORDER BY at DESC
@@ -35,35 +37,96 @@ func (r *EventRepository) GetJobEvents(jobID string, limit int) ([]models.JobEve
 
 	var events []models.JobEvent
 	for rows.Next() {
-		var event models.JobEvent
-		var fromStatus sql.NullString
-		var metaJSON string
-
-		err := rows.Scan(
-			&event.ID,
-			&event.JobID,
-			&event.At,
-			&fromStatus,
-			&event.ToStatus,
-			&event.Reason,
-			&metaJSON,
-		)
+		event, err := scanEvent(rows)
 		if err != nil {
 			continue
 		}
+		events = append(events, event)
+	}
 
-		if fromStatus.Valid {
-			status := models.JobStatus(fromStatus.String)
-			event.FromStatus = &status
-		}
+	return events, nil
+}
 
-		// Parse meta JSON
-		if metaJSON != "" {
-			json.Unmarshal([]byte(metaJSON), &event.MetaJSON)
-		}
+// ListPendingPostmortems returns failure events since the given time whose
+// job is still failed and that link no post-mortem bundle yet, oldest first
+func (r *EventRepository) ListPendingPostmortems(since time.Time, limit int) ([]models.JobEvent, error) {
+	rows, err := r.db.Query(`
+		SELECT `+eventColumnsOf("e")+`
+		FROM job_events e
+		JOIN jobs j ON j.id = e.job_id
+		WHERE e.to_status = 'failed' AND e.artifact_id IS NULL
+			AND COALESCE(e.from_status, '') <> 'failed'
+			AND j.status = 'failed' AND e.at >= $1
+		ORDER BY e.at
+		LIMIT $2
+	`, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
+	var events []models.JobEvent
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
 		events = append(events, event)
 	}
+	return events, rows.Err()
+}
 
-	return events, nil
+// LinkArtifact links an event to an artifact
+func (r *EventRepository) LinkArtifact(eventID, artifactID int64) error {
+	_, err := r.db.Exec(`UPDATE job_events SET artifact_id = $1 WHERE id = $2`, artifactID, eventID)
+	return err
+}
+
+// eventColumns is the select list scanned by scanEvent
+const eventColumns = `id, job_id, at, from_status, to_status, reason, meta_json, artifact_id`
+
+// eventColumnsOf is eventColumns qualified with a table alias
+func eventColumnsOf(alias string) string {
+	columns := strings.Split(eventColumns, ", ")
+	for i, column := range columns {
+		columns[i] = alias + "." + column
+	}
+	return strings.Join(columns, ", ")
+}
+
+// scanEvent scans one row selected with eventColumns
+func scanEvent(row rowScanner) (models.JobEvent, error) {
+	var event models.JobEvent
+	var fromStatus, reason sql.NullString
+	var metaJSON string
+	var artifactID sql.NullInt64
+
+	err := row.Scan(
+		&event.ID,
+		&event.JobID,
+		&event.At,
+		&fromStatus,
+		&event.ToStatus,
+		&reason,
+		&metaJSON,
+		&artifactID,
+	)
+	if err != nil {
+		return event, err
+	}
+
+	if fromStatus.Valid {
+		status := models.JobStatus(fromStatus.String)
+		event.FromStatus = &status
+	}
+	event.Reason = reason.String
+	if artifactID.Valid {
+		event.ArtifactID = &artifactID.Int64
+	}
+
+	// Parse meta JSON
+	if metaJSON != "" {
+		json.Unmarshal([]byte(metaJSON), &event.MetaJSON)
+	}
+	return event, nil
 }
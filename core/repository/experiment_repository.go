@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"gpu-orchestrator/core/models"
+
+	"github.com/google/uuid"
+)
+
+// ErrExperimentExists is returned when creating an experiment whose name is taken
+var ErrExperimentExists = errors.New("experiment name already exists")
+
+// ExperimentRepository handles database operations for experiments
+type ExperimentRepository struct {
+	db *DB
+}
+
+// NewExperimentRepository creates a new experiment repository
+func NewExperimentRepository(db *DB) *ExperimentRepository {
+	return &ExperimentRepository{db: db}
+}
+
+// Create inserts an experiment and sets its ID and creation time. Returns
+// ErrExperimentExists if the name is taken.
+func (r *ExperimentRepository) Create(experiment *models.Experiment) error {
+	labels := experiment.Labels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labelsJSON, err := json.Marshal(labels)
+	if err != nil {
+		return fmt.Errorf("failed to encode labels: %w", err)
+	}
+
+	err = r.db.QueryRow(`
+		INSERT INTO experiments (id, name, description, labels_json) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (name) DO NOTHING
+		RETURNING id, created_at
+	`, uuid.New().String(), experiment.Name, experiment.Description, string(labelsJSON)).Scan(&experiment.ID, &experiment.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrExperimentExists
+	}
+	return err
+}
+
+// Resolve returns the experiment with the given ID or, failing that, name.
+// Returns sql.ErrNoRows if neither matches.
+func (r *ExperimentRepository) Resolve(nameOrID string) (*models.Experiment, error) {
+	if _, err := uuid.Parse(nameOrID); err == nil {
+		experiment, err := r.get(`WHERE id = $1`, nameOrID)
+		if !errors.Is(err, sql.ErrNoRows) {
+			return experiment, err
+		}
+	}
+	return r.get(`WHERE name = $1`, nameOrID)
+}
+
+// List returns every experiment, newest first
+func (r *ExperimentRepository) List() ([]models.Experiment, error) {
+	rows, err := r.db.Query(`
+		SELECT id, name, description, labels_json, created_at
+		FROM experiments
+		ORDER BY created_at DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var experiments []models.Experiment
+	for rows.Next() {
+		experiment, err := scanExperiment(rows)
+		if err != nil {
+			return nil, err
+		}
+		experiments = append(experiments, *experiment)
+	}
+	return experiments, rows.Err()
+}
+
+// Delete deletes an experiment and detaches its jobs, which are kept.
+// Returns sql.ErrNoRows if it does not exist.
+func (r *ExperimentRepository) Delete(id string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE jobs SET experiment_id = NULL, updated_at = $2 WHERE experiment_id = $1`, id, r.db.Now()); err != nil {
+		return fmt.Errorf("failed to detach jobs: %w", err)
+	}
+	result, err := tx.Exec(`DELETE FROM experiments WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return tx.Commit()
+}
+
+// ListMembers returns the experiment's jobs, oldest first. A job runs from
+// its first transition to running until its first terminal transition, as
+// recorded in job_events. Cost is the tracked running cost, or the estimate
+// for completed jobs that were never tracked.
+func (r *ExperimentRepository) ListMembers(experimentID string) ([]models.ExperimentMember, error) {
+	rows, err := r.db.Query(`
+		SELECT j.id, j.name, j.status, j.gpus,
+			CASE WHEN j.cost_running_usd = 0 AND j.status = 'completed'
+				THEN COALESCE(j.cost_estimated_usd, 0) ELSE j.cost_running_usd END,
+			(SELECT MIN(e.at) FROM job_events e
+			 WHERE e.job_id = j.id AND e.to_status = 'running'),
+			(SELECT MIN(e.at) FROM job_events e
+			 WHERE e.job_id = j.id AND e.to_status IN ('completed', 'failed', 'cancelled'))
+		FROM jobs j
+		WHERE j.experiment_id = $1
+		ORDER BY j.created_at
+	`, experimentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []models.ExperimentMember
+	for rows.Next() {
+		var member models.ExperimentMember
+		var startedAt, finishedAt sql.NullTime
+		if err := rows.Scan(&member.JobID, &member.Name, &member.Status, &member.GPUs, &member.CostUSD, &startedAt, &finishedAt); err != nil {
+			return nil, err
+		}
+		if startedAt.Valid {
+			member.StartedAt = &startedAt.Time
+		}
+		if finishedAt.Valid {
+			member.FinishedAt = &finishedAt.Time
+		}
+		member.Link = "/v1/jobs/" + member.JobID
+		members = append(members, member)
+	}
+	return members, rows.Err()
+}
+
+// CostRollup returns the job count and cost of every experiment, costliest first
+func (r *ExperimentRepository) CostRollup() ([]models.ExperimentCost, error) {
+	rows, err := r.db.Query(`
+		SELECT x.id, x.name, COUNT(j.id),
+			COALESCE(SUM(CASE WHEN j.cost_running_usd = 0 AND j.status = 'completed'
+				THEN COALESCE(j.cost_estimated_usd, 0) ELSE j.cost_running_usd END), 0) AS cost
+		FROM experiments x
+		LEFT JOIN jobs j ON j.experiment_id = x.id
+		GROUP BY x.id, x.name
+		ORDER BY cost DESC, x.name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var costs []models.ExperimentCost
+	for rows.Next() {
+		var cost models.ExperimentCost
+		if err := rows.Scan(&cost.ID, &cost.Name, &cost.Jobs, &cost.CostUSD); err != nil {
+			return nil, err
+		}
+		costs = append(costs, cost)
+	}
+	return costs, rows.Err()
+}
+
+// get returns the single experiment matching a WHERE clause
+func (r *ExperimentRepository) get(where string, arg interface{}) (*models.Experiment, error) {
+	return scanExperiment(r.db.QueryRow(`
+		SELECT id, name, description, labels_json, created_at
+		FROM experiments
+	`+where, arg))
+}
+
+// scanExperiment scans one experiments row
+func scanExperiment(row interface{ Scan(...interface{}) error }) (*models.Experiment, error) {
+	var experiment models.Experiment
+	var labelsJSON []byte
+	if err := row.Scan(&experiment.ID, &experiment.Name, &experiment.Description, &labelsJSON, &experiment.CreatedAt); err != nil {
+		return nil, err
+	}
+	if len(labelsJSON) > 0 {
+		if err := json.Unmarshal(labelsJSON, &experiment.Labels); err != nil {
+			return nil, fmt.Errorf("failed to decode labels of experiment %s: %w", experiment.Name, err)
+		}
+	}
+	return &experiment, nil
+}
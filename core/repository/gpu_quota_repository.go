@@ -0,0 +1,303 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"gpu-orchestrator/core/models"
+)
+
+// GPUQuotaRepository stores GPU-hour quotas, their months, admin adjustments
+// and the reservations of scheduled jobs
+type GPUQuotaRepository struct {
+	db *DB
+}
+
+// NewGPUQuotaRepository creates a new GPU-hour quota repository
+func NewGPUQuotaRepository(db *DB) *GPUQuotaRepository {
+	return &GPUQuotaRepository{db: db}
+}
+
+// gpuQuotaColumns are the columns scanGPUQuota reads, in order
+const gpuQuotaColumns = `id, scope, scope_id, monthly_hours, carry_over, carry_over_max_hours, created_at, updated_at`
+
+// UpsertQuota creates or replaces the quota of a scope and sets its ID and
+// timestamps
+func (r *GPUQuotaRepository) UpsertQuota(quota *models.GPUQuota) error {
+	now := r.db.Now()
+	return r.db.QueryRow(`
+		INSERT INTO gpu_hour_quotas (scope, scope_id, monthly_hours, carry_over, carry_over_max_hours, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+		ON CONFLICT (scope, scope_id) DO UPDATE SET
+			monthly_hours = EXCLUDED.monthly_hours,
+			carry_over = EXCLUDED.carry_over,
+			carry_over_max_hours = EXCLUDED.carry_over_max_hours,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at, updated_at
+	`,
+		quota.Scope,
+		quota.ScopeID,
+		quota.MonthlyHours,
+		quota.CarryOver,
+		quota.CarryOverMaxHours,
+		now,
+	).Scan(&quota.ID, &quota.CreatedAt, &quota.UpdatedAt)
+}
+
+// GetQuota returns the quota of a scope. Returns sql.ErrNoRows when there is
+// none.
+func (r *GPUQuotaRepository) GetQuota(scope models.GPUQuotaScope, scopeID string) (*models.GPUQuota, error) {
+	return scanGPUQuota(r.db.QueryRow(`
+		SELECT `+gpuQuotaColumns+` FROM gpu_hour_quotas
+		WHERE scope = $1 AND scope_id = $2
+	`, scope, scopeID))
+}
+
+// DeleteQuota deletes the quota of a scope with its months, adjustments and
+// reservations. Returns sql.ErrNoRows when there is none.
+func (r *GPUQuotaRepository) DeleteQuota(scope models.GPUQuotaScope, scopeID string) error {
+	result, err := r.db.Exec(`DELETE FROM gpu_hour_quotas WHERE scope = $1 AND scope_id = $2`, scope, scopeID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ListQuotas returns every quota ordered by scope
+func (r *GPUQuotaRepository) ListQuotas() ([]models.GPUQuota, error) {
+	rows, err := r.db.Query(`SELECT ` + gpuQuotaColumns + ` FROM gpu_hour_quotas ORDER BY scope, scope_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var quotas []models.GPUQuota
+	for rows.Next() {
+		quota, err := scanGPUQuota(rows)
+		if err != nil {
+			return nil, err
+		}
+		quotas = append(quotas, *quota)
+	}
+	return quotas, rows.Err()
+}
+
+// scanGPUQuota scans gpuQuotaColumns
+func scanGPUQuota(row rowScanner) (*models.GPUQuota, error) {
+	var quota models.GPUQuota
+	err := row.Scan(
+		&quota.ID,
+		&quota.Scope,
+		&quota.ScopeID,
+		&quota.MonthlyHours,
+		&quota.CarryOver,
+		&quota.CarryOverMaxHours,
+		&quota.CreatedAt,
+		&quota.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &quota, nil
+}
+
+// GetPeriod returns a quota's month, nil when it was never opened
+func (r *GPUQuotaRepository) GetPeriod(quotaID int64, start time.Time) (*models.GPUQuotaPeriod, error) {
+	period, err := scanGPUQuotaPeriod(r.db.QueryRow(`
+		SELECT quota_id, period_start, carried_hours, consumed_hours, closed_at
+		FROM gpu_hour_quota_periods
+		WHERE quota_id = $1 AND period_start = $2
+	`, quotaID, start))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return period, err
+}
+
+// OpenPeriod opens a quota's month with the hours carried into it. A month
+// that is already open keeps its carried hours.
+func (r *GPUQuotaRepository) OpenPeriod(quotaID int64, start time.Time, carriedHours float64) error {
+	_, err := r.db.Exec(`
+		INSERT INTO gpu_hour_quota_periods (quota_id, period_start, carried_hours)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (quota_id, period_start) DO NOTHING
+	`, quotaID, start, carriedHours)
+	return err
+}
+
+// UnclosedPeriods returns the months that started before before and were
+// not closed yet, oldest first
+func (r *GPUQuotaRepository) UnclosedPeriods(before time.Time) ([]models.GPUQuotaPeriod, error) {
+	rows, err := r.db.Query(`
+		SELECT quota_id, period_start, carried_hours, consumed_hours, closed_at
+		FROM gpu_hour_quota_periods
+		WHERE closed_at IS NULL AND period_start < $1
+		ORDER BY period_start, quota_id
+	`, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var periods []models.GPUQuotaPeriod
+	for rows.Next() {
+		period, err := scanGPUQuotaPeriod(rows)
+		if err != nil {
+			return nil, err
+		}
+		periods = append(periods, *period)
+	}
+	return periods, rows.Err()
+}
+
+// ClosePeriod records what a finished month consumed and drops the
+// reservations held against it
+func (r *GPUQuotaRepository) ClosePeriod(quotaID int64, start time.Time, consumedHours float64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		UPDATE gpu_hour_quota_periods SET consumed_hours = $3, closed_at = $4
+		WHERE quota_id = $1 AND period_start = $2 AND closed_at IS NULL
+	`, quotaID, start, consumedHours, r.db.Now()); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		DELETE FROM gpu_hour_reservations WHERE quota_id = $1 AND period_start = $2
+	`, quotaID, start); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// scanGPUQuotaPeriod scans a gpu_hour_quota_periods row
+func scanGPUQuotaPeriod(row rowScanner) (*models.GPUQuotaPeriod, error) {
+	var period models.GPUQuotaPeriod
+	var consumed sql.NullFloat64
+	var closedAt sql.NullTime
+	if err := row.Scan(&period.QuotaID, &period.Start, &period.CarriedHours, &consumed, &closedAt); err != nil {
+		return nil, err
+	}
+	if consumed.Valid {
+		period.ConsumedHours = &consumed.Float64
+	}
+	if closedAt.Valid {
+		period.ClosedAt = &closedAt.Time
+	}
+	return &period, nil
+}
+
+// AddAdjustment records an admin change of a month's limit and sets its ID
+// and creation time
+func (r *GPUQuotaRepository) AddAdjustment(adjustment *models.GPUQuotaAdjustment) error {
+	adjustment.CreatedAt = r.db.Now()
+	return r.db.QueryRow(`
+		INSERT INTO gpu_hour_quota_adjustments (quota_id, period_start, hours, reason, actor, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`,
+		adjustment.QuotaID,
+		adjustment.PeriodStart,
+		adjustment.Hours,
+		adjustment.Reason,
+		adjustment.Actor,
+		adjustment.CreatedAt,
+	).Scan(&adjustment.ID)
+}
+
+// ListAdjustments returns the adjustments of a quota's month, oldest first
+func (r *GPUQuotaRepository) ListAdjustments(quotaID int64, start time.Time) ([]models.GPUQuotaAdjustment, error) {
+	rows, err := r.db.Query(`
+		SELECT id, quota_id, period_start, hours, reason, actor, created_at
+		FROM gpu_hour_quota_adjustments
+		WHERE quota_id = $1 AND period_start = $2
+		ORDER BY created_at, id
+	`, quotaID, start)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var adjustments []models.GPUQuotaAdjustment
+	for rows.Next() {
+		var adjustment models.GPUQuotaAdjustment
+		if err := rows.Scan(
+			&adjustment.ID,
+			&adjustment.QuotaID,
+			&adjustment.PeriodStart,
+			&adjustment.Hours,
+			&adjustment.Reason,
+			&adjustment.Actor,
+			&adjustment.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		adjustments = append(adjustments, adjustment)
+	}
+	return adjustments, rows.Err()
+}
+
+// Reserve holds a job's estimated GPU-hours against quotas. A job scheduled
+// again replaces its earlier reservations.
+func (r *GPUQuotaRepository) Reserve(reservations []models.GPUHourReservation) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := r.db.Now()
+	for _, reservation := range reservations {
+		if _, err := tx.Exec(`
+			INSERT INTO gpu_hour_reservations (job_id, quota_id, period_start, hours, created_at)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (job_id, quota_id) DO UPDATE SET
+				period_start = EXCLUDED.period_start,
+				hours = EXCLUDED.hours,
+				created_at = EXCLUDED.created_at
+		`, reservation.JobID, reservation.QuotaID, reservation.PeriodStart, reservation.Hours, now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ReleaseReservations drops a job's reservations
+func (r *GPUQuotaRepository) ReleaseReservations(jobID string) error {
+	_, err := r.db.Exec(`DELETE FROM gpu_hour_reservations WHERE job_id = $1`, jobID)
+	return err
+}
+
+// ActiveReservations returns the reservations held in a month by jobs that
+// are scheduled, running or suspended. Reservations of pending and finished
+// jobs hold nothing.
+func (r *GPUQuotaRepository) ActiveReservations(start time.Time) ([]models.GPUHourReservation, error) {
+	rows, err := r.db.Query(`
+		SELECT r.job_id, r.quota_id, r.period_start, r.hours
+		FROM gpu_hour_reservations r
+		JOIN jobs j ON j.id = r.job_id
+		WHERE r.period_start = $1
+			AND j.status IN ('scheduled', 'provisioning', 'running', 'checkpointing', 'cancelling', 'suspended')
+	`, start)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reservations []models.GPUHourReservation
+	for rows.Next() {
+		var reservation models.GPUHourReservation
+		if err := rows.Scan(&reservation.JobID, &reservation.QuotaID, &reservation.PeriodStart, &reservation.Hours); err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, reservation)
+	}
+	return reservations, rows.Err()
+}
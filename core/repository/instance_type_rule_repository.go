@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"database/sql"
+
+	"gpu-orchestrator/core/models"
+
+	"github.com/lib/pq"
+)
+
+// InstanceTypeRuleRepository stores admin changes to the org-wide instance
+// type allow and deny lists
+type InstanceTypeRuleRepository struct {
+	db *DB
+}
+
+// NewInstanceTypeRuleRepository creates a new instance type rule repository
+func NewInstanceTypeRuleRepository(db *DB) *InstanceTypeRuleRepository {
+	return &InstanceTypeRuleRepository{db: db}
+}
+
+// RecordChange stores a change and sets its ID and time
+func (r *InstanceTypeRuleRepository) RecordChange(change *models.InstanceTypeRuleChange) error {
+	if change.ChangedAt.IsZero() {
+		change.ChangedAt = r.db.Now()
+	}
+	return r.db.QueryRow(`
+		INSERT INTO instance_type_rule_changes (
+			allow_patterns, deny_patterns, previous_allow, previous_deny, reason, remote_addr, changed_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`,
+		pq.Array(nonNilStrings(change.Allow)),
+		pq.Array(nonNilStrings(change.Deny)),
+		pq.Array(nonNilStrings(change.PreviousAllow)),
+		pq.Array(nonNilStrings(change.PreviousDeny)),
+		nullString(change.Reason),
+		nullString(change.RemoteAddr),
+		change.ChangedAt,
+	).Scan(&change.ID)
+}
+
+// LatestChange returns the most recent change, or nil if the lists were
+// never changed through the admin API
+func (r *InstanceTypeRuleRepository) LatestChange() (*models.InstanceTypeRuleChange, error) {
+	changes, err := r.ListChanges(1)
+	if err != nil || len(changes) == 0 {
+		return nil, err
+	}
+	return &changes[0], nil
+}
+
+// ListChanges returns up to limit changes, newest first
+func (r *InstanceTypeRuleRepository) ListChanges(limit int) ([]models.InstanceTypeRuleChange, error) {
+	rows, err := r.db.Query(`
+		SELECT id, allow_patterns, deny_patterns, previous_allow, previous_deny, reason, remote_addr, changed_at
+		FROM instance_type_rule_changes
+		ORDER BY changed_at DESC, id DESC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []models.InstanceTypeRuleChange
+	for rows.Next() {
+		var c models.InstanceTypeRuleChange
+		var reason, remoteAddr sql.NullString
+		if err := rows.Scan(
+			&c.ID,
+			pq.Array(&c.Allow),
+			pq.Array(&c.Deny),
+			pq.Array(&c.PreviousAllow),
+			pq.Array(&c.PreviousDeny),
+			&reason,
+			&remoteAddr,
+			&c.ChangedAt,
+		); err != nil {
+			return nil, err
+		}
+		c.Reason, c.RemoteAddr = reason.String, remoteAddr.String
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
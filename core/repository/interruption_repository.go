@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"gpu-orchestrator/core/models"
+)
+
+// InterruptionRepository handles database operations for spot interruptions
+type InterruptionRepository struct {
+	db *DB
+}
+
+// NewInterruptionRepository creates a new interruption repository
+func NewInterruptionRepository(db *DB) *InterruptionRepository {
+	return &InterruptionRepository{db: db}
+}
+
+// RecordInterruption stores a spot interruption and sets its ID
+func (r *InterruptionRepository) RecordInterruption(interruption *models.SpotInterruption) error {
+	if interruption.InterruptedAt.IsZero() {
+		interruption.InterruptedAt = r.db.Now()
+	}
+	return r.db.QueryRow(`
+		INSERT INTO spot_interruptions (
+			job_id, provider, instance_type, region, zone, instance_id,
+			interrupted_at, hour_of_day, runtime_seconds
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`,
+		nullString(interruption.JobID),
+		string(interruption.Provider),
+		interruption.InstanceType,
+		interruption.Region,
+		nullString(interruption.Zone),
+		nullString(interruption.InstanceID),
+		interruption.InterruptedAt,
+		interruption.InterruptedAt.UTC().Hour(),
+		interruption.RuntimeSeconds,
+	).Scan(&interruption.ID)
+}
+
+// ListInterruptions returns the interruptions since a time, oldest first
+func (r *InterruptionRepository) ListInterruptions(since time.Time) ([]models.SpotInterruption, error) {
+	rows, err := r.db.Query(`
+		SELECT id, job_id, provider, instance_type, region, zone, instance_id, interrupted_at, runtime_seconds
+		FROM spot_interruptions
+		WHERE interrupted_at >= $1
+		ORDER BY interrupted_at
+	`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var interruptions []models.SpotInterruption
+	for rows.Next() {
+		var i models.SpotInterruption
+		var jobID, zone, instanceID sql.NullString
+		if err := rows.Scan(&i.ID, &jobID, &i.Provider, &i.InstanceType, &i.Region, &zone, &instanceID, &i.InterruptedAt, &i.RuntimeSeconds); err != nil {
+			return nil, err
+		}
+		i.JobID, i.Zone, i.InstanceID = jobID.String, zone.String, instanceID.String
+		interruptions = append(interruptions, i)
+	}
+	return interruptions, rows.Err()
+}
+
+// ListSpotExposure returns the launched spot allocations of jobs that ran
+// since a time, with when they ran. Jobs still running end now.
+func (r *InterruptionRepository) ListSpotExposure(since time.Time) ([]models.SpotExposure, error) {
+	rows, err := r.db.Query(`
+		SELECT a.provider, a.instance_type, a.region, a.provisioned_count, j.started_at, j.finished_at
+		FROM allocations a
+		JOIN jobs j ON j.id = a.job_id
+		WHERE a.spot AND a.provisioned_count > 0 AND j.started_at IS NOT NULL
+			AND (j.finished_at IS NULL OR j.finished_at >= $1)
+	`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	now := r.db.Now()
+	var exposure []models.SpotExposure
+	for rows.Next() {
+		var e models.SpotExposure
+		var finishedAt sql.NullTime
+		if err := rows.Scan(&e.Provider, &e.InstanceType, &e.Region, &e.Count, &e.Start, &finishedAt); err != nil {
+			return nil, err
+		}
+		e.End = now
+		if finishedAt.Valid {
+			e.End = finishedAt.Time
+		}
+		if e.Start.Before(since) {
+			e.Start = since
+		}
+		exposure = append(exposure, e)
+	}
+	return exposure, rows.Err()
+}
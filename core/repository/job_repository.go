@@ -2,12 +2,18 @@ package repository
 
 import (
 	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"gpu-orchestrator/core/models"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // JobRepository handles database operations for jobs
@@ -22,16 +28,29 @@ func NewJobRepository(db *DB) *JobRepository {
 
 // CreateJob creates a new job in the database
 func (r *JobRepository) CreateJob(job *models.Job) error {
+	now := r.db.Now()
 	query := `
 		INSERT INTO jobs (
 			id, user_id, name, team_id, project_id, job_type, framework, entrypoint_uri, dataset_uri,
 			execution_mode, status, gpus, max_gpus_per_node, requires_multi_node,
 			gpu_memory_gb, cpu_memory_gb, storage_gb, estimated_hours,
 			locality, replication, budget_usd, deadline_at, allow_spot,
-			min_reliability, performance_weight, spec_yaml, created_at, updated_at
+			min_reliability, performance_weight, spec_yaml, created_at, updated_at,
+			max_spot_fraction, on_demand_ranks, network_json, elastic_min_nodes, elastic_max_nodes,
+			cloned_from, session_json, artifact_retention_json, bootstrap_json, spec_hash,
+			task_policy_json, weights_json, labels_json, dataset_verify_json, stuck_after_json,
+			total_steps, data_access_json, max_queue_seconds, queue_cancel_seconds, allow_migration,
+			dataset_download_json, experiment_id, training_metric, model_class,
+			storage_iops, storage_throughput_mbps, instance_types, exclude_instance_types, export_topology,
+			placement_spread, framework_config_json, carbon_weight, spec_bases_json, ignore_data_gravity,
+			checkpointing_json, spot_bid_strategy, spot_bid_cap, region_spread_json, task_groups_json,
+			image_id, batch_id, min_interconnect_gbps
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16,
-			$17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28
+			$17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31,
+			$32, $33, $34, $35, $36, $37, $38, $39, $40, $41, $42, $43, $44, $45, $46, $47, $48,
+			$49, $50, $51, $52, $53, $54, $55, $56, $57, $58, $59, $60, $61, $62, $63, $64, $65,
+			$66, $67, $68, $69, $70
 		)
 	`
 
@@ -49,7 +68,153 @@ func (r *JobRepository) CreateJob(job *models.Job) error {
 		deadlineAt = job.Constraints.Deadline
 	}
 
-	_, err := r.db.Exec(query,
+	networkJSON := "{}"
+	if networkBytes, err := json.Marshal(job.Network); err == nil {
+		networkJSON = string(networkBytes)
+	}
+
+	var elasticMin, elasticMax sql.NullInt64
+	if job.Requirements.Elastic != nil {
+		elasticMin = sql.NullInt64{Int64: int64(job.Requirements.Elastic.MinNodes), Valid: true}
+		elasticMax = sql.NullInt64{Int64: int64(job.Requirements.Elastic.MaxNodes), Valid: true}
+	}
+
+	var sessionJSON sql.NullString
+	if job.Session != nil {
+		sessionBytes, err := json.Marshal(job.Session)
+		if err != nil {
+			return fmt.Errorf("failed to encode session config: %w", err)
+		}
+		sessionJSON = sql.NullString{String: string(sessionBytes), Valid: true}
+	}
+
+	var retentionJSON sql.NullString
+	if job.ArtifactRetention != nil {
+		retentionBytes, err := json.Marshal(job.ArtifactRetention)
+		if err != nil {
+			return fmt.Errorf("failed to encode artifact retention: %w", err)
+		}
+		retentionJSON = sql.NullString{String: string(retentionBytes), Valid: true}
+	}
+
+	var bootstrapJSON sql.NullString
+	if job.Bootstrap != nil {
+		bootstrapBytes, err := json.Marshal(job.Bootstrap)
+		if err != nil {
+			return fmt.Errorf("failed to encode bootstrap config: %w", err)
+		}
+		bootstrapJSON = sql.NullString{String: string(bootstrapBytes), Valid: true}
+	}
+
+	var taskPolicyJSON sql.NullString
+	if job.TaskPolicy != nil {
+		taskPolicyBytes, err := json.Marshal(job.TaskPolicy)
+		if err != nil {
+			return fmt.Errorf("failed to encode task policy: %w", err)
+		}
+		taskPolicyJSON = sql.NullString{String: string(taskPolicyBytes), Valid: true}
+	}
+
+	var weightsJSON sql.NullString
+	if job.Constraints.Weights != nil {
+		weightsBytes, err := json.Marshal(job.Constraints.Weights)
+		if err != nil {
+			return fmt.Errorf("failed to encode scoring weights: %w", err)
+		}
+		weightsJSON = sql.NullString{String: string(weightsBytes), Valid: true}
+	}
+
+	var datasetVerifyJSON sql.NullString
+	if job.DatasetVerify != nil {
+		datasetVerifyBytes, err := json.Marshal(job.DatasetVerify)
+		if err != nil {
+			return fmt.Errorf("failed to encode dataset verification: %w", err)
+		}
+		datasetVerifyJSON = sql.NullString{String: string(datasetVerifyBytes), Valid: true}
+	}
+
+	var stuckAfterJSON sql.NullString
+	if len(job.StuckAfter) > 0 {
+		stuckAfterBytes, err := json.Marshal(job.StuckAfter)
+		if err != nil {
+			return fmt.Errorf("failed to encode stuck thresholds: %w", err)
+		}
+		stuckAfterJSON = sql.NullString{String: string(stuckAfterBytes), Valid: true}
+	}
+
+	var dataAccessJSON sql.NullString
+	if job.DataAccess != nil {
+		dataAccessBytes, err := json.Marshal(job.DataAccess)
+		if err != nil {
+			return fmt.Errorf("failed to encode data access: %w", err)
+		}
+		dataAccessJSON = sql.NullString{String: string(dataAccessBytes), Valid: true}
+	}
+
+	var datasetDownloadJSON sql.NullString
+	if job.DatasetDownload != nil {
+		datasetDownloadBytes, err := json.Marshal(job.DatasetDownload)
+		if err != nil {
+			return fmt.Errorf("failed to encode dataset download: %w", err)
+		}
+		datasetDownloadJSON = sql.NullString{String: string(datasetDownloadBytes), Valid: true}
+	}
+
+	var frameworkConfigJSON sql.NullString
+	if job.FrameworkConfig != nil {
+		frameworkConfigBytes, err := json.Marshal(job.FrameworkConfig)
+		if err != nil {
+			return fmt.Errorf("failed to encode framework config: %w", err)
+		}
+		frameworkConfigJSON = sql.NullString{String: string(frameworkConfigBytes), Valid: true}
+	}
+
+	var specBasesJSON sql.NullString
+	if len(job.SpecBases) > 0 {
+		specBasesBytes, err := json.Marshal(job.SpecBases)
+		if err != nil {
+			return fmt.Errorf("failed to encode spec bases: %w", err)
+		}
+		specBasesJSON = sql.NullString{String: string(specBasesBytes), Valid: true}
+	}
+
+	var checkpointingJSON sql.NullString
+	if job.Checkpointing != nil {
+		checkpointingBytes, err := json.Marshal(job.Checkpointing)
+		if err != nil {
+			return fmt.Errorf("failed to encode checkpoint schedule: %w", err)
+		}
+		checkpointingJSON = sql.NullString{String: string(checkpointingBytes), Valid: true}
+	}
+
+	var regionSpreadJSON sql.NullString
+	if job.Requirements.RegionSpread != nil {
+		regionSpreadBytes, err := json.Marshal(job.Requirements.RegionSpread)
+		if err != nil {
+			return fmt.Errorf("failed to encode region spread: %w", err)
+		}
+		regionSpreadJSON = sql.NullString{String: string(regionSpreadBytes), Valid: true}
+	}
+
+	var taskGroupsJSON sql.NullString
+	if len(job.Requirements.TaskGroups) > 0 {
+		taskGroupsBytes, err := json.Marshal(job.Requirements.TaskGroups)
+		if err != nil {
+			return fmt.Errorf("failed to encode task groups: %w", err)
+		}
+		taskGroupsJSON = sql.NullString{String: string(taskGroupsBytes), Valid: true}
+	}
+
+	labels := job.Labels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labelsJSON, err := json.Marshal(labels)
+	if err != nil {
+		return fmt.Errorf("failed to encode labels: %w", err)
+	}
+
+	_, err = r.db.Exec(query,
 		jobID,
 		job.UserID,
 		job.Name,
@@ -76,8 +241,50 @@ func (r *JobRepository) CreateJob(job *models.Job) error {
 		job.Constraints.MinReliability,
 		job.Constraints.PerformanceWeight,
 		job.SpecYAML,
-		time.Now(),
-		time.Now(),
+		now,
+		now,
+		job.Constraints.MaxSpotFraction,
+		pq.Array(toInt64s(job.Constraints.OnDemandRanks)),
+		networkJSON,
+		elasticMin,
+		elasticMax,
+		job.ClonedFrom,
+		sessionJSON,
+		retentionJSON,
+		bootstrapJSON,
+		sql.NullString{String: job.SpecHash, Valid: job.SpecHash != ""},
+		taskPolicyJSON,
+		weightsJSON,
+		string(labelsJSON),
+		datasetVerifyJSON,
+		stuckAfterJSON,
+		sql.NullInt64{Int64: job.TotalSteps, Valid: job.TotalSteps > 0},
+		dataAccessJSON,
+		durationSeconds(job.Constraints.MaxQueueTime),
+		durationSeconds(job.Constraints.QueueCancelAfter),
+		job.Constraints.AllowMigration,
+		datasetDownloadJSON,
+		sql.NullString{String: job.ExperimentID, Valid: job.ExperimentID != ""},
+		sql.NullString{String: string(job.Requirements.Metric), Valid: job.Requirements.Metric != ""},
+		sql.NullString{String: job.Requirements.ModelClass, Valid: job.Requirements.ModelClass != ""},
+		job.Requirements.StorageIOPS,
+		job.Requirements.StorageThroughput,
+		pq.Array(nonNilStrings(job.Requirements.InstanceTypes)),
+		pq.Array(nonNilStrings(job.Requirements.ExcludeInstanceTypes)),
+		job.ExportTopology,
+		sql.NullString{String: string(job.PlacementSpread), Valid: job.PlacementSpread != ""},
+		frameworkConfigJSON,
+		job.Constraints.CarbonWeight,
+		specBasesJSON,
+		job.Constraints.IgnoreDataGravity,
+		checkpointingJSON,
+		sql.NullString{String: string(job.Constraints.SpotBidStrategy), Valid: job.Constraints.SpotBidStrategy != ""},
+		sql.NullFloat64{Float64: job.Constraints.SpotBidCap, Valid: job.Constraints.SpotBidCap > 0},
+		regionSpreadJSON,
+		taskGroupsJSON,
+		sql.NullString{String: job.Requirements.Image, Valid: job.Requirements.Image != ""},
+		sql.NullString{String: job.BatchID, Valid: job.BatchID != ""},
+		job.Requirements.MinInterconnectGbps,
 	)
 
 	if err != nil {
@@ -85,10 +292,10 @@ func (r *JobRepository) CreateJob(job *models.Job) error {
 	}
 
 	job.ID = jobID.String()
-	job.CreatedAt = time.Now()
+	job.CreatedAt = now
 
 	// Create initial event
-	return r.CreateJobEvent(job.ID, nil, job.Status, "job_created", nil)
+	return r.createJobEventAt(job.ID, nil, job.Status, "job_created", nil, now)
 }
 
 // GetJob retrieves a job by ID
@@ -100,7 +307,18 @@ func (r *JobRepository) GetJob(id string) (*models.Job, error) {
 			locality, replication, budget_usd, deadline_at, allow_spot,
 			min_reliability, performance_weight, selected_provider, selected_region,
 			selected_backend, cluster_vpc, cluster_id, started_at, finished_at,
-			cost_running_usd, cost_estimated_usd, spec_yaml, created_at, updated_at
+			cost_running_usd, cost_estimated_usd, spec_yaml, created_at, updated_at,
+			max_spot_fraction, on_demand_ranks, network_json, elastic_min_nodes, elastic_max_nodes,
+			cloned_from, session_json, session_endpoint_json, session_expires_at,
+			artifact_retention_json, bootstrap_json, task_policy_json, weights_json, labels_json,
+			priority_boost, dataset_verify_json, dataset_size_gb, stuck_after_json,
+			total_steps, progress_json, data_access_json, max_queue_seconds, queue_cancel_seconds,
+			allow_migration, dataset_download_json, experiment_id, training_metric, model_class,
+			storage_iops, storage_throughput_mbps, instance_types, exclude_instance_types, export_topology,
+			placement_spread, framework_config_json, carbon_weight, emissions_gco2e, spec_bases_json,
+			ignore_data_gravity, checkpointing_json, spot_bid_strategy, spot_bid_cap, region_spread_json,
+			cost_transfer_usd, task_groups_json, image_id, batch_id, cost_egress_usd, egress_bytes,
+			suspend_requested_at, min_interconnect_gbps, schedule_attempts, next_schedule_at
 		FROM jobs
 		WHERE id = $1
 	`
@@ -109,14 +327,46 @@ func (r *JobRepository) GetJob(id string) (*models.Job, error) {
 	var deadlineAt sql.NullTime
 	var startedAt sql.NullTime
 	var finishedAt sql.NullTime
+	var suspendRequestedAt sql.NullTime
+	var nextScheduleAt sql.NullTime
 	var selectedProvider sql.NullString
 	var selectedRegion sql.NullString
 	var selectedBackend sql.NullString
 	var clusterID sql.NullString
 	var costEstimatedUSD sql.NullFloat64
+	var emissions sql.NullFloat64
 
 	var teamID sql.NullString
 	var projectID sql.NullString
+	var onDemandRanks []int64
+	var networkJSON string
+	var elasticMin, elasticMax sql.NullInt64
+	var clonedFrom sql.NullString
+	var sessionJSON, sessionEndpointJSON sql.NullString
+	var sessionExpiresAt sql.NullTime
+	var retentionJSON sql.NullString
+	var bootstrapJSON sql.NullString
+	var taskPolicyJSON sql.NullString
+	var weightsJSON sql.NullString
+	var labelsJSON []byte
+	var datasetVerifyJSON sql.NullString
+	var datasetSizeGB sql.NullFloat64
+	var stuckAfterJSON sql.NullString
+	var totalSteps sql.NullInt64
+	var progressJSON sql.NullString
+	var dataAccessJSON sql.NullString
+	var maxQueueSeconds, queueCancelSeconds sql.NullInt64
+	var datasetDownloadJSON sql.NullString
+	var experimentID sql.NullString
+	var trainingMetric, modelClass, placementSpread, frameworkConfigJSON sql.NullString
+	var specBasesJSON sql.NullString
+	var checkpointingJSON sql.NullString
+	var spotBidStrategy sql.NullString
+	var spotBidCap sql.NullFloat64
+	var regionSpreadJSON sql.NullString
+	var taskGroupsJSON sql.NullString
+	var imageID sql.NullString
+	var batchID sql.NullString
 
 	err := r.db.QueryRow(query, id).Scan(
 		&job.ID,
@@ -156,6 +406,59 @@ func (r *JobRepository) GetJob(id string) (*models.Job, error) {
 		&job.SpecYAML,
 		&job.CreatedAt,
 		&job.UpdatedAt,
+		&job.Constraints.MaxSpotFraction,
+		pq.Array(&onDemandRanks),
+		&networkJSON,
+		&elasticMin,
+		&elasticMax,
+		&clonedFrom,
+		&sessionJSON,
+		&sessionEndpointJSON,
+		&sessionExpiresAt,
+		&retentionJSON,
+		&bootstrapJSON,
+		&taskPolicyJSON,
+		&weightsJSON,
+		&labelsJSON,
+		&job.PriorityBoost,
+		&datasetVerifyJSON,
+		&datasetSizeGB,
+		&stuckAfterJSON,
+		&totalSteps,
+		&progressJSON,
+		&dataAccessJSON,
+		&maxQueueSeconds,
+		&queueCancelSeconds,
+		&job.Constraints.AllowMigration,
+		&datasetDownloadJSON,
+		&experimentID,
+		&trainingMetric,
+		&modelClass,
+		&job.Requirements.StorageIOPS,
+		&job.Requirements.StorageThroughput,
+		pq.Array(&job.Requirements.InstanceTypes),
+		pq.Array(&job.Requirements.ExcludeInstanceTypes),
+		&job.ExportTopology,
+		&placementSpread,
+		&frameworkConfigJSON,
+		&job.Constraints.CarbonWeight,
+		&emissions,
+		&specBasesJSON,
+		&job.Constraints.IgnoreDataGravity,
+		&checkpointingJSON,
+		&spotBidStrategy,
+		&spotBidCap,
+		&regionSpreadJSON,
+		&job.CostTransferUSD,
+		&taskGroupsJSON,
+		&imageID,
+		&batchID,
+		&job.CostEgressUSD,
+		&job.EgressBytes,
+		&suspendRequestedAt,
+		&job.Requirements.MinInterconnectGbps,
+		&job.ScheduleAttempts,
+		&nextScheduleAt,
 	)
 
 	if err != nil {
@@ -171,6 +474,12 @@ func (r *JobRepository) GetJob(id string) (*models.Job, error) {
 	if finishedAt.Valid {
 		job.CompletedAt = &finishedAt.Time
 	}
+	if suspendRequestedAt.Valid {
+		job.SuspendRequestedAt = &suspendRequestedAt.Time
+	}
+	if nextScheduleAt.Valid {
+		job.NextScheduleAt = &nextScheduleAt.Time
+	}
 	if selectedProvider.Valid {
 		provider := models.Provider(selectedProvider.String)
 		job.SelectedProvider = &provider
@@ -187,133 +496,1426 @@ func (r *JobRepository) GetJob(id string) (*models.Job, error) {
 	if costEstimatedUSD.Valid {
 		job.CostEstimatedUSD = &costEstimatedUSD.Float64
 	}
+	if emissions.Valid {
+		job.EmissionsGCO2e = &emissions.Float64
+	}
 	if teamID.Valid {
 		job.TeamID = teamID.String
 	}
 	if projectID.Valid {
 		job.ProjectID = projectID.String
 	}
+	for _, rank := range onDemandRanks {
+		job.Constraints.OnDemandRanks = append(job.Constraints.OnDemandRanks, int(rank))
+	}
+	if networkJSON != "" {
+		json.Unmarshal([]byte(networkJSON), &job.Network)
+	}
+	if clonedFrom.Valid {
+		job.ClonedFrom = &clonedFrom.String
+	}
+	if sessionJSON.Valid {
+		job.Session = &models.SessionConfig{}
+		if err := json.Unmarshal([]byte(sessionJSON.String), job.Session); err != nil {
+			return nil, fmt.Errorf("failed to decode session config for job %s: %w", id, err)
+		}
+	}
+	if sessionEndpointJSON.Valid {
+		job.SessionEndpoint = &models.SessionEndpoint{}
+		if err := json.Unmarshal([]byte(sessionEndpointJSON.String), job.SessionEndpoint); err != nil {
+			return nil, fmt.Errorf("failed to decode session endpoint for job %s: %w", id, err)
+		}
+	}
+	if sessionExpiresAt.Valid {
+		job.SessionExpiresAt = &sessionExpiresAt.Time
+	}
+	if retentionJSON.Valid {
+		job.ArtifactRetention = &models.RetentionPolicy{}
+		if err := json.Unmarshal([]byte(retentionJSON.String), job.ArtifactRetention); err != nil {
+			return nil, fmt.Errorf("failed to decode artifact retention for job %s: %w", id, err)
+		}
+	}
+	if bootstrapJSON.Valid {
+		job.Bootstrap = &models.BootstrapConfig{}
+		if err := json.Unmarshal([]byte(bootstrapJSON.String), job.Bootstrap); err != nil {
+			return nil, fmt.Errorf("failed to decode bootstrap config for job %s: %w", id, err)
+		}
+	}
+	if taskPolicyJSON.Valid {
+		job.TaskPolicy = &models.TaskPolicy{}
+		if err := json.Unmarshal([]byte(taskPolicyJSON.String), job.TaskPolicy); err != nil {
+			return nil, fmt.Errorf("failed to decode task policy for job %s: %w", id, err)
+		}
+	}
+	if weightsJSON.Valid {
+		job.Constraints.Weights = &models.ScoringWeights{}
+		if err := json.Unmarshal([]byte(weightsJSON.String), job.Constraints.Weights); err != nil {
+			return nil, fmt.Errorf("failed to decode scoring weights for job %s: %w", id, err)
+		}
+	}
+	if len(labelsJSON) > 0 {
+		if err := json.Unmarshal(labelsJSON, &job.Labels); err != nil {
+			return nil, fmt.Errorf("failed to decode labels for job %s: %w", id, err)
+		}
+	}
+	if datasetVerifyJSON.Valid {
+		job.DatasetVerify = &models.DatasetVerify{}
+		if err := json.Unmarshal([]byte(datasetVerifyJSON.String), job.DatasetVerify); err != nil {
+			return nil, fmt.Errorf("failed to decode dataset verification for job %s: %w", id, err)
+		}
+	}
+	if stuckAfterJSON.Valid {
+		if err := json.Unmarshal([]byte(stuckAfterJSON.String), &job.StuckAfter); err != nil {
+			return nil, fmt.Errorf("failed to decode stuck thresholds for job %s: %w", id, err)
+		}
+	}
+	if totalSteps.Valid {
+		job.TotalSteps = totalSteps.Int64
+	}
+	if progressJSON.Valid {
+		job.Progress = &models.TrainingProgress{}
+		if err := json.Unmarshal([]byte(progressJSON.String), job.Progress); err != nil {
+			return nil, fmt.Errorf("failed to decode progress for job %s: %w", id, err)
+		}
+	}
+	job.Constraints.MaxQueueTime = time.Duration(maxQueueSeconds.Int64) * time.Second
+	job.Constraints.QueueCancelAfter = time.Duration(queueCancelSeconds.Int64) * time.Second
+	if dataAccessJSON.Valid {
+		job.DataAccess = &models.DataAccess{}
+		if err := json.Unmarshal([]byte(dataAccessJSON.String), job.DataAccess); err != nil {
+			return nil, fmt.Errorf("failed to decode data access for job %s: %w", id, err)
+		}
+	}
+	if datasetDownloadJSON.Valid {
+		job.DatasetDownload = &models.DatasetDownload{}
+		if err := json.Unmarshal([]byte(datasetDownloadJSON.String), job.DatasetDownload); err != nil {
+			return nil, fmt.Errorf("failed to decode dataset download for job %s: %w", id, err)
+		}
+	}
+	job.ExperimentID = experimentID.String
+	job.Requirements.Metric = models.TrainingMetric(trainingMetric.String)
+	job.Requirements.ModelClass = modelClass.String
+	job.PlacementSpread = models.PlacementSpread(placementSpread.String)
+	job.Constraints.SpotBidStrategy = models.SpotBidStrategy(spotBidStrategy.String)
+	job.Constraints.SpotBidCap = spotBidCap.Float64
+	if frameworkConfigJSON.Valid {
+		job.FrameworkConfig = &models.FrameworkConfig{}
+		if err := json.Unmarshal([]byte(frameworkConfigJSON.String), job.FrameworkConfig); err != nil {
+			return nil, fmt.Errorf("failed to decode framework config for job %s: %w", id, err)
+		}
+	}
+	if specBasesJSON.Valid {
+		if err := json.Unmarshal([]byte(specBasesJSON.String), &job.SpecBases); err != nil {
+			return nil, fmt.Errorf("failed to decode spec bases for job %s: %w", id, err)
+		}
+	}
+	if checkpointingJSON.Valid {
+		job.Checkpointing = &models.CheckpointSchedule{}
+		if err := json.Unmarshal([]byte(checkpointingJSON.String), job.Checkpointing); err != nil {
+			return nil, fmt.Errorf("failed to decode checkpoint schedule for job %s: %w", id, err)
+		}
+		job.Requirements.Checkpointed = true
+	}
+	if regionSpreadJSON.Valid {
+		job.Requirements.RegionSpread = &models.RegionSpread{}
+		if err := json.Unmarshal([]byte(regionSpreadJSON.String), job.Requirements.RegionSpread); err != nil {
+			return nil, fmt.Errorf("failed to decode region spread for job %s: %w", id, err)
+		}
+	}
+	if taskGroupsJSON.Valid {
+		if err := json.Unmarshal([]byte(taskGroupsJSON.String), &job.Requirements.TaskGroups); err != nil {
+			return nil, fmt.Errorf("failed to decode task groups for job %s: %w", id, err)
+		}
+	}
+	job.Requirements.Image = imageID.String
+	job.BatchID = batchID.String
+	job.Requirements.DatasetLocation = job.DatasetURI
+	if datasetSizeGB.Valid {
+		job.Requirements.DatasetSizeGB = datasetSizeGB.Float64
+	}
+	if elasticMin.Valid && elasticMax.Valid {
+		job.Requirements.Elastic = &models.ElasticConfig{
+			MinNodes: int(elasticMin.Int64),
+			MaxNodes: int(elasticMax.Int64),
+		}
+	}
 
 	return &job, nil
 }
 
-// UpdateJobStatus updates job status atomically with event logging
-func (r *JobRepository) UpdateJobStatus(jobID string, fromStatus, toStatus models.JobStatus, reason string, meta map[string]interface{}) error {
+// FindActiveDuplicate returns the ID of the user's oldest non-terminal job
+// with the same spec hash, or "" if there is none
+func (r *JobRepository) FindActiveDuplicate(userID, specHash string) (string, error) {
+	var jobID string
+	err := r.db.QueryRow(`
+		SELECT id FROM jobs
+		WHERE user_id = $1 AND spec_hash = $2
+			AND status NOT IN ('completed', 'failed', 'cancelled')
+		ORDER BY created_at
+		LIMIT 1
+	`, userID, specHash).Scan(&jobID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return jobID, err
+}
+
+// ListClones returns the IDs of jobs cloned from a job, oldest first
+func (r *JobRepository) ListClones(jobID string) ([]string, error) {
+	rows, err := r.db.Query(`SELECT id FROM jobs WHERE cloned_from = $1 ORDER BY created_at`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// SetSessionEndpoint records where a running interactive session is reachable and when it expires
+func (r *JobRepository) SetSessionEndpoint(jobID string, endpoint models.SessionEndpoint, expiresAt time.Time) error {
+	endpointJSON, err := json.Marshal(endpoint)
+	if err != nil {
+		return fmt.Errorf("failed to encode session endpoint: %w", err)
+	}
+	_, err = r.db.Exec(`
+		UPDATE jobs SET session_endpoint_json = $1, session_expires_at = $2, updated_at = $4
+		WHERE id = $3
+	`, string(endpointJSON), expiresAt, jobID, r.db.Now())
+	return err
+}
+
+// UpdateSessionExpiry moves the teardown time of an interactive session
+func (r *JobRepository) UpdateSessionExpiry(jobID string, expiresAt time.Time) error {
+	_, err := r.db.Exec(`UPDATE jobs SET session_expires_at = $1, updated_at = $3 WHERE id = $2`, expiresAt, jobID, r.db.Now())
+	return err
+}
+
+// SetPriorityBoost sets a job's priority boost and records the change as an
+// event with the given reason
+func (r *JobRepository) SetPriorityBoost(jobID string, boost int, reason string, meta map[string]interface{}) error {
 	tx, err := r.db.Begin()
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	// Update job status
-	updateQuery := `UPDATE jobs SET status = $1, updated_at = NOW() WHERE id = $2`
-	_, err = tx.Exec(updateQuery, toStatus, jobID)
+	now := r.db.Now()
+	var status models.JobStatus
+	err = tx.QueryRow(`
+		UPDATE jobs SET priority_boost = $1, updated_at = $3 WHERE id = $2 RETURNING status
+	`, boost, jobID, now).Scan(&status)
 	if err != nil {
 		return err
 	}
 
-	// Create event
-	err = r.createJobEventTx(tx, jobID, &fromStatus, toStatus, reason, meta)
-	if err != nil {
+	if err := r.createJobEventTx(tx, jobID, &status, status, reason, meta, now); err != nil {
 		return err
 	}
 
 	return tx.Commit()
 }
 
-// CreateJobEvent creates a job event
-func (r *JobRepository) CreateJobEvent(jobID string, fromStatus *models.JobStatus, toStatus models.JobStatus, reason string, meta map[string]interface{}) error {
+// UpdateJobConstraints replaces the user-editable constraints (budget,
+// deadline, allow_spot, min_reliability, performance_weight) of a job still
+// in fromStatus (ErrStatusConflict otherwise) and records the change as a
+// constraints_updated event. Only pending and scheduled jobs can be updated;
+// a scheduled job moves back to pending so it is placed again.
+func (r *JobRepository) UpdateJobConstraints(jobID string, fromStatus models.JobStatus, constraints models.JobConstraints, meta map[string]interface{}) error {
+	if fromStatus != models.JobStatusPending && fromStatus != models.JobStatusScheduled {
+		return fmt.Errorf("%w: job %s is %s, constraints are fixed once provisioning starts", ErrStatusConflict, jobID, fromStatus)
+	}
+
 	tx, err := r.db.Begin()
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	err = r.createJobEventTx(tx, jobID, fromStatus, toStatus, reason, meta)
+	var current models.JobStatus
+	if err := tx.QueryRow(`SELECT status FROM jobs WHERE id = $1 FOR UPDATE`, jobID).Scan(&current); err != nil {
+		return err
+	}
+	if current != fromStatus {
+		return fmt.Errorf("%w: job %s is %s, not %s", ErrStatusConflict, jobID, current, fromStatus)
+	}
+
+	now := r.db.Now()
+	toStatus := models.JobStatusPending
+	_, err = tx.Exec(`
+		UPDATE jobs
+		SET budget_usd = $1, deadline_at = $2, allow_spot = $3, min_reliability = $4,
+			performance_weight = $5, status = $6, updated_at = $7,
+			schedule_attempts = 0, next_schedule_at = NULL
+		WHERE id = $8
+	`,
+		constraints.MaxBudget,
+		constraints.Deadline,
+		constraints.AllowSpot,
+		constraints.MinReliability,
+		constraints.PerformanceWeight,
+		toStatus,
+		now,
+		jobID,
+	)
 	if err != nil {
 		return err
 	}
 
+	if err := r.createJobEventTx(tx, jobID, &fromStatus, toStatus, "constraints_updated", meta, now); err != nil {
+		return err
+	}
+
+	if fromStatus != toStatus {
+		actor, _ := meta["actor"].(string)
+		if actor == "" {
+			actor = models.ActorSystem
+		}
+		if err := insertAuditEntry(tx.Exec, &models.AuditEntry{
+			Actor:        actor,
+			Action:       models.AuditJobStatusChanged,
+			CreatedAt:    now,
+			ResourceType: "jobs",
+			ResourceID:   jobID,
+			Request: map[string]interface{}{
+				"from":   fromStatus,
+				"to":     toStatus,
+				"reason": "constraints_updated",
+			},
+		}); err != nil {
+			return fmt.Errorf("failed to audit status change of job %s: %w", jobID, err)
+		}
+	}
+
 	return tx.Commit()
 }
 
-func (r *JobRepository) createJobEventTx(tx *sql.Tx, jobID string, fromStatus *models.JobStatus, toStatus models.JobStatus, reason string, meta map[string]interface{}) error {
-	query := `
-		INSERT INTO job_events (job_id, from_status, to_status, reason, meta_json)
-		VALUES ($1, $2, $3, $4, $5)
-	`
+// RecordScheduleAttempt stores a pending job's failed scheduling attempts and
+// when it may be retried, and records the attempt as an event with the given
+// reason. ErrStatusConflict if the job left pending.
+func (r *JobRepository) RecordScheduleAttempt(jobID string, attempts int, retryAt time.Time, reason string, meta map[string]interface{}) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-	var fromStatusStr *string
-	if fromStatus != nil {
-		s := string(*fromStatus)
-		fromStatusStr = &s
+	now := r.db.Now()
+	pending := models.JobStatusPending
+	result, err := tx.Exec(`
+		UPDATE jobs SET schedule_attempts = $1, next_schedule_at = $2, updated_at = $3
+		WHERE id = $4 AND status = $5
+	`, attempts, retryAt, now, jobID, pending)
+	if err != nil {
+		return err
+	}
+	if updated, err := result.RowsAffected(); err == nil && updated == 0 {
+		return fmt.Errorf("%w: job %s left %s", ErrStatusConflict, jobID, pending)
 	}
 
-	// TODO: Serialize meta to JSON
-	metaJSON := "{}"
-	if meta != nil {
-		// Use json.Marshal in real implementation
-		metaJSON = "{}"
+	if err := r.createJobEventTx(tx, jobID, &pending, pending, reason, meta, now); err != nil {
+		return err
 	}
 
-	_, err := tx.Exec(query, jobID, fromStatusStr, toStatus, reason, metaJSON)
+	return tx.Commit()
+}
+
+// ResetScheduleAttempts clears a job's failed scheduling attempts and backoff
+func (r *JobRepository) ResetScheduleAttempts(jobID string) error {
+	_, err := r.db.Exec(`
+		UPDATE jobs SET schedule_attempts = 0, next_schedule_at = NULL
+		WHERE id = $1 AND (schedule_attempts > 0 OR next_schedule_at IS NOT NULL)
+	`, jobID)
 	return err
 }
 
-// ListJobs lists jobs with optional filters
-func (r *JobRepository) ListJobs(userID string, status *models.JobStatus, limit int, cursor string) ([]*models.Job, string, error) {
-	// TODO: Implement pagination with cursor
-	query := `
-		SELECT id, user_id, name, job_type, framework, status, created_at
-		FROM jobs
-		WHERE user_id = $1
-	`
-	args := []interface{}{userID}
-	argIndex := 2
+// SetDatasetSize records the dataset size measured by verification
+func (r *JobRepository) SetDatasetSize(jobID string, sizeGB float64, meta map[string]interface{}) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-	if status != nil {
-		query += fmt.Sprintf(" AND status = $%d", argIndex)
-		args = append(args, *status)
-		argIndex++
+	now := r.db.Now()
+	var status models.JobStatus
+	err = tx.QueryRow(`
+		UPDATE jobs SET dataset_size_gb = $1, updated_at = $3 WHERE id = $2 RETURNING status
+	`, sizeGB, jobID, now).Scan(&status)
+	if err != nil {
+		return err
+	}
+
+	if err := r.createJobEventTx(tx, jobID, &status, status, "dataset_verified", meta, now); err != nil {
+		return err
 	}
 
-	query += " ORDER BY created_at DESC LIMIT $%d"
-	args = append(args, limit)
-	query = fmt.Sprintf(query, argIndex)
+	return tx.Commit()
+}
 
-	rows, err := r.db.Query(query, args...)
+// RecordProgress folds a step report into the job's progress telemetry.
+// tokens is the cumulative token count, 0 when the job does not report it.
+// Only running and checkpointing jobs accept reports; others return
+// ErrStatusConflict. Returns the updated progress.
+func (r *JobRepository) RecordProgress(jobID string, steps, tokens int64, at time.Time) (*models.TrainingProgress, error) {
+	tx, err := r.db.Begin()
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
-	defer rows.Close()
+	defer tx.Rollback()
 
-	var jobs []*models.Job
+	var status models.JobStatus
+	var progressJSON sql.NullString
+	err = tx.QueryRow(`SELECT status, progress_json FROM jobs WHERE id = $1 FOR UPDATE`, jobID).Scan(&status, &progressJSON)
+	if err != nil {
+		return nil, err
+	}
+	if !status.IsRunning() && status != models.JobStatusCheckpointing {
+		return nil, fmt.Errorf("%w: job %s is %s", ErrStatusConflict, jobID, status)
+	}
+
+	var progress models.TrainingProgress
+	if progressJSON.Valid {
+		if err := json.Unmarshal([]byte(progressJSON.String), &progress); err != nil {
+			return nil, fmt.Errorf("failed to decode progress for job %s: %w", jobID, err)
+		}
+	}
+	progress.Observe(steps, at)
+	if tokens > 0 {
+		progress.Tokens = tokens
+	}
+
+	progressBytes, err := json.Marshal(progress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode progress: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE jobs SET progress_json = $1 WHERE id = $2`, string(progressBytes), jobID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+// JobStatusAge is how long a job has been in its current status
+type JobStatusAge struct {
+	JobID      string
+	Status     models.JobStatus
+	Since      time.Time // Latest change into the current status
+	Warned     bool      // A stuck_warning event was recorded since
+	Escalated  bool      // A stuck_escalated event was recorded since
+	StuckAfter map[models.JobStatus]time.Duration
+}
+
+// CountJobsCreatedBetween counts the jobs created in [from, to)
+func (r *JobRepository) CountJobsCreatedBetween(from, to time.Time) (int, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM jobs WHERE created_at >= $1 AND created_at < $2`, from, to).Scan(&count)
+	return count, err
+}
+
+// ListJobsCreatedBetween loads the jobs created in [from, to), oldest first,
+// up to limit
+func (r *JobRepository) ListJobsCreatedBetween(from, to time.Time, limit int) ([]*models.Job, error) {
+	rows, err := r.db.Query(`
+		SELECT id FROM jobs
+		WHERE created_at >= $1 AND created_at < $2
+		ORDER BY created_at
+		LIMIT $3
+	`, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
 	for rows.Next() {
-		var job models.Job
-		err := rows.Scan(
-			&job.ID,
-			&job.UserID,
-			&job.Name,
-			&job.JobType,
-			&job.Framework,
-			&job.Status,
-			&job.CreatedAt,
-		)
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*models.Job, 0, len(ids))
+	for _, id := range ids {
+		job, err := r.GetJob(id)
 		if err != nil {
-			continue
+			return nil, fmt.Errorf("failed to load job %s: %w", id, err)
 		}
-		jobs = append(jobs, &job)
+		jobs = append(jobs, job)
 	}
+	return jobs, nil
+}
 
-	// TODO: Calculate next cursor
-	nextCursor := ""
+// ListStatusAges returns the jobs in the given statuses with when they
+// entered them. Events that do not change the status do not reset the clock.
+func (r *JobRepository) ListStatusAges(statuses []models.JobStatus) ([]JobStatusAge, error) {
+	if len(statuses) == 0 {
+		return nil, nil
+	}
+	args := make([]interface{}, len(statuses))
+	for i, status := range statuses {
+		args[i] = string(status)
+	}
 
-	return jobs, nextCursor, nil
+	rows, err := r.db.Query(`
+		SELECT s.id, s.status, s.since, s.stuck_after_json,
+			EXISTS (SELECT 1 FROM job_events w WHERE w.job_id = s.id AND w.reason = 'stuck_warning' AND w.at >= s.since),
+			EXISTS (SELECT 1 FROM job_events w WHERE w.job_id = s.id AND w.reason = 'stuck_escalated' AND w.at >= s.since)
+		FROM (
+			SELECT j.id, j.status, j.stuck_after_json,
+				COALESCE((
+					SELECT MAX(e.at) FROM job_events e
+					WHERE e.job_id = j.id AND e.to_status = j.status AND e.from_status IS DISTINCT FROM e.to_status
+				), j.updated_at) AS since
+			FROM jobs j
+			WHERE j.status IN (`+inPlaceholders(1, len(statuses))+`)
+		) s
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ages []JobStatusAge
+	for rows.Next() {
+		var age JobStatusAge
+		var stuckAfterJSON sql.NullString
+		if err := rows.Scan(&age.JobID, &age.Status, &age.Since, &stuckAfterJSON, &age.Warned, &age.Escalated); err != nil {
+			return nil, err
+		}
+		if stuckAfterJSON.Valid {
+			if err := json.Unmarshal([]byte(stuckAfterJSON.String), &age.StuckAfter); err != nil {
+				return nil, fmt.Errorf("failed to decode stuck thresholds for job %s: %w", age.JobID, err)
+			}
+		}
+		ages = append(ages, age)
+	}
+	return ages, rows.Err()
 }
 
-// UpdateJobCost updates the running cost for a job
-func (r *JobRepository) UpdateJobCost(jobID string, cost float64) error {
-	query := `UPDATE jobs SET cost_running_usd = $1, updated_at = NOW() WHERE id = $2`
-	_, err := r.db.Exec(query, cost, jobID)
-	return err
+// JobQueueAge is a pending job with a queue-time alarm and how long it has
+// waited since it last entered pending
+type JobQueueAge struct {
+	JobID        string
+	UserID       string
+	Name         string
+	Since        time.Time
+	MaxQueueTime time.Duration // 0 = no warning
+	CancelAfter  time.Duration // 0 = never cancelled
+	Warned       bool          // A queue_time_exceeded event was recorded since
+}
+
+// ListQueueAges returns the pending jobs that have a queue-time alarm
+func (r *JobRepository) ListQueueAges() ([]JobQueueAge, error) {
+	rows, err := r.db.Query(`
+		SELECT s.id, s.user_id, s.name, s.since, s.max_queue_seconds, s.queue_cancel_seconds,
+			EXISTS (SELECT 1 FROM job_events w WHERE w.job_id = s.id AND w.reason = 'queue_time_exceeded' AND w.at >= s.since)
+		FROM (
+			SELECT j.id, j.user_id, j.name, j.max_queue_seconds, j.queue_cancel_seconds,
+				COALESCE((
+					SELECT MAX(e.at) FROM job_events e
+					WHERE e.job_id = j.id AND e.to_status = j.status AND e.from_status IS DISTINCT FROM e.to_status
+				), j.created_at) AS since
+			FROM jobs j
+			WHERE j.status = 'pending' AND (j.max_queue_seconds IS NOT NULL OR j.queue_cancel_seconds IS NOT NULL)
+		) s
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ages []JobQueueAge
+	for rows.Next() {
+		var age JobQueueAge
+		var maxQueueSeconds, cancelSeconds sql.NullInt64
+		if err := rows.Scan(&age.JobID, &age.UserID, &age.Name, &age.Since, &maxQueueSeconds, &cancelSeconds, &age.Warned); err != nil {
+			return nil, err
+		}
+		age.MaxQueueTime = time.Duration(maxQueueSeconds.Int64) * time.Second
+		age.CancelAfter = time.Duration(cancelSeconds.Int64) * time.Second
+		ages = append(ages, age)
+	}
+	return ages, rows.Err()
+}
+
+// SetSuspendRequested records when a job's suspension was requested; nil
+// clears it once the job is resumed
+func (r *JobRepository) SetSuspendRequested(jobID string, at *time.Time) error {
+	var requestedAt sql.NullTime
+	if at != nil {
+		requestedAt = sql.NullTime{Time: *at, Valid: true}
+	}
+	_, err := r.db.Exec(`UPDATE jobs SET suspend_requested_at = $1, updated_at = $3 WHERE id = $2`, requestedAt, jobID, r.db.Now())
+	return err
+}
+
+// JobSuspending is a job waiting for the checkpoint of its suspension
+type JobSuspending struct {
+	JobID       string
+	RequestedAt time.Time
+}
+
+// ListSuspending returns the checkpointing jobs whose suspension was requested
+func (r *JobRepository) ListSuspending() ([]JobSuspending, error) {
+	rows, err := r.db.Query(`
+		SELECT id, suspend_requested_at FROM jobs
+		WHERE status = 'checkpointing' AND suspend_requested_at IS NOT NULL
+		ORDER BY suspend_requested_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var suspending []JobSuspending
+	for rows.Next() {
+		var job JobSuspending
+		if err := rows.Scan(&job.JobID, &job.RequestedAt); err != nil {
+			return nil, err
+		}
+		suspending = append(suspending, job)
+	}
+	return suspending, rows.Err()
+}
+
+// SetWaitReasons stores why the scheduler's last pass deferred a pending job;
+// nil clears them
+func (r *JobRepository) SetWaitReasons(jobID string, reasons []models.WaitReason) error {
+	var reasonsJSON interface{}
+	if len(reasons) > 0 {
+		encoded, err := json.Marshal(reasons)
+		if err != nil {
+			return fmt.Errorf("failed to encode wait reasons: %w", err)
+		}
+		reasonsJSON = string(encoded)
+	}
+	_, err := r.db.Exec(`UPDATE jobs SET wait_reasons_json = $1 WHERE id = $2`, reasonsJSON, jobID)
+	return err
+}
+
+// GetWaitReasons returns the wait reasons recorded for a job
+func (r *JobRepository) GetWaitReasons(jobID string) ([]models.WaitReason, error) {
+	var reasonsJSON sql.NullString
+	if err := r.db.QueryRow(`SELECT wait_reasons_json FROM jobs WHERE id = $1`, jobID).Scan(&reasonsJSON); err != nil {
+		return nil, err
+	}
+	if !reasonsJSON.Valid {
+		return nil, nil
+	}
+
+	var reasons []models.WaitReason
+	if err := json.Unmarshal([]byte(reasonsJSON.String), &reasons); err != nil {
+		return nil, fmt.Errorf("failed to decode wait reasons: %w", err)
+	}
+	return reasons, nil
+}
+
+// ListDeadlineJobs loads the unfinished jobs that have a deadline. Suspended
+// jobs are left out: their owner paused them.
+func (r *JobRepository) ListDeadlineJobs() ([]*models.Job, error) {
+	rows, err := r.db.Query(`
+		SELECT id FROM jobs
+		WHERE deadline_at IS NOT NULL
+			AND status IN ('pending', 'scheduled', 'provisioning', 'running', 'checkpointing')
+		ORDER BY deadline_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*models.Job, 0, len(ids))
+	for _, id := range ids {
+		job, err := r.GetJob(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load job %s: %w", id, err)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// JobStartTiming is when a recently started job was created, first
+// scheduled and started
+type JobStartTiming struct {
+	CreatedAt   time.Time
+	ScheduledAt time.Time
+	StartedAt   time.Time
+}
+
+// ListStartTimings returns the timings of the jobs that started since the
+// given time and went through the scheduler
+func (r *JobRepository) ListStartTimings(since time.Time) ([]JobStartTiming, error) {
+	rows, err := r.db.Query(`
+		SELECT s.created_at, s.scheduled_at, s.started_at
+		FROM (
+			SELECT j.created_at, j.started_at,
+				(SELECT MIN(e.at) FROM job_events e WHERE e.job_id = j.id AND e.to_status = 'scheduled') AS scheduled_at
+			FROM jobs j
+			WHERE j.started_at >= $1
+		) s
+		WHERE s.scheduled_at IS NOT NULL
+	`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var timings []JobStartTiming
+	for rows.Next() {
+		var timing JobStartTiming
+		if err := rows.Scan(&timing.CreatedAt, &timing.ScheduledAt, &timing.StartedAt); err != nil {
+			return nil, err
+		}
+		timings = append(timings, timing)
+	}
+	return timings, rows.Err()
+}
+
+// SetDeadlineRisk stores a job's latest deadline risk assessment
+func (r *JobRepository) SetDeadlineRisk(jobID string, risk *models.DeadlineRisk) error {
+	riskJSON, err := json.Marshal(risk)
+	if err != nil {
+		return fmt.Errorf("failed to encode deadline risk: %w", err)
+	}
+	_, err = r.db.Exec(`UPDATE jobs SET deadline_risk_json = $1 WHERE id = $2`, string(riskJSON), jobID)
+	return err
+}
+
+// GetDeadlineRisk returns a job's latest deadline risk assessment, or nil if
+// it has not been assessed
+func (r *JobRepository) GetDeadlineRisk(jobID string) (*models.DeadlineRisk, error) {
+	var riskJSON sql.NullString
+	if err := r.db.QueryRow(`SELECT deadline_risk_json FROM jobs WHERE id = $1`, jobID).Scan(&riskJSON); err != nil {
+		return nil, err
+	}
+	if !riskJSON.Valid {
+		return nil, nil
+	}
+
+	var risk models.DeadlineRisk
+	if err := json.Unmarshal([]byte(riskJSON.String), &risk); err != nil {
+		return nil, fmt.Errorf("failed to decode deadline risk: %w", err)
+	}
+	return &risk, nil
+}
+
+// EscalateDeadlineRisk records that a job was notified of a risk level. It
+// returns false when the job was already notified of that level or a higher
+// one, so each level is notified once.
+func (r *JobRepository) EscalateDeadlineRisk(jobID, level string) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE jobs SET deadline_risk_notified = $2
+		WHERE id = $1 AND deadline_risk_notified < $2
+	`, jobID, models.DeadlineRiskRank(level))
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// SetTopology stores a job's topology manifest
+func (r *JobRepository) SetTopology(jobID string, manifest *models.TopologyManifest) error {
+	manifestJSON, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to encode topology: %w", err)
+	}
+	_, err = r.db.Exec(`UPDATE jobs SET topology_json = $1 WHERE id = $2`, string(manifestJSON), jobID)
+	return err
+}
+
+// GetTopology returns a job's topology manifest, or nil if it was never launched
+func (r *JobRepository) GetTopology(jobID string) (*models.TopologyManifest, error) {
+	var manifestJSON sql.NullString
+	if err := r.db.QueryRow(`SELECT topology_json FROM jobs WHERE id = $1`, jobID).Scan(&manifestJSON); err != nil {
+		return nil, err
+	}
+	if !manifestJSON.Valid {
+		return nil, nil
+	}
+
+	var manifest models.TopologyManifest
+	if err := json.Unmarshal([]byte(manifestJSON.String), &manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode topology: %w", err)
+	}
+	return &manifest, nil
+}
+
+// SetAllocationDecision stores the optimizer's latest decision for a job
+func (r *JobRepository) SetAllocationDecision(jobID string, decision *models.AllocationDecision) error {
+	decisionJSON, err := json.Marshal(decision)
+	if err != nil {
+		return fmt.Errorf("failed to encode allocation decision: %w", err)
+	}
+	_, err = r.db.Exec(`UPDATE jobs SET decision_json = $1, updated_at = $3 WHERE id = $2`, string(decisionJSON), jobID, r.db.Now())
+	return err
+}
+
+// GetAllocationDecision returns the optimizer's latest decision for a job,
+// or nil if the job has not been through the optimizer yet
+func (r *JobRepository) GetAllocationDecision(jobID string) (*models.AllocationDecision, error) {
+	var decisionJSON sql.NullString
+	err := r.db.QueryRow(`SELECT decision_json FROM jobs WHERE id = $1`, jobID).Scan(&decisionJSON)
+	if err != nil {
+		return nil, err
+	}
+	if !decisionJSON.Valid {
+		return nil, nil
+	}
+
+	var decision models.AllocationDecision
+	if err := json.Unmarshal([]byte(decisionJSON.String), &decision); err != nil {
+		return nil, fmt.Errorf("failed to decode allocation decision: %w", err)
+	}
+	return &decision, nil
+}
+
+// toInt64s converts an int slice for use with pq.Array
+// durationSeconds stores a duration as whole seconds; 0 is stored as NULL
+func durationSeconds(d time.Duration) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(d / time.Second), Valid: d > 0}
+}
+
+func toInt64s(values []int) []int64 {
+	result := make([]int64, len(values))
+	for i, v := range values {
+		result[i] = int64(v)
+	}
+	return result
+}
+
+// ErrStatusConflict is returned by UpdateJobStatus when the job's status is no
+// longer the expected one, typically because another writer changed it first.
+// Callers should re-read the job and usually skip their update.
+var ErrStatusConflict = errors.New("job status changed concurrently")
+
+// UpdateJobStatus moves a job from fromStatus to toStatus and records the
+// event. The transition must be legal (models.ErrIllegalTransition otherwise),
+// and the job is locked and updated only if it is still in fromStatus
+// (ErrStatusConflict otherwise).
+func (r *JobRepository) UpdateJobStatus(jobID string, fromStatus, toStatus models.JobStatus, reason string, meta map[string]interface{}) error {
+	if err := models.ValidateTransition(fromStatus, toStatus); err != nil {
+		return fmt.Errorf("job %s: %w", jobID, err)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var current models.JobStatus
+	if err := tx.QueryRow(`SELECT status FROM jobs WHERE id = $1 FOR UPDATE`, jobID).Scan(&current); err != nil {
+		return err
+	}
+	if current != fromStatus {
+		return fmt.Errorf("%w: job %s is %s, not %s (wanted %s)", ErrStatusConflict, jobID, current, fromStatus, toStatus)
+	}
+
+	// The status, its event and its audit entry share one timestamp
+	now := r.db.Now()
+	updateQuery := `UPDATE jobs SET status = $1, updated_at = $4 WHERE id = $2 AND status = $3`
+	result, err := tx.Exec(updateQuery, toStatus, jobID, fromStatus, now)
+	if err != nil {
+		return err
+	}
+	if updated, err := result.RowsAffected(); err == nil && updated == 0 {
+		return fmt.Errorf("%w: job %s left %s", ErrStatusConflict, jobID, fromStatus)
+	}
+
+	// Create event
+	err = r.createJobEventTx(tx, jobID, &fromStatus, toStatus, reason, meta, now)
+	if err != nil {
+		return err
+	}
+
+	// Audit the change; API callers pass themselves as meta["actor"]
+	actor, _ := meta["actor"].(string)
+	if actor == "" {
+		actor = models.ActorSystem
+	}
+	if err := insertAuditEntry(tx.Exec, &models.AuditEntry{
+		Actor:        actor,
+		Action:       models.AuditJobStatusChanged,
+		CreatedAt:    now,
+		ResourceType: "jobs",
+		ResourceID:   jobID,
+		Request: map[string]interface{}{
+			"from":   fromStatus,
+			"to":     toStatus,
+			"reason": reason,
+		},
+	}); err != nil {
+		return fmt.Errorf("failed to audit status change of job %s: %w", jobID, err)
+	}
+
+	return tx.Commit()
+}
+
+// CreateJobEvent creates a job event
+func (r *JobRepository) CreateJobEvent(jobID string, fromStatus *models.JobStatus, toStatus models.JobStatus, reason string, meta map[string]interface{}) error {
+	return r.createJobEventAt(jobID, fromStatus, toStatus, reason, meta, r.db.Now())
+}
+
+// createJobEventAt creates a job event stamped at
+func (r *JobRepository) createJobEventAt(jobID string, fromStatus *models.JobStatus, toStatus models.JobStatus, reason string, meta map[string]interface{}, at time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = r.createJobEventTx(tx, jobID, fromStatus, toStatus, reason, meta, at)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *JobRepository) createJobEventTx(tx *Tx, jobID string, fromStatus *models.JobStatus, toStatus models.JobStatus, reason string, meta map[string]interface{}, at time.Time) error {
+	query := `
+		INSERT INTO job_events (job_id, from_status, to_status, reason, meta_json, at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	var fromStatusStr *string
+	if fromStatus != nil {
+		s := string(*fromStatus)
+		fromStatusStr = &s
+	}
+
+	_, err := tx.Exec(query, jobID, fromStatusStr, toStatus, reason, eventMetaJSON(meta), at)
+	return err
+}
+
+// eventMetaJSON serializes event metadata. Errors are stored as their
+// message, and values JSON cannot encode as their fmt representation, so an
+// event is never dropped or stripped over one field.
+func eventMetaJSON(meta map[string]interface{}) string {
+	if len(meta) == 0 {
+		return "{}"
+	}
+	safe := make(map[string]interface{}, len(meta))
+	for key, value := range meta {
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		} else if _, err := json.Marshal(value); err != nil {
+			value = fmt.Sprintf("%v", value)
+		}
+		safe[key] = value
+	}
+	data, err := json.Marshal(safe)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// ErrInvalidCursor is returned by ListJobs for a cursor it did not issue
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// jobCursor is the position after the last job of a ListJobs page. Jobs are
+// listed newest first by (created_at, id), so later inserts never shift pages.
+type jobCursor struct {
+	CreatedAt time.Time `json:"c"`
+	ID        string    `json:"i"`
+}
+
+// encodeJobCursor returns the opaque token for the position after a job
+func encodeJobCursor(job *models.Job) string {
+	data, _ := json.Marshal(jobCursor{CreatedAt: job.CreatedAt, ID: job.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeJobCursor parses a token issued by encodeJobCursor
+func decodeJobCursor(token string) (*jobCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor jobCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == "" || cursor.CreatedAt.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// ListJobs lists jobs with optional filters, newest first. cursor is the
+// next cursor of the previous page ("" for the first); the returned next
+// cursor is "" when no jobs are left. Returns ErrInvalidCursor for a cursor
+// it did not issue.
+func (r *JobRepository) ListJobs(userID string, status *models.JobStatus, limit int, cursor string) ([]*models.Job, string, error) {
+	query := `
+		SELECT id, user_id, name, job_type, framework, status, created_at,
+			budget_usd, deadline_at, priority_boost, emissions_gco2e
+		FROM jobs
+		WHERE true
+	`
+	var args []interface{}
+	argIndex := 1
+
+	// An empty user ID lists every user's jobs (e.g. the scheduler loading pending jobs)
+	if userID != "" {
+		query += fmt.Sprintf(" AND user_id = $%d", argIndex)
+		args = append(args, userID)
+		argIndex++
+	}
+
+	if status != nil {
+		query += fmt.Sprintf(" AND status = $%d", argIndex)
+		args = append(args, *status)
+		argIndex++
+	}
+
+	if cursor != "" {
+		after, err := decodeJobCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		query += fmt.Sprintf(" AND (created_at, id) < ($%d, $%d)", argIndex, argIndex+1)
+		args = append(args, after.CreatedAt, after.ID)
+		argIndex += 2
+	}
+
+	// One extra row tells whether another page exists
+	query += fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d", argIndex)
+	args = append(args, limit+1)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var jobs []*models.Job
+	for rows.Next() {
+		var job models.Job
+		var deadlineAt sql.NullTime
+		var emissions sql.NullFloat64
+		err := rows.Scan(
+			&job.ID,
+			&job.UserID,
+			&job.Name,
+			&job.JobType,
+			&job.Framework,
+			&job.Status,
+			&job.CreatedAt,
+			&job.Constraints.MaxBudget,
+			&deadlineAt,
+			&job.PriorityBoost,
+			&emissions,
+		)
+		if err != nil {
+			return nil, "", err
+		}
+		if deadlineAt.Valid {
+			job.Constraints.Deadline = &deadlineAt.Time
+		}
+		if emissions.Valid {
+			job.EmissionsGCO2e = &emissions.Float64
+		}
+		jobs = append(jobs, &job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	nextCursor := ""
+	if len(jobs) > limit {
+		jobs = jobs[:limit]
+		if limit > 0 {
+			nextCursor = encodeJobCursor(jobs[limit-1])
+		}
+	}
+
+	return jobs, nextCursor, nil
+}
+
+// UpdateJobCost updates the running cost for a job
+func (r *JobRepository) UpdateJobCost(jobID string, cost float64) error {
+	query := `UPDATE jobs SET cost_running_usd = $1, updated_at = $3 WHERE id = $2`
+	_, err := r.db.Exec(query, cost, jobID, r.db.Now())
+	return err
+}
+
+// AddJobTransferCost charges data transfer, e.g. a checkpoint replica, to a job
+func (r *JobRepository) AddJobTransferCost(jobID string, costUSD float64) error {
+	_, err := r.db.Exec(`
+		UPDATE jobs SET cost_transfer_usd = cost_transfer_usd + $2, updated_at = $3
+		WHERE id = $1
+	`, jobID, costUSD, r.db.Now())
+	return err
+}
+
+// costBatchSize bounds the jobs written by one UpdateJobCosts statement
+const costBatchSize = 500
+
+// UpdateJobCosts stores the running costs of many jobs, one statement per
+// costBatchSize jobs
+func (r *JobRepository) UpdateJobCosts(costs map[string]float64) error {
+	ids := make([]string, 0, len(costs))
+	for id := range costs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids) // Stable lock order across concurrent writers
+
+	now := r.db.Now()
+	for start := 0; start < len(ids); start += costBatchSize {
+		batch := ids[start:min(start+costBatchSize, len(ids))]
+		var cases strings.Builder
+		idList := make([]string, len(batch))
+		args := make([]interface{}, 0, 2*len(batch)+1)
+		for i, id := range batch {
+			fmt.Fprintf(&cases, " WHEN $%d THEN CAST($%d AS numeric)", 2*i+1, 2*i+2)
+			idList[i] = fmt.Sprintf("$%d", 2*i+1)
+			args = append(args, id, costs[id])
+		}
+		args = append(args, now)
+		query := `UPDATE jobs SET cost_running_usd = CASE id` + cases.String() + fmt.Sprintf(` END, updated_at = $%d
+			WHERE id IN (`, len(args)) + strings.Join(idList, ", ") + `)`
+		if _, err := r.db.Exec(query, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetJobStatuses returns the status of each of the given jobs that exists
+func (r *JobRepository) GetJobStatuses(ids []string) (map[string]models.JobStatus, error) {
+	statuses := make(map[string]models.JobStatus, len(ids))
+	for start := 0; start < len(ids); start += costBatchSize {
+		batch := ids[start:min(start+costBatchSize, len(ids))]
+		args := make([]interface{}, len(batch))
+		for i, id := range batch {
+			args[i] = id
+		}
+		rows, err := r.db.Query(`SELECT id, status FROM jobs WHERE id IN (`+inPlaceholders(1, len(batch))+`)`, args...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var id string
+			var status models.JobStatus
+			if err := rows.Scan(&id, &status); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			statuses[id] = status
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return statuses, nil
+}
+
+// SetEmissions stores the estimated emissions of a finished job
+func (r *JobRepository) SetEmissions(jobID string, gco2e float64) error {
+	_, err := r.db.Exec(`UPDATE jobs SET emissions_gco2e = $1, updated_at = $3 WHERE id = $2`, gco2e, jobID, r.db.Now())
+	return err
+}
+
+// ListUnaccountedJobIDs returns finished jobs without estimated emissions,
+// longest finished first, up to limit
+func (r *JobRepository) ListUnaccountedJobIDs(limit int) ([]string, error) {
+	rows, err := r.db.Query(`
+		SELECT id FROM jobs
+		WHERE emissions_gco2e IS NULL AND status IN ('completed', 'failed', 'cancelled')
+		ORDER BY updated_at
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ListSpotCheckpointedJobIDs returns running jobs that declare a checkpoint
+// schedule and hold spot capacity
+func (r *JobRepository) ListSpotCheckpointedJobIDs() ([]string, error) {
+	rows, err := r.db.Query(`
+		SELECT DISTINCT j.id
+		FROM jobs j
+		JOIN allocations a ON a.job_id = j.id
+		WHERE j.status = 'running' AND j.checkpointing_json IS NOT NULL
+			AND a.spot AND a.status NOT IN ('failed', 'terminated')
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// JobCostRecord is the cost recorded so far for an active job
+type JobCostRecord struct {
+	JobID     string
+	Status    models.JobStatus
+	StartedAt *time.Time
+	CostUSD   float64 // cost_running_usd
+}
+
+// ListActiveJobCosts returns the recorded cost of every provisioning,
+// running, checkpointing or cancelling job
+func (r *JobRepository) ListActiveJobCosts() ([]JobCostRecord, error) {
+	rows, err := r.db.Query(`
+		SELECT id, status, started_at, cost_running_usd
+		FROM jobs
+		WHERE status IN ('provisioning', 'running', 'checkpointing', 'cancelling')
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []JobCostRecord
+	for rows.Next() {
+		var record JobCostRecord
+		var startedAt sql.NullTime
+		if err := rows.Scan(&record.JobID, &record.Status, &startedAt, &record.CostUSD); err != nil {
+			return nil, err
+		}
+		if startedAt.Valid {
+			record.StartedAt = &startedAt.Time
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// TeamCostRollup returns the job count, cost and estimated emissions of every
+// team's jobs created in [from, to), costliest first
+func (r *JobRepository) TeamCostRollup(from, to time.Time) ([]models.TeamCost, error) {
+	rows, err := r.db.Query(`
+		SELECT COALESCE(team_id, ''), COUNT(*),
+			COALESCE(SUM(CASE WHEN cost_running_usd = 0 AND status = 'completed'
+				THEN COALESCE(cost_estimated_usd, 0) ELSE cost_running_usd END), 0) AS cost,
+			COALESCE(SUM(emissions_gco2e), 0)
+		FROM jobs
+		WHERE created_at >= $1 AND created_at < $2
+		GROUP BY COALESCE(team_id, '')
+		ORDER BY cost DESC, 1
+	`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var costs []models.TeamCost
+	for rows.Next() {
+		var cost models.TeamCost
+		if err := rows.Scan(&cost.TeamID, &cost.Jobs, &cost.CostUSD, &cost.EmissionsGCO2e); err != nil {
+			return nil, err
+		}
+		costs = append(costs, cost)
+	}
+	return costs, rows.Err()
+}
+
+// AttachJobIdentity records a cloud identity attached to the job's instances,
+// stamped now unless AttachedAt is set
+func (r *JobRepository) AttachJobIdentity(jobID string, identity models.JobIdentity) error {
+	if identity.AttachedAt.IsZero() {
+		identity.AttachedAt = r.db.Now()
+	}
+	grantsJSON, err := json.Marshal(identity.Grants)
+	if err != nil {
+		return fmt.Errorf("failed to encode identity grants: %w", err)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var status models.JobStatus
+	if err := tx.QueryRow(`SELECT status FROM jobs WHERE id = $1`, jobID).Scan(&status); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO job_identities (job_id, provider, kind, name, managed, grants_json, attached_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (job_id, provider, name) DO UPDATE SET
+			grants_json = excluded.grants_json, attached_at = excluded.attached_at, deleted_at = NULL
+	`, jobID, identity.Provider, identity.Kind, identity.Name, identity.Managed, string(grantsJSON), identity.AttachedAt)
+	if err != nil {
+		return err
+	}
+
+	meta := map[string]interface{}{
+		"provider": identity.Provider,
+		"kind":     identity.Kind,
+		"name":     identity.Name,
+		"managed":  identity.Managed,
+	}
+	if err := r.createJobEventTx(tx, jobID, &status, status, "identity_attached", meta, identity.AttachedAt); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// MarkJobIdentityDeleted records that a managed identity was deleted
+func (r *JobRepository) MarkJobIdentityDeleted(jobID string, identity models.JobIdentity) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var status models.JobStatus
+	if err := tx.QueryRow(`SELECT status FROM jobs WHERE id = $1`, jobID).Scan(&status); err != nil {
+		return err
+	}
+
+	now := r.db.Now()
+	_, err = tx.Exec(`
+		UPDATE job_identities SET deleted_at = $4
+		WHERE job_id = $1 AND provider = $2 AND name = $3
+	`, jobID, identity.Provider, identity.Name, now)
+	if err != nil {
+		return err
+	}
+
+	meta := map[string]interface{}{
+		"provider": identity.Provider,
+		"kind":     identity.Kind,
+		"name":     identity.Name,
+	}
+	if err := r.createJobEventTx(tx, jobID, &status, status, "identity_deleted", meta, now); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ListJobIdentities returns the identities attached to a job, oldest first
+func (r *JobRepository) ListJobIdentities(jobID string) ([]models.JobIdentity, error) {
+	rows, err := r.db.Query(`
+		SELECT provider, kind, name, managed, grants_json, attached_at, deleted_at
+		FROM job_identities
+		WHERE job_id = $1
+		ORDER BY attached_at
+	`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var identities []models.JobIdentity
+	for rows.Next() {
+		identity, err := scanJobIdentity(rows)
+		if err != nil {
+			return nil, err
+		}
+		identities = append(identities, identity)
+	}
+	return identities, rows.Err()
+}
+
+// ListStaleJobIdentities returns the managed identities not yet deleted of
+// jobs that have ended, keyed by job ID
+func (r *JobRepository) ListStaleJobIdentities() (map[string][]models.JobIdentity, error) {
+	rows, err := r.db.Query(`
+		SELECT i.job_id, i.provider, i.kind, i.name, i.managed, i.grants_json, i.attached_at, i.deleted_at
+		FROM job_identities i
+		JOIN jobs j ON j.id = i.job_id
+		WHERE i.managed AND i.deleted_at IS NULL
+			AND j.status IN ('completed', 'failed', 'cancelled')
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stale := make(map[string][]models.JobIdentity)
+	for rows.Next() {
+		var jobID string
+		identity, err := scanJobIdentity(rows, &jobID)
+		if err != nil {
+			return nil, err
+		}
+		stale[jobID] = append(stale[jobID], identity)
+	}
+	return stale, rows.Err()
+}
+
+// scanJobIdentity scans a job_identities row, after any leading columns in dest
+func scanJobIdentity(rows *sql.Rows, dest ...interface{}) (models.JobIdentity, error) {
+	var identity models.JobIdentity
+	var grantsJSON []byte
+	var deletedAt sql.NullTime
+	dest = append(dest, &identity.Provider, &identity.Kind, &identity.Name, &identity.Managed,
+		&grantsJSON, &identity.AttachedAt, &deletedAt)
+	if err := rows.Scan(dest...); err != nil {
+		return identity, err
+	}
+	if len(grantsJSON) > 0 {
+		if err := json.Unmarshal(grantsJSON, &identity.Grants); err != nil {
+			return identity, fmt.Errorf("failed to decode identity grants: %w", err)
+		}
+	}
+	if deletedAt.Valid {
+		identity.DeletedAt = &deletedAt.Time
+	}
+	return identity, nil
+}
+
+// nonNilStrings returns an empty slice for nil so pq.Array stores '{}', not NULL
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
 }
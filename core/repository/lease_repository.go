@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+// LeaseRepository handles database operations for leader leases
+type LeaseRepository struct {
+	db *DB
+}
+
+// NewLeaseRepository creates a new lease repository
+func NewLeaseRepository(db *DB) *LeaseRepository {
+	return &LeaseRepository{db: db}
+}
+
+// Lease is the current holder of a leader lease
+type Lease struct {
+	Name       string
+	Holder     string
+	AcquiredAt time.Time
+	RenewedAt  time.Time
+	ExpiresAt  time.Time
+}
+
+// TryAcquire takes or renews the named lease for holder. It succeeds if the
+// lease is free, expired or already held by holder. Expiry uses the database
+// clock so replicas with skewed clocks agree.
+func (r *LeaseRepository) TryAcquire(name, holder string, ttl time.Duration) (bool, error) {
+	query := `
+		INSERT INTO leader_leases (name, holder, acquired_at, renewed_at, expires_at)
+		VALUES ($1, $2, NOW(), NOW(), NOW() + make_interval(secs => $3))
+		ON CONFLICT (name) DO UPDATE SET
+			holder = EXCLUDED.holder,
+			acquired_at = CASE WHEN leader_leases.holder = EXCLUDED.holder
+				THEN leader_leases.acquired_at ELSE NOW() END,
+			renewed_at = NOW(),
+			expires_at = EXCLUDED.expires_at
+		WHERE leader_leases.holder = EXCLUDED.holder OR leader_leases.expires_at < NOW()
+		RETURNING holder
+	`
+
+	var current string
+	err := r.db.QueryRow(query, name, holder, ttl.Seconds()).Scan(&current)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return current == holder, nil
+}
+
+// Release expires the named lease if holder still holds it, so another
+// replica can take over without waiting for the TTL
+func (r *LeaseRepository) Release(name, holder string) error {
+	_, err := r.db.Exec(`
+		UPDATE leader_leases SET expires_at = NOW() - INTERVAL '1 second'
+		WHERE name = $1 AND holder = $2
+	`, name, holder)
+	return err
+}
+
+// GetLease returns the named lease, or nil if it was never acquired
+func (r *LeaseRepository) GetLease(name string) (*Lease, error) {
+	lease := Lease{Name: name}
+	err := r.db.QueryRow(`
+		SELECT holder, acquired_at, renewed_at, expires_at FROM leader_leases WHERE name = $1
+	`, name).Scan(&lease.Holder, &lease.AcquiredAt, &lease.RenewedAt, &lease.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &lease, nil
+}
@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"gpu-orchestrator/core/models"
+)
+
+// ErrImageExists is returned when an image is already registered for the
+// provider, region and instance family
+var ErrImageExists = errors.New("machine image already registered")
+
+// MachineImageRepository handles the machine image registry and the boot
+// times observed for its images
+type MachineImageRepository struct {
+	db *DB
+}
+
+// NewMachineImageRepository creates a new machine image repository
+func NewMachineImageRepository(db *DB) *MachineImageRepository {
+	return &MachineImageRepository{db: db}
+}
+
+// machineImageColumns are the columns scanMachineImage reads, in order
+const machineImageColumns = `id, provider, region, instance_family, image_id, build_date,
+	validation, validated_at, boot_budget_seconds, degraded_at, degraded_reason,
+	created_at, updated_at`
+
+// CreateImage registers an image and sets its ID and timestamps. Returns
+// ErrImageExists when it is already registered for its family.
+func (r *MachineImageRepository) CreateImage(image *models.MachineImage) error {
+	now := r.db.Now()
+	image.ValidatedAt = nil
+	if image.Validation == models.ImageValidationValidated {
+		image.ValidatedAt = &now
+	}
+	err := r.db.QueryRow(`
+		INSERT INTO machine_images (
+			provider, region, instance_family, image_id, build_date, validation,
+			validated_at, boot_budget_seconds, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)
+		ON CONFLICT (provider, region, instance_family, image_id) DO NOTHING
+		RETURNING id
+	`,
+		image.Provider,
+		image.Region,
+		image.InstanceFamily,
+		image.ImageID,
+		image.BuildDate,
+		image.Validation,
+		image.ValidatedAt,
+		int(image.BootBudget.Seconds()),
+		now,
+	).Scan(&image.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrImageExists
+	}
+	if err != nil {
+		return err
+	}
+	image.CreatedAt, image.UpdatedAt = now, now
+	image.DegradedAt, image.DegradedReason = nil, ""
+	return nil
+}
+
+// UpdateImage sets an image's build date, validation and boot budget.
+// Validating an image again clears its degradation, and only boots observed
+// from then on count toward the next one. Returns sql.ErrNoRows when the
+// image is not registered.
+func (r *MachineImageRepository) UpdateImage(id int64, buildDate *time.Time, validation models.ImageValidation, budget time.Duration) (*models.MachineImage, error) {
+	now := r.db.Now()
+	revalidate := validation == models.ImageValidationValidated
+	_, err := r.db.Exec(`
+		UPDATE machine_images
+		SET build_date = $2, validation = $3, boot_budget_seconds = $4, updated_at = $5,
+			validated_at = CASE WHEN $6 THEN $5 ELSE validated_at END,
+			degraded_at = CASE WHEN $6 THEN NULL ELSE degraded_at END,
+			degraded_reason = CASE WHEN $6 THEN NULL ELSE degraded_reason END
+		WHERE id = $1
+	`, id, buildDate, validation, int(budget.Seconds()), now, revalidate)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetImage(id)
+}
+
+// DeleteImage removes an image and its boots from the registry. Returns
+// sql.ErrNoRows when it is not registered.
+func (r *MachineImageRepository) DeleteImage(id int64) error {
+	result, err := r.db.Exec(`DELETE FROM machine_images WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetImage returns a registered image
+func (r *MachineImageRepository) GetImage(id int64) (*models.MachineImage, error) {
+	return scanMachineImage(r.db.QueryRow(`SELECT `+machineImageColumns+` FROM machine_images WHERE id = $1`, id))
+}
+
+// ListImages returns the registry ordered by provider, region, family and
+// newest build first. provider filters when set.
+func (r *MachineImageRepository) ListImages(provider models.Provider) ([]models.MachineImage, error) {
+	return r.queryImages(`
+		SELECT `+machineImageColumns+` FROM machine_images
+		WHERE ($1 = '' OR CAST(provider AS text) = $1)
+		ORDER BY provider, region, instance_family, build_date IS NULL, build_date DESC, id DESC
+	`, string(provider))
+}
+
+// ImagesByImageID returns the registrations of a provider image, one per
+// provider, region and instance family it is registered for
+func (r *MachineImageRepository) ImagesByImageID(imageID string) ([]models.MachineImage, error) {
+	return r.queryImages(`
+		SELECT `+machineImageColumns+` FROM machine_images
+		WHERE image_id = $1
+		ORDER BY provider, region, instance_family
+	`, imageID)
+}
+
+// FamilyImages returns the images registered for an instance family in a
+// region, newest build first
+func (r *MachineImageRepository) FamilyImages(provider models.Provider, region, family string) ([]models.MachineImage, error) {
+	return r.queryImages(`
+		SELECT `+machineImageColumns+` FROM machine_images
+		WHERE provider = $1 AND region = $2 AND instance_family = $3
+		ORDER BY build_date IS NULL, build_date DESC, id DESC
+	`, provider, region, family)
+}
+
+// RecordBoot stores an observed bootstrap of an image's instances
+func (r *MachineImageRepository) RecordBoot(imageID int64, boot models.ImageBoot) error {
+	_, err := r.db.Exec(`
+		INSERT INTO machine_image_boots (machine_image_id, job_id, instance_type, boot_seconds, observed_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, imageID, nullString(boot.JobID), boot.InstanceType, boot.BootSeconds, boot.ObservedAt)
+	return err
+}
+
+// RecentBoots returns an image's latest limit boots since it was last
+// validated (or registered), newest first
+func (r *MachineImageRepository) RecentBoots(imageID int64, limit int) ([]models.ImageBoot, error) {
+	rows, err := r.db.Query(`
+		SELECT b.job_id, b.instance_type, b.boot_seconds, b.observed_at
+		FROM machine_image_boots b
+		JOIN machine_images m ON m.id = b.machine_image_id
+		WHERE b.machine_image_id = $1 AND b.observed_at >= COALESCE(m.validated_at, m.created_at)
+		ORDER BY b.observed_at DESC, b.id DESC
+		LIMIT $2
+	`, imageID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var boots []models.ImageBoot
+	for rows.Next() {
+		var boot models.ImageBoot
+		var jobID sql.NullString
+		if err := rows.Scan(&jobID, &boot.InstanceType, &boot.BootSeconds, &boot.ObservedAt); err != nil {
+			return nil, err
+		}
+		boot.JobID = jobID.String
+		boots = append(boots, boot)
+	}
+	return boots, rows.Err()
+}
+
+// MarkDegraded flags an image degraded. Returns false when it already was.
+func (r *MachineImageRepository) MarkDegraded(id int64, reason string, at time.Time) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE machine_images
+		SET degraded_at = $2, degraded_reason = $3, updated_at = $2
+		WHERE id = $1 AND degraded_at IS NULL
+	`, id, at, reason)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// queryImages runs a query selecting machineImageColumns
+func (r *MachineImageRepository) queryImages(query string, args ...interface{}) ([]models.MachineImage, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var images []models.MachineImage
+	for rows.Next() {
+		image, err := scanMachineImage(rows)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, *image)
+	}
+	return images, rows.Err()
+}
+
+// scanMachineImage scans machineImageColumns
+func scanMachineImage(row rowScanner) (*models.MachineImage, error) {
+	var image models.MachineImage
+	var buildDate, validatedAt, degradedAt sql.NullTime
+	var degradedReason sql.NullString
+	var budgetSeconds int
+	err := row.Scan(
+		&image.ID,
+		&image.Provider,
+		&image.Region,
+		&image.InstanceFamily,
+		&image.ImageID,
+		&buildDate,
+		&image.Validation,
+		&validatedAt,
+		&budgetSeconds,
+		&degradedAt,
+		&degradedReason,
+		&image.CreatedAt,
+		&image.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if buildDate.Valid {
+		image.BuildDate = &buildDate.Time
+	}
+	if validatedAt.Valid {
+		image.ValidatedAt = &validatedAt.Time
+	}
+	if degradedAt.Valid {
+		image.DegradedAt = &degradedAt.Time
+	}
+	image.DegradedReason = degradedReason.String
+	image.BootBudget = time.Duration(budgetSeconds) * time.Second
+	return &image, nil
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"gpu-orchestrator/core/models"
+)
+
+// MaintenanceRepository handles database operations for scheduled maintenance
+type MaintenanceRepository struct {
+	db *DB
+}
+
+// NewMaintenanceRepository creates a new maintenance repository
+func NewMaintenanceRepository(db *DB) *MaintenanceRepository {
+	return &MaintenanceRepository{db: db}
+}
+
+// RecordMaintenanceEvent stores a maintenance event and sets its ID. An event
+// already recorded for the instance is loaded instead; created reports
+// whether the event is new.
+func (r *MaintenanceRepository) RecordMaintenanceEvent(event *models.MaintenanceEvent) (created bool, err error) {
+	if event.DetectedAt.IsZero() {
+		event.DetectedAt = r.db.Now()
+	}
+	if event.Action == "" {
+		event.Action = models.MaintenanceWarned
+	}
+
+	err = r.db.QueryRow(`
+		INSERT INTO maintenance_events (
+			job_id, provider, region, instance_type, instance_id, event_type,
+			description, not_before, not_after, detected_at, action
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (instance_id, event_type, not_before) DO NOTHING
+		RETURNING id
+	`,
+		event.JobID,
+		string(event.Provider),
+		event.Region,
+		event.InstanceType,
+		event.InstanceID,
+		event.EventType,
+		nullString(event.Description),
+		event.NotBefore,
+		event.NotAfter,
+		event.DetectedAt,
+		string(event.Action),
+	).Scan(&event.ID)
+	if err == nil {
+		return true, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return false, err
+	}
+
+	err = r.db.QueryRow(`
+		SELECT id, detected_at, action FROM maintenance_events
+		WHERE instance_id = $1 AND event_type = $2 AND not_before = $3
+	`, event.InstanceID, event.EventType, event.NotBefore).Scan(&event.ID, &event.DetectedAt, &event.Action)
+	return false, err
+}
+
+// SetMaintenanceAction records what was done about a maintenance event
+func (r *MaintenanceRepository) SetMaintenanceAction(id int64, action models.MaintenanceAction) error {
+	_, err := r.db.Exec(`UPDATE maintenance_events SET action = $1 WHERE id = $2`, string(action), id)
+	return err
+}
+
+// ListPendingMaintenance returns the maintenance events not yet over of jobs
+// that are still running, soonest first
+func (r *MaintenanceRepository) ListPendingMaintenance(now time.Time) ([]models.MaintenanceEvent, error) {
+	rows, err := r.db.Query(`
+		SELECT m.id, m.job_id, m.provider, m.region, m.instance_type, m.instance_id, m.event_type,
+			m.description, m.not_before, m.not_after, m.detected_at, m.action
+		FROM maintenance_events m
+		JOIN jobs j ON j.id = m.job_id
+		WHERE COALESCE(m.not_after, m.not_before) >= $1
+			AND j.status IN ('provisioning', 'running', 'checkpointing', 'cancelling')
+		ORDER BY m.not_before, m.id
+	`, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []models.MaintenanceEvent
+	for rows.Next() {
+		var e models.MaintenanceEvent
+		var description sql.NullString
+		var notAfter sql.NullTime
+		if err := rows.Scan(&e.ID, &e.JobID, &e.Provider, &e.Region, &e.InstanceType, &e.InstanceID, &e.EventType,
+			&description, &e.NotBefore, &notAfter, &e.DetectedAt, &e.Action); err != nil {
+			return nil, err
+		}
+		e.Description = description.String
+		if notAfter.Valid {
+			e.NotAfter = &notAfter.Time
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
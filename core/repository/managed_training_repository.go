@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"database/sql"
+
+	"gpu-orchestrator/core/models"
+)
+
+// ManagedTrainingRepository handles database operations for jobs handed to
+// managed training services
+type ManagedTrainingRepository struct {
+	db *DB
+}
+
+// NewManagedTrainingRepository creates a new managed training repository
+func NewManagedTrainingRepository(db *DB) *ManagedTrainingRepository {
+	return &ManagedTrainingRepository{db: db}
+}
+
+// managedRunColumns are the columns scanned by scanManagedRun
+const managedRunColumns = `id, job_id, provider, region, service_job_id, instance_type, instance_count, spot,
+	on_demand_price, spot_price, status, status_detail, billable_seconds, cost_usd,
+	submitted_at, started_at, ended_at, updated_at`
+
+// CreateRun records a submitted run and sets its ID and timestamps
+func (r *ManagedTrainingRepository) CreateRun(run *models.ManagedTrainingRun) error {
+	now := r.db.Now()
+	if run.SubmittedAt.IsZero() {
+		run.SubmittedAt = now
+	}
+	if run.Status == "" {
+		run.Status = models.ManagedRunPending
+	}
+	run.UpdatedAt = now
+	return r.db.QueryRow(`
+		INSERT INTO managed_training_runs (
+			job_id, provider, region, service_job_id, instance_type, instance_count, spot,
+			on_demand_price, spot_price, status, submitted_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id
+	`,
+		run.JobID,
+		string(run.Provider),
+		run.Region,
+		run.ServiceJobID,
+		run.InstanceType,
+		run.Count,
+		run.Spot,
+		run.OnDemandPrice,
+		run.SpotPrice,
+		run.Status,
+		run.SubmittedAt,
+		run.UpdatedAt,
+	).Scan(&run.ID)
+}
+
+// ListActiveRuns returns the runs that have not ended, oldest first
+func (r *ManagedTrainingRepository) ListActiveRuns() ([]models.ManagedTrainingRun, error) {
+	return r.listRuns(`WHERE ended_at IS NULL`)
+}
+
+// ListRuns returns every run of a job, oldest first
+func (r *ManagedTrainingRepository) ListRuns(jobID string) ([]models.ManagedTrainingRun, error) {
+	return r.listRuns(`WHERE job_id = $1`, jobID)
+}
+
+// listRuns returns the runs matching a WHERE clause, oldest first
+func (r *ManagedTrainingRepository) listRuns(where string, args ...interface{}) ([]models.ManagedTrainingRun, error) {
+	rows, err := r.db.Query(`
+		SELECT `+managedRunColumns+`
+		FROM managed_training_runs
+		`+where+`
+		ORDER BY submitted_at, id
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []models.ManagedTrainingRun
+	for rows.Next() {
+		run, err := scanManagedRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, *run)
+	}
+	return runs, rows.Err()
+}
+
+// UpdateRun stores the status, billable time and cost of a run
+func (r *ManagedTrainingRepository) UpdateRun(run *models.ManagedTrainingRun) error {
+	run.UpdatedAt = r.db.Now()
+	_, err := r.db.Exec(`
+		UPDATE managed_training_runs
+		SET status = $1, status_detail = $2, billable_seconds = $3, cost_usd = $4,
+			started_at = $5, ended_at = $6, updated_at = $7
+		WHERE id = $8
+	`,
+		run.Status,
+		nullString(run.StatusDetail),
+		run.BillableSeconds,
+		run.CostUSD,
+		run.StartedAt,
+		run.EndedAt,
+		run.UpdatedAt,
+		run.ID,
+	)
+	return err
+}
+
+// JobCost returns the cost of every run of a job
+func (r *ManagedTrainingRepository) JobCost(jobID string) (float64, error) {
+	var cost float64
+	err := r.db.QueryRow(`SELECT COALESCE(SUM(cost_usd), 0) FROM managed_training_runs WHERE job_id = $1`, jobID).Scan(&cost)
+	return cost, err
+}
+
+// scanManagedRun scans a row selected with managedRunColumns
+func scanManagedRun(row rowScanner) (*models.ManagedTrainingRun, error) {
+	var run models.ManagedTrainingRun
+	var detail sql.NullString
+	var started, ended sql.NullTime
+	if err := row.Scan(&run.ID, &run.JobID, &run.Provider, &run.Region, &run.ServiceJobID, &run.InstanceType, &run.Count, &run.Spot,
+		&run.OnDemandPrice, &run.SpotPrice, &run.Status, &detail, &run.BillableSeconds, &run.CostUSD,
+		&run.SubmittedAt, &started, &ended, &run.UpdatedAt); err != nil {
+		return nil, err
+	}
+	run.StatusDetail = detail.String
+	if started.Valid {
+		run.StartedAt = &started.Time
+	}
+	if ended.Valid {
+		run.EndedAt = &ended.Time
+	}
+	return &run, nil
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gpu-orchestrator/core/models"
+)
+
+// MigrationRepository records the migration advisor's recommendations
+type MigrationRepository struct {
+	db *DB
+}
+
+// NewMigrationRepository creates a new migration advice repository
+func NewMigrationRepository(db *DB) *MigrationRepository {
+	return &MigrationRepository{db: db}
+}
+
+// CreateAdvice inserts a recommendation and sets its ID and creation time
+func (r *MigrationRepository) CreateAdvice(advice *models.MigrationAdvice) error {
+	inputsJSON, err := json.Marshal(advice.Inputs)
+	if err != nil {
+		return fmt.Errorf("failed to encode migration inputs: %w", err)
+	}
+	return r.db.QueryRow(`
+		INSERT INTO migration_advice (job_id, action, savings_usd, inputs_json) VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`, advice.JobID, advice.Action, advice.Inputs.SavingsUSD, string(inputsJSON)).Scan(&advice.ID, &advice.CreatedAt)
+}
+
+// ListAdvice returns a job's recommendations, newest first
+func (r *MigrationRepository) ListAdvice(jobID string) ([]models.MigrationAdvice, error) {
+	rows, err := r.db.Query(`
+		SELECT id, job_id, action, inputs_json, created_at
+		FROM migration_advice
+		WHERE job_id = $1
+		ORDER BY created_at DESC
+	`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var advice []models.MigrationAdvice
+	for rows.Next() {
+		var a models.MigrationAdvice
+		var inputsJSON string
+		if err := rows.Scan(&a.ID, &a.JobID, &a.Action, &inputsJSON, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(inputsJSON), &a.Inputs); err != nil {
+			return nil, fmt.Errorf("failed to decode inputs of migration advice %d: %w", a.ID, err)
+		}
+		advice = append(advice, a)
+	}
+	return advice, rows.Err()
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"gpu-orchestrator/core/models"
+)
+
+// orphanQuery selects the orphaned rows of one category
+type orphanQuery struct {
+	table  string
+	key    string // Columns identifying a row
+	label  string // Key as text, for reports
+	where  string // Condition on the table's rows
+	cutoff bool   // where compares job update times with $1
+}
+
+// orphanQueries holds the query of every models.OrphanCategories entry.
+// Jobs have no end time of their own: an ended job was last updated when it
+// ended.
+var orphanQueries = map[string]orphanQuery{
+	models.OrphanAllocationWithoutJob: {
+		table: "allocations", key: "id", label: "CAST(id AS text)",
+		where: "NOT EXISTS (SELECT 1 FROM jobs j WHERE j.id = allocations.job_id)",
+	},
+	models.OrphanUnscheduledAllocation: {
+		table: "allocations", key: "id", label: "CAST(id AS text)",
+		where: `allocations.status = 'planned' AND EXISTS (
+			SELECT 1 FROM jobs j WHERE j.id = allocations.job_id
+			AND j.status IN ('failed', 'cancelled') AND j.started_at IS NULL AND j.updated_at < $1)`,
+		cutoff: true,
+	},
+	models.OrphanEventWithoutJob: {
+		table: "job_events", key: "id", label: "CAST(id AS text)",
+		where: "NOT EXISTS (SELECT 1 FROM jobs j WHERE j.id = job_events.job_id)",
+	},
+	models.OrphanReservationWithoutJob: {
+		table: "gpu_hour_reservations", key: "job_id, quota_id", label: "CAST(job_id AS text) || '/' || CAST(quota_id AS text)",
+		where: "NOT EXISTS (SELECT 1 FROM jobs j WHERE j.id = gpu_hour_reservations.job_id)",
+	},
+	models.OrphanStaleEgressSample: {
+		table: "job_egress_counters", key: "job_id, node_id", label: "CAST(job_id AS text) || '/' || node_id",
+		where: `EXISTS (
+			SELECT 1 FROM jobs j WHERE j.id = job_egress_counters.job_id
+			AND j.status IN ('completed', 'failed', 'cancelled') AND j.updated_at < $1)`,
+		cutoff: true,
+	},
+	// Rows of objects written after the collection are not orphans
+	models.OrphanCollectedArtifact: {
+		table: "job_artifacts", key: "id", label: "CAST(id AS text)",
+		where: `job_artifacts.deleted_at IS NULL AND job_artifacts.uri <> '' AND EXISTS (
+			SELECT 1 FROM job_artifacts collected
+			WHERE collected.uri = job_artifacts.uri AND collected.id <> job_artifacts.id
+			AND collected.deleted_at IS NOT NULL AND job_artifacts.created_at < collected.deleted_at)`,
+	},
+}
+
+// OrphanRepository finds and deletes records left behind by missing or
+// ended jobs
+type OrphanRepository struct {
+	db *DB
+}
+
+// NewOrphanRepository creates a new orphan repository
+func NewOrphanRepository(db *DB) *OrphanRepository {
+	return &OrphanRepository{db: db}
+}
+
+// orphanQueryOf returns the query of category and its arguments
+func orphanQueryOf(category string, cutoff time.Time) (orphanQuery, []interface{}, error) {
+	q, ok := orphanQueries[category]
+	if !ok {
+		return q, nil, fmt.Errorf("unknown orphan category %q", category)
+	}
+	if q.cutoff {
+		return q, []interface{}{cutoff}, nil
+	}
+	return q, nil, nil
+}
+
+// Find counts the orphans of category and returns the keys of up to
+// sample of them
+func (r *OrphanRepository) Find(category string, cutoff time.Time, sample int) (int64, []string, error) {
+	q, args, err := orphanQueryOf(category, cutoff)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var count int64
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM `+q.table+` WHERE `+q.where, args...).Scan(&count); err != nil {
+		return 0, nil, fmt.Errorf("failed to count %s: %w", category, err)
+	}
+	if count == 0 || sample <= 0 {
+		return count, nil, nil
+	}
+
+	rows, err := r.db.Query(fmt.Sprintf(`SELECT %s FROM %s WHERE %s ORDER BY %s LIMIT $%d`,
+		q.label, q.table, q.where, q.key, len(args)+1), append(args, sample)...)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to sample %s: %w", category, err)
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return 0, nil, err
+		}
+		keys = append(keys, key)
+	}
+	return count, keys, rows.Err()
+}
+
+// DeleteBatch deletes up to limit orphans of category and returns how many
+// were deleted
+func (r *OrphanRepository) DeleteBatch(category string, cutoff time.Time, limit int) (int64, error) {
+	q, args, err := orphanQueryOf(category, cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := r.db.Exec(fmt.Sprintf(`
+		DELETE FROM %[1]s WHERE (%[2]s) IN (
+			SELECT %[2]s FROM %[1]s WHERE %[3]s LIMIT $%[4]d
+		)`, q.table, q.key, q.where, len(args)+1), append(args, limit)...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete %s: %w", category, err)
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gpu-orchestrator/core/models"
+)
+
+// PolicyRepository handles database operations for org policy rules
+type PolicyRepository struct {
+	db *DB
+}
+
+// NewPolicyRepository creates a new policy repository
+func NewPolicyRepository(db *DB) *PolicyRepository {
+	return &PolicyRepository{db: db}
+}
+
+// UpsertRule creates a rule or updates the rule with the same name
+func (r *PolicyRepository) UpsertRule(rule *models.PolicyRule) error {
+	ruleJSON, err := json.Marshal(rule)
+	if err != nil {
+		return fmt.Errorf("failed to encode policy rule: %w", err)
+	}
+
+	query := `
+		INSERT INTO policy_rules (name, rule_json, enabled)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (name) DO UPDATE SET
+			rule_json = EXCLUDED.rule_json,
+			enabled = EXCLUDED.enabled,
+			updated_at = $4
+		RETURNING id, created_at
+	`
+	return r.db.QueryRow(query, rule.Name, string(ruleJSON), rule.Enabled, r.db.Now()).Scan(&rule.ID, &rule.CreatedAt)
+}
+
+// ListRules lists policy rules, optionally only enabled ones
+func (r *PolicyRepository) ListRules(enabledOnly bool) ([]*models.PolicyRule, error) {
+	query := `SELECT id, name, rule_json, enabled, created_at FROM policy_rules`
+	if enabledOnly {
+		query += " WHERE enabled = true"
+	}
+	query += " ORDER BY id"
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []*models.PolicyRule
+	for rows.Next() {
+		var rule models.PolicyRule
+		var ruleJSON []byte
+		var id int64
+
+		if err := rows.Scan(&id, &rule.Name, &ruleJSON, &rule.Enabled, &rule.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan policy rule: %w", err)
+		}
+		if err := json.Unmarshal(ruleJSON, &rule); err != nil {
+			return nil, fmt.Errorf("failed to decode policy rule %s: %w", rule.Name, err)
+		}
+		rule.ID = id
+
+		rules = append(rules, &rule)
+	}
+
+	return rules, rows.Err()
+}
+
+// DeleteRule deletes a rule by name and reports whether it existed
+func (r *PolicyRepository) DeleteRule(name string) (bool, error) {
+	result, err := r.db.Exec(`DELETE FROM policy_rules WHERE name = $1`, name)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
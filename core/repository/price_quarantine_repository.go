@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"gpu-orchestrator/core/models"
+)
+
+// ErrAnomalyNotQuarantined is returned when accepting a price that is not
+// (or no longer) quarantined
+var ErrAnomalyNotQuarantined = errors.New("price is not quarantined")
+
+// PriceQuarantineRepository handles database operations for quarantined prices
+type PriceQuarantineRepository struct {
+	db *DB
+}
+
+// NewPriceQuarantineRepository creates a new price quarantine repository
+func NewPriceQuarantineRepository(db *DB) *PriceQuarantineRepository {
+	return &PriceQuarantineRepository{db: db}
+}
+
+const priceAnomalyColumns = `id, provider, region, instance_type, gpu_type, price_kind, price, previous_price,
+	reason, status, occurrences, detected_at, last_seen_at, resolved_at`
+
+// Lookup returns the quarantine row of a price, or nil if it was never quarantined
+func (r *PriceQuarantineRepository) Lookup(provider models.Provider, region, instanceType, kind string) (*models.PriceAnomaly, error) {
+	anomaly, err := scanPriceAnomaly(r.db.QueryRow(`
+		SELECT `+priceAnomalyColumns+`
+		FROM price_quarantine
+		WHERE provider = $1 AND region = $2 AND instance_type = $3 AND price_kind = $4
+	`, provider, region, instanceType, kind))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return anomaly, err
+}
+
+// Quarantine records an anomalous price. A price already quarantined has its
+// latest observation and occurrence count updated.
+func (r *PriceQuarantineRepository) Quarantine(anomaly *models.PriceAnomaly) error {
+	now := r.db.Now()
+	_, err := r.db.Exec(`
+		INSERT INTO price_quarantine (
+			provider, region, instance_type, gpu_type, price_kind, price, previous_price,
+			reason, status, occurrences, detected_at, last_seen_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, 'quarantined', 1, $9, $9)
+		ON CONFLICT (provider, region, instance_type, price_kind)
+		DO UPDATE SET
+			gpu_type = EXCLUDED.gpu_type,
+			price = EXCLUDED.price,
+			previous_price = EXCLUDED.previous_price,
+			reason = EXCLUDED.reason,
+			occurrences = CASE WHEN price_quarantine.status = 'quarantined' THEN price_quarantine.occurrences + 1 ELSE 1 END,
+			detected_at = CASE WHEN price_quarantine.status = 'quarantined' THEN price_quarantine.detected_at ELSE EXCLUDED.detected_at END,
+			last_seen_at = EXCLUDED.last_seen_at,
+			status = 'quarantined',
+			resolved_at = NULL
+	`,
+		anomaly.Provider,
+		anomaly.Region,
+		anomaly.InstanceType,
+		anomaly.GPUType,
+		anomaly.Kind,
+		anomaly.Price,
+		sql.NullFloat64{Float64: anomaly.PreviousPrice, Valid: anomaly.PreviousPrice > 0},
+		anomaly.Reason,
+		now,
+	)
+	return err
+}
+
+// Clear marks a quarantined price cleared after a refresh passed the checks
+func (r *PriceQuarantineRepository) Clear(provider models.Provider, region, instanceType, kind string) error {
+	_, err := r.db.Exec(`
+		UPDATE price_quarantine
+		SET status = 'cleared', resolved_at = $5
+		WHERE provider = $1 AND region = $2 AND instance_type = $3 AND price_kind = $4
+			AND status = 'quarantined'
+	`, provider, region, instanceType, kind, r.db.Now())
+	return err
+}
+
+// ListAnomalies returns quarantine rows, most recently seen first. An empty
+// status returns every row.
+func (r *PriceQuarantineRepository) ListAnomalies(status models.PriceAnomalyStatus) ([]models.PriceAnomaly, error) {
+	rows, err := r.db.Query(`
+		SELECT `+priceAnomalyColumns+`
+		FROM price_quarantine
+		WHERE $1 = '' OR status = $1
+		ORDER BY last_seen_at DESC, id DESC
+	`, string(status))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var anomalies []models.PriceAnomaly
+	for rows.Next() {
+		anomaly, err := scanPriceAnomaly(rows)
+		if err != nil {
+			return nil, err
+		}
+		anomalies = append(anomalies, *anomaly)
+	}
+	return anomalies, rows.Err()
+}
+
+// Accept stores a quarantined price in the pricing cache and marks it
+// accepted, so later refreshes returning the same price pass the checks
+func (r *PriceQuarantineRepository) Accept(id int64) (*models.PriceAnomaly, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	anomaly, err := scanPriceAnomaly(tx.QueryRow(`
+		SELECT `+priceAnomalyColumns+`
+		FROM price_quarantine
+		WHERE id = $1
+	`, id))
+	if err != nil {
+		return nil, err
+	}
+	if anomaly.Status != models.AnomalyQuarantined {
+		return nil, fmt.Errorf("%w: price %d is %s", ErrAnomalyNotQuarantined, id, anomaly.Status)
+	}
+
+	now := r.db.Now()
+	column := "on_demand_price_per_hour"
+	if anomaly.Kind == models.PriceKindSpot {
+		column = "spot_price_per_hour"
+	}
+	// Without a stored row (first price seen) nothing is updated; the next
+	// refresh stores the row and passes because the price was accepted
+	if _, err := tx.Exec(`
+		UPDATE gpu_pricing SET `+column+` = $4, last_updated = $5
+		WHERE provider = $1 AND region = $2 AND instance_type = $3
+	`, anomaly.Provider, anomaly.Region, anomaly.InstanceType, anomaly.Price, now); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE price_quarantine SET status = 'accepted', resolved_at = $2 WHERE id = $1
+	`, id, now); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	anomaly.Status = models.AnomalyAccepted
+	anomaly.ResolvedAt = &now
+	return anomaly, nil
+}
+
+// rowScanner is a *sql.Row or *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanPriceAnomaly scans a row selected with priceAnomalyColumns
+func scanPriceAnomaly(row rowScanner) (*models.PriceAnomaly, error) {
+	var a models.PriceAnomaly
+	var status string
+	var previousPrice sql.NullFloat64
+	var resolvedAt sql.NullTime
+	if err := row.Scan(
+		&a.ID, &a.Provider, &a.Region, &a.InstanceType, &a.GPUType, &a.Kind, &a.Price, &previousPrice,
+		&a.Reason, &status, &a.Occurrences, &a.DetectedAt, &a.LastSeenAt, &resolvedAt,
+	); err != nil {
+		return nil, err
+	}
+	a.Status = models.PriceAnomalyStatus(status)
+	a.PreviousPrice = previousPrice.Float64
+	if resolvedAt.Valid {
+		a.ResolvedAt = &resolvedAt.Time
+	}
+	return &a, nil
+}
@@ -0,0 +1,425 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gpu-orchestrator/core/models"
+
+	"github.com/lib/pq"
+)
+
+// ErrSubscriptionExists is returned when a user already has a pricing
+// subscription with the name
+var ErrSubscriptionExists = errors.New("pricing subscription name already exists")
+
+// ErrSubscriptionLimit is returned when creating a pricing subscription
+// beyond the per-user limit
+var ErrSubscriptionLimit = errors.New("pricing subscription limit reached")
+
+// priceHistoryBatchRows is how many prices one history insert writes; it
+// keeps statements under SQLite's 999 parameter limit
+const priceHistoryBatchRows = 150
+
+// PricePoint is the price of an instance type in a region
+type PricePoint struct {
+	Region       string
+	InstanceType string
+	Price        float64 // USD per instance-hour
+}
+
+// Key identifies the instance type and region of a price
+func (p PricePoint) Key() string {
+	return p.Region + "/" + p.InstanceType
+}
+
+// PricingSubscriptionRepository handles database operations for pricing
+// subscriptions, their notifications and the price history
+type PricingSubscriptionRepository struct {
+	db *DB
+}
+
+// NewPricingSubscriptionRepository creates a new pricing subscription repository
+func NewPricingSubscriptionRepository(db *DB) *PricingSubscriptionRepository {
+	return &PricingSubscriptionRepository{db: db}
+}
+
+const pricingSubscriptionColumns = `id, user_id, name, instance_types, gpu_types, regions, price_kind,
+	condition, threshold, window_seconds, webhook_url, enabled, created_at, updated_at`
+
+// CreateSubscription inserts a subscription and sets its ID and timestamps.
+// Returns ErrSubscriptionLimit when the user already has limit
+// subscriptions and ErrSubscriptionExists when the name is taken.
+func (r *PricingSubscriptionRepository) CreateSubscription(sub *models.PricingSubscription, limit int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var count int
+	if err := tx.QueryRow(`
+		SELECT COUNT(*) FROM pricing_subscriptions WHERE user_id = $1
+	`, sub.UserID).Scan(&count); err != nil {
+		return err
+	}
+	if count >= limit {
+		return fmt.Errorf("%w: %d per user", ErrSubscriptionLimit, limit)
+	}
+
+	now := r.db.Now()
+	err = tx.QueryRow(`
+		INSERT INTO pricing_subscriptions (
+			user_id, name, instance_types, gpu_types, regions, price_kind, condition,
+			threshold, window_seconds, webhook_url, enabled, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $12)
+		ON CONFLICT (user_id, name) DO NOTHING
+		RETURNING id
+	`,
+		sub.UserID,
+		sub.Name,
+		pq.Array(nonNilStrings(sub.InstanceTypes)),
+		pq.Array(nonNilStrings(sub.GPUTypes)),
+		pq.Array(nonNilStrings(sub.Regions)),
+		sub.Kind,
+		sub.Condition,
+		sub.Threshold,
+		int64(sub.Window.Seconds()),
+		nullString(sub.WebhookURL),
+		sub.Enabled,
+		now,
+	).Scan(&sub.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrSubscriptionExists
+	}
+	if err != nil {
+		return err
+	}
+	sub.CreatedAt, sub.UpdatedAt = now, now
+	return tx.Commit()
+}
+
+// UpdateSubscription replaces a user's subscription. Its open notifications
+// are cleared, so the new condition notifies again if it holds. Returns
+// sql.ErrNoRows when the user has no such subscription.
+func (r *PricingSubscriptionRepository) UpdateSubscription(sub *models.PricingSubscription) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var taken int
+	err = tx.QueryRow(`
+		SELECT COUNT(*) FROM pricing_subscriptions WHERE user_id = $1 AND name = $2 AND id <> $3
+	`, sub.UserID, sub.Name, sub.ID).Scan(&taken)
+	if err != nil {
+		return err
+	}
+	if taken > 0 {
+		return ErrSubscriptionExists
+	}
+
+	now := r.db.Now()
+	err = tx.QueryRow(`
+		UPDATE pricing_subscriptions
+		SET name = $3, instance_types = $4, gpu_types = $5, regions = $6, price_kind = $7,
+			condition = $8, threshold = $9, window_seconds = $10, webhook_url = $11,
+			enabled = $12, updated_at = $13
+		WHERE id = $1 AND user_id = $2
+		RETURNING created_at
+	`,
+		sub.ID,
+		sub.UserID,
+		sub.Name,
+		pq.Array(nonNilStrings(sub.InstanceTypes)),
+		pq.Array(nonNilStrings(sub.GPUTypes)),
+		pq.Array(nonNilStrings(sub.Regions)),
+		sub.Kind,
+		sub.Condition,
+		sub.Threshold,
+		int64(sub.Window.Seconds()),
+		nullString(sub.WebhookURL),
+		sub.Enabled,
+		now,
+	).Scan(&sub.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE pricing_notifications SET cleared_at = $2
+		WHERE subscription_id = $1 AND cleared_at IS NULL
+	`, sub.ID, now); err != nil {
+		return err
+	}
+	sub.UpdatedAt = now
+	return tx.Commit()
+}
+
+// GetSubscription returns a user's subscription, or sql.ErrNoRows if the
+// user has no such subscription
+func (r *PricingSubscriptionRepository) GetSubscription(userID string, id int64) (*models.PricingSubscription, error) {
+	return scanPricingSubscription(r.db.QueryRow(`
+		SELECT `+pricingSubscriptionColumns+`
+		FROM pricing_subscriptions
+		WHERE id = $1 AND user_id = $2
+	`, id, userID))
+}
+
+// ListSubscriptions returns a user's subscriptions, oldest first
+func (r *PricingSubscriptionRepository) ListSubscriptions(userID string) ([]models.PricingSubscription, error) {
+	return r.listSubscriptions(`WHERE user_id = $1`, userID)
+}
+
+// ListEnabledSubscriptions returns every enabled subscription to prices of kind
+func (r *PricingSubscriptionRepository) ListEnabledSubscriptions(kind string) ([]models.PricingSubscription, error) {
+	return r.listSubscriptions(`WHERE price_kind = $1 AND enabled = true`, kind)
+}
+
+// listSubscriptions returns the subscriptions matching a WHERE clause
+func (r *PricingSubscriptionRepository) listSubscriptions(where string, args ...interface{}) ([]models.PricingSubscription, error) {
+	rows, err := r.db.Query(`
+		SELECT `+pricingSubscriptionColumns+`
+		FROM pricing_subscriptions
+		`+where+`
+		ORDER BY id
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []models.PricingSubscription
+	for rows.Next() {
+		sub, err := scanPricingSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, *sub)
+	}
+	return subs, rows.Err()
+}
+
+// DeleteSubscription deletes a user's subscription and its notifications.
+// Returns sql.ErrNoRows when the user has no such subscription.
+func (r *PricingSubscriptionRepository) DeleteSubscription(userID string, id int64) error {
+	result, err := r.db.Exec(`
+		DELETE FROM pricing_subscriptions WHERE id = $1 AND user_id = $2
+	`, id, userID)
+	if err != nil {
+		return err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// scanPricingSubscription scans a row selected with pricingSubscriptionColumns
+func scanPricingSubscription(row rowScanner) (*models.PricingSubscription, error) {
+	var sub models.PricingSubscription
+	var windowSeconds int64
+	var webhookURL sql.NullString
+	if err := row.Scan(
+		&sub.ID, &sub.UserID, &sub.Name,
+		pq.Array(&sub.InstanceTypes), pq.Array(&sub.GPUTypes), pq.Array(&sub.Regions),
+		&sub.Kind, &sub.Condition, &sub.Threshold, &windowSeconds, &webhookURL, &sub.Enabled,
+		&sub.CreatedAt, &sub.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	sub.Window = time.Duration(windowSeconds) * time.Second
+	sub.WebhookURL = webhookURL.String
+	return &sub, nil
+}
+
+// OpenNotification records that a subscription's condition is met by an
+// instance type and sets the notification's ID. It returns false when a
+// notification is already open for the instance type: the condition has
+// been notified and has not cleared since.
+func (r *PricingSubscriptionRepository) OpenNotification(n *models.PriceNotification) (bool, error) {
+	err := r.db.QueryRow(`
+		INSERT INTO pricing_notifications (
+			subscription_id, provider, region, instance_type, gpu_type, old_price, new_price, fired_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (subscription_id, provider, region, instance_type) WHERE cleared_at IS NULL DO NOTHING
+		RETURNING id
+	`,
+		n.SubscriptionID,
+		n.Provider,
+		n.Region,
+		n.InstanceType,
+		n.GPUType,
+		sql.NullFloat64{Float64: n.OldPrice, Valid: n.OldPrice > 0},
+		n.NewPrice,
+		n.FiredAt,
+	).Scan(&n.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// DeleteNotification deletes a notification that could not be delivered,
+// so the condition notifies again on the next evaluation
+func (r *PricingSubscriptionRepository) DeleteNotification(id int64) error {
+	_, err := r.db.Exec(`DELETE FROM pricing_notifications WHERE id = $1`, id)
+	return err
+}
+
+// ClearNotification closes the open notification of a subscription and
+// instance type once its condition no longer holds, re-arming it
+func (r *PricingSubscriptionRepository) ClearNotification(subscriptionID int64, provider models.Provider, region, instanceType string, at time.Time) error {
+	_, err := r.db.Exec(`
+		UPDATE pricing_notifications SET cleared_at = $5
+		WHERE subscription_id = $1 AND provider = $2 AND region = $3 AND instance_type = $4
+			AND cleared_at IS NULL
+	`, subscriptionID, provider, region, instanceType, at)
+	return err
+}
+
+// ListOpenNotifications returns the open notifications of subscriptions to
+// a provider's prices of kind
+func (r *PricingSubscriptionRepository) ListOpenNotifications(provider models.Provider, kind string) ([]models.PriceNotification, error) {
+	return r.listNotifications(`
+		JOIN pricing_subscriptions s ON s.id = n.subscription_id
+		WHERE n.provider = $1 AND s.price_kind = $2 AND n.cleared_at IS NULL
+		ORDER BY n.id
+	`, provider, kind)
+}
+
+// ListNotifications returns a subscription's notifications, newest first
+func (r *PricingSubscriptionRepository) ListNotifications(subscriptionID int64, limit int) ([]models.PriceNotification, error) {
+	return r.listNotifications(`
+		WHERE n.subscription_id = $1
+		ORDER BY n.fired_at DESC, n.id DESC
+		LIMIT $2
+	`, subscriptionID, limit)
+}
+
+// listNotifications returns the notifications selected by a query tail
+func (r *PricingSubscriptionRepository) listNotifications(tail string, args ...interface{}) ([]models.PriceNotification, error) {
+	rows, err := r.db.Query(`
+		SELECT n.id, n.subscription_id, n.provider, n.region, n.instance_type, n.gpu_type,
+			n.old_price, n.new_price, n.fired_at, n.cleared_at
+		FROM pricing_notifications n
+	`+tail, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []models.PriceNotification
+	for rows.Next() {
+		var n models.PriceNotification
+		var oldPrice sql.NullFloat64
+		var clearedAt sql.NullTime
+		if err := rows.Scan(
+			&n.ID, &n.SubscriptionID, &n.Provider, &n.Region, &n.InstanceType, &n.GPUType,
+			&oldPrice, &n.NewPrice, &n.FiredAt, &clearedAt,
+		); err != nil {
+			return nil, err
+		}
+		n.OldPrice = oldPrice.Float64
+		if clearedAt.Valid {
+			n.ClearedAt = &clearedAt.Time
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, rows.Err()
+}
+
+// LatestPrices returns the current price of each of a provider's instance
+// types of kind in the history, keyed by PricePoint.Key
+func (r *PricingSubscriptionRepository) LatestPrices(provider models.Provider, kind string) (map[string]float64, error) {
+	rows, err := r.db.Query(`
+		SELECT h.region, h.instance_type, h.price
+		FROM gpu_price_history h
+		WHERE h.provider = $1 AND h.price_kind = $2
+			AND h.recorded_at = (
+				SELECT MAX(l.recorded_at) FROM gpu_price_history l
+				WHERE l.provider = h.provider AND l.region = h.region
+					AND l.instance_type = h.instance_type AND l.price_kind = h.price_kind
+			)
+	`, provider, kind)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prices := make(map[string]float64)
+	for rows.Next() {
+		var point PricePoint
+		if err := rows.Scan(&point.Region, &point.InstanceType, &point.Price); err != nil {
+			return nil, err
+		}
+		prices[point.Key()] = point.Price
+	}
+	return prices, rows.Err()
+}
+
+// RecordPriceChanges appends changed prices to the history
+func (r *PricingSubscriptionRepository) RecordPriceChanges(provider models.Provider, kind string, points []PricePoint, at time.Time) error {
+	for start := 0; start < len(points); start += priceHistoryBatchRows {
+		batch := points[start:min(start+priceHistoryBatchRows, len(points))]
+		values := make([]string, len(batch))
+		args := []interface{}{provider, kind, at}
+		for i, point := range batch {
+			args = append(args, point.Region, point.InstanceType, point.Price)
+			n := len(args)
+			values[i] = "($1, $" + strconv.Itoa(n-2) + ", $" + strconv.Itoa(n-1) + ", $2, $" + strconv.Itoa(n) + ", $3)"
+		}
+		if _, err := r.db.Exec(`
+			INSERT INTO gpu_price_history (provider, region, instance_type, price_kind, price, recorded_at)
+			VALUES `+strings.Join(values, ",\n"), args...); err != nil {
+			return fmt.Errorf("failed to record %d price changes: %w", len(batch), err)
+		}
+	}
+	return nil
+}
+
+// PeakPrice returns the highest price of an instance type of kind since a
+// time, counting the price in effect at that time; 0 without history
+func (r *PricingSubscriptionRepository) PeakPrice(provider models.Provider, region, instanceType, kind string, since time.Time) (float64, error) {
+	var peak sql.NullFloat64
+	err := r.db.QueryRow(`
+		SELECT MAX(price) FROM gpu_price_history
+		WHERE provider = $1 AND region = $2 AND instance_type = $3 AND price_kind = $4
+			AND recorded_at >= COALESCE((
+				SELECT MAX(recorded_at) FROM gpu_price_history
+				WHERE provider = $1 AND region = $2 AND instance_type = $3 AND price_kind = $4
+					AND recorded_at <= $5
+			), $5)
+	`, provider, region, instanceType, kind, since).Scan(&peak)
+	return peak.Float64, err
+}
+
+// PruneHistory deletes prices superseded before a time. The price in effect
+// at that time is kept, so peaks since then stay measurable.
+func (r *PricingSubscriptionRepository) PruneHistory(before time.Time) (int64, error) {
+	result, err := r.db.Exec(`
+		DELETE FROM gpu_price_history
+		WHERE recorded_at < $1 AND EXISTS (
+			SELECT 1 FROM gpu_price_history newer
+			WHERE newer.provider = gpu_price_history.provider
+				AND newer.region = gpu_price_history.region
+				AND newer.instance_type = gpu_price_history.instance_type
+				AND newer.price_kind = gpu_price_history.price_kind
+				AND newer.recorded_at > gpu_price_history.recorded_at
+				AND newer.recorded_at <= $1
+		)
+	`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"fmt"
+
+	"gpu-orchestrator/core/models"
+)
+
+// ProviderUsageRepository handles database operations for daily provider API usage
+type ProviderUsageRepository struct {
+	db *DB
+}
+
+// NewProviderUsageRepository creates a new provider usage repository
+func NewProviderUsageRepository(db *DB) *ProviderUsageRepository {
+	return &ProviderUsageRepository{db: db}
+}
+
+// AddUsage adds counts to their daily rollups. Replicas add their own counts,
+// so rows total the calls of every replica.
+func (r *ProviderUsageRepository) AddUsage(usage []models.ProviderAPIUsage) error {
+	if len(usage) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := r.db.Now()
+	for _, u := range usage {
+		_, err := tx.Exec(`
+			INSERT INTO provider_api_usage (day, provider, method, calls, errors, throttled, latency_ms, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT (day, provider, method) DO UPDATE SET
+				calls = provider_api_usage.calls + EXCLUDED.calls,
+				errors = provider_api_usage.errors + EXCLUDED.errors,
+				throttled = provider_api_usage.throttled + EXCLUDED.throttled,
+				latency_ms = provider_api_usage.latency_ms + EXCLUDED.latency_ms,
+				updated_at = EXCLUDED.updated_at
+		`, u.Day, string(u.Provider), u.Method, u.Calls, u.Errors, u.Throttled, u.LatencyMs, now)
+		if err != nil {
+			return fmt.Errorf("failed to add usage of %s %s: %w", u.Provider, u.Method, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// ListDaily returns the daily rollups from since (YYYY-MM-DD) on, newest day
+// first. An empty provider lists every provider.
+func (r *ProviderUsageRepository) ListDaily(since, provider string) ([]models.ProviderAPIUsage, error) {
+	rows, err := r.db.Query(`
+		SELECT day, provider, method, calls, errors, throttled, latency_ms
+		FROM provider_api_usage
+		WHERE day >= $1 AND ($2 = '' OR provider = $2)
+		ORDER BY day DESC, provider, method
+	`, since, provider)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var usage []models.ProviderAPIUsage
+	for rows.Next() {
+		var u models.ProviderAPIUsage
+		if err := rows.Scan(&u.Day, &u.Provider, &u.Method, &u.Calls, &u.Errors, &u.Throttled, &u.LatencyMs); err != nil {
+			return nil, err
+		}
+		usage = append(usage, u)
+	}
+	return usage, rows.Err()
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"gpu-orchestrator/core/models"
+)
+
+// RebalanceRepository handles database operations for spot rebalance
+// recommendations
+type RebalanceRepository struct {
+	db *DB
+}
+
+// NewRebalanceRepository creates a new rebalance repository
+func NewRebalanceRepository(db *DB) *RebalanceRepository {
+	return &RebalanceRepository{db: db}
+}
+
+// rebalanceColumns are the columns scanned by scanRebalanceSignal
+const rebalanceColumns = `id, job_id, provider, region, instance_type, instance_id, noticed_at, detected_at,
+	checkpoint_requested_at, checkpoint_saved_at, checkpoint_uri, work_saved_seconds,
+	replacement_node_id, replacement_started_at, interrupted_at`
+
+// RecordSignal stores a rebalance recommendation and sets its ID. A signal
+// already recorded for the instance is loaded instead; created reports
+// whether the signal is new.
+func (r *RebalanceRepository) RecordSignal(signal *models.RebalanceSignal) (created bool, err error) {
+	if signal.DetectedAt.IsZero() {
+		signal.DetectedAt = r.db.Now()
+	}
+
+	err = r.db.QueryRow(`
+		INSERT INTO rebalance_signals (
+			job_id, provider, region, instance_type, instance_id, noticed_at, detected_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (instance_id) DO NOTHING
+		RETURNING id
+	`,
+		signal.JobID,
+		string(signal.Provider),
+		signal.Region,
+		signal.InstanceType,
+		signal.InstanceID,
+		signal.NoticedAt,
+		signal.DetectedAt,
+	).Scan(&signal.ID)
+	if err == nil {
+		return true, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return false, err
+	}
+
+	existing, err := scanRebalanceSignal(r.db.QueryRow(`SELECT `+rebalanceColumns+` FROM rebalance_signals WHERE instance_id = $1`, signal.InstanceID))
+	if err != nil {
+		return false, err
+	}
+	*signal = *existing
+	return false, nil
+}
+
+// ListSignals returns every signal of a job, oldest first
+func (r *RebalanceRepository) ListSignals(jobID string) ([]models.RebalanceSignal, error) {
+	return r.listSignals(`WHERE job_id = $1`, jobID)
+}
+
+// ListOpenSignals returns the signals of a job whose node has not been
+// interrupted yet, oldest first
+func (r *RebalanceRepository) ListOpenSignals(jobID string) ([]models.RebalanceSignal, error) {
+	return r.listSignals(`WHERE job_id = $1 AND interrupted_at IS NULL`, jobID)
+}
+
+// listSignals returns the signals matching a WHERE clause, oldest first
+func (r *RebalanceRepository) listSignals(where string, args ...interface{}) ([]models.RebalanceSignal, error) {
+	rows, err := r.db.Query(`
+		SELECT `+rebalanceColumns+`
+		FROM rebalance_signals
+		`+where+`
+		ORDER BY noticed_at, id
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var signals []models.RebalanceSignal
+	for rows.Next() {
+		signal, err := scanRebalanceSignal(rows)
+		if err != nil {
+			return nil, err
+		}
+		signals = append(signals, *signal)
+	}
+	return signals, rows.Err()
+}
+
+// PendingCheckpointRequest returns the oldest checkpoint request of a job not
+// yet answered by a checkpoint; nil when there is none
+func (r *RebalanceRepository) PendingCheckpointRequest(jobID string) (*models.RebalanceSignal, error) {
+	signal, err := scanRebalanceSignal(r.db.QueryRow(`
+		SELECT `+rebalanceColumns+`
+		FROM rebalance_signals
+		WHERE job_id = $1 AND checkpoint_requested_at IS NOT NULL AND checkpoint_saved_at IS NULL
+		ORDER BY checkpoint_requested_at, id
+		LIMIT 1
+	`, jobID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return signal, err
+}
+
+// MarkCheckpointRequested records that an immediate checkpoint was requested
+func (r *RebalanceRepository) MarkCheckpointRequested(id int64, at time.Time) error {
+	_, err := r.db.Exec(`UPDATE rebalance_signals SET checkpoint_requested_at = $1 WHERE id = $2`, at, id)
+	return err
+}
+
+// MarkCheckpointSaved records the checkpoint that answered a request and the
+// training it preserved
+func (r *RebalanceRepository) MarkCheckpointSaved(id int64, uri string, at time.Time, workSaved time.Duration) error {
+	_, err := r.db.Exec(`
+		UPDATE rebalance_signals
+		SET checkpoint_saved_at = $1, checkpoint_uri = $2, work_saved_seconds = $3
+		WHERE id = $4
+	`, at, uri, int64(workSaved.Seconds()), id)
+	return err
+}
+
+// SetReplacement records the node provisioned ahead of the interruption
+func (r *RebalanceRepository) SetReplacement(id int64, nodeID string, at time.Time) error {
+	_, err := r.db.Exec(`
+		UPDATE rebalance_signals SET replacement_node_id = $1, replacement_started_at = $2 WHERE id = $3
+	`, nodeID, at, id)
+	return err
+}
+
+// MarkInterrupted records that the signalled node left the job's cluster
+func (r *RebalanceRepository) MarkInterrupted(id int64, at time.Time) error {
+	_, err := r.db.Exec(`UPDATE rebalance_signals SET interrupted_at = $1 WHERE id = $2`, at, id)
+	return err
+}
+
+// scanRebalanceSignal scans a row selected with rebalanceColumns
+func scanRebalanceSignal(row rowScanner) (*models.RebalanceSignal, error) {
+	var s models.RebalanceSignal
+	var requested, saved, replacementStarted, interrupted sql.NullTime
+	var checkpointURI, replacementNode sql.NullString
+	var workSaved sql.NullInt64
+	if err := row.Scan(&s.ID, &s.JobID, &s.Provider, &s.Region, &s.InstanceType, &s.InstanceID, &s.NoticedAt, &s.DetectedAt,
+		&requested, &saved, &checkpointURI, &workSaved,
+		&replacementNode, &replacementStarted, &interrupted); err != nil {
+		return nil, err
+	}
+	s.CheckpointURI, s.ReplacementNodeID = checkpointURI.String, replacementNode.String
+	s.WorkSavedSeconds = workSaved.Int64
+	if requested.Valid {
+		s.CheckpointRequestedAt = &requested.Time
+	}
+	if saved.Valid {
+		s.CheckpointSavedAt = &saved.Time
+	}
+	if replacementStarted.Valid {
+		s.ReplacementStartedAt = &replacementStarted.Time
+	}
+	if interrupted.Valid {
+		s.InterruptedAt = &interrupted.Time
+	}
+	return &s, nil
+}
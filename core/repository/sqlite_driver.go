@@ -0,0 +1,7 @@
+//go:build sqlite
+
+package repository
+
+import (
+	_ "modernc.org/sqlite" // SQLite driver (pure Go), registered as "sqlite"
+)
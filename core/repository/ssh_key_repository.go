@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"gpu-orchestrator/core/models"
+)
+
+// SSHKeyRepository stores the per-cluster SSH keys. Private keys arrive
+// sealed and are dropped as soon as a key stops being usable.
+type SSHKeyRepository struct {
+	db *DB
+}
+
+// NewSSHKeyRepository creates a new SSH key repository
+func NewSSHKeyRepository(db *DB) *SSHKeyRepository {
+	return &SSHKeyRepository{db: db}
+}
+
+// sshKeyColumns are the columns scanSSHKey reads, in order
+const sshKeyColumns = `id, job_id, cluster_id, fingerprint, public_key, private_key, status,
+	created_at, activated_at, retired_at`
+
+// CreateKey stores a newly issued pending key and sets its ID and creation time
+func (r *SSHKeyRepository) CreateKey(key *models.ClusterSSHKey) error {
+	key.Status = models.SSHKeyPending
+	key.CreatedAt = r.db.Now()
+	return r.db.QueryRow(`
+		INSERT INTO cluster_ssh_keys (job_id, cluster_id, fingerprint, public_key, private_key, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`,
+		nullString(key.JobID),
+		nullString(key.ClusterID),
+		key.Fingerprint,
+		key.PublicKey,
+		key.SealedKey,
+		key.Status,
+		key.CreatedAt,
+	).Scan(&key.ID)
+}
+
+// BindKey makes a pending key the active key of the cluster its instances
+// joined, or moves an active key to a renamed cluster. Returns sql.ErrNoRows
+// when the key was discarded meanwhile.
+func (r *SSHKeyRepository) BindKey(id int64, clusterID string) error {
+	result, err := r.db.Exec(`
+		UPDATE cluster_ssh_keys
+		SET cluster_id = $2, status = 'active', activated_at = COALESCE(activated_at, $3)
+		WHERE id = $1 AND status IN ('pending', 'active')
+	`, id, clusterID, r.db.Now())
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// PromoteKey makes a pending key the cluster's active key and retires the
+// previous one in one transaction, dropping its private key
+func (r *SSHKeyRepository) PromoteKey(id, previousID int64, clusterID string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := r.db.Now()
+	if _, err := tx.Exec(`
+		UPDATE cluster_ssh_keys SET status = 'retired', private_key = NULL, retired_at = $2
+		WHERE id = $1 AND status = 'active'
+	`, previousID, now); err != nil {
+		return err
+	}
+	result, err := tx.Exec(`
+		UPDATE cluster_ssh_keys SET cluster_id = $2, status = 'active', activated_at = $3
+		WHERE id = $1 AND status = 'pending'
+	`, id, clusterID, now)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return tx.Commit()
+}
+
+// DiscardKey destroys a key that never became or no longer is active, e.g.
+// after a failed launch or rotation
+func (r *SSHKeyRepository) DiscardKey(id int64) error {
+	_, err := r.db.Exec(`
+		UPDATE cluster_ssh_keys SET status = 'destroyed', private_key = NULL, retired_at = $2
+		WHERE id = $1 AND status IN ('pending', 'active')
+	`, id, r.db.Now())
+	return err
+}
+
+// DestroyClusterKeys destroys every live key of a terminated cluster and
+// returns them
+func (r *SSHKeyRepository) DestroyClusterKeys(clusterID string) ([]models.ClusterSSHKey, error) {
+	return r.queryKeys(`
+		UPDATE cluster_ssh_keys SET status = 'destroyed', private_key = NULL, retired_at = $2
+		WHERE cluster_id = $1 AND status IN ('pending', 'active')
+		RETURNING `+sshKeyColumns, clusterID, r.db.Now())
+}
+
+// ActiveKey returns a cluster's active key, nil when it has none, e.g. it
+// was launched before per-cluster keys
+func (r *SSHKeyRepository) ActiveKey(clusterID string) (*models.ClusterSSHKey, error) {
+	key, err := scanSSHKey(r.db.QueryRow(`
+		SELECT `+sshKeyColumns+` FROM cluster_ssh_keys
+		WHERE cluster_id = $1 AND status = 'active'
+	`, clusterID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return key, err
+}
+
+// ClusterKeys returns every key a cluster has had, newest first
+func (r *SSHKeyRepository) ClusterKeys(clusterID string) ([]models.ClusterSSHKey, error) {
+	return r.queryKeys(`
+		SELECT `+sshKeyColumns+` FROM cluster_ssh_keys
+		WHERE cluster_id = $1
+		ORDER BY created_at DESC, id DESC
+	`, clusterID)
+}
+
+// queryKeys runs a query returning sshKeyColumns
+func (r *SSHKeyRepository) queryKeys(query string, args ...interface{}) ([]models.ClusterSSHKey, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []models.ClusterSSHKey
+	for rows.Next() {
+		key, err := scanSSHKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+	return keys, rows.Err()
+}
+
+// scanSSHKey scans sshKeyColumns
+func scanSSHKey(row rowScanner) (*models.ClusterSSHKey, error) {
+	var key models.ClusterSSHKey
+	var jobID, clusterID, sealed sql.NullString
+	var activatedAt, retiredAt sql.NullTime
+	err := row.Scan(
+		&key.ID,
+		&jobID,
+		&clusterID,
+		&key.Fingerprint,
+		&key.PublicKey,
+		&sealed,
+		&key.Status,
+		&key.CreatedAt,
+		&activatedAt,
+		&retiredAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	key.JobID, key.ClusterID, key.SealedKey = jobID.String, clusterID.String, sealed.String
+	if activatedAt.Valid {
+		key.ActivatedAt = &activatedAt.Time
+	}
+	if retiredAt.Valid {
+		key.RetiredAt = &retiredAt.Time
+	}
+	return &key, nil
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"gpu-orchestrator/core/models"
+)
+
+// TaskRepository handles database operations for tasks of multi_task jobs
+type TaskRepository struct {
+	db *DB
+}
+
+// NewTaskRepository creates a new task repository
+func NewTaskRepository(db *DB) *TaskRepository {
+	return &TaskRepository{db: db}
+}
+
+// CreateTasks atomically creates one pending task per allocation, indexed in order
+func (r *TaskRepository) CreateTasks(jobID string, allocations []models.Allocation) ([]models.JobTask, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	tasks := make([]models.JobTask, len(allocations))
+	for i, alloc := range allocations {
+		_, err := tx.Exec(`
+			INSERT INTO job_tasks (
+				job_id, task_index, status, provider, region, instance_type, count, spot, price_per_hour, zone,
+				task_group
+			) VALUES (
+				$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+			)
+		`,
+			jobID,
+			i,
+			models.JobStatusPending,
+			alloc.Provider,
+			alloc.Region,
+			alloc.InstanceType,
+			alloc.Count,
+			alloc.Spot,
+			alloc.PricePerHour,
+			nullString(alloc.Zone),
+			nullString(alloc.TaskGroup),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create task %d: %w", i, err)
+		}
+		tasks[i] = models.JobTask{
+			JobID:      jobID,
+			Index:      i,
+			Status:     models.JobStatusPending,
+			Allocation: alloc,
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// ListTasks returns the tasks of a job ordered by index
+func (r *TaskRepository) ListTasks(jobID string) ([]models.JobTask, error) {
+	query := `
+		SELECT job_id, task_index, status, attempts, provider, region, instance_type, count, spot,
+			price_per_hour, zone, task_group, cluster_id, cost_usd, error, started_at, finished_at, updated_at
+		FROM job_tasks
+		WHERE job_id = $1
+		ORDER BY task_index
+	`
+
+	rows, err := r.db.Query(query, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []models.JobTask
+	for rows.Next() {
+		var task models.JobTask
+		var zone, taskGroup, clusterID, taskError sql.NullString
+		var startedAt, finishedAt sql.NullTime
+
+		err := rows.Scan(
+			&task.JobID,
+			&task.Index,
+			&task.Status,
+			&task.Attempts,
+			&task.Allocation.Provider,
+			&task.Allocation.Region,
+			&task.Allocation.InstanceType,
+			&task.Allocation.Count,
+			&task.Allocation.Spot,
+			&task.Allocation.PricePerHour,
+			&zone,
+			&taskGroup,
+			&clusterID,
+			&task.CostUSD,
+			&taskError,
+			&startedAt,
+			&finishedAt,
+			&task.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan task for job %s: %w", jobID, err)
+		}
+
+		task.Allocation.Zone = zone.String
+		task.Allocation.TaskGroup = taskGroup.String
+		if clusterID.Valid {
+			task.ClusterID = &clusterID.String
+		}
+		task.Error = taskError.String
+		if startedAt.Valid {
+			task.StartedAt = &startedAt.Time
+		}
+		if finishedAt.Valid {
+			task.FinishedAt = &finishedAt.Time
+		}
+		tasks = append(tasks, task)
+	}
+
+	return tasks, rows.Err()
+}
+
+// StartAttempt moves a task to provisioning for a new attempt and returns the attempt number
+func (r *TaskRepository) StartAttempt(jobID string, index int) (int, error) {
+	query := `
+		UPDATE job_tasks
+		SET status = $3, attempts = attempts + 1, cluster_id = NULL, finished_at = NULL, updated_at = $4
+		WHERE job_id = $1 AND task_index = $2
+		RETURNING attempts
+	`
+
+	var attempts int
+	err := r.db.QueryRow(query, jobID, index, models.JobStatusProvisioning, r.db.Now()).Scan(&attempts)
+	return attempts, err
+}
+
+// SetTaskRunning records the cluster of a task's current attempt
+func (r *TaskRepository) SetTaskRunning(jobID string, index int, clusterID string) error {
+	query := `
+		UPDATE job_tasks
+		SET status = $3, cluster_id = $4, started_at = COALESCE(started_at, $5), updated_at = $5
+		WHERE job_id = $1 AND task_index = $2
+	`
+	_, err := r.db.Exec(query, jobID, index, models.JobStatusRunning, clusterID, r.db.Now())
+	return err
+}
+
+// FinishTask records a task's status after an attempt ended. A failure that
+// will be retried keeps the task non-terminal (pending) with its error.
+func (r *TaskRepository) FinishTask(jobID string, index int, status models.JobStatus, taskError string) error {
+	query := `
+		UPDATE job_tasks
+		SET status = $3, error = NULLIF($4, ''),
+			finished_at = CASE WHEN $3 IN ('completed', 'failed', 'cancelled') THEN $5 END,
+			updated_at = $5
+		WHERE job_id = $1 AND task_index = $2
+	`
+	_, err := r.db.Exec(query, jobID, index, status, taskError, r.db.Now())
+	return err
+}
+
+// UpdateTaskCost stores a task's accrued cost and rolls the job's running
+// cost up to the sum over its tasks
+func (r *TaskRepository) UpdateTaskCost(jobID string, index int, cost float64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := r.db.Now()
+	if _, err := tx.Exec(`
+		UPDATE job_tasks SET cost_usd = $3, updated_at = $4
+		WHERE job_id = $1 AND task_index = $2
+	`, jobID, index, cost, now); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		UPDATE jobs
+		SET cost_running_usd = (SELECT COALESCE(SUM(cost_usd), 0) FROM job_tasks WHERE job_id = $1),
+			updated_at = $2
+		WHERE id = $1
+	`, jobID, now); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
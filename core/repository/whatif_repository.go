@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"gpu-orchestrator/core/models"
+)
+
+// WhatIfRepository records asynchronous what-if replays
+type WhatIfRepository struct {
+	db *DB
+}
+
+// NewWhatIfRepository creates a new what-if repository
+func NewWhatIfRepository(db *DB) *WhatIfRepository {
+	return &WhatIfRepository{db: db}
+}
+
+// CreateRun inserts a running what-if run and sets its ID, status and creation time
+func (r *WhatIfRepository) CreateRun(run *models.WhatIfRun) error {
+	requestJSON, err := json.Marshal(run.Request)
+	if err != nil {
+		return fmt.Errorf("failed to encode what-if request: %w", err)
+	}
+	run.Status = models.WhatIfRunning
+	return r.db.QueryRow(`
+		INSERT INTO whatif_runs (status, request_json, jobs) VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`, run.Status, string(requestJSON), run.Jobs).Scan(&run.ID, &run.CreatedAt)
+}
+
+// CompleteRun stores the result of a what-if run
+func (r *WhatIfRepository) CompleteRun(id int64, result *models.WhatIfResult) error {
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode what-if result: %w", err)
+	}
+	_, err = r.db.Exec(`
+		UPDATE whatif_runs SET status = $1, result_json = $2, finished_at = $3
+		WHERE id = $4
+	`, models.WhatIfCompleted, string(resultJSON), r.db.Now(), id)
+	return err
+}
+
+// FailRun records why a what-if run failed
+func (r *WhatIfRepository) FailRun(id int64, runErr error) error {
+	_, err := r.db.Exec(`
+		UPDATE whatif_runs SET status = $1, error = $2, finished_at = $3
+		WHERE id = $4
+	`, models.WhatIfFailed, runErr.Error(), r.db.Now(), id)
+	return err
+}
+
+// GetRun retrieves a what-if run
+func (r *WhatIfRepository) GetRun(id int64) (*models.WhatIfRun, error) {
+	run := &models.WhatIfRun{}
+	var requestJSON string
+	var resultJSON, runErr sql.NullString
+	var finishedAt sql.NullTime
+
+	err := r.db.QueryRow(`
+		SELECT id, status, request_json, jobs, result_json, error, created_at, finished_at
+		FROM whatif_runs
+		WHERE id = $1
+	`, id).Scan(&run.ID, &run.Status, &requestJSON, &run.Jobs, &resultJSON, &runErr, &run.CreatedAt, &finishedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(requestJSON), &run.Request); err != nil {
+		return nil, fmt.Errorf("failed to decode request of what-if run %d: %w", id, err)
+	}
+	if resultJSON.Valid {
+		run.Result = &models.WhatIfResult{}
+		if err := json.Unmarshal([]byte(resultJSON.String), run.Result); err != nil {
+			return nil, fmt.Errorf("failed to decode result of what-if run %d: %w", id, err)
+		}
+	}
+	run.Error = runErr.String
+	if finishedAt.Valid {
+		run.FinishedAt = &finishedAt.Time
+	}
+	return run, nil
+}
@@ -0,0 +1,114 @@
+package resource_manager
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"gpu-orchestrator/core/clock"
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/providers"
+)
+
+// AllocationStore records the lifecycle of stored allocations
+type AllocationStore interface {
+	UpdateAllocationStatus(id int64, status models.AllocationStatus, provisioned int, detail string) error
+	UpdateAllocationSharing(id int64, sharing models.GPUSharingMode, share, estimatedCost float64) error
+	UpdateAllocationZone(id int64, zone string) error
+	RecordInstancesLaunched(jobID string, alloc models.Allocation, instanceIDs []string, at time.Time) error
+	RecordInstancesTerminated(provider models.Provider, region string, instanceIDs []string, at time.Time) error
+}
+
+// SetAllocationStore sets where allocation status is recorded as instances
+// launch, fail and terminate. Without a store statuses are not tracked.
+func (p *Provisioner) SetAllocationStore(store AllocationStore) {
+	p.allocations = store
+}
+
+// setAllocationStatus records an allocation's status. Allocations that were
+// never stored (e.g. elastic growth) are skipped.
+func (p *Provisioner) setAllocationStatus(alloc models.Allocation, status models.AllocationStatus, provisioned int, detail string) {
+	if p.allocations == nil || alloc.ID == 0 {
+		return
+	}
+	if err := p.allocations.UpdateAllocationStatus(alloc.ID, status, provisioned, detail); err != nil {
+		log.Printf("Failed to record allocation %d as %s: %v", alloc.ID, status, err)
+	}
+}
+
+// setAllocationSharing records how a stored allocation shares its GPUs.
+// Allocations of whole GPUs are skipped.
+func (p *Provisioner) setAllocationSharing(alloc models.Allocation) {
+	if p.allocations == nil || alloc.ID == 0 || alloc.GPUSharing == models.GPUSharingNone {
+		return
+	}
+	if err := p.allocations.UpdateAllocationSharing(alloc.ID, alloc.GPUSharing, alloc.GPUShare, alloc.EstimatedCost); err != nil {
+		log.Printf("Failed to record GPU sharing of allocation %d: %v", alloc.ID, err)
+	}
+}
+
+// setAllocationZone records the zone chosen for a stored allocation
+func (p *Provisioner) setAllocationZone(alloc models.Allocation) {
+	if p.allocations == nil || alloc.ID == 0 || alloc.Zone == "" {
+		return
+	}
+	if err := p.allocations.UpdateAllocationZone(alloc.ID, alloc.Zone); err != nil {
+		log.Printf("Failed to record zone of allocation %d: %v", alloc.ID, err)
+	}
+}
+
+// recordLaunch records when a job's instances were launched, so
+// infrastructure is billed from launch rather than from the job's start
+func (p *Provisioner) recordLaunch(job *models.Job, alloc models.Allocation, instanceIDs []string) {
+	if p.allocations == nil || len(instanceIDs) == 0 {
+		return
+	}
+	if err := p.allocations.RecordInstancesLaunched(job.ID, alloc, instanceIDs, clock.System.Now()); err != nil {
+		log.Printf("Failed to record launch of %d instances for job %s: %v", len(instanceIDs), job.ID, err)
+	}
+}
+
+// recordTermination records when instances were terminated
+func (p *Provisioner) recordTermination(provider models.Provider, region string, instanceIDs []string) {
+	if p.allocations == nil || len(instanceIDs) == 0 {
+		return
+	}
+	if err := p.allocations.RecordInstancesTerminated(provider, region, instanceIDs, clock.System.Now()); err != nil {
+		log.Printf("Failed to record termination of %d %s instances in %s: %v", len(instanceIDs), provider, region, err)
+	}
+}
+
+// teardownBatches terminates the instances already launched for a gang that
+// could not be completed, including those of the partial launch. Launched
+// allocations are marked terminated once their instances are gone; they stay
+// active when termination fails, so they show up as orphans.
+func (p *Provisioner) teardownBatches(ctx context.Context, client providers.Provider, region string, launched []instanceBatch, partial []string) {
+	instanceIDs := append([]string(nil), partial...)
+	for _, batch := range launched {
+		instanceIDs = append(instanceIDs, batch.InstanceIDs...)
+	}
+	if len(instanceIDs) == 0 {
+		return
+	}
+
+	if err := client.TerminateInstances(ctx, region, instanceIDs); err != nil {
+		log.Printf("Failed to tear down %d instances of a partially provisioned gang: %v", len(instanceIDs), err)
+		return
+	}
+	p.recordTermination(client.Name(), region, instanceIDs)
+	for _, batch := range launched {
+		p.setAllocationStatus(batch.Allocation, models.AllocationTerminated, len(batch.InstanceIDs), "gang teardown after a partial launch")
+	}
+}
+
+// markClusterTerminated records the cluster's allocations as terminated
+func (p *Provisioner) markClusterTerminated(cluster *models.Cluster) {
+	if p.allocations == nil {
+		return
+	}
+	for _, id := range cluster.AllocationIDs {
+		if err := p.allocations.UpdateAllocationStatus(id, models.AllocationTerminated, -1, "cluster terminated"); err != nil {
+			log.Printf("Failed to record allocation %d of cluster %s as terminated: %v", id, cluster.ID, err)
+		}
+	}
+}
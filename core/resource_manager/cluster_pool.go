@@ -2,21 +2,37 @@ package resource_manager
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
+	"gpu-orchestrator/core/clock"
 	"gpu-orchestrator/core/models"
+
+	"github.com/google/uuid"
 )
 
+// reservationTTL is how long a cluster picked by GetBestCluster is held for
+// the scheduler to confirm the placement
+const reservationTTL = 2 * time.Minute
+
+// ErrReservationNotFound is returned when confirming a reservation that
+// expired or was already confirmed or cancelled
+var ErrReservationNotFound = errors.New("cluster reservation not found or expired")
+
 // ClusterPool manages a pool of GPU clusters for reuse across jobs
 // This improves utilization and reduces provisioning overhead (inspired by Cast AI)
 // Phase 2: Full implementation
 type ClusterPool struct {
-	clusters map[string]*ClusterInfo
-	mu       sync.RWMutex
-	minSize  int
-	maxSize  int
+	clusters   map[string]*ClusterInfo
+	hibernated map[string]*HibernatedCluster // Stopped clusters awaiting a follow-up job
+	reserved   map[string]*Reservation       // Token -> placement awaiting confirmation
+	mu         sync.RWMutex
+	minSize    int
+	maxSize    int
+	now        func() time.Time
 }
 
 // ClusterInfo tracks cluster state and utilization
@@ -25,118 +41,300 @@ type ClusterInfo struct {
 	CreatedAt     time.Time
 	LastUsedAt    time.Time
 	ActiveJobs    int
+	Reserved      int // Unconfirmed reservations; protects the cluster from scale-down
 	TotalGPUs     int
 	AvailableGPUs int
+	PricePerHour  float64 // Hourly cost of all nodes in the cluster
+	Provisioning  bool    // Added by ScaleUp and not ready yet; not placed on
+}
+
+// Reservation holds GPUs of a pooled cluster between GetBestCluster and the
+// scheduler confirming the placement
+type Reservation struct {
+	Token     string
+	ClusterID string
+	GPUs      int
+	ExpiresAt time.Time
+}
+
+// HibernatedCluster is a stopped cluster held for a follow-up job with the same requirements
+type HibernatedCluster struct {
+	Cluster            *models.Cluster
+	Allocations        []models.Allocation
+	Requirements       models.JobRequirements
+	Network            models.NetworkOverrides
+	SourceJobID        string
+	StoppedAt          time.Time
+	ExpiresAt          time.Time // Terminated if still unclaimed after this
+	StorageCostPerHour float64   // Disk cost while stopped
+}
+
+// Matches reports whether a job can reuse the hibernated cluster as-is
+func (hc *HibernatedCluster) Matches(job *models.Job) bool {
+	backend := job.SelectedBackend
+	if backend == "" {
+		backend = models.BackendVM
+	}
+	want, have := job.Requirements, hc.Requirements
+	return backend == hc.Cluster.Backend &&
+		job.DataAccess.UsesDefaultIdentity() && // Only default-identity clusters are hibernated
+		want.GPUs == have.GPUs &&
+		want.GPUMemory == have.GPUMemory &&
+		want.MaxGPUsPerNode == have.MaxGPUsPerNode &&
+		want.RequiresMultiNode == have.RequiresMultiNode &&
+		want.MinInterconnectGbps <= have.MinInterconnectGbps &&
+		want.ExecutionMode == have.ExecutionMode &&
+		job.Network.Profile == hc.Network.Profile
 }
 
 // NewClusterPool creates a new cluster pool
 func NewClusterPool(minSize, maxSize int) *ClusterPool {
 	return &ClusterPool{
-		clusters: make(map[string]*ClusterInfo),
-		minSize:  minSize,
-		maxSize:  maxSize,
+		clusters:   make(map[string]*ClusterInfo),
+		hibernated: make(map[string]*HibernatedCluster),
+		reserved:   make(map[string]*Reservation),
+		minSize:    minSize,
+		maxSize:    maxSize,
+		now:        clock.System.Now,
 	}
 }
 
-// GetBestCluster returns the best cluster for the given requirements
-func (cp *ClusterPool) GetBestCluster(requirements models.JobRequirements) *models.Cluster {
-	cp.mu.RLock()
-	defer cp.mu.RUnlock()
+// GetBestCluster returns the best cluster for the given requirements and
+// reserves the GPUs on it, or nil. The reservation keeps the cluster from
+// being scaled down until it is confirmed with ConfirmReservation, cancelled,
+// or expires after reservationTTL.
+func (cp *ClusterPool) GetBestCluster(requirements models.JobRequirements) (*models.Cluster, *Reservation) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	now := cp.now()
+	cp.expireReservations(now)
 
 	var bestCluster *models.Cluster
+	var bestInfo *ClusterInfo
 	bestScore := 0.0
 
 	for _, info := range cp.clusters {
-		// Skip if cluster doesn't have enough GPUs
-		if info.AvailableGPUs < requirements.GPUs {
+		// Skip if cluster is not up yet or doesn't have enough GPUs
+		if info.Provisioning || info.AvailableGPUs < requirements.GPUs {
 			continue
 		}
 
 		// Score based on available GPUs and last used time
 		// Prefer clusters with more available GPUs and recent usage
 		utilization := float64(info.AvailableGPUs) / float64(info.TotalGPUs)
-		ageScore := 1.0 / (1.0 + time.Since(info.LastUsedAt).Hours())
+		ageScore := 1.0 / (1.0 + now.Sub(info.LastUsedAt).Hours())
 		score := utilization * ageScore
 
 		if score > bestScore {
 			bestScore = score
 			bestCluster = info.Cluster
+			bestInfo = info
 		}
 	}
+	if bestCluster == nil {
+		return nil, nil
+	}
 
-	return bestCluster
+	reservation := &Reservation{
+		Token:     uuid.New().String(),
+		ClusterID: bestCluster.ID,
+		GPUs:      requirements.GPUs,
+		ExpiresAt: now.Add(reservationTTL),
+	}
+	bestInfo.AvailableGPUs -= reservation.GPUs
+	bestInfo.Reserved++
+	bestInfo.LastUsedAt = now
+	cp.reserved[reservation.Token] = reservation
+	return bestCluster, reservation
 }
 
-// ScaleUp scales up the cluster pool by adding new clusters
-func (cp *ClusterPool) ScaleUp(ctx context.Context, demand int) error {
+// ConfirmReservation turns a reservation into an active job on its cluster.
+// Returns ErrReservationNotFound if it expired; the scheduler must then
+// place the job again.
+func (cp *ClusterPool) ConfirmReservation(token string) error {
 	cp.mu.Lock()
 	defer cp.mu.Unlock()
 
-	// Check if we're at max size
-	if len(cp.clusters) >= cp.maxSize {
-		return fmt.Errorf("cluster pool at max size %d", cp.maxSize)
+	now := cp.now()
+	cp.expireReservations(now)
+
+	reservation, ok := cp.reserved[token]
+	if !ok {
+		return ErrReservationNotFound
 	}
+	delete(cp.reserved, token)
 
-	// Calculate how many clusters to add
-	clustersToAdd := demand / 8 // Assume 8 GPUs per cluster (placeholder)
-	if clustersToAdd == 0 {
-		clustersToAdd = 1
+	info, ok := cp.clusters[reservation.ClusterID]
+	if !ok {
+		return fmt.Errorf("cluster %s not found", reservation.ClusterID)
 	}
+	info.Reserved--
+	info.ActiveJobs++
+	info.LastUsedAt = now
+	return nil
+}
 
-	// Don't exceed max size
-	if len(cp.clusters)+clustersToAdd > cp.maxSize {
-		clustersToAdd = cp.maxSize - len(cp.clusters)
+// CancelReservation releases a reservation's GPUs. Unknown tokens are ignored.
+func (cp *ClusterPool) CancelReservation(token string) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	if reservation, ok := cp.reserved[token]; ok {
+		cp.releaseReservation(reservation)
 	}
+}
 
-	// Phase 2: Actually provision clusters using provisioner
-	// For now, create placeholder clusters (will be replaced with real provisioning)
-	for i := 0; i < clustersToAdd; i++ {
-		clusterID := fmt.Sprintf("cluster-%d-%d", time.Now().Unix(), i)
+// expireReservations releases reservations past their expiry. Callers hold
+// the write lock.
+func (cp *ClusterPool) expireReservations(now time.Time) {
+	for _, reservation := range cp.reserved {
+		if now.After(reservation.ExpiresAt) {
+			cp.releaseReservation(reservation)
+		}
+	}
+}
 
-		// TODO: Phase 2 - Use provisioner to create real clusters
-		// This would call:
-		// - provisioner.ProvisionGPUInstances(ctx, allocations)
-		// - Create cluster with real instance IDs
-		// - Track actual GPU counts
-
-		cp.clusters[clusterID] = &ClusterInfo{
-			Cluster: &models.Cluster{
-				ID:       clusterID,
-				Provider: models.ProviderAWS, // Placeholder - should come from provisioner
-				Region:   "us-east-1",        // Placeholder - should come from provisioner
-				Backend:  models.BackendVM,
-				Nodes:    []models.Node{}, // Will be populated by provisioner
-			},
-			CreatedAt:     time.Now(),
-			LastUsedAt:    time.Now(),
-			TotalGPUs:     8, // Placeholder - should come from actual instances
-			AvailableGPUs: 8, // Placeholder - should come from actual instances
+// releaseReservation returns a reservation's GPUs to its cluster. Callers
+// hold the write lock.
+func (cp *ClusterPool) releaseReservation(reservation *Reservation) {
+	delete(cp.reserved, reservation.Token)
+	if info, ok := cp.clusters[reservation.ClusterID]; ok {
+		info.AvailableGPUs += reservation.GPUs
+		info.Reserved--
+	}
+}
+
+// ClusterShape is a number of pooled clusters of one GPU count
+type ClusterShape struct {
+	GPUs  int // GPUs per cluster
+	Count int
+}
+
+// PoolCapacity is the pool's GPU supply
+type PoolCapacity struct {
+	TotalGPUs        int `json:"total_gpus"`        // GPUs of ready clusters
+	AvailableGPUs    int `json:"available_gpus"`    // Unreserved GPUs of ready clusters
+	ProvisioningGPUs int `json:"provisioning_gpus"` // GPUs of clusters still provisioning
+}
+
+// Capacity returns the pool's ready and in-flight GPUs
+func (cp *ClusterPool) Capacity() PoolCapacity {
+	cp.mu.RLock()
+	defer cp.mu.RUnlock()
+
+	var capacity PoolCapacity
+	for _, info := range cp.clusters {
+		if info.Provisioning {
+			capacity.ProvisioningGPUs += info.TotalGPUs
+			continue
+		}
+		capacity.TotalGPUs += info.TotalGPUs
+		capacity.AvailableGPUs += info.AvailableGPUs
+	}
+	return capacity
+}
+
+// ScaleUp adds clusters of the given shapes, up to the pool's max size, and
+// returns the GPUs added. Smaller shapes are added first when the max size
+// cuts the request short.
+func (cp *ClusterPool) ScaleUp(ctx context.Context, shapes []ClusterShape) (int, error) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	// Check if we're at max size
+	if len(cp.clusters) >= cp.maxSize {
+		return 0, fmt.Errorf("cluster pool at max size %d", cp.maxSize)
+	}
+
+	sorted := make([]ClusterShape, len(shapes))
+	copy(sorted, shapes)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].GPUs < sorted[j].GPUs })
+
+	now := cp.now()
+	added := 0
+	for _, shape := range sorted {
+		for i := 0; i < shape.Count && len(cp.clusters) < cp.maxSize; i++ {
+			clusterID := fmt.Sprintf("cluster-%d-%dg-%d", now.Unix(), shape.GPUs, i)
+
+			// TODO: Phase 2 - Use provisioner to create real clusters
+			// This would call:
+			// - provisioner.ProvisionGPUInstances(ctx, allocations)
+			// - Create cluster with real instance IDs
+			// - MarkReady once the nodes are up
+
+			cp.clusters[clusterID] = &ClusterInfo{
+				Cluster: &models.Cluster{
+					ID:       clusterID,
+					Provider: models.ProviderAWS, // Placeholder - should come from provisioner
+					Region:   "us-east-1",        // Placeholder - should come from provisioner
+					Backend:  models.BackendVM,
+					Nodes:    []models.Node{}, // Will be populated by provisioner
+				},
+				CreatedAt:     now,
+				LastUsedAt:    now,
+				TotalGPUs:     shape.GPUs,
+				AvailableGPUs: shape.GPUs,
+				Provisioning:  true,
+			}
+			added += shape.GPUs
 		}
 	}
 
+	return added, nil
+}
+
+// MarkReady makes a cluster added by ScaleUp available for placement
+func (cp *ClusterPool) MarkReady(clusterID string) error {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	info, ok := cp.clusters[clusterID]
+	if !ok {
+		return fmt.Errorf("cluster %s not found", clusterID)
+	}
+	info.Provisioning = false
+	info.LastUsedAt = cp.now()
 	return nil
 }
 
-// ScaleDown scales down idle clusters
-func (cp *ClusterPool) ScaleDown(ctx context.Context, idleTime time.Duration) error {
+// ScaleDown removes clusters idle for longer than idleTime, down to the
+// pool's min size. Enough idle GPUs to cover keepGPUs of expected demand
+// (beyond the available GPUs of busy clusters) are kept, largest clusters
+// first. Returns the clusters removed and the idle clusters kept for demand.
+func (cp *ClusterPool) ScaleDown(ctx context.Context, idleTime time.Duration, keepGPUs int) (int, int, error) {
 	cp.mu.Lock()
 	defer cp.mu.Unlock()
 
 	// Don't scale below min size
 	if len(cp.clusters) <= cp.minSize {
-		return nil
+		return 0, 0, nil
 	}
 
-	now := time.Now()
-	var toRemove []string
+	now := cp.now()
+	cp.expireReservations(now)
+	var idle []string
 
 	for id, info := range cp.clusters {
-		// Check if cluster is idle (no active jobs and not used recently)
-		if info.ActiveJobs == 0 && now.Sub(info.LastUsedAt) > idleTime {
-			toRemove = append(toRemove, id)
+		// Check if cluster is idle (no active or reserved jobs and not used recently)
+		if !info.Provisioning && info.ActiveJobs == 0 && info.Reserved == 0 && now.Sub(info.LastUsedAt) > idleTime {
+			idle = append(idle, id)
+			continue
 		}
+		keepGPUs -= info.AvailableGPUs
 	}
 
+	// Keep the largest idle clusters while expected demand is uncovered
+	sort.Slice(idle, func(i, j int) bool {
+		return cp.clusters[idle[i]].TotalGPUs > cp.clusters[idle[j]].TotalGPUs
+	})
+	kept := 0
+	for kept < len(idle) && keepGPUs > 0 {
+		keepGPUs -= cp.clusters[idle[kept]].TotalGPUs
+		kept++
+	}
+	toRemove := idle[kept:]
+
 	// Remove idle clusters (but keep at least minSize)
 	removeCount := len(toRemove)
 	if len(cp.clusters)-removeCount < cp.minSize {
@@ -154,7 +352,7 @@ func (cp *ClusterPool) ScaleDown(ctx context.Context, idleTime time.Duration) er
 		delete(cp.clusters, toRemove[i])
 	}
 
-	return nil
+	return removeCount, kept, nil
 }
 
 // ReserveGPUs reserves GPUs on a cluster
@@ -173,7 +371,7 @@ func (cp *ClusterPool) ReserveGPUs(clusterID string, gpus int) error {
 
 	info.AvailableGPUs -= gpus
 	info.ActiveJobs++
-	info.LastUsedAt = time.Now()
+	info.LastUsedAt = cp.now()
 
 	return nil
 }
@@ -197,6 +395,97 @@ func (cp *ClusterPool) ReleaseGPUs(clusterID string, gpus int) error {
 	return nil
 }
 
+// IdleCostPerHour returns the hourly cost of clusters with no active jobs
+func (cp *ClusterPool) IdleCostPerHour() float64 {
+	cp.mu.RLock()
+	defer cp.mu.RUnlock()
+
+	idle := 0.0
+	for _, info := range cp.clusters {
+		if info.ActiveJobs == 0 && info.Reserved == 0 {
+			idle += info.PricePerHour
+		}
+	}
+	for _, hc := range cp.hibernated {
+		idle += hc.StorageCostPerHour
+	}
+	return idle
+}
+
+// AddHibernated holds a stopped cluster until it is claimed or expires
+func (cp *ClusterPool) AddHibernated(hc *HibernatedCluster) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	cp.hibernated[hc.Cluster.ID] = hc
+}
+
+// ClaimHibernated removes and returns the unexpired hibernated cluster that
+// a job can reuse (the one closest to expiry), or nil. permit, if set, must
+// accept every allocation of the cluster.
+func (cp *ClusterPool) ClaimHibernated(job *models.Job, permit func(models.Allocation) bool) *HibernatedCluster {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	now := time.Now()
+	var best *HibernatedCluster
+	for _, hc := range cp.hibernated {
+		if now.After(hc.ExpiresAt) || !hc.Matches(job) || !hc.permittedBy(permit) {
+			continue
+		}
+		if best == nil || hc.ExpiresAt.Before(best.ExpiresAt) {
+			best = hc
+		}
+	}
+	if best != nil {
+		delete(cp.hibernated, best.Cluster.ID)
+	}
+	return best
+}
+
+// permittedBy reports whether permit accepts every allocation of the cluster
+func (hc *HibernatedCluster) permittedBy(permit func(models.Allocation) bool) bool {
+	if permit == nil {
+		return true
+	}
+	for _, alloc := range hc.Allocations {
+		if !permit(alloc) {
+			return false
+		}
+	}
+	return true
+}
+
+// Clusters returns every pooled and hibernated cluster
+func (cp *ClusterPool) Clusters() []*models.Cluster {
+	cp.mu.RLock()
+	defer cp.mu.RUnlock()
+
+	clusters := make([]*models.Cluster, 0, len(cp.clusters)+len(cp.hibernated))
+	for _, info := range cp.clusters {
+		clusters = append(clusters, info.Cluster)
+	}
+	for _, hc := range cp.hibernated {
+		clusters = append(clusters, hc.Cluster)
+	}
+	return clusters
+}
+
+// TakeExpiredHibernated removes and returns hibernated clusters past their window
+func (cp *ClusterPool) TakeExpiredHibernated(now time.Time) []*HibernatedCluster {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	var expired []*HibernatedCluster
+	for id, hc := range cp.hibernated {
+		if now.After(hc.ExpiresAt) {
+			expired = append(expired, hc)
+			delete(cp.hibernated, id)
+		}
+	}
+	return expired
+}
+
 // GetStatistics returns cluster pool statistics
 func (cp *ClusterPool) GetStatistics() map[string]interface{} {
 	cp.mu.RLock()
@@ -204,21 +493,29 @@ func (cp *ClusterPool) GetStatistics() map[string]interface{} {
 
 	totalGPUs := 0
 	availableGPUs := 0
+	provisioningGPUs := 0
 	activeJobs := 0
 
 	for _, info := range cp.clusters {
+		if info.Provisioning {
+			provisioningGPUs += info.TotalGPUs
+			continue
+		}
 		totalGPUs += info.TotalGPUs
 		availableGPUs += info.AvailableGPUs
 		activeJobs += info.ActiveJobs
 	}
 
 	return map[string]interface{}{
-		"total_clusters": len(cp.clusters),
-		"min_size":       cp.minSize,
-		"max_size":       cp.maxSize,
-		"total_gpus":     totalGPUs,
-		"available_gpus": availableGPUs,
-		"active_jobs":    activeJobs,
-		"utilization":    float64(totalGPUs-availableGPUs) / float64(totalGPUs),
+		"total_clusters":    len(cp.clusters),
+		"min_size":          cp.minSize,
+		"max_size":          cp.maxSize,
+		"total_gpus":        totalGPUs,
+		"available_gpus":    availableGPUs,
+		"provisioning_gpus": provisioningGPUs,
+		"active_jobs":       activeJobs,
+		"hibernated":        len(cp.hibernated),
+		"reservations":      len(cp.reserved),
+		"utilization":       float64(totalGPUs-availableGPUs) / float64(totalGPUs),
 	}
 }
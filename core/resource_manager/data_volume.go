@@ -0,0 +1,71 @@
+package resource_manager
+
+import (
+	"fmt"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/providers"
+)
+
+// DataMountPath is where the boot script mounts a job's data storage
+const DataMountPath = "/data"
+
+// dataVolumeRequest returns the data volume to attach to each instance of an
+// allocation, or nil when the job fits on the instance store or needs none
+func dataVolumeRequest(job *models.Job, alloc models.Allocation) *providers.DataVolume {
+	if alloc.VolumeGB <= 0 {
+		return nil
+	}
+	return &providers.DataVolume{
+		SizeGB:         alloc.VolumeGB,
+		IOPS:           job.Requirements.StorageIOPS,
+		ThroughputMBps: job.Requirements.StorageThroughput,
+	}
+}
+
+// dataMountScript formats and mounts the job's data storage at DataMountPath.
+// With an attached volume (volumeGB > 0) it waits for the disk of that size;
+// otherwise the unused instance store disks are striped together. Without
+// either, DataMountPath stays a directory on the boot disk.
+func dataMountScript(volumeGB int) string {
+	return fmt.Sprintf(`# Data storage: mount at %[1]s
+data_size_gb=%[2]d
+mkdir -p %[1]s
+if ! mountpoint -q %[1]s; then
+    root_disk="/dev/$(lsblk -no PKNAME "$(findmnt -no SOURCE /)")"
+    data_disks() {
+        for d in $(lsblk -dnpo NAME,TYPE | awk '$2 == "disk" {print $1}'); do
+            [ "$d" = "$root_disk" ] && continue
+            [ "$(lsblk -no NAME "$d" | wc -l)" -eq 1 ] || continue
+            [ -z "$(blkid -o value -s TYPE "$d")" ] || continue
+            if [ "$data_size_gb" -gt 0 ] && [ "$(lsblk -dnbo SIZE "$d")" -ne $((data_size_gb * 1073741824)) ]; then
+                continue
+            fi
+            echo "$d"
+        done
+    }
+    disks=$(data_disks)
+    tries=0
+    while [ "$data_size_gb" -gt 0 ] && [ -z "$disks" ] && [ "$tries" -lt 60 ]; do
+        sleep 5
+        tries=$((tries + 1))
+        disks=$(data_disks)
+    done
+    set -- $disks
+    dev=""
+    if [ "$#" -gt 1 ]; then
+        mdadm --create /dev/md0 --level=0 --raid-devices="$#" "$@" --run
+        dev=/dev/md0
+    elif [ "$#" -eq 1 ]; then
+        dev=$1
+    fi
+    if [ -n "$dev" ]; then
+        mkfs.ext4 -q -F "$dev"
+        mount -o noatime "$dev" %[1]s
+    else
+        echo "No data disk found; %[1]s is on the boot disk"
+    fi
+fi
+chmod 1777 %[1]s
+`, DataMountPath, volumeGB)
+}
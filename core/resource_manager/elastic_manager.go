@@ -0,0 +1,332 @@
+package resource_manager
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"gpu-orchestrator/core/models"
+)
+
+// AllocationObserver is notified when a running job's allocations change
+// (the cost tracker uses this to bill the new node count from now on)
+type AllocationObserver interface {
+	UpdateAllocations(jobID string, allocations []models.Allocation)
+}
+
+// ElasticCluster is the live state of an elastic job's cluster
+type ElasticCluster struct {
+	Job          *models.Job
+	Cluster      *models.Cluster
+	Allocations  []models.Allocation // Current allocations; Count tracks live instances
+	RegisteredAt time.Time
+	LastResizeAt time.Time
+}
+
+// ElasticManager grows and shrinks the clusters of running horovod_elastic jobs
+type ElasticManager struct {
+	provisioner *Provisioner
+	observer    AllocationObserver
+	clusters    map[string]*ElasticCluster
+	resizing    map[string]bool // Jobs with a resize in flight
+	mu          sync.RWMutex
+}
+
+// NewElasticManager creates a new elastic manager. observer may be nil.
+func NewElasticManager(provisioner *Provisioner, observer AllocationObserver) *ElasticManager {
+	return &ElasticManager{
+		provisioner: provisioner,
+		observer:    observer,
+		clusters:    make(map[string]*ElasticCluster),
+		resizing:    make(map[string]bool),
+	}
+}
+
+// Register starts managing a provisioned elastic cluster
+func (em *ElasticManager) Register(job *models.Job, cluster *models.Cluster, allocations []models.Allocation) error {
+	if job.Requirements.Elastic == nil {
+		return fmt.Errorf("job %s is not elastic", job.ID)
+	}
+
+	em.mu.Lock()
+	em.clusters[job.ID] = &ElasticCluster{
+		Job:          job,
+		Cluster:      cluster,
+		Allocations:  append([]models.Allocation(nil), allocations...),
+		RegisteredAt: time.Now(),
+	}
+	em.mu.Unlock()
+
+	em.notify(job.ID, allocations)
+	return nil
+}
+
+// Unregister stops managing a job's cluster (job finished or was cancelled)
+func (em *ElasticManager) Unregister(jobID string) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	delete(em.clusters, jobID)
+	delete(em.resizing, jobID)
+}
+
+// Get returns a snapshot of a job's elastic cluster
+func (em *ElasticManager) Get(jobID string) (ElasticCluster, bool) {
+	em.mu.RLock()
+	defer em.mu.RUnlock()
+
+	ec, ok := em.clusters[jobID]
+	if !ok {
+		return ElasticCluster{}, false
+	}
+	return ec.snapshot(), true
+}
+
+// List returns snapshots of all managed clusters
+func (em *ElasticManager) List() []ElasticCluster {
+	em.mu.RLock()
+	defer em.mu.RUnlock()
+
+	result := make([]ElasticCluster, 0, len(em.clusters))
+	for _, ec := range em.clusters {
+		result = append(result, ec.snapshot())
+	}
+	return result
+}
+
+// DiscoveryHosts returns "address:slots" lines for the Horovod discovery script
+func (em *ElasticManager) DiscoveryHosts(jobID string) ([]string, bool) {
+	em.mu.RLock()
+	defer em.mu.RUnlock()
+
+	ec, ok := em.clusters[jobID]
+	if !ok {
+		return nil, false
+	}
+
+	hosts := make([]string, 0, len(ec.Cluster.Nodes))
+	for _, node := range ec.Cluster.Nodes {
+		hosts = append(hosts, fmt.Sprintf("%s:%d", node.PrivateIP, node.GPUs))
+	}
+	return hosts, true
+}
+
+// ScaleUp provisions alloc.Count extra nodes for a job, up to its max_nodes
+func (em *ElasticManager) ScaleUp(ctx context.Context, jobID string, alloc models.Allocation) ([]models.Node, error) {
+	em.mu.Lock()
+	ec, ok := em.clusters[jobID]
+	if !ok {
+		em.mu.Unlock()
+		return nil, fmt.Errorf("job %s has no elastic cluster", jobID)
+	}
+	if em.resizing[jobID] {
+		em.mu.Unlock()
+		return nil, fmt.Errorf("job %s is already resizing", jobID)
+	}
+	maxNodes := ec.Job.Requirements.Elastic.MaxNodes
+	if len(ec.Cluster.Nodes)+alloc.Count > maxNodes {
+		em.mu.Unlock()
+		return nil, fmt.Errorf("job %s would exceed max_nodes %d", jobID, maxNodes)
+	}
+	em.resizing[jobID] = true
+	job, cluster := ec.Job, ec.snapshot().Cluster
+	em.mu.Unlock()
+
+	defer em.doneResizing(jobID)
+
+	nodes, err := em.provisioner.AddNodes(ctx, job, cluster, alloc)
+	if err != nil {
+		return nil, err
+	}
+
+	em.mu.Lock()
+	ec, ok = em.clusters[jobID]
+	if !ok {
+		// Job finished while we were provisioning: give the instances back
+		em.mu.Unlock()
+		if err := em.provisioner.TerminateNodes(ctx, cluster, nodes); err != nil {
+			return nil, fmt.Errorf("job %s finished during scale-up and cleanup failed: %w", jobID, err)
+		}
+		return nil, fmt.Errorf("job %s finished during scale-up", jobID)
+	}
+	ec.Cluster.Nodes = append(ec.Cluster.Nodes, nodes...)
+	ec.Allocations = addAllocation(ec.Allocations, alloc)
+	ec.LastResizeAt = time.Now()
+	allocations := append([]models.Allocation(nil), ec.Allocations...)
+	em.mu.Unlock()
+
+	em.notify(jobID, allocations)
+	return nodes, nil
+}
+
+// ScaleDown gracefully terminates the given nodes, never going below min_nodes
+func (em *ElasticManager) ScaleDown(ctx context.Context, jobID string, nodeIDs []string) ([]models.Node, error) {
+	em.mu.Lock()
+	ec, ok := em.clusters[jobID]
+	if !ok {
+		em.mu.Unlock()
+		return nil, fmt.Errorf("job %s has no elastic cluster", jobID)
+	}
+	if em.resizing[jobID] {
+		em.mu.Unlock()
+		return nil, fmt.Errorf("job %s is already resizing", jobID)
+	}
+	removed := findNodes(ec.Cluster.Nodes, nodeIDs)
+	if len(removed) != len(nodeIDs) {
+		em.mu.Unlock()
+		return nil, fmt.Errorf("job %s: some nodes in %v are not part of the cluster", jobID, nodeIDs)
+	}
+	minNodes := ec.Job.Requirements.Elastic.MinNodes
+	if len(ec.Cluster.Nodes)-len(removed) < minNodes {
+		em.mu.Unlock()
+		return nil, fmt.Errorf("job %s would drop below min_nodes %d", jobID, minNodes)
+	}
+	em.resizing[jobID] = true
+	cluster := ec.snapshot().Cluster
+	em.mu.Unlock()
+
+	defer em.doneResizing(jobID)
+
+	if err := em.provisioner.TerminateNodes(ctx, cluster, removed); err != nil {
+		return nil, fmt.Errorf("failed to terminate nodes for job %s: %w", jobID, err)
+	}
+
+	allocations, ok := em.removeNodes(jobID, removed)
+	if ok {
+		em.notify(jobID, allocations)
+	}
+	return removed, nil
+}
+
+// NodeLost drops a node the provider already reclaimed (e.g. spot interruption).
+// Returns the lost node and the number of nodes left; the caller decides whether
+// the job can continue when that is below min_nodes.
+func (em *ElasticManager) NodeLost(jobID, instanceID string) (*models.Node, int, error) {
+	em.mu.RLock()
+	ec, ok := em.clusters[jobID]
+	var lost []models.Node
+	if ok {
+		for _, node := range ec.Cluster.Nodes {
+			if node.InstanceID == instanceID {
+				lost = append(lost, node)
+			}
+		}
+	}
+	em.mu.RUnlock()
+
+	if !ok {
+		return nil, 0, fmt.Errorf("job %s has no elastic cluster", jobID)
+	}
+	if len(lost) == 0 {
+		return nil, 0, fmt.Errorf("instance %s is not part of job %s", instanceID, jobID)
+	}
+
+	allocations, ok := em.removeNodes(jobID, lost)
+	if !ok {
+		return nil, 0, fmt.Errorf("job %s finished", jobID)
+	}
+	em.notify(jobID, allocations)
+
+	remaining := 0
+	for _, alloc := range allocations {
+		remaining += alloc.Count
+	}
+	return &lost[0], remaining, nil
+}
+
+// removeNodes drops nodes from a cluster and its allocations
+func (em *ElasticManager) removeNodes(jobID string, removed []models.Node) ([]models.Allocation, bool) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	ec, ok := em.clusters[jobID]
+	if !ok {
+		return nil, false
+	}
+
+	drop := make(map[string]bool, len(removed))
+	for _, node := range removed {
+		drop[node.ID] = true
+		ec.Allocations = removeAllocation(ec.Allocations, node)
+	}
+
+	kept := ec.Cluster.Nodes[:0]
+	for _, node := range ec.Cluster.Nodes {
+		if !drop[node.ID] {
+			kept = append(kept, node)
+		}
+	}
+	ec.Cluster.Nodes = kept
+	ec.LastResizeAt = time.Now()
+
+	return append([]models.Allocation(nil), ec.Allocations...), true
+}
+
+// doneResizing clears the in-flight resize flag
+func (em *ElasticManager) doneResizing(jobID string) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	delete(em.resizing, jobID)
+}
+
+// notify forwards new allocations to the observer
+func (em *ElasticManager) notify(jobID string, allocations []models.Allocation) {
+	if em.observer != nil {
+		em.observer.UpdateAllocations(jobID, allocations)
+	}
+}
+
+// snapshot deep-copies the cluster so callers can read it without holding the lock
+func (ec *ElasticCluster) snapshot() ElasticCluster {
+	cluster := *ec.Cluster
+	cluster.Nodes = append([]models.Node(nil), ec.Cluster.Nodes...)
+
+	copied := *ec
+	copied.Cluster = &cluster
+	copied.Allocations = append([]models.Allocation(nil), ec.Allocations...)
+	return copied
+}
+
+// findNodes returns the cluster nodes with the given IDs
+func findNodes(nodes []models.Node, nodeIDs []string) []models.Node {
+	wanted := make(map[string]bool, len(nodeIDs))
+	for _, id := range nodeIDs {
+		wanted[id] = true
+	}
+
+	var found []models.Node
+	for _, node := range nodes {
+		if wanted[node.ID] {
+			found = append(found, node)
+		}
+	}
+	return found
+}
+
+// addAllocation merges alloc into the row with the same instance type and market
+func addAllocation(allocations []models.Allocation, alloc models.Allocation) []models.Allocation {
+	for i := range allocations {
+		if allocations[i].InstanceType == alloc.InstanceType && allocations[i].Spot == alloc.Spot &&
+			allocations[i].PricePerHour == alloc.PricePerHour {
+			allocations[i].Count += alloc.Count
+			return allocations
+		}
+	}
+	return append(allocations, alloc)
+}
+
+// removeAllocation decrements the row matching a removed node, dropping empty rows
+func removeAllocation(allocations []models.Allocation, node models.Node) []models.Allocation {
+	for i := range allocations {
+		if allocations[i].InstanceType == node.InstanceType && allocations[i].Spot == node.Spot {
+			allocations[i].Count--
+			if allocations[i].Count <= 0 {
+				return append(allocations[:i], allocations[i+1:]...)
+			}
+			return allocations
+		}
+	}
+	return allocations
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"math"
+	"strings"
 
 	"gpu-orchestrator/core/models"
 )
@@ -17,16 +19,17 @@ type GPUSharingManager struct {
 
 // GPUAllocation represents a GPU allocation with sharing info
 type GPUAllocation struct {
-	GPUID        string
-	NodeID       string
-	Provider     models.Provider
-	GPUType      string
-	TotalMemory  int // GB
-	UsedMemory   int // GB
-	Allocations  []JobGPUAllocation
-	MIGEnabled   bool
-	MIGProfile   string
-	TimeSlicing  bool
+	GPUID       string
+	NodeID      string
+	Provider    models.Provider
+	GPUType     string // GPU family, e.g. "A100"
+	GPUModel    string // Catalog model actually shared, e.g. "A100-80"
+	TotalMemory int    // GB
+	UsedMemory  int    // GB
+	Allocations []JobGPUAllocation
+	MIGEnabled  bool
+	MIGProfile  string
+	TimeSlicing bool
 }
 
 // JobGPUAllocation represents a job's allocation on a shared GPU
@@ -34,6 +37,7 @@ type JobGPUAllocation struct {
 	JobID       string
 	GPUFraction float64 // 0.0 - 1.0
 	MemoryGB    int
+	MIGProfile  string // MIG profile if using MIG
 	MIGInstance string // MIG instance ID if using MIG
 }
 
@@ -51,20 +55,74 @@ func (gsm *GPUSharingManager) AllocateGPU(
 	job *models.Job,
 	node *models.Node,
 ) (*GPUAllocation, error) {
-	// Phase 3: GPU sharing logic
-	
+	// Capacity checks use the node's actual GPU model
+	model, err := nodeGPUModel(node)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if job requires MIG
 	if job.Requirements.UseMIG {
-		return gsm.allocateMIG(ctx, job, node)
+		return gsm.allocateMIG(ctx, job, node, model)
 	}
-	
+
 	// Check if job requires fractional GPU
-	if job.Requirements.GPUFraction < 1.0 {
-		return gsm.allocateFractionalGPU(ctx, job, node)
+	if job.Requirements.GPUFraction > 0 && job.Requirements.GPUFraction < 1.0 {
+		return gsm.allocateFractionalGPU(ctx, job, node, model)
 	}
-	
+
 	// Full GPU allocation (no sharing)
-	return gsm.allocateFullGPU(ctx, job, node)
+	return gsm.allocateFullGPU(ctx, job, node, model)
+}
+
+// nodeGPUModel resolves the catalog model of the node's GPUs
+func nodeGPUModel(node *models.Node) (models.GPUModel, error) {
+	if node.GPUType == "" {
+		return models.GPUModel{}, fmt.Errorf("node %s has no GPU model (instance type %s)", node.ID, node.InstanceType)
+	}
+	model, err := models.ResolveGPUModel(node.GPUType, node.GPUMemoryGB)
+	if err != nil {
+		return models.GPUModel{}, fmt.Errorf("node %s: %w", node.ID, err)
+	}
+	return model, nil
+}
+
+// nodeGPUIDs returns the IDs of the node's GPUs
+func nodeGPUIDs(node *models.Node) []string {
+	count := node.GPUs
+	if count < 1 {
+		count = 1
+	}
+	ids := make([]string, count)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("gpu-%s-%d", node.ID, i)
+	}
+	return ids
+}
+
+// sharedGPU returns the tracked allocation of a GPU, creating an empty one
+// that is only stored once a job is placed on it
+func (gsm *GPUSharingManager) sharedGPU(gpuID string, node *models.Node, model models.GPUModel) *GPUAllocation {
+	if existing, ok := gsm.gpuAllocations[gpuID]; ok {
+		return existing
+	}
+	return &GPUAllocation{
+		GPUID:       gpuID,
+		NodeID:      node.ID,
+		Provider:    node.Provider,
+		GPUType:     model.Family,
+		GPUModel:    model.Name,
+		TotalMemory: model.MemoryGB,
+		Allocations: []JobGPUAllocation{},
+	}
+}
+
+// place records the job's allocation on the GPU
+func (gsm *GPUSharingManager) place(gpu *GPUAllocation, jobAlloc JobGPUAllocation) *GPUAllocation {
+	gpu.Allocations = append(gpu.Allocations, jobAlloc)
+	gpu.UsedMemory += jobAlloc.MemoryGB
+	gsm.gpuAllocations[gpu.GPUID] = gpu
+	return gpu
 }
 
 // allocateMIG allocates MIG (Multi-Instance GPU) partition
@@ -73,50 +131,91 @@ func (gsm *GPUSharingManager) allocateMIG(
 	ctx context.Context,
 	job *models.Job,
 	node *models.Node,
+	model models.GPUModel,
 ) (*GPUAllocation, error) {
-	// Phase 3: MIG allocation
 	// MIG allows partitioning a GPU into multiple isolated instances
 	// Example: A100 80GB can be partitioned into 7x 1g.10gb instances
-	
-	log.Printf("Allocating MIG instance for job %s", job.ID)
-	
-	// Validate MIG profile
-	migProfile := job.Requirements.MIGProfile
-	if migProfile == "" {
-		return nil, fmt.Errorf("MIG profile required when UseMIG is true")
+	log.Printf("Allocating MIG instance for job %s on %s", job.ID, model.Name)
+
+	if !model.SupportsMIG() {
+		return nil, fmt.Errorf("GPU model %s on node %s does not support MIG", model.Name, node.ID)
 	}
-	
-	// Parse MIG profile (e.g., "1g.10gb")
-	// Format: {count}g.{memory}gb
-	// Example: "1g.10gb" = 1 GPU instance with 10GB memory
-	
-	// TODO: Query node for available MIG instances
-	// This requires:
-	// 1. Check if GPU supports MIG (A100, A30, etc.)
-	// 2. Check if MIG is enabled on the GPU
-	// 3. List available MIG instances
-	// 4. Allocate matching MIG instance
-	
-	// For now, create placeholder allocation
-	allocation := &GPUAllocation{
-		GPUID:       fmt.Sprintf("gpu-%s-0", node.ID),
-		NodeID:      node.ID,
-		Provider:    node.Provider,
-		GPUType:     "A100", // Assume A100 for MIG
-		MIGEnabled:  true,
-		MIGProfile:  migProfile,
-		TimeSlicing: false,
-		Allocations: []JobGPUAllocation{
-			{
-				JobID:       job.ID,
-				GPUFraction: 1.0, // MIG instance is full allocation
-				MemoryGB:   10,  // From MIG profile
-				MIGInstance: "MIG-GPU-0/1/0", // MIG instance ID
-			},
-		},
+
+	profile, err := selectMIGProfile(model, job.Requirements)
+	if err != nil {
+		return nil, err
 	}
-	
-	return allocation, nil
+
+	// TODO: Query the node's MIG configuration instead of tracking it here
+	for _, gpuID := range nodeGPUIDs(node) {
+		gpu := gsm.sharedGPU(gpuID, node, model)
+		if gpu.TimeSlicing {
+			continue // Already shared by time-slicing
+		}
+
+		usedSlices, sameProfile := 0, 0
+		for _, alloc := range gpu.Allocations {
+			if used, ok := model.MIGProfile(alloc.MIGProfile); ok {
+				usedSlices += used.Slices
+				if used.Name == profile.Name {
+					sameProfile++
+				}
+			}
+		}
+		if usedSlices+profile.Slices > models.MIGSlices || sameProfile >= profile.MaxInstances {
+			continue
+		}
+
+		gpu.MIGEnabled = true
+		gpu.MIGProfile = profile.Name
+		return gsm.place(gpu, JobGPUAllocation{
+			JobID:       job.ID,
+			GPUFraction: profile.Fraction(),
+			MemoryGB:    profile.MemoryGB,
+			MIGProfile:  profile.Name,
+			MIGInstance: fmt.Sprintf("MIG-%s/%d", gpuID, len(gpu.Allocations)),
+		}), nil
+	}
+
+	return nil, fmt.Errorf("no %s GPU on node %s has room for MIG profile %s", model.Name, node.ID, profile.Name)
+}
+
+// selectMIGProfile validates the requested MIG profile against the GPU model,
+// or maps a fraction/memory request to the smallest profile that covers it
+func selectMIGProfile(model models.GPUModel, req models.JobRequirements) (models.MIGProfile, error) {
+	if req.MIGProfile != "" {
+		profile, ok := model.MIGProfile(req.MIGProfile)
+		if !ok {
+			return models.MIGProfile{}, fmt.Errorf("MIG profile %s is not valid for %s (valid: %s)",
+				req.MIGProfile, model.Name, strings.Join(model.MIGProfileNames(), ", "))
+		}
+		if req.GPUMemory > profile.MemoryGB {
+			return models.MIGProfile{}, fmt.Errorf("MIG profile %s provides %dGB, job requests %dGB per GPU",
+				profile.Name, profile.MemoryGB, req.GPUMemory)
+		}
+		return profile, nil
+	}
+
+	fraction := req.GPUFraction
+	if fraction <= 0 || fraction > 1 {
+		fraction = 1
+	}
+	var best *models.MIGProfile
+	for i := range model.MIGProfiles {
+		profile := &model.MIGProfiles[i]
+		// Small tolerance so that e.g. 3/7 satisfies a 0.43 request
+		if profile.Fraction()+1e-2 < fraction || profile.MemoryGB < req.GPUMemory {
+			continue
+		}
+		if best == nil || profile.Slices < best.Slices || (profile.Slices == best.Slices && profile.MemoryGB < best.MemoryGB) {
+			best = profile
+		}
+	}
+	if best == nil {
+		return models.MIGProfile{}, fmt.Errorf("no MIG profile on %s fits fraction %.2f with %dGB (valid: %s)",
+			model.Name, fraction, req.GPUMemory, strings.Join(model.MIGProfileNames(), ", "))
+	}
+	return *best, nil
 }
 
 // allocateFractionalGPU allocates fractional GPU (time-slicing)
@@ -125,63 +224,55 @@ func (gsm *GPUSharingManager) allocateFractionalGPU(
 	ctx context.Context,
 	job *models.Job,
 	node *models.Node,
+	model models.GPUModel,
 ) (*GPUAllocation, error) {
-	// Phase 3: Fractional GPU allocation
 	// This allows multiple jobs to share one physical GPU
 	// Uses time-slicing or memory partitioning
-	
-	log.Printf("Allocating fractional GPU (%.2f) for job %s", job.Requirements.GPUFraction, job.ID)
-	
-	// Find available GPU on node
-	gpuID := fmt.Sprintf("gpu-%s-0", node.ID)
-	
-	// Check if GPU already has allocations
-	existingAlloc, exists := gsm.gpuAllocations[gpuID]
-	if !exists {
-		// Create new GPU allocation
-		existingAlloc = &GPUAllocation{
-			GPUID:       gpuID,
-			NodeID:      node.ID,
-			Provider:    node.Provider,
-			GPUType:     "T4", // Assume T4 for fractional (common for sharing)
-			TotalMemory: 16,   // GB
-			MIGEnabled:  false,
-			TimeSlicing: true,
-			Allocations: []JobGPUAllocation{},
-		}
-		gsm.gpuAllocations[gpuID] = existingAlloc
-	}
-	
-	// Check if there's enough capacity
-	usedFraction := 0.0
-	usedMemory := 0
-	for _, alloc := range existingAlloc.Allocations {
-		usedFraction += alloc.GPUFraction
-		usedMemory += alloc.MemoryGB
-	}
-	
 	requiredFraction := job.Requirements.GPUFraction
+	log.Printf("Allocating fractional GPU (%.2f of %s) for job %s", requiredFraction, model.Name, job.ID)
+
+	// The fraction's share of the GPU's memory bounds what the job may request
+	share := int(math.Floor(requiredFraction * float64(model.MemoryGB)))
 	requiredMemory := job.Requirements.GPUMemory
-	
-	if usedFraction+requiredFraction > 1.0 {
-		return nil, fmt.Errorf("insufficient GPU capacity: %.2f used, %.2f required", usedFraction, requiredFraction)
+	if requiredMemory == 0 {
+		requiredMemory = share
 	}
-	
-	if usedMemory+requiredMemory > existingAlloc.TotalMemory {
-		return nil, fmt.Errorf("insufficient GPU memory: %dGB used, %dGB required", usedMemory, requiredMemory)
+	if requiredMemory > model.MemoryGB {
+		return nil, fmt.Errorf("job requests %dGB per GPU but %s has %dGB", requiredMemory, model.Name, model.MemoryGB)
 	}
-	
-	// Allocate fractional GPU
-	jobAlloc := JobGPUAllocation{
-		JobID:       job.ID,
-		GPUFraction: requiredFraction,
-		MemoryGB:    requiredMemory,
+	if requiredMemory > share {
+		return nil, fmt.Errorf("fraction %.2f of a %dGB %s provides %dGB, job requests %dGB; raise gpu_fraction to at least %.2f",
+			requiredFraction, model.MemoryGB, model.Name, share, requiredMemory,
+			math.Ceil(float64(requiredMemory)/float64(model.MemoryGB)*100)/100)
 	}
-	
-	existingAlloc.Allocations = append(existingAlloc.Allocations, jobAlloc)
-	existingAlloc.UsedMemory += requiredMemory
-	
-	return existingAlloc, nil
+
+	var usedFraction float64
+	var usedMemory int
+	for _, gpuID := range nodeGPUIDs(node) {
+		gpu := gsm.sharedGPU(gpuID, node, model)
+		if gpu.MIGEnabled {
+			continue // Already partitioned with MIG
+		}
+
+		usedFraction, usedMemory = 0, 0
+		for _, alloc := range gpu.Allocations {
+			usedFraction += alloc.GPUFraction
+			usedMemory += alloc.MemoryGB
+		}
+		if usedFraction+requiredFraction > 1.0+1e-9 || usedMemory+requiredMemory > gpu.TotalMemory {
+			continue
+		}
+
+		gpu.TimeSlicing = true
+		return gsm.place(gpu, JobGPUAllocation{
+			JobID:       job.ID,
+			GPUFraction: requiredFraction,
+			MemoryGB:    requiredMemory,
+		}), nil
+	}
+
+	return nil, fmt.Errorf("insufficient %s capacity on node %s: %.2f and %dGB required, last GPU had %.2f and %dGB of %dGB used",
+		model.Name, node.ID, requiredFraction, requiredMemory, usedFraction, usedMemory, model.MemoryGB)
 }
 
 // allocateFullGPU allocates full GPU (no sharing)
@@ -189,40 +280,34 @@ func (gsm *GPUSharingManager) allocateFullGPU(
 	ctx context.Context,
 	job *models.Job,
 	node *models.Node,
+	model models.GPUModel,
 ) (*GPUAllocation, error) {
-	// Full GPU allocation (no sharing)
-	log.Printf("Allocating full GPU for job %s", job.ID)
-	
-	gpuID := fmt.Sprintf("gpu-%s-0", node.ID)
-	
-	allocation := &GPUAllocation{
-		GPUID:       gpuID,
-		NodeID:      node.ID,
-		Provider:    node.Provider,
-		GPUType:     "V100", // Assume V100
-		TotalMemory: job.Requirements.GPUMemory,
-		UsedMemory:  job.Requirements.GPUMemory,
-		MIGEnabled:  false,
-		TimeSlicing: false,
-		Allocations: []JobGPUAllocation{
-			{
-				JobID:       job.ID,
-				GPUFraction: 1.0,
-				MemoryGB:    job.Requirements.GPUMemory,
-			},
-		},
+	log.Printf("Allocating full %s for job %s", model.Name, job.ID)
+
+	if job.Requirements.GPUMemory > model.MemoryGB {
+		return nil, fmt.Errorf("job requests %dGB per GPU but %s has %dGB", job.Requirements.GPUMemory, model.Name, model.MemoryGB)
+	}
+
+	for _, gpuID := range nodeGPUIDs(node) {
+		gpu := gsm.sharedGPU(gpuID, node, model)
+		if len(gpu.Allocations) > 0 {
+			continue
+		}
+		return gsm.place(gpu, JobGPUAllocation{
+			JobID:       job.ID,
+			GPUFraction: 1.0,
+			MemoryGB:    model.MemoryGB,
+		}), nil
 	}
-	
-	gsm.gpuAllocations[gpuID] = allocation
-	
-	return allocation, nil
+
+	return nil, fmt.Errorf("no free %s GPU on node %s", model.Name, node.ID)
 }
 
 // ReleaseGPU releases GPU allocation for a job
 func (gsm *GPUSharingManager) ReleaseGPU(ctx context.Context, jobID string) error {
 	// Phase 3: Release GPU allocation
 	log.Printf("Releasing GPU allocation for job %s", jobID)
-	
+
 	// Find and remove job allocation
 	for gpuID, alloc := range gsm.gpuAllocations {
 		for i, jobAlloc := range alloc.Allocations {
@@ -230,17 +315,17 @@ func (gsm *GPUSharingManager) ReleaseGPU(ctx context.Context, jobID string) erro
 				// Remove job allocation
 				alloc.Allocations = append(alloc.Allocations[:i], alloc.Allocations[i+1:]...)
 				alloc.UsedMemory -= jobAlloc.MemoryGB
-				
+
 				// If no more allocations, remove GPU allocation
 				if len(alloc.Allocations) == 0 {
 					delete(gsm.gpuAllocations, gpuID)
 				}
-				
+
 				return nil
 			}
 		}
 	}
-	
+
 	return fmt.Errorf("GPU allocation not found for job %s", jobID)
 }
 
@@ -251,42 +336,29 @@ func (gsm *GPUSharingManager) GetGPUUtilization(gpuID string) (float64, error) {
 	if !exists {
 		return 0.0, fmt.Errorf("GPU allocation not found: %s", gpuID)
 	}
-	
+
 	// Utilization = sum of all fractional allocations
 	utilization := 0.0
 	for _, jobAlloc := range alloc.Allocations {
 		utilization += jobAlloc.GPUFraction
 	}
-	
+
 	return utilization, nil
 }
 
 // CheckMIGSupport checks if GPU supports MIG
 func (gsm *GPUSharingManager) CheckMIGSupport(gpuType string) bool {
-	// Phase 3: Check if GPU type supports MIG
-	// MIG-capable GPUs: A100, A30, A10
-	migCapableGPUs := map[string]bool{
-		"A100": true,
-		"A30":  true,
-		"A10":  false, // A10 doesn't support MIG
-	}
-	
-	return migCapableGPUs[gpuType]
+	// MIG-capable GPUs: A100, A30, H100
+	model, err := models.ResolveGPUModel(gpuType, 0)
+	return err == nil && model.SupportsMIG()
 }
 
-// GetMIGProfiles returns available MIG profiles for a GPU type
+// GetMIGProfiles returns available MIG profiles for a GPU type. For families
+// with several memory sizes (A100) the largest model's profiles are returned.
 func (gsm *GPUSharingManager) GetMIGProfiles(gpuType string) []string {
-	// Phase 3: Return available MIG profiles
-	// Example: A100 80GB supports:
-	// - 1g.10gb (7 instances)
-	// - 2g.20gb (3 instances)
-	// - 3g.40gb (2 instances)
-	// - 7g.80gb (1 instance)
-	
-	profiles := map[string][]string{
-		"A100": {"1g.10gb", "2g.20gb", "3g.40gb", "7g.80gb"},
-		"A30":  {"1g.6gb", "2g.12gb", "3g.24gb", "4g.48gb"},
+	model, err := models.ResolveGPUModel(gpuType, 0)
+	if err != nil || !model.SupportsMIG() {
+		return nil
 	}
-	
-	return profiles[gpuType]
+	return model.MIGProfileNames()
 }
@@ -0,0 +1,154 @@
+package resource_manager
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"gpu-orchestrator/core/clock"
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/supervisor"
+)
+
+// storagePricePerGBMonth approximates block storage (EBS gp3) pricing for stopped instances
+const storagePricePerGBMonth = 0.08
+
+// hoursPerMonth converts monthly storage prices to hourly
+const hoursPerMonth = 730.0
+
+// OverheadTracker records cost that is not attributable to a running job
+type OverheadTracker interface {
+	StartOverhead(key, reason string, costPerHour float64)
+	StopOverhead(key string) float64
+}
+
+// Hibernator stops finished clusters instead of terminating them so that a
+// follow-up job with the same requirements can restart them within a window.
+// Restarted instances keep their disks and skip AMI boot and network bootstrap.
+type Hibernator struct {
+	pool             *ClusterPool
+	provisioner      *Provisioner
+	overhead         OverheadTracker
+	window           time.Duration
+	storageGBPerNode int
+	interval         time.Duration
+}
+
+// NewHibernator creates a new hibernator. overhead may be nil.
+func NewHibernator(
+	pool *ClusterPool,
+	provisioner *Provisioner,
+	overhead OverheadTracker,
+	window time.Duration,
+	storageGBPerNode int,
+) *Hibernator {
+	return &Hibernator{
+		pool:             pool,
+		provisioner:      provisioner,
+		overhead:         overhead,
+		window:           window,
+		storageGBPerNode: storageGBPerNode,
+		interval:         time.Minute,
+	}
+}
+
+// SetInterval sets how often expired windows are checked (default 1 minute)
+func (h *Hibernator) SetInterval(interval time.Duration) {
+	h.interval = interval
+}
+
+// Start terminates hibernated clusters whose window has expired
+func (h *Hibernator) Start(ctx context.Context) {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			supervisor.Heartbeat(ctx)
+			h.terminateExpired(ctx, clock.System.Now())
+		}
+	}
+}
+
+// Release hands back a finished job's cluster: stopped for reuse when the
+// provider supports it, terminated otherwise
+func (h *Hibernator) Release(ctx context.Context, job *models.Job, cluster *models.Cluster, allocations []models.Allocation) error {
+	// Instances keep the job's identity while stopped; only shareable ones are kept
+	if !h.provisioner.CanStop(cluster) || !job.DataAccess.UsesDefaultIdentity() {
+		return h.provisioner.TerminateCluster(ctx, cluster)
+	}
+
+	if err := h.provisioner.StopCluster(ctx, cluster); err != nil {
+		log.Printf("Failed to hibernate cluster %s, terminating: %v", cluster.ID, err)
+		return h.provisioner.TerminateCluster(ctx, cluster)
+	}
+
+	now := clock.System.Now()
+	hc := &HibernatedCluster{
+		Cluster:            cluster,
+		Allocations:        allocations,
+		Requirements:       job.Requirements,
+		Network:            job.Network,
+		SourceJobID:        job.ID,
+		StoppedAt:          now,
+		ExpiresAt:          now.Add(h.window),
+		StorageCostPerHour: float64(len(cluster.Nodes)*h.storageGBPerNode) * storagePricePerGBMonth / hoursPerMonth,
+	}
+	h.pool.AddHibernated(hc)
+	if h.overhead != nil {
+		h.overhead.StartOverhead(cluster.ID, "hibernated_cluster", hc.StorageCostPerHour)
+	}
+
+	log.Printf("Cluster %s hibernated after job %s (held until %s, $%.4f/hr storage)",
+		cluster.ID, job.ID, hc.ExpiresAt.Format(time.RFC3339), hc.StorageCostPerHour)
+	return nil
+}
+
+// Claim takes a hibernated cluster the job can reuse, or returns nil
+func (h *Hibernator) Claim(job *models.Job, permit func(models.Allocation) bool) *HibernatedCluster {
+	hc := h.pool.ClaimHibernated(job, permit)
+	if hc == nil {
+		return nil
+	}
+
+	storageCost := h.stopOverhead(hc)
+	log.Printf("Job %s claimed hibernated cluster %s from job %s (stopped %v, storage cost $%.4f)",
+		job.ID, hc.Cluster.ID, hc.SourceJobID, time.Since(hc.StoppedAt).Round(time.Second), storageCost)
+	return hc
+}
+
+// Resume restarts a claimed cluster. On failure its instances are terminated
+// so the caller can fall back to fresh provisioning.
+func (h *Hibernator) Resume(ctx context.Context, hc *HibernatedCluster) (*models.Cluster, error) {
+	if err := h.provisioner.StartCluster(ctx, hc.Cluster); err != nil {
+		if termErr := h.provisioner.TerminateCluster(ctx, hc.Cluster); termErr != nil {
+			log.Printf("Failed to terminate cluster %s after failed restart: %v", hc.Cluster.ID, termErr)
+		}
+		return nil, fmt.Errorf("failed to resume hibernated cluster %s: %w", hc.Cluster.ID, err)
+	}
+	return hc.Cluster, nil
+}
+
+// terminateExpired terminates clusters nobody claimed within the window
+func (h *Hibernator) terminateExpired(ctx context.Context, now time.Time) {
+	for _, hc := range h.pool.TakeExpiredHibernated(now) {
+		storageCost := h.stopOverhead(hc)
+		if err := h.provisioner.TerminateCluster(ctx, hc.Cluster); err != nil {
+			log.Printf("Failed to terminate expired hibernated cluster %s: %v", hc.Cluster.ID, err)
+			continue
+		}
+		log.Printf("Hibernated cluster %s expired unclaimed and was terminated (storage cost $%.4f)", hc.Cluster.ID, storageCost)
+	}
+}
+
+// stopOverhead ends storage cost accrual for a hibernated cluster
+func (h *Hibernator) stopOverhead(hc *HibernatedCluster) float64 {
+	if h.overhead == nil {
+		return hc.StorageCostPerHour * time.Since(hc.StoppedAt).Hours()
+	}
+	return h.overhead.StopOverhead(hc.Cluster.ID)
+}
@@ -0,0 +1,184 @@
+package resource_manager
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/supervisor"
+	"gpu-orchestrator/providers"
+)
+
+// IdentityStore records the cloud identities attached to jobs' instances
+type IdentityStore interface {
+	AttachJobIdentity(jobID string, identity models.JobIdentity) error
+	ListJobIdentities(jobID string) ([]models.JobIdentity, error)
+	MarkJobIdentityDeleted(jobID string, identity models.JobIdentity) error
+	ListStaleJobIdentities() (map[string][]models.JobIdentity, error)
+}
+
+// SetIdentityStore sets where attached identities are recorded. Without a
+// store, jobs with data.access auto cannot be provisioned (their identities
+// could not be cleaned up).
+func (p *Provisioner) SetIdentityStore(store IdentityStore) {
+	p.identities = store
+}
+
+// instanceIdentity resolves the identity the job's instances on client run
+// as, creating a job-scoped one for data.access auto, and records it. The
+// result goes into InstanceRequest.Identity; empty means the provider default.
+func (p *Provisioner) instanceIdentity(ctx context.Context, client providers.Provider, job *models.Job) (string, error) {
+	access := job.DataAccess
+	if access == nil {
+		access = &models.DataAccess{}
+	}
+	provider := client.Name()
+	identityProvisioner, supported := client.(providers.IdentityProvisioner)
+
+	var identity models.JobIdentity
+	switch access.Mode {
+	case models.DataAccessDefault:
+		if !supported {
+			return "", nil
+		}
+		identity = identityProvisioner.DefaultIdentity()
+	case models.DataAccessRoleARN:
+		if provider != models.ProviderAWS {
+			return "", fmt.Errorf("data.access role-arn is only supported on aws, job %s was placed on %s", job.ID, provider)
+		}
+		identity = models.JobIdentity{Provider: provider, Kind: models.IdentityInstanceProfile, Name: access.Identity}
+	case models.DataAccessServiceAccount:
+		if provider != models.ProviderGCP {
+			return "", fmt.Errorf("data.access service-account is only supported on gcp, job %s was placed on %s", job.ID, provider)
+		}
+		identity = models.JobIdentity{Provider: provider, Kind: models.IdentityServiceAccount, Name: access.Identity}
+	case models.DataAccessAuto:
+		if !supported {
+			return "", fmt.Errorf("provider %s cannot create job-scoped identities (data.access auto)", provider)
+		}
+		if p.identities == nil {
+			return "", fmt.Errorf("job-scoped identities need an identity store")
+		}
+		return p.managedIdentity(ctx, identityProvisioner, provider, job)
+	}
+
+	if p.identities != nil {
+		if err := p.identities.AttachJobIdentity(job.ID, identity); err != nil {
+			log.Printf("Failed to record identity %s for job %s: %v", identity.Name, job.ID, err)
+		}
+	}
+	if access.Mode == models.DataAccessDefault {
+		return "", nil
+	}
+	return identity.Name, nil
+}
+
+// managedIdentity returns the job's live identity on the provider, creating
+// one on first use. Nodes added later (elastic jobs) reuse it.
+func (p *Provisioner) managedIdentity(
+	ctx context.Context,
+	identityProvisioner providers.IdentityProvisioner,
+	provider models.Provider,
+	job *models.Job,
+) (string, error) {
+	existing, err := p.identities.ListJobIdentities(job.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to load identities of job %s: %w", job.ID, err)
+	}
+	for _, identity := range existing {
+		if identity.Provider == provider && identity.Managed && identity.DeletedAt == nil {
+			return identity.Name, nil
+		}
+	}
+
+	identity, err := identityProvisioner.CreateJobIdentity(ctx, job.ID, job.Grants())
+	if err != nil {
+		return "", err
+	}
+	// Record before launching so a crash cannot leave an identity nobody deletes
+	if err := p.identities.AttachJobIdentity(job.ID, identity); err != nil {
+		if deleteErr := identityProvisioner.DeleteJobIdentity(ctx, identity); deleteErr != nil {
+			log.Printf("Failed to delete unrecorded identity %s of job %s: %v", identity.Name, job.ID, deleteErr)
+		}
+		return "", fmt.Errorf("failed to record identity of job %s: %w", job.ID, err)
+	}
+	log.Printf("Created identity %s for job %s (%d grants)", identity.Name, job.ID, len(identity.Grants))
+	return identity.Name, nil
+}
+
+// ReleaseJobIdentities deletes the identities created for a job. Call it once
+// the job's instances are terminated; failures are retried by the cleanup worker.
+func (p *Provisioner) ReleaseJobIdentities(ctx context.Context, jobID string) error {
+	if p.identities == nil {
+		return nil
+	}
+	identities, err := p.identities.ListJobIdentities(jobID)
+	if err != nil {
+		return fmt.Errorf("failed to load identities of job %s: %w", jobID, err)
+	}
+	return p.deleteIdentities(ctx, jobID, identities)
+}
+
+// deleteIdentities deletes the live managed identities among identities
+func (p *Provisioner) deleteIdentities(ctx context.Context, jobID string, identities []models.JobIdentity) error {
+	var firstErr error
+	for _, identity := range identities {
+		if !identity.Managed || identity.DeletedAt != nil {
+			continue
+		}
+		client, ok := p.providers.Get(identity.Provider)
+		identityProvisioner, supported := client.(providers.IdentityProvisioner)
+		if !ok || !supported {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("provider %s cannot delete identity %s", identity.Provider, identity.Name)
+			}
+			continue
+		}
+
+		if err := identityProvisioner.DeleteJobIdentity(ctx, identity); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if err := p.identities.MarkJobIdentityDeleted(jobID, identity); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to record deletion of identity %s: %w", identity.Name, err)
+		}
+	}
+	return firstErr
+}
+
+// StartIdentityCleanup deletes the identities of ended jobs every interval,
+// catching releases that failed or were interrupted. Its provider calls are
+// non-critical and wait for the next pass when over the call budget.
+func (p *Provisioner) StartIdentityCleanup(ctx context.Context, interval time.Duration) {
+	ctx = providers.NonCritical(ctx)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			supervisor.Heartbeat(ctx)
+			p.cleanupIdentities(ctx)
+		}
+	}
+}
+
+// cleanupIdentities deletes every managed identity left on an ended job
+func (p *Provisioner) cleanupIdentities(ctx context.Context) {
+	stale, err := p.identities.ListStaleJobIdentities()
+	if err != nil {
+		log.Printf("Failed to list stale job identities: %v", err)
+		return
+	}
+	for jobID, identities := range stale {
+		if err := p.deleteIdentities(ctx, jobID, identities); err != nil {
+			log.Printf("Failed to delete identities of job %s: %v", jobID, err)
+		}
+	}
+}
@@ -14,6 +14,9 @@ type KubernetesBackend struct {
 	// k8sClient would be *kubernetes.Clientset
 	// For now, we'll use interface for abstraction
 	k8sClient interface{} // TODO: Replace with actual Kubernetes client
+
+	api                 KubernetesAPI // Checks advertised GPU resources; see SetAPI
+	timeSlicingReplicas int           // Replicas per GPU of the device plugin's time-slicing config
 }
 
 // NewKubernetesBackend creates a new Kubernetes backend manager
@@ -30,7 +33,22 @@ func NewKubernetesBackend() *KubernetesBackend {
 	// }
 	
 	return &KubernetesBackend{
-		k8sClient: nil, // Placeholder
+		k8sClient:           nil, // Placeholder
+		timeSlicingReplicas: DefaultTimeSlicingReplicas,
+	}
+}
+
+// SetAPI sets the Kubernetes API that clusters' GPU resources are verified
+// with before jobs are placed. Without it the check is skipped.
+func (kb *KubernetesBackend) SetAPI(api KubernetesAPI) {
+	kb.api = api
+}
+
+// SetTimeSlicingReplicas sets how many replicas the clusters' device plugin
+// splits each GPU into; fractional jobs request replicas in that unit
+func (kb *KubernetesBackend) SetTimeSlicingReplicas(replicas int) {
+	if replicas > 0 {
+		kb.timeSlicingReplicas = replicas
 	}
 }
 
@@ -50,13 +68,26 @@ func (kb *KubernetesBackend) ProvisionCluster(
 	// 2. Create managed K8s cluster (EKS, GKE, AKS)
 	
 	// Check if using existing cluster or creating new one
+	var cluster *models.Cluster
+	var err error
 	if job.ClusterID != nil {
 		// Use existing cluster
-		return kb.useExistingCluster(ctx, *job.ClusterID, allocations)
+		cluster, err = kb.useExistingCluster(ctx, *job.ClusterID, allocations)
+	} else {
+		// Create new managed K8s cluster
+		cluster, err = kb.createManagedCluster(ctx, job, allocations, allocations[0])
 	}
-	
-	// Create new managed K8s cluster
-	return kb.createManagedCluster(ctx, job, allocations, allocations[0])
+	if err != nil {
+		return nil, err
+	}
+
+	// Fail fast when the cluster cannot serve the job's GPU sharing mode
+	request, err := kb.applyGPUSharing(ctx, cluster.ID, job, allocations)
+	if err != nil {
+		return nil, fmt.Errorf("job %s cannot run on Kubernetes cluster %s: %w", job.ID, cluster.ID, err)
+	}
+	log.Printf("Job %s requests %d %s per pod on cluster %s", job.ID, request.Quantity, request.Resource, cluster.ID)
+	return cluster, nil
 }
 
 // useExistingCluster uses an existing Kubernetes cluster
@@ -216,7 +247,18 @@ func (kb *KubernetesBackend) SubmitJob(
 	// This uses Kubernetes Job resource for distributed training
 	
 	log.Printf("Submitting job %s to Kubernetes cluster %s", job.ID, cluster.ID)
-	
+
+	// GPUs, time-sliced replicas or a MIG partition, by the job's sharing mode
+	var gpuType string
+	var gpuMemoryGB int
+	if len(cluster.Nodes) > 0 {
+		gpuType, gpuMemoryGB = cluster.Nodes[0].GPUType, cluster.Nodes[0].GPUMemoryGB
+	}
+	gpuRequest, err := kb.RenderGPURequest(job, gpuType, gpuMemoryGB)
+	if err != nil {
+		return err
+	}
+
 	// TODO: Create Kubernetes Job resource
 	// jobSpec := &batchv1.Job{
 	// 	ObjectMeta: metav1.ObjectMeta{
@@ -234,18 +276,18 @@ func (kb *KubernetesBackend) SubmitJob(
 	// 						Name:  "training",
 	// 						Image: "pytorch/pytorch:latest",
 	// 						Resources: corev1.ResourceRequirements{
-	// 							Limits: corev1.ResourceList{
-	// 								"nvidia.com/gpu": resource.MustParse(fmt.Sprintf("%d", job.Requirements.GPUs)),
-	// 							},
+	// 							Limits: resourceList(gpuRequest.Limits()),
 	// 						},
 	// 					},
 	// 				},
+	// 				NodeSelector: gpuRequest.NodeSelector,
 	// 			},
 	// 		},
 	// 	},
 	// }
-	// _, err := kb.k8sClient.BatchV1().Jobs("default").Create(ctx, jobSpec, metav1.CreateOptions{})
-	
+	// _, err = kb.k8sClient.BatchV1().Jobs("default").Create(ctx, jobSpec, metav1.CreateOptions{})
+	log.Printf("Job %s pod GPU limits: %v", job.ID, gpuRequest.Limits())
+
 	return nil
 }
 
@@ -0,0 +1,207 @@
+package resource_manager
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+
+	"gpu-orchestrator/core/models"
+)
+
+// Resources and labels of the NVIDIA device plugin and mig-parted
+const (
+	ResourceGPU       = "nvidia.com/gpu"
+	ResourceSharedGPU = "nvidia.com/gpu.shared" // Time-sliced replicas (renameByDefault)
+	migResourcePrefix = "nvidia.com/mig-"       // Mixed MIG strategy, e.g. nvidia.com/mig-1g.10gb
+	migConfigLabel    = "nvidia.com/mig.config" // mig-parted configuration applied to a node
+)
+
+// DefaultTimeSlicingReplicas is how many replicas the device plugin splits
+// each GPU into when no other count is configured
+const DefaultTimeSlicingReplicas = 4
+
+// KubernetesAPI is the part of the Kubernetes API the backend checks GPU
+// resources with. A client-go clientset is wrapped to satisfy it.
+type KubernetesAPI interface {
+	// NodeAllocatable returns the allocatable resources of each schedulable
+	// node of a cluster, by node name
+	NodeAllocatable(ctx context.Context, clusterID string) (map[string]map[string]int64, error)
+}
+
+// GPUResourceRequest is the GPU part of a job's pod spec
+type GPUResourceRequest struct {
+	Sharing      models.GPUSharingMode
+	Resource     string            // Extended resource requested, e.g. nvidia.com/mig-1g.10gb
+	Quantity     int64             // Units of Resource per pod
+	Fraction     float64           // Share of a physical GPU per GPU the job requests
+	NodeSelector map[string]string // Labels the node must carry, e.g. its mig-parted config
+}
+
+// Limits returns the pod resource limits of the request
+func (r *GPUResourceRequest) Limits() map[string]string {
+	return map[string]string{r.Resource: fmt.Sprintf("%d", r.Quantity)}
+}
+
+// RenderGPURequest maps a job's GPU requirements to pod resource requests:
+// whole GPUs, time-sliced replicas for gpu_fraction < 1, or a MIG partition
+// for use_mig. The GPU type and memory select the MIG profile when the job
+// names none.
+func (kb *KubernetesBackend) RenderGPURequest(job *models.Job, gpuType string, gpuMemoryGB int) (*GPUResourceRequest, error) {
+	gpus := int64(job.Requirements.GPUs)
+	if gpus < 1 {
+		gpus = 1
+	}
+
+	if job.Requirements.UseMIG {
+		profile, err := kubernetesMIGProfile(job.Requirements, gpuType, gpuMemoryGB)
+		if err != nil {
+			return nil, err
+		}
+		return &GPUResourceRequest{
+			Sharing:      models.GPUSharingMIG,
+			Resource:     migResourcePrefix + profile.Name,
+			Quantity:     gpus,
+			Fraction:     profile.Fraction(),
+			NodeSelector: map[string]string{migConfigLabel: "all-" + profile.Name},
+		}, nil
+	}
+
+	fraction := job.Requirements.GPUFraction
+	if fraction > 0 && fraction < 1 {
+		// Whole replicas, so the job gets at least the share it asked for
+		replicas := kb.timeSlicingReplicas
+		perGPU := int64(math.Ceil(fraction*float64(replicas) - 1e-9))
+		return &GPUResourceRequest{
+			Sharing:  models.GPUSharingTimeSlicing,
+			Resource: ResourceSharedGPU,
+			Quantity: gpus * perGPU,
+			Fraction: float64(perGPU) / float64(replicas),
+		}, nil
+	}
+
+	return &GPUResourceRequest{
+		Sharing:  models.GPUSharingNone,
+		Resource: ResourceGPU,
+		Quantity: gpus,
+		Fraction: 1,
+	}, nil
+}
+
+// kubernetesMIGProfile selects the MIG profile of a job. Without a GPU model
+// only an explicitly named profile can be used.
+func kubernetesMIGProfile(req models.JobRequirements, gpuType string, gpuMemoryGB int) (models.MIGProfile, error) {
+	if gpuType == "" {
+		if req.MIGProfile == "" {
+			return models.MIGProfile{}, fmt.Errorf("mig_profile is required when the GPU model is unknown")
+		}
+		var slices int
+		if _, err := fmt.Sscanf(req.MIGProfile, "%dg.", &slices); err != nil || slices < 1 || slices > models.MIGSlices {
+			return models.MIGProfile{}, fmt.Errorf("invalid MIG profile %q", req.MIGProfile)
+		}
+		return models.MIGProfile{Name: req.MIGProfile, Slices: slices}, nil
+	}
+
+	model, err := models.ResolveGPUModel(gpuType, gpuMemoryGB)
+	if err != nil {
+		return models.MIGProfile{}, err
+	}
+	if !model.SupportsMIG() {
+		return models.MIGProfile{}, fmt.Errorf("GPU model %s does not support MIG", model.Name)
+	}
+	return selectMIGProfile(model, req)
+}
+
+// verifyGPUResources fails fast when the cluster does not advertise the
+// requested resource in the needed quantity, which means the device plugin
+// is not configured for the sharing mode. Without an API nothing is checked.
+func (kb *KubernetesBackend) verifyGPUResources(ctx context.Context, clusterID string, request *GPUResourceRequest) error {
+	if kb.api == nil {
+		log.Printf("Cannot verify %s on Kubernetes cluster %s: no Kubernetes API configured", request.Resource, clusterID)
+		return nil
+	}
+
+	nodes, err := kb.api.NodeAllocatable(ctx, clusterID)
+	if err != nil {
+		return fmt.Errorf("failed to list allocatable resources of cluster %s: %w", clusterID, err)
+	}
+
+	var total, largest int64
+	for _, allocatable := range nodes {
+		quantity := allocatable[request.Resource]
+		total += quantity
+		if quantity > largest {
+			largest = quantity
+		}
+	}
+	if total == 0 {
+		return fmt.Errorf("cluster %s does not advertise %s: %s", clusterID, request.Resource, devicePluginHint(request))
+	}
+	if largest < request.Quantity {
+		return fmt.Errorf("no node of cluster %s has %d allocatable %s (largest: %d)", clusterID, request.Quantity, request.Resource, largest)
+	}
+	return nil
+}
+
+// devicePluginHint says how to make a cluster advertise a sharing resource
+func devicePluginHint(request *GPUResourceRequest) string {
+	switch request.Sharing {
+	case models.GPUSharingTimeSlicing:
+		return "configure the NVIDIA device plugin for time-slicing with renameByDefault (see DevicePluginConfig)"
+	case models.GPUSharingMIG:
+		return fmt.Sprintf("enable the mixed MIG strategy and label GPU nodes %s=%s for mig-parted",
+			migConfigLabel, request.NodeSelector[migConfigLabel])
+	default:
+		return "install the NVIDIA device plugin"
+	}
+}
+
+// DevicePluginConfig returns the NVIDIA device plugin configuration (the
+// ConfigMap data) that splits every GPU into replicas time-sliced replicas
+// advertised as nvidia.com/gpu.shared
+func DevicePluginConfig(replicas int) string {
+	return strings.Join([]string{
+		"version: v1",
+		"sharing:",
+		"  timeSlicing:",
+		"    renameByDefault: true",
+		"    resources:",
+		"      - name: nvidia.com/gpu",
+		fmt.Sprintf("        replicas: %d", replicas),
+		"",
+	}, "\n")
+}
+
+// applyGPUSharing renders the job's GPU request, verifies the cluster can
+// satisfy it and records the sharing mode on the allocations. Shared jobs are
+// billed for the share of the allocated GPUs they use.
+func (kb *KubernetesBackend) applyGPUSharing(ctx context.Context, clusterID string, job *models.Job, allocations []models.Allocation) (*GPUResourceRequest, error) {
+	first := allocations[0]
+	request, err := kb.RenderGPURequest(job, first.GPUType, first.GPUMemoryGB)
+	if err != nil {
+		return nil, err
+	}
+	if err := kb.verifyGPUResources(ctx, clusterID, request); err != nil {
+		return nil, err
+	}
+	if request.Sharing == models.GPUSharingNone {
+		return request, nil
+	}
+
+	capacity := 0
+	for _, alloc := range allocations {
+		capacity += alloc.Count * alloc.GPUsPerNode
+	}
+	gpus := math.Max(1, float64(job.Requirements.GPUs))
+	share := 0.0 // Unknown GPU counts bill whole instances
+	if capacity > 0 {
+		share = math.Min(1, gpus*request.Fraction/float64(capacity))
+	}
+	for i := range allocations {
+		allocations[i].GPUSharing = request.Sharing
+		allocations[i].GPUShare = share
+		allocations[i].EstimatedCost = allocations[i].ExpectedCost()
+	}
+	return request, nil
+}
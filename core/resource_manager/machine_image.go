@@ -0,0 +1,86 @@
+package resource_manager
+
+import (
+	"fmt"
+	"log"
+
+	"gpu-orchestrator/core/clock"
+	"gpu-orchestrator/core/models"
+)
+
+// ImageStore is the registry of pre-baked machine images and the boot times
+// observed for them
+type ImageStore interface {
+	FamilyImages(provider models.Provider, region, family string) ([]models.MachineImage, error)
+	RecordBoot(imageID int64, boot models.ImageBoot) error
+}
+
+// SetImageStore sets the machine image registry. Without one every instance
+// boots the provider's generic GPU image and jobs cannot pin an image.
+func (p *Provisioner) SetImageStore(store ImageStore) {
+	p.images = store
+}
+
+// selectImage returns the registered image an allocation's instances boot:
+// the job's pinned image, else the newest usable image of the allocation's
+// instance family. nil means the provider's generic GPU image. A pinned image
+// must be validated for the allocation's family; a degraded one is still used.
+func (p *Provisioner) selectImage(job *models.Job, alloc models.Allocation) (*models.MachineImage, error) {
+	pinned := job.Requirements.Image
+	if p.images == nil {
+		if pinned != "" {
+			return nil, fmt.Errorf("job pins image %s but no image registry is configured", pinned)
+		}
+		return nil, nil
+	}
+
+	family := models.InstanceFamily(alloc.InstanceType)
+	images, err := p.images.FamilyImages(alloc.Provider, alloc.Region, family)
+	if err != nil {
+		if pinned != "" {
+			return nil, fmt.Errorf("failed to look up pinned image %s: %w", pinned, err)
+		}
+		log.Printf("Failed to look up %s %s %s images, using the generic image: %v", alloc.Provider, alloc.Region, family, err)
+		return nil, nil
+	}
+
+	for i := range images {
+		image := &images[i]
+		if pinned == "" && image.Usable(false) {
+			return image, nil
+		}
+		if pinned != "" && image.ImageID == pinned && image.Usable(true) {
+			if image.DegradedAt != nil {
+				log.Printf("Job %s pins degraded image %s (%s)", job.ID, pinned, image.DegradedReason)
+			}
+			return image, nil
+		}
+	}
+	if pinned != "" {
+		return nil, fmt.Errorf("image %s is not validated for %s %s %s", pinned, alloc.Provider, alloc.Region, family)
+	}
+	return nil, nil
+}
+
+// recordImageBoots records how long the batches booted from registered images
+// took from launch until their instances were ready
+func (p *Provisioner) recordImageBoots(job *models.Job, batches []instanceBatch) {
+	if p.images == nil {
+		return
+	}
+	now := clock.System.Now()
+	for _, batch := range batches {
+		if batch.Image == nil {
+			continue
+		}
+		err := p.images.RecordBoot(batch.Image.ID, models.ImageBoot{
+			JobID:        job.ID,
+			InstanceType: batch.Allocation.InstanceType,
+			BootSeconds:  now.Sub(batch.LaunchedAt).Seconds(),
+			ObservedAt:   now,
+		})
+		if err != nil {
+			log.Printf("Failed to record boot of image %s for job %s: %v", batch.Image.ImageID, job.ID, err)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package resource_manager
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"gpu-orchestrator/core/clock"
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/providers"
+)
+
+// managedRuntimeFactor bounds a managed training job's runtime at this many
+// times its estimate, so a hung job stops billing
+const managedRuntimeFactor = 3
+
+// provisionManagedCluster hands the job to the provider's managed training
+// service. The service provisions and tears down the instances itself, so
+// the cluster has no nodes, only the service's job.
+func (p *Provisioner) provisionManagedCluster(
+	ctx context.Context,
+	job *models.Job,
+	allocations []models.Allocation,
+	clusterID string,
+) (*models.Cluster, error) {
+	first := allocations[0]
+	count, spot := 0, true
+	for _, alloc := range allocations {
+		if alloc.InstanceType != first.InstanceType {
+			return nil, fmt.Errorf("managed training runs one instance type, allocations have %s and %s", first.InstanceType, alloc.InstanceType)
+		}
+		count += alloc.Count
+		// The service runs every instance on spot or none; pinned on-demand ranks win
+		spot = spot && alloc.Spot
+	}
+
+	trainer, err := p.managedTrainer(first.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	req := providers.TrainingJobRequest{
+		Name:            fmt.Sprintf("gpu-%s-%d", job.ID, clock.System.Now().Unix()),
+		Region:          first.Region,
+		InstanceType:    first.InstanceType,
+		GPUType:         first.GPUType,
+		GPUsPerInstance: first.GPUsPerNode,
+		Count:           count,
+		Spot:            spot,
+		MaxRuntime:      time.Duration(job.Requirements.EstimatedHours * managedRuntimeFactor * float64(time.Hour)),
+		InputURI:        job.DatasetURI,
+		Environment:     managedEnvironment(job),
+		Tags:            map[string]string{"ManagedBy": "gpu-orchestrator", "JobID": job.ID},
+	}
+	if job.DataAccess != nil {
+		req.OutputURI = job.DataAccess.OutputURI
+	}
+
+	serviceJobID, err := trainer.SubmitTrainingJob(ctx, req)
+	for _, alloc := range allocations {
+		if err != nil {
+			p.setAllocationStatus(alloc, models.AllocationFailed, 0, err.Error())
+		} else {
+			p.setAllocationStatus(alloc, models.AllocationActive, alloc.Count, "submitted to managed training")
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit managed training job: %w", err)
+	}
+	log.Printf("Job %s submitted to %s managed training as %s (%d x %s, spot=%v)", job.ID, first.Provider, serviceJobID, count, first.InstanceType, spot)
+
+	return &models.Cluster{
+		ID:            clusterID,
+		Provider:      first.Provider,
+		Region:        first.Region,
+		Backend:       models.BackendManaged,
+		AllocationIDs: allocationIDs(allocations),
+		ServiceJobID:  serviceJobID,
+	}, nil
+}
+
+// managedEnvironment returns the variables the training container gets
+func managedEnvironment(job *models.Job) map[string]string {
+	env := map[string]string{
+		"JOB_ID":             job.ID,
+		"TRAINING_FRAMEWORK": job.Framework,
+		"ENTRYPOINT_URI":     job.EntrypointURI,
+	}
+	if job.DatasetURI != "" {
+		env["DATASET_URI"] = job.DatasetURI
+	}
+	if job.Checkpointing != nil {
+		for name, value := range job.Checkpointing.Environment(job.ID) {
+			env[name] = value
+		}
+	}
+	return env
+}
+
+// terminateManagedCluster stops the cluster's training job if it still runs
+func (p *Provisioner) terminateManagedCluster(ctx context.Context, cluster *models.Cluster) error {
+	trainer, err := p.managedTrainer(cluster.Provider)
+	if err != nil {
+		return err
+	}
+	return trainer.StopTrainingJob(ctx, cluster.Region, cluster.ServiceJobID)
+}
+
+// managedTrainer returns a provider's managed training service
+func (p *Provisioner) managedTrainer(provider models.Provider) (providers.ManagedTrainer, error) {
+	client, ok := p.providers.Get(provider)
+	if !ok {
+		return nil, fmt.Errorf("provider %s not configured", provider)
+	}
+	trainer, ok := client.(providers.ManagedTrainer)
+	if !ok {
+		return nil, fmt.Errorf("provider %s has no managed training service", provider)
+	}
+	return trainer, nil
+}
@@ -0,0 +1,77 @@
+package resource_manager
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/supervisor"
+)
+
+// PortStore persists the ports jobs hold on nodes
+type PortStore interface {
+	AllocateNodePort(node, jobID, service string, low, high int) (int, error)
+	ReleaseJobPorts(jobID string) (int64, error)
+	ReleaseEndedJobPorts() (int64, error)
+}
+
+// PortAllocator hands out master and worker ports from a configured range,
+// per node, so jobs whose processes share a node (GPU sharing, bin packing)
+// never listen on the same port. Ports are held until the job ends.
+type PortAllocator struct {
+	store     PortStore
+	low, high int
+}
+
+// NewPortAllocator creates a port allocator over ports [low, high]
+func NewPortAllocator(store PortStore, low, high int) *PortAllocator {
+	return &PortAllocator{store: store, low: low, high: high}
+}
+
+// AllocatePort returns the port of a job's service on a node. A service the
+// job already holds on the node keeps its port, so relaunches reuse it.
+func (pa *PortAllocator) AllocatePort(node models.Node, jobID, service string) (int, error) {
+	return pa.store.AllocateNodePort(portNodeKey(node), jobID, service, pa.low, pa.high)
+}
+
+// ReleaseJob releases every port a job holds
+func (pa *PortAllocator) ReleaseJob(jobID string) error {
+	if _, err := pa.store.ReleaseJobPorts(jobID); err != nil {
+		return fmt.Errorf("failed to release ports of job %s: %w", jobID, err)
+	}
+	return nil
+}
+
+// Start releases the ports of ended jobs every interval, catching jobs that
+// ended without releasing their cluster (failures, cancellations)
+func (pa *PortAllocator) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			supervisor.Heartbeat(ctx)
+			released, err := pa.store.ReleaseEndedJobPorts()
+			if err != nil {
+				log.Printf("Failed to release ports of ended jobs: %v", err)
+			} else if released > 0 {
+				log.Printf("Released %d ports held by ended jobs", released)
+			}
+		}
+	}
+}
+
+// portNodeKey identifies a node across jobs: its provider instance, or its
+// node ID (Kubernetes nodes) when it has none
+func portNodeKey(node models.Node) string {
+	id := node.InstanceID
+	if id == "" {
+		id = node.ID
+	}
+	return string(node.Provider) + "/" + id
+}
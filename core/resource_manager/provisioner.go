@@ -4,40 +4,70 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
+	"gpu-orchestrator/core/clock"
 	"gpu-orchestrator/core/models"
-	"gpu-orchestrator/providers/aws"
-	"gpu-orchestrator/providers/azure"
-	"gpu-orchestrator/providers/gcp"
+	"gpu-orchestrator/providers"
+	"gpu-orchestrator/training/bootstrap"
+	"gpu-orchestrator/training/network"
 )
 
+// BootstrapRecorder stores the rendered boot script of a job for debugging
+type BootstrapRecorder interface {
+	CreateArtifact(jobID string, artifactType models.ArtifactType, uri string, meta map[string]interface{}) error
+}
+
 // Provisioner manages compute resource provisioning across providers
 type Provisioner struct {
-	awsClient   *aws.Client
-	gcpClient   *gcp.Client
-	azureClient *azure.Client
+	providers        providers.Registry
+	bootstrapDefault *models.BootstrapConfig // Org-level snippets applied before the job's
+	bootstrapRecords BootstrapRecorder
+	identities       IdentityStore   // Attached instance identities; see SetIdentityStore
+	allocations      AllocationStore // Allocation lifecycle; see SetAllocationStore
+	images           ImageStore      // Pre-baked machine images; see SetImageStore
+	sshKeys          SSHKeyIssuer    // Per-cluster SSH keys; see SetSSHKeys
+	sshUser          string          // Node user the cluster SSH keys log in as
+	kubernetes       *KubernetesBackend
 }
 
 // NewProvisioner creates a new provisioner
-func NewProvisioner(
-	awsClient *aws.Client,
-	gcpClient *gcp.Client,
-	azureClient *azure.Client,
-) *Provisioner {
+func NewProvisioner(registry providers.Registry) *Provisioner {
 	return &Provisioner{
-		awsClient:   awsClient,
-		gcpClient:   gcpClient,
-		azureClient: azureClient,
+		providers:  registry,
+		kubernetes: NewKubernetesBackend(),
 	}
 }
 
+// Kubernetes returns the backend that places jobs on Kubernetes clusters
+func (p *Provisioner) Kubernetes() *KubernetesBackend {
+	return p.kubernetes
+}
+
+// SetBootstrap sets the org-level default bootstrap and where rendered boot
+// scripts are recorded. Both may be nil.
+func (p *Provisioner) SetBootstrap(defaults *models.BootstrapConfig, recorder BootstrapRecorder) {
+	p.bootstrapDefault = defaults
+	p.bootstrapRecords = recorder
+}
+
 // ProvisionCluster provisions a cluster for a job
 // Phase 3: Supports both VM and Kubernetes backends
 func (p *Provisioner) ProvisionCluster(
 	ctx context.Context,
 	job *models.Job,
 	allocations []models.Allocation,
+) (*models.Cluster, error) {
+	return p.provisionCluster(ctx, job, allocations, fmt.Sprintf("cluster-%s", job.ID))
+}
+
+// provisionCluster provisions a cluster for a job under clusterID
+func (p *Provisioner) provisionCluster(
+	ctx context.Context,
+	job *models.Job,
+	allocations []models.Allocation,
+	clusterID string,
 ) (*models.Cluster, error) {
 	if len(allocations) == 0 {
 		return nil, fmt.Errorf("no allocations provided")
@@ -61,9 +91,28 @@ func (p *Provisioner) ProvisionCluster(
 	// Route to appropriate backend
 	switch backend {
 	case models.BackendKubernetes:
-		return p.provisionKubernetesCluster(ctx, job, allocations)
+		cluster, err := p.provisionKubernetesCluster(ctx, job, allocations)
+		for _, alloc := range allocations {
+			if err != nil {
+				p.setAllocationStatus(alloc, models.AllocationFailed, 0, err.Error())
+			} else {
+				p.setAllocationSharing(alloc)
+				p.setAllocationStatus(alloc, models.AllocationActive, alloc.Count, "")
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		cluster.AllocationIDs = allocationIDs(allocations)
+		return cluster, nil
 	case models.BackendVM:
-		return p.provisionVMCluster(ctx, job, allocations)
+		return p.provisionVMCluster(ctx, job, allocations, clusterID)
+	case models.BackendManaged:
+		if models.IsManagedInstanceType(firstAlloc.Provider, firstAlloc.InstanceType) {
+			return p.provisionManagedCluster(ctx, job, allocations, clusterID)
+		}
+		// The optimizer found raw VMs cheaper than any managed service
+		return p.provisionVMCluster(ctx, job, allocations, clusterID)
 	case models.BackendSlurm:
 		return nil, fmt.Errorf("Slurm backend not yet implemented")
 	case models.BackendRay:
@@ -73,66 +122,187 @@ func (p *Provisioner) ProvisionCluster(
 	}
 }
 
+// ProvisionTaskCluster provisions the cluster of one task of a multi_task job.
+// Cluster and node IDs are scoped to the task so tearing it down never
+// touches sibling tasks.
+func (p *Provisioner) ProvisionTaskCluster(ctx context.Context, job *models.Job, task *models.JobTask) (*models.Cluster, error) {
+	clusterID := fmt.Sprintf("cluster-%s-task-%d", job.ID, task.Index)
+	cluster, err := p.provisionCluster(ctx, job, []models.Allocation{task.Allocation}, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster.ID = clusterID
+	jobPrefix := fmt.Sprintf("node-%s-", job.ID)
+	for i := range cluster.Nodes {
+		cluster.Nodes[i].ID = fmt.Sprintf("node-%s-task-%d-%s", job.ID, task.Index, strings.TrimPrefix(cluster.Nodes[i].ID, jobPrefix))
+		cluster.Nodes[i].ClusterID = clusterID
+	}
+	return cluster, nil
+}
+
 // provisionVMCluster provisions a VM-based cluster (Phase 1/2)
 func (p *Provisioner) provisionVMCluster(
 	ctx context.Context,
 	job *models.Job,
 	allocations []models.Allocation,
+	clusterID string,
 ) (*models.Cluster, error) {
 	firstAlloc := allocations[0]
 
 	// Provision instances based on provider
 	var nodes []models.Node
-	var instanceIDs []string
-	var err error
-
-	switch firstAlloc.Provider {
-	case models.ProviderAWS:
-		instanceIDs, err = p.provisionAWS(ctx, allocations)
-	case models.ProviderGCP:
-		instanceIDs, err = p.provisionGCP(ctx, allocations)
-	case models.ProviderAzure:
-		instanceIDs, err = p.provisionAzure(ctx, allocations)
-	case models.ProviderOnPrem:
-		return nil, fmt.Errorf("on-premise provisioning not yet implemented")
-	default:
-		return nil, fmt.Errorf("unsupported provider: %s", firstAlloc.Provider)
+
+	client, ok := p.providers.Get(firstAlloc.Provider)
+	if !ok {
+		if firstAlloc.Provider == models.ProviderOnPrem {
+			return nil, fmt.Errorf("on-premise provisioning not yet implemented")
+		}
+		return nil, fmt.Errorf("provider %s not configured", firstAlloc.Provider)
 	}
 
+	// Every instance of a cluster goes to one zone; spread tasks arrive with theirs
+	zone := firstAlloc.Zone
+	if zone == "" {
+		zone = chooseZone(ctx, client, allocations)
+	}
+	for i := range allocations {
+		allocations[i].Zone = zone
+		p.setAllocationZone(allocations[i])
+	}
+
+	// The nodes authorize a key of their own, issued before they boot
+	sshKey, err := p.issueSSHKey(job, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	batches, err := p.provisionInstances(ctx, client, job, allocations, sshKey)
 	if err != nil {
+		p.discardSSHKey(sshKey)
 		return nil, fmt.Errorf("failed to provision instances: %w", err)
 	}
 
+	instanceCount := 0
+	for _, batch := range batches {
+		instanceCount += len(batch.InstanceIDs)
+	}
+
 	// Wait for instances to be ready
-	log.Printf("Waiting for %d instances to be ready...", len(instanceIDs))
+	log.Printf("Waiting for %d instances to be ready...", instanceCount)
 	time.Sleep(30 * time.Second) // TODO: Implement proper instance readiness check
+	p.recordImageBoots(job, batches)
 
 	// Build cluster and nodes
 	cluster := &models.Cluster{
-		ID:       fmt.Sprintf("cluster-%s", job.ID),
+		ID:       clusterID,
 		Provider: firstAlloc.Provider,
 		Region:   firstAlloc.Region,
+		Zone:     zone,
 		VPC:      "default", // TODO: Get actual VPC
 		Backend:  models.BackendVM,
 		Nodes:    nodes,
 	}
 
-	// Create nodes from instance IDs
-	for i, instanceID := range instanceIDs {
-		alloc := allocations[0] // For now, assume single allocation type
-		node := models.Node{
-			ID:         fmt.Sprintf("node-%s-%d", job.ID, i),
-			InstanceID: instanceID,
-			Provider:   firstAlloc.Provider,
-			Region:     firstAlloc.Region,
-			VPC:        cluster.VPC,
-			PrivateIP:  fmt.Sprintf("10.0.1.%d", i+10), // TODO: Get actual private IP
-			GPUs:       alloc.Count * 8,                // TODO: Get actual GPU count from instance type
+	cluster.Nodes = buildNodes(job, cluster, batches, 0)
+	cluster.AllocationIDs = allocationIDs(allocations)
+	recordNodeZones(ctx, client, cluster)
+	p.activateSSHKey(sshKey)
+
+	return cluster, nil
+}
+
+// allocationIDs returns the IDs of stored allocations
+func allocationIDs(allocations []models.Allocation) []int64 {
+	var ids []int64
+	for _, alloc := range allocations {
+		if alloc.ID != 0 {
+			ids = append(ids, alloc.ID)
 		}
-		cluster.Nodes = append(cluster.Nodes, node)
 	}
+	return ids
+}
 
-	return cluster, nil
+// buildNodes creates nodes from launched instances (spot flag comes from the allocation row).
+// Node indexes start at firstIndex so nodes added to a running cluster keep unique IDs.
+func buildNodes(job *models.Job, cluster *models.Cluster, batches []instanceBatch, firstIndex int) []models.Node {
+	var nodes []models.Node
+	i := firstIndex
+	for _, batch := range batches {
+		for _, instanceID := range batch.InstanceIDs {
+			nodes = append(nodes, models.Node{
+				ID:           fmt.Sprintf("node-%s-%d", job.ID, i),
+				ClusterID:    cluster.ID,
+				InstanceID:   instanceID,
+				InstanceType: batch.Allocation.InstanceType,
+				Provider:     cluster.Provider,
+				Region:       cluster.Region,
+				Zone:         batch.Allocation.Zone,
+				VPC:          cluster.VPC,
+				PrivateIP:    fmt.Sprintf("10.0.1.%d", i+10), // TODO: Get actual private IP
+				GPUs:         batch.Allocation.Count * 8,     // TODO: Get actual GPU count from instance type
+				GPUType:      batch.Allocation.GPUType,
+				GPUMemoryGB:  batch.Allocation.GPUMemoryGB,
+				Spot:         batch.Allocation.Spot,
+			})
+			i++
+		}
+	}
+	return nodes
+}
+
+// AddNodes provisions extra instances for a running VM cluster and returns the new nodes.
+// Used by elastic jobs to grow without reprovisioning the whole cluster.
+func (p *Provisioner) AddNodes(
+	ctx context.Context,
+	job *models.Job,
+	cluster *models.Cluster,
+	alloc models.Allocation,
+) ([]models.Node, error) {
+	if cluster.Backend != models.BackendVM {
+		return nil, fmt.Errorf("adding nodes is only supported on the VM backend, cluster %s uses %s", cluster.ID, cluster.Backend)
+	}
+	if alloc.Provider != cluster.Provider || alloc.Region != cluster.Region {
+		return nil, fmt.Errorf("new nodes must be in %s/%s, got %s/%s", cluster.Provider, cluster.Region, alloc.Provider, alloc.Region)
+	}
+
+	client, ok := p.providers.Get(cluster.Provider)
+	if !ok {
+		return nil, fmt.Errorf("provider %s not configured", cluster.Provider)
+	}
+
+	// New nodes join the cluster's zone
+	alloc.Zone = cluster.Zone
+
+	// New nodes authorize the key the cluster uses now
+	sshKey, err := p.clusterSSHKey(cluster.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	batches, err := p.provisionInstances(ctx, client, job, []models.Allocation{alloc}, sshKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to provision instances: %w", err)
+	}
+
+	// Wait for instances to be ready
+	time.Sleep(30 * time.Second) // TODO: Implement proper instance readiness check
+	p.recordImageBoots(job, batches)
+
+	return buildNodes(job, cluster, batches, nextNodeIndex(job, cluster)), nil
+}
+
+// nextNodeIndex returns the first node index not used by the cluster's node IDs
+func nextNodeIndex(job *models.Job, cluster *models.Cluster) int {
+	next := len(cluster.Nodes)
+	prefix := fmt.Sprintf("node-%s-", job.ID)
+	for _, node := range cluster.Nodes {
+		var index int
+		if _, err := fmt.Sscanf(strings.TrimPrefix(node.ID, prefix), "%d", &index); err == nil && index >= next {
+			next = index + 1
+		}
+	}
+	return next
 }
 
 // provisionKubernetesCluster provisions a Kubernetes cluster (Phase 3)
@@ -142,57 +312,258 @@ func (p *Provisioner) provisionKubernetesCluster(
 	allocations []models.Allocation,
 ) (*models.Cluster, error) {
 	// Phase 3: Use Kubernetes backend
-	k8sBackend := NewKubernetesBackend()
-	return k8sBackend.ProvisionCluster(ctx, job, allocations)
+	return p.kubernetes.ProvisionCluster(ctx, job, allocations)
 }
 
-// provisionAWS provisions AWS EC2 instances
-func (p *Provisioner) provisionAWS(ctx context.Context, allocations []models.Allocation) ([]string, error) {
-	if p.awsClient == nil {
-		return nil, fmt.Errorf("AWS client not initialized")
-	}
+// instanceBatch pairs an allocation row with the instances launched for it
+type instanceBatch struct {
+	Allocation  models.Allocation
+	InstanceIDs []string
+	Image       *models.MachineImage // Registered image the instances booted; nil = generic
+	LaunchedAt  time.Time
+}
 
-	var allInstanceIDs []string
+// provisionInstances provisions all allocations through the provider client.
+// Allocations are launched as a gang: when one fails or launches fewer
+// instances than requested, every instance launched so far is terminated.
+func (p *Provisioner) provisionInstances(
+	ctx context.Context,
+	client providers.Provider,
+	job *models.Job,
+	allocations []models.Allocation,
+	sshKey *models.ClusterSSHKey,
+) ([]instanceBatch, error) {
+	var batches []instanceBatch
+
+	identity, err := p.instanceIdentity(ctx, client, job)
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach instance identity: %w", err)
+	}
 
 	for _, alloc := range allocations {
-		instanceIDs, err := p.awsClient.ProvisionGPUInstance(
-			ctx,
-			alloc.InstanceType,
-			alloc.Region,
-			alloc.Spot,
-			alloc.Count,
-		)
+		launchedAt := clock.System.Now()
+		image, err := p.selectImage(job, alloc)
+		var instanceIDs []string
+		if err == nil {
+			instanceIDs, err = p.launchAllocation(ctx, client, job, alloc, identity, image, sshKey)
+			p.recordLaunch(job, alloc, instanceIDs)
+		}
+		if err == nil && len(instanceIDs) < alloc.Count {
+			err = fmt.Errorf("provider launched %d of %d %s instances", len(instanceIDs), alloc.Count, alloc.InstanceType)
+		}
 		if err != nil {
-			return nil, fmt.Errorf("failed to provision AWS instances: %w", err)
+			p.setAllocationStatus(alloc, models.AllocationFailed, len(instanceIDs), err.Error())
+			p.teardownBatches(ctx, client, alloc.Region, batches, instanceIDs)
+			return nil, err
 		}
-		allInstanceIDs = append(allInstanceIDs, instanceIDs...)
+		p.setAllocationStatus(alloc, models.AllocationActive, len(instanceIDs), "")
+		batches = append(batches, instanceBatch{Allocation: alloc, InstanceIDs: instanceIDs, Image: image, LaunchedAt: launchedAt})
 	}
 
-	return allInstanceIDs, nil
+	return batches, nil
 }
 
-// provisionGCP provisions GCP instances
-func (p *Provisioner) provisionGCP(_ context.Context, _ []models.Allocation) ([]string, error) {
-	if p.gcpClient == nil {
-		return nil, fmt.Errorf("GCP client not initialized")
+// launchAllocation renders the boot script of one allocation and launches its
+// instances from image (nil = the generic GPU image), authorizing sshKey when
+// set. Providers may return the instances they launched with an error.
+func (p *Provisioner) launchAllocation(ctx context.Context, client providers.Provider, job *models.Job, alloc models.Allocation, identity string, image *models.MachineImage, sshKey *models.ClusterSSHKey) ([]string, error) {
+	// Install the host packages the network profile needs (EFA, OFED, ...)
+	profile, err := network.Resolve(alloc.Provider, alloc.InstanceType, job.Network)
+	if err != nil {
+		return nil, err
 	}
 
-	// TODO: Implement GCP provisioning
-	return nil, fmt.Errorf("GCP provisioning not yet implemented")
-}
+	// Mount data storage before the snippets so they can use it
+	script := profile.BootstrapScript()
+	script += authorizedKeyScript(p.sshUser, sshKey)
+	if job.Requirements.Storage > 0 {
+		script += dataMountScript(alloc.VolumeGB)
+	}
+
+	// Org default and job snippets (CA certs, package index, agents)
+	snippets, err := bootstrap.Render(alloc.Provider, alloc.Region, bootstrap.Merge(p.bootstrapDefault, job.Bootstrap))
+	if err != nil {
+		return nil, fmt.Errorf("failed to render bootstrap: %w", err)
+	}
+	script += snippets
+	p.recordBootstrap(job, alloc, script)
 
-// provisionAzure provisions Azure instances
-func (p *Provisioner) provisionAzure(_ context.Context, _ []models.Allocation) ([]string, error) {
-	if p.azureClient == nil {
-		return nil, fmt.Errorf("Azure client not initialized")
+	var imageID string
+	if image != nil {
+		imageID = image.ImageID
 	}
 
-	// TODO: Implement Azure provisioning
-	return nil, fmt.Errorf("Azure provisioning not yet implemented")
+	p.setAllocationStatus(alloc, models.AllocationProvisioning, 0, "")
+	return client.ProvisionInstances(ctx, providers.InstanceRequest{
+		InstanceType:    alloc.InstanceType,
+		Region:          alloc.Region,
+		Zone:            alloc.Zone,
+		Spot:            alloc.Spot,
+		Count:           alloc.Count,
+		BootstrapScript: script,
+		Identity:        identity,
+		DataVolume:      dataVolumeRequest(job, alloc),
+		SpotMaxPrice:    alloc.SpotBid,
+		ImageID:         imageID,
+	})
+}
+
+// recordBootstrap stores the boot script rendered for an allocation.
+// Provider-specific user-data wraps it (see the provider clients).
+func (p *Provisioner) recordBootstrap(job *models.Job, alloc models.Allocation, script string) {
+	if p.bootstrapRecords == nil || script == "" {
+		return
+	}
+	uri := fmt.Sprintf("inline:bootstrap/%s/%s/%s", alloc.Provider, alloc.Region, alloc.InstanceType)
+	err := p.bootstrapRecords.CreateArtifact(job.ID, models.ArtifactTypeBootstrap, uri, map[string]interface{}{
+		"provider":      alloc.Provider,
+		"region":        alloc.Region,
+		"instance_type": alloc.InstanceType,
+		"script":        script,
+	})
+	if err != nil {
+		log.Printf("Failed to record bootstrap script for job %s: %v", job.ID, err)
+	}
 }
 
 // TerminateCluster terminates all instances in a cluster
 func (p *Provisioner) TerminateCluster(ctx context.Context, cluster *models.Cluster) error {
-	// TODO: Implement termination logic
+	if cluster.Backend == models.BackendKubernetes {
+		if err := p.kubernetes.TerminateCluster(ctx, cluster); err != nil {
+			return err
+		}
+	} else if cluster.Backend == models.BackendManaged {
+		if err := p.terminateManagedCluster(ctx, cluster); err != nil {
+			return fmt.Errorf("failed to stop training job of cluster %s: %w", cluster.ID, err)
+		}
+	} else if err := p.TerminateNodes(ctx, cluster, cluster.Nodes); err != nil {
+		return fmt.Errorf("failed to terminate cluster %s: %w", cluster.ID, err)
+	}
+
+	p.markClusterTerminated(cluster)
+	p.destroySSHKeys(cluster.ID)
+	return nil
+}
+
+// TerminateNodes terminates a subset of a VM cluster's instances
+func (p *Provisioner) TerminateNodes(ctx context.Context, cluster *models.Cluster, nodes []models.Node) error {
+	client, ok := p.providers.Get(cluster.Provider)
+	if !ok {
+		return fmt.Errorf("provider %s not configured", cluster.Provider)
+	}
+
+	instanceIDs := nodeInstanceIDs(nodes)
+	if len(instanceIDs) == 0 {
+		return nil
+	}
+
+	if err := client.TerminateInstances(ctx, cluster.Region, instanceIDs); err != nil {
+		return err
+	}
+	p.recordTermination(cluster.Provider, cluster.Region, instanceIDs)
+	return nil
+}
+
+// CanStop reports whether a cluster can be stopped and restarted later:
+// VM backend, a provider that supports stopping, and no spot nodes
+// (spot instances are reclaimed rather than stopped)
+func (p *Provisioner) CanStop(cluster *models.Cluster) bool {
+	if cluster.Backend != models.BackendVM {
+		return false
+	}
+	client, ok := p.providers.Get(cluster.Provider)
+	if !ok {
+		return false
+	}
+	if _, ok := client.(providers.Stopper); !ok {
+		return false
+	}
+	for _, node := range cluster.Nodes {
+		if node.Spot {
+			return false
+		}
+	}
+	return true
+}
+
+// StopCluster stops a cluster's instances, keeping their disks
+func (p *Provisioner) StopCluster(ctx context.Context, cluster *models.Cluster) error {
+	stopper, err := p.stopper(cluster)
+	if err != nil {
+		return err
+	}
+	if err := stopper.StopInstances(ctx, cluster.Region, nodeInstanceIDs(cluster.Nodes)); err != nil {
+		return fmt.Errorf("failed to stop cluster %s: %w", cluster.ID, err)
+	}
 	return nil
 }
+
+// StartCluster restarts a stopped cluster and waits until every instance is running.
+// Private IPs can change across a stop/start, so nodes are refreshed from the provider.
+func (p *Provisioner) StartCluster(ctx context.Context, cluster *models.Cluster) error {
+	stopper, err := p.stopper(cluster)
+	if err != nil {
+		return err
+	}
+	instanceIDs := nodeInstanceIDs(cluster.Nodes)
+	if err := stopper.StartInstances(ctx, cluster.Region, instanceIDs); err != nil {
+		return fmt.Errorf("failed to start cluster %s: %w", cluster.ID, err)
+	}
+
+	client, _ := p.providers.Get(cluster.Provider)
+	deadline := time.Now().Add(10 * time.Minute)
+	for {
+		infos, err := client.DescribeInstances(ctx, cluster.Region, instanceIDs)
+		if err != nil {
+			return fmt.Errorf("failed to describe cluster %s: %w", cluster.ID, err)
+		}
+
+		running := make(map[string]providers.InstanceInfo, len(infos))
+		for _, info := range infos {
+			if info.State == providers.InstanceStateRunning {
+				running[info.InstanceID] = info
+			}
+		}
+		if len(running) == len(instanceIDs) {
+			for i := range cluster.Nodes {
+				if ip := running[cluster.Nodes[i].InstanceID].PrivateIP; ip != "" {
+					cluster.Nodes[i].PrivateIP = ip
+				}
+			}
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("cluster %s: %d/%d instances running after restart", cluster.ID, len(running), len(instanceIDs))
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(10 * time.Second):
+		}
+	}
+}
+
+// stopper returns the cluster provider's stop/start support
+func (p *Provisioner) stopper(cluster *models.Cluster) (providers.Stopper, error) {
+	client, ok := p.providers.Get(cluster.Provider)
+	if !ok {
+		return nil, fmt.Errorf("provider %s not configured", cluster.Provider)
+	}
+	stopper, ok := client.(providers.Stopper)
+	if !ok {
+		return nil, fmt.Errorf("provider %s does not support stopping instances", cluster.Provider)
+	}
+	return stopper, nil
+}
+
+// nodeInstanceIDs returns the provider instance IDs of nodes
+func nodeInstanceIDs(nodes []models.Node) []string {
+	instanceIDs := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		if node.InstanceID != "" {
+			instanceIDs = append(instanceIDs, node.InstanceID)
+		}
+	}
+	return instanceIDs
+}
@@ -0,0 +1,114 @@
+package resource_manager
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"gpu-orchestrator/core/models"
+)
+
+// SSHKeyIssuer issues the per-cluster SSH keys whose public half is baked
+// into the nodes' boot script
+type SSHKeyIssuer interface {
+	// Issue creates a pending key for a cluster about to launch
+	Issue(jobID, clusterID string) (*models.ClusterSSHKey, error)
+	// Activate makes an issued key its cluster's active key
+	Activate(key *models.ClusterSSHKey) error
+	// Active returns the cluster's active key, nil when it has none
+	Active(clusterID string) (*models.ClusterSSHKey, error)
+	// Discard destroys a key whose cluster never came up
+	Discard(key *models.ClusterSSHKey) error
+	// DestroyCluster destroys every key of a terminated cluster
+	DestroyCluster(clusterID string) error
+}
+
+// SetSSHKeys sets the issuer of per-cluster SSH keys and the node user they
+// authorize. Without an issuer, nodes only accept the keys of their image.
+func (p *Provisioner) SetSSHKeys(issuer SSHKeyIssuer, user string) {
+	p.sshKeys = issuer
+	p.sshUser = user
+}
+
+// issueSSHKey issues the key of a cluster about to launch; nil without an issuer
+func (p *Provisioner) issueSSHKey(job *models.Job, clusterID string) (*models.ClusterSSHKey, error) {
+	if p.sshKeys == nil {
+		return nil, nil
+	}
+	key, err := p.sshKeys.Issue(job.ID, clusterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue SSH key for cluster %s: %w", clusterID, err)
+	}
+	return key, nil
+}
+
+// activateSSHKey activates the key of a cluster whose nodes came up
+func (p *Provisioner) activateSSHKey(key *models.ClusterSSHKey) {
+	if key == nil {
+		return
+	}
+	if err := p.sshKeys.Activate(key); err != nil {
+		log.Printf("Failed to activate SSH key %s of cluster %s: %v", key.Fingerprint, key.ClusterID, err)
+	}
+}
+
+// discardSSHKey destroys the key of a cluster that failed to launch
+func (p *Provisioner) discardSSHKey(key *models.ClusterSSHKey) {
+	if key == nil {
+		return
+	}
+	if err := p.sshKeys.Discard(key); err != nil {
+		log.Printf("Failed to discard SSH key %s of cluster %s: %v", key.Fingerprint, key.ClusterID, err)
+	}
+}
+
+// clusterSSHKey returns the active key nodes added to a running cluster must
+// accept; nil without an issuer or for clusters launched without one
+func (p *Provisioner) clusterSSHKey(clusterID string) (*models.ClusterSSHKey, error) {
+	if p.sshKeys == nil {
+		return nil, nil
+	}
+	key, err := p.sshKeys.Active(clusterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SSH key of cluster %s: %w", clusterID, err)
+	}
+	return key, nil
+}
+
+// destroySSHKeys destroys the keys of a terminated cluster
+func (p *Provisioner) destroySSHKeys(clusterID string) {
+	if p.sshKeys == nil {
+		return
+	}
+	if err := p.sshKeys.DestroyCluster(clusterID); err != nil {
+		log.Printf("Failed to destroy SSH keys of cluster %s: %v", clusterID, err)
+	}
+}
+
+// authorizedKeyScript appends key to the node user's authorized_keys at boot;
+// empty for a nil key
+func authorizedKeyScript(user string, key *models.ClusterSSHKey) string {
+	if key == nil {
+		return ""
+	}
+	return fmt.Sprintf(`# Cluster SSH key %[3]s
+ssh_user=%[1]s
+ssh_home=$(getent passwd "$ssh_user" | cut -d: -f6)
+if [ -n "$ssh_home" ]; then
+    install -d -m 700 -o "$ssh_user" "$ssh_home/.ssh"
+    echo '%[2]s' >> "$ssh_home/.ssh/authorized_keys"
+    chown "$ssh_user" "$ssh_home/.ssh/authorized_keys"
+    chmod 600 "$ssh_home/.ssh/authorized_keys"
+fi
+`, shellWord(user), key.PublicKey, key.Fingerprint)
+}
+
+// shellWord keeps the characters of a user name that are safe unquoted
+func shellWord(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || r == '.' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return -1
+	}, s)
+}
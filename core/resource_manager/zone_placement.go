@@ -0,0 +1,140 @@
+package resource_manager
+
+import (
+	"context"
+	"log"
+	"sort"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/providers"
+)
+
+// zoneRank is a zone able to host a set of allocations
+type zoneRank struct {
+	zone     string
+	spotCost float64 // Hourly spot cost of the allocations' spot instances in the zone
+	score    int     // Lowest placement score over the spot allocations; 0 = unknown
+}
+
+// rankZones returns the zones offering every allocation's instance type, best
+// first: cheapest spot cost, then best placement score. Returns nil when the
+// provider does not report zones.
+func rankZones(ctx context.Context, client providers.Provider, allocations []models.Allocation) []zoneRank {
+	reporter, ok := client.(providers.ZoneReporter)
+	if !ok || len(allocations) == 0 {
+		return nil
+	}
+
+	ranks := make(map[string]*zoneRank)
+	offered := make(map[string]int) // Allocations each zone can host
+	for _, alloc := range allocations {
+		offers, err := reporter.ZoneOffers(ctx, alloc.Region, alloc.InstanceType)
+		if err != nil {
+			log.Printf("Failed to get zones of %s in %s %s, leaving the zone to the provider: %v", alloc.InstanceType, alloc.Provider, alloc.Region, err)
+			return nil
+		}
+		for _, offer := range offers {
+			rank, ok := ranks[offer.Zone]
+			if !ok {
+				rank = &zoneRank{zone: offer.Zone}
+				ranks[offer.Zone] = rank
+			}
+			offered[offer.Zone]++
+			if !alloc.Spot {
+				continue
+			}
+			// Without a zone price the region's spot price stands in
+			price := offer.SpotPrice
+			if price == 0 {
+				price = alloc.PricePerHour
+			}
+			rank.spotCost += price * float64(alloc.Count)
+			if offer.PlacementScore > 0 && (rank.score == 0 || offer.PlacementScore < rank.score) {
+				rank.score = offer.PlacementScore
+			}
+		}
+	}
+
+	var ranked []zoneRank
+	for zone, rank := range ranks {
+		if offered[zone] == len(allocations) {
+			ranked = append(ranked, *rank)
+		}
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].spotCost != ranked[j].spotCost {
+			return ranked[i].spotCost < ranked[j].spotCost
+		}
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		return ranked[i].zone < ranked[j].zone
+	})
+	return ranked
+}
+
+// chooseZone picks the one zone every instance of a cluster is launched in,
+// so its nodes share low-latency networking. Returns "" when the provider
+// does not report zones or no zone offers every instance type.
+func chooseZone(ctx context.Context, client providers.Provider, allocations []models.Allocation) string {
+	ranked := rankZones(ctx, client, allocations)
+	if len(ranked) == 0 {
+		return ""
+	}
+	return ranked[0].zone
+}
+
+// SpreadZones assigns the allocations of a multi_task job (one per task) to
+// distinct zones of their region, best zone first, for availability. When a
+// region has fewer zones than tasks, zones are reused evenly.
+func (p *Provisioner) SpreadZones(ctx context.Context, allocations []models.Allocation) {
+	used := make(map[string]map[string]int) // Tasks per zone, by provider and region
+	for i := range allocations {
+		alloc := &allocations[i]
+		client, ok := p.providers.Get(alloc.Provider)
+		if !ok {
+			continue
+		}
+		ranked := rankZones(ctx, client, []models.Allocation{*alloc})
+		if len(ranked) == 0 {
+			continue
+		}
+
+		key := string(alloc.Provider) + "/" + alloc.Region
+		if used[key] == nil {
+			used[key] = make(map[string]int)
+		}
+		// Best ranked among the least used zones
+		best := ranked[0].zone
+		for _, rank := range ranked[1:] {
+			if used[key][rank.zone] < used[key][best] {
+				best = rank.zone
+			}
+		}
+		if used[key][best] > 0 {
+			log.Printf("%s %s has fewer zones than tasks; task %d shares zone %s", alloc.Provider, alloc.Region, i, best)
+		}
+		used[key][best]++
+		alloc.Zone = best
+	}
+}
+
+// recordNodeZones fills in the zones of nodes launched without a chosen zone
+// from the provider
+func recordNodeZones(ctx context.Context, client providers.Provider, cluster *models.Cluster) {
+	if cluster.Zone != "" {
+		return
+	}
+	infos, err := client.DescribeInstances(ctx, cluster.Region, nodeInstanceIDs(cluster.Nodes))
+	if err != nil {
+		log.Printf("Failed to get zones of cluster %s: %v", cluster.ID, err)
+		return
+	}
+	zones := make(map[string]string, len(infos))
+	for _, info := range infos {
+		zones[info.InstanceID] = info.Zone
+	}
+	for i := range cluster.Nodes {
+		cluster.Nodes[i].Zone = zones[cluster.Nodes[i].InstanceID]
+	}
+}
@@ -4,39 +4,91 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"math"
+	"sort"
+	"sync"
 	"time"
 
+	"gpu-orchestrator/core/clock"
 	"gpu-orchestrator/core/resource_manager"
+	"gpu-orchestrator/core/supervisor"
 )
 
+// maxWaitWeight caps how much a long-waiting job's GPUs count toward demand
+const maxWaitWeight = 2.0
+
+// waitWeightPeriod is the wait after which a job's GPUs count double
+const waitWeightPeriod = 30 * time.Minute
+
+// maxClusterShapeGPUs is the largest cluster shape the autoscaler requests
+const maxClusterShapeGPUs = 64
+
+// DemandForecast predicts GPUs of jobs that will be queued soon, such as
+// jobs whose schedule windows open within the horizon
+type DemandForecast interface {
+	ForecastGPUs(from, to time.Time) int
+}
+
 // AutoScaler automatically scales cluster pool based on demand
 // Inspired by Cast AI's autoscaling approach
 // Phase 2: Full implementation
 type AutoScaler struct {
 	clusterPool       *resource_manager.ClusterPool
 	queue             *JobQueue
-	scaleUpThreshold  int           // Number of pending jobs to trigger scale-up
+	minScaleUpGPUs    int           // Unmet GPU demand needed to trigger scale-up
 	scaleDownIdleTime time.Duration // Idle time before scale-down
+	interval          time.Duration
+	forecast          DemandForecast // Optional; nil keeps no idle clusters for future demand
+	forecastHorizon   time.Duration
+	now               func() time.Time
+	mu                sync.Mutex
+	last              DemandSnapshot
+}
+
+// DemandSnapshot is GPU demand against pool capacity at one check
+type DemandSnapshot struct {
+	DemandGPUs         int     `json:"demand_gpus"`          // GPUs requested by queued jobs
+	WeightedDemandGPUs float64 `json:"weighted_demand_gpus"` // Demand weighted by time waited
+	ForecastGPUs       int     `json:"forecast_gpus"`
+	AvailableGPUs      int     `json:"available_gpus"`
+	ProvisioningGPUs   int     `json:"provisioning_gpus"`
+	UnmetGPUs          int     `json:"unmet_gpus"`
 }
 
-// NewAutoScaler creates a new autoscaler
+// NewAutoScaler creates a new autoscaler. The pool grows once weighted GPU
+// demand exceeds its available and provisioning GPUs by more than
+// minScaleUpGPUs.
 func NewAutoScaler(
 	clusterPool *resource_manager.ClusterPool,
 	queue *JobQueue,
-	scaleUpThreshold int,
+	minScaleUpGPUs int,
 	scaleDownIdleTime time.Duration,
 ) *AutoScaler {
 	return &AutoScaler{
 		clusterPool:       clusterPool,
 		queue:             queue,
-		scaleUpThreshold:  scaleUpThreshold,
+		minScaleUpGPUs:    minScaleUpGPUs,
 		scaleDownIdleTime: scaleDownIdleTime,
+		interval:          30 * time.Second,
+		now:               clock.System.Now,
 	}
 }
 
+// SetInterval sets how often demand is checked (default 30 seconds)
+func (as *AutoScaler) SetInterval(interval time.Duration) {
+	as.interval = interval
+}
+
+// SetForecast keeps idle clusters that cover demand forecast within horizon
+// from being scaled down
+func (as *AutoScaler) SetForecast(forecast DemandForecast, horizon time.Duration) {
+	as.forecast = forecast
+	as.forecastHorizon = horizon
+}
+
 // Start starts the autoscaler background worker
 func (as *AutoScaler) Start(ctx context.Context) {
-	ticker := time.NewTicker(30 * time.Second) // Check every 30 seconds
+	ticker := time.NewTicker(as.interval)
 	defer ticker.Stop()
 
 	for {
@@ -44,6 +96,7 @@ func (as *AutoScaler) Start(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
+			supervisor.Heartbeat(ctx)
 			if err := as.CheckAndScale(ctx); err != nil {
 				log.Printf("Autoscaler error: %v", err)
 			}
@@ -51,32 +104,123 @@ func (as *AutoScaler) Start(ctx context.Context) {
 	}
 }
 
-// CheckAndScale checks queue depth and scales cluster pool accordingly
+// CheckAndScale compares queued GPU demand with pool capacity and scales the
+// pool by GPUs. Demand counts each queued job's GPUs weighted by how long it
+// has waited; supply is the pool's available GPUs plus GPUs still
+// provisioning.
 func (as *AutoScaler) CheckAndScale(ctx context.Context) error {
-	queueDepth := as.queue.Len()
+	now := as.now()
+	queued := as.queue.Snapshot()
+	capacity := as.clusterPool.Capacity()
+
+	snapshot := DemandSnapshot{
+		AvailableGPUs:    capacity.AvailableGPUs,
+		ProvisioningGPUs: capacity.ProvisioningGPUs,
+	}
+	for _, item := range queued {
+		snapshot.DemandGPUs += item.Job.Requirements.GPUs
+		snapshot.WeightedDemandGPUs += float64(item.Job.Requirements.GPUs) * waitWeight(now.Sub(item.Job.CreatedAt))
+	}
+	supply := capacity.AvailableGPUs + capacity.ProvisioningGPUs
+	if unmet := int(math.Ceil(snapshot.WeightedDemandGPUs)) - supply; unmet > 0 {
+		snapshot.UnmetGPUs = unmet
+	}
+	if as.forecast != nil {
+		snapshot.ForecastGPUs = as.forecast.ForecastGPUs(now, now.Add(as.forecastHorizon))
+	}
+	as.mu.Lock()
+	as.last = snapshot
+	as.mu.Unlock()
 
-	// Scale up if queue depth exceeds threshold
-	if queueDepth > as.scaleUpThreshold {
-		demand := queueDepth - as.scaleUpThreshold
-		log.Printf("Autoscaler: Queue depth %d exceeds threshold %d, scaling up by %d", queueDepth, as.scaleUpThreshold, demand)
-		if err := as.clusterPool.ScaleUp(ctx, demand); err != nil {
+	// Scale up if unmet demand exceeds threshold
+	if snapshot.UnmetGPUs > as.minScaleUpGPUs {
+		shapes := planShapes(queued, supply, snapshot.UnmetGPUs)
+		log.Printf("Autoscaler: GPU demand %d (weighted %.1f) exceeds supply %d, scaling up by %d GPUs",
+			snapshot.DemandGPUs, snapshot.WeightedDemandGPUs, supply, snapshot.UnmetGPUs)
+		if _, err := as.clusterPool.ScaleUp(ctx, shapes); err != nil {
 			return fmt.Errorf("failed to scale up: %w", err)
 		}
 	}
 
-	// Scale down idle clusters
-	if err := as.clusterPool.ScaleDown(ctx, as.scaleDownIdleTime); err != nil {
+	// Scale down idle clusters, keeping enough for queued and forecast demand
+	keep := snapshot.DemandGPUs + snapshot.ForecastGPUs
+	removed, kept, err := as.clusterPool.ScaleDown(ctx, as.scaleDownIdleTime, keep)
+	if err != nil {
 		return fmt.Errorf("failed to scale down: %w", err)
 	}
+	if removed > 0 || kept > 0 {
+		log.Printf("Autoscaler: removed %d idle clusters, kept %d for %d GPUs of queued and forecast demand", removed, kept, keep)
+	}
 
 	return nil
 }
 
-// GetStatistics returns autoscaler statistics
+// waitWeight scales a job's GPU demand by how long it has waited, from 1 when
+// queued up to maxWaitWeight
+func waitWeight(waited time.Duration) float64 {
+	if waited <= 0 {
+		return 1
+	}
+	return math.Min(1+waited.Hours()/waitWeightPeriod.Hours(), maxWaitWeight)
+}
+
+// planShapes picks cluster shapes for unmet GPU demand following the queue's
+// size distribution: jobs at the head are assumed to fit in existing supply,
+// and each later job adds a cluster of the smallest power-of-two size that
+// fits it until the unmet demand is covered
+func planShapes(queued []QueuedJob, supply, unmet int) []resource_manager.ClusterShape {
+	counts := make(map[int]int)
+	for _, item := range queued {
+		gpus := item.Job.Requirements.GPUs
+		if gpus <= 0 {
+			continue
+		}
+		if supply >= gpus {
+			supply -= gpus
+			continue
+		}
+		shape := clusterShapeGPUs(gpus)
+		counts[shape]++
+		unmet -= shape
+		if unmet <= 0 {
+			break
+		}
+	}
+
+	shapes := make([]resource_manager.ClusterShape, 0, len(counts))
+	for gpus, count := range counts {
+		shapes = append(shapes, resource_manager.ClusterShape{GPUs: gpus, Count: count})
+	}
+	sort.Slice(shapes, func(i, j int) bool { return shapes[i].GPUs < shapes[j].GPUs })
+	return shapes
+}
+
+// clusterShapeGPUs returns the smallest power of two of at least gpus, capped
+// at maxClusterShapeGPUs (larger jobs span several clusters)
+func clusterShapeGPUs(gpus int) int {
+	shape := 1
+	for shape < gpus && shape < maxClusterShapeGPUs {
+		shape *= 2
+	}
+	return shape
+}
+
+// GetStatistics returns autoscaler statistics, with GPU demand and capacity
+// as of the last check
 func (as *AutoScaler) GetStatistics() map[string]interface{} {
+	as.mu.Lock()
+	last := as.last
+	as.mu.Unlock()
+
 	return map[string]interface{}{
 		"queue_depth":                  as.queue.Len(),
-		"scale_up_threshold":           as.scaleUpThreshold,
+		"min_scale_up_gpus":            as.minScaleUpGPUs,
 		"scale_down_idle_time_seconds": int(as.scaleDownIdleTime.Seconds()),
+		"demand_gpus":                  last.DemandGPUs,
+		"weighted_demand_gpus":         last.WeightedDemandGPUs,
+		"forecast_gpus":                last.ForecastGPUs,
+		"available_gpus":               last.AvailableGPUs,
+		"provisioning_gpus":            last.ProvisioningGPUs,
+		"unmet_gpus":                   last.UnmetGPUs,
 	}
 }
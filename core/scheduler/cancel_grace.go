@@ -0,0 +1,82 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gpu-orchestrator/core/clock"
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/core/supervisor"
+)
+
+// CancelGrace finishes cancellations whose grace window ended. Cancelling a
+// running job first moves it to cancelling, where it keeps running and
+// accruing cost and can be restored with POST /v1/jobs/{id}/uncancel. Once
+// the window passes, the job is moved to cancelled and its execution path
+// tears the cluster down, as for an immediate cancellation.
+type CancelGrace struct {
+	jobRepo *repository.JobRepository
+	window  time.Duration
+	now     func() time.Time
+}
+
+// NewCancelGrace creates a sweeper for cancellations with the given grace window
+func NewCancelGrace(jobRepo *repository.JobRepository, window time.Duration) *CancelGrace {
+	return &CancelGrace{
+		jobRepo: jobRepo,
+		window:  window,
+		now:     clock.System.Now,
+	}
+}
+
+// Start sweeps every interval until ctx is done
+func (cg *CancelGrace) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			supervisor.Heartbeat(ctx)
+			if err := cg.Sweep(ctx); err != nil {
+				log.Printf("Cancellation grace sweep failed: %v", err)
+			}
+		}
+	}
+}
+
+// Sweep cancels every job that has been cancelling for the whole window. With
+// the window disabled, jobs left cancelling by an earlier configuration are
+// cancelled right away.
+func (cg *CancelGrace) Sweep(_ context.Context) error {
+	ages, err := cg.jobRepo.ListStatusAges([]models.JobStatus{models.JobStatusCancelling})
+	if err != nil {
+		return fmt.Errorf("failed to list cancelling jobs: %w", err)
+	}
+
+	now := cg.now()
+	for _, age := range ages {
+		if now.Sub(age.Since) < cg.window {
+			continue
+		}
+		err := cg.jobRepo.UpdateJobStatus(age.JobID, models.JobStatusCancelling, models.JobStatusCancelled, "cancel_grace_expired", map[string]interface{}{
+			"requested_at":  age.Since,
+			"grace_seconds": int(cg.window.Seconds()),
+		})
+		if errors.Is(err, repository.ErrStatusConflict) {
+			continue // Uncancelled or finished meanwhile
+		}
+		if err != nil {
+			log.Printf("Failed to finish cancellation of job %s: %v", age.JobID, err)
+			continue
+		}
+		log.Printf("Job %s cancelled after its %s grace window", age.JobID, cg.window)
+	}
+	return nil
+}
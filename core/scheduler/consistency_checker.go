@@ -0,0 +1,453 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gpu-orchestrator/core/clock"
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/core/resource_manager"
+	"gpu-orchestrator/core/supervisor"
+	"gpu-orchestrator/providers"
+)
+
+// Consistency check parameters
+const (
+	// consistencyDescribeBatch is how many instances one describe call asks about
+	consistencyDescribeBatch = 100
+	// consistencyAllocationLimit caps the active allocations read per check
+	consistencyAllocationLimit = 5000
+)
+
+// ConsistencyPolicy configures the consistency checker
+type ConsistencyPolicy struct {
+	ProviderPause time.Duration // Wait between provider API calls
+	Grace         time.Duration // Instances launched and jobs started more recently are not checked
+	CostGapRatio  float64       // Flag running jobs whose recorded cost is below this share of their allocations' price
+}
+
+// ConsistencyChecker compares the jobs and allocations in the database with
+// the clusters the scheduler tracks, the instances providers report and the
+// cost recorded so far, and stores what disagrees as a report. It only reads:
+// nothing is terminated, requeued or re-billed. Provider calls are paced by
+// ProviderPause and marked non-critical, so a check never eats the call
+// budget that provisioning needs.
+type ConsistencyChecker struct {
+	jobRepo        *repository.JobRepository
+	allocationRepo *repository.AllocationRepository
+	reportRepo     *repository.ConsistencyRepository
+	providers      providers.Registry
+	scheduler      *Scheduler                    // Tracked clusters; set with SetScheduler
+	clusterPool    *resource_manager.ClusterPool // Pooled and hibernated clusters; optional
+	policy         ConsistencyPolicy
+	now            func() time.Time
+
+	mu     sync.Mutex
+	latest *models.ConsistencyReport // Last finished check, for metrics
+}
+
+// NewConsistencyChecker creates a new consistency checker
+func NewConsistencyChecker(jobRepo *repository.JobRepository, allocationRepo *repository.AllocationRepository, reportRepo *repository.ConsistencyRepository, registry providers.Registry, policy ConsistencyPolicy) *ConsistencyChecker {
+	return &ConsistencyChecker{
+		jobRepo:        jobRepo,
+		allocationRepo: allocationRepo,
+		reportRepo:     reportRepo,
+		providers:      registry,
+		policy:         policy,
+		now:            clock.System.Now,
+	}
+}
+
+// SetScheduler sets the scheduler whose running clusters are checked
+func (cc *ConsistencyChecker) SetScheduler(scheduler *Scheduler) {
+	cc.scheduler = scheduler
+}
+
+// SetClusterPool sets the pool whose pooled and hibernated clusters count as tracked
+func (cc *ConsistencyChecker) SetClusterPool(pool *resource_manager.ClusterPool) {
+	cc.clusterPool = pool
+}
+
+// Start checks every interval until ctx is done
+func (cc *ConsistencyChecker) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			supervisor.Heartbeat(ctx)
+			report, err := cc.Check(ctx)
+			if err != nil {
+				log.Printf("Consistency check failed: %v", err)
+				continue
+			}
+			if len(report.Discrepancies) > 0 {
+				log.Printf("Consistency check %d found %d discrepancies: %v", report.ID, len(report.Discrepancies), report.Counts)
+			}
+		}
+	}
+}
+
+// trackedNode is a node of a cluster the orchestrator tracks
+type trackedNode struct {
+	clusterID string
+	jobID     string // Empty for pooled and hibernated clusters
+	node      models.Node
+}
+
+// Check runs one consistency check and stores its report
+func (cc *ConsistencyChecker) Check(ctx context.Context) (*models.ConsistencyReport, error) {
+	report := &models.ConsistencyReport{
+		StartedAt:     cc.now(),
+		Counts:        make(map[string]int),
+		Discrepancies: []models.Discrepancy{},
+	}
+	for _, category := range models.DiscrepancyCategories {
+		report.Counts[category] = 0
+	}
+
+	if err := cc.checkRecords(report); err != nil {
+		return nil, err
+	}
+	if err := cc.checkInstances(ctx, report); err != nil {
+		return nil, err
+	}
+
+	report.FinishedAt = cc.now()
+	if err := cc.reportRepo.CreateReport(report); err != nil {
+		return nil, fmt.Errorf("failed to store consistency report: %w", err)
+	}
+
+	cc.mu.Lock()
+	cc.latest = report
+	cc.mu.Unlock()
+	return report, nil
+}
+
+// checkRecords compares job statuses, active allocations, tracked clusters
+// and recorded cost
+func (cc *ConsistencyChecker) checkRecords(report *models.ConsistencyReport) error {
+	costs, err := cc.jobRepo.ListActiveJobCosts()
+	if err != nil {
+		return fmt.Errorf("failed to list active job costs: %w", err)
+	}
+	fleet, err := cc.allocationRepo.ListFleetAllocations(models.AllocationActive, false, consistencyAllocationLimit)
+	if err != nil {
+		return fmt.Errorf("failed to list active allocations: %w", err)
+	}
+
+	active := make(map[string][]models.Allocation)
+	for _, entry := range fleet {
+		if entry.Orphaned {
+			report.Add(models.Discrepancy{
+				Category:     models.DiscrepancyStatusMismatch,
+				JobID:        entry.JobID,
+				AllocationID: entry.Allocation.ID,
+				Provider:     entry.Allocation.Provider,
+				Region:       entry.Allocation.Region,
+				Expected:     "job provisioning, running, checkpointing or cancelling",
+				Actual:       string(entry.JobStatus),
+				Detail:       fmt.Sprintf("allocation %d is active but its job is %s", entry.Allocation.ID, entry.JobStatus),
+			})
+			continue
+		}
+		active[entry.JobID] = append(active[entry.JobID], entry.Allocation)
+	}
+
+	now := cc.now()
+	for _, record := range costs {
+		if record.Status == models.JobStatusProvisioning || record.StartedAt == nil || now.Sub(*record.StartedAt) < cc.policy.Grace {
+			continue
+		}
+		allocations := active[record.JobID]
+		if len(allocations) == 0 {
+			report.Add(models.Discrepancy{
+				Category: models.DiscrepancyStatusMismatch,
+				JobID:    record.JobID,
+				Expected: "an active allocation",
+				Actual:   "none",
+				Detail:   fmt.Sprintf("job is %s but holds no active allocation", record.Status),
+			})
+			continue
+		}
+		hourly := 0.0
+		for _, alloc := range allocations {
+			hourly += alloc.HourlyPrice() * float64(alloc.Count)
+		}
+		expected := hourly * now.Sub(*record.StartedAt).Hours()
+		if cc.policy.CostGapRatio > 0 && expected > 0 && record.CostUSD < expected*cc.policy.CostGapRatio {
+			report.Add(models.Discrepancy{
+				Category: models.DiscrepancyCostGap,
+				JobID:    record.JobID,
+				Expected: fmt.Sprintf("$%.2f", expected),
+				Actual:   fmt.Sprintf("$%.2f", record.CostUSD),
+				Detail: fmt.Sprintf("recorded cost is %.0f%% of $%.2f/h since %s",
+					100*record.CostUSD/expected, hourly, record.StartedAt.Format(time.RFC3339)),
+			})
+		}
+	}
+
+	if cc.scheduler == nil {
+		return nil
+	}
+	clusters := cc.scheduler.RunningClusters()
+	jobIDs := make([]string, 0, len(clusters))
+	for jobID := range clusters {
+		jobIDs = append(jobIDs, jobID)
+	}
+	sort.Strings(jobIDs)
+	statuses, err := cc.jobRepo.GetJobStatuses(jobIDs)
+	if err != nil {
+		return fmt.Errorf("failed to load statuses of tracked jobs: %w", err)
+	}
+	for _, jobID := range jobIDs {
+		status := statuses[jobID]
+		switch status {
+		case models.JobStatusProvisioning, models.JobStatusRunning, models.JobStatusCheckpointing, models.JobStatusCancelling:
+			continue
+		}
+		actual := string(status)
+		if actual == "" {
+			actual = "missing"
+		}
+		report.Add(models.Discrepancy{
+			Category:  models.DiscrepancyStatusMismatch,
+			JobID:     jobID,
+			ClusterID: clusters[jobID].ID,
+			Provider:  clusters[jobID].Provider,
+			Region:    clusters[jobID].Region,
+			Expected:  "job provisioning, running, checkpointing or cancelling",
+			Actual:    actual,
+			Detail:    fmt.Sprintf("scheduler still tracks cluster %s of a %s job", clusters[jobID].ID, actual),
+		})
+	}
+	return nil
+}
+
+// checkInstances compares the nodes of tracked clusters with the instances
+// providers report, in both directions
+func (cc *ConsistencyChecker) checkInstances(ctx context.Context, report *models.ConsistencyReport) error {
+	ctx = providers.NonCritical(ctx)
+
+	tracked := cc.trackedNodes()
+	byRegion := make(map[string][]trackedNode)
+	var regionKeys []string
+	for _, tn := range tracked {
+		key := string(tn.node.Provider) + "/" + tn.node.Region
+		if _, ok := byRegion[key]; !ok {
+			regionKeys = append(regionKeys, key)
+		}
+		byRegion[key] = append(byRegion[key], tn)
+	}
+	sort.Strings(regionKeys)
+
+	// Missing instances: tracked nodes the provider no longer runs
+	var missing []trackedNode
+	for _, key := range regionKeys {
+		nodes := byRegion[key]
+		client, ok := cc.providers[nodes[0].node.Provider]
+		if !ok {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("%s: provider not configured", key))
+			continue
+		}
+		for start := 0; start < len(nodes); start += consistencyDescribeBatch {
+			end := start + consistencyDescribeBatch
+			if end > len(nodes) {
+				end = len(nodes)
+			}
+			ids := make([]string, 0, end-start)
+			for _, tn := range nodes[start:end] {
+				ids = append(ids, tn.node.InstanceID)
+			}
+			if err := cc.pause(ctx); err != nil {
+				return err
+			}
+			infos, err := client.DescribeInstances(ctx, nodes[0].node.Region, ids)
+			if err != nil {
+				report.Skipped = append(report.Skipped, fmt.Sprintf("%s: describe instances: %v", key, err))
+				break
+			}
+			states := make(map[string]providers.InstanceState, len(infos))
+			for _, info := range infos {
+				states[info.InstanceID] = info.State
+			}
+			for _, tn := range nodes[start:end] {
+				if state, ok := states[tn.node.InstanceID]; !ok || state == providers.InstanceStateTerminated {
+					missing = append(missing, tn)
+				}
+			}
+		}
+	}
+
+	// Unexpected instances: managed instances no tracked cluster holds
+	known := make(map[string]bool, len(tracked))
+	for _, tn := range tracked {
+		known[tn.node.InstanceID] = true
+	}
+	var unexpected []models.Discrepancy
+	for _, name := range sortedProviders(cc.providers) {
+		lister, ok := cc.providers[name].(providers.InstanceLister)
+		if !ok {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("%s: cannot list instances", name))
+			continue
+		}
+		for _, region := range cc.providers[name].Regions() {
+			if err := cc.pause(ctx); err != nil {
+				return err
+			}
+			infos, err := lister.ListManagedInstances(ctx, region)
+			if errors.Is(err, providers.ErrUnsupported) {
+				report.Skipped = append(report.Skipped, fmt.Sprintf("%s: cannot list instances", name))
+				break
+			}
+			if err != nil {
+				report.Skipped = append(report.Skipped, fmt.Sprintf("%s/%s: list instances: %v", name, region, err))
+				continue
+			}
+			for _, info := range infos {
+				if known[info.InstanceID] || info.State == providers.InstanceStateTerminated {
+					continue
+				}
+				if info.LaunchedAt != nil && cc.now().Sub(*info.LaunchedAt) < cc.policy.Grace {
+					continue // Likely still being attached to its cluster
+				}
+				known[info.InstanceID] = true // Clients that ignore the region list it again
+				unexpected = append(unexpected, models.Discrepancy{
+					Category:   models.DiscrepancyUnexpectedInstance,
+					Provider:   name,
+					Region:     info.Region,
+					InstanceID: info.InstanceID,
+					Expected:   "no instance",
+					Actual:     string(info.State),
+					Detail:     fmt.Sprintf("%s instance tagged ManagedBy=gpu-orchestrator belongs to no tracked cluster", info.InstanceType),
+				})
+			}
+		}
+	}
+
+	// Clusters change while the providers are asked; only report what still
+	// disagrees with a fresh snapshot
+	current := make(map[string]bool)
+	for _, tn := range cc.trackedNodes() {
+		current[tn.node.InstanceID] = true
+	}
+	for _, tn := range missing {
+		if !current[tn.node.InstanceID] {
+			continue
+		}
+		report.Add(models.Discrepancy{
+			Category:   models.DiscrepancyMissingInstance,
+			JobID:      tn.jobID,
+			ClusterID:  tn.clusterID,
+			Provider:   tn.node.Provider,
+			Region:     tn.node.Region,
+			InstanceID: tn.node.InstanceID,
+			Expected:   "instance present",
+			Actual:     "absent or terminated",
+			Detail:     fmt.Sprintf("%s node of cluster %s is gone at the provider", tn.node.InstanceType, tn.clusterID),
+		})
+	}
+	for _, d := range unexpected {
+		if !current[d.InstanceID] {
+			report.Add(d)
+		}
+	}
+	return nil
+}
+
+// trackedNodes returns the VM nodes of every cluster the scheduler and the
+// cluster pool hold
+func (cc *ConsistencyChecker) trackedNodes() []trackedNode {
+	var nodes []trackedNode
+	add := func(cluster *models.Cluster, jobID string) {
+		if cluster == nil || (cluster.Backend != "" && cluster.Backend != models.BackendVM) {
+			return // Pods and managed jobs are not provider instances
+		}
+		for _, node := range cluster.Nodes {
+			if node.InstanceID == "" {
+				continue
+			}
+			if node.Provider == "" {
+				node.Provider = cluster.Provider
+			}
+			if node.Region == "" {
+				node.Region = cluster.Region
+			}
+			nodes = append(nodes, trackedNode{clusterID: cluster.ID, jobID: jobID, node: node})
+		}
+	}
+	if cc.scheduler != nil {
+		for jobID, cluster := range cc.scheduler.RunningClusters() {
+			add(cluster, jobID)
+		}
+	}
+	if cc.clusterPool != nil {
+		for _, cluster := range cc.clusterPool.Clusters() {
+			add(cluster, "")
+		}
+	}
+	return nodes
+}
+
+// pause waits ProviderPause before a provider call
+func (cc *ConsistencyChecker) pause(ctx context.Context) error {
+	if cc.policy.ProviderPause <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(cc.policy.ProviderPause)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// PrometheusMetrics returns the checker's metrics in Prometheus format
+func (cc *ConsistencyChecker) PrometheusMetrics() string {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	if cc.latest == nil {
+		return ""
+	}
+
+	var metrics strings.Builder
+
+	metrics.WriteString("# HELP gpu_consistency_discrepancies Discrepancies found by the last consistency check\n")
+	metrics.WriteString("# TYPE gpu_consistency_discrepancies gauge\n")
+	for _, category := range models.DiscrepancyCategories {
+		fmt.Fprintf(&metrics, "gpu_consistency_discrepancies{category=\"%s\"} %d\n", category, cc.latest.Counts[category])
+	}
+
+	metrics.WriteString("# HELP gpu_consistency_skipped_checks Provider regions the last consistency check could not check\n")
+	metrics.WriteString("# TYPE gpu_consistency_skipped_checks gauge\n")
+	fmt.Fprintf(&metrics, "gpu_consistency_skipped_checks %d\n", len(cc.latest.Skipped))
+
+	metrics.WriteString("# HELP gpu_consistency_last_check_timestamp_seconds When the last consistency check finished\n")
+	metrics.WriteString("# TYPE gpu_consistency_last_check_timestamp_seconds gauge\n")
+	fmt.Fprintf(&metrics, "gpu_consistency_last_check_timestamp_seconds %d\n", cc.latest.FinishedAt.Unix())
+
+	return metrics.String()
+}
+
+// sortedProviders returns the registry's providers in order
+func sortedProviders(registry providers.Registry) []models.Provider {
+	names := make([]models.Provider, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
@@ -0,0 +1,478 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"gpu-orchestrator/core/clock"
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/monitoring"
+	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/core/supervisor"
+)
+
+const (
+	deadlineForecastWindow     = 24 * time.Hour   // Recent starts the queue forecast is built from
+	deadlineForecastMinSamples = 3                // Fewer starts than this: no wait forecast
+	deadlineDefaultStartup     = 10 * time.Minute // Provisioning time assumed without a forecast
+	deadlineMinSpread          = 5 * time.Minute  // Least uncertainty of a projection
+
+	// Relative uncertainty of each projected duration
+	deadlineStepsUncertainty = 0.15 // Measured step rate
+	deadlineTimeUncertainty  = 0.30 // Estimated runtime
+	deadlineQueueUncertainty = 0.50 // Queue wait and startup
+
+	// Speedup per added GPU assumed when suggesting more GPUs
+	deadlineScalingEfficiency = 0.9
+)
+
+// deadlineScalableFrameworks are the distributed training frameworks that
+// run faster on more GPUs without code changes
+var deadlineScalableFrameworks = map[string]bool{
+	"pytorch_ddp":            true,
+	"horovod":                true,
+	"horovod_elastic":        true,
+	"tensorflow_multiworker": true,
+	"deepspeed":              true,
+	"jax":                    true,
+}
+
+// DeadlineRiskPolicy holds the risk (0-1) at which each level starts
+type DeadlineRiskPolicy struct {
+	Watch      float64
+	AtRisk     float64
+	LikelyMiss float64
+}
+
+// level returns the level of a risk
+func (p DeadlineRiskPolicy) level(risk float64) string {
+	switch {
+	case risk >= p.LikelyMiss:
+		return models.DeadlineRiskLikelyMiss
+	case risk >= p.AtRisk:
+		return models.DeadlineRiskAtRisk
+	case risk >= p.Watch:
+		return models.DeadlineRiskWatch
+	}
+	return models.DeadlineRiskLow
+}
+
+// queueForecast is how long queued jobs are expected to wait, from the jobs
+// that started recently
+type queueForecast struct {
+	Wait    time.Duration // 75th percentile from submission to scheduling
+	Startup time.Duration // Median from scheduling to start
+	Samples int
+}
+
+// DeadlineRiskEvaluator periodically projects when each unfinished job with
+// a deadline will finish and how likely it is to miss the deadline. Running
+// jobs are projected from their reported step rate, or their estimated
+// runtime before they report; queued jobs also wait for the forecast queue
+// time and startup. Each assessment is stored for the job view. When a job
+// first reaches watch, at_risk or likely_miss an event is recorded and its
+// owner notified, with remedies that would lower the risk.
+type DeadlineRiskEvaluator struct {
+	jobRepo        *repository.JobRepository
+	allocationRepo *repository.AllocationRepository
+	notifier       monitoring.Notifier // Optional; nil records events only
+	policy         DeadlineRiskPolicy
+	now            func() time.Time
+}
+
+// NewDeadlineRiskEvaluator creates a new deadline risk evaluator
+func NewDeadlineRiskEvaluator(jobRepo *repository.JobRepository, allocationRepo *repository.AllocationRepository, policy DeadlineRiskPolicy) *DeadlineRiskEvaluator {
+	return &DeadlineRiskEvaluator{
+		jobRepo:        jobRepo,
+		allocationRepo: allocationRepo,
+		policy:         policy,
+		now:            clock.System.Now,
+	}
+}
+
+// SetNotifier sets where deadline risk warnings are delivered
+func (e *DeadlineRiskEvaluator) SetNotifier(notifier monitoring.Notifier) {
+	e.notifier = notifier
+}
+
+// Start evaluates deadline jobs every interval until ctx is done
+func (e *DeadlineRiskEvaluator) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			supervisor.Heartbeat(ctx)
+			if err := e.Evaluate(ctx); err != nil {
+				log.Printf("Deadline risk evaluation failed: %v", err)
+			}
+		}
+	}
+}
+
+// Evaluate assesses every unfinished job with a deadline
+func (e *DeadlineRiskEvaluator) Evaluate(ctx context.Context) error {
+	jobs, err := e.jobRepo.ListDeadlineJobs()
+	if err != nil {
+		return fmt.Errorf("failed to list deadline jobs: %w", err)
+	}
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	now := e.now()
+	forecast, err := e.forecast(now)
+	if err != nil {
+		return fmt.Errorf("failed to forecast queue wait: %w", err)
+	}
+	ages, err := e.jobRepo.ListStatusAges([]models.JobStatus{models.JobStatusPending})
+	if err != nil {
+		return fmt.Errorf("failed to list pending job ages: %w", err)
+	}
+	pendingSince := make(map[string]time.Time, len(ages))
+	for _, age := range ages {
+		pendingSince[age.JobID] = age.Since
+	}
+
+	for _, job := range jobs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		risk := assessDeadlineRisk(job, forecast, pendingSince[job.ID], now, e.policy)
+		if models.DeadlineRiskRank(risk.Level) > 0 {
+			risk.Remedies = deadlineRemedies(job, e.decision(job), e.onSpot(job), risk)
+		}
+		if err := e.jobRepo.SetDeadlineRisk(job.ID, risk); err != nil {
+			log.Printf("Failed to store deadline risk of job %s: %v", job.ID, err)
+			continue
+		}
+		if models.DeadlineRiskRank(risk.Level) == 0 {
+			continue
+		}
+		escalated, err := e.jobRepo.EscalateDeadlineRisk(job.ID, risk.Level)
+		if err != nil {
+			log.Printf("Failed to escalate deadline risk of job %s: %v", job.ID, err)
+			continue
+		}
+		if escalated {
+			e.warn(ctx, job, risk)
+		}
+	}
+	return nil
+}
+
+// forecast builds the queue forecast from the jobs that started in the
+// forecast window
+func (e *DeadlineRiskEvaluator) forecast(now time.Time) (queueForecast, error) {
+	timings, err := e.jobRepo.ListStartTimings(now.Add(-deadlineForecastWindow))
+	if err != nil {
+		return queueForecast{}, err
+	}
+	return buildQueueForecast(timings), nil
+}
+
+// buildQueueForecast summarizes start timings; with too few of them queued
+// jobs are assumed to be scheduled right away
+func buildQueueForecast(timings []repository.JobStartTiming) queueForecast {
+	forecast := queueForecast{Startup: deadlineDefaultStartup, Samples: len(timings)}
+	if len(timings) < deadlineForecastMinSamples {
+		return forecast
+	}
+	waits := make([]time.Duration, 0, len(timings))
+	startups := make([]time.Duration, 0, len(timings))
+	for _, timing := range timings {
+		waits = append(waits, nonNegative(timing.ScheduledAt.Sub(timing.CreatedAt)))
+		startups = append(startups, nonNegative(timing.StartedAt.Sub(timing.ScheduledAt)))
+	}
+	forecast.Wait = durationPercentile(waits, 0.75)
+	forecast.Startup = durationPercentile(startups, 0.5)
+	return forecast
+}
+
+// durationPercentile returns the nearest-rank percentile of durations
+func durationPercentile(durations []time.Duration, p float64) time.Duration {
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	index := int(math.Ceil(p*float64(len(durations)))) - 1
+	if index < 0 {
+		index = 0
+	}
+	return durations[index]
+}
+
+func nonNegative(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// assessDeadlineRisk projects a job's finish and the risk of it falling
+// after the deadline. The projection is treated as normally distributed
+// around the expected finish, with a spread from the uncertainty of each
+// remaining duration; the risk is the share of it past the deadline.
+func assessDeadlineRisk(job *models.Job, forecast queueForecast, pendingSince, now time.Time, policy DeadlineRiskPolicy) *models.DeadlineRisk {
+	deadline := *job.Constraints.Deadline
+	risk := &models.DeadlineRisk{Deadline: deadline, EvaluatedAt: now}
+
+	// Training time left
+	var run time.Duration
+	uncertainty := deadlineTimeUncertainty
+	from := now
+	if job.TotalSteps > 0 && job.Progress != nil && job.Progress.Rate() > 0 {
+		risk.Basis = models.DeadlineBasisSteps
+		uncertainty = deadlineStepsUncertainty
+		left := job.TotalSteps - job.Progress.StepsCompleted
+		if left < 0 {
+			left = 0
+		}
+		run = time.Duration(float64(left) / job.Progress.Rate() * float64(time.Hour))
+		// Steps keep being trained between reports of a running job
+		if job.Status == models.JobStatusRunning && job.Progress.ReportedAt.Before(now) {
+			from = job.Progress.ReportedAt
+		}
+	} else {
+		risk.Basis = models.DeadlineBasisTime
+		estimate := time.Duration(job.Requirements.EstimatedHours * float64(time.Hour))
+		run = estimate
+		if job.StartedAt != nil && (job.Status == models.JobStatusRunning || job.Status == models.JobStatusCheckpointing) {
+			// Overrunning jobs are assumed to be at least a tenth from done
+			run = estimate - now.Sub(*job.StartedAt)
+			if run < estimate/10 {
+				run = estimate / 10
+			}
+		}
+	}
+
+	// Wait and startup before training runs
+	var queue time.Duration
+	switch job.Status {
+	case models.JobStatusPending:
+		waited := time.Duration(0)
+		if !pendingSince.IsZero() {
+			waited = nonNegative(now.Sub(pendingSince))
+		}
+		// A job that already waited longer than the forecast is expected to
+		// wait about half as long again
+		queue = forecast.Wait - waited
+		if queue < waited/2 {
+			queue = waited / 2
+		}
+		queue += forecast.Startup
+	case models.JobStatusScheduled, models.JobStatusProvisioning:
+		queue = forecast.Startup
+	}
+
+	finish := from.Add(queue + run)
+	if finish.Before(now) {
+		finish = now
+	}
+	spread := time.Duration(math.Hypot(uncertainty*float64(run), deadlineQueueUncertainty*float64(queue)))
+	if spread < deadlineMinSpread {
+		spread = deadlineMinSpread
+	}
+
+	z := float64(finish.Sub(deadline)) / float64(spread)
+	risk.Risk = math.Round(0.5*math.Erfc(-z/math.Sqrt2)*1000) / 1000
+	risk.Level = policy.level(risk.Risk)
+	risk.ProjectedFinish = finish.Truncate(time.Second)
+	risk.SlackHours = roundHours(deadline.Sub(finish))
+	risk.RunHours = roundHours(run)
+	risk.QueueHours = roundHours(queue)
+	return risk
+}
+
+func roundHours(d time.Duration) float64 {
+	return math.Round(d.Hours()*100) / 100
+}
+
+// deadlineRemedies suggests spec changes that would lower a job's risk:
+// a budget that affords on-demand capacity, relaxed data locality for a job
+// still waiting for placement, and more GPUs for frameworks that scale
+func deadlineRemedies(job *models.Job, decision *models.AllocationDecision, onSpot bool, risk *models.DeadlineRisk) []models.DeadlineRemedy {
+	var remedies []models.DeadlineRemedy
+	pending := job.Status == models.JobStatusPending
+
+	// Budget: the cheapest strategy the budget ruled out, else on-demand
+	// instead of spot that can be interrupted
+	overBudget := 0.0
+	if pending && decision != nil {
+		for _, strategy := range decision.Strategies {
+			for _, rejection := range strategy.Rejections {
+				if rejection.Reason == models.RejectionOverBudget && (overBudget == 0 || rejection.Value < overBudget) {
+					overBudget = rejection.Value
+				}
+			}
+		}
+	}
+	switch {
+	case overBudget > 0:
+		remedies = append(remedies, models.DeadlineRemedy{
+			Action:  models.RemedyRaiseBudget,
+			Field:   "constraints.budget",
+			Value:   fmt.Sprintf("%.2f", math.Ceil(overBudget*100)/100),
+			Message: fmt.Sprintf("A budget of $%.2f affords an allocation the current budget of $%.2f rules out", overBudget, job.Constraints.MaxBudget),
+		})
+	case job.Constraints.AllowSpot && (pending || onSpot):
+		remedies = append(remedies, models.DeadlineRemedy{
+			Action:  models.RemedyRaiseBudget,
+			Field:   "constraints.allow_spot",
+			Value:   "false",
+			Message: "Raise the budget to run on on-demand instances, which are not interrupted or held back by spot capacity",
+		})
+	}
+
+	// Locality only constrains jobs still to be placed
+	if pending {
+		switch {
+		case job.Constraints.DataLocality == models.DataLocalityRequired:
+			remedies = append(remedies, models.DeadlineRemedy{
+				Action:  models.RemedyRelaxLocality,
+				Field:   "constraints.data_locality",
+				Value:   string(models.DataLocalityPrefer),
+				Message: "Prefer rather than require the dataset's region so the job can be placed where capacity is free",
+			})
+		case len(job.Constraints.PreferredRegions) > 0:
+			remedies = append(remedies, models.DeadlineRemedy{
+				Action:  models.RemedyRelaxLocality,
+				Field:   "constraints.preferred_regions",
+				Message: fmt.Sprintf("Allow regions besides %s so the job can be placed where capacity is free", strings.Join(job.Constraints.PreferredRegions, ", ")),
+			})
+		}
+	}
+
+	// GPUs that would finish the remaining work before the deadline
+	if gpus := deadlineGPUs(job, risk); gpus > 0 {
+		message := fmt.Sprintf("%s scales with GPUs; %d instead of %d GPUs are projected to finish before the deadline", job.Framework, gpus, job.Requirements.GPUs)
+		if !pending {
+			message += ", after a restart from the last checkpoint"
+		}
+		remedies = append(remedies, models.DeadlineRemedy{
+			Action:  models.RemedyIncreaseGPUs,
+			Field:   "resources.gpus",
+			Value:   fmt.Sprintf("%d", gpus),
+			Message: message,
+		})
+	}
+	return remedies
+}
+
+// deadlineGPUs returns how many GPUs a single-cluster job of a scalable
+// framework would need to finish its remaining work with margin before the
+// deadline, rounded up to whole nodes, or 0 when more GPUs would not help
+func deadlineGPUs(job *models.Job, risk *models.DeadlineRisk) int {
+	if !deadlineScalableFrameworks[job.Framework] || job.Requirements.ExecutionMode == models.ModeMultiTask || job.Requirements.GPUs <= 0 {
+		return 0
+	}
+	run := risk.RunHours
+	available := run + risk.SlackHours // Training time the deadline leaves
+	if run <= 0 || available <= 0 {
+		return 0
+	}
+	uncertainty := deadlineTimeUncertainty
+	if risk.Basis == models.DeadlineBasisSteps {
+		uncertainty = deadlineStepsUncertainty
+	}
+	factor := run * (1 + uncertainty) / available / deadlineScalingEfficiency
+	if factor <= 1 {
+		return 0
+	}
+	gpus := int(math.Ceil(float64(job.Requirements.GPUs) * factor))
+	if perNode := job.Requirements.MaxGPUsPerNode; perNode > 0 {
+		gpus = (gpus + perNode - 1) / perNode * perNode
+	}
+	return gpus
+}
+
+// decision returns a pending job's latest allocation decision, or nil
+func (e *DeadlineRiskEvaluator) decision(job *models.Job) *models.AllocationDecision {
+	if job.Status != models.JobStatusPending {
+		return nil
+	}
+	decision, err := e.jobRepo.GetAllocationDecision(job.ID)
+	if err != nil {
+		log.Printf("Failed to load allocation decision of job %s: %v", job.ID, err)
+		return nil
+	}
+	return decision
+}
+
+// onSpot reports whether any of a started job's live allocations is spot
+func (e *DeadlineRiskEvaluator) onSpot(job *models.Job) bool {
+	if job.Status == models.JobStatusPending || e.allocationRepo == nil {
+		return false
+	}
+	allocations, err := e.allocationRepo.GetAllocationsByJobID(job.ID)
+	if err != nil {
+		log.Printf("Failed to load allocations of job %s: %v", job.ID, err)
+		return false
+	}
+	for _, allocation := range allocations {
+		if allocation.Spot && allocation.Status != models.AllocationFailed && allocation.Status != models.AllocationTerminated {
+			return true
+		}
+	}
+	return false
+}
+
+// warn records the escalation event, then notifies. The event is written
+// first so a failed delivery is not retried every tick.
+func (e *DeadlineRiskEvaluator) warn(ctx context.Context, job *models.Job, risk *models.DeadlineRisk) {
+	remedies := make([]string, 0, len(risk.Remedies))
+	for _, remedy := range risk.Remedies {
+		remedies = append(remedies, remedy.Action)
+	}
+	meta := map[string]interface{}{
+		"risk":             risk.Risk,
+		"level":            risk.Level,
+		"deadline":         risk.Deadline,
+		"projected_finish": risk.ProjectedFinish,
+		"slack_hours":      risk.SlackHours,
+		"basis":            risk.Basis,
+		"remedies":         remedies,
+	}
+	if err := e.jobRepo.CreateJobEvent(job.ID, &job.Status, job.Status, "deadline_risk", meta); err != nil {
+		log.Printf("Failed to record deadline risk of job %s: %v", job.ID, err)
+		return
+	}
+	log.Printf("Job %s is %s to miss its deadline (risk %.2f, projected finish %s)", job.ID, risk.Level, risk.Risk, risk.ProjectedFinish.Format(time.RFC3339))
+
+	if e.notifier == nil {
+		return
+	}
+	message := fmt.Sprintf("Job %s (%s) is projected to finish at %s, %s its deadline of %s (risk %.0f%%, %s).",
+		job.Name, job.ID, risk.ProjectedFinish.Format(time.RFC3339), slackPhrase(risk.SlackHours),
+		risk.Deadline.Format(time.RFC3339), risk.Risk*100, risk.Level)
+	for _, remedy := range risk.Remedies {
+		message += " " + remedy.Message + "."
+	}
+	notificationMeta := map[string]interface{}{"job_id": job.ID, "user_id": job.UserID}
+	for key, value := range meta {
+		notificationMeta[key] = value
+	}
+	err := e.notifier.Notify(ctx, monitoring.Notification{
+		Subject: "Job " + job.Name + " may miss its deadline",
+		Message: message,
+		Source:  "deadline_risk",
+		Meta:    notificationMeta,
+		SentAt:  e.now(),
+	})
+	if err != nil {
+		log.Printf("Failed to notify about deadline risk of job %s: %v", job.ID, err)
+	}
+}
+
+// slackPhrase describes slack relative to the deadline
+func slackPhrase(slackHours float64) string {
+	if slackHours < 0 {
+		return fmt.Sprintf("%.1fh after", -slackHours)
+	}
+	return fmt.Sprintf("%.1fh before", slackHours)
+}
@@ -0,0 +1,298 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"gpu-orchestrator/core/clock"
+	"gpu-orchestrator/core/executor"
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/monitoring"
+	"gpu-orchestrator/core/optimizer"
+	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/core/supervisor"
+)
+
+// egressSampleTimeout bounds reading the counter of one node
+const egressSampleTimeout = 30 * time.Second
+
+// bytesPerGB converts counters to the GB transfer is priced in
+const bytesPerGB = 1 << 30
+
+// egressScript prints the bytes a node sent out. It installs an iptables
+// chain counting packets to addresses outside private networks (sent by the
+// node or forwarded from its containers) on first use, and falls back to the
+// bytes sent on the node's physical interfaces without passwordless sudo.
+// The fallback also counts traffic between the cluster's nodes.
+const egressScript = `ipt="sudo -n iptables -w"
+if ! $ipt -nL GPU_EGRESS >/dev/null 2>&1 && $ipt -N GPU_EGRESS 2>/dev/null; then
+	for net in 10.0.0.0/8 172.16.0.0/12 192.168.0.0/16 100.64.0.0/10 127.0.0.0/8 169.254.0.0/16; do
+		$ipt -A GPU_EGRESS -d "$net" -j RETURN
+	done
+	$ipt -A GPU_EGRESS
+	$ipt -I OUTPUT -j GPU_EGRESS
+	$ipt -I FORWARD -j GPU_EGRESS
+fi
+if $ipt -nL GPU_EGRESS >/dev/null 2>&1; then
+	$ipt -nvxL GPU_EGRESS | awk '$3 == "all" { print "EGRESS_BYTES", $2 }'
+else
+	sed 1,2d /proc/net/dev | tr ':' ' ' | awk '$1 !~ /^(lo|docker|veth|br-|cni|flannel|cali|virbr|tailscale|wg)/ { tx += $10 } END { print "NETDEV_TX_BYTES", tx + 0 }'
+fi`
+
+// EgressPolicy sets when a job's egress cost is alerted on. The alert fires
+// once per job, usually for a dataset or checkpoint bucket in the wrong place.
+type EgressPolicy struct {
+	MaxUSD   float64 // Egress cost that alerts; 0 = no absolute threshold
+	MaxRatio float64 // Egress cost over compute cost that alerts; 0 = no proportional threshold
+}
+
+// EgressTracker samples the egress counters of running jobs' nodes, charges
+// the bytes sent to each job as internet transfer from the node's region and
+// alerts when a job's egress cost crosses the policy's thresholds
+type EgressTracker struct {
+	jobRepo    *repository.JobRepository
+	egressRepo *repository.EgressRepository
+	runner     executor.NodeRunner
+	costs      *optimizer.CostCalculator
+	scheduler  *Scheduler // Tracks the clusters of running jobs; nil samples nothing
+	policy     EgressPolicy
+	notifier   monitoring.Notifier // Optional; nil records events only
+	now        func() time.Time
+}
+
+// NewEgressTracker creates a new egress tracker
+func NewEgressTracker(
+	jobRepo *repository.JobRepository,
+	egressRepo *repository.EgressRepository,
+	runner executor.NodeRunner,
+	costs *optimizer.CostCalculator,
+	policy EgressPolicy,
+) *EgressTracker {
+	return &EgressTracker{
+		jobRepo:    jobRepo,
+		egressRepo: egressRepo,
+		runner:     runner,
+		costs:      costs,
+		policy:     policy,
+		now:        clock.System.Now,
+	}
+}
+
+// SetScheduler sets the scheduler whose running clusters are sampled
+func (et *EgressTracker) SetScheduler(s *Scheduler) {
+	et.scheduler = s
+}
+
+// SetNotifier sets where egress alerts are delivered
+func (et *EgressTracker) SetNotifier(notifier monitoring.Notifier) {
+	et.notifier = notifier
+}
+
+// Start samples running jobs every interval until ctx is done
+func (et *EgressTracker) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			supervisor.Heartbeat(ctx)
+			et.Check(ctx)
+		}
+	}
+}
+
+// Check samples the nodes of every running job once
+func (et *EgressTracker) Check(ctx context.Context) {
+	if et.scheduler == nil {
+		return
+	}
+	for jobID, cluster := range et.scheduler.RunningClusters() {
+		if ctx.Err() != nil {
+			return
+		}
+		if cluster.Backend != models.BackendVM {
+			continue
+		}
+		job, err := et.jobRepo.GetJob(jobID)
+		if err != nil {
+			log.Printf("Failed to load job %s for egress sampling: %v", jobID, err)
+			continue
+		}
+		if job.Status != models.JobStatusRunning {
+			continue
+		}
+		if err := et.sample(ctx, job, cluster); err != nil {
+			log.Printf("Egress sampling of job %s failed: %v", job.ID, err)
+			continue
+		}
+		et.checkThresholds(ctx, job.ID)
+	}
+}
+
+// egressRegion is where egress is charged from
+type egressRegion struct {
+	provider models.Provider
+	region   string
+}
+
+// sample reads the counter of each node of a cluster and charges what the
+// nodes sent since the previous sample to the job
+func (et *EgressTracker) sample(ctx context.Context, job *models.Job, cluster *models.Cluster) error {
+	charged, err := et.egressRepo.JobEgress(job.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load egress: %w", err)
+	}
+	totals := make(map[egressRegion]int64, len(charged))
+	for _, usage := range charged {
+		totals[egressRegion{usage.Provider, usage.Region}] += usage.Bytes
+	}
+
+	day := et.now().UTC().Truncate(24 * time.Hour)
+	for _, node := range cluster.Nodes {
+		counter, source, err := et.readCounter(ctx, node)
+		if err != nil {
+			log.Printf("Failed to read egress counter of node %s of job %s: %v", node.ID, job.ID, err)
+			continue
+		}
+		sent, err := et.egressRepo.RecordCounter(job.ID, node.ID, counter, source)
+		if err != nil {
+			return fmt.Errorf("failed to record egress counter of node %s: %w", node.ID, err)
+		}
+		if sent <= 0 {
+			continue
+		}
+
+		region := egressRegion{node.Provider, node.Region}
+		usage := models.EgressUsage{
+			JobID:    job.ID,
+			Day:      day,
+			Provider: node.Provider,
+			Region:   node.Region,
+			Bytes:    sent,
+			CostUSD:  et.egressCost(region, totals[region], sent),
+		}
+		if err := et.egressRepo.AddEgress(usage); err != nil {
+			return fmt.Errorf("failed to charge egress of node %s: %w", node.ID, err)
+		}
+		totals[region] += sent
+	}
+	return nil
+}
+
+// egressCost prices bytes sent from a region on top of what the job already
+// sent from it, so tiered rates apply to the job's running total
+func (et *EgressTracker) egressCost(region egressRegion, before, sent int64) float64 {
+	price := func(bytes int64) float64 {
+		return et.costs.CalculateEgressCost(float64(bytes)/bytesPerGB, region.provider, region.region)
+	}
+	return price(before+sent) - price(before)
+}
+
+// readCounter runs the counter script on a node
+func (et *EgressTracker) readCounter(ctx context.Context, node models.Node) (int64, string, error) {
+	nodeCtx, cancel := context.WithTimeout(ctx, egressSampleTimeout)
+	defer cancel()
+
+	var lines []string
+	if err := et.runner.Run(nodeCtx, node, egressScript, func(line string) {
+		lines = append(lines, line)
+	}); err != nil {
+		return 0, "", err
+	}
+	return parseEgressCounter(lines)
+}
+
+// parseEgressCounter reads the counter and its source from the output of
+// egressScript
+func parseEgressCounter(lines []string) (int64, string, error) {
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		var source string
+		switch fields[0] {
+		case "EGRESS_BYTES":
+			source = models.EgressSourceIPTables
+		case "NETDEV_TX_BYTES":
+			source = models.EgressSourceNetdev
+		default:
+			continue
+		}
+		counter, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil || counter < 0 {
+			return 0, "", fmt.Errorf("invalid egress counter %q", fields[1])
+		}
+		return counter, source, nil
+	}
+	return 0, "", fmt.Errorf("no egress counter in output")
+}
+
+// checkThresholds alerts once when a job's egress cost crosses a threshold
+func (et *EgressTracker) checkThresholds(ctx context.Context, jobID string) {
+	job, err := et.jobRepo.GetJob(jobID)
+	if err != nil {
+		log.Printf("Failed to load job %s for egress alert: %v", jobID, err)
+		return
+	}
+	threshold, exceeded := et.policy.exceeded(job.CostEgressUSD, job.CostRunningUSD)
+	if !exceeded {
+		return
+	}
+	first, err := et.egressRepo.MarkEgressAlerted(job.ID)
+	if err != nil {
+		log.Printf("Failed to record egress alert of job %s: %v", job.ID, err)
+		return
+	}
+	if !first {
+		return
+	}
+
+	meta := map[string]interface{}{
+		"egress_usd":  job.CostEgressUSD,
+		"egress_gb":   float64(job.EgressBytes) / bytesPerGB,
+		"compute_usd": job.CostRunningUSD,
+		"threshold":   threshold,
+	}
+	running := models.JobStatusRunning
+	if err := et.jobRepo.CreateJobEvent(job.ID, &running, running, "egress_cost_exceeded", meta); err != nil {
+		log.Printf("Failed to record egress alert event for job %s: %v", job.ID, err)
+	}
+	log.Printf("Job %s: egress cost $%.2f exceeds %s", job.ID, job.CostEgressUSD, threshold)
+
+	if et.notifier == nil {
+		return
+	}
+	meta["job_id"] = job.ID
+	meta["user_id"] = job.UserID
+	err = et.notifier.Notify(ctx, monitoring.Notification{
+		Subject: fmt.Sprintf("Job %s is paying for network egress", job.Name),
+		Message: fmt.Sprintf("Job %s (%s) sent %.1f GB out of its provider's network, an estimated $%.2f of egress against $%.2f of compute (%s). "+
+			"This usually means its dataset or checkpoint bucket is in another region or cloud.",
+			job.Name, job.ID, float64(job.EgressBytes)/bytesPerGB, job.CostEgressUSD, job.CostRunningUSD, threshold),
+		Source: "egress_tracker",
+		Meta:   meta,
+		SentAt: et.now(),
+	})
+	if err != nil {
+		log.Printf("Failed to notify about egress of job %s: %v", job.ID, err)
+	}
+}
+
+// exceeded reports whether an egress cost crosses a threshold, and which
+func (p EgressPolicy) exceeded(egressUSD, computeUSD float64) (string, bool) {
+	if p.MaxUSD > 0 && egressUSD > p.MaxUSD {
+		return fmt.Sprintf("the $%.2f egress threshold", p.MaxUSD), true
+	}
+	if p.MaxRatio > 0 && computeUSD > 0 && egressUSD > p.MaxRatio*computeUSD {
+		return fmt.Sprintf("%.0f%% of compute cost", p.MaxRatio*100), true
+	}
+	return "", false
+}
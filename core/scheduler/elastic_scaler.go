@@ -0,0 +1,335 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"gpu-orchestrator/core/clock"
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/optimizer"
+	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/core/resource_manager"
+	"gpu-orchestrator/core/supervisor"
+)
+
+// ElasticScaler resizes running horovod_elastic jobs: it adds spot nodes when they
+// are cheap and the budget allows, and removes nodes when the job is projected
+// to overrun its budget
+type ElasticScaler struct {
+	manager       *resource_manager.ElasticManager
+	jobRepo       *repository.JobRepository
+	pricing       *optimizer.PricingFetcher
+	spotDiscount  float64       // Minimum spot discount vs on-demand to scale up (0.4 = 40% cheaper)
+	cooldown      time.Duration // Minimum time between resizes of the same job
+	interval      time.Duration
+	interruptions *repository.InterruptionRepository // Spot reclaims; see SetInterruptionRepository
+}
+
+// NewElasticScaler creates a new elastic scaler
+func NewElasticScaler(
+	manager *resource_manager.ElasticManager,
+	jobRepo *repository.JobRepository,
+	pricing *optimizer.PricingFetcher,
+) *ElasticScaler {
+	return &ElasticScaler{
+		manager:      manager,
+		jobRepo:      jobRepo,
+		pricing:      pricing,
+		spotDiscount: 0.4,
+		cooldown:     5 * time.Minute,
+		interval:     time.Minute,
+	}
+}
+
+// SetInterval sets how often elastic clusters are evaluated (default 1 minute)
+func (es *ElasticScaler) SetInterval(interval time.Duration) {
+	es.interval = interval
+}
+
+// SetInterruptionRepository sets where spot reclaims are recorded for the
+// interruption rates of the reliability model
+func (es *ElasticScaler) SetInterruptionRepository(repo *repository.InterruptionRepository) {
+	es.interruptions = repo
+}
+
+// Start starts the elastic scaler background worker
+func (es *ElasticScaler) Start(ctx context.Context) {
+	ticker := time.NewTicker(es.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			supervisor.Heartbeat(ctx)
+			es.CheckAndScale(ctx)
+		}
+	}
+}
+
+// CheckAndScale evaluates every managed elastic cluster once
+func (es *ElasticScaler) CheckAndScale(ctx context.Context) {
+	for _, ec := range es.manager.List() {
+		if err := es.evaluate(ctx, ec); err != nil {
+			log.Printf("Elastic scaling failed for job %s: %v", ec.Job.ID, err)
+		}
+	}
+}
+
+// evaluate applies the budget and spot-price policy to one job
+func (es *ElasticScaler) evaluate(ctx context.Context, ec resource_manager.ElasticCluster) error {
+	job, err := es.jobRepo.GetJob(ec.Job.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch job: %w", err)
+	}
+	if job.Status != models.JobStatusRunning {
+		es.manager.Unregister(job.ID)
+		return nil
+	}
+	if !ec.LastResizeAt.IsZero() && time.Since(ec.LastResizeAt) < es.cooldown {
+		return nil
+	}
+
+	remainingHours := job.Requirements.EstimatedHours - time.Since(ec.RegisteredAt).Hours()
+	if remainingHours <= 0 {
+		return nil
+	}
+	projected := job.CostRunningUSD + hourlyRate(ec.Allocations)*remainingHours
+
+	// Budget pressure: shed nodes (spot first) until the projection fits
+	if job.Constraints.MaxBudget > 0 && projected > job.Constraints.MaxBudget {
+		return es.scaleDownForBudget(ctx, job, ec, projected-job.Constraints.MaxBudget, remainingHours)
+	}
+
+	return es.scaleUpOnCheapSpot(ctx, job, ec, projected, remainingHours)
+}
+
+// scaleDownForBudget removes enough nodes to save excess over the remaining hours
+func (es *ElasticScaler) scaleDownForBudget(
+	ctx context.Context,
+	job *models.Job,
+	ec resource_manager.ElasticCluster,
+	excess float64,
+	remainingHours float64,
+) error {
+	removable := len(ec.Cluster.Nodes) - job.Requirements.Elastic.MinNodes
+	var nodeIDs []string
+	saved := 0.0
+	for _, node := range scaleDownOrder(ec.Cluster.Nodes) {
+		if len(nodeIDs) >= removable || saved >= excess {
+			break
+		}
+		nodeIDs = append(nodeIDs, node.ID)
+		saved += nodePrice(ec.Allocations, node) * remainingHours
+	}
+	if len(nodeIDs) == 0 {
+		return nil
+	}
+
+	removed, err := es.manager.ScaleDown(ctx, job.ID, nodeIDs)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Elastic job %s scaled down by %d nodes (budget pressure)", job.ID, len(removed))
+	es.updateTopology(job.ID)
+	es.recordResize(job.ID, "elastic_scale_down", map[string]interface{}{
+		"trigger":       "budget_pressure",
+		"removed_nodes": nodeIDs,
+		"nodes":         len(ec.Cluster.Nodes) - len(removed),
+	})
+	return nil
+}
+
+// scaleUpOnCheapSpot adds one spot node when spot is well below on-demand and the budget has room
+func (es *ElasticScaler) scaleUpOnCheapSpot(
+	ctx context.Context,
+	job *models.Job,
+	ec resource_manager.ElasticCluster,
+	projected float64,
+	remainingHours float64,
+) error {
+	nodes := ec.Cluster.Nodes
+	if !job.Constraints.AllowSpot || len(nodes) == 0 || len(nodes) >= job.Requirements.Elastic.MaxNodes {
+		return nil
+	}
+
+	// Respect the spot mixture limit after adding the node
+	spotNodes := 0
+	for _, node := range nodes {
+		if node.Spot {
+			spotNodes++
+		}
+	}
+	if float64(spotNodes+1) > job.Constraints.MaxSpotFraction*float64(len(nodes)+1) {
+		return nil
+	}
+
+	// New nodes match the existing instance type so the cluster stays homogeneous
+	template := nodes[0]
+	spotPrice, err := es.pricing.GetPrice(template.Provider, template.InstanceType, template.Region, true)
+	if err != nil {
+		return nil // No spot market for this instance type
+	}
+	onDemandPrice, err := es.pricing.GetPrice(template.Provider, template.InstanceType, template.Region, false)
+	if err != nil || onDemandPrice <= 0 {
+		return nil
+	}
+	if spotPrice > onDemandPrice*(1-es.spotDiscount) {
+		return nil
+	}
+	alloc := models.Allocation{
+		Provider:      template.Provider,
+		InstanceType:  template.InstanceType,
+		Region:        template.Region,
+		Count:         1,
+		Spot:          true,
+		PricePerHour:  spotPrice,
+		EstimatedTime: time.Duration(remainingHours * float64(time.Hour)),
+	}
+	optimizer.ApplyDataVolume(&alloc, job.Requirements)
+	alloc.EstimatedCost = alloc.ExpectedCost()
+	if job.Constraints.MaxBudget > 0 && projected+alloc.EstimatedCost > job.Constraints.MaxBudget {
+		return nil
+	}
+
+	added, err := es.manager.ScaleUp(ctx, job.ID, alloc)
+	if err != nil {
+		return err
+	}
+
+	addedIDs := make([]string, len(added))
+	for i, node := range added {
+		addedIDs[i] = node.ID
+	}
+	log.Printf("Elastic job %s scaled up by %d spot nodes at $%.2f/hr", job.ID, len(added), spotPrice)
+	es.updateTopology(job.ID)
+	es.recordResize(job.ID, "elastic_scale_up", map[string]interface{}{
+		"trigger":        "cheap_spot",
+		"added_nodes":    addedIDs,
+		"spot_price":     spotPrice,
+		"ondemand_price": onDemandPrice,
+		"nodes":          len(nodes) + len(added),
+	})
+	return nil
+}
+
+// HandleSpotReclaim removes a reclaimed spot node from an elastic job.
+// The job fails if fewer than min_nodes remain.
+func (es *ElasticScaler) HandleSpotReclaim(ctx context.Context, jobID, instanceID string) error {
+	node, remaining, err := es.manager.NodeLost(jobID, instanceID)
+	if err != nil {
+		return err
+	}
+	ec, ok := es.manager.Get(jobID)
+	if ok {
+		es.recordInterruption(ec.Job, node)
+		es.updateTopology(jobID)
+	}
+
+	es.recordResize(jobID, "elastic_node_lost", map[string]interface{}{
+		"trigger": "spot_reclaim",
+		"node":    node.ID,
+		"nodes":   remaining,
+	})
+
+	if !ok || remaining >= ec.Job.Requirements.Elastic.MinNodes {
+		return nil
+	}
+
+	es.manager.Unregister(jobID)
+	return es.jobRepo.UpdateJobStatus(jobID, models.JobStatusRunning, models.JobStatusFailed, "elastic_below_min_nodes", map[string]interface{}{
+		"nodes":     remaining,
+		"min_nodes": ec.Job.Requirements.Elastic.MinNodes,
+	})
+}
+
+// recordInterruption stores a reclaimed spot node with the job's runtime
+func (es *ElasticScaler) recordInterruption(job *models.Job, node *models.Node) {
+	if es.interruptions == nil || !node.Spot {
+		return
+	}
+	interruption := &models.SpotInterruption{
+		JobID:         job.ID,
+		Provider:      node.Provider,
+		InstanceType:  node.InstanceType,
+		Region:        node.Region,
+		InstanceID:    node.InstanceID,
+		InterruptedAt: clock.System.Now(),
+	}
+	if job.StartedAt != nil {
+		interruption.RuntimeSeconds = int64(interruption.InterruptedAt.Sub(*job.StartedAt).Seconds())
+	}
+	if err := es.interruptions.RecordInterruption(interruption); err != nil {
+		log.Printf("Failed to record spot interruption of %s for job %s: %v", node.InstanceID, job.ID, err)
+	}
+}
+
+// updateTopology refreshes the job's topology manifest after a resize. Ranks
+// follow cluster order as Horovod elastic re-ranks workers; the master port
+// and network profile of the launch are kept.
+func (es *ElasticScaler) updateTopology(jobID string) {
+	ec, ok := es.manager.Get(jobID)
+	if !ok {
+		return
+	}
+	manifest, err := es.jobRepo.GetTopology(jobID)
+	if err != nil || manifest == nil {
+		log.Printf("No topology to update for elastic job %s: %v", jobID, err)
+		return
+	}
+
+	tc := models.NewTopologyCluster(ec.Cluster)
+	if previous, ok := manifest.Cluster(ec.Cluster.ID); ok {
+		tc.MasterPort = previous.MasterPort
+		tc.NetworkProfile = previous.NetworkProfile
+	}
+	manifest.Upsert(tc)
+	if err := es.jobRepo.SetTopology(jobID, manifest); err != nil {
+		log.Printf("Failed to update topology of elastic job %s: %v", jobID, err)
+	}
+}
+
+// recordResize logs a running -> running job event for a cluster resize
+func (es *ElasticScaler) recordResize(jobID, reason string, meta map[string]interface{}) {
+	running := models.JobStatusRunning
+	if err := es.jobRepo.CreateJobEvent(jobID, &running, running, reason, meta); err != nil {
+		log.Printf("Failed to record %s event for job %s: %v", reason, jobID, err)
+	}
+}
+
+// scaleDownOrder returns removal candidates: newest spot nodes first, then newest
+// on-demand nodes. The first node (rank 0 coordinator) is never a candidate.
+func scaleDownOrder(nodes []models.Node) []models.Node {
+	var spot, onDemand []models.Node
+	for i := len(nodes) - 1; i >= 1; i-- {
+		if nodes[i].Spot {
+			spot = append(spot, nodes[i])
+		} else {
+			onDemand = append(onDemand, nodes[i])
+		}
+	}
+	return append(spot, onDemand...)
+}
+
+// hourlyRate returns the total hourly price of the allocations
+func hourlyRate(allocations []models.Allocation) float64 {
+	rate := 0.0
+	for _, alloc := range allocations {
+		rate += alloc.HourlyPrice() * float64(alloc.Count)
+	}
+	return rate
+}
+
+// nodePrice returns the hourly price of the allocation row a node belongs to
+func nodePrice(allocations []models.Allocation, node models.Node) float64 {
+	for _, alloc := range allocations {
+		if alloc.InstanceType == node.InstanceType && alloc.Spot == node.Spot {
+			return alloc.PricePerHour
+		}
+	}
+	return 0
+}
@@ -0,0 +1,166 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"gpu-orchestrator/core/clock"
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/core/supervisor"
+)
+
+// fairSharePriorityHours is how many hours of deadline slack one unit of
+// fair-share adjustment is worth in the computed priority
+const fairSharePriorityHours = 24.0
+
+// UsageSource streams the allocations that ran during a period
+type UsageSource interface {
+	StreamBillableAllocations(start, end time.Time, fn func(repository.BillableAllocation) error) error
+}
+
+// FairShare tracks rolling GPU-hour usage per team against configured share
+// weights. Teams under their share get their jobs moved ahead in the queue,
+// teams over it get them moved back. The adjustment is capped, and jobs
+// pending longer than maxDelay are never moved back.
+type FairShare struct {
+	usage         UsageSource
+	weights       map[string]float64 // Team -> share weight
+	window        time.Duration
+	maxAdjustment float64
+	maxDelay      time.Duration
+	now           func() time.Time
+
+	mu          sync.RWMutex
+	teams       map[string]models.TeamShare
+	refreshedAt time.Time
+}
+
+// NewFairShare creates a fair-share tracker over the last window of usage.
+// Until the first refresh every adjustment is 0.
+func NewFairShare(usage UsageSource, weights map[string]float64, window time.Duration, maxAdjustment float64, maxDelay time.Duration) *FairShare {
+	return &FairShare{
+		usage:         usage,
+		weights:       weights,
+		window:        window,
+		maxAdjustment: maxAdjustment,
+		maxDelay:      maxDelay,
+		now:           clock.System.Now,
+		teams:         make(map[string]models.TeamShare),
+	}
+}
+
+// Start refreshes usage every interval until ctx is done
+func (fs *FairShare) Start(ctx context.Context, interval time.Duration) {
+	if err := fs.Refresh(); err != nil {
+		log.Printf("Fair-share refresh failed: %v", err)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			supervisor.Heartbeat(ctx)
+			if err := fs.Refresh(); err != nil {
+				log.Printf("Fair-share refresh failed: %v", err)
+			}
+		}
+	}
+}
+
+// Refresh recomputes every team's usage over the window
+func (fs *FairShare) Refresh() error {
+	end := fs.now()
+	gpuHours := make(map[string]float64)
+	err := fs.usage.StreamBillableAllocations(end.Add(-fs.window), end, func(alloc repository.BillableAllocation) error {
+		if _, ok := fs.weights[alloc.TeamID]; ok && alloc.To.After(alloc.From) {
+			gpuHours[alloc.TeamID] += float64(alloc.Count*alloc.GPUsPerInstance) * alloc.To.Sub(alloc.From).Hours()
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read usage: %w", err)
+	}
+
+	fs.mu.Lock()
+	fs.teams = computeShares(fs.weights, gpuHours, fs.maxAdjustment)
+	fs.refreshedAt = end
+	fs.mu.Unlock()
+	return nil
+}
+
+// Adjustment returns how far the job is moved in the queue: negative ahead,
+// positive back, 0 for teams without a share
+func (fs *FairShare) Adjustment(job *models.Job) float64 {
+	fs.mu.RLock()
+	adjustment := fs.teams[job.TeamID].Adjustment
+	fs.mu.RUnlock()
+
+	if adjustment > 0 && fs.maxDelay > 0 && !job.CreatedAt.IsZero() && fs.now().Sub(job.CreatedAt) > fs.maxDelay {
+		return 0
+	}
+	return adjustment
+}
+
+// Report returns the current shares and usage, ordered by team
+func (fs *FairShare) Report() models.FairShareReport {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	report := models.FairShareReport{
+		WindowHours:     fs.window.Hours(),
+		MaxAdjustment:   fs.maxAdjustment,
+		MaxDelayMinutes: fs.maxDelay.Minutes(),
+		RefreshedAt:     fs.refreshedAt,
+		Teams:           make([]models.TeamShare, 0, len(fs.weights)),
+	}
+	for team, weight := range fs.weights {
+		share, ok := fs.teams[team]
+		if !ok {
+			share = models.TeamShare{TeamID: team, Weight: weight}
+		}
+		report.Teams = append(report.Teams, share)
+	}
+	sort.Slice(report.Teams, func(i, j int) bool { return report.Teams[i].TeamID < report.Teams[j].TeamID })
+	return report
+}
+
+// computeShares compares each team's fraction of the GPU-hours used by teams
+// with a share against its fraction of the weights. Without any usage every
+// adjustment is 0, so the queue order is unchanged.
+func computeShares(weights, gpuHours map[string]float64, maxAdjustment float64) map[string]models.TeamShare {
+	var totalWeight, totalHours float64
+	for team, weight := range weights {
+		totalWeight += weight
+		totalHours += gpuHours[team]
+	}
+
+	teams := make(map[string]models.TeamShare, len(weights))
+	for team, weight := range weights {
+		share := models.TeamShare{
+			TeamID:   team,
+			Weight:   weight,
+			Share:    weight / totalWeight,
+			GPUHours: gpuHours[team],
+		}
+		if totalHours > 0 {
+			share.Usage = share.GPUHours / totalHours
+			share.Ratio = share.Usage / share.Share
+			share.Adjustment = -maxAdjustment
+			if share.Ratio > 0 {
+				share.Adjustment = math.Max(-maxAdjustment, math.Min(maxAdjustment, math.Log2(share.Ratio)))
+			}
+		}
+		teams[team] = share
+	}
+	return teams
+}
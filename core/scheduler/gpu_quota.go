@@ -0,0 +1,393 @@
+package scheduler
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"gpu-orchestrator/core/clock"
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/repository"
+)
+
+// GPUQuotas enforces monthly GPU-hour quotas of teams and projects. A month's
+// consumption is the GPUs of every allocation that ran times how long it ran,
+// scaled by the job's share of shared GPUs. Jobs reserve their estimated
+// GPU-hours when they are scheduled; a reservation holds only what the job
+// has not consumed yet, and only while it is scheduled, running or suspended.
+type GPUQuotas struct {
+	repo   *repository.GPUQuotaRepository
+	usage  UsageSource
+	maxAge time.Duration // Consumption computed longer ago than this is recomputed
+	now    func() time.Time
+
+	mu       sync.Mutex
+	snapshot *quotaSnapshot
+}
+
+// quotaSnapshot is the current month of every quota
+type quotaSnapshot struct {
+	start    time.Time
+	at       time.Time
+	quotas   []models.GPUQuota
+	carried  map[int64]float64  // Quota -> hours carried into the month
+	adjusted map[int64]float64  // Quota -> hours added by admins
+	consumed map[int64]float64  // Quota -> GPU-hours consumed
+	cost     map[int64]float64  // Quota -> compute cost of the consumed hours
+	jobHours map[string]float64 // Job -> GPU-hours consumed this month
+}
+
+// NewGPUQuotas creates the quota enforcer. Consumption is recomputed once it
+// is older than maxAge.
+func NewGPUQuotas(repo *repository.GPUQuotaRepository, usage UsageSource, maxAge time.Duration) *GPUQuotas {
+	return &GPUQuotas{
+		repo:   repo,
+		usage:  usage,
+		maxAge: maxAge,
+		now:    clock.System.Now,
+	}
+}
+
+// Invalidate makes the next check recompute consumption, e.g. after a quota
+// was changed
+func (gq *GPUQuotas) Invalidate() {
+	gq.mu.Lock()
+	gq.snapshot = nil
+	gq.mu.Unlock()
+}
+
+// Reserve holds the job's estimated GPU-hours against every quota of its team
+// and project. It returns false with the reason when a quota cannot cover
+// them; nothing is reserved then.
+func (gq *GPUQuotas) Reserve(job *models.Job) (models.WaitReason, bool, error) {
+	gq.mu.Lock()
+	defer gq.mu.Unlock()
+
+	snapshot, err := gq.current()
+	if err != nil {
+		return models.WaitReason{}, false, err
+	}
+	var quotas []models.GPUQuota
+	for _, quota := range snapshot.quotas {
+		if quotaApplies(quota, job) {
+			quotas = append(quotas, quota)
+		}
+	}
+	if len(quotas) == 0 {
+		return models.WaitReason{}, true, nil
+	}
+
+	active, err := gq.repo.ActiveReservations(snapshot.start)
+	if err != nil {
+		return models.WaitReason{}, false, fmt.Errorf("failed to load reservations: %w", err)
+	}
+	estimate := EstimatedGPUHours(job)
+	reservations := make([]models.GPUHourReservation, 0, len(quotas))
+	for _, quota := range quotas {
+		usage := snapshot.usage(quota, active, job.ID)
+		if usage.Exhausted || estimate > usage.RemainingHours {
+			return gpuHourQuotaReason(usage, estimate), false, nil
+		}
+		reservations = append(reservations, models.GPUHourReservation{
+			JobID:       job.ID,
+			QuotaID:     quota.ID,
+			PeriodStart: snapshot.start,
+			Hours:       estimate,
+		})
+	}
+	if err := gq.repo.Reserve(reservations); err != nil {
+		return models.WaitReason{}, false, fmt.Errorf("failed to reserve GPU-hours: %w", err)
+	}
+	return models.WaitReason{}, true, nil
+}
+
+// Release drops the job's reservations, e.g. when it is suspended to free
+// its GPU-hours for other jobs
+func (gq *GPUQuotas) Release(jobID string) error {
+	gq.mu.Lock()
+	defer gq.mu.Unlock()
+	return gq.repo.ReleaseReservations(jobID)
+}
+
+// Report returns the current month of every quota
+func (gq *GPUQuotas) Report() ([]models.GPUQuotaUsage, error) {
+	gq.mu.Lock()
+	defer gq.mu.Unlock()
+
+	snapshot, err := gq.current()
+	if err != nil {
+		return nil, err
+	}
+	active, err := gq.repo.ActiveReservations(snapshot.start)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load reservations: %w", err)
+	}
+	report := make([]models.GPUQuotaUsage, 0, len(snapshot.quotas))
+	for _, quota := range snapshot.quotas {
+		report = append(report, snapshot.usage(quota, active, ""))
+	}
+	return report, nil
+}
+
+// Adjust adds hours (negative removes them) to the current month of a quota
+func (gq *GPUQuotas) Adjust(quota *models.GPUQuota, hours float64, reason, actor string) (*models.GPUQuotaAdjustment, error) {
+	gq.mu.Lock()
+	defer gq.mu.Unlock()
+
+	adjustment := &models.GPUQuotaAdjustment{
+		QuotaID:     quota.ID,
+		PeriodStart: monthStart(gq.now()),
+		Hours:       hours,
+		Reason:      reason,
+		Actor:       actor,
+	}
+	if err := gq.repo.AddAdjustment(adjustment); err != nil {
+		return nil, err
+	}
+	gq.snapshot = nil
+	return adjustment, nil
+}
+
+// current returns the snapshot of the current month, recomputing it when it
+// is stale or the month rolled over. Callers hold mu.
+func (gq *GPUQuotas) current() (*quotaSnapshot, error) {
+	now := gq.now()
+	start := monthStart(now)
+	if gq.snapshot != nil && gq.snapshot.start.Equal(start) && now.Sub(gq.snapshot.at) < gq.maxAge {
+		return gq.snapshot, nil
+	}
+
+	quotas, err := gq.repo.ListQuotas()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load GPU-hour quotas: %w", err)
+	}
+	snapshot := &quotaSnapshot{
+		start:    start,
+		at:       now,
+		quotas:   quotas,
+		carried:  make(map[int64]float64),
+		adjusted: make(map[int64]float64),
+		consumed: make(map[int64]float64),
+		cost:     make(map[int64]float64),
+		jobHours: make(map[string]float64),
+	}
+	if len(quotas) > 0 {
+		if err := gq.rollover(snapshot); err != nil {
+			return nil, err
+		}
+		if err := gq.consume(quotas, start, now, snapshot.consumed, snapshot.cost, snapshot.jobHours); err != nil {
+			return nil, err
+		}
+	}
+	gq.snapshot = snapshot
+	return snapshot, nil
+}
+
+// rollover closes the months before the snapshot's with what they consumed
+// and opens the snapshot's month of every quota, carrying unused hours over
+// where the quota's policy says so
+func (gq *GPUQuotas) rollover(snapshot *quotaSnapshot) error {
+	unclosed, err := gq.repo.UnclosedPeriods(snapshot.start)
+	if err != nil {
+		return fmt.Errorf("failed to load unclosed months: %w", err)
+	}
+	for i := 0; i < len(unclosed); {
+		start := unclosed[i].Start
+		consumed := make(map[int64]float64)
+		if err := gq.consume(snapshot.quotas, start, start.AddDate(0, 1, 0), consumed, nil, nil); err != nil {
+			return err
+		}
+		for ; i < len(unclosed) && unclosed[i].Start.Equal(start); i++ {
+			if err := gq.repo.ClosePeriod(unclosed[i].QuotaID, start, consumed[unclosed[i].QuotaID]); err != nil {
+				return fmt.Errorf("failed to close month %s of quota %d: %w", start.Format("2006-01"), unclosed[i].QuotaID, err)
+			}
+		}
+	}
+
+	for _, quota := range snapshot.quotas {
+		period, err := gq.repo.GetPeriod(quota.ID, snapshot.start)
+		if err != nil {
+			return fmt.Errorf("failed to load month of quota %d: %w", quota.ID, err)
+		}
+		if period == nil {
+			carried, err := gq.carryOver(quota, snapshot.start.AddDate(0, -1, 0))
+			if err != nil {
+				return err
+			}
+			if err := gq.repo.OpenPeriod(quota.ID, snapshot.start, carried); err != nil {
+				return fmt.Errorf("failed to open month of quota %d: %w", quota.ID, err)
+			}
+			if period, err = gq.repo.GetPeriod(quota.ID, snapshot.start); err != nil || period == nil {
+				return fmt.Errorf("failed to load month of quota %d: %v", quota.ID, err)
+			}
+		}
+		snapshot.carried[quota.ID] = period.CarriedHours
+
+		adjusted, err := gq.adjustedHours(quota.ID, snapshot.start)
+		if err != nil {
+			return err
+		}
+		snapshot.adjusted[quota.ID] = adjusted
+	}
+	return nil
+}
+
+// carryOver returns the hours a quota carries out of the month starting at
+// previous: what was left of it under the carry_over unused policy, capped
+// at carry_over_max_hours
+func (gq *GPUQuotas) carryOver(quota models.GPUQuota, previous time.Time) (float64, error) {
+	if quota.CarryOver != models.CarryOverUnused {
+		return 0, nil
+	}
+	period, err := gq.repo.GetPeriod(quota.ID, previous)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load previous month of quota %d: %w", quota.ID, err)
+	}
+	if period == nil || period.ConsumedHours == nil {
+		return 0, nil
+	}
+	adjusted, err := gq.adjustedHours(quota.ID, previous)
+	if err != nil {
+		return 0, err
+	}
+	left := quota.MonthlyHours + period.CarriedHours + adjusted - *period.ConsumedHours
+	if left < 0 {
+		return 0, nil
+	}
+	if quota.CarryOverMaxHours > 0 && left > quota.CarryOverMaxHours {
+		left = quota.CarryOverMaxHours
+	}
+	return left, nil
+}
+
+// adjustedHours sums the admin adjustments of a quota's month
+func (gq *GPUQuotas) adjustedHours(quotaID int64, start time.Time) (float64, error) {
+	adjustments, err := gq.repo.ListAdjustments(quotaID, start)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load adjustments of quota %d: %w", quotaID, err)
+	}
+	var hours float64
+	for _, adjustment := range adjustments {
+		hours += adjustment.Hours
+	}
+	return hours, nil
+}
+
+// consume adds the GPU-hours (and compute cost, when cost is set) that ran
+// during [start, end) to the quotas they count against, and each job's
+// GPU-hours to jobHours when set
+func (gq *GPUQuotas) consume(quotas []models.GPUQuota, start, end time.Time, consumed, cost map[int64]float64, jobHours map[string]float64) error {
+	byScope := make(map[models.GPUQuotaScope]map[string]int64)
+	for _, quota := range quotas {
+		if byScope[quota.Scope] == nil {
+			byScope[quota.Scope] = make(map[string]int64)
+		}
+		byScope[quota.Scope][quota.ScopeID] = quota.ID
+	}
+
+	err := gq.usage.StreamBillableAllocations(start, end, func(alloc repository.BillableAllocation) error {
+		if !alloc.To.After(alloc.From) {
+			return nil
+		}
+		hours := alloc.To.Sub(alloc.From).Hours()
+		share := 1.0
+		if alloc.GPUShare > 0 && alloc.GPUShare < 1 {
+			share = alloc.GPUShare
+		}
+		gpuHours := float64(alloc.Count*alloc.GPUsPerInstance) * share * hours
+		if jobHours != nil {
+			jobHours[alloc.JobID] += gpuHours
+		}
+
+		for scope, scopeID := range map[models.GPUQuotaScope]string{
+			models.GPUQuotaTeam:    alloc.TeamID,
+			models.GPUQuotaProject: alloc.ProjectID,
+		} {
+			id, ok := byScope[scope][scopeID]
+			if !ok || scopeID == "" {
+				continue
+			}
+			consumed[id] += gpuHours
+			if cost != nil {
+				cost[id] += alloc.PricePerHour * share * float64(alloc.Count) * hours
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read usage: %w", err)
+	}
+	return nil
+}
+
+// usage returns a quota's month. Reservations hold what their job has not
+// consumed yet; the reservation of exceptJobID is left out.
+func (s *quotaSnapshot) usage(quota models.GPUQuota, active []models.GPUHourReservation, exceptJobID string) models.GPUQuotaUsage {
+	var reserved float64
+	for _, reservation := range active {
+		if reservation.QuotaID != quota.ID || reservation.JobID == exceptJobID {
+			continue
+		}
+		if outstanding := reservation.Hours - s.jobHours[reservation.JobID]; outstanding > 0 {
+			reserved += outstanding
+		}
+	}
+
+	limit := quota.MonthlyHours + s.carried[quota.ID] + s.adjusted[quota.ID]
+	remaining := limit - s.consumed[quota.ID] - reserved
+	return models.GPUQuotaUsage{
+		Quota:           quota,
+		PeriodStart:     s.start,
+		PeriodEnd:       s.start.AddDate(0, 1, 0),
+		LimitHours:      limit,
+		CarriedHours:    s.carried[quota.ID],
+		AdjustmentHours: s.adjusted[quota.ID],
+		ConsumedHours:   s.consumed[quota.ID],
+		ReservedHours:   reserved,
+		RemainingHours:  remaining,
+		CostUSD:         s.cost[quota.ID],
+		Exhausted:       remaining <= 0,
+	}
+}
+
+// quotaApplies reports whether a quota limits the job
+func quotaApplies(quota models.GPUQuota, job *models.Job) bool {
+	switch quota.Scope {
+	case models.GPUQuotaTeam:
+		return job.TeamID != "" && job.TeamID == quota.ScopeID
+	case models.GPUQuotaProject:
+		return job.ProjectID != "" && job.ProjectID == quota.ScopeID
+	}
+	return false
+}
+
+// EstimatedGPUHours returns the GPU-hours a job is expected to consume: its
+// GPUs, scaled by its GPU fraction, times its estimated hours
+func EstimatedGPUHours(job *models.Job) float64 {
+	gpus := float64(job.Requirements.GPUs)
+	if fraction := job.Requirements.GPUFraction; fraction > 0 && fraction < 1 {
+		gpus *= fraction
+	}
+	return gpus * job.Requirements.EstimatedHours
+}
+
+// gpuHourQuotaReason is recorded when a quota cannot cover a job
+func gpuHourQuotaReason(usage models.GPUQuotaUsage, estimate float64) models.WaitReason {
+	left := usage.RemainingHours
+	if left < 0 {
+		left = 0
+	}
+	return models.WaitReason{
+		Code: models.WaitGPUHourQuota,
+		Message: fmt.Sprintf("The %s %s GPU-hour quota for %s has %.1f of %.1f hours left (%.1f consumed, %.1f reserved); the job needs an estimated %.1f",
+			usage.Quota.Scope, usage.Quota.ScopeID, usage.PeriodStart.Format("January 2006"),
+			left, usage.LimitHours, usage.ConsumedHours, usage.ReservedHours, estimate),
+		Value: fmt.Sprintf("%.1f GPU-hours left", left),
+	}
+}
+
+// monthStart returns the start of t's month in UTC
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
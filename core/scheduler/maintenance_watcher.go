@@ -0,0 +1,275 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gpu-orchestrator/core/clock"
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/monitoring"
+	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/core/supervisor"
+	"gpu-orchestrator/providers"
+)
+
+// MaintenancePolicy controls what is done about scheduled maintenance
+type MaintenancePolicy struct {
+	// MigrateLead is how long before a maintenance window jobs with
+	// constraints.allow_migration and a checkpoint are checkpointed and
+	// requeued; 0 only warns
+	MigrateLead time.Duration
+}
+
+// MaintenanceWatcher polls providers for scheduled host maintenance of the
+// nodes of running jobs. New events are recorded and announced through a job
+// event and webhook; jobs that opted into migration are moved off the node
+// before the window instead of taking an unplanned reboot.
+type MaintenanceWatcher struct {
+	jobRepo         *repository.JobRepository
+	artifactRepo    *repository.ArtifactRepository
+	maintenanceRepo *repository.MaintenanceRepository
+	registry        providers.Registry
+	scheduler       *Scheduler // Tracks the clusters of running jobs; nil checks nothing
+	policy          MaintenancePolicy
+	notifier        monitoring.Notifier // Optional; nil records events only
+	now             func() time.Time
+}
+
+// NewMaintenanceWatcher creates a new maintenance watcher
+func NewMaintenanceWatcher(
+	jobRepo *repository.JobRepository,
+	artifactRepo *repository.ArtifactRepository,
+	maintenanceRepo *repository.MaintenanceRepository,
+	registry providers.Registry,
+	policy MaintenancePolicy,
+) *MaintenanceWatcher {
+	return &MaintenanceWatcher{
+		jobRepo:         jobRepo,
+		artifactRepo:    artifactRepo,
+		maintenanceRepo: maintenanceRepo,
+		registry:        registry,
+		policy:          policy,
+		now:             clock.System.Now,
+	}
+}
+
+// SetScheduler sets the scheduler whose running clusters are watched and
+// which migrates jobs
+func (mw *MaintenanceWatcher) SetScheduler(s *Scheduler) {
+	mw.scheduler = s
+}
+
+// SetNotifier sets where maintenance warnings are delivered
+func (mw *MaintenanceWatcher) SetNotifier(notifier monitoring.Notifier) {
+	mw.notifier = notifier
+}
+
+// Start checks running jobs every interval until ctx is done
+func (mw *MaintenanceWatcher) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			supervisor.Heartbeat(ctx)
+			mw.Check(ctx)
+		}
+	}
+}
+
+// Check polls the scheduled events of every running job's nodes once
+func (mw *MaintenanceWatcher) Check(ctx context.Context) {
+	if mw.scheduler == nil {
+		return
+	}
+	for jobID, cluster := range mw.scheduler.RunningClusters() {
+		if ctx.Err() != nil {
+			return
+		}
+		job, err := mw.jobRepo.GetJob(jobID)
+		if err != nil {
+			log.Printf("Failed to load job %s for maintenance check: %v", jobID, err)
+			continue
+		}
+		if job.Status != models.JobStatusRunning {
+			continue
+		}
+		events, err := mw.scheduledEvents(ctx, job, cluster)
+		if err != nil {
+			log.Printf("Maintenance check of job %s failed: %v", job.ID, err)
+		}
+		mw.handleEvents(ctx, job, events)
+	}
+}
+
+// scheduledEvents returns the maintenance events of a cluster's nodes from
+// every provider that reports them
+func (mw *MaintenanceWatcher) scheduledEvents(ctx context.Context, job *models.Job, cluster *models.Cluster) ([]models.MaintenanceEvent, error) {
+	type placement struct {
+		provider models.Provider
+		region   string
+	}
+	nodes := make(map[placement]map[string]models.Node)
+	for _, node := range cluster.Nodes {
+		if node.InstanceID == "" {
+			continue
+		}
+		key := placement{node.Provider, node.Region}
+		if nodes[key] == nil {
+			nodes[key] = make(map[string]models.Node)
+		}
+		nodes[key][node.InstanceID] = node
+	}
+
+	var events []models.MaintenanceEvent
+	var errs []error
+	for key, byInstance := range nodes {
+		client, ok := mw.registry.Get(key.provider)
+		if !ok {
+			continue
+		}
+		reporter, ok := client.(providers.MaintenanceReporter)
+		if !ok {
+			continue
+		}
+		instanceIDs := make([]string, 0, len(byInstance))
+		for instanceID := range byInstance {
+			instanceIDs = append(instanceIDs, instanceID)
+		}
+		scheduled, err := reporter.ScheduledEvents(ctx, key.region, instanceIDs)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s %s: %w", key.provider, key.region, err))
+			continue
+		}
+		for _, e := range scheduled {
+			node, ok := byInstance[e.InstanceID]
+			if !ok {
+				continue
+			}
+			events = append(events, models.MaintenanceEvent{
+				JobID:        job.ID,
+				Provider:     key.provider,
+				Region:       key.region,
+				InstanceType: node.InstanceType,
+				InstanceID:   e.InstanceID,
+				EventType:    e.EventType,
+				Description:  e.Description,
+				NotBefore:    e.NotBefore,
+				NotAfter:     e.NotAfter,
+			})
+		}
+	}
+	return events, errors.Join(errs...)
+}
+
+// handleEvents records a job's maintenance events, warns about new ones and
+// migrates the job when an event is within the migration lead time
+func (mw *MaintenanceWatcher) handleEvents(ctx context.Context, job *models.Job, events []models.MaintenanceEvent) {
+	for i := range events {
+		event := &events[i]
+		created, err := mw.maintenanceRepo.RecordMaintenanceEvent(event)
+		if err != nil {
+			log.Printf("Failed to record maintenance of %s for job %s: %v", event.InstanceID, job.ID, err)
+			continue
+		}
+		if created {
+			mw.warn(ctx, job, event)
+		}
+		if event.Action != models.MaintenanceMigrated && mw.shouldMigrate(job, event) {
+			mw.migrate(ctx, job, event)
+			return // The job no longer runs on these nodes
+		}
+	}
+}
+
+// maintenanceMeta describes an event for job events and notifications
+func maintenanceMeta(event *models.MaintenanceEvent) map[string]interface{} {
+	meta := map[string]interface{}{
+		"maintenance_id": event.ID,
+		"provider":       event.Provider,
+		"region":         event.Region,
+		"instance_id":    event.InstanceID,
+		"instance_type":  event.InstanceType,
+		"event_type":     event.EventType,
+		"not_before":     event.NotBefore,
+	}
+	if event.NotAfter != nil {
+		meta["not_after"] = *event.NotAfter
+	}
+	if event.Description != "" {
+		meta["description"] = event.Description
+	}
+	return meta
+}
+
+// warn records a maintenance_scheduled event on the job and notifies operators
+func (mw *MaintenanceWatcher) warn(ctx context.Context, job *models.Job, event *models.MaintenanceEvent) {
+	running := models.JobStatusRunning
+	if err := mw.jobRepo.CreateJobEvent(job.ID, &running, running, "maintenance_scheduled", maintenanceMeta(event)); err != nil {
+		log.Printf("Failed to record maintenance warning for job %s: %v", job.ID, err)
+	}
+	log.Printf("Job %s: %s %s scheduled on %s at %s", job.ID, event.Provider, event.EventType, event.InstanceID, event.NotBefore.Format(time.RFC3339))
+
+	if mw.notifier == nil {
+		return
+	}
+	meta := maintenanceMeta(event)
+	meta["job_id"] = job.ID
+	meta["user_id"] = job.UserID
+	err := mw.notifier.Notify(ctx, monitoring.Notification{
+		Subject: fmt.Sprintf("Scheduled maintenance on a node of job %s", job.Name),
+		Message: fmt.Sprintf("%s scheduled %s of instance %s (%s %s) running job %s (%s), not before %s. "+
+			"Set constraints.allow_migration to have the job checkpointed and moved before the window.",
+			event.Provider, event.EventType, event.InstanceID, event.InstanceType, event.Region, job.Name, job.ID,
+			event.NotBefore.UTC().Format(time.RFC1123)),
+		Source: "maintenance_watcher",
+		Meta:   meta,
+		SentAt: mw.now(),
+	})
+	if err != nil {
+		log.Printf("Failed to notify about maintenance of job %s: %v", job.ID, err)
+	}
+}
+
+// shouldMigrate reports whether a job is moved off a node before its
+// maintenance: it opted into migration, has a checkpoint to resume from and
+// the window is within the lead time
+func (mw *MaintenanceWatcher) shouldMigrate(job *models.Job, event *models.MaintenanceEvent) bool {
+	if mw.policy.MigrateLead <= 0 || !job.Constraints.AllowMigration {
+		return false
+	}
+	if event.NotBefore.Sub(mw.now()) > mw.policy.MigrateLead {
+		return false
+	}
+	checkpointType := models.ArtifactTypeCheckpoint
+	checkpoints, err := mw.artifactRepo.GetJobArtifacts(job.ID, &checkpointType)
+	if err != nil {
+		log.Printf("Failed to load checkpoints of job %s: %v", job.ID, err)
+		return false
+	}
+	return len(checkpoints) > 0
+}
+
+// migrate checkpoints and requeues a job ahead of maintenance
+func (mw *MaintenanceWatcher) migrate(ctx context.Context, job *models.Job, event *models.MaintenanceEvent) {
+	meta := maintenanceMeta(event)
+	meta["trigger"] = "maintenance"
+	err := mw.scheduler.migrateJob(ctx, job.ID, meta)
+	if errors.Is(err, repository.ErrStatusConflict) {
+		return // Finished or cancelled meanwhile
+	}
+	if err != nil {
+		log.Printf("Failed to migrate job %s ahead of maintenance: %v", job.ID, err)
+		return
+	}
+	if err := mw.maintenanceRepo.SetMaintenanceAction(event.ID, models.MaintenanceMigrated); err != nil {
+		log.Printf("Failed to record migration for maintenance %d: %v", event.ID, err)
+	}
+	log.Printf("Migrating job %s ahead of %s on %s at %s", job.ID, event.EventType, event.InstanceID, event.NotBefore.Format(time.RFC3339))
+}
@@ -0,0 +1,260 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gpu-orchestrator/core/clock"
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/optimizer"
+	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/core/supervisor"
+	"gpu-orchestrator/providers"
+)
+
+// ManagedTrainingWatcher follows jobs handed to managed training services
+// (SageMaker Training, Vertex AI Custom Training). It polls every active run,
+// charges the billable time the service reports to the job, moves the job to
+// completed or failed when the service's job ends, and stops the service's
+// job when ours is cancelled. Runs are stored, so polling resumes after a
+// restart.
+type ManagedTrainingWatcher struct {
+	jobRepo  *repository.JobRepository
+	runRepo  *repository.ManagedTrainingRepository
+	registry providers.Registry
+	pricing  *optimizer.PricingFetcher // Optional; on-demand rates of spot runs
+	release  func(ctx context.Context, job *models.Job, cluster *models.Cluster)
+	now      func() time.Time
+	mu       sync.Mutex // One poll at a time
+}
+
+// NewManagedTrainingWatcher creates a new managed training watcher
+func NewManagedTrainingWatcher(
+	jobRepo *repository.JobRepository,
+	runRepo *repository.ManagedTrainingRepository,
+	registry providers.Registry,
+	pricing *optimizer.PricingFetcher,
+) *ManagedTrainingWatcher {
+	return &ManagedTrainingWatcher{
+		jobRepo:  jobRepo,
+		runRepo:  runRepo,
+		registry: registry,
+		pricing:  pricing,
+		now:      clock.System.Now,
+	}
+}
+
+// SetReleaseHandler registers the callback that releases the cluster of a
+// job whose run ended
+func (w *ManagedTrainingWatcher) SetReleaseHandler(release func(ctx context.Context, job *models.Job, cluster *models.Cluster)) {
+	w.release = release
+}
+
+// Track records a job's submission to a managed training service so it is
+// polled. Prices are those the job was placed at; spot runs also record the
+// on-demand rate, which SageMaker bills managed spot time at.
+func (w *ManagedTrainingWatcher) Track(job *models.Job, cluster *models.Cluster, allocations []models.Allocation) error {
+	first := allocations[0]
+	run := &models.ManagedTrainingRun{
+		JobID:         job.ID,
+		Provider:      cluster.Provider,
+		Region:        cluster.Region,
+		ServiceJobID:  cluster.ServiceJobID,
+		InstanceType:  first.InstanceType,
+		Spot:          true,
+		OnDemandPrice: first.PricePerHour,
+	}
+	for _, alloc := range allocations {
+		run.Count += alloc.Count
+		run.Spot = run.Spot && alloc.Spot
+	}
+	if run.Spot {
+		run.SpotPrice = first.PricePerHour
+		run.OnDemandPrice = w.onDemandPrice(run)
+	}
+	if err := w.runRepo.CreateRun(run); err != nil {
+		return fmt.Errorf("failed to record managed training run of job %s: %w", job.ID, err)
+	}
+	return nil
+}
+
+// onDemandPrice returns the cached on-demand rate of a spot run's instance
+// type, falling back to its spot rate
+func (w *ManagedTrainingWatcher) onDemandPrice(run *models.ManagedTrainingRun) float64 {
+	if w.pricing == nil {
+		return run.SpotPrice
+	}
+	price, err := w.pricing.GetPrice(run.Provider, run.InstanceType, run.Region, false)
+	if err != nil || price <= 0 {
+		log.Printf("No on-demand price for %s %s in %s, billing job %s at its spot rate: %v", run.Provider, run.InstanceType, run.Region, run.JobID, err)
+		return run.SpotPrice
+	}
+	return price
+}
+
+// Start polls every interval until ctx is done
+func (w *ManagedTrainingWatcher) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			supervisor.Heartbeat(ctx)
+			if err := w.Poll(ctx); err != nil {
+				log.Printf("Managed training poll failed: %v", err)
+			}
+		}
+	}
+}
+
+// Poll checks every active run once. A run whose service cannot be reached
+// is retried on the next poll.
+func (w *ManagedTrainingWatcher) Poll(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	runs, err := w.runRepo.ListActiveRuns()
+	if err != nil {
+		return fmt.Errorf("failed to list managed training runs: %w", err)
+	}
+	var errs []error
+	for i := range runs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := w.poll(ctx, &runs[i]); err != nil {
+			errs = append(errs, fmt.Errorf("job %s: %w", runs[i].JobID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// poll checks one run: stops it when the job was cancelled, stores what the
+// service reports and finishes the job once the run ended
+func (w *ManagedTrainingWatcher) poll(ctx context.Context, run *models.ManagedTrainingRun) error {
+	client, ok := w.registry.Get(run.Provider)
+	if !ok {
+		return fmt.Errorf("provider %s not configured", run.Provider)
+	}
+	trainer, ok := client.(providers.ManagedTrainer)
+	if !ok {
+		return fmt.Errorf("provider %s has no managed training service", run.Provider)
+	}
+	job, err := w.jobRepo.GetJob(run.JobID)
+	if err != nil {
+		return err
+	}
+
+	// Nothing wants the training of a cancelled or failed job anymore
+	if (job.Status == models.JobStatusCancelled || job.Status == models.JobStatusFailed) && run.Status != models.ManagedRunStopping {
+		if err := trainer.StopTrainingJob(ctx, run.Region, run.ServiceJobID); err != nil {
+			return fmt.Errorf("failed to stop %s: %w", run.ServiceJobID, err)
+		}
+		log.Printf("Stopped managed training job %s of %s job %s", run.ServiceJobID, job.Status, job.ID)
+	}
+
+	status, err := trainer.DescribeTrainingJob(providers.NonCritical(ctx), run.Region, run.ServiceJobID)
+	if err != nil {
+		return fmt.Errorf("failed to describe %s: %w", run.ServiceJobID, err)
+	}
+
+	previous := run.Status
+	applyTrainingStatus(run, status, w.now())
+	if err := w.runRepo.UpdateRun(run); err != nil {
+		return err
+	}
+	if cost, err := w.runRepo.JobCost(job.ID); err != nil {
+		log.Printf("Failed to total managed training cost of job %s: %v", job.ID, err)
+	} else if err := w.jobRepo.UpdateJobCost(job.ID, cost); err != nil {
+		log.Printf("Failed to update cost of job %s: %v", job.ID, err)
+	}
+
+	if run.Status != previous {
+		meta := map[string]interface{}{
+			"service_job_id": run.ServiceJobID,
+			"from":           previous,
+			"to":             run.Status,
+			"detail":         run.StatusDetail,
+		}
+		if err := w.jobRepo.CreateJobEvent(job.ID, &job.Status, job.Status, "managed_training_status", meta); err != nil {
+			log.Printf("Failed to record managed training status of job %s: %v", job.ID, err)
+		}
+	}
+
+	if models.ManagedRunEnded(run.Status) {
+		w.finish(ctx, job, run)
+	}
+	return nil
+}
+
+// applyTrainingStatus copies a service status into a run and prices its
+// billable time: spot runs at the spot rate, unless the service already took
+// the spot savings off the billable time
+func applyTrainingStatus(run *models.ManagedTrainingRun, status *providers.TrainingJobStatus, now time.Time) {
+	run.Status = status.State
+	run.StatusDetail = status.Detail
+	run.StartedAt = status.StartedAt
+	run.EndedAt = status.EndedAt
+	if models.ManagedRunEnded(run.Status) && run.EndedAt == nil {
+		// Ended before training started, e.g. stopped while waiting for capacity
+		run.EndedAt = &now
+	}
+	if status.BillableSeconds > run.BillableSeconds || models.ManagedRunEnded(run.Status) {
+		run.BillableSeconds = status.BillableSeconds
+	}
+
+	rate := run.OnDemandPrice
+	if run.Spot && !status.SpotDiscounted && run.SpotPrice > 0 {
+		rate = run.SpotPrice
+	}
+	run.CostUSD = float64(run.BillableSeconds) / 3600 * rate * float64(run.Count)
+}
+
+// finish moves the job of an ended run to the matching status and releases
+// its cluster. Jobs cancelled or failed meanwhile keep their status.
+func (w *ManagedTrainingWatcher) finish(ctx context.Context, job *models.Job, run *models.ManagedTrainingRun) {
+	to, reason := models.JobStatusFailed, "managed_training_failed"
+	switch run.Status {
+	case models.ManagedRunCompleted:
+		to, reason = models.JobStatusCompleted, "managed_training_completed"
+	case models.ManagedRunStopped:
+		// Stopped by someone else, or spot capacity never came within the max wait
+		reason = "managed_training_stopped"
+	}
+
+	if job.Status.CanTransitionTo(to) {
+		meta := map[string]interface{}{
+			"service_job_id":   run.ServiceJobID,
+			"billable_seconds": run.BillableSeconds,
+			"cost_usd":         run.CostUSD,
+		}
+		if run.StatusDetail != "" {
+			meta["detail"] = run.StatusDetail
+		}
+		err := w.jobRepo.UpdateJobStatus(job.ID, job.Status, to, reason, meta)
+		if errors.Is(err, repository.ErrStatusConflict) {
+			log.Printf("Job %s left %s before its managed training ended: %v", job.ID, job.Status, err)
+		} else if err != nil {
+			log.Printf("Failed to update status of job %s: %v", job.ID, err)
+		} else {
+			log.Printf("Job %s %s on managed training (%ds billable, $%.2f)", job.ID, to, run.BillableSeconds, run.CostUSD)
+		}
+	}
+
+	if w.release != nil {
+		w.release(ctx, job, &models.Cluster{
+			ID:           fmt.Sprintf("cluster-%s", job.ID),
+			Provider:     run.Provider,
+			Region:       run.Region,
+			Backend:      models.BackendManaged,
+			ServiceJobID: run.ServiceJobID,
+		})
+	}
+}
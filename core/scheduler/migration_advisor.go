@@ -0,0 +1,354 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gpu-orchestrator/core/clock"
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/monitoring"
+	"gpu-orchestrator/core/optimizer"
+	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/core/supervisor"
+	"gpu-orchestrator/storage"
+)
+
+// migrationScanLimit bounds how many running jobs one pass evaluates
+const migrationScanLimit = 500
+
+// MigrationPolicy controls when restarting a job elsewhere is recommended
+type MigrationPolicy struct {
+	MinSavingsUSD    float64       // Recommend only above this saving
+	RestartOverhead  time.Duration // Provisioning and checkpoint restore on the new cluster
+	MaxCheckpointAge time.Duration // Older checkpoints would redo too much work
+}
+
+// MigrationAdvisor compares, for running jobs with a recent checkpoint, the
+// remaining cost on their current allocation with restarting from the
+// checkpoint on the best placement available now. Cheaper restarts are
+// recommended through an event and webhook; jobs with
+// constraints.allow_migration are checkpointed and rescheduled instead.
+type MigrationAdvisor struct {
+	jobRepo        *repository.JobRepository
+	allocationRepo *repository.AllocationRepository
+	artifactRepo   *repository.ArtifactRepository
+	migrationRepo  *repository.MigrationRepository
+	optimizer      *optimizer.AllocationOptimizer
+	costs          *optimizer.CostCalculator
+	policy         MigrationPolicy
+	scheduler      *Scheduler                    // Optional; nil only recommends
+	notifier       monitoring.Notifier           // Optional; nil records events only
+	replicator     *storage.CheckpointReplicator // Optional; nil restores from the primary copy
+	now            func() time.Time
+	advised        map[string]string // Job ID -> checkpoint URI last advised on
+}
+
+// NewMigrationAdvisor creates a new migration advisor
+func NewMigrationAdvisor(
+	jobRepo *repository.JobRepository,
+	allocationRepo *repository.AllocationRepository,
+	artifactRepo *repository.ArtifactRepository,
+	migrationRepo *repository.MigrationRepository,
+	opt *optimizer.AllocationOptimizer,
+	costs *optimizer.CostCalculator,
+	policy MigrationPolicy,
+) *MigrationAdvisor {
+	return &MigrationAdvisor{
+		jobRepo:        jobRepo,
+		allocationRepo: allocationRepo,
+		artifactRepo:   artifactRepo,
+		migrationRepo:  migrationRepo,
+		optimizer:      opt,
+		costs:          costs,
+		policy:         policy,
+		now:            clock.System.Now,
+		advised:        make(map[string]string),
+	}
+}
+
+// SetScheduler sets the scheduler that reschedules migrated jobs
+func (ma *MigrationAdvisor) SetScheduler(s *Scheduler) {
+	ma.scheduler = s
+}
+
+// SetNotifier sets where recommendations are delivered
+func (ma *MigrationAdvisor) SetNotifier(notifier monitoring.Notifier) {
+	ma.notifier = notifier
+}
+
+// SetCheckpointReplicator sets the replicator whose copies a migration may
+// restore from when they are closer to the alternative placement
+func (ma *MigrationAdvisor) SetCheckpointReplicator(replicator *storage.CheckpointReplicator) {
+	ma.replicator = replicator
+}
+
+// Start evaluates running jobs every interval until ctx is done
+func (ma *MigrationAdvisor) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			supervisor.Heartbeat(ctx)
+			if err := ma.Check(ctx); err != nil {
+				log.Printf("Migration check failed: %v", err)
+			}
+		}
+	}
+}
+
+// Check evaluates every running job once
+func (ma *MigrationAdvisor) Check(ctx context.Context) error {
+	running := models.JobStatusRunning
+	jobs, _, err := ma.jobRepo.ListJobs("", &running, migrationScanLimit, "")
+	if err != nil {
+		return fmt.Errorf("failed to list running jobs: %w", err)
+	}
+
+	for _, listed := range jobs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		job, err := ma.jobRepo.GetJob(listed.ID)
+		if err != nil {
+			log.Printf("Failed to load job %s for migration check: %v", listed.ID, err)
+			continue
+		}
+		inputs, err := ma.Evaluate(ctx, job)
+		if err != nil {
+			log.Printf("Migration check of job %s skipped: %v", job.ID, err)
+			continue
+		}
+		if inputs == nil || inputs.SavingsUSD < ma.policy.MinSavingsUSD || ma.advised[job.ID] == inputs.CheckpointURI {
+			continue
+		}
+		ma.advise(ctx, job, inputs)
+	}
+	return nil
+}
+
+// Evaluate computes the cost of restarting the job elsewhere from its latest
+// checkpoint. Returns nil without error when the job has no recent
+// checkpoint, no progress estimate, or the best placement is its current one.
+func (ma *MigrationAdvisor) Evaluate(ctx context.Context, job *models.Job) (*models.MigrationInputs, error) {
+	now := ma.now()
+	checkpoint, err := ma.latestCheckpoint(job.ID)
+	if err != nil {
+		return nil, err
+	}
+	if checkpoint == nil || now.Sub(checkpoint.CreatedAt) > ma.policy.MaxCheckpointAge {
+		return nil, nil
+	}
+
+	progress := monitoring.ComputeProgress(job, now)
+	if progress.ETA == nil || !progress.ETA.After(now) {
+		return nil, nil
+	}
+	remaining := progress.ETA.Sub(now)
+
+	current, err := ma.allocationRepo.GetAllocationsByJobID(job.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load allocations: %w", err)
+	}
+	if len(current) == 0 {
+		return nil, nil
+	}
+
+	requirements := job.Requirements
+	requirements.EstimatedHours = remaining.Hours()
+	alternative, _, err := ma.optimizer.OptimizeWithDecision(ctx, requirements, job.Constraints)
+	if err != nil {
+		return nil, fmt.Errorf("failed to optimize alternative placement: %w", err)
+	}
+	if len(alternative) == 0 || samePlacement(current, alternative) {
+		return nil, nil
+	}
+
+	// Restore from the replica when it is closer to the alternative placement
+	source := storage.CheckpointCopy{URI: checkpoint.URI, Provider: current[0].Provider, Region: current[0].Region}
+	if ma.replicator != nil {
+		if preferred := ma.replicator.PreferredCopy(job, *checkpoint, alternative[0].Provider, alternative[0].Region); preferred.Replica {
+			source = preferred
+		}
+	}
+
+	inputs := &models.MigrationInputs{
+		CheckpointURI:        source.URI,
+		CheckpointAt:         checkpoint.CreatedAt,
+		RemainingHours:       remaining.Hours(),
+		LostHours:            now.Sub(checkpoint.CreatedAt).Hours(),
+		RestartOverheadHours: ma.policy.RestartOverhead.Hours(),
+		Current:              migrationTargets(current),
+		Alternative:          migrationTargets(alternative),
+		MinSavingsUSD:        ma.policy.MinSavingsUSD,
+	}
+	if size, ok := checkpoint.MetaJSON["size_bytes"].(float64); ok {
+		inputs.CheckpointSizeGB = size / (1 << 30)
+	}
+	inputs.CurrentHourlyCost = hourlyCost(current)
+	inputs.CurrentRemainingCost = inputs.CurrentHourlyCost * inputs.RemainingHours
+	inputs.AlternativeHourly = hourlyCost(alternative)
+	inputs.AlternativeCost = inputs.AlternativeHourly * inputs.RemainingHours
+	inputs.RestartCost = inputs.AlternativeHourly * (inputs.LostHours + inputs.RestartOverheadHours)
+	inputs.TransferCost = ma.costs.CalculateDataTransferCost(inputs.CheckpointSizeGB,
+		source.Provider, source.Region, alternative[0].Provider, alternative[0].Region)
+	inputs.SavingsUSD = inputs.CurrentRemainingCost - (inputs.RestartCost + inputs.TransferCost + inputs.AlternativeCost)
+	return inputs, nil
+}
+
+// advise records the recommendation with its inputs, then migrates the job
+// if it opted in, or notifies otherwise. Jobs behind their declared
+// checkpoint schedule are only recommended: restarting would lose more work
+// than the schedule promised.
+func (ma *MigrationAdvisor) advise(ctx context.Context, job *models.Job, inputs *models.MigrationInputs) {
+	action := models.MigrationRecommended
+	behind := false
+	if job.Constraints.AllowMigration && ma.scheduler != nil {
+		action = models.MigrationStarted
+		if behind = ma.checkpointingBehind(job); behind {
+			action = models.MigrationRecommended
+		}
+	}
+
+	advice := &models.MigrationAdvice{JobID: job.ID, Action: action, Inputs: *inputs}
+	if err := ma.migrationRepo.CreateAdvice(advice); err != nil {
+		log.Printf("Failed to record migration advice for job %s: %v", job.ID, err)
+		return
+	}
+	ma.advised[job.ID] = inputs.CheckpointURI
+
+	meta := map[string]interface{}{
+		"advice_id":      advice.ID,
+		"savings_usd":    inputs.SavingsUSD,
+		"checkpoint_uri": inputs.CheckpointURI,
+		"provider":       inputs.Alternative[0].Provider,
+		"region":         inputs.Alternative[0].Region,
+		"instance_type":  inputs.Alternative[0].InstanceType,
+	}
+	if behind {
+		meta["auto_migration_refused"] = "checkpointing_behind"
+	}
+
+	if action == models.MigrationStarted {
+		err := ma.scheduler.migrateJob(ctx, job.ID, meta)
+		if errors.Is(err, repository.ErrStatusConflict) {
+			return // Finished or cancelled meanwhile
+		}
+		if err != nil {
+			log.Printf("Failed to migrate job %s: %v", job.ID, err)
+			return
+		}
+		log.Printf("Migrating job %s to %s/%s to save $%.2f", job.ID, meta["provider"], meta["region"], inputs.SavingsUSD)
+		return
+	}
+
+	running := models.JobStatusRunning
+	if err := ma.jobRepo.CreateJobEvent(job.ID, &running, running, "migration_recommended", meta); err != nil {
+		log.Printf("Failed to record migration recommendation for job %s: %v", job.ID, err)
+	}
+	log.Printf("Job %s would save $%.2f restarting on %s/%s", job.ID, inputs.SavingsUSD, meta["provider"], meta["region"])
+
+	if ma.notifier == nil {
+		return
+	}
+	hint := "Set constraints.allow_migration to let the scheduler do this automatically."
+	if behind {
+		hint = "It was not migrated automatically because it is behind its declared checkpoint schedule."
+	}
+	err := ma.notifier.Notify(ctx, monitoring.Notification{
+		Subject: "Job " + job.Name + " is cheaper to restart elsewhere",
+		Message: fmt.Sprintf("Job %s (%s) would save an estimated $%.2f by restarting from checkpoint %s on %s %s in %s. %s",
+			job.Name, job.ID, inputs.SavingsUSD, inputs.CheckpointURI, inputs.Alternative[0].InstanceType, inputs.Alternative[0].Provider, inputs.Alternative[0].Region, hint),
+		Source: "migration_advisor",
+		Meta:   map[string]interface{}{"job_id": job.ID, "user_id": job.UserID, "advice_id": advice.ID, "savings_usd": inputs.SavingsUSD},
+		SentAt: ma.now(),
+	})
+	if err != nil {
+		log.Printf("Failed to notify about migration of job %s: %v", job.ID, err)
+	}
+}
+
+// latestCheckpoint returns the job's newest checkpoint artifact, or nil.
+// Artifacts are listed newest first.
+func (ma *MigrationAdvisor) latestCheckpoint(jobID string) (*models.JobArtifact, error) {
+	checkpointType := models.ArtifactTypeCheckpoint
+	artifacts, err := ma.artifactRepo.GetJobArtifacts(jobID, &checkpointType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load checkpoints: %w", err)
+	}
+	if len(artifacts) == 0 {
+		return nil, nil
+	}
+	return &artifacts[0], nil
+}
+
+// checkpointingBehind reports whether the job declares a checkpoint schedule
+// and has fallen behind it
+func (ma *MigrationAdvisor) checkpointingBehind(job *models.Job) bool {
+	if job.Checkpointing == nil {
+		return false
+	}
+	latest, err := ma.latestCheckpoint(job.ID)
+	if err != nil {
+		log.Printf("Failed to check checkpoint cadence of job %s: %v", job.ID, err)
+		return true
+	}
+	cadence := models.CheckpointingBehind(job, latest, ma.now())
+	return cadence != nil && cadence.Behind
+}
+
+// hourlyCost returns the combined hourly price of allocations
+func hourlyCost(allocations []models.Allocation) float64 {
+	var cost float64
+	for _, alloc := range allocations {
+		cost += alloc.HourlyPrice() * float64(alloc.Count)
+	}
+	return cost
+}
+
+// migrationTargets returns the audit view of allocations
+func migrationTargets(allocations []models.Allocation) []models.MigrationTarget {
+	targets := make([]models.MigrationTarget, len(allocations))
+	for i, alloc := range allocations {
+		targets[i] = models.MigrationTarget{
+			Provider:     alloc.Provider,
+			Region:       alloc.Region,
+			InstanceType: alloc.InstanceType,
+			Count:        alloc.Count,
+			Spot:         alloc.Spot,
+			PricePerHour: alloc.PricePerHour,
+		}
+	}
+	return targets
+}
+
+// samePlacement reports whether both allocation sets use the same instances
+func samePlacement(a, b []models.Allocation) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := make(map[string]int)
+	for _, alloc := range a {
+		counts[placementKey(alloc)] += alloc.Count
+	}
+	for _, alloc := range b {
+		counts[placementKey(alloc)] -= alloc.Count
+	}
+	for _, count := range counts {
+		if count != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// placementKey identifies where an allocation runs
+func placementKey(alloc models.Allocation) string {
+	return fmt.Sprintf("%s/%s/%s/%t", alloc.Provider, alloc.Region, alloc.InstanceType, alloc.Spot)
+}
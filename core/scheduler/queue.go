@@ -2,46 +2,112 @@ package scheduler
 
 import (
 	"container/heap"
+	"math"
 	"sync"
 	"time"
 
+	"gpu-orchestrator/core/clock"
 	"gpu-orchestrator/core/models"
 )
 
+// boostPriorityHours is how many hours of deadline slack or dollars of budget
+// one level of priority boost outweighs in the computed priority
+const boostPriorityHours = 1000.0
+
 // JobQueue is a priority queue for jobs
 type JobQueue struct {
-	jobs []*QueuedJob
-	mu   sync.Mutex
+	jobs      []*QueuedJob
+	queued    map[string]bool // IDs of queued jobs, so re-loading pending jobs does not duplicate them
+	fairShare *FairShare      // Optional; nil orders without team usage
+	mu        sync.Mutex
+	now       func() time.Time
 }
 
 // QueuedJob wraps a job with priority information
 type QueuedJob struct {
-	Job      *models.Job
-	Priority float64 // Lower is higher priority
-	Index    int     // For heap.Interface
+	Job       *models.Job
+	Priority  float64 // Lower is higher priority
+	FairShare float64 // Team fair-share adjustment; negative moves the job ahead
+	Index     int     // For heap.Interface
 }
 
 // NewJobQueue creates a new job queue
 func NewJobQueue() *JobQueue {
 	jq := &JobQueue{
-		jobs: make([]*QueuedJob, 0),
+		jobs:   make([]*QueuedJob, 0),
+		queued: make(map[string]bool),
+		now:    clock.System.Now,
 	}
 	heap.Init(jq)
 	return jq
 }
 
-// Enqueue adds a job to the queue
+// SetFairShare orders jobs of teams under their fair share ahead of jobs of
+// teams over it. Adjustments are re-read whenever a job is (re-)queued.
+func (jq *JobQueue) SetFairShare(fairShare *FairShare) {
+	jq.mu.Lock()
+	defer jq.mu.Unlock()
+	jq.fairShare = fairShare
+}
+
+// Enqueue adds a job to the queue unless it is already queued
 func (jq *JobQueue) Enqueue(job *models.Job) {
 	jq.mu.Lock()
 	defer jq.mu.Unlock()
 
-	priority := jq.calculatePriority(job)
+	if job.ID != "" && jq.queued[job.ID] {
+		return
+	}
+	jq.queued[job.ID] = true
+
+	heap.Push(jq, &QueuedJob{
+		Job:       job,
+		Priority:  jq.calculatePriority(job),
+		FairShare: jq.fairShareAdjustment(job),
+	})
+}
+
+// Update re-prioritizes a queued job after its boost, deadline or budget
+// changed, or enqueues it if it is not queued
+func (jq *JobQueue) Update(job *models.Job) {
+	jq.mu.Lock()
+	defer jq.mu.Unlock()
+
+	for _, item := range jq.jobs {
+		if item.Job.ID == job.ID {
+			item.Job = job
+			item.Priority = jq.calculatePriority(job)
+			item.FairShare = jq.fairShareAdjustment(job)
+			heap.Fix(jq, item.Index)
+			return
+		}
+	}
+
+	jq.queued[job.ID] = true
 	heap.Push(jq, &QueuedJob{
-		Job:      job,
-		Priority: priority,
+		Job:       job,
+		Priority:  jq.calculatePriority(job),
+		FairShare: jq.fairShareAdjustment(job),
 	})
 }
 
+// Snapshot returns the queued jobs in the order they would be popped
+func (jq *JobQueue) Snapshot() []QueuedJob {
+	jq.mu.Lock()
+	ordered := &JobQueue{jobs: make([]*QueuedJob, len(jq.jobs))}
+	for i, item := range jq.jobs {
+		copied := *item
+		ordered.jobs[i] = &copied
+	}
+	jq.mu.Unlock()
+
+	snapshot := make([]QueuedJob, 0, len(ordered.jobs))
+	for ordered.Len() > 0 {
+		snapshot = append(snapshot, *heap.Pop(ordered).(*QueuedJob))
+	}
+	return snapshot
+}
+
 // PopJob removes and returns the highest priority job
 func (jq *JobQueue) PopJob() *models.Job {
 	jq.mu.Lock()
@@ -52,6 +118,7 @@ func (jq *JobQueue) PopJob() *models.Job {
 	}
 
 	item := heap.Pop(jq).(*QueuedJob)
+	delete(jq.queued, item.Job.ID)
 	return item.Job
 }
 
@@ -62,6 +129,17 @@ func (jq *JobQueue) Len() int {
 
 // Less compares two jobs for priority (lower priority value = higher priority)
 func (jq *JobQueue) Less(i, j int) bool {
+	// Operator boosts outrank everything else
+	if jq.jobs[i].Job.PriorityBoost != jq.jobs[j].Job.PriorityBoost {
+		return jq.jobs[i].Job.PriorityBoost > jq.jobs[j].Job.PriorityBoost
+	}
+
+	// Then fair share, in whole steps so teams near their share keep the
+	// deadline/budget order
+	if fi, fj := math.Round(jq.jobs[i].FairShare), math.Round(jq.jobs[j].FairShare); fi != fj {
+		return fi < fj
+	}
+
 	// Priority: deadline first, then budget
 	if jq.jobs[i].Job.Constraints.Deadline != nil && jq.jobs[j].Job.Constraints.Deadline != nil {
 		return jq.jobs[i].Job.Constraints.Deadline.Before(*jq.jobs[j].Job.Constraints.Deadline)
@@ -107,7 +185,7 @@ func (jq *JobQueue) calculatePriority(job *models.Job) float64 {
 
 	// Deadline urgency (sooner = higher priority)
 	if job.Constraints.Deadline != nil {
-		timeUntilDeadline := time.Until(*job.Constraints.Deadline).Hours()
+		timeUntilDeadline := job.Constraints.Deadline.Sub(jq.now()).Hours()
 		if timeUntilDeadline > 0 {
 			priority += timeUntilDeadline // Lower time = lower priority value = higher priority
 		}
@@ -116,5 +194,19 @@ func (jq *JobQueue) calculatePriority(job *models.Job) float64 {
 	// Budget (lower budget = higher priority, to process cheaper jobs first)
 	priority += job.Constraints.MaxBudget
 
+	// Team fair share (under-served teams first)
+	priority += jq.fairShareAdjustment(job) * fairSharePriorityHours
+
+	// Operator boost
+	priority -= float64(job.PriorityBoost) * boostPriorityHours
+
 	return priority
 }
+
+// fairShareAdjustment returns the job's fair-share adjustment, 0 without fair share
+func (jq *JobQueue) fairShareAdjustment(job *models.Job) float64 {
+	if jq.fairShare == nil {
+		return 0
+	}
+	return jq.fairShare.Adjustment(job)
+}
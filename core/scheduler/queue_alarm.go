@@ -0,0 +1,139 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gpu-orchestrator/core/clock"
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/monitoring"
+	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/core/supervisor"
+)
+
+// QueueAlarm warns about jobs pending longer than constraints.max_queue_time,
+// so users raise their budget or relax constraints instead of waiting, and
+// cancels jobs pending longer than constraints.cancel_after_queue_time. The
+// warning is recorded once per pending episode.
+type QueueAlarm struct {
+	jobRepo  *repository.JobRepository
+	notifier monitoring.Notifier // Optional; nil records events only
+	now      func() time.Time
+}
+
+// NewQueueAlarm creates a new queue-time alarm
+func NewQueueAlarm(jobRepo *repository.JobRepository) *QueueAlarm {
+	return &QueueAlarm{
+		jobRepo: jobRepo,
+		now:     clock.System.Now,
+	}
+}
+
+// SetNotifier sets where queue-time warnings are delivered
+func (qa *QueueAlarm) SetNotifier(notifier monitoring.Notifier) {
+	qa.notifier = notifier
+}
+
+// Start checks pending jobs every interval until ctx is done
+func (qa *QueueAlarm) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			supervisor.Heartbeat(ctx)
+			if err := qa.Check(ctx); err != nil {
+				log.Printf("Queue time check failed: %v", err)
+			}
+		}
+	}
+}
+
+// Check warns about and cancels pending jobs past their thresholds
+func (qa *QueueAlarm) Check(ctx context.Context) error {
+	ages, err := qa.jobRepo.ListQueueAges()
+	if err != nil {
+		return fmt.Errorf("failed to list pending job ages: %w", err)
+	}
+
+	now := qa.now()
+	for _, age := range ages {
+		waited := now.Sub(age.Since)
+		meta := map[string]interface{}{
+			"since":          age.Since,
+			"queued_seconds": int(waited.Seconds()),
+		}
+
+		if age.CancelAfter > 0 && waited >= age.CancelAfter {
+			meta["threshold_seconds"] = int(age.CancelAfter.Seconds())
+			qa.cancel(ctx, age, meta)
+			continue
+		}
+		if age.MaxQueueTime > 0 && waited >= age.MaxQueueTime && !age.Warned {
+			meta["threshold_seconds"] = int(age.MaxQueueTime.Seconds())
+			qa.warn(ctx, age, waited, meta)
+		}
+	}
+	return nil
+}
+
+// warn records the warning event, then notifies. The event is written first
+// so a failed delivery is not retried every tick.
+func (qa *QueueAlarm) warn(ctx context.Context, age repository.JobQueueAge, waited time.Duration, meta map[string]interface{}) {
+	pending := models.JobStatusPending
+	if err := qa.jobRepo.CreateJobEvent(age.JobID, &pending, pending, "queue_time_exceeded", meta); err != nil {
+		log.Printf("Failed to record queue time warning for job %s: %v", age.JobID, err)
+		return
+	}
+	log.Printf("Job %s has been pending for %s (max_queue_time %s)", age.JobID, waited.Round(time.Second), age.MaxQueueTime)
+
+	message := fmt.Sprintf("Job %s (%s) has been pending for %s, past its max_queue_time of %s. "+
+		"Raise its budget or relax its constraints to get it scheduled.", age.Name, age.JobID, waited.Round(time.Minute), age.MaxQueueTime)
+	if age.CancelAfter > 0 {
+		message += fmt.Sprintf(" It will be cancelled after %s pending.", age.CancelAfter)
+	}
+	qa.notify(ctx, age, "Job "+age.Name+" is still pending", message, meta)
+}
+
+// cancel cancels a job still pending past its cancel threshold
+func (qa *QueueAlarm) cancel(ctx context.Context, age repository.JobQueueAge, meta map[string]interface{}) {
+	err := qa.jobRepo.UpdateJobStatus(age.JobID, models.JobStatusPending, models.JobStatusCancelled, "queue_time_cancelled", meta)
+	if errors.Is(err, repository.ErrStatusConflict) {
+		return // Scheduled or cancelled meanwhile
+	}
+	if err != nil {
+		log.Printf("Failed to cancel job %s past its queue time: %v", age.JobID, err)
+		return
+	}
+	log.Printf("Cancelled job %s after %s pending", age.JobID, age.CancelAfter)
+
+	message := fmt.Sprintf("Job %s (%s) was cancelled after %s pending (cancel_after_queue_time).", age.Name, age.JobID, age.CancelAfter)
+	qa.notify(ctx, age, "Job "+age.Name+" was cancelled", message, meta)
+}
+
+// notify delivers a queue-time notification if a notifier is set
+func (qa *QueueAlarm) notify(ctx context.Context, age repository.JobQueueAge, subject, message string, meta map[string]interface{}) {
+	if qa.notifier == nil {
+		return
+	}
+	notificationMeta := map[string]interface{}{"job_id": age.JobID, "user_id": age.UserID}
+	for key, value := range meta {
+		notificationMeta[key] = value
+	}
+	err := qa.notifier.Notify(ctx, monitoring.Notification{
+		Subject: subject,
+		Message: message,
+		Source:  "queue_alarm",
+		Meta:    notificationMeta,
+		SentAt:  qa.now(),
+	})
+	if err != nil {
+		log.Printf("Failed to notify about queued job %s: %v", age.JobID, err)
+	}
+}
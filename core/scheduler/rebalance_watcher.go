@@ -0,0 +1,386 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gpu-orchestrator/core/clock"
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/optimizer"
+	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/core/supervisor"
+	"gpu-orchestrator/providers"
+)
+
+// RebalancePolicy controls what is done about spot rebalance recommendations
+type RebalancePolicy struct {
+	// RequestCheckpoint asks the training wrapper of jobs with checkpointing
+	// configured for an immediate checkpoint
+	RequestCheckpoint bool
+	// ProvisionReplacement adds an on-demand node to elastic jobs ahead of
+	// the interruption, so the job does not shrink while a replacement boots
+	ProvisionReplacement bool
+}
+
+// RebalanceWatcher polls providers for rebalance recommendations of the spot
+// nodes of running jobs. A recommendation usually comes minutes before the
+// interruption notice: it is recorded as a warning on the job, an immediate
+// checkpoint is requested so the interruption loses little work, and elastic
+// jobs get their replacement node early. Everything shows in the job's events.
+type RebalanceWatcher struct {
+	jobRepo       *repository.JobRepository
+	artifactRepo  *repository.ArtifactRepository
+	rebalanceRepo *repository.RebalanceRepository
+	registry      providers.Registry
+	pricing       *optimizer.PricingFetcher
+	scheduler     *Scheduler // Tracks the clusters of running jobs; nil checks nothing
+	policy        RebalancePolicy
+	now           func() time.Time
+}
+
+// NewRebalanceWatcher creates a new rebalance watcher
+func NewRebalanceWatcher(
+	jobRepo *repository.JobRepository,
+	artifactRepo *repository.ArtifactRepository,
+	rebalanceRepo *repository.RebalanceRepository,
+	registry providers.Registry,
+	pricing *optimizer.PricingFetcher,
+	policy RebalancePolicy,
+) *RebalanceWatcher {
+	return &RebalanceWatcher{
+		jobRepo:       jobRepo,
+		artifactRepo:  artifactRepo,
+		rebalanceRepo: rebalanceRepo,
+		registry:      registry,
+		pricing:       pricing,
+		policy:        policy,
+		now:           clock.System.Now,
+	}
+}
+
+// SetScheduler sets the scheduler whose running clusters are watched
+func (rw *RebalanceWatcher) SetScheduler(s *Scheduler) {
+	rw.scheduler = s
+}
+
+// Start checks running jobs every interval until ctx is done
+func (rw *RebalanceWatcher) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			supervisor.Heartbeat(ctx)
+			rw.Check(ctx)
+		}
+	}
+}
+
+// Check polls the rebalance recommendations of every running job's spot
+// nodes once and follows up on earlier ones
+func (rw *RebalanceWatcher) Check(ctx context.Context) {
+	if rw.scheduler == nil {
+		return
+	}
+	for jobID, cluster := range rw.scheduler.RunningClusters() {
+		if ctx.Err() != nil {
+			return
+		}
+		job, err := rw.jobRepo.GetJob(jobID)
+		if err != nil {
+			log.Printf("Failed to load job %s for rebalance check: %v", jobID, err)
+			continue
+		}
+		if job.Status != models.JobStatusRunning {
+			continue
+		}
+		signals, err := rw.recommendations(ctx, job, cluster)
+		if err != nil {
+			log.Printf("Rebalance check of job %s failed: %v", job.ID, err)
+		}
+		rw.handleSignals(ctx, job, signals)
+		rw.followUp(job, cluster)
+	}
+}
+
+// recommendations returns the rebalance recommendations of a cluster's spot
+// nodes from every provider that reports them
+func (rw *RebalanceWatcher) recommendations(ctx context.Context, job *models.Job, cluster *models.Cluster) ([]models.RebalanceSignal, error) {
+	type placement struct {
+		provider models.Provider
+		region   string
+	}
+	nodes := make(map[placement]map[string]models.Node)
+	for _, node := range cluster.Nodes {
+		if !node.Spot || node.InstanceID == "" {
+			continue
+		}
+		key := placement{node.Provider, node.Region}
+		if nodes[key] == nil {
+			nodes[key] = make(map[string]models.Node)
+		}
+		nodes[key][node.InstanceID] = node
+	}
+
+	var signals []models.RebalanceSignal
+	var errs []error
+	for key, byInstance := range nodes {
+		client, ok := rw.registry.Get(key.provider)
+		if !ok {
+			continue
+		}
+		reporter, ok := client.(providers.RebalanceReporter)
+		if !ok {
+			continue
+		}
+		instanceIDs := make([]string, 0, len(byInstance))
+		for instanceID := range byInstance {
+			instanceIDs = append(instanceIDs, instanceID)
+		}
+		recommendations, err := reporter.RebalanceRecommendations(ctx, key.region, instanceIDs)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s %s: %w", key.provider, key.region, err))
+			continue
+		}
+		for _, rec := range recommendations {
+			node, ok := byInstance[rec.InstanceID]
+			if !ok {
+				continue
+			}
+			signals = append(signals, models.RebalanceSignal{
+				JobID:        job.ID,
+				Provider:     key.provider,
+				Region:       key.region,
+				InstanceType: node.InstanceType,
+				InstanceID:   rec.InstanceID,
+				NoticedAt:    rec.NoticedAt,
+			})
+		}
+	}
+	return signals, errors.Join(errs...)
+}
+
+// handleSignals records a job's recommendations and acts on new ones
+func (rw *RebalanceWatcher) handleSignals(ctx context.Context, job *models.Job, signals []models.RebalanceSignal) {
+	for i := range signals {
+		signal := &signals[i]
+		created, err := rw.rebalanceRepo.RecordSignal(signal)
+		if err != nil {
+			log.Printf("Failed to record rebalance recommendation of %s for job %s: %v", signal.InstanceID, job.ID, err)
+			continue
+		}
+		if !created {
+			continue
+		}
+		rw.recordEvent(job.ID, "rebalance_recommended", rebalanceMeta(signal))
+		log.Printf("Job %s: %s rebalance recommendation for spot instance %s", job.ID, signal.Provider, signal.InstanceID)
+
+		if rw.policy.RequestCheckpoint && rw.checkpointing(job) {
+			rw.requestCheckpoint(job, signal)
+		}
+		if rw.policy.ProvisionReplacement {
+			rw.provisionReplacement(ctx, job, signal)
+		}
+	}
+}
+
+// rebalanceMeta describes a signal for job events
+func rebalanceMeta(signal *models.RebalanceSignal) map[string]interface{} {
+	return map[string]interface{}{
+		"rebalance_id":  signal.ID,
+		"provider":      signal.Provider,
+		"region":        signal.Region,
+		"instance_id":   signal.InstanceID,
+		"instance_type": signal.InstanceType,
+		"noticed_at":    signal.NoticedAt,
+	}
+}
+
+// recordEvent logs a running -> running job event
+func (rw *RebalanceWatcher) recordEvent(jobID, reason string, meta map[string]interface{}) {
+	running := models.JobStatusRunning
+	if err := rw.jobRepo.CreateJobEvent(jobID, &running, running, reason, meta); err != nil {
+		log.Printf("Failed to record %s event for job %s: %v", reason, jobID, err)
+	}
+}
+
+// checkpointing reports whether a job has checkpointing configured: it
+// writes to an output prefix or has recorded checkpoints
+func (rw *RebalanceWatcher) checkpointing(job *models.Job) bool {
+	if job.DataAccess != nil && job.DataAccess.OutputURI != "" {
+		return true
+	}
+	checkpointType := models.ArtifactTypeCheckpoint
+	checkpoints, err := rw.artifactRepo.GetJobArtifacts(job.ID, &checkpointType)
+	if err != nil {
+		log.Printf("Failed to load checkpoints of job %s: %v", job.ID, err)
+		return false
+	}
+	return len(checkpoints) > 0
+}
+
+// requestCheckpoint raises the checkpoint request the training wrapper polls
+// from GET /v1/jobs/{id}/checkpoint-request
+func (rw *RebalanceWatcher) requestCheckpoint(job *models.Job, signal *models.RebalanceSignal) {
+	now := rw.now()
+	if err := rw.rebalanceRepo.MarkCheckpointRequested(signal.ID, now); err != nil {
+		log.Printf("Failed to request a checkpoint of job %s: %v", job.ID, err)
+		return
+	}
+	signal.CheckpointRequestedAt = &now
+	meta := rebalanceMeta(signal)
+	meta["reason"] = models.CheckpointRequestRebalance
+	rw.recordEvent(job.ID, "checkpoint_requested", meta)
+}
+
+// provisionReplacement adds an on-demand node of the signalled node's type to
+// an elastic job. On-demand, because the spot pool just signalled risk.
+func (rw *RebalanceWatcher) provisionReplacement(ctx context.Context, job *models.Job, signal *models.RebalanceSignal) {
+	manager := rw.scheduler.ElasticManager()
+	if manager == nil {
+		return
+	}
+	ec, ok := manager.Get(job.ID)
+	if !ok {
+		return // Only elastic jobs can take a node before losing one
+	}
+	if len(ec.Cluster.Nodes) >= job.Requirements.Elastic.MaxNodes {
+		log.Printf("Job %s is at max_nodes, no replacement provisioned for %s", job.ID, signal.InstanceID)
+		return
+	}
+	price, err := rw.pricing.GetPrice(signal.Provider, signal.InstanceType, signal.Region, false)
+	if err != nil {
+		log.Printf("No on-demand price for a replacement of %s in job %s: %v", signal.InstanceID, job.ID, err)
+		return
+	}
+	alloc := models.Allocation{
+		Provider:     signal.Provider,
+		InstanceType: signal.InstanceType,
+		Region:       signal.Region,
+		Count:        1,
+		PricePerHour: price,
+	}
+	optimizer.ApplyDataVolume(&alloc, job.Requirements)
+
+	started := rw.now()
+	added, err := manager.ScaleUp(ctx, job.ID, alloc)
+	if err != nil {
+		log.Printf("Failed to provision a replacement of %s for job %s: %v", signal.InstanceID, job.ID, err)
+		return
+	}
+	if len(added) == 0 {
+		return
+	}
+	if err := rw.rebalanceRepo.SetReplacement(signal.ID, added[0].ID, started); err != nil {
+		log.Printf("Failed to record the replacement of %s for job %s: %v", signal.InstanceID, job.ID, err)
+	}
+	meta := rebalanceMeta(signal)
+	meta["replacement_node"] = added[0].ID
+	meta["price_per_hour"] = price
+	meta["nodes"] = len(ec.Cluster.Nodes) + len(added)
+	rw.recordEvent(job.ID, "rebalance_replacement_started", meta)
+}
+
+// followUp records the checkpoints answering a job's requests and the
+// interruptions of its signalled nodes, with the work and time they saved
+func (rw *RebalanceWatcher) followUp(job *models.Job, cluster *models.Cluster) {
+	signals, err := rw.rebalanceRepo.ListOpenSignals(job.ID)
+	if err != nil {
+		log.Printf("Failed to load rebalance signals of job %s: %v", job.ID, err)
+		return
+	}
+	if len(signals) == 0 {
+		return
+	}
+
+	inCluster := make(map[string]bool, len(cluster.Nodes))
+	for _, node := range cluster.Nodes {
+		inCluster[node.InstanceID] = true
+	}
+	var checkpoints []models.JobArtifact
+	for i := range signals {
+		signal := &signals[i]
+		if signal.CheckpointRequestedAt != nil && signal.CheckpointSavedAt == nil {
+			if checkpoints == nil {
+				checkpointType := models.ArtifactTypeCheckpoint
+				if checkpoints, err = rw.artifactRepo.GetJobArtifacts(job.ID, &checkpointType); err != nil {
+					log.Printf("Failed to load checkpoints of job %s: %v", job.ID, err)
+					return
+				}
+			}
+			rw.checkpointSaved(job, signal, checkpoints)
+		}
+		if !inCluster[signal.InstanceID] {
+			rw.interrupted(job, signal)
+		}
+	}
+}
+
+// checkpointSaved records the first checkpoint written after a request. The
+// work it saved is the training since the checkpoint before the request.
+func (rw *RebalanceWatcher) checkpointSaved(job *models.Job, signal *models.RebalanceSignal, checkpoints []models.JobArtifact) {
+	var saved *models.JobArtifact
+	var previous time.Time
+	if job.StartedAt != nil {
+		previous = *job.StartedAt
+	}
+	for i := range checkpoints {
+		checkpoint := &checkpoints[i]
+		switch {
+		case checkpoint.CreatedAt.Before(*signal.CheckpointRequestedAt):
+			if checkpoint.CreatedAt.After(previous) {
+				previous = checkpoint.CreatedAt
+			}
+		case saved == nil || checkpoint.CreatedAt.Before(saved.CreatedAt):
+			saved = checkpoint
+		}
+	}
+	if saved == nil {
+		return
+	}
+
+	var workSaved time.Duration
+	if !previous.IsZero() {
+		workSaved = saved.CreatedAt.Sub(previous)
+	}
+	if err := rw.rebalanceRepo.MarkCheckpointSaved(signal.ID, saved.URI, saved.CreatedAt, workSaved); err != nil {
+		log.Printf("Failed to record the checkpoint of job %s for %s: %v", job.ID, signal.InstanceID, err)
+		return
+	}
+	signal.CheckpointSavedAt = &saved.CreatedAt
+	signal.WorkSavedSeconds = int64(workSaved.Seconds())
+
+	meta := rebalanceMeta(signal)
+	meta["checkpoint_uri"] = saved.URI
+	meta["seconds_after_request"] = int64(saved.CreatedAt.Sub(*signal.CheckpointRequestedAt).Seconds())
+	meta["work_saved_seconds"] = signal.WorkSavedSeconds
+	rw.recordEvent(job.ID, "rebalance_checkpoint_saved", meta)
+}
+
+// interrupted records that a signalled node left the job's cluster: how much
+// warning the recommendation gave and how far ahead the replacement started
+func (rw *RebalanceWatcher) interrupted(job *models.Job, signal *models.RebalanceSignal) {
+	now := rw.now()
+	if err := rw.rebalanceRepo.MarkInterrupted(signal.ID, now); err != nil {
+		log.Printf("Failed to record the interruption of %s for job %s: %v", signal.InstanceID, job.ID, err)
+		return
+	}
+
+	meta := rebalanceMeta(signal)
+	meta["warning_seconds"] = int64(now.Sub(signal.NoticedAt).Seconds())
+	meta["checkpoint_saved"] = signal.CheckpointSavedAt != nil
+	if signal.CheckpointSavedAt != nil {
+		meta["work_saved_seconds"] = signal.WorkSavedSeconds
+	}
+	if signal.ReplacementStartedAt != nil {
+		meta["replacement_node"] = signal.ReplacementNodeID
+		meta["replacement_lead_seconds"] = int64(now.Sub(*signal.ReplacementStartedAt).Seconds())
+	}
+	rw.recordEvent(job.ID, "rebalance_interrupted", meta)
+}
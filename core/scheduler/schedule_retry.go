@@ -0,0 +1,97 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gpu-orchestrator/core/clock"
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/repository"
+)
+
+// ScheduleRetryPolicy bounds how often a pending job the optimizer places
+// nowhere is tried again. The wait doubles with every failed attempt.
+type ScheduleRetryPolicy struct {
+	MaxAttempts int           // Failed attempts before the job fails; 0 retries forever
+	BaseDelay   time.Duration // Wait after the first failed attempt
+	MaxDelay    time.Duration // Longest wait between attempts
+}
+
+// defaultScheduleRetry fails a job after 10 attempts, waiting 30s to 30m
+var defaultScheduleRetry = ScheduleRetryPolicy{MaxAttempts: 10, BaseDelay: 30 * time.Second, MaxDelay: 30 * time.Minute}
+
+// noAllocationMessage is the reason recorded with every failed attempt
+const noAllocationMessage = "no suitable allocation within budget"
+
+// SetScheduleRetry sets the policy for retrying jobs no allocation was found for
+func (s *Scheduler) SetScheduleRetry(policy ScheduleRetryPolicy) {
+	s.scheduleRetry = policy
+}
+
+// Delay returns the wait after the given number of failed attempts
+func (p ScheduleRetryPolicy) Delay(attempts int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempts && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// retryUnplaced records a failed scheduling attempt of a pending job and
+// backs it off, or fails the job once it is out of attempts. Only a status
+// conflict is returned; the job stays pending on other errors.
+func (s *Scheduler) retryUnplaced(job *models.Job, decision *models.AllocationDecision) error {
+	reason := noAllocationReason(decision)
+	attempts := job.ScheduleAttempts + 1
+	meta := map[string]interface{}{
+		"attempt":     attempts,
+		"message":     noAllocationMessage,
+		"explanation": reason.Message,
+	}
+	if s.scheduleRetry.MaxAttempts > 0 {
+		meta["max_attempts"] = s.scheduleRetry.MaxAttempts
+	}
+
+	if s.scheduleRetry.MaxAttempts > 0 && attempts >= s.scheduleRetry.MaxAttempts {
+		log.Printf("Failing job %s: %s after %d attempts", job.ID, noAllocationMessage, attempts)
+		err := s.jobRepo.UpdateJobStatus(job.ID, models.JobStatusPending, models.JobStatusFailed, "no_allocation", meta)
+		if err != nil && !errors.Is(err, repository.ErrStatusConflict) {
+			log.Printf("Failed to fail unplaced job %s: %v", job.ID, err)
+			return nil
+		}
+		return err
+	}
+
+	retryAt := clock.System.Now().Add(s.scheduleRetry.Delay(attempts))
+	meta["retry_at"] = retryAt
+	reason.Value = fmt.Sprintf("attempt %d, retrying at %s", attempts, retryAt.Format(time.RFC3339))
+	s.deferJob(job, reason)
+
+	err := s.jobRepo.RecordScheduleAttempt(job.ID, attempts, retryAt, "scheduling_attempt_failed", meta)
+	if err != nil && !errors.Is(err, repository.ErrStatusConflict) {
+		log.Printf("Failed to record scheduling attempt of job %s: %v", job.ID, err)
+		return nil
+	}
+	return err
+}
+
+// backingOff reports whether a pending job waits out the backoff of its
+// failed scheduling attempts
+func backingOff(job *models.Job) bool {
+	return job.NextScheduleAt != nil && clock.System.Now().Before(*job.NextScheduleAt)
+}
+
+// resetScheduleAttempts clears the failed scheduling attempts of a placed job
+func (s *Scheduler) resetScheduleAttempts(job *models.Job) {
+	if job.ScheduleAttempts == 0 && job.NextScheduleAt == nil {
+		return
+	}
+	if err := s.jobRepo.ResetScheduleAttempts(job.ID); err != nil {
+		log.Printf("Failed to reset scheduling attempts of job %s: %v", job.ID, err)
+	}
+}
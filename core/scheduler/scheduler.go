@@ -2,7 +2,9 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"log"
+	"sync"
 	"time"
 
 	"gpu-orchestrator/core/executor"
@@ -10,8 +12,28 @@ import (
 	"gpu-orchestrator/core/optimizer"
 	"gpu-orchestrator/core/repository"
 	"gpu-orchestrator/core/resource_manager"
+	"gpu-orchestrator/core/supervisor"
+	"gpu-orchestrator/storage"
 )
 
+// allocationCostTolerance is the relative drift allowed between an allocation's
+// estimated cost and price x count x estimated time
+const allocationCostTolerance = 0.01
+
+// defaultTick is how often the scheduler processes its queue by default
+const defaultTick = 5 * time.Second
+
+// pendingResyncInterval is how often the scheduler re-loads pending jobs from
+// the database by default, picking up jobs submitted through other replicas
+const pendingResyncInterval = 30 * time.Second
+
+// datasetVerifyTimeout bounds the pre-flight dataset check of one job
+const datasetVerifyTimeout = 2 * time.Minute
+
+// defaultPriceRecheck re-optimizes when prices rose more than 10% between
+// scheduling and provisioning
+var defaultPriceRecheck = optimizer.PriceRecheckPolicy{MaxDrift: 0.10, FreshFor: time.Minute}
+
 // Scheduler manages job scheduling and execution
 type Scheduler struct {
 	jobRepo        *repository.JobRepository
@@ -20,6 +42,24 @@ type Scheduler struct {
 	optimizer      *optimizer.AllocationOptimizer
 	provisioner    *resource_manager.Provisioner
 	executor       *executor.TrainingExecutor
+	elastic        *resource_manager.ElasticManager
+	hibernator     *resource_manager.Hibernator // Optional; nil terminates finished clusters
+	sessions       *SessionManager
+	tasks          *TaskRunner                     // Optional; runs multi_task jobs as independent tasks
+	datasets       *storage.DatasetVerifier        // Optional; checks datasets of jobs with data.verify
+	fairShare      *FairShare                      // Optional; orders the queue by team usage
+	gpuQuotas      *GPUQuotas                      // Optional; holds jobs over their GPU-hour quotas
+	suspender      *Suspender                      // Optional; suspends and resumes jobs on request
+	dataGravity    *optimizer.DataGravity          // Optional; leans jobs toward their team's data
+	ports          *resource_manager.PortAllocator // Optional; releases finished jobs' node ports
+	managed        *ManagedTrainingWatcher         // Follows jobs handed to managed training services
+	priceRecheck   optimizer.PriceRecheckPolicy
+	scheduleRetry  ScheduleRetryPolicy
+	tick           time.Duration              // How often the queue is processed
+	resync         time.Duration              // How often pending jobs are reloaded from the database
+	clusters       map[string]*models.Cluster // Clusters of running jobs, by job ID
+	released       map[string]bool            // Clusters of suspended jobs released before training finished, by cluster ID
+	clustersMu     sync.Mutex
 	stopChan       chan struct{}
 }
 
@@ -30,22 +70,125 @@ func NewScheduler(
 	optimizer *optimizer.AllocationOptimizer,
 	provisioner *resource_manager.Provisioner,
 	executor *executor.TrainingExecutor,
+	elastic *resource_manager.ElasticManager,
+	hibernator *resource_manager.Hibernator,
+	sessions *SessionManager,
 ) *Scheduler {
-	return &Scheduler{
+	s := &Scheduler{
 		jobRepo:        jobRepo,
 		allocationRepo: allocationRepo,
 		queue:          NewJobQueue(),
 		optimizer:      optimizer,
 		provisioner:    provisioner,
 		executor:       executor,
+		elastic:        elastic,
+		hibernator:     hibernator,
+		sessions:       sessions,
+		priceRecheck:   defaultPriceRecheck,
+		scheduleRetry:  defaultScheduleRetry,
+		tick:           defaultTick,
+		resync:         pendingResyncInterval,
+		clusters:       make(map[string]*models.Cluster),
+		released:       make(map[string]bool),
 		stopChan:       make(chan struct{}),
 	}
+	if executor != nil {
+		executor.SetCompletionHandler(s.completeCluster)
+	}
+	if sessions != nil {
+		sessions.SetReleaseHandler(s.releaseCluster)
+	}
+	return s
+}
+
+// SetPriceRecheck sets the policy for re-pricing allocations before provisioning
+func (s *Scheduler) SetPriceRecheck(policy optimizer.PriceRecheckPolicy) {
+	s.priceRecheck = policy
+}
+
+// SetTaskRunner sets the runner that fans multi_task jobs out into tasks
+func (s *Scheduler) SetTaskRunner(tasks *TaskRunner) {
+	s.tasks = tasks
+}
+
+// SetPortAllocator sets the allocator whose ports finished jobs release
+func (s *Scheduler) SetPortAllocator(ports *resource_manager.PortAllocator) {
+	s.ports = ports
+}
+
+// SetManagedTraining sets the watcher that follows jobs handed to managed
+// training services and releases their clusters once they end
+func (s *Scheduler) SetManagedTraining(managed *ManagedTrainingWatcher) {
+	s.managed = managed
+	managed.SetReleaseHandler(s.completeCluster)
+}
+
+// SetDatasetVerifier sets the verifier that checks datasets before provisioning
+func (s *Scheduler) SetDatasetVerifier(datasets *storage.DatasetVerifier) {
+	s.datasets = datasets
+}
+
+// SetFairShare orders queued jobs by their team's recent usage against its share
+func (s *Scheduler) SetFairShare(fairShare *FairShare) {
+	s.fairShare = fairShare
+	s.queue.SetFairShare(fairShare)
+}
+
+// FairShare returns the fair-share tracker, or nil
+func (s *Scheduler) FairShare() *FairShare {
+	return s.fairShare
+}
+
+// SetGPUQuotas holds jobs whose team or project quota cannot cover their
+// estimated GPU-hours
+func (s *Scheduler) SetGPUQuotas(quotas *GPUQuotas) {
+	s.gpuQuotas = quotas
+}
+
+// GPUQuotas returns the GPU-hour quota enforcer, or nil
+func (s *Scheduler) GPUQuotas() *GPUQuotas {
+	return s.gpuQuotas
+}
+
+// SetSuspender enables suspending and resuming jobs
+func (s *Scheduler) SetSuspender(suspender *Suspender) {
+	s.suspender = suspender
+}
+
+// Suspender returns the job suspender, or nil
+func (s *Scheduler) Suspender() *Suspender {
+	return s.suspender
+}
+
+// SetDataGravity leans jobs without a region constraint toward the regions
+// holding their team's data
+func (s *Scheduler) SetDataGravity(gravity *optimizer.DataGravity) {
+	s.dataGravity = gravity
+}
+
+// DataGravity returns the data gravity model, or nil
+func (s *Scheduler) DataGravity() *optimizer.DataGravity {
+	return s.dataGravity
+}
+
+// SetIntervals sets how often the queue is processed and how often pending
+// jobs are reloaded from the database
+func (s *Scheduler) SetIntervals(tick, resync time.Duration) {
+	s.tick = tick
+	s.resync = resync
+}
+
+// TaskRunner returns the runner for multi_task jobs, or nil
+func (s *Scheduler) TaskRunner() *TaskRunner {
+	return s.tasks
 }
 
 // Start starts the scheduler worker
 func (s *Scheduler) Start(ctx context.Context) {
-	ticker := time.NewTicker(5 * time.Second) // Check queue every 5 seconds
+	ticker := time.NewTicker(s.tick)
 	defer ticker.Stop()
+	resync := time.NewTicker(s.resync)
+	defer resync.Stop()
 
 	// Load pending jobs from database
 	s.loadPendingJobs(ctx)
@@ -56,7 +199,10 @@ func (s *Scheduler) Start(ctx context.Context) {
 			return
 		case <-s.stopChan:
 			return
+		case <-resync.C:
+			s.loadPendingJobs(ctx)
 		case <-ticker.C:
+			supervisor.Heartbeat(ctx)
 			s.processQueue(ctx)
 		}
 	}
@@ -67,6 +213,54 @@ func (s *Scheduler) Stop() {
 	close(s.stopChan)
 }
 
+// ElasticManager returns the manager for running elastic clusters
+func (s *Scheduler) ElasticManager() *resource_manager.ElasticManager {
+	return s.elastic
+}
+
+// SessionManager returns the manager for running interactive sessions
+func (s *Scheduler) SessionManager() *SessionManager {
+	return s.sessions
+}
+
+// EstimateStrategies scores a job's placement strategies as the scheduler
+// would place it now, best first, without placing anything
+func (s *Scheduler) EstimateStrategies(ctx context.Context, job *models.Job) ([]optimizer.Strategy, *models.AllocationDecision, error) {
+	s.applyDataGravity(job)
+	return s.optimizer.ScoreStrategies(ctx, job.Requirements, job.Constraints)
+}
+
+// CheckAdmission runs the optimizer's cache-only feasibility check for a job
+func (s *Scheduler) CheckAdmission(ctx context.Context, job *models.Job, timeout time.Duration) *optimizer.AdmissionResult {
+	return s.optimizer.CheckAdmission(ctx, job.Requirements, job.Constraints, timeout)
+}
+
+// WhatIf replays jobs through the optimizer against today's supply and a
+// modified one. Nothing is provisioned or recorded.
+func (s *Scheduler) WhatIf(ctx context.Context, jobs []*models.Job, scenario models.SupplyScenario) (*models.WhatIfResult, error) {
+	return s.optimizer.WhatIf(ctx, jobs, scenario)
+}
+
+// NodeLimits returns the per-cluster node limits used by the optimizer
+func (s *Scheduler) NodeLimits() *optimizer.NodeLimits {
+	return s.optimizer.NodeLimits()
+}
+
+// InstanceTypeRules returns the org-wide instance type lists used by the optimizer
+func (s *Scheduler) InstanceTypeRules() *optimizer.InstanceTypeRules {
+	return s.optimizer.InstanceTypeRules()
+}
+
+// InterruptionModel returns the spot interruption rates used by the optimizer
+func (s *Scheduler) InterruptionModel() *optimizer.InterruptionModel {
+	return s.optimizer.InterruptionModel()
+}
+
+// PerformanceMetrics returns the optimizer's throughput benchmarks
+func (s *Scheduler) PerformanceMetrics() *optimizer.PerformanceMetricsStore {
+	return s.optimizer.PerformanceMetrics()
+}
+
 // Enqueue adds a job to the queue
 func (s *Scheduler) Enqueue(job *models.Job) {
 	s.queue.Enqueue(job)
@@ -81,9 +275,52 @@ func (s *Scheduler) loadPendingJobs(_ context.Context) {
 		return
 	}
 
+	// Update rather than enqueue, so boosts set through other replicas apply
 	for _, job := range jobs {
-		s.queue.Enqueue(job)
+		s.queue.Update(job)
+	}
+}
+
+// Reprioritize applies a pending job's changed priority boost to the queue
+func (s *Scheduler) Reprioritize(job *models.Job) {
+	s.queue.Update(job)
+}
+
+// UpdateConstraints stores new constraints of a pending or scheduled job and
+// re-queues it under them. A scheduled job drops its planned allocations and
+// is placed again; its provisioning goroutine hits a status conflict and backs
+// off. Returns repository.ErrStatusConflict once provisioning has started.
+func (s *Scheduler) UpdateConstraints(job *models.Job, constraints models.JobConstraints, meta map[string]interface{}) error {
+	if err := s.jobRepo.UpdateJobConstraints(job.ID, job.Status, constraints, meta); err != nil {
+		return err
+	}
+	if job.Status == models.JobStatusScheduled {
+		if err := s.allocationRepo.ReplaceAllocations(job.ID, nil); err != nil {
+			log.Printf("Failed to drop allocations of rescheduled job %s: %v", job.ID, err)
+		}
 	}
+
+	job.Constraints = constraints
+	job.Status = models.JobStatusPending
+	s.queue.Update(job)
+	return nil
+}
+
+// ProjectedQueue returns the pending jobs in the order the scheduler would
+// pick them up. It is built from the database, so any replica can answer.
+func (s *Scheduler) ProjectedQueue() ([]QueuedJob, error) {
+	status := models.JobStatusPending
+	jobs, _, err := s.jobRepo.ListJobs("", &status, 100, "")
+	if err != nil {
+		return nil, err
+	}
+
+	projected := NewJobQueue()
+	projected.SetFairShare(s.fairShare)
+	for _, job := range jobs {
+		projected.Enqueue(job)
+	}
+	return projected.Snapshot(), nil
 }
 
 // processQueue processes jobs from the queue
@@ -105,9 +342,15 @@ func (s *Scheduler) processQueue(ctx context.Context) {
 		if freshJob.Status != models.JobStatusPending {
 			continue
 		}
+		// Backing off after failed scheduling attempts; the pending resync re-queues it
+		if backingOff(freshJob) {
+			continue
+		}
 
 		// Process job
-		if err := s.processJob(ctx, freshJob); err != nil {
+		if err := s.processJob(ctx, freshJob); errors.Is(err, repository.ErrStatusConflict) {
+			log.Printf("Skipping job %s: %v", freshJob.ID, err)
+		} else if err != nil {
 			log.Printf("Failed to process job %s: %v", freshJob.ID, err)
 			// Update job status to failed
 			s.jobRepo.UpdateJobStatus(freshJob.ID, freshJob.Status, models.JobStatusFailed, "scheduler_error", map[string]interface{}{
@@ -121,52 +364,267 @@ func (s *Scheduler) processQueue(ctx context.Context) {
 func (s *Scheduler) processJob(ctx context.Context, job *models.Job) error {
 	log.Printf("Processing job %s", job.ID)
 
+	// Fail fast on a missing or incomplete dataset, before anything is provisioned
+	if job.DatasetVerify != nil {
+		if ok, err := s.verifyDataset(ctx, job); err != nil || !ok {
+			return err
+		}
+	}
+
+	// Reserve the job's estimated GPU-hours; a job its quotas cannot cover waits
+	if s.gpuQuotas != nil {
+		reason, ok, err := s.gpuQuotas.Reserve(job)
+		if err != nil {
+			log.Printf("Failed to check GPU-hour quotas of job %s: %v", job.ID, err)
+			return nil
+		}
+		if !ok {
+			s.deferJob(job, reason)
+			return nil
+		}
+	}
+
+	// Reuse a hibernated cluster with the same requirements instead of provisioning
+	if s.hibernator != nil && !s.runsTasks(job) {
+		permit := func(alloc models.Allocation) bool {
+			return s.optimizer.PermitsAllocation(alloc, job.Requirements)
+		}
+		if hc := s.hibernator.Claim(job, permit); hc != nil {
+			return s.scheduleOnHibernated(ctx, job, hc)
+		}
+	}
+
 	// Step 1: Run optimizer to select allocation
-	allocations, err := s.optimizer.Optimize(ctx, job.Requirements, job.Constraints)
+	s.applyDataGravity(job)
+	allocations, decision, err := s.optimizer.OptimizeWithDecision(ctx, job.Requirements, job.Constraints)
+	if decision != nil {
+		if err := s.jobRepo.SetAllocationDecision(job.ID, decision); err != nil {
+			log.Printf("Failed to store allocation decision for job %s: %v", job.ID, err)
+		}
+	}
+	if errors.Is(err, optimizer.ErrNoPricing) {
+		s.deferJob(job, stalePricingReason())
+		return nil
+	}
+	if errors.Is(err, optimizer.ErrInstanceTypesExcluded) {
+		s.deferJob(job, instanceTypesReason(decision))
+		return nil
+	}
 	if err != nil {
 		return err
 	}
 
 	if len(allocations) == 0 {
-		return err
+		return s.retryUnplaced(job, decision)
 	}
 
 	// Step 2: Update job status to scheduled
 	if err := s.jobRepo.UpdateJobStatus(job.ID, models.JobStatusPending, models.JobStatusScheduled, "optimizer_selected_allocation", nil); err != nil {
 		return err
 	}
+	s.clearBoost(job)
+	s.clearWaitReasons(job)
+	s.resetScheduleAttempts(job)
 
-	// Step 3: Store allocations
-	for _, alloc := range allocations {
-		if err := s.allocationRepo.CreateAllocation(job.ID, alloc); err != nil {
+	// Step 3: Store allocations (planned until the provisioner launches them)
+	for i := range allocations {
+		if err := s.allocationRepo.CreateAllocation(job.ID, &allocations[i]); err != nil {
 			return err
 		}
 	}
+	s.checkAllocationEstimates(job, allocations)
 
 	// Step 4: Update job with selected provider/region in database
 	// This is done via allocations table, but we could also update jobs table
 	// For now, allocations table is sufficient
 
 	// Step 5: Trigger provisioning (async)
-	go s.provisionAndExecuteJob(ctx, job, allocations)
+	go s.provisionAndExecuteJob(ctx, job, allocations, time.Now(), nil)
 
 	return nil
 }
 
-// provisionAndExecuteJob provisions compute resources and executes training
-func (s *Scheduler) provisionAndExecuteJob(ctx context.Context, job *models.Job, allocations []models.Allocation) {
+// applyDataGravity sets the team's data gravity on the job's requirements
+// unless the job constrains its region or opted out with
+// constraints.data_gravity: false
+func (s *Scheduler) applyDataGravity(job *models.Job) {
+	job.Requirements.DataGravity = nil
+	if s.dataGravity == nil || job.TeamID == "" || job.Constraints.IgnoreDataGravity {
+		return
+	}
+	if len(job.Constraints.PreferredRegions) > 0 || job.Constraints.DataLocality == models.DataLocalityRequired {
+		return
+	}
+	profile, err := s.dataGravity.Profile(job.TeamID)
+	if err != nil {
+		log.Printf("Failed to compute data gravity for job %s: %v", job.ID, err)
+		return
+	}
+	job.Requirements.DataGravity = profile.Scores()
+}
+
+// verifyDataset runs the pre-flight dataset check and records the measured
+// size for the transfer cost estimate. It returns false when the job was
+// failed because of its dataset.
+func (s *Scheduler) verifyDataset(ctx context.Context, job *models.Job) (bool, error) {
+	if s.datasets == nil {
+		log.Printf("Job %s requests dataset verification but no object storage is configured; skipping", job.ID)
+		return true, nil
+	}
+
+	verifyCtx, cancel := context.WithTimeout(ctx, datasetVerifyTimeout)
+	defer cancel()
+
+	report, err := s.datasets.Verify(verifyCtx, job.DatasetURI, *job.DatasetVerify)
+	if err != nil {
+		log.Printf("Dataset verification failed for job %s: %v", job.ID, err)
+		meta := map[string]interface{}{
+			"dataset": job.DatasetURI,
+			"error":   err.Error(),
+		}
+		if report != nil {
+			meta["missing"] = report.Missing
+			meta["mismatched"] = report.Mismatched
+		}
+		return false, s.jobRepo.UpdateJobStatus(job.ID, models.JobStatusPending, models.JobStatusFailed, "dataset_verification_failed", meta)
+	}
+
+	job.Requirements.DatasetSizeGB = report.SizeGB()
+	if err := s.jobRepo.SetDatasetSize(job.ID, report.SizeGB(), map[string]interface{}{
+		"dataset":    job.DatasetURI,
+		"mode":       report.Mode,
+		"objects":    report.Objects,
+		"size_bytes": report.SizeBytes,
+		"size_exact": report.SizeExact,
+		"checked":    report.Checked,
+	}); err != nil {
+		log.Printf("Failed to record dataset size of job %s: %v", job.ID, err)
+	}
+	return true, nil
+}
+
+// clearBoost removes an operator priority boost once the job is scheduled
+func (s *Scheduler) clearBoost(job *models.Job) {
+	if job.PriorityBoost == 0 {
+		return
+	}
+	if err := s.jobRepo.SetPriorityBoost(job.ID, 0, "priority_boost_cleared", map[string]interface{}{
+		"boost": job.PriorityBoost,
+	}); err != nil {
+		log.Printf("Failed to clear priority boost of job %s: %v", job.ID, err)
+	}
+}
+
+// scheduleOnHibernated schedules a job onto a claimed hibernated cluster,
+// reusing its allocations (same instances, same prices)
+func (s *Scheduler) scheduleOnHibernated(ctx context.Context, job *models.Job, hc *resource_manager.HibernatedCluster) error {
+	allocations := make([]models.Allocation, len(hc.Allocations))
+	for i, alloc := range hc.Allocations {
+		alloc.EstimatedTime = optimizer.EstimatedDuration(job.Requirements)
+		alloc.EstimatedCost = alloc.ExpectedCost()
+		allocations[i] = alloc
+	}
+
+	if err := s.jobRepo.UpdateJobStatus(job.ID, models.JobStatusPending, models.JobStatusScheduled, "reusing_hibernated_cluster", map[string]interface{}{
+		"cluster_id":    hc.Cluster.ID,
+		"source_job_id": hc.SourceJobID,
+	}); err != nil {
+		return err
+	}
+	s.clearBoost(job)
+	s.clearWaitReasons(job)
+	s.resetScheduleAttempts(job)
+
+	for i := range allocations {
+		if err := s.allocationRepo.CreateAllocation(job.ID, &allocations[i]); err != nil {
+			return err
+		}
+	}
+	s.checkAllocationEstimates(job, allocations)
+
+	go s.provisionAndExecuteJob(ctx, job, allocations, time.Time{}, hc)
+
+	return nil
+}
+
+// checkAllocationEstimates records a warning event for allocations whose
+// estimated cost does not match price x count x estimated time
+func (s *Scheduler) checkAllocationEstimates(job *models.Job, allocations []models.Allocation) {
+	var mismatched []map[string]interface{}
+	for _, alloc := range allocations {
+		if alloc.CostConsistent(allocationCostTolerance) {
+			continue
+		}
+		mismatched = append(mismatched, map[string]interface{}{
+			"instance_type":   alloc.InstanceType,
+			"region":          alloc.Region,
+			"spot":            alloc.Spot,
+			"estimated_cost":  alloc.EstimatedCost,
+			"expected_cost":   alloc.ExpectedCost(),
+			"estimated_hours": alloc.EstimatedTime.Hours(),
+		})
+	}
+	if len(mismatched) == 0 {
+		return
+	}
+
+	log.Printf("Job %s: %d allocations have inconsistent cost estimates", job.ID, len(mismatched))
+	scheduled := models.JobStatusScheduled
+	if err := s.jobRepo.CreateJobEvent(job.ID, &scheduled, scheduled, "allocation_estimate_mismatch", map[string]interface{}{
+		"allocations": mismatched,
+		"tolerance":   allocationCostTolerance,
+	}); err != nil {
+		log.Printf("Failed to record allocation estimate warning for job %s: %v", job.ID, err)
+	}
+}
+
+// provisionAndExecuteJob provisions compute resources (or resumes a hibernated
+// cluster when hc is set) and executes training. pricedAt is when the
+// allocations were priced; zero forces a price re-check.
+func (s *Scheduler) provisionAndExecuteJob(ctx context.Context, job *models.Job, allocations []models.Allocation, pricedAt time.Time, hc *resource_manager.HibernatedCluster) {
 	log.Printf("Provisioning resources for job %s", job.ID)
 
 	// Update status to provisioning
 	if err := s.jobRepo.UpdateJobStatus(job.ID, models.JobStatusScheduled, models.JobStatusProvisioning, "starting_provisioning", nil); err != nil {
 		log.Printf("Failed to update job status: %v", err)
+		s.endAllocations(job.ID, models.AllocationTerminated, "job left scheduled before provisioning")
+		if hc != nil {
+			// The claimed hibernated cluster has no other owner
+			if err := s.provisioner.TerminateCluster(ctx, hc.Cluster); err != nil {
+				log.Printf("Failed to terminate hibernated cluster %s: %v", hc.Cluster.ID, err)
+			}
+		}
 		return
 	}
 
-	// Provision cluster
-	cluster, err := s.provisioner.ProvisionCluster(ctx, job, allocations)
+	// Resume the hibernated cluster, falling back to fresh provisioning
+	var cluster *models.Cluster
+	if hc != nil {
+		resumed, err := s.hibernator.Resume(ctx, hc)
+		if err != nil {
+			log.Printf("Job %s: %v; provisioning a new cluster", job.ID, err)
+		} else {
+			cluster = resumed
+			s.adoptCluster(cluster, allocations)
+		}
+	}
+
+	// Provision cluster at current prices
+	var err error
+	if cluster == nil {
+		var ok bool
+		if allocations, ok = s.recheckPrices(ctx, job, allocations, pricedAt); !ok {
+			return
+		}
+		if s.runsTasks(job) {
+			s.runTasks(ctx, job, allocations)
+			return
+		}
+		cluster, err = s.provisioner.ProvisionCluster(ctx, job, allocations)
+	}
 	if err != nil {
 		log.Printf("Failed to provision cluster: %v", err)
+		s.endAllocations(job.ID, models.AllocationFailed, err.Error())
 		s.jobRepo.UpdateJobStatus(job.ID, models.JobStatusProvisioning, models.JobStatusFailed, "provisioning_failed", map[string]interface{}{
 			"error": err.Error(),
 		})
@@ -178,17 +636,378 @@ func (s *Scheduler) provisionAndExecuteJob(ctx context.Context, job *models.Job,
 	// Update status to running
 	if err := s.jobRepo.UpdateJobStatus(job.ID, models.JobStatusProvisioning, models.JobStatusRunning, "provisioning_complete", nil); err != nil {
 		log.Printf("Failed to update job status: %v", err)
+		if errors.Is(err, repository.ErrStatusConflict) {
+			// Cancelled while provisioning; nothing will run on the cluster
+			if err := s.provisioner.TerminateCluster(ctx, cluster); err != nil {
+				log.Printf("Failed to terminate cluster %s for job %s: %v", cluster.ID, job.ID, err)
+			}
+		}
+		return
+	}
+
+	// Managed training services run the job themselves
+	if cluster.Backend == models.BackendManaged {
+		s.watchManaged(ctx, job, cluster, allocations)
 		return
 	}
 
 	// Execute training
 	if err := s.executor.ExecuteJob(ctx, job, cluster); err != nil {
 		log.Printf("Failed to execute training: %v", err)
-		s.jobRepo.UpdateJobStatus(job.ID, models.JobStatusRunning, models.JobStatusFailed, "execution_failed", map[string]interface{}{
+		reason := "execution_failed"
+		if errors.Is(err, executor.ErrDatasetDownload) {
+			reason = "dataset_download_failed"
+		}
+		s.jobRepo.UpdateJobStatus(job.ID, models.JobStatusRunning, models.JobStatusFailed, reason, map[string]interface{}{
 			"error": err.Error(),
 		})
+		// Nothing runs on the cluster
+		if err := s.provisioner.TerminateCluster(ctx, cluster); err != nil {
+			log.Printf("Failed to terminate cluster %s for job %s: %v", cluster.ID, job.ID, err)
+		}
 		return
 	}
 
+	s.trackCluster(job.ID, cluster)
+
+	// Hand elastic clusters to the elastic manager so they can be resized while running
+	if job.Requirements.Elastic != nil && s.elastic != nil {
+		if err := s.elastic.Register(job, cluster, allocations); err != nil {
+			log.Printf("Failed to register elastic cluster for job %s: %v", job.ID, err)
+		}
+	}
+
+	// Interactive sessions run until their TTL, idle stop or cancellation
+	if job.JobType == models.JobTypeInteractive && s.sessions != nil {
+		if err := s.sessions.Register(job, cluster, allocations); err != nil {
+			log.Printf("Failed to register session for job %s: %v", job.ID, err)
+		}
+	}
+
 	log.Printf("Job %s is now running", job.ID)
 }
+
+// watchManaged hands a job running on a managed training service to the
+// watcher, stopping the service's job when it cannot be followed
+func (s *Scheduler) watchManaged(ctx context.Context, job *models.Job, cluster *models.Cluster, allocations []models.Allocation) {
+	err := errors.New("managed training watcher not configured")
+	if s.managed != nil {
+		err = s.managed.Track(job, cluster, allocations)
+	}
+	if err == nil {
+		log.Printf("Job %s is now running on managed training job %s", job.ID, cluster.ServiceJobID)
+		return
+	}
+
+	log.Printf("Failed to watch managed training of job %s: %v", job.ID, err)
+	s.jobRepo.UpdateJobStatus(job.ID, models.JobStatusRunning, models.JobStatusFailed, "execution_failed", map[string]interface{}{
+		"error": err.Error(),
+	})
+	s.releaseCluster(ctx, job, cluster)
+}
+
+// runsTasks reports whether a job runs as independent tasks
+func (s *Scheduler) runsTasks(job *models.Job) bool {
+	return s.tasks != nil && job.Requirements.ExecutionMode == models.ModeMultiTask
+}
+
+// runTasks hands a multi_task job to the task runner, which provisions a
+// cluster per allocation and aggregates the job status
+func (s *Scheduler) runTasks(ctx context.Context, job *models.Job, allocations []models.Allocation) {
+	if err := s.tasks.Run(ctx, job, allocations); err != nil {
+		log.Printf("Failed to start tasks for job %s: %v", job.ID, err)
+		s.endAllocations(job.ID, models.AllocationFailed, err.Error())
+		s.jobRepo.UpdateJobStatus(job.ID, models.JobStatusProvisioning, models.JobStatusFailed, "task_fanout_failed", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+// recheckPrices re-prices allocations from the pricing cache right before
+// launch, persisting a swapped or repriced allocation. It fails the job and
+// returns false when no allocation fits the budget at current prices.
+func (s *Scheduler) recheckPrices(ctx context.Context, job *models.Job, allocations []models.Allocation, pricedAt time.Time) ([]models.Allocation, bool) {
+	recheck := s.optimizer.RecheckPrices(ctx, job.Requirements, job.Constraints, allocations, pricedAt, s.priceRecheck)
+	if !recheck.Checked {
+		return allocations, true
+	}
+
+	meta := map[string]interface{}{
+		"original_hourly_usd": recheck.OriginalHourly,
+		"current_hourly_usd":  recheck.CurrentHourly,
+		"drift":               recheck.Drift,
+		"projected_cost_usd":  recheck.ProjectedCost,
+		"max_drift":           s.priceRecheck.MaxDrift,
+	}
+
+	if recheck.OverBudget {
+		log.Printf("Job %s: allocation costs $%.2f at current prices, over budget $%.2f", job.ID, recheck.ProjectedCost, job.Constraints.MaxBudget)
+		meta["budget_usd"] = job.Constraints.MaxBudget
+		s.endAllocations(job.ID, models.AllocationFailed, "over budget at current prices")
+		if err := s.jobRepo.UpdateJobStatus(job.ID, models.JobStatusProvisioning, models.JobStatusFailed, "price_recheck_over_budget", meta); err != nil {
+			log.Printf("Failed to update job status: %v", err)
+		}
+		return nil, false
+	}
+
+	reason := ""
+	switch {
+	case recheck.Swapped:
+		reason = "allocation_swapped"
+	case recheck.CurrentHourly != recheck.OriginalHourly:
+		reason = "allocation_repriced"
+	default:
+		return allocations, true
+	}
+
+	if err := s.allocationRepo.ReplaceAllocations(job.ID, recheck.Allocations); err != nil {
+		// Provisioning must match the stored allocations
+		log.Printf("Failed to store rechecked allocations for job %s, keeping original: %v", job.ID, err)
+		return allocations, true
+	}
+	if recheck.Swapped && recheck.Decision != nil {
+		if err := s.jobRepo.SetAllocationDecision(job.ID, recheck.Decision); err != nil {
+			log.Printf("Failed to store allocation decision for job %s: %v", job.ID, err)
+		}
+	}
+
+	log.Printf("Job %s: %s (hourly $%.2f -> $%.2f)", job.ID, reason, recheck.OriginalHourly, recheck.CurrentHourly)
+	provisioning := models.JobStatusProvisioning
+	if err := s.jobRepo.CreateJobEvent(job.ID, &provisioning, provisioning, reason, meta); err != nil {
+		log.Printf("Failed to record %s event for job %s: %v", reason, job.ID, err)
+	}
+	return recheck.Allocations, true
+}
+
+// requeueStuck moves a stuck scheduled/provisioning job back to pending,
+// dropping its allocations so the optimizer runs again. A wedged provisioning
+// goroutine that wakes up later hits a status conflict and backs off.
+func (s *Scheduler) requeueStuck(jobID string, from models.JobStatus, meta map[string]interface{}) error {
+	if err := s.jobRepo.UpdateJobStatus(jobID, from, models.JobStatusPending, "stuck_requeued", meta); err != nil {
+		return err
+	}
+	if err := s.allocationRepo.ReplaceAllocations(jobID, nil); err != nil {
+		log.Printf("Failed to drop allocations of requeued job %s: %v", jobID, err)
+	}
+
+	job, err := s.jobRepo.GetJob(jobID)
+	if err != nil {
+		return err
+	}
+	s.Enqueue(job)
+	return nil
+}
+
+// migrateJob checkpoints a running job and puts it back in the queue, so it
+// is placed again and resumes from its latest checkpoint. Like cancellation,
+// the execution path releases the old cluster when its next status update
+// conflicts; elastic clusters are released here.
+func (s *Scheduler) migrateJob(ctx context.Context, jobID string, meta map[string]interface{}) error {
+	if err := s.jobRepo.UpdateJobStatus(jobID, models.JobStatusRunning, models.JobStatusCheckpointing, "migration_checkpoint", meta); err != nil {
+		return err
+	}
+	if err := s.jobRepo.UpdateJobStatus(jobID, models.JobStatusCheckpointing, models.JobStatusPending, "migration_requeued", meta); err != nil {
+		return err
+	}
+	if err := s.allocationRepo.ReplaceAllocations(jobID, nil); err != nil {
+		log.Printf("Failed to drop allocations of migrated job %s: %v", jobID, err)
+	}
+
+	job, err := s.jobRepo.GetJob(jobID)
+	if err != nil {
+		return err
+	}
+	if s.elastic != nil {
+		if ec, ok := s.elastic.Get(jobID); ok {
+			s.releaseCluster(ctx, job, ec.Cluster)
+		}
+	}
+	s.Enqueue(job)
+	return nil
+}
+
+// releaseSuspended releases the cluster of a job being suspended, like a
+// finished job's cluster, and returns whether the scheduler tracked one. The
+// training it interrupts does not release the cluster again when it ends.
+func (s *Scheduler) releaseSuspended(ctx context.Context, job *models.Job) bool {
+	cluster, ok := s.RunningClusters()[job.ID]
+	if !ok {
+		return false
+	}
+	s.clustersMu.Lock()
+	s.released[cluster.ID] = true
+	s.clustersMu.Unlock()
+
+	s.releaseCluster(ctx, job, cluster)
+	return true
+}
+
+// resumeJob puts a suspended job back in the queue. Its allocations are
+// dropped so the optimizer places it again at current prices, and it
+// restarts from its latest checkpoint like a migrated job.
+func (s *Scheduler) resumeJob(jobID string, meta map[string]interface{}) error {
+	if err := s.jobRepo.UpdateJobStatus(jobID, models.JobStatusSuspended, models.JobStatusPending, "resume_requested", meta); err != nil {
+		return err
+	}
+	if err := s.jobRepo.SetSuspendRequested(jobID, nil); err != nil {
+		log.Printf("Failed to clear suspension of resumed job %s: %v", jobID, err)
+	}
+	if err := s.allocationRepo.ReplaceAllocations(jobID, nil); err != nil {
+		log.Printf("Failed to drop allocations of resumed job %s: %v", jobID, err)
+	}
+
+	job, err := s.jobRepo.GetJob(jobID)
+	if err != nil {
+		return err
+	}
+	s.Enqueue(job)
+	return nil
+}
+
+// failStuck fails a stuck job and releases a cluster the scheduler still
+// tracks for it. Provisioning and execution paths release their own cluster
+// when their next status update conflicts.
+func (s *Scheduler) failStuck(ctx context.Context, jobID string, from models.JobStatus, meta map[string]interface{}) error {
+	if err := s.jobRepo.UpdateJobStatus(jobID, from, models.JobStatusFailed, "stuck_timeout", meta); err != nil {
+		return err
+	}
+	// Allocations that launched stay active until torn down, so a wedged
+	// launch that never releases them shows up as an orphan
+	s.endAllocations(jobID, models.AllocationFailed, "stuck_timeout")
+
+	if s.elastic != nil {
+		if ec, ok := s.elastic.Get(jobID); ok {
+			job, err := s.jobRepo.GetJob(jobID)
+			if err != nil {
+				return err
+			}
+			s.releaseCluster(ctx, job, ec.Cluster)
+		}
+	}
+	return nil
+}
+
+// releaseCluster hands a finished job's cluster back: hibernated for a
+// follow-up job when enabled, terminated otherwise
+func (s *Scheduler) releaseCluster(ctx context.Context, job *models.Job, cluster *models.Cluster) {
+	s.untrackCluster(job.ID)
+
+	// Job-scoped identities go once no instance runs as them
+	defer func() {
+		if err := s.provisioner.ReleaseJobIdentities(ctx, job.ID); err != nil {
+			log.Printf("Failed to release identities of job %s, cleanup will retry: %v", job.ID, err)
+		}
+		if s.ports != nil {
+			if err := s.ports.ReleaseJob(job.ID); err != nil {
+				log.Printf("%v; cleanup will retry", err)
+			}
+		}
+	}()
+
+	// Elastic clusters may have been resized since execution started
+	if s.elastic != nil {
+		if ec, ok := s.elastic.Get(job.ID); ok {
+			cluster = ec.Cluster
+			s.elastic.Unregister(job.ID)
+		}
+	}
+
+	if s.hibernator != nil {
+		allocations, err := s.allocationRepo.GetAllocationsByJobID(job.ID)
+		if err == nil {
+			if err := s.hibernator.Release(ctx, job, cluster, allocations); err != nil {
+				log.Printf("Failed to release cluster %s for job %s: %v", cluster.ID, job.ID, err)
+				return
+			}
+			// A hibernated cluster belongs to the pool, not the job
+			s.endAllocations(job.ID, models.AllocationTerminated, "cluster released")
+			return
+		}
+		log.Printf("Failed to load allocations for job %s, terminating cluster: %v", job.ID, err)
+	}
+
+	if err := s.provisioner.TerminateCluster(ctx, cluster); err != nil {
+		log.Printf("Failed to terminate cluster %s for job %s: %v", cluster.ID, job.ID, err)
+		return
+	}
+	s.endAllocations(job.ID, models.AllocationTerminated, "cluster terminated")
+}
+
+// completeCluster releases the cluster of a job whose training ended, unless
+// it was released when the job was suspended
+func (s *Scheduler) completeCluster(ctx context.Context, job *models.Job, cluster *models.Cluster) {
+	s.clustersMu.Lock()
+	released := s.released[cluster.ID]
+	delete(s.released, cluster.ID)
+	s.clustersMu.Unlock()
+
+	if released {
+		log.Printf("Cluster %s of job %s was released on suspension", cluster.ID, job.ID)
+		return
+	}
+	s.releaseCluster(ctx, job, cluster)
+}
+
+// trackCluster remembers the cluster a job runs on until it is released
+func (s *Scheduler) trackCluster(jobID string, cluster *models.Cluster) {
+	s.clustersMu.Lock()
+	defer s.clustersMu.Unlock()
+	s.clusters[jobID] = cluster
+}
+
+// untrackCluster forgets the cluster of a job
+func (s *Scheduler) untrackCluster(jobID string) {
+	s.clustersMu.Lock()
+	defer s.clustersMu.Unlock()
+	delete(s.clusters, jobID)
+}
+
+// RunningClusters returns the clusters of running jobs by job ID. Elastic
+// clusters are returned as last resized; tasks of multi_task jobs are not
+// included.
+func (s *Scheduler) RunningClusters() map[string]*models.Cluster {
+	s.clustersMu.Lock()
+	clusters := make(map[string]*models.Cluster, len(s.clusters))
+	for jobID, cluster := range s.clusters {
+		clusters[jobID] = cluster
+	}
+	s.clustersMu.Unlock()
+
+	if s.elastic != nil {
+		for jobID := range clusters {
+			if ec, ok := s.elastic.Get(jobID); ok {
+				clusters[jobID] = ec.Cluster
+			}
+		}
+	}
+	return clusters
+}
+
+// adoptCluster records a resumed hibernated cluster's instances as the job's
+// allocations, so tearing the cluster down terminates them
+func (s *Scheduler) adoptCluster(cluster *models.Cluster, allocations []models.Allocation) {
+	cluster.AllocationIDs = nil
+	for _, alloc := range allocations {
+		if alloc.ID == 0 {
+			continue
+		}
+		cluster.AllocationIDs = append(cluster.AllocationIDs, alloc.ID)
+		if err := s.allocationRepo.UpdateAllocationStatus(alloc.ID, models.AllocationActive, alloc.Count, "resumed hibernated cluster"); err != nil {
+			log.Printf("Failed to record allocation %d as active: %v", alloc.ID, err)
+		}
+	}
+}
+
+// endAllocations moves a job's unfinished allocations to a terminal status:
+// failed affects those not launched yet, terminated all of them
+func (s *Scheduler) endAllocations(jobID string, status models.AllocationStatus, detail string) {
+	var err error
+	if status == models.AllocationFailed {
+		err = s.allocationRepo.FailAllocations(jobID, detail)
+	} else {
+		err = s.allocationRepo.TerminateAllocations(jobID, detail)
+	}
+	if err != nil {
+		log.Printf("Failed to mark allocations of job %s %s: %v", jobID, status, err)
+	}
+}
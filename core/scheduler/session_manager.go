@@ -0,0 +1,277 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gpu-orchestrator/core/executor"
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/core/supervisor"
+)
+
+// Errors returned by SessionManager.Extend
+var (
+	ErrSessionNotFound   = errors.New("no running session for job")
+	ErrSessionOverBudget = errors.New("extension exceeds job budget")
+)
+
+// sessionExpiryWarning is how long before teardown the expiry warning event is recorded
+const sessionExpiryWarning = 15 * time.Minute
+
+// idleUtilizationThreshold is the average GPU utilization (percent) below which a session counts as idle
+const idleUtilizationThreshold = 5.0
+
+// SessionCostTracker accrues cost for running sessions
+type SessionCostTracker interface {
+	TrackJob(jobID string, allocations []models.Allocation, accrued float64)
+	StopTracking(jobID string)
+}
+
+// Session is a running interactive session
+type Session struct {
+	Job          *models.Job
+	Cluster      *models.Cluster
+	Allocations  []models.Allocation
+	ExpiresAt    time.Time
+	LastActiveAt time.Time // Last GPU utilization report above the idle threshold
+	Warned       bool      // Expiry warning recorded for the current ExpiresAt
+}
+
+// SessionManager enforces interactive session TTLs: it warns before
+// teardown, stops sessions at expiry or when idle, and handles extensions
+type SessionManager struct {
+	jobRepo     *repository.JobRepository
+	costTracker SessionCostTracker
+	release     executor.CompletionHandler
+	idleTimeout time.Duration // 0 disables idle auto-stop
+	interval    time.Duration
+	sessions    map[string]*Session
+	mu          sync.Mutex
+}
+
+// NewSessionManager creates a new session manager. costTracker may be nil.
+func NewSessionManager(jobRepo *repository.JobRepository, costTracker SessionCostTracker, idleTimeout time.Duration) *SessionManager {
+	return &SessionManager{
+		jobRepo:     jobRepo,
+		costTracker: costTracker,
+		idleTimeout: idleTimeout,
+		interval:    30 * time.Second,
+		sessions:    make(map[string]*Session),
+	}
+}
+
+// SetInterval sets how often session deadlines are checked (default 30 seconds)
+func (sm *SessionManager) SetInterval(interval time.Duration) {
+	sm.interval = interval
+}
+
+// SetReleaseHandler registers the callback that releases a finished session's cluster
+func (sm *SessionManager) SetReleaseHandler(handler executor.CompletionHandler) {
+	sm.release = handler
+}
+
+// Start enforces session deadlines in the background
+func (sm *SessionManager) Start(ctx context.Context) {
+	ticker := time.NewTicker(sm.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			supervisor.Heartbeat(ctx)
+			sm.CheckSessions(ctx, time.Now())
+		}
+	}
+}
+
+// Register starts managing a session whose endpoint has been recorded
+func (sm *SessionManager) Register(job *models.Job, cluster *models.Cluster, allocations []models.Allocation) error {
+	if job.SessionExpiresAt == nil {
+		return fmt.Errorf("job %s has no session expiry", job.ID)
+	}
+
+	sm.mu.Lock()
+	sm.sessions[job.ID] = &Session{
+		Job:          job,
+		Cluster:      cluster,
+		Allocations:  allocations,
+		ExpiresAt:    *job.SessionExpiresAt,
+		LastActiveAt: time.Now(),
+	}
+	sm.mu.Unlock()
+
+	if sm.costTracker != nil {
+		sm.costTracker.TrackJob(job.ID, allocations, job.CostRunningUSD)
+	}
+	return nil
+}
+
+// Get returns a copy of a running session
+func (sm *SessionManager) Get(jobID string) (Session, bool) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	session, ok := sm.sessions[jobID]
+	if !ok {
+		return Session{}, false
+	}
+	return *session, true
+}
+
+// RecordUtilization records a GPU utilization report (percent) from a session node
+func (sm *SessionManager) RecordUtilization(jobID string, utilization float64) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	session, ok := sm.sessions[jobID]
+	if !ok {
+		return ErrSessionNotFound
+	}
+	if utilization >= idleUtilizationThreshold {
+		session.LastActiveAt = time.Now()
+	}
+	return nil
+}
+
+// Extend moves a session's expiry forward by d. The extension is refused when
+// the cost accrued so far plus the cluster's hourly rate until the new expiry
+// exceeds the job budget.
+func (sm *SessionManager) Extend(jobID string, d time.Duration) (time.Time, error) {
+	if d <= 0 {
+		return time.Time{}, fmt.Errorf("extension must be positive, got %s", d)
+	}
+
+	sm.mu.Lock()
+	session, ok := sm.sessions[jobID]
+	if !ok {
+		sm.mu.Unlock()
+		return time.Time{}, ErrSessionNotFound
+	}
+	expiresAt := session.ExpiresAt.Add(d)
+	allocations := session.Allocations
+	sm.mu.Unlock()
+
+	job, err := sm.jobRepo.GetJob(jobID)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to fetch job: %w", err)
+	}
+	projected := job.CostRunningUSD + hourlyRate(allocations)*time.Until(expiresAt).Hours()
+	if job.Constraints.MaxBudget > 0 && projected > job.Constraints.MaxBudget {
+		return time.Time{}, fmt.Errorf("%w: projected $%.2f, budget $%.2f", ErrSessionOverBudget, projected, job.Constraints.MaxBudget)
+	}
+
+	if err := sm.jobRepo.UpdateSessionExpiry(jobID, expiresAt); err != nil {
+		return time.Time{}, fmt.Errorf("failed to store session expiry: %w", err)
+	}
+
+	sm.mu.Lock()
+	if session, ok := sm.sessions[jobID]; ok {
+		session.ExpiresAt = expiresAt
+		session.Warned = false
+	}
+	sm.mu.Unlock()
+
+	running := models.JobStatusRunning
+	if err := sm.jobRepo.CreateJobEvent(jobID, &running, running, "session_extended", map[string]interface{}{
+		"extension":     d.String(),
+		"expires_at":    expiresAt,
+		"projected_usd": projected,
+	}); err != nil {
+		log.Printf("Failed to record session_extended event for job %s: %v", jobID, err)
+	}
+
+	return expiresAt, nil
+}
+
+// CheckSessions tears down expired, idle and cancelled sessions and records
+// expiry warnings
+func (sm *SessionManager) CheckSessions(ctx context.Context, now time.Time) {
+	sm.mu.Lock()
+	sessions := make([]Session, 0, len(sm.sessions))
+	for _, session := range sm.sessions {
+		sessions = append(sessions, *session)
+	}
+	sm.mu.Unlock()
+
+	for _, session := range sessions {
+		sm.checkSession(ctx, session, now)
+	}
+}
+
+// checkSession applies the TTL and idle policy to one session
+func (sm *SessionManager) checkSession(ctx context.Context, session Session, now time.Time) {
+	jobID := session.Job.ID
+	job, err := sm.jobRepo.GetJob(jobID)
+	if err != nil {
+		log.Printf("Failed to fetch session job %s: %v", jobID, err)
+		return
+	}
+
+	// Cancelled or failed elsewhere: only the cluster is left to clean up
+	if !job.Status.IsRunning() {
+		sm.teardown(ctx, session)
+		return
+	}
+
+	switch {
+	case !now.Before(session.ExpiresAt):
+		sm.finish(ctx, session, "session_expired", map[string]interface{}{
+			"expires_at": session.ExpiresAt,
+		})
+	case job.Session != nil && job.Session.IdleStop && sm.idleTimeout > 0 && now.Sub(session.LastActiveAt) >= sm.idleTimeout:
+		sm.finish(ctx, session, "session_idle_stopped", map[string]interface{}{
+			"last_active_at": session.LastActiveAt,
+			"idle_timeout":   sm.idleTimeout.String(),
+		})
+	case !session.Warned && session.ExpiresAt.Sub(now) <= sessionExpiryWarning:
+		running := models.JobStatusRunning
+		if err := sm.jobRepo.CreateJobEvent(jobID, &running, running, "session_expiring", map[string]interface{}{
+			"expires_at": session.ExpiresAt,
+		}); err != nil {
+			log.Printf("Failed to record session_expiring event for job %s: %v", jobID, err)
+			return
+		}
+		sm.mu.Lock()
+		if s, ok := sm.sessions[jobID]; ok && s.ExpiresAt.Equal(session.ExpiresAt) {
+			s.Warned = true
+		}
+		sm.mu.Unlock()
+	}
+}
+
+// finish completes a session's job and releases its cluster
+func (sm *SessionManager) finish(ctx context.Context, session Session, reason string, meta map[string]interface{}) {
+	err := sm.jobRepo.UpdateJobStatus(session.Job.ID, models.JobStatusRunning, models.JobStatusCompleted, reason, meta)
+	switch {
+	case errors.Is(err, repository.ErrStatusConflict):
+		// Cancelled meanwhile; still tear the session down
+		log.Printf("Session job %s is no longer running: %v", session.Job.ID, err)
+	case err != nil:
+		log.Printf("Failed to complete session job %s: %v", session.Job.ID, err)
+		return
+	default:
+		log.Printf("Session for job %s ended: %s", session.Job.ID, reason)
+	}
+	sm.teardown(ctx, session)
+}
+
+// teardown stops managing a session, stops its cost accrual and releases its cluster
+func (sm *SessionManager) teardown(ctx context.Context, session Session) {
+	sm.mu.Lock()
+	delete(sm.sessions, session.Job.ID)
+	sm.mu.Unlock()
+
+	if sm.costTracker != nil {
+		sm.costTracker.StopTracking(session.Job.ID)
+	}
+	if sm.release != nil {
+		sm.release(ctx, session.Job, session.Cluster)
+	}
+}
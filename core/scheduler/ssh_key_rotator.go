@@ -0,0 +1,80 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"gpu-orchestrator/core/clock"
+	"gpu-orchestrator/core/executor"
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/supervisor"
+)
+
+// SSHKeyRotator rotates the SSH keys of running clusters once they reach a
+// maximum age, so long jobs do not keep one key for their whole life
+type SSHKeyRotator struct {
+	keys      *executor.ClusterKeys
+	scheduler *Scheduler // Tracks the clusters of running jobs; nil rotates nothing
+	maxAge    time.Duration
+	now       func() time.Time
+}
+
+// NewSSHKeyRotator creates a rotator for keys older than maxAge
+func NewSSHKeyRotator(keys *executor.ClusterKeys, maxAge time.Duration) *SSHKeyRotator {
+	return &SSHKeyRotator{
+		keys:   keys,
+		maxAge: maxAge,
+		now:    clock.System.Now,
+	}
+}
+
+// SetScheduler sets the scheduler whose running clusters are rotated
+func (kr *SSHKeyRotator) SetScheduler(s *Scheduler) {
+	kr.scheduler = s
+}
+
+// Start checks key ages every interval until ctx is done
+func (kr *SSHKeyRotator) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			supervisor.Heartbeat(ctx)
+			kr.Check(ctx)
+		}
+	}
+}
+
+// Check rotates every running cluster's key that is due once
+func (kr *SSHKeyRotator) Check(ctx context.Context) {
+	if kr.scheduler == nil {
+		return
+	}
+	for jobID, cluster := range kr.scheduler.RunningClusters() {
+		if ctx.Err() != nil {
+			return
+		}
+		if cluster.Backend != models.BackendVM {
+			continue
+		}
+		key, err := kr.keys.Active(cluster.ID)
+		if err != nil {
+			log.Printf("Failed to load SSH key of cluster %s: %v", cluster.ID, err)
+			continue
+		}
+		if key == nil || key.ActivatedAt == nil || kr.now().Sub(*key.ActivatedAt) < kr.maxAge {
+			continue
+		}
+		rotated, err := kr.keys.Rotate(ctx, cluster, jobID, models.ActorSystem)
+		if err != nil {
+			log.Printf("Scheduled rotation of SSH key %s of cluster %s failed: %v", key.Fingerprint, cluster.ID, err)
+			continue
+		}
+		log.Printf("Rotated SSH key of cluster %s from %s to %s", cluster.ID, key.Fingerprint, rotated.Fingerprint)
+	}
+}
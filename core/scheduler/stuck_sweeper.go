@@ -0,0 +1,245 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"gpu-orchestrator/core/clock"
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/core/supervisor"
+)
+
+// StuckAction is what the sweeper does once a job stays stuck past escalation
+type StuckAction string
+
+const (
+	StuckActionAlert   StuckAction = "alert"   // Record the escalation only
+	StuckActionRequeue StuckAction = "requeue" // Move scheduled/provisioning jobs back to pending
+	StuckActionFail    StuckAction = "fail"    // Fail the job and release its cluster
+)
+
+// StuckPolicy configures the stuck-state sweeper
+type StuckPolicy struct {
+	Thresholds map[models.JobStatus]time.Duration // Warn after this long in a status
+	Escalation int                                // Act after Escalation x the threshold
+	Action     StuckAction
+}
+
+// ParseStuckAction validates a configured stuck action
+func ParseStuckAction(action string) (StuckAction, error) {
+	switch StuckAction(action) {
+	case StuckActionAlert, StuckActionRequeue, StuckActionFail:
+		return StuckAction(action), nil
+	}
+	return "", fmt.Errorf("invalid stuck action %q (want alert, requeue or fail)", action)
+}
+
+// StuckSweeper flags jobs that stay too long in a transitional status: a
+// stuck_warning event after the threshold, then a stuck_escalated event and
+// the configured action after the escalation threshold. Each happens once per
+// episode (the time since the job last entered its status).
+type StuckSweeper struct {
+	jobRepo   *repository.JobRepository
+	scheduler *Scheduler // Runs the requeue and fail actions; set with SetScheduler
+	policy    StuckPolicy
+	now       func() time.Time
+
+	mu          sync.Mutex
+	warnings    map[models.JobStatus]int
+	escalations map[stuckEscalation]int
+	stuck       map[models.JobStatus]int // Jobs past their threshold at the last sweep
+}
+
+// stuckEscalation counts escalations per status and action
+type stuckEscalation struct {
+	status models.JobStatus
+	action StuckAction
+}
+
+// NewStuckSweeper creates a new stuck-state sweeper
+func NewStuckSweeper(jobRepo *repository.JobRepository, policy StuckPolicy) *StuckSweeper {
+	if policy.Escalation < 1 {
+		policy.Escalation = 1
+	}
+	return &StuckSweeper{
+		jobRepo:     jobRepo,
+		policy:      policy,
+		now:         clock.System.Now,
+		warnings:    make(map[models.JobStatus]int),
+		escalations: make(map[stuckEscalation]int),
+		stuck:       make(map[models.JobStatus]int),
+	}
+}
+
+// SetScheduler sets the scheduler that requeues and fails stuck jobs
+func (ss *StuckSweeper) SetScheduler(scheduler *Scheduler) {
+	ss.scheduler = scheduler
+}
+
+// Start sweeps every interval until ctx is done
+func (ss *StuckSweeper) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			supervisor.Heartbeat(ctx)
+			if err := ss.Sweep(ctx); err != nil {
+				log.Printf("Stuck job sweep failed: %v", err)
+			}
+		}
+	}
+}
+
+// Sweep checks every job in a watched status once
+func (ss *StuckSweeper) Sweep(ctx context.Context) error {
+	statuses := make([]models.JobStatus, 0, len(ss.policy.Thresholds))
+	for status := range ss.policy.Thresholds {
+		statuses = append(statuses, status)
+	}
+
+	ages, err := ss.jobRepo.ListStatusAges(statuses)
+	if err != nil {
+		return fmt.Errorf("failed to list job status ages: %w", err)
+	}
+
+	now := ss.now()
+	stuck := make(map[models.JobStatus]int)
+	for _, age := range ages {
+		threshold := ss.threshold(age)
+		if threshold <= 0 {
+			continue
+		}
+		elapsed := now.Sub(age.Since)
+		if elapsed < threshold {
+			continue
+		}
+		stuck[age.Status]++
+
+		meta := map[string]interface{}{
+			"since":             age.Since,
+			"elapsed_seconds":   int(elapsed.Seconds()),
+			"threshold_seconds": int(threshold.Seconds()),
+		}
+		if !age.Warned {
+			ss.warn(age, meta)
+		}
+		if !age.Escalated && elapsed >= threshold*time.Duration(ss.policy.Escalation) {
+			ss.escalate(ctx, age, meta)
+		}
+	}
+
+	ss.mu.Lock()
+	ss.stuck = stuck
+	ss.mu.Unlock()
+	return nil
+}
+
+// threshold returns the job's own threshold for its status, or the default
+func (ss *StuckSweeper) threshold(age repository.JobStatusAge) time.Duration {
+	if threshold, ok := age.StuckAfter[age.Status]; ok {
+		return threshold
+	}
+	return ss.policy.Thresholds[age.Status]
+}
+
+// warn records that the job passed its threshold
+func (ss *StuckSweeper) warn(age repository.JobStatusAge, meta map[string]interface{}) {
+	log.Printf("Job %s has been %s for %ds (threshold %ds)", age.JobID, age.Status, meta["elapsed_seconds"], meta["threshold_seconds"])
+	if err := ss.jobRepo.CreateJobEvent(age.JobID, &age.Status, age.Status, "stuck_warning", meta); err != nil {
+		log.Printf("Failed to record stuck warning for job %s: %v", age.JobID, err)
+		return
+	}
+
+	ss.mu.Lock()
+	ss.warnings[age.Status]++
+	ss.mu.Unlock()
+}
+
+// escalate records the escalation, then takes the configured action. The
+// escalation event is written first so the action runs at most once.
+func (ss *StuckSweeper) escalate(ctx context.Context, age repository.JobStatusAge, meta map[string]interface{}) {
+	action := ss.policy.Action
+	if action == StuckActionRequeue && age.Status == models.JobStatusCheckpointing {
+		// A checkpointing job has training state on its cluster; only alert
+		action = StuckActionAlert
+	}
+
+	meta["action"] = action
+	if err := ss.jobRepo.CreateJobEvent(age.JobID, &age.Status, age.Status, "stuck_escalated", meta); err != nil {
+		log.Printf("Failed to record stuck escalation for job %s: %v", age.JobID, err)
+		return
+	}
+	log.Printf("Job %s stuck in %s past escalation; action: %s", age.JobID, age.Status, action)
+
+	ss.mu.Lock()
+	ss.escalations[stuckEscalation{status: age.Status, action: action}]++
+	ss.mu.Unlock()
+
+	if ss.scheduler == nil {
+		return
+	}
+	var err error
+	switch action {
+	case StuckActionRequeue:
+		err = ss.scheduler.requeueStuck(age.JobID, age.Status, meta)
+	case StuckActionFail:
+		err = ss.scheduler.failStuck(ctx, age.JobID, age.Status, meta)
+	}
+	if errors.Is(err, repository.ErrStatusConflict) {
+		log.Printf("Job %s moved on before the stuck action: %v", age.JobID, err)
+	} else if err != nil {
+		log.Printf("Stuck action %s failed for job %s: %v", action, age.JobID, err)
+	}
+}
+
+// PrometheusMetrics returns the sweeper's metrics in Prometheus format
+func (ss *StuckSweeper) PrometheusMetrics() string {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	var metrics string
+
+	metrics += "# HELP gpu_jobs_stuck Jobs past their stuck threshold at the last sweep\n"
+	metrics += "# TYPE gpu_jobs_stuck gauge\n"
+	for _, status := range sortedStatuses(ss.policy.Thresholds) {
+		metrics += fmt.Sprintf("gpu_jobs_stuck{status=\"%s\"} %d\n", status, ss.stuck[status])
+	}
+
+	metrics += "# HELP gpu_job_stuck_warnings_total Stuck warnings recorded\n"
+	metrics += "# TYPE gpu_job_stuck_warnings_total counter\n"
+	for _, status := range sortedStatuses(ss.policy.Thresholds) {
+		metrics += fmt.Sprintf("gpu_job_stuck_warnings_total{status=\"%s\"} %d\n", status, ss.warnings[status])
+	}
+
+	metrics += "# HELP gpu_job_stuck_escalations_total Stuck escalations by status and action\n"
+	metrics += "# TYPE gpu_job_stuck_escalations_total counter\n"
+	for _, status := range sortedStatuses(ss.policy.Thresholds) {
+		for _, action := range []StuckAction{StuckActionAlert, StuckActionRequeue, StuckActionFail} {
+			if count, ok := ss.escalations[stuckEscalation{status: status, action: action}]; ok {
+				metrics += fmt.Sprintf("gpu_job_stuck_escalations_total{status=\"%s\",action=\"%s\"} %d\n", status, action, count)
+			}
+		}
+	}
+
+	return metrics
+}
+
+// sortedStatuses returns the watched statuses in a stable order
+func sortedStatuses(thresholds map[models.JobStatus]time.Duration) []models.JobStatus {
+	statuses := make([]models.JobStatus, 0, len(thresholds))
+	for status := range thresholds {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i] < statuses[j] })
+	return statuses
+}
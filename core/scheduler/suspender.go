@@ -0,0 +1,261 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gpu-orchestrator/core/clock"
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/core/supervisor"
+)
+
+// ErrNotSuspendable is returned for jobs that run in a way suspension does
+// not support
+var ErrNotSuspendable = errors.New("job cannot be suspended")
+
+// Suspender suspends and resumes jobs. Suspending a running job moves it to
+// checkpointing and asks its training wrapper for a checkpoint through GET
+// /v1/jobs/{id}/checkpoint-request. Once a checkpoint newer than the request
+// is recorded, or the wait times out, the job moves to suspended and its
+// cluster is released, which ends its allocations and stops its cost.
+// Resuming puts the job back in the queue to be placed at current prices; it
+// restarts from its newest checkpoint.
+type Suspender struct {
+	jobRepo      *repository.JobRepository
+	artifactRepo *repository.ArtifactRepository
+	scheduler    *Scheduler
+	timeout      time.Duration // Longest wait for the requested checkpoint; 0 = none
+	now          func() time.Time
+}
+
+// NewSuspender creates a suspender that waits up to timeout for the
+// checkpoint of a suspension
+func NewSuspender(jobRepo *repository.JobRepository, artifactRepo *repository.ArtifactRepository, timeout time.Duration) *Suspender {
+	return &Suspender{
+		jobRepo:      jobRepo,
+		artifactRepo: artifactRepo,
+		timeout:      timeout,
+		now:          clock.System.Now,
+	}
+}
+
+// SetScheduler sets the scheduler that releases suspended jobs' clusters and
+// queues resumed jobs
+func (sp *Suspender) SetScheduler(s *Scheduler) {
+	sp.scheduler = s
+}
+
+// Start finishes suspensions every interval until ctx is done
+func (sp *Suspender) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			supervisor.Heartbeat(ctx)
+			if err := sp.Sweep(ctx); err != nil {
+				log.Printf("Suspension sweep failed: %v", err)
+			}
+		}
+	}
+}
+
+// Suspend requests the suspension of a running job. With release, the job's
+// GPU-hour reservations are dropped; otherwise it keeps holding them while
+// suspended. The warning of the result is set when resuming is expected to
+// lose training progress.
+func (sp *Suspender) Suspend(job *models.Job, release bool, actor string) (*models.JobSuspension, error) {
+	if job.Status != models.JobStatusRunning {
+		return nil, fmt.Errorf("%w: job %s is %s, not running", repository.ErrStatusConflict, job.ID, job.Status)
+	}
+	if job.JobType == models.JobTypeInteractive || job.Requirements.ExecutionMode == models.ModeMultiTask {
+		return nil, fmt.Errorf("%w: interactive sessions and multi_task jobs are stopped instead", ErrNotSuspendable)
+	}
+	latest, err := sp.latestCheckpoint(job.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := sp.now()
+	if err := sp.jobRepo.SetSuspendRequested(job.ID, &now); err != nil {
+		return nil, fmt.Errorf("failed to record suspension: %w", err)
+	}
+	err = sp.jobRepo.UpdateJobStatus(job.ID, models.JobStatusRunning, models.JobStatusCheckpointing, "suspend_requested", map[string]interface{}{
+		"actor":                      actor,
+		"release_reservation":        release,
+		"checkpoint_timeout_seconds": int(sp.timeout.Seconds()),
+	})
+	if err != nil {
+		if clearErr := sp.jobRepo.SetSuspendRequested(job.ID, nil); clearErr != nil {
+			log.Printf("Failed to clear suspension of job %s: %v", job.ID, clearErr)
+		}
+		return nil, err
+	}
+	log.Printf("Job %s: suspension requested by %s", job.ID, actor)
+
+	suspension := &models.JobSuspension{
+		JobID:              job.ID,
+		Status:             models.JobStatusCheckpointing,
+		SuspendRequestedAt: &now,
+		Warning:            sp.suspendWarning(job, latest),
+	}
+	if latest != nil {
+		suspension.CheckpointURI = latest.URI
+		suspension.CheckpointAt = &latest.CreatedAt
+	}
+	if release && sp.scheduler != nil && sp.scheduler.GPUQuotas() != nil {
+		if err := sp.scheduler.GPUQuotas().Release(job.ID); err != nil {
+			log.Printf("Failed to release GPU-hour reservations of job %s: %v", job.ID, err)
+		} else {
+			suspension.ReservationReleased = true
+		}
+	}
+	return suspension, nil
+}
+
+// Resume puts a suspended job back in the queue
+func (sp *Suspender) Resume(job *models.Job, actor string) (*models.JobSuspension, error) {
+	if job.Status != models.JobStatusSuspended {
+		return nil, fmt.Errorf("%w: job %s is %s, not suspended", repository.ErrStatusConflict, job.ID, job.Status)
+	}
+	if sp.scheduler == nil {
+		return nil, fmt.Errorf("no scheduler to queue job %s", job.ID)
+	}
+	latest, err := sp.latestCheckpoint(job.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := map[string]interface{}{
+		"actor": actor,
+	}
+	if latest != nil {
+		meta["checkpoint_uri"] = latest.URI
+	}
+	if err := sp.scheduler.resumeJob(job.ID, meta); err != nil {
+		return nil, err
+	}
+	log.Printf("Job %s resumed by %s", job.ID, actor)
+
+	resumption := &models.JobSuspension{
+		JobID:              job.ID,
+		Status:             models.JobStatusPending,
+		SuspendRequestedAt: job.SuspendRequestedAt,
+		Warning:            resumeWarning(job, latest),
+	}
+	if latest != nil {
+		resumption.CheckpointURI = latest.URI
+		resumption.CheckpointAt = &latest.CreatedAt
+	}
+	return resumption, nil
+}
+
+// Sweep suspends every job whose requested checkpoint was recorded or whose
+// wait for it timed out
+func (sp *Suspender) Sweep(ctx context.Context) error {
+	suspending, err := sp.jobRepo.ListSuspending()
+	if err != nil {
+		return fmt.Errorf("failed to list suspending jobs: %w", err)
+	}
+
+	now := sp.now()
+	for _, pending := range suspending {
+		if ctx.Err() != nil {
+			return nil
+		}
+		latest, err := sp.latestCheckpoint(pending.JobID)
+		if err != nil {
+			log.Printf("Failed to check suspension checkpoint of job %s: %v", pending.JobID, err)
+			continue
+		}
+		saved := latest != nil && !latest.CreatedAt.Before(pending.RequestedAt)
+		if !saved && now.Sub(pending.RequestedAt) < sp.timeout {
+			continue
+		}
+		sp.finish(ctx, pending, latest, saved, now)
+	}
+	return nil
+}
+
+// finish moves a job to suspended and releases its cluster
+func (sp *Suspender) finish(ctx context.Context, pending repository.JobSuspending, latest *models.JobArtifact, saved bool, now time.Time) {
+	meta := map[string]interface{}{
+		"requested_at":     pending.RequestedAt,
+		"checkpoint_saved": saved,
+		"waited_seconds":   int(now.Sub(pending.RequestedAt).Seconds()),
+	}
+	if latest != nil {
+		meta["checkpoint_uri"] = latest.URI
+		meta["checkpoint_at"] = latest.CreatedAt
+	}
+	err := sp.jobRepo.UpdateJobStatus(pending.JobID, models.JobStatusCheckpointing, models.JobStatusSuspended, "suspended", meta)
+	if errors.Is(err, repository.ErrStatusConflict) {
+		return // Cancelled or finished meanwhile; released like any other such job
+	}
+	if err != nil {
+		log.Printf("Failed to suspend job %s: %v", pending.JobID, err)
+		return
+	}
+	log.Printf("Job %s suspended (checkpoint saved: %t)", pending.JobID, saved)
+
+	job, err := sp.jobRepo.GetJob(pending.JobID)
+	if err != nil {
+		log.Printf("Failed to load suspended job %s to release its cluster: %v", pending.JobID, err)
+		return
+	}
+	if sp.scheduler == nil || !sp.scheduler.releaseSuspended(ctx, job) {
+		log.Printf("Suspended job %s has no tracked cluster; the orphan cleanup terminates its instances", job.ID)
+	}
+}
+
+// latestCheckpoint returns the job's newest checkpoint artifact, or nil.
+// Artifacts are listed newest first.
+func (sp *Suspender) latestCheckpoint(jobID string) (*models.JobArtifact, error) {
+	checkpointType := models.ArtifactTypeCheckpoint
+	artifacts, err := sp.artifactRepo.GetJobArtifacts(jobID, &checkpointType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load checkpoints: %w", err)
+	}
+	if len(artifacts) == 0 {
+		return nil, nil
+	}
+	return &artifacts[0], nil
+}
+
+// suspendWarning explains how much progress resuming a job being suspended
+// is expected to lose, or returns ""
+func (sp *Suspender) suspendWarning(job *models.Job, latest *models.JobArtifact) string {
+	writesCheckpoints := latest != nil || job.Checkpointing != nil ||
+		(job.DataAccess != nil && job.DataAccess.OutputURI != "")
+	switch {
+	case !writesCheckpoints:
+		return "The job records no checkpoints and writes no output prefix; resuming restarts it from the beginning"
+	case sp.timeout > 0:
+		return ""
+	case latest == nil:
+		return "Suspension does not wait for a checkpoint and none is recorded yet; resuming restarts the job from the beginning"
+	default:
+		return fmt.Sprintf("Suspension does not wait for a checkpoint; resuming continues from the one written %s ago and loses the training since",
+			sp.now().Sub(latest.CreatedAt).Round(time.Second))
+	}
+}
+
+// resumeWarning explains how much progress resuming a suspended job loses,
+// or returns ""
+func resumeWarning(job *models.Job, latest *models.JobArtifact) string {
+	if latest == nil {
+		return "No checkpoint was recorded; the job restarts from the beginning"
+	}
+	if job.SuspendRequestedAt != nil && latest.CreatedAt.Before(*job.SuspendRequestedAt) {
+		return fmt.Sprintf("The newest checkpoint was written %s before the suspension; the training since is lost",
+			job.SuspendRequestedAt.Sub(latest.CreatedAt).Round(time.Second))
+	}
+	return ""
+}
@@ -0,0 +1,307 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"gpu-orchestrator/core/executor"
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/core/resource_manager"
+)
+
+// ErrTaskNotRunning is returned by TaskRunner.FailTask for tasks without a running attempt
+var ErrTaskNotRunning = errors.New("task has no running attempt")
+
+// TaskCostTracker accrues cost per task of multi_task jobs
+type TaskCostTracker interface {
+	TrackTask(jobID string, index int, allocation models.Allocation, accrued float64)
+	StopTrackingTask(jobID string, index int) float64
+	StopTracking(jobID string)
+}
+
+// runningTask is a task attempt with a provisioned cluster
+type runningTask struct {
+	task    models.JobTask
+	cluster *models.Cluster
+}
+
+// TaskRunner runs multi_task jobs as independent tasks, one per allocation.
+// Each task has its own cluster, cost and retries: a failed task is torn down
+// and retried without affecting its siblings, and the job's status is
+// aggregated from its tasks.
+type TaskRunner struct {
+	taskRepo    *repository.TaskRepository
+	jobRepo     *repository.JobRepository
+	provisioner *resource_manager.Provisioner
+	executor    *executor.TrainingExecutor
+	costTracker TaskCostTracker
+	running     map[string]*runningTask // Keyed by job ID and task index
+	mu          sync.Mutex
+	statusMu    sync.Mutex // Serializes job status changes derived from tasks
+}
+
+// NewTaskRunner creates a new task runner. costTracker may be nil.
+func NewTaskRunner(
+	taskRepo *repository.TaskRepository,
+	jobRepo *repository.JobRepository,
+	provisioner *resource_manager.Provisioner,
+	executor *executor.TrainingExecutor,
+	costTracker TaskCostTracker,
+) *TaskRunner {
+	tr := &TaskRunner{
+		taskRepo:    taskRepo,
+		jobRepo:     jobRepo,
+		provisioner: provisioner,
+		executor:    executor,
+		costTracker: costTracker,
+		running:     make(map[string]*runningTask),
+	}
+	if executor != nil {
+		executor.SetTaskHandler(tr.finishAttempt)
+	}
+	return tr
+}
+
+// runningKey identifies a task in the running map
+func runningKey(jobID string, index int) string {
+	return fmt.Sprintf("%s/%d", jobID, index)
+}
+
+// Run fans a provisioning multi_task job out into one task per allocation
+// and starts them
+func (tr *TaskRunner) Run(ctx context.Context, job *models.Job, allocations []models.Allocation) error {
+	if job.PlacementSpread == models.SpreadAZ {
+		tr.provisioner.SpreadZones(ctx, allocations)
+	}
+
+	tasks, err := tr.taskRepo.CreateTasks(job.ID, allocations)
+	if err != nil {
+		return fmt.Errorf("failed to create tasks: %w", err)
+	}
+
+	log.Printf("Job %s fanned out into %d tasks", job.ID, len(tasks))
+	for _, task := range tasks {
+		go tr.runAttempt(ctx, job, task)
+	}
+	return nil
+}
+
+// FailTask ends the running attempt of a task after one of its nodes failed.
+// The task's cluster is torn down and the task retried per the job's policy;
+// sibling tasks keep running.
+func (tr *TaskRunner) FailTask(ctx context.Context, jobID string, index int, reason string) error {
+	tr.mu.Lock()
+	rt, ok := tr.running[runningKey(jobID, index)]
+	tr.mu.Unlock()
+	if !ok {
+		return ErrTaskNotRunning
+	}
+
+	job, err := tr.jobRepo.GetJob(jobID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch job: %w", err)
+	}
+	tr.finishAttempt(ctx, job, rt.task, rt.cluster, errors.New(reason))
+	return nil
+}
+
+// runAttempt provisions a fresh cluster for the task and starts it
+func (tr *TaskRunner) runAttempt(ctx context.Context, job *models.Job, task models.JobTask) {
+	attempt, err := tr.taskRepo.StartAttempt(job.ID, task.Index)
+	if err != nil {
+		log.Printf("Failed to start task %d of job %s: %v", task.Index, job.ID, err)
+		return
+	}
+	task.Attempts = attempt
+	task.Status = models.JobStatusProvisioning
+
+	cluster, err := tr.provisioner.ProvisionTaskCluster(ctx, job, &task)
+	if err != nil {
+		tr.finishAttempt(ctx, job, task, nil, fmt.Errorf("provisioning failed: %w", err))
+		return
+	}
+
+	task.Status = models.JobStatusRunning
+	task.ClusterID = &cluster.ID
+	tr.mu.Lock()
+	tr.running[runningKey(job.ID, task.Index)] = &runningTask{task: task, cluster: cluster}
+	tr.mu.Unlock()
+
+	if err := tr.taskRepo.SetTaskRunning(job.ID, task.Index, cluster.ID); err != nil {
+		log.Printf("Failed to record running task %d of job %s: %v", task.Index, job.ID, err)
+	}
+	if tr.costTracker != nil {
+		tr.costTracker.TrackTask(job.ID, task.Index, task.Allocation, task.CostUSD)
+	}
+	tr.markJobRunning(job, task)
+
+	if err := tr.executor.ExecuteTask(ctx, job, task, cluster); err != nil {
+		tr.finishAttempt(ctx, job, task, cluster, fmt.Errorf("execution failed: %w", err))
+	}
+}
+
+// finishAttempt ends a task attempt: it settles the task's cost, tears down
+// only the task's cluster, then retries the task or records its final status
+// and re-aggregates the job. Outcomes of attempts that already ended (e.g. a
+// late success report after a node failure) are ignored.
+func (tr *TaskRunner) finishAttempt(ctx context.Context, job *models.Job, task models.JobTask, cluster *models.Cluster, attemptErr error) {
+	key := runningKey(job.ID, task.Index)
+	if cluster != nil {
+		tr.mu.Lock()
+		rt, ok := tr.running[key]
+		if !ok || rt.task.Attempts != task.Attempts {
+			tr.mu.Unlock()
+			log.Printf("Ignoring outcome of ended attempt %d of task %d of job %s", task.Attempts, task.Index, job.ID)
+			return
+		}
+		delete(tr.running, key)
+		tr.mu.Unlock()
+
+		if tr.costTracker != nil {
+			task.CostUSD = tr.costTracker.StopTrackingTask(job.ID, task.Index)
+			if err := tr.taskRepo.UpdateTaskCost(job.ID, task.Index, task.CostUSD); err != nil {
+				log.Printf("Failed to store cost of task %d of job %s: %v", task.Index, job.ID, err)
+			}
+		}
+
+		// Teardown is scoped to this task's cluster
+		if err := tr.provisioner.TerminateCluster(ctx, cluster); err != nil {
+			log.Printf("Failed to terminate cluster %s of task %d of job %s: %v", cluster.ID, task.Index, job.ID, err)
+		}
+	}
+
+	current, err := tr.jobRepo.GetJob(job.ID)
+	if err != nil {
+		log.Printf("Failed to fetch job %s after task %d ended: %v", job.ID, task.Index, err)
+		return
+	}
+
+	policy := models.DefaultTaskPolicy
+	if job.TaskPolicy != nil {
+		policy = *job.TaskPolicy
+	}
+	jobActive := current.Status == models.JobStatusProvisioning || current.Status.IsRunning()
+
+	status, reason, taskError := models.JobStatusCompleted, "task_completed", ""
+	if attemptErr != nil {
+		taskError = attemptErr.Error()
+		switch {
+		case !jobActive:
+			status, reason = models.JobStatusCancelled, "task_cancelled"
+		case task.Attempts <= policy.MaxRetries:
+			status, reason = models.JobStatusPending, "task_retrying"
+		default:
+			status, reason = models.JobStatusFailed, "task_failed"
+		}
+	}
+
+	if err := tr.taskRepo.FinishTask(job.ID, task.Index, status, taskError); err != nil {
+		log.Printf("Failed to record status of task %d of job %s: %v", task.Index, job.ID, err)
+	}
+
+	meta := map[string]interface{}{
+		"task":     task.Index,
+		"attempt":  task.Attempts,
+		"cost_usd": task.CostUSD,
+	}
+	if task.ClusterID != nil {
+		meta["cluster_id"] = *task.ClusterID
+	}
+	if taskError != "" {
+		meta["error"] = taskError
+	}
+	if reason == "task_retrying" {
+		meta["max_retries"] = policy.MaxRetries
+	}
+	log.Printf("Job %s: %s (task %d, attempt %d)", job.ID, reason, task.Index, task.Attempts)
+	if err := tr.jobRepo.CreateJobEvent(job.ID, &current.Status, current.Status, reason, meta); err != nil {
+		log.Printf("Failed to record %s event for job %s: %v", reason, job.ID, err)
+	}
+
+	if status == models.JobStatusPending {
+		go tr.runAttempt(ctx, job, task)
+		return
+	}
+	tr.aggregate(job, policy)
+}
+
+// markJobRunning moves the job to running when its first task starts
+func (tr *TaskRunner) markJobRunning(job *models.Job, task models.JobTask) {
+	tr.statusMu.Lock()
+	defer tr.statusMu.Unlock()
+
+	current, err := tr.jobRepo.GetJob(job.ID)
+	if err != nil {
+		log.Printf("Failed to fetch job %s: %v", job.ID, err)
+		return
+	}
+	if current.Status != models.JobStatusProvisioning {
+		return
+	}
+	if err := tr.jobRepo.UpdateJobStatus(job.ID, models.JobStatusProvisioning, models.JobStatusRunning, "task_running", map[string]interface{}{
+		"task": task.Index,
+	}); err != nil {
+		log.Printf("Failed to update job status: %v", err)
+	}
+}
+
+// aggregate sets the job's final status once none of its tasks will run again
+func (tr *TaskRunner) aggregate(job *models.Job, policy models.TaskPolicy) {
+	tr.statusMu.Lock()
+	defer tr.statusMu.Unlock()
+
+	tasks, err := tr.taskRepo.ListTasks(job.ID)
+	if err != nil {
+		log.Printf("Failed to list tasks of job %s: %v", job.ID, err)
+		return
+	}
+	status := policy.Aggregate(tasks)
+	if status == models.JobStatusRunning {
+		return
+	}
+
+	current, err := tr.jobRepo.GetJob(job.ID)
+	if err != nil {
+		log.Printf("Failed to fetch job %s: %v", job.ID, err)
+		return
+	}
+	if current.Status != models.JobStatusProvisioning && !current.Status.IsRunning() {
+		// Cancelled, or another task already finished the job
+		return
+	}
+
+	completed, failed, cost := 0, 0, 0.0
+	for _, task := range tasks {
+		switch task.Status {
+		case models.JobStatusCompleted:
+			completed++
+		case models.JobStatusFailed:
+			failed++
+		}
+		cost += task.CostUSD
+	}
+
+	reason := "tasks_completed"
+	if status == models.JobStatusFailed {
+		reason = "tasks_failed"
+	}
+	if err := tr.jobRepo.UpdateJobStatus(job.ID, current.Status, status, reason, map[string]interface{}{
+		"tasks":       len(tasks),
+		"completed":   completed,
+		"failed":      failed,
+		"aggregation": policy.Aggregation,
+		"cost_usd":    cost,
+	}); err != nil {
+		log.Printf("Failed to update job status: %v", err)
+		return
+	}
+	log.Printf("Job %s %s: %d/%d tasks completed", job.ID, status, completed, len(tasks))
+
+	if tr.costTracker != nil {
+		tr.costTracker.StopTracking(job.ID)
+	}
+}
@@ -0,0 +1,142 @@
+package scheduler
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"gpu-orchestrator/core/clock"
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/optimizer"
+)
+
+// deferJob records why a pass left a pending job in the queue. The job is
+// picked up again when pending jobs are reloaded.
+func (s *Scheduler) deferJob(job *models.Job, reason models.WaitReason) {
+	reason.RecordedAt = clock.System.Now()
+	log.Printf("Deferring job %s: %s", job.ID, reason.Message)
+	if err := s.jobRepo.SetWaitReasons(job.ID, []models.WaitReason{reason}); err != nil {
+		log.Printf("Failed to record wait reason for job %s: %v", job.ID, err)
+	}
+}
+
+// clearWaitReasons drops the recorded wait reasons of a scheduled job
+func (s *Scheduler) clearWaitReasons(job *models.Job) {
+	if err := s.jobRepo.SetWaitReasons(job.ID, nil); err != nil {
+		log.Printf("Failed to clear wait reasons of job %s: %v", job.ID, err)
+	}
+}
+
+// stalePricingReason is recorded when there is no fresh price to optimize with
+func stalePricingReason() models.WaitReason {
+	return models.WaitReason{
+		Code:    models.WaitStalePricing,
+		Message: "No GPU prices were refreshed within the last hour; the job waits for the next pricing refresh",
+		Value:   "0 priced instances",
+	}
+}
+
+// noAllocationReason is recorded when the optimizer placed the job nowhere
+func noAllocationReason(decision *models.AllocationDecision) models.WaitReason {
+	reason := models.WaitReason{
+		Code:    models.WaitNoAllocation,
+		Message: "The optimizer found no allocation for the job",
+	}
+	if decision != nil {
+		reason.Message = optimizer.ExplainDecision(decision)
+		reason.Value = fmt.Sprintf("%d strategies evaluated", len(decision.Strategies))
+	}
+	return reason
+}
+
+// instanceTypesReason is recorded when the instance type lists excluded every
+// instance that met the job's requirements
+func instanceTypesReason(decision *models.AllocationDecision) models.WaitReason {
+	reason := models.WaitReason{
+		Code:    models.WaitInstanceTypes,
+		Message: "The org or job instance type allow/deny lists exclude every suitable instance; the job waits until the lists change",
+	}
+	if decision != nil {
+		reason.Value = fmt.Sprintf("%d instances excluded", decision.ExcludedInstances)
+	}
+	return reason
+}
+
+// WhyPending returns what keeps a pending or scheduled job from starting:
+// its place in the queue and team fair share, worked out now, and the
+// reasons the scheduler recorded when it last deferred the job. Jobs in
+// other states have no wait reasons.
+func (s *Scheduler) WhyPending(job *models.Job) ([]models.WaitReason, error) {
+	now := clock.System.Now()
+	switch job.Status {
+	case models.JobStatusScheduled:
+		return []models.WaitReason{{
+			Code:       models.WaitProvisioning,
+			Message:    "Allocations were selected; provisioning has not started yet",
+			Value:      "scheduled " + now.Sub(job.UpdatedAt).Round(time.Second).String() + " ago",
+			RecordedAt: now,
+		}}, nil
+	case models.JobStatusPending:
+	default:
+		return nil, nil
+	}
+
+	var reasons []models.WaitReason
+	queued, err := s.ProjectedQueue()
+	if err != nil {
+		return nil, fmt.Errorf("failed to project queue: %w", err)
+	}
+	for i, item := range queued {
+		if item.Job.ID != job.ID {
+			continue
+		}
+		if i > 0 {
+			reasons = append(reasons, models.WaitReason{
+				Code:       models.WaitQueuePosition,
+				Message:    fmt.Sprintf("%d jobs are ahead in the queue", i),
+				Value:      fmt.Sprintf("position %d of %d", i+1, len(queued)),
+				RecordedAt: now,
+			})
+		}
+		if reason, ok := s.fairShareReason(job, item.FairShare, now); ok {
+			reasons = append(reasons, reason)
+		}
+		break
+	}
+
+	recorded, err := s.jobRepo.GetWaitReasons(job.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load wait reasons: %w", err)
+	}
+	reasons = append(reasons, recorded...)
+
+	if len(reasons) == 0 {
+		reasons = append(reasons, models.WaitReason{
+			Code:       models.WaitSchedulerPass,
+			Message:    "Nothing blocks the job; the scheduler picks it up on its next pass",
+			Value:      "passes every " + s.tick.String(),
+			RecordedAt: now,
+		})
+	}
+	return reasons, nil
+}
+
+// fairShareReason describes a queue position lowered by the team's usage
+func (s *Scheduler) fairShareReason(job *models.Job, adjustment float64, now time.Time) (models.WaitReason, bool) {
+	if s.fairShare == nil || adjustment <= 0 {
+		return models.WaitReason{}, false
+	}
+	for _, share := range s.fairShare.Report().Teams {
+		if share.TeamID != job.TeamID {
+			continue
+		}
+		return models.WaitReason{
+			Code: models.WaitFairShare,
+			Message: fmt.Sprintf("Team %s used %.0f%% of recent GPU-hours against a %.0f%% share, so its jobs are moved back in the queue",
+				share.TeamID, share.Usage*100, share.Share*100),
+			Value:      fmt.Sprintf("%.1f GPU-hours, %.2fx its share", share.GPUHours, share.Ratio),
+			RecordedAt: now,
+		}, true
+	}
+	return models.WaitReason{}, false
+}
@@ -0,0 +1,136 @@
+// Package secrets encrypts material the orchestrator has to keep, such as
+// per-cluster SSH private keys, with envelope encryption: every value is
+// sealed under its own random data key, and only the data key is wrapped
+// with the master key.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// sealedVersion prefixes sealed values so the format can change later
+const sealedVersion = "v1"
+
+// ErrSealed is returned when a sealed value is malformed, was sealed under
+// another master key or was tampered with
+var ErrSealed = errors.New("sealed value cannot be opened")
+
+// Keyring seals and opens values under a 256-bit master key
+type Keyring struct {
+	master cipher.AEAD
+}
+
+// NewKeyring creates a keyring from a 32-byte master key
+func NewKeyring(masterKey []byte) (*Keyring, error) {
+	if len(masterKey) != 32 {
+		return nil, fmt.Errorf("master key must be 32 bytes, got %d", len(masterKey))
+	}
+	master, err := newGCM(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Keyring{master: master}, nil
+}
+
+// ParseMasterKey decodes a base64 master key, e.g. from openssl rand -base64 32
+func ParseMasterKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("master key is not base64: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("master key must decode to 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
+// Seal encrypts plaintext under a fresh data key. context is authenticated
+// but not encrypted: Open fails unless given the same context, so a sealed
+// value cannot be moved to another record.
+func (k *Keyring) Seal(plaintext, context []byte) (string, error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", fmt.Errorf("failed to generate data key: %w", err)
+	}
+	data, err := newGCM(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	wrapped, err := seal(k.master, dataKey, context)
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := seal(data, plaintext, context)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join([]string{
+		sealedVersion,
+		base64.RawStdEncoding.EncodeToString(wrapped),
+		base64.RawStdEncoding.EncodeToString(ciphertext),
+	}, "."), nil
+}
+
+// Open decrypts a value sealed with the same master key and context
+func (k *Keyring) Open(sealed string, context []byte) ([]byte, error) {
+	parts := strings.Split(sealed, ".")
+	if len(parts) != 3 || parts[0] != sealedVersion {
+		return nil, ErrSealed
+	}
+	wrapped, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrSealed
+	}
+	ciphertext, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrSealed
+	}
+
+	dataKey, err := open(k.master, wrapped, context)
+	if err != nil {
+		return nil, err
+	}
+	data, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return open(data, ciphertext, context)
+}
+
+// newGCM returns AES-256-GCM under key
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext under a random nonce and prepends the nonce
+func seal(aead cipher.AEAD, plaintext, context []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, context), nil
+}
+
+// open reverses seal
+func open(aead cipher.AEAD, sealed, context []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrSealed
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, context)
+	if err != nil {
+		return nil, ErrSealed
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,64 @@
+package secrets
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"strings"
+)
+
+// SSHKeyPair is a freshly generated Ed25519 SSH keypair
+type SSHKeyPair struct {
+	PrivateKeyPEM []byte // PKCS #8 PEM, as read by ssh.ParsePrivateKey
+	AuthorizedKey string // authorized_keys line: "ssh-ed25519 AAAA... comment"
+	Fingerprint   string // SHA256:..., as printed by ssh-keygen -l
+}
+
+// GenerateSSHKey creates an Ed25519 keypair. comment ends the authorized_keys
+// line so the key can be recognised on a node.
+func GenerateSSHKey(comment string) (*SSHKeyPair, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate SSH key: %w", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode SSH key: %w", err)
+	}
+
+	// Wire format of RFC 8709: string "ssh-ed25519", string key
+	wire := sshString(nil, []byte("ssh-ed25519"))
+	wire = sshString(wire, public)
+	sum := sha256.Sum256(wire)
+
+	line := "ssh-ed25519 " + base64.StdEncoding.EncodeToString(wire)
+	if comment = strings.Join(strings.Fields(comment), "-"); comment != "" {
+		line += " " + comment
+	}
+	return &SSHKeyPair{
+		PrivateKeyPEM: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}),
+		AuthorizedKey: line,
+		Fingerprint:   "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:]),
+	}, nil
+}
+
+// AuthorizedKeyBody returns the base64 key of an authorized_keys line, which
+// identifies the key whatever its comment
+func AuthorizedKeyBody(line string) string {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return ""
+	}
+	return fields[1]
+}
+
+// sshString appends b as an SSH wire-format string (uint32 length, bytes)
+func sshString(buf, b []byte) []byte {
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(b)))
+	return append(buf, b...)
+}
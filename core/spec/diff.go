@@ -0,0 +1,172 @@
+package spec
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gpu-orchestrator/core/models"
+)
+
+// DiffSpecs compares two job specs field by field on their parsed form, so
+// formatting, key order, comments and defaults spelled out or left implicit
+// do not show up as changes. Paths use the YAML keys below job:, e.g.
+// resources.gpus. Changes are listed in spec order.
+func DiffSpecs(baseYAML, otherYAML string) ([]models.SpecChange, error) {
+	base, _, err := decodeSpec(baseYAML)
+	if err != nil {
+		return nil, fmt.Errorf("base spec: %w", err)
+	}
+	other, _, err := decodeSpec(otherYAML)
+	if err != nil {
+		return nil, fmt.Errorf("other spec: %w", err)
+	}
+
+	var changes []models.SpecChange
+	diffValue(reflect.ValueOf(base.Job), reflect.ValueOf(other.Job), "", &changes)
+	return changes, nil
+}
+
+// diffValue appends the differences between two values of the same type.
+// Structs and maps are walked key by key and lists of structs element by
+// element; lists of scalars (instance types, regions) change as a whole.
+func diffValue(a, b reflect.Value, path string, changes *[]models.SpecChange) {
+	switch a.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			// Optional blocks compare as if unset fields were zero, so
+			// adding a block lists the fields it sets
+			if a.Kind() == reflect.Ptr && a.Type().Elem().Kind() == reflect.Struct {
+				diffValue(derefOrZero(a), derefOrZero(b), path, changes)
+				return
+			}
+			addChange(specValue(a), specValue(b), path, changes)
+			return
+		}
+		if a.Kind() == reflect.Interface && a.Elem().Type() != b.Elem().Type() {
+			addChange(specValue(a), specValue(b), path, changes)
+			return
+		}
+		diffValue(a.Elem(), b.Elem(), path, changes)
+
+	case reflect.Struct:
+		t := a.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = strings.ToLower(field.Name)
+			}
+			diffValue(a.Field(i), b.Field(i), joinPath(path, name), changes)
+		}
+
+	case reflect.Map:
+		keys := make(map[string]reflect.Value)
+		for _, key := range append(a.MapKeys(), b.MapKeys()...) {
+			keys[fmt.Sprint(key.Interface())] = key
+		}
+		names := make([]string, 0, len(keys))
+		for name := range keys {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			key := keys[name]
+			av, bv := a.MapIndex(key), b.MapIndex(key)
+			switch {
+			case !av.IsValid():
+				addChange(nil, specValue(bv), joinPath(path, name), changes)
+			case !bv.IsValid():
+				addChange(specValue(av), nil, joinPath(path, name), changes)
+			default:
+				diffValue(av, bv, joinPath(path, name), changes)
+			}
+		}
+
+	case reflect.Slice, reflect.Array:
+		if !structElements(a.Type().Elem()) {
+			addChange(specValue(a), specValue(b), path, changes)
+			return
+		}
+		for i := 0; i < max(a.Len(), b.Len()); i++ {
+			elemPath := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= a.Len():
+				addChange(nil, specValue(b.Index(i)), elemPath, changes)
+			case i >= b.Len():
+				addChange(specValue(a.Index(i)), nil, elemPath, changes)
+			default:
+				diffValue(a.Index(i), b.Index(i), elemPath, changes)
+			}
+		}
+
+	default:
+		addChange(specValue(a), specValue(b), path, changes)
+	}
+}
+
+// addChange appends a change when the values differ. Zero values count as
+// unset, matching how the parser treats omitted fields.
+func addChange(from, to interface{}, path string, changes *[]models.SpecChange) {
+	if reflect.DeepEqual(from, to) {
+		return
+	}
+	change := models.SpecChangeChanged
+	switch {
+	case from == nil:
+		change = models.SpecChangeAdded
+	case to == nil:
+		change = models.SpecChangeRemoved
+	}
+	*changes = append(*changes, models.SpecChange{Path: path, Change: change, From: from, To: to})
+}
+
+// specValue returns a value for reporting: nil for unset (nil or zero)
+// values, the pointed-to value for pointers. Booleans are always set, so
+// allow_spot: true → false reads as a change.
+func specValue(v reflect.Value) interface{} {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() || (v.IsZero() && v.Kind() != reflect.Bool) {
+		return nil
+	}
+	if (v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.Len() == 0 {
+		return nil
+	}
+	return v.Interface()
+}
+
+// derefOrZero returns the struct a pointer points to, or its zero value
+func derefOrZero(v reflect.Value) reflect.Value {
+	if v.IsNil() {
+		return reflect.Zero(v.Type().Elem())
+	}
+	return v.Elem()
+}
+
+// structElements reports whether list elements are compared field by field
+func structElements(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+// joinPath appends a key to a dotted path
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
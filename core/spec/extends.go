@@ -0,0 +1,180 @@
+package spec
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/storage"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MaxExtendsDepth is the longest chain of bases a spec may extend
+const MaxExtendsDepth = 5
+
+// baseFetchTimeout bounds fetching one base spec
+const baseFetchTimeout = 10 * time.Second
+
+// ErrNoBaseResolver is returned for a spec that extends a base when the
+// parser has no way to fetch it
+var ErrNoBaseResolver = errors.New("extends is not supported: no base spec source configured")
+
+// BaseSpec is a fetched base spec document
+type BaseSpec struct {
+	YAML    string
+	Version string // Identifies the fetched revision, e.g. an ETag
+}
+
+// BaseResolver fetches the base spec an extends reference names
+type BaseResolver interface {
+	ResolveBase(ctx context.Context, ref string) (*BaseSpec, error)
+}
+
+// ObjectStoreBases fetches base specs from object storage URIs
+type ObjectStoreBases struct {
+	stores *storage.Registry
+}
+
+// NewObjectStoreBases creates a resolver for extends URIs such as
+// s3://bucket/specs/base.yaml
+func NewObjectStoreBases(stores *storage.Registry) *ObjectStoreBases {
+	return &ObjectStoreBases{stores: stores}
+}
+
+// ResolveBase fetches the base spec at ref. Its version is the object's
+// checksum, or the SHA-256 of its content when the backend reports none.
+func (b *ObjectStoreBases) ResolveBase(ctx context.Context, ref string) (*BaseSpec, error) {
+	if !strings.Contains(ref, "://") {
+		return nil, fmt.Errorf("base %q is not an object storage URI", ref)
+	}
+	info, err := b.stores.Stat(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	if info.Size > MaxSpecBytes {
+		return nil, fmt.Errorf("base %s is %d bytes, the limit is %d", ref, info.Size, MaxSpecBytes)
+	}
+
+	body, err := b.stores.Get(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	data, err := io.ReadAll(io.LimitReader(body, MaxSpecBytes+1))
+	if err != nil {
+		return nil, err
+	}
+
+	version := info.Checksum
+	if version == "" {
+		sum := sha256.Sum256(data)
+		version = "sha256:" + hex.EncodeToString(sum[:])
+	}
+	return &BaseSpec{YAML: string(data), Version: version}, nil
+}
+
+// resolveExtends returns the spec with its extends chain merged in, and the
+// bases it was built from, nearest first. A spec without extends is returned
+// unchanged.
+//
+// Merge semantics, base first and each overlay on top: mappings merge key by
+// key, lists and scalars replace, and an explicit null removes the field.
+func resolveExtends(specYAML string, resolver BaseResolver) (string, []models.SpecBase, error) {
+	doc, err := decodeDocument(specYAML)
+	if err != nil {
+		return "", nil, err
+	}
+	if _, ok := doc["extends"]; !ok {
+		return specYAML, nil, nil
+	}
+	if resolver == nil {
+		return "", nil, ErrNoBaseResolver
+	}
+
+	var bases []models.SpecBase
+	resolved, err := mergeBases(doc, resolver, nil, &bases)
+	if err != nil {
+		return "", nil, err
+	}
+	rendered, err := yaml.Marshal(resolved)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to render resolved spec: %w", err)
+	}
+	return string(rendered), bases, nil
+}
+
+// mergeBases merges doc onto its base chain. chain holds the references
+// already being resolved, to detect cycles.
+func mergeBases(doc map[string]interface{}, resolver BaseResolver, chain []string, bases *[]models.SpecBase) (map[string]interface{}, error) {
+	raw, ok := doc["extends"]
+	if !ok {
+		return doc, nil
+	}
+	delete(doc, "extends")
+	ref, ok := raw.(string)
+	if !ok || ref == "" {
+		return nil, fmt.Errorf("extends must be a template name or URI")
+	}
+
+	for _, seen := range chain {
+		if seen == ref {
+			return nil, fmt.Errorf("extends cycle: %s -> %s", strings.Join(chain, " -> "), ref)
+		}
+	}
+	chain = append(chain, ref)
+	if len(chain) > MaxExtendsDepth {
+		return nil, fmt.Errorf("extends chain %s is deeper than %d bases", strings.Join(chain, " -> "), MaxExtendsDepth)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), baseFetchTimeout)
+	base, err := resolver.ResolveBase(ctx, ref)
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch base %s: %w", ref, err)
+	}
+	*bases = append(*bases, models.SpecBase{Ref: ref, Version: base.Version})
+
+	baseDoc, err := decodeDocument(base.YAML)
+	if err != nil {
+		return nil, fmt.Errorf("base %s: %w", ref, err)
+	}
+	merged, err := mergeBases(baseDoc, resolver, chain, bases)
+	if err != nil {
+		return nil, err
+	}
+	if err := mergeInto(merged, doc, ""); err != nil {
+		return nil, fmt.Errorf("failed to merge onto base %s: %w", ref, err)
+	}
+	return merged, nil
+}
+
+// decodeDocument decodes a spec document into generic maps after the size,
+// depth and alias checks submitted specs get
+func decodeDocument(specYAML string) (map[string]interface{}, error) {
+	if len(specYAML) > MaxSpecBytes {
+		return nil, fmt.Errorf("spec is %d bytes, the limit is %d", len(specYAML), MaxSpecBytes)
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(specYAML), &root); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	doc := make(map[string]interface{})
+	if root.Kind == 0 {
+		return doc, nil
+	}
+	var unknown []string
+	if err := checkNode(&root, nil, "", 0, &unknown); err != nil {
+		return nil, err
+	}
+	if err := root.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	return doc, nil
+}
@@ -0,0 +1,130 @@
+package spec
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ImmutableCloneFields are structural spec fields a clone may not change
+// (paths are relative to the job section). Changing them is a new job, not a tweak.
+var ImmutableCloneFields = []string{
+	"type",
+	"framework",
+	"execution.mode",
+	"execution.backend",
+}
+
+// FieldChange is one difference between a source spec and its clone
+type FieldChange struct {
+	Path string      `json:"path"`
+	From interface{} `json:"from"` // nil when added
+	To   interface{} `json:"to"`   // nil when removed
+}
+
+// MergeOverrides deep-merges a sparse override object onto the job section of
+// specYAML. Nested objects merge key by key, other values replace, and explicit
+// nil values remove the field. Returns the merged spec YAML and the changes.
+func MergeOverrides(specYAML string, overrides map[string]interface{}) (string, []FieldChange, error) {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal([]byte(specYAML), &doc); err != nil {
+		return "", nil, fmt.Errorf("failed to parse source spec: %w", err)
+	}
+	job, ok := doc["job"].(map[string]interface{})
+	if !ok {
+		return "", nil, fmt.Errorf("source spec has no job section")
+	}
+
+	before := flatten(job, "")
+	if err := mergeInto(job, overrides, ""); err != nil {
+		return "", nil, err
+	}
+	after := flatten(job, "")
+
+	changes := diffFlattened(before, after)
+	for _, change := range changes {
+		for _, field := range ImmutableCloneFields {
+			if change.Path == field || strings.HasPrefix(change.Path, field+".") {
+				return "", nil, fmt.Errorf("field %s cannot be changed when cloning", field)
+			}
+		}
+	}
+
+	merged, err := yaml.Marshal(doc)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to render merged spec: %w", err)
+	}
+	return string(merged), changes, nil
+}
+
+// mergeInto applies overrides onto dst in place
+func mergeInto(dst, overrides map[string]interface{}, prefix string) error {
+	for key, value := range overrides {
+		path := prefix + key
+		if value == nil {
+			delete(dst, key)
+			continue
+		}
+
+		override, isMap := value.(map[string]interface{})
+		if !isMap {
+			dst[key] = value
+			continue
+		}
+
+		switch existing := dst[key].(type) {
+		case map[string]interface{}:
+			if err := mergeInto(existing, override, path+"."); err != nil {
+				return err
+			}
+		case nil:
+			nested := make(map[string]interface{})
+			if err := mergeInto(nested, override, path+"."); err != nil {
+				return err
+			}
+			dst[key] = nested
+		default:
+			return fmt.Errorf("cannot merge an object into %s (a %T)", path, existing)
+		}
+	}
+	return nil
+}
+
+// flatten maps dotted paths to leaf values (lists are leaves)
+func flatten(m map[string]interface{}, prefix string) map[string]interface{} {
+	flat := make(map[string]interface{})
+	for key, value := range m {
+		path := prefix + key
+		if nested, ok := value.(map[string]interface{}); ok {
+			for k, v := range flatten(nested, path+".") {
+				flat[k] = v
+			}
+			continue
+		}
+		flat[path] = value
+	}
+	return flat
+}
+
+// diffFlattened returns changed, added and removed paths sorted by path.
+// Values are compared by their printed form so 8 (YAML int) equals 8.0 (JSON number).
+func diffFlattened(before, after map[string]interface{}) []FieldChange {
+	var changes []FieldChange
+	for path, from := range before {
+		to, ok := after[path]
+		if !ok {
+			changes = append(changes, FieldChange{Path: path, From: from})
+		} else if fmt.Sprint(from) != fmt.Sprint(to) {
+			changes = append(changes, FieldChange{Path: path, From: from, To: to})
+		}
+	}
+	for path, to := range after {
+		if _, ok := before[path]; !ok {
+			changes = append(changes, FieldChange{Path: path, To: to})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
@@ -1,12 +1,23 @@
 package spec
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"math"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
+	"gpu-orchestrator/core/clock"
 	"gpu-orchestrator/core/models"
-
-	"gopkg.in/yaml.v3"
+	"gpu-orchestrator/training/bootstrap"
+	"gpu-orchestrator/training/frameworks"
+	"gpu-orchestrator/training/network"
 )
 
 // JobSpec represents the YAML job specification
@@ -16,25 +27,53 @@ type JobSpec struct {
 
 // JobSpecJob represents the job section of the spec
 type JobSpecJob struct {
-	Type        string             `yaml:"type"`
-	Framework   string             `yaml:"framework"`
-	Entrypoint  string             `yaml:"entrypoint"`
-	Resources   JobSpecResources   `yaml:"resources"`
-	Data        JobSpecData        `yaml:"data"`
-	Constraints JobSpecConstraints `yaml:"constraints"`
-	Execution   JobSpecExecution   `yaml:"execution"`
+	Type            string             `yaml:"type"`
+	Framework       string             `yaml:"framework"`
+	Entrypoint      string             `yaml:"entrypoint"`
+	Resources       JobSpecResources   `yaml:"resources"`
+	Data            JobSpecData        `yaml:"data"`
+	Constraints     JobSpecConstraints `yaml:"constraints"`
+	Execution       JobSpecExecution   `yaml:"execution"`
+	Network         JobSpecNetwork     `yaml:"network,omitempty"`
+	Placement       JobSpecPlacement   `yaml:"placement,omitempty"`
+	FrameworkConfig *JobSpecFramework  `yaml:"framework_config,omitempty"`
+	Elastic         *JobSpecElastic    `yaml:"elastic,omitempty"`
+	Session         *JobSpecSession    `yaml:"session,omitempty"`
+	Artifacts       JobSpecArtifacts   `yaml:"artifacts,omitempty"`
+	Training        JobSpecTraining    `yaml:"training,omitempty"`
+	Bootstrap       *bootstrap.Spec    `yaml:"bootstrap,omitempty"`
+	Labels          map[string]string  `yaml:"labels,omitempty"`     // Free key/value pairs, e.g. cost-center
+	Experiment      string             `yaml:"experiment,omitempty"` // Experiment name or ID to group the job under
+
+	// Checkpoint cadence exposed to the training process and checked for spot-backed jobs
+	Checkpointing *JobSpecCheckpointing `yaml:"checkpointing,omitempty"`
 }
 
 // JobSpecResources represents resource requirements
 type JobSpecResources struct {
 	GPUs              int      `yaml:"gpus"`
 	GPUFraction       *float64 `yaml:"gpu_fraction,omitempty"` // Phase 3: Fractional GPU (0.0-1.0)
-	UseMIG            *bool    `yaml:"use_mig,omitempty"`     // Phase 3: Enable MIG
-	MIGProfile        *string  `yaml:"mig_profile,omitempty"` // Phase 3: MIG profile (e.g., "1g.10gb")
+	UseMIG            *bool    `yaml:"use_mig,omitempty"`      // Phase 3: Enable MIG
+	MIGProfile        *string  `yaml:"mig_profile,omitempty"`  // Phase 3: MIG profile (e.g., "1g.10gb")
 	MaxGPUsPerNode    int      `yaml:"max_gpus_per_node"`
 	RequiresMultiNode bool     `yaml:"requires_multi_node"`
-	GPUMemory         string   `yaml:"gpu_memory"` // e.g., "80GB"
-	CPUMemory         string   `yaml:"cpu_memory"` // e.g., "512GB"
+	GPUMemory         string   `yaml:"gpu_memory"`                   // e.g., "80GB"
+	CPUMemory         string   `yaml:"cpu_memory"`                   // e.g., "512GB"
+	Storage           string   `yaml:"storage,omitempty"`            // Data volume per instance, e.g. "500GB" or "2TB"
+	StorageIOPS       int      `yaml:"storage_iops,omitempty"`       // Provisioned IOPS (AWS gp3)
+	StorageThroughput int      `yaml:"storage_throughput,omitempty"` // Provisioned MB/s (AWS gp3)
+
+	// Least node-to-node bandwidth in Gbps of a multi-node placement, e.g.
+	// 200 for large all-reduce; 0 = the platform default
+	MinInterconnectGbps float64 `yaml:"min_interconnect_gbps,omitempty"`
+
+	// Instance or GPU type globs, e.g. "p4d.*", "*-k80", "A100"; deny wins
+	InstanceTypes        []string `yaml:"instance_types,omitempty"`
+	ExcludeInstanceTypes []string `yaml:"exclude_instance_types,omitempty"`
+
+	// Registered machine image to boot, e.g. "ami-0abc..."; checked against
+	// the image registry at submit
+	Image string `yaml:"image,omitempty"`
 }
 
 // JobSpecData represents data configuration
@@ -42,28 +81,171 @@ type JobSpecData struct {
 	Dataset           string `yaml:"dataset"`
 	Locality          string `yaml:"locality"`
 	ReplicationPolicy string `yaml:"replication_policy"`
+	Verify            bool   `yaml:"verify,omitempty"`          // Check the dataset before provisioning
+	VerifyMode        string `yaml:"verify_mode,omitempty"`     // full (default) | sample | size
+	Manifest          string `yaml:"manifest,omitempty"`        // Manifest URI; implies verify
+	Output            string `yaml:"output,omitempty"`          // Prefix the job writes checkpoints and outputs to
+	Access            string `yaml:"access,omitempty"`          // auto | role-arn | service-account; empty = provider default
+	RoleARN           string `yaml:"role_arn,omitempty"`        // AWS instance profile ARN (access: role-arn)
+	ServiceAccount    string `yaml:"service_account,omitempty"` // GCP service account email (access: service-account)
+	Download          bool   `yaml:"download,omitempty"`        // Stage the dataset on the nodes before training
+	SharedMount       string `yaml:"shared_mount,omitempty"`    // Shared filesystem path on every node; downloads once
 }
 
 // JobSpecConstraints represents job constraints
 type JobSpecConstraints struct {
-	Budget            float64 `yaml:"budget"`
-	Deadline          string  `yaml:"deadline"` // ISO 8601
-	AllowSpot         bool    `yaml:"allow_spot"`
-	MinReliability    float64 `yaml:"min_reliability"`
-	PerformanceWeight float64 `yaml:"performance_weight"`
+	Budget            float64         `yaml:"budget"`
+	Deadline          string          `yaml:"deadline"` // ISO 8601
+	AllowSpot         bool            `yaml:"allow_spot"`
+	MaxSpotFraction   *float64        `yaml:"max_spot_fraction,omitempty"` // 0.0-1.0 share of nodes on spot
+	OnDemandRanks     []int           `yaml:"on_demand_ranks,omitempty"`   // Ranks pinned to on-demand nodes
+	MinReliability    float64         `yaml:"min_reliability"`
+	PerformanceWeight float64         `yaml:"performance_weight"`
+	Weights           *JobSpecWeights `yaml:"weights,omitempty"`                 // Overrides performance_weight
+	MaxQueueTime      string          `yaml:"max_queue_time,omitempty"`          // e.g. "2h"; warn when pending longer
+	CancelAfterQueue  string          `yaml:"cancel_after_queue_time,omitempty"` // e.g. "12h"; cancel when pending longer
+	AllowMigration    bool            `yaml:"allow_migration,omitempty"`         // Checkpoint and reschedule when cheaper elsewhere
+	CarbonWeight      float64         `yaml:"carbon_weight,omitempty"`           // 0-1; prefer low-carbon regions when prices are close
+	DataGravity       *bool           `yaml:"data_gravity,omitempty"`            // false: no bonus toward regions holding the team's data
+	SpotBidStrategy   string          `yaml:"spot_bid_strategy,omitempty"`       // low | market (default) | on_demand_cap
+	SpotBidCap        float64         `yaml:"spot_bid_cap,omitempty"`            // Explicit low bid, USD per instance-hour
+}
+
+// JobSpecWeights weights the optimizer's score terms (each 0-1, normalized server-side)
+type JobSpecWeights struct {
+	Cost        float64 `yaml:"cost"`
+	Reliability float64 `yaml:"reliability"`
+	Time        float64 `yaml:"time"`
+	Locality    float64 `yaml:"locality"`
 }
 
 // JobSpecExecution represents execution configuration
 type JobSpecExecution struct {
-	Mode   string `yaml:"mode"`              // single_cluster | multi_task
-	Backend string `yaml:"backend,omitempty"` // Phase 3: k8s | vm | slurm | ray (default: vm)
+	Mode        string `yaml:"mode"`                  // single_cluster | multi_task
+	Backend     string `yaml:"backend,omitempty"`     // Phase 3: k8s | vm | managed | slurm | ray (default: vm)
+	MaxRetries  *int   `yaml:"max_retries,omitempty"` // multi_task: retries per failed task (default: 1)
+	Aggregation string `yaml:"aggregation,omitempty"` // multi_task: all | any (default: all)
+	// Per-status stuck thresholds (Go durations), e.g. provisioning: 3h for slow dataset staging
+	StuckAfter map[string]string `yaml:"stuck_after,omitempty"`
+	// Write the topology manifest to $GPU_TOPOLOGY_FILE on the nodes and record it as an artifact
+	ExportTopology bool `yaml:"export_topology,omitempty"`
+	// multi_task: how many regions the tasks spread over
+	Spread *JobSpecSpread `yaml:"spread,omitempty"`
+	// multi_task: task shapes planned independently; default one shape of resources.gpus tasks
+	TaskGroups []JobSpecTaskGroup `yaml:"task_groups,omitempty"`
+}
+
+// JobSpecTaskGroup is one shape of a multi_task job's tasks
+type JobSpecTaskGroup struct {
+	Name          string   `yaml:"name"`
+	Tasks         int      `yaml:"tasks"`
+	GPUsPerTask   int      `yaml:"gpus_per_task,omitempty"`  // Default: 1
+	GPUMemory     string   `yaml:"gpu_memory,omitempty"`     // Default: resources.gpu_memory
+	InstanceTypes []string `yaml:"instance_types,omitempty"` // Default: resources.instance_types
+}
+
+// JobSpecSpread bounds the regions a multi_task job's tasks spread over
+type JobSpecSpread struct {
+	MaxRegions        int `yaml:"max_regions,omitempty"`          // Default: every candidate region
+	MinTasksPerRegion int `yaml:"min_tasks_per_region,omitempty"` // Default: 1
+}
+
+// JobSpecTraining describes the training run for progress reporting
+type JobSpecTraining struct {
+	TotalSteps int64  `yaml:"total_steps,omitempty"` // Planned steps; progress falls back to elapsed time without it
+	Metric     string `yaml:"metric,omitempty"`      // hours (default) | tokens: optimize $/hour or $/1M tokens
+	ModelClass string `yaml:"model_class,omitempty"` // Benchmark model class, e.g. llama; required for tokens
+}
+
+// JobSpecNetwork overrides the auto-selected NCCL/fabric network profile
+type JobSpecNetwork struct {
+	Profile string            `yaml:"profile,omitempty"` // e.g. aws-efa, gcp-gvnic, azure-ndv5-infiniband
+	Env     map[string]string `yaml:"env,omitempty"`     // Extra/overriding NCCL environment variables
 }
 
-// ParseJobSpec parses a YAML job specification into a Job model
+// JobSpecPlacement controls zone placement
+type JobSpecPlacement struct {
+	Spread string `yaml:"spread,omitempty"` // az: multi_task tasks in distinct availability zones
+}
+
+// JobSpecFramework configures the framework launcher (deepspeed and jax)
+type JobSpecFramework struct {
+	ZeroStage   *int                   `yaml:"zero_stage,omitempty"`  // deepspeed: ZeRO stage 0-3
+	Accelerator string                 `yaml:"accelerator,omitempty"` // deepspeed: DS_ACCELERATOR, e.g. cuda
+	ConfigURI   string                 `yaml:"config_uri,omitempty"`  // deepspeed: ds_config.json object URI
+	Config      map[string]interface{} `yaml:"config,omitempty"`      // deepspeed: inline ds_config
+	XLAFlags    []string               `yaml:"xla_flags,omitempty"`   // jax: XLA_FLAGS, e.g. --xla_gpu_enable_latency_hiding_scheduler=true
+}
+
+// JobSpecElastic bounds the node count of a horovod_elastic job
+type JobSpecElastic struct {
+	MinNodes int `yaml:"min_nodes"`
+	MaxNodes int `yaml:"max_nodes"`
+}
+
+// JobSpecSession configures an interactive session job
+type JobSpecSession struct {
+	MaxRuntime string `yaml:"max_runtime"`         // Go duration, e.g. "4h"
+	Jupyter    *bool  `yaml:"jupyter,omitempty"`   // Default: true
+	IdleStop   bool   `yaml:"idle_stop,omitempty"` // Auto-stop when GPUs stay idle
+}
+
+// JobSpecArtifacts configures artifact handling
+type JobSpecArtifacts struct {
+	Retention *JobSpecRetention `yaml:"retention,omitempty"`
+}
+
+// JobSpecRetention overrides the global checkpoint retention policy
+type JobSpecRetention struct {
+	KeepLast   *int `yaml:"keep_last,omitempty"`    // Newest checkpoints always kept
+	MaxAgeDays *int `yaml:"max_age_days,omitempty"` // Older unkept checkpoints are deleted
+}
+
+// JobSpecCheckpointing declares how often the job checkpoints and where to;
+// set exactly one interval
+type JobSpecCheckpointing struct {
+	IntervalSteps   int64  `yaml:"interval_steps,omitempty"`
+	IntervalMinutes int    `yaml:"interval_minutes,omitempty"`
+	URI             string `yaml:"uri,omitempty"`          // Prefix; defaults to <data.output>/checkpoints
+	ReplicateTo     string `yaml:"replicate_to,omitempty"` // provider/region or URI prefix of a secondary copy
+}
+
+// ParseJobSpec parses a YAML job specification into a Job model, rejecting
+// unknown fields
 func ParseJobSpec(specYAML string) (*models.Job, error) {
-	var spec JobSpec
-	if err := yaml.Unmarshal([]byte(specYAML), &spec); err != nil {
-		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	return ParseJobSpecWith(specYAML, ParseOptions{})
+}
+
+// ParseJobSpecWith parses a YAML job specification with the given options.
+// A spec that extends a base is merged onto it first; the resolved spec is
+// what the job stores.
+func ParseJobSpecWith(specYAML string, opts ParseOptions) (*models.Job, error) {
+	specYAML, bases, err := resolveExtends(specYAML, opts.Bases)
+	if err != nil {
+		return nil, err
+	}
+	if len(bases) > 0 {
+		if unset := unsetParameters(specYAML); len(unset) > 0 {
+			return nil, fmt.Errorf("template parameters not set: %s", strings.Join(unset, ", "))
+		}
+	}
+
+	spec, unknown, err := decodeSpec(specYAML)
+	if err != nil {
+		return nil, err
+	}
+	if len(unknown) > 0 && !opts.AllowUnknownFields {
+		return nil, &UnknownFieldsError{Fields: unknown}
+	}
+
+	specHash, err := hashSpec(spec.Job)
+	if err != nil {
+		return nil, err
+	}
+
+	// Interactive sessions run no training setup
+	if spec.Job.Type != string(models.JobTypeInteractive) && !frameworks.Registered(spec.Job.Framework) {
+		return nil, fmt.Errorf("unknown framework %q (supported: %s)", spec.Job.Framework, strings.Join(frameworks.Names(), ", "))
 	}
 
 	job := &models.Job{
@@ -73,6 +255,11 @@ func ParseJobSpec(specYAML string) (*models.Job, error) {
 		DatasetURI:    spec.Job.Data.Dataset,
 		Status:        models.JobStatusPending,
 		SpecYAML:      specYAML,
+		SpecHash:      specHash,
+		SpecBases:     bases,
+	}
+	for _, field := range unknown {
+		job.SpecWarnings = append(job.SpecWarnings, "unknown field "+field+" ignored")
 	}
 
 	// Parse resources
@@ -81,7 +268,7 @@ func ParseJobSpec(specYAML string) (*models.Job, error) {
 	if spec.Job.Resources.GPUFraction != nil {
 		gpuFraction = *spec.Job.Resources.GPUFraction
 	}
-	
+
 	useMIG := false
 	migProfile := ""
 	if spec.Job.Resources.UseMIG != nil {
@@ -90,70 +277,921 @@ func ParseJobSpec(specYAML string) (*models.Job, error) {
 	if spec.Job.Resources.MIGProfile != nil {
 		migProfile = *spec.Job.Resources.MIGProfile
 	}
-	
+
 	job.Requirements = models.JobRequirements{
 		GPUs:              spec.Job.Resources.GPUs,
 		GPUFraction:       gpuFraction, // Phase 3: Support fractional GPUs
-		UseMIG:            useMIG,     // Phase 3: Support MIG
+		UseMIG:            useMIG,      // Phase 3: Support MIG
 		MIGProfile:        migProfile,  // Phase 3: MIG profile
 		MaxGPUsPerNode:    spec.Job.Resources.MaxGPUsPerNode,
 		RequiresMultiNode: spec.Job.Resources.RequiresMultiNode,
 		GPUMemory:         parseMemoryGB(spec.Job.Resources.GPUMemory),
 		CPUMemory:         parseMemoryGB(spec.Job.Resources.CPUMemory),
-		Storage:           0,   // TODO: Parse from spec
 		EstimatedHours:    1.0, // TODO: Parse from spec
 		Framework:         spec.Job.Framework,
 		DatasetLocation:   spec.Job.Data.Dataset,
 	}
 
+	noteMemory(job, "resources.gpu_memory", spec.Job.Resources.GPUMemory, job.Requirements.GPUMemory)
+	noteMemory(job, "resources.cpu_memory", spec.Job.Resources.CPUMemory, job.Requirements.CPUMemory)
+
 	// Determine execution mode
 	if spec.Job.Execution.Mode != "" {
 		job.Requirements.ExecutionMode = models.ExecutionMode(spec.Job.Execution.Mode)
 	} else {
 		// Auto-detect based on framework
 		job.Requirements.ExecutionMode = detectExecutionMode(spec.Job.Framework, spec.Job.Type)
+		note(job, models.SpecNoteDetected, "execution.mode", string(job.Requirements.ExecutionMode),
+			fmt.Sprintf("detected from framework %q and type %q", spec.Job.Framework, spec.Job.Type))
 	}
-	
+
+	if err := parseTaskPolicy(job, spec.Job.Execution); err != nil {
+		return nil, err
+	}
+
+	if err := parseTaskGroups(job, spec.Job.Execution.TaskGroups); err != nil {
+		return nil, err
+	}
+
+	if err := parseRegionSpread(job, spec.Job.Execution.Spread); err != nil {
+		return nil, err
+	}
+
+	if err := parseStorage(job, spec.Job.Resources); err != nil {
+		return nil, err
+	}
+
+	if err := parseInstanceTypes(job, spec.Job.Resources); err != nil {
+		return nil, err
+	}
+
+	if err := parseInterconnect(job, spec.Job.Resources); err != nil {
+		return nil, err
+	}
+
 	// Phase 3: Parse backend type
 	if spec.Job.Execution.Backend != "" {
 		job.SelectedBackend = models.BackendType(spec.Job.Execution.Backend)
+		job.Requirements.Managed = job.SelectedBackend == models.BackendManaged
 	} else {
 		job.SelectedBackend = models.BackendVM // Default to VM
+		note(job, models.SpecNoteDefault, "execution.backend", string(job.SelectedBackend), "no backend set; jobs run on provider VMs")
+	}
+	job.ExportTopology = spec.Job.Execution.ExportTopology
+
+	job.Requirements.Image = strings.TrimSpace(spec.Job.Resources.Image)
+	if job.Requirements.Image != "" && job.SelectedBackend != models.BackendVM {
+		return nil, fmt.Errorf("resources.image requires the vm backend")
+	}
+
+	if err := parsePlacement(job, spec.Job.Placement); err != nil {
+		return nil, err
+	}
+
+	if err := parseFrameworkConfig(job, spec.Job.FrameworkConfig); err != nil {
+		return nil, err
 	}
 
 	// Parse constraints
 	job.Constraints = models.JobConstraints{
 		MaxBudget:         spec.Job.Constraints.Budget,
 		AllowSpot:         spec.Job.Constraints.AllowSpot,
+		AllowMigration:    spec.Job.Constraints.AllowMigration,
 		MinReliability:    spec.Job.Constraints.MinReliability,
 		PerformanceWeight: spec.Job.Constraints.PerformanceWeight,
 		DataLocality:      models.DataLocality(spec.Job.Data.Locality),
 		ReplicationPolicy: models.ReplicationPolicy(spec.Job.Data.ReplicationPolicy),
 	}
 
-	// Parse deadline
+	// Parse spot mixture constraints
+	job.Constraints.MaxSpotFraction = 1.0
+	if spec.Job.Constraints.MaxSpotFraction != nil {
+		fraction := *spec.Job.Constraints.MaxSpotFraction
+		if fraction < 0 || fraction > 1 {
+			return nil, fmt.Errorf("max_spot_fraction must be between 0.0 and 1.0, got %v", fraction)
+		}
+		job.Constraints.MaxSpotFraction = fraction
+	} else if job.Constraints.AllowSpot {
+		note(job, models.SpecNoteDefault, "constraints.max_spot_fraction", "1", "every node may run on spot")
+	}
+	for _, rank := range spec.Job.Constraints.OnDemandRanks {
+		if rank < 0 {
+			return nil, fmt.Errorf("on_demand_ranks must be non-negative, got %d", rank)
+		}
+	}
+	job.Constraints.OnDemandRanks = spec.Job.Constraints.OnDemandRanks
+
+	// Parse carbon weight
+	if weight := spec.Job.Constraints.CarbonWeight; weight < 0 || weight > 1 {
+		return nil, fmt.Errorf("carbon_weight must be between 0.0 and 1.0, got %v", weight)
+	}
+	job.Constraints.CarbonWeight = spec.Job.Constraints.CarbonWeight
+	job.Constraints.IgnoreDataGravity = spec.Job.Constraints.DataGravity != nil && !*spec.Job.Constraints.DataGravity
+
+	// Parse spot bid strategy
+	if err := parseSpotBid(job, spec.Job.Constraints); err != nil {
+		return nil, err
+	}
+
+	// Parse scoring weights
+	if spec.Job.Constraints.PerformanceWeight != 0 {
+		message := "superseded by constraints.weights"
+		if spec.Job.Constraints.Weights != nil {
+			message += ", which are set, so it is ignored"
+		}
+		note(job, models.SpecNoteDeprecated, "constraints.performance_weight", fmt.Sprint(spec.Job.Constraints.PerformanceWeight), message)
+	}
+	if weights := spec.Job.Constraints.Weights; weights != nil {
+		job.Constraints.Weights = &models.ScoringWeights{
+			Cost:        weights.Cost,
+			Reliability: weights.Reliability,
+			Time:        weights.Time,
+			Locality:    weights.Locality,
+		}
+		if err := validateWeights(*job.Constraints.Weights); err != nil {
+			return nil, err
+		}
+	}
+
+	// Parse network overrides
+	job.Network = models.NetworkOverrides{
+		Profile: spec.Job.Network.Profile,
+		Env:     spec.Job.Network.Env,
+	}
+	if err := network.ValidateOverrides(job.Network); err != nil {
+		return nil, err
+	}
+
+	// Parse elastic bounds
+	if spec.Job.Elastic != nil {
+		elastic := spec.Job.Elastic
+		if spec.Job.Framework != "horovod_elastic" {
+			return nil, fmt.Errorf("elastic block requires framework horovod_elastic, got %q", spec.Job.Framework)
+		}
+		if elastic.MinNodes < 1 {
+			return nil, fmt.Errorf("elastic.min_nodes must be at least 1, got %d", elastic.MinNodes)
+		}
+		if elastic.MaxNodes < elastic.MinNodes {
+			return nil, fmt.Errorf("elastic.max_nodes (%d) must be >= min_nodes (%d)", elastic.MaxNodes, elastic.MinNodes)
+		}
+		job.Requirements.Elastic = &models.ElasticConfig{
+			MinNodes: elastic.MinNodes,
+			MaxNodes: elastic.MaxNodes,
+		}
+	}
+
+	// Parse interactive session
+	if err := parseSession(job, spec.Job.Session); err != nil {
+		return nil, err
+	}
+
+	if job.Requirements.Managed {
+		if err := validateManaged(job); err != nil {
+			return nil, err
+		}
+	}
+
+	// Parse artifact retention override
+	if retention := spec.Job.Artifacts.Retention; retention != nil {
+		if retention.KeepLast != nil && *retention.KeepLast < 0 {
+			return nil, fmt.Errorf("artifacts.retention.keep_last must be non-negative, got %d", *retention.KeepLast)
+		}
+		if retention.MaxAgeDays != nil && *retention.MaxAgeDays < 0 {
+			return nil, fmt.Errorf("artifacts.retention.max_age_days must be non-negative, got %d", *retention.MaxAgeDays)
+		}
+		job.ArtifactRetention = &models.RetentionPolicy{
+			KeepLast:   retention.KeepLast,
+			MaxAgeDays: retention.MaxAgeDays,
+		}
+	}
+
+	// Parse node bootstrap snippets
+	if spec.Job.Bootstrap != nil {
+		job.Bootstrap = spec.Job.Bootstrap.Config()
+		if err := bootstrap.Validate(job.Bootstrap); err != nil {
+			return nil, err
+		}
+	}
+
+	// Parse dataset verification
+	if err := parseDatasetVerify(job, spec.Job.Data); err != nil {
+		return nil, err
+	}
+
+	// Parse instance identity
+	if err := parseDataAccess(job, spec.Job.Data); err != nil {
+		return nil, err
+	}
+
+	// Parse dataset download
+	if err := parseDatasetDownload(job, spec.Job.Data); err != nil {
+		return nil, err
+	}
+
+	// Parse stuck-state threshold overrides
+	if err := parseStuckAfter(job, spec.Job.Execution.StuckAfter); err != nil {
+		return nil, err
+	}
+
+	// Parse planned training steps
+	if spec.Job.Training.TotalSteps < 0 {
+		return nil, fmt.Errorf("training.total_steps must be non-negative, got %d", spec.Job.Training.TotalSteps)
+	}
+	job.TotalSteps = spec.Job.Training.TotalSteps
+	if err := parseTrainingMetric(job, spec.Job.Training); err != nil {
+		return nil, err
+	}
+
+	// Parse checkpoint schedule
+	if err := parseCheckpointing(job, spec.Job.Checkpointing, spec.Job.Data); err != nil {
+		return nil, err
+	}
+
+	// Parse labels
+	if err := validateLabels(spec.Job.Labels); err != nil {
+		return nil, err
+	}
+	job.Labels = spec.Job.Labels
+	job.Experiment = strings.TrimSpace(spec.Job.Experiment)
+
+	// Parse deadline; any offset is accepted and stored as UTC
 	if spec.Job.Constraints.Deadline != "" {
-		deadline, err := time.Parse(time.RFC3339, spec.Job.Constraints.Deadline)
+		deadline, err := clock.ParseRFC3339(spec.Job.Constraints.Deadline)
 		if err != nil {
 			return nil, fmt.Errorf("invalid deadline format: %w", err)
 		}
+		if opts.Clock != nil {
+			if err := clock.CheckNotPast(deadline, opts.Clock.Now(), opts.ClockSkew); err != nil {
+				return nil, fmt.Errorf("invalid deadline: %w", err)
+			}
+		}
 		job.Constraints.Deadline = &deadline
 	}
 
+	// Parse queue time alarms
+	if err := parseQueueTime(job, spec.Job.Constraints); err != nil {
+		return nil, err
+	}
+
 	// Set defaults
 	if job.Constraints.MinReliability == 0 {
 		job.Constraints.MinReliability = 0.9
+		note(job, models.SpecNoteDefault, "constraints.min_reliability", "0.9", "no minimum set")
 	}
 	if job.Constraints.DataLocality == "" {
 		job.Constraints.DataLocality = models.DataLocalityPrefer
+		note(job, models.SpecNoteDefault, "data.locality", string(job.Constraints.DataLocality), "no locality set; the dataset's region is preferred")
 	}
 	if job.Constraints.ReplicationPolicy == "" {
 		job.Constraints.ReplicationPolicy = models.ReplicationNone
+		note(job, models.SpecNoteDefault, "data.replication_policy", string(job.Constraints.ReplicationPolicy), "no replication policy set; the dataset is read in place")
 	}
 
 	return job, nil
 }
 
+// hashSpec hashes the parsed job section, so YAML formatting, key order and
+// comments do not change the result. The job name is not part of the spec.
+func hashSpec(job JobSpecJob) (string, error) {
+	canonical, err := json.Marshal(job)
+	if err != nil {
+		return "", fmt.Errorf("failed to canonicalize spec: %w", err)
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// parseDatasetVerify enables the pre-flight dataset check when the spec sets
+// data.verify or a manifest
+func parseDatasetVerify(job *models.Job, data JobSpecData) error {
+	if !data.Verify && data.Manifest == "" {
+		if data.VerifyMode != "" {
+			return fmt.Errorf("data.verify_mode requires data.verify or data.manifest")
+		}
+		return nil
+	}
+	if data.Dataset == "" {
+		return fmt.Errorf("dataset verification requires data.dataset")
+	}
+
+	mode := models.DatasetVerifyMode(data.VerifyMode)
+	switch mode {
+	case "":
+		mode = models.DatasetVerifyFull
+	case models.DatasetVerifyFull, models.DatasetVerifySample, models.DatasetVerifySize:
+	default:
+		return fmt.Errorf("invalid data.verify_mode %q (want full, sample or size)", data.VerifyMode)
+	}
+	if mode == models.DatasetVerifySize && data.Manifest != "" {
+		return fmt.Errorf("data.manifest cannot be checked in verify_mode size")
+	}
+
+	job.DatasetVerify = &models.DatasetVerify{Mode: mode, ManifestURI: data.Manifest}
+	return nil
+}
+
+// sharedMountPattern matches absolute paths safe to use unquoted in node scripts
+var sharedMountPattern = regexp.MustCompile(`^/[A-Za-z0-9._/-]+$`)
+
+// parseTrainingMetric sets what the optimizer's cost term measures. Tokens
+// mode is for training jobs and needs a model class to look up the
+// tokens-per-hour benchmark.
+func parseTrainingMetric(job *models.Job, training JobSpecTraining) error {
+	job.Requirements.ModelClass = training.ModelClass
+	switch models.TrainingMetric(training.Metric) {
+	case "", models.MetricHours:
+		return nil
+	case models.MetricTokens:
+		if job.JobType != models.JobTypeTraining {
+			return fmt.Errorf("training.metric tokens requires job type training, got %q", job.JobType)
+		}
+		if training.ModelClass == "" {
+			return fmt.Errorf("training.metric tokens requires training.model_class")
+		}
+		job.Requirements.Metric = models.MetricTokens
+		return nil
+	default:
+		return fmt.Errorf("training.metric must be hours or tokens, got %q", training.Metric)
+	}
+}
+
+// parseCheckpointing reads the job's checkpoint schedule
+func parseCheckpointing(job *models.Job, block *JobSpecCheckpointing, data JobSpecData) error {
+	if block == nil {
+		return nil
+	}
+	if block.IntervalSteps < 0 || block.IntervalMinutes < 0 {
+		return fmt.Errorf("checkpointing intervals must be non-negative")
+	}
+	if (block.IntervalSteps > 0) == (block.IntervalMinutes > 0) {
+		return fmt.Errorf("checkpointing requires exactly one of interval_steps or interval_minutes")
+	}
+	uri := block.URI
+	if uri == "" && data.Output != "" {
+		uri = strings.TrimRight(data.Output, "/") + "/checkpoints"
+	}
+	if uri == "" {
+		return fmt.Errorf("checkpointing requires uri or data.output")
+	}
+	if !strings.Contains(uri, "://") {
+		return fmt.Errorf("checkpointing.uri must be an object store URI, got %q", block.URI)
+	}
+
+	replicateTo := strings.TrimRight(block.ReplicateTo, "/")
+	if replicateTo != "" {
+		if strings.Contains(replicateTo, "://") {
+			if replicateTo == strings.TrimRight(uri, "/") {
+				return fmt.Errorf("checkpointing.replicate_to must differ from checkpointing.uri")
+			}
+		} else if provider, region, ok := strings.Cut(replicateTo, "/"); !ok || provider == "" || region == "" || strings.Contains(region, "/") {
+			return fmt.Errorf("checkpointing.replicate_to must be provider/region or an object store URI, got %q", block.ReplicateTo)
+		}
+	}
+
+	job.Checkpointing = &models.CheckpointSchedule{
+		IntervalSteps:   block.IntervalSteps,
+		IntervalMinutes: block.IntervalMinutes,
+		URI:             uri,
+		ReplicateTo:     replicateTo,
+	}
+	job.Requirements.Checkpointed = true
+	return nil
+}
+
+// parseDatasetDownload enables the dataset download phase when the spec sets
+// data.download or a shared mount
+func parseDatasetDownload(job *models.Job, data JobSpecData) error {
+	if !data.Download && data.SharedMount == "" {
+		return nil
+	}
+	if data.Dataset == "" {
+		return fmt.Errorf("dataset download requires data.dataset")
+	}
+	mount := strings.TrimSuffix(data.SharedMount, "/")
+	if data.SharedMount != "" && (!sharedMountPattern.MatchString(mount) || strings.Contains(mount, "..")) {
+		return fmt.Errorf("data.shared_mount must be an absolute path, got %q", data.SharedMount)
+	}
+
+	job.DatasetDownload = &models.DatasetDownload{SharedMount: mount}
+	return nil
+}
+
+// parseDataAccess reads the identity the job's instances run as and its output prefix
+func parseDataAccess(job *models.Job, data JobSpecData) error {
+	mode, err := models.ParseDataAccessMode(data.Access)
+	if err != nil {
+		return fmt.Errorf("data.access: %w", err)
+	}
+	if data.RoleARN != "" && mode != models.DataAccessRoleARN {
+		return fmt.Errorf("data.role_arn requires data.access role-arn")
+	}
+	if data.ServiceAccount != "" && mode != models.DataAccessServiceAccount {
+		return fmt.Errorf("data.service_account requires data.access service-account")
+	}
+
+	access := &models.DataAccess{Mode: mode, OutputURI: data.Output}
+	switch mode {
+	case models.DataAccessRoleARN:
+		// RunInstances attaches instance profiles, not roles
+		if !strings.HasPrefix(data.RoleARN, "arn:aws:iam::") || !strings.Contains(data.RoleARN, ":instance-profile/") {
+			return fmt.Errorf("data.role_arn must be an instance profile ARN (arn:aws:iam::<account>:instance-profile/<name>), got %q", data.RoleARN)
+		}
+		access.Identity = data.RoleARN
+	case models.DataAccessServiceAccount:
+		if !strings.HasSuffix(data.ServiceAccount, ".gserviceaccount.com") || !strings.Contains(data.ServiceAccount, "@") {
+			return fmt.Errorf("data.service_account must be a service account email, got %q", data.ServiceAccount)
+		}
+		access.Identity = data.ServiceAccount
+	case models.DataAccessDefault:
+		if data.Output == "" {
+			return nil
+		}
+	}
+
+	job.DataAccess = access
+	return nil
+}
+
+// validateManaged rejects what a managed training service cannot run: the
+// service runs one fixed-size training job until it ends
+func validateManaged(job *models.Job) error {
+	switch {
+	case job.Requirements.ExecutionMode != models.ModeSingleCluster:
+		return fmt.Errorf("execution.backend managed requires execution.mode single_cluster")
+	case job.JobType == models.JobTypeInteractive:
+		return fmt.Errorf("execution.backend managed cannot run interactive sessions")
+	case job.Requirements.Elastic != nil:
+		return fmt.Errorf("execution.backend managed cannot resize elastic jobs")
+	case job.Constraints.AllowMigration:
+		return fmt.Errorf("constraints.allow_migration is not supported on execution.backend managed")
+	}
+	return nil
+}
+
+// stuckStatuses are the statuses whose duration the stuck-state sweeper checks
+var stuckStatuses = []models.JobStatus{models.JobStatusScheduled, models.JobStatusProvisioning, models.JobStatusCheckpointing}
+
+// parseSpotBid reads the spot bid strategy; an explicit cap implies low
+func parseSpotBid(job *models.Job, constraints JobSpecConstraints) error {
+	strategy := models.SpotBidStrategy(constraints.SpotBidStrategy)
+	if !strategy.Valid() {
+		return fmt.Errorf("spot_bid_strategy must be low, market or on_demand_cap, got %q", constraints.SpotBidStrategy)
+	}
+	if constraints.SpotBidCap < 0 {
+		return fmt.Errorf("spot_bid_cap must be positive, got %v", constraints.SpotBidCap)
+	}
+	if constraints.SpotBidCap > 0 {
+		if strategy != "" && strategy != models.SpotBidLow {
+			return fmt.Errorf("spot_bid_cap only applies to spot_bid_strategy low, got %q", strategy)
+		}
+		strategy = models.SpotBidLow
+	}
+	if strategy != "" && !constraints.AllowSpot {
+		return fmt.Errorf("spot_bid_strategy requires allow_spot")
+	}
+
+	job.Constraints.SpotBidStrategy = strategy
+	job.Constraints.SpotBidCap = constraints.SpotBidCap
+	return nil
+}
+
+// parseQueueTime validates the pending-time warning and cancel thresholds
+func parseQueueTime(job *models.Job, constraints JobSpecConstraints) error {
+	for _, field := range []struct {
+		name  string
+		value string
+		dest  *time.Duration
+	}{
+		{"max_queue_time", constraints.MaxQueueTime, &job.Constraints.MaxQueueTime},
+		{"cancel_after_queue_time", constraints.CancelAfterQueue, &job.Constraints.QueueCancelAfter},
+	} {
+		if field.value == "" {
+			continue
+		}
+		threshold, err := time.ParseDuration(field.value)
+		if err != nil {
+			return fmt.Errorf("invalid constraints.%s: %w", field.name, err)
+		}
+		if threshold <= 0 {
+			return fmt.Errorf("constraints.%s must be positive, got %s", field.name, field.value)
+		}
+		*field.dest = threshold
+	}
+
+	maxQueue, cancelAfter := job.Constraints.MaxQueueTime, job.Constraints.QueueCancelAfter
+	if maxQueue > 0 && cancelAfter > 0 && cancelAfter < maxQueue {
+		return fmt.Errorf("constraints.cancel_after_queue_time (%s) must not be shorter than max_queue_time (%s)", cancelAfter, maxQueue)
+	}
+	return nil
+}
+
+// parseStuckAfter validates per-status stuck thresholds
+func parseStuckAfter(job *models.Job, stuckAfter map[string]string) error {
+	if len(stuckAfter) == 0 {
+		return nil
+	}
+
+	job.StuckAfter = make(map[models.JobStatus]time.Duration, len(stuckAfter))
+	for status, value := range stuckAfter {
+		if !slices.Contains(stuckStatuses, models.JobStatus(status)) {
+			return fmt.Errorf("execution.stuck_after: unknown status %q (want scheduled, provisioning or checkpointing)", status)
+		}
+		threshold, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid execution.stuck_after.%s: %w", status, err)
+		}
+		if threshold <= 0 {
+			return fmt.Errorf("execution.stuck_after.%s must be positive, got %s", status, value)
+		}
+		job.StuckAfter[models.JobStatus(status)] = threshold
+	}
+	return nil
+}
+
+// parseSession validates the session block: required for interactive jobs
+// and rejected for every other type. The session TTL doubles as the
+// estimated duration so cost estimates and budget checks cover the session.
+func parseSession(job *models.Job, session *JobSpecSession) error {
+	if job.JobType != models.JobTypeInteractive {
+		if session != nil {
+			return fmt.Errorf("session block requires type interactive, got %q", job.JobType)
+		}
+		return nil
+	}
+	if session == nil || session.MaxRuntime == "" {
+		return fmt.Errorf("interactive jobs require session.max_runtime")
+	}
+
+	maxRuntime, err := time.ParseDuration(session.MaxRuntime)
+	if err != nil {
+		return fmt.Errorf("invalid session.max_runtime: %w", err)
+	}
+	if maxRuntime <= 0 {
+		return fmt.Errorf("session.max_runtime must be positive, got %s", session.MaxRuntime)
+	}
+
+	jupyter := true
+	if session.Jupyter != nil {
+		jupyter = *session.Jupyter
+	}
+
+	job.Session = &models.SessionConfig{
+		MaxRuntime: maxRuntime,
+		Jupyter:    jupyter,
+		IdleStop:   session.IdleStop,
+	}
+	job.Requirements.EstimatedHours = maxRuntime.Hours()
+	return nil
+}
+
+// validateWeights requires every weight in [0, 1] and at least one above zero
+func validateWeights(weights models.ScoringWeights) error {
+	terms := []struct {
+		name  string
+		value float64
+	}{
+		{"cost", weights.Cost},
+		{"reliability", weights.Reliability},
+		{"time", weights.Time},
+		{"locality", weights.Locality},
+	}
+	sum := 0.0
+	for _, term := range terms {
+		if term.value < 0 || term.value > 1 {
+			return fmt.Errorf("constraints.weights.%s must be between 0.0 and 1.0, got %v", term.name, term.value)
+		}
+		sum += term.value
+	}
+	if sum == 0 {
+		return fmt.Errorf("constraints.weights must have at least one non-zero weight")
+	}
+	return nil
+}
+
+// validateLabels bounds label keys and values so they stay usable in
+// policies, metrics and billing exports
+func validateLabels(labels map[string]string) error {
+	for key, value := range labels {
+		if key == "" || len(key) > 63 {
+			return fmt.Errorf("label keys must be 1-63 characters, got %q", key)
+		}
+		if len(value) > 255 {
+			return fmt.Errorf("label %s: value must be at most 255 characters", key)
+		}
+	}
+	return nil
+}
+
+// parseTaskPolicy sets the retry and aggregation policy of multi_task jobs
+// and rejects task settings on any other execution mode
+func parseTaskPolicy(job *models.Job, execution JobSpecExecution) error {
+	if job.Requirements.ExecutionMode != models.ModeMultiTask {
+		if execution.MaxRetries != nil || execution.Aggregation != "" {
+			return fmt.Errorf("execution.max_retries and execution.aggregation require mode multi_task")
+		}
+		return nil
+	}
+
+	policy := models.DefaultTaskPolicy
+	if execution.MaxRetries != nil {
+		if *execution.MaxRetries < 0 {
+			return fmt.Errorf("execution.max_retries must be non-negative, got %d", *execution.MaxRetries)
+		}
+		policy.MaxRetries = *execution.MaxRetries
+	}
+	switch aggregation := models.TaskAggregation(execution.Aggregation); aggregation {
+	case "":
+	case models.TaskAggregationAll, models.TaskAggregationAny:
+		policy.Aggregation = aggregation
+	default:
+		return fmt.Errorf("execution.aggregation must be all or any, got %q", execution.Aggregation)
+	}
+	job.TaskPolicy = &policy
+	return nil
+}
+
+// parseRegionSpread parses execution.spread, which only multi_task jobs
+// may set
+func parseRegionSpread(job *models.Job, spread *JobSpecSpread) error {
+	if spread == nil {
+		return nil
+	}
+	if job.Requirements.ExecutionMode != models.ModeMultiTask {
+		return fmt.Errorf("execution.spread requires mode multi_task")
+	}
+	if spread.MaxRegions < 0 || spread.MinTasksPerRegion < 0 {
+		return fmt.Errorf("execution.spread.max_regions and min_tasks_per_region must be non-negative")
+	}
+	if spread.MinTasksPerRegion > job.Requirements.GPUs {
+		return fmt.Errorf("execution.spread.min_tasks_per_region %d exceeds the job's %d tasks", spread.MinTasksPerRegion, job.Requirements.GPUs)
+	}
+	// Each task group is spread on its own
+	for _, group := range job.Requirements.TaskGroups {
+		if spread.MinTasksPerRegion > group.Tasks {
+			return fmt.Errorf("execution.spread.min_tasks_per_region %d exceeds task group %q's %d tasks", spread.MinTasksPerRegion, group.Name, group.Tasks)
+		}
+	}
+	job.Requirements.RegionSpread = &models.RegionSpread{
+		MaxRegions:        spread.MaxRegions,
+		MinTasksPerRegion: spread.MinTasksPerRegion,
+	}
+	return nil
+}
+
+// taskGroupNamePattern matches a task group name, which is exported to the
+// group's tasks as TASK_GROUP
+var taskGroupNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// parseTaskGroups parses execution.task_groups, which only multi_task jobs
+// take. Group names must be unique. The groups' GPUs must add up to
+// resources.gpus when it is set; otherwise resources.gpus is their sum.
+func parseTaskGroups(job *models.Job, groups []JobSpecTaskGroup) error {
+	if len(groups) == 0 {
+		return nil
+	}
+	if job.Requirements.ExecutionMode != models.ModeMultiTask {
+		return fmt.Errorf("execution.task_groups requires mode multi_task")
+	}
+
+	seen := make(map[string]bool)
+	total := 0
+	for i, group := range groups {
+		name := strings.TrimSpace(group.Name)
+		if name == "" {
+			return fmt.Errorf("execution.task_groups[%d].name is required", i)
+		}
+		if !taskGroupNamePattern.MatchString(name) {
+			return fmt.Errorf("execution.task_groups name %q must be letters, digits, '.', '_' or '-'", name)
+		}
+		if seen[name] {
+			return fmt.Errorf("execution.task_groups has duplicate name %q", name)
+		}
+		seen[name] = true
+		if group.Tasks <= 0 {
+			return fmt.Errorf("execution.task_groups %q: tasks must be positive", name)
+		}
+		if group.GPUsPerTask < 0 {
+			return fmt.Errorf("execution.task_groups %q: gpus_per_task must be positive", name)
+		}
+		if limit := job.Requirements.MaxGPUsPerNode; limit > 0 && group.GPUsPerTask > limit {
+			return fmt.Errorf("execution.task_groups %q: gpus_per_task %d exceeds resources.max_gpus_per_node %d", name, group.GPUsPerTask, limit)
+		}
+		for _, pattern := range group.InstanceTypes {
+			if strings.TrimSpace(pattern) == "" {
+				return fmt.Errorf("execution.task_groups %q: instance_types must not contain empty patterns", name)
+			}
+			if _, err := path.Match(strings.ToLower(pattern), ""); err != nil {
+				return fmt.Errorf("execution.task_groups %q has invalid instance type pattern %q: %w", name, pattern, err)
+			}
+		}
+
+		taskGroup := models.TaskGroup{
+			Name:          name,
+			Tasks:         group.Tasks,
+			GPUsPerTask:   max(group.GPUsPerTask, 1),
+			GPUMemory:     parseMemoryGB(group.GPUMemory),
+			InstanceTypes: group.InstanceTypes,
+		}
+		total += taskGroup.GPUs()
+		job.Requirements.TaskGroups = append(job.Requirements.TaskGroups, taskGroup)
+	}
+
+	switch gpus := job.Requirements.GPUs; {
+	case gpus == 0:
+		job.Requirements.GPUs = total
+	case gpus != total:
+		return fmt.Errorf("execution.task_groups need %d GPUs in total but resources.gpus is %d", total, gpus)
+	}
+	return nil
+}
+
+// parsePlacement parses placement.spread. Single-cluster jobs always run in
+// one zone, so spreading is only allowed for multi_task jobs.
+func parsePlacement(job *models.Job, placement JobSpecPlacement) error {
+	switch spread := models.PlacementSpread(placement.Spread); spread {
+	case models.SpreadNone:
+	case models.SpreadAZ:
+		if job.Requirements.ExecutionMode != models.ModeMultiTask {
+			return fmt.Errorf("placement.spread requires execution mode multi_task")
+		}
+		job.PlacementSpread = spread
+	default:
+		return fmt.Errorf("placement.spread must be az, got %q", placement.Spread)
+	}
+	return nil
+}
+
+// deepSpeedAccelerators are the DS_ACCELERATOR values DeepSpeed supports
+var deepSpeedAccelerators = []string{"cuda", "cpu", "xpu", "npu", "hpu", "mps"}
+
+// xlaFlagPattern matches one XLA flag, e.g. --xla_gpu_enable_triton_gemm=false
+var xlaFlagPattern = regexp.MustCompile(`^--xla_[a-z0-9_]+(=\S+)?$`)
+
+// parseFrameworkConfig parses the framework_config block of deepspeed and
+// jax jobs; each takes only its own fields
+func parseFrameworkConfig(job *models.Job, block *JobSpecFramework) error {
+	if block == nil {
+		return nil
+	}
+	switch job.Framework {
+	case "deepspeed":
+		if len(block.XLAFlags) > 0 {
+			return fmt.Errorf("framework_config.xla_flags requires framework jax")
+		}
+		return parseDeepSpeedConfig(job, block)
+	case "jax":
+		if block.ZeroStage != nil || block.Accelerator != "" || block.ConfigURI != "" || block.Config != nil {
+			return fmt.Errorf("framework_config of jax jobs takes only xla_flags")
+		}
+		for _, flag := range block.XLAFlags {
+			if !xlaFlagPattern.MatchString(flag) {
+				return fmt.Errorf("framework_config.xla_flags: %q is not an XLA flag (--xla_name or --xla_name=value)", flag)
+			}
+		}
+		job.FrameworkConfig = &models.FrameworkConfig{XLAFlags: block.XLAFlags}
+		return nil
+	default:
+		return fmt.Errorf("framework_config requires framework deepspeed or jax, got %q", job.Framework)
+	}
+}
+
+// parseDeepSpeedConfig parses the ZeRO stage, accelerator and ds_config of a
+// deepspeed job
+func parseDeepSpeedConfig(job *models.Job, block *JobSpecFramework) error {
+	if block.ZeroStage != nil && (*block.ZeroStage < 0 || *block.ZeroStage > 3) {
+		return fmt.Errorf("framework_config.zero_stage must be between 0 and 3, got %d", *block.ZeroStage)
+	}
+	if block.Accelerator != "" && !slices.Contains(deepSpeedAccelerators, block.Accelerator) {
+		return fmt.Errorf("framework_config.accelerator must be one of %s, got %q", strings.Join(deepSpeedAccelerators, ", "), block.Accelerator)
+	}
+	if block.ConfigURI != "" && block.Config != nil {
+		return fmt.Errorf("framework_config.config_uri and config are mutually exclusive")
+	}
+	if block.Config != nil {
+		if _, err := json.Marshal(block.Config); err != nil {
+			return fmt.Errorf("framework_config.config is not valid JSON: %w", err)
+		}
+	}
+	job.FrameworkConfig = &models.FrameworkConfig{
+		ZeroStage:   block.ZeroStage,
+		Accelerator: block.Accelerator,
+		ConfigURI:   block.ConfigURI,
+		Config:      block.Config,
+	}
+	return nil
+}
+
+// Data volume limits shared by the supported volume types
+const (
+	maxStorageGB         = 16384
+	maxStorageIOPS       = 16000
+	maxStorageThroughput = 1000
+)
+
+// parseStorage parses resources.storage and its IOPS and throughput options
+func parseStorage(job *models.Job, resources JobSpecResources) error {
+	if resources.Storage == "" {
+		if resources.StorageIOPS != 0 || resources.StorageThroughput != 0 {
+			return fmt.Errorf("resources.storage_iops and storage_throughput require resources.storage")
+		}
+		return nil
+	}
+
+	gb, err := parseStorageGB(resources.Storage)
+	if err != nil {
+		return err
+	}
+	if gb <= 0 || gb > maxStorageGB {
+		return fmt.Errorf("resources.storage must be between 1GB and %dGB, got %q", maxStorageGB, resources.Storage)
+	}
+	if resources.StorageIOPS < 0 || resources.StorageIOPS > maxStorageIOPS {
+		return fmt.Errorf("resources.storage_iops must be between 0 and %d", maxStorageIOPS)
+	}
+	if resources.StorageThroughput < 0 || resources.StorageThroughput > maxStorageThroughput {
+		return fmt.Errorf("resources.storage_throughput must be between 0 and %d MB/s", maxStorageThroughput)
+	}
+
+	job.Requirements.Storage = gb
+	job.Requirements.StorageIOPS = resources.StorageIOPS
+	job.Requirements.StorageThroughput = resources.StorageThroughput
+	return nil
+}
+
+// maxInterconnectGbps bounds resources.min_interconnect_gbps; the fastest
+// catalog types offer 3200
+const maxInterconnectGbps = 6400
+
+// parseInterconnect parses resources.min_interconnect_gbps. It only applies
+// to single-cluster jobs spanning nodes.
+func parseInterconnect(job *models.Job, resources JobSpecResources) error {
+	if resources.MinInterconnectGbps == 0 {
+		return nil
+	}
+	if resources.MinInterconnectGbps < 0 || resources.MinInterconnectGbps > maxInterconnectGbps {
+		return fmt.Errorf("resources.min_interconnect_gbps must be between 0 and %d", maxInterconnectGbps)
+	}
+	if !job.Requirements.RequiresMultiNode || job.Requirements.ExecutionMode != models.ModeSingleCluster {
+		return fmt.Errorf("resources.min_interconnect_gbps requires resources.requires_multi_node and the single_cluster execution mode")
+	}
+	job.Requirements.MinInterconnectGbps = resources.MinInterconnectGbps
+	return nil
+}
+
+// parseInstanceTypes parses resources.instance_types and exclude_instance_types
+func parseInstanceTypes(job *models.Job, resources JobSpecResources) error {
+	for field, patterns := range map[string][]string{
+		"resources.instance_types":         resources.InstanceTypes,
+		"resources.exclude_instance_types": resources.ExcludeInstanceTypes,
+	} {
+		for _, pattern := range patterns {
+			if strings.TrimSpace(pattern) == "" {
+				return fmt.Errorf("%s must not contain empty patterns", field)
+			}
+			if _, err := path.Match(strings.ToLower(pattern), ""); err != nil {
+				return fmt.Errorf("%s has invalid pattern %q: %w", field, pattern, err)
+			}
+		}
+	}
+
+	job.Requirements.InstanceTypes = resources.InstanceTypes
+	job.Requirements.ExcludeInstanceTypes = resources.ExcludeInstanceTypes
+	return nil
+}
+
+// parseStorageGB parses a size such as "500GB" or "2TB" to GB
+func parseStorageGB(size string) (int, error) {
+	value := strings.ToUpper(strings.TrimSpace(size))
+	multiplier := 1
+	switch {
+	case strings.HasSuffix(value, "TB"):
+		value, multiplier = strings.TrimSuffix(value, "TB"), 1000
+	case strings.HasSuffix(value, "GB"):
+		value = strings.TrimSuffix(value, "GB")
+	default:
+		return 0, fmt.Errorf("resources.storage must end in GB or TB, got %q", size)
+	}
+
+	amount, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid resources.storage %q: %w", size, err)
+	}
+	return int(math.Ceil(amount * float64(multiplier))), nil
+}
+
+// note records how a spec field was resolved
+func note(job *models.Job, kind, field, value, message string) {
+	job.SpecNotes = append(job.SpecNotes, models.SpecNote{Kind: kind, Field: field, Value: value, Message: message})
+}
+
+// noteMemory records a memory size that was not written as whole GB, since
+// only the leading number is read
+func noteMemory(job *models.Job, field, raw string, gb int) {
+	if raw == "" || strings.TrimSpace(raw) == fmt.Sprintf("%dGB", gb) {
+		return
+	}
+	note(job, models.SpecNoteParsed, field, fmt.Sprintf("%d", gb), fmt.Sprintf("%q read as %d GB; write sizes as whole GB, e.g. \"80GB\"", raw, gb))
+}
+
 // parseMemoryGB parses memory string (e.g., "80GB") to GB integer
 func parseMemoryGB(memoryStr string) int {
 	// Simple parser - assumes format like "80GB" or "512GB"
@@ -171,7 +1209,7 @@ func detectExecutionMode(framework, jobType string) models.ExecutionMode {
 	}
 
 	// Single-cluster for synchronous training frameworks
-	if framework == "pytorch_ddp" || framework == "horovod" || framework == "tensorflow_multiworker" {
+	if framework == "pytorch_ddp" || framework == "horovod" || framework == "horovod_elastic" || framework == "tensorflow_multiworker" || framework == "deepspeed" || framework == "jax" {
 		return models.ModeSingleCluster
 	}
 
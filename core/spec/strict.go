@@ -0,0 +1,177 @@
+package spec
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gpu-orchestrator/core/clock"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Limits on submitted specs
+const (
+	MaxSpecBytes = 512 << 10 // Largest accepted spec document
+	MaxSpecDepth = 16        // Deepest accepted nesting of mappings and sequences
+)
+
+// ParseOptions controls how strictly a spec is decoded
+type ParseOptions struct {
+	// AllowUnknownFields downgrades unknown fields from an error to warnings
+	// on the parsed job (Job.SpecWarnings)
+	AllowUnknownFields bool
+
+	// Bases fetches the base specs named by extends; nil rejects specs that
+	// extend a base
+	Bases BaseResolver
+
+	// Clock rejects deadlines more than ClockSkew before its current time.
+	// nil accepts any deadline, e.g. when a spec is only linted.
+	Clock     clock.Clock
+	ClockSkew time.Duration
+}
+
+// UnknownFieldsError rejects a spec with fields that match nothing
+type UnknownFieldsError struct {
+	Fields []string // Spec paths with their line, e.g. "job.resources.gpu (line 6)"
+}
+
+func (e *UnknownFieldsError) Error() string {
+	return "unknown fields: " + strings.Join(e.Fields, ", ")
+}
+
+// ErrorField returns the spec path a parse error starts with, such as
+// "execution.spread requires mode multi_task", or "" when it names none
+func ErrorField(err error) string {
+	word, _, _ := strings.Cut(err.Error(), " ")
+	if !strings.ContainsAny(word, "._") {
+		return ""
+	}
+	for _, r := range word {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' || r == '.') {
+			return ""
+		}
+	}
+	return word
+}
+
+// decodeSpec decodes a spec document after checking its size and nesting,
+// rejecting anchors and aliases, and collecting fields JobSpec does not have.
+// Unknown fields are returned with their path; the caller decides whether
+// they are an error.
+func decodeSpec(specYAML string) (*JobSpec, []string, error) {
+	if len(specYAML) > MaxSpecBytes {
+		return nil, nil, fmt.Errorf("spec is %d bytes, the limit is %d", len(specYAML), MaxSpecBytes)
+	}
+
+	// Decoding into a node keeps aliases unexpanded, so alias bombs are
+	// rejected below before anything is expanded
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(specYAML), &root); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	var spec JobSpec
+	if root.Kind == 0 {
+		return &spec, nil, nil
+	}
+
+	var unknown []string
+	if err := checkNode(&root, reflect.TypeOf(spec), "", 0, &unknown); err != nil {
+		return nil, nil, err
+	}
+	if err := root.Decode(&spec); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	return &spec, unknown, nil
+}
+
+// checkNode walks a YAML node against the Go type it decodes into. It enforces
+// the depth limit, rejects anchors and aliases, and appends mapping keys that
+// match no field to unknown. A nil t (free-form values) is only checked for
+// depth and aliases.
+func checkNode(node *yaml.Node, t reflect.Type, path string, depth int, unknown *[]string) error {
+	if depth > MaxSpecDepth {
+		return fmt.Errorf("%s (line %d): nesting deeper than %d levels", path, node.Line, MaxSpecDepth)
+	}
+	if node.Kind == yaml.AliasNode || node.Anchor != "" {
+		return fmt.Errorf("line %d: YAML anchors and aliases are not allowed in job specs", node.Line)
+	}
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t != nil && t.Kind() == reflect.Interface {
+		t = nil
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			if err := checkNode(child, t, path, depth, unknown); err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Kind == yaml.AliasNode || key.Anchor != "" {
+				return fmt.Errorf("line %d: YAML anchors and aliases are not allowed in job specs", key.Line)
+			}
+			keyPath := key.Value
+			if path != "" {
+				keyPath = path + "." + key.Value
+			}
+
+			var valueType reflect.Type
+			switch {
+			case t == nil:
+			case t.Kind() == reflect.Struct:
+				field, ok := yamlField(t, key.Value)
+				if !ok {
+					*unknown = append(*unknown, fmt.Sprintf("%s (line %d)", keyPath, key.Line))
+				} else {
+					valueType = field.Type
+				}
+			case t.Kind() == reflect.Map:
+				valueType = t.Elem()
+			}
+			if err := checkNode(value, valueType, keyPath, depth+1, unknown); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		var elemType reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			elemType = t.Elem()
+		}
+		for i, child := range node.Content {
+			if err := checkNode(child, elemType, fmt.Sprintf("%s[%d]", path, i), depth+1, unknown); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// yamlField finds the struct field a YAML key decodes into
+func yamlField(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		if name == key {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
@@ -0,0 +1,186 @@
+package spec
+
+import (
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed templates/*.yaml
+var templateFiles embed.FS
+
+// Template is a built-in starter spec. Its spec holds {{NAME}} placeholders,
+// one per parameter, inside quoted YAML strings.
+type Template struct {
+	Name        string              `yaml:"name" json:"name"`
+	Description string              `yaml:"description" json:"description"`
+	Parameters  []TemplateParameter `yaml:"parameters" json:"parameters"`
+	Spec        string              `yaml:"spec" json:"spec"`
+}
+
+// TemplateParameter is a value a template leaves to the user
+type TemplateParameter struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description" json:"description"`
+	Example     string `yaml:"example" json:"example"`
+}
+
+// templates holds the embedded templates sorted by name
+var templates []Template
+
+// placeholderPattern matches a template parameter placeholder
+var placeholderPattern = regexp.MustCompile(`\{\{([A-Z][A-Z0-9_]*)\}\}`)
+
+func init() {
+	files, err := templateFiles.ReadDir("templates")
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded spec templates: %v", err))
+	}
+	for _, file := range files {
+		data, err := templateFiles.ReadFile(path.Join("templates", file.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("invalid embedded spec template %s: %v", file.Name(), err))
+		}
+		var template Template
+		if err := yaml.Unmarshal(data, &template); err != nil {
+			panic(fmt.Sprintf("invalid embedded spec template %s: %v", file.Name(), err))
+		}
+		templates = append(templates, template)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+}
+
+// BuiltinTemplates returns the built-in templates sorted by name
+func BuiltinTemplates() []Template {
+	return templates
+}
+
+// GetTemplate returns a built-in template by name
+func GetTemplate(name string) (*Template, bool) {
+	for i := range templates {
+		if templates[i].Name == name {
+			return &templates[i], true
+		}
+	}
+	return nil, false
+}
+
+// Render fills the template's placeholders. Parameters missing from params
+// get their example value.
+func (t *Template) Render(params map[string]string) (string, error) {
+	known := make(map[string]bool, len(t.Parameters))
+	for _, param := range t.Parameters {
+		known[param.Name] = true
+	}
+	for name := range params {
+		if !known[name] {
+			return "", fmt.Errorf("template %s has no parameter %s", t.Name, name)
+		}
+	}
+
+	rendered := t.Spec
+	for _, param := range t.Parameters {
+		value, ok := params[param.Name]
+		if !ok {
+			value = param.Example
+		}
+		// Placeholders sit inside double-quoted YAML strings
+		if strings.ContainsAny(value, "\"\\\n") {
+			return "", fmt.Errorf("parameter %s must not contain quotes, backslashes or newlines", param.Name)
+		}
+		rendered = strings.ReplaceAll(rendered, "{{"+param.Name+"}}", value)
+	}
+	return rendered, nil
+}
+
+// RenderSpec fills the {{NAME}} placeholders of a submitted template, such
+// as the shared template of a bulk submission. Every placeholder must be set
+// and every parameter used.
+func RenderSpec(specYAML string, params map[string]string) (string, error) {
+	template := &Template{Name: "submitted", Spec: specYAML}
+	for _, name := range unsetParameters(specYAML) {
+		if _, ok := params[name]; !ok {
+			return "", fmt.Errorf("parameter %s is not set", name)
+		}
+		template.Parameters = append(template.Parameters, TemplateParameter{Name: name})
+	}
+	return template.Render(params)
+}
+
+// ValidateBuiltinTemplates checks that every built-in template declares the
+// placeholders it uses and that, rendered with its examples, it parses
+func ValidateBuiltinTemplates() error {
+	for i := range templates {
+		template := &templates[i]
+		declared := make(map[string]bool, len(template.Parameters))
+		for _, param := range template.Parameters {
+			if param.Example == "" || param.Description == "" {
+				return fmt.Errorf("template %s: parameter %s needs a description and an example", template.Name, param.Name)
+			}
+			declared[param.Name] = true
+		}
+		for _, match := range placeholderPattern.FindAllStringSubmatch(template.Spec, -1) {
+			if !declared[match[1]] {
+				return fmt.Errorf("template %s: placeholder %s is not a declared parameter", template.Name, match[1])
+			}
+		}
+
+		rendered, err := template.Render(nil)
+		if err != nil {
+			return fmt.Errorf("template %s: %w", template.Name, err)
+		}
+		if _, err := ParseJobSpec(rendered); err != nil {
+			return fmt.Errorf("template %s: %w", template.Name, err)
+		}
+	}
+	return nil
+}
+
+// unsetParameters returns the template placeholders left in a resolved spec
+func unsetParameters(specYAML string) []string {
+	var names []string
+	for _, match := range placeholderPattern.FindAllStringSubmatch(specYAML, -1) {
+		names = append(names, match[1])
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// BuiltinBases resolves extends references naming a built-in template and
+// hands everything else to the next resolver
+type BuiltinBases struct {
+	next BaseResolver
+}
+
+// NewBuiltinBases creates a resolver for built-in template names, falling
+// back to next (e.g. object storage) for URIs
+func NewBuiltinBases(next BaseResolver) *BuiltinBases {
+	return &BuiltinBases{next: next}
+}
+
+// ResolveBase returns the named template's spec with its placeholders in
+// place; the extending spec must set every one of them. Its version is the
+// SHA-256 of the template spec, so a job records which revision it used.
+func (b *BuiltinBases) ResolveBase(ctx context.Context, ref string) (*BaseSpec, error) {
+	if strings.Contains(ref, "://") {
+		if b.next == nil {
+			return nil, ErrNoBaseResolver
+		}
+		return b.next.ResolveBase(ctx, ref)
+	}
+	template, ok := GetTemplate(ref)
+	if !ok {
+		return nil, fmt.Errorf("unknown template %q", ref)
+	}
+	sum := sha256.Sum256([]byte(template.Spec))
+	return &BaseSpec{YAML: template.Spec, Version: "builtin:sha256:" + hex.EncodeToString(sum[:])}, nil
+}
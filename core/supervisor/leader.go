@@ -0,0 +1,177 @@
+package supervisor
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// LeaseStore stores the leader lease shared by all replicas
+type LeaseStore interface {
+	TryAcquire(name, holder string, ttl time.Duration) (bool, error)
+	Release(name, holder string) error
+}
+
+// LeaderStatus is this replica's view of leader election
+type LeaderStatus struct {
+	Instance string     `json:"instance"` // This replica's ID
+	Leader   bool       `json:"leader"`
+	Since    *time.Time `json:"since,omitempty"` // When this replica became leader
+}
+
+// Elector runs leader election over a lease with a TTL. The leader renews
+// the lease every third of the TTL; when a renewal fails or the lease is
+// taken, it steps down and cancels the context its singleton workers run
+// with. Followers keep trying and take over once the lease expires.
+type Elector struct {
+	store       LeaseStore
+	name        string
+	instance    string
+	ttl         time.Duration
+	onElected   func(ctx context.Context) // Starts singleton workers with the leadership context
+	leading     bool
+	resigned    bool
+	since       time.Time
+	lastRenewed time.Time
+	cancel      context.CancelFunc
+	mu          sync.Mutex
+	now         func() time.Time
+}
+
+// NewElector creates a new elector for the named lease
+func NewElector(store LeaseStore, name, instance string, ttl time.Duration) *Elector {
+	return &Elector{
+		store:    store,
+		name:     name,
+		instance: instance,
+		ttl:      ttl,
+		now:      time.Now,
+	}
+}
+
+// OnElected registers the callback that starts singleton workers. It is
+// called each time this replica becomes leader; the context is cancelled
+// when leadership is lost.
+func (e *Elector) OnElected(start func(ctx context.Context)) {
+	e.onElected = start
+}
+
+// RenewInterval is how often the lease is acquired or renewed
+func (e *Elector) RenewInterval() time.Duration {
+	return e.ttl / 3
+}
+
+// Run campaigns for and renews the lease until ctx is done, then resigns
+func (e *Elector) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.RenewInterval())
+	defer ticker.Stop()
+
+	e.campaign(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			e.Resign()
+			return
+		case <-ticker.C:
+			Heartbeat(ctx)
+			e.campaign(ctx)
+		}
+	}
+}
+
+// campaign acquires or renews the lease and starts or stops leading
+func (e *Elector) campaign(ctx context.Context) {
+	e.mu.Lock()
+	resigned := e.resigned
+	e.mu.Unlock()
+	if resigned {
+		return
+	}
+
+	acquired, err := e.store.TryAcquire(e.name, e.instance, e.ttl)
+
+	e.mu.Lock()
+	now := e.now()
+	var leaderCtx context.Context
+
+	switch {
+	case err != nil:
+		log.Printf("Leader lease %s: renewal failed: %v", e.name, err)
+		// Step down before the lease can expire and be taken by another replica
+		if e.leading && now.Sub(e.lastRenewed) > e.ttl-e.RenewInterval() {
+			e.stepDown("lease renewal failing")
+		}
+	case acquired:
+		e.lastRenewed = now
+		if !e.leading {
+			leaderCtx = e.lead(ctx, now)
+		}
+	case e.leading:
+		e.stepDown("lease taken by another replica")
+	}
+	e.mu.Unlock()
+
+	if leaderCtx != nil && e.onElected != nil {
+		e.onElected(leaderCtx)
+	}
+}
+
+// lead marks this replica leader and returns the context for its singleton
+// workers. Callers hold mu.
+func (e *Elector) lead(ctx context.Context, now time.Time) context.Context {
+	leaderCtx, cancel := context.WithCancel(ctx)
+	e.leading = true
+	e.since = now
+	e.cancel = cancel
+	log.Printf("Instance %s acquired leader lease %s", e.instance, e.name)
+	return leaderCtx
+}
+
+// stepDown stops the singleton workers. Callers hold mu.
+func (e *Elector) stepDown(reason string) {
+	log.Printf("Instance %s lost leader lease %s: %s", e.instance, e.name, reason)
+	e.leading = false
+	if e.cancel != nil {
+		e.cancel()
+		e.cancel = nil
+	}
+}
+
+// Resign stops leading and releases the lease so another replica takes over
+// immediately instead of after the TTL. The elector does not campaign again.
+func (e *Elector) Resign() {
+	e.mu.Lock()
+	e.resigned = true
+	wasLeading := e.leading
+	if wasLeading {
+		e.stepDown("resigned")
+	}
+	e.mu.Unlock()
+
+	if wasLeading {
+		if err := e.store.Release(e.name, e.instance); err != nil {
+			log.Printf("Failed to release leader lease %s: %v", e.name, err)
+		}
+	}
+}
+
+// IsLeader reports whether this replica currently holds the lease
+func (e *Elector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.leading
+}
+
+// Status returns this replica's leadership
+func (e *Elector) Status() LeaderStatus {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	status := LeaderStatus{Instance: e.instance, Leader: e.leading}
+	if e.leading {
+		since := e.since
+		status.Since = &since
+	}
+	return status
+}
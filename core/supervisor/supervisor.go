@@ -0,0 +1,273 @@
+package supervisor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Worker states reported by Status
+const (
+	StateRunning    = "running"
+	StateRestarting = "restarting" // Waiting out the backoff after a crash
+	StateFailed     = "failed"     // Crashed more than the restart limit; not restarted again
+	StateStopped    = "stopped"    // Returned after its context was cancelled
+)
+
+// staleFactor is how many expected intervals may pass without a heartbeat
+// before a worker counts as stalled
+const staleFactor = 2
+
+// WorkerFunc is a background loop. It must call Heartbeat(ctx) every
+// iteration and return when ctx is done.
+type WorkerFunc func(ctx context.Context)
+
+// WorkerStatus is the health of one supervised worker
+type WorkerStatus struct {
+	Name          string        `json:"name"`
+	State         string        `json:"state"`
+	Interval      time.Duration `json:"interval"` // Expected time between heartbeats
+	LastHeartbeat time.Time     `json:"last_heartbeat"`
+	Restarts      int           `json:"restarts"`
+	LastError     string        `json:"last_error,omitempty"`
+	Stalled       bool          `json:"stalled"` // No heartbeat within staleFactor intervals
+}
+
+// Healthy reports whether the worker is running and heartbeating
+func (w WorkerStatus) Healthy() bool {
+	return !w.Stalled && (w.State == StateRunning || w.State == StateRestarting || w.State == StateStopped)
+}
+
+// StallHandler is called once when a worker stops heartbeating and once
+// when it is marked failed
+type StallHandler func(ctx context.Context, status WorkerStatus)
+
+// worker is the supervisor's record of a worker
+type worker struct {
+	status WorkerStatus
+	run    WorkerFunc
+}
+
+// Supervisor runs background workers with panic recovery and restarts with
+// exponential backoff, and watches their heartbeats. A worker that crashes
+// more than maxRestarts times is left stopped and the process reported
+// unhealthy; a worker that hangs cannot be restarted and is reported stalled.
+type Supervisor struct {
+	maxRestarts int
+	backoff     time.Duration // First restart delay; doubles per restart up to maxBackoff
+	maxBackoff  time.Duration
+	onStall     StallHandler
+	workers     map[string]*worker
+	mu          sync.Mutex
+	now         func() time.Time
+}
+
+// NewSupervisor creates a new supervisor
+func NewSupervisor(maxRestarts int, backoff, maxBackoff time.Duration) *Supervisor {
+	return &Supervisor{
+		maxRestarts: maxRestarts,
+		backoff:     backoff,
+		maxBackoff:  maxBackoff,
+		workers:     make(map[string]*worker),
+		now:         time.Now,
+	}
+}
+
+// SetStallHandler registers the callback for stalled and failed workers
+func (s *Supervisor) SetStallHandler(handler StallHandler) {
+	s.onStall = handler
+}
+
+// heartbeatKey carries a worker's heartbeat function in its context
+type heartbeatKey struct{}
+
+// Heartbeat records that the worker running with ctx is alive. It is a
+// no-op for loops not started by a supervisor.
+func Heartbeat(ctx context.Context) {
+	if beat, ok := ctx.Value(heartbeatKey{}).(func()); ok {
+		beat()
+	}
+}
+
+// Go starts a supervised worker. interval is how often the worker is
+// expected to heartbeat.
+func (s *Supervisor) Go(ctx context.Context, name string, interval time.Duration, run WorkerFunc) {
+	s.mu.Lock()
+	w := &worker{
+		status: WorkerStatus{
+			Name:          name,
+			State:         StateRunning,
+			Interval:      interval,
+			LastHeartbeat: s.now(),
+		},
+		run: run,
+	}
+	s.workers[name] = w
+	s.mu.Unlock()
+
+	go s.supervise(ctx, w)
+}
+
+// supervise runs a worker until ctx is done, restarting it after crashes
+func (s *Supervisor) supervise(ctx context.Context, w *worker) {
+	workerCtx := context.WithValue(ctx, heartbeatKey{}, func() { s.beat(w) })
+
+	for {
+		err := runRecovered(workerCtx, w.run)
+		if ctx.Err() != nil {
+			s.setState(w, StateStopped, "")
+			return
+		}
+		if err == nil {
+			err = fmt.Errorf("worker returned before shutdown")
+		}
+
+		s.mu.Lock()
+		w.status.Restarts++
+		restarts := w.status.Restarts
+		s.mu.Unlock()
+
+		if restarts > s.maxRestarts {
+			log.Printf("Worker %s failed %d times, giving up: %v", w.status.Name, restarts, err)
+			status := s.setState(w, StateFailed, err.Error())
+			s.notify(ctx, status)
+			return
+		}
+
+		delay := s.backoffFor(restarts)
+		log.Printf("Worker %s crashed (restart %d/%d in %s): %v", w.status.Name, restarts, s.maxRestarts, delay, err)
+		s.setState(w, StateRestarting, err.Error())
+
+		select {
+		case <-ctx.Done():
+			s.setState(w, StateStopped, "")
+			return
+		case <-time.After(delay):
+		}
+		s.setState(w, StateRunning, err.Error())
+		s.beat(w)
+	}
+}
+
+// runRecovered runs a worker, turning a panic into an error
+func runRecovered(ctx context.Context, run WorkerFunc) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+	run(ctx)
+	return nil
+}
+
+// backoffFor returns the delay before the given restart
+func (s *Supervisor) backoffFor(restart int) time.Duration {
+	delay := s.backoff
+	for i := 1; i < restart && delay < s.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > s.maxBackoff {
+		delay = s.maxBackoff
+	}
+	return delay
+}
+
+// beat records a heartbeat and clears a stall
+func (s *Supervisor) beat(w *worker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if w.status.Stalled {
+		log.Printf("Worker %s is heartbeating again", w.status.Name)
+	}
+	w.status.LastHeartbeat = s.now()
+	w.status.Stalled = false
+}
+
+// setState updates a worker's state and returns a snapshot
+func (s *Supervisor) setState(w *worker, state, lastError string) WorkerStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w.status.State = state
+	if lastError != "" {
+		w.status.LastError = lastError
+	}
+	return w.status
+}
+
+// Watch checks heartbeats every interval until ctx is done
+func (s *Supervisor) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.CheckHeartbeats(ctx)
+		}
+	}
+}
+
+// CheckHeartbeats marks running workers without a recent heartbeat as
+// stalled, notifying once per stall
+func (s *Supervisor) CheckHeartbeats(ctx context.Context) {
+	now := s.now()
+	var stalled []WorkerStatus
+
+	s.mu.Lock()
+	for _, w := range s.workers {
+		if w.status.State != StateRunning || w.status.Stalled {
+			continue
+		}
+		if now.Sub(w.status.LastHeartbeat) > staleFactor*w.status.Interval {
+			w.status.Stalled = true
+			stalled = append(stalled, w.status)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, status := range stalled {
+		log.Printf("Worker %s has not heartbeated for %s (expected every %s)",
+			status.Name, now.Sub(status.LastHeartbeat).Round(time.Second), status.Interval)
+		s.notify(ctx, status)
+	}
+}
+
+// notify calls the stall handler, if any
+func (s *Supervisor) notify(ctx context.Context, status WorkerStatus) {
+	if s.onStall != nil {
+		s.onStall(ctx, status)
+	}
+}
+
+// Status returns the health of every worker, ordered by name
+func (s *Supervisor) Status() []WorkerStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]WorkerStatus, 0, len(s.workers))
+	for _, w := range s.workers {
+		statuses = append(statuses, w.status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+// Healthy reports whether every worker is healthy
+func (s *Supervisor) Healthy() bool {
+	for _, status := range s.Status() {
+		if !status.Healthy() {
+			return false
+		}
+	}
+	return true
+}
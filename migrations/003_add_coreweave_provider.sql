@@ -0,0 +1,4 @@
+-- Migration: Add CoreWeave as a provider
+-- Specialty GPU clouds are first-class providers in the optimizer
+
+ALTER TYPE provider ADD VALUE IF NOT EXISTS 'coreweave';
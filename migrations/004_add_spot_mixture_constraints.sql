@@ -0,0 +1,10 @@
+-- Migration: Add spot mixture constraints
+-- Bounds the share of spot nodes per job and pins selected ranks to on-demand capacity
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS max_spot_fraction numeric(4,3) NOT NULL DEFAULT 1.000
+    CHECK (max_spot_fraction >= 0 AND max_spot_fraction <= 1),
+  ADD COLUMN IF NOT EXISTS on_demand_ranks int[] NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN jobs.max_spot_fraction IS 'Maximum share of nodes allowed on spot capacity (1.0 = no limit)';
+COMMENT ON COLUMN jobs.on_demand_ranks IS 'Distributed training ranks that must run on on-demand nodes';
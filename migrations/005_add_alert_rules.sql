@@ -0,0 +1,47 @@
+-- Migration: Add alert rules
+-- Operator-defined alert rules evaluated against job and fleet state, with an audit trail
+-- of every evaluation and every delivered firing
+
+DO $$ BEGIN
+  CREATE TYPE alert_condition AS ENUM ('status_duration','failure_count','cost_threshold','idle_cost');
+EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+
+CREATE TABLE IF NOT EXISTS alert_rules (
+  id                bigserial PRIMARY KEY,
+  name              text NOT NULL UNIQUE,
+  condition         alert_condition NOT NULL,
+  threshold         numeric(12,4) NOT NULL CHECK (threshold >= 0),
+  status            job_status NULL,                 -- status_duration only
+  window_seconds    bigint NOT NULL DEFAULT 0,       -- failure_count only
+  team_id           text NULL,
+  cooldown_seconds  bigint NOT NULL DEFAULT 3600,
+  webhook_url       text NULL,
+  email_recipients  text[] NOT NULL DEFAULT '{}',
+  enabled           boolean NOT NULL DEFAULT true,
+  created_at        timestamptz NOT NULL DEFAULT now(),
+  last_evaluated_at timestamptz NULL
+);
+
+CREATE TABLE IF NOT EXISTS alert_evaluations (
+  id            bigserial PRIMARY KEY,
+  rule_id       bigint NOT NULL REFERENCES alert_rules(id) ON DELETE CASCADE,
+  at            timestamptz NOT NULL DEFAULT now(),
+  matched       int NOT NULL DEFAULT 0,              -- Keys crossing the condition
+  fired         int NOT NULL DEFAULT 0,              -- Keys delivered (not in cooldown)
+  error         text NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_evaluations_rule_at ON alert_evaluations (rule_id, at DESC);
+
+CREATE TABLE IF NOT EXISTS alert_firings (
+  id          bigserial PRIMARY KEY,
+  rule_id     bigint NOT NULL REFERENCES alert_rules(id) ON DELETE CASCADE,
+  dedup_key   text NOT NULL,                         -- job id, team id or 'fleet'
+  fired_at    timestamptz NOT NULL DEFAULT now(),
+  subject     text NOT NULL,
+  message     text NOT NULL,
+  meta_json   jsonb NOT NULL DEFAULT '{}'::jsonb
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_firings_rule_key ON alert_firings (rule_id, dedup_key, fired_at DESC);
+CREATE INDEX IF NOT EXISTS idx_alert_firings_fired_at ON alert_firings (fired_at DESC);
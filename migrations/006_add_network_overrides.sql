@@ -0,0 +1,7 @@
+-- Migration: Add network profile overrides
+-- Stores the spec `network` block (forced profile + extra NCCL env) for framework setup
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS network_json jsonb NOT NULL DEFAULT '{}'::jsonb;
+
+COMMENT ON COLUMN jobs.network_json IS 'Network profile overrides from the job spec: {"profile": ..., "env": {...}}';
@@ -0,0 +1,16 @@
+-- Migration: Add elastic node bounds
+-- Stores the spec `elastic` block for horovod_elastic jobs (NULL = fixed-size cluster)
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS elastic_min_nodes INT,
+  ADD COLUMN IF NOT EXISTS elastic_max_nodes INT;
+
+ALTER TABLE jobs
+  ADD CONSTRAINT jobs_elastic_bounds_check
+  CHECK (
+    (elastic_min_nodes IS NULL AND elastic_max_nodes IS NULL)
+    OR (elastic_min_nodes >= 1 AND elastic_max_nodes >= elastic_min_nodes)
+  );
+
+COMMENT ON COLUMN jobs.elastic_min_nodes IS 'Minimum node count for elastic jobs; scale-down never goes below this';
+COMMENT ON COLUMN jobs.elastic_max_nodes IS 'Maximum node count for elastic jobs; scale-up never exceeds this';
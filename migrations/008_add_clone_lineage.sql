@@ -0,0 +1,11 @@
+-- Migration: Add clone lineage
+-- Jobs created via POST /v1/jobs/{id}/clone record their source job
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS cloned_from uuid NULL REFERENCES jobs(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_cloned_from
+ON jobs (cloned_from)
+WHERE cloned_from IS NOT NULL;
+
+COMMENT ON COLUMN jobs.cloned_from IS 'Source job when this job was created by cloning';
@@ -0,0 +1,19 @@
+-- Migration: Add interactive session jobs
+-- Interactive jobs provision a GPU dev box with SSH/Jupyter access; the
+-- session config, connection endpoint and TTL are stored on the job.
+-- Note: ALTER TYPE ... ADD VALUE cannot run inside a transaction block on PostgreSQL < 12.
+
+ALTER TYPE job_type ADD VALUE IF NOT EXISTS 'interactive';
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS session_json jsonb NULL,
+  ADD COLUMN IF NOT EXISTS session_endpoint_json jsonb NULL,
+  ADD COLUMN IF NOT EXISTS session_expires_at timestamptz NULL;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_session_expires_at
+ON jobs (session_expires_at)
+WHERE session_expires_at IS NOT NULL;
+
+COMMENT ON COLUMN jobs.session_json IS 'Interactive session config: max_runtime (ns), jupyter, idle_stop';
+COMMENT ON COLUMN jobs.session_endpoint_json IS 'Connection endpoint (host, port, protocol, token) once the session is ready';
+COMMENT ON COLUMN jobs.session_expires_at IS 'Session teardown time; extended via POST /v1/jobs/{id}/extend';
@@ -0,0 +1,30 @@
+-- Migration: Add checkpoint retention and garbage collection
+-- Completed jobs keep their newest N and pinned checkpoints; older ones are
+-- deleted from object storage and their rows marked deleted (never removed).
+
+ALTER TABLE job_artifacts
+  ADD COLUMN IF NOT EXISTS pinned boolean NOT NULL DEFAULT false,
+  ADD COLUMN IF NOT EXISTS deleted_at timestamptz NULL,
+  ADD COLUMN IF NOT EXISTS reclaimed_bytes bigint NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_artifacts_live
+ON job_artifacts (job_id, type)
+WHERE deleted_at IS NULL;
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS artifact_retention_json jsonb NULL;
+
+COMMENT ON COLUMN jobs.artifact_retention_json IS 'Per-job override of the global checkpoint retention (keep_last, max_age_days)';
+
+-- ---------- ARTIFACT GC RUNS ----------
+CREATE TABLE IF NOT EXISTS artifact_gc_runs (
+  id              bigserial PRIMARY KEY,
+  started_at      timestamptz NOT NULL DEFAULT now(),
+  finished_at     timestamptz NULL,
+  dry_run         boolean NOT NULL,
+  jobs_scanned    int NOT NULL DEFAULT 0,
+  bytes_reclaimed bigint NOT NULL DEFAULT 0,
+  deletions_json  jsonb NOT NULL DEFAULT '[]'::jsonb
+);
+
+CREATE INDEX IF NOT EXISTS idx_artifact_gc_runs_started ON artifact_gc_runs (started_at DESC);
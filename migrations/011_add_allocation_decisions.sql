@@ -0,0 +1,8 @@
+-- Migration: Record optimizer allocation decisions
+-- Every evaluated strategy with its costs, reliability and rejection reasons,
+-- plus a human-readable explanation, for GET /v1/jobs/{id}/decision.
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS decision_json jsonb NULL;
+
+COMMENT ON COLUMN jobs.decision_json IS 'Latest optimizer decision: strategies evaluated, chosen strategy and explanation';
@@ -0,0 +1,11 @@
+-- Migration: Add per-job node bootstrap snippets
+-- Validated write_files/run/env_from_secrets blocks merged into the node boot
+-- script; the rendered script is kept as a 'bootstrap' artifact for debugging.
+-- Note: ALTER TYPE ... ADD VALUE cannot run inside a transaction block on PostgreSQL < 12.
+
+ALTER TYPE artifact_type ADD VALUE IF NOT EXISTS 'bootstrap';
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS bootstrap_json jsonb NULL;
+
+COMMENT ON COLUMN jobs.bootstrap_json IS 'Job bootstrap block: write_files, run (as gpu-bootstrap), env_from_secrets';
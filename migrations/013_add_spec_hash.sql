@@ -0,0 +1,12 @@
+-- Migration: Add spec hashes for duplicate submission detection
+-- SubmitJob rejects a spec whose canonical hash matches a non-terminal job of
+-- the same user unless allow_duplicate is set.
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS spec_hash text NULL;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_user_spec_hash_active
+ON jobs (user_id, spec_hash)
+WHERE status NOT IN ('completed', 'failed', 'cancelled');
+
+COMMENT ON COLUMN jobs.spec_hash IS 'SHA-256 of the canonicalized parsed job spec (formatting-insensitive)';
@@ -0,0 +1,33 @@
+-- Migration: Add tasks of multi_task jobs
+-- A multi_task job fans out into one task per allocation. Each task has its
+-- own cluster, retry count and cost; the parent job's status and
+-- cost_running_usd are aggregated from its tasks.
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS task_policy_json jsonb NULL;
+
+COMMENT ON COLUMN jobs.task_policy_json IS 'multi_task only: max_retries per task and aggregation (all | any)';
+
+CREATE TABLE IF NOT EXISTS job_tasks (
+  job_id         uuid NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  task_index     int NOT NULL CHECK (task_index >= 0),
+  status         job_status NOT NULL DEFAULT 'pending',
+  attempts       int NOT NULL DEFAULT 0,
+
+  provider       provider NOT NULL,
+  region         text NOT NULL,
+  instance_type  text NOT NULL,
+  count          int NOT NULL CHECK (count > 0),
+  spot           boolean NOT NULL DEFAULT false,
+  price_per_hour numeric(12,6) NOT NULL CHECK (price_per_hour >= 0),
+
+  cluster_id     text NULL,
+  cost_usd       numeric(12,4) NOT NULL DEFAULT 0,  -- Accrued over all attempts
+  error          text NULL,                         -- Last attempt failure
+
+  started_at     timestamptz NULL,
+  finished_at    timestamptz NULL,
+  updated_at     timestamptz NOT NULL DEFAULT now(),
+
+  PRIMARY KEY (job_id, task_index)
+);
@@ -0,0 +1,8 @@
+-- Migration: Add explicit optimizer scoring weights
+-- constraints.weights (cost, reliability, time, locality) replaces the single
+-- performance_weight knob when set; NULL keeps the legacy mapping.
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS weights_json jsonb NULL;
+
+COMMENT ON COLUMN jobs.weights_json IS 'Score term weights (cost, reliability, time, locality), each 0-1, normalized by the optimizer';
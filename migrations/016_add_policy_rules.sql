@@ -0,0 +1,18 @@
+-- Migration: Add org policy rules and job labels
+-- Policy rules are checked against every submitted job (required fields,
+-- numeric bounds, allowed values, conditional requirements). Labels are free
+-- key/value pairs from the spec that policies and the policy webhook can set.
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS labels_json jsonb NOT NULL DEFAULT '{}'::jsonb;
+
+COMMENT ON COLUMN jobs.labels_json IS 'Job labels from the spec, possibly mutated by the policy webhook';
+
+CREATE TABLE IF NOT EXISTS policy_rules (
+  id          bigserial PRIMARY KEY,
+  name        text NOT NULL UNIQUE,
+  rule_json   jsonb NOT NULL,                      -- type, check, when, then, message
+  enabled     boolean NOT NULL DEFAULT true,
+  created_at  timestamptz NOT NULL DEFAULT now(),
+  updated_at  timestamptz NOT NULL DEFAULT now()
+);
@@ -0,0 +1,12 @@
+-- Migration: Add leader leases
+-- One row per singleton role (e.g. the scheduler and other background
+-- workers). A replica holds the role while it keeps renewing the lease; any
+-- replica may take it over once expires_at has passed.
+
+CREATE TABLE IF NOT EXISTS leader_leases (
+  name         text PRIMARY KEY,
+  holder       text NOT NULL,                      -- Replica instance ID
+  acquired_at  timestamptz NOT NULL DEFAULT now(),
+  renewed_at   timestamptz NOT NULL DEFAULT now(),
+  expires_at   timestamptz NOT NULL
+);
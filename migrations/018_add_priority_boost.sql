@@ -0,0 +1,8 @@
+-- Migration: Add operator priority boosts
+-- A boosted pending job is scheduled ahead of every job with a lower boost.
+-- The boost is cleared when the job is scheduled.
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS priority_boost int NOT NULL DEFAULT 0 CHECK (priority_boost >= 0);
+
+COMMENT ON COLUMN jobs.priority_boost IS 'Operator boost (0-100); higher boosts are scheduled first, 0 = none';
@@ -0,0 +1,10 @@
+-- Migration: Add pre-flight dataset verification
+-- Jobs that set data.verify or data.manifest have their dataset checked before
+-- provisioning; the measured size feeds the data transfer cost estimate.
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS dataset_verify_json jsonb,
+  ADD COLUMN IF NOT EXISTS dataset_size_gb double precision;
+
+COMMENT ON COLUMN jobs.dataset_verify_json IS 'Dataset check config {mode, manifest_uri}; NULL = not verified';
+COMMENT ON COLUMN jobs.dataset_size_gb IS 'Dataset size measured by verification; NULL = unknown';
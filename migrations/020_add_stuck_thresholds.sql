@@ -0,0 +1,8 @@
+-- Migration: Add per-job stuck-state thresholds
+-- Overrides the sweeper's default time limits for scheduled, provisioning and
+-- checkpointing jobs (execution.stuck_after in the spec).
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS stuck_after_json jsonb;
+
+COMMENT ON COLUMN jobs.stuck_after_json IS 'Status -> threshold (nanoseconds); NULL = sweeper defaults';
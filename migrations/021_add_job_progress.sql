@@ -0,0 +1,10 @@
+-- Migration: Add training progress telemetry
+-- Running jobs report completed steps; the API derives progress and an ETA
+-- from them and the planned step count (training.total_steps in the spec).
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS total_steps bigint,
+  ADD COLUMN IF NOT EXISTS progress_json jsonb;
+
+COMMENT ON COLUMN jobs.total_steps IS 'Planned training steps; NULL = unknown (progress uses elapsed time)';
+COMMENT ON COLUMN jobs.progress_json IS 'Latest step report {steps_completed, steps_per_hour, intervals, reported_at}';
@@ -0,0 +1,25 @@
+-- Migration: Add per-job instance identities
+-- data.access selects the cloud identity a job's instances run as. Every
+-- attached identity is recorded for audit; identities created for the job
+-- (access: auto) are deleted when it ends and deleted_at is set.
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS data_access_json jsonb NULL;
+
+COMMENT ON COLUMN jobs.data_access_json IS 'Instance identity {mode, identity, output_uri}; NULL = provider default';
+
+CREATE TABLE IF NOT EXISTS job_identities (
+  job_id       uuid NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  provider     provider NOT NULL,
+  kind         text NOT NULL,                 -- instance_profile | service_account
+  name         text NOT NULL,                 -- Instance profile name/ARN or service account email
+  managed      boolean NOT NULL DEFAULT false, -- Created for the job; deleted when it ends
+  grants_json  jsonb NOT NULL DEFAULT '[]',   -- [{uri, write}] granted to a managed identity
+  attached_at  timestamptz NOT NULL DEFAULT now(),
+  deleted_at   timestamptz NULL,
+
+  PRIMARY KEY (job_id, provider, name)
+);
+
+-- Managed identities still to be deleted
+CREATE INDEX IF NOT EXISTS idx_job_identities_live ON job_identities (job_id) WHERE managed AND deleted_at IS NULL;
@@ -0,0 +1,17 @@
+-- Migration: Add what-if replay runs
+-- A what-if replays a window of past jobs through the optimizer against a
+-- hypothetical supply. Replays too long to answer inline run in the
+-- background and record their result here.
+
+CREATE TABLE IF NOT EXISTS whatif_runs (
+  id            bigserial PRIMARY KEY,
+  status        text NOT NULL DEFAULT 'running', -- running | completed | failed
+  request_json  jsonb NOT NULL,                  -- {from, to, scenario}
+  jobs          int NOT NULL DEFAULT 0,          -- Jobs in the window
+  result_json   jsonb NULL,                      -- {baseline, scenario, delta}
+  error         text NULL,
+  created_at    timestamptz NOT NULL DEFAULT now(),
+  finished_at   timestamptz NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_whatif_runs_created ON whatif_runs (created_at DESC);
@@ -0,0 +1,13 @@
+-- Migration: Add pending-time alarms
+-- constraints.max_queue_time warns (event + webhook) once a job has been
+-- pending that long; constraints.cancel_after_queue_time cancels it.
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS max_queue_seconds int NULL CHECK (max_queue_seconds > 0),
+  ADD COLUMN IF NOT EXISTS queue_cancel_seconds int NULL CHECK (queue_cancel_seconds > 0);
+
+COMMENT ON COLUMN jobs.max_queue_seconds IS 'Warn when pending longer than this; NULL = never';
+COMMENT ON COLUMN jobs.queue_cancel_seconds IS 'Cancel when pending longer than this; NULL = never';
+
+-- Pending jobs with an alarm
+CREATE INDEX IF NOT EXISTS idx_jobs_queue_alarm ON jobs (status) WHERE max_queue_seconds IS NOT NULL OR queue_cancel_seconds IS NOT NULL;
@@ -0,0 +1,7 @@
+-- Migration: Add launch_config artifacts
+-- The generated training script and the secret-redacted environment of every
+-- node are recorded per launch. Scripts are stored content-addressed, so the
+-- same script is uploaded once however many nodes, retries or jobs run it.
+-- Note: ALTER TYPE ... ADD VALUE cannot run inside a transaction block on PostgreSQL < 12.
+
+ALTER TYPE artifact_type ADD VALUE IF NOT EXISTS 'launch_config';
@@ -0,0 +1,20 @@
+-- Migration: Add the checkpoint-aware migration advisor
+-- constraints.allow_migration lets the advisor checkpoint a running job and
+-- reschedule it when restarting elsewhere is cheaper. Every recommendation
+-- keeps the inputs of its calculation for audit.
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS allow_migration boolean NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN jobs.allow_migration IS 'Migration advisor may checkpoint and reschedule the job when cheaper';
+
+CREATE TABLE IF NOT EXISTS migration_advice (
+  id            bigserial PRIMARY KEY,
+  job_id        uuid NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  action        text NOT NULL,   -- recommended | migrated
+  savings_usd   numeric(12,2) NOT NULL,
+  inputs_json   jsonb NOT NULL,  -- Calculation inputs and result
+  created_at    timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_migration_advice_job ON migration_advice (job_id, created_at DESC);
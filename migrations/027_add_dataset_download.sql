@@ -0,0 +1,13 @@
+-- Migration: Add the dataset download phase
+-- data.download stages the dataset onto the nodes before the training launch.
+-- Download parallelism is derived from the instance network bandwidth.
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS dataset_download_json jsonb NULL;
+
+COMMENT ON COLUMN jobs.dataset_download_json IS 'Dataset download before training (shared_mount); NULL = not staged';
+
+ALTER TABLE gpu_pricing
+  ADD COLUMN IF NOT EXISTS network_gbps numeric(8,2) NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN gpu_pricing.network_gbps IS 'Instance network bandwidth in Gbps; 0 = unknown';
@@ -0,0 +1,19 @@
+-- Migration: Add experiments
+-- An experiment groups related jobs (e.g. a hyperparameter sweep) so their
+-- statuses and costs can be read and cancelled together. Deleting an
+-- experiment detaches its jobs instead of deleting them.
+
+CREATE TABLE IF NOT EXISTS experiments (
+  id            uuid PRIMARY KEY,
+  name          text NOT NULL UNIQUE,
+  description   text NOT NULL DEFAULT '',
+  labels_json   jsonb NOT NULL DEFAULT '{}',
+  created_at    timestamptz NOT NULL DEFAULT now()
+);
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS experiment_id uuid NULL REFERENCES experiments(id) ON DELETE SET NULL;
+
+COMMENT ON COLUMN jobs.experiment_id IS 'Experiment the job belongs to; NULL = none';
+
+CREATE INDEX IF NOT EXISTS idx_jobs_experiment ON jobs (experiment_id) WHERE experiment_id IS NOT NULL;
@@ -0,0 +1,10 @@
+-- Migration: Add the tokens-per-dollar optimization mode
+-- training.metric: tokens scores allocations by $/1M tokens from the
+-- tokens-per-hour benchmark of training.model_class instead of $/hour.
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS training_metric text NULL,
+  ADD COLUMN IF NOT EXISTS model_class text NULL;
+
+COMMENT ON COLUMN jobs.training_metric IS 'Unit of the optimizer cost term: hours | tokens; NULL = hours';
+COMMENT ON COLUMN jobs.model_class IS 'Benchmark model class, e.g. llama';
@@ -0,0 +1,15 @@
+-- Migration: Add daily rollups of provider API calls
+-- Each replica meters the calls its provider clients make and adds its
+-- counts to the row of the UTC day, provider and method.
+
+CREATE TABLE IF NOT EXISTS provider_api_usage (
+  day         text NOT NULL,  -- UTC date, YYYY-MM-DD
+  provider    text NOT NULL,
+  method      text NOT NULL,
+  calls       bigint NOT NULL DEFAULT 0,
+  errors      bigint NOT NULL DEFAULT 0,
+  throttled   bigint NOT NULL DEFAULT 0,  -- Non-critical calls refused over the hourly budget
+  latency_ms  numeric(16,3) NOT NULL DEFAULT 0,  -- Total latency of the calls
+  updated_at  timestamptz NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (day, provider, method)
+);
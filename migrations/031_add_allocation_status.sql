@@ -0,0 +1,21 @@
+-- Migration: Track the lifecycle of allocations
+-- The provisioner moves each allocation planned -> provisioning -> active
+-- (or failed, with how many of its instances launched), and teardown moves
+-- it to terminated. Active allocations of jobs that are not provisioning or
+-- running are orphans.
+
+ALTER TABLE allocations
+  ADD COLUMN IF NOT EXISTS status text NOT NULL DEFAULT 'planned'
+    CHECK (status IN ('planned', 'provisioning', 'active', 'failed', 'terminated')),
+  ADD COLUMN IF NOT EXISTS provisioned_count int NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS status_detail text NULL,
+  ADD COLUMN IF NOT EXISTS updated_at timestamptz NOT NULL DEFAULT now();
+
+-- Existing rows: allocations of running jobs are launched, those of ended jobs released
+UPDATE allocations a SET
+  status = CASE WHEN j.status IN ('running', 'checkpointing') THEN 'active' ELSE 'terminated' END,
+  provisioned_count = a.count
+FROM jobs j
+WHERE j.id = a.job_id AND j.status NOT IN ('pending', 'scheduled', 'provisioning');
+
+CREATE INDEX IF NOT EXISTS idx_allocations_status ON allocations (status);
@@ -0,0 +1,18 @@
+-- Migration: Add per-job data volumes
+-- resources.storage sizes a data volume attached to each instance and
+-- mounted at /data, unless the instance store is large enough. Its prorated
+-- hourly price is tracked per instance next to the instance price.
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS storage_iops int NOT NULL DEFAULT 0 CHECK (storage_iops >= 0),
+  ADD COLUMN IF NOT EXISTS storage_throughput_mbps int NOT NULL DEFAULT 0 CHECK (storage_throughput_mbps >= 0);
+
+ALTER TABLE allocations
+  ADD COLUMN IF NOT EXISTS volume_type text NULL,
+  ADD COLUMN IF NOT EXISTS volume_gb int NOT NULL DEFAULT 0 CHECK (volume_gb >= 0),
+  ADD COLUMN IF NOT EXISTS storage_price_per_hour numeric(12,6) NOT NULL DEFAULT 0 CHECK (storage_price_per_hour >= 0);
+
+COMMENT ON COLUMN jobs.storage_iops IS 'Provisioned IOPS of the data volume; 0 = volume baseline';
+COMMENT ON COLUMN jobs.storage_throughput_mbps IS 'Provisioned throughput of the data volume in MB/s; 0 = volume baseline';
+COMMENT ON COLUMN allocations.volume_gb IS 'Data volume attached per instance; 0 = none or instance store';
+COMMENT ON COLUMN allocations.storage_price_per_hour IS 'Prorated data volume price per instance per hour';
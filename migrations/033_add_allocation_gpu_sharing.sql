@@ -0,0 +1,9 @@
+-- Migration: Record GPU sharing on allocations
+-- Jobs with gpu_fraction < 1 or use_mig on the Kubernetes backend share GPUs
+-- through time-slicing or MIG, and are billed gpu_share of the instances.
+
+ALTER TABLE allocations
+  ADD COLUMN IF NOT EXISTS gpu_sharing text NULL CHECK (gpu_sharing IN ('time-slicing', 'mig')),
+  ADD COLUMN IF NOT EXISTS gpu_share numeric(6,4) NOT NULL DEFAULT 0 CHECK (gpu_share >= 0 AND gpu_share <= 1);
+
+COMMENT ON COLUMN allocations.gpu_share IS 'Share of the instances billed to the job; 0 = whole instances';
@@ -0,0 +1,21 @@
+-- Migration: Record spot interruptions
+-- Observed interruptions per instance type and region, divided by the spot
+-- instance-hours of allocations, calibrate the reliability model.
+
+CREATE TABLE IF NOT EXISTS spot_interruptions (
+  id              bigserial PRIMARY KEY,
+  job_id          uuid NULL REFERENCES jobs(id) ON DELETE SET NULL,
+  provider        text NOT NULL,
+  instance_type   text NOT NULL,
+  region          text NOT NULL,
+  zone            text NULL,
+  instance_id     text NULL,
+  interrupted_at  timestamptz NOT NULL DEFAULT now(),
+  hour_of_day     int NOT NULL CHECK (hour_of_day >= 0 AND hour_of_day < 24),
+  runtime_seconds bigint NOT NULL DEFAULT 0 CHECK (runtime_seconds >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_spot_interruptions_type
+  ON spot_interruptions (provider, instance_type, region, interrupted_at);
+
+COMMENT ON COLUMN spot_interruptions.hour_of_day IS 'UTC hour of the interruption';
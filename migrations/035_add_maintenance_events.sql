@@ -0,0 +1,22 @@
+-- Migration: Record scheduled provider maintenance
+-- Host maintenance of nodes of running jobs (AWS scheduled events, Azure
+-- Scheduled Events), detected before it reboots the node.
+
+CREATE TABLE IF NOT EXISTS maintenance_events (
+  id            bigserial PRIMARY KEY,
+  job_id        uuid NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  provider      text NOT NULL,
+  region        text NOT NULL,
+  instance_type text NOT NULL,
+  instance_id   text NOT NULL,
+  event_type    text NOT NULL,
+  description   text NULL,
+  not_before    timestamptz NOT NULL,
+  not_after     timestamptz NULL,
+  detected_at   timestamptz NOT NULL DEFAULT now(),
+  action        text NOT NULL DEFAULT 'warned' CHECK (action IN ('warned', 'migrated')),
+  UNIQUE (instance_id, event_type, not_before)
+);
+
+CREATE INDEX IF NOT EXISTS idx_maintenance_events_not_before
+  ON maintenance_events (not_before);
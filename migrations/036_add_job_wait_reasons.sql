@@ -0,0 +1,8 @@
+-- Migration: Record why pending jobs were deferred
+-- The scheduler stores the reasons of its last pass over a pending job that
+-- did not schedule it, for GET /v1/jobs/{id}/why-pending.
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS wait_reasons_json jsonb NULL;
+
+COMMENT ON COLUMN jobs.wait_reasons_json IS 'Reasons the last scheduler pass deferred the job; cleared when scheduled';
@@ -0,0 +1,25 @@
+-- Migration: Instance type allow/deny lists
+-- Per-job globs from resources.instance_types and exclude_instance_types,
+-- and the history of org-wide list changes made through the admin API. The
+-- latest change is the effective org list and survives restarts.
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS instance_types text[] NOT NULL DEFAULT '{}',
+  ADD COLUMN IF NOT EXISTS exclude_instance_types text[] NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN jobs.instance_types IS 'Allowed instance/GPU type globs; empty = any';
+COMMENT ON COLUMN jobs.exclude_instance_types IS 'Denied instance/GPU type globs; deny wins over allow';
+
+CREATE TABLE IF NOT EXISTS instance_type_rule_changes (
+  id             bigserial PRIMARY KEY,
+  allow_patterns text[] NOT NULL DEFAULT '{}',
+  deny_patterns  text[] NOT NULL DEFAULT '{}',
+  previous_allow text[] NOT NULL DEFAULT '{}',
+  previous_deny  text[] NOT NULL DEFAULT '{}',
+  reason         text NULL,
+  remote_addr    text NULL,
+  changed_at     timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_instance_type_rule_changes_at
+  ON instance_type_rule_changes (changed_at DESC);
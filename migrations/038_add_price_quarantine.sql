@@ -0,0 +1,28 @@
+-- Migration: Quarantine anomalous pricing
+-- Refreshed prices far from the stored price or outside the GPU type's
+-- $/GPU-hour band are held here instead of overwriting gpu_pricing, which
+-- keeps serving the last known good price until an admin accepts them.
+
+CREATE TABLE IF NOT EXISTS price_quarantine (
+  id             bigserial PRIMARY KEY,
+  provider       provider NOT NULL,
+  region         text NOT NULL,
+  instance_type  text NOT NULL,
+  gpu_type       text NOT NULL,
+  price_kind     text NOT NULL CHECK (price_kind IN ('on_demand', 'spot')),
+  price          numeric(12,6) NOT NULL,
+  previous_price numeric(12,6) NULL,
+  reason         text NOT NULL CHECK (reason IN ('deviation', 'out_of_band')),
+  status         text NOT NULL DEFAULT 'quarantined' CHECK (status IN ('quarantined', 'accepted', 'cleared')),
+  occurrences    int NOT NULL DEFAULT 1 CHECK (occurrences > 0),
+  detected_at    timestamptz NOT NULL DEFAULT now(),
+  last_seen_at   timestamptz NOT NULL DEFAULT now(),
+  resolved_at    timestamptz NULL,
+  UNIQUE (provider, region, instance_type, price_kind)
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_quarantine_status
+  ON price_quarantine (status, last_seen_at DESC);
+
+COMMENT ON COLUMN price_quarantine.price IS 'Latest anomalous price the provider returned';
+COMMENT ON COLUMN price_quarantine.occurrences IS 'Refreshes that returned an anomalous price since it was quarantined';
@@ -0,0 +1,14 @@
+-- Migration: Persist job topology manifests
+-- The nodes, ranks and master endpoint of a running job, assembled at launch
+-- from the cluster and the framework setup and updated on elastic resizes,
+-- for GET /v1/jobs/{id}/topology. Jobs with execution.export_topology also
+-- get the manifest on their nodes and as a topology artifact.
+-- Note: ALTER TYPE ... ADD VALUE cannot run inside a transaction block on PostgreSQL < 12.
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS topology_json jsonb NULL,
+  ADD COLUMN IF NOT EXISTS export_topology boolean NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN jobs.topology_json IS 'Versioned topology manifest of the running job';
+
+ALTER TYPE artifact_type ADD VALUE IF NOT EXISTS 'topology';
@@ -0,0 +1,16 @@
+-- Migration: Record availability zones of allocations and tasks
+-- Instances of one cluster are launched in a single zone, chosen by spot
+-- price and placement score. multi_task jobs with placement.spread: az put
+-- their tasks in distinct zones.
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS placement_spread text NULL CHECK (placement_spread IN ('az'));
+
+ALTER TABLE allocations
+  ADD COLUMN IF NOT EXISTS zone text NULL;
+
+ALTER TABLE job_tasks
+  ADD COLUMN IF NOT EXISTS zone text NULL;
+
+COMMENT ON COLUMN jobs.placement_spread IS 'az = multi_task tasks in distinct availability zones';
+COMMENT ON COLUMN allocations.zone IS 'Availability zone the instances are launched in; NULL = provider choice';
@@ -0,0 +1,40 @@
+-- Migration: Append-only audit log
+-- One row per mutating API call (POST, PUT, PATCH, DELETE) and per job
+-- status change. Rows are never updated; only the retention worker deletes
+-- rows older than AUDIT_RETENTION_DAYS.
+
+CREATE TABLE IF NOT EXISTS audit_log (
+  id             bigserial PRIMARY KEY,
+  actor          text NOT NULL,
+  action         text NOT NULL,
+  resource_type  text NULL,
+  resource_id    text NULL,
+  request_json   jsonb NULL,
+  status_code    int NULL,
+  remote_addr    text NULL,
+  created_at     timestamptz NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at
+  ON audit_log (created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor
+  ON audit_log (actor, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_resource
+  ON audit_log (resource_type, resource_id);
+
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+CREATE TRIGGER audit_log_append_only
+  BEFORE UPDATE ON audit_log
+  FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+
+COMMENT ON TABLE audit_log IS 'Append-only record of mutating API calls and job status changes';
+COMMENT ON COLUMN audit_log.actor IS 'Caller identity, admin, anonymous or system';
+COMMENT ON COLUMN audit_log.request_json IS 'Request summary with secret values redacted';
@@ -0,0 +1,8 @@
+-- Migration: Framework launch settings
+-- DeepSpeed jobs carry a framework_config block: ZeRO stage, accelerator and
+-- a ds_config given by URI or inline.
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS framework_config_json jsonb NULL;
+
+COMMENT ON COLUMN jobs.framework_config_json IS 'Spec framework_config block; NULL = framework defaults';
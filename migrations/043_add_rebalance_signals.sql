@@ -0,0 +1,26 @@
+-- Migration: Record spot rebalance recommendations
+-- Provider warnings that a spot node of a running job is at elevated risk of
+-- interruption (EC2 rebalance recommendations), with the checkpoint requested
+-- and the replacement node provisioned ahead of the interruption.
+
+CREATE TABLE IF NOT EXISTS rebalance_signals (
+  id                      bigserial PRIMARY KEY,
+  job_id                  uuid NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  provider                text NOT NULL,
+  region                  text NOT NULL,
+  instance_type           text NOT NULL,
+  instance_id             text NOT NULL UNIQUE,
+  noticed_at              timestamptz NOT NULL,
+  detected_at             timestamptz NOT NULL DEFAULT now(),
+  checkpoint_requested_at timestamptz NULL,
+  checkpoint_saved_at     timestamptz NULL,
+  checkpoint_uri          text NULL,
+  work_saved_seconds      bigint NULL,
+  replacement_node_id     text NULL,
+  replacement_started_at  timestamptz NULL,
+  interrupted_at          timestamptz NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_rebalance_signals_open
+  ON rebalance_signals (job_id)
+  WHERE interrupted_at IS NULL;
@@ -0,0 +1,14 @@
+-- Migration: Per-job carbon footprint
+-- carbon_weight weights the optimizer's emissions term; emissions_gco2e is the
+-- estimated footprint of the job's GPU-hours, stored once the job finishes.
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS carbon_weight numeric(4,3) NOT NULL DEFAULT 0.000 CHECK (carbon_weight >= 0 AND carbon_weight <= 1),
+  ADD COLUMN IF NOT EXISTS emissions_gco2e numeric(14,2) NULL;
+
+COMMENT ON COLUMN jobs.carbon_weight IS 'constraints.carbon_weight; 0 = emissions not scored';
+COMMENT ON COLUMN jobs.emissions_gco2e IS 'Estimated gCO2e of the job''s GPU-hours; NULL until accounted after the job finishes';
+
+-- Finished jobs still to be accounted
+CREATE INDEX IF NOT EXISTS idx_jobs_emissions_pending ON jobs(updated_at)
+  WHERE emissions_gco2e IS NULL AND status IN ('completed', 'failed', 'cancelled');
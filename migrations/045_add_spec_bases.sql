@@ -0,0 +1,8 @@
+-- Migration: Spec inheritance provenance
+-- Specs may extend a base spec; spec_yaml stores the resolved spec and
+-- spec_bases_json the bases it was merged onto, with the fetched revisions.
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS spec_bases_json jsonb NULL;
+
+COMMENT ON COLUMN jobs.spec_bases_json IS 'Bases the spec extends, nearest first ([{ref, version}]); NULL = no extends';
@@ -0,0 +1,6 @@
+-- Migration: Add console artifacts
+-- Console attach sessions record their keystrokes and terminal output as a
+-- transcript artifact per session.
+-- Note: ALTER TYPE ... ADD VALUE cannot run inside a transaction block on PostgreSQL < 12.
+
+ALTER TYPE artifact_type ADD VALUE IF NOT EXISTS 'console';
@@ -0,0 +1,16 @@
+-- Migration: Failure post-mortem bundles
+-- When a job fails, its spec, launch config, node log tails, event timeline,
+-- allocations, cost and provider errors are collected into one bundle
+-- artifact, linked from the failure event.
+-- Note: ALTER TYPE ... ADD VALUE cannot run inside a transaction block on PostgreSQL < 12.
+
+ALTER TYPE artifact_type ADD VALUE IF NOT EXISTS 'postmortem';
+
+ALTER TABLE job_events
+  ADD COLUMN IF NOT EXISTS artifact_id bigint NULL REFERENCES job_artifacts(id) ON DELETE SET NULL;
+
+COMMENT ON COLUMN job_events.artifact_id IS 'Artifact the event links to, e.g. the post-mortem bundle of a failure';
+
+-- Failure events still waiting for a bundle
+CREATE INDEX IF NOT EXISTS idx_job_events_postmortem_pending ON job_events(at)
+  WHERE to_status = 'failed' AND artifact_id IS NULL;
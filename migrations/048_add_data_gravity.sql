@@ -0,0 +1,10 @@
+-- Migration: Data gravity opt-out
+-- Jobs without a region constraint get a small score bonus toward regions
+-- holding their team's datasets, checkpoints and completed jobs;
+-- constraints.data_gravity: false opts a job out.
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS ignore_data_gravity boolean NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN jobs.ignore_data_gravity IS 'constraints.data_gravity: false; no bonus toward the team''s data regions';
+
@@ -0,0 +1,9 @@
+-- Migration: Declared checkpoint schedule
+-- The checkpointing spec block (interval_steps or interval_minutes, URI
+-- prefix) is exposed to the training process; the job monitor warns when a
+-- spot-backed job falls behind it.
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS checkpointing_json jsonb NULL;
+
+COMMENT ON COLUMN jobs.checkpointing_json IS 'Declared checkpoint cadence (interval_steps | interval_minutes, uri)';
@@ -0,0 +1,18 @@
+-- Migration: Spot bid strategies
+-- constraints.spot_bid_strategy sets the max price of a job's spot requests
+-- (low = 1.1x current spot or spot_bid_cap, market = none, on_demand_cap =
+-- the on-demand rate). The bid is recorded on the allocation so it can be
+-- compared with the price paid.
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS spot_bid_strategy text NULL CHECK (spot_bid_strategy IN ('low', 'market', 'on_demand_cap')),
+  ADD COLUMN IF NOT EXISTS spot_bid_cap numeric(12,6) NULL CHECK (spot_bid_cap > 0);
+
+COMMENT ON COLUMN jobs.spot_bid_strategy IS 'constraints.spot_bid_strategy; NULL = market';
+COMMENT ON COLUMN jobs.spot_bid_cap IS 'Explicit low bid in USD per instance-hour; NULL = 1.1x current spot';
+
+ALTER TABLE allocations
+  ADD COLUMN IF NOT EXISTS spot_bid_strategy text NULL,
+  ADD COLUMN IF NOT EXISTS spot_bid_usd numeric(12,6) NULL;
+
+COMMENT ON COLUMN allocations.spot_bid_usd IS 'Max spot price per instance-hour requested; NULL = no max price';
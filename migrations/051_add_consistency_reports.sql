@@ -0,0 +1,15 @@
+-- Migration: Add consistency reports
+-- A periodic read-only check compares jobs and allocations with the
+-- instances providers report and with recorded cost, and keeps what it
+-- found here for operators.
+
+CREATE TABLE IF NOT EXISTS consistency_reports (
+  id                  bigserial PRIMARY KEY,
+  started_at          timestamptz NOT NULL,
+  finished_at         timestamptz NOT NULL,
+  counts_json         jsonb NOT NULL,   -- {category: discrepancies}
+  discrepancies_json  jsonb NOT NULL,   -- [{category, job_id, instance_id, ...}]
+  skipped_json        jsonb NOT NULL DEFAULT '[]'
+);
+
+CREATE INDEX IF NOT EXISTS idx_consistency_reports_started ON consistency_reports (started_at DESC);
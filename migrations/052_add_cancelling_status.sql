@@ -0,0 +1,7 @@
+-- Migration: Add the cancelling job status
+-- Cancelling a running job first moves it to cancelling for a grace window,
+-- during which the cancellation can be undone; the job keeps running and
+-- accruing cost until the window ends and it is cancelled.
+-- Note: ALTER TYPE ... ADD VALUE cannot run inside a transaction block on PostgreSQL < 12.
+
+ALTER TYPE job_status ADD VALUE IF NOT EXISTS 'cancelling';
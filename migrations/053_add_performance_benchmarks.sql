@@ -0,0 +1,16 @@
+-- Migration: Add learned performance benchmarks
+-- Per-GPU throughput observed on jobs, per framework, GPU type and model
+-- class. The optimizer loads these at startup and prefers them over the
+-- shipped baselines once enough jobs have been observed.
+
+CREATE TABLE IF NOT EXISTS performance_benchmarks (
+  framework        text NOT NULL,
+  gpu_type         text NOT NULL,
+  model_class      text NOT NULL,
+  steps_per_hour   double precision NOT NULL,
+  samples          integer NOT NULL,
+  tokens_per_hour  double precision NOT NULL DEFAULT 0,
+  token_samples    integer NOT NULL DEFAULT 0,
+  updated_at       timestamptz NOT NULL,
+  PRIMARY KEY (framework, gpu_type, model_class)
+);
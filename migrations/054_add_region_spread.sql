@@ -0,0 +1,9 @@
+-- Migration: Region spread of multi_task jobs
+-- execution.spread bounds how many regions the geo-distributed strategy
+-- spreads a multi_task job's tasks over (max_regions) and how few tasks a
+-- region may get (min_tasks_per_region).
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS region_spread_json jsonb NULL;
+
+COMMENT ON COLUMN jobs.region_spread_json IS 'execution.spread {max_regions, min_tasks_per_region}; NULL = every region, 1 task minimum';
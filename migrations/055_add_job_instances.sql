@@ -0,0 +1,24 @@
+-- Migration: Add per-instance launch and termination records
+-- Every provider instance launched for a job, with the price it was launched
+-- at. Infrastructure is billed from launch to termination, independent of
+-- job status transitions; the row outlives its allocation, which is
+-- replaced when a job is requeued.
+
+CREATE TABLE IF NOT EXISTS job_instances (
+  id              bigserial PRIMARY KEY,
+  job_id          uuid NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  allocation_id   bigint NULL REFERENCES allocations(id) ON DELETE SET NULL, -- NULL for elastic growth
+  provider        text NOT NULL,
+  region          text NOT NULL,
+  instance_type   text NOT NULL,
+  instance_id     text NOT NULL,
+  spot            boolean NOT NULL DEFAULT false,
+  price_per_hour  double precision NOT NULL CHECK (price_per_hour >= 0),
+  launched_at     timestamptz NOT NULL,
+  terminated_at   timestamptz NULL,
+  UNIQUE (provider, region, instance_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_instances_job ON job_instances (job_id);
+CREATE INDEX IF NOT EXISTS idx_job_instances_open ON job_instances (provider, region) WHERE terminated_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_job_instances_launched ON job_instances (launched_at);
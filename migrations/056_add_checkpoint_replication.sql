@@ -0,0 +1,18 @@
+-- Migration: Add checkpoint replication
+-- Checkpoints of jobs with checkpointing.replicate_to are copied to a
+-- secondary location by the replicator; the replica URI, lag and cost go to
+-- the artifact metadata. The transfer cost is charged to the job.
+
+ALTER TABLE job_artifacts
+  ADD COLUMN IF NOT EXISTS replica_status text NULL
+    CHECK (replica_status IN ('pending', 'replicated', 'failed', 'none')),
+  ADD COLUMN IF NOT EXISTS replica_attempts int NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_artifacts_replica_pending ON job_artifacts (created_at)
+  WHERE type = 'checkpoint' AND deleted_at IS NULL AND (replica_status IS NULL OR replica_status = 'pending');
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS cost_transfer_usd numeric(12, 4) NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN job_artifacts.replica_status IS 'Checkpoint replication: pending | replicated | failed | none (job does not replicate); NULL = not seen yet';
+COMMENT ON COLUMN jobs.cost_transfer_usd IS 'Data transfer charged to the job (checkpoint replication), priced by the transfer pricing table';
@@ -0,0 +1,17 @@
+-- Migration: Track ports handed out to jobs on each node
+-- Jobs sharing a node (GPU sharing, bin packing) get distinct master and
+-- worker ports from a configured range. Rows are deleted when the job ends.
+
+CREATE TABLE IF NOT EXISTS node_ports (
+  node          text NOT NULL,   -- <provider>/<instance id, or node id without one>
+  port          int NOT NULL CHECK (port > 0 AND port < 65536),
+  job_id        uuid NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  service       text NOT NULL,   -- master | worker
+  allocated_at  timestamptz NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (node, port),
+  UNIQUE (node, job_id, service)
+);
+
+CREATE INDEX IF NOT EXISTS idx_node_ports_job ON node_ports (job_id);
+
+COMMENT ON TABLE node_ports IS 'Ports held by jobs on nodes; a job keeps its port for a service across relaunches on the node';
@@ -0,0 +1,62 @@
+-- Migration: Pricing subscriptions
+-- Users subscribe to price conditions on instance or GPU types (spot below
+-- $X/GPU-hour, a drop of Y% within a window). Subscriptions are evaluated
+-- after every pricing refresh. Stored prices are kept as a change history so
+-- drops can be measured against the window's peak.
+
+CREATE TABLE IF NOT EXISTS gpu_price_history (
+  id             bigserial PRIMARY KEY,
+  provider       provider NOT NULL,
+  region         text NOT NULL,
+  instance_type  text NOT NULL,
+  price_kind     text NOT NULL CHECK (price_kind IN ('on_demand', 'spot')),
+  price          numeric(12,6) NOT NULL CHECK (price >= 0),
+  recorded_at    timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_gpu_price_history_key
+  ON gpu_price_history (provider, region, instance_type, price_kind, recorded_at DESC);
+
+COMMENT ON TABLE gpu_price_history IS 'A row per change of a stored price; the latest row per key is the current price';
+
+CREATE TABLE IF NOT EXISTS pricing_subscriptions (
+  id              bigserial PRIMARY KEY,
+  user_id         text NOT NULL,
+  name            text NOT NULL,
+  instance_types  text[] NOT NULL DEFAULT '{}',
+  gpu_types       text[] NOT NULL DEFAULT '{}',
+  regions         text[] NOT NULL DEFAULT '{}',   -- Empty = every region
+  price_kind      text NOT NULL DEFAULT 'spot' CHECK (price_kind IN ('on_demand', 'spot')),
+  condition       text NOT NULL CHECK (condition IN ('below', 'drop')),
+  threshold       numeric(12,6) NOT NULL CHECK (threshold > 0),
+  window_seconds  bigint NOT NULL DEFAULT 0,
+  webhook_url     text NULL,
+  enabled         boolean NOT NULL DEFAULT true,
+  created_at      timestamptz NOT NULL DEFAULT now(),
+  updated_at      timestamptz NOT NULL DEFAULT now(),
+  UNIQUE (user_id, name)
+);
+
+COMMENT ON COLUMN pricing_subscriptions.threshold IS 'USD per GPU-hour for below, percent for drop';
+COMMENT ON COLUMN pricing_subscriptions.window_seconds IS 'How far back drop conditions look for the peak price';
+
+CREATE TABLE IF NOT EXISTS pricing_notifications (
+  id               bigserial PRIMARY KEY,
+  subscription_id  bigint NOT NULL REFERENCES pricing_subscriptions(id) ON DELETE CASCADE,
+  provider         provider NOT NULL,
+  region           text NOT NULL,
+  instance_type    text NOT NULL,
+  gpu_type         text NOT NULL,
+  old_price        numeric(12,6) NULL,   -- USD per GPU-hour; NULL when nothing was stored before
+  new_price        numeric(12,6) NOT NULL,
+  fired_at         timestamptz NOT NULL DEFAULT now(),
+  cleared_at       timestamptz NULL
+);
+
+-- One open notification per subscription and instance type: a condition that
+-- persists notifies once, and re-arms when it clears
+CREATE UNIQUE INDEX IF NOT EXISTS uq_pricing_notifications_open
+  ON pricing_notifications (subscription_id, provider, region, instance_type)
+  WHERE cleared_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_pricing_notifications_subscription
+  ON pricing_notifications (subscription_id, fired_at DESC);
@@ -0,0 +1,17 @@
+-- Migration: Task groups of multi_task jobs
+-- execution.task_groups declares task shapes (e.g. GPU-heavy trainers and
+-- light evaluators) the optimizer plans independently. Allocations and tasks
+-- record the group they run so status and cost roll up per group.
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS task_groups_json jsonb NULL;
+
+ALTER TABLE allocations
+  ADD COLUMN IF NOT EXISTS task_group text NULL;
+
+ALTER TABLE job_tasks
+  ADD COLUMN IF NOT EXISTS task_group text NULL;
+
+COMMENT ON COLUMN jobs.task_groups_json IS 'execution.task_groups [{name, tasks, gpus_per_task, gpu_memory, instance_types}]; NULL = one task shape';
+COMMENT ON COLUMN allocations.task_group IS 'Task group of a multi_task job the allocation runs; NULL = no groups';
+COMMENT ON COLUMN job_tasks.task_group IS 'Task group of the task; NULL = no groups';
@@ -0,0 +1,47 @@
+-- Migration: Machine image registry
+-- Pre-baked images per provider, region and instance family boot straight
+-- into readiness instead of installing onto the generic GPU image. Validated
+-- images are preferred by the provisioner; each has a bootstrap time budget,
+-- and an image whose observed boots exceed it is flagged degraded and skipped
+-- until an operator validates it again. Jobs may pin a registered image.
+
+CREATE TABLE IF NOT EXISTS machine_images (
+  id                  bigserial PRIMARY KEY,
+  provider            provider NOT NULL,
+  region              text NOT NULL,
+  instance_family     text NOT NULL,
+  image_id            text NOT NULL,
+  build_date          date NULL,
+  validation          text NOT NULL DEFAULT 'pending' CHECK (validation IN ('pending', 'validated', 'failed')),
+  validated_at        timestamptz NULL,
+  boot_budget_seconds int NOT NULL CHECK (boot_budget_seconds > 0),
+  degraded_at         timestamptz NULL,
+  degraded_reason     text NULL,
+  created_at          timestamptz NOT NULL DEFAULT now(),
+  updated_at          timestamptz NOT NULL DEFAULT now(),
+  UNIQUE (provider, region, instance_family, image_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_machine_images_image ON machine_images (image_id);
+
+COMMENT ON COLUMN machine_images.validated_at IS 'Last validation; boots observed before it no longer count toward degradation';
+COMMENT ON COLUMN machine_images.degraded_at IS 'Observed boots exceeded the budget; the image is not preferred until validated again';
+
+CREATE TABLE IF NOT EXISTS machine_image_boots (
+  id               bigserial PRIMARY KEY,
+  machine_image_id bigint NOT NULL REFERENCES machine_images(id) ON DELETE CASCADE,
+  job_id           uuid NULL,
+  instance_type    text NOT NULL,
+  boot_seconds     numeric(12,3) NOT NULL CHECK (boot_seconds >= 0),
+  observed_at      timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_machine_image_boots_image
+  ON machine_image_boots (machine_image_id, observed_at DESC);
+
+COMMENT ON TABLE machine_image_boots IS 'Bootstrap time of instances launched from a registered image, from launch request to readiness';
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS image_id text NULL;
+
+COMMENT ON COLUMN jobs.image_id IS 'resources.image: registered machine image the job pins; NULL = the registry or generic image';
@@ -0,0 +1,10 @@
+-- Migration: Bulk job submission batches
+-- POST /v1/jobs/batch submits many related jobs (e.g. a sweep) at once and
+-- links the jobs it created by a batch ID.
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS batch_id uuid NULL;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_batch ON jobs (batch_id) WHERE batch_id IS NOT NULL;
+
+COMMENT ON COLUMN jobs.batch_id IS 'Bulk submission the job was created by; NULL = submitted on its own';
@@ -0,0 +1,27 @@
+-- Migration: Per-cluster SSH keys
+-- Every VM cluster gets its own ephemeral keypair instead of one static key:
+-- the public key is installed on its instances at boot and the private key
+-- is stored envelope-encrypted. Rotation pushes a new key to the running
+-- nodes and retires the old one; terminating the cluster destroys them all.
+
+CREATE TABLE IF NOT EXISTS cluster_ssh_keys (
+  id           bigserial PRIMARY KEY,
+  job_id       uuid NULL,
+  cluster_id   text NULL,
+  fingerprint  text NOT NULL UNIQUE,
+  public_key   text NOT NULL,
+  private_key  text NULL,
+  status       text NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'active', 'retired', 'destroyed')),
+  created_at   timestamptz NOT NULL DEFAULT now(),
+  activated_at timestamptz NULL,
+  retired_at   timestamptz NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_cluster_ssh_keys_cluster ON cluster_ssh_keys (cluster_id, created_at DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_cluster_ssh_keys_active
+  ON cluster_ssh_keys (cluster_id)
+  WHERE status = 'active';
+
+COMMENT ON COLUMN cluster_ssh_keys.cluster_id IS 'Cluster whose instances trust the key; NULL while its instances are still launching';
+COMMENT ON COLUMN cluster_ssh_keys.private_key IS 'Envelope-encrypted private key; NULL once the key is retired or destroyed';
+COMMENT ON COLUMN cluster_ssh_keys.status IS 'pending = issued, not yet trusted by a cluster; active = used for SSH; retired = replaced by rotation; destroyed = cluster terminated';
@@ -0,0 +1,55 @@
+-- Migration: GPU-hour quotas
+-- Monthly GPU-hour limits per team or project, for capacity that is shared
+-- by hours rather than dollars (e.g. on-prem). Consumption is computed from
+-- the allocations that ran; jobs reserve their estimated GPU-hours when they
+-- are scheduled. Unused hours may carry over into the next month.
+
+CREATE TABLE IF NOT EXISTS gpu_hour_quotas (
+  id                   bigserial PRIMARY KEY,
+  scope                text NOT NULL CHECK (scope IN ('team', 'project')),
+  scope_id             text NOT NULL,
+  monthly_hours        double precision NOT NULL CHECK (monthly_hours >= 0),
+  carry_over           text NOT NULL DEFAULT 'none' CHECK (carry_over IN ('none', 'unused')),
+  carry_over_max_hours double precision NOT NULL DEFAULT 0 CHECK (carry_over_max_hours >= 0),
+  created_at           timestamptz NOT NULL DEFAULT now(),
+  updated_at           timestamptz NOT NULL DEFAULT now(),
+  UNIQUE (scope, scope_id)
+);
+
+CREATE TABLE IF NOT EXISTS gpu_hour_quota_periods (
+  quota_id       bigint NOT NULL REFERENCES gpu_hour_quotas(id) ON DELETE CASCADE,
+  period_start   timestamptz NOT NULL,
+  carried_hours  double precision NOT NULL DEFAULT 0,
+  consumed_hours double precision NULL,
+  closed_at      timestamptz NULL,
+  PRIMARY KEY (quota_id, period_start)
+);
+
+CREATE TABLE IF NOT EXISTS gpu_hour_quota_adjustments (
+  id           bigserial PRIMARY KEY,
+  quota_id     bigint NOT NULL REFERENCES gpu_hour_quotas(id) ON DELETE CASCADE,
+  period_start timestamptz NOT NULL,
+  hours        double precision NOT NULL,
+  reason       text NOT NULL,
+  actor        text NOT NULL,
+  created_at   timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_gpu_hour_quota_adjustments_period
+  ON gpu_hour_quota_adjustments (quota_id, period_start);
+
+CREATE TABLE IF NOT EXISTS gpu_hour_reservations (
+  job_id       uuid NOT NULL,
+  quota_id     bigint NOT NULL REFERENCES gpu_hour_quotas(id) ON DELETE CASCADE,
+  period_start timestamptz NOT NULL,
+  hours        double precision NOT NULL CHECK (hours >= 0),
+  created_at   timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY (job_id, quota_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_gpu_hour_reservations_quota ON gpu_hour_reservations (quota_id, period_start);
+
+COMMENT ON COLUMN gpu_hour_quotas.carry_over IS 'none = each month starts at monthly_hours; unused = hours left at month end are added to the next month';
+COMMENT ON COLUMN gpu_hour_quotas.carry_over_max_hours IS 'Cap on carried hours; 0 = no cap';
+COMMENT ON COLUMN gpu_hour_quota_periods.consumed_hours IS 'GPU-hours consumed in the month, recorded when it is closed';
+COMMENT ON COLUMN gpu_hour_reservations.hours IS 'Estimated GPU-hours held for a scheduled job until it consumes them or ends';
@@ -0,0 +1,36 @@
+-- Migration: Job network egress
+-- The egress tracker samples a byte counter on every node of a running job:
+-- bytes sent to addresses outside private networks, or bytes sent on the
+-- node's interfaces where the counter cannot be installed. Deltas are priced
+-- as internet transfer and charged to the job per UTC day, separately from
+-- checkpoint replication.
+
+CREATE TABLE IF NOT EXISTS job_egress_counters (
+  job_id        uuid NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  node_id       text NOT NULL,
+  counter_bytes bigint NOT NULL CHECK (counter_bytes >= 0),
+  source        text NOT NULL CHECK (source IN ('iptables', 'netdev')),
+  sampled_at    timestamptz NOT NULL,
+  PRIMARY KEY (job_id, node_id)
+);
+
+CREATE TABLE IF NOT EXISTS job_egress_daily (
+  job_id   uuid NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  day      timestamptz NOT NULL,
+  provider text NOT NULL,
+  region   text NOT NULL,
+  bytes    bigint NOT NULL DEFAULT 0,
+  cost_usd numeric(12, 4) NOT NULL DEFAULT 0,
+  PRIMARY KEY (job_id, day, provider, region)
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_egress_daily_day ON job_egress_daily (day);
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS egress_bytes bigint NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS cost_egress_usd numeric(12, 4) NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS egress_alerted_at timestamptz NULL;
+
+COMMENT ON COLUMN job_egress_counters.counter_bytes IS 'Last counter value read from the node; a lower value means the counter was reset';
+COMMENT ON COLUMN jobs.cost_egress_usd IS 'Network egress charged to the job, priced as internet transfer by the transfer pricing table';
+COMMENT ON COLUMN jobs.egress_alerted_at IS 'When the egress cost alert fired; it fires once per job';
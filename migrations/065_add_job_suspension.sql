@@ -0,0 +1,9 @@
+-- Migration: Job suspension
+-- POST /v1/jobs/{id}/suspend moves a running job to checkpointing and asks
+-- its training wrapper for a checkpoint; the suspension sweep terminates the
+-- cluster once the checkpoint is recorded or the wait times out and moves the
+-- job to suspended. The request time tells a suspension from a migration and
+-- stays until the job is resumed, so resuming can warn about lost progress.
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS suspend_requested_at timestamptz NULL;
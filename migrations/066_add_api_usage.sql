@@ -0,0 +1,15 @@
+-- Migration: Add daily rollups of API requests
+-- Each replica meters the requests it serves and adds its counts to the row
+-- of the UTC day, endpoint, client and job.
+
+CREATE TABLE IF NOT EXISTS api_usage (
+  day         text NOT NULL,  -- UTC date, YYYY-MM-DD
+  endpoint    text NOT NULL,  -- Method and route template, e.g. GET /v1/jobs/{id}
+  client      text NOT NULL,  -- Caller identity; _other past API_USAGE_MAX_CLIENTS
+  job_id      text NOT NULL DEFAULT '',  -- Job of job-scoped routes; _other past API_USAGE_MAX_JOBS
+  requests    bigint NOT NULL DEFAULT 0,
+  errors      bigint NOT NULL DEFAULT 0,  -- 4xx and 5xx responses
+  latency_ms  numeric(16,3) NOT NULL DEFAULT 0,  -- Total latency of the requests
+  updated_at  timestamptz NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (day, endpoint, client, job_id)
+);
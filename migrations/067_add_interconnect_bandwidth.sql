@@ -0,0 +1,13 @@
+-- Migration: Add numeric interconnect bandwidth
+-- Multi-node jobs can require a minimum node-to-node bandwidth
+-- (resources.min_interconnect_gbps) instead of relying on the interconnect tier.
+
+ALTER TABLE gpu_pricing
+  ADD COLUMN IF NOT EXISTS interconnect_gbps numeric(8,2) NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN gpu_pricing.interconnect_gbps IS 'Node-to-node bandwidth for collectives (EFA, InfiniBand) in Gbps; 0 = unknown, judged by interconnect';
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS min_interconnect_gbps numeric(8,2) NOT NULL DEFAULT 0 CHECK (min_interconnect_gbps >= 0);
+
+COMMENT ON COLUMN jobs.min_interconnect_gbps IS 'Least node-to-node bandwidth of multi-node placements (resources.min_interconnect_gbps); 0 = default';
@@ -0,0 +1,10 @@
+-- Migration: Tie GPU-hour reservations to their jobs
+-- Reservations were the last job-scoped rows without a foreign key. The
+-- constraint is NOT VALID: new rows are checked at once, while reservations
+-- of jobs already gone are left to the orphan cleaner
+-- (POST /v1/admin/orphans/cleanup). Validate it once the cleaner removed them:
+--   ALTER TABLE gpu_hour_reservations VALIDATE CONSTRAINT gpu_hour_reservations_job_id_fkey;
+
+ALTER TABLE gpu_hour_reservations
+  ADD CONSTRAINT gpu_hour_reservations_job_id_fkey
+  FOREIGN KEY (job_id) REFERENCES jobs(id) ON DELETE CASCADE NOT VALID;
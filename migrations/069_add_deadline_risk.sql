@@ -0,0 +1,11 @@
+-- Migration: Deadline risk
+-- The deadline risk evaluator stores its latest assessment of every
+-- unfinished job with a deadline, and the highest risk level the job's owner
+-- was notified of, so each level is notified once.
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS deadline_risk_json jsonb NULL,
+  ADD COLUMN IF NOT EXISTS deadline_risk_notified smallint NOT NULL DEFAULT 0
+    CHECK (deadline_risk_notified BETWEEN 0 AND 3);
+
+COMMENT ON COLUMN jobs.deadline_risk_notified IS 'Highest deadline risk level notified: 0 none, 1 watch, 2 at_risk, 3 likely_miss';
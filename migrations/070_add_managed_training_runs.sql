@@ -0,0 +1,34 @@
+-- Migration: Managed training runs
+-- Jobs on the managed backend are handed to a provider's training service
+-- (SageMaker Training, Vertex AI Custom Training). Each submission is
+-- recorded with the service's job ID, the prices it was placed at and the
+-- status and billable time the service last reported, so the watcher can
+-- resume polling after a restart.
+
+CREATE TABLE IF NOT EXISTS managed_training_runs (
+  id               bigserial PRIMARY KEY,
+  job_id           uuid NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  provider         text NOT NULL,
+  region           text NOT NULL,
+  service_job_id   text NOT NULL,
+  instance_type    text NOT NULL,
+  instance_count   int NOT NULL CHECK (instance_count > 0),
+  spot             boolean NOT NULL DEFAULT false,
+  on_demand_price  numeric(12,6) NOT NULL CHECK (on_demand_price >= 0),
+  spot_price       numeric(12,6) NOT NULL DEFAULT 0 CHECK (spot_price >= 0),
+  status           text NOT NULL DEFAULT 'pending'
+    CHECK (status IN ('pending', 'running', 'stopping', 'completed', 'failed', 'stopped')),
+  status_detail    text NULL,
+  billable_seconds bigint NOT NULL DEFAULT 0 CHECK (billable_seconds >= 0),
+  cost_usd         numeric(12,4) NOT NULL DEFAULT 0 CHECK (cost_usd >= 0),
+  submitted_at     timestamptz NOT NULL DEFAULT now(),
+  started_at       timestamptz NULL,
+  ended_at         timestamptz NULL,
+  updated_at       timestamptz NOT NULL DEFAULT now(),
+  UNIQUE (provider, service_job_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_managed_training_runs_job ON managed_training_runs (job_id);
+CREATE INDEX IF NOT EXISTS idx_managed_training_runs_active
+  ON managed_training_runs (submitted_at)
+  WHERE ended_at IS NULL;
@@ -0,0 +1,11 @@
+-- Migration: Scheduling retries
+-- A pending job the optimizer places nowhere records a failed scheduling
+-- attempt and is retried with exponential backoff until it is placed or runs
+-- out of attempts, when it fails with reason no_allocation.
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS schedule_attempts int NOT NULL DEFAULT 0 CHECK (schedule_attempts >= 0),
+  ADD COLUMN IF NOT EXISTS next_schedule_at timestamptz NULL;
+
+COMMENT ON COLUMN jobs.schedule_attempts IS 'Failed scheduling attempts since the job was last placed or its constraints changed';
+COMMENT ON COLUMN jobs.next_schedule_at IS 'The scheduler leaves the pending job alone until then; NULL = no backoff';
@@ -0,0 +1,11 @@
+// Package migrations holds the database schema. The numbered files are the
+// PostgreSQL migrations; sqlite/schema.sql is the same schema for SQLite
+// (single-node/dev mode) and must be kept in step with them.
+package migrations
+
+import _ "embed"
+
+// SQLiteSchema creates the full schema in a SQLite database. It is idempotent.
+//
+//go:embed sqlite/schema.sql
+var SQLiteSchema string
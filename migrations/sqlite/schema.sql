@@ -0,0 +1,789 @@
+-- SQLite schema for single-node/dev deployments (DATABASE_URL=sqlite:…).
+-- Equivalent to the PostgreSQL migrations 001-021: enums are text, jsonb is
+-- text holding JSON, arrays are text in PostgreSQL array syntax ('{1,2}'),
+-- and timestamps are TIMESTAMP text. Applied on every start; keep it
+-- idempotent and update it with each new migration.
+
+-- ---------- EXPERIMENTS ----------
+CREATE TABLE IF NOT EXISTS experiments (
+  id            uuid PRIMARY KEY,
+  name          text NOT NULL UNIQUE,
+  description   text NOT NULL DEFAULT '',
+  labels_json   text NOT NULL DEFAULT '{}',
+  created_at    timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- ---------- JOBS ----------
+CREATE TABLE IF NOT EXISTS jobs (
+  id                uuid PRIMARY KEY,
+  user_id           text NOT NULL,
+  name              text NOT NULL,
+  team_id           text,
+  project_id        text,
+
+  job_type          text NOT NULL,
+  framework         text NOT NULL,
+  entrypoint_uri    text NOT NULL,
+  dataset_uri       text NOT NULL,
+
+  execution_mode    text NOT NULL,
+  status            text NOT NULL DEFAULT 'pending',
+
+  -- Resources
+  gpus              int NOT NULL CHECK (gpus > 0),
+  max_gpus_per_node int NOT NULL CHECK (max_gpus_per_node > 0),
+  requires_multi_node boolean NOT NULL DEFAULT false,
+  gpu_memory_gb     int NOT NULL CHECK (gpu_memory_gb > 0),
+  cpu_memory_gb     int NOT NULL CHECK (cpu_memory_gb >= 0),
+  storage_gb        int NOT NULL CHECK (storage_gb >= 0),
+  estimated_hours   real NOT NULL CHECK (estimated_hours > 0),
+
+  -- Data rules
+  locality          text NOT NULL DEFAULT 'prefer',
+  replication       text NOT NULL DEFAULT 'none',
+
+  -- Constraints
+  budget_usd        real NOT NULL CHECK (budget_usd >= 0),
+  deadline_at       timestamp NULL,
+  allow_spot        boolean NOT NULL DEFAULT false,
+  min_reliability   real NOT NULL DEFAULT 0.9 CHECK (min_reliability >= 0 AND min_reliability <= 1),
+  performance_weight real NOT NULL DEFAULT 0 CHECK (performance_weight >= 0 AND performance_weight <= 1),
+  max_spot_fraction real NOT NULL DEFAULT 1 CHECK (max_spot_fraction >= 0 AND max_spot_fraction <= 1),
+  on_demand_ranks   text NOT NULL DEFAULT '{}',
+  weights_json      text NULL,
+
+  -- Scheduling outputs
+  selected_provider text NULL,
+  selected_region   text NULL,
+  selected_backend  text NULL DEFAULT 'vm',
+  cluster_vpc       text NULL,
+  cluster_id        uuid NULL,
+  decision_json     text NULL,
+  wait_reasons_json text NULL,
+  topology_json     text NULL,
+  priority_boost    int NOT NULL DEFAULT 0 CHECK (priority_boost >= 0),
+
+  -- Runtime tracking
+  started_at        timestamp NULL,
+  finished_at       timestamp NULL,
+  last_heartbeat_at timestamp NULL,
+  total_steps       bigint NULL,
+  progress_json     text NULL,
+
+  -- Cost tracking
+  cost_running_usd  real NOT NULL DEFAULT 0,
+  cost_estimated_usd real NULL,
+  cost_transfer_usd real NOT NULL DEFAULT 0,
+  egress_bytes      bigint NOT NULL DEFAULT 0,
+  cost_egress_usd   real NOT NULL DEFAULT 0,
+  egress_alerted_at timestamp NULL,
+  suspend_requested_at timestamp NULL,
+  emissions_gco2e   real NULL,
+
+  -- Job options
+  network_json      text NOT NULL DEFAULT '{}',
+  elastic_min_nodes int NULL,
+  elastic_max_nodes int NULL,
+  cloned_from       uuid NULL REFERENCES jobs(id) ON DELETE SET NULL,
+  session_json      text NULL,
+  session_endpoint_json text NULL,
+  session_expires_at timestamp NULL,
+  artifact_retention_json text NULL,
+  bootstrap_json    text NULL,
+  task_policy_json  text NULL,
+  labels_json       text NOT NULL DEFAULT '{}',
+  dataset_verify_json text NULL,
+  dataset_size_gb   real NULL,
+  stuck_after_json  text NULL,
+  data_access_json  text NULL,
+  max_queue_seconds int NULL CHECK (max_queue_seconds > 0),
+  queue_cancel_seconds int NULL CHECK (queue_cancel_seconds > 0),
+  allow_migration   boolean NOT NULL DEFAULT false,
+  dataset_download_json text NULL,
+  experiment_id     uuid NULL REFERENCES experiments(id) ON DELETE SET NULL,
+  training_metric   text NULL,
+  model_class       text NULL,
+  storage_iops      int NOT NULL DEFAULT 0 CHECK (storage_iops >= 0),
+  storage_throughput_mbps int NOT NULL DEFAULT 0 CHECK (storage_throughput_mbps >= 0),
+  min_interconnect_gbps real NOT NULL DEFAULT 0 CHECK (min_interconnect_gbps >= 0),
+  schedule_attempts int NOT NULL DEFAULT 0 CHECK (schedule_attempts >= 0),
+  next_schedule_at  timestamp NULL,
+  deadline_risk_json text NULL,
+  deadline_risk_notified int NOT NULL DEFAULT 0 CHECK (deadline_risk_notified BETWEEN 0 AND 3),
+  instance_types    text NOT NULL DEFAULT '{}',
+  exclude_instance_types text NOT NULL DEFAULT '{}',
+  export_topology   boolean NOT NULL DEFAULT false,
+  placement_spread  text NULL CHECK (placement_spread IN ('az')),
+  framework_config_json text NULL,
+  carbon_weight     real NOT NULL DEFAULT 0 CHECK (carbon_weight >= 0 AND carbon_weight <= 1),
+  ignore_data_gravity boolean NOT NULL DEFAULT false,
+  checkpointing_json text NULL,
+  spot_bid_strategy text NULL CHECK (spot_bid_strategy IN ('low', 'market', 'on_demand_cap')),
+  spot_bid_cap      real NULL CHECK (spot_bid_cap > 0),
+  region_spread_json text NULL,
+  task_groups_json  text NULL,
+  image_id          text NULL,
+  batch_id          uuid NULL,
+
+  -- Spec storage
+  spec_yaml         text NOT NULL,
+  spec_hash         text NULL,
+  spec_bases_json   text NULL,
+  created_at        timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at        timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  CHECK (
+    (elastic_min_nodes IS NULL AND elastic_max_nodes IS NULL)
+    OR (elastic_min_nodes >= 1 AND elastic_max_nodes >= elastic_min_nodes)
+  )
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_user_created ON jobs (user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_jobs_status ON jobs (status);
+CREATE INDEX IF NOT EXISTS idx_jobs_deadline ON jobs (deadline_at);
+CREATE INDEX IF NOT EXISTS idx_jobs_queue_alarm ON jobs (status) WHERE max_queue_seconds IS NOT NULL OR queue_cancel_seconds IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_jobs_team ON jobs (team_id);
+CREATE INDEX IF NOT EXISTS idx_jobs_project ON jobs (project_id);
+CREATE INDEX IF NOT EXISTS idx_jobs_team_project ON jobs (team_id, project_id);
+CREATE INDEX IF NOT EXISTS idx_jobs_cloned_from ON jobs (cloned_from) WHERE cloned_from IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_jobs_experiment ON jobs (experiment_id) WHERE experiment_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_jobs_batch ON jobs (batch_id) WHERE batch_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_jobs_session_expires_at ON jobs (session_expires_at) WHERE session_expires_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_jobs_emissions_pending ON jobs (updated_at)
+  WHERE emissions_gco2e IS NULL AND status IN ('completed', 'failed', 'cancelled');
+CREATE INDEX IF NOT EXISTS idx_jobs_user_spec_hash_active ON jobs (user_id, spec_hash)
+  WHERE status NOT IN ('completed', 'failed', 'cancelled');
+
+-- ---------- JOB EVENTS ----------
+CREATE TABLE IF NOT EXISTS job_events (
+  id          integer PRIMARY KEY,
+  job_id      uuid NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  at          timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  from_status text NULL,
+  to_status   text NOT NULL,
+  reason      text NULL,
+  meta_json   text NOT NULL DEFAULT '{}',
+  artifact_id bigint NULL REFERENCES job_artifacts(id) ON DELETE SET NULL -- e.g. a failure's post-mortem bundle
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_events_job_at ON job_events (job_id, at DESC);
+CREATE INDEX IF NOT EXISTS idx_job_events_postmortem_pending ON job_events (at) WHERE to_status = 'failed' AND artifact_id IS NULL;
+
+-- ---------- ALLOCATIONS ----------
+CREATE TABLE IF NOT EXISTS allocations (
+  id            integer PRIMARY KEY,
+  job_id        uuid NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  provider      text NOT NULL,
+  region        text NOT NULL,
+  backend       text NOT NULL DEFAULT 'vm',
+  instance_type text NOT NULL,
+  count         int NOT NULL CHECK (count > 0),
+  spot          boolean NOT NULL DEFAULT false,
+  price_per_hour real NOT NULL CHECK (price_per_hour >= 0),
+  estimated_hours real NOT NULL CHECK (estimated_hours > 0),
+  estimated_cost_usd real NOT NULL CHECK (estimated_cost_usd >= 0),
+  status        text NOT NULL DEFAULT 'planned'
+    CHECK (status IN ('planned', 'provisioning', 'active', 'failed', 'terminated')),
+  provisioned_count int NOT NULL DEFAULT 0,
+  status_detail text NULL,
+  volume_type   text NULL,
+  volume_gb     int NOT NULL DEFAULT 0 CHECK (volume_gb >= 0),
+  storage_price_per_hour real NOT NULL DEFAULT 0 CHECK (storage_price_per_hour >= 0),
+  gpu_sharing   text NULL CHECK (gpu_sharing IN ('time-slicing', 'mig')),
+  gpu_share     real NOT NULL DEFAULT 0 CHECK (gpu_share >= 0 AND gpu_share <= 1),
+  zone          text NULL,
+  spot_bid_strategy text NULL,
+  spot_bid_usd  real NULL,
+  task_group    text NULL,
+  updated_at    timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  created_at    timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_allocations_job ON allocations (job_id);
+CREATE INDEX IF NOT EXISTS idx_allocations_status ON allocations (status);
+
+-- ---------- JOB INSTANCES ----------
+CREATE TABLE IF NOT EXISTS job_instances (
+  id              integer PRIMARY KEY,
+  job_id          uuid NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  allocation_id   bigint NULL REFERENCES allocations(id) ON DELETE SET NULL,
+  provider        text NOT NULL,
+  region          text NOT NULL,
+  instance_type   text NOT NULL,
+  instance_id     text NOT NULL,
+  spot            boolean NOT NULL DEFAULT false,
+  price_per_hour  real NOT NULL CHECK (price_per_hour >= 0),
+  launched_at     timestamp NOT NULL,
+  terminated_at   timestamp NULL,
+  UNIQUE (provider, region, instance_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_instances_job ON job_instances (job_id);
+CREATE INDEX IF NOT EXISTS idx_job_instances_open ON job_instances (provider, region) WHERE terminated_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_job_instances_launched ON job_instances (launched_at);
+
+-- ---------- NODE PORTS ----------
+CREATE TABLE IF NOT EXISTS node_ports (
+  node          text NOT NULL,
+  port          integer NOT NULL CHECK (port > 0 AND port < 65536),
+  job_id        uuid NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  service       text NOT NULL,
+  allocated_at  timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (node, port),
+  UNIQUE (node, job_id, service)
+);
+
+CREATE INDEX IF NOT EXISTS idx_node_ports_job ON node_ports (job_id);
+
+-- ---------- JOB TASKS (multi_task) ----------
+CREATE TABLE IF NOT EXISTS job_tasks (
+  job_id         uuid NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  task_index     int NOT NULL CHECK (task_index >= 0),
+  status         text NOT NULL DEFAULT 'pending',
+  attempts       int NOT NULL DEFAULT 0,
+  provider       text NOT NULL,
+  region         text NOT NULL,
+  instance_type  text NOT NULL,
+  count          int NOT NULL CHECK (count > 0),
+  spot           boolean NOT NULL DEFAULT false,
+  price_per_hour real NOT NULL CHECK (price_per_hour >= 0),
+  zone           text NULL,
+  task_group     text NULL,
+  cluster_id     text NULL,
+  cost_usd       real NOT NULL DEFAULT 0,
+  error          text NULL,
+  started_at     timestamp NULL,
+  finished_at    timestamp NULL,
+  updated_at     timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (job_id, task_index)
+);
+
+-- ---------- JOB IDENTITIES ----------
+CREATE TABLE IF NOT EXISTS job_identities (
+  job_id       uuid NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  provider     text NOT NULL,
+  kind         text NOT NULL,
+  name         text NOT NULL,
+  managed      boolean NOT NULL DEFAULT false,
+  grants_json  text NOT NULL DEFAULT '[]',
+  attached_at  timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  deleted_at   timestamp NULL,
+  PRIMARY KEY (job_id, provider, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_identities_live ON job_identities (job_id) WHERE managed AND deleted_at IS NULL;
+
+-- ---------- ARTIFACTS ----------
+CREATE TABLE IF NOT EXISTS job_artifacts (
+  id              integer PRIMARY KEY,
+  job_id          uuid NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  type            text NOT NULL, -- checkpoint, log, output, metrics, bootstrap, launch_config, topology, console, postmortem
+  uri             text NOT NULL,
+  created_at      timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  meta_json       text NOT NULL DEFAULT '{}',
+  pinned          boolean NOT NULL DEFAULT false,
+  deleted_at      timestamp NULL,
+  reclaimed_bytes bigint NOT NULL DEFAULT 0,
+  replica_status  text NULL CHECK (replica_status IN ('pending', 'replicated', 'failed', 'none')),
+  replica_attempts int NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_artifacts_job_type ON job_artifacts (job_id, type);
+CREATE INDEX IF NOT EXISTS idx_artifacts_live ON job_artifacts (job_id, type) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_artifacts_replica_pending ON job_artifacts (created_at)
+  WHERE type = 'checkpoint' AND deleted_at IS NULL AND (replica_status IS NULL OR replica_status = 'pending');
+
+CREATE TABLE IF NOT EXISTS artifact_gc_runs (
+  id              integer PRIMARY KEY,
+  started_at      timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  finished_at     timestamp NULL,
+  dry_run         boolean NOT NULL,
+  jobs_scanned    int NOT NULL DEFAULT 0,
+  bytes_reclaimed bigint NOT NULL DEFAULT 0,
+  deletions_json  text NOT NULL DEFAULT '[]'
+);
+
+CREATE INDEX IF NOT EXISTS idx_artifact_gc_runs_started ON artifact_gc_runs (started_at DESC);
+
+-- ---------- GPU PRICING CACHE ----------
+CREATE TABLE IF NOT EXISTS gpu_pricing (
+  id                integer PRIMARY KEY,
+  provider          text NOT NULL,
+  region            text NOT NULL,
+  instance_type     text NOT NULL,
+  gpu_type          text NOT NULL,
+  gpus_per_instance int NOT NULL CHECK (gpus_per_instance > 0),
+  memory_per_gpu_gb int NOT NULL CHECK (memory_per_gpu_gb > 0),
+  interconnect      text NOT NULL DEFAULT 'standard',
+  on_demand_price_per_hour real NOT NULL CHECK (on_demand_price_per_hour >= 0),
+  spot_price_per_hour      real NULL CHECK (spot_price_per_hour >= 0),
+  spot_availability        real NULL CHECK (spot_availability >= 0 AND spot_availability <= 1),
+  interruption_rate        real NULL CHECK (interruption_rate >= 0 AND interruption_rate <= 1),
+  network_gbps             real NOT NULL DEFAULT 0,
+  interconnect_gbps        real NOT NULL DEFAULT 0,
+  last_updated       timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_gpu_pricing_key ON gpu_pricing (provider, region, instance_type);
+CREATE INDEX IF NOT EXISTS idx_gpu_pricing_updated ON gpu_pricing (last_updated DESC);
+
+-- ---------- ALERTS ----------
+CREATE TABLE IF NOT EXISTS alert_rules (
+  id                integer PRIMARY KEY,
+  name              text NOT NULL UNIQUE,
+  condition         text NOT NULL,
+  threshold         real NOT NULL CHECK (threshold >= 0),
+  status            text NULL,
+  window_seconds    bigint NOT NULL DEFAULT 0,
+  team_id           text NULL,
+  cooldown_seconds  bigint NOT NULL DEFAULT 3600,
+  webhook_url       text NULL,
+  email_recipients  text NOT NULL DEFAULT '{}',
+  enabled           boolean NOT NULL DEFAULT true,
+  created_at        timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  last_evaluated_at timestamp NULL
+);
+
+CREATE TABLE IF NOT EXISTS alert_evaluations (
+  id            integer PRIMARY KEY,
+  rule_id       bigint NOT NULL REFERENCES alert_rules(id) ON DELETE CASCADE,
+  at            timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  matched       int NOT NULL DEFAULT 0,
+  fired         int NOT NULL DEFAULT 0,
+  error         text NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_evaluations_rule_at ON alert_evaluations (rule_id, at DESC);
+
+CREATE TABLE IF NOT EXISTS alert_firings (
+  id          integer PRIMARY KEY,
+  rule_id     bigint NOT NULL REFERENCES alert_rules(id) ON DELETE CASCADE,
+  dedup_key   text NOT NULL,
+  fired_at    timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  subject     text NOT NULL,
+  message     text NOT NULL,
+  meta_json   text NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_firings_rule_key ON alert_firings (rule_id, dedup_key, fired_at DESC);
+CREATE INDEX IF NOT EXISTS idx_alert_firings_fired_at ON alert_firings (fired_at DESC);
+
+-- ---------- POLICY RULES ----------
+CREATE TABLE IF NOT EXISTS policy_rules (
+  id          integer PRIMARY KEY,
+  name        text NOT NULL UNIQUE,
+  rule_json   text NOT NULL,
+  enabled     boolean NOT NULL DEFAULT true,
+  created_at  timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at  timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- ---------- LEADER LEASES (unused: SQLite mode is single-instance) ----------
+CREATE TABLE IF NOT EXISTS leader_leases (
+  name         text PRIMARY KEY,
+  holder       text NOT NULL,
+  acquired_at  timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  renewed_at   timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  expires_at   timestamp NOT NULL
+);
+
+-- ---------- WHAT-IF RUNS ----------
+CREATE TABLE IF NOT EXISTS whatif_runs (
+  id            integer PRIMARY KEY,
+  status        text NOT NULL DEFAULT 'running',
+  request_json  text NOT NULL,
+  jobs          int NOT NULL DEFAULT 0,
+  result_json   text NULL,
+  error         text NULL,
+  created_at    timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  finished_at   timestamp NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_whatif_runs_created ON whatif_runs (created_at DESC);
+
+-- ---------- MIGRATION ADVICE ----------
+CREATE TABLE IF NOT EXISTS migration_advice (
+  id            integer PRIMARY KEY,
+  job_id        uuid NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  action        text NOT NULL,
+  savings_usd   real NOT NULL,
+  inputs_json   text NOT NULL,
+  created_at    timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_migration_advice_job ON migration_advice (job_id, created_at DESC);
+
+-- ---------- PROVIDER API USAGE ----------
+CREATE TABLE IF NOT EXISTS provider_api_usage (
+  day         text NOT NULL,
+  provider    text NOT NULL,
+  method      text NOT NULL,
+  calls       integer NOT NULL DEFAULT 0,
+  errors      integer NOT NULL DEFAULT 0,
+  throttled   integer NOT NULL DEFAULT 0,
+  latency_ms  real NOT NULL DEFAULT 0,
+  updated_at  timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (day, provider, method)
+);
+
+-- ---------- API USAGE ----------
+CREATE TABLE IF NOT EXISTS api_usage (
+  day         text NOT NULL,
+  endpoint    text NOT NULL,
+  client      text NOT NULL,
+  job_id      text NOT NULL DEFAULT '',
+  requests    integer NOT NULL DEFAULT 0,
+  errors      integer NOT NULL DEFAULT 0,
+  latency_ms  real NOT NULL DEFAULT 0,
+  updated_at  timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (day, endpoint, client, job_id)
+);
+
+-- ---------- SPOT INTERRUPTIONS ----------
+CREATE TABLE IF NOT EXISTS spot_interruptions (
+  id              integer PRIMARY KEY,
+  job_id          uuid NULL REFERENCES jobs(id) ON DELETE SET NULL,
+  provider        text NOT NULL,
+  instance_type   text NOT NULL,
+  region          text NOT NULL,
+  zone            text NULL,
+  instance_id     text NULL,
+  interrupted_at  timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  hour_of_day     int NOT NULL CHECK (hour_of_day >= 0 AND hour_of_day < 24),
+  runtime_seconds integer NOT NULL DEFAULT 0 CHECK (runtime_seconds >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_spot_interruptions_type
+  ON spot_interruptions (provider, instance_type, region, interrupted_at);
+
+-- ---------- MAINTENANCE EVENTS ----------
+CREATE TABLE IF NOT EXISTS maintenance_events (
+  id            integer PRIMARY KEY,
+  job_id        uuid NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  provider      text NOT NULL,
+  region        text NOT NULL,
+  instance_type text NOT NULL,
+  instance_id   text NOT NULL,
+  event_type    text NOT NULL,
+  description   text NULL,
+  not_before    timestamp NOT NULL,
+  not_after     timestamp NULL,
+  detected_at   timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  action        text NOT NULL DEFAULT 'warned' CHECK (action IN ('warned', 'migrated')),
+  UNIQUE (instance_id, event_type, not_before)
+);
+
+CREATE INDEX IF NOT EXISTS idx_maintenance_events_not_before
+  ON maintenance_events (not_before);
+
+-- ---------- INSTANCE TYPE RULES ----------
+CREATE TABLE IF NOT EXISTS instance_type_rule_changes (
+  id             integer PRIMARY KEY,
+  allow_patterns text NOT NULL DEFAULT '{}',
+  deny_patterns  text NOT NULL DEFAULT '{}',
+  previous_allow text NOT NULL DEFAULT '{}',
+  previous_deny  text NOT NULL DEFAULT '{}',
+  reason         text NULL,
+  remote_addr    text NULL,
+  changed_at     timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_instance_type_rule_changes_at
+  ON instance_type_rule_changes (changed_at DESC);
+
+-- ---------- PRICE QUARANTINE ----------
+CREATE TABLE IF NOT EXISTS price_quarantine (
+  id             integer PRIMARY KEY,
+  provider       text NOT NULL,
+  region         text NOT NULL,
+  instance_type  text NOT NULL,
+  gpu_type       text NOT NULL,
+  price_kind     text NOT NULL CHECK (price_kind IN ('on_demand', 'spot')),
+  price          real NOT NULL,
+  previous_price real NULL,
+  reason         text NOT NULL CHECK (reason IN ('deviation', 'out_of_band')),
+  status         text NOT NULL DEFAULT 'quarantined' CHECK (status IN ('quarantined', 'accepted', 'cleared')),
+  occurrences    int NOT NULL DEFAULT 1 CHECK (occurrences > 0),
+  detected_at    timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  last_seen_at   timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  resolved_at    timestamp NULL,
+  UNIQUE (provider, region, instance_type, price_kind)
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_quarantine_status
+  ON price_quarantine (status, last_seen_at DESC);
+
+-- ---------- PRICING SUBSCRIPTIONS ----------
+CREATE TABLE IF NOT EXISTS gpu_price_history (
+  id             integer PRIMARY KEY,
+  provider       text NOT NULL,
+  region         text NOT NULL,
+  instance_type  text NOT NULL,
+  price_kind     text NOT NULL CHECK (price_kind IN ('on_demand', 'spot')),
+  price          real NOT NULL CHECK (price >= 0),
+  recorded_at    timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_gpu_price_history_key
+  ON gpu_price_history (provider, region, instance_type, price_kind, recorded_at DESC);
+
+CREATE TABLE IF NOT EXISTS pricing_subscriptions (
+  id              integer PRIMARY KEY,
+  user_id         text NOT NULL,
+  name            text NOT NULL,
+  instance_types  text NOT NULL DEFAULT '{}',
+  gpu_types       text NOT NULL DEFAULT '{}',
+  regions         text NOT NULL DEFAULT '{}',
+  price_kind      text NOT NULL DEFAULT 'spot' CHECK (price_kind IN ('on_demand', 'spot')),
+  condition       text NOT NULL CHECK (condition IN ('below', 'drop')),
+  threshold       real NOT NULL CHECK (threshold > 0),
+  window_seconds  bigint NOT NULL DEFAULT 0,
+  webhook_url     text NULL,
+  enabled         boolean NOT NULL DEFAULT true,
+  created_at      timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at      timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (user_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS pricing_notifications (
+  id               integer PRIMARY KEY,
+  subscription_id  bigint NOT NULL REFERENCES pricing_subscriptions(id) ON DELETE CASCADE,
+  provider         text NOT NULL,
+  region           text NOT NULL,
+  instance_type    text NOT NULL,
+  gpu_type         text NOT NULL,
+  old_price        real NULL,
+  new_price        real NOT NULL,
+  fired_at         timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  cleared_at       timestamp NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_pricing_notifications_open
+  ON pricing_notifications (subscription_id, provider, region, instance_type)
+  WHERE cleared_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_pricing_notifications_subscription
+  ON pricing_notifications (subscription_id, fired_at DESC);
+
+-- ---------- MACHINE IMAGES ----------
+CREATE TABLE IF NOT EXISTS machine_images (
+  id                  integer PRIMARY KEY,
+  provider            text NOT NULL,
+  region              text NOT NULL,
+  instance_family     text NOT NULL,
+  image_id            text NOT NULL,
+  build_date          timestamp NULL,
+  validation          text NOT NULL DEFAULT 'pending' CHECK (validation IN ('pending', 'validated', 'failed')),
+  validated_at        timestamp NULL,
+  boot_budget_seconds int NOT NULL CHECK (boot_budget_seconds > 0),
+  degraded_at         timestamp NULL,
+  degraded_reason     text NULL,
+  created_at          timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at          timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (provider, region, instance_family, image_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_machine_images_image ON machine_images (image_id);
+
+CREATE TABLE IF NOT EXISTS machine_image_boots (
+  id               integer PRIMARY KEY,
+  machine_image_id integer NOT NULL REFERENCES machine_images(id) ON DELETE CASCADE,
+  job_id           uuid NULL,
+  instance_type    text NOT NULL,
+  boot_seconds     real NOT NULL CHECK (boot_seconds >= 0),
+  observed_at      timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_machine_image_boots_image
+  ON machine_image_boots (machine_image_id, observed_at DESC);
+
+-- ---------- CLUSTER SSH KEYS ----------
+CREATE TABLE IF NOT EXISTS cluster_ssh_keys (
+  id           integer PRIMARY KEY,
+  job_id       uuid NULL,
+  cluster_id   text NULL,
+  fingerprint  text NOT NULL UNIQUE,
+  public_key   text NOT NULL,
+  private_key  text NULL,
+  status       text NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'active', 'retired', 'destroyed')),
+  created_at   timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  activated_at timestamp NULL,
+  retired_at   timestamp NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_cluster_ssh_keys_cluster ON cluster_ssh_keys (cluster_id, created_at DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_cluster_ssh_keys_active
+  ON cluster_ssh_keys (cluster_id)
+  WHERE status = 'active';
+
+-- ---------- JOB EGRESS ----------
+CREATE TABLE IF NOT EXISTS job_egress_counters (
+  job_id        uuid NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  node_id       text NOT NULL,
+  counter_bytes bigint NOT NULL CHECK (counter_bytes >= 0),
+  source        text NOT NULL CHECK (source IN ('iptables', 'netdev')),
+  sampled_at    timestamp NOT NULL,
+  PRIMARY KEY (job_id, node_id)
+);
+
+CREATE TABLE IF NOT EXISTS job_egress_daily (
+  job_id   uuid NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  day      timestamp NOT NULL,
+  provider text NOT NULL,
+  region   text NOT NULL,
+  bytes    bigint NOT NULL DEFAULT 0,
+  cost_usd real NOT NULL DEFAULT 0,
+  PRIMARY KEY (job_id, day, provider, region)
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_egress_daily_day ON job_egress_daily (day);
+
+-- ---------- GPU-HOUR QUOTAS ----------
+CREATE TABLE IF NOT EXISTS gpu_hour_quotas (
+  id                   integer PRIMARY KEY,
+  scope                text NOT NULL CHECK (scope IN ('team', 'project')),
+  scope_id             text NOT NULL,
+  monthly_hours        real NOT NULL CHECK (monthly_hours >= 0),
+  carry_over           text NOT NULL DEFAULT 'none' CHECK (carry_over IN ('none', 'unused')),
+  carry_over_max_hours real NOT NULL DEFAULT 0 CHECK (carry_over_max_hours >= 0),
+  created_at           timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at           timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (scope, scope_id)
+);
+
+CREATE TABLE IF NOT EXISTS gpu_hour_quota_periods (
+  quota_id       integer NOT NULL REFERENCES gpu_hour_quotas(id) ON DELETE CASCADE,
+  period_start   timestamp NOT NULL,
+  carried_hours  real NOT NULL DEFAULT 0,
+  consumed_hours real NULL,
+  closed_at      timestamp NULL,
+  PRIMARY KEY (quota_id, period_start)
+);
+
+CREATE TABLE IF NOT EXISTS gpu_hour_quota_adjustments (
+  id           integer PRIMARY KEY,
+  quota_id     integer NOT NULL REFERENCES gpu_hour_quotas(id) ON DELETE CASCADE,
+  period_start timestamp NOT NULL,
+  hours        real NOT NULL,
+  reason       text NOT NULL,
+  actor        text NOT NULL,
+  created_at   timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_gpu_hour_quota_adjustments_period
+  ON gpu_hour_quota_adjustments (quota_id, period_start);
+
+CREATE TABLE IF NOT EXISTS gpu_hour_reservations (
+  job_id       uuid NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  quota_id     integer NOT NULL REFERENCES gpu_hour_quotas(id) ON DELETE CASCADE,
+  period_start timestamp NOT NULL,
+  hours        real NOT NULL CHECK (hours >= 0),
+  created_at   timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (job_id, quota_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_gpu_hour_reservations_quota ON gpu_hour_reservations (quota_id, period_start);
+
+-- ---------- AUDIT LOG ----------
+CREATE TABLE IF NOT EXISTS audit_log (
+  id             integer PRIMARY KEY,
+  actor          text NOT NULL,
+  action         text NOT NULL,
+  resource_type  text NULL,
+  resource_id    text NULL,
+  request_json   text NULL,
+  status_code    int NULL,
+  remote_addr    text NULL,
+  created_at     timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at
+  ON audit_log (created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor
+  ON audit_log (actor, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_resource
+  ON audit_log (resource_type, resource_id);
+
+CREATE TRIGGER IF NOT EXISTS audit_log_append_only
+  BEFORE UPDATE ON audit_log
+BEGIN
+  SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+
+-- ---------- REBALANCE SIGNALS ----------
+CREATE TABLE IF NOT EXISTS rebalance_signals (
+  id                      integer PRIMARY KEY,
+  job_id                  uuid NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  provider                text NOT NULL,
+  region                  text NOT NULL,
+  instance_type           text NOT NULL,
+  instance_id             text NOT NULL UNIQUE,
+  noticed_at              timestamp NOT NULL,
+  detected_at             timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  checkpoint_requested_at timestamp NULL,
+  checkpoint_saved_at     timestamp NULL,
+  checkpoint_uri          text NULL,
+  work_saved_seconds      bigint NULL,
+  replacement_node_id     text NULL,
+  replacement_started_at  timestamp NULL,
+  interrupted_at          timestamp NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_rebalance_signals_open
+  ON rebalance_signals (job_id)
+  WHERE interrupted_at IS NULL;
+
+-- ---------- CONSISTENCY REPORTS ----------
+CREATE TABLE IF NOT EXISTS consistency_reports (
+  id                  integer PRIMARY KEY,
+  started_at          timestamp NOT NULL,
+  finished_at         timestamp NOT NULL,
+  counts_json         text NOT NULL,
+  discrepancies_json  text NOT NULL,
+  skipped_json        text NOT NULL DEFAULT '[]'
+);
+
+CREATE INDEX IF NOT EXISTS idx_consistency_reports_started ON consistency_reports (started_at DESC);
+
+-- ---------- PERFORMANCE BENCHMARKS ----------
+CREATE TABLE IF NOT EXISTS performance_benchmarks (
+  framework        text NOT NULL,
+  gpu_type         text NOT NULL,
+  model_class      text NOT NULL,
+  steps_per_hour   real NOT NULL,
+  samples          integer NOT NULL,
+  tokens_per_hour  real NOT NULL DEFAULT 0,
+  token_samples    integer NOT NULL DEFAULT 0,
+  updated_at       timestamp NOT NULL,
+  PRIMARY KEY (framework, gpu_type, model_class)
+);
+
+-- ---------- MANAGED TRAINING RUNS ----------
+CREATE TABLE IF NOT EXISTS managed_training_runs (
+  id               integer PRIMARY KEY,
+  job_id           uuid NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  provider         text NOT NULL,
+  region           text NOT NULL,
+  service_job_id   text NOT NULL,
+  instance_type    text NOT NULL,
+  instance_count   int NOT NULL CHECK (instance_count > 0),
+  spot             boolean NOT NULL DEFAULT false,
+  on_demand_price  real NOT NULL CHECK (on_demand_price >= 0),
+  spot_price       real NOT NULL DEFAULT 0 CHECK (spot_price >= 0),
+  status           text NOT NULL DEFAULT 'pending'
+    CHECK (status IN ('pending', 'running', 'stopping', 'completed', 'failed', 'stopped')),
+  status_detail    text NULL,
+  billable_seconds bigint NOT NULL DEFAULT 0 CHECK (billable_seconds >= 0),
+  cost_usd         real NOT NULL DEFAULT 0 CHECK (cost_usd >= 0),
+  submitted_at     timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  started_at       timestamp NULL,
+  ended_at         timestamp NULL,
+  updated_at       timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (provider, service_job_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_managed_training_runs_job ON managed_training_runs (job_id);
+CREATE INDEX IF NOT EXISTS idx_managed_training_runs_active
+  ON managed_training_runs (submitted_at)
+  WHERE ended_at IS NULL;
@@ -25,6 +25,12 @@ func (c *Client) GetGPUOptimizedAMI(ctx context.Context, region string, instance
 			"p3.16xlarge":  "ami-0c55b159cbfafe1f0",
 			"p4d.24xlarge": "ami-0c55b159cbfafe1f0", // A100 instances
 			"g4dn.xlarge":  "ami-0c55b159cbfafe1f0",
+			"p5.48xlarge":  "ami-0c55b159cbfafe1f0", // H100 instances
+			"g5.xlarge":    "ami-0c55b159cbfafe1f0",
+			"g6.xlarge":    "ami-0c55b159cbfafe1f0", // L4 instances
+			"g6.12xlarge":  "ami-0c55b159cbfafe1f0",
+			"g6e.xlarge":   "ami-0c55b159cbfafe1f0", // L40S instances
+			"g6e.12xlarge": "ami-0c55b159cbfafe1f0",
 		},
 		"us-west-2": {
 			"p3.2xlarge":   "ami-0c55b159cbfafe1f0",
@@ -32,6 +38,12 @@ func (c *Client) GetGPUOptimizedAMI(ctx context.Context, region string, instance
 			"p3.16xlarge":  "ami-0c55b159cbfafe1f0",
 			"p4d.24xlarge": "ami-0c55b159cbfafe1f0",
 			"g4dn.xlarge":  "ami-0c55b159cbfafe1f0",
+			"p5.48xlarge":  "ami-0c55b159cbfafe1f0",
+			"g5.xlarge":    "ami-0c55b159cbfafe1f0",
+			"g6.xlarge":    "ami-0c55b159cbfafe1f0",
+			"g6.12xlarge":  "ami-0c55b159cbfafe1f0",
+			"g6e.xlarge":   "ami-0c55b159cbfafe1f0",
+			"g6e.12xlarge": "ami-0c55b159cbfafe1f0",
 		},
 	}
 
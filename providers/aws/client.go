@@ -4,17 +4,36 @@ import (
 	"context"
 
 	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/providers"
 
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/pricing"
 )
 
+// Ensure Client satisfies the provider interfaces at compile time
+var (
+	_ providers.Provider = (*Client)(nil)
+	_ providers.Stopper  = (*Client)(nil)
+)
+
+// defaultInstanceProfile is attached when a job asks for no identity
+const defaultInstanceProfile = "gpu-instance-profile"
+
 // Client is the AWS provider client
 type Client struct {
 	ec2Client     *ec2.Client
 	pricingClient *pricing.Client
+	iam           IAMAPI       // Job-scoped instance profiles
+	sagemaker     SageMakerAPI // Managed training jobs
 	regions       []string
+
+	instanceProfile string            // Default instance profile; see SetInstanceProfile
+	subnets         map[string]string // Subnet by availability zone; see SetSubnets
+
+	sageMakerRole   string // Training job execution role; empty = SageMaker disabled; see SetSageMaker
+	sageMakerImage  string // Training container image
+	sageMakerOutput string // Default s3:// output prefix
 }
 
 // NewClient creates a new AWS client
@@ -25,12 +44,25 @@ func NewClient(ctx context.Context, regions []string) (*Client, error) {
 	}
 
 	return &Client{
-		ec2Client:     ec2.NewFromConfig(cfg),
-		pricingClient: pricing.NewFromConfig(cfg),
-		regions:       regions,
+		ec2Client:       ec2.NewFromConfig(cfg),
+		pricingClient:   pricing.NewFromConfig(cfg),
+		iam:             newIAMClient(cfg.Credentials),
+		sagemaker:       newSageMakerClient(cfg.Credentials),
+		regions:         regions,
+		instanceProfile: defaultInstanceProfile,
 	}, nil
 }
 
+// Name returns the provider identifier
+func (c *Client) Name() models.Provider {
+	return models.ProviderAWS
+}
+
+// Regions returns the configured AWS regions
+func (c *Client) Regions() []string {
+	return c.regions
+}
+
 // FetchOnDemandPricing fetches on-demand pricing from AWS Pricing API
 func (c *Client) FetchOnDemandPricing(ctx context.Context) ([]models.GPUInstance, error) {
 	// Phase 2: Real AWS Pricing API implementation
@@ -80,12 +112,20 @@ func (c *Client) getMockGPUInstances() []models.GPUInstance {
 		Memory           int
 		PricePerHour     float64
 		InterconnectTier models.InterconnectTier
+		NetworkGbps      float64
+		InterconnectGbps float64 // EFA, InfiniBand or GPUDirect where the type has it, else NetworkGbps
 	}{
-		{"p3.2xlarge", "V100", 1, 16, 3.06, models.InterconnectStandard},
-		{"p3.8xlarge", "V100", 4, 64, 12.24, models.InterconnectStandard},
-		{"p3.16xlarge", "V100", 8, 128, 24.48, models.InterconnectStandard},
-		{"p4d.24xlarge", "A100", 8, 320, 32.77, models.InterconnectHigh},
-		{"g4dn.xlarge", "T4", 1, 16, 0.526, models.InterconnectStandard},
+		{"p3.2xlarge", "V100", 1, 16, 3.06, models.InterconnectStandard, 10, 10},
+		{"p3.8xlarge", "V100", 4, 64, 12.24, models.InterconnectStandard, 10, 10},
+		{"p3.16xlarge", "V100", 8, 128, 24.48, models.InterconnectStandard, 25, 25},
+		{"p4d.24xlarge", "A100", 8, 320, 32.77, models.InterconnectHigh, 400, 400},
+		{"g4dn.xlarge", "T4", 1, 16, 0.526, models.InterconnectStandard, 25, 25},
+		{"p5.48xlarge", "H100", 8, 640, 98.32, models.InterconnectHigh, 3200, 3200},
+		{"g5.xlarge", "A10G", 1, 24, 1.006, models.InterconnectStandard, 10, 10},
+		{"g6.xlarge", "L4", 1, 24, 0.805, models.InterconnectStandard, 10, 10},
+		{"g6.12xlarge", "L4", 4, 96, 4.602, models.InterconnectStandard, 40, 40},
+		{"g6e.xlarge", "L40S", 1, 48, 1.861, models.InterconnectStandard, 20, 20},
+		{"g6e.12xlarge", "L40S", 4, 192, 10.493, models.InterconnectStandard, 100, 100},
 	}
 
 	var instances []models.GPUInstance
@@ -100,6 +140,8 @@ func (c *Client) getMockGPUInstances() []models.GPUInstance {
 				MemoryPerGPU:     gpu.Memory,
 				PricePerHour:     gpu.PricePerHour,
 				InterconnectTier: gpu.InterconnectTier,
+				NetworkGbps:      gpu.NetworkGbps,
+				InterconnectGbps: gpu.InterconnectGbps,
 			})
 		}
 	}
@@ -3,6 +3,11 @@ package aws
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gpu-orchestrator/providers"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
@@ -14,25 +19,34 @@ func (c *Client) ProvisionGPUInstance(
 	ctx context.Context,
 	instanceType string,
 	region string,
+	zone string, // Availability zone of every instance; empty = EC2's choice
 	spot bool,
+	spotMaxPrice float64, // USD per instance-hour; 0 = no max price (capped at on-demand by EC2)
 	count int,
+	bootstrapScript string,
+	instanceProfile string, // Name or ARN; empty = the client's default
+	dataVolume *providers.DataVolume, // gp3 volume per instance; nil = none
+	imageID string, // Pre-baked AMI from the image registry; empty = the generic GPU AMI
 ) ([]string, error) { // Returns instance IDs
-	// Get GPU-optimized AMI for this region and instance type
-	amiID, err := c.GetGPUOptimizedAMI(ctx, region, instanceType)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get GPU AMI: %w", err)
+	// Get GPU-optimized AMI for this region and instance type unless a
+	// registered image was chosen
+	amiID := imageID
+	if amiID == "" {
+		var err error
+		amiID, err = c.GetGPUOptimizedAMI(ctx, region, instanceType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get GPU AMI: %w", err)
+		}
 	}
 
 	// Create EC2 instances
 	input := &ec2.RunInstancesInput{
-		ImageId:      aws.String(amiID),
-		InstanceType: types.InstanceType(instanceType),
-		MinCount:     aws.Int32(int32(count)),
-		MaxCount:     aws.Int32(int32(count)),
-		IamInstanceProfile: &types.IamInstanceProfileSpecification{
-			Name: aws.String("gpu-instance-profile"), // TODO: Make configurable
-		},
-		UserData: aws.String(getUserDataScript()),
+		ImageId:            aws.String(amiID),
+		InstanceType:       types.InstanceType(instanceType),
+		MinCount:           aws.Int32(int32(count)),
+		MaxCount:           aws.Int32(int32(count)),
+		IamInstanceProfile: instanceProfileSpec(instanceProfile, c.instanceProfile),
+		UserData:           aws.String(getUserDataScript(bootstrapScript)),
 		TagSpecifications: []types.TagSpecification{
 			{
 				ResourceType: types.ResourceTypeInstance,
@@ -50,17 +64,23 @@ func (c *Client) ProvisionGPUInstance(
 		},
 	}
 
+	c.zonePlacement(input, zone)
+
+	if dataVolume != nil {
+		input.BlockDeviceMappings = []types.BlockDeviceMapping{dataVolumeMapping(dataVolume)}
+	}
+
 	if spot {
 		input.InstanceMarketOptions = &types.InstanceMarketOptionsRequest{
 			MarketType: types.MarketTypeSpot,
 			SpotOptions: &types.SpotMarketOptions{
 				SpotInstanceType: types.SpotInstanceTypeOneTime,
-				MaxPrice:         aws.String("0.50"), // TODO: Use dynamic max price
+				MaxPrice:         spotBidPrice(spotMaxPrice),
 			},
 		}
 	}
 
-	result, err := c.ec2Client.RunInstances(ctx, input)
+	result, err := c.runInstances(ctx, input)
 	if err != nil {
 		return nil, fmt.Errorf("failed to provision instances: %w", err)
 	}
@@ -73,8 +93,68 @@ func (c *Client) ProvisionGPUInstance(
 	return instanceIDs, nil
 }
 
-// getUserDataScript returns the user data script for instance initialization
-func getUserDataScript() string {
+// spotBidPrice formats a spot bid for EC2; nil leaves the max price unset
+func spotBidPrice(price float64) *string {
+	if price <= 0 {
+		return nil
+	}
+	return aws.String(strconv.FormatFloat(price, 'f', 4, 64))
+}
+
+// dataVolumeDevice is the device name the data volume is attached as. Nitro
+// instances expose it as an NVMe disk; the boot script finds it by size.
+const dataVolumeDevice = "/dev/sdf"
+
+// dataVolumeMapping attaches a gp3 volume that is deleted with the instance
+func dataVolumeMapping(volume *providers.DataVolume) types.BlockDeviceMapping {
+	ebs := &types.EbsBlockDevice{
+		VolumeType:          types.VolumeTypeGp3,
+		VolumeSize:          aws.Int32(int32(volume.SizeGB)),
+		DeleteOnTermination: aws.Bool(true),
+	}
+	if volume.IOPS > 0 {
+		ebs.Iops = aws.Int32(int32(volume.IOPS))
+	}
+	if volume.ThroughputMBps > 0 {
+		ebs.Throughput = aws.Int32(int32(volume.ThroughputMBps))
+	}
+	return types.BlockDeviceMapping{DeviceName: aws.String(dataVolumeDevice), Ebs: ebs}
+}
+
+// instanceProfileRetries bounds how long RunInstances waits for a new
+// instance profile to propagate through IAM
+const instanceProfileRetries = 6
+
+// runInstances launches instances, retrying while EC2 does not see a newly
+// created instance profile yet
+func (c *Client) runInstances(ctx context.Context, input *ec2.RunInstancesInput) (*ec2.RunInstancesOutput, error) {
+	for attempt := 1; ; attempt++ {
+		result, err := c.ec2Client.RunInstances(ctx, input)
+		if err == nil || attempt == instanceProfileRetries || !strings.Contains(err.Error(), "iamInstanceProfile") {
+			return result, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+// instanceProfileSpec selects a profile by ARN or name, falling back to the default
+func instanceProfileSpec(profile, fallback string) *types.IamInstanceProfileSpecification {
+	if profile == "" {
+		profile = fallback
+	}
+	if strings.HasPrefix(profile, "arn:") {
+		return &types.IamInstanceProfileSpecification{Arn: aws.String(profile)}
+	}
+	return &types.IamInstanceProfileSpecification{Name: aws.String(profile)}
+}
+
+// getUserDataScript returns the user data script for instance initialization.
+// extra is appended before the completion marker (e.g. network profile packages).
+func getUserDataScript(extra string) string {
 	return `#!/bin/bash
 set -e
 
@@ -103,7 +183,159 @@ pip3 install numpy pandas scikit-learn
 mkdir -p /opt/training
 chmod 777 /opt/training
 
+` + extra + `
 # Log completion
 echo "Instance initialization complete" >> /var/log/user-data.log
 `
 }
+
+// ProvisionInstances provisions instances for a provider-agnostic request
+func (c *Client) ProvisionInstances(ctx context.Context, req providers.InstanceRequest) ([]string, error) {
+	return c.ProvisionGPUInstance(ctx, req.InstanceType, req.Region, req.Zone, req.Spot, req.SpotMaxPrice, req.Count, req.BootstrapScript, req.Identity, req.DataVolume, req.ImageID)
+}
+
+// TerminateInstances terminates EC2 instances
+func (c *Client) TerminateInstances(ctx context.Context, _ string, instanceIDs []string) error {
+	if len(instanceIDs) == 0 {
+		return nil
+	}
+
+	_, err := c.ec2Client.TerminateInstances(ctx, &ec2.TerminateInstancesInput{
+		InstanceIds: instanceIDs,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to terminate instances: %w", err)
+	}
+
+	return nil
+}
+
+// StopInstances stops EC2 instances, keeping their EBS volumes
+func (c *Client) StopInstances(ctx context.Context, _ string, instanceIDs []string) error {
+	if len(instanceIDs) == 0 {
+		return nil
+	}
+
+	_, err := c.ec2Client.StopInstances(ctx, &ec2.StopInstancesInput{
+		InstanceIds: instanceIDs,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to stop instances: %w", err)
+	}
+
+	return nil
+}
+
+// StartInstances restarts stopped EC2 instances.
+// User data does not run again, so AMI setup and network bootstrap are skipped.
+func (c *Client) StartInstances(ctx context.Context, _ string, instanceIDs []string) error {
+	if len(instanceIDs) == 0 {
+		return nil
+	}
+
+	_, err := c.ec2Client.StartInstances(ctx, &ec2.StartInstancesInput{
+		InstanceIds: instanceIDs,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start instances: %w", err)
+	}
+
+	return nil
+}
+
+// DescribeInstances returns the current state of EC2 instances
+func (c *Client) DescribeInstances(ctx context.Context, region string, instanceIDs []string) ([]providers.InstanceInfo, error) {
+	if len(instanceIDs) == 0 {
+		return nil, nil
+	}
+
+	result, err := c.ec2Client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{
+		InstanceIds: instanceIDs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe instances: %w", err)
+	}
+
+	var infos []providers.InstanceInfo
+	for _, reservation := range result.Reservations {
+		for _, instance := range reservation.Instances {
+			info := providers.InstanceInfo{
+				InstanceID:   aws.ToString(instance.InstanceId),
+				InstanceType: string(instance.InstanceType),
+				Region:       region,
+				PrivateIP:    aws.ToString(instance.PrivateIpAddress),
+				LaunchedAt:   instance.LaunchTime,
+				State:        providers.InstanceStateUnknown,
+			}
+			if instance.Placement != nil {
+				info.Zone = aws.ToString(instance.Placement.AvailabilityZone)
+			}
+			if instance.State != nil {
+				info.State = ec2InstanceState(instance.State.Name)
+			}
+			infos = append(infos, info)
+		}
+	}
+
+	return infos, nil
+}
+
+// ListManagedInstances returns the region's pending, running and stopped
+// instances tagged ManagedBy=gpu-orchestrator
+func (c *Client) ListManagedInstances(ctx context.Context, region string) ([]providers.InstanceInfo, error) {
+	input := &ec2.DescribeInstancesInput{
+		Filters: []types.Filter{
+			{Name: aws.String("tag:ManagedBy"), Values: []string{"gpu-orchestrator"}},
+			{Name: aws.String("instance-state-name"), Values: []string{"pending", "running", "stopping", "stopped"}},
+		},
+	}
+
+	var infos []providers.InstanceInfo
+	for {
+		result, err := c.ec2Client.DescribeInstances(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list managed instances: %w", err)
+		}
+		for _, reservation := range result.Reservations {
+			for _, instance := range reservation.Instances {
+				info := providers.InstanceInfo{
+					InstanceID:   aws.ToString(instance.InstanceId),
+					InstanceType: string(instance.InstanceType),
+					Region:       region,
+					PrivateIP:    aws.ToString(instance.PrivateIpAddress),
+					LaunchedAt:   instance.LaunchTime,
+					State:        providers.InstanceStateUnknown,
+				}
+				if instance.Placement != nil {
+					info.Zone = aws.ToString(instance.Placement.AvailabilityZone)
+				}
+				if instance.State != nil {
+					info.State = ec2InstanceState(instance.State.Name)
+				}
+				infos = append(infos, info)
+			}
+		}
+		if aws.ToString(result.NextToken) == "" {
+			break
+		}
+		input.NextToken = result.NextToken
+	}
+
+	return infos, nil
+}
+
+// ec2InstanceState maps EC2 instance states to provider-agnostic states
+func ec2InstanceState(state types.InstanceStateName) providers.InstanceState {
+	switch state {
+	case "pending":
+		return providers.InstanceStatePending
+	case "running":
+		return providers.InstanceStateRunning
+	case "stopping", "stopped":
+		return providers.InstanceStateStopped
+	case "shutting-down", "terminated":
+		return providers.InstanceStateTerminated
+	default:
+		return providers.InstanceStateUnknown
+	}
+}
@@ -0,0 +1,164 @@
+package aws
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// iamEndpoint is the global IAM endpoint; requests are signed for us-east-1
+const iamEndpoint = "https://iam.amazonaws.com/"
+
+// errIAMNoSuchEntity is returned when the role, policy or profile does not exist
+var errIAMNoSuchEntity = errors.New("iam: no such entity")
+
+// errIAMAlreadyExists is returned when creating a role or profile that exists
+var errIAMAlreadyExists = errors.New("iam: entity already exists")
+
+// IAMAPI is the subset of IAM the client uses to manage job instance profiles
+type IAMAPI interface {
+	CreateRole(ctx context.Context, name, path, trustPolicy string, tags map[string]string) error
+	PutRolePolicy(ctx context.Context, role, policyName, policy string) error
+	CreateInstanceProfile(ctx context.Context, name, path string) error
+	AddRoleToInstanceProfile(ctx context.Context, profile, role string) error
+	RemoveRoleFromInstanceProfile(ctx context.Context, profile, role string) error
+	DeleteInstanceProfile(ctx context.Context, name string) error
+	DeleteRolePolicy(ctx context.Context, role, policyName string) error
+	DeleteRole(ctx context.Context, name string) error
+}
+
+// iamClient calls the IAM query API directly
+type iamClient struct {
+	credentials aws.CredentialsProvider
+	signer      *v4.Signer
+	httpClient  *http.Client
+}
+
+// newIAMClient creates an IAM client with the given credentials
+func newIAMClient(credentials aws.CredentialsProvider) *iamClient {
+	return &iamClient{
+		credentials: credentials,
+		signer:      v4.NewSigner(),
+		httpClient:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// CreateRole creates a role with a trust policy and tags
+func (c *iamClient) CreateRole(ctx context.Context, name, path, trustPolicy string, tags map[string]string) error {
+	params := url.Values{
+		"RoleName":                 {name},
+		"Path":                     {path},
+		"AssumeRolePolicyDocument": {trustPolicy},
+	}
+	i := 1
+	for key, value := range tags {
+		params.Set(fmt.Sprintf("Tags.member.%d.Key", i), key)
+		params.Set(fmt.Sprintf("Tags.member.%d.Value", i), value)
+		i++
+	}
+	return c.call(ctx, "CreateRole", params)
+}
+
+// PutRolePolicy sets an inline policy on a role
+func (c *iamClient) PutRolePolicy(ctx context.Context, role, policyName, policy string) error {
+	return c.call(ctx, "PutRolePolicy", url.Values{
+		"RoleName":       {role},
+		"PolicyName":     {policyName},
+		"PolicyDocument": {policy},
+	})
+}
+
+// CreateInstanceProfile creates an empty instance profile
+func (c *iamClient) CreateInstanceProfile(ctx context.Context, name, path string) error {
+	return c.call(ctx, "CreateInstanceProfile", url.Values{"InstanceProfileName": {name}, "Path": {path}})
+}
+
+// AddRoleToInstanceProfile puts a role in an instance profile
+func (c *iamClient) AddRoleToInstanceProfile(ctx context.Context, profile, role string) error {
+	return c.call(ctx, "AddRoleToInstanceProfile", url.Values{"InstanceProfileName": {profile}, "RoleName": {role}})
+}
+
+// RemoveRoleFromInstanceProfile takes a role out of an instance profile
+func (c *iamClient) RemoveRoleFromInstanceProfile(ctx context.Context, profile, role string) error {
+	return c.call(ctx, "RemoveRoleFromInstanceProfile", url.Values{"InstanceProfileName": {profile}, "RoleName": {role}})
+}
+
+// DeleteInstanceProfile deletes an instance profile with no roles
+func (c *iamClient) DeleteInstanceProfile(ctx context.Context, name string) error {
+	return c.call(ctx, "DeleteInstanceProfile", url.Values{"InstanceProfileName": {name}})
+}
+
+// DeleteRolePolicy deletes an inline role policy
+func (c *iamClient) DeleteRolePolicy(ctx context.Context, role, policyName string) error {
+	return c.call(ctx, "DeleteRolePolicy", url.Values{"RoleName": {role}, "PolicyName": {policyName}})
+}
+
+// DeleteRole deletes a role with no policies or profiles
+func (c *iamClient) DeleteRole(ctx context.Context, name string) error {
+	return c.call(ctx, "DeleteRole", url.Values{"RoleName": {name}})
+}
+
+// iamErrorResponse is the IAM query API error body
+type iamErrorResponse struct {
+	Error struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	} `xml:"Error"`
+}
+
+// call signs and sends an IAM action. The response body of successful calls is not needed.
+func (c *iamClient) call(ctx context.Context, action string, params url.Values) error {
+	params.Set("Action", action)
+	params.Set("Version", "2010-05-08")
+	body := params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, iamEndpoint, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+
+	creds, err := c.credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve IAM credentials: %w", err)
+	}
+	sum := sha256.Sum256([]byte(body))
+	if err := c.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(sum[:]), "iam", "us-east-1", time.Now()); err != nil {
+		return fmt.Errorf("failed to sign IAM request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("iam %s: %w", action, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	var apiErr iamErrorResponse
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if xml.Unmarshal(raw, &apiErr) != nil || apiErr.Error.Code == "" {
+		return fmt.Errorf("iam %s: status %d: %s", action, resp.StatusCode, string(raw))
+	}
+	switch apiErr.Error.Code {
+	case "NoSuchEntity":
+		return fmt.Errorf("iam %s: %w: %s", action, errIAMNoSuchEntity, apiErr.Error.Message)
+	case "EntityAlreadyExists":
+		return fmt.Errorf("iam %s: %w: %s", action, errIAMAlreadyExists, apiErr.Error.Message)
+	}
+	return fmt.Errorf("iam %s: %s: %s", action, apiErr.Error.Code, apiErr.Error.Message)
+}
@@ -0,0 +1,177 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/providers"
+)
+
+var _ providers.IdentityProvisioner = (*Client)(nil)
+
+const (
+	// jobIdentityPath groups the roles and profiles created for jobs
+	jobIdentityPath = "/gpu-orchestrator/"
+	// jobPolicyName is the inline policy holding a job role's storage grants
+	jobPolicyName = "job-storage-access"
+)
+
+// ec2TrustPolicy lets EC2 instances assume the role
+const ec2TrustPolicy = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"Service":"ec2.amazonaws.com"},"Action":"sts:AssumeRole"}]}`
+
+// SetInstanceProfile sets the instance profile attached when a job asks for no identity
+func (c *Client) SetInstanceProfile(name string) {
+	c.instanceProfile = name
+}
+
+// DefaultIdentity returns the configured instance profile
+func (c *Client) DefaultIdentity() models.JobIdentity {
+	return models.JobIdentity{
+		Provider: models.ProviderAWS,
+		Kind:     models.IdentityInstanceProfile,
+		Name:     c.instanceProfile,
+	}
+}
+
+// CreateJobIdentity creates a role and instance profile that can only read the
+// job's read grants and write its output prefix. Only s3:// grants can be
+// scoped; others are left out of the policy. Calling it again for the same job
+// converges on the same role and policy.
+func (c *Client) CreateJobIdentity(ctx context.Context, jobID string, grants []models.StorageGrant) (models.JobIdentity, error) {
+	scoped := s3Grants(grants)
+	policy, err := jobIdentityPolicy(scoped)
+	if err != nil {
+		return models.JobIdentity{}, err
+	}
+
+	name := jobIdentityName(jobID)
+	tags := map[string]string{"ManagedBy": "gpu-orchestrator", "JobID": jobID}
+	if err := c.iam.CreateRole(ctx, name, jobIdentityPath, ec2TrustPolicy, tags); err != nil && !errors.Is(err, errIAMAlreadyExists) {
+		return models.JobIdentity{}, fmt.Errorf("failed to create role for job %s: %w", jobID, err)
+	}
+
+	identity := models.JobIdentity{
+		Provider: models.ProviderAWS,
+		Kind:     models.IdentityInstanceProfile,
+		Name:     name,
+		Managed:  true,
+		Grants:   scoped,
+	}
+	if err := c.attachJobRole(ctx, name, policy); err != nil {
+		if cleanupErr := c.DeleteJobIdentity(ctx, identity); cleanupErr != nil {
+			log.Printf("Failed to clean up identity %s after a failed create: %v", name, cleanupErr)
+		}
+		return models.JobIdentity{}, fmt.Errorf("failed to set up instance profile for job %s: %w", jobID, err)
+	}
+	return identity, nil
+}
+
+// attachJobRole sets the role's policy and puts it in an instance profile of the same name
+func (c *Client) attachJobRole(ctx context.Context, name, policy string) error {
+	if err := c.iam.PutRolePolicy(ctx, name, jobPolicyName, policy); err != nil {
+		return err
+	}
+	if err := c.iam.CreateInstanceProfile(ctx, name, jobIdentityPath); err != nil && !errors.Is(err, errIAMAlreadyExists) {
+		return err
+	}
+	// A profile holds one role; LimitExceeded means the role is already in it
+	if err := c.iam.AddRoleToInstanceProfile(ctx, name, name); err != nil && !strings.Contains(err.Error(), "LimitExceeded") {
+		return err
+	}
+	return nil
+}
+
+// DeleteJobIdentity deletes a job's instance profile, role and policy
+func (c *Client) DeleteJobIdentity(ctx context.Context, identity models.JobIdentity) error {
+	steps := []func() error{
+		func() error { return c.iam.RemoveRoleFromInstanceProfile(ctx, identity.Name, identity.Name) },
+		func() error { return c.iam.DeleteInstanceProfile(ctx, identity.Name) },
+		func() error { return c.iam.DeleteRolePolicy(ctx, identity.Name, jobPolicyName) },
+		func() error { return c.iam.DeleteRole(ctx, identity.Name) },
+	}
+	for _, step := range steps {
+		if err := step(); err != nil && !errors.Is(err, errIAMNoSuchEntity) {
+			return fmt.Errorf("failed to delete identity %s: %w", identity.Name, err)
+		}
+	}
+	return nil
+}
+
+// jobIdentityName names a job's role and instance profile (IAM allows 64 characters)
+func jobIdentityName(jobID string) string {
+	return "gpu-job-" + jobID
+}
+
+// s3Grants keeps the grants on s3:// URIs
+func s3Grants(grants []models.StorageGrant) []models.StorageGrant {
+	var scoped []models.StorageGrant
+	for _, grant := range grants {
+		if strings.HasPrefix(grant.URI, "s3://") {
+			scoped = append(scoped, grant)
+		} else {
+			log.Printf("Job identity: %s is not on S3 and is not granted", grant.URI)
+		}
+	}
+	return scoped
+}
+
+// iamStatement is one statement of an IAM policy document
+type iamStatement struct {
+	Effect    string                         `json:"Effect"`
+	Action    []string                       `json:"Action"`
+	Resource  []string                       `json:"Resource"`
+	Condition map[string]map[string][]string `json:"Condition,omitempty"`
+}
+
+// jobIdentityPolicy builds a policy allowing exactly the grants: each URI is
+// treated as an object or a prefix (the key itself and key/*), never a
+// sibling with the same leading characters
+func jobIdentityPolicy(grants []models.StorageGrant) (string, error) {
+	var statements []iamStatement
+	for _, grant := range grants {
+		bucket, key, err := splitS3URI(grant.URI)
+		if err != nil {
+			return "", err
+		}
+
+		objects := []string{fmt.Sprintf("arn:aws:s3:::%s/*", bucket)}
+		list := iamStatement{Effect: "Allow", Action: []string{"s3:ListBucket"}, Resource: []string{"arn:aws:s3:::" + bucket}}
+		if key != "" {
+			objects = []string{
+				fmt.Sprintf("arn:aws:s3:::%s/%s", bucket, key),
+				fmt.Sprintf("arn:aws:s3:::%s/%s/*", bucket, key),
+			}
+			list.Condition = map[string]map[string][]string{"StringLike": {"s3:prefix": {key, key + "/*"}}}
+		}
+
+		actions := []string{"s3:GetObject"}
+		if grant.Write {
+			actions = append(actions, "s3:PutObject", "s3:AbortMultipartUpload")
+		}
+		statements = append(statements, iamStatement{Effect: "Allow", Action: actions, Resource: objects}, list)
+	}
+	if len(statements) == 0 {
+		return "", fmt.Errorf("job identity needs at least one s3:// entrypoint, dataset or output URI")
+	}
+
+	policy, err := json.Marshal(map[string]interface{}{"Version": "2012-10-17", "Statement": statements})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode job policy: %w", err)
+	}
+	return string(policy), nil
+}
+
+// splitS3URI splits s3://bucket/key into bucket and key (without trailing slash)
+func splitS3URI(uri string) (string, string, error) {
+	rest := strings.TrimPrefix(uri, "s3://")
+	bucket, key, _ := strings.Cut(rest, "/")
+	if bucket == "" {
+		return "", "", fmt.Errorf("invalid S3 URI %q", uri)
+	}
+	return bucket, strings.TrimSuffix(key, "/"), nil
+}
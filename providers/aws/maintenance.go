@@ -0,0 +1,54 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gpu-orchestrator/providers"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+var _ providers.MaintenanceReporter = (*Client)(nil)
+
+// ScheduledEvents returns the upcoming scheduled events (system-reboot,
+// instance-retirement, ...) of EC2 instances. EC2 keeps completed and
+// cancelled events for a while, marked in their description.
+func (c *Client) ScheduledEvents(ctx context.Context, _ string, instanceIDs []string) ([]providers.MaintenanceEvent, error) {
+	if len(instanceIDs) == 0 {
+		return nil, nil
+	}
+
+	result, err := c.ec2Client.DescribeInstanceStatus(ctx, &ec2.DescribeInstanceStatusInput{
+		InstanceIds:         instanceIDs,
+		IncludeAllInstances: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe instance status: %w", err)
+	}
+	return scheduledEvents(result.InstanceStatuses), nil
+}
+
+// scheduledEvents returns the pending events of instance statuses
+func scheduledEvents(statuses []types.InstanceStatus) []providers.MaintenanceEvent {
+	var events []providers.MaintenanceEvent
+	for _, status := range statuses {
+		for _, e := range status.Events {
+			description := aws.ToString(e.Description)
+			if strings.HasPrefix(description, "[Completed]") || strings.HasPrefix(description, "[Canceled]") || e.NotBefore == nil {
+				continue
+			}
+			events = append(events, providers.MaintenanceEvent{
+				InstanceID:  aws.ToString(status.InstanceId),
+				EventType:   string(e.Code),
+				Description: description,
+				NotBefore:   *e.NotBefore,
+				NotAfter:    e.NotAfter,
+			})
+		}
+	}
+	return events
+}
@@ -0,0 +1,64 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gpu-orchestrator/providers"
+)
+
+var _ providers.RebalanceReporter = (*Client)(nil)
+
+// rebalanceDocument is the rebalance recommendation the instance metadata
+// service serves at /latest/meta-data/events/recommendations/rebalance
+type rebalanceDocument struct {
+	NoticeTime string `json:"noticeTime"` // RFC 3339
+}
+
+// RebalanceRecommendations returns the rebalance recommendations of EC2 spot
+// instances. EC2 has no API for them outside the instance, so the metadata
+// of every instance is relayed.
+func (c *Client) RebalanceRecommendations(ctx context.Context, region string, instanceIDs []string) ([]providers.RebalanceRecommendation, error) {
+	var recommendations []providers.RebalanceRecommendation
+	for _, instanceID := range instanceIDs {
+		document, err := c.fetchRebalanceRecommendation(ctx, region, instanceID)
+		if err != nil {
+			return nil, err
+		}
+		if document == nil {
+			continue // No recommendation
+		}
+		recommendation, err := parseRebalanceRecommendation(document, instanceID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse rebalance recommendation of %s: %w", instanceID, err)
+		}
+		recommendations = append(recommendations, recommendation)
+	}
+	return recommendations, nil
+}
+
+// fetchRebalanceRecommendation returns the rebalance recommendation document
+// of an instance, nil when there is none (the metadata service answers 404).
+// The metadata service only answers from inside the instance, so it is relayed.
+func (c *Client) fetchRebalanceRecommendation(_ context.Context, _ string, _ string) ([]byte, error) {
+	// TODO: Relay through SSM SendCommand (AWS-RunShellScript) once nodes run the agent:
+	// TOKEN=$(curl -X PUT http://169.254.169.254/latest/api/token -H "X-aws-ec2-metadata-token-ttl-seconds: 60")
+	// curl -H "X-aws-ec2-metadata-token: $TOKEN" http://169.254.169.254/latest/meta-data/events/recommendations/rebalance
+	return nil, fmt.Errorf("EC2 rebalance recommendations not yet implemented")
+}
+
+// parseRebalanceRecommendation parses the rebalance recommendation document
+// of an instance
+func parseRebalanceRecommendation(document []byte, instanceID string) (providers.RebalanceRecommendation, error) {
+	var doc rebalanceDocument
+	if err := json.Unmarshal(document, &doc); err != nil {
+		return providers.RebalanceRecommendation{}, err
+	}
+	noticed, err := time.Parse(time.RFC3339, doc.NoticeTime)
+	if err != nil {
+		return providers.RebalanceRecommendation{}, fmt.Errorf("invalid noticeTime %q: %w", doc.NoticeTime, err)
+	}
+	return providers.RebalanceRecommendation{InstanceID: instanceID, NoticedAt: noticed}, nil
+}
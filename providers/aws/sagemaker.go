@@ -0,0 +1,410 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/providers"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+var _ providers.ManagedTrainer = (*Client)(nil)
+
+// sageMakerPriceFactor is what SageMaker Training charges for an ml.*
+// instance relative to the same EC2 instance type
+const sageMakerPriceFactor = 1.2
+
+// sageMakerVolumeGB is the storage volume of each training instance
+const sageMakerVolumeGB = 100
+
+// sageMakerDefaultRuntime stops training jobs without an estimated runtime
+const sageMakerDefaultRuntime = 5 * 24 * time.Hour
+
+// sageMakerMaxRuntime is the longest runtime SageMaker accepts
+const sageMakerMaxRuntime = 28 * 24 * time.Hour
+
+// errSageMakerNotFound is returned for training jobs that do not exist
+var errSageMakerNotFound = errors.New("sagemaker: training job not found")
+
+// SageMakerAPI is the subset of SageMaker the client uses to run training jobs
+type SageMakerAPI interface {
+	CreateTrainingJob(ctx context.Context, region string, input *SageMakerTrainingJob) error
+	DescribeTrainingJob(ctx context.Context, region, name string) (*SageMakerTrainingJobDescription, error)
+	StopTrainingJob(ctx context.Context, region, name string) error
+}
+
+// SageMakerTrainingJob is a CreateTrainingJob request
+type SageMakerTrainingJob struct {
+	TrainingJobName           string
+	AlgorithmSpecification    sageMakerAlgorithm
+	RoleArn                   string
+	InputDataConfig           []sageMakerChannel `json:",omitempty"`
+	OutputDataConfig          sageMakerOutput
+	ResourceConfig            sageMakerResources
+	StoppingCondition         sageMakerStopping
+	EnableManagedSpotTraining bool              `json:",omitempty"`
+	Environment               map[string]string `json:",omitempty"`
+	Tags                      []sageMakerTag    `json:",omitempty"`
+}
+
+type sageMakerAlgorithm struct {
+	TrainingImage     string
+	TrainingInputMode string
+}
+
+type sageMakerChannel struct {
+	ChannelName string
+	DataSource  struct {
+		S3DataSource struct {
+			S3DataType             string
+			S3Uri                  string
+			S3DataDistributionType string
+		}
+	}
+}
+
+type sageMakerOutput struct {
+	S3OutputPath string
+}
+
+type sageMakerResources struct {
+	InstanceType   string
+	InstanceCount  int
+	VolumeSizeInGB int
+}
+
+type sageMakerStopping struct {
+	MaxRuntimeInSeconds  int64
+	MaxWaitTimeInSeconds int64 `json:",omitempty"`
+}
+
+type sageMakerTag struct {
+	Key   string
+	Value string
+}
+
+// SageMakerTrainingJobDescription is the part of a DescribeTrainingJob
+// response the client reads
+type SageMakerTrainingJobDescription struct {
+	TrainingJobStatus         string     // InProgress | Completed | Failed | Stopping | Stopped
+	SecondaryStatus           string     // e.g. Starting, Downloading, Training, Interrupted
+	FailureReason             string     `json:",omitempty"`
+	TrainingStartTime         *epochTime `json:",omitempty"`
+	TrainingEndTime           *epochTime `json:",omitempty"`
+	BillableTimeInSeconds     int64      `json:",omitempty"` // Per instance; set once the job ended
+	EnableManagedSpotTraining bool       `json:",omitempty"`
+}
+
+// epochTime is a JSON protocol timestamp: fractional seconds since the epoch
+type epochTime struct {
+	time.Time
+}
+
+func (t *epochTime) UnmarshalJSON(data []byte) error {
+	var seconds float64
+	if err := json.Unmarshal(data, &seconds); err != nil {
+		return err
+	}
+	whole, frac := math.Modf(seconds)
+	t.Time = time.Unix(int64(whole), int64(frac*1e9)).UTC()
+	return nil
+}
+
+func (t epochTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(float64(t.UnixNano()) / 1e9)
+}
+
+// SetSageMaker enables SageMaker Training: jobs run image as role and write
+// to outputURI unless they set an output prefix. Without a role, no managed
+// entries are priced and the service is never chosen.
+func (c *Client) SetSageMaker(roleARN, image, outputURI string) {
+	c.sageMakerRole = roleARN
+	c.sageMakerImage = image
+	c.sageMakerOutput = outputURI
+}
+
+// ManagedPricing prices the raw instance types as SageMaker ml.* instances
+func (c *Client) ManagedPricing(raw []models.GPUInstance) []models.GPUInstance {
+	if c.sageMakerRole == "" {
+		return nil
+	}
+	managed := make([]models.GPUInstance, 0, len(raw))
+	for _, instance := range raw {
+		instance.InstanceType = models.ManagedInstanceType(models.ProviderAWS, instance.InstanceType)
+		instance.PricePerHour *= sageMakerPriceFactor
+		instance.SpotPrice *= sageMakerPriceFactor
+		managed = append(managed, instance)
+	}
+	return managed
+}
+
+// SubmitTrainingJob creates a SageMaker training job and returns its name
+func (c *Client) SubmitTrainingJob(ctx context.Context, req providers.TrainingJobRequest) (string, error) {
+	if c.sageMakerRole == "" {
+		return "", fmt.Errorf("sagemaker: no execution role configured")
+	}
+	input, err := c.sageMakerJob(req)
+	if err != nil {
+		return "", err
+	}
+	if err := c.sagemaker.CreateTrainingJob(ctx, req.Region, input); err != nil {
+		return "", fmt.Errorf("failed to create training job %s: %w", req.Name, err)
+	}
+	return req.Name, nil
+}
+
+// sageMakerJob translates a training job request into a CreateTrainingJob request
+func (c *Client) sageMakerJob(req providers.TrainingJobRequest) (*SageMakerTrainingJob, error) {
+	if !models.IsManagedInstanceType(models.ProviderAWS, req.InstanceType) {
+		return nil, fmt.Errorf("sagemaker: %s is not a SageMaker instance type", req.InstanceType)
+	}
+	image := req.Image
+	if image == "" {
+		image = c.sageMakerImage
+	}
+	if image == "" {
+		return nil, fmt.Errorf("sagemaker: no training image configured")
+	}
+	output := req.OutputURI
+	if output == "" {
+		output = c.sageMakerOutput
+	}
+	if !strings.HasPrefix(output, "s3://") {
+		return nil, fmt.Errorf("sagemaker: output must be an s3:// prefix (data.output), got %q", output)
+	}
+
+	runtime := req.MaxRuntime
+	if runtime <= 0 {
+		runtime = sageMakerDefaultRuntime
+	}
+	if runtime > sageMakerMaxRuntime {
+		runtime = sageMakerMaxRuntime
+	}
+
+	input := &SageMakerTrainingJob{
+		TrainingJobName:        req.Name,
+		AlgorithmSpecification: sageMakerAlgorithm{TrainingImage: image, TrainingInputMode: "File"},
+		RoleArn:                c.sageMakerRole,
+		OutputDataConfig:       sageMakerOutput{S3OutputPath: output},
+		ResourceConfig: sageMakerResources{
+			InstanceType:   req.InstanceType,
+			InstanceCount:  req.Count,
+			VolumeSizeInGB: sageMakerVolumeGB,
+		},
+		StoppingCondition: sageMakerStopping{MaxRuntimeInSeconds: int64(runtime.Seconds())},
+		Environment:       req.Environment,
+	}
+	if req.Spot {
+		// Max wait covers the runtime and the time spent waiting for spot capacity
+		wait := req.MaxWait
+		if wait <= 0 {
+			wait = 2 * runtime
+		}
+		if wait < runtime {
+			wait = runtime
+		}
+		if wait > sageMakerMaxRuntime {
+			wait = sageMakerMaxRuntime
+		}
+		input.EnableManagedSpotTraining = true
+		input.StoppingCondition.MaxWaitTimeInSeconds = int64(wait.Seconds())
+	}
+	if req.InputURI != "" {
+		if !strings.HasPrefix(req.InputURI, "s3://") {
+			return nil, fmt.Errorf("sagemaker: input channels read s3:// URIs, dataset is %q", req.InputURI)
+		}
+		var channel sageMakerChannel
+		channel.ChannelName = "training"
+		channel.DataSource.S3DataSource.S3DataType = "S3Prefix"
+		channel.DataSource.S3DataSource.S3Uri = req.InputURI
+		channel.DataSource.S3DataSource.S3DataDistributionType = "FullyReplicated"
+		input.InputDataConfig = []sageMakerChannel{channel}
+	}
+
+	keys := make([]string, 0, len(req.Tags))
+	for key := range req.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		input.Tags = append(input.Tags, sageMakerTag{Key: key, Value: req.Tags[key]})
+	}
+	return input, nil
+}
+
+// DescribeTrainingJob returns the status of a SageMaker training job
+func (c *Client) DescribeTrainingJob(ctx context.Context, region, name string) (*providers.TrainingJobStatus, error) {
+	desc, err := c.sagemaker.DescribeTrainingJob(ctx, region, name)
+	if err != nil {
+		return nil, err
+	}
+	return sageMakerStatus(desc, time.Now()), nil
+}
+
+// sageMakerStatus maps a training job description to a status as of now
+func sageMakerStatus(desc *SageMakerTrainingJobDescription, now time.Time) *providers.TrainingJobStatus {
+	status := &providers.TrainingJobStatus{Detail: desc.SecondaryStatus}
+	if desc.TrainingStartTime != nil {
+		started := desc.TrainingStartTime.Time
+		status.StartedAt = &started
+	}
+	if desc.TrainingEndTime != nil {
+		ended := desc.TrainingEndTime.Time
+		status.EndedAt = &ended
+	}
+
+	switch desc.TrainingJobStatus {
+	case "Completed":
+		status.State = models.ManagedRunCompleted
+	case "Failed":
+		status.State = models.ManagedRunFailed
+		status.Detail = desc.FailureReason
+	case "Stopped":
+		status.State = models.ManagedRunStopped
+	case "Stopping":
+		status.State = models.ManagedRunStopping
+	default:
+		status.State = models.ManagedRunPending
+		if status.StartedAt != nil {
+			status.State = models.ManagedRunRunning
+		}
+	}
+
+	if desc.BillableTimeInSeconds > 0 {
+		// Managed spot bills fewer seconds at the on-demand rate
+		status.BillableSeconds = desc.BillableTimeInSeconds
+		status.SpotDiscounted = desc.EnableManagedSpotTraining
+	} else if status.StartedAt != nil {
+		end := now
+		if status.EndedAt != nil {
+			end = *status.EndedAt
+		}
+		status.BillableSeconds = int64(end.Sub(*status.StartedAt).Seconds())
+	}
+	return status
+}
+
+// StopTrainingJob stops a SageMaker training job that has not ended
+func (c *Client) StopTrainingJob(ctx context.Context, region, name string) error {
+	desc, err := c.sagemaker.DescribeTrainingJob(ctx, region, name)
+	if errors.Is(err, errSageMakerNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	switch desc.TrainingJobStatus {
+	case "Completed", "Failed", "Stopped", "Stopping":
+		return nil
+	}
+	return c.sagemaker.StopTrainingJob(ctx, region, name)
+}
+
+// sageMakerClient calls the SageMaker JSON API directly
+type sageMakerClient struct {
+	credentials aws.CredentialsProvider
+	signer      *v4.Signer
+	httpClient  *http.Client
+}
+
+// newSageMakerClient creates a SageMaker client with the given credentials
+func newSageMakerClient(credentials aws.CredentialsProvider) *sageMakerClient {
+	return &sageMakerClient{
+		credentials: credentials,
+		signer:      v4.NewSigner(),
+		httpClient:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// CreateTrainingJob creates a training job
+func (c *sageMakerClient) CreateTrainingJob(ctx context.Context, region string, input *SageMakerTrainingJob) error {
+	return c.call(ctx, region, "CreateTrainingJob", input, nil)
+}
+
+// DescribeTrainingJob describes a training job
+func (c *sageMakerClient) DescribeTrainingJob(ctx context.Context, region, name string) (*SageMakerTrainingJobDescription, error) {
+	var desc SageMakerTrainingJobDescription
+	if err := c.call(ctx, region, "DescribeTrainingJob", map[string]string{"TrainingJobName": name}, &desc); err != nil {
+		return nil, err
+	}
+	return &desc, nil
+}
+
+// StopTrainingJob stops a training job
+func (c *sageMakerClient) StopTrainingJob(ctx context.Context, region, name string) error {
+	return c.call(ctx, region, "StopTrainingJob", map[string]string{"TrainingJobName": name}, nil)
+}
+
+// sageMakerErrorResponse is the JSON protocol error body
+type sageMakerErrorResponse struct {
+	Type    string `json:"__type"`
+	Message string `json:"message"`
+}
+
+// call signs and sends a SageMaker action, decoding the response into output when set
+func (c *sageMakerClient) call(ctx context.Context, region, action string, input, output interface{}) error {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	endpoint := fmt.Sprintf("https://api.sagemaker.%s.amazonaws.com/", region)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "SageMaker."+action)
+
+	creds, err := c.credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve SageMaker credentials: %w", err)
+	}
+	sum := sha256.Sum256(body)
+	if err := c.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(sum[:]), "sagemaker", region, time.Now()); err != nil {
+		return fmt.Errorf("failed to sign SageMaker request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("sagemaker %s: %w", action, err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("sagemaker %s: %w", action, err)
+	}
+	if resp.StatusCode < 300 {
+		if output == nil {
+			return nil
+		}
+		if err := json.Unmarshal(raw, output); err != nil {
+			return fmt.Errorf("sagemaker %s: invalid response: %w", action, err)
+		}
+		return nil
+	}
+
+	var apiErr sageMakerErrorResponse
+	if json.Unmarshal(raw, &apiErr) != nil || apiErr.Type == "" {
+		return fmt.Errorf("sagemaker %s: status %d: %s", action, resp.StatusCode, string(raw))
+	}
+	code := apiErr.Type[strings.LastIndex(apiErr.Type, "#")+1:]
+	if code == "ResourceNotFound" || (code == "ValidationException" && strings.Contains(apiErr.Message, "not found")) {
+		return fmt.Errorf("sagemaker %s: %w: %s", action, errSageMakerNotFound, apiErr.Message)
+	}
+	return fmt.Errorf("sagemaker %s: %s: %s", action, code, apiErr.Message)
+}
@@ -0,0 +1,81 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"gpu-orchestrator/providers"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+var _ providers.ZoneReporter = (*Client)(nil)
+
+// spotHistoryWindow is how far back ZoneOffers looks for each zone's latest spot price
+const spotHistoryWindow = 6 * time.Hour
+
+// SetSubnets sets the subnet instances launched into a zone use, by zone
+// name. Zones without a subnet use the default subnet of the zone.
+func (c *Client) SetSubnets(subnets map[string]string) {
+	c.subnets = subnets
+}
+
+// ZoneOffers returns the zones offering an instance type with their latest
+// Linux spot price from the spot price history
+func (c *Client) ZoneOffers(ctx context.Context, _ string, instanceType string) ([]providers.ZoneOffer, error) {
+	offerings, err := c.ec2Client.DescribeInstanceTypeOfferings(ctx, &ec2.DescribeInstanceTypeOfferingsInput{
+		LocationType: types.LocationTypeAvailabilityZone,
+		Filters: []types.Filter{
+			{Name: aws.String("instance-type"), Values: []string{instanceType}},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe instance type offerings: %w", err)
+	}
+
+	history, err := c.ec2Client.DescribeSpotPriceHistory(ctx, &ec2.DescribeSpotPriceHistoryInput{
+		InstanceTypes:       []types.InstanceType{types.InstanceType(instanceType)},
+		ProductDescriptions: []string{"Linux/UNIX"},
+		StartTime:           aws.Time(time.Now().Add(-spotHistoryWindow)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe spot price history: %w", err)
+	}
+
+	// History is newest first; keep each zone's latest price
+	prices := make(map[string]float64)
+	for _, entry := range history.SpotPriceHistory {
+		zone := aws.ToString(entry.AvailabilityZone)
+		if _, seen := prices[zone]; seen {
+			continue
+		}
+		if price, err := strconv.ParseFloat(aws.ToString(entry.SpotPrice), 64); err == nil {
+			prices[zone] = price
+		}
+	}
+
+	offers := make([]providers.ZoneOffer, 0, len(offerings.InstanceTypeOfferings))
+	for _, offering := range offerings.InstanceTypeOfferings {
+		zone := aws.ToString(offering.Location)
+		offers = append(offers, providers.ZoneOffer{Zone: zone, SpotPrice: prices[zone]})
+	}
+	sort.Slice(offers, func(i, j int) bool { return offers[i].Zone < offers[j].Zone })
+	return offers, nil
+}
+
+// zonePlacement places instances in a zone, through its configured subnet
+// when there is one
+func (c *Client) zonePlacement(input *ec2.RunInstancesInput, zone string) {
+	if zone == "" {
+		return
+	}
+	input.Placement = &types.Placement{AvailabilityZone: aws.String(zone)}
+	if subnet, ok := c.subnets[zone]; ok {
+		input.SubnetId = aws.String(subnet)
+	}
+}
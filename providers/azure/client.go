@@ -4,32 +4,64 @@ import (
 	"context"
 
 	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/providers"
 )
 
+// Ensure Client satisfies the provider interface at compile time
+var _ providers.Provider = (*Client)(nil)
+
 // Client is the Azure provider client
 type Client struct {
 	subscriptionID string
 	regions        []string
-	// TODO: Phase 2 - Add Azure Compute client
-	// computeClient *compute.VirtualMachinesClient
+	compute        ComputeAPI
+	resourceGroup  string // VMs and their network interfaces are created here
+	subnetID       string // Subnet resource ID the VMs join
+	adminUser      string
+	sshPublicKey   string // Authorized for adminUser; Azure requires a key or password
 }
 
 // NewClient creates a new Azure client
 func NewClient(ctx context.Context, subscriptionID string, regions []string) (*Client, error) {
-	// Phase 2: Initialize Azure Compute client
-	// TODO: Uncomment when Azure credentials are configured:
-	// cred, err := azidentity.NewDefaultAzureCredential(nil)
-	// if err != nil {
-	// 	return nil, err
-	// }
-	// computeClient := compute.NewVirtualMachinesClient(subscriptionID, cred)
-	
 	return &Client{
 		subscriptionID: subscriptionID,
 		regions:        regions,
+		compute:        newComputeClient(""),
+		adminUser:      "azureuser",
 	}, nil
 }
 
+// SetAccessToken sets a static ARM token for compute calls; empty uses the
+// managed identity of the VM the server runs on
+func (c *Client) SetAccessToken(token string) {
+	c.compute = newComputeClient(token)
+}
+
+// SetNetwork sets the resource group VMs are created in and the subnet
+// resource ID their network interfaces join
+func (c *Client) SetNetwork(resourceGroup, subnetID string) {
+	c.resourceGroup = resourceGroup
+	c.subnetID = subnetID
+}
+
+// SetAdminUser sets the VMs' admin user and the SSH public key it authorizes
+func (c *Client) SetAdminUser(user, sshPublicKey string) {
+	if user != "" {
+		c.adminUser = user
+	}
+	c.sshPublicKey = sshPublicKey
+}
+
+// Name returns the provider identifier
+func (c *Client) Name() models.Provider {
+	return models.ProviderAzure
+}
+
+// Regions returns the configured Azure regions
+func (c *Client) Regions() []string {
+	return c.regions
+}
+
 // GetGPUInstances returns available GPU instances (Phase 2: from Azure API)
 func (c *Client) GetGPUInstances(ctx context.Context) ([]models.GPUInstance, error) {
 	// Phase 2: Query Azure Compute API for GPU instances
@@ -51,7 +83,7 @@ func (c *Client) FetchSpotPricing(ctx context.Context) ([]models.GPUInstance, er
 	instances := c.getMockGPUInstances()
 	for i := range instances {
 		instances[i].SpotPrice = instances[i].PricePerHour * 0.3 // 70% discount
-		instances[i].Availability = 0.75                        // 75% availability
+		instances[i].Availability = 0.75                         // 75% availability
 	}
 	return instances, nil
 }
@@ -65,11 +97,14 @@ func (c *Client) getMockGPUInstances() []models.GPUInstance {
 		Memory           int
 		PricePerHour     float64
 		InterconnectTier models.InterconnectTier
+		NetworkGbps      float64
+		InterconnectGbps float64 // EFA, InfiniBand or GPUDirect where the type has it, else NetworkGbps
 	}{
-		{"Standard_NC6s_v3", "V100", 1, 16, 3.50, models.InterconnectStandard},
-		{"Standard_NC12s_v3", "V100", 2, 32, 7.00, models.InterconnectStandard},
-		{"Standard_NC24s_v3", "V100", 4, 64, 14.00, models.InterconnectStandard},
-		{"Standard_NC96ads_A100_v4", "A100", 8, 320, 35.00, models.InterconnectHigh},
+		{"Standard_NC6s_v3", "V100", 1, 16, 3.50, models.InterconnectStandard, 12, 12},
+		{"Standard_NC12s_v3", "V100", 2, 32, 7.00, models.InterconnectStandard, 24, 24},
+		{"Standard_NC24s_v3", "V100", 4, 64, 14.00, models.InterconnectStandard, 24, 24},
+		{"Standard_NC96ads_A100_v4", "A100", 8, 320, 35.00, models.InterconnectHigh, 80, 80},
+		{"Standard_ND96isr_H100_v5", "H100", 8, 640, 98.32, models.InterconnectHigh, 80, 3200},
 	}
 
 	var instances []models.GPUInstance
@@ -84,6 +119,8 @@ func (c *Client) getMockGPUInstances() []models.GPUInstance {
 				MemoryPerGPU:     gpu.Memory,
 				PricePerHour:     gpu.PricePerHour,
 				InterconnectTier: gpu.InterconnectTier,
+				NetworkGbps:      gpu.NetworkGbps,
+				InterconnectGbps: gpu.InterconnectGbps,
 			})
 		}
 	}
@@ -0,0 +1,340 @@
+package azure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// armEndpoint is the Azure Resource Manager endpoint
+const armEndpoint = "https://management.azure.com"
+
+// computeAPIVersion and networkAPIVersion are the ARM API versions of the
+// Compute and Network resource providers
+const (
+	computeAPIVersion = "2023-09-01"
+	networkAPIVersion = "2023-09-01"
+)
+
+// metadataTokenURL serves ARM access tokens for the VM's managed identity
+const metadataTokenURL = "http://169.254.169.254/metadata/identity/oauth2/token?api-version=2018-02-01&resource=https%3A%2F%2Fmanagement.azure.com%2F"
+
+// errNotFound is returned when the VM or network interface does not exist
+var errNotFound = errors.New("azure: not found")
+
+// ComputeAPI is the subset of the Compute and Network resource providers the
+// client uses to run VMs
+type ComputeAPI interface {
+	CreateNetworkInterface(ctx context.Context, subscriptionID, resourceGroup string, nic *NetworkInterface) (*NetworkInterface, error)
+	GetNetworkInterface(ctx context.Context, subscriptionID, resourceGroup, name string) (*NetworkInterface, error)
+	DeleteNetworkInterface(ctx context.Context, subscriptionID, resourceGroup, name string) error
+	CreateVirtualMachine(ctx context.Context, subscriptionID, resourceGroup string, vm *VirtualMachine) (*VirtualMachine, error)
+	GetVirtualMachine(ctx context.Context, subscriptionID, resourceGroup, name string) (*VirtualMachine, error)
+	DeleteVirtualMachine(ctx context.Context, subscriptionID, resourceGroup, name string) error
+}
+
+// NetworkInterface is a Microsoft.Network/networkInterfaces resource
+type NetworkInterface struct {
+	ID         string            `json:"id,omitempty"`
+	Name       string            `json:"-"`
+	Location   string            `json:"location"`
+	Tags       map[string]string `json:"tags,omitempty"`
+	Properties nicProperties     `json:"properties"`
+}
+
+type nicProperties struct {
+	IPConfigurations []nicIPConfiguration `json:"ipConfigurations"`
+}
+
+type nicIPConfiguration struct {
+	Name       string                       `json:"name"`
+	Properties nicIPConfigurationProperties `json:"properties"`
+}
+
+type nicIPConfigurationProperties struct {
+	Subnet                    armReference `json:"subnet"`
+	PrivateIPAllocationMethod string       `json:"privateIPAllocationMethod,omitempty"`
+	PrivateIPAddress          string       `json:"privateIPAddress,omitempty"` // Set by the service
+}
+
+// armReference refers to another resource by ID
+type armReference struct {
+	ID string `json:"id"`
+}
+
+// VirtualMachine is a Microsoft.Compute/virtualMachines resource
+type VirtualMachine struct {
+	ID         string            `json:"id,omitempty"`
+	Name       string            `json:"-"`
+	Location   string            `json:"location"`
+	Zones      []string          `json:"zones,omitempty"`
+	Tags       map[string]string `json:"tags,omitempty"`
+	Identity   *vmIdentity       `json:"identity,omitempty"`
+	Properties vmProperties      `json:"properties"`
+}
+
+// vmIdentity attaches user-assigned managed identities, keyed by resource ID
+type vmIdentity struct {
+	Type                   string                 `json:"type"`
+	UserAssignedIdentities map[string]interface{} `json:"userAssignedIdentities"`
+}
+
+type vmProperties struct {
+	HardwareProfile   vmHardwareProfile `json:"hardwareProfile"`
+	StorageProfile    vmStorageProfile  `json:"storageProfile"`
+	OSProfile         *vmOSProfile      `json:"osProfile,omitempty"` // Not returned with the instance view
+	NetworkProfile    vmNetworkProfile  `json:"networkProfile"`
+	Priority          string            `json:"priority,omitempty"`       // Regular or Spot
+	EvictionPolicy    string            `json:"evictionPolicy,omitempty"` // Spot only
+	BillingProfile    *vmBillingProfile `json:"billingProfile,omitempty"`
+	ProvisioningState string            `json:"provisioningState,omitempty"` // Set by the service
+	TimeCreated       *time.Time        `json:"timeCreated,omitempty"`       // Set by the service
+	InstanceView      *vmInstanceView   `json:"instanceView,omitempty"`      // With $expand=instanceView
+}
+
+type vmHardwareProfile struct {
+	VMSize string `json:"vmSize"`
+}
+
+type vmStorageProfile struct {
+	ImageReference vmImageReference `json:"imageReference"`
+	OSDisk         vmOSDisk         `json:"osDisk"`
+	DataDisks      []vmDataDisk     `json:"dataDisks,omitempty"`
+}
+
+// vmImageReference is a marketplace image or, with ID, a managed image or
+// gallery image version
+type vmImageReference struct {
+	ID        string `json:"id,omitempty"`
+	Publisher string `json:"publisher,omitempty"`
+	Offer     string `json:"offer,omitempty"`
+	SKU       string `json:"sku,omitempty"`
+	Version   string `json:"version,omitempty"`
+}
+
+type vmOSDisk struct {
+	CreateOption string       `json:"createOption"`
+	DeleteOption string       `json:"deleteOption,omitempty"`
+	ManagedDisk  *managedDisk `json:"managedDisk,omitempty"`
+}
+
+type vmDataDisk struct {
+	Lun          int          `json:"lun"`
+	DiskSizeGB   int          `json:"diskSizeGB"`
+	CreateOption string       `json:"createOption"`
+	DeleteOption string       `json:"deleteOption,omitempty"`
+	ManagedDisk  *managedDisk `json:"managedDisk,omitempty"`
+}
+
+type managedDisk struct {
+	StorageAccountType string `json:"storageAccountType"`
+}
+
+type vmOSProfile struct {
+	ComputerName       string                `json:"computerName"`
+	AdminUsername      string                `json:"adminUsername"`
+	CustomData         string                `json:"customData,omitempty"` // Base64
+	LinuxConfiguration *vmLinuxConfiguration `json:"linuxConfiguration,omitempty"`
+}
+
+type vmLinuxConfiguration struct {
+	DisablePasswordAuthentication bool      `json:"disablePasswordAuthentication"`
+	SSH                           vmSSHKeys `json:"ssh"`
+}
+
+type vmSSHKeys struct {
+	PublicKeys []vmSSHPublicKey `json:"publicKeys"`
+}
+
+type vmSSHPublicKey struct {
+	Path    string `json:"path"`
+	KeyData string `json:"keyData"`
+}
+
+type vmNetworkProfile struct {
+	NetworkInterfaces []vmNetworkInterface `json:"networkInterfaces"`
+}
+
+type vmNetworkInterface struct {
+	ID         string                       `json:"id"`
+	Properties vmNetworkInterfaceProperties `json:"properties"`
+}
+
+type vmNetworkInterfaceProperties struct {
+	Primary      bool   `json:"primary"`
+	DeleteOption string `json:"deleteOption,omitempty"`
+}
+
+// vmBillingProfile caps the hourly price of a spot VM; -1 = up to the
+// on-demand price
+type vmBillingProfile struct {
+	MaxPrice float64 `json:"maxPrice"`
+}
+
+type vmInstanceView struct {
+	Statuses []vmStatus `json:"statuses"`
+}
+
+// vmStatus is an instance view status, e.g. ProvisioningState/succeeded or
+// PowerState/running
+type vmStatus struct {
+	Code string `json:"code"`
+}
+
+// computeClient calls the ARM REST API directly
+type computeClient struct {
+	accessToken string // Static token; empty = use the instance metadata service
+	httpClient  *http.Client
+}
+
+// newComputeClient creates a compute client.
+// If accessToken is empty, tokens are fetched for the VM's managed identity.
+func newComputeClient(accessToken string) *computeClient {
+	return &computeClient{
+		accessToken: accessToken,
+		httpClient:  &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// resourceURL returns the ARM URL of a resource in a resource group
+func resourceURL(subscriptionID, resourceGroup, provider, kind, name, apiVersion string) string {
+	return fmt.Sprintf("%s/subscriptions/%s/resourceGroups/%s/providers/%s/%s/%s?api-version=%s",
+		armEndpoint, url.PathEscape(subscriptionID), url.PathEscape(resourceGroup), provider, kind, url.PathEscape(name), apiVersion)
+}
+
+// CreateNetworkInterface creates or updates a network interface
+func (c *computeClient) CreateNetworkInterface(ctx context.Context, subscriptionID, resourceGroup string, nic *NetworkInterface) (*NetworkInterface, error) {
+	rawURL := resourceURL(subscriptionID, resourceGroup, "Microsoft.Network", "networkInterfaces", nic.Name, networkAPIVersion)
+	var created NetworkInterface
+	if err := c.doJSON(ctx, http.MethodPut, rawURL, nic, &created); err != nil {
+		return nil, err
+	}
+	created.Name = nic.Name
+	return &created, nil
+}
+
+// GetNetworkInterface returns a network interface
+func (c *computeClient) GetNetworkInterface(ctx context.Context, subscriptionID, resourceGroup, name string) (*NetworkInterface, error) {
+	rawURL := resourceURL(subscriptionID, resourceGroup, "Microsoft.Network", "networkInterfaces", name, networkAPIVersion)
+	var nic NetworkInterface
+	if err := c.doJSON(ctx, http.MethodGet, rawURL, nil, &nic); err != nil {
+		return nil, err
+	}
+	nic.Name = name
+	return &nic, nil
+}
+
+// DeleteNetworkInterface starts deleting a network interface
+func (c *computeClient) DeleteNetworkInterface(ctx context.Context, subscriptionID, resourceGroup, name string) error {
+	rawURL := resourceURL(subscriptionID, resourceGroup, "Microsoft.Network", "networkInterfaces", name, networkAPIVersion)
+	return c.doJSON(ctx, http.MethodDelete, rawURL, nil, nil)
+}
+
+// CreateVirtualMachine starts creating a VM. The returned VM is still
+// provisioning; quota and capacity errors are reported synchronously.
+func (c *computeClient) CreateVirtualMachine(ctx context.Context, subscriptionID, resourceGroup string, vm *VirtualMachine) (*VirtualMachine, error) {
+	rawURL := resourceURL(subscriptionID, resourceGroup, "Microsoft.Compute", "virtualMachines", vm.Name, computeAPIVersion)
+	var created VirtualMachine
+	if err := c.doJSON(ctx, http.MethodPut, rawURL, vm, &created); err != nil {
+		return nil, err
+	}
+	created.Name = vm.Name
+	return &created, nil
+}
+
+// GetVirtualMachine returns a VM with its instance view
+func (c *computeClient) GetVirtualMachine(ctx context.Context, subscriptionID, resourceGroup, name string) (*VirtualMachine, error) {
+	rawURL := resourceURL(subscriptionID, resourceGroup, "Microsoft.Compute", "virtualMachines", name, computeAPIVersion) + "&$expand=instanceView"
+	var vm VirtualMachine
+	if err := c.doJSON(ctx, http.MethodGet, rawURL, nil, &vm); err != nil {
+		return nil, err
+	}
+	vm.Name = name
+	return &vm, nil
+}
+
+// DeleteVirtualMachine starts deleting a VM, along with the disks and
+// network interface it was created to delete
+func (c *computeClient) DeleteVirtualMachine(ctx context.Context, subscriptionID, resourceGroup, name string) error {
+	rawURL := resourceURL(subscriptionID, resourceGroup, "Microsoft.Compute", "virtualMachines", name, computeAPIVersion)
+	return c.doJSON(ctx, http.MethodDelete, rawURL, nil, nil)
+}
+
+// doJSON performs an authenticated JSON request
+func (c *computeClient) doJSON(ctx context.Context, method, rawURL string, in, out interface{}) error {
+	token, err := c.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	var body io.Reader
+	if in != nil {
+		payload, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return errNotFound
+	case resp.StatusCode >= 300:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s: status %d: %s", method, rawURL, resp.StatusCode, string(msg))
+	}
+
+	// Accepted deletes have no body
+	if out == nil || resp.StatusCode == http.StatusAccepted || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// token returns the static token or one for the VM's managed identity
+func (c *computeClient) token(ctx context.Context) (string, error) {
+	if c.accessToken != "" {
+		return c.accessToken, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, metadataTokenURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata", "true")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch Azure token from metadata service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var tok struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return "", fmt.Errorf("failed to decode Azure token: %w", err)
+	}
+	return tok.AccessToken, nil
+}
@@ -0,0 +1,303 @@
+package azure
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"gpu-orchestrator/providers"
+)
+
+// defaultImage is the marketplace image VMs boot when no registered image was
+// chosen: Ubuntu 22.04 with the NVIDIA driver, CUDA and InfiniBand stack
+var defaultImage = vmImageReference{
+	Publisher: "microsoft-dsvm",
+	Offer:     "ubuntu-hpc",
+	SKU:       "2204",
+	Version:   "latest",
+}
+
+// ProvisionGPUInstance creates count GPU VMs, each with its own network
+// interface in the configured subnet, and returns their names. VMs are
+// created one at a time; when one fails, the names of those already created
+// are returned with an error saying how many were, so the caller can
+// terminate them.
+func (c *Client) ProvisionGPUInstance(
+	ctx context.Context,
+	vmSize string,
+	region string,
+	zone string, // Availability zone ("1", "2", "3"); empty = Azure's choice
+	spot bool,
+	spotMaxPrice float64, // USD per VM-hour; 0 = up to the on-demand price
+	count int,
+	bootstrapScript string,
+	identity string, // User-assigned managed identity resource ID; empty = none
+	dataVolume *providers.DataVolume, // Premium SSD per VM; nil = none
+	imageID string, // Managed image or gallery image version ID; empty = the generic GPU image
+) ([]string, error) { // Returns VM names
+	if c.resourceGroup == "" || c.subnetID == "" {
+		return nil, fmt.Errorf("Azure resource group and subnet not configured")
+	}
+	if c.sshPublicKey == "" {
+		return nil, fmt.Errorf("Azure admin SSH public key not configured")
+	}
+
+	prefix, err := vmNamePrefix()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for i := 0; i < count; i++ {
+		name := fmt.Sprintf("%s-%d", prefix, i)
+		if err := c.createVM(ctx, name, vmSize, region, zone, spot, spotMaxPrice, bootstrapScript, identity, dataVolume, imageID); err != nil {
+			return names, fmt.Errorf("created %d of %d Azure VMs: %w", len(names), count, err)
+		}
+		names = append(names, name)
+	}
+
+	return names, nil
+}
+
+// createVM creates one VM and its network interface. The interface is
+// deleted again when the VM cannot be created.
+func (c *Client) createVM(
+	ctx context.Context,
+	name, vmSize, region, zone string,
+	spot bool,
+	spotMaxPrice float64,
+	bootstrapScript, identity string,
+	dataVolume *providers.DataVolume,
+	imageID string,
+) error {
+	tags := map[string]string{"ManagedBy": "gpu-orchestrator"}
+
+	nic, err := c.compute.CreateNetworkInterface(ctx, c.subscriptionID, c.resourceGroup, &NetworkInterface{
+		Name:     nicName(name),
+		Location: region,
+		Tags:     tags,
+		Properties: nicProperties{
+			IPConfigurations: []nicIPConfiguration{{
+				Name: "ipconfig1",
+				Properties: nicIPConfigurationProperties{
+					Subnet:                    armReference{ID: c.subnetID},
+					PrivateIPAllocationMethod: "Dynamic",
+				},
+			}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create network interface of %s: %w", name, err)
+	}
+
+	vm := &VirtualMachine{
+		Name:     name,
+		Location: region,
+		Tags:     tags,
+		Properties: vmProperties{
+			HardwareProfile: vmHardwareProfile{VMSize: vmSize},
+			StorageProfile: vmStorageProfile{
+				ImageReference: imageReference(imageID),
+				OSDisk: vmOSDisk{
+					CreateOption: "FromImage",
+					DeleteOption: "Delete",
+					ManagedDisk:  &managedDisk{StorageAccountType: "Premium_LRS"},
+				},
+			},
+			OSProfile: &vmOSProfile{
+				ComputerName:  name,
+				AdminUsername: c.adminUser,
+				CustomData:    customData(bootstrapScript),
+				LinuxConfiguration: &vmLinuxConfiguration{
+					DisablePasswordAuthentication: true,
+					SSH: vmSSHKeys{PublicKeys: []vmSSHPublicKey{{
+						Path:    fmt.Sprintf("/home/%s/.ssh/authorized_keys", c.adminUser),
+						KeyData: c.sshPublicKey,
+					}}},
+				},
+			},
+			NetworkProfile: vmNetworkProfile{NetworkInterfaces: []vmNetworkInterface{{
+				ID:         nic.ID,
+				Properties: vmNetworkInterfaceProperties{Primary: true, DeleteOption: "Delete"},
+			}}},
+		},
+	}
+	if zone != "" {
+		vm.Zones = []string{zone}
+	}
+	if identity != "" {
+		vm.Identity = &vmIdentity{
+			Type:                   "UserAssigned",
+			UserAssignedIdentities: map[string]interface{}{identity: struct{}{}},
+		}
+	}
+	if dataVolume != nil {
+		vm.Properties.StorageProfile.DataDisks = []vmDataDisk{dataDisk(dataVolume)}
+	}
+	if spot {
+		// Evicted spot VMs are deleted with their disks, like terminated spot instances on AWS
+		vm.Properties.Priority = "Spot"
+		vm.Properties.EvictionPolicy = "Delete"
+		vm.Properties.BillingProfile = &vmBillingProfile{MaxPrice: spotBidPrice(spotMaxPrice)}
+	}
+
+	if _, err := c.compute.CreateVirtualMachine(ctx, c.subscriptionID, c.resourceGroup, vm); err != nil {
+		if delErr := c.compute.DeleteNetworkInterface(ctx, c.subscriptionID, c.resourceGroup, nicName(name)); delErr != nil && !errors.Is(delErr, errNotFound) {
+			log.Printf("Failed to delete network interface of Azure VM %s that could not be created: %v", name, delErr)
+		}
+		return fmt.Errorf("failed to create VM %s: %w", name, err)
+	}
+	return nil
+}
+
+// vmNamePrefix returns a random prefix for the VMs of one request
+func vmNamePrefix() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate VM name: %w", err)
+	}
+	return "gpu-" + hex.EncodeToString(b), nil
+}
+
+// nicName returns the name of a VM's network interface
+func nicName(vmName string) string {
+	return vmName + "-nic"
+}
+
+// imageReference returns the image of a registered image ID, or the generic
+// GPU image
+func imageReference(imageID string) vmImageReference {
+	if imageID == "" {
+		return defaultImage
+	}
+	return vmImageReference{ID: imageID}
+}
+
+// customData returns the base64 custom data that runs a boot script through
+// cloud-init; empty for none
+func customData(script string) string {
+	if script == "" {
+		return ""
+	}
+	if !strings.HasPrefix(script, "#!") {
+		script = "#!/bin/bash\nset -e\n\n" + script
+	}
+	return base64.StdEncoding.EncodeToString([]byte(script))
+}
+
+// dataDisk returns the Premium SSD data disk of a volume request, deleted
+// with the VM. IOPS and throughput come from the disk size on Premium SSD.
+func dataDisk(volume *providers.DataVolume) vmDataDisk {
+	return vmDataDisk{
+		Lun:          0,
+		DiskSizeGB:   volume.SizeGB,
+		CreateOption: "Empty",
+		DeleteOption: "Delete",
+		ManagedDisk:  &managedDisk{StorageAccountType: "Premium_LRS"},
+	}
+}
+
+// spotBidPrice returns the max price of a spot VM; -1 caps it at the
+// on-demand price
+func spotBidPrice(maxPrice float64) float64 {
+	if maxPrice <= 0 {
+		return -1
+	}
+	return maxPrice
+}
+
+// ProvisionInstances provisions Azure VMs for a provider-agnostic request
+func (c *Client) ProvisionInstances(ctx context.Context, req providers.InstanceRequest) ([]string, error) {
+	return c.ProvisionGPUInstance(ctx, req.InstanceType, req.Region, req.Zone, req.Spot, req.SpotMaxPrice, req.Count, req.BootstrapScript, req.Identity, req.DataVolume, req.ImageID)
+}
+
+// TerminateInstances deletes Azure VMs with their disks and network
+// interfaces. VMs that no longer exist are skipped; a failed deletion does
+// not stop the others.
+func (c *Client) TerminateInstances(ctx context.Context, _ string, instanceIDs []string) error {
+	if len(instanceIDs) == 0 {
+		return nil
+	}
+	if c.resourceGroup == "" {
+		return fmt.Errorf("Azure resource group not configured")
+	}
+
+	var errs []error
+	for _, name := range instanceIDs {
+		err := c.compute.DeleteVirtualMachine(ctx, c.subscriptionID, c.resourceGroup, name)
+		if err != nil && !errors.Is(err, errNotFound) {
+			errs = append(errs, fmt.Errorf("failed to terminate VM %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// DescribeInstances returns the current state of Azure VMs. VMs that no
+// longer exist are reported terminated.
+func (c *Client) DescribeInstances(ctx context.Context, region string, instanceIDs []string) ([]providers.InstanceInfo, error) {
+	if len(instanceIDs) == 0 {
+		return nil, nil
+	}
+	if c.resourceGroup == "" {
+		return nil, fmt.Errorf("Azure resource group not configured")
+	}
+
+	infos := make([]providers.InstanceInfo, 0, len(instanceIDs))
+	for _, name := range instanceIDs {
+		vm, err := c.compute.GetVirtualMachine(ctx, c.subscriptionID, c.resourceGroup, name)
+		if errors.Is(err, errNotFound) {
+			infos = append(infos, providers.InstanceInfo{InstanceID: name, Region: region, State: providers.InstanceStateTerminated})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe VM %s: %w", name, err)
+		}
+
+		info := providers.InstanceInfo{
+			InstanceID:   name,
+			InstanceType: vm.Properties.HardwareProfile.VMSize,
+			Region:       vm.Location,
+			State:        vmState(vm),
+			LaunchedAt:   vm.Properties.TimeCreated,
+		}
+		if len(vm.Zones) > 0 {
+			info.Zone = vm.Zones[0]
+		}
+		if nic, err := c.compute.GetNetworkInterface(ctx, c.subscriptionID, c.resourceGroup, nicName(name)); err == nil && len(nic.Properties.IPConfigurations) > 0 {
+			info.PrivateIP = nic.Properties.IPConfigurations[0].Properties.PrivateIPAddress
+		}
+		infos = append(infos, info)
+	}
+
+	return infos, nil
+}
+
+// vmState maps a VM's provisioning and power state to a provider-agnostic
+// state
+func vmState(vm *VirtualMachine) providers.InstanceState {
+	switch vm.Properties.ProvisioningState {
+	case "Creating":
+		return providers.InstanceStatePending
+	case "Deleting":
+		return providers.InstanceStateTerminated
+	}
+
+	if vm.Properties.InstanceView != nil {
+		for _, status := range vm.Properties.InstanceView.Statuses {
+			switch status.Code {
+			case "PowerState/starting":
+				return providers.InstanceStatePending
+			case "PowerState/running":
+				return providers.InstanceStateRunning
+			case "PowerState/stopping", "PowerState/stopped", "PowerState/deallocating", "PowerState/deallocated":
+				return providers.InstanceStateStopped
+			}
+		}
+	}
+	return providers.InstanceStateUnknown
+}
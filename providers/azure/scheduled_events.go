@@ -0,0 +1,113 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gpu-orchestrator/providers"
+)
+
+var _ providers.MaintenanceReporter = (*Client)(nil)
+
+// scheduledEventsDocument is the Scheduled Events document the instance
+// metadata service serves to a VM (api-version 2020-07-01)
+type scheduledEventsDocument struct {
+	DocumentIncarnation int              `json:"DocumentIncarnation"`
+	Events              []scheduledEvent `json:"Events"`
+}
+
+// scheduledEvent is one event of a Scheduled Events document
+type scheduledEvent struct {
+	EventID           string   `json:"EventId"`
+	EventType         string   `json:"EventType"` // Freeze, Reboot, Redeploy, Preempt, Terminate
+	ResourceType      string   `json:"ResourceType"`
+	Resources         []string `json:"Resources"`   // VM names
+	EventStatus       string   `json:"EventStatus"` // Scheduled or Started
+	NotBefore         string   `json:"NotBefore"`   // RFC 1123; empty once started
+	Description       string   `json:"Description"`
+	EventSource       string   `json:"EventSource"` // Platform or User
+	DurationInSeconds int      `json:"DurationInSeconds"`
+}
+
+// maintenanceEventTypes are the platform events that interrupt a running VM.
+// Preempt is a spot eviction and Terminate is user initiated.
+var maintenanceEventTypes = map[string]bool{
+	"Freeze":   true,
+	"Reboot":   true,
+	"Redeploy": true,
+}
+
+// ScheduledEvents returns the upcoming platform maintenance of Azure VMs
+func (c *Client) ScheduledEvents(ctx context.Context, region string, instanceIDs []string) ([]providers.MaintenanceEvent, error) {
+	var events []providers.MaintenanceEvent
+	for _, instanceID := range instanceIDs {
+		document, err := c.fetchScheduledEvents(ctx, region, instanceID)
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := parseScheduledEvents(document, instanceID, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse scheduled events of %s: %w", instanceID, err)
+		}
+		events = append(events, parsed...)
+	}
+	return events, nil
+}
+
+// fetchScheduledEvents returns the Scheduled Events document of a VM. The
+// metadata service only answers from inside the VM, so it is relayed.
+func (c *Client) fetchScheduledEvents(_ context.Context, _ string, _ string) ([]byte, error) {
+	// TODO: Relay through computeClient.BeginRunCommand:
+	// curl -H Metadata:true http://169.254.169.254/metadata/scheduledevents?api-version=2020-07-01
+	return nil, fmt.Errorf("Azure scheduled events not yet implemented")
+}
+
+// parseScheduledEvents returns the platform maintenance events of a Scheduled
+// Events document that affect a VM. Started events have no NotBefore and
+// start now.
+func parseScheduledEvents(document []byte, vmName string, now time.Time) ([]providers.MaintenanceEvent, error) {
+	var doc scheduledEventsDocument
+	if err := json.Unmarshal(document, &doc); err != nil {
+		return nil, err
+	}
+
+	var events []providers.MaintenanceEvent
+	for _, e := range doc.Events {
+		if !maintenanceEventTypes[e.EventType] || e.EventSource == "User" || !containsString(e.Resources, vmName) {
+			continue
+		}
+		notBefore := now
+		if e.NotBefore != "" {
+			parsed, err := time.Parse(http.TimeFormat, e.NotBefore)
+			if err != nil {
+				return nil, fmt.Errorf("invalid NotBefore %q of event %s: %w", e.NotBefore, e.EventID, err)
+			}
+			notBefore = parsed
+		}
+		event := providers.MaintenanceEvent{
+			InstanceID:  vmName,
+			EventType:   e.EventType,
+			Description: e.Description,
+			NotBefore:   notBefore,
+		}
+		if e.DurationInSeconds > 0 {
+			notAfter := notBefore.Add(time.Duration(e.DurationInSeconds) * time.Second)
+			event.NotAfter = &notAfter
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
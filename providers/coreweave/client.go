@@ -0,0 +1,331 @@
+package coreweave
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/providers"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Ensure Client satisfies the provider interface at compile time
+var _ providers.Provider = (*Client)(nil)
+
+// Client is the CoreWeave provider client.
+// Pricing comes either from a static price sheet (YAML) or from the REST API;
+// provisioning goes through the REST instance API.
+type Client struct {
+	endpoint   string
+	apiToken   string
+	regions    []string
+	priceSheet *PriceSheet
+	httpClient *http.Client
+}
+
+// PriceSheet is a static price list for specialty clouds that don't expose a pricing API
+type PriceSheet struct {
+	Instances []PriceSheetEntry `yaml:"instances" json:"instances"`
+}
+
+// PriceSheetEntry is a single instance type in a price sheet
+type PriceSheetEntry struct {
+	InstanceType     string  `yaml:"instance_type" json:"instance_type"`
+	GPUType          string  `yaml:"gpu_type" json:"gpu_type"`
+	GPUs             int     `yaml:"gpus" json:"gpus"`
+	MemoryPerGPU     int     `yaml:"memory_per_gpu_gb" json:"memory_per_gpu_gb"`
+	PricePerHour     float64 `yaml:"price_per_hour" json:"price_per_hour"`
+	SpotPricePerHour float64 `yaml:"spot_price_per_hour" json:"spot_price_per_hour"`
+	SpotAvailability float64 `yaml:"spot_availability" json:"spot_availability"`
+	Interconnect     string  `yaml:"interconnect" json:"interconnect"`
+	NetworkGbps      float64 `yaml:"network_gbps" json:"network_gbps"`
+	InterconnectGbps float64 `yaml:"interconnect_gbps" json:"interconnect_gbps"` // Node-to-node (InfiniBand); 0 = unknown
+	// Regions restricts the entry to specific regions (empty = all configured regions)
+	Regions []string `yaml:"regions" json:"regions"`
+}
+
+// NewClient creates a new CoreWeave client.
+// If priceSheetPath is set, pricing is served from that file instead of the API.
+func NewClient(_ context.Context, endpoint, apiToken string, regions []string, priceSheetPath string) (*Client, error) {
+	c := &Client{
+		endpoint:   endpoint,
+		apiToken:   apiToken,
+		regions:    regions,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+
+	if priceSheetPath != "" {
+		sheet, err := LoadPriceSheet(priceSheetPath)
+		if err != nil {
+			return nil, err
+		}
+		c.priceSheet = sheet
+	}
+
+	if c.endpoint == "" && c.priceSheet == nil {
+		return nil, fmt.Errorf("coreweave: either an API endpoint or a price sheet is required")
+	}
+
+	return c, nil
+}
+
+// LoadPriceSheet loads a static price sheet from a YAML file
+func LoadPriceSheet(path string) (*PriceSheet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read price sheet: %w", err)
+	}
+
+	var sheet PriceSheet
+	if err := yaml.Unmarshal(data, &sheet); err != nil {
+		return nil, fmt.Errorf("failed to parse price sheet: %w", err)
+	}
+
+	return &sheet, nil
+}
+
+// Name returns the provider identifier
+func (c *Client) Name() models.Provider {
+	return models.ProviderCoreWeave
+}
+
+// Regions returns the configured CoreWeave regions
+func (c *Client) Regions() []string {
+	return c.regions
+}
+
+// FetchOnDemandPricing fetches on-demand pricing from the price sheet or API
+func (c *Client) FetchOnDemandPricing(ctx context.Context) ([]models.GPUInstance, error) {
+	sheet, err := c.getPriceSheet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.toGPUInstances(sheet, false), nil
+}
+
+// FetchSpotPricing fetches preemptible pricing; entries without a spot price are skipped
+func (c *Client) FetchSpotPricing(ctx context.Context) ([]models.GPUInstance, error) {
+	sheet, err := c.getPriceSheet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.toGPUInstances(sheet, true), nil
+}
+
+// getPriceSheet returns the static price sheet or fetches it from the API
+func (c *Client) getPriceSheet(ctx context.Context) (*PriceSheet, error) {
+	if c.priceSheet != nil {
+		return c.priceSheet, nil
+	}
+
+	var sheet PriceSheet
+	if err := c.doRequest(ctx, http.MethodGet, "/v1/pricing", nil, &sheet); err != nil {
+		return nil, fmt.Errorf("failed to fetch CoreWeave pricing: %w", err)
+	}
+	return &sheet, nil
+}
+
+// toGPUInstances expands price sheet entries across configured regions
+func (c *Client) toGPUInstances(sheet *PriceSheet, spotOnly bool) []models.GPUInstance {
+	var instances []models.GPUInstance
+	now := time.Now()
+
+	for _, entry := range sheet.Instances {
+		if spotOnly && entry.SpotPricePerHour <= 0 {
+			continue
+		}
+
+		interconnect := models.InterconnectStandard
+		if entry.Interconnect == string(models.InterconnectHigh) {
+			interconnect = models.InterconnectHigh
+		}
+
+		regions := entry.Regions
+		if len(regions) == 0 {
+			regions = c.regions
+		}
+
+		for _, region := range regions {
+			instance := models.GPUInstance{
+				Provider:         models.ProviderCoreWeave,
+				InstanceType:     entry.InstanceType,
+				Region:           region,
+				GPUType:          entry.GPUType,
+				GPUsPerInstance:  entry.GPUs,
+				MemoryPerGPU:     entry.MemoryPerGPU,
+				PricePerHour:     entry.PricePerHour,
+				InterconnectTier: interconnect,
+				NetworkGbps:      entry.NetworkGbps,
+				InterconnectGbps: entry.InterconnectGbps,
+				LastUpdated:      now,
+			}
+			if spotOnly {
+				instance.SpotPrice = entry.SpotPricePerHour
+				instance.Availability = entry.SpotAvailability
+			}
+			instances = append(instances, instance)
+		}
+	}
+
+	return instances
+}
+
+// instanceRequest is the REST payload for creating instances
+type instanceRequest struct {
+	InstanceType string `json:"instance_type"`
+	Region       string `json:"region"`
+	Count        int    `json:"count"`
+	Preemptible  bool   `json:"preemptible"`
+	UserData     string `json:"user_data,omitempty"`
+	DataVolumeGB int    `json:"data_volume_gb,omitempty"` // Block volume deleted with the instance
+}
+
+// instanceResponse is the REST representation of an instance
+type instanceResponse struct {
+	ID           string     `json:"id"`
+	InstanceType string     `json:"instance_type"`
+	Region       string     `json:"region"`
+	Zone         string     `json:"zone"`
+	State        string     `json:"state"`
+	PrivateIP    string     `json:"private_ip"`
+	CreatedAt    *time.Time `json:"created_at"`
+}
+
+// ProvisionInstances provisions instances through the CoreWeave REST API
+func (c *Client) ProvisionInstances(ctx context.Context, req providers.InstanceRequest) ([]string, error) {
+	if c.endpoint == "" {
+		return nil, fmt.Errorf("CoreWeave API endpoint not configured")
+	}
+
+	var resp struct {
+		Instances []instanceResponse `json:"instances"`
+	}
+	err := c.doRequest(ctx, http.MethodPost, "/v1/instances", instanceRequest{
+		InstanceType: req.InstanceType,
+		Region:       req.Region,
+		Count:        req.Count,
+		Preemptible:  req.Spot,
+		UserData:     req.BootstrapScript,
+		DataVolumeGB: dataVolumeGB(req.DataVolume),
+	}, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to provision CoreWeave instances: %w", err)
+	}
+
+	instanceIDs := make([]string, len(resp.Instances))
+	for i, instance := range resp.Instances {
+		instanceIDs[i] = instance.ID
+	}
+
+	return instanceIDs, nil
+}
+
+// dataVolumeGB returns the size of a requested data volume, 0 for none
+func dataVolumeGB(volume *providers.DataVolume) int {
+	if volume == nil {
+		return 0
+	}
+	return volume.SizeGB
+}
+
+// TerminateInstances terminates CoreWeave instances
+func (c *Client) TerminateInstances(ctx context.Context, _ string, instanceIDs []string) error {
+	if c.endpoint == "" {
+		return fmt.Errorf("CoreWeave API endpoint not configured")
+	}
+
+	for _, id := range instanceIDs {
+		if err := c.doRequest(ctx, http.MethodDelete, "/v1/instances/"+id, nil, nil); err != nil {
+			return fmt.Errorf("failed to terminate instance %s: %w", id, err)
+		}
+	}
+
+	return nil
+}
+
+// DescribeInstances returns the current state of CoreWeave instances
+func (c *Client) DescribeInstances(ctx context.Context, _ string, instanceIDs []string) ([]providers.InstanceInfo, error) {
+	if c.endpoint == "" {
+		return nil, fmt.Errorf("CoreWeave API endpoint not configured")
+	}
+
+	infos := make([]providers.InstanceInfo, 0, len(instanceIDs))
+	for _, id := range instanceIDs {
+		var instance instanceResponse
+		if err := c.doRequest(ctx, http.MethodGet, "/v1/instances/"+id, nil, &instance); err != nil {
+			return nil, fmt.Errorf("failed to describe instance %s: %w", id, err)
+		}
+
+		infos = append(infos, providers.InstanceInfo{
+			InstanceID:   instance.ID,
+			InstanceType: instance.InstanceType,
+			Region:       instance.Region,
+			Zone:         instance.Zone,
+			State:        instanceState(instance.State),
+			PrivateIP:    instance.PrivateIP,
+			LaunchedAt:   instance.CreatedAt,
+		})
+	}
+
+	return infos, nil
+}
+
+// instanceState maps CoreWeave instance states to provider-agnostic states
+func instanceState(state string) providers.InstanceState {
+	switch state {
+	case "provisioning", "starting":
+		return providers.InstanceStatePending
+	case "running", "ready":
+		return providers.InstanceStateRunning
+	case "stopping", "stopped":
+		return providers.InstanceStateStopped
+	case "deleting", "deleted", "terminated":
+		return providers.InstanceStateTerminated
+	default:
+		return providers.InstanceStateUnknown
+	}
+}
+
+// doRequest performs an authenticated JSON request against the CoreWeave API
+func (c *Client) doRequest(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiToken)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(msg))
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
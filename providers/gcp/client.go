@@ -2,18 +2,30 @@ package gcp
 
 import (
 	"context"
+	"fmt"
 
 	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/providers"
 	// Phase 2: Uncomment when GCP credentials are configured
 	// "google.golang.org/api/compute/v1"
 	// "google.golang.org/api/option"
 )
 
+// Ensure Client satisfies the provider interface at compile time
+var _ providers.Provider = (*Client)(nil)
+
 // Client is the GCP provider client
 type Client struct {
 	// computeService *compute.Service // Phase 2: Uncomment when GCP client is initialized
 	projectID string
 	regions   []string
+
+	iam            IAMAPI // Job-scoped service accounts
+	serviceAccount string // Default service account; see SetServiceAccount
+
+	vertex       VertexAPI // Managed training jobs
+	vertexImage  string    // Custom job container image; empty = Vertex AI disabled; see SetVertexTraining
+	vertexOutput string    // Default gs:// output prefix
 }
 
 // NewClient creates a new GCP client
@@ -28,9 +40,28 @@ func NewClient(ctx context.Context, projectID string, regions []string) (*Client
 	return &Client{
 		projectID: projectID,
 		regions:   regions,
+		iam:       newIAMClient(""),
+		vertex:    newVertexClient(""),
 	}, nil
 }
 
+// SetAccessToken sets a static token for IAM, bucket policy and Vertex AI
+// calls; empty uses the GCE metadata server
+func (c *Client) SetAccessToken(token string) {
+	c.iam = newIAMClient(token)
+	c.vertex = newVertexClient(token)
+}
+
+// Name returns the provider identifier
+func (c *Client) Name() models.Provider {
+	return models.ProviderGCP
+}
+
+// Regions returns the configured GCP regions
+func (c *Client) Regions() []string {
+	return c.regions
+}
+
 // GetGPUInstances returns available GPU instances (Phase 2: from GCP API)
 func (c *Client) GetGPUInstances(ctx context.Context) ([]models.GPUInstance, error) {
 	// Phase 2: Query GCP Compute Engine API for GPU instances
@@ -66,12 +97,17 @@ func (c *Client) getMockGPUInstances() []models.GPUInstance {
 		Memory           int
 		PricePerHour     float64
 		InterconnectTier models.InterconnectTier
+		NetworkGbps      float64
+		InterconnectGbps float64 // EFA, InfiniBand or GPUDirect where the type has it, else NetworkGbps
 	}{
-		{"a2-highgpu-1g", "A100", 1, 40, 3.67, models.InterconnectStandard},
-		{"a2-highgpu-2g", "A100", 2, 80, 7.34, models.InterconnectStandard},
-		{"a2-highgpu-4g", "A100", 4, 160, 14.68, models.InterconnectStandard},
-		{"a2-highgpu-8g", "A100", 8, 320, 29.36, models.InterconnectHigh},
-		{"n1-standard-4-k80", "K80", 4, 12, 1.50, models.InterconnectStandard},
+		{"a2-highgpu-1g", "A100", 1, 40, 3.67, models.InterconnectStandard, 24, 24},
+		{"a2-highgpu-2g", "A100", 2, 80, 7.34, models.InterconnectStandard, 32, 32},
+		{"a2-highgpu-4g", "A100", 4, 160, 14.68, models.InterconnectStandard, 50, 50},
+		{"a2-highgpu-8g", "A100", 8, 320, 29.36, models.InterconnectHigh, 100, 100},
+		{"n1-standard-4-k80", "K80", 4, 12, 1.50, models.InterconnectStandard, 10, 10},
+		{"a3-highgpu-8g", "H100", 8, 640, 88.25, models.InterconnectHigh, 200, 800},
+		{"g2-standard-4", "L4", 1, 24, 0.71, models.InterconnectStandard, 10, 10},
+		{"g2-standard-48", "L4", 4, 96, 4.00, models.InterconnectStandard, 50, 50},
 	}
 
 	var instances []models.GPUInstance
@@ -86,9 +122,34 @@ func (c *Client) getMockGPUInstances() []models.GPUInstance {
 				MemoryPerGPU:     gpu.Memory,
 				PricePerHour:     gpu.PricePerHour,
 				InterconnectTier: gpu.InterconnectTier,
+				NetworkGbps:      gpu.NetworkGbps,
+				InterconnectGbps: gpu.InterconnectGbps,
 			})
 		}
 	}
 
 	return instances
 }
+
+// FetchSpotPricing returns preemptible pricing (GCP's equivalent of spot)
+func (c *Client) FetchSpotPricing(ctx context.Context) ([]models.GPUInstance, error) {
+	return c.FetchPreemptiblePricing(ctx)
+}
+
+// ProvisionInstances provisions GCP instances
+func (c *Client) ProvisionInstances(_ context.Context, _ providers.InstanceRequest) ([]string, error) {
+	// TODO: Implement GCP provisioning (req.DataVolume as an auto-deleted pd-ssd disk)
+	return nil, fmt.Errorf("GCP provisioning not yet implemented")
+}
+
+// TerminateInstances terminates GCP instances
+func (c *Client) TerminateInstances(_ context.Context, _ string, _ []string) error {
+	// TODO: Implement GCP termination
+	return fmt.Errorf("GCP termination not yet implemented")
+}
+
+// DescribeInstances returns the current state of GCP instances
+func (c *Client) DescribeInstances(_ context.Context, _ string, _ []string) ([]providers.InstanceInfo, error) {
+	// TODO: Implement via computeService.Instances.Get()
+	return nil, fmt.Errorf("GCP instance describe not yet implemented")
+}
@@ -0,0 +1,173 @@
+package gcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// metadataTokenURL serves access tokens for the VM's service account
+const metadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+// errNotFound is returned when the service account or bucket does not exist
+var errNotFound = errors.New("gcp: not found")
+
+// errConflict is returned when the resource exists or its etag changed
+var errConflict = errors.New("gcp: conflict")
+
+// IAMAPI is the subset of IAM and Cloud Storage the client uses to manage job
+// service accounts
+type IAMAPI interface {
+	CreateServiceAccount(ctx context.Context, project, accountID, displayName string) error
+	DeleteServiceAccount(ctx context.Context, email string) error
+	GetBucketPolicy(ctx context.Context, bucket string) (*BucketPolicy, error)
+	SetBucketPolicy(ctx context.Context, bucket string, policy *BucketPolicy) error
+}
+
+// BucketPolicy is a Cloud Storage bucket IAM policy (version 3 for conditions)
+type BucketPolicy struct {
+	Version  int             `json:"version"`
+	Etag     string          `json:"etag,omitempty"`
+	Bindings []BucketBinding `json:"bindings"`
+}
+
+// BucketBinding grants a role to members, optionally under a condition
+type BucketBinding struct {
+	Role      string        `json:"role"`
+	Members   []string      `json:"members"`
+	Condition *IAMCondition `json:"condition,omitempty"`
+}
+
+// IAMCondition is a CEL condition on an IAM binding
+type IAMCondition struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Expression  string `json:"expression"`
+}
+
+// iamClient calls the IAM and Cloud Storage JSON APIs directly
+type iamClient struct {
+	accessToken string // Static token; empty = use the GCE metadata server
+	httpClient  *http.Client
+}
+
+// newIAMClient creates an IAM client.
+// If accessToken is empty, tokens are fetched from the GCE metadata server.
+func newIAMClient(accessToken string) *iamClient {
+	return &iamClient{
+		accessToken: accessToken,
+		httpClient:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// CreateServiceAccount creates a service account in the project
+func (c *iamClient) CreateServiceAccount(ctx context.Context, project, accountID, displayName string) error {
+	body := map[string]interface{}{
+		"accountId":      accountID,
+		"serviceAccount": map[string]string{"displayName": displayName},
+	}
+	path := fmt.Sprintf("https://iam.googleapis.com/v1/projects/%s/serviceAccounts", url.PathEscape(project))
+	return c.doJSON(ctx, http.MethodPost, path, body, nil)
+}
+
+// DeleteServiceAccount deletes a service account by email
+func (c *iamClient) DeleteServiceAccount(ctx context.Context, email string) error {
+	path := fmt.Sprintf("https://iam.googleapis.com/v1/projects/-/serviceAccounts/%s", url.PathEscape(email))
+	return c.doJSON(ctx, http.MethodDelete, path, nil, nil)
+}
+
+// GetBucketPolicy reads a bucket's IAM policy, conditions included
+func (c *iamClient) GetBucketPolicy(ctx context.Context, bucket string) (*BucketPolicy, error) {
+	var policy BucketPolicy
+	path := fmt.Sprintf("https://storage.googleapis.com/storage/v1/b/%s/iam?optionsRequestedPolicyVersion=3", url.PathEscape(bucket))
+	if err := c.doJSON(ctx, http.MethodGet, path, nil, &policy); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// SetBucketPolicy writes a bucket's IAM policy; a changed etag returns errConflict
+func (c *iamClient) SetBucketPolicy(ctx context.Context, bucket string, policy *BucketPolicy) error {
+	path := fmt.Sprintf("https://storage.googleapis.com/storage/v1/b/%s/iam", url.PathEscape(bucket))
+	return c.doJSON(ctx, http.MethodPut, path, policy, nil)
+}
+
+// doJSON performs an authenticated JSON request
+func (c *iamClient) doJSON(ctx context.Context, method, rawURL string, in, out interface{}) error {
+	token, err := c.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	var body io.Reader
+	if in != nil {
+		payload, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return errNotFound
+	case resp.StatusCode == http.StatusConflict || resp.StatusCode == http.StatusPreconditionFailed:
+		return errConflict
+	case resp.StatusCode >= 300:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s: status %d: %s", method, rawURL, resp.StatusCode, string(msg))
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// token returns the static token or one from the metadata server
+func (c *iamClient) token(ctx context.Context) (string, error) {
+	if c.accessToken != "" {
+		return c.accessToken, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, metadataTokenURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch GCP token from metadata server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var tok struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return "", fmt.Errorf("failed to decode GCP token: %w", err)
+	}
+	return tok.AccessToken, nil
+}
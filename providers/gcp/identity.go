@@ -0,0 +1,190 @@
+package gcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/providers"
+)
+
+var _ providers.IdentityProvisioner = (*Client)(nil)
+
+const (
+	// policyRetries bounds read-modify-write attempts on a bucket policy
+	policyRetries = 5
+	// roleObjectViewer reads and lists objects
+	roleObjectViewer = "roles/storage.objectViewer"
+	// roleObjectCreator creates objects
+	roleObjectCreator = "roles/storage.objectCreator"
+)
+
+// SetServiceAccount sets the service account attached when a job asks for no
+// identity; empty uses the project's default compute service account
+func (c *Client) SetServiceAccount(email string) {
+	c.serviceAccount = email
+}
+
+// DefaultIdentity returns the configured service account
+func (c *Client) DefaultIdentity() models.JobIdentity {
+	name := c.serviceAccount
+	if name == "" {
+		name = "default"
+	}
+	return models.JobIdentity{
+		Provider: models.ProviderGCP,
+		Kind:     models.IdentityServiceAccount,
+		Name:     name,
+	}
+}
+
+// CreateJobIdentity creates a service account and binds it on each gs://
+// grant's bucket with a condition limited to the grant's object or prefix.
+// Grants on other schemes are left out.
+func (c *Client) CreateJobIdentity(ctx context.Context, jobID string, grants []models.StorageGrant) (models.JobIdentity, error) {
+	var scoped []models.StorageGrant
+	for _, grant := range grants {
+		if strings.HasPrefix(grant.URI, "gs://") {
+			scoped = append(scoped, grant)
+		} else {
+			log.Printf("Job identity: %s is not on GCS and is not granted", grant.URI)
+		}
+	}
+	if len(scoped) == 0 {
+		return models.JobIdentity{}, fmt.Errorf("job identity needs at least one gs:// entrypoint, dataset or output URI")
+	}
+
+	accountID := serviceAccountID(jobID)
+	if err := c.iam.CreateServiceAccount(ctx, c.projectID, accountID, "gpu job "+jobID); err != nil && !errors.Is(err, errConflict) {
+		return models.JobIdentity{}, fmt.Errorf("failed to create service account for job %s: %w", jobID, err)
+	}
+
+	identity := models.JobIdentity{
+		Provider: models.ProviderGCP,
+		Kind:     models.IdentityServiceAccount,
+		Name:     fmt.Sprintf("%s@%s.iam.gserviceaccount.com", accountID, c.projectID),
+		Managed:  true,
+		Grants:   scoped,
+	}
+	for _, grant := range scoped {
+		bucket, key, err := splitGCSURI(grant.URI)
+		if err == nil {
+			err = c.updateBucketPolicy(ctx, bucket, func(policy *BucketPolicy) {
+				policy.Bindings = append(policy.Bindings, grantBindings(identity.Name, bucket, key, grant.Write)...)
+			})
+		}
+		if err != nil {
+			if cleanupErr := c.DeleteJobIdentity(ctx, identity); cleanupErr != nil {
+				log.Printf("Failed to clean up identity %s after a failed create: %v", identity.Name, cleanupErr)
+			}
+			return models.JobIdentity{}, fmt.Errorf("failed to grant %s to job %s: %w", grant.URI, jobID, err)
+		}
+	}
+	return identity, nil
+}
+
+// DeleteJobIdentity removes the service account's bucket bindings, then the account
+func (c *Client) DeleteJobIdentity(ctx context.Context, identity models.JobIdentity) error {
+	member := "serviceAccount:" + identity.Name
+	done := make(map[string]bool)
+	for _, grant := range identity.Grants {
+		bucket, _, err := splitGCSURI(grant.URI)
+		if err != nil || done[bucket] {
+			continue
+		}
+		done[bucket] = true
+
+		err = c.updateBucketPolicy(ctx, bucket, func(policy *BucketPolicy) {
+			policy.Bindings = removeMember(policy.Bindings, member)
+		})
+		if err != nil && !errors.Is(err, errNotFound) {
+			return fmt.Errorf("failed to remove %s from bucket %s: %w", identity.Name, bucket, err)
+		}
+	}
+
+	if err := c.iam.DeleteServiceAccount(ctx, identity.Name); err != nil && !errors.Is(err, errNotFound) {
+		return fmt.Errorf("failed to delete service account %s: %w", identity.Name, err)
+	}
+	return nil
+}
+
+// updateBucketPolicy applies change to a bucket policy, retrying when a
+// concurrent writer changed it in between
+func (c *Client) updateBucketPolicy(ctx context.Context, bucket string, change func(*BucketPolicy)) error {
+	for attempt := 1; ; attempt++ {
+		policy, err := c.iam.GetBucketPolicy(ctx, bucket)
+		if err != nil {
+			return err
+		}
+		change(policy)
+		policy.Version = 3 // Required for conditional bindings
+		err = c.iam.SetBucketPolicy(ctx, bucket, policy)
+		if !errors.Is(err, errConflict) || attempt == policyRetries {
+			return err
+		}
+	}
+}
+
+// grantBindings builds the conditional bindings for one grant. The bucket
+// itself is included so the prefix can be listed; object names elsewhere in
+// the bucket are listable but not readable.
+func grantBindings(email, bucket, key string, write bool) []BucketBinding {
+	bucketName := "projects/_/buckets/" + bucket
+	objects := bucketName + "/objects/"
+	expression := fmt.Sprintf("resource.name.startsWith(%q)", objects)
+	if key != "" {
+		expression = fmt.Sprintf("resource.name == %q || resource.name.startsWith(%q)", objects+key, objects+key+"/")
+	}
+	condition := &IAMCondition{
+		Title:       "gpu-job-access",
+		Description: "Scoped to " + fmt.Sprintf("gs://%s/%s", bucket, key),
+		Expression:  fmt.Sprintf("resource.name == %q || %s", bucketName, expression),
+	}
+
+	member := "serviceAccount:" + email
+	bindings := []BucketBinding{{Role: roleObjectViewer, Members: []string{member}, Condition: condition}}
+	if write {
+		bindings = append(bindings, BucketBinding{Role: roleObjectCreator, Members: []string{member}, Condition: condition})
+	}
+	return bindings
+}
+
+// removeMember drops a member from every binding and drops bindings left empty
+func removeMember(bindings []BucketBinding, member string) []BucketBinding {
+	kept := bindings[:0]
+	for _, binding := range bindings {
+		members := binding.Members[:0]
+		for _, m := range binding.Members {
+			if m != member {
+				members = append(members, m)
+			}
+		}
+		binding.Members = members
+		if len(members) > 0 {
+			kept = append(kept, binding)
+		}
+	}
+	return kept
+}
+
+// serviceAccountID derives a valid account ID (6-30 characters) from a job ID
+func serviceAccountID(jobID string) string {
+	id := "gpu-job-" + strings.ToLower(strings.ReplaceAll(jobID, "-", ""))
+	if len(id) > 30 {
+		id = id[:30]
+	}
+	return id
+}
+
+// splitGCSURI splits gs://bucket/key into bucket and key (without trailing slash)
+func splitGCSURI(uri string) (string, string, error) {
+	rest := strings.TrimPrefix(uri, "gs://")
+	bucket, key, _ := strings.Cut(rest, "/")
+	if bucket == "" {
+		return "", "", fmt.Errorf("invalid GCS URI %q", uri)
+	}
+	return bucket, strings.TrimSuffix(key, "/"), nil
+}
@@ -0,0 +1,335 @@
+package gcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/providers"
+)
+
+var _ providers.ManagedTrainer = (*Client)(nil)
+
+// vertexPriceFactor is what Vertex AI Custom Training charges for a machine
+// type relative to the same Compute Engine machine type
+const vertexPriceFactor = 1.15
+
+// vertexDefaultRuntime stops custom jobs without an estimated runtime
+const vertexDefaultRuntime = 7 * 24 * time.Hour
+
+// vertexAccelerators are the accelerator types Vertex AI attaches to the
+// machine types it trains on, by GPU type
+var vertexAccelerators = map[string]string{
+	"A100": "NVIDIA_TESLA_A100",
+	"H100": "NVIDIA_H100_80GB",
+	"L4":   "NVIDIA_L4",
+	"V100": "NVIDIA_TESLA_V100",
+	"T4":   "NVIDIA_TESLA_T4",
+}
+
+// vertexMachineFamilies are the GPU machine families custom jobs run on
+var vertexMachineFamilies = []string{"a2-", "a3-", "g2-"}
+
+// vertexLabelInvalid matches characters labels may not contain
+var vertexLabelInvalid = regexp.MustCompile(`[^a-z0-9_-]`)
+
+// VertexAPI is the subset of Vertex AI the client uses to run custom jobs
+type VertexAPI interface {
+	CreateCustomJob(ctx context.Context, project, region string, job *VertexCustomJob) (*VertexCustomJob, error)
+	GetCustomJob(ctx context.Context, region, name string) (*VertexCustomJob, error)
+	CancelCustomJob(ctx context.Context, region, name string) error
+}
+
+// VertexCustomJob is a Vertex AI CustomJob resource
+type VertexCustomJob struct {
+	Name        string            `json:"name,omitempty"` // projects/*/locations/*/customJobs/*; set by the service
+	DisplayName string            `json:"displayName"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	JobSpec     vertexJobSpec     `json:"jobSpec"`
+	State       string            `json:"state,omitempty"` // JOB_STATE_*
+	StartTime   *time.Time        `json:"startTime,omitempty"`
+	EndTime     *time.Time        `json:"endTime,omitempty"`
+	Error       *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+type vertexJobSpec struct {
+	WorkerPoolSpecs     []vertexWorkerPool `json:"workerPoolSpecs"`
+	Scheduling          vertexScheduling   `json:"scheduling"`
+	BaseOutputDirectory *struct {
+		OutputURIPrefix string `json:"outputUriPrefix"`
+	} `json:"baseOutputDirectory,omitempty"`
+}
+
+type vertexWorkerPool struct {
+	MachineSpec struct {
+		MachineType      string `json:"machineType"`
+		AcceleratorType  string `json:"acceleratorType,omitempty"`
+		AcceleratorCount int    `json:"acceleratorCount,omitempty"`
+	} `json:"machineSpec"`
+	ReplicaCount  string `json:"replicaCount"` // int64 as a string
+	ContainerSpec struct {
+		ImageURI string      `json:"imageUri"`
+		Env      []vertexEnv `json:"env,omitempty"`
+	} `json:"containerSpec"`
+}
+
+type vertexEnv struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type vertexScheduling struct {
+	Timeout  string `json:"timeout"`            // Duration, e.g. "3600s"
+	Strategy string `json:"strategy,omitempty"` // SPOT for spot VMs
+}
+
+// SetVertexTraining enables Vertex AI Custom Training: jobs run image and
+// write to outputURI unless they set an output prefix. Without an image, no
+// managed entries are priced and the service is never chosen.
+func (c *Client) SetVertexTraining(image, outputURI string) {
+	c.vertexImage = image
+	c.vertexOutput = outputURI
+}
+
+// ManagedPricing prices the raw machine types Vertex AI trains on as
+// vertex.* entries
+func (c *Client) ManagedPricing(raw []models.GPUInstance) []models.GPUInstance {
+	if c.vertexImage == "" {
+		return nil
+	}
+	var managed []models.GPUInstance
+	for _, instance := range raw {
+		if _, ok := vertexAccelerators[instance.GPUType]; !ok || !vertexMachineType(instance.InstanceType) {
+			continue
+		}
+		instance.InstanceType = models.ManagedInstanceType(models.ProviderGCP, instance.InstanceType)
+		instance.PricePerHour *= vertexPriceFactor
+		instance.SpotPrice *= vertexPriceFactor
+		managed = append(managed, instance)
+	}
+	return managed
+}
+
+// vertexMachineType reports whether custom jobs run on a machine type
+func vertexMachineType(machineType string) bool {
+	for _, family := range vertexMachineFamilies {
+		if strings.HasPrefix(machineType, family) {
+			return true
+		}
+	}
+	return false
+}
+
+// SubmitTrainingJob creates a Vertex AI custom job and returns its resource name
+func (c *Client) SubmitTrainingJob(ctx context.Context, req providers.TrainingJobRequest) (string, error) {
+	job, err := c.vertexJob(req)
+	if err != nil {
+		return "", err
+	}
+	created, err := c.vertex.CreateCustomJob(ctx, c.projectID, req.Region, job)
+	if err != nil {
+		return "", fmt.Errorf("failed to create custom job %s: %w", req.Name, err)
+	}
+	return created.Name, nil
+}
+
+// vertexJob translates a training job request into a custom job. The first
+// worker pool holds the chief, the second the other replicas.
+func (c *Client) vertexJob(req providers.TrainingJobRequest) (*VertexCustomJob, error) {
+	if !models.IsManagedInstanceType(models.ProviderGCP, req.InstanceType) {
+		return nil, fmt.Errorf("vertex: %s is not a Vertex AI machine type", req.InstanceType)
+	}
+	accelerator, ok := vertexAccelerators[req.GPUType]
+	if !ok {
+		return nil, fmt.Errorf("vertex: no accelerator type for %s GPUs", req.GPUType)
+	}
+	image := req.Image
+	if image == "" {
+		image = c.vertexImage
+	}
+	if image == "" {
+		return nil, fmt.Errorf("vertex: no training image configured")
+	}
+
+	var env []vertexEnv
+	keys := make([]string, 0, len(req.Environment))
+	for key := range req.Environment {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		env = append(env, vertexEnv{Name: key, Value: req.Environment[key]})
+	}
+	if req.InputURI != "" {
+		env = append(env, vertexEnv{Name: "AIP_TRAINING_DATA_URI", Value: req.InputURI})
+	}
+
+	pool := func(replicas int) vertexWorkerPool {
+		var p vertexWorkerPool
+		p.MachineSpec.MachineType = models.RawInstanceType(models.ProviderGCP, req.InstanceType)
+		p.MachineSpec.AcceleratorType = accelerator
+		p.MachineSpec.AcceleratorCount = req.GPUsPerInstance
+		p.ReplicaCount = fmt.Sprint(replicas)
+		p.ContainerSpec.ImageURI = image
+		p.ContainerSpec.Env = env
+		return p
+	}
+	pools := []vertexWorkerPool{pool(1)}
+	if req.Count > 1 {
+		pools = append(pools, pool(req.Count-1))
+	}
+
+	runtime := req.MaxRuntime
+	if runtime <= 0 {
+		runtime = vertexDefaultRuntime
+	}
+	job := &VertexCustomJob{
+		DisplayName: req.Name,
+		JobSpec: vertexJobSpec{
+			WorkerPoolSpecs: pools,
+			Scheduling:      vertexScheduling{Timeout: fmt.Sprintf("%ds", int64(runtime.Seconds()))},
+		},
+	}
+	if req.Spot {
+		job.JobSpec.Scheduling.Strategy = "SPOT"
+	}
+	output := req.OutputURI
+	if output == "" {
+		output = c.vertexOutput
+	}
+	if output != "" {
+		if !strings.HasPrefix(output, "gs://") {
+			return nil, fmt.Errorf("vertex: output must be a gs:// prefix (data.output), got %q", output)
+		}
+		job.JobSpec.BaseOutputDirectory = &struct {
+			OutputURIPrefix string `json:"outputUriPrefix"`
+		}{output}
+	}
+	if len(req.Tags) > 0 {
+		job.Labels = make(map[string]string, len(req.Tags))
+		for key, value := range req.Tags {
+			job.Labels[vertexLabel(key)] = vertexLabel(value)
+		}
+	}
+	return job, nil
+}
+
+// vertexLabel lowercases s and replaces what labels may not contain
+func vertexLabel(s string) string {
+	s = vertexLabelInvalid.ReplaceAllString(strings.ToLower(s), "-")
+	if len(s) > 63 {
+		s = s[:63]
+	}
+	return s
+}
+
+// DescribeTrainingJob returns the status of a Vertex AI custom job
+func (c *Client) DescribeTrainingJob(ctx context.Context, region, name string) (*providers.TrainingJobStatus, error) {
+	job, err := c.vertex.GetCustomJob(ctx, region, name)
+	if err != nil {
+		return nil, err
+	}
+	return vertexStatus(job, time.Now()), nil
+}
+
+// vertexStatus maps a custom job to a status as of now. Vertex AI reports no
+// billable time: it bills from start to end, at the spot rate for spot jobs.
+func vertexStatus(job *VertexCustomJob, now time.Time) *providers.TrainingJobStatus {
+	status := &providers.TrainingJobStatus{
+		Detail:    strings.TrimPrefix(job.State, "JOB_STATE_"),
+		StartedAt: job.StartTime,
+		EndedAt:   job.EndTime,
+	}
+	switch job.State {
+	case "JOB_STATE_SUCCEEDED":
+		status.State = models.ManagedRunCompleted
+	case "JOB_STATE_FAILED", "JOB_STATE_EXPIRED":
+		status.State = models.ManagedRunFailed
+		if job.Error != nil {
+			status.Detail = job.Error.Message
+		}
+	case "JOB_STATE_CANCELLED":
+		status.State = models.ManagedRunStopped
+	case "JOB_STATE_CANCELLING":
+		status.State = models.ManagedRunStopping
+	case "JOB_STATE_RUNNING":
+		status.State = models.ManagedRunRunning
+	default:
+		status.State = models.ManagedRunPending
+	}
+
+	if status.StartedAt != nil {
+		end := now
+		if status.EndedAt != nil {
+			end = *status.EndedAt
+		}
+		status.BillableSeconds = int64(end.Sub(*status.StartedAt).Seconds())
+	}
+	return status
+}
+
+// StopTrainingJob cancels a Vertex AI custom job that has not ended
+func (c *Client) StopTrainingJob(ctx context.Context, region, name string) error {
+	job, err := c.vertex.GetCustomJob(ctx, region, name)
+	if errors.Is(err, errNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	switch job.State {
+	case "JOB_STATE_SUCCEEDED", "JOB_STATE_FAILED", "JOB_STATE_EXPIRED", "JOB_STATE_CANCELLED", "JOB_STATE_CANCELLING":
+		return nil
+	}
+	return c.vertex.CancelCustomJob(ctx, region, name)
+}
+
+// vertexClient calls the Vertex AI REST API directly
+type vertexClient struct {
+	rest *iamClient // Authenticated JSON requests
+}
+
+// newVertexClient creates a Vertex AI client.
+// If accessToken is empty, tokens are fetched from the GCE metadata server.
+func newVertexClient(accessToken string) *vertexClient {
+	return &vertexClient{rest: newIAMClient(accessToken)}
+}
+
+// vertexEndpoint is the regional API endpoint
+func vertexEndpoint(region string) string {
+	return fmt.Sprintf("https://%s-aiplatform.googleapis.com/v1/", region)
+}
+
+// CreateCustomJob creates a custom job in the project's region
+func (c *vertexClient) CreateCustomJob(ctx context.Context, project, region string, job *VertexCustomJob) (*VertexCustomJob, error) {
+	var created VertexCustomJob
+	path := vertexEndpoint(region) + fmt.Sprintf("projects/%s/locations/%s/customJobs", url.PathEscape(project), url.PathEscape(region))
+	if err := c.rest.doJSON(ctx, http.MethodPost, path, job, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// GetCustomJob reads a custom job by resource name
+func (c *vertexClient) GetCustomJob(ctx context.Context, region, name string) (*VertexCustomJob, error) {
+	var job VertexCustomJob
+	if err := c.rest.doJSON(ctx, http.MethodGet, vertexEndpoint(region)+name, nil, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// CancelCustomJob requests cancellation of a custom job
+func (c *vertexClient) CancelCustomJob(ctx context.Context, region, name string) error {
+	return c.rest.doJSON(ctx, http.MethodPost, vertexEndpoint(region)+name+":cancel", struct{}{}, nil)
+}
@@ -0,0 +1,252 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"gpu-orchestrator/core/models"
+)
+
+// Provider is the contract every compute provider client implements.
+// PricingFetcher and Provisioner only talk to providers through this interface,
+// so adding a new cloud is a matter of implementing it and registering the client.
+type Provider interface {
+	// Name returns the provider identifier used across the platform
+	Name() models.Provider
+	// Regions returns the regions this client is configured for
+	Regions() []string
+	// FetchOnDemandPricing returns on-demand pricing for all GPU instance types
+	FetchOnDemandPricing(ctx context.Context) ([]models.GPUInstance, error)
+	// FetchSpotPricing returns spot/preemptible pricing (SpotPrice and Availability set)
+	FetchSpotPricing(ctx context.Context) ([]models.GPUInstance, error)
+	// ProvisionInstances launches instances and returns their provider-specific IDs
+	ProvisionInstances(ctx context.Context, req InstanceRequest) ([]string, error)
+	// TerminateInstances terminates the given instances
+	TerminateInstances(ctx context.Context, region string, instanceIDs []string) error
+	// DescribeInstances returns the current state of the given instances
+	DescribeInstances(ctx context.Context, region string, instanceIDs []string) ([]InstanceInfo, error)
+}
+
+// Stopper is implemented by providers that can stop instances while keeping their
+// disks and restart them later (used to hibernate idle clusters between jobs)
+type Stopper interface {
+	// StopInstances stops (does not terminate) the given instances
+	StopInstances(ctx context.Context, region string, instanceIDs []string) error
+	// StartInstances restarts stopped instances
+	StartInstances(ctx context.Context, region string, instanceIDs []string) error
+}
+
+// QuotaReporter is implemented by providers that can report account node quotas
+type QuotaReporter interface {
+	// NodeQuotas returns the maximum nodes per cluster the account can launch
+	NodeQuotas(ctx context.Context) ([]NodeQuota, error)
+}
+
+// IdentityProvisioner is implemented by providers that attach a cloud identity
+// to instances, so training scripts get storage credentials without long-lived keys
+type IdentityProvisioner interface {
+	// DefaultIdentity returns the identity instances get when a job asks for none
+	DefaultIdentity() models.JobIdentity
+	// CreateJobIdentity creates an identity limited to the grants' URIs
+	CreateJobIdentity(ctx context.Context, jobID string, grants []models.StorageGrant) (models.JobIdentity, error)
+	// DeleteJobIdentity deletes an identity created by CreateJobIdentity.
+	// Deleting one that no longer exists is not an error.
+	DeleteJobIdentity(ctx context.Context, identity models.JobIdentity) error
+}
+
+// MaintenanceReporter is implemented by providers that publish scheduled
+// host maintenance (reboots, retirements) for instances
+type MaintenanceReporter interface {
+	// ScheduledEvents returns the upcoming maintenance events of the given
+	// instances. Completed and cancelled events are left out.
+	ScheduledEvents(ctx context.Context, region string, instanceIDs []string) ([]MaintenanceEvent, error)
+}
+
+// MaintenanceEvent is provider-scheduled maintenance of one instance
+type MaintenanceEvent struct {
+	InstanceID  string
+	EventType   string // Provider event code, e.g. system-reboot (AWS) or Redeploy (Azure)
+	Description string
+	NotBefore   time.Time  // Earliest start of the maintenance
+	NotAfter    *time.Time // Latest end, when the provider gives one
+}
+
+// RebalanceReporter is implemented by providers that warn when spot
+// instances are at elevated risk of interruption, e.g. EC2 rebalance
+// recommendations, which usually arrive minutes before the interruption notice
+type RebalanceReporter interface {
+	// RebalanceRecommendations returns the recommendations outstanding for
+	// the given instances
+	RebalanceRecommendations(ctx context.Context, region string, instanceIDs []string) ([]RebalanceRecommendation, error)
+}
+
+// RebalanceRecommendation is a provider warning that one spot instance is at
+// elevated risk of interruption
+type RebalanceRecommendation struct {
+	InstanceID string
+	NoticedAt  time.Time // When the provider raised the recommendation
+}
+
+// ZoneReporter is implemented by providers whose regions have availability
+// zones the provisioner can choose between
+type ZoneReporter interface {
+	// ZoneOffers returns the zones of a region that offer an instance type
+	ZoneOffers(ctx context.Context, region, instanceType string) ([]ZoneOffer, error)
+}
+
+// ZoneOffer is an instance type's availability in one zone
+type ZoneOffer struct {
+	Zone           string
+	SpotPrice      float64 // Current spot price per instance-hour; 0 = unknown
+	PlacementScore int     // Likelihood a spot request succeeds, 1-10; 0 = unknown
+}
+
+// InstanceLister is implemented by providers that can list every instance
+// the orchestrator launched in a region, tracked or not
+type InstanceLister interface {
+	// ListManagedInstances returns the region's non-terminated instances
+	// tagged ManagedBy=gpu-orchestrator
+	ListManagedInstances(ctx context.Context, region string) ([]InstanceInfo, error)
+}
+
+// ManagedTrainer is implemented by providers with a managed training service
+// (SageMaker Training, Vertex AI Custom Training) that provisions a job's
+// instances, runs its container and tears them down itself
+type ManagedTrainer interface {
+	// ManagedPricing returns the service's catalog entries for raw prices
+	// from FetchOnDemandPricing or FetchSpotPricing: the instance types the
+	// service offers, named with models.ManagedInstanceType and priced at the
+	// service's rates
+	ManagedPricing(raw []models.GPUInstance) []models.GPUInstance
+	// SubmitTrainingJob starts a training job and returns its service ID
+	SubmitTrainingJob(ctx context.Context, req TrainingJobRequest) (string, error)
+	// DescribeTrainingJob returns the current status of a training job
+	DescribeTrainingJob(ctx context.Context, region, serviceJobID string) (*TrainingJobStatus, error)
+	// StopTrainingJob stops a training job. Stopping one that already
+	// ended is not an error.
+	StopTrainingJob(ctx context.Context, region, serviceJobID string) error
+}
+
+// TrainingJobRequest describes a job for a managed training service
+type TrainingJobRequest struct {
+	Name            string // Service job name; unique per job and attempt
+	Region          string
+	InstanceType    string // Managed catalog entry, e.g. ml.p4d.24xlarge or vertex.a2-highgpu-8g
+	GPUType         string
+	GPUsPerInstance int
+	Count           int
+	Spot            bool
+	MaxRuntime      time.Duration     // Training is stopped after this long
+	MaxWait         time.Duration     // Spot: runtime plus time waiting for capacity; 0 = twice MaxRuntime
+	Image           string            // Training container; empty = the client's configured image
+	InputURI        string            // Dataset, mounted as the "training" input channel; empty = none
+	OutputURI       string            // Prefix the service writes model artifacts to; empty = the client's default
+	Environment     map[string]string // Passed to the training container
+	Tags            map[string]string
+}
+
+// TrainingJobStatus is a managed training job as reported by the service
+type TrainingJobStatus struct {
+	State     string // models.ManagedRun* state
+	Detail    string // Service's secondary status or failure reason
+	StartedAt *time.Time
+	EndedAt   *time.Time
+	// Seconds billed per instance; while running, the elapsed training time
+	BillableSeconds int64
+	// Spot savings are already taken off BillableSeconds (SageMaker managed
+	// spot), so they are billed at the on-demand rate
+	SpotDiscounted bool
+}
+
+// ErrUnsupported is returned by metered clients for optional calls the
+// wrapped client does not implement
+var ErrUnsupported = errors.New("not supported by provider")
+
+// NodeQuota is an account limit on nodes; empty Region/InstanceFamily apply to all
+type NodeQuota struct {
+	Region         string
+	InstanceFamily string
+	MaxNodes       int
+}
+
+// InstanceRequest describes a batch of identical instances to provision
+type InstanceRequest struct {
+	InstanceType    string
+	Region          string
+	Zone            string // Availability zone of every instance; empty = provider's choice
+	Spot            bool
+	Count           int
+	BootstrapScript string      // Appended to the instance's boot script (e.g. network drivers)
+	Identity        string      // Instance profile name/ARN (AWS) or service account (GCP); empty = provider default
+	DataVolume      *DataVolume // Extra volume attached to each instance; nil = none
+	SpotMaxPrice    float64     // Spot bid per instance-hour where the provider takes one; 0 = no max price
+	ImageID         string      // Registered pre-baked image to boot; empty = the provider's generic GPU image
+}
+
+// DataVolume is a data disk attached at launch and deleted with its instance.
+// Providers pick their volume type (gp3 on AWS, pd-ssd on GCP, Premium SSD on Azure).
+type DataVolume struct {
+	SizeGB         int
+	IOPS           int // Provisioned IOPS where the volume type supports it; 0 = baseline
+	ThroughputMBps int // Provisioned throughput where supported; 0 = baseline
+}
+
+// InstanceState represents the lifecycle state of a provider instance
+type InstanceState string
+
+const (
+	InstanceStatePending    InstanceState = "pending"
+	InstanceStateRunning    InstanceState = "running"
+	InstanceStateStopped    InstanceState = "stopped"
+	InstanceStateTerminated InstanceState = "terminated"
+	InstanceStateUnknown    InstanceState = "unknown"
+)
+
+// InstanceInfo describes a provisioned instance as reported by the provider
+type InstanceInfo struct {
+	InstanceID   string
+	InstanceType string
+	Region       string
+	Zone         string
+	State        InstanceState
+	PrivateIP    string
+	LaunchedAt   *time.Time
+}
+
+// Registry maps provider identifiers to their clients
+type Registry map[models.Provider]Provider
+
+// NewRegistry creates a registry from the given provider clients
+func NewRegistry(clients ...Provider) Registry {
+	r := make(Registry)
+	for _, c := range clients {
+		r.Register(c)
+	}
+	return r
+}
+
+// Register adds (or replaces) a provider client
+func (r Registry) Register(p Provider) {
+	if p == nil {
+		return
+	}
+	r[p.Name()] = p
+}
+
+// Get returns the client for a provider
+func (r Registry) Get(name models.Provider) (Provider, bool) {
+	p, ok := r[name]
+	return p, ok
+}
+
+// Names returns the registered provider identifiers in a stable order
+func (r Registry) Names() []models.Provider {
+	names := make([]models.Provider, 0, len(r))
+	for name := range r {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
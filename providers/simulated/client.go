@@ -0,0 +1,266 @@
+package simulated
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/providers"
+)
+
+// Ensure Client satisfies the provider interfaces at compile time
+var (
+	_ providers.Provider     = (*Client)(nil)
+	_ providers.Stopper      = (*Client)(nil)
+	_ providers.ZoneReporter = (*Client)(nil)
+
+	_ providers.RebalanceReporter = (*Client)(nil)
+	_ providers.InstanceLister    = (*Client)(nil)
+)
+
+// simulatedZones are the zone suffixes of every simulated region with their
+// spot price relative to the catalog and their placement score
+var simulatedZones = []struct {
+	suffix     string
+	priceRatio float64
+	score      int
+}{
+	{"a", 1.00, 7},
+	{"b", 0.90, 5},
+	{"c", 1.10, 9},
+}
+
+// Client is an in-memory provider used for local development and simulation.
+// It serves a static catalog and "provisions" instances without touching any cloud API.
+type Client struct {
+	name      models.Provider
+	regions   []string
+	catalog   []models.GPUInstance
+	instances map[string]providers.InstanceInfo
+	nextID    int
+	mu        sync.Mutex
+
+	rebalance map[string]time.Time // Raised rebalance recommendations by instance; see RecommendRebalance
+}
+
+// NewClient creates a simulated provider that reports itself as the given provider.
+// The catalog is replicated across all regions (Region on catalog entries is ignored).
+func NewClient(name models.Provider, regions []string, catalog []models.GPUInstance) *Client {
+	return &Client{
+		name:      name,
+		regions:   regions,
+		catalog:   catalog,
+		instances: make(map[string]providers.InstanceInfo),
+		rebalance: make(map[string]time.Time),
+	}
+}
+
+// Name returns the provider identifier
+func (c *Client) Name() models.Provider {
+	return c.name
+}
+
+// Regions returns the simulated regions
+func (c *Client) Regions() []string {
+	return c.regions
+}
+
+// FetchOnDemandPricing returns the static catalog for every region
+func (c *Client) FetchOnDemandPricing(_ context.Context) ([]models.GPUInstance, error) {
+	var instances []models.GPUInstance
+	for _, region := range c.regions {
+		for _, entry := range c.catalog {
+			instance := entry
+			instance.Provider = c.name
+			instance.Region = region
+			instances = append(instances, instance)
+		}
+	}
+	return instances, nil
+}
+
+// FetchSpotPricing returns the static catalog; entries without a SpotPrice get a 70% discount
+func (c *Client) FetchSpotPricing(ctx context.Context) ([]models.GPUInstance, error) {
+	instances, _ := c.FetchOnDemandPricing(ctx)
+	for i := range instances {
+		if instances[i].SpotPrice == 0 {
+			instances[i].SpotPrice = instances[i].PricePerHour * 0.3
+		}
+		if instances[i].Availability == 0 {
+			instances[i].Availability = 0.8
+		}
+	}
+	return instances, nil
+}
+
+// ProvisionInstances records simulated instances and returns their IDs
+func (c *Client) ProvisionInstances(_ context.Context, req providers.InstanceRequest) ([]string, error) {
+	if req.Count <= 0 {
+		return nil, fmt.Errorf("instance count must be positive, got %d", req.Count)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	zone := req.Zone
+	if zone == "" {
+		zone = req.Region + simulatedZones[0].suffix
+	}
+
+	now := time.Now()
+	ids := make([]string, 0, req.Count)
+	for i := 0; i < req.Count; i++ {
+		c.nextID++
+		id := fmt.Sprintf("sim-%s-%d", c.name, c.nextID)
+		c.instances[id] = providers.InstanceInfo{
+			InstanceID:   id,
+			InstanceType: req.InstanceType,
+			Region:       req.Region,
+			Zone:         zone,
+			State:        providers.InstanceStateRunning,
+			PrivateIP:    fmt.Sprintf("10.0.%d.%d", c.nextID/250, c.nextID%250+10),
+			LaunchedAt:   &now,
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// ZoneOffers returns the simulated zones of a region with per-zone spot prices
+func (c *Client) ZoneOffers(ctx context.Context, region, instanceType string) ([]providers.ZoneOffer, error) {
+	instances, _ := c.FetchSpotPricing(ctx)
+	for _, instance := range instances {
+		if instance.Region != region || instance.InstanceType != instanceType {
+			continue
+		}
+		offers := make([]providers.ZoneOffer, len(simulatedZones))
+		for i, zone := range simulatedZones {
+			offers[i] = providers.ZoneOffer{
+				Zone:           region + zone.suffix,
+				SpotPrice:      instance.SpotPrice * zone.priceRatio,
+				PlacementScore: zone.score,
+			}
+		}
+		return offers, nil
+	}
+	return nil, fmt.Errorf("instance type %s not offered in %s", instanceType, region)
+}
+
+// TerminateInstances marks simulated instances as terminated
+func (c *Client) TerminateInstances(_ context.Context, _ string, instanceIDs []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, id := range instanceIDs {
+		info, ok := c.instances[id]
+		if !ok {
+			return fmt.Errorf("instance %s not found", id)
+		}
+		info.State = providers.InstanceStateTerminated
+		c.instances[id] = info
+	}
+
+	return nil
+}
+
+// ListManagedInstances returns the region's simulated instances that are
+// not terminated
+func (c *Client) ListManagedInstances(_ context.Context, region string) ([]providers.InstanceInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var infos []providers.InstanceInfo
+	for _, info := range c.instances {
+		if info.Region == region && info.State != providers.InstanceStateTerminated {
+			infos = append(infos, info)
+		}
+	}
+	return infos, nil
+}
+
+// StopInstances marks running simulated instances as stopped
+func (c *Client) StopInstances(_ context.Context, _ string, instanceIDs []string) error {
+	return c.transition(instanceIDs, providers.InstanceStateRunning, providers.InstanceStateStopped)
+}
+
+// StartInstances marks stopped simulated instances as running
+func (c *Client) StartInstances(_ context.Context, _ string, instanceIDs []string) error {
+	return c.transition(instanceIDs, providers.InstanceStateStopped, providers.InstanceStateRunning)
+}
+
+// transition moves instances from one state to another
+func (c *Client) transition(instanceIDs []string, from, to providers.InstanceState) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, id := range instanceIDs {
+		info, ok := c.instances[id]
+		if !ok {
+			return fmt.Errorf("instance %s not found", id)
+		}
+		if info.State != from {
+			return fmt.Errorf("instance %s is %s, expected %s", id, info.State, from)
+		}
+	}
+	for _, id := range instanceIDs {
+		info := c.instances[id]
+		info.State = to
+		c.instances[id] = info
+	}
+
+	return nil
+}
+
+// DescribeInstances returns the recorded state of simulated instances
+func (c *Client) DescribeInstances(_ context.Context, _ string, instanceIDs []string) ([]providers.InstanceInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	infos := make([]providers.InstanceInfo, 0, len(instanceIDs))
+	for _, id := range instanceIDs {
+		if info, ok := c.instances[id]; ok {
+			infos = append(infos, info)
+		}
+	}
+
+	return infos, nil
+}
+
+// RecommendRebalance raises a rebalance recommendation for a running
+// simulated instance, as EC2 does ahead of a spot interruption
+func (c *Client) RecommendRebalance(instanceID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	info, ok := c.instances[instanceID]
+	if !ok {
+		return fmt.Errorf("instance %s not found", instanceID)
+	}
+	if info.State != providers.InstanceStateRunning {
+		return fmt.Errorf("instance %s is %s, expected %s", instanceID, info.State, providers.InstanceStateRunning)
+	}
+	if _, raised := c.rebalance[instanceID]; !raised {
+		c.rebalance[instanceID] = time.Now()
+	}
+	return nil
+}
+
+// RebalanceRecommendations returns the recommendations raised for running
+// simulated instances
+func (c *Client) RebalanceRecommendations(_ context.Context, _ string, instanceIDs []string) ([]providers.RebalanceRecommendation, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var recommendations []providers.RebalanceRecommendation
+	for _, id := range instanceIDs {
+		noticed, ok := c.rebalance[id]
+		if !ok || c.instances[id].State != providers.InstanceStateRunning {
+			continue
+		}
+		recommendations = append(recommendations, providers.RebalanceRecommendation{InstanceID: id, NoticedAt: noticed})
+	}
+	return recommendations, nil
+}
@@ -0,0 +1,501 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"gpu-orchestrator/core/models"
+)
+
+// ErrCallBudgetExceeded is returned to non-critical callers when a provider
+// has used its hourly call budget
+var ErrCallBudgetExceeded = errors.New("provider API call budget exceeded")
+
+// nonCriticalKey marks contexts of deferrable provider calls
+type nonCriticalKey struct{}
+
+// NonCritical marks provider calls made with ctx as deferrable: they are
+// refused with ErrCallBudgetExceeded once the provider is over its hourly
+// budget. Unmarked calls (provisioning, termination) always go through.
+func NonCritical(ctx context.Context) context.Context {
+	return context.WithValue(ctx, nonCriticalKey{}, true)
+}
+
+// isNonCritical reports whether ctx was marked by NonCritical
+func isNonCritical(ctx context.Context) bool {
+	nonCritical, _ := ctx.Value(nonCriticalKey{}).(bool)
+	return nonCritical
+}
+
+// usageKey identifies the counters of one provider method on one day
+type usageKey struct {
+	day      string
+	provider models.Provider
+	method   string
+}
+
+// budgetWindow counts a provider's calls in the current clock hour
+type budgetWindow struct {
+	hour  time.Time
+	calls int
+}
+
+// UsageMeter counts provider API calls, errors and latency per method and
+// enforces hourly call budgets. Providers are metered by wrapping them with
+// Instrument.
+type UsageMeter struct {
+	mu      sync.Mutex
+	budgets map[models.Provider]int
+	totals  map[usageKey]*models.ProviderAPIUsage // Since start, for metrics
+	pending map[usageKey]*models.ProviderAPIUsage // Per day, since the last Drain
+	windows map[models.Provider]*budgetWindow
+	now     func() time.Time
+}
+
+// NewUsageMeter creates a meter with hourly call budgets per provider.
+// Providers without a positive budget are unlimited.
+func NewUsageMeter(budgets map[string]int) *UsageMeter {
+	m := &UsageMeter{
+		budgets: make(map[models.Provider]int),
+		totals:  make(map[usageKey]*models.ProviderAPIUsage),
+		pending: make(map[usageKey]*models.ProviderAPIUsage),
+		windows: make(map[models.Provider]*budgetWindow),
+		now:     time.Now,
+	}
+	for provider, budget := range budgets {
+		if budget > 0 {
+			m.budgets[models.Provider(provider)] = budget
+		}
+	}
+	return m
+}
+
+// admit counts a call against the provider's hourly budget. Non-critical
+// calls over budget are refused and counted as throttled instead.
+func (m *UsageMeter) admit(ctx context.Context, provider models.Provider, method string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	window := m.window(provider)
+	if budget := m.budgets[provider]; budget > 0 && window.calls >= budget && isNonCritical(ctx) {
+		m.counters(provider, method, func(u *models.ProviderAPIUsage) { u.Throttled++ })
+		return fmt.Errorf("%w: %s used %d calls this hour", ErrCallBudgetExceeded, provider, window.calls)
+	}
+	window.calls++
+	return nil
+}
+
+// record counts a finished call
+func (m *UsageMeter) record(provider models.Provider, method string, latency time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.counters(provider, method, func(u *models.ProviderAPIUsage) {
+		u.Calls++
+		if err != nil {
+			u.Errors++
+		}
+		u.LatencyMs += float64(latency) / float64(time.Millisecond)
+	})
+}
+
+// counters applies update to the running total and today's pending rollup.
+// Callers hold m.mu.
+func (m *UsageMeter) counters(provider models.Provider, method string, update func(*models.ProviderAPIUsage)) {
+	day := m.now().UTC().Format("2006-01-02")
+	for _, key := range []usageKey{{provider: provider, method: method}, {day: day, provider: provider, method: method}} {
+		counters := m.totals
+		if key.day != "" {
+			counters = m.pending
+		}
+		usage, ok := counters[key]
+		if !ok {
+			usage = &models.ProviderAPIUsage{Day: key.day, Provider: provider, Method: method}
+			counters[key] = usage
+		}
+		update(usage)
+	}
+}
+
+// window returns the provider's budget window, starting a new one each
+// clock hour. Callers hold m.mu.
+func (m *UsageMeter) window(provider models.Provider) *budgetWindow {
+	hour := m.now().Truncate(time.Hour)
+	window, ok := m.windows[provider]
+	if !ok || !window.hour.Equal(hour) {
+		window = &budgetWindow{hour: hour}
+		m.windows[provider] = window
+	}
+	return window
+}
+
+// Usage returns the running totals since start, by provider and method
+func (m *UsageMeter) Usage() []models.ProviderAPIUsage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return sortedUsage(m.totals)
+}
+
+// Drain returns the per-day counts since the last Drain and resets them.
+// Counts that could not be stored are handed back with Restore.
+func (m *UsageMeter) Drain() []models.ProviderAPIUsage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	usage := sortedUsage(m.pending)
+	m.pending = make(map[usageKey]*models.ProviderAPIUsage)
+	return usage
+}
+
+// Restore adds drained counts back so the next Drain includes them
+func (m *UsageMeter) Restore(usage []models.ProviderAPIUsage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, u := range usage {
+		key := usageKey{day: u.Day, provider: u.Provider, method: u.Method}
+		pending, ok := m.pending[key]
+		if !ok {
+			pending = &models.ProviderAPIUsage{Day: u.Day, Provider: u.Provider, Method: u.Method}
+			m.pending[key] = pending
+		}
+		pending.Calls += u.Calls
+		pending.Errors += u.Errors
+		pending.Throttled += u.Throttled
+		pending.LatencyMs += u.LatencyMs
+	}
+}
+
+// Budgets returns every metered or budgeted provider's hourly budget and
+// its calls this hour
+func (m *UsageMeter) Budgets() []models.ProviderCallBudget {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make(map[models.Provider]bool)
+	for provider := range m.budgets {
+		names[provider] = true
+	}
+	for key := range m.totals {
+		names[key.provider] = true
+	}
+
+	budgets := make([]models.ProviderCallBudget, 0, len(names))
+	for provider := range names {
+		budget := models.ProviderCallBudget{
+			Provider:  provider,
+			PerHour:   m.budgets[provider],
+			HourCalls: m.window(provider).calls,
+		}
+		budget.Throttled = budget.PerHour > 0 && budget.HourCalls >= budget.PerHour
+		budgets = append(budgets, budget)
+	}
+	sort.Slice(budgets, func(i, j int) bool { return budgets[i].Provider < budgets[j].Provider })
+	return budgets
+}
+
+// PrometheusMetrics exports call counters and budget use
+func (m *UsageMeter) PrometheusMetrics() string {
+	usage := m.Usage()
+	var metrics string
+
+	metrics += "# HELP gpu_provider_api_calls_total Provider API calls by method\n"
+	metrics += "# TYPE gpu_provider_api_calls_total counter\n"
+	for _, u := range usage {
+		metrics += fmt.Sprintf("gpu_provider_api_calls_total{provider=\"%s\",method=\"%s\"} %d\n", u.Provider, u.Method, u.Calls)
+	}
+
+	metrics += "# HELP gpu_provider_api_errors_total Provider API calls that returned an error\n"
+	metrics += "# TYPE gpu_provider_api_errors_total counter\n"
+	for _, u := range usage {
+		metrics += fmt.Sprintf("gpu_provider_api_errors_total{provider=\"%s\",method=\"%s\"} %d\n", u.Provider, u.Method, u.Errors)
+	}
+
+	metrics += "# HELP gpu_provider_api_throttled_total Non-critical provider API calls refused over the hourly budget\n"
+	metrics += "# TYPE gpu_provider_api_throttled_total counter\n"
+	for _, u := range usage {
+		metrics += fmt.Sprintf("gpu_provider_api_throttled_total{provider=\"%s\",method=\"%s\"} %d\n", u.Provider, u.Method, u.Throttled)
+	}
+
+	metrics += "# HELP gpu_provider_api_latency_seconds_sum Total latency of provider API calls\n"
+	metrics += "# TYPE gpu_provider_api_latency_seconds_sum counter\n"
+	for _, u := range usage {
+		metrics += fmt.Sprintf("gpu_provider_api_latency_seconds_sum{provider=\"%s\",method=\"%s\"} %.3f\n", u.Provider, u.Method, u.LatencyMs/1000)
+	}
+
+	budgets := m.Budgets()
+	metrics += "# HELP gpu_provider_api_hour_calls Provider API calls in the current clock hour\n"
+	metrics += "# TYPE gpu_provider_api_hour_calls gauge\n"
+	for _, budget := range budgets {
+		metrics += fmt.Sprintf("gpu_provider_api_hour_calls{provider=\"%s\"} %d\n", budget.Provider, budget.HourCalls)
+	}
+
+	metrics += "# HELP gpu_provider_api_hour_budget Hourly call budget of a provider (0 = unlimited)\n"
+	metrics += "# TYPE gpu_provider_api_hour_budget gauge\n"
+	for _, budget := range budgets {
+		metrics += fmt.Sprintf("gpu_provider_api_hour_budget{provider=\"%s\"} %d\n", budget.Provider, budget.PerHour)
+	}
+
+	return metrics
+}
+
+// sortedUsage copies counters ordered by day, provider and method
+func sortedUsage(counters map[usageKey]*models.ProviderAPIUsage) []models.ProviderAPIUsage {
+	usage := make([]models.ProviderAPIUsage, 0, len(counters))
+	for _, u := range counters {
+		usage = append(usage, *u)
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Day != usage[j].Day {
+			return usage[i].Day < usage[j].Day
+		}
+		if usage[i].Provider != usage[j].Provider {
+			return usage[i].Provider < usage[j].Provider
+		}
+		return usage[i].Method < usage[j].Method
+	})
+	return usage
+}
+
+// Instrument wraps every registered client so its calls are metered
+func (r Registry) Instrument(meter *UsageMeter) {
+	for name, client := range r {
+		r[name] = meter.Instrument(client)
+	}
+}
+
+// Instrument wraps a provider client so its calls are metered. The wrapper
+// implements the same optional interfaces (Stopper, QuotaReporter,
+// IdentityProvisioner) as the client.
+func (m *UsageMeter) Instrument(client Provider) Provider {
+	base := &meteredProvider{client: client, meter: m}
+	stopper, isStopper := client.(Stopper)
+	reporter, isReporter := client.(QuotaReporter)
+	identities, isIdentities := client.(IdentityProvisioner)
+	s := meteredStopper{base, stopper}
+	q := meteredQuotaReporter{base, reporter}
+	i := meteredIdentityProvisioner{base, identities}
+
+	switch {
+	case isStopper && isReporter && isIdentities:
+		return &struct {
+			*meteredProvider
+			meteredStopper
+			meteredQuotaReporter
+			meteredIdentityProvisioner
+		}{base, s, q, i}
+	case isStopper && isReporter:
+		return &struct {
+			*meteredProvider
+			meteredStopper
+			meteredQuotaReporter
+		}{base, s, q}
+	case isStopper && isIdentities:
+		return &struct {
+			*meteredProvider
+			meteredStopper
+			meteredIdentityProvisioner
+		}{base, s, i}
+	case isReporter && isIdentities:
+		return &struct {
+			*meteredProvider
+			meteredQuotaReporter
+			meteredIdentityProvisioner
+		}{base, q, i}
+	case isStopper:
+		return &struct {
+			*meteredProvider
+			meteredStopper
+		}{base, s}
+	case isReporter:
+		return &struct {
+			*meteredProvider
+			meteredQuotaReporter
+		}{base, q}
+	case isIdentities:
+		return &struct {
+			*meteredProvider
+			meteredIdentityProvisioner
+		}{base, i}
+	}
+	return base
+}
+
+// meteredProvider meters the calls of a provider client
+type meteredProvider struct {
+	client Provider
+	meter  *UsageMeter
+}
+
+// call runs one metered provider API call
+func (p *meteredProvider) call(ctx context.Context, method string, fn func() error) error {
+	provider := p.client.Name()
+	if err := p.meter.admit(ctx, provider, method); err != nil {
+		return err
+	}
+	started := p.meter.now()
+	err := fn()
+	p.meter.record(provider, method, p.meter.now().Sub(started), err)
+	return err
+}
+
+func (p *meteredProvider) Name() models.Provider { return p.client.Name() }
+func (p *meteredProvider) Regions() []string     { return p.client.Regions() }
+
+func (p *meteredProvider) FetchOnDemandPricing(ctx context.Context) (instances []models.GPUInstance, err error) {
+	err = p.call(ctx, "FetchOnDemandPricing", func() error {
+		instances, err = p.client.FetchOnDemandPricing(ctx)
+		return err
+	})
+	return instances, err
+}
+
+func (p *meteredProvider) FetchSpotPricing(ctx context.Context) (instances []models.GPUInstance, err error) {
+	err = p.call(ctx, "FetchSpotPricing", func() error {
+		instances, err = p.client.FetchSpotPricing(ctx)
+		return err
+	})
+	return instances, err
+}
+
+func (p *meteredProvider) ProvisionInstances(ctx context.Context, req InstanceRequest) (ids []string, err error) {
+	err = p.call(ctx, "ProvisionInstances", func() error {
+		ids, err = p.client.ProvisionInstances(ctx, req)
+		return err
+	})
+	return ids, err
+}
+
+func (p *meteredProvider) TerminateInstances(ctx context.Context, region string, instanceIDs []string) error {
+	return p.call(ctx, "TerminateInstances", func() error {
+		return p.client.TerminateInstances(ctx, region, instanceIDs)
+	})
+}
+
+func (p *meteredProvider) DescribeInstances(ctx context.Context, region string, instanceIDs []string) (infos []InstanceInfo, err error) {
+	err = p.call(ctx, "DescribeInstances", func() error {
+		infos, err = p.client.DescribeInstances(ctx, region, instanceIDs)
+		return err
+	})
+	return infos, err
+}
+
+// ListManagedInstances meters the client's InstanceLister call; it returns
+// ErrUnsupported when the client cannot list its instances
+func (p *meteredProvider) ListManagedInstances(ctx context.Context, region string) (infos []InstanceInfo, err error) {
+	lister, ok := p.client.(InstanceLister)
+	if !ok {
+		return nil, ErrUnsupported
+	}
+	err = p.call(ctx, "ListManagedInstances", func() error {
+		infos, err = lister.ListManagedInstances(ctx, region)
+		return err
+	})
+	return infos, err
+}
+
+// ManagedPricing returns the client's managed training entries; none when
+// the client has no managed training service
+func (p *meteredProvider) ManagedPricing(raw []models.GPUInstance) []models.GPUInstance {
+	trainer, ok := p.client.(ManagedTrainer)
+	if !ok {
+		return nil
+	}
+	return trainer.ManagedPricing(raw)
+}
+
+// SubmitTrainingJob meters the client's ManagedTrainer call; it returns
+// ErrUnsupported when the client has no managed training service
+func (p *meteredProvider) SubmitTrainingJob(ctx context.Context, req TrainingJobRequest) (id string, err error) {
+	trainer, ok := p.client.(ManagedTrainer)
+	if !ok {
+		return "", ErrUnsupported
+	}
+	err = p.call(ctx, "SubmitTrainingJob", func() error {
+		id, err = trainer.SubmitTrainingJob(ctx, req)
+		return err
+	})
+	return id, err
+}
+
+// DescribeTrainingJob meters the client's ManagedTrainer call
+func (p *meteredProvider) DescribeTrainingJob(ctx context.Context, region, serviceJobID string) (status *TrainingJobStatus, err error) {
+	trainer, ok := p.client.(ManagedTrainer)
+	if !ok {
+		return nil, ErrUnsupported
+	}
+	err = p.call(ctx, "DescribeTrainingJob", func() error {
+		status, err = trainer.DescribeTrainingJob(ctx, region, serviceJobID)
+		return err
+	})
+	return status, err
+}
+
+// StopTrainingJob meters the client's ManagedTrainer call
+func (p *meteredProvider) StopTrainingJob(ctx context.Context, region, serviceJobID string) error {
+	trainer, ok := p.client.(ManagedTrainer)
+	if !ok {
+		return ErrUnsupported
+	}
+	return p.call(ctx, "StopTrainingJob", func() error {
+		return trainer.StopTrainingJob(ctx, region, serviceJobID)
+	})
+}
+
+// meteredStopper meters a client's Stopper calls
+type meteredStopper struct {
+	p       *meteredProvider
+	stopper Stopper
+}
+
+func (s meteredStopper) StopInstances(ctx context.Context, region string, instanceIDs []string) error {
+	return s.p.call(ctx, "StopInstances", func() error {
+		return s.stopper.StopInstances(ctx, region, instanceIDs)
+	})
+}
+
+func (s meteredStopper) StartInstances(ctx context.Context, region string, instanceIDs []string) error {
+	return s.p.call(ctx, "StartInstances", func() error {
+		return s.stopper.StartInstances(ctx, region, instanceIDs)
+	})
+}
+
+// meteredQuotaReporter meters a client's QuotaReporter calls
+type meteredQuotaReporter struct {
+	p        *meteredProvider
+	reporter QuotaReporter
+}
+
+func (q meteredQuotaReporter) NodeQuotas(ctx context.Context) (quotas []NodeQuota, err error) {
+	err = q.p.call(ctx, "NodeQuotas", func() error {
+		quotas, err = q.reporter.NodeQuotas(ctx)
+		return err
+	})
+	return quotas, err
+}
+
+// meteredIdentityProvisioner meters a client's IdentityProvisioner calls
+type meteredIdentityProvisioner struct {
+	p          *meteredProvider
+	identities IdentityProvisioner
+}
+
+func (i meteredIdentityProvisioner) DefaultIdentity() models.JobIdentity {
+	return i.identities.DefaultIdentity()
+}
+
+func (i meteredIdentityProvisioner) CreateJobIdentity(ctx context.Context, jobID string, grants []models.StorageGrant) (identity models.JobIdentity, err error) {
+	err = i.p.call(ctx, "CreateJobIdentity", func() error {
+		identity, err = i.identities.CreateJobIdentity(ctx, jobID, grants)
+		return err
+	})
+	return identity, err
+}
+
+func (i meteredIdentityProvisioner) DeleteJobIdentity(ctx context.Context, identity models.JobIdentity) error {
+	return i.p.call(ctx, "DeleteJobIdentity", func() error {
+		return i.identities.DeleteJobIdentity(ctx, identity)
+	})
+}
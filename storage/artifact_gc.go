@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/core/supervisor"
+)
+
+// ArtifactGC deletes checkpoints of completed jobs according to their
+// retention policy. Deleted artifacts keep their rows, marked deleted with
+// the bytes reclaimed; every pass is stored as a report.
+type ArtifactGC struct {
+	artifactRepo *repository.ArtifactRepository
+	jobRepo      *repository.JobRepository
+	stores       *Registry
+	defaults     models.RetentionPolicy
+	dryRun       bool       // Default mode for scheduled passes
+	mu           sync.Mutex // One pass at a time
+}
+
+// NewArtifactGC creates a retention garbage collector.
+// defaults must set both KeepLast and MaxAgeDays.
+func NewArtifactGC(
+	artifactRepo *repository.ArtifactRepository,
+	jobRepo *repository.JobRepository,
+	stores *Registry,
+	defaults models.RetentionPolicy,
+	dryRun bool,
+) *ArtifactGC {
+	return &ArtifactGC{
+		artifactRepo: artifactRepo,
+		jobRepo:      jobRepo,
+		stores:       stores,
+		defaults:     defaults,
+		dryRun:       dryRun,
+	}
+}
+
+// Start runs a pass every interval in the configured mode
+func (gc *ArtifactGC) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			supervisor.Heartbeat(ctx)
+			if _, err := gc.Run(ctx, gc.dryRun); err != nil {
+				log.Printf("Artifact GC failed: %v", err)
+			}
+		}
+	}
+}
+
+// DryRunDefault reports whether scheduled passes only report what they would delete
+func (gc *ArtifactGC) DryRunDefault() bool {
+	return gc.dryRun
+}
+
+// Run performs one GC pass. In a dry run nothing is deleted; the report lists
+// what would be deleted and the bytes that would be reclaimed.
+func (gc *ArtifactGC) Run(ctx context.Context, dryRun bool) (*models.ArtifactGCRun, error) {
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+
+	run := &models.ArtifactGCRun{DryRun: dryRun}
+	if err := gc.artifactRepo.CreateGCRun(run); err != nil {
+		return nil, fmt.Errorf("failed to create gc run: %w", err)
+	}
+
+	jobIDs, err := gc.artifactRepo.ListJobsWithLiveCheckpoints()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs with checkpoints: %w", err)
+	}
+
+	now := time.Now()
+	for _, jobID := range jobIDs {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := gc.collectJob(ctx, run, jobID, now); err != nil {
+			log.Printf("Artifact GC skipped job %s: %v", jobID, err)
+			continue
+		}
+		run.JobsScanned++
+	}
+
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	if err := gc.artifactRepo.FinishGCRun(run); err != nil {
+		return nil, fmt.Errorf("failed to store gc run %d: %w", run.ID, err)
+	}
+
+	log.Printf("Artifact GC run %d (dry_run=%v): %d jobs, %d artifacts, %d bytes",
+		run.ID, dryRun, run.JobsScanned, len(run.Deletions), run.BytesReclaimed)
+	return run, nil
+}
+
+// collectJob applies a job's retention policy to its checkpoints
+func (gc *ArtifactGC) collectJob(ctx context.Context, run *models.ArtifactGCRun, jobID string, now time.Time) error {
+	job, err := gc.jobRepo.GetJob(jobID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch job: %w", err)
+	}
+	policy := job.ArtifactRetention.Resolve(gc.defaults)
+
+	checkpointType := models.ArtifactTypeCheckpoint
+	checkpoints, err := gc.artifactRepo.GetJobArtifacts(jobID, &checkpointType)
+	if err != nil {
+		return fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+
+	for _, artifact := range SelectExpiredCheckpoints(checkpoints, policy, now) {
+		deletion := models.ArtifactDeletion{
+			JobID:      jobID,
+			ArtifactID: artifact.ID,
+			URI:        artifact.URI,
+			CreatedAt:  artifact.CreatedAt,
+		}
+
+		if run.DryRun {
+			deletion.Bytes = recordedSize(artifact)
+		} else {
+			deletion.Bytes, err = gc.stores.DeleteArtifact(ctx, artifact.URI)
+			if replicaURI, ok := artifact.MetaJSON["replica_uri"].(string); ok && err == nil {
+				// The replica expires with its checkpoint
+				_, err = gc.stores.DeleteArtifact(ctx, replicaURI)
+			}
+			if err == nil {
+				err = gc.artifactRepo.MarkDeleted(artifact.ID, deletion.Bytes)
+			}
+			if err != nil {
+				deletion.Error = err.Error()
+				log.Printf("Artifact GC failed to delete %s for job %s: %v", artifact.URI, jobID, err)
+			}
+		}
+
+		if deletion.Error == "" {
+			run.BytesReclaimed += deletion.Bytes
+		}
+		run.Deletions = append(run.Deletions, deletion)
+	}
+	return nil
+}
+
+// SelectExpiredCheckpoints returns the checkpoints a policy deletes: everything
+// except the newest KeepLast, pinned checkpoints and those younger than MaxAgeDays
+func SelectExpiredCheckpoints(checkpoints []models.JobArtifact, policy models.RetentionPolicy, now time.Time) []models.JobArtifact {
+	sorted := make([]models.JobArtifact, len(checkpoints))
+	copy(sorted, checkpoints)
+	sortCheckpointsNewestFirst(sorted)
+
+	keepLast, maxAgeDays := 0, 0
+	if policy.KeepLast != nil {
+		keepLast = *policy.KeepLast
+	}
+	if policy.MaxAgeDays != nil {
+		maxAgeDays = *policy.MaxAgeDays
+	}
+	cutoff := now.AddDate(0, 0, -maxAgeDays)
+
+	var expired []models.JobArtifact
+	for i, artifact := range sorted {
+		if i < keepLast || artifact.Pinned || artifact.CreatedAt.After(cutoff) {
+			continue
+		}
+		expired = append(expired, artifact)
+	}
+	return expired
+}
+
+// recordedSize returns the size captured when the checkpoint was saved
+func recordedSize(artifact models.JobArtifact) int64 {
+	if size, ok := artifact.MetaJSON["size_bytes"].(float64); ok {
+		return int64(size)
+	}
+	return 0
+}
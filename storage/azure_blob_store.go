@@ -0,0 +1,265 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// azureBlobAPIVersion is the Blob service REST API version used for all requests
+const azureBlobAPIVersion = "2021-08-06"
+
+// AzureBlobStore talks to Azure Blob Storage over the REST API using a SAS token.
+// URIs are az://container/blob within the configured storage account.
+type AzureBlobStore struct {
+	account    string
+	sasToken   string // Without leading "?"
+	httpClient *http.Client
+}
+
+// NewAzureBlobStore creates an Azure Blob store for a storage account
+func NewAzureBlobStore(account, sasToken string) *AzureBlobStore {
+	return &AzureBlobStore{
+		account:    account,
+		sasToken:   strings.TrimPrefix(sasToken, "?"),
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// Stat returns blob properties via HEAD
+func (a *AzureBlobStore) Stat(ctx context.Context, container, blob string) (*ObjectInfo, error) {
+	resp, err := a.do(ctx, http.MethodHead, a.blobURL(container, blob, nil), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrObjectNotFound
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("azure stat %s/%s: status %d", container, blob, resp.StatusCode)
+	}
+
+	checksum := resp.Header.Get("Content-MD5")
+	if checksum == "" {
+		checksum = strings.Trim(resp.Header.Get("ETag"), `"`)
+	}
+	info := &ObjectInfo{
+		Key:      blob,
+		Size:     resp.ContentLength,
+		Checksum: checksum,
+	}
+	if lastModified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.LastModified = lastModified
+	}
+	return info, nil
+}
+
+// enumerationResults is the List Blobs response body
+type enumerationResults struct {
+	Blobs struct {
+		Blob []struct {
+			Name       string `xml:"Name"`
+			Properties struct {
+				ContentLength int64  `xml:"Content-Length"`
+				ContentMD5    string `xml:"Content-MD5"`
+				Etag          string `xml:"Etag"`
+				LastModified  string `xml:"Last-Modified"`
+			} `xml:"Properties"`
+		} `xml:"Blob"`
+	} `xml:"Blobs"`
+	NextMarker string `xml:"NextMarker"`
+}
+
+// List returns up to limit blobs under prefix
+func (a *AzureBlobStore) List(ctx context.Context, container, prefix string, limit int) ([]ObjectInfo, bool, error) {
+	var objects []ObjectInfo
+	marker := ""
+
+	for {
+		query := url.Values{"restype": {"container"}, "comp": {"list"}, "prefix": {prefix}}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+
+		resp, err := a.do(ctx, http.MethodGet, a.blobURL(container, "", query), nil)
+		if err != nil {
+			return nil, false, err
+		}
+
+		var result enumerationResults
+		if err := decodeXMLResponse(resp, &result); err != nil {
+			return nil, false, fmt.Errorf("azure list %s/%s: %w", container, prefix, err)
+		}
+
+		for _, b := range result.Blobs.Blob {
+			if limit > 0 && len(objects) == limit {
+				return objects, true, nil
+			}
+			checksum := b.Properties.ContentMD5
+			if checksum == "" {
+				checksum = strings.Trim(b.Properties.Etag, `"`)
+			}
+			info := ObjectInfo{
+				Key:      b.Name,
+				Size:     b.Properties.ContentLength,
+				Checksum: checksum,
+			}
+			if lastModified, err := http.ParseTime(b.Properties.LastModified); err == nil {
+				info.LastModified = lastModified
+			}
+			objects = append(objects, info)
+		}
+
+		if result.NextMarker == "" {
+			return objects, false, nil
+		}
+		marker = result.NextMarker
+	}
+}
+
+// Get downloads a blob; the caller closes the body
+func (a *AzureBlobStore) Get(ctx context.Context, container, blob string) (io.ReadCloser, error) {
+	resp, err := a.do(ctx, http.MethodGet, a.blobURL(container, blob, nil), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrObjectNotFound
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("azure get %s/%s: status %d: %s", container, blob, resp.StatusCode, string(msg))
+	}
+	return resp.Body, nil
+}
+
+// Copy performs a server-side Copy Blob within the account
+func (a *AzureBlobStore) Copy(ctx context.Context, srcContainer, srcBlob, dstContainer, dstBlob string) error {
+	header := http.Header{}
+	header.Set("x-ms-copy-source", a.blobURL(srcContainer, srcBlob, nil))
+
+	resp, err := a.do(ctx, http.MethodPut, a.blobURL(dstContainer, dstBlob, nil), header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrObjectNotFound
+	}
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("azure copy to %s/%s: status %d: %s", dstContainer, dstBlob, resp.StatusCode, string(msg))
+	}
+	return nil
+}
+
+// Delete removes a blob (and its snapshots)
+func (a *AzureBlobStore) Delete(ctx context.Context, container, blob string) error {
+	header := http.Header{}
+	header.Set("x-ms-delete-snapshots", "include")
+
+	resp, err := a.do(ctx, http.MethodDelete, a.blobURL(container, blob, nil), header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrObjectNotFound
+	}
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("azure delete %s/%s: status %d: %s", container, blob, resp.StatusCode, string(msg))
+	}
+	return nil
+}
+
+// Put uploads a block blob in a single Put Blob request (up to 5000 MiB).
+// The upload is not subject to the client timeout; ctx bounds it.
+func (a *AzureBlobStore) Put(ctx context.Context, container, blob string, body io.Reader, size int64, contentType string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, a.blobURL(container, blob, nil), body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("x-ms-blob-type", "BlockBlob")
+	req.Header.Set("x-ms-version", azureBlobAPIVersion)
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := (&http.Client{Transport: a.httpClient.Transport}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("azure put %s/%s: status %d: %s", container, blob, resp.StatusCode, string(msg))
+	}
+	return nil
+}
+
+// FetchCommand returns an az CLI download snippet (uses the node's managed identity)
+func (a *AzureBlobStore) FetchCommand(container, blob, dest string) string {
+	return fmt.Sprintf(
+		"az storage blob download --auth-mode login --only-show-errors --account-name %s --container-name %s --name %s --file %s",
+		a.account, container, blob, dest,
+	)
+}
+
+// SyncCommand returns an azcopy sync snippet (uses the node's managed identity)
+func (a *AzureBlobStore) SyncCommand(container, prefix, dest string, concurrency int) string {
+	return fmt.Sprintf(`azcopy login --identity >/dev/null
+AZCOPY_CONCURRENCY_VALUE=%d azcopy sync 'https://%s.blob.core.windows.net/%s/%s' '%s' --recursive --delete-destination=true --log-level=ERROR`,
+		concurrency, a.account, container, prefix, dest)
+}
+
+// blobURL builds a blob (or container, when blob is empty) URL with the SAS token appended
+func (a *AzureBlobStore) blobURL(container, blob string, query url.Values) string {
+	u := fmt.Sprintf("https://%s.blob.core.windows.net/%s", a.account, container)
+	if blob != "" {
+		u += "/" + escapeKey(blob)
+	}
+
+	params := query.Encode()
+	if a.sasToken != "" {
+		if params != "" {
+			params += "&"
+		}
+		params += a.sasToken
+	}
+	if params != "" {
+		u += "?" + params
+	}
+	return u
+}
+
+// do sends a request with the Blob service version header
+func (a *AzureBlobStore) do(ctx context.Context, method, rawURL string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, values := range header {
+		for _, v := range values {
+			req.Header.Add(k, v)
+		}
+	}
+	req.Header.Set("x-ms-version", azureBlobAPIVersion)
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+
+	return a.httpClient.Do(req)
+}
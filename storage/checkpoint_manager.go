@@ -2,8 +2,10 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"time"
+	"log"
+	"sort"
 
 	"gpu-orchestrator/core/models"
 	"gpu-orchestrator/core/repository"
@@ -12,12 +14,15 @@ import (
 // CheckpointManager manages checkpoint storage and retrieval
 type CheckpointManager struct {
 	artifactRepo *repository.ArtifactRepository
+	stores       *Registry // Optional; enables existence checks and size/checksum collection
 }
 
-// NewCheckpointManager creates a new checkpoint manager
-func NewCheckpointManager(artifactRepo *repository.ArtifactRepository) *CheckpointManager {
+// NewCheckpointManager creates a new checkpoint manager.
+// stores may be nil, in which case checkpoint URIs are recorded without verification.
+func NewCheckpointManager(artifactRepo *repository.ArtifactRepository, stores *Registry) *CheckpointManager {
 	return &CheckpointManager{
 		artifactRepo: artifactRepo,
+		stores:       stores,
 	}
 }
 
@@ -39,6 +44,17 @@ func (cm *CheckpointManager) SaveCheckpoint(
 		meta[k] = v
 	}
 
+	// Verify the checkpoint was actually written and record its size/checksum
+	if cm.stores != nil {
+		objectMeta, err := cm.stores.CollectArtifactMeta(ctx, checkpointURI)
+		if err != nil {
+			return fmt.Errorf("failed to verify checkpoint %s: %w", checkpointURI, err)
+		}
+		for k, v := range objectMeta {
+			meta[k] = v
+		}
+	}
+
 	return cm.artifactRepo.CreateArtifact(
 		jobID,
 		models.ArtifactTypeCheckpoint,
@@ -47,44 +63,55 @@ func (cm *CheckpointManager) SaveCheckpoint(
 	)
 }
 
-// GetLatestCheckpoint retrieves the latest checkpoint for a job
+// GetLatestCheckpoint retrieves the latest checkpoint for a job.
+// When object storage is configured, checkpoints that no longer exist are skipped.
 func (cm *CheckpointManager) GetLatestCheckpoint(ctx context.Context, jobID string) (string, error) {
-	artifacts, err := cm.artifactRepo.GetJobArtifacts(jobID, nil)
+	checkpointType := models.ArtifactTypeCheckpoint
+	artifacts, err := cm.artifactRepo.GetJobArtifacts(jobID, &checkpointType)
 	if err != nil {
 		return "", err
 	}
 
-	var latestCheckpoint string
-	var latestStep int = -1
-	latestTime := time.Time{}
+	sortCheckpointsNewestFirst(artifacts)
 
 	for _, artifact := range artifacts {
-		if artifact.Type != models.ArtifactTypeCheckpoint {
+		if err := cm.VerifyCheckpoint(ctx, artifact.URI); err != nil {
+			log.Printf("Skipping checkpoint %s for job %s: %v", artifact.URI, jobID, err)
 			continue
 		}
+		return artifact.URI, nil
+	}
 
-		// Extract step from metadata
-		step, ok := artifact.MetaJSON["step"].(float64)
-		if !ok {
-			// Fallback to time-based selection
-			if artifact.CreatedAt.After(latestTime) {
-				latestTime = artifact.CreatedAt
-				latestCheckpoint = artifact.URI
-			}
-			continue
-		}
+	return "", fmt.Errorf("no checkpoint found for job %s", jobID)
+}
 
-		if int(step) > latestStep {
-			latestStep = int(step)
-			latestCheckpoint = artifact.URI
+// sortCheckpointsNewestFirst orders checkpoints by step (highest first);
+// checkpoints without a step sort after, newest first
+func sortCheckpointsNewestFirst(artifacts []models.JobArtifact) {
+	sort.SliceStable(artifacts, func(i, j int) bool {
+		stepI, okI := artifacts[i].MetaJSON["step"].(float64)
+		stepJ, okJ := artifacts[j].MetaJSON["step"].(float64)
+		if okI != okJ {
+			return okI
 		}
-	}
+		if okI && stepI != stepJ {
+			return stepI > stepJ
+		}
+		return artifacts[i].CreatedAt.After(artifacts[j].CreatedAt)
+	})
+}
 
-	if latestCheckpoint == "" {
-		return "", fmt.Errorf("no checkpoint found for job %s", jobID)
+// VerifyCheckpoint checks that a checkpoint object (or prefix) exists in storage.
+// Always succeeds when object storage is not configured.
+func (cm *CheckpointManager) VerifyCheckpoint(ctx context.Context, checkpointURI string) error {
+	if cm.stores == nil {
+		return nil
 	}
-
-	return latestCheckpoint, nil
+	_, err := cm.stores.CollectArtifactMeta(ctx, checkpointURI)
+	if errors.Is(err, ErrObjectNotFound) {
+		return fmt.Errorf("checkpoint %s does not exist", checkpointURI)
+	}
+	return err
 }
 
 // ListCheckpoints lists all checkpoints for a job
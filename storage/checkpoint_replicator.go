@@ -0,0 +1,387 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"gpu-orchestrator/core/clock"
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/core/repository"
+	"gpu-orchestrator/core/supervisor"
+)
+
+// checkpointReplicationBatch bounds the checkpoints one sweep copies
+const checkpointReplicationBatch = 100
+
+// TransferPricer prices moving data between provider regions
+type TransferPricer interface {
+	CalculateDataTransferCost(dataSizeGB float64, sourceProvider models.Provider, sourceRegion string, targetProvider models.Provider, targetRegion string) float64
+}
+
+// CheckpointCopy is one copy of a checkpoint and where it is stored.
+// Provider and region are empty when the location is unknown.
+type CheckpointCopy struct {
+	URI      string
+	Provider models.Provider
+	Region   string
+	Replica  bool
+}
+
+// CheckpointReplicator copies the checkpoints of jobs declaring
+// checkpointing.replicate_to to their secondary location, so a regional
+// outage does not strand their progress. Every sweep picks up checkpoints
+// registered since the last one; failed copies are retried up to
+// maxAttempts. The replica URI, lag and cost are recorded on the artifact
+// and the transfer is charged to the job.
+type CheckpointReplicator struct {
+	artifactRepo *repository.ArtifactRepository
+	jobRepo      *repository.JobRepository
+	stores       *Registry
+	locations    map[string]string // "provider/region" -> URI prefix (CHECKPOINT_LOCATIONS)
+	pricer       TransferPricer    // Optional; without it replication is not charged
+	maxAttempts  int
+	now          func() time.Time
+	mu           sync.Mutex // One sweep at a time
+}
+
+// NewCheckpointReplicator creates a checkpoint replicator. locations maps
+// "provider/region" to the URI prefix checkpoints are stored under there.
+func NewCheckpointReplicator(
+	artifactRepo *repository.ArtifactRepository,
+	jobRepo *repository.JobRepository,
+	stores *Registry,
+	locations map[string]string,
+	maxAttempts int,
+) *CheckpointReplicator {
+	return &CheckpointReplicator{
+		artifactRepo: artifactRepo,
+		jobRepo:      jobRepo,
+		stores:       stores,
+		locations:    locations,
+		maxAttempts:  max(maxAttempts, 1),
+		now:          clock.System.Now,
+	}
+}
+
+// SetTransferPricer sets how replication transfers are priced
+func (cr *CheckpointReplicator) SetTransferPricer(pricer TransferPricer) {
+	cr.pricer = pricer
+}
+
+// Start sweeps every interval until ctx is done
+func (cr *CheckpointReplicator) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			supervisor.Heartbeat(ctx)
+			if err := cr.Sweep(ctx); err != nil {
+				log.Printf("Checkpoint replication sweep failed: %v", err)
+			}
+		}
+	}
+}
+
+// Sweep replicates checkpoints that are not replicated yet, newest first.
+// Checkpoints of jobs without replicate_to are marked as not replicated.
+func (cr *CheckpointReplicator) Sweep(ctx context.Context) error {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	artifacts, err := cr.artifactRepo.ListReplicationCandidates(checkpointReplicationBatch)
+	if err != nil {
+		return fmt.Errorf("failed to list checkpoints to replicate: %w", err)
+	}
+
+	jobs := make(map[string]*models.Job)
+	for _, artifact := range artifacts {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		job, ok := jobs[artifact.JobID]
+		if !ok {
+			job, err = cr.jobRepo.GetJob(artifact.JobID)
+			if err != nil {
+				log.Printf("Failed to load job %s for checkpoint replication: %v", artifact.JobID, err)
+				continue
+			}
+			jobs[artifact.JobID] = job
+		}
+
+		if job.Checkpointing == nil || job.Checkpointing.ReplicateTo == "" {
+			if err := cr.artifactRepo.SetReplication(artifact.ID, models.ReplicaNone, 0, artifact.MetaJSON); err != nil {
+				log.Printf("Failed to record checkpoint %d as not replicated: %v", artifact.ID, err)
+			}
+			continue
+		}
+		cr.replicate(ctx, job, artifact)
+	}
+	return nil
+}
+
+// replicate copies one checkpoint and records the outcome
+func (cr *CheckpointReplicator) replicate(ctx context.Context, job *models.Job, artifact models.JobArtifact) {
+	attempts := artifact.ReplicaAttempts + 1
+	meta := make(map[string]interface{}, len(artifact.MetaJSON)+6)
+	for k, v := range artifact.MetaJSON {
+		meta[k] = v
+	}
+
+	replicaURI, err := cr.ReplicaURI(job, artifact.URI)
+	var copied int64
+	if err == nil {
+		copied, err = cr.stores.CopyArtifact(ctx, artifact.URI, replicaURI)
+	}
+	if err != nil {
+		status := models.ReplicaPending
+		if attempts >= cr.maxAttempts {
+			status = models.ReplicaFailed
+		}
+		meta["replica_error"] = err.Error()
+		if err := cr.artifactRepo.SetReplication(artifact.ID, status, attempts, meta); err != nil {
+			log.Printf("Failed to record replication failure of checkpoint %d: %v", artifact.ID, err)
+		}
+		cr.recordEvent(job, "checkpoint_replication_failed", map[string]interface{}{
+			"artifact_id": artifact.ID,
+			"uri":         artifact.URI,
+			"attempts":    attempts,
+			"final":       status == models.ReplicaFailed,
+			"error":       err.Error(),
+		})
+		log.Printf("Failed to replicate checkpoint %s of job %s (attempt %d): %v", artifact.URI, job.ID, attempts, err)
+		return
+	}
+
+	now := cr.now()
+	sizeGB := float64(copied) / (1 << 30)
+	source := cr.primaryCopy(job, artifact.URI)
+	target := cr.replicaCopy(job, replicaURI)
+	cost := 0.0
+	if cr.pricer != nil {
+		cost = cr.pricer.CalculateDataTransferCost(sizeGB, source.Provider, source.Region, target.Provider, target.Region)
+	}
+	lag := now.Sub(artifact.CreatedAt).Seconds()
+
+	delete(meta, "replica_error")
+	meta["replica_uri"] = replicaURI
+	meta["replicated_at"] = now
+	meta["replica_lag_seconds"] = lag
+	meta["replica_size_bytes"] = copied
+	meta["replica_cost_usd"] = cost
+	if err := cr.artifactRepo.SetReplication(artifact.ID, models.ReplicaReplicated, attempts, meta); err != nil {
+		log.Printf("Failed to record replica of checkpoint %d: %v", artifact.ID, err)
+		return
+	}
+	if cost > 0 {
+		if err := cr.jobRepo.AddJobTransferCost(job.ID, cost); err != nil {
+			log.Printf("Failed to charge replication of checkpoint %d to job %s: %v", artifact.ID, job.ID, err)
+		}
+	}
+	cr.recordEvent(job, "checkpoint_replicated", map[string]interface{}{
+		"artifact_id": artifact.ID,
+		"uri":         artifact.URI,
+		"replica_uri": replicaURI,
+		"lag_seconds": lag,
+		"size_gb":     sizeGB,
+		"cost_usd":    cost,
+	})
+}
+
+// recordEvent records a replication event at the job's current status
+func (cr *CheckpointReplicator) recordEvent(job *models.Job, reason string, meta map[string]interface{}) {
+	status := job.Status
+	if err := cr.jobRepo.CreateJobEvent(job.ID, &status, status, reason, meta); err != nil {
+		log.Printf("Failed to record %s event for job %s: %v", reason, job.ID, err)
+	}
+}
+
+// ReplicaPrefix resolves replicate_to to a URI prefix: URIs are used as
+// they are, provider/region through CHECKPOINT_LOCATIONS
+func (cr *CheckpointReplicator) ReplicaPrefix(replicateTo string) (string, error) {
+	if strings.Contains(replicateTo, "://") {
+		return strings.TrimRight(replicateTo, "/"), nil
+	}
+	prefix, ok := cr.locations[replicateTo]
+	if !ok {
+		return "", fmt.Errorf("no checkpoint location configured for %s (CHECKPOINT_LOCATIONS)", replicateTo)
+	}
+	return strings.TrimRight(prefix, "/"), nil
+}
+
+// ReplicaURI returns where a checkpoint of the job is replicated to. Paths
+// below the job's checkpoint prefix are kept; other checkpoints keep their
+// base name.
+func (cr *CheckpointReplicator) ReplicaURI(job *models.Job, checkpointURI string) (string, error) {
+	prefix, err := cr.ReplicaPrefix(job.Checkpointing.ReplicateTo)
+	if err != nil {
+		return "", err
+	}
+	jobURI := job.Checkpointing.JobURI(job.ID)
+	relative := "/" + path.Base(strings.TrimRight(checkpointURI, "/"))
+	if strings.HasPrefix(checkpointURI, jobURI+"/") {
+		relative = strings.TrimPrefix(checkpointURI, jobURI)
+	}
+	return prefix + "/" + job.ID + relative, nil
+}
+
+// locate returns the provider and region whose configured location is the
+// longest prefix of uri
+func (cr *CheckpointReplicator) locate(uri string) (models.Provider, string, bool) {
+	best, bestLen := "", 0
+	for key, prefix := range cr.locations {
+		prefix = strings.TrimRight(prefix, "/")
+		if (uri == prefix || strings.HasPrefix(uri, prefix+"/")) && len(prefix) > bestLen {
+			best, bestLen = key, len(prefix)
+		}
+	}
+	if best == "" {
+		return "", "", false
+	}
+	provider, region, _ := strings.Cut(best, "/")
+	return models.Provider(provider), region, true
+}
+
+// primaryCopy locates a checkpoint's primary copy. Locations not covered by
+// CHECKPOINT_LOCATIONS are taken to be where the job last ran.
+func (cr *CheckpointReplicator) primaryCopy(job *models.Job, uri string) CheckpointCopy {
+	if provider, region, ok := cr.locate(uri); ok {
+		return CheckpointCopy{URI: uri, Provider: provider, Region: region}
+	}
+	primary := CheckpointCopy{URI: uri, Region: job.SelectedRegion}
+	if job.SelectedProvider != nil {
+		primary.Provider = *job.SelectedProvider
+	}
+	return primary
+}
+
+// replicaCopy locates a checkpoint's replica: the replicate_to region, or
+// the configured location of its URI
+func (cr *CheckpointReplicator) replicaCopy(job *models.Job, uri string) CheckpointCopy {
+	replica := CheckpointCopy{URI: uri, Replica: true}
+	if replicateTo := job.Checkpointing.ReplicateTo; !strings.Contains(replicateTo, "://") {
+		provider, region, _ := strings.Cut(replicateTo, "/")
+		replica.Provider, replica.Region = models.Provider(provider), region
+	} else if provider, region, ok := cr.locate(uri); ok {
+		replica.Provider, replica.Region = provider, region
+	}
+	return replica
+}
+
+// PreferredCopy returns the copy of a checkpoint a job restarting in
+// provider/region should read: the replica when it is in that region, or
+// cheaper to move there than the primary copy; the primary otherwise.
+func (cr *CheckpointReplicator) PreferredCopy(job *models.Job, checkpoint models.JobArtifact, provider models.Provider, region string) CheckpointCopy {
+	primary := cr.primaryCopy(job, checkpoint.URI)
+	replicaURI, _ := checkpoint.MetaJSON["replica_uri"].(string)
+	if job.Checkpointing == nil || job.Checkpointing.ReplicateTo == "" ||
+		checkpoint.ReplicaStatus != models.ReplicaReplicated || replicaURI == "" {
+		return primary
+	}
+
+	replica := cr.replicaCopy(job, replicaURI)
+	if replica.Provider == "" || (primary.Provider == provider && primary.Region == region) {
+		return primary
+	}
+	if replica.Provider == provider && replica.Region == region {
+		return replica
+	}
+	if cr.pricer == nil || primary.Provider == "" {
+		return primary
+	}
+	// Per-GB prices; the tiers of the pricing table rank regions the same way
+	replicaRate := cr.pricer.CalculateDataTransferCost(1, replica.Provider, replica.Region, provider, region)
+	primaryRate := cr.pricer.CalculateDataTransferCost(1, primary.Provider, primary.Region, provider, region)
+	if replicaRate < primaryRate {
+		return replica
+	}
+	return primary
+}
+
+// ResumeCheckpoint returns the copy of the job's newest checkpoint a restart
+// in provider/region resumes from; ok is false when the job has none
+func (cr *CheckpointReplicator) ResumeCheckpoint(job *models.Job, provider models.Provider, region string) (CheckpointCopy, bool, error) {
+	checkpointType := models.ArtifactTypeCheckpoint
+	checkpoints, err := cr.artifactRepo.GetJobArtifacts(job.ID, &checkpointType)
+	if err != nil {
+		return CheckpointCopy{}, false, err
+	}
+	if len(checkpoints) == 0 {
+		return CheckpointCopy{}, false, nil
+	}
+	sortCheckpointsNewestFirst(checkpoints)
+	return cr.PreferredCopy(job, checkpoints[0], provider, region), true, nil
+}
+
+// Replication returns the replication state of each checkpoint (newest
+// first, as listed) and the job's summary. The summary is nil when the job
+// does not replicate checkpoints.
+func (cr *CheckpointReplicator) Replication(job *models.Job, checkpoints []models.JobArtifact) ([]models.CheckpointReplica, *models.CheckpointReplication) {
+	if job.Checkpointing == nil || job.Checkpointing.ReplicateTo == "" {
+		return nil, nil
+	}
+
+	now := cr.now()
+	summary := &models.CheckpointReplication{ReplicateTo: job.Checkpointing.ReplicateTo}
+	summary.Location, _ = cr.ReplicaPrefix(job.Checkpointing.ReplicateTo)
+
+	replicas := make([]models.CheckpointReplica, len(checkpoints))
+	for i, checkpoint := range checkpoints {
+		replica := CheckpointReplicaOf(checkpoint, now)
+		replicas[i] = replica
+
+		switch replica.Status {
+		case models.ReplicaReplicated:
+			summary.Replicated++
+			summary.CostUSD += replica.CostUSD
+			if summary.LastReplicatedAt == nil || replica.ReplicatedAt.After(*summary.LastReplicatedAt) {
+				summary.LastReplicatedAt = replica.ReplicatedAt
+			}
+		case models.ReplicaFailed:
+			summary.Failed++
+			summary.LagSeconds = max(summary.LagSeconds, replica.LagSeconds)
+		default:
+			summary.Pending++
+			summary.LagSeconds = max(summary.LagSeconds, replica.LagSeconds)
+		}
+	}
+	return replicas, summary
+}
+
+// CheckpointReplicaOf reads a checkpoint's replication state from its
+// status and metadata. Checkpoints the replicator has not seen are pending.
+func CheckpointReplicaOf(checkpoint models.JobArtifact, now time.Time) models.CheckpointReplica {
+	replica := models.CheckpointReplica{
+		Status:   checkpoint.ReplicaStatus,
+		Attempts: checkpoint.ReplicaAttempts,
+	}
+	if replica.Status == "" {
+		replica.Status = models.ReplicaPending
+	}
+	replica.URI, _ = checkpoint.MetaJSON["replica_uri"].(string)
+	replica.Error, _ = checkpoint.MetaJSON["replica_error"].(string)
+	replica.CostUSD, _ = checkpoint.MetaJSON["replica_cost_usd"].(float64)
+	if size, ok := checkpoint.MetaJSON["replica_size_bytes"].(float64); ok {
+		replica.SizeGB = size / (1 << 30)
+	}
+
+	if replica.Status != models.ReplicaReplicated {
+		replica.LagSeconds = now.Sub(checkpoint.CreatedAt).Seconds()
+		return replica
+	}
+	replica.LagSeconds, _ = checkpoint.MetaJSON["replica_lag_seconds"].(float64)
+	if at, ok := checkpoint.MetaJSON["replicated_at"].(string); ok {
+		if parsed, err := time.Parse(time.RFC3339Nano, at); err == nil {
+			replica.ReplicatedAt = &parsed
+		}
+	}
+	return replica
+}
@@ -0,0 +1,221 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gpu-orchestrator/core/models"
+)
+
+// ErrDatasetInvalid is returned when a dataset is empty, too large to list or
+// does not match its manifest
+var ErrDatasetInvalid = errors.New("dataset verification failed")
+
+// maxManifestBytes bounds how much of a manifest is read
+const maxManifestBytes = 64 << 20
+
+// DatasetManifest lists the files a dataset is expected to contain. Paths are
+// relative to the dataset prefix; size and checksum are compared when set.
+// Checksums are compared as reported by the backend (S3 ETag, GCS/Azure MD5).
+type DatasetManifest struct {
+	Files []ManifestFile `json:"files"`
+}
+
+// ManifestFile is one expected dataset file
+type ManifestFile struct {
+	Path     string `json:"path"`
+	Size     int64  `json:"size,omitempty"`
+	Checksum string `json:"checksum,omitempty"`
+}
+
+// totalBytes sums the manifest's file sizes
+func (m *DatasetManifest) totalBytes() int64 {
+	var total int64
+	for _, f := range m.Files {
+		total += f.Size
+	}
+	return total
+}
+
+// DatasetReport is the outcome of a dataset check
+type DatasetReport struct {
+	Mode       models.DatasetVerifyMode `json:"mode"`
+	Objects    int                      `json:"objects"` // Objects listed (bounded in sample/size mode)
+	SizeBytes  int64                    `json:"size_bytes"`
+	SizeExact  bool                     `json:"size_exact"` // False when the listing was cut off and SizeBytes is a lower bound
+	Checked    int                      `json:"checked"`    // Manifest entries compared
+	Missing    []string                 `json:"missing,omitempty"`
+	Mismatched []string                 `json:"mismatched,omitempty"`
+}
+
+// SizeGB returns the dataset size in GB
+func (r *DatasetReport) SizeGB() float64 {
+	return float64(r.SizeBytes) / (1 << 30)
+}
+
+// DatasetVerifier checks that a job's dataset exists and matches its manifest
+// before any instance is provisioned
+type DatasetVerifier struct {
+	stores     *Registry
+	maxObjects int // Listing cap; full mode fails above it
+	sampleSize int // Objects listed and manifest entries checked in sample mode
+}
+
+// NewDatasetVerifier creates a new dataset verifier
+func NewDatasetVerifier(stores *Registry, maxObjects, sampleSize int) *DatasetVerifier {
+	return &DatasetVerifier{
+		stores:     stores,
+		maxObjects: maxObjects,
+		sampleSize: sampleSize,
+	}
+}
+
+// Verify checks the dataset at datasetURI. Errors wrapping ErrDatasetInvalid
+// come with a report naming the missing and mismatched files; other errors
+// mean the storage backend could not be read.
+func (v *DatasetVerifier) Verify(ctx context.Context, datasetURI string, cfg models.DatasetVerify) (*DatasetReport, error) {
+	prefix := strings.TrimSuffix(datasetURI, "/") + "/"
+	loc, err := ParseObjectURI(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := v.maxObjects
+	if cfg.Mode == models.DatasetVerifySample {
+		limit = v.sampleSize
+	}
+	objects, truncated, err := v.stores.ListLimit(ctx, prefix, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dataset %s: %w", datasetURI, err)
+	}
+	if len(objects) == 0 {
+		// The dataset may be a single object rather than a prefix
+		info, err := v.stores.Stat(ctx, datasetURI)
+		if errors.Is(err, ErrObjectNotFound) {
+			return &DatasetReport{Mode: cfg.Mode}, fmt.Errorf("%w: no objects under %s", ErrDatasetInvalid, datasetURI)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat dataset %s: %w", datasetURI, err)
+		}
+		objects = []ObjectInfo{*info}
+		loc.Key = strings.TrimSuffix(info.Key, objectName(info.Key))
+	}
+	if truncated && cfg.Mode == models.DatasetVerifyFull {
+		return &DatasetReport{Mode: cfg.Mode, Objects: len(objects)}, fmt.Errorf(
+			"%w: %s has more than %d objects; use verify_mode sample or size", ErrDatasetInvalid, datasetURI, v.maxObjects)
+	}
+
+	report := &DatasetReport{Mode: cfg.Mode, Objects: len(objects), SizeExact: !truncated}
+	for _, obj := range objects {
+		report.SizeBytes += obj.Size
+	}
+
+	if cfg.ManifestURI == "" {
+		return report, nil
+	}
+	manifest, err := v.loadManifest(ctx, cfg.ManifestURI)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Mode == models.DatasetVerifySample {
+		if err := v.checkSample(ctx, report, loc, manifest); err != nil {
+			return nil, err
+		}
+		// The manifest knows the full size; the bounded listing does not
+		if total := manifest.totalBytes(); total > 0 {
+			report.SizeBytes, report.SizeExact = total, true
+		}
+	} else {
+		listed := make(map[string]ObjectInfo, len(objects))
+		for _, obj := range objects {
+			listed[strings.TrimPrefix(obj.Key, loc.Key)] = obj
+		}
+		for _, file := range manifest.Files {
+			obj, ok := listed[file.Path]
+			report.compare(file, obj, ok)
+		}
+	}
+
+	if len(report.Missing) > 0 || len(report.Mismatched) > 0 {
+		return report, fmt.Errorf("%w: %d missing and %d mismatched of %d manifest files (%s)",
+			ErrDatasetInvalid, len(report.Missing), len(report.Mismatched), report.Checked, report.summary())
+	}
+	return report, nil
+}
+
+// checkSample stats up to sampleSize manifest entries spread evenly over the manifest
+func (v *DatasetVerifier) checkSample(ctx context.Context, report *DatasetReport, prefix *ObjectLocation, manifest *DatasetManifest) error {
+	step := 1
+	if len(manifest.Files) > v.sampleSize && v.sampleSize > 0 {
+		step = len(manifest.Files) / v.sampleSize
+	}
+	for i := 0; i < len(manifest.Files) && report.Checked < v.sampleSize; i += step {
+		file := manifest.Files[i]
+		loc := *prefix
+		loc.Key = prefix.Key + file.Path
+		info, err := v.stores.Stat(ctx, loc.String())
+		if err != nil && !errors.Is(err, ErrObjectNotFound) {
+			return fmt.Errorf("failed to stat dataset file %s: %w", file.Path, err)
+		}
+		if info != nil {
+			report.compare(file, *info, true)
+		} else {
+			report.compare(file, ObjectInfo{}, false)
+		}
+	}
+	return nil
+}
+
+// compare records whether a manifest entry exists with the expected size and checksum
+func (r *DatasetReport) compare(file ManifestFile, obj ObjectInfo, found bool) {
+	r.Checked++
+	switch {
+	case !found:
+		r.Missing = append(r.Missing, file.Path)
+	case file.Size > 0 && obj.Size != file.Size:
+		r.Mismatched = append(r.Mismatched, fmt.Sprintf("%s (size %d, expected %d)", file.Path, obj.Size, file.Size))
+	case file.Checksum != "" && obj.Checksum != "" && obj.Checksum != file.Checksum:
+		r.Mismatched = append(r.Mismatched, fmt.Sprintf("%s (checksum %s, expected %s)", file.Path, obj.Checksum, file.Checksum))
+	}
+}
+
+// summary names the first few missing and mismatched files
+func (r *DatasetReport) summary() string {
+	names := append(append([]string{}, r.Missing...), r.Mismatched...)
+	const shown = 5
+	if len(names) <= shown {
+		return strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(names[:shown], ", "), len(names)-shown)
+}
+
+// loadManifest reads and parses a JSON dataset manifest
+func (v *DatasetVerifier) loadManifest(ctx context.Context, uri string) (*DatasetManifest, error) {
+	body, err := v.stores.Get(ctx, uri)
+	if errors.Is(err, ErrObjectNotFound) {
+		return nil, fmt.Errorf("%w: manifest %s not found", ErrDatasetInvalid, uri)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest %s: %w", uri, err)
+	}
+	defer body.Close()
+
+	var manifest DatasetManifest
+	if err := json.NewDecoder(io.LimitReader(body, maxManifestBytes)).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("%w: invalid manifest %s: %v", ErrDatasetInvalid, uri, err)
+	}
+	if len(manifest.Files) == 0 {
+		return nil, fmt.Errorf("%w: manifest %s lists no files", ErrDatasetInvalid, uri)
+	}
+	return &manifest, nil
+}
+
+// objectName returns the last path segment of a key
+func objectName(key string) string {
+	return key[strings.LastIndex(key, "/")+1:]
+}
@@ -0,0 +1,261 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// gcsMetadataTokenURL serves access tokens for the VM's service account
+const gcsMetadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+// GCSStore talks to Google Cloud Storage over the JSON API
+type GCSStore struct {
+	accessToken string // Static token; empty = use the GCE metadata server
+	httpClient  *http.Client
+}
+
+// NewGCSStore creates a GCS store.
+// If accessToken is empty, tokens are fetched from the GCE metadata server.
+func NewGCSStore(accessToken string) *GCSStore {
+	return &GCSStore{
+		accessToken: accessToken,
+		httpClient:  &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// gcsObject is the JSON API object resource
+type gcsObject struct {
+	Name    string    `json:"name"`
+	Size    string    `json:"size"`
+	MD5Hash string    `json:"md5Hash"`
+	CRC32C  string    `json:"crc32c"`
+	Updated time.Time `json:"updated"`
+}
+
+func (o gcsObject) toObjectInfo() ObjectInfo {
+	checksum := o.MD5Hash
+	if checksum == "" {
+		checksum = o.CRC32C // Composite objects have no MD5
+	}
+	return ObjectInfo{
+		Key:          o.Name,
+		Size:         parseContentLength(o.Size),
+		Checksum:     checksum,
+		LastModified: o.Updated,
+	}
+}
+
+// Stat returns object metadata
+func (g *GCSStore) Stat(ctx context.Context, bucket, key string) (*ObjectInfo, error) {
+	var obj gcsObject
+	path := fmt.Sprintf("/storage/v1/b/%s/o/%s", url.PathEscape(bucket), url.PathEscape(key))
+	if err := g.doJSON(ctx, http.MethodGet, path, nil, &obj); err != nil {
+		return nil, err
+	}
+	info := obj.toObjectInfo()
+	return &info, nil
+}
+
+// List returns up to limit objects under prefix
+func (g *GCSStore) List(ctx context.Context, bucket, prefix string, limit int) ([]ObjectInfo, bool, error) {
+	var objects []ObjectInfo
+	pageToken := ""
+
+	for {
+		query := url.Values{"prefix": {prefix}}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+
+		var page struct {
+			Items         []gcsObject `json:"items"`
+			NextPageToken string      `json:"nextPageToken"`
+		}
+		path := fmt.Sprintf("/storage/v1/b/%s/o?%s", url.PathEscape(bucket), query.Encode())
+		if err := g.doJSON(ctx, http.MethodGet, path, nil, &page); err != nil {
+			return nil, false, fmt.Errorf("gcs list %s/%s: %w", bucket, prefix, err)
+		}
+
+		for _, item := range page.Items {
+			if limit > 0 && len(objects) == limit {
+				return objects, true, nil
+			}
+			objects = append(objects, item.toObjectInfo())
+		}
+
+		if page.NextPageToken == "" {
+			return objects, false, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+// Get downloads object media; the caller closes the body
+func (g *GCSStore) Get(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	token, err := g.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rawURL := fmt.Sprintf("https://storage.googleapis.com/storage/v1/b/%s/o/%s?alt=media", url.PathEscape(bucket), url.PathEscape(key))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrObjectNotFound
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("gcs get %s/%s: status %d: %s", bucket, key, resp.StatusCode, string(msg))
+	}
+	return resp.Body, nil
+}
+
+// Copy performs a server-side rewrite, looping until large objects finish
+func (g *GCSStore) Copy(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string) error {
+	rewriteToken := ""
+	for {
+		path := fmt.Sprintf("/storage/v1/b/%s/o/%s/rewriteTo/b/%s/o/%s",
+			url.PathEscape(srcBucket), url.PathEscape(srcKey), url.PathEscape(dstBucket), url.PathEscape(dstKey))
+		if rewriteToken != "" {
+			path += "?rewriteToken=" + url.QueryEscape(rewriteToken)
+		}
+
+		var resp struct {
+			Done         bool   `json:"done"`
+			RewriteToken string `json:"rewriteToken"`
+		}
+		if err := g.doJSON(ctx, http.MethodPost, path, nil, &resp); err != nil {
+			return fmt.Errorf("gcs copy to %s/%s: %w", dstBucket, dstKey, err)
+		}
+		if resp.Done {
+			return nil
+		}
+		rewriteToken = resp.RewriteToken
+	}
+}
+
+// Delete removes an object
+func (g *GCSStore) Delete(ctx context.Context, bucket, key string) error {
+	path := fmt.Sprintf("/storage/v1/b/%s/o/%s", url.PathEscape(bucket), url.PathEscape(key))
+	return g.doJSON(ctx, http.MethodDelete, path, nil, nil)
+}
+
+// Put uploads an object with a simple media upload. The upload is not subject
+// to the client timeout; ctx bounds it.
+func (g *GCSStore) Put(ctx context.Context, bucket, key string, body io.Reader, size int64, contentType string) error {
+	token, err := g.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	uploadURL := fmt.Sprintf("https://storage.googleapis.com/upload/storage/v1/b/%s/o?uploadType=media&name=%s",
+		url.PathEscape(bucket), url.QueryEscape(key))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadURL, body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Authorization", "Bearer "+token)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := (&http.Client{Transport: g.httpClient.Transport}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("gcs put %s/%s: status %d: %s", bucket, key, resp.StatusCode, string(msg))
+	}
+	return nil
+}
+
+// FetchCommand returns a gsutil download snippet
+func (g *GCSStore) FetchCommand(bucket, key, dest string) string {
+	return fmt.Sprintf("gsutil cp gs://%s/%s %s", bucket, key, dest)
+}
+
+// SyncCommand returns a parallel gsutil rsync snippet
+func (g *GCSStore) SyncCommand(bucket, prefix, dest string, concurrency int) string {
+	return fmt.Sprintf(`gsutil -q -m -o "GSUtil:parallel_process_count=1" -o "GSUtil:parallel_thread_count=%d" rsync -r -d gs://%s/%s '%s'`,
+		concurrency, bucket, prefix, dest)
+}
+
+// doJSON performs an authenticated JSON API request
+func (g *GCSStore) doJSON(ctx context.Context, method, path string, body io.Reader, out interface{}) error {
+	token, err := g.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, "https://storage.googleapis.com"+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrObjectNotFound
+	}
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("status %d: %s", resp.StatusCode, string(msg))
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// token returns the static token or one from the metadata server
+func (g *GCSStore) token(ctx context.Context) (string, error) {
+	if g.accessToken != "" {
+		return g.accessToken, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, gcsMetadataTokenURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch GCS token from metadata server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var tok struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return "", fmt.Errorf("failed to decode GCS token: %w", err)
+	}
+	return tok.AccessToken, nil
+}
@@ -0,0 +1,381 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ErrObjectNotFound is returned when an object does not exist
+var ErrObjectNotFound = errors.New("object not found")
+
+// ErrPresignUnsupported is returned for backends that cannot issue temporary
+// download URLs
+var ErrPresignUnsupported = errors.New("temporary download URLs are not supported by this storage backend")
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	URI          string
+	Key          string
+	Size         int64
+	Checksum     string // ETag/MD5 as reported by the backend
+	LastModified time.Time
+}
+
+// ObjectStore is a bucket-oriented object storage backend
+type ObjectStore interface {
+	// Stat returns metadata for a single object (ErrObjectNotFound if missing)
+	Stat(ctx context.Context, bucket, key string) (*ObjectInfo, error)
+	// List returns up to limit objects under a prefix (0 = no limit) and
+	// whether more objects exist beyond the limit
+	List(ctx context.Context, bucket, prefix string, limit int) ([]ObjectInfo, bool, error)
+	// Get opens an object for reading (ErrObjectNotFound if missing)
+	Get(ctx context.Context, bucket, key string) (io.ReadCloser, error)
+	// Copy copies an object within the same backend
+	Copy(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string) error
+	// Delete removes a single object (ErrObjectNotFound if missing)
+	Delete(ctx context.Context, bucket, key string) error
+	// Put uploads size bytes from body as a single object
+	Put(ctx context.Context, bucket, key string, body io.Reader, size int64, contentType string) error
+	// FetchCommand returns a shell snippet that downloads the object to dest on a node
+	FetchCommand(bucket, key, dest string) string
+	// SyncCommand returns a shell snippet that mirrors every object under
+	// prefix into the directory dest on a node with concurrency parallel transfers
+	SyncCommand(bucket, prefix, dest string, concurrency int) string
+}
+
+// Presigner is implemented by stores that can issue temporary download URLs
+type Presigner interface {
+	// PresignGet returns a URL that downloads the object without credentials
+	// until ttl has passed
+	PresignGet(ctx context.Context, bucket, key string, ttl time.Duration) (string, error)
+}
+
+// ObjectLocation is a parsed object URI
+// Supported forms: s3://bucket/key, gs://bucket/key, az://container/key,
+// minio://alias/bucket/key (alias maps to a configured S3-compatible endpoint)
+type ObjectLocation struct {
+	Scheme string
+	Alias  string // minio:// only
+	Bucket string
+	Key    string
+}
+
+// ParseObjectURI parses an object storage URI
+func ParseObjectURI(uri string) (*ObjectLocation, error) {
+	scheme, rest, ok := strings.Cut(uri, "://")
+	if !ok || rest == "" {
+		return nil, fmt.Errorf("invalid object URI %q", uri)
+	}
+
+	loc := &ObjectLocation{Scheme: scheme}
+	if scheme == "minio" {
+		alias, remainder, ok := strings.Cut(rest, "/")
+		if !ok || alias == "" {
+			return nil, fmt.Errorf("invalid MinIO URI %q: expected minio://alias/bucket/path", uri)
+		}
+		loc.Alias = alias
+		rest = remainder
+	}
+
+	bucket, key, _ := strings.Cut(rest, "/")
+	if bucket == "" {
+		return nil, fmt.Errorf("invalid object URI %q: missing bucket", uri)
+	}
+	loc.Bucket = bucket
+	loc.Key = key
+
+	return loc, nil
+}
+
+// String formats the location back into a URI
+func (l *ObjectLocation) String() string {
+	if l.Scheme == "minio" {
+		return fmt.Sprintf("minio://%s/%s/%s", l.Alias, l.Bucket, l.Key)
+	}
+	return fmt.Sprintf("%s://%s/%s", l.Scheme, l.Bucket, l.Key)
+}
+
+// Registry resolves object URIs to the configured storage backend
+type Registry struct {
+	schemes map[string]ObjectStore
+	aliases map[string]ObjectStore // MinIO aliases
+}
+
+// NewRegistry creates an empty object store registry
+func NewRegistry() *Registry {
+	return &Registry{
+		schemes: make(map[string]ObjectStore),
+		aliases: make(map[string]ObjectStore),
+	}
+}
+
+// RegisterScheme registers the backend for a URI scheme (s3, gs, az)
+func (r *Registry) RegisterScheme(scheme string, store ObjectStore) {
+	r.schemes[scheme] = store
+}
+
+// RegisterMinIOAlias registers the backend for a minio://alias/... URI
+func (r *Registry) RegisterMinIOAlias(alias string, store ObjectStore) {
+	r.aliases[alias] = store
+}
+
+// Resolve returns the backend and parsed location for a URI
+func (r *Registry) Resolve(uri string) (ObjectStore, *ObjectLocation, error) {
+	loc, err := ParseObjectURI(uri)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if loc.Scheme == "minio" {
+		store, ok := r.aliases[loc.Alias]
+		if !ok {
+			return nil, nil, fmt.Errorf("MinIO alias %q not configured", loc.Alias)
+		}
+		return store, loc, nil
+	}
+
+	store, ok := r.schemes[loc.Scheme]
+	if !ok {
+		return nil, nil, fmt.Errorf("object storage scheme %q not configured", loc.Scheme)
+	}
+	return store, loc, nil
+}
+
+// Stat returns metadata for the object at uri
+func (r *Registry) Stat(ctx context.Context, uri string) (*ObjectInfo, error) {
+	store, loc, err := r.Resolve(uri)
+	if err != nil {
+		return nil, err
+	}
+	info, err := store.Stat(ctx, loc.Bucket, loc.Key)
+	if err != nil {
+		return nil, err
+	}
+	info.URI = uri
+	return info, nil
+}
+
+// List returns all objects under the prefix uri
+func (r *Registry) List(ctx context.Context, uri string) ([]ObjectInfo, error) {
+	objects, _, err := r.ListLimit(ctx, uri, 0)
+	return objects, err
+}
+
+// ListLimit returns up to limit objects under the prefix uri (0 = no limit)
+// and whether the listing was truncated
+func (r *Registry) ListLimit(ctx context.Context, uri string, limit int) ([]ObjectInfo, bool, error) {
+	store, loc, err := r.Resolve(uri)
+	if err != nil {
+		return nil, false, err
+	}
+	objects, truncated, err := store.List(ctx, loc.Bucket, loc.Key, limit)
+	if err != nil {
+		return nil, false, err
+	}
+	for i := range objects {
+		objLoc := *loc
+		objLoc.Key = objects[i].Key
+		objects[i].URI = objLoc.String()
+	}
+	return objects, truncated, nil
+}
+
+// Get opens the object at uri for reading
+func (r *Registry) Get(ctx context.Context, uri string) (io.ReadCloser, error) {
+	store, loc, err := r.Resolve(uri)
+	if err != nil {
+		return nil, err
+	}
+	return store.Get(ctx, loc.Bucket, loc.Key)
+}
+
+// PresignGet returns a temporary download URL for the object at uri, valid
+// for ttl. ErrPresignUnsupported if its backend cannot issue one.
+func (r *Registry) PresignGet(ctx context.Context, uri string, ttl time.Duration) (string, error) {
+	store, loc, err := r.Resolve(uri)
+	if err != nil {
+		return "", err
+	}
+	presigner, ok := store.(Presigner)
+	if !ok {
+		return "", ErrPresignUnsupported
+	}
+	return presigner.PresignGet(ctx, loc.Bucket, loc.Key, ttl)
+}
+
+// Copy copies an object; both URIs must resolve to the same backend
+func (r *Registry) Copy(ctx context.Context, srcURI, dstURI string) error {
+	srcStore, src, err := r.Resolve(srcURI)
+	if err != nil {
+		return err
+	}
+	dstStore, dst, err := r.Resolve(dstURI)
+	if err != nil {
+		return err
+	}
+	if srcStore != dstStore {
+		return fmt.Errorf("cross-backend copy from %s to %s is not supported", srcURI, dstURI)
+	}
+	return srcStore.Copy(ctx, src.Bucket, src.Key, dst.Bucket, dst.Key)
+}
+
+// Put uploads size bytes from body to uri
+func (r *Registry) Put(ctx context.Context, uri string, body io.Reader, size int64, contentType string) error {
+	store, loc, err := r.Resolve(uri)
+	if err != nil {
+		return err
+	}
+	return store.Put(ctx, loc.Bucket, loc.Key, body, size, contentType)
+}
+
+// Delete removes the object at uri
+func (r *Registry) Delete(ctx context.Context, uri string) error {
+	store, loc, err := r.Resolve(uri)
+	if err != nil {
+		return err
+	}
+	return store.Delete(ctx, loc.Bucket, loc.Key)
+}
+
+// DeleteArtifact removes an artifact and returns the bytes reclaimed. Like
+// CollectArtifactMeta, a URI that is not a single object is treated as a prefix.
+// An artifact that no longer exists reclaims 0 bytes without error.
+func (r *Registry) DeleteArtifact(ctx context.Context, uri string) (int64, error) {
+	info, err := r.Stat(ctx, uri)
+	if err == nil {
+		if err := r.Delete(ctx, uri); err != nil && !errors.Is(err, ErrObjectNotFound) {
+			return 0, err
+		}
+		return info.Size, nil
+	}
+	if !errors.Is(err, ErrObjectNotFound) {
+		return 0, err
+	}
+
+	prefix := strings.TrimSuffix(uri, "/") + "/"
+	objects, err := r.List(ctx, prefix)
+	if err != nil {
+		return 0, err
+	}
+
+	var reclaimed int64
+	for _, obj := range objects {
+		if err := r.Delete(ctx, obj.URI); err != nil && !errors.Is(err, ErrObjectNotFound) {
+			return reclaimed, fmt.Errorf("failed to delete %s: %w", obj.URI, err)
+		}
+		reclaimed += obj.Size
+	}
+	return reclaimed, nil
+}
+
+// CopyArtifact copies an artifact to dstURI and returns the bytes copied.
+// Like CollectArtifactMeta, a URI that is not a single object is treated as
+// a prefix and every object under it is copied below dstURI. Copies between
+// backends stream each object through this process.
+func (r *Registry) CopyArtifact(ctx context.Context, srcURI, dstURI string) (int64, error) {
+	info, err := r.Stat(ctx, srcURI)
+	if err == nil {
+		return info.Size, r.copyObject(ctx, srcURI, dstURI, info.Size)
+	}
+	if !errors.Is(err, ErrObjectNotFound) {
+		return 0, err
+	}
+
+	prefix := strings.TrimSuffix(srcURI, "/") + "/"
+	objects, err := r.List(ctx, prefix)
+	if err != nil {
+		return 0, err
+	}
+	if len(objects) == 0 {
+		return 0, fmt.Errorf("%s: %w", srcURI, ErrObjectNotFound)
+	}
+
+	var copied int64
+	for _, obj := range objects {
+		dst := strings.TrimSuffix(dstURI, "/") + "/" + strings.TrimPrefix(obj.URI, prefix)
+		if err := r.copyObject(ctx, obj.URI, dst, obj.Size); err != nil {
+			return copied, fmt.Errorf("failed to copy %s: %w", obj.URI, err)
+		}
+		copied += obj.Size
+	}
+	return copied, nil
+}
+
+// copyObject copies one object of size bytes, server-side within a backend
+func (r *Registry) copyObject(ctx context.Context, srcURI, dstURI string, size int64) error {
+	srcStore, _, err := r.Resolve(srcURI)
+	if err != nil {
+		return err
+	}
+	dstStore, _, err := r.Resolve(dstURI)
+	if err != nil {
+		return err
+	}
+	if srcStore == dstStore {
+		return r.Copy(ctx, srcURI, dstURI)
+	}
+
+	body, err := r.Get(ctx, srcURI)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return r.Put(ctx, dstURI, body, size, "application/octet-stream")
+}
+
+// FetchCommand returns a shell snippet that downloads uri to dest on a node
+func (r *Registry) FetchCommand(uri, dest string) (string, error) {
+	store, loc, err := r.Resolve(uri)
+	if err != nil {
+		return "", err
+	}
+	return store.FetchCommand(loc.Bucket, loc.Key, dest), nil
+}
+
+// SyncCommand returns a shell snippet that mirrors the prefix uri into the
+// directory dest on a node, deleting files no longer under the prefix
+func (r *Registry) SyncCommand(uri, dest string, concurrency int) (string, error) {
+	store, loc, err := r.Resolve(uri)
+	if err != nil {
+		return "", err
+	}
+	return store.SyncCommand(loc.Bucket, loc.Key, dest, concurrency), nil
+}
+
+// CollectArtifactMeta verifies that uri exists and returns size/checksum metadata.
+// A URI that is not a single object is treated as a prefix (e.g. a sharded checkpoint directory).
+func (r *Registry) CollectArtifactMeta(ctx context.Context, uri string) (map[string]interface{}, error) {
+	info, err := r.Stat(ctx, uri)
+	if err == nil {
+		return map[string]interface{}{
+			"size_bytes":   info.Size,
+			"checksum":     info.Checksum,
+			"object_count": 1,
+		}, nil
+	}
+	if !errors.Is(err, ErrObjectNotFound) {
+		return nil, err
+	}
+
+	prefix := strings.TrimSuffix(uri, "/") + "/"
+	objects, err := r.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("%s: %w", uri, ErrObjectNotFound)
+	}
+
+	var size int64
+	for _, obj := range objects {
+		size += obj.Size
+	}
+	return map[string]interface{}{
+		"size_bytes":   size,
+		"object_count": len(objects),
+	}, nil
+}
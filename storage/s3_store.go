@@ -0,0 +1,363 @@
+package storage
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// emptyPayloadHash is the SHA-256 of an empty request body
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// unsignedPayload skips hashing streamed upload bodies (integrity is covered by TLS)
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// S3Store talks to AWS S3 or any S3-compatible endpoint (MinIO, Ceph RGW) over the REST API
+type S3Store struct {
+	alias       string // MinIO alias; empty for AWS S3
+	endpoint    string // Empty for AWS S3
+	region      string
+	credentials aws.CredentialsProvider
+	signer      *v4.Signer
+	httpClient  *http.Client
+}
+
+// NewS3Store creates a store for AWS S3
+func NewS3Store(region string, credentials aws.CredentialsProvider) *S3Store {
+	return &S3Store{
+		region:      region,
+		credentials: credentials,
+		signer:      v4.NewSigner(),
+		httpClient:  &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// NewS3CompatibleStore creates a store for an S3-compatible endpoint such as MinIO.
+// Requests use path-style addressing (endpoint/bucket/key).
+func NewS3CompatibleStore(alias, endpoint, region, accessKey, secretKey string) *S3Store {
+	if region == "" {
+		region = "us-east-1" // MinIO default
+	}
+	return &S3Store{
+		alias:    alias,
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		region:   region,
+		credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: accessKey, SecretAccessKey: secretKey, Source: "static"}, nil
+		}),
+		signer:     v4.NewSigner(),
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// Stat returns object metadata via HEAD
+func (s *S3Store) Stat(ctx context.Context, bucket, key string) (*ObjectInfo, error) {
+	resp, err := s.do(ctx, http.MethodHead, bucket, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrObjectNotFound
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("s3 stat %s/%s: status %d", bucket, key, resp.StatusCode)
+	}
+
+	info := &ObjectInfo{
+		Key:      key,
+		Size:     resp.ContentLength,
+		Checksum: strings.Trim(resp.Header.Get("ETag"), `"`),
+	}
+	if lastModified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.LastModified = lastModified
+	}
+	return info, nil
+}
+
+// listBucketResult is the ListObjectsV2 response body
+type listBucketResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		ETag         string    `xml:"ETag"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List returns up to limit objects under prefix using ListObjectsV2
+func (s *S3Store) List(ctx context.Context, bucket, prefix string, limit int) ([]ObjectInfo, bool, error) {
+	var objects []ObjectInfo
+	token := ""
+
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		resp, err := s.do(ctx, http.MethodGet, bucket, "", query, nil)
+		if err != nil {
+			return nil, false, err
+		}
+
+		var result listBucketResult
+		err = decodeXMLResponse(resp, &result)
+		if err != nil {
+			return nil, false, fmt.Errorf("s3 list %s/%s: %w", bucket, prefix, err)
+		}
+
+		for _, c := range result.Contents {
+			if limit > 0 && len(objects) == limit {
+				return objects, true, nil
+			}
+			objects = append(objects, ObjectInfo{
+				Key:          c.Key,
+				Size:         c.Size,
+				Checksum:     strings.Trim(c.ETag, `"`),
+				LastModified: c.LastModified,
+			})
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, false, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// Get performs GetObject; the caller closes the body
+func (s *S3Store) Get(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, bucket, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrObjectNotFound
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("s3 get %s/%s: status %d: %s", bucket, key, resp.StatusCode, string(msg))
+	}
+	return resp.Body, nil
+}
+
+// Copy performs a server-side CopyObject
+func (s *S3Store) Copy(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string) error {
+	header := http.Header{}
+	header.Set("X-Amz-Copy-Source", "/"+srcBucket+"/"+escapeKey(srcKey))
+
+	resp, err := s.do(ctx, http.MethodPut, dstBucket, dstKey, nil, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrObjectNotFound
+	}
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("s3 copy to %s/%s: status %d: %s", dstBucket, dstKey, resp.StatusCode, string(msg))
+	}
+	return nil
+}
+
+// Delete performs DeleteObject. S3 reports success for missing keys, so
+// Delete never returns ErrObjectNotFound for AWS.
+func (s *S3Store) Delete(ctx context.Context, bucket, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, bucket, key, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrObjectNotFound
+	}
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("s3 delete %s/%s: status %d: %s", bucket, key, resp.StatusCode, string(msg))
+	}
+	return nil
+}
+
+// Put performs PutObject, streaming the body without buffering it
+func (s *S3Store) Put(ctx context.Context, bucket, key string, body io.Reader, size int64, contentType string) error {
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s.send(ctx, http.MethodPut, bucket, key, nil, header, body, size, unsignedPayload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("s3 put %s/%s: status %d: %s", bucket, key, resp.StatusCode, string(msg))
+	}
+	return nil
+}
+
+// FetchCommand returns an aws CLI download snippet.
+// For MinIO aliases, credentials are read from MINIO_<ALIAS>_ACCESS_KEY/SECRET_KEY on the node.
+func (s *S3Store) FetchCommand(bucket, key, dest string) string {
+	src := fmt.Sprintf("s3://%s/%s", bucket, key)
+	if s.endpoint == "" {
+		return fmt.Sprintf("aws s3 cp %s %s", src, dest)
+	}
+
+	envPrefix := "MINIO_" + strings.ToUpper(strings.ReplaceAll(s.alias, "-", "_"))
+	return fmt.Sprintf(
+		`AWS_ACCESS_KEY_ID="$%s_ACCESS_KEY" AWS_SECRET_ACCESS_KEY="$%s_SECRET_KEY" AWS_DEFAULT_REGION=%s aws s3 cp %s %s --endpoint-url %s`,
+		envPrefix, envPrefix, s.region, src, dest, s.endpoint,
+	)
+}
+
+// SyncCommand returns an s5cmd sync snippet, falling back to aws s3 sync on
+// nodes without s5cmd
+func (s *S3Store) SyncCommand(bucket, prefix, dest string, concurrency int) string {
+	src := fmt.Sprintf("s3://%s/%s", bucket, prefix)
+	env, endpoint := "", ""
+	if s.endpoint != "" {
+		envPrefix := "MINIO_" + strings.ToUpper(strings.ReplaceAll(s.alias, "-", "_"))
+		env = fmt.Sprintf(`AWS_ACCESS_KEY_ID="$%s_ACCESS_KEY" AWS_SECRET_ACCESS_KEY="$%s_SECRET_KEY" AWS_DEFAULT_REGION=%s `, envPrefix, envPrefix, s.region)
+		endpoint = " --endpoint-url " + s.endpoint
+	}
+	return fmt.Sprintf(`if command -v s5cmd >/dev/null 2>&1; then
+  %ss5cmd%s --numworkers %d sync --delete '%s*' '%s/'
+else
+  aws configure set default.s3.max_concurrent_requests %d
+  %saws s3 sync --only-show-errors --delete %s '%s'%s
+fi`, env, endpoint, concurrency, src, dest, concurrency, env, src, dest, endpoint)
+}
+
+// do builds, signs and sends a request without a body
+func (s *S3Store) do(ctx context.Context, method, bucket, key string, query url.Values, header http.Header) (*http.Response, error) {
+	return s.send(ctx, method, bucket, key, query, header, nil, 0, emptyPayloadHash)
+}
+
+// send builds, signs and sends a request with an optional body.
+// Requests with a body are not subject to the client timeout; ctx bounds them.
+func (s *S3Store) send(
+	ctx context.Context,
+	method, bucket, key string,
+	query url.Values,
+	header http.Header,
+	body io.Reader,
+	size int64,
+	payloadHash string,
+) (*http.Response, error) {
+	rawURL := s.objectURL(bucket, key)
+	if len(query) > 0 {
+		rawURL += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	for k, values := range header {
+		for _, v := range values {
+			req.Header.Add(k, v)
+		}
+	}
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	creds, err := s.credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve S3 credentials: %w", err)
+	}
+	err = s.signer.SignHTTP(ctx, creds, req, payloadHash, "s3", s.region, time.Now(), func(o *v4.SignerOptions) {
+		o.DisableURIPathEscaping = true // Keys are escaped above
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign S3 request: %w", err)
+	}
+
+	if body != nil {
+		return (&http.Client{Transport: s.httpClient.Transport}).Do(req)
+	}
+	return s.httpClient.Do(req)
+}
+
+// PresignGet returns a SigV4 query-signed GET URL valid for ttl (at most
+// 7 days)
+func (s *S3Store) PresignGet(ctx context.Context, bucket, key string, ttl time.Duration) (string, error) {
+	if ttl <= 0 || ttl > 7*24*time.Hour {
+		return "", fmt.Errorf("presigned URL lifetime must be between 1s and 7 days, got %s", ttl)
+	}
+	query := url.Values{"X-Amz-Expires": {strconv.Itoa(int(ttl.Seconds()))}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(bucket, key)+"?"+query.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	creds, err := s.credentials.Retrieve(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve S3 credentials: %w", err)
+	}
+	signed, _, err := s.signer.PresignHTTP(ctx, creds, req, unsignedPayload, "s3", s.region, time.Now(), func(o *v4.SignerOptions) {
+		o.DisableURIPathEscaping = true // Keys are escaped by objectURL
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to presign S3 request: %w", err)
+	}
+	return signed, nil
+}
+
+// objectURL is the URL of an object: path-style for S3-compatible endpoints,
+// virtual-hosted for AWS S3
+func (s *S3Store) objectURL(bucket, key string) string {
+	if s.endpoint != "" {
+		return fmt.Sprintf("%s/%s/%s", s.endpoint, bucket, escapeKey(key))
+	}
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", bucket, s.region, escapeKey(key))
+}
+
+// escapeKey escapes each path segment of an object key
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// decodeXMLResponse decodes a successful XML response and closes the body
+func decodeXMLResponse(resp *http.Response, out interface{}) error {
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("status %d: %s", resp.StatusCode, string(msg))
+	}
+	return xml.NewDecoder(resp.Body).Decode(out)
+}
+
+// parseContentLength parses a Content-Length style header value
+func parseContentLength(value string) int64 {
+	n, _ := strconv.ParseInt(value, 10, 64)
+	return n
+}
@@ -0,0 +1,330 @@
+package bootstrap
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gpu-orchestrator/core/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MaxBytes bounds the combined size of file contents and commands so the
+// rendered user-data stays under provider limits (16 KB on AWS)
+const MaxBytes = 8 * 1024
+
+// maxFiles bounds the number of write_files entries
+const maxFiles = 32
+
+// User is the unprivileged account bootstrap commands run as
+const User = "gpu-bootstrap"
+
+// allowedPathPrefixes are the only locations write_files may target
+var allowedPathPrefixes = []string{
+	"/usr/local/share/ca-certificates/", // Internal CA certificates
+	"/etc/pip.conf",                     // Package index (e.g. Artifactory)
+	"/etc/profile.d/",                   // Environment for login shells
+	"/opt/",                             // Agents and tooling
+}
+
+// caCertPrefix triggers update-ca-certificates when a file is written under it
+const caCertPrefix = "/usr/local/share/ca-certificates/"
+
+// secretPatterns match credentials pasted into snippets. Secrets must be
+// referenced through env_from_secrets instead.
+var secretPatterns = []struct {
+	pattern *regexp.Regexp
+	what    string
+}{
+	{regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`), "an AWS access key"},
+	{regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`), "a private key"},
+	{regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`), "a GitHub token"},
+	{regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`), "a Slack token"},
+	{regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`), "a Google API key"},
+	{regexp.MustCompile(`://[^/\s:@]+:[^$/\s@][^/\s@]*@`), "credentials in a URL"},
+	{regexp.MustCompile(`(?i)\b(password|passwd|secret|token|api[_-]?key)\s*[:=]\s*["']?[^\s"'$]{6,}`), "a literal secret assignment"},
+}
+
+// forbiddenPatterns match commands that escape the restricted user or
+// execute unreviewed code
+var forbiddenPatterns = []struct {
+	pattern *regexp.Regexp
+	what    string
+}{
+	{regexp.MustCompile(`(^|[\s;&|(])sudo\b`), "sudo"},
+	{regexp.MustCompile(`(^|[\s;&|(])(su|runuser|doas|pkexec)\b`), "user switching"},
+	{regexp.MustCompile(`/etc/(sudoers|shadow|passwd)`), "system account files"},
+	{regexp.MustCompile(`chmod\s+[^;&|]*([ugo]*\+[rwx]*s|\b[2467][0-7]{3}\b)`), "setuid/setgid bits"},
+	{regexp.MustCompile(`\|\s*(ba|z|da)?sh\b`), "piping into a shell"},
+}
+
+// envNamePattern matches valid environment variable names
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// secretNamePattern matches secret manager names/paths
+var secretNamePattern = regexp.MustCompile(`^[A-Za-z0-9/_+=.@-]+$`)
+
+// reservedEnv may not be set from secrets
+var reservedEnv = map[string]bool{
+	"PATH": true, "HOME": true, "USER": true, "SHELL": true, "LD_PRELOAD": true, "LD_LIBRARY_PATH": true,
+}
+
+// Validate checks a bootstrap config against the allow-listed capabilities,
+// the size limit and the forbidden patterns
+func Validate(cfg *models.BootstrapConfig) error {
+	if cfg == nil {
+		return nil
+	}
+	if len(cfg.WriteFiles) > maxFiles {
+		return fmt.Errorf("bootstrap.write_files has %d entries, at most %d allowed", len(cfg.WriteFiles), maxFiles)
+	}
+
+	size := 0
+	for i, file := range cfg.WriteFiles {
+		field := fmt.Sprintf("bootstrap.write_files[%d]", i)
+		if err := validatePath(file.Path); err != nil {
+			return fmt.Errorf("%s: %w", field, err)
+		}
+		if _, err := permissions(file); err != nil {
+			return fmt.Errorf("%s: %w", field, err)
+		}
+		if err := checkSecrets(file.Content); err != nil {
+			return fmt.Errorf("%s: %w", field, err)
+		}
+		size += len(file.Content)
+	}
+
+	for i, command := range cfg.Run {
+		field := fmt.Sprintf("bootstrap.run[%d]", i)
+		if strings.TrimSpace(command) == "" {
+			return fmt.Errorf("%s: command is empty", field)
+		}
+		if err := checkSecrets(command); err != nil {
+			return fmt.Errorf("%s: %w", field, err)
+		}
+		for _, forbidden := range forbiddenPatterns {
+			if forbidden.pattern.MatchString(command) {
+				return fmt.Errorf("%s: %s is not allowed; commands run as the unprivileged %s user", field, forbidden.what, User)
+			}
+		}
+		size += len(command)
+	}
+
+	for name, secret := range cfg.EnvFromSecrets {
+		if !envNamePattern.MatchString(name) || reservedEnv[name] {
+			return fmt.Errorf("bootstrap.env_from_secrets: invalid variable name %q", name)
+		}
+		if !secretNamePattern.MatchString(secret) {
+			return fmt.Errorf("bootstrap.env_from_secrets.%s: invalid secret name %q", name, secret)
+		}
+	}
+
+	if size > MaxBytes {
+		return fmt.Errorf("bootstrap snippets are %d bytes, at most %d allowed", size, MaxBytes)
+	}
+	return nil
+}
+
+// validatePath allows absolute, normalized paths under allowedPathPrefixes
+func validatePath(p string) error {
+	if !path.IsAbs(p) || path.Clean(p) != p {
+		return fmt.Errorf("path %q must be absolute and normalized", p)
+	}
+	for _, prefix := range allowedPathPrefixes {
+		if p == prefix || (strings.HasSuffix(prefix, "/") && strings.HasPrefix(p, prefix)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("path %q is not writable (allowed: %s)", p, strings.Join(allowedPathPrefixes, ", "))
+}
+
+// permissions parses a file's octal mode, rejecting setuid/setgid/sticky bits
+func permissions(file models.BootstrapFile) (os.FileMode, error) {
+	if file.Permissions == "" {
+		return 0644, nil
+	}
+	mode, err := strconv.ParseUint(file.Permissions, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf("invalid permissions %q (octal, e.g. 0644, without setuid/setgid/sticky bits)", file.Permissions)
+	}
+	return os.FileMode(mode), nil
+}
+
+// checkSecrets rejects text that contains a literal credential
+func checkSecrets(text string) error {
+	for _, secret := range secretPatterns {
+		if secret.pattern.MatchString(text) {
+			return fmt.Errorf("contains %s; reference secrets through bootstrap.env_from_secrets", secret.what)
+		}
+	}
+	return nil
+}
+
+// ContainsSecret reports whether text contains a literal credential
+func ContainsSecret(text string) bool {
+	return checkSecrets(text) != nil
+}
+
+// LoadDefault loads and validates the org-level default bootstrap from a YAML
+// file with the same shape as the spec's bootstrap block
+func LoadDefault(filePath string) (*models.BootstrapConfig, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bootstrap defaults: %w", err)
+	}
+
+	var file struct {
+		Bootstrap Spec `yaml:"bootstrap"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse bootstrap defaults: %w", err)
+	}
+
+	cfg := file.Bootstrap.Config()
+	if err := Validate(cfg); err != nil {
+		return nil, fmt.Errorf("invalid bootstrap defaults: %w", err)
+	}
+	return cfg, nil
+}
+
+// Spec is the YAML form of a bootstrap block
+type Spec struct {
+	WriteFiles []struct {
+		Path        string `yaml:"path"`
+		Content     string `yaml:"content"`
+		Permissions string `yaml:"permissions,omitempty"`
+	} `yaml:"write_files,omitempty"`
+	Run            []string          `yaml:"run,omitempty"`
+	EnvFromSecrets map[string]string `yaml:"env_from_secrets,omitempty"`
+}
+
+// Config converts the YAML block to its model
+func (s Spec) Config() *models.BootstrapConfig {
+	cfg := &models.BootstrapConfig{
+		Run:            s.Run,
+		EnvFromSecrets: s.EnvFromSecrets,
+	}
+	for _, file := range s.WriteFiles {
+		cfg.WriteFiles = append(cfg.WriteFiles, models.BootstrapFile{
+			Path:        file.Path,
+			Content:     file.Content,
+			Permissions: file.Permissions,
+		})
+	}
+	return cfg
+}
+
+// Merge layers a job's bootstrap over the org default: default files and
+// commands come first, job files replace default files with the same path and
+// job secrets replace default secrets with the same name. Returns nil if both
+// are nil.
+func Merge(defaults, job *models.BootstrapConfig) *models.BootstrapConfig {
+	if defaults == nil && job == nil {
+		return nil
+	}
+
+	merged := &models.BootstrapConfig{}
+	filePos := make(map[string]int)
+	for _, layer := range []*models.BootstrapConfig{defaults, job} {
+		if layer == nil {
+			continue
+		}
+		for _, file := range layer.WriteFiles {
+			if i, ok := filePos[file.Path]; ok {
+				merged.WriteFiles[i] = file
+				continue
+			}
+			filePos[file.Path] = len(merged.WriteFiles)
+			merged.WriteFiles = append(merged.WriteFiles, file)
+		}
+		merged.Run = append(merged.Run, layer.Run...)
+		for name, secret := range layer.EnvFromSecrets {
+			if merged.EnvFromSecrets == nil {
+				merged.EnvFromSecrets = make(map[string]string)
+			}
+			merged.EnvFromSecrets[name] = secret
+		}
+	}
+	return merged
+}
+
+// Render renders a validated bootstrap as a bash snippet for the node boot
+// script. File contents and commands are base64-encoded so no snippet can
+// break out of its quoting; commands run as User with the secrets resolved
+// from the provider's secret manager in their environment only.
+func Render(provider models.Provider, region string, cfg *models.BootstrapConfig) (string, error) {
+	if cfg == nil {
+		return "", nil
+	}
+	if err := Validate(cfg); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString("\n# Job bootstrap\n")
+
+	updateCACerts := false
+	for _, file := range cfg.WriteFiles {
+		mode, _ := permissions(file)
+		fmt.Fprintf(&b, "mkdir -p %s\n", shellQuote(path.Dir(file.Path)))
+		fmt.Fprintf(&b, "echo %s | base64 -d > %s\n", base64.StdEncoding.EncodeToString([]byte(file.Content)), shellQuote(file.Path))
+		fmt.Fprintf(&b, "chmod %04o %s\n", mode, shellQuote(file.Path))
+		if strings.HasPrefix(file.Path, caCertPrefix) {
+			updateCACerts = true
+		}
+	}
+	if updateCACerts {
+		b.WriteString("update-ca-certificates\n")
+	}
+
+	if len(cfg.Run) == 0 {
+		return b.String(), nil
+	}
+
+	fmt.Fprintf(&b, "id -u %s >/dev/null 2>&1 || useradd --system --create-home --shell /usr/sbin/nologin %s\n", User, User)
+	b.WriteString("(\n")
+
+	names := make([]string, 0, len(cfg.EnvFromSecrets))
+	for name := range cfg.EnvFromSecrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fetch, err := secretFetchCommand(provider, region, cfg.EnvFromSecrets[name])
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "  export %s=\"$(%s)\"\n", name, fetch)
+	}
+
+	script := "set -e\n" + strings.Join(cfg.Run, "\n") + "\n"
+	fmt.Fprintf(&b, "  echo %s | base64 -d | runuser -u %s -- bash\n", base64.StdEncoding.EncodeToString([]byte(script)), User)
+	b.WriteString(")\n")
+
+	return b.String(), nil
+}
+
+// secretFetchCommand returns the command that prints a secret on the node
+// using the instance's own identity
+func secretFetchCommand(provider models.Provider, region, secret string) (string, error) {
+	switch provider {
+	case models.ProviderAWS:
+		return fmt.Sprintf("aws secretsmanager get-secret-value --region %s --secret-id %s --query SecretString --output text",
+			shellQuote(region), shellQuote(secret)), nil
+	case models.ProviderGCP:
+		return fmt.Sprintf("gcloud secrets versions access latest --secret=%s", shellQuote(secret)), nil
+	default:
+		return "", fmt.Errorf("bootstrap.env_from_secrets is not supported on provider %s", provider)
+	}
+}
+
+// shellQuote single-quotes s for bash
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
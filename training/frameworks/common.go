@@ -4,8 +4,113 @@ import (
 	"fmt"
 
 	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/training/network"
 )
 
+// ObjectFetcher generates shell commands that download an object URI onto a node
+type ObjectFetcher interface {
+	FetchCommand(uri, dest string) (string, error)
+}
+
+// PortAllocator hands out ports on nodes, so the processes of jobs sharing a
+// node (GPU sharing, bin packing) listen on different ports
+type PortAllocator interface {
+	// AllocatePort returns the port of a job's service on a node; a service
+	// the job already holds there keeps its port
+	AllocatePort(node models.Node, jobID, service string) (int, error)
+}
+
+// Services ports are allocated for
+const (
+	PortServiceMaster = "master" // Rendezvous or coordinator on the master node
+	PortServiceWorker = "worker" // Per-node server, e.g. TensorFlow workers
+)
+
+// masterPort allocates the master port on the master node. Without an
+// allocator the framework's default port is used.
+func masterPort(ports PortAllocator, master models.Node, job *models.Job, fallback int) (int, error) {
+	if ports == nil {
+		return fallback, nil
+	}
+	port, err := ports.AllocatePort(master, job.ID, PortServiceMaster)
+	if err != nil {
+		return 0, fmt.Errorf("failed to allocate master port on node %s: %w", master.ID, err)
+	}
+	return port, nil
+}
+
+// workerPorts allocates the worker port of every node, in node order.
+// Without an allocator every node uses the framework's default port.
+func workerPorts(ports PortAllocator, nodes []models.Node, job *models.Job, fallback int) ([]int, error) {
+	allocated := make([]int, len(nodes))
+	for i, node := range nodes {
+		if ports == nil {
+			allocated[i] = fallback
+			continue
+		}
+		port, err := ports.AllocatePort(node, job.ID, PortServiceWorker)
+		if err != nil {
+			return nil, fmt.Errorf("failed to allocate worker port on node %s: %w", node.ID, err)
+		}
+		allocated[i] = port
+	}
+	return allocated, nil
+}
+
+// entrypointPath is where generated scripts place the downloaded entrypoint
+const entrypointPath = "/tmp/train.py"
+
+// fetchEntrypointCommand returns the shell snippet that downloads the job entrypoint.
+// Without a fetcher the entrypoint is assumed to live on AWS S3.
+func fetchEntrypointCommand(fetcher ObjectFetcher, uri string) string {
+	return fetchObjectCommand(fetcher, uri, entrypointPath, "entrypoint")
+}
+
+// fetchObjectCommand returns the shell snippet that downloads uri to dest;
+// what names the object in the error the script exits with
+func fetchObjectCommand(fetcher ObjectFetcher, uri, dest, what string) string {
+	if fetcher == nil {
+		return fmt.Sprintf("aws s3 cp %s %s", uri, dest)
+	}
+	cmd, err := fetcher.FetchCommand(uri, dest)
+	if err != nil {
+		return fmt.Sprintf("echo %q >&2\nexit 1", "cannot fetch "+what+": "+err.Error())
+	}
+	return cmd
+}
+
+// networkEnvironment resolves the network profile for the cluster (nodes are
+// homogeneous after topology validation) and applies the job's spec overrides
+func networkEnvironment(nodes []models.Node, job *models.Job) (string, map[string]string, error) {
+	profile, err := network.Resolve(nodes[0].Provider, nodes[0].InstanceType, job.Network)
+	if err != nil {
+		return "", nil, err
+	}
+	return profile.Name, network.Environment(profile, job.Network), nil
+}
+
+// sharedEnvironment is the network environment plus the job's checkpoint
+// schedule (CHECKPOINT_INTERVAL, CHECKPOINT_INTERVAL_UNIT, CHECKPOINT_URI)
+func sharedEnvironment(nodes []models.Node, job *models.Job) (string, map[string]string, error) {
+	profileName, env, err := networkEnvironment(nodes, job)
+	if err != nil || job.Checkpointing == nil {
+		return profileName, env, err
+	}
+	return profileName, mergeEnv(env, job.Checkpointing.Environment(job.ID)), nil
+}
+
+// mergeEnv returns base with overrides applied on top
+func mergeEnv(base, overrides map[string]string) map[string]string {
+	merged := make(map[string]string, len(base)+len(overrides))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range overrides {
+		merged[k] = v
+	}
+	return merged
+}
+
 // validateClusterTopology validates that all nodes are in same provider+region+network
 // Phase 4: Common validation for all frameworks
 func validateClusterTopology(cluster *models.Cluster) error {
@@ -34,3 +139,49 @@ func validateClusterTopology(cluster *models.Cluster) error {
 
 	return nil
 }
+
+// assignRanks orders cluster nodes so that index == rank and every rank in
+// onDemandRanks lands on an on-demand (non-spot) node.
+// Ranks beyond the cluster size are ignored.
+func assignRanks(nodes []models.Node, onDemandRanks []int) ([]models.Node, error) {
+	pinned := make(map[int]bool)
+	for _, rank := range onDemandRanks {
+		if rank < len(nodes) {
+			pinned[rank] = true
+		}
+	}
+	if len(pinned) == 0 {
+		return nodes, nil
+	}
+
+	var onDemand, spot []models.Node
+	for _, node := range nodes {
+		if node.Spot {
+			spot = append(spot, node)
+		} else {
+			onDemand = append(onDemand, node)
+		}
+	}
+	if len(onDemand) < len(pinned) {
+		return nil, fmt.Errorf("cannot pin %d ranks to on-demand nodes: only %d on-demand nodes", len(pinned), len(onDemand))
+	}
+
+	ordered := make([]models.Node, len(nodes))
+	for rank := 0; rank < len(nodes); rank++ {
+		if pinned[rank] {
+			ordered[rank] = onDemand[0]
+			onDemand = onDemand[1:]
+		}
+	}
+
+	// Fill remaining ranks with leftover nodes (on-demand first, preserving order)
+	remaining := append(onDemand, spot...)
+	for rank := 0; rank < len(nodes); rank++ {
+		if !pinned[rank] {
+			ordered[rank] = remaining[0]
+			remaining = remaining[1:]
+		}
+	}
+
+	return ordered, nil
+}
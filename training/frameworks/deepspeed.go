@@ -0,0 +1,234 @@
+package frameworks
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/training/network"
+)
+
+// Paths of the files the DeepSpeed launcher reads on the master node
+const (
+	deepSpeedHostfile = "/tmp/deepspeed_hostfile"
+	deepSpeedConfig   = "/tmp/ds_config.json"
+)
+
+// defaultDeepSpeedConfig is used when the job gives no ds_config and none
+// is on the node yet (e.g. placed by a bootstrap snippet); ZeRO is off
+var defaultDeepSpeedConfig = map[string]interface{}{
+	"train_micro_batch_size_per_gpu": 1,
+	"gradient_accumulation_steps":    1,
+	"zero_optimization":              map[string]interface{}{"stage": 0},
+}
+
+// DeepSpeedSetup handles DeepSpeed training setup. The deepspeed launcher
+// runs on the master node and starts the workers of every node over SSH
+// from a hostfile.
+type DeepSpeedSetup struct {
+	Fetcher ObjectFetcher // Downloads the entrypoint and ds_config; nil = aws s3 cp
+	Ports   PortAllocator // Allocates the master port; nil = 29500
+}
+
+var _ Setup = (*DeepSpeedSetup)(nil)
+
+// Name returns the framework name
+func (d *DeepSpeedSetup) Name() string {
+	return "deepspeed"
+}
+
+// SetupDistributedTraining sets up DeepSpeed training within a single cluster
+func (d *DeepSpeedSetup) SetupDistributedTraining(
+	cluster *models.Cluster,
+	job *models.Job,
+) (*DistributedConfig, error) {
+	if err := validateClusterTopology(cluster); err != nil {
+		return nil, fmt.Errorf("cluster topology validation failed: %w", err)
+	}
+
+	// Pin requested ranks (e.g. rank 0) onto on-demand nodes
+	nodes, err := assignRanks(cluster.Nodes, job.Constraints.OnDemandRanks)
+	if err != nil {
+		return nil, err
+	}
+
+	// Select NCCL/fabric settings for the provider and instance type
+	networkProfile, networkEnv, err := sharedEnvironment(nodes, job)
+	if err != nil {
+		return nil, err
+	}
+
+	port, err := masterPort(d.Ports, nodes[0], job, 29500)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &DistributedConfig{
+		Framework:      "deepspeed",
+		MasterAddr:     nodes[0].PrivateIP,
+		MasterPort:     port,
+		WorldSize:      len(nodes),
+		Nodes:          make([]NodeConfig, len(nodes)),
+		NetworkProfile: networkProfile,
+		NetworkEnv:     networkEnv,
+	}
+
+	for i, node := range nodes {
+		config.Nodes[i] = NodeConfig{
+			Rank:        i,
+			Address:     node.PrivateIP,
+			GPUs:        node.GPUs,
+			Spot:        node.Spot,
+			Environment: mergeEnv(d.getEnvironment(config, job, i), networkEnv),
+		}
+	}
+
+	return config, nil
+}
+
+// getEnvironment returns environment variables for a node
+func (d *DeepSpeedSetup) getEnvironment(config *DistributedConfig, job *models.Job, rank int) map[string]string {
+	return map[string]string{
+		"MASTER_ADDR":    config.MasterAddr,
+		"MASTER_PORT":    strconv.Itoa(config.MasterPort),
+		"NODE_RANK":      strconv.Itoa(rank),
+		"NNODES":         strconv.Itoa(config.WorldSize),
+		"DS_HOSTFILE":    deepSpeedHostfile,
+		"DS_CONFIG":      deepSpeedConfig,
+		"DS_ACCELERATOR": deepSpeedAccelerator(job),
+		"NCCL_DEBUG":     "INFO",
+	}
+}
+
+// deepSpeedAccelerator returns the job's DS_ACCELERATOR, cuda by default
+func deepSpeedAccelerator(job *models.Job) string {
+	if job.FrameworkConfig != nil && job.FrameworkConfig.Accelerator != "" {
+		return job.FrameworkConfig.Accelerator
+	}
+	return "cuda"
+}
+
+// Environment returns the node's DeepSpeed environment
+func (d *DeepSpeedSetup) Environment(config *DistributedConfig, rank int) map[string]string {
+	return nodeEnvironment(config, rank)
+}
+
+// GenerateNodeScripts returns the launcher script. Only the master node
+// launches; the other nodes must accept SSH from it.
+func (d *DeepSpeedSetup) GenerateNodeScripts(config *DistributedConfig, job *models.Job) string {
+	// A single node needs no hostfile or SSH
+	launcher := fmt.Sprintf("deepspeed --num_gpus=%d", config.Nodes[0].GPUs)
+	if config.WorldSize > 1 {
+		launcher = "deepspeed \\\n    --hostfile=$DS_HOSTFILE \\\n    --master_addr=$MASTER_ADDR \\\n    --master_port=$MASTER_PORT"
+	}
+
+	return fmt.Sprintf(`#!/bin/bash
+set -e
+
+# Download training script
+%s
+
+# Network tuning (profile: %s)
+%s
+export MASTER_ADDR=%s
+export MASTER_PORT=%d
+export DS_HOSTFILE=%s
+export DS_CONFIG=%s
+export DS_ACCELERATOR=%s
+export NCCL_DEBUG=${NCCL_DEBUG:-INFO}
+
+# DeepSpeed hostfile (one line per node)
+cat > $DS_HOSTFILE <<EOF
+%sEOF
+
+# DeepSpeed config
+%s
+
+# Launch training on every node from the master
+%s \
+    %s --deepspeed --deepspeed_config $DS_CONFIG
+`, fetchEntrypointCommand(d.Fetcher, job.EntrypointURI), config.NetworkProfile, network.ExportLines(config.NetworkEnv),
+		config.MasterAddr, config.MasterPort, deepSpeedHostfile, deepSpeedConfig, deepSpeedAccelerator(job),
+		renderHostfile(config), d.configCommand(job), launcher, entrypointPath)
+}
+
+// renderHostfile returns the DeepSpeed hostfile: one "address slots=GPUs"
+// line per node, in rank order
+func renderHostfile(config *DistributedConfig) string {
+	var hostfile strings.Builder
+	for _, node := range config.Nodes {
+		fmt.Fprintf(&hostfile, "%s slots=%d\n", node.Address, node.GPUs)
+	}
+	return hostfile.String()
+}
+
+// configCommand returns the shell snippet that places the job's ds_config at
+// DS_CONFIG: downloaded from config_uri, rendered from the inline config, or
+// the default unless the node already has one. A spec ZeRO stage overrides
+// the stage of any of them.
+func (d *DeepSpeedSetup) configCommand(job *models.Job) string {
+	fc := job.FrameworkConfig
+	if fc == nil {
+		fc = &models.FrameworkConfig{}
+	}
+
+	if fc.ConfigURI != "" {
+		command := fetchObjectCommand(d.Fetcher, fc.ConfigURI, deepSpeedConfig, "ds_config")
+		if fc.ZeroStage != nil {
+			command += fmt.Sprintf(`
+python3 - <<'EOF'
+import json
+with open(%q) as f:
+    config = json.load(f)
+config.setdefault("zero_optimization", {})["stage"] = %d
+with open(%q, "w") as f:
+    json.dump(config, f, indent=2)
+EOF`, deepSpeedConfig, *fc.ZeroStage, deepSpeedConfig)
+		}
+		return command
+	}
+
+	rendered, err := RenderDeepSpeedConfig(fc)
+	if err != nil {
+		return fmt.Sprintf("echo %q >&2\nexit 1", "cannot render ds_config: "+err.Error())
+	}
+	write := fmt.Sprintf("cat > $DS_CONFIG <<'EOF'\n%s\nEOF", rendered)
+	if fc.Config == nil && fc.ZeroStage == nil {
+		// Keep a config placed by a bootstrap snippet
+		return fmt.Sprintf("if [ ! -f \"$DS_CONFIG\" ]; then\n%s\nfi", write)
+	}
+	return write
+}
+
+// RenderDeepSpeedConfig renders the inline ds_config of a framework_config
+// block, or the default config without one, with its ZeRO stage applied
+func RenderDeepSpeedConfig(fc *models.FrameworkConfig) (string, error) {
+	base := defaultDeepSpeedConfig
+	if fc != nil && fc.Config != nil {
+		base = fc.Config
+	}
+
+	// Copy the top level and the ZeRO section so the job's config is not modified
+	config := make(map[string]interface{}, len(base))
+	for key, value := range base {
+		config[key] = value
+	}
+	if fc != nil && fc.ZeroStage != nil {
+		zero := make(map[string]interface{})
+		if existing, ok := config["zero_optimization"].(map[string]interface{}); ok {
+			for key, value := range existing {
+				zero[key] = value
+			}
+		}
+		zero["stage"] = *fc.ZeroStage
+		config["zero_optimization"] = zero
+	}
+
+	rendered, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode ds_config: %w", err)
+	}
+	return string(rendered), nil
+}
@@ -9,7 +9,39 @@ import (
 
 // HorovodSetup handles Horovod distributed training setup
 // Phase 4: Full Horovod support
-type HorovodSetup struct{}
+type HorovodSetup struct {
+	Fetcher    ObjectFetcher // Downloads the entrypoint; nil = aws s3 cp
+	APIBaseURL string        // Orchestrator URL nodes poll for elastic hosts; empty = static host list
+	Ports      PortAllocator // Allocates the master port; nil = 29500
+	name       string        // Registered name; "" = horovod
+}
+
+var _ Setup = (*HorovodSetup)(nil)
+
+// Name returns the framework name
+func (h *HorovodSetup) Name() string {
+	if h.name == "" {
+		return "horovod"
+	}
+	return h.name
+}
+
+// Environment returns the node's Horovod environment
+func (h *HorovodSetup) Environment(config *DistributedConfig, rank int) map[string]string {
+	return nodeEnvironment(config, rank)
+}
+
+// GenerateNodeScripts returns the horovodrun script, elastic when the job
+// has node bounds
+func (h *HorovodSetup) GenerateNodeScripts(config *DistributedConfig, job *models.Job) string {
+	elastic := job.Requirements.Elastic
+	if elastic == nil {
+		return h.GenerateTrainingScript(config, job)
+	}
+	// Workers are GPU slots; nodes are homogeneous so scale the node bounds
+	gpusPerNode := config.Nodes[0].GPUs
+	return h.GenerateElasticTrainingScript(config, job, elastic.MinNodes*gpusPerNode, elastic.MaxNodes*gpusPerNode)
+}
 
 // SetupDistributedTraining sets up Horovod distributed training
 func (h *HorovodSetup) SetupDistributedTraining(
@@ -28,29 +60,49 @@ func (h *HorovodSetup) SetupDistributedTraining(
 		return nil, fmt.Errorf("cluster has no nodes")
 	}
 
+	// Pin requested ranks (e.g. rank 0) onto on-demand nodes
+	nodes, err := assignRanks(cluster.Nodes, job.Constraints.OnDemandRanks)
+	if err != nil {
+		return nil, err
+	}
+
+	// Select NCCL/fabric settings for the provider and instance type
+	networkProfile, networkEnv, err := sharedEnvironment(nodes, job)
+	if err != nil {
+		return nil, err
+	}
+
+	port, err := masterPort(h.Ports, nodes[0], job, 29500)
+	if err != nil {
+		return nil, err
+	}
+
 	// Horovod uses MPI for communication
 	// Master node (rank 0) coordinates training
 	config := &DistributedConfig{
-		Framework:  "horovod",
-		MasterAddr: cluster.Nodes[0].PrivateIP,
-		MasterPort: 29500,
-		WorldSize:  len(cluster.Nodes),
-		Nodes:      make([]NodeConfig, len(cluster.Nodes)),
+		Framework:      "horovod",
+		MasterAddr:     nodes[0].PrivateIP,
+		MasterPort:     port,
+		WorldSize:      len(nodes),
+		Nodes:          make([]NodeConfig, len(nodes)),
+		NetworkProfile: networkProfile,
+		NetworkEnv:     networkEnv,
 	}
 
 	// Calculate total GPUs across all nodes
 	totalGPUs := 0
-	for _, node := range cluster.Nodes {
+	for _, node := range nodes {
 		totalGPUs += node.GPUs
 	}
 
 	// Setup each node
-	for i, node := range cluster.Nodes {
+	for i, node := range nodes {
 		config.Nodes[i] = NodeConfig{
 			Rank:        i,
 			Address:     node.PrivateIP,
 			GPUs:        node.GPUs,
-			Environment: h.getEnvironment(job, i, len(cluster.Nodes), totalGPUs),
+			Spot:        node.Spot,
+			Environment: mergeEnv(h.getEnvironment(job, i, len(nodes), totalGPUs), networkEnv),
 		}
 	}
 
@@ -98,6 +150,9 @@ func (h *HorovodSetup) GenerateTrainingScript(
 	script := `#!/bin/bash
 # Auto-generated Horovod training script
 
+# Download training script
+` + fetchEntrypointCommand(h.Fetcher, job.EntrypointURI) + `
+
 # Set environment variables
 `
 	
@@ -134,7 +189,7 @@ horovodrun \
     -np $TOTAL_PROCESSES \
     -H ` + config.MasterAddr + `:$TOTAL_PROCESSES \
     --hostfile $HOSTFILE \
-    python ` + job.EntrypointURI + `
+    python ` + entrypointPath + `
 `
 
 	return script
@@ -154,6 +209,9 @@ func (h *HorovodSetup) GenerateElasticTrainingScript(
 	script := `#!/bin/bash
 # Auto-generated Horovod Elastic training script
 
+# Download training script
+` + fetchEntrypointCommand(h.Fetcher, job.EntrypointURI) + `
+
 # Horovod Elastic configuration
 export HOROVOD_ELASTIC_MIN_WORKERS=` + strconv.Itoa(minWorkers) + `
 export HOROVOD_ELASTIC_MAX_WORKERS=` + strconv.Itoa(maxWorkers) + `
@@ -164,10 +222,15 @@ cat > $HOROVOD_ELASTIC_DISCOVERY_SCRIPT <<'EOF'
 #!/bin/bash
 # Discovery script returns available hosts
 `
-	
-	// Add hosts to discovery script
-	for _, node := range config.Nodes {
-		script += fmt.Sprintf("echo \"%s:%d\"\n", node.Address, node.GPUs)
+
+	if h.APIBaseURL != "" {
+		// Hosts change as the orchestrator adds or removes nodes
+		script += fmt.Sprintf("curl -sf %s/v1/jobs/%s/elastic/hosts\n", h.APIBaseURL, job.ID)
+	} else {
+		// Add hosts to discovery script
+		for _, node := range config.Nodes {
+			script += fmt.Sprintf("echo \"%s:%d\"\n", node.Address, node.GPUs)
+		}
 	}
 	
 	script += `EOF
@@ -179,7 +242,7 @@ horovodrun \
     --min-np ` + strconv.Itoa(minWorkers) + ` \
     --max-np ` + strconv.Itoa(maxWorkers) + ` \
     --discovery-script $HOROVOD_ELASTIC_DISCOVERY_SCRIPT \
-    python ` + job.EntrypointURI + `
+    python ` + entrypointPath + `
 `
 
 	return script
@@ -0,0 +1,142 @@
+package frameworks
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/training/network"
+)
+
+// jaxCoordinatorPort is the port of the jax.distributed coordinator on rank 0
+const jaxCoordinatorPort = 1234
+
+// JAXSetup handles multi-host JAX training. Every node runs one process that
+// sees all of its GPUs; the entrypoint calls jax.distributed.initialize with
+// the coordinator address, process ID and process count from the environment.
+type JAXSetup struct {
+	Fetcher ObjectFetcher // Downloads the entrypoint; nil = aws s3 cp
+	Ports   PortAllocator // Allocates the coordinator port; nil = 1234
+}
+
+var _ Setup = (*JAXSetup)(nil)
+
+// Name returns the framework name
+func (j *JAXSetup) Name() string {
+	return "jax"
+}
+
+// SetupDistributedTraining sets up jax.distributed within a single cluster,
+// with rank 0 as the coordinator
+func (j *JAXSetup) SetupDistributedTraining(
+	cluster *models.Cluster,
+	job *models.Job,
+) (*DistributedConfig, error) {
+	if err := validateClusterTopology(cluster); err != nil {
+		return nil, fmt.Errorf("cluster topology validation failed: %w", err)
+	}
+
+	// Pin requested ranks (e.g. the coordinator) onto on-demand nodes
+	nodes, err := assignRanks(cluster.Nodes, job.Constraints.OnDemandRanks)
+	if err != nil {
+		return nil, err
+	}
+
+	// Select NCCL/fabric settings for the provider and instance type
+	networkProfile, networkEnv, err := sharedEnvironment(nodes, job)
+	if err != nil {
+		return nil, err
+	}
+
+	port, err := masterPort(j.Ports, nodes[0], job, jaxCoordinatorPort)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &DistributedConfig{
+		Framework:      "jax",
+		MasterAddr:     nodes[0].PrivateIP,
+		MasterPort:     port,
+		WorldSize:      len(nodes),
+		Nodes:          make([]NodeConfig, len(nodes)),
+		NetworkProfile: networkProfile,
+		NetworkEnv:     networkEnv,
+	}
+
+	for i, node := range nodes {
+		config.Nodes[i] = NodeConfig{
+			Rank:        i,
+			Address:     node.PrivateIP,
+			GPUs:        node.GPUs,
+			Spot:        node.Spot,
+			Environment: mergeEnv(j.getEnvironment(config, job, i), networkEnv),
+		}
+	}
+
+	return config, nil
+}
+
+// getEnvironment returns environment variables for a node
+func (j *JAXSetup) getEnvironment(config *DistributedConfig, job *models.Job, rank int) map[string]string {
+	env := map[string]string{
+		"JAX_COORDINATOR_ADDRESS": fmt.Sprintf("%s:%d", config.MasterAddr, config.MasterPort),
+		"JAX_PROCESS_ID":          strconv.Itoa(rank),
+		"JAX_NUM_PROCESSES":       strconv.Itoa(config.WorldSize),
+		"NCCL_DEBUG":              "INFO",
+	}
+	if flags := jaxXLAFlags(job); flags != "" {
+		env["XLA_FLAGS"] = flags
+	}
+	return env
+}
+
+// jaxXLAFlags returns the job's XLA_FLAGS value; "" = none
+func jaxXLAFlags(job *models.Job) string {
+	if job.FrameworkConfig == nil {
+		return ""
+	}
+	return strings.Join(job.FrameworkConfig.XLAFlags, " ")
+}
+
+// Environment returns the node's JAX environment
+func (j *JAXSetup) Environment(config *DistributedConfig, rank int) map[string]string {
+	return nodeEnvironment(config, rank)
+}
+
+// GenerateNodeScripts returns the script run on every node. Each node finds
+// its process ID by its private IP.
+func (j *JAXSetup) GenerateNodeScripts(config *DistributedConfig, job *models.Job) string {
+	var processIDs strings.Builder
+	for _, node := range config.Nodes {
+		fmt.Fprintf(&processIDs, "    %s) export JAX_PROCESS_ID=%d ;;\n", node.Address, node.Rank)
+	}
+
+	xlaFlags := ""
+	if flags := jaxXLAFlags(job); flags != "" {
+		xlaFlags = fmt.Sprintf("export XLA_FLAGS=%q\n", flags)
+	}
+
+	return fmt.Sprintf(`#!/bin/bash
+set -e
+
+# Download training script
+%s
+
+# Network tuning (profile: %s)
+%s
+export JAX_COORDINATOR_ADDRESS=%s:%d
+export JAX_NUM_PROCESSES=%d
+export NCCL_DEBUG=${NCCL_DEBUG:-INFO}
+%s
+# Process ID of this node (rank order)
+NODE_IP=$(hostname -I | awk '{print $1}')
+case "$NODE_IP" in
+%s    *) echo "node $NODE_IP is not part of the cluster" >&2; exit 1 ;;
+esac
+
+# One process per node; the entrypoint calls jax.distributed.initialize()
+python %s
+`, fetchEntrypointCommand(j.Fetcher, job.EntrypointURI), config.NetworkProfile, network.ExportLines(config.NetworkEnv),
+		config.MasterAddr, config.MasterPort, config.WorldSize, xlaFlags, processIDs.String(), entrypointPath)
+}
@@ -6,18 +6,31 @@ import (
 	"strings"
 
 	"gpu-orchestrator/core/models"
+	"gpu-orchestrator/training/network"
 )
 
 // PyTorchSetup handles PyTorch DDP training setup
-type PyTorchSetup struct{}
+type PyTorchSetup struct {
+	Fetcher ObjectFetcher // Downloads the entrypoint; nil = aws s3 cp
+	Ports   PortAllocator // Allocates the master port; nil = 29500
+}
+
+var _ Setup = (*PyTorchSetup)(nil)
+
+// Name returns the framework name
+func (p *PyTorchSetup) Name() string {
+	return "pytorch_ddp"
+}
 
 // DistributedConfig represents distributed training configuration
 type DistributedConfig struct {
-	Framework  string
-	MasterAddr string
-	MasterPort int
-	WorldSize  int
-	Nodes      []NodeConfig
+	Framework      string
+	MasterAddr     string
+	MasterPort     int
+	WorldSize      int
+	Nodes          []NodeConfig
+	NetworkProfile string            // Name of the selected network profile
+	NetworkEnv     map[string]string // NCCL/fabric and checkpoint settings shared by all nodes
 }
 
 // NodeConfig represents configuration for a single node
@@ -25,6 +38,8 @@ type NodeConfig struct {
 	Rank        int
 	Address     string
 	GPUs        int
+	Spot        bool
+	Port        int // Server port of the node's worker; 0 = the framework runs none
 	Environment map[string]string
 }
 
@@ -38,18 +53,36 @@ func (p *PyTorchSetup) SetupDistributedTraining(
 		return nil, fmt.Errorf("cluster topology validation failed: %w", err)
 	}
 
-	nodes := cluster.Nodes
-	if len(nodes) == 0 {
+	if len(cluster.Nodes) == 0 {
 		return nil, fmt.Errorf("cluster has no nodes")
 	}
 
+	// Pin requested ranks (e.g. rank 0) onto on-demand nodes
+	nodes, err := assignRanks(cluster.Nodes, job.Constraints.OnDemandRanks)
+	if err != nil {
+		return nil, err
+	}
+
+	// Select NCCL/fabric settings for the provider and instance type
+	networkProfile, networkEnv, err := sharedEnvironment(nodes, job)
+	if err != nil {
+		return nil, err
+	}
+
+	port, err := masterPort(p.Ports, nodes[0], job, 29500)
+	if err != nil {
+		return nil, err
+	}
+
 	// All nodes should be in same provider/region/VPC (validated above)
 	config := &DistributedConfig{
-		Framework:  "pytorch",
-		MasterAddr: nodes[0].PrivateIP,
-		MasterPort: 29500,
-		WorldSize:  len(nodes),
-		Nodes:      make([]NodeConfig, len(nodes)),
+		Framework:      "pytorch",
+		MasterAddr:     nodes[0].PrivateIP,
+		MasterPort:     port,
+		WorldSize:      len(nodes),
+		Nodes:          make([]NodeConfig, len(nodes)),
+		NetworkProfile: networkProfile,
+		NetworkEnv:     networkEnv,
 	}
 
 	for i, node := range nodes {
@@ -57,7 +90,8 @@ func (p *PyTorchSetup) SetupDistributedTraining(
 			Rank:        i,
 			Address:     node.PrivateIP,
 			GPUs:        node.GPUs,
-			Environment: p.getEnvironment(job, i, len(nodes)),
+			Spot:        node.Spot,
+			Environment: mergeEnv(p.getEnvironment(job, i, len(nodes), port), networkEnv),
 		}
 	}
 
@@ -94,18 +128,31 @@ func (p *PyTorchSetup) validateClusterTopology(cluster *models.Cluster) error {
 }
 
 // getEnvironment returns environment variables for a node
-func (p *PyTorchSetup) getEnvironment(_ *models.Job, rank int, worldSize int) map[string]string {
+func (p *PyTorchSetup) getEnvironment(_ *models.Job, rank int, worldSize int, masterPort int) map[string]string {
 	return map[string]string{
 		"MASTER_ADDR":          "", // Will be set per node
-		"MASTER_PORT":          "29500",
+		"MASTER_PORT":          strconv.Itoa(masterPort),
 		"WORLD_SIZE":           strconv.Itoa(worldSize),
 		"RANK":                 strconv.Itoa(rank),
 		"NCCL_DEBUG":           "INFO",
-		"NCCL_SOCKET_IFNAME":   "eth0",
 		"CUDA_VISIBLE_DEVICES": "0,1,2,3,4,5,6,7", // TODO: Set based on actual GPUs
 	}
 }
 
+// Environment returns the node's environment with the master address resolved
+func (p *PyTorchSetup) Environment(config *DistributedConfig, rank int) map[string]string {
+	env := nodeEnvironment(config, rank)
+	if env != nil {
+		env["MASTER_ADDR"] = config.MasterAddr
+	}
+	return env
+}
+
+// GenerateNodeScripts returns the torchrun script run on every node
+func (p *PyTorchSetup) GenerateNodeScripts(config *DistributedConfig, job *models.Job) string {
+	return p.GenerateTrainingScript(config, job)
+}
+
 // GenerateTrainingScript generates the training script wrapper
 func (p *PyTorchSetup) GenerateTrainingScript(config *DistributedConfig, job *models.Job) string {
 	// For single-node multi-GPU
@@ -113,15 +160,17 @@ func (p *PyTorchSetup) GenerateTrainingScript(config *DistributedConfig, job *mo
 		return fmt.Sprintf(`#!/bin/bash
 set -e
 
-# Download training script from S3
-aws s3 cp %s /tmp/train.py
+# Download training script
+%s
 
+# Network tuning (profile: %s)
+%s
 # Set environment variables
 export MASTER_ADDR=%s
 export MASTER_PORT=%d
 export WORLD_SIZE=%d
 export RANK=0
-export NCCL_DEBUG=INFO
+export NCCL_DEBUG=${NCCL_DEBUG:-INFO}
 
 # Launch training with torchrun (PyTorch 2.0+)
 python -m torch.distributed.run \
@@ -131,7 +180,7 @@ python -m torch.distributed.run \
     --master_addr=$MASTER_ADDR \
     --master_port=$MASTER_PORT \
     /tmp/train.py
-`, job.EntrypointURI, config.MasterAddr, config.MasterPort, config.WorldSize, config.Nodes[0].GPUs)
+`, fetchEntrypointCommand(p.Fetcher, job.EntrypointURI), config.NetworkProfile, network.ExportLines(config.NetworkEnv), config.MasterAddr, config.MasterPort, config.WorldSize, config.Nodes[0].GPUs)
 	}
 
 	// For multi-node
@@ -142,7 +191,7 @@ export MASTER_ADDR=%s
 export MASTER_PORT=%d
 export WORLD_SIZE=%d
 export RANK=%d
-export NCCL_DEBUG=INFO
+export NCCL_DEBUG=${NCCL_DEBUG:-INFO}
 
 python -m torch.distributed.run \
     --nproc_per_node=%d \
@@ -158,9 +207,11 @@ python -m torch.distributed.run \
 	return fmt.Sprintf(`#!/bin/bash
 set -e
 
-# Download training script from S3
-aws s3 cp %s /tmp/train.py
+# Download training script
+%s
 
+# Network tuning (profile: %s)
+%s
 %s
-`, job.EntrypointURI, strings.Join(nodeScripts, "\n\n"))
+`, fetchEntrypointCommand(p.Fetcher, job.EntrypointURI), config.NetworkProfile, network.ExportLines(config.NetworkEnv), strings.Join(nodeScripts, "\n\n"))
 }
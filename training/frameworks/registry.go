@@ -0,0 +1,107 @@
+package frameworks
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"gpu-orchestrator/core/models"
+)
+
+// Setup prepares distributed training for one framework on a cluster
+type Setup interface {
+	// Name is the framework name jobs select in their spec
+	Name() string
+	// SetupDistributedTraining assigns ranks and resolves the configuration
+	// of every node of the cluster
+	SetupDistributedTraining(cluster *models.Cluster, job *models.Job) (*DistributedConfig, error)
+	// GenerateNodeScripts returns the script run on the cluster's nodes
+	GenerateNodeScripts(config *DistributedConfig, job *models.Job) string
+	// Environment returns the resolved environment of the node with rank
+	Environment(config *DistributedConfig, rank int) map[string]string
+}
+
+// Options are the deployment settings a setup is created with
+type Options struct {
+	Fetcher    ObjectFetcher // Downloads the entrypoint; nil = aws s3 cp
+	APIBaseURL string        // Orchestrator URL reachable from nodes; empty = not reachable
+	Ports      PortAllocator // Allocates master and worker ports; nil = framework default ports
+}
+
+// Factory creates a framework's setup for a deployment
+type Factory func(opts Options) Setup
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Factory)
+)
+
+func init() {
+	Register("pytorch_ddp", func(opts Options) Setup {
+		return &PyTorchSetup{Fetcher: opts.Fetcher, Ports: opts.Ports}
+	})
+	horovod := func(name string) Factory {
+		return func(opts Options) Setup {
+			return &HorovodSetup{Fetcher: opts.Fetcher, APIBaseURL: opts.APIBaseURL, Ports: opts.Ports, name: name}
+		}
+	}
+	Register("horovod", horovod("horovod"))
+	Register("horovod_elastic", horovod("horovod_elastic"))
+	Register("tensorflow_multiworker", func(opts Options) Setup {
+		return &TensorFlowSetup{Fetcher: opts.Fetcher, Ports: opts.Ports}
+	})
+	Register("deepspeed", func(opts Options) Setup {
+		return &DeepSpeedSetup{Fetcher: opts.Fetcher, Ports: opts.Ports}
+	})
+	Register("jax", func(opts Options) Setup {
+		return &JAXSetup{Fetcher: opts.Fetcher, Ports: opts.Ports}
+	})
+}
+
+// Register makes a framework available to job specs under name, replacing
+// any setup registered under the same name. Deployments register custom
+// setups before the server starts accepting jobs.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = factory
+}
+
+// Lookup returns the setup of a framework created with opts
+func Lookup(name string, opts Options) (Setup, error) {
+	registryMu.RLock()
+	factory, ok := registry[name]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported framework %q (registered: %v)", name, Names())
+	}
+	return factory(opts), nil
+}
+
+// Registered reports whether a framework has a setup
+func Registered(name string) bool {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	_, ok := registry[name]
+	return ok
+}
+
+// Names returns the registered frameworks, sorted
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// nodeEnvironment returns a copy of the environment resolved for rank
+func nodeEnvironment(config *DistributedConfig, rank int) map[string]string {
+	if rank < 0 || rank >= len(config.Nodes) {
+		return nil
+	}
+	return mergeEnv(config.Nodes[rank].Environment, nil)
+}
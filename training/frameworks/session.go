@@ -0,0 +1,80 @@
+package frameworks
+
+import (
+	"fmt"
+	"strconv"
+
+	"gpu-orchestrator/core/models"
+)
+
+// Session ports on the node
+const (
+	JupyterPort = 8888
+	SSHPort     = 22
+)
+
+// SessionSetup generates the bootstrap script for interactive session jobs
+type SessionSetup struct {
+	ActivityURL string // Endpoint the node posts GPU utilization to; empty = no idle detection
+}
+
+// Endpoint returns the connection endpoint of a session on its first node
+func (s *SessionSetup) Endpoint(cluster *models.Cluster, job *models.Job, token string) (models.SessionEndpoint, error) {
+	if len(cluster.Nodes) == 0 {
+		return models.SessionEndpoint{}, fmt.Errorf("cluster has no nodes")
+	}
+
+	host := cluster.Nodes[0].PrivateIP
+	if job.Session != nil && job.Session.Jupyter {
+		return models.SessionEndpoint{
+			Host:     host,
+			Port:     JupyterPort,
+			Protocol: "jupyter",
+			Token:    token,
+			URL:      fmt.Sprintf("http://%s:%d/lab?token=%s", host, JupyterPort, token),
+		}, nil
+	}
+	return models.SessionEndpoint{
+		Host:     host,
+		Port:     SSHPort,
+		Protocol: "ssh",
+	}, nil
+}
+
+// GenerateSessionScript generates the node bootstrap script for a session:
+// ensure SSH, optionally start Jupyter, and report GPU utilization
+func (s *SessionSetup) GenerateSessionScript(job *models.Job, endpoint models.SessionEndpoint) string {
+	script := `#!/bin/bash
+# Auto-generated interactive session bootstrap for job ` + job.ID + `
+
+# Ensure SSH is running
+systemctl enable --now ssh 2>/dev/null || systemctl enable --now sshd
+`
+
+	if endpoint.Protocol == "jupyter" {
+		script += `
+# Start Jupyter
+pip install --quiet jupyterlab
+nohup jupyter lab \
+    --ip=0.0.0.0 \
+    --port=` + strconv.Itoa(endpoint.Port) + ` \
+    --no-browser \
+    --ServerApp.token=` + endpoint.Token + ` \
+    > /var/log/jupyter.log 2>&1 &
+`
+	}
+
+	if s.ActivityURL != "" {
+		// Average utilization across GPUs, reported every minute for idle detection
+		script += `
+# Report GPU utilization
+nohup bash -c 'while true; do
+    util=$(nvidia-smi --query-gpu=utilization.gpu --format=csv,noheader,nounits | awk "{s+=\$1} END {print (NR ? s/NR : 0)}")
+    curl -sf -X POST -H "Content-Type: application/json" -d "{\"gpu_utilization\": ${util}}" ` + s.ActivityURL + ` > /dev/null
+    sleep 60
+done' > /dev/null 2>&1 &
+`
+	}
+
+	return script
+}
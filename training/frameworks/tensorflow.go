@@ -9,7 +9,27 @@ import (
 
 // TensorFlowSetup handles TensorFlow MultiWorkerMirroredStrategy setup
 // Phase 4: TensorFlow distributed training support
-type TensorFlowSetup struct{}
+type TensorFlowSetup struct {
+	Fetcher ObjectFetcher // Downloads the entrypoint; nil = aws s3 cp
+	Ports   PortAllocator // Allocates each worker's port; nil = 2222
+}
+
+var _ Setup = (*TensorFlowSetup)(nil)
+
+// Name returns the framework name
+func (t *TensorFlowSetup) Name() string {
+	return "tensorflow_multiworker"
+}
+
+// Environment returns the node's environment, including its TF_CONFIG
+func (t *TensorFlowSetup) Environment(config *DistributedConfig, rank int) map[string]string {
+	return nodeEnvironment(config, rank)
+}
+
+// GenerateNodeScripts returns the script run on every worker
+func (t *TensorFlowSetup) GenerateNodeScripts(config *DistributedConfig, job *models.Job) string {
+	return t.GenerateTrainingScript(config, job)
+}
 
 // SetupDistributedTraining sets up TensorFlow MultiWorkerMirroredStrategy
 func (t *TensorFlowSetup) SetupDistributedTraining(
@@ -28,28 +48,50 @@ func (t *TensorFlowSetup) SetupDistributedTraining(
 		return nil, fmt.Errorf("cluster has no nodes")
 	}
 
+	// Pin requested ranks (chief is worker 0) onto on-demand nodes
+	nodes, err := assignRanks(cluster.Nodes, job.Constraints.OnDemandRanks)
+	if err != nil {
+		return nil, err
+	}
+
+	// Select NCCL/fabric settings for the provider and instance type
+	networkProfile, networkEnv, err := sharedEnvironment(nodes, job)
+	if err != nil {
+		return nil, err
+	}
+
 	// Calculate total workers
 	totalWorkers := 0
-	for _, node := range cluster.Nodes {
+	for _, node := range nodes {
 		totalWorkers += node.GPUs // Each GPU is a worker
 	}
 
+	// Every worker runs a server; workers sharing a node need their own port
+	ports, err := workerPorts(t.Ports, nodes, job, 2222) // TensorFlow default port
+	if err != nil {
+		return nil, err
+	}
+
 	config := &DistributedConfig{
-		Framework:  "tensorflow",
-		MasterAddr: cluster.Nodes[0].PrivateIP,
-		MasterPort: 2222, // TensorFlow default port
-		WorldSize:  len(cluster.Nodes),
-		Nodes:      make([]NodeConfig, len(cluster.Nodes)),
+		Framework:      "tensorflow",
+		MasterAddr:     nodes[0].PrivateIP,
+		MasterPort:     ports[0], // Chief is worker 0
+		WorldSize:      len(nodes),
+		Nodes:          make([]NodeConfig, len(nodes)),
+		NetworkProfile: networkProfile,
+		NetworkEnv:     networkEnv,
 	}
 
 	// Setup each node
 	workerIndex := 0
-	for i, node := range cluster.Nodes {
+	for i, node := range nodes {
 		config.Nodes[i] = NodeConfig{
 			Rank:        i,
 			Address:     node.PrivateIP,
 			GPUs:        node.GPUs,
-			Environment: t.getEnvironment(job, i, len(cluster.Nodes), workerIndex, totalWorkers),
+			Spot:        node.Spot,
+			Port:        ports[i],
+			Environment: mergeEnv(t.getEnvironment(job, i, ports, workerIndex, totalWorkers), networkEnv),
 		}
 		workerIndex += node.GPUs
 	}
@@ -61,7 +103,7 @@ func (t *TensorFlowSetup) SetupDistributedTraining(
 func (t *TensorFlowSetup) getEnvironment(
 	job *models.Job,
 	taskIndex int,
-	numTasks int,
+	ports []int,
 	workerIndex int,
 	totalWorkers int,
 ) map[string]string {
@@ -70,11 +112,11 @@ func (t *TensorFlowSetup) getEnvironment(
 
 	// Build cluster spec
 	clusterSpec := `{"worker": [`
-	for i := 0; i < numTasks; i++ {
+	for i, port := range ports {
 		if i > 0 {
 			clusterSpec += ","
 		}
-		clusterSpec += fmt.Sprintf(`"%s:2222"`, fmt.Sprintf("node-%d", i))
+		clusterSpec += fmt.Sprintf(`"%s:%d"`, fmt.Sprintf("node-%d", i), port)
 	}
 	clusterSpec += `]}`
 
@@ -113,6 +155,9 @@ func (t *TensorFlowSetup) GenerateTrainingScript(
 	script := `#!/bin/bash
 # Auto-generated TensorFlow MultiWorker training script
 
+# Download training script
+` + fetchEntrypointCommand(t.Fetcher, job.EntrypointURI) + `
+
 # Set environment variables
 `
 	
@@ -129,23 +174,23 @@ func (t *TensorFlowSetup) GenerateTrainingScript(
 
 # Run TensorFlow training
 python %s
-`, job.EntrypointURI)
+`, entrypointPath)
 
 	return script
 }
 
-// GenerateTFConfig generates TF_CONFIG JSON for a specific node
+// GenerateTFConfig generates TF_CONFIG JSON for a specific node of a setup
 func (t *TensorFlowSetup) GenerateTFConfig(
-	cluster *models.Cluster,
+	config *DistributedConfig,
 	taskIndex int,
 ) string {
 	// Phase 4: Generate TF_CONFIG for specific task
 	clusterSpec := `{"worker": [`
-	for i := 0; i < len(cluster.Nodes); i++ {
+	for i, node := range config.Nodes {
 		if i > 0 {
 			clusterSpec += ","
 		}
-		clusterSpec += fmt.Sprintf(`"%s:2222"`, cluster.Nodes[i].PrivateIP)
+		clusterSpec += fmt.Sprintf(`"%s:%d"`, node.Address, node.Port)
 	}
 	clusterSpec += `]}`
 
@@ -0,0 +1,176 @@
+package network
+
+import (
+	_ "embed"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gpu-orchestrator/core/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed profiles.yaml
+var profilesYAML []byte
+
+// Profile is a set of NCCL/fabric settings for a provider + interconnect combination
+type Profile struct {
+	Name          string                  `yaml:"name"`
+	Provider      models.Provider         `yaml:"provider"`
+	Interconnect  models.InterconnectTier `yaml:"interconnect"`
+	InstanceTypes []string                `yaml:"instance_types"` // Instance type prefixes
+	Env           map[string]string       `yaml:"env"`
+	Packages      []Package               `yaml:"packages"`
+}
+
+// Package is a host dependency installed by the VM bootstrap script
+type Package struct {
+	Name    string `yaml:"name"`
+	Check   string `yaml:"check"`   // Shell command; exit 0 = already installed
+	Install string `yaml:"install"` // Shell snippet run when Check fails
+}
+
+// profiles holds the embedded profiles in file order
+var profiles []Profile
+
+// envKeyPattern matches valid environment variable names
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func init() {
+	var file struct {
+		Profiles []Profile `yaml:"profiles"`
+	}
+	if err := yaml.Unmarshal(profilesYAML, &file); err != nil {
+		panic(fmt.Sprintf("invalid embedded network profiles: %v", err))
+	}
+	profiles = file.Profiles
+}
+
+// Get returns a profile by name
+func Get(name string) (*Profile, bool) {
+	for i := range profiles {
+		if profiles[i].Name == name {
+			return &profiles[i], true
+		}
+	}
+	return nil, false
+}
+
+// Lookup returns the best profile for a provider and instance type.
+// Instance type matches win over the provider default, which wins over the global fallback.
+func Lookup(provider models.Provider, instanceType string) *Profile {
+	var providerDefault, fallback *Profile
+
+	for i := range profiles {
+		p := &profiles[i]
+		switch {
+		case p.Provider == "":
+			if fallback == nil {
+				fallback = p
+			}
+		case p.Provider != provider:
+			continue
+		case len(p.InstanceTypes) == 0:
+			if providerDefault == nil {
+				providerDefault = p
+			}
+		default:
+			for _, prefix := range p.InstanceTypes {
+				if instanceType != "" && strings.HasPrefix(instanceType, prefix) {
+					return p
+				}
+			}
+		}
+	}
+
+	if providerDefault != nil {
+		return providerDefault
+	}
+	return fallback
+}
+
+// Resolve selects the profile for a node type, honoring a spec override.
+// Returns an error if the override names an unknown profile.
+func Resolve(provider models.Provider, instanceType string, overrides models.NetworkOverrides) (*Profile, error) {
+	if overrides.Profile != "" {
+		p, ok := Get(overrides.Profile)
+		if !ok {
+			return nil, fmt.Errorf("unknown network profile %q", overrides.Profile)
+		}
+		return p, nil
+	}
+	return Lookup(provider, instanceType), nil
+}
+
+// Environment returns the profile environment with spec overrides applied
+func Environment(p *Profile, overrides models.NetworkOverrides) map[string]string {
+	env := make(map[string]string, len(p.Env)+len(overrides.Env))
+	for k, v := range p.Env {
+		env[k] = v
+	}
+	for k, v := range overrides.Env {
+		env[k] = v
+	}
+	return env
+}
+
+// BootstrapScript returns the shell snippet that installs missing host packages
+func (p *Profile) BootstrapScript() string {
+	if len(p.Packages) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Network profile: %s\n", p.Name)
+	for _, pkg := range p.Packages {
+		fmt.Fprintf(&b, "if ! %s; then\n", pkg.Check)
+		fmt.Fprintf(&b, "    echo \"Installing %s\"\n", pkg.Name)
+		for _, line := range strings.Split(strings.TrimRight(pkg.Install, "\n"), "\n") {
+			fmt.Fprintf(&b, "    %s\n", line)
+		}
+		b.WriteString("fi\n")
+	}
+	return b.String()
+}
+
+// ValidateOverrides checks a spec network block
+func ValidateOverrides(overrides models.NetworkOverrides) error {
+	if overrides.Profile != "" {
+		if _, ok := Get(overrides.Profile); !ok {
+			return fmt.Errorf("unknown network profile %q (available: %s)", overrides.Profile, strings.Join(Names(), ", "))
+		}
+	}
+	for key := range overrides.Env {
+		if !envKeyPattern.MatchString(key) {
+			return fmt.Errorf("invalid network env variable name %q", key)
+		}
+	}
+	return nil
+}
+
+// Names returns all profile names, sorted
+func Names() []string {
+	names := make([]string, len(profiles))
+	for i, p := range profiles {
+		names[i] = p.Name
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ExportLines renders env as sorted shell export statements
+func ExportLines(env map[string]string) string {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, "export %s=%q\n", k, env[k])
+	}
+	return b.String()
+}